@@ -22,6 +22,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/component/tracer"
 	"github.com/benthosdev/benthos/v4/internal/config"
 	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/manager"
 
 	_ "github.com/benthosdev/benthos/v4/public/components/all"
 )
@@ -236,6 +237,11 @@ func walkTypeWithConfig(t *testing.T, prefix string, spec docs.FieldSpec, v refl
 		return
 	}
 
+	// Fields of raw resources are parsed by their implementations.
+	if v == reflect.TypeOf(manager.RawResourceConfig{}) {
+		return
+	}
+
 	if spec.Kind == docs.Kind2DArray {
 		if !assert.True(t, v.Kind() == reflect.Slice, "%v: documented as array but is %v", prefix, v.Kind()) {
 			return
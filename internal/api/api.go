@@ -14,6 +14,7 @@ import (
 	"github.com/gorilla/mux"
 	yaml "gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/httpserver"
 	"github.com/benthosdev/benthos/v4/internal/log"
@@ -29,6 +30,23 @@ type Config struct {
 	KeyFile        string                     `json:"key_file" yaml:"key_file"`
 	CORS           httpserver.CORSConfig      `json:"cors" yaml:"cors"`
 	BasicAuth      httpserver.BasicAuthConfig `json:"basic_auth" yaml:"basic_auth"`
+	Readiness      ReadinessConfig            `json:"readiness" yaml:"readiness"`
+	UI             UIConfig                   `json:"ui" yaml:"ui"`
+}
+
+// ReadinessConfig contains configuration fields that determine how the
+// readiness of a stream is derived from the connectivity of its components.
+type ReadinessConfig struct {
+	Strict      bool   `json:"strict" yaml:"strict"`
+	GracePeriod string `json:"grace_period" yaml:"grace_period"`
+}
+
+// NewReadinessConfig creates a new readiness config with default values.
+func NewReadinessConfig() ReadinessConfig {
+	return ReadinessConfig{
+		Strict:      false,
+		GracePeriod: "",
+	}
 }
 
 // NewConfig creates a new API config with default values.
@@ -42,6 +60,8 @@ func NewConfig() Config {
 		KeyFile:        "",
 		CORS:           httpserver.NewServerCORSConfig(),
 		BasicAuth:      httpserver.NewBasicAuthConfig(),
+		Readiness:      NewReadinessConfig(),
+		UI:             NewUIConfig(),
 	}
 }
 
@@ -57,6 +77,15 @@ func OptWithMiddleware(m func(http.Handler) http.Handler) OptFunc {
 	}
 }
 
+// OptWithBloblangEnvironment sets the Bloblang environment used for executing
+// mappings via the /bloblang/execute endpoint, which defaults to the global
+// environment.
+func OptWithBloblangEnvironment(env *bloblang.Environment) OptFunc {
+	return func(t *Type) {
+		t.bloblEnv = env
+	}
+}
+
 // OptWithTLS replaces the tls options of the HTTP server.
 func OptWithTLS(tls *tls.Config) OptFunc {
 	return func(t *Type) {
@@ -81,6 +110,9 @@ type Type struct {
 	log    log.Modular
 	mux    *mux.Router
 	server *http.Server
+
+	bloblEnv *bloblang.Environment
+	ui       *UIRecorder
 }
 
 // New creates a new Benthos HTTP API.
@@ -118,6 +150,7 @@ func New(
 		mux:       gMux,
 		server:    server,
 		log:       log,
+		bloblEnv:  bloblang.GlobalEnvironment(),
 	}
 	t.ctx, t.cancel = context.WithCancel(context.Background())
 
@@ -221,6 +254,12 @@ func New(
 				" parameter, or for 1 second if not specified.",
 			pprof.Trace,
 		)
+		t.RegisterEndpoint(
+			"/bloblang/execute", "DEBUG: Executes a Bloblang mapping against an"+
+				" input document with the functions and methods available to"+
+				" the running instance.",
+			bloblangExecuteHandler(t.ExecuteBloblang),
+		)
 	}
 
 	t.RegisterEndpoint("/ping", "Ping me.", handlePing)
@@ -233,6 +272,11 @@ func New(
 		t.RegisterEndpoint("/metrics", "Exposes service-wide metrics in the format configured.", wHandlerFunc)
 	}
 
+	if conf.UI.Enabled {
+		t.ui = NewUIRecorder(wholeConf)
+		t.registerUI(version)
+	}
+
 	for _, opt := range opts {
 		opt(t)
 	}
@@ -240,6 +284,11 @@ func New(
 	return t, nil
 }
 
+// UI returns the recorder of the admin UI, or nil if the UI is disabled.
+func (t *Type) UI() *UIRecorder {
+	return t.ui
+}
+
 // Handler returns the underlying http.Hander where paths are registered.
 func (t *Type) Handler() http.Handler {
 	return t.server.Handler
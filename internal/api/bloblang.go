@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/parser"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// BloblangExecuteRequest is the body of a request to the /bloblang/execute
+// endpoint.
+type BloblangExecuteRequest struct {
+	Mapping  string            `json:"mapping"`
+	Input    string            `json:"input"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// BloblangExecuteResponse is the body of a response from the /bloblang/execute
+// endpoint. When the mapping fails to parse the line and column of the error
+// within the mapping are also provided.
+type BloblangExecuteResponse struct {
+	ParseError       string            `json:"parse_error,omitempty"`
+	ParseErrorLine   int               `json:"parse_error_line,omitempty"`
+	ParseErrorColumn int               `json:"parse_error_column,omitempty"`
+	MappingError     string            `json:"mapping_error,omitempty"`
+	Deleted          bool              `json:"deleted,omitempty"`
+	Result           string            `json:"result"`
+	Metadata         map[string]string `json:"metadata"`
+}
+
+// ExecuteBloblang parses and executes a Bloblang mapping against an input
+// document using the Bloblang environment of the API, which includes any
+// functions and methods registered as plugins.
+func (t *Type) ExecuteBloblang(req BloblangExecuteRequest) BloblangExecuteResponse {
+	return executeBloblang(t.bloblEnv, req)
+}
+
+func executeBloblang(env *bloblang.Environment, req BloblangExecuteRequest) (res BloblangExecuteResponse) {
+	exec, err := env.NewMapping(req.Mapping)
+	if err != nil {
+		var perr *parser.Error
+		if errors.As(err, &perr) {
+			mapping := []rune(req.Mapping)
+			res.ParseError = fmt.Sprintf("failed to parse mapping: %v", perr.ErrorAtPositionStructured("", mapping))
+			res.ParseErrorLine, res.ParseErrorColumn = parser.LineAndColOf(mapping, perr.Input)
+		} else {
+			res.ParseError = err.Error()
+		}
+		return
+	}
+
+	part := message.NewPart([]byte(req.Input))
+	for k, v := range req.Metadata {
+		part.MetaSetMut(k, v)
+	}
+
+	resPart, err := exec.MapPart(0, message.Batch{part})
+	if err != nil {
+		res.MappingError = err.Error()
+		return
+	}
+	if resPart == nil {
+		res.Deleted = true
+		return
+	}
+
+	res.Result = string(resPart.AsBytes())
+	res.Metadata = map[string]string{}
+	_ = resPart.MetaIterStr(func(k, v string) error {
+		res.Metadata[k] = v
+		return nil
+	})
+	return
+}
+
+func bloblangExecuteHandler(execute func(BloblangExecuteRequest) BloblangExecuteResponse) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req BloblangExecuteRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(execute(req)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/api"
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/log"
+)
+
+func TestBloblangExecuteDisabled(t *testing.T) {
+	s, err := api.New("", "", api.NewConfig(), nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	request, _ := http.NewRequest("POST", "/bloblang/execute", bytes.NewReader([]byte(`{"mapping":"root = this"}`)))
+	response := httptest.NewRecorder()
+	s.Handler().ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestBloblangExecute(t *testing.T) {
+	env := bloblang.NewEnvironment()
+	require.NoError(t, env.RegisterFunction(
+		query.NewFunctionSpec(query.FunctionCategoryGeneral, "meow", ""),
+		func(args *query.ParsedParams) (query.Function, error) {
+			return query.ClosureFunction("meow", func(ctx query.FunctionContext) (any, error) {
+				return "meow", nil
+			}, nil), nil
+		},
+	))
+
+	conf := api.NewConfig()
+	conf.DebugEndpoints = true
+
+	s, err := api.New("", "", conf, nil, log.Noop(), metrics.Noop(), api.OptWithBloblangEnvironment(env))
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name   string
+		method string
+		body   string
+		code   int
+		output api.BloblangExecuteResponse
+	}{
+		{
+			name:   "custom function",
+			method: "POST",
+			body:   `{"mapping":"root.sound = meow()\nroot.name = this.name\nmeta foo = \"bar\"","input":"{\"name\":\"cat\"}"}`,
+			code:   http.StatusOK,
+			output: api.BloblangExecuteResponse{
+				Result:   `{"name":"cat","sound":"meow"}`,
+				Metadata: map[string]string{"foo": "bar"},
+			},
+		},
+		{
+			name:   "parse error position",
+			method: "POST",
+			body:   `{"mapping":"root = this\nroot.foo = nope()","input":"{}"}`,
+			code:   http.StatusOK,
+			output: api.BloblangExecuteResponse{
+				ParseError:       "failed to parse mapping: line 2 char 12: unrecognised function 'nope'\n  |\n2 | root.foo = nope()\n  |            ^---",
+				ParseErrorLine:   2,
+				ParseErrorColumn: 12,
+			},
+		},
+		{
+			name:   "mapping error",
+			method: "POST",
+			body:   `{"mapping":"root = this.name.uppercase()","input":"{}"}`,
+			code:   http.StatusOK,
+			output: api.BloblangExecuteResponse{
+				MappingError: "failed assignment (line 1): expected string value, got null from field `this.name`",
+			},
+		},
+		{
+			name:   "bad request",
+			method: "POST",
+			body:   `not json`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "wrong method",
+			method: "GET",
+			code:   http.StatusMethodNotAllowed,
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			request, _ := http.NewRequest(test.method, "/bloblang/execute", bytes.NewReader([]byte(test.body)))
+			response := httptest.NewRecorder()
+			s.Handler().ServeHTTP(response, request)
+			require.Equal(t, test.code, response.Code, response.Body.String())
+			if test.code != http.StatusOK {
+				return
+			}
+
+			var res api.BloblangExecuteResponse
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &res))
+			assert.Equal(t, test.output, res)
+		})
+	}
+}
@@ -25,6 +25,11 @@ func Spec() docs.FieldSpecs {
 		docs.FieldString("key_file", "An optional key file for enabling TLS.").Advanced().HasDefault(""),
 		httpserver.ServerCORSFieldSpec(),
 		httpserver.BasicAuthFieldSpec(),
+		docs.FieldObject("readiness", "Determines how the readiness of the service, as reported by the `/ready` endpoint, is derived from the connection status of its inputs and outputs.").WithChildren(
+			docs.FieldBool("strict", "Whether readiness should fail when any output (including those within brokers) is disconnected, rather than only when the top level output is disconnected.").HasDefault(false),
+			docs.FieldString("grace_period", "An optional period during which components that have lost their connection are reported as degraded rather than disconnected, and are therefore still considered ready. This prevents brief connection losses from failing readiness checks.", "5s", "1m").HasDefault(""),
+		).Advanced().AtVersion("4.24.0"),
+		UIFieldSpec(),
 	}
 }
 
@@ -62,12 +67,22 @@ http:
   cors:
     enabled: false
     allowed_origins: []
+    allowed_methods: [ GET, HEAD, POST, PUT, PATCH, DELETE ]
+    allowed_headers: []
+    exposed_headers: []
+    allow_credentials: false
+    max_age: 0
   basic_auth:
     enabled: false
     username: ""
     password_hash: ""
     algorithm: "sha256"
     salt: ""
+  readiness:
+    strict: false
+    grace_period: ""
+  ui:
+    enabled: false
 `,
 	})
 
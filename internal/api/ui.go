@@ -0,0 +1,444 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+
+	_ "embed"
+)
+
+//go:embed resources/ui.html
+var uiPage string
+
+const (
+	uiMaxErrors      = 50
+	uiMaxSamples     = 20
+	uiSampleInterval = time.Second
+)
+
+// UIConfig contains configuration fields for the embedded admin UI.
+type UIConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+}
+
+// NewUIConfig creates a new UI config with default values.
+func NewUIConfig() UIConfig {
+	return UIConfig{
+		Enabled: false,
+	}
+}
+
+// UIFieldSpec returns a field spec for the admin UI configuration.
+func UIFieldSpec() docs.FieldSpec {
+	return docs.FieldObject("ui", "Serves an embedded admin UI at the `/ui` endpoint, showing the topology of the pipeline, the live status and metrics of its components, recent errors, and a Bloblang playground that can be used against messages sampled from the input. The UI is only available when running a single config outside of streams mode.\n\nThe contents and metadata of messages sampled from the input are served by the `/ui/samples` endpoint to anyone able to reach the HTTP server, and therefore the UI should not be enabled when the server is exposed to untrusted networks. Mappings executed by the playground are unable to import files or to use functions and methods that access the host, such as `env` and `file`.").WithChildren(
+		docs.FieldBool("enabled", "Whether to serve the admin UI.").HasDefault(false),
+	).Advanced().AtVersion("4.24.0")
+}
+
+//------------------------------------------------------------------------------
+
+// UIError is a log entry at the error or warning level captured for the admin
+// UI.
+type UIError struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields"`
+}
+
+// UISample is a message consumed by the input captured for the admin UI.
+type UISample struct {
+	Time     time.Time         `json:"time"`
+	Content  string            `json:"content"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// UIComponent describes a component of the pipeline along with its metrics.
+type UIComponent struct {
+	Path    string           `json:"path"`
+	Kind    string           `json:"kind"`
+	Type    string           `json:"type"`
+	Label   string           `json:"label"`
+	Metrics map[string]int64 `json:"metrics"`
+}
+
+// UIRecorder captures the metrics, errors and sampled messages of a running
+// service that are presented by the admin UI.
+type UIRecorder struct {
+	conf  any
+	local *metrics.Local
+
+	mut        sync.Mutex
+	errors     []UIError
+	samples    []UISample
+	lastSample time.Time
+}
+
+// NewUIRecorder creates a recorder for the admin UI, where conf is the config
+// of the service that is used in order to determine component types.
+func NewUIRecorder(conf any) *UIRecorder {
+	return &UIRecorder{
+		conf:  conf,
+		local: metrics.NewLocal(),
+	}
+}
+
+// Metrics returns local metrics that should be added to the metrics of the
+// service.
+func (u *UIRecorder) Metrics() *metrics.Local {
+	return u.local
+}
+
+// WrapLogger returns a logger that records errors and warnings before passing
+// them to the provided logger.
+func (u *UIRecorder) WrapLogger(l log.Modular) log.Modular {
+	return &uiLogger{next: l, rec: u, fields: map[string]string{}}
+}
+
+// SampleBatch captures the messages of a batch consumed by the input, at most
+// one batch is captured each second.
+func (u *UIRecorder) SampleBatch(batch message.Batch) {
+	now := time.Now()
+
+	u.mut.Lock()
+	defer u.mut.Unlock()
+
+	if now.Sub(u.lastSample) < uiSampleInterval {
+		return
+	}
+	u.lastSample = now
+
+	_ = batch.Iter(func(i int, p *message.Part) error {
+		s := UISample{
+			Time:     now,
+			Content:  string(p.AsBytes()),
+			Metadata: map[string]string{},
+		}
+		_ = p.MetaIterStr(func(k, v string) error {
+			s.Metadata[k] = v
+			return nil
+		})
+		u.samples = append(u.samples, s)
+		return nil
+	})
+	if len(u.samples) > uiMaxSamples {
+		u.samples = u.samples[len(u.samples)-uiMaxSamples:]
+	}
+}
+
+func (u *UIRecorder) recordError(level, msg string, fields map[string]string) {
+	u.mut.Lock()
+	defer u.mut.Unlock()
+
+	u.errors = append(u.errors, UIError{
+		Time:    time.Now(),
+		Level:   level,
+		Message: strings.TrimSpace(msg),
+		Fields:  fields,
+	})
+	if len(u.errors) > uiMaxErrors {
+		u.errors = u.errors[len(u.errors)-uiMaxErrors:]
+	}
+}
+
+// Errors returns the errors and warnings that were recently logged, most
+// recent first.
+func (u *UIRecorder) Errors() []UIError {
+	u.mut.Lock()
+	defer u.mut.Unlock()
+
+	errs := make([]UIError, 0, len(u.errors))
+	for i := len(u.errors) - 1; i >= 0; i-- {
+		errs = append(errs, u.errors[i])
+	}
+	return errs
+}
+
+// Samples returns the messages that were recently sampled, most recent first.
+func (u *UIRecorder) Samples() []UISample {
+	u.mut.Lock()
+	defer u.mut.Unlock()
+
+	samples := make([]UISample, 0, len(u.samples))
+	for i := len(u.samples) - 1; i >= 0; i-- {
+		samples = append(samples, u.samples[i])
+	}
+	return samples
+}
+
+var uiKindOrder = map[string]int{
+	"input":      0,
+	"buffer":     1,
+	"processor":  2,
+	"output":     3,
+	"cache":      4,
+	"rate_limit": 5,
+}
+
+// Topology returns the components of the service that have emitted metrics,
+// in the order that messages flow through them.
+func (u *UIRecorder) Topology() []UIComponent {
+	var conf any
+	switch t := u.conf.(type) {
+	case yaml.Node:
+		_ = t.Decode(&conf)
+	case *yaml.Node:
+		_ = t.Decode(&conf)
+	default:
+		conf = t
+	}
+
+	components := map[string]*UIComponent{}
+	for k, v := range u.local.GetCounters() {
+		name, tagNames, tagValues := metrics.ReverseLabelledPath(k)
+
+		var path, label string
+		for i, tName := range tagNames {
+			switch tName {
+			case "path":
+				path = tagValues[i]
+			case "label":
+				label = tagValues[i]
+			}
+		}
+		if path == "" {
+			continue
+		}
+
+		var kind, metric string
+		for kindName := range uiKindOrder {
+			if m, ok := strings.CutPrefix(name, kindName+"_"); ok {
+				kind, metric = kindName, m
+				break
+			}
+		}
+		if kind == "" {
+			continue
+		}
+
+		c, exists := components[path]
+		if !exists {
+			c = &UIComponent{
+				Path:    path,
+				Kind:    kind,
+				Type:    uiComponentType(conf, path),
+				Label:   label,
+				Metrics: map[string]int64{},
+			}
+			components[path] = c
+		}
+		c.Metrics[metric] += v
+	}
+
+	res := make([]UIComponent, 0, len(components))
+	for _, c := range components {
+		res = append(res, *c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Kind != res[j].Kind {
+			return uiKindOrder[res[i].Kind] < uiKindOrder[res[j].Kind]
+		}
+		return res[i].Path < res[j].Path
+	})
+	return res
+}
+
+// uiComponentType resolves the type of a component from its path within the
+// config, returning an empty string if it cannot be resolved.
+func uiComponentType(conf any, path string) string {
+	segments := strings.Split(path, ".")
+	if len(segments) == 0 || segments[0] != "root" {
+		return ""
+	}
+
+	current := conf
+	for _, seg := range segments[1:] {
+		switch t := current.(type) {
+		case map[string]any:
+			current = t[seg]
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil {
+				// Labelled children are identified by their label.
+				if i = uiLabelIndex(t, seg); i == -1 {
+					return ""
+				}
+			}
+			if i < 0 || i >= len(t) {
+				return ""
+			}
+			current = t[i]
+		default:
+			return ""
+		}
+	}
+
+	obj, ok := current.(map[string]any)
+	if !ok {
+		return ""
+	}
+	if tStr, ok := obj["type"].(string); ok {
+		return tStr
+	}
+	var candidates []string
+	for k := range obj {
+		if k != "label" && k != "processors" {
+			candidates = append(candidates, k)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Strings(candidates)
+	return candidates[0]
+}
+
+func uiLabelIndex(elements []any, label string) int {
+	for i, e := range elements {
+		if obj, ok := e.(map[string]any); ok && obj["label"] == label {
+			return i
+		}
+	}
+	return -1
+}
+
+//------------------------------------------------------------------------------
+
+type uiLogger struct {
+	next   log.Modular
+	rec    *UIRecorder
+	fields map[string]string
+}
+
+func (l *uiLogger) withFields(fields map[string]string, next log.Modular) log.Modular {
+	newFields := make(map[string]string, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		newFields[k] = v
+	}
+	for k, v := range fields {
+		newFields[k] = v
+	}
+	return &uiLogger{next: next, rec: l.rec, fields: newFields}
+}
+
+func (l *uiLogger) WithFields(fields map[string]string) log.Modular {
+	return l.withFields(fields, l.next.WithFields(fields))
+}
+
+func (l *uiLogger) With(keyValues ...any) log.Modular {
+	fields := map[string]string{}
+	for i := 0; i < len(keyValues)-1; i += 2 {
+		fields[fmt.Sprint(keyValues[i])] = fmt.Sprint(keyValues[i+1])
+	}
+	return l.withFields(fields, l.next.With(keyValues...))
+}
+
+func (l *uiLogger) Fatalf(format string, v ...any) {
+	l.rec.recordError("FATAL", fmt.Sprintf(format, v...), l.fields)
+	l.next.Fatalf(format, v...)
+}
+
+func (l *uiLogger) Errorf(format string, v ...any) {
+	l.rec.recordError("ERROR", fmt.Sprintf(format, v...), l.fields)
+	l.next.Errorf(format, v...)
+}
+
+func (l *uiLogger) Warnf(format string, v ...any) {
+	l.rec.recordError("WARN", fmt.Sprintf(format, v...), l.fields)
+	l.next.Warnf(format, v...)
+}
+
+func (l *uiLogger) Infof(format string, v ...any) {
+	l.next.Infof(format, v...)
+}
+
+func (l *uiLogger) Debugf(format string, v ...any) {
+	l.next.Debugf(format, v...)
+}
+
+func (l *uiLogger) Tracef(format string, v ...any) {
+	l.next.Tracef(format, v...)
+}
+
+func (l *uiLogger) Fatalln(message string) {
+	l.rec.recordError("FATAL", message, l.fields)
+	l.next.Fatalln(message)
+}
+
+func (l *uiLogger) Errorln(message string) {
+	l.rec.recordError("ERROR", message, l.fields)
+	l.next.Errorln(message)
+}
+
+func (l *uiLogger) Warnln(message string) {
+	l.rec.recordError("WARN", message, l.fields)
+	l.next.Warnln(message)
+}
+
+func (l *uiLogger) Infoln(message string) {
+	l.next.Infoln(message)
+}
+
+func (l *uiLogger) Debugln(message string) {
+	l.next.Debugln(message)
+}
+
+func (l *uiLogger) Traceln(message string) {
+	l.next.Traceln(message)
+}
+
+//------------------------------------------------------------------------------
+
+func writeUIJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	}
+}
+
+func (t *Type) registerUI(version string) {
+	indexTemplate := template.Must(template.New("ui").Parse(uiPage))
+
+	t.RegisterEndpoint("/ui", "Serves the admin UI.", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := indexTemplate.Execute(w, struct {
+			Version string
+		}{
+			Version: version,
+		}); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		}
+	})
+	t.RegisterEndpoint("/ui/topology", "Returns the components of the pipeline and their metrics for the admin UI.", func(w http.ResponseWriter, r *http.Request) {
+		writeUIJSON(w, t.ui.Topology())
+	})
+	t.RegisterEndpoint("/ui/errors", "Returns recently logged errors and warnings for the admin UI.", func(w http.ResponseWriter, r *http.Request) {
+		writeUIJSON(w, t.ui.Errors())
+	})
+	t.RegisterEndpoint("/ui/samples", "Returns recently sampled input messages for the admin UI.", func(w http.ResponseWriter, r *http.Request) {
+		writeUIJSON(w, t.ui.Samples())
+	})
+	t.RegisterEndpoint("/ui/execute", "Executes a Bloblang mapping against a message for the admin UI.", bloblangExecuteHandler(t.executeUIBloblang))
+}
+
+// executeUIBloblang executes a mapping for the admin UI. Unlike the
+// /bloblang/execute endpoint the UI is served without debug endpoints being
+// enabled, and therefore mappings are unable to use functions and methods that
+// access the host, such as env and file, or to import files.
+func (t *Type) executeUIBloblang(req BloblangExecuteRequest) BloblangExecuteResponse {
+	return executeBloblang(t.bloblEnv.OnlyPure().WithDisabledImports(), req)
+}
@@ -0,0 +1,212 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/api"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func newUITestAPI(t testing.TB, confStr string) *api.Type {
+	t.Helper()
+
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(confStr), &node))
+
+	conf := api.NewConfig()
+	conf.UI.Enabled = true
+
+	s, err := api.New("1.2.3", "", conf, node, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	require.NotNil(t, s.UI())
+	return s
+}
+
+func getUIJSON(t testing.TB, s *api.Type, path string, v any) {
+	t.Helper()
+
+	request, _ := http.NewRequest("GET", path, http.NoBody)
+	response := httptest.NewRecorder()
+	s.Handler().ServeHTTP(response, request)
+
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), v))
+}
+
+func TestUIDisabled(t *testing.T) {
+	s, err := api.New("", "", api.NewConfig(), nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	assert.Nil(t, s.UI())
+
+	request, _ := http.NewRequest("GET", "/ui", http.NoBody)
+	response := httptest.NewRecorder()
+	s.Handler().ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestUIPage(t *testing.T) {
+	s := newUITestAPI(t, `{}`)
+
+	request, _ := http.NewRequest("GET", "/benthos/ui", http.NoBody)
+	response := httptest.NewRecorder()
+	s.Handler().ServeHTTP(response, request)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "1.2.3")
+	assert.Contains(t, response.Body.String(), "Bloblang Playground")
+}
+
+func TestUITopology(t *testing.T) {
+	s := newUITestAPI(t, `
+input:
+  label: foo
+  generate:
+    mapping: 'root = "hello"'
+pipeline:
+  processors:
+    - mapping: 'root = content().uppercase()'
+output:
+  broker:
+    outputs:
+      - drop: {}
+      - label: bar
+        stdout: {}
+`)
+
+	stats := metrics.NewNamespaced(s.UI().Metrics())
+	stats.WithLabels("path", "root.output.broker.outputs.0").GetCounter("output_sent").Incr(3)
+	stats.WithLabels("path", "root.output.broker.outputs.bar", "label", "bar").GetCounter("output_sent").Incr(4)
+	stats.WithLabels("path", "root.input", "label", "foo").GetCounter("input_received").Incr(5)
+	stats.WithLabels("path", "root.pipeline.processors.0").GetCounter("processor_sent").Incr(5)
+	stats.WithLabels("path", "root.pipeline.processors.0").GetCounter("processor_error").Incr(2)
+	stats.GetCounter("some_other_metric").Incr(1)
+
+	var components []api.UIComponent
+	getUIJSON(t, s, "/ui/topology", &components)
+
+	assert.Equal(t, []api.UIComponent{
+		{Path: "root.input", Kind: "input", Type: "generate", Label: "foo", Metrics: map[string]int64{"received": 5}},
+		{Path: "root.pipeline.processors.0", Kind: "processor", Type: "mapping", Metrics: map[string]int64{"sent": 5, "error": 2}},
+		{Path: "root.output.broker.outputs.0", Kind: "output", Type: "drop", Metrics: map[string]int64{"sent": 3}},
+		{Path: "root.output.broker.outputs.bar", Kind: "output", Type: "stdout", Label: "bar", Metrics: map[string]int64{"sent": 4}},
+	}, components)
+}
+
+func TestUIErrors(t *testing.T) {
+	s := newUITestAPI(t, `{}`)
+
+	logger := s.UI().WrapLogger(log.Noop())
+	logger.Infof("not recorded")
+	logger.With("path", "root.output").Errorf("first: %v", "boom")
+	logger.WithFields(map[string]string{"label": "foo"}).Warnln("second")
+
+	var errs []api.UIError
+	getUIJSON(t, s, "/ui/errors", &errs)
+
+	require.Len(t, errs, 2)
+	assert.Equal(t, "WARN", errs[0].Level)
+	assert.Equal(t, "second", errs[0].Message)
+	assert.Equal(t, map[string]string{"label": "foo"}, errs[0].Fields)
+	assert.Equal(t, "ERROR", errs[1].Level)
+	assert.Equal(t, "first: boom", errs[1].Message)
+	assert.Equal(t, map[string]string{"path": "root.output"}, errs[1].Fields)
+}
+
+func TestUISamples(t *testing.T) {
+	s := newUITestAPI(t, `{}`)
+
+	part := message.NewPart([]byte("hello"))
+	part.MetaSetMut("foo", "bar")
+	s.UI().SampleBatch(message.Batch{part, message.NewPart([]byte("world"))})
+
+	// Batches within the sample interval are ignored.
+	s.UI().SampleBatch(message.QuickBatch([][]byte{[]byte("ignored")}))
+
+	var samples []api.UISample
+	getUIJSON(t, s, "/ui/samples", &samples)
+
+	require.Len(t, samples, 2)
+	assert.Equal(t, "world", samples[0].Content)
+	assert.Equal(t, "hello", samples[1].Content)
+	assert.Equal(t, map[string]string{"foo": "bar"}, samples[1].Metadata)
+}
+
+func TestUIExecute(t *testing.T) {
+	s := newUITestAPI(t, `{}`)
+
+	for _, test := range []struct {
+		name     string
+		body     string
+		output   map[string]any
+		errField string
+		errStr   string
+	}{
+		{
+			name: "mapping with metadata",
+			body: `{"mapping":"root.name = this.name.uppercase()\nmeta baz = @foo","input":"{\"name\":\"bob\"}","metadata":{"foo":"bar"}}`,
+			output: map[string]any{
+				"result":   `{"name":"BOB"}`,
+				"metadata": map[string]any{"foo": "bar", "baz": "bar"},
+			},
+		},
+		{
+			name:     "parse error",
+			body:     `{"mapping":"root = ","input":"{}"}`,
+			errField: "parse_error",
+			errStr:   "failed to parse mapping",
+		},
+		{
+			name:     "mapping error",
+			body:     `{"mapping":"root = this.name.uppercase()","input":"{}"}`,
+			errField: "mapping_error",
+			errStr:   "expected string value",
+		},
+		{
+			name:     "impure function",
+			body:     `{"mapping":"root = count(\"foo\")","input":"{}"}`,
+			errField: "parse_error",
+			errStr:   "unrecognised function 'count'",
+		},
+		{
+			name:     "import",
+			body:     `{"mapping":"import \"./foo.blobl\"\nroot = this","input":"{}"}`,
+			errField: "parse_error",
+			errStr:   "imports are disabled",
+		},
+		{
+			name: "deleted",
+			body: `{"mapping":"root = deleted()","input":"{}"}`,
+			output: map[string]any{
+				"deleted":  true,
+				"result":   "",
+				"metadata": nil,
+			},
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			request, _ := http.NewRequest("POST", "/ui/execute", bytes.NewReader([]byte(test.body)))
+			response := httptest.NewRecorder()
+			s.Handler().ServeHTTP(response, request)
+			require.Equal(t, http.StatusOK, response.Code)
+
+			var res map[string]any
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &res))
+			if test.errField != "" {
+				assert.Contains(t, res[test.errField], test.errStr)
+			} else {
+				assert.Equal(t, test.output, res)
+			}
+		})
+	}
+}
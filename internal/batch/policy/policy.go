@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/batch/policy/batchconfig"
@@ -61,7 +60,7 @@ func New(conf batchconfig.Config, mgr bundle.NewManagement) (*Batcher, error) {
 	}
 	var procs []iprocessor.V1
 	for i, pconf := range conf.Processors {
-		pMgr := mgr.IntoPath("processors", strconv.Itoa(i))
+		pMgr := mgr.IntoPath("processors", bundle.PathSegment(i, pconf.Label))
 		proc, err := pMgr.NewProcessor(pconf)
 		if err != nil {
 			return nil, err
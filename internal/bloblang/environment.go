@@ -12,6 +12,7 @@ import (
 type Environment struct {
 	pCtx            parser.Context
 	maxMapRecursion int
+	fields          *fieldCache
 }
 
 // GlobalEnvironment returns the global default environment. Modifying this
@@ -20,7 +21,8 @@ type Environment struct {
 // changes.
 func GlobalEnvironment() *Environment {
 	return &Environment{
-		pCtx: parser.GlobalContext(),
+		pCtx:   parser.GlobalContext(),
+		fields: globalFieldCache,
 	}
 }
 
@@ -43,20 +45,44 @@ func NewEnvironment() *Environment {
 // empty, where no functions or methods are initially available.
 func NewEmptyEnvironment() *Environment {
 	return &Environment{
-		pCtx: parser.EmptyContext(),
+		pCtx:   parser.EmptyContext(),
+		fields: newFieldCache(),
 	}
 }
 
 // NewField attempts to parse and create a dynamic field expression from a
 // string. If the expression is invalid an error is returned.
 //
+// Parsed expressions are cached by the environment, and expressions that only
+// use functions that do not hold state are shared between calls.
+//
 // When a parsing error occurs the returned error will be a *parser.Error type,
 // which allows you to gain positional and structured error messages.
 func (e *Environment) NewField(expr string) (*field.Expression, error) {
-	f, err := parser.ParseField(e.pCtx, expr)
+	if e.fields == nil {
+		f, err := parser.ParseField(e.pCtx, expr)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+
+	if f, exists := e.fields.get(expr); exists {
+		return f, nil
+	}
+
+	shareable := true
+	f, err := parser.ParseField(e.pCtx.OnInitFunction(func(name string) {
+		if _, exists := shareableFieldFunctions[name]; !exists {
+			shareable = false
+		}
+	}), expr)
 	if err != nil {
 		return nil, err
 	}
+	if shareable {
+		e.fields.set(expr, f)
+	}
 	return f, nil
 }
 
@@ -89,6 +115,7 @@ func (e *Environment) NewMapping(blobl string) (*mapping.Executor, error) {
 func (e *Environment) Deactivated() *Environment {
 	env := *e
 	env.pCtx = env.pCtx.Deactivated()
+	env.fields = nil
 	return &env
 }
 
@@ -101,16 +128,23 @@ func (e *Environment) OnlyPure() *Environment {
 	env := *e
 	env.pCtx.Functions = env.pCtx.Functions.OnlyPure()
 	env.pCtx.Methods = env.pCtx.Methods.OnlyPure()
+	env.fields = newFieldCache()
 	return &env
 }
 
 // RegisterMethod adds a new Bloblang method to the environment.
 func (e *Environment) RegisterMethod(spec query.MethodSpec, ctor query.MethodCtor) error {
+	if e.fields != nil {
+		e.fields.reset()
+	}
 	return e.pCtx.Methods.Add(spec, ctor)
 }
 
 // RegisterFunction adds a new Bloblang function to the environment.
 func (e *Environment) RegisterFunction(spec query.FunctionSpec, ctor query.FunctionCtor) error {
+	if e.fields != nil {
+		e.fields.reset()
+	}
 	return e.pCtx.Functions.Add(spec, ctor)
 }
 
@@ -156,6 +190,7 @@ func (e *Environment) WithCustomImporter(fn func(name string) ([]byte, error)) *
 func (e *Environment) WithoutMethods(names ...string) *Environment {
 	env := *e
 	env.pCtx.Methods = env.pCtx.Methods.Without(names...)
+	env.fields = newFieldCache()
 	return &env
 }
 
@@ -165,6 +200,7 @@ func (e *Environment) WithoutMethods(names ...string) *Environment {
 func (e *Environment) WithoutFunctions(names ...string) *Environment {
 	env := *e
 	env.pCtx.Functions = env.pCtx.Functions.Without(names...)
+	env.fields = newFieldCache()
 	return &env
 }
 
@@ -178,6 +214,16 @@ func (e *Environment) WithMaxMapRecursion(n int) *Environment {
 	return &env
 }
 
+// WithMaps returns a copy of the environment where the map definitions of a
+// mapping are available to field expressions and mappings parsed from the
+// environment, where they can be executed with the method `apply`.
+func (e *Environment) WithMaps(exec *mapping.Executor) *Environment {
+	env := *e
+	env.pCtx = env.pCtx.WithMaps(exec.Maps())
+	env.fields = newFieldCache()
+	return &env
+}
+
 // WalkFunctions executes a provided function argument for every function that
 // has been registered to the environment.
 func (e *Environment) WalkFunctions(fn func(name string, spec query.FunctionSpec)) {
@@ -35,13 +35,21 @@ func (s StaticResolver) ResolveBytes(index int, msg Message, escaped bool) ([]by
 // QueryResolver executes a query and returns a string representation of the
 // result.
 type QueryResolver struct {
-	fn query.Function
+	fn   query.Function
+	maps map[string]query.Function
 }
 
 // NewQueryResolver creates a field query resolver that returns the result of a
 // query function.
 func NewQueryResolver(fn query.Function) *QueryResolver {
-	return &QueryResolver{fn}
+	return &QueryResolver{fn: fn}
+}
+
+// WithMaps returns a resolver where map definitions are available to the query
+// function with the method `apply`.
+func (q *QueryResolver) WithMaps(maps map[string]query.Function) *QueryResolver {
+	q.maps = maps
+	return q
 }
 
 // ResolveString returns a string.
@@ -50,6 +58,7 @@ func (q QueryResolver) ResolveString(index int, msg Message, escaped bool) (stri
 		msg = message.QuickBatch(nil)
 	}
 	return query.ExecToString(q.fn, query.FunctionContext{
+		Maps:     q.maps,
 		Index:    index,
 		MsgBatch: msg,
 		NewMeta:  msg.Get(index),
@@ -67,6 +76,7 @@ func (q QueryResolver) ResolveBytes(index int, msg Message, escaped bool) ([]byt
 		msg = message.QuickBatch(nil)
 	}
 	bs, err := query.ExecToBytes(q.fn, query.FunctionContext{
+		Maps:     q.maps,
 		Index:    index,
 		MsgBatch: msg,
 		NewMeta:  msg.Get(index),
@@ -0,0 +1,83 @@
+package bloblang
+
+import (
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
+)
+
+// maxCachedFields is the maximum number of field expressions cached by an
+// environment, once reached the cache is emptied.
+const maxCachedFields = 4096
+
+// shareableFieldFunctions are functions that do not hold state between
+// executions, and therefore field expressions that only use these functions
+// can be shared between components.
+var shareableFieldFunctions = map[string]struct{}{
+	"batch_index":          {},
+	"batch_map":            {},
+	"batch_reduce":         {},
+	"batch_size":           {},
+	"batch_sort_by":        {},
+	"content":              {},
+	"deleted":              {},
+	"env":                  {},
+	"error":                {},
+	"errored":              {},
+	"hostname":             {},
+	"json":                 {},
+	"ksuid":                {},
+	"meta":                 {},
+	"metadata":             {},
+	"nanoid":               {},
+	"nothing":              {},
+	"now":                  {},
+	"range":                {},
+	"throw":                {},
+	"timestamp_unix":       {},
+	"timestamp_unix_micro": {},
+	"timestamp_unix_milli": {},
+	"timestamp_unix_nano":  {},
+	"tracing_baggage":      {},
+	"tracing_id":           {},
+	"tracing_span":         {},
+	"uuid_v4":              {},
+	"var":                  {},
+}
+
+// fieldCache stores parsed field expressions by their raw string so that
+// identical expressions, which are common across components such as the topic
+// and key fields of outputs, are only parsed once.
+type fieldCache struct {
+	mut    sync.RWMutex
+	fields map[string]*field.Expression
+}
+
+func newFieldCache() *fieldCache {
+	return &fieldCache{fields: map[string]*field.Expression{}}
+}
+
+// globalFieldCache is shared by all instances of the global environment.
+var globalFieldCache = newFieldCache()
+
+func (c *fieldCache) get(expr string) (*field.Expression, bool) {
+	c.mut.RLock()
+	e, exists := c.fields[expr]
+	c.mut.RUnlock()
+	return e, exists
+}
+
+func (c *fieldCache) set(expr string, e *field.Expression) {
+	c.mut.Lock()
+	if len(c.fields) >= maxCachedFields {
+		c.fields = map[string]*field.Expression{}
+	}
+	c.fields[expr] = e
+	c.mut.Unlock()
+}
+
+func (c *fieldCache) reset() {
+	c.mut.Lock()
+	c.fields = map[string]*field.Expression{}
+	c.mut.Unlock()
+}
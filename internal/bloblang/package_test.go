@@ -269,3 +269,74 @@ func TestMappingParallelExecution(t *testing.T) {
 		})
 	}
 }
+
+func TestFieldCaching(t *testing.T) {
+	env := NewEnvironment()
+
+	a, err := env.NewField(`${! meta("foo") } and ${! json("bar").uppercase() }`)
+	require.NoError(t, err)
+
+	b, err := env.NewField(`${! meta("foo") } and ${! json("bar").uppercase() }`)
+	require.NoError(t, err)
+	assert.Same(t, a, b)
+
+	for _, expr := range []string{
+		`${! count("foo") }`,
+		`${! random_int() }`,
+		`${! json("foo").or(count("foo")) }`,
+	} {
+		a, err := env.NewField(expr)
+		require.NoError(t, err, expr)
+
+		b, err := env.NewField(expr)
+		require.NoError(t, err, expr)
+		assert.NotSame(t, a, b, expr)
+	}
+
+	_, err = env.NewField(`${! nope() }`)
+	require.Error(t, err)
+
+	_, err = NewEmptyEnvironment().NewField(`${! meta("foo") }`)
+	require.Error(t, err)
+}
+
+func TestEnvironmentWithMaps(t *testing.T) {
+	exec, err := GlobalEnvironment().NewMapping(`
+map greet {
+  root = "hello " + this.name
+}
+map shout {
+  root = this.uppercase()
+}
+`)
+	require.NoError(t, err)
+
+	env := GlobalEnvironment().WithMaps(exec)
+
+	f, err := env.NewField(`${! json().apply("greet").apply("shout") }`)
+	require.NoError(t, err)
+
+	msg := message.QuickBatch([][]byte{[]byte(`{"name":"bob"}`)})
+	res, err := f.String(0, msg)
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO BOB", res)
+
+	m, err := env.NewMapping(`
+map shout {
+  root = this.lowercase()
+}
+root = this.apply("greet").apply("shout")
+`)
+	require.NoError(t, err)
+
+	p, err := m.MapPart(0, msg)
+	require.NoError(t, err)
+	assert.Equal(t, `hello bob`, string(p.AsBytes()))
+
+	f, err = GlobalEnvironment().NewField(`${! json().apply("greet") }`)
+	require.NoError(t, err)
+
+	_, err = f.String(0, msg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no maps were found")
+}
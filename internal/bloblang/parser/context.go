@@ -16,6 +16,8 @@ type Context struct {
 	Methods      *query.MethodSet
 	namedContext *namedContext
 	importer     Importer
+	maps         map[string]query.Function
+	onInitFunc   func(name string)
 }
 
 // EmptyContext returns a parser context with no functions, methods or import
@@ -65,9 +67,32 @@ func (pCtx Context) HasNamedContext(name string) bool {
 // InitFunction attempts to initialise a function from the available
 // constructors of the parser context.
 func (pCtx Context) InitFunction(name string, args *query.ParsedParams) (query.Function, error) {
+	if pCtx.onInitFunc != nil {
+		pCtx.onInitFunc(name)
+	}
 	return pCtx.Functions.Init(name, args)
 }
 
+// OnInitFunction returns a Context where the provided closure is called with
+// the name of each function that is initialised while parsing.
+func (pCtx Context) OnInitFunction(fn func(name string)) Context {
+	pCtx.onInitFunc = fn
+	return pCtx
+}
+
+// WithMaps returns a Context where a set of map definitions are available to
+// be executed with the method `apply` from within parsed field expressions and
+// mappings. Maps declared within mappings take precedence over these.
+func (pCtx Context) WithMaps(maps map[string]query.Function) Context {
+	pCtx.maps = maps
+	return pCtx
+}
+
+// Maps returns the map definitions available to the parser context.
+func (pCtx Context) Maps() map[string]query.Function {
+	return pCtx.maps
+}
+
 // InitMethod attempts to initialise a method from the available constructors of
 // the parser context.
 func (pCtx Context) InitMethod(name string, target query.Function, args *query.ParsedParams) (query.Function, error) {
@@ -28,7 +28,7 @@ func aFunction(pCtx Context) Func {
 		if res.Err != nil {
 			return res
 		}
-		res.Payload = field.NewQueryResolver(res.Payload.([]any)[2].(query.Function))
+		res.Payload = field.NewQueryResolver(res.Payload.([]any)[2].(query.Function)).WithMaps(pCtx.maps)
 		return res
 	}
 }
@@ -48,7 +48,10 @@ func parseExecutor(pCtx Context) Func {
 	allWhitespace := DiscardAll(OneOf(whitespace, newline))
 
 	return func(input []rune) Result {
-		maps := map[string]query.Function{}
+		maps := make(map[string]query.Function, len(pCtx.maps))
+		for k, v := range pCtx.maps {
+			maps[k] = v
+		}
 		statements := []mapping.Statement{}
 
 		statement := OneOf(
@@ -167,7 +170,11 @@ func singleRootMapping(pCtx Context) Func {
 		}
 
 		stmt := mapping.NewStatement(input, mapping.NewJSONAssignment(), fn)
-		return Success(mapping.NewExecutor("", input, map[string]query.Function{}, stmt), nil)
+		maps := make(map[string]query.Function, len(pCtx.maps))
+		for k, v := range pCtx.maps {
+			maps[k] = v
+		}
+		return Success(mapping.NewExecutor("", input, maps, stmt), nil)
 	}
 }
 
@@ -186,6 +193,18 @@ func varNameParser() Func {
 	)
 }
 
+// mapDeclared returns whether a map of a given name has already been declared
+// or imported by the mapping being parsed, where maps inherited from the parser
+// context are allowed to be overridden.
+func mapDeclared(maps map[string]query.Function, pCtx Context, name string) bool {
+	fn, exists := maps[name]
+	if !exists {
+		return false
+	}
+	inherited, isInherited := pCtx.maps[name]
+	return !isInherited || inherited != fn
+}
+
 func importParser(maps map[string]query.Function, pCtx Context) Func {
 	p := Sequence(
 		Term("import"),
@@ -226,7 +245,7 @@ func importParser(maps map[string]query.Function, pCtx Context) Func {
 
 		collisions := []string{}
 		for k, v := range exec.Maps() {
-			if _, exists := maps[k]; exists {
+			if mapDeclared(maps, pCtx, k) {
 				collisions = append(collisions, k)
 			} else {
 				maps[k] = v
@@ -293,7 +312,7 @@ func mapParser(maps map[string]query.Function, pCtx Context) Func {
 		ident := seqSlice[2].(string)
 		stmtSlice := seqSlice[4].([]any)
 
-		if _, exists := maps[ident]; exists {
+		if mapDeclared(maps, pCtx, ident) {
 			return Fail(NewFatalError(input, fmt.Errorf("map name collision: %v", ident)), input)
 		}
 
@@ -172,6 +172,7 @@ var (
 	MethodCategoryObjectAndArray = "Object & Array Manipulation"
 	MethodCategoryJWT            = "JSON Web Tokens"
 	MethodCategoryGeoIP          = "GeoIP"
+	MethodCategoryIP             = "IP Addresses"
 	MethodCategoryDeprecated     = "Deprecated"
 	MethodCategoryPlugin         = "Plugin"
 )
@@ -264,6 +264,38 @@ var _ = registerSimpleFunction(
 
 //------------------------------------------------------------------------------
 
+var _ = registerFunction(
+	NewFunctionSpec(
+		FunctionCategoryMessage, "tracing_baggage",
+		"Provides the [baggage](https://www.w3.org/TR/baggage/) of the message tracing context as an object of keys to string values, or the value of a single member when a key is provided, which is `null` if the member does not exist. Baggage is propagated along with tracing spans, and can be set with the [`baggage` processor](/docs/components/processors/baggage).",
+		NewExampleSpec("",
+			`root.tenant = tracing_baggage("tenant_id")`,
+		),
+		NewExampleSpec("",
+			`root.baggage = tracing_baggage()`,
+		),
+	).Experimental().AtVersion("4.24.0").
+		Param(ParamString("key", "An optional key of a baggage member to return the value of.").Default("")),
+	func(args *ParsedParams) (Function, error) {
+		key, err := args.FieldString("key")
+		if err != nil {
+			return nil, err
+		}
+		return ClosureFunction("function tracing_baggage", func(ctx FunctionContext) (any, error) {
+			part := ctx.MsgBatch.Get(ctx.Index)
+			if key == "" {
+				return tracing.GetBaggage(part), nil
+			}
+			if v, exists := tracing.GetBaggageMember(part, key); exists {
+				return v, nil
+			}
+			return nil, nil
+		}, nil), nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerFunction(
 	NewDeprecatedFunctionSpec(
 		"count",
@@ -445,15 +477,11 @@ func jsonFunction(args *ParsedParams) (Function, error) {
 		argPath = gabs.DotPathToSlice(path)
 	}
 	return ClosureFunction("json path `"+SliceToDotPath(argPath...)+"`", func(ctx FunctionContext) (any, error) {
-		jPart, err := ctx.MsgBatch.Get(ctx.Index).AsStructured()
+		jPart, err := ctx.MsgBatch.Get(ctx.Index).AsStructuredPath(argPath...)
 		if err != nil {
 			return nil, err
 		}
-		gPart := gabs.Wrap(jPart)
-		if len(argPath) > 0 {
-			gPart = gPart.Search(argPath...)
-		}
-		return ISanitize(gPart.Data()), nil
+		return ISanitize(jPart), nil
 	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
 		paths := []TargetPath{
 			NewTargetPath(TargetValue, argPath...),
@@ -0,0 +1,180 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// batchMessageContext returns a function context that targets a message of the
+// batch by index, where the context value is the structured contents of the
+// message, which is only parsed when referenced.
+func batchMessageContext(ctx FunctionContext, i int) FunctionContext {
+	ctx.Index = i
+	return ctx.WithValueFunc(func() *any {
+		v, err := ctx.MsgBatch.Get(i).AsStructured()
+		if err != nil {
+			return nil
+		}
+		return &v
+	})
+}
+
+//------------------------------------------------------------------------------
+
+var _ = registerFunction(
+	NewFunctionSpec(
+		FunctionCategoryMessage, "batch_map",
+		"Executes a query for each message of the batch and returns an array of the results. The context of the query is the structured contents of each message, and functions such as `content()`, `meta()` and `batch_index()` refer to the message being queried. Results that are `deleted()` are removed from the array.",
+		NewExampleSpec("",
+			`root.ids = if batch_index() == 0 { batch_map(this.id) } else { deleted() }`,
+		),
+		NewExampleSpec("Functions that access message contents or metadata refer to each message of the batch.",
+			`root.topics = batch_map(meta("kafka_topic")).unique()`,
+		),
+	).Beta().Param(ParamQuery("query", "A query to execute for each message of the batch.", false)),
+	func(args *ParsedParams) (Function, error) {
+		mapFn, err := args.FieldQuery("query")
+		if err != nil {
+			return nil, err
+		}
+		return ClosureFunction("function batch_map", func(ctx FunctionContext) (any, error) {
+			results := make([]any, 0, ctx.MsgBatch.Len())
+			for i := 0; i < ctx.MsgBatch.Len(); i++ {
+				res, err := mapFn.Exec(batchMessageContext(ctx, i))
+				if err != nil {
+					return nil, fmt.Errorf("batch message %v: %w", i, err)
+				}
+				switch res.(type) {
+				case Delete:
+				case Nothing:
+					results = append(results, nil)
+				default:
+					results = append(results, res)
+				}
+			}
+			return results, nil
+		}, nil), nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerFunction(
+	NewFunctionSpec(
+		FunctionCategoryMessage, "batch_reduce",
+		"Reduces the messages of the batch into a single value by executing a query for each message. The context of the query is an object with two fields; `tally` containing the current accumulated value, and `value` containing the structured contents of the current message. The query must return the new tally, which is passed to the query of the next message. Functions such as `content()` and `meta()` refer to the current message.",
+		NewExampleSpec("",
+			`root.total = batch_reduce(0, item -> item.tally + item.value.price)`,
+		),
+		NewExampleSpec("Messages of a batch can be merged into a single document.",
+			`root = if batch_index() == 0 { batch_reduce({}, item -> item.tally.merge(item.value)) } else { deleted() }`,
+		),
+	).Beta().
+		Param(ParamAny("initial", "The initial value of the tally.")).
+		Param(ParamQuery("query", "A query to execute for each message of the batch, which is provided an object with the fields `tally` and `value`, and should result in the new tally.", false)),
+	func(args *ParsedParams) (Function, error) {
+		initial, err := args.Field("initial")
+		if err != nil {
+			return nil, err
+		}
+		reduceFn, err := args.FieldQuery("query")
+		if err != nil {
+			return nil, err
+		}
+		return ClosureFunction("function batch_reduce", func(ctx FunctionContext) (any, error) {
+			tally := IClone(initial)
+			for i := 0; i < ctx.MsgBatch.Len(); i++ {
+				v, err := ctx.MsgBatch.Get(i).AsStructured()
+				if err != nil {
+					return nil, fmt.Errorf("batch message %v: %w", i, err)
+				}
+				msgCtx := ctx
+				msgCtx.Index = i
+				if tally, err = reduceFn.Exec(msgCtx.WithValue(map[string]any{
+					"tally": tally,
+					"value": v,
+				})); err != nil {
+					return nil, fmt.Errorf("batch message %v: %w", i, err)
+				}
+			}
+			return tally, nil
+		}, nil), nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerFunction(
+	NewFunctionSpec(
+		FunctionCategoryMessage, "batch_sort_by",
+		"Returns an array of the structured contents of all messages of the batch, sorted in increasing order by a value emitted by a query executed for each message. The type of all values must match in order for the ordering to succeed. Supports string and number values.",
+		NewExampleSpec("",
+			`root = if batch_index() == 0 { batch_sort_by(this.timestamp) } else { deleted() }`,
+		),
+		NewExampleSpec("The query can reference metadata of each message.",
+			`root.offsets = batch_sort_by(meta("kafka_offset").number()).map_each(doc -> doc.id)`,
+		),
+	).Beta().Param(ParamQuery("query", "A query to execute for each message of the batch that yields a value used for sorting.", false)),
+	func(args *ParsedParams) (Function, error) {
+		sortFn, err := args.FieldQuery("query")
+		if err != nil {
+			return nil, err
+		}
+		return ClosureFunction("function batch_sort_by", func(ctx FunctionContext) (any, error) {
+			type sortable struct {
+				doc any
+				key any
+			}
+
+			items := make([]sortable, ctx.MsgBatch.Len())
+			for i := range items {
+				doc, err := ctx.MsgBatch.Get(i).AsStructured()
+				if err != nil {
+					return nil, fmt.Errorf("batch message %v: %w", i, err)
+				}
+				key, err := sortFn.Exec(batchMessageContext(ctx, i))
+				if err != nil {
+					return nil, fmt.Errorf("batch message %v: %w", i, err)
+				}
+				switch key.(type) {
+				case float64, int, int64, uint64, json.Number, string, []byte:
+				default:
+					return nil, fmt.Errorf("batch message %v: %w", i, ErrFrom(NewTypeError(key, ValueNumber, ValueString), sortFn))
+				}
+				items[i] = sortable{doc: IClone(doc), key: key}
+			}
+
+			var sortErr error
+			sort.SliceStable(items, func(i, j int) bool {
+				if sortErr != nil {
+					return false
+				}
+				switch items[i].key.(type) {
+				case string, []byte:
+					lhs, _ := IGetString(items[i].key)
+					rhs, err := IGetString(items[j].key)
+					if err != nil {
+						sortErr = fmt.Errorf("batch_sort_by mismatched types: %w", ErrFrom(err, sortFn))
+					}
+					return lhs < rhs
+				}
+				lhs, _ := IGetNumber(items[i].key)
+				rhs, err := IGetNumber(items[j].key)
+				if err != nil {
+					sortErr = fmt.Errorf("batch_sort_by mismatched types: %w", ErrFrom(err, sortFn))
+				}
+				return lhs < rhs
+			})
+			if sortErr != nil {
+				return nil, sortErr
+			}
+
+			docs := make([]any, len(items))
+			for i, item := range items {
+				docs[i] = item.doc
+			}
+			return docs, nil
+		}, nil), nil
+	},
+)
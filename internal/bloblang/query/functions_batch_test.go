@@ -0,0 +1,89 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func TestBatchFunctions(t *testing.T) {
+	batch := message.QuickBatch([][]byte{
+		[]byte(`{"id":"c","ts":3,"price":5}`),
+		[]byte(`{"id":"a","ts":1,"price":10}`),
+		[]byte(`{"id":"b","ts":2,"price":2.5}`),
+	})
+	for i, topic := range []string{"foo", "bar", "foo"} {
+		batch.Get(i).MetaSetMut("topic", topic)
+	}
+
+	tests := []struct {
+		name    string
+		mapping string
+		index   int
+		output  string
+		err     string
+	}{
+		{
+			name:    "batch_map documents",
+			mapping: `root = batch_map(this.id)`,
+			index:   1,
+			output:  `["c","a","b"]`,
+		},
+		{
+			name:    "batch_map metadata and index",
+			mapping: `root = batch_map("%v:%v".format(batch_index(), meta("topic")))`,
+			output:  `["0:foo","1:bar","2:foo"]`,
+		},
+		{
+			name:    "batch_map deleted",
+			mapping: `root = batch_map(if this.ts > 1 { this.id } else { deleted() })`,
+			output:  `["c","b"]`,
+		},
+		{
+			name:    "batch_reduce sum",
+			mapping: `root = batch_reduce(0, item -> item.tally + item.value.price)`,
+			output:  `17.5`,
+		},
+		{
+			name:    "batch_reduce merge",
+			mapping: `root = batch_reduce({}, item -> item.tally.merge({meta("topic"): item.value.id}))`,
+			output:  `{"bar":"a","foo":["c","b"]}`,
+		},
+		{
+			name:    "batch_sort_by number",
+			mapping: `root = batch_sort_by(this.ts).map_each(doc -> doc.id)`,
+			output:  `["a","b","c"]`,
+		},
+		{
+			name:    "batch_sort_by metadata",
+			mapping: `root = batch_sort_by(meta("topic")).map_each(doc -> doc.id)`,
+			output:  `["a","c","b"]`,
+		},
+		{
+			name:    "batch_sort_by bad type",
+			mapping: `root = batch_sort_by(this.missing)`,
+			err:     "batch message 0: expected number or string value, got null from field `this.missing`",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			m, err := bloblang.GlobalEnvironment().NewMapping(test.mapping)
+			require.NoError(t, err)
+
+			p, err := m.MapPart(test.index, batch)
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.output, string(p.AsBytes()))
+		})
+	}
+}
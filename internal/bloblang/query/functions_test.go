@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/tracing"
 )
 
 func TestFunctions(t *testing.T) {
@@ -268,6 +269,37 @@ func TestNanoidFunctionAlphabet(t *testing.T) {
 	assert.Equal(t, "a", res)
 }
 
+func TestTracingBaggageFunction(t *testing.T) {
+	part := message.NewPart([]byte("hello"))
+	ctx, err := tracing.WithBaggageMembers(part.GetContext(), map[string]any{
+		"tenant": "foo",
+		"user":   "bar",
+	})
+	require.NoError(t, err)
+	msg := message.Batch{part.WithContext(ctx)}
+
+	e, err := InitFunctionHelper("tracing_baggage")
+	require.NoError(t, err)
+
+	res, err := e.Exec(FunctionContext{MsgBatch: msg})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"tenant": "foo", "user": "bar"}, res)
+
+	e, err = InitFunctionHelper("tracing_baggage", "tenant")
+	require.NoError(t, err)
+
+	res, err = e.Exec(FunctionContext{MsgBatch: msg})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", res)
+
+	e, err = InitFunctionHelper("tracing_baggage", "nope")
+	require.NoError(t, err)
+
+	res, err = e.Exec(FunctionContext{MsgBatch: msg})
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
+
 func TestKsuidFunction(t *testing.T) {
 	e, err := InitFunctionHelper("ksuid")
 	require.Nil(t, err)
@@ -20,6 +20,7 @@ import (
 	"hash/crc32"
 	"html"
 	"io"
+	"net"
 	"net/url"
 	"path/filepath"
 	"regexp"
@@ -32,6 +33,8 @@ import (
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/message"
 )
 
 var _ = registerSimpleMethod(
@@ -1206,6 +1209,11 @@ var _ = registerSimpleMethod(
 			`{"doc":{"foo":"bar"}}`,
 			`{"foo":"bar"}`,
 		),
+		NewExampleSpec("Set the `canonical` parameter to true in order to serialize the value as canonical JSON following [RFC 8785](https://www.rfc-editor.org/rfc/rfc8785), where object keys are sorted, numbers are formatted consistently and characters are only escaped where necessary. The result is stable across runs and is therefore suitable for hashing or signing.",
+			`root.hash = this.doc.format_json(canonical: true).hash("sha256").encode("hex")`,
+			`{"doc":{"b":1.0,"a":"<foo>"}}`,
+			`{"hash":"617e69ff0b65833695de5c7f06e2a8db8aa9d4681b5a16aa20bba13f8fa6d9ad"}`,
+		),
 	).
 		Beta().
 		Param(ParamString(
@@ -1215,6 +1223,10 @@ var _ = registerSimpleMethod(
 		Param(ParamBool(
 			"no_indent",
 			"Disable indentation.",
+		).Default(false)).
+		Param(ParamBool(
+			"canonical",
+			"Serialize the value as canonical JSON ([RFC 8785](https://www.rfc-editor.org/rfc/rfc8785)), in which case indentation is disabled.",
 		).Default(false)),
 	func(args *ParsedParams) (simpleMethod, error) {
 		indentOpt, err := args.FieldOptionalString("indent")
@@ -1229,7 +1241,14 @@ var _ = registerSimpleMethod(
 		if err != nil {
 			return nil, err
 		}
+		canonicalOpt, err := args.FieldOptionalBool("canonical")
+		if err != nil {
+			return nil, err
+		}
 		return func(v any, ctx FunctionContext) (any, error) {
+			if *canonicalOpt {
+				return message.MarshalCanonicalJSON(v)
+			}
 			if *noIndentOpt {
 				return json.Marshal(v)
 			}
@@ -1240,13 +1259,13 @@ var _ = registerSimpleMethod(
 
 var _ = registerSimpleMethod(
 	NewMethodSpec(
-		"parse_url", "Attempts to parse a URL from a string value, returning a structured result that describes the various facets of the URL. The fields returned within the structured result roughly follow https://pkg.go.dev/net/url#URL, and may be expanded in future in order to present more information.",
+		"parse_url", "Attempts to parse a URL from a string value, returning a structured result that describes the various facets of the URL. The fields returned within the structured result roughly follow https://pkg.go.dev/net/url#URL, and may be expanded in future in order to present more information. The host is also provided as the separate fields `hostname` and `port`, and the query string is parsed into the object field `query`, where keys with multiple values are arrays. The result can be modified and converted back into a URL with [`format_url`](#format_url).",
 	).InCategory(
 		MethodCategoryParsing, "",
 		NewExampleSpec("",
 			`root.foo_url = this.foo_url.parse_url()`,
 			`{"foo_url":"https://www.benthos.dev/docs/guides/bloblang/about"}`,
-			`{"foo_url":{"fragment":"","host":"www.benthos.dev","opaque":"","path":"/docs/guides/bloblang/about","raw_fragment":"","raw_path":"","raw_query":"","scheme":"https"}}`,
+			`{"foo_url":{"fragment":"","host":"www.benthos.dev","hostname":"www.benthos.dev","opaque":"","path":"/docs/guides/bloblang/about","port":"","query":{},"raw_fragment":"","raw_path":"","raw_query":"","scheme":"https"}}`,
 		),
 		NewExampleSpec("",
 			`root.username = this.url.parse_url().user.name | "unknown"`,
@@ -1255,6 +1274,12 @@ var _ = registerSimpleMethod(
 			`{"url":"redis://localhost:6379"}`,
 			`{"username":"unknown"}`,
 		),
+		NewExampleSpec("",
+			`root.page = this.url.parse_url().query.page.number()
+root.tags = this.url.parse_url().query.tag`,
+			`{"url":"https://example.com/search?page=2&tag=foo&tag=bar"}`,
+			`{"page":2,"tags":["foo","bar"]}`,
+		),
 	),
 	func(*ParsedParams) (simpleMethod, error) {
 		return stringMethod(func(data string) (any, error) {
@@ -1266,9 +1291,12 @@ var _ = registerSimpleMethod(
 				"scheme":       urlParsed.Scheme,
 				"opaque":       urlParsed.Opaque,
 				"host":         urlParsed.Host,
+				"hostname":     urlParsed.Hostname(),
+				"port":         urlParsed.Port(),
 				"path":         urlParsed.Path,
 				"raw_path":     urlParsed.RawPath,
 				"raw_query":    urlParsed.RawQuery,
+				"query":        urlValuesToObject(urlParsed.Query()),
 				"fragment":     urlParsed.Fragment,
 				"raw_fragment": urlParsed.RawFragment,
 			}
@@ -1286,6 +1314,167 @@ var _ = registerSimpleMethod(
 	},
 )
 
+// urlValuesToObject converts URL values into an object where keys with a
+// single value are strings and keys with multiple values are arrays.
+func urlValuesToObject(values url.Values) map[string]any {
+	obj := make(map[string]any, len(values))
+	for k, v := range values {
+		if len(v) == 1 {
+			obj[k] = v[0]
+			continue
+		}
+		arr := make([]any, len(v))
+		for i, e := range v {
+			arr[i] = e
+		}
+		obj[k] = arr
+	}
+	return obj
+}
+
+// objectToURLValues converts an object into URL values, where arrays are
+// expanded into multiple values of the same key and other values are
+// converted into strings.
+func objectToURLValues(obj map[string]any) url.Values {
+	values := make(url.Values, len(obj))
+	for k, v := range obj {
+		if arr, ok := v.([]any); ok {
+			for _, e := range arr {
+				values.Add(k, IToString(e))
+			}
+			continue
+		}
+		values.Set(k, IToString(v))
+	}
+	return values
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"format_url", "Formats an object into a URL, where the fields of the object follow the structure returned by [`parse_url`](#parse_url). This makes it possible to parse a URL, modify its components, and then format it back into a string.",
+	).InCategory(
+		MethodCategoryParsing,
+		"The `host` field is only used when the field `hostname` is absent or empty, otherwise the host is formed from the fields `hostname` and `port`. Similarly, the `raw_query` field is only used when the field `query` is absent, otherwise the query string is encoded from the object `query`, where arrays are expanded into multiple values of the same key and the keys are sorted.",
+		NewExampleSpec("",
+			`root.url = this.url.parse_url().assign({"scheme":"https","port":"8443","query":{"page":"3"}}).format_url()`,
+			`{"url":"http://example.com:8080/search?page=2"}`,
+			`{"url":"https://example.com:8443/search?page=3"}`,
+		),
+		NewExampleSpec("",
+			`root.url = {"scheme":"https","hostname":"api.example.com","path":"/v1/items","query":{"id":[1,2],"sort":"asc"}}.format_url()`,
+			`{}`,
+			`{"url":"https://api.example.com/v1/items?id=1&id=2&sort=asc"}`,
+		),
+	),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil, NewTypeError(v, ValueObject)
+			}
+
+			getStr := func(key string) (string, error) {
+				f, exists := obj[key]
+				if !exists || f == nil {
+					return "", nil
+				}
+				s, err := IGetString(f)
+				if err != nil {
+					return "", fmt.Errorf("field %v: %w", key, err)
+				}
+				return s, nil
+			}
+
+			var u url.URL
+			var err error
+			if u.Scheme, err = getStr("scheme"); err != nil {
+				return nil, err
+			}
+			if u.Opaque, err = getStr("opaque"); err != nil {
+				return nil, err
+			}
+			if u.Host, err = getStr("host"); err != nil {
+				return nil, err
+			}
+			hostname, err := getStr("hostname")
+			if err != nil {
+				return nil, err
+			}
+			if hostname != "" {
+				port, err := getStr("port")
+				if err != nil {
+					return nil, err
+				}
+				if port != "" {
+					u.Host = net.JoinHostPort(hostname, port)
+				} else if strings.Contains(hostname, ":") {
+					u.Host = "[" + hostname + "]"
+				} else {
+					u.Host = hostname
+				}
+			}
+			if u.Path, err = getStr("path"); err != nil {
+				return nil, err
+			}
+			if u.RawPath, err = getStr("raw_path"); err != nil {
+				return nil, err
+			}
+			if q, exists := obj["query"]; exists && q != nil {
+				qObj, ok := q.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("field query: %w", NewTypeError(q, ValueObject))
+				}
+				u.RawQuery = objectToURLValues(qObj).Encode()
+			} else if u.RawQuery, err = getStr("raw_query"); err != nil {
+				return nil, err
+			}
+			if u.Fragment, err = getStr("fragment"); err != nil {
+				return nil, err
+			}
+			if u.RawFragment, err = getStr("raw_fragment"); err != nil {
+				return nil, err
+			}
+
+			if user, exists := obj["user"]; exists && user != nil {
+				userObj, ok := user.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("field user: %w", NewTypeError(user, ValueObject))
+				}
+				name, _ := IGetString(userObj["name"])
+				if pass, exists := userObj["password"]; exists {
+					passStr, _ := IGetString(pass)
+					u.User = url.UserPassword(name, passStr)
+				} else {
+					u.User = url.User(name)
+				}
+			}
+			return u.String(), nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"parse_query_string", "Parses a URL query string into an object, where keys with a single value are strings and keys with multiple values are arrays. A leading `?` is ignored.",
+	).InCategory(
+		MethodCategoryParsing, "",
+		NewExampleSpec("",
+			`root.params = this.query.parse_query_string()`,
+			`{"query":"?user=ash&role=admin&role=dev"}`,
+			`{"params":{"role":["admin","dev"],"user":"ash"}}`,
+		),
+	),
+	func(*ParsedParams) (simpleMethod, error) {
+		return stringMethod(func(data string) (any, error) {
+			values, err := url.ParseQuery(strings.TrimPrefix(data, "?"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse query string: %w", err)
+			}
+			return urlValuesToObject(values), nil
+		}), nil
+	},
+)
+
 //------------------------------------------------------------------------------
 
 var _ = registerSimpleMethod(
@@ -77,6 +77,90 @@ func TestMethods(t *testing.T) {
 		messages []easyMsg
 		index    int
 	}{
+		"check parse_url query": {
+			input: methods(
+				literalFn("https://foo:bar@[::1]:8080/a%20b?x=1&y=2&y=3#frag"),
+				method("parse_url"),
+			),
+			output: map[string]any{
+				"scheme":       "https",
+				"opaque":       "",
+				"host":         "[::1]:8080",
+				"hostname":     "::1",
+				"port":         "8080",
+				"path":         "/a b",
+				"raw_path":     "",
+				"raw_query":    "x=1&y=2&y=3",
+				"query":        map[string]any{"x": "1", "y": []any{"2", "3"}},
+				"fragment":     "frag",
+				"raw_fragment": "",
+				"user":         map[string]any{"name": "foo", "password": "bar"},
+			},
+		},
+		"check format_url round trip": {
+			input: methods(
+				literalFn("https://foo:bar@[::1]:8080/a%20b?x=1&y=2&y=3#frag"),
+				method("parse_url"),
+				method("format_url"),
+			),
+			output: "https://foo:bar@[::1]:8080/a%20b?x=1&y=2&y=3#frag",
+		},
+		"check format_url host without hostname": {
+			input: methods(
+				jsonFn(`{"scheme":"redis","host":"localhost:6379","raw_query":"db=2"}`),
+				method("format_url"),
+			),
+			output: "redis://localhost:6379?db=2",
+		},
+		"check format_url ipv6 hostname without port": {
+			input: methods(
+				jsonFn(`{"scheme":"http","hostname":"::1","path":"/"}`),
+				method("format_url"),
+			),
+			output: "http://[::1]/",
+		},
+		"check format_url opaque": {
+			input: methods(
+				jsonFn(`{"scheme":"mailto","opaque":"ash@example.com"}`),
+				method("format_url"),
+			),
+			output: "mailto:ash@example.com",
+		},
+		"check format_url bad query": {
+			input: methods(
+				jsonFn(`{"scheme":"http","hostname":"example.com","query":"a=b"}`),
+				method("format_url"),
+			),
+			err: `object literal: field query: expected object value, got string ("a=b")`,
+		},
+		"check format_url not an object": {
+			input: methods(
+				literalFn("http://example.com"),
+				method("format_url"),
+			),
+			err: `expected object value, got string from string literal ("http://example.com")`,
+		},
+		"check parse_query_string": {
+			input: methods(
+				literalFn("a=1&b=%20two&a=3"),
+				method("parse_query_string"),
+			),
+			output: map[string]any{"a": []any{"1", "3"}, "b": " two"},
+		},
+		"check parse_query_string empty": {
+			input: methods(
+				literalFn("?"),
+				method("parse_query_string"),
+			),
+			output: map[string]any{},
+		},
+		"check parse_query_string bad": {
+			input: methods(
+				literalFn("a=%zz"),
+				method("parse_query_string"),
+			),
+			err: `string literal: failed to parse query string: invalid URL escape "%zz"`,
+		},
 		"check format_json with default indentation": {
 			input: methods(
 				jsonFn(`{"doc":{"foo":"bar"}}`),
@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
 
 	"go.opentelemetry.io/otel/trace"
 
@@ -85,9 +86,48 @@ type NewManagement interface {
 	StoreRateLimit(ctx context.Context, name string, conf ratelimit.Config) error
 	RemoveRateLimit(ctx context.Context, name string) error
 
+	ProbeHTTPClient(name string) bool
+	GetHTTPClient(name string, ctor LazyResourceConstructor) (any, error)
+
+	ProbeSQLDB(name string) bool
+	GetSQLDB(name string, ctor LazyResourceConstructor) (any, error)
+
 	GetPipe(name string) (<-chan message.Transaction, error)
 	SetPipe(name string, t <-chan message.Transaction)
 	UnsetPipe(name string, t <-chan message.Transaction)
+
+	GetTopicSubscribers(name string) []*TopicSubscriber
+	SubscribeTopic(name string, s *TopicSubscriber)
+	UnsubscribeTopic(name string, s *TopicSubscriber)
+}
+
+// PathSegment returns the segment that identifies a child component within the
+// path of its parent, provided to IntoPath, which is the label of the child when
+// one is configured and otherwise its index within a list. This keeps the
+// observability of labelled components stable when the order of a list changes.
+func PathSegment(index int, label string) string {
+	if label != "" {
+		return label
+	}
+	return strconv.Itoa(index)
+}
+
+// LazyResourceConstructor creates a resource from its raw config the first time
+// it is accessed, such as the shared client of an http_client resource. The
+// constructor is provided by the caller as the manager is unable to import the
+// implementations of these resources.
+type LazyResourceConstructor func(conf map[string]any, mgr NewManagement) (any, error)
+
+// TopicSubscriber is a subscription to a named in-process topic, where each
+// subscriber receives its own copy of the transactions published to the
+// topic.
+type TopicSubscriber struct {
+	// Transactions receives the transactions published to the topic.
+	Transactions chan<- message.Transaction
+
+	// Done is closed once the subscriber is no longer consuming transactions,
+	// at which point publishers should abandon any pending deliveries.
+	Done <-chan struct{}
 }
 
 type componentErr struct {
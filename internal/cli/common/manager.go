@@ -22,6 +22,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/profiling"
 )
 
 // CreateManager from a CLI context and a stream config.
@@ -33,21 +34,88 @@ func CreateManager(
 	conf config.Type,
 	mgrOpts ...manager.OptFunc,
 ) (stoppableMgr *StoppableManager, err error) {
-	var stats *metrics.Namespaced
-	var trac trace.TracerProvider
+	if streamsMode && conf.HTTP.UI.Enabled {
+		logger.Warnln("The admin UI is not supported in streams mode and has been disabled")
+		conf.HTTP.UI.Enabled = false
+	}
+
+	obs, err := createObservability(logger, version, dateBuilt, conf)
+	if err != nil {
+		return nil, err
+	}
 	defer func() {
-		if err == nil {
-			return
+		if err != nil {
+			obs.close()
 		}
-		if trac != nil {
-			if shutter, ok := trac.(interface {
-				Shutdown(context.Context) error
-			}); ok {
-				_ = shutter.Shutdown(context.Background())
-			}
+	}()
+
+	mgrOpts = append([]manager.OptFunc{
+		manager.OptSetAPIReg(obs.httpServer),
+		manager.OptSetStreamHTTPNamespacing(c.Bool("prefix-stream-endpoints")),
+		manager.OptSetLogger(obs.logger),
+		manager.OptSetMetrics(obs.stats),
+		manager.OptSetTracer(obs.trac),
+		manager.OptSetStreamsMode(streamsMode),
+	}, mgrOpts...)
+
+	// Create resource manager.
+	var mgr *manager.Type
+	if mgr, err = manager.New(conf.ResourceConfig, mgrOpts...); err != nil {
+		err = fmt.Errorf("failed to initialise resources: %w", err)
+		return
+	}
+
+	var profiler *profiling.Profiler
+	if profiler, err = startProfiling(conf, mgr); err != nil {
+		return
+	}
+
+	stoppableMgr = newStoppableManager(obs.httpServer, mgr, profiler)
+	return
+}
+
+// observability contains the components of a service that are shared by all
+// of its pipelines.
+type observability struct {
+	logger     log.Modular
+	stats      *metrics.Namespaced
+	trac       trace.TracerProvider
+	httpServer *api.Type
+}
+
+func (o observability) close() {
+	if o.trac != nil {
+		if shutter, ok := o.trac.(interface {
+			Shutdown(context.Context) error
+		}); ok {
+			_ = shutter.Shutdown(context.Background())
 		}
-		if stats != nil {
-			_ = stats.Close()
+	}
+	if o.stats != nil {
+		_ = o.stats.Close()
+	}
+}
+
+func startProfiling(conf config.Type, mgr *manager.Type) (*profiling.Profiler, error) {
+	if !conf.Profiling.Enabled {
+		return nil, nil
+	}
+	profiler, err := profiling.New(conf.Profiling, mgr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise profiling: %w", err)
+	}
+	profiler.Start()
+	return profiler, nil
+}
+
+func createObservability(
+	logger log.Modular,
+	version, dateBuilt string,
+	conf config.Type,
+) (obs observability, err error) {
+	defer func() {
+		if err != nil {
+			obs.close()
 		}
 	}()
 
@@ -59,13 +127,13 @@ func CreateManager(
 	tmpMgr.L = logger
 
 	// Create our metrics type.
-	if stats, err = bundle.AllMetrics.Init(conf.Metrics, tmpMgr); err != nil {
+	if obs.stats, err = bundle.AllMetrics.Init(conf.Metrics, tmpMgr); err != nil {
 		err = fmt.Errorf("failed to connect to metrics aggregator: %w", err)
 		return
 	}
 
 	// Create our tracer type.
-	if trac, err = bundle.AllTracers.Init(conf.Tracer, tmpMgr); err != nil {
+	if obs.trac, err = bundle.AllTracers.Init(conf.Tracer, tmpMgr); err != nil {
 		err = fmt.Errorf("failed to initialise tracer: %w", err)
 		return
 	}
@@ -83,29 +151,17 @@ func CreateManager(
 		return
 	}
 
-	var httpServer *api.Type
-	if httpServer, err = api.New(version, dateBuilt, conf.HTTP, sanitNode, logger, stats); err != nil {
+	if obs.httpServer, err = api.New(version, dateBuilt, conf.HTTP, sanitNode, logger, obs.stats); err != nil {
 		err = fmt.Errorf("failed to initialise API: %w", err)
 		return
 	}
 
-	mgrOpts = append([]manager.OptFunc{
-		manager.OptSetAPIReg(httpServer),
-		manager.OptSetStreamHTTPNamespacing(c.Bool("prefix-stream-endpoints")),
-		manager.OptSetLogger(logger),
-		manager.OptSetMetrics(stats),
-		manager.OptSetTracer(trac),
-		manager.OptSetStreamsMode(streamsMode),
-	}, mgrOpts...)
-
-	// Create resource manager.
-	var mgr *manager.Type
-	if mgr, err = manager.New(conf.ResourceConfig, mgrOpts...); err != nil {
-		err = fmt.Errorf("failed to initialise resources: %w", err)
-		return
+	// The admin UI records the metrics and errors of all components.
+	obs.logger = logger
+	if ui := obs.httpServer.UI(); ui != nil {
+		obs.stats = obs.stats.WithStats(metrics.Combine(obs.stats.Child(), ui.Metrics()))
+		obs.logger = ui.WrapLogger(logger)
 	}
-
-	stoppableMgr = newStoppableManager(httpServer, mgr)
 	return
 }
 
@@ -121,6 +177,22 @@ func RunManagerUntilStopped(
 	stopStrm Stoppable,
 	dataStreamClosedChan chan struct{},
 ) int {
+	return runManagerUntilStopped(c, conf, stopMgr, stopStrm, dataStreamClosedChan, nil)
+}
+
+// runManagerUntilStopped behaves the same as RunManagerUntilStopped but also
+// accepts a closure that is called once the stream has stopped and before the
+// manager is stopped, with a boolean indicating whether the stream terminated
+// on its own accord.
+func runManagerUntilStopped(
+	c *cli.Context,
+	conf config.Type,
+	stopMgr *StoppableManager,
+	stopStrm Stoppable,
+	dataStreamClosedChan chan struct{},
+	onStreamStopped func(ctx context.Context, terminated bool),
+) int {
+	var terminated bool
 	var exitDelay time.Duration
 	if td := conf.SystemCloseDelay; len(td) > 0 {
 		var err error
@@ -162,6 +234,9 @@ func RunManagerUntilStopped(
 		if err := stopStrm.Stop(ctx); err != nil {
 			os.Exit(1)
 		}
+		if onStreamStopped != nil {
+			onStreamStopped(ctx, terminated)
+		}
 
 		if err := stopMgr.Stop(ctx); err != nil {
 			stopMgr.Manager().Logger().Warnf(
@@ -208,6 +283,7 @@ func RunManagerUntilStopped(
 		}
 		stopMgr.Manager().Logger().Infof("Received %s, the service is closing", sigName)
 	case <-dataStreamClosedChan:
+		terminated = true
 		stopMgr.Manager().Logger().Infoln("Pipeline has terminated. Shutting down the service")
 	case <-deadLineTrigger:
 		stopMgr.Manager().Logger().Infoln("Run context deadline about to be reached. Shutting down the service")
@@ -217,11 +293,12 @@ func RunManagerUntilStopped(
 	return 0
 }
 
-func newStoppableManager(api *api.Type, mgr *manager.Type) *StoppableManager {
+func newStoppableManager(api *api.Type, mgr *manager.Type, profiler *profiling.Profiler) *StoppableManager {
 	s := &StoppableManager{
 		api:           api,
 		apiClosedChan: make(chan struct{}),
 		mgr:           mgr,
+		profiler:      profiler,
 	}
 	// Start HTTP server.
 	go func() {
@@ -241,6 +318,7 @@ type StoppableManager struct {
 	api           *api.Type
 	apiClosedChan chan struct{}
 	mgr           *manager.Type
+	profiler      *profiling.Profiler
 }
 
 // Manager returns the underlying manager type.
@@ -273,6 +351,14 @@ func (s *StoppableManager) Stop(ctx context.Context) error {
 		s.mgr.Logger().Warnln("Service failed to close HTTP server gracefully in time")
 	}()
 
+	// Profiles may be sent to output resources, and therefore profiling must
+	// stop before resources are closed.
+	if s.profiler != nil {
+		if err := s.profiler.Close(ctx); err != nil {
+			s.mgr.Logger().Warnf("Failed to stop profiling cleanly: %v\n", err)
+		}
+	}
+
 	s.mgr.TriggerStopConsuming()
 	if err := s.mgr.WaitForClose(ctx); err != nil {
 		return err
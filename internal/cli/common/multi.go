@@ -0,0 +1,296 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/benthosdev/benthos/v4/internal/config"
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/manager"
+)
+
+// ConfigPaths returns the main config paths specified by CLI flags, where any
+// directories are expanded into the YAML files they contain. The returned
+// boolean indicates whether multiple configs are to be executed, which is the
+// case when the config flag is specified more than once or targets a directory.
+func ConfigPaths(c *cli.Context) (paths []string, multi bool, err error) {
+	flagPaths := c.StringSlice("config")
+	multi = len(flagPaths) > 1
+	for _, p := range flagPaths {
+		info, err := ifs.OS().Stat(p)
+		if err != nil || !info.IsDir() {
+			paths = append(paths, p)
+			continue
+		}
+		multi = true
+
+		entries, err := fs.ReadDir(ifs.OS(), p)
+		if err != nil {
+			return nil, false, err
+		}
+		var dirPaths []string
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			if ext := filepath.Ext(e.Name()); ext == ".yaml" || ext == ".yml" {
+				dirPaths = append(dirPaths, filepath.Join(p, e.Name()))
+			}
+		}
+		if len(dirPaths) == 0 {
+			return nil, false, fmt.Errorf("directory %v does not contain any config files", p)
+		}
+		sort.Strings(dirPaths)
+		paths = append(paths, dirPaths...)
+	}
+	return
+}
+
+// configID derives an identifier for a config from its file name.
+func configID(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+type multiPipeline struct {
+	id         string
+	conf       config.Type
+	confReader *config.Reader
+	mgr        *manager.Type
+	strm       Stoppable
+	closedChan chan struct{}
+}
+
+// runMultiService runs multiple independent configs concurrently within a
+// single process. The observability fields (http, logger, metrics, tracer and
+// profiling) of the first config are used for the whole process, and each
+// config is otherwise isolated with its own resources, a "stream" label added
+// to its logs and metrics, and HTTP endpoints prefixed with its identifier.
+func runMultiService(c *cli.Context, version, dateBuilt string, paths []string) int {
+	if c.Bool("job") {
+		fmt.Fprintln(os.Stderr, "The --job flag is not supported when running multiple configs")
+		return 1
+	}
+
+	strict := !c.Bool("chilled")
+	watching := c.Bool("watcher")
+
+	seenIDs := map[string]string{}
+	pipelines := make([]*multiPipeline, 0, len(paths))
+	var lints []string
+	for _, p := range paths {
+		id := configID(p)
+		if existing, exists := seenIDs[id]; exists {
+			fmt.Fprintf(os.Stderr, "Configs %v and %v share the same name %v\n", existing, p, id)
+			return 1
+		}
+		seenIDs[id] = p
+
+		confReader := config.NewReader(p, c.StringSlice("resources"),
+			config.OptAddOverrides(c.StringSlice("set")...),
+			config.OptTestSuffix("_benthos_test"),
+		)
+		conf, confLints, err := confReader.Read()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration file %v read error: %v\n", p, err)
+			return 1
+		}
+		defer func() {
+			_ = confReader.Close(c.Context)
+		}()
+		for _, l := range confLints {
+			lints = append(lints, p+": "+l)
+		}
+		pipelines = append(pipelines, &multiPipeline{id: id, conf: conf, confReader: confReader})
+	}
+
+	rootConf := pipelines[0].conf
+	logger, err := CreateLogger(c, rootConf, false)
+	if err != nil {
+		fmt.Printf("Failed to create logger: %v\n", err)
+		return 1
+	}
+	applySIMDJSON(rootConf, logger)
+	logger.With("benthos_version", version, "paths", paths).Infof("Running multiple main configs from specified files")
+
+	for _, lint := range lints {
+		if strict {
+			logger.With("lint", lint).Errorln("Config lint error")
+		} else {
+			logger.With("lint", lint).Warnln("Config lint error")
+		}
+	}
+	if strict && len(lints) > 0 {
+		logger.Errorln("Shutting down due to linter errors, to prevent shutdown run Benthos with --chilled")
+		return 1
+	}
+
+	if rootConf.HTTP.UI.Enabled {
+		logger.Warnln("The admin UI is not supported when running multiple configs and has been disabled")
+		rootConf.HTTP.UI.Enabled = false
+	}
+
+	obs, err := createObservability(logger, version, dateBuilt, rootConf)
+	if err != nil {
+		logger.Errorln(err.Error())
+		return 1
+	}
+	for _, p := range pipelines {
+		if p.mgr, err = manager.New(p.conf.ResourceConfig,
+			manager.OptSetAPIReg(obs.httpServer),
+			manager.OptSetStreamHTTPNamespacing(true),
+			manager.OptSetLogger(logger),
+			manager.OptSetMetrics(obs.stats),
+			manager.OptSetTracer(obs.trac),
+			manager.OptSetStream(p.id),
+		); err != nil {
+			logger.Errorf("Failed to initialise resources of config %v: %v", p.id, err)
+			obs.close()
+			return 1
+		}
+	}
+
+	profiler, err := startProfiling(rootConf, pipelines[0].mgr)
+	if err != nil {
+		logger.Errorln(err.Error())
+		obs.close()
+		return 1
+	}
+	stopMgr := newStoppableManager(obs.httpServer, pipelines[0].mgr, profiler)
+
+	for _, p := range pipelines {
+		p.strm, p.closedChan = initNormalMode(p.conf, strict, watching, nil, nil, p.confReader, p.mgr)
+	}
+	return runMultiUntilStopped(c, logger, rootConf, stopMgr, pipelines)
+}
+
+// runMultiUntilStopped blocks until either all pipelines have terminated or a
+// signal is given to the process to terminate. Each pipeline is stopped
+// independently according to its own shutdown timeout as soon as it
+// terminates, and the shared manager and HTTP server are stopped last.
+func runMultiUntilStopped(c *cli.Context, logger log.Modular, rootConf config.Type, stopMgr *StoppableManager, pipelines []*multiPipeline) int {
+	stopChan := make(chan struct{})
+	var stopOnce sync.Once
+	stopAll := func() {
+		stopOnce.Do(func() {
+			close(stopChan)
+		})
+	}
+
+	var failed bool
+	var failedMut sync.Mutex
+
+	exitTimeouts := make([]time.Duration, len(pipelines))
+	for i, p := range pipelines {
+		var err error
+		if exitTimeouts[i], err = parseOptionalDuration(p.conf.SystemCloseTimeout); err != nil {
+			logger.Errorf("Failed to parse shutdown timeout period string of config %v: %v\n", p.id, err)
+			return 1
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, p := range pipelines {
+		p, exitTimeout := p, exitTimeouts[i]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case <-p.closedChan:
+				logger.Infof("Pipeline %v has terminated", p.id)
+			case <-stopChan:
+			}
+
+			ctx, done := context.WithTimeout(context.Background(), exitTimeout)
+			defer done()
+
+			err := p.strm.Stop(ctx)
+			if err == nil && p.mgr != stopMgr.Manager() {
+				p.mgr.TriggerStopConsuming()
+				err = p.mgr.WaitForClose(ctx)
+			}
+			if err != nil {
+				logger.Errorf("Pipeline %v failed to close cleanly within allocated time: %v", p.id, err)
+				failedMut.Lock()
+				failed = true
+				failedMut.Unlock()
+			}
+		}()
+	}
+
+	allStoppedChan := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(allStoppedChan)
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case sig := <-sigChan:
+		logger.Infof("Received %s, the service is closing", sig)
+	case <-allStoppedChan:
+		logger.Infoln("All pipelines have terminated. Shutting down the service")
+	case <-c.Context.Done():
+		logger.Infoln("Run context was cancelled. Shutting down the service")
+	}
+	stopAll()
+	<-allStoppedChan
+
+	exitDelay, err := parseOptionalDuration(rootConf.SystemCloseDelay)
+	if err != nil {
+		logger.Errorf("Failed to parse shutdown delay period string: %v\n", err)
+		return 1
+	}
+	if exitDelay > 0 {
+		logger.Infof("Shutdown delay is in effect for %s\n", exitDelay)
+		if err := DelayShutdown(context.Background(), exitDelay); err != nil {
+			logger.Errorf("Shutdown delay failed: %s", err)
+		}
+	}
+
+	exitTimeout, err := parseOptionalDuration(rootConf.SystemCloseTimeout)
+	if err != nil {
+		logger.Errorf("Failed to parse shutdown timeout period string: %v\n", err)
+		return 1
+	}
+	ctx, done := context.WithTimeout(context.Background(), exitTimeout)
+	defer done()
+	if err := stopMgr.Stop(ctx); err != nil {
+		logger.Errorf("Service failed to close resources cleanly within allocated time: %v", err)
+		return 1
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, errors.New("duration must not be negative")
+	}
+	return d, nil
+}
@@ -11,7 +11,10 @@ import (
 // config.Reader based on input CLI flags. This includes applying any config
 // overrides expressed by the --set flag.
 func ReadConfig(c *cli.Context, streamsMode bool) (mainPath string, inferred bool, conf *config.Reader) {
-	path := c.String("config")
+	var path string
+	if paths := c.StringSlice("config"); len(paths) > 0 {
+		path = paths[0]
+	}
 	if path == "" {
 		// Iterate default config paths
 		for _, dpath := range []string{
@@ -2,16 +2,23 @@ package common
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/benthosdev/benthos/v4/internal/api"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/config"
+	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/stream"
 	strmmgr "github.com/benthosdev/benthos/v4/internal/stream/manager"
 
@@ -21,6 +28,17 @@ import (
 // RunService runs a service command (either the default or the streams
 // subcommand).
 func RunService(c *cli.Context, version, dateBuilt string, streamsMode bool) int {
+	if !streamsMode {
+		paths, multi, err := ConfigPaths(c)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration paths error: %v\n", err)
+			return 1
+		}
+		if multi {
+			return runMultiService(c, version, dateBuilt, paths)
+		}
+	}
+
 	mainPath, inferredMainPath, confReader := ReadConfig(c, streamsMode)
 
 	conf, lints, err := confReader.Read()
@@ -38,6 +56,8 @@ func RunService(c *cli.Context, version, dateBuilt string, streamsMode bool) int
 		return 1
 	}
 
+	applySIMDJSON(conf, logger)
+
 	verLogger := logger.With("benthos_version", version)
 	if mainPath == "" {
 		verLogger.Infof("Running without a main config file")
@@ -69,16 +89,59 @@ func RunService(c *cli.Context, version, dateBuilt string, streamsMode bool) int
 	var stoppableStream Stoppable
 	var dataStreamClosedChan chan struct{}
 
+	var jobTracker *stream.JobTracker
+	if !streamsMode && (c.Bool("job") || conf.Completion.Enabled()) {
+		jobTracker = stream.NewJobTracker(metrics.NewLocal())
+	}
+
 	// Create data streams.
 	watching := c.Bool("watcher")
 	if streamsMode {
 		enableStreamsAPI := !c.Bool("no-api")
 		stoppableStream = initStreamsMode(strict, watching, enableStreamsAPI, confReader, stoppableManager.Manager())
 	} else {
-		stoppableStream, dataStreamClosedChan = initNormalMode(conf, strict, watching, confReader, stoppableManager.Manager())
+		stoppableStream, dataStreamClosedChan = initNormalMode(conf, strict, watching, jobTracker, stoppableManager.API().UI(), confReader, stoppableManager.Manager())
 	}
 
-	return RunManagerUntilStopped(c, conf, stoppableManager, stoppableStream, dataStreamClosedChan)
+	if jobTracker == nil {
+		return RunManagerUntilStopped(c, conf, stoppableManager, stoppableStream, dataStreamClosedChan)
+	}
+
+	var report stream.JobReport
+	code := runManagerUntilStopped(c, conf, stoppableManager, stoppableStream, dataStreamClosedChan, func(ctx context.Context, terminated bool) {
+		report = jobTracker.Report(terminated)
+		if terminated && conf.Completion.Enabled() {
+			if err := stream.SendCompletion(ctx, conf.Completion, report, stoppableManager.Manager()); err != nil {
+				logger.Errorf("Failed to send completion callbacks: %v\n", err)
+			}
+		}
+	})
+	if code != 0 || !c.Bool("job") {
+		return code
+	}
+	return reportJob(report)
+}
+
+// applySIMDJSON enables the SIMD accelerated JSON parser when configured.
+func applySIMDJSON(conf config.Type, logger log.Modular) {
+	if conf.SIMDJSON && !message.SetSIMDJSON(true) {
+		logger.Warnln("SIMD JSON parsing is not supported by this CPU and will not be used")
+	}
+}
+
+// reportJob prints a job report as JSON to stdout and returns the exit code
+// that reflects its success.
+func reportJob(report stream.JobReport) int {
+	reportBytes, err := json.Marshal(report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to marshal job report: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(reportBytes))
+	if !report.Succeeded {
+		return 1
+	}
+	return 0
 }
 
 // DelayShutdown attempts to block until either:
@@ -172,6 +235,8 @@ func initStreamsMode(
 func initNormalMode(
 	conf config.Type,
 	strict, watching bool,
+	jobTracker *stream.JobTracker,
+	ui *api.UIRecorder,
 	confReader *config.Reader,
 	mgr *manager.Type,
 ) (newStream Stoppable, stoppedChan chan struct{}) {
@@ -179,17 +244,63 @@ func initNormalMode(
 
 	stoppedChan = make(chan struct{})
 	var closeOnce sync.Once
+	closeStopped := func() {
+		closeOnce.Do(func() {
+			close(stoppedChan)
+		})
+	}
+
+	// A stream that is restarted by its watchdog is closed deliberately, and
+	// therefore its closure isn't considered the pipeline terminating.
+	var restartStream func(restarted *atomic.Bool)
 	streamInit := func() (Stoppable, error) {
-		return stream.New(conf.Config, mgr, stream.OptOnClose(func() {
-			if !watching {
-				closeOnce.Do(func() {
-					close(stoppedChan)
-				})
+		var restarted atomic.Bool
+		var readinessGrace time.Duration
+		if gp := conf.HTTP.Readiness.GracePeriod; gp != "" {
+			var err error
+			if readinessGrace, err = time.ParseDuration(gp); err != nil {
+				return nil, fmt.Errorf("failed to parse http.readiness.grace_period: %w", err)
 			}
-		}))
+		}
+		var strmMgr bundle.NewManagement = mgr
+		opts := []func(*stream.Type){
+			stream.OptOnClose(func() {
+				if !watching && !restarted.Load() {
+					closeStopped()
+				}
+			}),
+			stream.OptReadiness(conf.HTTP.Readiness.Strict, readinessGrace),
+			stream.OptWatchdog(conf.Watchdog),
+			stream.OptShutdown(conf.Shutdown),
+			stream.OptOnWatchdogRestart(func() {
+				restartStream(&restarted)
+			}),
+		}
+		if jobTracker != nil {
+			strmMgr = mgr.WithAddedMetrics(jobTracker.Metrics())
+			opts = append(opts, stream.OptJobTracker(jobTracker))
+		}
+		if ui != nil {
+			opts = append(opts, stream.OptSampler(ui.SampleBatch))
+		}
+		return stream.New(conf.Config, strmMgr, opts...)
 	}
 
 	var stoppableStream *SwappableStopper
+	restartStream = func(restarted *atomic.Bool) {
+		if !restarted.CompareAndSwap(false, true) {
+			// The stream is already being restarted.
+			return
+		}
+		ctx, done := context.WithTimeout(context.Background(), 30*time.Second)
+		defer done()
+		if err := stoppableStream.Replace(ctx, streamInit); err != nil {
+			logger.Errorf("Failed to restart stream: %v\n", err)
+			if !watching {
+				closeStopped()
+			}
+		}
+	}
 	if initStream, err := streamInit(); err != nil {
 		logger.Errorf("Service closing due to: %v\n", err)
 		os.Exit(1)
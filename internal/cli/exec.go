@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/benthosdev/benthos/v4/internal/cli/test"
+	"github.com/benthosdev/benthos/v4/internal/component/processor"
+	ifilepath "github.com/benthosdev/benthos/v4/internal/filepath"
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func execCliCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "exec",
+		Usage: "Dry-run sample messages through the processors of a config",
+		Description: `
+Reads sample messages from a file, where each line is the raw contents of a
+message, and executes them through the processors of a config targeted with a
+JSON pointer. Each resulting message is printed to stdout as a JSON object
+containing its contents, metadata and any error flagged during processing. The
+inputs and outputs of the config are never started:
+
+  benthos exec -c ./config.yaml --input-file ./samples.ndjson
+  benthos exec -c ./config.yaml --target /pipeline/processors/0 < ./samples.ndjson
+  benthos exec -c ./config.yaml -r ./resources.yaml --target /input/processors
+
+If the input file is omitted or set to '-' then sample messages are read from
+stdin.`[1:],
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "config",
+				Aliases: []string{"c"},
+				Usage:   "a path to the configuration file containing the target processors",
+			},
+			&cli.StringSliceFlag{
+				Name:    "resources",
+				Aliases: []string{"r"},
+				Usage:   "pull in extra resources from a file, which can be referenced by the target processors, supports glob patterns (requires quotes)",
+			},
+			&cli.StringFlag{
+				Name:  "input-file",
+				Value: "-",
+				Usage: "a path to a file of sample messages delimited by line breaks, or '-' to read from stdin",
+			},
+			&cli.StringFlag{
+				Name:  "target",
+				Value: "/pipeline/processors",
+				Usage: "a JSON pointer to the processors within the config that should be executed",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if code := ExecAction(c, os.Stdin, os.Stdout, os.Stderr); code != 0 {
+				os.Exit(code)
+			}
+			return nil
+		},
+	}
+}
+
+type execResult struct {
+	Content  string         `json:"content"`
+	Metadata map[string]any `json:"metadata"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// execStringSlice returns the values of a flag from the exec subcommand, or
+// from the root level flags when they aren't set on the subcommand.
+func execStringSlice(c *cli.Context, name string) []string {
+	for _, pc := range c.Lineage() {
+		if v := pc.StringSlice(name); len(v) > 0 {
+			return v
+		}
+	}
+	return nil
+}
+
+// ExecAction performs the benthos exec subcommand and returns the appropriate
+// exit code. This function is exported for testing purposes only.
+func ExecAction(c *cli.Context, stdin io.Reader, stdout, stderr io.Writer) int {
+	confPaths := execStringSlice(c, "config")
+	if len(confPaths) != 1 {
+		fmt.Fprintln(stderr, "A single config file must be specified with --config (-c)")
+		return 1
+	}
+	confPath := confPaths[0]
+
+	resourcesPaths, err := ifilepath.Globs(ifs.OS(), execStringSlice(c, "resources"))
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to resolve resource glob pattern: %v\n", err)
+		return 1
+	}
+
+	samples := stdin
+	if inputPath := c.String("input-file"); inputPath != "-" && inputPath != "" {
+		f, err := ifs.OS().Open(inputPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to open input file: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		samples = f
+	}
+
+	procs, err := test.NewProcessorsProvider(confPath, test.OptAddResourcesPaths(resourcesPaths)).Provide(c.String("target"), nil, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to initialise processors: %v\n", err)
+		return 1
+	}
+	defer func() {
+		for _, p := range procs {
+			_ = p.Close(context.Background())
+		}
+	}()
+
+	enc := json.NewEncoder(stdout)
+	scanner := bufio.NewScanner(samples)
+	scanner.Buffer(nil, 1024*1024*64)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		batch := message.QuickBatch([][]byte{append([]byte(nil), scanner.Bytes()...)})
+
+		outputs, err := processor.ExecuteAll(c.Context, procs, batch)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to execute processors: %v\n", err)
+			return 1
+		}
+
+		for _, b := range outputs {
+			for _, p := range b {
+				res := execResult{
+					Content:  string(p.AsBytes()),
+					Metadata: map[string]any{},
+				}
+				_ = p.MetaIterMut(func(k string, v any) error {
+					res.Metadata[k] = v
+					return nil
+				})
+				if pErr := p.ErrorGet(); pErr != nil {
+					res.Error = pErr.Error()
+				}
+				if err := enc.Encode(res); err != nil {
+					fmt.Fprintf(stderr, "Failed to write result: %v\n", err)
+					return 1
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(stderr, "Failed to read input file: %v\n", err)
+		return 1
+	}
+	return 0
+}
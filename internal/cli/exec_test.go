@@ -0,0 +1,116 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	icli "github.com/benthosdev/benthos/v4/internal/cli"
+)
+
+func executeExecSubcmd(t *testing.T, stdin string, args []string) (exitCode int, stdout, stderr string) {
+	cliApp := icli.App()
+	for _, c := range cliApp.Commands {
+		if c.Name == "exec" {
+			c.Action = func(ctx *cli.Context) error {
+				var outBuf, errBuf bytes.Buffer
+				exitCode = icli.ExecAction(ctx, strings.NewReader(stdin), &outBuf, &errBuf)
+				stdout, stderr = outBuf.String(), errBuf.String()
+				return nil
+			}
+		}
+	}
+	require.NoError(t, cliApp.Run(args))
+	return
+}
+
+func TestExec(t *testing.T) {
+	tmpDir := t.TempDir()
+	tFile := func(name string) string {
+		return filepath.Join(tmpDir, name)
+	}
+
+	require.NoError(t, os.WriteFile(tFile("config.yaml"), []byte(`
+input:
+  stdin: {}
+pipeline:
+  processors:
+    - mapping: |
+        meta foo = "bar"
+        root.doc = this
+    - resource: baz
+output:
+  drop: {}
+`), 0o644))
+	require.NoError(t, os.WriteFile(tFile("resources.yaml"), []byte(`
+processor_resources:
+  - label: baz
+    mapping: 'root.count = this.doc.count + 1'
+`), 0o644))
+	require.NoError(t, os.WriteFile(tFile("samples.ndjson"), []byte(`{"count":1}
+{"count":"nope"}
+`), 0o644))
+
+	tests := []struct {
+		name         string
+		stdin        string
+		args         []string
+		expectedCode int
+		expectedOut  []string
+		expectedErr  string
+	}{
+		{
+			name: "input file with resources",
+			args: []string{"benthos", "exec", "-c", tFile("config.yaml"), "-r", tFile("resources.yaml"), "--input-file", tFile("samples.ndjson")},
+			expectedOut: []string{
+				`{"content":"{\"count\":2}","metadata":{"foo":"bar"}}`,
+				`{"content":"{\"doc\":{\"count\":\"nope\"}}","metadata":{"foo":"bar"},"error":"failed assignment (line 1): cannot add types string (from field ` + "`this.doc.count`" + `) and number (from number literal)"}`,
+			},
+		},
+		{
+			name:  "stdin with root config flag and target",
+			stdin: `{"count":5}`,
+			args:  []string{"benthos", "-c", tFile("config.yaml"), "exec", "--target", "/pipeline/processors/0"},
+			expectedOut: []string{
+				`{"content":"{\"doc\":{\"count\":5}}","metadata":{"foo":"bar"}}`,
+			},
+		},
+		{
+			name:         "missing resources",
+			stdin:        `{"count":5}`,
+			args:         []string{"benthos", "exec", "-c", tFile("config.yaml")},
+			expectedCode: 1,
+			expectedErr:  "Failed to initialise processors",
+		},
+		{
+			name:         "no config",
+			args:         []string{"benthos", "exec"},
+			expectedCode: 1,
+			expectedErr:  "A single config file must be specified",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			code, stdout, stderr := executeExecSubcmd(t, test.stdin, test.args)
+			assert.Equal(t, test.expectedCode, code, stderr)
+			if test.expectedErr != "" {
+				assert.Contains(t, stderr, test.expectedErr)
+			}
+			var outLines []string
+			for _, l := range strings.Split(stdout, "\n") {
+				if l != "" {
+					outLines = append(outLines, l)
+				}
+			}
+			assert.Equal(t, test.expectedOut, outLines)
+		})
+	}
+}
@@ -85,11 +85,10 @@ func App() *cli.App {
 			Aliases: []string{"s"},
 			Usage:   "set a field (identified by a dot path) in the main configuration file, e.g. `\"metrics.type=prometheus\"`",
 		},
-		&cli.StringFlag{
+		&cli.StringSliceFlag{
 			Name:    "config",
 			Aliases: []string{"c"},
-			Value:   "",
-			Usage:   "a path to a configuration file",
+			Usage:   "a path to a configuration file, specify more than once or target a directory in order to run multiple independent configs within a single process",
 		},
 		&cli.StringSliceFlag{
 			Name:    "resources",
@@ -112,6 +111,11 @@ func App() *cli.App {
 			Value:   false,
 			Usage:   "EXPERIMENTAL: watch config files for changes and automatically apply them",
 		},
+		&cli.BoolFlag{
+			Name:  "job",
+			Value: false,
+			Usage: "run the config as a job, printing a JSON summary report once the pipeline terminates and exiting with a non-zero status if messages were rejected or dead lettered, or if the input was not fully drained",
+		},
 	}
 
 	app := &cli.App{
@@ -123,6 +127,8 @@ Either run Benthos as a stream processor or choose a command:
   benthos list inputs
   benthos create kafka//file > ./config.yaml
   benthos -c ./config.yaml
+  benthos --job -c ./batch_job.yaml
+  benthos -c ./pipelines
   benthos -r "./production/*.yaml" -c ./config.yaml`[1:],
 		Flags: flags,
 		Before: func(c *cli.Context) error {
@@ -223,6 +229,7 @@ variables have been resolved:
 				},
 			},
 			lintCliCommand(),
+			execCliCommand(),
 			{
 				Name:  "streams",
 				Usage: "Run Benthos in streams mode",
@@ -41,3 +41,174 @@ output:
 	data, _ := os.ReadFile(outPath)
 	assert.Contains(t, string(data), "foobar")
 }
+
+func TestRunCLIJob(t *testing.T) {
+	tmpDir := t.TempDir()
+	confPath := filepath.Join(tmpDir, "foo.yaml")
+	outPath := filepath.Join(tmpDir, "out.txt")
+
+	require.NoError(t, os.WriteFile(confPath, fmt.Appendf(nil, `
+input:
+  generate:
+    mapping: 'root.id = "foobar"'
+    count: 3
+    interval: ""
+output:
+  file:
+    codec: lines
+    path: %v
+`, outPath), 0o644))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	require.NoError(t, icli.App().RunContext(ctx, []string{"benthos", "--job", "-c", confPath}))
+
+	data, _ := os.ReadFile(outPath)
+	assert.Equal(t, "{\"id\":\"foobar\"}\n{\"id\":\"foobar\"}\n{\"id\":\"foobar\"}\n", string(data))
+}
+
+func TestRunCLICompletionOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	confPath := filepath.Join(tmpDir, "foo.yaml")
+
+	require.NoError(t, os.WriteFile(confPath, fmt.Appendf(nil, `
+input:
+  generate:
+    mapping: 'root.id = "foobar"'
+    count: 3
+    interval: ""
+output:
+  drop: {}
+output_resources:
+  - label: marker
+    file:
+      codec: all-bytes
+      path: '%v/${! @job_succeeded }.json'
+completion:
+  output: marker
+`, tmpDir), 0o644))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	require.NoError(t, icli.App().RunContext(ctx, []string{"benthos", "-c", confPath}))
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "true.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"received":3`)
+	assert.Contains(t, string(data), `"drained":true`)
+}
+
+func TestRunCLIMultiConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	confDir := filepath.Join(tmpDir, "configs")
+	require.NoError(t, os.Mkdir(confDir, 0o755))
+
+	// Both configs define a resource with the same label, which is fine as the
+	// resources of each config are isolated.
+	for _, name := range []string{"foo", "bar"} {
+		require.NoError(t, os.WriteFile(filepath.Join(confDir, name+".yaml"), fmt.Appendf(nil, `
+input:
+  generate:
+    mapping: 'root.id = "%v"'
+    count: 2
+    interval: ""
+pipeline:
+  processors:
+    - resource: tagger
+output:
+  file:
+    codec: lines
+    path: %v
+processor_resources:
+  - label: tagger
+    mapping: 'root.config = "%v"'
+`, name, filepath.Join(tmpDir, name+".txt"), name), 0o644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(confDir, "README.md"), []byte("not a config"), 0o644))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	require.NoError(t, icli.App().RunContext(ctx, []string{"benthos", "--log.level", "none", "-c", confDir}))
+
+	for _, name := range []string{"foo", "bar"} {
+		data, err := os.ReadFile(filepath.Join(tmpDir, name+".txt"))
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("{\"config\":\"%v\"}\n{\"config\":\"%v\"}\n", name, name), string(data))
+	}
+}
+
+func TestRunCLIMultiConfigRepeatedFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var args []string
+	for _, name := range []string{"foo", "bar"} {
+		confPath := filepath.Join(tmpDir, name+".yaml")
+		require.NoError(t, os.WriteFile(confPath, fmt.Appendf(nil, `
+input:
+  generate:
+    mapping: 'root.id = "%v"'
+    count: 1
+    interval: ""
+output:
+  file:
+    codec: lines
+    path: %v
+`, name, filepath.Join(tmpDir, name+".txt")), 0o644))
+		args = append(args, "-c", confPath)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	require.NoError(t, icli.App().RunContext(ctx, append([]string{"benthos", "--log.level", "none"}, args...)))
+
+	for _, name := range []string{"foo", "bar"} {
+		data, err := os.ReadFile(filepath.Join(tmpDir, name+".txt"))
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("{\"id\":\"%v\"}\n", name), string(data))
+	}
+}
+
+func TestRunCLIWatchdogRestart(t *testing.T) {
+	tmpDir := t.TempDir()
+	confPath := filepath.Join(tmpDir, "foo.yaml")
+	logPath := filepath.Join(tmpDir, "benthos.log")
+
+	require.NoError(t, os.WriteFile(confPath, fmt.Appendf(nil, `
+input:
+  generate:
+    mapping: 'root.id = "foobar"'
+    interval: "10ms"
+pipeline:
+  processors:
+    - sleep:
+        duration: 100ms
+output:
+  drop: {}
+watchdog:
+  enabled: true
+  stall_timeout: 50ms
+  action: restart
+logger:
+  level: info
+  file:
+    path: %v
+`, logPath), 0o644))
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*2))
+	defer cancel()
+
+	require.NoError(t, icli.App().RunContext(ctx, []string{"benthos", "-c", confPath}))
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Restarting stream due to stalled pipeline")
+
+	// The service must keep running after the stream is restarted, until the
+	// run context deadline is reached.
+	assert.NotContains(t, string(data), "Pipeline has terminated")
+	assert.Contains(t, string(data), "Run context deadline about to be reached")
+}
@@ -130,7 +130,8 @@ func NewPullRunner(c *cli.Context, version, dateBuilt, token, secret string, opt
 	baseURL.Path = path.Join(baseURL.Path, fmt.Sprintf("/api/v1/node/session/%v", c.String("session")))
 
 	var localLints []string
-	if localConfPath := c.String("config"); localConfPath != "" {
+	if localConfPaths := c.StringSlice("config"); len(localConfPaths) > 0 {
+		localConfPath := localConfPaths[0]
 		localReader := config.NewReader(localConfPath, c.StringSlice("resources"),
 			config.OptAddOverrides(r.setList...),
 			config.OptTestSuffix("_benthos_test"),
@@ -1,22 +1,36 @@
 package test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
+	"sync"
+	"time"
 
 	yaml "gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/component"
+	icache "github.com/benthosdev/benthos/v4/internal/component/cache"
+	ioutput "github.com/benthosdev/benthos/v4/internal/component/output"
 	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
 	"github.com/benthosdev/benthos/v4/internal/message"
 )
 
+// outputTimeout is the maximum period of time that the target output of a test
+// case is given to acknowledge all batches.
+const outputTimeout = time.Second * 30
+
 // InputPart defines an input part for a test case.
 type InputPart struct {
 	Content  string         `yaml:"content"`
 	Metadata map[string]any `yaml:"metadata"`
 	filePath string
+	fileJSON bool
 }
 
 func (i *InputPart) getContent(dir string) (string, error) {
@@ -28,6 +42,9 @@ func (i *InputPart) getContent(dir string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if i.fileJSON && !json.Valid(rawBytes) {
+		return "", fmt.Errorf("file '%v' does not contain a valid JSON document", i.filePath)
+	}
 	return string(rawBytes), nil
 }
 
@@ -47,10 +64,15 @@ func (i *InputPart) UnmarshalYAML(value *yaml.Node) error {
 			if err := yamlNodeToTestString(&v, &i.Content); err != nil {
 				return fmt.Errorf("line %v: %v", v.Line, err)
 			}
-		case "file_content":
+		case "file_content", "file_binary":
+			if err := v.Decode(&i.filePath); err != nil {
+				return fmt.Errorf("line %v: %v", v.Line, err)
+			}
+		case "file_json":
 			if err := v.Decode(&i.filePath); err != nil {
 				return fmt.Errorf("line %v: %v", v.Line, err)
 			}
+			i.fileJSON = true
 		case "metadata":
 			if err := v.Decode(&i.Metadata); err != nil {
 				return fmt.Errorf("line %v: %v", v.Line, err)
@@ -64,14 +86,20 @@ func (i *InputPart) UnmarshalYAML(value *yaml.Node) error {
 
 // Case contains a definition of a single Benthos config test case.
 type Case struct {
-	Name             string               `yaml:"name"`
-	Environment      map[string]string    `yaml:"environment"`
-	TargetProcessors string               `yaml:"target_processors"`
-	TargetMapping    string               `yaml:"target_mapping"`
-	Mocks            map[string]yaml.Node `yaml:"mocks"`
-	InputBatch       []InputPart          `yaml:"input_batch"`
-	InputBatches     [][]InputPart        `yaml:"input_batches"`
-	OutputBatches    [][]ConditionsMap    `yaml:"output_batches"`
+	Name             string                        `yaml:"name"`
+	Environment      map[string]string             `yaml:"environment"`
+	TargetProcessors string                        `yaml:"target_processors"`
+	TargetMapping    string                        `yaml:"target_mapping"`
+	Mocks            map[string]yaml.Node          `yaml:"mocks"`
+	TargetOutput     string                        `yaml:"target_output"`
+	OutputMocks      map[string]string             `yaml:"output_mocks"`
+	InputBatch       []InputPart                   `yaml:"input_batch"`
+	InputBatches     [][]InputPart                 `yaml:"input_batches"`
+	OutputBatches    [][]ConditionsMap             `yaml:"output_batches"`
+	OutputFiles      [][]string                    `yaml:"output_files"`
+	OutputCaptures   map[string][][]ConditionsMap  `yaml:"output_captures"`
+	PreCache         map[string]map[string]string  `yaml:"pre_cache"`
+	PostCache        map[string]map[string]*string `yaml:"post_cache"`
 
 	line int
 }
@@ -90,9 +118,15 @@ func NewCase() Case {
 		TargetProcessors: "/pipeline/processors",
 		TargetMapping:    "",
 		Mocks:            map[string]yaml.Node{},
+		TargetOutput:     "/output",
+		OutputMocks:      map[string]string{},
 		InputBatch:       []InputPart{},
 		InputBatches:     [][]InputPart{},
 		OutputBatches:    [][]ConditionsMap{},
+		OutputFiles:      [][]string{},
+		OutputCaptures:   map[string][][]ConditionsMap{},
+		PreCache:         map[string]map[string]string{},
+		PostCache:        map[string]map[string]*string{},
 	}
 }
 
@@ -131,14 +165,55 @@ type ProcProvider interface {
 	ProvideBloblang(path string) ([]iprocessor.V1, error)
 }
 
+// OutputProvider returns a compiled output extracted from a Benthos config
+// using a JSON Pointer, where mocked outputs are replaced with capture sinks.
+type OutputProvider interface {
+	ProvideOutput(jsonPtr string, environment map[string]string, mocks map[string]yaml.Node, outputMocks map[string]string) (ioutput.Streamed, map[string]<-chan message.Transaction, error)
+}
+
+// CacheAccessor provides access to cache resources by their names.
+type CacheAccessor interface {
+	AccessCache(ctx context.Context, name string, fn func(icache.V1)) error
+}
+
+// CacheProvider returns compiled processors in the same way as ProcProvider,
+// where cache resources are populated before the processors are constructed
+// and remain accessible once they have been executed.
+type CacheProvider interface {
+	ProvideWithCaches(jsonPtr string, environment map[string]string, mocks map[string]yaml.Node, preCache map[string]map[string]string) ([]iprocessor.V1, CacheAccessor, error)
+}
+
 // ExecuteFrom executes a test case from the perspective of a given directory,
 // which is used for obtaining relative condition file imports.
 func (c *Case) ExecuteFrom(dir string, provider ProcProvider) (failures []CaseFailure, err error) {
+	return c.executeFrom(dir, provider, false)
+}
+
+// UpdateFrom executes a test case from the perspective of a given directory in
+// the same way as ExecuteFrom, but rather than comparing the output of the
+// case against its golden files they are rewritten with the output.
+func (c *Case) UpdateFrom(dir string, provider ProcProvider) (failures []CaseFailure, err error) {
+	return c.executeFrom(dir, provider, true)
+}
+
+func (c *Case) executeFrom(dir string, provider ProcProvider, update bool) (failures []CaseFailure, err error) {
 	var procSet []iprocessor.V1
+	var caches CacheAccessor
 	if c.TargetMapping != "" {
+		if len(c.PreCache) > 0 || len(c.PostCache) > 0 {
+			return nil, errors.New("pre_cache and post_cache are not supported when testing a target_mapping")
+		}
 		if procSet, err = provider.ProvideBloblang(c.TargetMapping); err != nil {
 			return nil, fmt.Errorf("failed to initialise Bloblang mapping '%v': %v", c.TargetMapping, err)
 		}
+	} else if len(c.PreCache) > 0 || len(c.PostCache) > 0 {
+		cacheProvider, ok := provider.(CacheProvider)
+		if !ok {
+			return nil, errors.New("pre_cache and post_cache are not supported by this test provider")
+		}
+		if procSet, caches, err = cacheProvider.ProvideWithCaches(c.TargetProcessors, c.Environment, c.Mocks, c.PreCache); err != nil {
+			return nil, fmt.Errorf("failed to initialise processors '%v': %v", c.TargetProcessors, err)
+		}
 	} else {
 		if procSet, err = provider.Provide(c.TargetProcessors, c.Environment, c.Mocks); err != nil {
 			return nil, fmt.Errorf("failed to initialise processors '%v': %v", c.TargetProcessors, err)
@@ -184,16 +259,136 @@ func (c *Case) ExecuteFrom(dir string, provider ProcProvider) (failures []CaseFa
 		reportFailure(fmt.Sprintf("processors resulted in error: %v", result))
 	}
 
-	if lExp, lAct := len(c.OutputBatches), len(outputBatches); lAct < lExp {
+	// When output mocks, golden files or cache assertions are used the
+	// results of the processors are only checked against conditions if output
+	// batches are also specified.
+	if (len(c.OutputMocks) == 0 && len(c.OutputFiles) == 0 && len(c.PostCache) == 0) || len(c.OutputBatches) > 0 {
+		checkBatches(dir, c.OutputBatches, outputBatches, reportFailure)
+	}
+	if len(c.OutputFiles) > 0 {
+		checkFiles(dir, c.OutputFiles, outputBatches, update, reportFailure)
+	}
+	if len(c.PostCache) > 0 {
+		if err := checkCaches(caches, c.PostCache, reportFailure); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.OutputMocks) > 0 {
+		captured, err := c.executeOutput(provider, outputBatches, reportFailure)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(c.OutputCaptures))
+		for name := range c.OutputCaptures {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, exists := captured[name]; !exists {
+				return nil, fmt.Errorf("output captures '%v' does not match the capture name of an output mock", name)
+			}
+			checkBatches(dir, c.OutputCaptures[name], captured[name], func(reason string) {
+				reportFailure(fmt.Sprintf("output mock %v: %v", name, reason))
+			})
+		}
+	} else if len(c.OutputCaptures) > 0 {
+		return nil, errors.New("output captures require at least one output mock")
+	}
+	return
+}
+
+// executeOutput writes batches to the target output of the case and returns
+// the batches received by each output mock, keyed by capture name.
+func (c *Case) executeOutput(provider ProcProvider, batches []message.Batch, reportFailure func(string)) (map[string][]message.Batch, error) {
+	outProvider, ok := provider.(OutputProvider)
+	if !ok {
+		return nil, errors.New("output mocks are not supported by this test provider")
+	}
+
+	out, captures, err := outProvider.ProvideOutput(c.TargetOutput, c.Environment, c.Mocks, c.OutputMocks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise output '%v': %v", c.TargetOutput, err)
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), outputTimeout)
+	defer done()
+
+	var capturedMut sync.Mutex
+	captured := make(map[string][]message.Batch, len(captures))
+	for name, tranChan := range captures {
+		captured[name] = nil
+		go func(name string, tranChan <-chan message.Transaction) {
+			for {
+				select {
+				case tran, open := <-tranChan:
+					if !open {
+						return
+					}
+					capturedMut.Lock()
+					captured[name] = append(captured[name], tran.Payload.ShallowCopy())
+					capturedMut.Unlock()
+					_ = tran.Ack(ctx, nil)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(name, tranChan)
+	}
+
+	tranChan := make(chan message.Transaction)
+	if err := out.Consume(tranChan); err != nil {
+		return nil, fmt.Errorf("failed to start output '%v': %v", c.TargetOutput, err)
+	}
+	defer func() {
+		close(tranChan)
+		out.TriggerCloseNow()
+		_ = out.WaitForClose(ctx)
+	}()
+
+	// Batches are written one at a time and in order, where each write is
+	// complete once the output has acknowledged it, and therefore once the
+	// output mocks have received it.
+	for i, b := range batches {
+		resChan := make(chan error, 1)
+		select {
+		case tranChan <- message.NewTransaction(b, resChan):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out writing batch %v to output '%v'", i, c.TargetOutput)
+		}
+		select {
+		case err := <-resChan:
+			if err != nil {
+				reportFailure(fmt.Sprintf("output resulted in error for batch %v: %v", i, err))
+			}
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out writing batch %v to output '%v'", i, c.TargetOutput)
+		}
+	}
+
+	capturedMut.Lock()
+	defer capturedMut.Unlock()
+	res := make(map[string][]message.Batch, len(captured))
+	for k, v := range captured {
+		res[k] = v
+	}
+	return res, nil
+}
+
+// checkBatches reports failures for each batch that doesn't match its expected
+// conditions.
+func checkBatches(dir string, expected [][]ConditionsMap, actual []message.Batch, reportFailure func(string)) {
+	if lExp, lAct := len(expected), len(actual); lAct < lExp {
 		reportFailure(fmt.Sprintf("wrong batch count, expected %v, got %v", lExp, lAct))
 	}
 
-	for i, v := range outputBatches {
-		if len(c.OutputBatches) <= i {
+	for i, v := range actual {
+		if len(expected) <= i {
 			reportFailure(fmt.Sprintf("unexpected batch: %s", message.GetAllBytes(v)))
 			continue
 		}
-		expectedBatch := c.OutputBatches[i]
+		expectedBatch := expected[i]
 		if lExp, lAct := len(expectedBatch), v.Len(); lExp != lAct {
 			reportFailure(fmt.Sprintf("mismatch of output batch %v message counts, expected %v, got %v", i, lExp, lAct))
 		}
@@ -212,5 +407,95 @@ func (c *Case) ExecuteFrom(dir string, provider ProcProvider) (failures []CaseFa
 			return nil
 		})
 	}
-	return
+}
+
+// checkCaches reports failures for each cache key that doesn't match its
+// expected value, where a nil value expects the key to not exist.
+func checkCaches(caches CacheAccessor, expected map[string]map[string]*string, reportFailure func(string)) error {
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ctx := context.Background()
+	for _, name := range names {
+		keys := make([]string, 0, len(expected[name]))
+		for k := range expected[name] {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		if err := caches.AccessCache(ctx, name, func(c icache.V1) {
+			for _, k := range keys {
+				exp := expected[name][k]
+				act, err := c.Get(ctx, k)
+				switch {
+				case exp == nil && errors.Is(err, component.ErrKeyNotFound):
+				case exp == nil && err == nil:
+					reportFailure(fmt.Sprintf("cache %v key %v: expected key to not exist\n  received: %v", name, k, red(string(act))))
+				case err != nil:
+					reportFailure(fmt.Sprintf("cache %v key %v: %v", name, k, err))
+				case *exp != string(act):
+					reportFailure(fmt.Sprintf("cache %v key %v: content mismatch\n  expected: %v\n  received: %v", name, k, blue(*exp), red(string(act))))
+				}
+			}
+		}); err != nil {
+			return fmt.Errorf("failed to access post_cache resource '%v': %v", name, err)
+		}
+	}
+	return nil
+}
+
+// checkFiles reports failures for each message that doesn't match the contents
+// of its golden file. When update is true the golden files are instead
+// rewritten with the contents of the messages.
+func checkFiles(dir string, expected [][]string, actual []message.Batch, update bool, reportFailure func(string)) {
+	if lExp, lAct := len(expected), len(actual); lAct < lExp {
+		reportFailure(fmt.Sprintf("wrong batch count, expected %v, got %v", lExp, lAct))
+	}
+
+	for i, v := range actual {
+		if len(expected) <= i {
+			reportFailure(fmt.Sprintf("unexpected batch: %s", message.GetAllBytes(v)))
+			continue
+		}
+		expectedBatch := expected[i]
+		if lExp, lAct := len(expectedBatch), v.Len(); lExp != lAct {
+			reportFailure(fmt.Sprintf("mismatch of output batch %v message counts, expected %v, got %v", i, lExp, lAct))
+		}
+		_ = v.Iter(func(i2 int, part *message.Part) error {
+			if len(expectedBatch) <= i2 {
+				reportFailure(fmt.Sprintf("unexpected message from batch %v: %s", i, part.AsBytes()))
+				return nil
+			}
+			var err error
+			if update {
+				err = updateGoldenFile(dir, expectedBatch[i2], part)
+			} else {
+				err = FileEqualsCondition(expectedBatch[i2]).checkFrom(dir, part)
+			}
+			if err != nil {
+				reportFailure(fmt.Sprintf("batch %v message %v: %v", i, i2, err))
+			}
+			return nil
+		})
+	}
+}
+
+// updateGoldenFile writes the contents of a message to a golden file, creating
+// it and its parent directories if necessary. Files that already match the
+// message are left untouched.
+func updateGoldenFile(dir, path string, p *message.Part) error {
+	relPath := filepath.Join(dir, path)
+	if content, err := ifs.ReadFile(ifs.OS(), relPath); err == nil && bytes.Equal(content, p.AsBytes()) {
+		return nil
+	}
+	if err := ifs.OS().MkdirAll(filepath.Dir(relPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create golden file directory: %w", err)
+	}
+	if err := ifs.WriteFile(ifs.OS(), relPath, p.AsBytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to update golden file: %w", err)
+	}
+	return nil
 }
@@ -329,6 +329,54 @@ output_batches:
 	}, fails)
 }
 
+func TestFileJSONAndBinaryCaseInputs(t *testing.T) {
+	color.NoColor = true
+
+	provider := mockProvider{}
+	procConf := processor.NewConfig()
+
+	procConf.Type = "bloblang"
+	procConf.Bloblang = `root = content().length()`
+	proc, err := mock.NewManager().NewProcessor(procConf)
+	require.NoError(t, err)
+
+	provider["/pipeline/processors"] = []processor.V1{proc}
+
+	tmpDir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "fixtures"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "fixtures", "doc.json"), []byte(`{"foo":"bar"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "fixtures", "bad.json"), []byte(`{"foo":`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "fixtures", "msg.bin"), []byte{0x00, 0xff, 0x0a, 0x80}, 0o644))
+
+	c := test.NewCase()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: fixtures
+input_batch:
+  - file_json: ./fixtures/doc.json
+  - file_binary: ./fixtures/msg.bin
+output_batches:
+-
+  - content_equals: "13"
+  - content_equals: "4"
+`), &c))
+
+	fails, err := c.ExecuteFrom(tmpDir, provider)
+	require.NoError(t, err)
+	assert.Equal(t, []test.CaseFailure(nil), fails)
+
+	c = test.NewCase()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: bad json
+input_batch:
+  - file_json: ./fixtures/bad.json
+`), &c))
+
+	_, err = c.ExecuteFrom(tmpDir, provider)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "file './fixtures/bad.json' does not contain a valid JSON document")
+}
+
 func TestFileCaseConditions(t *testing.T) {
 	color.NoColor = true
 
@@ -387,3 +435,90 @@ output_batches:
 		},
 	}, fails)
 }
+
+func TestCaseOutputFiles(t *testing.T) {
+	color.NoColor = true
+
+	provider := mockProvider{}
+	procConf := processor.NewConfig()
+
+	procConf.Type = "bloblang"
+	procConf.Bloblang = `root = content().uppercase()`
+	proc, err := mock.NewManager().NewProcessor(procConf)
+	require.NoError(t, err)
+
+	provider["/pipeline/processors"] = []processor.V1{proc}
+
+	tmpDir := t.TempDir()
+
+	goldenPath := filepath.Join(tmpDir, "golden", "second.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(goldenPath), 0o755))
+	require.NoError(t, os.WriteFile(goldenPath, []byte(`stale`), 0o644))
+
+	goldenCase := func() test.Case {
+		c := test.NewCase()
+		require.NoError(t, yaml.Unmarshal([]byte(`
+name: golden
+input_batch:
+  - content: foo
+  - content: bar
+output_files:
+-
+  - ./golden/first.txt
+  - ./golden/second.txt
+`), &c))
+		return c
+	}
+
+	c := goldenCase()
+	fails, err := c.ExecuteFrom(tmpDir, provider)
+	require.NoError(t, err)
+	require.Len(t, fails, 2)
+	assert.Contains(t, fails[0].Reason, "batch 0 message 0: failed to read comparison file")
+	assert.Equal(t, "batch 0 message 1: content mismatch\n  expected: stale\n  received: BAR", fails[1].Reason)
+
+	c = goldenCase()
+	fails, err = c.UpdateFrom(tmpDir, provider)
+	require.NoError(t, err)
+	assert.Equal(t, []test.CaseFailure(nil), fails)
+
+	for path, exp := range map[string]string{
+		"first.txt":  "FOO",
+		"second.txt": "BAR",
+	} {
+		content, err := os.ReadFile(filepath.Join(tmpDir, "golden", path))
+		require.NoError(t, err)
+		assert.Equal(t, exp, string(content), path)
+	}
+
+	c = goldenCase()
+	fails, err = c.ExecuteFrom(tmpDir, provider)
+	require.NoError(t, err)
+	assert.Equal(t, []test.CaseFailure(nil), fails)
+
+	c = test.NewCase()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: too few files
+input_batch:
+  - content: foo
+  - content: bar
+output_files:
+-
+  - ./golden/first.txt
+`), &c))
+
+	fails, err = c.UpdateFrom(tmpDir, provider)
+	require.NoError(t, err)
+	assert.Equal(t, []test.CaseFailure{
+		{
+			Name:     "too few files",
+			TestLine: 2,
+			Reason:   "mismatch of output batch 0 message counts, expected 1, got 2",
+		},
+		{
+			Name:     "too few files",
+			TestLine: 2,
+			Reason:   "unexpected message from batch 0: BAR",
+		},
+	}, fails)
+}
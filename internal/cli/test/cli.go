@@ -30,7 +30,17 @@ https://benthos.dev/docs/configuration/unit_testing`[1:],
 			&cli.StringFlag{
 				Name:  "log",
 				Value: "",
-				Usage: "allow components to write logs at a provided level to stdout.",
+				Usage: "allow components to write logs at a provided level to stdout, or stderr when the report format is not text.",
+			},
+			&cli.BoolFlag{
+				Name:  "update",
+				Value: false,
+				Usage: "rewrite the golden files of test cases that use output_files with their actual output.",
+			},
+			&cli.StringFlag{
+				Name:  "report-format",
+				Value: ReportFormatText,
+				Usage: "the format of the test results written to stdout, one of: text, junit, tap.",
 			},
 		},
 		Action: func(c *cli.Context) error {
@@ -44,18 +54,27 @@ https://benthos.dev/docs/configuration/unit_testing`[1:],
 				fmt.Printf("Failed to resolve resource glob pattern: %v\n", err)
 				os.Exit(1)
 			}
+			reportFormat := c.String("report-format")
+			update := c.Bool("update")
 			if logLevel := c.String("log"); len(logLevel) > 0 {
 				logConf := log.NewConfig()
 				logConf.LogLevel = logLevel
-				logger, err := log.New(os.Stdout, ifs.OS(), logConf)
+
+				// Reports other than text are written to stdout and must not
+				// be interleaved with logs.
+				logWriter := os.Stdout
+				if reportFormat != ReportFormatText {
+					logWriter = os.Stderr
+				}
+				logger, err := log.New(logWriter, ifs.OS(), logConf)
 				if err != nil {
 					fmt.Printf("Failed to init logger: %v\n", err)
 					os.Exit(1)
 				}
-				if RunAll(c.Args().Slice(), "_benthos_test", true, logger, resourcesPaths) {
+				if RunAllWithReport(c.Args().Slice(), "_benthos_test", true, update, logger, resourcesPaths, reportFormat, os.Stdout) {
 					os.Exit(0)
 				}
-			} else if RunAll(c.Args().Slice(), "_benthos_test", true, log.Noop(), resourcesPaths) {
+			} else if RunAllWithReport(c.Args().Slice(), "_benthos_test", true, update, log.Noop(), resourcesPaths, reportFormat, os.Stdout) {
 				os.Exit(0)
 			}
 			os.Exit(1)
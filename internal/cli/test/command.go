@@ -3,6 +3,7 @@ package test
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -114,81 +115,124 @@ func lintTarget(path, testSuffix string) ([]docs.Lint, error) {
 // a config file, a config files test definition file, a directory, or the
 // wildcard pattern './...'.
 func RunAll(paths []string, testSuffix string, lint bool, logger log.Modular, resourcesPaths []string) bool {
+	return RunAllWithReport(paths, testSuffix, lint, false, logger, resourcesPaths, ReportFormatText, os.Stdout)
+}
+
+// RunAllWithReport executes the test command for a slice of paths and writes
+// the results to a writer in a given report format. When update is true the
+// golden files of test cases are rewritten with their output rather than
+// being compared against it.
+func RunAllWithReport(paths []string, testSuffix string, lint, update bool, logger log.Modular, resourcesPaths []string, reportFormat string, w io.Writer) bool {
+	var writeReport func(io.Writer, []targetResult) error
+	switch reportFormat {
+	case ReportFormatText:
+	case ReportFormatJUnit:
+		writeReport = writeJUnitReport
+	case ReportFormatTAP:
+		writeReport = writeTAPReport
+	default:
+		fmt.Fprintf(os.Stderr, "Report format not recognised: %v\n", reportFormat)
+		return false
+	}
+
 	targets, err := GetTestTargets(paths, testSuffix)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to obtain test targets: %v\n", err)
 		return false
 	}
 	if len(targets) == 0 {
-		fmt.Printf("%v\n", yellow("No tests were found"))
+		if writeReport != nil {
+			fmt.Fprintln(os.Stderr, "No tests were found")
+		} else {
+			fmt.Fprintf(w, "%v\n", yellow("No tests were found"))
+		}
 		return false
 	}
 
-	type failedTarget struct {
-		target string
-		lints  []docs.Lint
-		cases  []CaseFailure
-	}
-	fails := []failedTarget{}
-
 	targetPaths := make([]string, 0, len(targets))
 	for k := range targets {
 		targetPaths = append(targetPaths, k)
 	}
 	sort.Strings(targetPaths)
 
+	results := make([]targetResult, 0, len(targetPaths))
 	for _, target := range targetPaths {
-		var lints []docs.Lint
-		var failCases []CaseFailure
+		res := targetResult{target: target}
 		if lint {
-			if lints, err = lintTarget(target, testSuffix); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to execute test target '%v': %v\n", target, err)
+			res.lints, res.err = lintTarget(target, testSuffix)
+		}
+		if res.err == nil {
+			executeCases := targets[target].ExecuteCases
+			if update {
+				executeCases = targets[target].UpdateCases
+			}
+			res.cases, res.err = executeCases(target, resourcesPaths, logger)
+		}
+		if res.err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to execute test target '%v': %v\n", target, res.err)
+			if writeReport == nil {
 				return false
 			}
 		}
-		if failCases, err = targets[target].Execute(target, resourcesPaths, logger); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to execute test target '%v': %v\n", target, err)
-			return false
+		results = append(results, res)
+
+		if writeReport != nil {
+			continue
 		}
-		if len(lints) > 0 || len(failCases) > 0 {
-			fails = append(fails, failedTarget{
-				target: target,
-				lints:  lints,
-				cases:  failCases,
-			})
-			fmt.Printf("Test '%v' %v\n", target, red("failed"))
+		if res.failed() {
+			fmt.Fprintf(w, "Test '%v' %v\n", target, red("failed"))
 		} else {
-			fmt.Printf("Test '%v' %v\n", target, green("succeeded"))
+			fmt.Fprintf(w, "Test '%v' %v\n", target, green("succeeded"))
+		}
+	}
+
+	succeeded := true
+	for _, res := range results {
+		if res.failed() {
+			succeeded = false
+		}
+	}
+
+	if writeReport != nil {
+		if err := writeReport(w, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write test report: %v\n", err)
+			return false
 		}
+		return succeeded
 	}
-	if len(fails) > 0 {
-		fmt.Printf("\nFailures:\n\n")
-		for i, fail := range fails {
-			if i > 0 {
-				fmt.Println("")
+
+	if !succeeded {
+		fmt.Fprintf(w, "\nFailures:\n\n")
+		first := true
+		for _, fail := range results {
+			if !fail.failed() {
+				continue
+			}
+			if !first {
+				fmt.Fprintln(w, "")
 			}
-			fmt.Printf("--- %v ---\n\n", fail.target)
+			first = false
+			fmt.Fprintf(w, "--- %v ---\n\n", fail.target)
 			for _, lint := range fail.lints {
-				fmt.Printf("Lint: %v\n", lint)
+				fmt.Fprintf(w, "Lint: %v\n", lint)
 			}
-			if len(fail.cases) > 0 {
+			if failCases := fail.failures(); len(failCases) > 0 {
 				if len(fail.lints) > 0 {
-					fmt.Println("")
+					fmt.Fprintln(w, "")
 				}
 				var namePrev string
-				for i, fail := range fail.cases {
+				for i, fail := range failCases {
 					if namePrev != fail.Name {
 						if i > 0 {
-							fmt.Println("")
+							fmt.Fprintln(w, "")
 						}
-						fmt.Printf("%v [line %v]:\n", fail.Name, fail.TestLine)
+						fmt.Fprintf(w, "%v [line %v]:\n", fail.Name, fail.TestLine)
 						namePrev = fail.Name
 					}
-					fmt.Println(fail.Reason)
+					fmt.Fprintln(w, fail.Reason)
 				}
 			}
 		}
-		return false
 	}
-	return true
+	return succeeded
 }
@@ -1,11 +1,14 @@
 package test_test
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/benthosdev/benthos/v4/internal/cli/test"
 	"github.com/benthosdev/benthos/v4/internal/log"
 )
@@ -256,3 +259,44 @@ tests:
 		t.Error("Unexpected result")
 	}
 }
+
+func TestCommandRunReportExecuteError(t *testing.T) {
+	testDir, err := initTestFiles(t, map[string]string{
+		"foo.yaml": `
+pipeline:
+  processors:
+  - bloblang: 'root = content().uppercase()'`,
+		"foo_benthos_test.yaml": `
+tests:
+  - name: example test
+    target_processors: '/pipeline/processors'
+    input_batch:
+      - content: 'example content'
+    output_batches:
+      -
+        - content_equals: EXAMPLE CONTENT`,
+		"bar.yaml": `
+pipeline:
+  processors: [`,
+		"bar_benthos_test.yaml": `
+tests:
+  - name: example test
+    target_processors: '/pipeline/processors'
+    input_batch:
+      - content: 'example content'`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	var buf bytes.Buffer
+	if test.RunAllWithReport([]string{testDir + "/..."}, "_benthos_test", true, false, log.Noop(), nil, test.ReportFormatJUnit, &buf) {
+		t.Error("Unexpected result")
+	}
+
+	report := buf.String()
+	assert.Contains(t, report, `<testsuites name="benthos" tests="2" failures="0" errors="1"`)
+	assert.Contains(t, report, `<testcase name="execute" classname="`+filepath.Join(testDir, "bar.yaml")+`"`)
+	assert.Contains(t, report, `<testcase name="example test" classname="`+filepath.Join(testDir, "foo.yaml")+`"`)
+}
@@ -3,6 +3,7 @@ package test
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/log"
 )
@@ -12,8 +13,41 @@ type Definition struct {
 	Cases []Case `yaml:"tests"`
 }
 
+// CaseResult describes the outcome of executing a single test case.
+type CaseResult struct {
+	Name     string
+	TestLine int
+	Duration time.Duration
+	Failures []CaseFailure
+}
+
 // Execute the test definition.
 func (d Definition) Execute(testFilePath string, resourcesPaths []string, logger log.Modular) ([]CaseFailure, error) {
+	results, err := d.ExecuteCases(testFilePath, resourcesPaths, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalFailures []CaseFailure
+	for _, r := range results {
+		totalFailures = append(totalFailures, r.Failures...)
+	}
+	return totalFailures, nil
+}
+
+// ExecuteCases executes the test definition and returns the result of each
+// test case.
+func (d Definition) ExecuteCases(testFilePath string, resourcesPaths []string, logger log.Modular) ([]CaseResult, error) {
+	return d.executeCases(testFilePath, resourcesPaths, logger, false)
+}
+
+// UpdateCases executes the test definition, rewriting the golden files of each
+// test case with its output, and returns the result of each test case.
+func (d Definition) UpdateCases(testFilePath string, resourcesPaths []string, logger log.Modular) ([]CaseResult, error) {
+	return d.executeCases(testFilePath, resourcesPaths, logger, true)
+}
+
+func (d Definition) executeCases(testFilePath string, resourcesPaths []string, logger log.Modular, update bool) ([]CaseResult, error) {
 	procsProvider := NewProcessorsProvider(
 		testFilePath,
 		OptAddResourcesPaths(resourcesPaths),
@@ -22,17 +56,23 @@ func (d Definition) Execute(testFilePath string, resourcesPaths []string, logger
 
 	dir := filepath.Dir(testFilePath)
 
-	var totalFailures []CaseFailure
+	results := make([]CaseResult, 0, len(d.Cases))
 	for i, c := range d.Cases {
 		cleanupEnv := setEnvironment(c.Environment)
-		failures, err := c.ExecuteFrom(dir, procsProvider)
+		started := time.Now()
+		failures, err := c.executeFrom(dir, procsProvider, update)
 		if err != nil {
 			cleanupEnv()
 			return nil, fmt.Errorf("test case %v failed: %v", i, err)
 		}
-		totalFailures = append(totalFailures, failures...)
+		results = append(results, CaseResult{
+			Name:     c.Name,
+			TestLine: c.line,
+			Duration: time.Since(started),
+			Failures: failures,
+		})
 		cleanupEnv()
 	}
 
-	return totalFailures, nil
+	return results, nil
 }
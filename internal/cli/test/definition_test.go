@@ -6,6 +6,9 @@ import (
 	"testing"
 
 	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
 
 	"github.com/benthosdev/benthos/v4/internal/cli/test"
 	"github.com/benthosdev/benthos/v4/internal/log"
@@ -163,3 +166,146 @@ pipeline:
 		t.Errorf("Mismatched fail message: %v != %v", act, exp)
 	}
 }
+
+func TestDefinitionOutputMocks(t *testing.T) {
+	color.NoColor = true
+
+	testDir, err := initTestFiles(t, map[string]string{
+		"config1.yaml": `
+pipeline:
+  processors:
+    - mapping: 'root = this.merge({"checked": true})'
+
+output:
+  switch:
+    cases:
+      - check: this.type == "order"
+        output:
+          label: orders_api
+          http_client:
+            url: http://localhost:1/orders
+            verb: POST
+      - output:
+          file:
+            path: /dev/null/nope
+          processors:
+            - mapping: 'root = content().uppercase()'
+`,
+	})
+	require.NoError(t, err)
+
+	var def test.Definition
+	require.NoError(t, yaml.Unmarshal([]byte(`
+tests:
+  - name: routes orders
+    output_mocks:
+      orders_api: orders
+      /output/switch/cases/1/output: misc
+    input_batch:
+      - json_content: { "type": "order", "id": "foo" }
+      - json_content: { "type": "refund", "id": "bar" }
+      - json_content: { "type": "order", "id": "baz" }
+    output_captures:
+      orders:
+        - - json_equals: { "type": "order", "id": "foo", "checked": true }
+          - json_equals: { "type": "order", "id": "nope", "checked": true }
+      misc:
+        - - content_equals: '{"CHECKED":TRUE,"ID":"BAR","TYPE":"REFUND"}'
+`), &def))
+
+	failures, err := def.Execute(filepath.Join(testDir, "config1.yaml"), nil, log.Noop())
+	require.NoError(t, err)
+
+	require.Len(t, failures, 1)
+	assert.Contains(t, failures[0].String(), "routes orders [line 3]: output mock orders: batch 0 message 1: json_equals: JSON content mismatch")
+}
+
+func TestDefinitionCaches(t *testing.T) {
+	color.NoColor = true
+
+	testDir, err := initTestFiles(t, map[string]string{
+		"config1.yaml": `
+cache_resources:
+  - label: foocache
+    memory: {}
+
+pipeline:
+  processors:
+    - branch:
+        processors:
+          - cache:
+              resource: foocache
+              operator: get
+              key: 'user_${! this.id }'
+        result_map: 'root.name = content().string()'
+    - cache:
+        resource: foocache
+        operator: set
+        key: 'seen_${! this.id }'
+        value: '${! this.name }'
+    - cache:
+        resource: foocache
+        operator: delete
+        key: stale
+`,
+	})
+	require.NoError(t, err)
+
+	var def test.Definition
+	require.NoError(t, yaml.Unmarshal([]byte(`
+tests:
+  - name: enriches from cache
+    pre_cache:
+      foocache:
+        user_1: alice
+        stale: old value
+    input_batch:
+      - json_content: { "id": 1 }
+    output_batches:
+      - - json_equals: { "id": 1, "name": "alice" }
+    post_cache:
+      foocache:
+        user_1: alice
+        seen_1: alice
+        stale: null
+
+  - name: bad cache assertions
+    pre_cache:
+      foocache:
+        user_2: bob
+    input_batch:
+      - json_content: { "id": 2 }
+    post_cache:
+      foocache:
+        seen_2: alice
+        user_2: null
+        user_3: carol
+`), &def))
+
+	failures, err := def.Execute(filepath.Join(testDir, "config1.yaml"), nil, log.Noop())
+	require.NoError(t, err)
+
+	var failureStrs []string
+	for _, f := range failures {
+		failureStrs = append(failureStrs, f.String())
+	}
+	assert.Equal(t, []string{
+		"bad cache assertions [line 18]: cache foocache key seen_2: content mismatch\n  expected: alice\n  received: bob",
+		"bad cache assertions [line 18]: cache foocache key user_2: expected key to not exist\n  received: bob",
+		"bad cache assertions [line 18]: cache foocache key user_3: key does not exist",
+	}, failureStrs)
+
+	require.NoError(t, yaml.Unmarshal([]byte(`
+tests:
+  - name: unknown cache
+    pre_cache:
+      barcache:
+        foo: bar
+    input_batch:
+      - content: foo
+`), &def))
+
+	_, err = def.Execute(filepath.Join(testDir, "config1.yaml"), nil, log.Noop())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to access pre_cache resource 'barcache'")
+}
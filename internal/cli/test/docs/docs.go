@@ -38,7 +38,7 @@ func ConfigSpec() docs.FieldSpec {
 		docs.FieldString(
 			"target_processors",
 			`
-A [JSON Pointer][json-pointer] that identifies the specific processors which should be executed by the test. The target can either be a single processor or an array of processors. Alternatively a resource label can be used to identify a processor.
+A [JSON Pointer][json-pointer] that identifies the specific processors which should be executed by the test. The target can either be a single processor or an array of processors. Alternatively the label of a processor can be used to identify it, which can be a processor within the target file or a processor resource of a resources file provided with the resources flag, and remains valid when processors are reordered.
 
 It is also possible to target processors in a separate file by prefixing the target with a path relative to the test file followed by a # symbol.
 `,
@@ -65,6 +65,20 @@ It is also possible to target processors in a separate file by prefixing the tar
 				},
 			},
 		).Map().Optional(),
+		docs.FieldString(
+			"target_output",
+			"A [JSON Pointer][json-pointer] or label that identifies the output to write the results of the target processors to when `output_mocks` are defined. Following the same rules as `target_processors` the output can be within a separate file.",
+			"/output",
+			"target.yaml#/output",
+		).HasDefault("/output"),
+		docs.FieldString(
+			"output_mocks",
+			"An optional map of outputs to replace with capture sinks, allowing a pipeline to be tested end to end without reaching out to external services. Keys should contain either a label or a JSON pointer of an output within the `target_output`, and values contain the name of the capture, against which the messages received can be checked with `output_captures`. When set the results of the target processors are written to the `target_output`, including its output processors and any brokers or switches.",
+			map[string]any{
+				"/output/switch/cases/0/output": "orders",
+				"dead_letters":                  "dlq",
+			},
+		).Map().Optional(),
 		docs.FieldObject(
 			"input_batch", "Define a batch of messages to feed into your test, specify either an `input_batch` or a series of `input_batches`.",
 		).Array().Optional().WithChildren(
@@ -79,6 +93,16 @@ It is also possible to target processors in a separate file by prefixing the tar
 				"Sets the raw content of the message by reading a file. The path of the file should be relative to the path of the test file.",
 				"./foo/bar.txt",
 			).Optional(),
+			docs.FieldString(
+				`file_json`,
+				"Sets the raw content of the message by reading a file that must contain a valid JSON document. The path of the file should be relative to the path of the test file.",
+				"./fixtures/msg1.json",
+			).Optional(),
+			docs.FieldString(
+				`file_binary`,
+				"Sets the raw content of the message to the exact bytes of a file, which is suitable for binary fixtures such as Avro or Protobuf encoded messages. The path of the file should be relative to the path of the test file.",
+				"./fixtures/msg1.avro",
+			).Optional(),
 			docs.FieldAnything("metadata", "A map of metadata key/values to add to the input message.").Map().Optional(),
 		),
 		docs.FieldObject(
@@ -95,6 +119,16 @@ It is also possible to target processors in a separate file by prefixing the tar
 				"Sets the raw content of the message by reading a file. The path of the file should be relative to the path of the test file.",
 				"./foo/bar.txt",
 			).Optional(),
+			docs.FieldString(
+				`file_json`,
+				"Sets the raw content of the message by reading a file that must contain a valid JSON document. The path of the file should be relative to the path of the test file.",
+				"./fixtures/msg1.json",
+			).Optional(),
+			docs.FieldString(
+				`file_binary`,
+				"Sets the raw content of the message to the exact bytes of a file, which is suitable for binary fixtures such as Avro or Protobuf encoded messages. The path of the file should be relative to the path of the test file.",
+				"./fixtures/msg1.avro",
+			).Optional(),
 			docs.FieldString("metadata", "A map of metadata key/values to add to the input message.").Map().Optional(),
 		),
 		docs.FieldObject(
@@ -142,5 +176,31 @@ It is also possible to target processors in a separate file by prefixing the tar
 				"./foo/bar.json",
 			).Optional(),
 		),
+		docs.FieldString(
+			"output_files", "An optional list of output batches, where each batch lists the paths of golden files that contain the expected raw contents of each message. The paths should be relative to the path of the test file, and the files can be rewritten from the actual output with `benthos test --update`.",
+			[]any{[]any{"./golden/out_0.json", "./golden/out_1.json"}},
+		).ArrayOfArrays().Optional(),
+		docs.FieldAnything(
+			"pre_cache", "An optional map of cache resource labels to key/value pairs that are set within the cache before the target processors are executed, allowing processors that read from caches to be tested deterministically.",
+			map[string]any{
+				"users": map[string]any{"u1": "alice", "u2": "bob"},
+			},
+		).Map().Optional(),
+		docs.FieldAnything(
+			"post_cache", "An optional map of cache resource labels to key/value pairs that are expected to be within the cache once the target processors have been executed. Keys with a `null` value are expected to not exist within the cache.",
+			map[string]any{
+				"users": map[string]any{"last_seen_u1": "2024-01-01T00:00:00Z", "u3": nil},
+			},
+		).Map().Optional(),
+		docs.FieldAnything(
+			"output_captures", "An optional map of capture names of `output_mocks` to the batches of messages they are expected to receive, where each batch is a list of conditions following the same format as `output_batches`.",
+			map[string]any{
+				"orders": []any{
+					[]any{
+						map[string]any{"json_contains": map[string]any{"status": "accepted"}},
+					},
+				},
+			},
+		).Map().Optional(),
 	)
 }
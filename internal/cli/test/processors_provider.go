@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/Jeffail/gabs/v2"
@@ -13,6 +14,8 @@ import (
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
 	"github.com/benthosdev/benthos/v4/internal/bloblang/parser"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/config"
 	"github.com/benthosdev/benthos/v4/internal/docs"
@@ -24,8 +27,9 @@ import (
 )
 
 type cachedConfig struct {
-	mgr   manager.ResourceConfig
-	procs []processor.Config
+	mgr    manager.ResourceConfig
+	procs  []processor.Config
+	output output.Config
 }
 
 // ProcessorsProvider consumes a Benthos config and, given a JSON Pointer,
@@ -72,11 +76,85 @@ func OptProcessorsProviderSetLogger(logger log.Modular) func(*ProcessorsProvider
 // targets a single processor config it will be constructed and returned as an
 // array of one element.
 func (p *ProcessorsProvider) Provide(jsonPtr string, environment map[string]string, mocks map[string]yaml.Node) ([]processor.V1, error) {
-	confs, err := p.getConfs(jsonPtr, environment, mocks)
+	confs, err := p.getConfs(jsonPtr, environment, mocks, false)
 	if err != nil {
 		return nil, err
 	}
-	return p.initProcs(confs)
+	procs, _, err := p.initProcs(confs, nil)
+	return procs, err
+}
+
+// ProvideWithCaches attempts to extract an array of processors from a Benthos
+// config in the same way as Provide, where the cache resources identified by
+// the keys of preCache are populated with its key/value pairs before the
+// processors are constructed. The resources of the processors are also
+// returned, allowing the contents of caches to be checked once the processors
+// have been executed.
+func (p *ProcessorsProvider) ProvideWithCaches(jsonPtr string, environment map[string]string, mocks map[string]yaml.Node, preCache map[string]map[string]string) ([]processor.V1, CacheAccessor, error) {
+	confs, err := p.getConfs(jsonPtr, environment, mocks, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.initProcs(confs, preCache)
+}
+
+// captureMockPipe returns the name of the inproc pipe that an output mock with
+// a given capture name writes to.
+func captureMockPipe(name string) string {
+	return "benthos_test_capture_" + name
+}
+
+// ProvideOutput attempts to extract an output from a Benthos config, where the
+// outputs identified by the keys of outputMocks (either labels or JSON
+// Pointers) are replaced with capture sinks. The transactions received by each
+// sink are returned keyed by the capture names of outputMocks, and must be
+// acknowledged by the caller.
+func (p *ProcessorsProvider) ProvideOutput(jsonPtr string, environment map[string]string, mocks map[string]yaml.Node, outputMocks map[string]string) (output.Streamed, map[string]<-chan message.Transaction, error) {
+	allMocks := make(map[string]yaml.Node, len(mocks)+len(outputMocks))
+	for k, v := range mocks {
+		allMocks[k] = v
+	}
+
+	captureNames := map[string]struct{}{}
+	for k, name := range outputMocks {
+		if _, exists := allMocks[k]; exists {
+			return nil, nil, fmt.Errorf("output mock '%v' is also defined as a mock", k)
+		}
+		if _, exists := captureNames[name]; exists {
+			return nil, nil, fmt.Errorf("capture name '%v' is used by multiple output mocks", name)
+		}
+		captureNames[name] = struct{}{}
+
+		var node yaml.Node
+		if err := node.Encode(map[string]any{"inproc": captureMockPipe(name)}); err != nil {
+			return nil, nil, err
+		}
+		allMocks[k] = node
+	}
+
+	confs, err := p.getConfs(jsonPtr, environment, allMocks, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mgr, err := manager.New(confs.mgr, manager.OptSetLogger(p.logger))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialise resources: %v", err)
+	}
+
+	out, err := mgr.NewOutput(confs.output)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialise output: %v", err)
+	}
+
+	captures := make(map[string]<-chan message.Transaction, len(captureNames))
+	for name := range captureNames {
+		if captures[name], err = mgr.GetPipe(captureMockPipe(name)); err != nil {
+			out.TriggerCloseNow()
+			return nil, nil, fmt.Errorf("output mock with capture name '%v' was not reached by the target output", name)
+		}
+	}
+	return out, captures, nil
 }
 
 // ProvideBloblang attempts to parse a Bloblang mapping and returns a processor
@@ -142,24 +220,56 @@ func (b *bloblangProc) Close(context.Context) error {
 
 //------------------------------------------------------------------------------
 
-func (p *ProcessorsProvider) initProcs(confs cachedConfig) ([]processor.V1, error) {
+func (p *ProcessorsProvider) initProcs(confs cachedConfig, preCache map[string]map[string]string) ([]processor.V1, CacheAccessor, error) {
 	mgr, err := manager.New(confs.mgr, manager.OptSetLogger(p.logger))
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialise resources: %v", err)
+		return nil, nil, fmt.Errorf("failed to initialise resources: %v", err)
+	}
+
+	if err := seedCaches(mgr, preCache); err != nil {
+		return nil, nil, err
 	}
 
 	procs := make([]processor.V1, len(confs.procs))
 	for i, conf := range confs.procs {
 		if procs[i], err = mgr.NewProcessor(conf); err != nil {
-			return nil, fmt.Errorf("failed to initialise processor index '%v': %v", i, err)
+			return nil, nil, fmt.Errorf("failed to initialise processor index '%v': %v", i, err)
+		}
+	}
+	return procs, mgr, nil
+}
+
+// seedCaches sets the key/value pairs of preCache within the cache resources
+// identified by its keys.
+func seedCaches(mgr CacheAccessor, preCache map[string]map[string]string) error {
+	names := make([]string, 0, len(preCache))
+	for name := range preCache {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ctx := context.Background()
+	for _, name := range names {
+		var setErr error
+		if err := mgr.AccessCache(ctx, name, func(c cache.V1) {
+			for k, v := range preCache[name] {
+				if setErr = c.Set(ctx, k, []byte(v), nil); setErr != nil {
+					return
+				}
+			}
+		}); err != nil {
+			return fmt.Errorf("failed to access pre_cache resource '%v': %v", name, err)
+		}
+		if setErr != nil {
+			return fmt.Errorf("failed to populate pre_cache resource '%v': %v", name, setErr)
 		}
 	}
-	return procs, nil
+	return nil
 }
 
-func confTargetID(jsonPtr string, environment map[string]string, mocks map[string]yaml.Node) string {
+func confTargetID(jsonPtr string, environment map[string]string, mocks map[string]yaml.Node, isOutput bool) string {
 	mocksBytes, _ := yaml.Marshal(mocks)
-	return fmt.Sprintf("%v-%v-%s", jsonPtr, environment, mocksBytes)
+	return fmt.Sprintf("%v-%v-%s-%v", jsonPtr, environment, mocksBytes, isOutput)
 }
 
 func setEnvironment(vars map[string]string) func() {
@@ -226,9 +336,33 @@ func setMock(confSpec docs.FieldSpecs, root, mock *yaml.Node, pathSlice ...strin
 		labelPull.Label = nil
 	}
 
+	// Mocked outputs retain their processors unless the mock defines its own.
+	procsPull := struct {
+		Processors []any `yaml:"processors"`
+	}{}
+	if err := mock.Decode(&procsPull); err != nil {
+		return fmt.Errorf("decode mock processors: %w", err)
+	}
+	if procsPull.Processors == nil {
+		if targetNode, _ := docs.GetYAMLPath(root, pathSlice...); targetNode != nil {
+			_ = targetNode.Decode(&procsPull)
+		}
+	} else {
+		procsPull.Processors = nil
+	}
+
 	if err := confSpec.SetYAMLPath(docs.DeprecatedProvider, root, mock, pathSlice...); err != nil {
 		return err
 	}
+	if procsPull.Processors != nil {
+		var procsNode yaml.Node
+		if err := procsNode.Encode(procsPull.Processors); err != nil {
+			return fmt.Errorf("encode mock processors: %w", err)
+		}
+		if err := confSpec.SetYAMLPath(docs.DeprecatedProvider, root, &procsNode, append(pathSlice, "processors")...); err != nil {
+			return fmt.Errorf("set mock processors: %w", err)
+		}
+	}
 	if labelPull.Label != nil {
 		var labelNode yaml.Node
 		if err := labelNode.Encode(labelPull.Label); err != nil {
@@ -241,8 +375,8 @@ func setMock(confSpec docs.FieldSpecs, root, mock *yaml.Node, pathSlice ...strin
 	return nil
 }
 
-func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]string, mocks map[string]yaml.Node) (cachedConfig, error) {
-	cacheKey := confTargetID(jsonPtr, environment, mocks)
+func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]string, mocks map[string]yaml.Node, isOutput bool) (cachedConfig, error) {
+	cacheKey := confTargetID(jsonPtr, environment, mocks, isOutput)
 
 	confs, exists := p.cachedConfigs[cacheKey]
 	if exists {
@@ -326,13 +460,20 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 		return confs, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
 	}
 
+	resourceRoots := make([]*yaml.Node, 0, len(p.resourcesPaths))
 	for _, path := range p.resourcesPaths {
 		resourceBytes, _, _, err := config.ReadFileEnvSwap(ifs.OS(), path, envVarLookup)
 		if err != nil {
 			return confs, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
 		}
+		resourceRoot := &yaml.Node{}
+		if err = yaml.Unmarshal(resourceBytes, resourceRoot); err != nil {
+			return confs, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
+		}
+		resourceRoots = append(resourceRoots, resourceRoot)
+
 		extraMgrWrapper := manager.NewResourceConfig()
-		if err = yaml.Unmarshal(resourceBytes, &extraMgrWrapper); err != nil {
+		if err = resourceRoot.Decode(&extraMgrWrapper); err != nil {
 			return confs, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
 		}
 		if err = mgrWrapper.AddFrom(&extraMgrWrapper); err != nil {
@@ -351,7 +492,19 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 			confSpec.YAMLLabelsToPaths(docs.DeprecatedProvider, root, labelsToPaths, nil)
 		}
 		if pathSlice, exists = labelsToPaths[procPath]; !exists {
-			return confs, fmt.Errorf("target for label '%v' failed as the label was not found in the test target file, it is not currently possible to target resources imported separate to the test file", procPath)
+			// Fall back to processors of the resources files, which allows
+			// processor resources to be targeted by their label.
+			for _, resourceRoot := range resourceRoots {
+				resourceLabelsToPaths := map[string][]string{}
+				confSpec.YAMLLabelsToPaths(docs.DeprecatedProvider, resourceRoot, resourceLabelsToPaths, nil)
+				if pathSlice, exists = resourceLabelsToPaths[procPath]; exists {
+					root = resourceRoot
+					break
+				}
+			}
+		}
+		if !exists {
+			return confs, fmt.Errorf("target for label '%v' failed as the label was not found in the test target file or resources files", procPath)
 		}
 	}
 
@@ -359,7 +512,12 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 		return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
 	}
 
-	if root.Kind == yaml.SequenceNode {
+	if isOutput {
+		confs.output = output.NewConfig()
+		if err = root.Decode(&confs.output); err != nil {
+			return confs, fmt.Errorf("failed to resolve case output from '%v': %v", targetPath, err)
+		}
+	} else if root.Kind == yaml.SequenceNode {
 		if err = root.Decode(&confs.procs); err != nil {
 			return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
 		}
@@ -161,6 +161,48 @@ pipeline:
 	}
 }
 
+func TestProcessorsProviderResourceLabel(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	files := map[string]string{
+		"resources1.yaml": `
+processor_resources:
+  - label: upperproc
+    mapping: 'root = content().uppercase()'
+`,
+		"config1.yaml": `
+pipeline:
+  processors:
+  - resource: upperproc
+  - label: fooproc
+    mapping: 'root = content() + " foo"'
+`,
+	}
+
+	testDir, err := initTestFiles(t, files)
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	provider := test.NewProcessorsProvider(
+		filepath.Join(testDir, "config1.yaml"),
+		test.OptAddResourcesPaths([]string{
+			filepath.Join(testDir, "resources1.yaml"),
+		}),
+	)
+
+	procs, err := provider.Provide("upperproc", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, procs, 1)
+
+	msgs, res := processor.ExecuteAll(tCtx, procs, message.QuickBatch([][]byte{[]byte("hello world")}))
+	require.NoError(t, res)
+	assert.Equal(t, "HELLO WORLD", string(msgs[0].Get(0).AsBytes()))
+
+	_, err = provider.Provide("nope", nil, nil)
+	require.EqualError(t, err, "target for label 'nope' failed as the label was not found in the test target file or resources files")
+}
+
 func TestProcessorsProviderMocks(t *testing.T) {
 	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
 	defer done()
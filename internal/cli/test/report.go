@@ -0,0 +1,270 @@
+package test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/docs"
+)
+
+// Report formats supported by the test command.
+const (
+	ReportFormatText  = "text"
+	ReportFormatJUnit = "junit"
+	ReportFormatTAP   = "tap"
+)
+
+// targetResult describes the outcome of linting and executing the test
+// definition of a config, where err is set when the config could not be
+// linted or its test cases could not be executed.
+type targetResult struct {
+	target string
+	lints  []docs.Lint
+	cases  []CaseResult
+	err    error
+}
+
+func (t targetResult) failures() []CaseFailure {
+	var failures []CaseFailure
+	for _, c := range t.cases {
+		failures = append(failures, c.Failures...)
+	}
+	return failures
+}
+
+func (t targetResult) failed() bool {
+	return t.err != nil || len(t.lints) > 0 || len(t.failures()) > 0
+}
+
+func (t targetResult) duration() (d time.Duration) {
+	for _, c := range t.cases {
+		d += c.Duration
+	}
+	return
+}
+
+func lintsReason(lints []docs.Lint) string {
+	reasons := make([]string, len(lints))
+	for i, l := range lints {
+		reasons[i] = l.Error()
+	}
+	return strings.Join(reasons, "\n")
+}
+
+func failuresReason(failures []CaseFailure) string {
+	reasons := make([]string, len(failures))
+	for i, f := range failures {
+		reasons[i] = f.Reason
+	}
+	return strings.Join(reasons, "\n")
+}
+
+//------------------------------------------------------------------------------
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure"`
+	Error     *junitFailure `xml:"error"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func newJUnitFailure(reason string) *junitFailure {
+	message, _, _ := strings.Cut(reason, "\n")
+	return &junitFailure{Message: message, Contents: reason}
+}
+
+// writeJUnitReport writes test results as a JUnit XML report, where each
+// config is a test suite and each of its test cases is a test case. Linting
+// errors of a config are reported as a failed test case named lint, and
+// configs that could not be executed are reported as an errored test case
+// named execute.
+func writeJUnitReport(w io.Writer, results []targetResult) error {
+	report := junitTestSuites{Name: "benthos"}
+
+	var total time.Duration
+	for _, res := range results {
+		suite := junitTestSuite{
+			Name: res.target,
+			Time: junitSeconds(res.duration()),
+		}
+		if len(res.lints) > 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      "lint",
+				ClassName: res.target,
+				File:      res.target,
+				Time:      junitSeconds(0),
+				Failure:   newJUnitFailure(lintsReason(res.lints)),
+			})
+		}
+		if res.err != nil {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      "execute",
+				ClassName: res.target,
+				File:      res.target,
+				Time:      junitSeconds(0),
+				Error:     newJUnitFailure(res.err.Error()),
+			})
+		}
+		for _, c := range res.cases {
+			tc := junitTestCase{
+				Name:      c.Name,
+				ClassName: res.target,
+				File:      res.target,
+				Line:      c.TestLine,
+				Time:      junitSeconds(c.Duration),
+			}
+			if len(c.Failures) > 0 {
+				tc.Failure = newJUnitFailure(failuresReason(c.Failures))
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		for _, tc := range suite.Cases {
+			if tc.Failure != nil {
+				suite.Failures++
+			}
+			if tc.Error != nil {
+				suite.Errors++
+			}
+		}
+		suite.Tests = len(suite.Cases)
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		total += res.duration()
+		report.Suites = append(report.Suites, suite)
+	}
+	report.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+//------------------------------------------------------------------------------
+
+type tapDiagnostic struct {
+	Message    string  `yaml:"message"`
+	File       string  `yaml:"file"`
+	Line       int     `yaml:"line,omitempty"`
+	DurationMS float64 `yaml:"duration_ms"`
+}
+
+func tapDescription(target, name string) string {
+	desc := target + " :: " + name
+	return strings.NewReplacer(`\`, `\\`, "#", `\#`, "\n", " ").Replace(desc)
+}
+
+// writeTAPReport writes test results as a TAP version 13 report with a test
+// point for each test case, where failed test points are followed by a YAML
+// diagnostic block describing the failures. Linting errors of a config are
+// reported as a failed test point named lint, and configs that could not be
+// executed are reported as a failed test point named execute.
+func writeTAPReport(w io.Writer, results []targetResult) error {
+	var b strings.Builder
+
+	total := 0
+	writePoint := func(desc string, d time.Duration, diag *tapDiagnostic) error {
+		total++
+		if diag == nil {
+			fmt.Fprintf(&b, "ok %v - %v # time=%.3fms\n", total, desc, float64(d)/float64(time.Millisecond))
+			return nil
+		}
+		fmt.Fprintf(&b, "not ok %v - %v\n", total, desc)
+		var diagBuf bytes.Buffer
+		enc := yaml.NewEncoder(&diagBuf)
+		enc.SetIndent(2)
+		if err := enc.Encode(diag); err != nil {
+			return err
+		}
+		b.WriteString("  ---\n")
+		for _, l := range strings.Split(strings.TrimSuffix(diagBuf.String(), "\n"), "\n") {
+			b.WriteString("  " + l + "\n")
+		}
+		b.WriteString("  ...\n")
+		return nil
+	}
+
+	for _, res := range results {
+		if len(res.lints) > 0 {
+			if err := writePoint(tapDescription(res.target, "lint"), 0, &tapDiagnostic{
+				Message: lintsReason(res.lints),
+				File:    res.target,
+			}); err != nil {
+				return err
+			}
+		}
+		if res.err != nil {
+			if err := writePoint(tapDescription(res.target, "execute"), 0, &tapDiagnostic{
+				Message: res.err.Error(),
+				File:    res.target,
+			}); err != nil {
+				return err
+			}
+		}
+		for _, c := range res.cases {
+			var diag *tapDiagnostic
+			if len(c.Failures) > 0 {
+				diag = &tapDiagnostic{
+					Message:    failuresReason(c.Failures),
+					File:       res.target,
+					Line:       c.TestLine,
+					DurationMS: float64(c.Duration) / float64(time.Millisecond),
+				}
+			}
+			if err := writePoint(tapDescription(res.target, c.Name), c.Duration, diag); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "TAP version 13\n1..%v\n", total); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,99 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/docs"
+)
+
+func testReportResults() []targetResult {
+	return []targetResult{
+		{
+			target: "foo.yaml",
+			cases: []CaseResult{
+				{Name: "passes", TestLine: 3, Duration: time.Millisecond * 12},
+				{
+					Name: "fails #1", TestLine: 9, Duration: time.Millisecond * 1500,
+					Failures: []CaseFailure{
+						{Name: "fails #1", TestLine: 9, Reason: "batch 0 message 0: content_equals: content mismatch"},
+						{Name: "fails #1", TestLine: 9, Reason: "wrong batch count, expected 2, got 1"},
+					},
+				},
+			},
+		},
+		{
+			target: "bar.yaml",
+			lints:  []docs.Lint{docs.NewLintError(4, docs.LintUnknown, errors.New("field nope not recognised"))},
+			cases: []CaseResult{
+				{Name: "passes", TestLine: 2, Duration: time.Millisecond},
+			},
+		},
+		{
+			target: "baz.yaml",
+			err:    errors.New("failed to create resources: nope"),
+		},
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeJUnitReport(&buf, testReportResults()))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="benthos" tests="5" failures="2" errors="1" time="1.513">
+  <testsuite name="foo.yaml" tests="2" failures="1" errors="0" time="1.512">
+    <testcase name="passes" classname="foo.yaml" file="foo.yaml" line="3" time="0.012"></testcase>
+    <testcase name="fails #1" classname="foo.yaml" file="foo.yaml" line="9" time="1.500">
+      <failure message="batch 0 message 0: content_equals: content mismatch">batch 0 message 0: content_equals: content mismatch&#xA;wrong batch count, expected 2, got 1</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="bar.yaml" tests="2" failures="1" errors="0" time="0.001">
+    <testcase name="lint" classname="bar.yaml" file="bar.yaml" time="0.000">
+      <failure message="(4,1) field nope not recognised">(4,1) field nope not recognised</failure>
+    </testcase>
+    <testcase name="passes" classname="bar.yaml" file="bar.yaml" line="2" time="0.001"></testcase>
+  </testsuite>
+  <testsuite name="baz.yaml" tests="1" failures="0" errors="1" time="0.000">
+    <testcase name="execute" classname="baz.yaml" file="baz.yaml" time="0.000">
+      <error message="failed to create resources: nope">failed to create resources: nope</error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func TestTAPReport(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeTAPReport(&buf, testReportResults()))
+	assert.Equal(t, `TAP version 13
+1..5
+ok 1 - foo.yaml :: passes # time=12.000ms
+not ok 2 - foo.yaml :: fails \#1
+  ---
+  message: |-
+    batch 0 message 0: content_equals: content mismatch
+    wrong batch count, expected 2, got 1
+  file: foo.yaml
+  line: 9
+  duration_ms: 1500
+  ...
+not ok 3 - bar.yaml :: lint
+  ---
+  message: (4,1) field nope not recognised
+  file: bar.yaml
+  duration_ms: 0
+  ...
+ok 4 - bar.yaml :: passes # time=1.000ms
+not ok 5 - baz.yaml :: execute
+  ---
+  message: 'failed to create resources: nope'
+  file: baz.yaml
+  duration_ms: 0
+  ...
+`, buf.String())
+}
@@ -0,0 +1,254 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// autoCodecMetaKey is the metadata key added to messages consumed with the
+// auto codec, containing the codec that was selected for the source.
+const autoCodecMetaKey = "auto_codec"
+
+// The number of bytes peeked from a source in order to detect its contents.
+const autoSniffSize = 4096
+
+func autoCodec(conf ReaderConfig) ReaderConstructor {
+	return func(path string, r io.ReadCloser, fn ReaderAckFn) (Reader, error) {
+		codec := autoCodecFromPath(path)
+
+		var rc io.ReadCloser = r
+		if codec == "" {
+			br := bufio.NewReaderSize(r, autoSniffSize)
+			sample, err := br.Peek(autoSniffSize)
+			if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+				return nil, fmt.Errorf("failed to infer codec: %w", err)
+			}
+			codec = autoCodecFromSample(sample)
+			rc = ioReadCloserWrapper{Reader: br, underlying: r}
+		}
+
+		ctor, err := GetReader(codec, conf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to infer codec: %v", err)
+		}
+		rdr, err := ctor(path, rc, fn)
+		if err != nil {
+			return nil, err
+		}
+		return &autoMetaReader{r: rdr, codec: codec}, nil
+	}
+}
+
+// autoCodecFromPath attempts to derive a codec from the extension of a path,
+// returning an empty string when the extension is not recognised.
+func autoCodecFromPath(path string) string {
+	if strings.HasSuffix(path, ".tar.gzip") || strings.HasSuffix(path, ".tar.gz") {
+		return "gzip/tar"
+	}
+	if strings.HasSuffix(path, ".csv.gzip") || strings.HasSuffix(path, ".csv.gz") {
+		return "gzip/csv"
+	}
+	switch filepath.Ext(path) {
+	case ".avro":
+		return "avro-ocf"
+	case ".csv":
+		return "csv"
+	case ".tar":
+		return "tar"
+	case ".tgz":
+		return "gzip/tar"
+	case ".parquet":
+		if _, exists := getPluginReader("parquet"); exists {
+			return "parquet"
+		}
+	}
+	return ""
+}
+
+// autoCodecFromSample derives a codec from the leading bytes of a source by
+// detecting compression magic bytes and then the structure of the
+// (decompressed) contents.
+func autoCodecFromSample(sample []byte) string {
+	var prefix string
+	complete := len(sample) < autoSniffSize
+	for _, c := range autoCompressions {
+		if !bytes.HasPrefix(sample, c.magic) {
+			continue
+		}
+		prefix = c.codec + "/"
+
+		// Decompress as much of the sample as we can in order to inspect
+		// the structure of the contents. The sample is very likely to be
+		// truncated and so read errors are expected.
+		dr, err := c.reader(bytes.NewReader(sample))
+		if err != nil {
+			return prefix + "all-bytes"
+		}
+		sample, err = io.ReadAll(io.LimitReader(dr, autoSniffSize))
+		complete = err == nil && len(sample) < autoSniffSize
+		break
+	}
+	return prefix + autoStructureFromSample(sample, complete)
+}
+
+var autoCompressions = []struct {
+	codec  string
+	magic  []byte
+	reader func(io.Reader) (io.Reader, error)
+}{
+	{
+		codec: "gzip",
+		magic: []byte{0x1f, 0x8b},
+		reader: func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		},
+	},
+	{
+		codec: "zstd",
+		magic: []byte{0x28, 0xb5, 0x2f, 0xfd},
+		reader: func(r io.Reader) (io.Reader, error) {
+			return zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		},
+	},
+	{
+		codec: "lz4",
+		magic: []byte{0x04, 0x22, 0x4d, 0x18},
+		reader: func(r io.Reader) (io.Reader, error) {
+			return lz4.NewReader(r), nil
+		},
+	},
+	{
+		codec: "bzip2",
+		magic: []byte("BZh"),
+		reader: func(r io.Reader) (io.Reader, error) {
+			return bzip2.NewReader(r), nil
+		},
+	},
+}
+
+var (
+	avroOCFMagic = []byte{'O', 'b', 'j', 0x01}
+	parquetMagic = []byte("PAR1")
+	tarMagic     = []byte("ustar")
+)
+
+// autoStructureFromSample derives a codec from the structure of a sample, where
+// complete indicates that the sample contains the entire source.
+func autoStructureFromSample(sample []byte, complete bool) string {
+	if bytes.HasPrefix(sample, avroOCFMagic) {
+		return "avro-ocf"
+	}
+	if bytes.HasPrefix(sample, parquetMagic) {
+		if _, exists := getPluginReader("parquet"); exists {
+			return "parquet"
+		}
+	}
+	if len(sample) >= 262 && bytes.Equal(sample[257:262], tarMagic) {
+		return "tar"
+	}
+
+	trimmed := bytes.TrimLeft(sample, " \t\r\n")
+	if len(trimmed) == 0 || !utf8.Valid(trimmedTail(trimmed)) {
+		return "all-bytes"
+	}
+
+	switch trimmed[0] {
+	case '[':
+		return "json-array"
+	case '{':
+		// Only treat the contents as newline delimited when the first line
+		// is a complete JSON document, otherwise it's likely a single
+		// document spanning multiple lines.
+		firstLine := trimmed
+		if i := bytes.IndexByte(trimmed, '\n'); i >= 0 {
+			firstLine = trimmed[:i]
+		}
+		if json.Valid(firstLine) {
+			return "lines"
+		}
+		return "all-bytes"
+	}
+
+	if looksLikeCSV(sample, complete) {
+		return "csv"
+	}
+	return "all-bytes"
+}
+
+// trimmedTail removes a potentially partial UTF-8 rune from the end of a
+// truncated sample.
+func trimmedTail(b []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(b) > 0; i++ {
+		if r, _ := utf8.DecodeLastRune(b); r != utf8.RuneError {
+			break
+		}
+		b = b[:len(b)-1]
+	}
+	return b
+}
+
+// looksLikeCSV returns true when the complete lines of a sample parse as
+// comma separated values with a consistent number of fields (more than one)
+// across at least two rows.
+func looksLikeCSV(sample []byte, complete bool) bool {
+	if !complete {
+		// Drop the final line as it may have been truncated.
+		i := bytes.LastIndexByte(sample, '\n')
+		if i < 0 {
+			return false
+		}
+		sample = sample[:i+1]
+	}
+
+	r := csv.NewReader(bytes.NewReader(sample))
+	r.FieldsPerRecord = 0
+
+	rows := 0
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil || len(record) < 2 {
+			return false
+		}
+		rows++
+	}
+	return rows >= 2
+}
+
+//------------------------------------------------------------------------------
+
+// autoMetaReader annotates each message with the codec that was selected.
+type autoMetaReader struct {
+	r     Reader
+	codec string
+}
+
+func (a *autoMetaReader) Next(ctx context.Context) ([]*message.Part, ReaderAckFn, error) {
+	parts, ackFn, err := a.r.Next(ctx)
+	for _, p := range parts {
+		p.MetaSetMut(autoCodecMetaKey, a.codec)
+	}
+	return parts, ackFn, err
+}
+
+func (a *autoMetaReader) Close(ctx context.Context) error {
+	return a.r.Close(ctx)
+}
@@ -0,0 +1,110 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoCodecFromSample(t *testing.T) {
+	gzipped := func(b []byte) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write(b)
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		return buf.Bytes()
+	}
+
+	zstded := func(b []byte) []byte {
+		var buf bytes.Buffer
+		zw, err := zstd.NewWriter(&buf)
+		require.NoError(t, err)
+		_, err = zw.Write(b)
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		return buf.Bytes()
+	}
+
+	// A large CSV document that will be truncated by the sample size.
+	var largeCSV bytes.Buffer
+	largeCSV.WriteString("id,name,value\n")
+	for largeCSV.Len() < autoSniffSize*2 {
+		largeCSV.WriteString("1,foo,bar\n")
+	}
+
+	tests := []struct {
+		name   string
+		sample []byte
+		codec  string
+	}{
+		{name: "empty", sample: nil, codec: "all-bytes"},
+		{name: "plain text", sample: []byte("hello world"), codec: "all-bytes"},
+		{name: "binary", sample: []byte{0x00, 0xff, 0xfe, 0x10}, codec: "all-bytes"},
+		{name: "json array", sample: []byte(`  [{"id":1},{"id":2}]`), codec: "json-array"},
+		{name: "ndjson", sample: []byte("{\"id\":1}\n{\"id\":2}\n"), codec: "lines"},
+		{name: "multiline json", sample: []byte("{\n  \"id\": 1\n}\n"), codec: "all-bytes"},
+		{name: "csv", sample: []byte("a,b,c\n1,2,3\n4,5,6"), codec: "csv"},
+		{name: "truncated csv", sample: largeCSV.Bytes()[:autoSniffSize], codec: "csv"},
+		{name: "inconsistent csv", sample: []byte("a,b,c\n1,2\n"), codec: "all-bytes"},
+		{name: "single column", sample: []byte("a\nb\nc\n"), codec: "all-bytes"},
+		{name: "avro", sample: []byte("Obj\x01foo"), codec: "avro-ocf"},
+		{name: "gzip ndjson", sample: gzipped([]byte("{\"id\":1}\n{\"id\":2}\n")), codec: "gzip/lines"},
+		{name: "gzip csv", sample: gzipped([]byte("a,b\n1,2\n")), codec: "gzip/csv"},
+		{name: "gzip truncated csv", sample: gzipped(largeCSV.Bytes())[:64], codec: "gzip/csv"},
+		{name: "gzip truncated text", sample: gzipped([]byte("foo,bar\nbaz"))[:24], codec: "gzip/all-bytes"},
+		{name: "zstd json array", sample: zstded([]byte(`[1,2,3]`)), codec: "zstd/json-array"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.codec, autoCodecFromSample(test.sample))
+		})
+	}
+}
+
+func TestJSONArrayReader(t *testing.T) {
+	data := []byte(` [ {"id":1}, "foo", 5.0, [1,2] ] `)
+	testReaderSuite(t, "json-array", "", data, `{"id":1}`, `"foo"`, `5.0`, `[1,2]`)
+
+	data = []byte(`[]`)
+	testReaderSuite(t, "json-array", "", data)
+}
+
+func TestAutoReaderSniffing(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte("{\"id\":1}\n{\"id\":2}\n{\"id\":3}"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	data := buf.Bytes()
+	testReaderSuite(t, "auto", "foo.json.gz", data, `{"id":1}`, `{"id":2}`, `{"id":3}`)
+
+	ctor, err := GetReader("auto", NewReaderConfig())
+	require.NoError(t, err)
+
+	r, err := ctor("foo.json.gz", noopCloser{bytes.NewReader(data), false}, func(ctx context.Context, err error) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	for {
+		parts, ackFn, err := r.Next(context.Background())
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.Len(t, parts, 1)
+		assert.Equal(t, "gzip/lines", parts[0].MetaGetStr("auto_codec"))
+		require.NoError(t, ackFn(context.Background(), nil))
+	}
+	require.NoError(t, r.Close(context.Background()))
+}
@@ -4,19 +4,24 @@ import (
 	"archive/tar"
 	"bufio"
 	"bytes"
+	"compress/bzip2"
 	"context"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"path/filepath"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
 	"github.com/klauspost/pgzip"
+	"github.com/pierrec/lz4/v4"
 
 	goavro "github.com/linkedin/goavro/v2"
 
@@ -28,26 +33,56 @@ import (
 var ReaderDocs = docs.FieldString(
 	"codec", "The way in which the bytes of a data source should be converted into discrete messages, codecs are useful for specifying how large files or continuous streams of data might be processed in small chunks rather than loading it all in memory. It's possible to consume lines using a custom delimiter with the `delim:x` codec, where x is the character sequence custom delimiter. Codecs can be chained with `/`, for example a gzip compressed CSV file can be consumed with the codec `gzip/csv`.", "lines", "delim:\t", "delim:foobar", "gzip/csv",
 ).HasAnnotatedOptions(
-	"auto", "EXPERIMENTAL: Attempts to derive a codec for each file based on information such as the extension. For example, a .tar.gz file would be consumed with the `gzip/tar` codec. Defaults to all-bytes.",
+	"auto", "EXPERIMENTAL: Attempts to derive a codec for each file based on information such as the extension. For example, a .tar.gz file would be consumed with the `gzip/tar` codec. When the extension isn't recognised the leading bytes of the file are inspected in order to detect compression (gzip, zstd, lz4, bzip2) and common structures (tar, Avro OCF, Parquet, JSON arrays, newline delimited JSON and CSV). Defaults to all-bytes. The selected codec is added to each message as the metadata field `auto_codec`.",
 	"all-bytes", "Consume the entire file as a single binary message.",
-	"avro-ocf:marshaler=x", "EXPERIMENTAL: Consume a stream of Avro OCF datum. The `marshaler` parameter is optional and has the options: `goavro` (default), `json`. Use `goavro` if OCF contains logical types.",
+	"avro-ocf:marshaler=x", "EXPERIMENTAL: Consume a stream of Avro OCF datum. The `marshaler` parameter is optional and has the options: `goavro` (default), `json`. Use `goavro` if OCF contains logical types. The schema of the file is added to each message as the metadata field `avro_schema`.",
 	"chunker:x", "Consume the file in chunks of a given number of bytes.",
 	"csv", "Consume structured rows as comma separated values, the first row must be a header row.",
 	"csv:x", "Consume structured rows as values separated by a custom delimiter, the first row must be a header row. The custom delimiter must be a single character, e.g. the codec `\"csv:\\t\"` would consume a tab delimited file.",
 	"csv-safe", "Consume structured rows like `csv`, but sends messages with empty maps on failure to parse. Includes row number and parsing errors (if any) in the message's metadata.",
 	"csv-safe:x", "Consume structured rows like `csv:x` as values separated by a custom delimiter, but sends messages with empty maps on failure to parse. The custom delimiter must be a single character, e.g. the codec `\"csv-safe:\\t\"` would consume a tab delimited file. Includes row number and parsing errors (if any) in the message's metadata.",
 	"delim:x", "Consume the file in segments divided by a custom delimiter.",
+	"json-array", "Consume a file containing a single JSON array, where each element of the array is consumed as a message. The array is decoded as a stream and therefore the entire file is not loaded into memory.",
+	"bzip2", "Decompress a bzip2 file, this codec should precede another codec, e.g. `bzip2/all-bytes`, `bzip2/lines`, etc.",
 	"gzip", "Decompress a gzip file, this codec should precede another codec, e.g. `gzip/all-bytes`, `gzip/tar`, `gzip/csv`, etc.",
 	"pgzip", "Decompress a gzip file in parallel, this codec should precede another codec, e.g. `pgzip/all-bytes`, `pgzip/tar`, `pgzip/csv`, etc.",
 	"lines", "Consume the file in segments divided by linebreaks.",
+	"lz4", "Decompress an lz4 frame stream, this codec should precede another codec, e.g. `lz4/all-bytes`, `lz4/lines`, etc.",
+	"parquet", "EXPERIMENTAL: Consume a [Parquet file](https://parquet.apache.org/docs/), where each row is consumed as a structured message. The schema of the file is added to each message as the metadata field `parquet_schema`. Parquet files must be read in their entirety before rows can be extracted and therefore the whole file is held in memory.",
 	"multipart", "Consumes the output of another codec and batches messages together. A batch ends when an empty message is consumed. For example, the codec `lines/multipart` could be used to consume multipart messages where an empty line indicates the end of each batch.",
 	"regex:(?m)^\\d\\d:\\d\\d:\\d\\d", "Consume the file in segments divided by regular expression.",
 	"skipbom", "Skip one or more byte order marks for each opened reader, this codec should precede another codec, e.g. `skipbom/csv`, etc.",
-	"tar", "Parse the file as a tar archive, and consume each file of the archive as a message.",
+	"snappy", "Decompress a framed snappy stream, this codec should precede another codec, e.g. `snappy/all-bytes`, `snappy/lines`, etc.",
+	"tar", "Parse the file as a tar archive, and consume each file of the archive as a message. Files are read from the archive one at a time and so archives of any size can be consumed, including compressed archives with codecs such as `gzip/tar`. The path of each file within the archive is added as the metadata fields `archive_filename`, `archive_dir` and `archive_basename`, and its size in bytes as `archive_size`.",
+	"zstd", "Decompress a zstd file, this codec should precede another codec, e.g. `zstd/all-bytes`, `zstd/tar`, `zstd/csv`, etc.",
 )
 
 //------------------------------------------------------------------------------
 
+// RegisterPartReader adds a reader codec implemented outside of this package,
+// allowing codecs with heavy dependencies to only be included in builds that
+// import them. Registered codecs are resolved after those built into this
+// package and can be chained with decompression codecs.
+func RegisterPartReader(name string, ctor ReaderConstructor) {
+	pluginReadersMut.Lock()
+	pluginReaders[name] = ctor
+	pluginReadersMut.Unlock()
+}
+
+var (
+	pluginReadersMut sync.RWMutex
+	pluginReaders    = map[string]ReaderConstructor{}
+)
+
+func getPluginReader(name string) (ReaderConstructor, bool) {
+	pluginReadersMut.RLock()
+	ctor, exists := pluginReaders[name]
+	pluginReadersMut.RUnlock()
+	return ctor, exists
+}
+
+//------------------------------------------------------------------------------
+
 // ReaderConfig is a general configuration struct that covers all reader codecs.
 type ReaderConfig struct {
 	MaxScanTokenSize int
@@ -210,6 +245,31 @@ func ioReader(codec string, conf ReaderConfig) (ioReaderConstructor, bool) {
 			unzipped := ioReadCloserWrapper{Reader: g, underlying: r}
 			return &unzipped, nil
 		}, true
+	case "bzip2":
+		return func(_ string, r io.ReadCloser) (io.ReadCloser, error) {
+			unzipped := ioReadCloserWrapper{Reader: bzip2.NewReader(r), underlying: r}
+			return &unzipped, nil
+		}, true
+	case "lz4":
+		return func(_ string, r io.ReadCloser) (io.ReadCloser, error) {
+			unzipped := ioReadCloserWrapper{Reader: lz4.NewReader(r), underlying: r}
+			return &unzipped, nil
+		}, true
+	case "snappy":
+		return func(_ string, r io.ReadCloser) (io.ReadCloser, error) {
+			unzipped := ioReadCloserWrapper{Reader: snappy.NewReader(r), underlying: r}
+			return &unzipped, nil
+		}, true
+	case "zstd":
+		return func(_ string, r io.ReadCloser) (io.ReadCloser, error) {
+			z, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+			if err != nil {
+				r.Close()
+				return nil, err
+			}
+			unzipped := ioReadCloserWrapper{Reader: z.IOReadCloser(), underlying: r}
+			return &unzipped, nil
+		}, true
 	case "skipbom":
 		return func(_ string, r io.ReadCloser) (io.ReadCloser, error) {
 			skipBom := ioReadCloserWrapper{Reader: skipBOM(r), underlying: r}
@@ -252,6 +312,10 @@ func partReader(codec string, conf ReaderConfig) (ReaderConstructor, bool, error
 		}, true, nil
 	case "tar":
 		return newTarReader, true, nil
+	case "json-array":
+		return func(path string, r io.ReadCloser, fn ReaderAckFn) (Reader, error) {
+			return newJSONArrayReader(r, fn)
+		}, true, nil
 	}
 
 	if strings.HasPrefix(codec, "avro-ocf:") {
@@ -325,6 +389,9 @@ func partReader(codec string, conf ReaderConfig) (ReaderConstructor, bool, error
 			return newRexExpSplitReader(conf, r, by, fn)
 		}, true, nil
 	}
+	if ctor, exists := getPluginReader(codec); exists {
+		return ctor, true, nil
+	}
 	return nil, false, nil
 }
 
@@ -347,35 +414,6 @@ func GetReader(codec string, conf ReaderConfig) (ReaderConstructor, error) {
 	return chainedReader(codec, conf)
 }
 
-func autoCodec(conf ReaderConfig) ReaderConstructor {
-	return func(path string, r io.ReadCloser, fn ReaderAckFn) (Reader, error) {
-		codec := "all-bytes"
-		switch filepath.Ext(path) {
-		case ".avro":
-			codec = "avro-ocf"
-		case ".csv":
-			codec = "csv"
-		case ".csv.gz", ".csv.gzip":
-			codec = "gzip/csv"
-		case ".tar":
-			codec = "tar"
-		case ".tgz":
-			codec = "gzip/tar"
-		}
-		if strings.HasSuffix(path, ".tar.gzip") {
-			codec = "gzip/tar"
-		} else if strings.HasSuffix(path, ".tar.gz") {
-			codec = "gzip/tar"
-		}
-
-		ctor, err := GetReader(codec, conf)
-		if err != nil {
-			return nil, fmt.Errorf("failed to infer codec: %v", err)
-		}
-		return ctor(path, r, fn)
-	}
-}
-
 // ioReadCloserWrapper is a helper that closes both the upper and underlying reader
 // when you are creating some sort of wrapped reader where you want to ensure both
 // are closed.
@@ -424,6 +462,7 @@ func (a *allBytesReader) Close(ctx context.Context) error {
 
 type avroOCFReader struct {
 	ocf          *goavro.OCFReader
+	schema       string
 	r            io.ReadCloser
 	avroCodec    *goavro.Codec
 	decoder      avroDecoder
@@ -477,6 +516,7 @@ func newAvroOCFReader(conf ReaderConfig, marshaler string, r io.ReadCloser, ackF
 
 	return &avroOCFReader{
 		ocf:          ocf,
+		schema:       ocfSchema,
 		r:            r,
 		logicalTypes: logicalTypes,
 		decoder:      decoder,
@@ -510,6 +550,7 @@ func (a *avroOCFReader) Next(ctx context.Context) ([]*message.Part, ReaderAckFn,
 		if err != nil {
 			return nil, nil, err
 		}
+		part.MetaSetMut("avro_schema", a.schema)
 		return []*message.Part{part}, a.ack, nil
 	}
 	err := a.ocf.Err()
@@ -611,6 +652,93 @@ func (a *linesReader) Close(ctx context.Context) error {
 
 //------------------------------------------------------------------------------
 
+type jsonArrayReader struct {
+	dec       *json.Decoder
+	r         io.ReadCloser
+	sourceAck ReaderAckFn
+
+	mut      sync.Mutex
+	finished bool
+	pending  int32
+}
+
+func newJSONArrayReader(r io.ReadCloser, ackFn ReaderAckFn) (Reader, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := t.(json.Delim); !ok || d != '[' {
+		return nil, fmt.Errorf("expected a JSON array, found %v", t)
+	}
+
+	return &jsonArrayReader{
+		dec:       dec,
+		r:         r,
+		sourceAck: ackOnce(ackFn),
+	}, nil
+}
+
+func (a *jsonArrayReader) ack(ctx context.Context, err error) error {
+	a.mut.Lock()
+	a.pending--
+	doAck := a.pending == 0 && a.finished
+	a.mut.Unlock()
+
+	if err != nil {
+		return a.sourceAck(ctx, err)
+	}
+	if doAck {
+		return a.sourceAck(ctx, nil)
+	}
+	return nil
+}
+
+func (a *jsonArrayReader) Next(ctx context.Context) ([]*message.Part, ReaderAckFn, error) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if a.finished {
+		return nil, nil, io.EOF
+	}
+
+	if !a.dec.More() {
+		// Consume the closing bracket of the array.
+		if _, err := a.dec.Token(); err != nil {
+			_ = a.sourceAck(ctx, err)
+			return nil, nil, err
+		}
+		a.finished = true
+		return nil, nil, io.EOF
+	}
+
+	var raw json.RawMessage
+	if err := a.dec.Decode(&raw); err != nil {
+		_ = a.sourceAck(ctx, err)
+		return nil, nil, err
+	}
+
+	a.pending++
+	return []*message.Part{message.NewPart(raw)}, a.ack, nil
+}
+
+func (a *jsonArrayReader) Close(ctx context.Context) error {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if !a.finished {
+		_ = a.sourceAck(ctx, errors.New("service shutting down"))
+	}
+	if a.pending == 0 {
+		_ = a.sourceAck(ctx, nil)
+	}
+	return a.r.Close()
+}
+
+//------------------------------------------------------------------------------
+
 type csvReader struct {
 	scanner   *csv.Reader
 	r         io.ReadCloser
@@ -971,7 +1099,7 @@ func (a *tarReader) ack(ctx context.Context, err error) error {
 }
 
 func (a *tarReader) Next(ctx context.Context) ([]*message.Part, ReaderAckFn, error) {
-	_, err := a.buf.Next()
+	h, err := a.buf.Next()
 
 	a.mut.Lock()
 	defer a.mut.Unlock()
@@ -983,7 +1111,13 @@ func (a *tarReader) Next(ctx context.Context) ([]*message.Part, ReaderAckFn, err
 			return nil, nil, err
 		}
 		a.pending++
-		return []*message.Part{message.NewPart(fileBuf.Bytes())}, a.ack, nil
+
+		part := message.NewPart(fileBuf.Bytes())
+		part.MetaSetMut("archive_filename", h.Name)
+		part.MetaSetMut("archive_dir", path.Dir(h.Name))
+		part.MetaSetMut("archive_basename", path.Base(h.Name))
+		part.MetaSetMut("archive_size", fileBuf.Len())
+		return []*message.Part{part}, a.ack, nil
 	}
 
 	if errors.Is(err, io.EOF) {
@@ -11,7 +11,12 @@ import (
 	"testing"
 
 	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
 	"github.com/klauspost/pgzip"
+	"github.com/pierrec/lz4/v4"
+
+	goavro "github.com/linkedin/goavro/v2"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -496,6 +501,42 @@ func TestCSVPGzipReader(t *testing.T) {
 	)
 }
 
+func TestCSVCompressedReaders(t *testing.T) {
+	data := []byte("col1,col2,col3\nfoo1,bar1,baz1\nfoo2,bar2,baz2\nfoo3,bar3,baz3")
+
+	compressors := map[string]func(io.Writer) io.WriteCloser{
+		"lz4": func(w io.Writer) io.WriteCloser {
+			return lz4.NewWriter(w)
+		},
+		"snappy": func(w io.Writer) io.WriteCloser {
+			return snappy.NewBufferedWriter(w)
+		},
+		"zstd": func(w io.Writer) io.WriteCloser {
+			zw, err := zstd.NewWriter(w)
+			require.NoError(t, err)
+			return zw
+		},
+	}
+
+	for algo, ctor := range compressors {
+		algo, ctor := algo, ctor
+		t.Run(algo, func(t *testing.T) {
+			var buf bytes.Buffer
+			zw := ctor(&buf)
+			_, err := zw.Write(data)
+			require.NoError(t, err)
+			require.NoError(t, zw.Close())
+
+			testReaderSuite(
+				t, algo+"/csv", "", buf.Bytes(),
+				`{"col1":"foo1","col2":"bar1","col3":"baz1"}`,
+				`{"col1":"foo2","col2":"bar2","col3":"baz2"}`,
+				`{"col1":"foo3","col2":"bar3","col3":"baz3"}`,
+			)
+		})
+	}
+}
+
 func TestCSVGzipReaderOld(t *testing.T) {
 	var gzipBuf bytes.Buffer
 	zw := gzip.NewWriter(&gzipBuf)
@@ -625,6 +666,50 @@ func TestTarGzipReader(t *testing.T) {
 	testReaderSuite(t, "auto", "foo.tgz", gzipBuf.Bytes(), input...)
 }
 
+func TestTarReaderMetadata(t *testing.T) {
+	var gzipBuf bytes.Buffer
+
+	zw := gzip.NewWriter(&gzipBuf)
+	tw := tar.NewWriter(zw)
+	for _, name := range []string{"foo.txt", "logs/bar.json"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(name))}))
+		_, err := tw.Write([]byte(name))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+
+	ctor, err := GetReader("gzip/tar", NewReaderConfig())
+	require.NoError(t, err)
+
+	r, err := ctor("", io.NopCloser(bytes.NewReader(gzipBuf.Bytes())), func(ctx context.Context, err error) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	for _, exp := range []map[string]any{
+		{"archive_filename": "foo.txt", "archive_dir": ".", "archive_basename": "foo.txt", "archive_size": 7},
+		{"archive_filename": "logs/bar.json", "archive_dir": "logs", "archive_basename": "bar.json", "archive_size": 13},
+	} {
+		p, ackFn, err := r.Next(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, ackFn(context.Background(), nil))
+		require.Len(t, p, 1)
+		assert.Equal(t, exp["archive_filename"], string(p[0].AsBytes()))
+
+		meta := map[string]any{}
+		_ = p[0].MetaIterMut(func(k string, v any) error {
+			meta[k] = v
+			return nil
+		})
+		assert.Equal(t, exp, meta)
+	}
+
+	_, _, err = r.Next(context.Background())
+	assert.ErrorIs(t, err, io.EOF)
+	require.NoError(t, r.Close(context.Background()))
+}
+
 func TestTarPGzipReader(t *testing.T) {
 	input := []string{
 		"first document",
@@ -925,3 +1010,39 @@ func TestRegexpSplitReader(t *testing.T) {
 	data = []byte("")
 	testReaderSuite(t, "regex:split", "", data)
 }
+
+func TestAvroOCFReaderSchemaMetadata(t *testing.T) {
+	schema := `{"type":"record","name":"foo","fields":[{"name":"id","type":"long"}]}`
+
+	var buf bytes.Buffer
+	w, err := goavro.NewOCFWriter(goavro.OCFConfig{W: &buf, Schema: schema})
+	require.NoError(t, err)
+	require.NoError(t, w.Append([]any{
+		map[string]any{"id": int64(1)},
+		map[string]any{"id": int64(2)},
+	}))
+
+	ctor, err := GetReader("avro-ocf:marshaler=json", NewReaderConfig())
+	require.NoError(t, err)
+
+	r, err := ctor("", noopCloser{bytes.NewReader(buf.Bytes()), false}, func(ctx context.Context, err error) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	for i := int64(1); i <= 2; i++ {
+		parts, ackFn, err := r.Next(context.Background())
+		require.NoError(t, err)
+		require.Len(t, parts, 1)
+
+		v, err := parts[0].AsStructured()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": i}, v)
+		assert.Equal(t, schema, parts[0].MetaGetStr("avro_schema"))
+		require.NoError(t, ackFn(context.Background(), nil))
+	}
+
+	_, _, err = r.Next(context.Background())
+	assert.Equal(t, io.EOF, err)
+	require.NoError(t, r.Close(context.Background()))
+}
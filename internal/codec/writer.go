@@ -8,20 +8,55 @@ import (
 	"io"
 	"strings"
 
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+
 	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
 	"github.com/benthosdev/benthos/v4/internal/message"
 )
 
 // WriterDocs is a static field documentation for output codecs.
 var WriterDocs = docs.FieldString(
-	"codec", "The way in which the bytes of messages should be written out into the output data stream. It's possible to write lines using a custom delimiter with the `delim:x` codec, where x is the character sequence custom delimiter.", "lines", "delim:\t", "delim:foobar",
+	"codec", "The way in which the bytes of messages should be written out into the output data stream. It's possible to write lines using a custom delimiter with the `delim:x` codec, where x is the character sequence custom delimiter. The output stream can be compressed by prefixing a codec with a compression algorithm, for example `gzip/lines`.", "lines", "delim:\t", "delim:foobar", "zstd/lines",
 ).HasAnnotatedOptions(
 	"all-bytes", "Only applicable to file based outputs. Writes each message to a file in full, if the file already exists the old content is deleted.",
 	"append", "Append each message to the output stream without any delimiter or special encoding.",
 	"lines", "Append each message to the output stream followed by a line break.",
 	"delim:x", "Append each message to the output stream followed by a custom delimiter.",
+	"gzip", "Compress the output stream with gzip, this codec should precede another codec, e.g. `gzip/lines`, `gzip/all-bytes`, etc.",
+	"lz4", "Compress the output stream as lz4 frames, this codec should precede another codec, e.g. `lz4/lines`, `lz4/all-bytes`, etc.",
+	"snappy", "Compress the output stream as a framed snappy stream, this codec should precede another codec, e.g. `snappy/lines`, `snappy/all-bytes`, etc.",
+	"zstd", "Compress the output stream with zstd, this codec should precede another codec, e.g. `zstd/lines`, `zstd/all-bytes`, etc.",
 )
 
+// WriterCompressionDocs is a static field documentation for the compression
+// options of output codecs.
+var WriterCompressionDocs = docs.FieldObject(
+	"compression", "Options for the compression algorithm that prefixes the `codec`, if any.",
+).WithChildren(
+	docs.FieldInt("level", "The level of compression to use, where `-1` uses the default level of the algorithm. Supported by the `gzip` (`0` to `9`), `lz4` (`0` to `9`) and `zstd` (`1` to `22`) algorithms.").HasDefault(-1),
+	docs.FieldString("dictionary", "An optional path to a file containing a compression dictionary. Only supported by the `zstd` algorithm, and data compressed with a dictionary must be decompressed with the same dictionary.", "./dicts/events.dict").HasDefault(""),
+).Advanced().AtVersion("4.24.0")
+
+// WriterCompressionConfig contains options for the compression algorithm that
+// prefixes an output codec.
+type WriterCompressionConfig struct {
+	Level      int    `json:"level" yaml:"level"`
+	Dictionary string `json:"dictionary" yaml:"dictionary"`
+}
+
+// NewWriterCompressionConfig creates a new WriterCompressionConfig with default
+// values.
+func NewWriterCompressionConfig() WriterCompressionConfig {
+	return WriterCompressionConfig{
+		Level:      -1,
+		Dictionary: "",
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // Writer is a codec type that reads message parts from a source.
@@ -43,6 +78,51 @@ type WriterConstructor func(io.WriteCloser) (Writer, error)
 
 // GetWriter returns a constructor that creates write codecs.
 func GetWriter(codec string) (WriterConstructor, WriterConfig, error) {
+	return getWriter(codec, NewWriterCompressionConfig().Level, nil)
+}
+
+// GetCompressedWriter returns a constructor that creates write codecs, where
+// the compression algorithm prefixing the codec, if any, is configured with the
+// provided options. A compression dictionary is read from the filesystem.
+func GetCompressedWriter(codec string, compConf WriterCompressionConfig, fs ifs.FS) (WriterConstructor, WriterConfig, error) {
+	var dict []byte
+	if compConf.Dictionary != "" {
+		var err error
+		if dict, err = ifs.ReadFile(fs, compConf.Dictionary); err != nil {
+			return nil, WriterConfig{}, fmt.Errorf("failed to read compression dictionary: %w", err)
+		}
+	}
+	return getWriter(codec, compConf.Level, dict)
+}
+
+func getWriter(codec string, level int, dict []byte) (WriterConstructor, WriterConfig, error) {
+	algo, inner, _ := strings.Cut(codec, "/")
+	if compressor, exists := writerCompressors[algo]; exists && inner != "" {
+		innerCtor, conf, err := getWriter(inner, level, dict)
+		if err != nil {
+			return nil, WriterConfig{}, err
+		}
+
+		// Create a compressor up front in order to validate the options.
+		cw, err := compressor(io.Discard, level, dict)
+		if err != nil {
+			return nil, WriterConfig{}, fmt.Errorf("%v: %w", algo, err)
+		}
+		_ = cw.Close()
+
+		return func(w io.WriteCloser) (Writer, error) {
+			cw, err := compressor(w, level, dict)
+			if err != nil {
+				return nil, err
+			}
+			innerWriter, err := innerCtor(&compressedWriteCloser{w: cw, underlying: w})
+			if err != nil {
+				return nil, err
+			}
+			return &flushingWriter{Writer: innerWriter, f: cw}, nil
+		}, conf, nil
+	}
+
 	switch codec {
 	case "all-bytes":
 		return func(w io.WriteCloser) (Writer, error) {
@@ -148,3 +228,94 @@ func (d *customDelimWriter) Write(ctx context.Context, p *message.Part) error {
 func (d *customDelimWriter) Close(ctx context.Context) error {
 	return d.w.Close()
 }
+
+//------------------------------------------------------------------------------
+
+type flushWriteCloser interface {
+	io.WriteCloser
+	Flush() error
+}
+
+var errDictionaryNotSupported = errors.New("compression dictionaries are only supported by zstd")
+
+// writerCompressors create compressors with a level, where -1 is the default
+// level of the algorithm, and an optional dictionary.
+var writerCompressors = map[string]func(w io.Writer, level int, dict []byte) (flushWriteCloser, error){
+	"gzip": func(w io.Writer, level int, dict []byte) (flushWriteCloser, error) {
+		if dict != nil {
+			return nil, errDictionaryNotSupported
+		}
+		return gzip.NewWriterLevel(w, level)
+	},
+	"lz4": func(w io.Writer, level int, dict []byte) (flushWriteCloser, error) {
+		if dict != nil {
+			return nil, errDictionaryNotSupported
+		}
+		lw := lz4.NewWriter(w)
+		if level >= 0 {
+			if level > 9 {
+				return nil, fmt.Errorf("compression level %v is out of range 0 to 9", level)
+			}
+			compLevel := lz4.Fast
+			if level > 0 {
+				compLevel = lz4.Level1 << (level - 1)
+			}
+			if err := lw.Apply(lz4.CompressionLevelOption(compLevel)); err != nil {
+				return nil, err
+			}
+		}
+		return lw, nil
+	},
+	"snappy": func(w io.Writer, level int, dict []byte) (flushWriteCloser, error) {
+		if dict != nil {
+			return nil, errDictionaryNotSupported
+		}
+		return snappy.NewBufferedWriter(w), nil
+	},
+	"zstd": func(w io.Writer, level int, dict []byte) (flushWriteCloser, error) {
+		opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+		if level >= 0 {
+			if level < 1 || level > 22 {
+				return nil, fmt.Errorf("compression level %v is out of range 1 to 22", level)
+			}
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+		if dict != nil {
+			opts = append(opts, zstd.WithEncoderDict(dict))
+		}
+		return zstd.NewWriter(w, opts...)
+	},
+}
+
+// compressedWriteCloser writes into a compressor and, when closed, flushes the
+// remaining compressed data before closing the underlying writer.
+type compressedWriteCloser struct {
+	w          flushWriteCloser
+	underlying io.WriteCloser
+}
+
+func (c *compressedWriteCloser) Write(p []byte) (int, error) {
+	return c.w.Write(p)
+}
+
+func (c *compressedWriteCloser) Close() error {
+	if err := c.w.Close(); err != nil {
+		_ = c.underlying.Close()
+		return err
+	}
+	return c.underlying.Close()
+}
+
+// flushingWriter flushes a compressor after each message is written so that
+// data isn't held in memory indefinitely for long lived output streams.
+type flushingWriter struct {
+	Writer
+	f flushWriteCloser
+}
+
+func (f *flushingWriter) Write(ctx context.Context, p *message.Part) error {
+	if err := f.Writer.Write(ctx, p); err != nil {
+		return err
+	}
+	return f.f.Flush()
+}
@@ -0,0 +1,167 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufferCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestCompressedWriters(t *testing.T) {
+	for _, algo := range []string{"gzip", "lz4", "snappy", "zstd"} {
+		algo := algo
+		t.Run(algo, func(t *testing.T) {
+			ctor, conf, err := GetWriter(algo + "/lines")
+			require.NoError(t, err)
+			assert.True(t, conf.Append)
+
+			var buf bufferCloser
+			w, err := ctor(&buf)
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			require.NoError(t, w.Write(ctx, message.NewPart([]byte("foo"))))
+			require.NoError(t, w.Write(ctx, message.NewPart([]byte("bar"))))
+			require.NoError(t, w.Close(ctx))
+			assert.True(t, buf.closed)
+
+			rCtor, err := GetReader(algo+"/lines", NewReaderConfig())
+			require.NoError(t, err)
+
+			r, err := rCtor("", io.NopCloser(&buf), func(ctx context.Context, err error) error {
+				return nil
+			})
+			require.NoError(t, err)
+
+			var results []string
+			for {
+				parts, ackFn, err := r.Next(ctx)
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				require.NoError(t, ackFn(ctx, nil))
+				for _, p := range parts {
+					results = append(results, string(p.AsBytes()))
+				}
+			}
+			assert.Equal(t, []string{"foo", "bar"}, results)
+			require.NoError(t, r.Close(ctx))
+		})
+	}
+}
+
+func TestCompressedWriterLevels(t *testing.T) {
+	for _, algo := range []string{"gzip", "lz4", "zstd"} {
+		algo := algo
+		t.Run(algo, func(t *testing.T) {
+			compConf := NewWriterCompressionConfig()
+			compConf.Level = 9
+
+			ctor, _, err := GetCompressedWriter(algo+"/lines", compConf, ifs.OS())
+			require.NoError(t, err)
+
+			var buf bufferCloser
+			w, err := ctor(&buf)
+			require.NoError(t, err)
+			require.NoError(t, w.Write(context.Background(), message.NewPart([]byte("foo"))))
+			require.NoError(t, w.Close(context.Background()))
+
+			rCtor, err := GetReader(algo+"/all-bytes", NewReaderConfig())
+			require.NoError(t, err)
+
+			r, err := rCtor("", io.NopCloser(&buf), func(ctx context.Context, err error) error {
+				return nil
+			})
+			require.NoError(t, err)
+
+			parts, _, err := r.Next(context.Background())
+			require.NoError(t, err)
+			require.Len(t, parts, 1)
+			assert.Equal(t, "foo\n", string(parts[0].AsBytes()))
+		})
+	}
+
+	compConf := NewWriterCompressionConfig()
+	compConf.Level = 30
+	_, _, err := GetCompressedWriter("zstd/lines", compConf, ifs.OS())
+	require.EqualError(t, err, "zstd: compression level 30 is out of range 1 to 22")
+}
+
+func TestCompressedWriterDictionary(t *testing.T) {
+	var samples [][]byte
+	for i := 0; i < 1000; i++ {
+		samples = append(samples, []byte(fmt.Sprintf(`{"id":"%v","type":"page_view","user":{"name":"user %v"}}`, i, i%50)))
+	}
+	d, err := dict.BuildZstdDict(samples, dict.Options{
+		MaxDictSize: 4096,
+		HashBytes:   6,
+	})
+	require.NoError(t, err)
+
+	dictPath := filepath.Join(t.TempDir(), "events.dict")
+	require.NoError(t, os.WriteFile(dictPath, d, 0o644))
+
+	compConf := NewWriterCompressionConfig()
+	compConf.Dictionary = dictPath
+
+	ctor, _, err := GetCompressedWriter("zstd/lines", compConf, ifs.OS())
+	require.NoError(t, err)
+
+	var buf bufferCloser
+	w, err := ctor(&buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(context.Background(), message.NewPart(samples[0])))
+	require.NoError(t, w.Close(context.Background()))
+
+	dec, err := zstd.NewReader(&buf, zstd.WithDecoderDicts(d))
+	require.NoError(t, err)
+	defer dec.Close()
+
+	decompressed, err := io.ReadAll(dec)
+	require.NoError(t, err)
+	assert.Equal(t, string(samples[0])+"\n", string(decompressed))
+
+	_, _, err = GetCompressedWriter("gzip/lines", compConf, ifs.OS())
+	require.EqualError(t, err, "gzip: compression dictionaries are only supported by zstd")
+
+	compConf.Dictionary = filepath.Join(t.TempDir(), "nope.dict")
+	_, _, err = GetCompressedWriter("zstd/lines", compConf, ifs.OS())
+	require.ErrorContains(t, err, "failed to read compression dictionary")
+}
+
+func TestWriterDelimWithSlash(t *testing.T) {
+	ctor, _, err := GetWriter("delim:/")
+	require.NoError(t, err)
+
+	var buf bufferCloser
+	w, err := ctor(&buf)
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write(context.Background(), message.NewPart([]byte("foo"))))
+	assert.Equal(t, "foo/", buf.String())
+
+	_, _, err = GetWriter("nope/lines")
+	require.Error(t, err)
+}
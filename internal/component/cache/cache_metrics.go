@@ -31,6 +31,14 @@ type metricsCache struct {
 	mDelError   metrics.StatCounter
 	mDelSuccess metrics.StatCounter
 	mDelLatency metrics.StatTimer
+
+	mIncrError   metrics.StatCounter
+	mIncrSuccess metrics.StatCounter
+	mIncrLatency metrics.StatTimer
+
+	mCASError   metrics.StatCounter
+	mCASSuccess metrics.StatCounter
+	mCASLatency metrics.StatTimer
 }
 
 // MetricsForCache wraps a cache with a struct that adds standard metrics over
@@ -60,6 +68,14 @@ func MetricsForCache(c V1, stats metrics.Type) V1 {
 		mDelError:   cacheError.With("delete"),
 		mDelSuccess: cacheSuccess.With("delete"),
 		mDelLatency: cacheLatency.With("delete"),
+
+		mIncrError:   cacheError.With("incr"),
+		mIncrSuccess: cacheSuccess.With("incr"),
+		mIncrLatency: cacheLatency.With("incr"),
+
+		mCASError:   cacheError.With("compare_and_swap"),
+		mCASSuccess: cacheSuccess.With("compare_and_swap"),
+		mCASLatency: cacheLatency.With("compare_and_swap"),
 	}
 }
 
@@ -131,6 +147,38 @@ func (a *metricsCache) Delete(ctx context.Context, key string) error {
 	return err
 }
 
+func (a *metricsCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	i, ok := a.c.(Incrementer)
+	if !ok {
+		return 0, ErrIncrNotSupported
+	}
+	started := time.Now()
+	v, err := i.Incr(ctx, key, delta, ttl)
+	a.mIncrLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		a.mIncrError.Incr(1)
+	} else {
+		a.mIncrSuccess.Incr(1)
+	}
+	return v, err
+}
+
+func (a *metricsCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) (bool, error) {
+	c, ok := a.c.(CompareAndSwapper)
+	if !ok {
+		return false, ErrCompareAndSwapNotSupported
+	}
+	started := time.Now()
+	swapped, err := c.CompareAndSwap(ctx, key, old, value, ttl)
+	a.mCASLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		a.mCASError.Incr(1)
+	} else {
+		a.mCASSuccess.Incr(1)
+	}
+	return swapped, err
+}
+
 func (a *metricsCache) Close(ctx context.Context) error {
 	return a.c.Close(ctx)
 }
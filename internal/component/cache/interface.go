@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -38,3 +39,30 @@ type V1 interface {
 	// is cancelled.
 	Close(ctx context.Context) error
 }
+
+// ErrIncrNotSupported is returned by caches that do not support atomic
+// increments.
+var ErrIncrNotSupported = errors.New("cache does not support atomic increments")
+
+// Incrementer is an optional interface implemented by caches that are able to
+// atomically increment the integer value of a key.
+type Incrementer interface {
+	// Incr atomically adds a delta to the integer value of a key, where a key
+	// that does not exist is treated as zero, and returns the resulting value.
+	// The TTL of the key is set when provided.
+	Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error)
+}
+
+// ErrCompareAndSwapNotSupported is returned by caches that do not support
+// atomic compare-and-swap operations.
+var ErrCompareAndSwapNotSupported = errors.New("cache does not support compare-and-swap")
+
+// CompareAndSwapper is an optional interface implemented by caches that are
+// able to atomically replace the value of a key based on its current value.
+type CompareAndSwapper interface {
+	// CompareAndSwap sets the value of a key only when its current value is
+	// equal to old, and returns whether the value was swapped. A key that does
+	// not exist never matches, and a nil value deletes the key rather than
+	// setting it. The TTL of the key is set when provided.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) (bool, error)
+}
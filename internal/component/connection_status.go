@@ -0,0 +1,103 @@
+package component
+
+import (
+	"sync"
+	"time"
+)
+
+// ConnectionStatus describes the connectivity of an input or output component
+// at a point in time.
+type ConnectionStatus struct {
+	// Kind is the kind of component, either input or output.
+	Kind      string
+	Label     string
+	Path      []string
+	Connected bool
+
+	// Err is the last error encountered whilst connecting, or that caused
+	// the component to lose its connection.
+	Err error
+
+	// Since is the time at which the connected state of the component last
+	// changed.
+	Since time.Time
+
+	// HasConnected indicates whether the component has ever connected.
+	HasConnected bool
+}
+
+// ConnectionStatusRegistry is implemented by observability providers (usually
+// a manager) that aggregate the connection statuses of components.
+type ConnectionStatusRegistry interface {
+	// RegisterConnectionStatus adds a connection status tracker to the
+	// registry, and returns a func that removes it.
+	RegisterConnectionStatus(c *ConnectionStatusTracker) (deregister func())
+}
+
+// ConnectionStatusTracker records changes to the connectivity of a component
+// and is safe to use from multiple goroutines.
+type ConnectionStatusTracker struct {
+	mut        sync.Mutex
+	status     ConnectionStatus
+	deregister func()
+}
+
+// NewConnectionStatusTracker creates a connection status tracker for a
+// component, and if the provided observability type is a registry of
+// connection statuses it is registered.
+func NewConnectionStatusTracker(kind string, mgr Observability) *ConnectionStatusTracker {
+	c := &ConnectionStatusTracker{
+		status: ConnectionStatus{
+			Kind:  kind,
+			Since: time.Now(),
+		},
+		deregister: func() {},
+	}
+	if p, ok := mgr.(interface{ Path() []string }); ok {
+		c.status.Path = p.Path()
+	}
+	if l, ok := mgr.(interface{ Label() string }); ok {
+		c.status.Label = l.Label()
+	}
+	if r, ok := mgr.(ConnectionStatusRegistry); ok {
+		c.deregister = r.RegisterConnectionStatus(c)
+	}
+	return c
+}
+
+// SetConnected marks the component as connected.
+func (c *ConnectionStatusTracker) SetConnected() {
+	c.mut.Lock()
+	if !c.status.Connected {
+		c.status.Connected = true
+		c.status.HasConnected = true
+		c.status.Since = time.Now()
+	}
+	c.mut.Unlock()
+}
+
+// SetDisconnected marks the component as disconnected with an optional error
+// describing the cause.
+func (c *ConnectionStatusTracker) SetDisconnected(err error) {
+	c.mut.Lock()
+	if c.status.Connected {
+		c.status.Connected = false
+		c.status.Since = time.Now()
+	}
+	if err != nil {
+		c.status.Err = err
+	}
+	c.mut.Unlock()
+}
+
+// Status returns the current connection status of the component.
+func (c *ConnectionStatusTracker) Status() ConnectionStatus {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.status
+}
+
+// Close removes the tracker from any registry it was added to.
+func (c *ConnectionStatusTracker) Close() {
+	c.deregister()
+}
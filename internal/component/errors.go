@@ -64,14 +64,16 @@ func (e *ErrBackOff) Error() string {
 
 // Manager errors.
 var (
-	ErrInputNotFound     = errors.New("input not found")
-	ErrCacheNotFound     = errors.New("cache not found")
-	ErrProcessorNotFound = errors.New("processor not found")
-	ErrRateLimitNotFound = errors.New("rate limit not found")
-	ErrOutputNotFound    = errors.New("output not found")
-	ErrKeyAlreadyExists  = errors.New("key already exists")
-	ErrKeyNotFound       = errors.New("key does not exist")
-	ErrPipeNotFound      = errors.New("pipe was not found")
+	ErrInputNotFound      = errors.New("input not found")
+	ErrCacheNotFound      = errors.New("cache not found")
+	ErrProcessorNotFound  = errors.New("processor not found")
+	ErrRateLimitNotFound  = errors.New("rate limit not found")
+	ErrHTTPClientNotFound = errors.New("http client not found")
+	ErrSQLDBNotFound      = errors.New("sql db not found")
+	ErrOutputNotFound     = errors.New("output not found")
+	ErrKeyAlreadyExists   = errors.New("key already exists")
+	ErrKeyNotFound        = errors.New("key does not exist")
+	ErrPipeNotFound       = errors.New("pipe was not found")
 )
 
 //------------------------------------------------------------------------------
@@ -19,6 +19,7 @@ import (
 // input.Async component.
 type AsyncReader struct {
 	connected   int32
+	connStatus  *component.ConnectionStatusTracker
 	connBackoff backoff.BackOff
 	readBackoff backoff.BackOff
 
@@ -54,6 +55,7 @@ func NewAsyncReader(
 		typeStr:      typeStr,
 		reader:       r,
 		mgr:          mgr,
+		connStatus:   component.NewConnectionStatusTracker("input", mgr),
 		transactions: make(chan message.Transaction),
 		shutSig:      shutdown.NewSignaller(),
 	}
@@ -98,6 +100,8 @@ func (r *AsyncReader) loop() {
 		_ = r.reader.Close(context.Background())
 
 		atomic.StoreInt32(&r.connected, 0)
+		r.connStatus.SetDisconnected(nil)
+		r.connStatus.Close()
 
 		close(r.transactions)
 		r.shutSig.ShutdownComplete()
@@ -121,6 +125,7 @@ func (r *AsyncReader) loop() {
 				}
 				r.mgr.Logger().Errorf("Failed to connect to %v: %v\n", r.typeStr, err)
 				mFailedConn.Incr(1)
+				r.connStatus.SetDisconnected(err)
 
 				var nextBoff time.Duration
 
@@ -149,6 +154,7 @@ func (r *AsyncReader) loop() {
 	}
 	mConn.Incr(1)
 	atomic.StoreInt32(&r.connected, 1)
+	r.connStatus.SetConnected()
 
 	for {
 		msg, ackFn, err := r.reader.ReadBatch(closeAtLeisureCtx)
@@ -157,6 +163,7 @@ func (r *AsyncReader) loop() {
 		if errors.Is(err, component.ErrNotConnected) {
 			mLostConn.Incr(1)
 			atomic.StoreInt32(&r.connected, 0)
+			r.connStatus.SetDisconnected(err)
 
 			// Continue to try to reconnect while still active.
 			if !initConnection() {
@@ -164,6 +171,7 @@ func (r *AsyncReader) loop() {
 			}
 			mConn.Incr(1)
 			atomic.StoreInt32(&r.connected, 1)
+			r.connStatus.SetConnected()
 			continue
 		}
 
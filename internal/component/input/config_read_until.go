@@ -6,32 +6,44 @@ import (
 
 // ReadUntilConfig contains configuration values for the ReadUntil input type.
 type ReadUntilConfig struct {
-	Input   *Config `json:"input" yaml:"input"`
-	Restart bool    `json:"restart_input" yaml:"restart_input"`
-	Check   string  `json:"check" yaml:"check"`
+	Input       *Config `json:"input" yaml:"input"`
+	Restart     bool    `json:"restart_input" yaml:"restart_input"`
+	Check       string  `json:"check" yaml:"check"`
+	IdleTimeout string  `json:"idle_timeout" yaml:"idle_timeout"`
+	MaxMessages int     `json:"max_messages" yaml:"max_messages"`
+	MaxBytes    int     `json:"max_bytes" yaml:"max_bytes"`
 }
 
 // NewReadUntilConfig creates a new ReadUntilConfig with default values.
 func NewReadUntilConfig() ReadUntilConfig {
 	return ReadUntilConfig{
-		Input:   nil,
-		Restart: false,
-		Check:   "",
+		Input:       nil,
+		Restart:     false,
+		Check:       "",
+		IdleTimeout: "",
+		MaxMessages: 0,
+		MaxBytes:    0,
 	}
 }
 
 type dummyReadUntilConfig struct {
-	Input   any    `json:"input" yaml:"input"`
-	Restart bool   `json:"restart_input" yaml:"restart_input"`
-	Check   string `json:"check" yaml:"check"`
+	Input       any    `json:"input" yaml:"input"`
+	Restart     bool   `json:"restart_input" yaml:"restart_input"`
+	Check       string `json:"check" yaml:"check"`
+	IdleTimeout string `json:"idle_timeout" yaml:"idle_timeout"`
+	MaxMessages int    `json:"max_messages" yaml:"max_messages"`
+	MaxBytes    int    `json:"max_bytes" yaml:"max_bytes"`
 }
 
 // MarshalJSON prints an empty object instead of nil.
 func (r ReadUntilConfig) MarshalJSON() ([]byte, error) {
 	dummy := dummyReadUntilConfig{
-		Input:   r.Input,
-		Restart: r.Restart,
-		Check:   r.Check,
+		Input:       r.Input,
+		Restart:     r.Restart,
+		Check:       r.Check,
+		IdleTimeout: r.IdleTimeout,
+		MaxMessages: r.MaxMessages,
+		MaxBytes:    r.MaxBytes,
 	}
 	if r.Input == nil {
 		dummy.Input = struct{}{}
@@ -42,9 +54,12 @@ func (r ReadUntilConfig) MarshalJSON() ([]byte, error) {
 // MarshalYAML prints an empty object instead of nil.
 func (r ReadUntilConfig) MarshalYAML() (any, error) {
 	dummy := dummyReadUntilConfig{
-		Input:   r.Input,
-		Restart: r.Restart,
-		Check:   r.Check,
+		Input:       r.Input,
+		Restart:     r.Restart,
+		Check:       r.Check,
+		IdleTimeout: r.IdleTimeout,
+		MaxMessages: r.MaxMessages,
+		MaxBytes:    r.MaxBytes,
 	}
 	if r.Input == nil {
 		dummy.Input = struct{}{}
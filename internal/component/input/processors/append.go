@@ -2,7 +2,6 @@ package processors
 
 import (
 	"fmt"
-	"strconv"
 
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/input"
@@ -18,7 +17,7 @@ func AppendFromConfig(conf input.Config, mgr bundle.NewManagement, pipelines ...
 		pipelines = append([]processor.PipelineConstructorFunc{func() (processor.Pipeline, error) {
 			processors := make([]processor.V1, len(conf.Processors))
 			for j, procConf := range conf.Processors {
-				newMgr := mgr.IntoPath("processors", strconv.Itoa(j))
+				newMgr := mgr.IntoPath("processors", bundle.PathSegment(j, procConf.Label))
 				var err error
 				processors[j], err = newMgr.NewProcessor(procConf)
 				if err != nil {
@@ -12,6 +12,7 @@ type PrometheusConfig struct {
 	PushInterval        string                             `json:"push_interval" yaml:"push_interval"`
 	PushJobName         string                             `json:"push_job_name" yaml:"push_job_name"`
 	FileOutputPath      string                             `json:"file_output_path" yaml:"file_output_path"`
+	AddTypeLabel        bool                               `json:"add_type_label" yaml:"add_type_label"`
 }
 
 // PrometheusPushBasicAuthConfig contains parameters for establishing basic
@@ -52,5 +53,6 @@ func NewPrometheusConfig() PrometheusConfig {
 		PushInterval:        "",
 		PushJobName:         "benthos_push",
 		FileOutputPath:      "",
+		AddTypeLabel:        false,
 	}
 }
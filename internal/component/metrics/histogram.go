@@ -0,0 +1,107 @@
+package metrics
+
+// StatHistogram is a representation of a single histogram metric stat, where
+// observed values are counted within a set of buckets. Interactions with this
+// stat are thread safe.
+type StatHistogram interface {
+	// Observe adds a single observation to the histogram.
+	Observe(value float64)
+}
+
+// StatHistogramVec creates StatHistograms with dynamic labels.
+type StatHistogramVec interface {
+	// With returns a StatHistogram with a set of label values.
+	With(labelValues ...string) StatHistogram
+}
+
+// HistogramProvider is an optional interface implemented by metrics exporters
+// that support histograms with custom buckets.
+type HistogramProvider interface {
+	// GetHistogramVec returns an editable histogram stat for a given path with
+	// labels, where observations are counted within the provided bucket upper
+	// bounds. When no buckets are provided the defaults of the exporter are
+	// used.
+	GetHistogramVec(path string, buckets []float64, labelNames ...string) StatHistogramVec
+}
+
+type fHistogramVec struct {
+	f func(...string) StatHistogram
+}
+
+func (f *fHistogramVec) With(labels ...string) StatHistogram {
+	return f.f(labels...)
+}
+
+// FakeHistogramVec returns a histogram vec implementation that ignores labels.
+func FakeHistogramVec(f func(...string) StatHistogram) StatHistogramVec {
+	return &fHistogramVec{
+		f: f,
+	}
+}
+
+// GetHistogramVec returns an editable histogram stat from a metrics exporter for
+// a given path with labels. If the exporter does not support histograms then
+// the last observed value is emitted as a gauge instead.
+func GetHistogramVec(t Type, path string, buckets []float64, labelNames ...string) StatHistogramVec {
+	if hp, ok := t.(HistogramProvider); ok {
+		return hp.GetHistogramVec(path, buckets, labelNames...)
+	}
+	return histogramVecAsGauge(t, path, labelNames...)
+}
+
+func histogramVecAsGauge(t Type, path string, labelNames ...string) StatHistogramVec {
+	gv := t.GetGaugeVec(path, labelNames...)
+	return FakeHistogramVec(func(values ...string) StatHistogram {
+		return gaugeHistogram{gauge: gv.With(values...)}
+	})
+}
+
+// gaugeHistogram is used in place of a histogram for exporters that do not
+// support them, where the last observed value is set as a gauge.
+type gaugeHistogram struct {
+	gauge StatGauge
+}
+
+func (g gaugeHistogram) Observe(value float64) {
+	g.gauge.SetFloat64(value)
+}
+
+type histogramVecWithStatic struct {
+	staticValues []string
+	child        StatHistogramVec
+}
+
+func (c *histogramVecWithStatic) With(values ...string) StatHistogram {
+	newValues := make([]string, 0, len(c.staticValues)+len(values))
+	newValues = append(newValues, c.staticValues...)
+	newValues = append(newValues, values...)
+	return c.child.With(newValues...)
+}
+
+// GetHistogramVec returns an editable histogram stat for a given path with
+// labels, these labels must be consistent with any other metrics registered on
+// the same path. If the child exporter does not support histograms then the
+// last observed value is emitted as a gauge instead.
+func (n *Namespaced) GetHistogramVec(path string, buckets []float64, labelNames ...string) StatHistogramVec {
+	hp, ok := n.child.(HistogramProvider)
+	if !ok {
+		return histogramVecAsGauge(n, path, labelNames...)
+	}
+
+	path, staticKeys, staticValues := n.getPathAndLabels(path)
+	if path == "" {
+		return FakeHistogramVec(func(...string) StatHistogram {
+			return DudStat{}
+		})
+	}
+	if len(staticKeys) > 0 {
+		newNames := make([]string, 0, len(staticKeys)+len(labelNames))
+		newNames = append(newNames, staticKeys...)
+		newNames = append(newNames, labelNames...)
+		return &histogramVecWithStatic{
+			staticValues: staticValues,
+			child:        hp.GetHistogramVec(path, buckets, newNames...),
+		}
+	}
+	return hp.GetHistogramVec(path, buckets, labelNames...)
+}
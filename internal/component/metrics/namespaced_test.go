@@ -255,3 +255,32 @@ root = this.replace_all("bar","baz")`, log.Noop())
 	assert.Contains(t, body, "\ngaugetwo{extra1=\"extravalue1\",extra2=\"extravalue2\",label2=\"value3\",static1=\"sbaz1\"} 12")
 	assert.Contains(t, body, "\ntimertwo_sum{extra1=\"extravalue1\",extra2=\"extravalue2\",label3=\"value4\",label4=\"value5\",static1=\"sbaz1\"} 1.3e-08")
 }
+
+func TestNamespacedHistogram(t *testing.T) {
+	prom, handler := getTestProm(t)
+
+	nm := metrics.NewNamespaced(prom).WithLabels("static1", "svalue1")
+
+	hist := nm.GetHistogramVec("histone", []float64{5, 10}, "label1")
+	hist.With("value1").Observe(7)
+
+	body := getPage(t, handler)
+
+	assert.Contains(t, body, "\nhistone_bucket{label1=\"value1\",static1=\"svalue1\",le=\"5\"} 0")
+	assert.Contains(t, body, "\nhistone_bucket{label1=\"value1\",static1=\"svalue1\",le=\"10\"} 1")
+	assert.Contains(t, body, "\nhistone_sum{label1=\"value1\",static1=\"svalue1\"} 7")
+}
+
+func TestNamespacedHistogramFallback(t *testing.T) {
+	local := metrics.NewLocal()
+
+	nm := metrics.NewNamespaced(local)
+
+	hist := nm.GetHistogramVec("histone", []float64{5, 10}, "label1")
+	hist.With("value1").Observe(3)
+	hist.With("value1").Observe(7)
+
+	assert.Equal(t, map[string]int64{
+		`histone{label1="value1"}`: 7,
+	}, local.GetCounters())
+}
@@ -100,3 +100,18 @@ type Type interface {
 	// Close stops aggregating stats and cleans up resources.
 	Close() error
 }
+
+// ComponentTypeLabeler is an optional interface implemented by metrics
+// exporters that wish for the metrics of each component to be labelled with
+// the type of that component (e.g. `kafka`).
+type ComponentTypeLabeler interface {
+	// ComponentTypeLabels returns whether component type labels are enabled.
+	ComponentTypeLabels() bool
+}
+
+// HasComponentTypeLabels returns whether a namespaced metrics exporter wraps a
+// child that expects component type labels.
+func HasComponentTypeLabels(n *Namespaced) bool {
+	l, ok := n.Child().(ComponentTypeLabeler)
+	return ok && l.ComponentTypeLabels()
+}
@@ -46,9 +46,10 @@ type AsyncWriter struct {
 	maxInflight int
 	writer      AsyncSink
 
-	log    log.Modular
-	stats  metrics.Type
-	tracer trace.TracerProvider
+	log        log.Modular
+	stats      metrics.Type
+	tracer     trace.TracerProvider
+	connStatus *component.ConnectionStatusTracker
 
 	transactions <-chan message.Transaction
 
@@ -64,6 +65,7 @@ func NewAsyncWriter(typeStr string, maxInflight int, w AsyncSink, mgr component.
 		log:          mgr.Logger(),
 		stats:        mgr.Metrics(),
 		tracer:       mgr.Tracer(),
+		connStatus:   component.NewConnectionStatusTracker("output", mgr),
 		transactions: nil,
 		shutSig:      shutdown.NewSignaller(),
 	}
@@ -100,6 +102,8 @@ func (w *AsyncWriter) loop() {
 		_ = w.writer.Close(context.Background())
 
 		atomic.StoreInt32(&w.isConnected, 0)
+		w.connStatus.SetDisconnected(nil)
+		w.connStatus.Close()
 		w.shutSig.ShutdownComplete()
 	}()
 
@@ -119,6 +123,7 @@ func (w *AsyncWriter) loop() {
 				}
 				w.log.Errorf("Failed to connect to %v: %v\n", w.typeStr, err)
 				mFailedConn.Incr(1)
+				w.connStatus.SetDisconnected(err)
 
 				var nextBoff time.Duration
 
@@ -143,6 +148,7 @@ func (w *AsyncWriter) loop() {
 	}
 	mConn.Incr(1)
 	atomic.StoreInt32(&w.isConnected, 1)
+	w.connStatus.SetConnected()
 
 	wg := sync.WaitGroup{}
 	wg.Add(w.maxInflight)
@@ -164,6 +170,7 @@ func (w *AsyncWriter) loop() {
 			}
 		}
 		mLostConn.Incr(1)
+		w.connStatus.SetDisconnected(component.ErrNotConnected)
 
 		// Continue to try to reconnect while still active.
 		for {
@@ -173,6 +180,7 @@ func (w *AsyncWriter) loop() {
 			}
 			if latency, err = w.latencyMeasuringWrite(closeLeisureCtx, msg); err != component.ErrNotConnected {
 				atomic.StoreInt32(&w.isConnected, 1)
+				w.connStatus.SetConnected()
 				mConn.Incr(1)
 				return
 			} else if err != nil {
@@ -229,6 +237,10 @@ func (w *AsyncWriter) loop() {
 			}
 
 			for _, s := range spans {
+				if err != nil {
+					s.SetTag("error", "true")
+					s.LogKV("event", "error", "type", err.Error())
+				}
 				s.Finish()
 			}
 
@@ -1,17 +1,23 @@
 package output
 
+import (
+	"github.com/benthosdev/benthos/v4/internal/codec"
+)
+
 // SocketConfig contains configuration fields for the Socket output type.
 type SocketConfig struct {
-	Network string `json:"network" yaml:"network"`
-	Address string `json:"address" yaml:"address"`
-	Codec   string `json:"codec" yaml:"codec"`
+	Network     string                        `json:"network" yaml:"network"`
+	Address     string                        `json:"address" yaml:"address"`
+	Codec       string                        `json:"codec" yaml:"codec"`
+	Compression codec.WriterCompressionConfig `json:"compression" yaml:"compression"`
 }
 
 // NewSocketConfig creates a new SocketConfig with default values.
 func NewSocketConfig() SocketConfig {
 	return SocketConfig{
-		Network: "",
-		Address: "",
-		Codec:   "lines",
+		Network:     "",
+		Address:     "",
+		Codec:       "lines",
+		Compression: codec.NewWriterCompressionConfig(),
 	}
 }
@@ -1,13 +1,33 @@
 package output
 
+import (
+	"github.com/benthosdev/benthos/v4/internal/codec"
+)
+
 // STDOUTConfig contains configuration fields for the stdout based output type.
 type STDOUTConfig struct {
-	Codec string `json:"codec" yaml:"codec"`
+	Codec       string                        `json:"codec" yaml:"codec"`
+	Compression codec.WriterCompressionConfig `json:"compression" yaml:"compression"`
+	Pretty      STDOUTPrettyConfig            `json:"pretty" yaml:"pretty"`
+}
+
+// STDOUTPrettyConfig contains configuration fields for printing messages in a
+// human readable format.
+type STDOUTPrettyConfig struct {
+	Enabled  bool   `json:"enabled" yaml:"enabled"`
+	Metadata bool   `json:"metadata" yaml:"metadata"`
+	Color    string `json:"color" yaml:"color"`
 }
 
 // NewSTDOUTConfig creates a new STDOUTConfig with default values.
 func NewSTDOUTConfig() STDOUTConfig {
 	return STDOUTConfig{
-		Codec: "lines",
+		Codec:       "lines",
+		Compression: codec.NewWriterCompressionConfig(),
+		Pretty: STDOUTPrettyConfig{
+			Enabled:  false,
+			Metadata: true,
+			Color:    "auto",
+		},
 	}
 }
@@ -1,8 +1,6 @@
 package processors
 
 import (
-	"strconv"
-
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/output"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
@@ -18,7 +16,7 @@ func AppendFromConfig(conf output.Config, mgr bundle.NewManagement, pipelines ..
 			processors := make([]processor.V1, len(conf.Processors))
 			for j, procConf := range conf.Processors {
 				var err error
-				pMgr := mgr.IntoPath("processors", strconv.Itoa(j))
+				pMgr := mgr.IntoPath("processors", bundle.PathSegment(j, procConf.Label))
 				processors[j], err = pMgr.NewProcessor(procConf)
 				if err != nil {
 					return nil, err
@@ -2,14 +2,17 @@ package processor
 
 // JQConfig contains configuration fields for the JQ processor.
 type JQConfig struct {
-	Query     string `json:"query" yaml:"query"`
-	Raw       bool   `json:"raw" yaml:"raw"`
-	OutputRaw bool   `json:"output_raw" yaml:"output_raw"`
+	Query          string            `json:"query" yaml:"query"`
+	Raw            bool              `json:"raw" yaml:"raw"`
+	OutputRaw      bool              `json:"output_raw" yaml:"output_raw"`
+	OutputMultiple bool              `json:"output_multiple" yaml:"output_multiple"`
+	Args           map[string]string `json:"args" yaml:"args"`
 }
 
 // NewJQConfig returns a JQConfig with default values.
 func NewJQConfig() JQConfig {
 	return JQConfig{
 		Query: "",
+		Args:  map[string]string{},
 	}
 }
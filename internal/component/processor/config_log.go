@@ -6,6 +6,7 @@ type LogConfig struct {
 	Fields        map[string]string `json:"fields" yaml:"fields"`
 	FieldsMapping string            `json:"fields_mapping" yaml:"fields_mapping"`
 	Message       string            `json:"message" yaml:"message"`
+	SampleRate    float64           `json:"sample_rate" yaml:"sample_rate"`
 }
 
 // NewLogConfig returns a LogConfig with default values.
@@ -15,5 +16,6 @@ func NewLogConfig() LogConfig {
 		Fields:        map[string]string{},
 		FieldsMapping: "",
 		Message:       "",
+		SampleRate:    1,
 	}
 }
@@ -2,18 +2,24 @@ package processor
 
 // MetricConfig contains configuration fields for the Metric processor.
 type MetricConfig struct {
-	Type   string            `json:"type" yaml:"type"`
-	Name   string            `json:"name" yaml:"name"`
-	Labels map[string]string `json:"labels" yaml:"labels"`
-	Value  string            `json:"value" yaml:"value"`
+	Type          string            `json:"type" yaml:"type"`
+	Name          string            `json:"name" yaml:"name"`
+	Labels        map[string]string `json:"labels" yaml:"labels"`
+	LabelsMapping string            `json:"labels_mapping" yaml:"labels_mapping"`
+	Value         string            `json:"value" yaml:"value"`
+	Buckets       []float64         `json:"buckets" yaml:"buckets"`
+	Check         string            `json:"check" yaml:"check"`
 }
 
 // NewMetricConfig returns a MetricConfig with default values.
 func NewMetricConfig() MetricConfig {
 	return MetricConfig{
-		Type:   "",
-		Name:   "",
-		Labels: map[string]string{},
-		Value:  "",
+		Type:          "",
+		Name:          "",
+		Labels:        map[string]string{},
+		LabelsMapping: "",
+		Value:         "",
+		Buckets:       []float64{},
+		Check:         "",
 	}
 }
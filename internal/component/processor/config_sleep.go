@@ -2,12 +2,16 @@ package processor
 
 // SleepConfig contains configuration fields for the Sleep processor.
 type SleepConfig struct {
-	Duration string `json:"duration" yaml:"duration"`
+	Duration        string  `json:"duration" yaml:"duration"`
+	DurationMapping string  `json:"duration_mapping" yaml:"duration_mapping"`
+	Jitter          float64 `json:"jitter" yaml:"jitter"`
 }
 
 // NewSleepConfig returns a SleepConfig with default values.
 func NewSleepConfig() SleepConfig {
 	return SleepConfig{
-		Duration: "",
+		Duration:        "",
+		DurationMapping: "",
+		Jitter:          0,
 	}
 }
@@ -3,6 +3,7 @@ package ratelimit
 import (
 	yaml "gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/docs"
 )
 
@@ -10,9 +11,10 @@ import (
 // Deprecated: Do not add new components here. Instead, use the public plugin
 // APIs. Examples can be found in: ./internal/impl.
 type Config struct {
-	Label  string `json:"label" yaml:"label"`
-	Type   string `json:"type" yaml:"type"`
-	Plugin any    `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+	Label  string                        `json:"label" yaml:"label"`
+	Type   string                        `json:"type" yaml:"type"`
+	Init   *component.ResourceInitConfig `json:"init,omitempty" yaml:"init,omitempty"`
+	Plugin any                           `json:"plugin,omitempty" yaml:"plugin,omitempty"`
 }
 
 // NewConfig returns a configuration struct fully populated with default values.
@@ -22,6 +24,7 @@ func NewConfig() Config {
 	return Config{
 		Label:  "",
 		Type:   "local",
+		Init:   nil,
 		Plugin: nil,
 	}
 }
@@ -0,0 +1,35 @@
+package component
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// ResourceInitConfig determines when a resource, such as a cache or rate
+// limit, is initialised and how many attempts are made to initialise it.
+type ResourceInitConfig struct {
+	Lazy        bool   `json:"lazy" yaml:"lazy"`
+	Retries     int    `json:"retries" yaml:"retries"`
+	RetryPeriod string `json:"retry_period" yaml:"retry_period"`
+}
+
+// NewResourceInitConfig returns a ResourceInitConfig populated with default
+// values, where a resource is initialised eagerly in a single attempt.
+func NewResourceInitConfig() ResourceInitConfig {
+	return ResourceInitConfig{
+		Lazy:        false,
+		Retries:     0,
+		RetryPeriod: "1s",
+	}
+}
+
+// UnmarshalYAML ensures that default values are applied to fields that are
+// omitted from the config.
+func (c *ResourceInitConfig) UnmarshalYAML(value *yaml.Node) error {
+	type confAlias ResourceInitConfig
+	aliased := confAlias(NewResourceInitConfig())
+	if err := value.Decode(&aliased); err != nil {
+		return err
+	}
+	*c = ResourceInitConfig(aliased)
+	return nil
+}
@@ -10,7 +10,7 @@ import (
 
 func init() {
 	// TODO: I'm so confused, these APIs are a nightmare.
-	otel.SetTextMapPropagator(propagation.TraceContext{})
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 }
 
 // Config is the all encompassing configuration struct for all tracer types.
@@ -2,10 +2,11 @@ package tracer
 
 // CloudTraceConfig is config for the Google Cloud Trace tracer.
 type CloudTraceConfig struct {
-	Project       string            `json:"project" yaml:"project"`
-	SamplingRatio float64           `json:"sampling_ratio" yaml:"sampling_ratio"`
-	Tags          map[string]string `json:"tags" yaml:"tags"`
-	FlushInterval string            `json:"flush_interval" yaml:"flush_interval"`
+	Project       string             `json:"project" yaml:"project"`
+	SamplingRatio float64            `json:"sampling_ratio" yaml:"sampling_ratio"`
+	Tags          map[string]string  `json:"tags" yaml:"tags"`
+	FlushInterval string             `json:"flush_interval" yaml:"flush_interval"`
+	TailSampling  TailSamplingConfig `json:"tail_sampling" yaml:"tail_sampling"`
 }
 
 // NewCloudTraceConfig creates an CloudTraceConfig struct with default values.
@@ -15,5 +16,6 @@ func NewCloudTraceConfig() CloudTraceConfig {
 		SamplingRatio: 1.0,
 		Tags:          map[string]string{},
 		FlushInterval: "",
+		TailSampling:  NewTailSamplingConfig(),
 	}
 }
@@ -0,0 +1,53 @@
+package tracer
+
+import (
+	"fmt"
+	"time"
+
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/tracing"
+)
+
+// TailSamplingConfig is config for recording only the traces of messages that
+// errored or exceeded a latency budget.
+type TailSamplingConfig struct {
+	Enabled          bool   `json:"enabled" yaml:"enabled"`
+	LatencyBudget    string `json:"latency_budget" yaml:"latency_budget"`
+	MaxPendingTraces int    `json:"max_pending_traces" yaml:"max_pending_traces"`
+}
+
+// NewTailSamplingConfig creates a TailSamplingConfig struct with default
+// values.
+func NewTailSamplingConfig() TailSamplingConfig {
+	return TailSamplingConfig{
+		Enabled:          false,
+		LatencyBudget:    "",
+		MaxPendingTraces: 10000,
+	}
+}
+
+// TailSamplingFieldSpec returns the field spec of a tail sampling config.
+func TailSamplingFieldSpec() docs.FieldSpec {
+	return docs.FieldObject("tail_sampling", "EXPERIMENTAL: Record the traces of only those messages that experienced an error or exceeded a latency budget. The spans of each message are held in memory until the message has been delivered or rejected by an output, at which point the decision to record them is made.").WithChildren(
+		docs.FieldBool("enabled", "Whether to enable tail sampling.").HasDefault(false),
+		docs.FieldString("latency_budget", "An optional duration, where the traces of messages that take longer than it to be delivered are recorded. When empty only the traces of messages that experienced an error are recorded.", "500ms", "10s").HasDefault(""),
+		docs.FieldInt("max_pending_traces", "The maximum number of traces to hold in memory whilst waiting for a decision, when exceeded the oldest pending traces are dropped.").Advanced().HasDefault(10000),
+	).Advanced().AtVersion("4.24.0")
+}
+
+// SpanProcessor wraps a span processor with tail sampling when enabled.
+func (c TailSamplingConfig) SpanProcessor(next tracesdk.SpanProcessor) (tracesdk.SpanProcessor, error) {
+	if !c.Enabled {
+		return next, nil
+	}
+	var budget time.Duration
+	if c.LatencyBudget != "" {
+		var err error
+		if budget, err = time.ParseDuration(c.LatencyBudget); err != nil {
+			return nil, fmt.Errorf("failed to parse tail sampling latency budget '%s': %v", c.LatencyBudget, err)
+		}
+	}
+	return tracing.NewTailSampler(next, budget, c.MaxPendingTraces), nil
+}
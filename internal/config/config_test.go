@@ -184,8 +184,8 @@ cache_resources:
 	require.NoError(t, err)
 	require.Len(t, lints, 3)
 	assert.Contains(t, lints[0], "/main.yaml(3,1) field meow1 ")
-	assert.Contains(t, lints[1], "/res1.yaml(5,1) field meow2 ")
-	assert.Contains(t, lints[2], "/res2.yaml(5,1) field meow3 ")
+	assert.Contains(t, lints[1], "/res1.yaml(5,1) foo: field meow2 ")
+	assert.Contains(t, lints[2], "/res2.yaml(5,1) bar: field meow3 ")
 
 	assert.Equal(t, "generate", conf.Input.Type)
 	assert.Equal(t, `root = "meow"`, conf.Input.Generate.Mapping)
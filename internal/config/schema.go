@@ -10,6 +10,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/docs"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/profiling"
 	"github.com/benthosdev/benthos/v4/internal/stream"
 )
 
@@ -18,12 +19,17 @@ type Type struct {
 	HTTP                   api.Config `json:"http" yaml:"http"`
 	stream.Config          `json:",inline" yaml:",inline"`
 	manager.ResourceConfig `json:",inline" yaml:",inline"`
-	Logger                 log.Config     `json:"logger" yaml:"logger"`
-	Metrics                metrics.Config `json:"metrics" yaml:"metrics"`
-	Tracer                 tracer.Config  `json:"tracer" yaml:"tracer"`
-	SystemCloseDelay       string         `json:"shutdown_delay" yaml:"shutdown_delay"`
-	SystemCloseTimeout     string         `json:"shutdown_timeout" yaml:"shutdown_timeout"`
-	Tests                  []any          `json:"tests,omitempty" yaml:"tests,omitempty"`
+	Logger                 log.Config              `json:"logger" yaml:"logger"`
+	Metrics                metrics.Config          `json:"metrics" yaml:"metrics"`
+	Tracer                 tracer.Config           `json:"tracer" yaml:"tracer"`
+	SystemCloseDelay       string                  `json:"shutdown_delay" yaml:"shutdown_delay"`
+	SystemCloseTimeout     string                  `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+	Watchdog               stream.WatchdogConfig   `json:"watchdog" yaml:"watchdog"`
+	Completion             stream.CompletionConfig `json:"completion" yaml:"completion"`
+	Shutdown               stream.ShutdownConfig   `json:"shutdown" yaml:"shutdown"`
+	Profiling              profiling.Config        `json:"profiling" yaml:"profiling"`
+	SIMDJSON               bool                    `json:"simd_json" yaml:"simd_json"`
+	Tests                  []any                   `json:"tests,omitempty" yaml:"tests,omitempty"`
 }
 
 // New returns a new configuration with default values.
@@ -37,6 +43,11 @@ func New() Type {
 		Tracer:             tracer.NewConfig(),
 		SystemCloseDelay:   "",
 		SystemCloseTimeout: "20s",
+		Watchdog:           stream.NewWatchdogConfig(),
+		Completion:         stream.NewCompletionConfig(),
+		Shutdown:           stream.NewShutdownConfig(),
+		Profiling:          profiling.NewConfig(),
+		SIMDJSON:           false,
 		Tests:              nil,
 	}
 }
@@ -58,6 +69,11 @@ var observabilityFields = docs.FieldSpecs{
 	docs.FieldTracer("tracer", "A mechanism for exporting traces.").Optional(),
 	docs.FieldString("shutdown_delay", "A period of time to wait for metrics and traces to be pulled or pushed from the process.").HasDefault("0s"),
 	docs.FieldString("shutdown_timeout", "The maximum period of time to wait for a clean shutdown. If this time is exceeded Benthos will forcefully close.").HasDefault("20s"),
+	stream.ShutdownFieldSpec(),
+	stream.WatchdogFieldSpec(),
+	stream.CompletionFieldSpec(),
+	profiling.Spec(),
+	docs.FieldBool("simd_json", "Experimental: Whether to use a SIMD accelerated parser for extracting values at paths of unparsed JSON messages, such as with the Bloblang `json` function. The parser is only used on CPUs that support AVX2 and CLMUL, and normalises the formatting of numbers within extracted objects and arrays.").HasDefault(false).Advanced().AtVersion("4.24.0"),
 }
 
 // Spec returns a docs.FieldSpec for an entire Benthos configuration.
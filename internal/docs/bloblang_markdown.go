@@ -321,6 +321,7 @@ func BloblangMethodsMarkdown() ([]byte, error) {
 		query.MethodCategoryEncoding,
 		query.MethodCategoryJWT,
 		query.MethodCategoryGeoIP,
+		query.MethodCategoryIP,
 		query.MethodCategoryDeprecated,
 	} {
 		methods := methodCategory{
@@ -60,6 +60,14 @@ var labelField = FieldString(
 	return nil
 }).HasDefault("")
 
+var resourceInitField = FieldObject(
+	"init", "Determines when the resource is initialised and how many attempts are made to initialise it when it is created at startup.",
+).WithChildren(
+	FieldBool("lazy", "Whether to postpone the initialisation of the resource until it is first used, allowing the config to start without it. When initialisation fails the error is returned to the component that attempted to use the resource and is attempted again on its next use.").HasDefault(false),
+	FieldInt("retries", "The number of additional attempts to make when initialising the resource at startup fails, after which the config fails to start. Ignored when `lazy` is `true`.").HasDefault(0),
+	FieldString("retry_period", "The period of time to wait between attempts to initialise the resource at startup.").HasDefault("1s"),
+).Advanced().Optional().AtVersion("4.24.0")
+
 // ReservedFieldsByType returns a map of fields for a specific type.
 func ReservedFieldsByType(t Type) map[string]FieldSpec {
 	m := map[string]FieldSpec{
@@ -86,6 +94,9 @@ func ReservedFieldsByType(t Type) map[string]FieldSpec {
 	}[t]; isLabelType {
 		m["label"] = labelField
 	}
+	if t == TypeCache || t == TypeRateLimit {
+		m["init"] = resourceInitField
+	}
 	return m
 }
 
@@ -758,6 +758,10 @@ type Lint struct {
 	Level  LintLevel
 	Type   LintType
 	What   string
+
+	// Label is the label of the closest component that encloses the lint, or
+	// empty if the component is not labelled.
+	Label string
 }
 
 // NewLintError returns an error lint.
@@ -775,8 +779,11 @@ func NewLintWarning(line int, t LintType, msg string) Lint {
 }
 
 // Error returns a formatted string explaining the lint error prefixed with its
-// location within the file.
+// location within the file, and the label of its component when it has one.
 func (l Lint) Error() string {
+	if l.Label != "" {
+		return fmt.Sprintf("(%v,%v) %v: %v", l.Line, l.Column, l.Label, l.What)
+	}
 	return fmt.Sprintf("(%v,%v) %v", l.Line, l.Column, l.What)
 }
 
@@ -502,6 +502,7 @@ func LintYAML(ctx LintContext, cType Type, node *yaml.Node) []Lint {
 	reservedFields := ReservedFieldsByType(cType)
 	_, canLabel := reservedFields["label"]
 	hasLabel := false
+	label := ""
 	for i := 0; i < len(node.Content)-1; i += 2 {
 		key := node.Content[i].Value
 		if key == name || key == "type" {
@@ -515,7 +516,10 @@ func LintYAML(ctx LintContext, cType Type, node *yaml.Node) []Lint {
 			}
 		}
 		spec, exists := reservedFields[key]
-		hasLabel = hasLabel || (key == "label")
+		if key == "label" {
+			hasLabel = true
+			label = node.Content[i+1].Value
+		}
 		if exists {
 			lints = append(lints, lintYAMLFromOmit(cSpec.Config.Children, spec, node, node.Content[i+1])...)
 			lints = append(lints, spec.LintYAML(ctx, node.Content[i+1])...)
@@ -532,6 +536,16 @@ func LintYAML(ctx LintContext, cType Type, node *yaml.Node) []Lint {
 		lints = append(lints, NewLintError(node.Line, LintMissingLabel, fmt.Errorf("label is required for %s", cSpec.Name)))
 	}
 
+	// Lints of nested components already carry the label of the closest
+	// labelled component, and lints of labels themselves are not attributed.
+	if canLabel && label != "" && ValidateLabel(label) == nil {
+		for i := range lints {
+			if lints[i].Label != "" || lints[i].Type == LintBadLabel || lints[i].Type == LintDuplicateLabel {
+				continue
+			}
+			lints[i].Label = label
+		}
+	}
 	return lints
 }
 
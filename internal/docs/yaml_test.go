@@ -1012,6 +1012,25 @@ testlintfooinput:
 				docs.NewLintError(3, docs.LintCustom, errors.New("this is a custom lint")),
 			},
 		},
+		{
+			name:      "lints of labelled components",
+			inputType: docs.TypeInput,
+			inputConf: `
+label: foo
+testlintfooinput:
+  foo1: lint me please
+processors:
+  - label: bar
+    testlintfooprocessor:
+      foo1: lint me please
+  - testlintfooprocessor:
+      foo1: lint me please`,
+			res: []docs.Lint{
+				{Line: 4, Column: 1, Type: docs.LintCustom, What: "this is a custom lint", Label: "foo"},
+				{Line: 8, Column: 1, Type: docs.LintCustom, What: "this is a custom lint", Label: "bar"},
+				{Line: 10, Column: 1, Type: docs.LintCustom, What: "this is a custom lint", Label: "foo"},
+			},
+		},
 	}
 
 	for _, test := range tests {
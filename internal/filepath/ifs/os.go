@@ -57,6 +57,41 @@ func FileWrite(file fs.File, data []byte) (int, error) {
 	return writer.Write(data)
 }
 
+// Rename moves a file from one path to another. When the FS does not support
+// renaming files directly the contents are copied to the new path and the
+// original file is removed.
+func Rename(f FS, from, to string) error {
+	if rf, ok := f.(interface {
+		Rename(from, to string) error
+	}); ok {
+		return rf.Rename(from, to)
+	}
+
+	src, err := f.OpenFile(from, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := f.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o666)
+	if err != nil {
+		return err
+	}
+	dstWriter, isw := dst.(io.Writer)
+	if !isw {
+		_ = dst.Close()
+		return errors.New("failed to open a writable file")
+	}
+	if _, err = io.Copy(dstWriter, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return f.Remove(from)
+}
+
 // OS implements fs.FS as if calls were being made directly via the os package,
 // with which relative paths are resolved from the directory the process is
 // executed from.
@@ -93,3 +128,7 @@ func (o *osPT) Remove(name string) error {
 func (o *osPT) MkdirAll(path string, perm fs.FileMode) error {
 	return os.MkdirAll(path, perm)
 }
+
+func (o *osPT) Rename(from, to string) error {
+	return os.Rename(from, to)
+}
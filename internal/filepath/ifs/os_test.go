@@ -3,6 +3,8 @@ package ifs
 import (
 	"errors"
 	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
 	"testing/fstest"
 
@@ -34,3 +36,18 @@ func TestOSAccess(t *testing.T) {
 
 	require.True(t, IsOS(fs))
 }
+
+func TestRename(t *testing.T) {
+	dir := t.TempDir()
+	from, to := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+
+	require.NoError(t, os.WriteFile(from, []byte("hello world"), 0o644))
+	require.NoError(t, Rename(OS(), from, to))
+
+	_, err := os.Stat(from)
+	require.True(t, errors.Is(err, fs.ErrNotExist))
+
+	b, err := os.ReadFile(to)
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(b))
+}
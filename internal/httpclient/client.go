@@ -75,45 +75,61 @@ func NewClientFromOldConfig(conf OldConfig, mgr bundle.NewManagement, opts ...Re
 	}
 	h.clientCtx, h.clientCancel = context.WithCancel(context.Background())
 
-	if tout := conf.Timeout; len(tout) > 0 {
-		var err error
-		if h.client.Timeout, err = time.ParseDuration(tout); err != nil {
-			return nil, fmt.Errorf("failed to parse timeout string: %v", err)
-		}
-	}
-
-	if conf.TLS.Enabled {
-		tlsConf, err := conf.TLS.Get(mgr.FS())
+	var sharedRateLimit string
+	if conf.ClientResource != "" {
+		shared, err := getSharedClient(mgr, conf.ClientResource)
 		if err != nil {
 			return nil, err
 		}
-		if tlsConf != nil {
-			if c, ok := http.DefaultTransport.(*http.Transport); ok {
-				cloned := c.Clone()
-				cloned.TLSClientConfig = tlsConf
-				h.client.Transport = cloned
-			} else {
-				h.client.Transport = &http.Transport{
-					TLSClientConfig: tlsConf,
-				}
+
+		// Copying the client retains the shared transport, and therefore
+		// its connection pool, whilst allowing request logging to be
+		// configured per component.
+		sharedClient := *shared.client
+		h.client = &sharedClient
+		sharedRateLimit = shared.rateLimit
+		reqCreator.reqSigner = chainSigners(reqCreator.reqSigner, shared.signer)
+	} else {
+		if tout := conf.Timeout; len(tout) > 0 {
+			var err error
+			if h.client.Timeout, err = time.ParseDuration(tout); err != nil {
+				return nil, fmt.Errorf("failed to parse timeout string: %v", err)
 			}
 		}
-	}
 
-	if conf.ProxyURL != "" {
-		proxyURL, err := url.Parse(conf.ProxyURL)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse proxy_url string: %v", err)
+		if conf.TLS.Enabled {
+			tlsConf, err := conf.TLS.Get(mgr.FS())
+			if err != nil {
+				return nil, err
+			}
+			if tlsConf != nil {
+				if c, ok := http.DefaultTransport.(*http.Transport); ok {
+					cloned := c.Clone()
+					cloned.TLSClientConfig = tlsConf
+					h.client.Transport = cloned
+				} else {
+					h.client.Transport = &http.Transport{
+						TLSClientConfig: tlsConf,
+					}
+				}
+			}
 		}
-		if h.client.Transport != nil {
-			if tr, ok := h.client.Transport.(*http.Transport); ok {
-				tr.Proxy = http.ProxyURL(proxyURL)
-			} else {
-				return nil, fmt.Errorf("unable to apply proxy_url to transport, unexpected type %T", h.client.Transport)
+
+		if conf.ProxyURL != "" {
+			proxyURL, err := url.Parse(conf.ProxyURL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse proxy_url string: %v", err)
 			}
-		} else {
-			h.client.Transport = &http.Transport{
-				Proxy: http.ProxyURL(proxyURL),
+			if h.client.Transport != nil {
+				if tr, ok := h.client.Transport.(*http.Transport); ok {
+					tr.Proxy = http.ProxyURL(proxyURL)
+				} else {
+					return nil, fmt.Errorf("unable to apply proxy_url to transport, unexpected type %T", h.client.Transport)
+				}
+			} else {
+				h.client.Transport = &http.Transport{
+					Proxy: http.ProxyURL(proxyURL),
+				}
 			}
 		}
 	}
@@ -123,7 +139,9 @@ func NewClientFromOldConfig(conf OldConfig, mgr bundle.NewManagement, opts ...Re
 		return nil, fmt.Errorf("failed to config logger for request dump: %v", err)
 	}
 
-	h.client = conf.OAuth2.Client(h.clientCtx, h.client)
+	if conf.ClientResource == "" {
+		h.client = conf.OAuth2.Client(h.clientCtx, h.client)
+	}
 
 	for _, c := range conf.BackoffOn {
 		h.backoffOn[c] = struct{}{}
@@ -156,7 +174,10 @@ func NewClientFromOldConfig(conf OldConfig, mgr bundle.NewManagement, opts ...Re
 		}
 	}
 
-	if h.rateLimit = conf.RateLimit; h.rateLimit != "" {
+	if h.rateLimit = conf.RateLimit; h.rateLimit == "" {
+		h.rateLimit = sharedRateLimit
+	}
+	if h.rateLimit != "" {
 		if !h.mgr.ProbeRateLimit(h.rateLimit) {
 			return nil, fmt.Errorf("rate limit resource '%v' was not found", h.rateLimit)
 		}
@@ -628,3 +628,65 @@ func TestHTTPClientOAuth2AndTLSConf(t *testing.T) {
 	mBytes := resBatch[0].AsBytes()
 	assert.Equal(t, "HELLO WORLD", string(mBytes))
 }
+
+func TestHTTPClientSharedResource(t *testing.T) {
+	var reqCount uint32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint32(&reqCount, 1)
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "foo" || pass != "bar" {
+			http.Error(w, "nope", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	mgr := mock.NewManager()
+	mgr.HTTPClients["shared"] = map[string]any{
+		"basic_auth": map[string]any{
+			"enabled":  true,
+			"username": "foo",
+			"password": "bar",
+		},
+		"max_idle_connections_per_host": 5,
+	}
+
+	var clients []*Client
+	for i := 0; i < 2; i++ {
+		conf := NewOldConfig()
+		conf.URL = ts.URL
+		conf.NumRetries = 0
+		conf.ClientResource = "shared"
+
+		h, err := NewClientFromOldConfig(conf, mgr)
+		require.NoError(t, err)
+		clients = append(clients, h)
+	}
+
+	shared, ok := mgr.HTTPClients["shared"].(*sharedClient)
+	require.True(t, ok)
+	assert.Equal(t, 5, shared.client.Transport.(*http.Transport).MaxIdleConnsPerHost)
+
+	for _, h := range clients {
+		res, err := h.Send(context.Background(), message.QuickBatch([][]byte{[]byte("test")}))
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(res.Get(0).AsBytes()))
+
+		// Request logging wraps the shared transport without replacing it.
+		assert.Equal(t, shared.client.Transport, h.client.Transport)
+		require.NoError(t, h.Close(context.Background()))
+	}
+	assert.Equal(t, uint32(2), atomic.LoadUint32(&reqCount))
+	require.NoError(t, shared.Close(context.Background()))
+}
+
+func TestHTTPClientSharedResourceNotFound(t *testing.T) {
+	conf := NewOldConfig()
+	conf.URL = "http://localhost:1234"
+	conf.ClientResource = "nope"
+
+	_, err := NewClientFromOldConfig(conf, mock.NewManager())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http client resource 'nope' was not found")
+}
@@ -51,6 +51,7 @@ func OldFieldSpec(defaultVerb string, forOutput bool, extraChildren ...docs.Fiel
 	httpSpecs = append(httpSpecs, tls.FieldSpec(),
 		docs.FieldObject("extract_headers", extractHeadersDesc).WithChildren(metadata.IncludeFilterDocs()...).Advanced(),
 		docs.FieldString("rate_limit", "An optional [rate limit](/docs/components/rate_limits/about) to throttle requests by.").Optional(),
+		docs.FieldString("client_resource", "An optional [HTTP client resource](/docs/configuration/resources#http-client-resources) to send requests with. When set the connection pool, timeout, TLS, proxy and authentication settings of the resource are used in place of those of this component, and the rate limit of the resource is used unless this component specifies its own.").Optional().Advanced().AtVersion("4.24.0"),
 		docs.FieldString("timeout", "A static timeout to apply to requests.").HasDefault("5s"),
 		docs.FieldString("retry_period", "The base period to wait between failed requests.").Advanced().HasDefault("1s"),
 		docs.FieldString("max_retry_backoff", "The maximum period to wait between failed requests.").Advanced().HasDefault("300s"),
@@ -112,6 +113,7 @@ type OldConfig struct {
 	Metadata            metadata.IncludeFilterConfig `json:"metadata" yaml:"metadata"`
 	ExtractMetadata     metadata.IncludeFilterConfig `json:"extract_headers" yaml:"extract_headers"`
 	RateLimit           string                       `json:"rate_limit" yaml:"rate_limit"`
+	ClientResource      string                       `json:"client_resource" yaml:"client_resource"`
 	Timeout             string                       `json:"timeout" yaml:"timeout"`
 	Retry               string                       `json:"retry_period" yaml:"retry_period"`
 	MaxBackoff          string                       `json:"max_retry_backoff" yaml:"max_retry_backoff"`
@@ -135,6 +137,7 @@ func NewOldConfig() OldConfig {
 		Metadata:        metadata.NewIncludeFilterConfig(),
 		ExtractMetadata: metadata.NewIncludeFilterConfig(),
 		RateLimit:       "",
+		ClientResource:  "",
 		Timeout:         "5s",
 		Retry:           "1s",
 		MaxBackoff:      "300s",
@@ -0,0 +1,159 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/docs/interop"
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/tls"
+)
+
+func init() {
+	manager.HTTPClientResourceFields = resourceFieldSpecs()
+}
+
+func resourceFieldSpecs() docs.FieldSpecs {
+	specs := docs.FieldSpecs{
+		docs.FieldString("label", "A unique label for the resource, which HTTP components reference with their `client_resource` field."),
+	}
+	for _, s := range AuthFieldSpecsExpanded() {
+		specs = append(specs, interop.Unwrap(s))
+	}
+	return append(specs,
+		tls.FieldSpec(),
+		docs.FieldString("rate_limit", "An optional [rate limit](/docs/components/rate_limits/about) to throttle requests by, which applies to components using this resource that do not specify their own.").Optional(),
+		docs.FieldString("timeout", "A static timeout to apply to requests.").HasDefault("5s"),
+		docs.FieldURL("proxy_url", "An optional HTTP proxy URL.").Advanced().HasDefault(""),
+		docs.FieldInt("max_idle_connections", "The maximum number of idle connections to keep open across all hosts, zero means no limit.").Advanced().HasDefault(100),
+		docs.FieldInt("max_idle_connections_per_host", "The maximum number of idle connections to keep open per host.").Advanced().HasDefault(10),
+		docs.FieldString("idle_connection_timeout", "The maximum period for which an idle connection is kept open before closing itself.").Advanced().HasDefault("90s"),
+	)
+}
+
+type resourceConfig struct {
+	RateLimit           string     `yaml:"rate_limit"`
+	Timeout             string     `yaml:"timeout"`
+	TLS                 tls.Config `yaml:"tls"`
+	ProxyURL            string     `yaml:"proxy_url"`
+	MaxIdleConns        int        `yaml:"max_idle_connections"`
+	MaxIdleConnsPerHost int        `yaml:"max_idle_connections_per_host"`
+	IdleConnTimeout     string     `yaml:"idle_connection_timeout"`
+	AuthConfig          `yaml:",inline"`
+	OAuth2              OAuth2Config `yaml:"oauth2"`
+}
+
+func newResourceConfig() resourceConfig {
+	return resourceConfig{
+		Timeout:             "5s",
+		TLS:                 tls.NewConfig(),
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     "90s",
+		AuthConfig:          NewAuthConfig(),
+		OAuth2:              NewOAuth2Config(),
+	}
+}
+
+// sharedClient is the client of an http_client resource, which is shared by all
+// components that reference it.
+type sharedClient struct {
+	client    *http.Client
+	signer    RequestSigner
+	rateLimit string
+
+	cancel func()
+}
+
+func newSharedClient(fields map[string]any, mgr bundle.NewManagement) (any, error) {
+	conf := newResourceConfig()
+
+	var node yaml.Node
+	if err := node.Encode(fields); err != nil {
+		return nil, fmt.Errorf("failed to parse http client resource: %w", err)
+	}
+	if err := node.Decode(&conf); err != nil {
+		return nil, fmt.Errorf("failed to parse http client resource: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = conf.MaxIdleConns
+	transport.MaxIdleConnsPerHost = conf.MaxIdleConnsPerHost
+
+	var err error
+	if transport.IdleConnTimeout, err = time.ParseDuration(conf.IdleConnTimeout); err != nil {
+		return nil, fmt.Errorf("failed to parse idle_connection_timeout string: %v", err)
+	}
+	if conf.TLS.Enabled {
+		if transport.TLSClientConfig, err = conf.TLS.Get(mgr.FS()); err != nil {
+			return nil, err
+		}
+	}
+	if conf.ProxyURL != "" {
+		proxyURL, err := url.Parse(conf.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy_url string: %v", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	client := &http.Client{Transport: transport}
+	if tout := conf.Timeout; len(tout) > 0 {
+		if client.Timeout, err = time.ParseDuration(tout); err != nil {
+			return nil, fmt.Errorf("failed to parse timeout string: %v", err)
+		}
+	}
+
+	if conf.RateLimit != "" && !mgr.ProbeRateLimit(conf.RateLimit) {
+		return nil, fmt.Errorf("rate limit resource '%v' was not found", conf.RateLimit)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &sharedClient{
+		client:    conf.OAuth2.Client(ctx, client),
+		signer:    conf.AuthConfig.Sign,
+		rateLimit: conf.RateLimit,
+		cancel:    cancel,
+	}, nil
+}
+
+func (s *sharedClient) Close(ctx context.Context) error {
+	s.cancel()
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+func getSharedClient(mgr bundle.NewManagement, name string) (*sharedClient, error) {
+	if !mgr.ProbeHTTPClient(name) {
+		return nil, fmt.Errorf("http client resource '%v' was not found", name)
+	}
+	c, err := mgr.GetHTTPClient(name, newSharedClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client resource '%v': %w", name, err)
+	}
+	shared, ok := c.(*sharedClient)
+	if !ok {
+		return nil, errors.New("http client resource is of an unexpected type")
+	}
+	return shared, nil
+}
+
+func chainSigners(signers ...RequestSigner) RequestSigner {
+	return func(f ifs.FS, req *http.Request) error {
+		for _, s := range signers {
+			if err := s(f, req); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
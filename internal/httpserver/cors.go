@@ -11,15 +11,29 @@ import (
 
 // CORSConfig contains struct configuration for allowing CORS headers.
 type CORSConfig struct {
-	Enabled        bool     `json:"enabled" yaml:"enabled"`
-	AllowedOrigins []string `json:"allowed_origins" yaml:"allowed_origins"`
+	Enabled          bool     `json:"enabled" yaml:"enabled"`
+	AllowedOrigins   []string `json:"allowed_origins" yaml:"allowed_origins"`
+	AllowedMethods   []string `json:"allowed_methods" yaml:"allowed_methods"`
+	AllowedHeaders   []string `json:"allowed_headers" yaml:"allowed_headers"`
+	ExposedHeaders   []string `json:"exposed_headers" yaml:"exposed_headers"`
+	AllowCredentials bool     `json:"allow_credentials" yaml:"allow_credentials"`
+	MaxAge           int      `json:"max_age" yaml:"max_age"`
+}
+
+func defaultCORSAllowedMethods() []string {
+	return []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}
 }
 
 // NewServerCORSConfig returns a new server CORS config with default fields.
 func NewServerCORSConfig() CORSConfig {
 	return CORSConfig{
-		Enabled:        false,
-		AllowedOrigins: []string{},
+		Enabled:          false,
+		AllowedOrigins:   []string{},
+		AllowedMethods:   defaultCORSAllowedMethods(),
+		AllowedHeaders:   []string{},
+		ExposedHeaders:   []string{},
+		AllowCredentials: false,
+		MaxAge:           0,
 	}
 }
 
@@ -32,10 +46,32 @@ func (conf CORSConfig) WrapHandler(handler http.Handler) (http.Handler, error) {
 	if len(conf.AllowedOrigins) == 0 {
 		return nil, errors.New("must specify at least one allowed origin")
 	}
-	return handlers.CORS(
+	if conf.MaxAge < 0 {
+		return nil, errors.New("max age must not be negative")
+	}
+
+	allowedMethods := conf.AllowedMethods
+	if len(allowedMethods) == 0 {
+		allowedMethods = defaultCORSAllowedMethods()
+	}
+
+	opts := []handlers.CORSOption{
 		handlers.AllowedOrigins(conf.AllowedOrigins),
-		handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}),
-	)(handler), nil
+		handlers.AllowedMethods(allowedMethods),
+	}
+	if len(conf.AllowedHeaders) > 0 {
+		opts = append(opts, handlers.AllowedHeaders(conf.AllowedHeaders))
+	}
+	if len(conf.ExposedHeaders) > 0 {
+		opts = append(opts, handlers.ExposedHeaders(conf.ExposedHeaders))
+	}
+	if conf.AllowCredentials {
+		opts = append(opts, handlers.AllowCredentials())
+	}
+	if conf.MaxAge > 0 {
+		opts = append(opts, handlers.MaxAge(conf.MaxAge))
+	}
+	return handlers.CORS(opts...)(handler), nil
 }
 
 // ServerCORSFieldSpec returns a field spec for an http server CORS component.
@@ -43,5 +79,10 @@ func ServerCORSFieldSpec() docs.FieldSpec {
 	return docs.FieldObject("cors", "Adds Cross-Origin Resource Sharing headers.").WithChildren(
 		docs.FieldBool("enabled", "Whether to allow CORS requests.").HasDefault(false),
 		docs.FieldString("allowed_origins", "An explicit list of origins that are allowed for CORS requests.").Array().HasDefault([]string{}),
+		docs.FieldString("allowed_methods", "A list of methods that are allowed for CORS requests, which are returned in response to preflight requests.").Array().HasDefault(defaultCORSAllowedMethods()).AtVersion("4.24.0"),
+		docs.FieldString("allowed_headers", "A list of request headers that are allowed for CORS requests in addition to the simple headers `Accept`, `Accept-Language`, `Content-Language` and `Origin`.").Array().HasDefault([]string{}).AtVersion("4.24.0"),
+		docs.FieldString("exposed_headers", "A list of response headers that browser clients are allowed to access.").Array().HasDefault([]string{}).AtVersion("4.24.0"),
+		docs.FieldBool("allow_credentials", "Whether browser clients are allowed to send credentials such as cookies with CORS requests.").HasDefault(false).AtVersion("4.24.0"),
+		docs.FieldInt("max_age", "The maximum number of seconds that the results of a preflight request can be cached by browser clients, where `0` means the header is not set.").HasDefault(0).AtVersion("4.24.0"),
 	).AtVersion("3.63.0").Advanced()
 }
@@ -85,3 +85,44 @@ func TestAPIEnableCORSNoHeaders(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "must specify at least one allowed origin")
 }
+
+func TestAPIEnableCORSPreflightOptions(t *testing.T) {
+	conf := NewServerCORSConfig()
+	conf.Enabled = true
+	conf.AllowedOrigins = []string{"foo"}
+	conf.AllowedMethods = []string{"POST"}
+	conf.AllowedHeaders = []string{"Content-Type", "X-Api-Key"}
+	conf.AllowCredentials = true
+	conf.MaxAge = 600
+
+	tmpHandler := http.NewServeMux()
+	tmpHandler.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("1.2.3"))
+	})
+
+	handler, err := conf.WrapHandler(tmpHandler)
+	require.NoError(t, err)
+
+	request, _ := http.NewRequest("OPTIONS", "/version", http.NoBody)
+	request.Header.Add("Origin", "foo")
+	request.Header.Add("Access-Control-Request-Method", "POST")
+	request.Header.Add("Access-Control-Request-Headers", "X-Api-Key")
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "foo", response.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Api-Key", response.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "true", response.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "600", response.Header().Get("Access-Control-Max-Age"))
+
+	request, _ = http.NewRequest("OPTIONS", "/version", http.NoBody)
+	request.Header.Add("Origin", "foo")
+	request.Header.Add("Access-Control-Request-Method", "DELETE")
+
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, response.Code)
+}
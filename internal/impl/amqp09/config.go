@@ -42,4 +42,5 @@ const (
 	messageIDField              = "message_id"
 	userIDField                 = "user_id"
 	appIDField                  = "app_id"
+	batchingField               = "batching"
 )
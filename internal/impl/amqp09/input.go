@@ -15,6 +15,7 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 
 	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/input/span"
 	"github.com/benthosdev/benthos/v4/public/service"
 )
 
@@ -112,12 +113,17 @@ You can access these metadata fields using [function interpolation](/docs/config
 			Default(0).
 			Advanced(),
 		service.NewTLSToggledField(tlsField),
+		span.ExtractTracingSpanMappingDocs().Version("4.24.0"),
 	)
 }
 
 func init() {
 	err := service.RegisterInput("amqp_0_9", amqp09InputSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
-		return amqp09ReaderFromParsed(conf, mgr)
+		rdr, err := amqp09ReaderFromParsed(conf, mgr)
+		if err != nil {
+			return nil, err
+		}
+		return span.NewInput("amqp_0_9", conf, rdr, mgr)
 	})
 	if err != nil {
 		panic(err)
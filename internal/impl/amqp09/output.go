@@ -13,7 +13,7 @@ import (
 
 	amqp "github.com/rabbitmq/amqp091-go"
 
-	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/output/span"
 	"github.com/benthosdev/benthos/v4/public/service"
 )
 
@@ -28,6 +28,12 @@ It's possible for this output type to create the target exchange by setting `+"`
 
 TLS is automatic when connecting to an `+"`amqps`"+` URL, but custom settings can be enabled in the `+"`tls`"+` section.
 
+### Delivery Guarantees
+
+Messages are published in confirm mode and are only acknowledged once the server has confirmed each individual message. When the `+"`mandatory`"+` flag is set, messages that are returned by the server as unroutable are treated as failed deliveries, which can be handled with [error handling patterns](/docs/configuration/error_handling) such as a `+"`fallback`"+` output.
+
+When a [batching policy](/docs/configuration/batching) is configured all messages of a batch are published before waiting for their confirmations, which can greatly improve throughput. Individual messages of a batch that fail to be confirmed are retried without resending the rest of the batch.
+
 The fields 'key', 'exchange' and 'type' can be dynamically set using function interpolations described [here](/docs/configuration/interpolation#bloblang-queries).`).
 		Fields(
 			service.NewURLListField(urlsField).
@@ -117,17 +123,25 @@ The fields 'key', 'exchange' and 'type' can be dynamically set using function in
 				Advanced().
 				Default(""),
 			service.NewTLSToggledField(tlsField),
+			service.NewBatchPolicyField(batchingField).
+				Version("4.24.0"),
+			span.InjectTracingSpanMappingDocs().Version("4.24.0"),
 		)
 }
 
 func init() {
-	err := service.RegisterOutput("amqp_0_9", amqp09OutputSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (service.Output, int, error) {
-		maxInFlight, err := conf.FieldMaxInFlight()
-		if err != nil {
-			return nil, 0, err
+	err := service.RegisterBatchOutput("amqp_0_9", amqp09OutputSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPol service.BatchPolicy, maxInFlight int, err error) {
+		if maxInFlight, err = conf.FieldMaxInFlight(); err != nil {
+			return
+		}
+		if batchPol, err = conf.FieldBatchPolicy(batchingField); err != nil {
+			return
 		}
-		w, err := amqp09WriterFromParsed(conf, mgr)
-		return w, maxInFlight, err
+		if out, err = amqp09WriterFromParsed(conf, mgr); err != nil {
+			return
+		}
+		out, err = span.NewBatchOutput("amqp_0_9", conf, out, mgr)
+		return
 	})
 
 	if err != nil {
@@ -167,9 +181,9 @@ type amqp09Writer struct {
 
 	log *service.Logger
 
-	conn       *amqp.Connection
-	amqpChan   *amqp.Channel
-	returnChan <-chan amqp.Return
+	conn     *amqp.Connection
+	amqpChan *amqp.Channel
+	confirms *confirmTracker
 
 	connLock sync.RWMutex
 }
@@ -292,9 +306,8 @@ func (a *amqp09Writer) Connect(ctx context.Context) error {
 
 	a.conn = conn
 	a.amqpChan = amqpChan
-	if a.mandatory || a.immediate {
-		a.returnChan = amqpChan.NotifyReturn(make(chan amqp.Return, 1))
-	}
+	a.confirms = newConfirmTracker()
+	a.confirms.listen(amqpChan, a.mandatory || a.immediate)
 
 	if sExchange, isStatic := a.exchange.Static(); isStatic {
 		if err := a.declareExchange(sExchange); err != nil {
@@ -313,6 +326,7 @@ func (a *amqp09Writer) disconnect() error {
 
 	if a.amqpChan != nil {
 		a.amqpChan = nil
+		a.confirms = nil
 	}
 	if a.conn != nil {
 		if err := a.conn.Close(); err != nil {
@@ -358,11 +372,11 @@ func (a *amqp09Writer) declareExchange(exchange string) error {
 	return nil
 }
 
-func (a *amqp09Writer) Write(ctx context.Context, msg *service.Message) error {
+func (a *amqp09Writer) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
 	a.connLock.RLock()
 	conn := a.conn
 	amqpChan := a.amqpChan
-	returnChan := a.returnChan
+	confirms := a.confirms
 	a.connLock.RUnlock()
 
 	if conn == nil {
@@ -375,77 +389,134 @@ func (a *amqp09Writer) Write(ctx context.Context, msg *service.Message) error {
 		defer cancel()
 	}
 
+	var batchErr *service.BatchError
+	setErr := func(i int, err error) {
+		if batchErr == nil {
+			batchErr = service.NewBatchError(batch, err)
+		}
+		batchErr.Failed(i, err)
+	}
+
+	// Publish the entire batch before waiting for any confirmations so that
+	// the batch is confirmed as a single window.
+	pending := make([]*pendingPublish, len(batch))
+	for i, msg := range batch {
+		exchange, bindingKey, publishing, err := a.publishingFromMessage(msg)
+		if err != nil {
+			setErr(i, err)
+			continue
+		}
+		if err := a.declareExchange(exchange); err != nil {
+			setErr(i, fmt.Errorf("amqp failed to declare exchange: %s", err))
+			continue
+		}
+		if pending[i], err = confirms.publish(ctx, amqpChan, exchange, bindingKey, a.mandatory, a.immediate, publishing); err != nil {
+			_ = a.disconnect()
+			a.log.Errorf("Failed to send message: %v\n", err)
+			return service.ErrNotConnected
+		}
+	}
+
+	for i, p := range pending {
+		if p == nil {
+			continue
+		}
+		if err := p.wait(ctx); err != nil {
+			a.log.Errorf("Failed to acknowledge message: %v\n", err)
+			setErr(i, err)
+		}
+	}
+	if batchErr != nil {
+		return batchErr
+	}
+	return nil
+}
+
+func (a *amqp09Writer) publishingFromMessage(msg *service.Message) (exchange, bindingKey string, publishing amqp.Publishing, err error) {
 	msgBytes, err := msg.AsBytes()
 	if err != nil {
-		return err
+		return
 	}
 
-	bindingKey, err := a.key.TryString(msg)
+	bindingKey, err = a.key.TryString(msg)
 	if err != nil {
-		return fmt.Errorf("binding key interpolation error: %w", err)
+		err = fmt.Errorf("binding key interpolation error: %w", err)
+		return
 	}
 	bindingKey = strings.ReplaceAll(bindingKey, "/", ".")
 
 	msgType, err := a.msgType.TryString(msg)
 	if err != nil {
-		return fmt.Errorf("msg type interpolation error: %w", err)
+		err = fmt.Errorf("msg type interpolation error: %w", err)
+		return
 	}
 	msgType = strings.ReplaceAll(msgType, "/", ".")
 
 	contentType, err := a.contentType.TryString(msg)
 	if err != nil {
-		return fmt.Errorf("content type interpolation error: %w", err)
+		err = fmt.Errorf("content type interpolation error: %w", err)
+		return
 	}
 	contentEncoding, err := a.contentEncoding.TryString(msg)
 	if err != nil {
-		return fmt.Errorf("content encoding interpolation error: %w", err)
+		err = fmt.Errorf("content encoding interpolation error: %w", err)
+		return
 	}
 
 	priorityString, err := a.priority.TryString(msg)
 	if err != nil {
-		return fmt.Errorf("priority interpolation error: %w", err)
+		err = fmt.Errorf("priority interpolation error: %w", err)
+		return
 	}
 
 	var priority uint8
 	if priorityString != "" {
-		priorityInt, err := strconv.Atoi(priorityString)
-		if err != nil {
-			return fmt.Errorf("failed to parse valid integer from priority expression: %w", err)
+		var priorityInt int
+		if priorityInt, err = strconv.Atoi(priorityString); err != nil {
+			err = fmt.Errorf("failed to parse valid integer from priority expression: %w", err)
+			return
 		}
 		if priorityInt > 9 || priorityInt < 0 {
-			return fmt.Errorf("invalid priority parsed from expression, must be <= 9 and >= 0, got %v", priorityInt)
+			err = fmt.Errorf("invalid priority parsed from expression, must be <= 9 and >= 0, got %v", priorityInt)
+			return
 		}
 		priority = uint8(priorityInt)
 	}
 
 	correlationID, err := a.correlationID.TryString(msg)
 	if err != nil {
-		return fmt.Errorf("correlation ID interpolation error: %w", err)
+		err = fmt.Errorf("correlation ID interpolation error: %w", err)
+		return
 	}
 
 	replyTo, err := a.replyTo.TryString(msg)
 	if err != nil {
-		return fmt.Errorf("reply to interpolation error: %w", err)
+		err = fmt.Errorf("reply to interpolation error: %w", err)
+		return
 	}
 
 	expiration, err := a.expiration.TryString(msg)
 	if err != nil {
-		return fmt.Errorf("expiration interpolation error: %w", err)
+		err = fmt.Errorf("expiration interpolation error: %w", err)
+		return
 	}
 
 	messageID, err := a.messageID.TryString(msg)
 	if err != nil {
-		return fmt.Errorf("message ID interpolation error: %w", err)
+		err = fmt.Errorf("message ID interpolation error: %w", err)
+		return
 	}
 
 	userID, err := a.userID.TryString(msg)
 	if err != nil {
-		return fmt.Errorf("user ID interpolation error: %w", err)
+		err = fmt.Errorf("user ID interpolation error: %w", err)
+		return
 	}
 
 	appID, err := a.appID.TryString(msg)
 	if err != nil {
-		return fmt.Errorf("app ID interpolation error: %w", err)
+		err = fmt.Errorf("app ID interpolation error: %w", err)
+		return
 	}
 	headers := amqp.Table{}
 	_ = a.metaFilter.WalkMut(msg, func(k string, v any) error {
@@ -453,57 +524,29 @@ func (a *amqp09Writer) Write(ctx context.Context, msg *service.Message) error {
 		return nil
 	})
 
-	exchange, err := a.exchange.TryString(msg)
+	exchange, err = a.exchange.TryString(msg)
 	if err != nil {
-		return fmt.Errorf("exchange name interpolation error: %w", err)
-	}
-	if err := a.declareExchange(exchange); err != nil {
-		return fmt.Errorf("amqp failed to declare exchange: %s", err)
-	}
-
-	conf, err := amqpChan.PublishWithDeferredConfirmWithContext(
-		ctx,
-		exchange,    // publish to an exchange
-		bindingKey,  // routing to 0 or more queues
-		a.mandatory, // mandatory
-		a.immediate, // immediate
-		amqp.Publishing{
-			Headers:         headers,
-			ContentType:     contentType,
-			ContentEncoding: contentEncoding,
-			Body:            msgBytes,
-			DeliveryMode:    a.deliveryMode, // 1=non-persistent, 2=persistent
-			Priority:        priority,       // 0-9
-			Type:            msgType,
-			CorrelationId:   correlationID,
-			ReplyTo:         replyTo,
-			Expiration:      expiration,
-			MessageId:       messageID,
-			AppId:           appID,
-			UserId:          userID,
-			// a bunch of application/implementation-specific fields
-		},
-	)
-	if err != nil {
-		_ = a.disconnect()
-		a.log.Errorf("Failed to send message: %v\n", err)
-		return service.ErrNotConnected
-	}
-	if !conf.Wait() {
-		a.log.Error("Failed to acknowledge message.")
-		return component.ErrNoAck
-	}
-	if returnChan != nil {
-		select {
-		case _, open := <-returnChan:
-			if !open {
-				return fmt.Errorf("acknowledgement not supported, ensure server supports immediate and mandatory flags")
-			}
-			return component.ErrNoAck
-		default:
-		}
-	}
-	return nil
+		err = fmt.Errorf("exchange name interpolation error: %w", err)
+		return
+	}
+
+	publishing = amqp.Publishing{
+		Headers:         headers,
+		ContentType:     contentType,
+		ContentEncoding: contentEncoding,
+		Body:            msgBytes,
+		DeliveryMode:    a.deliveryMode, // 1=non-persistent, 2=persistent
+		Priority:        priority,       // 0-9
+		Type:            msgType,
+		CorrelationId:   correlationID,
+		ReplyTo:         replyTo,
+		Expiration:      expiration,
+		MessageId:       messageID,
+		AppId:           appID,
+		UserId:          userID,
+		// a bunch of application/implementation-specific fields
+	}
+	return
 }
 
 func (a *amqp09Writer) Close(context.Context) error {
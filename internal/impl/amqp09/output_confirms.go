@@ -0,0 +1,179 @@
+package amqp09
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+var errConfirmChanClosed = errors.New("amqp channel closed before the publish was confirmed")
+
+// pendingPublish is a message that has been published and is awaiting a
+// confirmation from the server.
+type pendingPublish struct {
+	exchange string
+	key      string
+	body     []byte
+
+	returned *amqp.Return
+	resChan  chan error
+}
+
+// wait blocks until the server has confirmed the publish, the publish has been
+// returned as unroutable, or the context is cancelled.
+func (p *pendingPublish) wait(ctx context.Context) error {
+	select {
+	case err := <-p.resChan:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// confirmTracker tracks publisher confirms and returned messages for a single
+// AMQP channel in order to resolve the outcome of each individual publish.
+//
+// The server always sends a basic.return for a message before the basic.ack
+// of that same message. Both are consumed from a single goroutine, and the
+// returns channel is unbuffered so that the client library cannot deliver the
+// ack of a message before its return has been fully processed.
+type confirmTracker struct {
+	mut     sync.Mutex
+	pending map[uint64]*pendingPublish
+	closed  bool
+}
+
+func newConfirmTracker() *confirmTracker {
+	return &confirmTracker{
+		pending: map[uint64]*pendingPublish{},
+	}
+}
+
+// listen registers confirmation and return listeners on an AMQP channel and
+// begins tracking them.
+func (c *confirmTracker) listen(amqpChan *amqp.Channel, returns bool) {
+	confirms := amqpChan.NotifyPublish(make(chan amqp.Confirmation, 1024))
+
+	var returnChan chan amqp.Return
+	if returns {
+		// NOTE: Must remain unbuffered, see the type docs for details.
+		returnChan = amqpChan.NotifyReturn(make(chan amqp.Return))
+	}
+	go c.loop(confirms, returnChan)
+}
+
+// publish a message and register it as pending. The lock is held for the
+// duration of the publish so that a confirmation or return cannot be processed
+// before the publish is registered.
+func (c *confirmTracker) publish(
+	ctx context.Context,
+	amqpChan *amqp.Channel,
+	exchange, key string,
+	mandatory, immediate bool,
+	msg amqp.Publishing,
+) (*pendingPublish, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.closed {
+		return nil, errConfirmChanClosed
+	}
+
+	dConf, err := amqpChan.PublishWithDeferredConfirmWithContext(ctx, exchange, key, mandatory, immediate, msg)
+	if err != nil {
+		return nil, err
+	}
+	if dConf == nil {
+		return nil, errors.New("amqp channel is not in confirm mode")
+	}
+	return c.registerLocked(dConf.DeliveryTag, exchange, key, msg.Body), nil
+}
+
+func (c *confirmTracker) registerLocked(tag uint64, exchange, key string, body []byte) *pendingPublish {
+	p := &pendingPublish{
+		exchange: exchange,
+		key:      key,
+		body:     body,
+		resChan:  make(chan error, 1),
+	}
+	c.pending[tag] = p
+	return p
+}
+
+func (c *confirmTracker) loop(confirms <-chan amqp.Confirmation, returns <-chan amqp.Return) {
+	defer c.close()
+	for {
+		select {
+		case conf, open := <-confirms:
+			if !open {
+				return
+			}
+			c.confirm(conf)
+		case ret, open := <-returns:
+			if !open {
+				returns = nil
+				continue
+			}
+			c.markReturned(ret)
+		}
+	}
+}
+
+func (c *confirmTracker) confirm(conf amqp.Confirmation) {
+	c.mut.Lock()
+	p, exists := c.pending[conf.DeliveryTag]
+	delete(c.pending, conf.DeliveryTag)
+	c.mut.Unlock()
+	if !exists {
+		return
+	}
+
+	switch {
+	case p.returned != nil:
+		p.resChan <- fmt.Errorf("message was returned by the server: %v (%v)", p.returned.ReplyText, p.returned.ReplyCode)
+	case !conf.Ack:
+		p.resChan <- component.ErrNoAck
+	default:
+		p.resChan <- nil
+	}
+}
+
+// markReturned flags the oldest unconfirmed publish that matches a returned
+// message. Returns do not carry a delivery tag and therefore the publish is
+// identified by its exchange, routing key and contents.
+func (c *confirmTracker) markReturned(ret amqp.Return) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var oldestTag uint64
+	var oldest *pendingPublish
+	for tag, p := range c.pending {
+		if p.returned != nil || p.exchange != ret.Exchange || p.key != ret.RoutingKey || !bytes.Equal(p.body, ret.Body) {
+			continue
+		}
+		if oldest == nil || tag < oldestTag {
+			oldestTag, oldest = tag, p
+		}
+	}
+	if oldest != nil {
+		oldest.returned = &ret
+	}
+}
+
+func (c *confirmTracker) close() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.closed = true
+	for tag, p := range c.pending {
+		p.resChan <- service.ErrNotConnected
+		delete(c.pending, tag)
+	}
+}
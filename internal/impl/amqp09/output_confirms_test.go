@@ -0,0 +1,72 @@
+package amqp09
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestConfirmTrackerOutcomes(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	confirms := make(chan amqp.Confirmation)
+	returns := make(chan amqp.Return)
+
+	c := newConfirmTracker()
+	go c.loop(confirms, returns)
+
+	c.mut.Lock()
+	p1 := c.registerLocked(1, "foo", "a", []byte("hello"))
+	p2 := c.registerLocked(2, "foo", "b", []byte("hello"))
+	p3 := c.registerLocked(3, "foo", "a", []byte("hello"))
+	p4 := c.registerLocked(4, "foo", "a", []byte("world"))
+	c.mut.Unlock()
+
+	// A return should only mark the oldest matching publish.
+	returns <- amqp.Return{Exchange: "foo", RoutingKey: "a", Body: []byte("hello"), ReplyCode: 312, ReplyText: "NO_ROUTE"}
+
+	confirms <- amqp.Confirmation{DeliveryTag: 1, Ack: true}
+	confirms <- amqp.Confirmation{DeliveryTag: 2, Ack: true}
+	confirms <- amqp.Confirmation{DeliveryTag: 3, Ack: true}
+	confirms <- amqp.Confirmation{DeliveryTag: 4, Ack: false}
+
+	err := p1.wait(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NO_ROUTE")
+
+	require.NoError(t, p2.wait(ctx))
+	require.NoError(t, p3.wait(ctx))
+	assert.Equal(t, component.ErrNoAck, p4.wait(ctx))
+}
+
+func TestConfirmTrackerClosed(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	confirms := make(chan amqp.Confirmation)
+
+	c := newConfirmTracker()
+	go c.loop(confirms, nil)
+
+	c.mut.Lock()
+	p1 := c.registerLocked(1, "foo", "a", []byte("hello"))
+	p2 := c.registerLocked(2, "foo", "a", []byte("world"))
+	c.mut.Unlock()
+
+	confirms <- amqp.Confirmation{DeliveryTag: 1, Ack: true}
+	close(confirms)
+
+	require.NoError(t, p1.wait(ctx))
+	assert.Equal(t, service.ErrNotConnected, p2.wait(ctx))
+
+	_, err := c.publish(ctx, nil, "foo", "a", false, false, amqp.Publishing{})
+	assert.Equal(t, errConfirmChanClosed, err)
+}
@@ -0,0 +1,202 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// canonicalSchema returns the Parsing Canonical Form of an Avro schema as
+// described in https://avro.apache.org/docs/1.11.1/specification/#parsing-canonical-form-for-schemas.
+//
+// The canonical form computed by goavro is not used as it depends on map
+// iteration order for schemas containing fields that share the name of a
+// named type, which results in fingerprints that change randomly.
+func canonicalSchema(schema string) (string, error) {
+	var v any
+	if err := json.Unmarshal([]byte(schema), &v); err != nil {
+		return "", err
+	}
+	c := &canonicalizer{named: map[string]struct{}{}}
+	var sb strings.Builder
+	if err := c.write(&sb, v, ""); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+type canonicalizer struct {
+	named map[string]struct{}
+}
+
+func (c *canonicalizer) writeName(sb *strings.Builder, name, namespace string) {
+	if _, exists := avroPrimitives[name]; !exists {
+		if full := fullName(name, namespace); full != name {
+			if _, exists := c.named[full]; exists {
+				name = full
+			}
+		}
+	}
+	sb.WriteString(`"` + name + `"`)
+}
+
+func (c *canonicalizer) write(sb *strings.Builder, v any, namespace string) error {
+	switch t := v.(type) {
+	case string:
+		c.writeName(sb, t, namespace)
+		return nil
+	case []any:
+		sb.WriteByte('[')
+		for i, b := range t {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			if err := c.write(sb, b, namespace); err != nil {
+				return err
+			}
+		}
+		sb.WriteByte(']')
+		return nil
+	case map[string]any:
+		return c.writeObject(sb, t, namespace)
+	}
+	return fmt.Errorf("unexpected schema type: %T", v)
+}
+
+func (c *canonicalizer) writeObject(sb *strings.Builder, m map[string]any, namespace string) error {
+	typ, isStr := m["type"].(string)
+	if !isStr {
+		return c.write(sb, m["type"], namespace)
+	}
+
+	switch typ {
+	case "record", "error", "enum", "fixed":
+		name, _ := m["name"].(string)
+		if name == "" {
+			return fmt.Errorf("%v type is missing a name", typ)
+		}
+		if ns, _ := m["namespace"].(string); ns != "" && !strings.Contains(name, ".") {
+			namespace = ns
+		}
+		name = fullName(name, namespace)
+		namespace = namespaceOf(name)
+		c.named[name] = struct{}{}
+
+		sb.WriteString(`{"name":"` + name + `","type":"` + typ + `"`)
+		switch typ {
+		case "enum":
+			symbols, _ := m["symbols"].([]any)
+			sb.WriteString(`,"symbols":[`)
+			for i, s := range symbols {
+				if i > 0 {
+					sb.WriteByte(',')
+				}
+				sStr, _ := s.(string)
+				sb.WriteString(`"` + sStr + `"`)
+			}
+			sb.WriteByte(']')
+		case "fixed":
+			size, err := schemaSize(m["size"])
+			if err != nil {
+				return err
+			}
+			sb.WriteString(`,"size":` + strconv.FormatInt(size, 10))
+		default:
+			fields, _ := m["fields"].([]any)
+			sb.WriteString(`,"fields":[`)
+			for i, f := range fields {
+				fMap, _ := f.(map[string]any)
+				if fMap == nil {
+					return errors.New("record field must be an object")
+				}
+				if i > 0 {
+					sb.WriteByte(',')
+				}
+				fName, _ := fMap["name"].(string)
+				sb.WriteString(`{"name":"` + fName + `","type":`)
+				if err := c.write(sb, fMap["type"], namespace); err != nil {
+					return err
+				}
+				sb.WriteByte('}')
+			}
+			sb.WriteByte(']')
+		}
+		sb.WriteByte('}')
+		return nil
+	case "array":
+		sb.WriteString(`{"type":"array","items":`)
+		if err := c.write(sb, m["items"], namespace); err != nil {
+			return err
+		}
+		sb.WriteByte('}')
+		return nil
+	case "map":
+		sb.WriteString(`{"type":"map","values":`)
+		if err := c.write(sb, m["values"], namespace); err != nil {
+			return err
+		}
+		sb.WriteByte('}')
+		return nil
+	}
+
+	// Primitive types and references to named types are reduced to their
+	// simple form.
+	c.writeName(sb, typ, namespace)
+	return nil
+}
+
+func schemaSize(v any) (int64, error) {
+	switch t := v.(type) {
+	case float64:
+		return int64(t), nil
+	case string:
+		return strconv.ParseInt(t, 10, 64)
+	}
+	return 0, errors.New("fixed type is missing a size")
+}
+
+//------------------------------------------------------------------------------
+
+const rabinEmpty = uint64(0xc15d213aa4d7a795)
+
+var rabinTable = func() (t [256]uint64) {
+	for i := range t {
+		fp := uint64(i)
+		for j := 0; j < 8; j++ {
+			fp = (fp >> 1) ^ (rabinEmpty & -(fp & 1))
+		}
+		t[i] = fp
+	}
+	return
+}()
+
+// schemaFingerprint returns the CRC-64-AVRO fingerprint of the Parsing
+// Canonical Form of a schema, which identifies the schema of single object
+// encoded documents.
+func schemaFingerprint(schema string) (uint64, error) {
+	canonical, err := canonicalSchema(schema)
+	if err != nil {
+		return 0, err
+	}
+	fp := rabinEmpty
+	for _, b := range []byte(canonical) {
+		fp = (fp >> 8) ^ rabinTable[byte(fp)^b]
+	}
+	return fp, nil
+}
+
+// singleObjectHeaderLen is the length of the header of single object encoded
+// documents, consisting of a two byte marker and an eight byte fingerprint.
+const singleObjectHeaderLen = 10
+
+// singleObjectFingerprint returns the schema fingerprint of a single object
+// encoded document along with the remaining binary encoded document.
+func singleObjectFingerprint(b []byte) (uint64, []byte, error) {
+	if len(b) < singleObjectHeaderLen || b[0] != 0xc3 || b[1] != 0x01 {
+		return 0, nil, errors.New("document is not single object encoded")
+	}
+	return binary.LittleEndian.Uint64(b[2:singleObjectHeaderLen]), b[singleObjectHeaderLen:], nil
+}
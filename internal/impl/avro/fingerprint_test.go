@@ -0,0 +1,86 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SchemaFingerprint exposes schema fingerprints to the tests of the avro_test
+// package.
+var SchemaFingerprint = schemaFingerprint
+
+func TestCanonicalSchema(t *testing.T) {
+	tests := map[string]struct {
+		schema   string
+		expected string
+	}{
+		"primitive": {
+			schema:   `{ "type": "string", "logicalType": "uuid" }`,
+			expected: `"string"`,
+		},
+		"fields sharing type names": {
+			schema: `{
+  "namespace": "com.example",
+  "type": "record",
+  "name": "user",
+  "doc": "A user.",
+  "fields": [
+    { "name": "status", "type": { "type": "enum", "name": "status", "symbols": [ "ACTIVE" ], "default": "ACTIVE" } },
+    { "name": "previous", "type": [ "null", "status" ], "default": null },
+    { "name": "address", "type": { "type": "record", "namespace": "com.geo", "name": "address", "fields": [
+      { "name": "city", "type": "string" }
+    ] } },
+    { "name": "tags", "type": { "type": "map", "values": { "type": "array", "items": "string" } } },
+    { "name": "hash", "type": { "type": "fixed", "name": "hash", "size": 16 } }
+  ]
+}`,
+			expected: `{"name":"com.example.user","type":"record","fields":[` +
+				`{"name":"status","type":{"name":"com.example.status","type":"enum","symbols":["ACTIVE"]}},` +
+				`{"name":"previous","type":["null","com.example.status"]},` +
+				`{"name":"address","type":{"name":"com.geo.address","type":"record","fields":[{"name":"city","type":"string"}]}},` +
+				`{"name":"tags","type":{"type":"map","values":{"type":"array","items":"string"}}},` +
+				`{"name":"hash","type":{"name":"com.example.hash","type":"fixed","size":16}}]}`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				canonical, err := canonicalSchema(test.schema)
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, canonical)
+			}
+		})
+	}
+}
+
+func TestSchemaFingerprintMatchesGoavro(t *testing.T) {
+	// The fingerprints of goavro are correct for schemas without fields that
+	// share the names of types.
+	for _, schema := range []string{
+		`"int"`,
+		`{ "type": "array", "items": "long" }`,
+		`{
+  "namespace": "com.example",
+  "type": "record",
+  "name": "user",
+  "fields": [
+    { "name": "id", "type": "long" },
+    { "name": "email", "type": [ "null", "string" ], "default": null },
+    { "name": "kind", "type": { "type": "enum", "name": "user_kind", "symbols": [ "A", "B" ] } },
+    { "name": "previous_kind", "type": "user_kind" }
+  ]
+}`,
+	} {
+		codec, err := goavro.NewCodec(schema)
+		require.NoError(t, err)
+
+		fingerprint, err := schemaFingerprint(schema)
+		require.NoError(t, err)
+		assert.Equal(t, codec.Rabin, fingerprint, schema)
+	}
+}
@@ -32,7 +32,27 @@ specifies how the source documents are encoded.
 ### ` + "`from_json`" + `
 
 Attempts to convert JSON documents into Avro documents according to the
-specified encoding.`).
+specified encoding.
+
+## Schema Evolution
+
+When converting documents to JSON that were written with older versions of a
+schema it is possible to specify those versions with the ` + "`writer_schemas`" + `
+and ` + "`writer_schema_paths`" + ` fields, in which case documents are decoded with the
+writer schema and then resolved against the ` + "`schema`" + ` as the reader schema,
+following the [schema resolution rules](https://avro.apache.org/docs/1.11.1/specification/#schema-resolution)
+of the Avro specification. Fields that do not exist in the reader schema are
+removed, fields that do not exist in the writer schema are given their default
+values, and numeric and string types are promoted where necessary. This
+results in JSON documents that match the reader schema regardless of the schema
+version they were written with.
+
+With the ` + "`single`" + ` encoding the writer schema of each document is selected by
+matching its fingerprint against the CRC-64-AVRO fingerprints of the Parsing
+Canonical Form of the reader and writer schemas, and therefore any number of
+writer schemas can be specified. With the ` + "`textual`" + ` and ` + "`binary`" + `
+encodings documents do not identify their schema and only a single writer
+schema can be specified.`).
 		Field(service.NewStringEnumField("operator", "to_json", "from_json").Description("The [operator](#operators) to execute")).
 		Field(service.NewStringEnumField("encoding", "textual", "binary", "single").Description("An Avro encoding format to use for conversions to and from a schema.").Default("textual")).
 		Field(service.NewStringField("schema").Description("A full Avro schema to use.").Default("")).
@@ -40,7 +60,18 @@ specified encoding.`).
 			Description("The path of a schema document to apply. Use either this or the `schema` field.").
 			Default("").
 			Example("file://path/to/spec.avsc").
-			Example("http://localhost:8081/path/to/spec/versions/1"))
+			Example("http://localhost:8081/path/to/spec/versions/1")).
+		Field(service.NewStringListField("writer_schemas").
+			Description("A list of full Avro schemas that documents may have been written with, which are resolved against the reader `schema` when using the `to_json` operator. Refer to [schema evolution](#schema-evolution) for more details.").
+			Default([]any{}).
+			Advanced().
+			Version("4.24.0")).
+		Field(service.NewStringListField("writer_schema_paths").
+			Description("A list of paths of schema documents that documents may have been written with, which are resolved against the reader `schema` when using the `to_json` operator.").
+			Default([]any{}).
+			Example([]any{"file://path/to/spec_v1.avsc", "file://path/to/spec_v2.avsc"}).
+			Advanced().
+			Version("4.24.0"))
 }
 
 func init() {
@@ -154,6 +185,106 @@ func strToAvroOperator(opStr, encoding string, codec *goavro.Codec) (avroOperato
 	return nil, fmt.Errorf("operator not recognised: %v", opStr)
 }
 
+func newAvroResolvingToJSONOperator(encoding string, reader *goavro.Codec, readerSchema string, writerSchemas []string) (avroOperator, error) {
+	readerNode, err := parseSchemaNode(readerSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %v", err)
+	}
+
+	type writer struct {
+		codec *goavro.Codec
+		node  *schemaNode
+	}
+	var writers []writer
+	for i, schema := range writerSchemas {
+		codec, err := goavro.NewCodec(schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse writer schema %v: %v", i, err)
+		}
+		node, err := parseSchemaNode(schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse writer schema %v: %v", i, err)
+		}
+		writers = append(writers, writer{codec: codec, node: node})
+	}
+
+	resolve := func(part *service.Message, w writer, jObj any) error {
+		resolved, err := resolveDatum(w.node, readerNode, jObj)
+		if err != nil {
+			return fmt.Errorf("failed to resolve Avro document against reader schema: %v", err)
+		}
+		part.SetStructuredMut(resolved)
+		return nil
+	}
+
+	switch encoding {
+	case "textual", "binary":
+		if len(writers) > 1 {
+			return nil, fmt.Errorf("multiple writer schemas are only supported with the single encoding")
+		}
+		w := writers[0]
+		return func(part *service.Message) error {
+			pBytes, err := part.AsBytes()
+			if err != nil {
+				return err
+			}
+			var jObj any
+			if encoding == "textual" {
+				jObj, _, err = w.codec.NativeFromTextual(pBytes)
+			} else {
+				jObj, _, err = w.codec.NativeFromBinary(pBytes)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to convert Avro document to JSON: %v", err)
+			}
+			return resolve(part, w, jObj)
+		}, nil
+	case "single":
+		// Schemas are selected by fingerprints that we compute ourselves, as
+		// those of goavro codecs are not deterministic for all schemas.
+		readerFingerprint, err := schemaFingerprint(readerSchema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schema: %v", err)
+		}
+		byFingerprint := make(map[uint64]writer, len(writers))
+		for i, w := range writers {
+			fingerprint, err := schemaFingerprint(writerSchemas[i])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse writer schema %v: %v", i, err)
+			}
+			byFingerprint[fingerprint] = w
+		}
+		return func(part *service.Message) error {
+			pBytes, err := part.AsBytes()
+			if err != nil {
+				return err
+			}
+			fingerprint, body, err := singleObjectFingerprint(pBytes)
+			if err != nil {
+				return fmt.Errorf("failed to convert Avro document to JSON: %v", err)
+			}
+			if fingerprint == readerFingerprint {
+				jObj, _, err := reader.NativeFromBinary(body)
+				if err != nil {
+					return fmt.Errorf("failed to convert Avro document to JSON: %v", err)
+				}
+				part.SetStructuredMut(jObj)
+				return nil
+			}
+			w, exists := byFingerprint[fingerprint]
+			if !exists {
+				return fmt.Errorf("no writer schema found matching fingerprint %#x", fingerprint)
+			}
+			jObj, _, err := w.codec.NativeFromBinary(body)
+			if err != nil {
+				return fmt.Errorf("failed to convert Avro document to JSON: %v", err)
+			}
+			return resolve(part, w, jObj)
+		}, nil
+	}
+	return nil, fmt.Errorf("encoding '%v' not recognised", encoding)
+}
+
 func loadSchema(schemaPath string) (string, error) {
 	t := &http.Transport{}
 	t.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
@@ -207,6 +338,24 @@ func newAvroFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (serv
 			return nil, fmt.Errorf("failed to load Avro schema definition: %v", err)
 		}
 	}
+
+	var writerSchemas, writerSchemaPaths []string
+	if writerSchemas, err = conf.FieldStringList("writer_schemas"); err != nil {
+		return nil, err
+	}
+	if writerSchemaPaths, err = conf.FieldStringList("writer_schema_paths"); err != nil {
+		return nil, err
+	}
+	for _, path := range writerSchemaPaths {
+		if !(strings.HasPrefix(path, "file://") || strings.HasPrefix(path, "http://")) {
+			return nil, fmt.Errorf("invalid writer_schema_paths provided, must start with file:// or http://")
+		}
+		writerSchema, err := loadSchema(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Avro writer schema definition: %v", err)
+		}
+		writerSchemas = append(writerSchemas, writerSchema)
+	}
 	if schema == "" {
 		return nil, errors.New("a schema must be specified with either the `schema` or `schema_path` fields")
 	}
@@ -216,6 +365,16 @@ func newAvroFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (serv
 		return nil, fmt.Errorf("failed to parse schema: %v", err)
 	}
 
+	if len(writerSchemas) > 0 {
+		if operator != "to_json" {
+			return nil, errors.New("writer schemas are only supported with the to_json operator")
+		}
+		if a.operator, err = newAvroResolvingToJSONOperator(encoding, codec, schema, writerSchemas); err != nil {
+			return nil, err
+		}
+		return a, nil
+	}
+
 	if a.operator, err = strToAvroOperator(operator, encoding, codec); err != nil {
 		return nil, err
 	}
@@ -2,17 +2,20 @@ package avro_test
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"os"
 	"reflect"
 	"strconv"
 	"testing"
 
+	"github.com/linkedin/goavro/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/impl/avro"
 	"github.com/benthosdev/benthos/v4/internal/manager/mock"
 	"github.com/benthosdev/benthos/v4/internal/message"
 )
@@ -335,3 +338,166 @@ avro:
 		t.Error("expected error from loading non existent schema file")
 	}
 }
+
+const (
+	testWriterSchemaV1 = `{
+  "namespace": "com.example",
+  "type": "record",
+  "name": "user",
+  "fields": [
+    { "name": "id", "type": "int" },
+    { "name": "name", "type": "string" },
+    { "name": "legacy", "type": "string" },
+    { "name": "status", "type": { "type": "enum", "name": "status", "symbols": [ "ACTIVE", "BANNED" ] } },
+    { "name": "scores", "type": { "type": "array", "items": "float" } }
+  ]
+}`
+	testWriterSchemaV2 = `{
+  "namespace": "com.example",
+  "type": "record",
+  "name": "user",
+  "fields": [
+    { "name": "id", "type": "long" },
+    { "name": "name", "type": "string" },
+    { "name": "status", "type": { "type": "enum", "name": "status", "symbols": [ "ACTIVE", "BANNED" ] } },
+    { "name": "scores", "type": { "type": "array", "items": "double" } }
+  ]
+}`
+	testReaderSchema = `{
+  "namespace": "com.example",
+  "type": "record",
+  "name": "user",
+  "fields": [
+    { "name": "id", "type": "long" },
+    { "name": "full_name", "aliases": [ "name" ], "type": [ "null", "string" ], "default": null },
+    { "name": "email", "type": [ "null", "string" ], "default": null },
+    { "name": "address", "type": { "type": "record", "name": "address", "fields": [
+      { "name": "city", "type": "string", "default": "unknown" }
+    ] }, "default": {} },
+    { "name": "status", "type": { "type": "enum", "name": "status", "symbols": [ "ACTIVE", "UNKNOWN" ], "default": "UNKNOWN" } },
+    { "name": "scores", "type": { "type": "array", "items": "double" } }
+  ]
+}`
+)
+
+// singleFromNative single object encodes a document with the fingerprint of
+// the Parsing Canonical Form of its schema, as goavro codecs do not compute
+// fingerprints deterministically for the test schemas.
+func singleFromNative(t testing.TB, codec *goavro.Codec, schema string, datum any) []byte {
+	t.Helper()
+
+	fingerprint, err := avro.SchemaFingerprint(schema)
+	require.NoError(t, err)
+
+	header := []byte{0xc3, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint64(header[2:], fingerprint)
+
+	b, err := codec.BinaryFromNative(header, datum)
+	require.NoError(t, err)
+	return b
+}
+
+func TestAvroSchemaEvolution(t *testing.T) {
+	v1, err := goavro.NewCodec(testWriterSchemaV1)
+	require.NoError(t, err)
+
+	v2, err := goavro.NewCodec(testWriterSchemaV2)
+	require.NoError(t, err)
+
+	reader, err := goavro.NewCodec(testReaderSchema)
+	require.NoError(t, err)
+
+	v1Single := singleFromNative(t, v1, testWriterSchemaV1, map[string]any{
+		"id": 1, "name": "foo", "legacy": "bar", "status": "BANNED", "scores": []any{float32(0.5)},
+	})
+
+	v2Single := singleFromNative(t, v2, testWriterSchemaV2, map[string]any{
+		"id": 2, "name": "baz", "status": "ACTIVE", "scores": []any{1.5},
+	})
+
+	readerSingle := singleFromNative(t, reader, testReaderSchema, map[string]any{
+		"id": 3, "full_name": nil, "email": goavro.Union("string", "qux@example.com"),
+		"address": map[string]any{"city": "london"}, "status": "ACTIVE", "scores": []any{},
+	})
+
+	v1Binary, err := v1.BinaryFromNative(nil, map[string]any{
+		"id": 4, "name": "quz", "legacy": "", "status": "ACTIVE", "scores": []any{},
+	})
+	require.NoError(t, err)
+
+	newProc := func(encoding string, writerSchemas ...string) processor.Config {
+		return testAvroEvolutionConfig(t, encoding, writerSchemas...)
+	}
+
+	singleProc, err := mock.NewManager().NewProcessor(newProc("single", testWriterSchemaV1, testWriterSchemaV2))
+	require.NoError(t, err)
+
+	msgs, res := singleProc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{v1Single, v2Single, readerSingle}))
+	require.NoError(t, res)
+	require.Len(t, msgs, 1)
+	require.NoError(t, msgs[0].Get(0).ErrorGet())
+
+	assert.Equal(t, []string{
+		`{"address":{"city":"unknown"},"email":null,"full_name":{"string":"foo"},"id":1,"scores":[0.5],"status":"UNKNOWN"}`,
+		`{"address":{"city":"unknown"},"email":null,"full_name":{"string":"baz"},"id":2,"scores":[1.5],"status":"ACTIVE"}`,
+		`{"address":{"city":"london"},"email":{"string":"qux@example.com"},"full_name":null,"id":3,"scores":[],"status":"ACTIVE"}`,
+	}, bytesToStrings(message.GetAllBytes(msgs[0])))
+
+	binaryProc, err := mock.NewManager().NewProcessor(newProc("binary", testWriterSchemaV1))
+	require.NoError(t, err)
+
+	msgs, res = binaryProc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{v1Binary}))
+	require.NoError(t, res)
+	require.Len(t, msgs, 1)
+	require.NoError(t, msgs[0].Get(0).ErrorGet())
+	assert.Equal(t, `{"address":{"city":"unknown"},"email":null,"full_name":{"string":"quz"},"id":4,"scores":[],"status":"ACTIVE"}`, string(msgs[0].Get(0).AsBytes()))
+
+	_, err = mock.NewManager().NewProcessor(newProc("binary", testWriterSchemaV1, testWriterSchemaV2))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "multiple writer schemas are only supported with the single encoding")
+}
+
+func TestAvroSchemaEvolutionUnknownFingerprint(t *testing.T) {
+	v1, err := goavro.NewCodec(testWriterSchemaV1)
+	require.NoError(t, err)
+
+	v1Single := singleFromNative(t, v1, testWriterSchemaV1, map[string]any{
+		"id": 1, "name": "foo", "legacy": "bar", "status": "ACTIVE", "scores": []any{},
+	})
+
+	proc, err := mock.NewManager().NewProcessor(testAvroEvolutionConfig(t, "single", testWriterSchemaV2))
+	require.NoError(t, err)
+
+	msgs, res := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{v1Single}))
+	require.NoError(t, res)
+	require.Len(t, msgs, 1)
+
+	err = msgs[0].Get(0).ErrorGet()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no writer schema found matching fingerprint")
+}
+
+func testAvroEvolutionConfig(t testing.TB, encoding string, writerSchemas ...string) processor.Config {
+	t.Helper()
+
+	confBytes, err := yaml.Marshal(map[string]any{
+		"avro": map[string]any{
+			"operator":       "to_json",
+			"encoding":       encoding,
+			"schema":         testReaderSchema,
+			"writer_schemas": writerSchemas,
+		},
+	})
+	require.NoError(t, err)
+
+	conf := processor.NewConfig()
+	require.NoError(t, yaml.Unmarshal(confBytes, &conf))
+	return conf
+}
+
+func bytesToStrings(b [][]byte) (s []string) {
+	for _, v := range b {
+		s = append(s, string(v))
+	}
+	return
+}
@@ -0,0 +1,627 @@
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// schemaNode is a parsed Avro schema with named type references resolved,
+// containing only the information required for schema resolution as described
+// in https://avro.apache.org/docs/1.11.1/specification/#schema-resolution.
+type schemaNode struct {
+	typ     string
+	logical string
+	scale   int // decimal
+
+	// Named types (record, enum and fixed)
+	name    string
+	aliases []string
+
+	fields []*schemaField // record
+
+	symbols        []string // enum
+	enumDefault    string
+	hasEnumDefault bool
+
+	items    *schemaNode   // array items and map values
+	branches []*schemaNode // union
+}
+
+type schemaField struct {
+	name       string
+	aliases    []string
+	node       *schemaNode
+	def        any
+	hasDefault bool
+}
+
+var avroPrimitives = map[string]struct{}{
+	"null": {}, "boolean": {}, "int": {}, "long": {}, "float": {}, "double": {}, "bytes": {}, "string": {},
+}
+
+func fullName(name, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func namespaceOf(fullName string) string {
+	if i := strings.LastIndex(fullName, "."); i >= 0 {
+		return fullName[:i]
+	}
+	return ""
+}
+
+func shortName(fullName string) string {
+	return fullName[strings.LastIndex(fullName, ".")+1:]
+}
+
+type schemaParser struct {
+	named map[string]*schemaNode
+}
+
+func parseSchemaNode(schema string) (*schemaNode, error) {
+	var v any
+	if err := json.Unmarshal([]byte(schema), &v); err != nil {
+		return nil, err
+	}
+	p := &schemaParser{named: map[string]*schemaNode{}}
+	return p.parse(v, "")
+}
+
+func (p *schemaParser) parse(v any, namespace string) (*schemaNode, error) {
+	switch t := v.(type) {
+	case string:
+		if _, exists := avroPrimitives[t]; exists {
+			return &schemaNode{typ: t}, nil
+		}
+		if n, exists := p.named[fullName(t, namespace)]; exists {
+			return n, nil
+		}
+		if n, exists := p.named[t]; exists {
+			return n, nil
+		}
+		return nil, fmt.Errorf("unknown type: %v", t)
+	case []any:
+		n := &schemaNode{typ: "union"}
+		for _, b := range t {
+			bn, err := p.parse(b, namespace)
+			if err != nil {
+				return nil, err
+			}
+			n.branches = append(n.branches, bn)
+		}
+		return n, nil
+	case map[string]any:
+		return p.parseObject(t, namespace)
+	}
+	return nil, fmt.Errorf("unexpected schema type: %T", v)
+}
+
+func (p *schemaParser) parseObject(m map[string]any, namespace string) (*schemaNode, error) {
+	typ, isStr := m["type"].(string)
+	if !isStr {
+		return p.parse(m["type"], namespace)
+	}
+
+	n := &schemaNode{typ: typ}
+	n.logical, _ = m["logicalType"].(string)
+	if scale, ok := m["scale"].(float64); ok {
+		n.scale = int(scale)
+	}
+
+	switch typ {
+	case "record", "error", "enum", "fixed":
+		if typ == "error" {
+			n.typ = "record"
+		}
+		name, _ := m["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("%v type is missing a name", typ)
+		}
+		if ns, _ := m["namespace"].(string); ns != "" && !strings.Contains(name, ".") {
+			namespace = ns
+		}
+		n.name = fullName(name, namespace)
+		namespace = namespaceOf(n.name)
+		if aliases, _ := m["aliases"].([]any); aliases != nil {
+			for _, a := range aliases {
+				if aStr, _ := a.(string); aStr != "" {
+					n.aliases = append(n.aliases, fullName(aStr, namespace))
+				}
+			}
+		}
+		// Registered before parsing fields in order to support recursive types.
+		p.named[n.name] = n
+	}
+
+	switch n.typ {
+	case "record":
+		fields, _ := m["fields"].([]any)
+		for _, f := range fields {
+			fm, ok := f.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("record %v has an invalid field", n.name)
+			}
+			sf := &schemaField{}
+			sf.name, _ = fm["name"].(string)
+			if aliases, _ := fm["aliases"].([]any); aliases != nil {
+				for _, a := range aliases {
+					if aStr, _ := a.(string); aStr != "" {
+						sf.aliases = append(sf.aliases, aStr)
+					}
+				}
+			}
+			sf.def, sf.hasDefault = fm["default"]
+
+			var err error
+			if sf.node, err = p.parse(fm["type"], namespace); err != nil {
+				return nil, fmt.Errorf("record %v field %v: %w", n.name, sf.name, err)
+			}
+			n.fields = append(n.fields, sf)
+		}
+	case "enum":
+		symbols, _ := m["symbols"].([]any)
+		for _, s := range symbols {
+			sStr, _ := s.(string)
+			n.symbols = append(n.symbols, sStr)
+		}
+		n.enumDefault, n.hasEnumDefault = m["default"].(string)
+	case "array":
+		var err error
+		if n.items, err = p.parse(m["items"], namespace); err != nil {
+			return nil, err
+		}
+	case "map":
+		var err error
+		if n.items, err = p.parse(m["values"], namespace); err != nil {
+			return nil, err
+		}
+	case "fixed":
+	default:
+		if _, exists := avroPrimitives[n.typ]; !exists {
+			return nil, fmt.Errorf("unknown type: %v", n.typ)
+		}
+	}
+	return n, nil
+}
+
+//------------------------------------------------------------------------------
+
+// Logical types that goavro decodes into native Go types, and which are
+// therefore reflected in the names of union branches.
+var goavroLogicalTypes = map[string]struct{}{
+	"long.timestamp-millis": {},
+	"long.timestamp-micros": {},
+	"int.time-millis":       {},
+	"long.time-micros":      {},
+	"int.date":              {},
+	"bytes.decimal":         {},
+}
+
+func (n *schemaNode) logicalName() string {
+	if n.logical == "" {
+		return ""
+	}
+	if _, exists := goavroLogicalTypes[n.typ+"."+n.logical]; exists {
+		return n.logical
+	}
+	return ""
+}
+
+// branchName returns the name of a type when it is a branch of a union, which
+// is how goavro identifies union values.
+func (n *schemaNode) branchName() string {
+	switch n.typ {
+	case "record", "enum", "fixed":
+		return n.name
+	}
+	if l := n.logicalName(); l != "" {
+		return n.typ + "." + l
+	}
+	return n.typ
+}
+
+func (n *schemaNode) String() string {
+	if n.name != "" {
+		return n.name
+	}
+	return n.branchName()
+}
+
+func namesMatch(writer, reader *schemaNode) bool {
+	if shortName(writer.name) == shortName(reader.name) {
+		return true
+	}
+	for _, a := range reader.aliases {
+		if a == writer.name || shortName(a) == shortName(writer.name) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPromotable(writerType, readerType string) bool {
+	switch writerType {
+	case "int":
+		return readerType == "long" || readerType == "float" || readerType == "double"
+	case "long":
+		return readerType == "float" || readerType == "double"
+	case "float":
+		return readerType == "double"
+	case "string":
+		return readerType == "bytes"
+	case "bytes":
+		return readerType == "string"
+	}
+	return false
+}
+
+func schemasMatch(writer, reader *schemaNode, allowPromotion bool) bool {
+	if writer.typ != reader.typ {
+		return allowPromotion && isPromotable(writer.typ, reader.typ)
+	}
+	switch writer.typ {
+	case "record", "enum", "fixed":
+		return namesMatch(writer, reader)
+	}
+	return true
+}
+
+//------------------------------------------------------------------------------
+
+// resolveDatum converts a datum decoded by goavro with a writer schema into the
+// equivalent datum of a reader schema, projecting records onto the fields of
+// the reader, filling missing fields with defaults and promoting types.
+func resolveDatum(writer, reader *schemaNode, v any) (any, error) {
+	if writer.typ == "union" {
+		branch, bv, err := writerUnionBranch(writer, v)
+		if err != nil {
+			return nil, err
+		}
+		return resolveDatum(branch, reader, bv)
+	}
+
+	if reader.typ == "union" {
+		for _, allowPromotion := range []bool{false, true} {
+			for _, rb := range reader.branches {
+				if !schemasMatch(writer, rb, allowPromotion) {
+					continue
+				}
+				res, err := resolveDatum(writer, rb, v)
+				if err != nil {
+					return nil, err
+				}
+				if rb.typ == "null" {
+					return nil, nil
+				}
+				return map[string]any{rb.branchName(): res}, nil
+			}
+		}
+		return nil, fmt.Errorf("writer type %v does not match any branch of the reader union", writer)
+	}
+
+	if !schemasMatch(writer, reader, true) {
+		return nil, fmt.Errorf("writer type %v cannot be resolved to reader type %v", writer, reader)
+	}
+
+	switch reader.typ {
+	case "record":
+		return resolveRecord(writer, reader, v)
+	case "enum":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected enum symbol, got %T", v)
+		}
+		for _, sym := range reader.symbols {
+			if sym == s {
+				return s, nil
+			}
+		}
+		if reader.hasEnumDefault {
+			return reader.enumDefault, nil
+		}
+		return nil, fmt.Errorf("symbol %v does not exist in reader enum %v", s, reader.name)
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("expected array, got %T", v)
+		}
+		res := make([]any, len(arr))
+		for i, e := range arr {
+			var err error
+			if res[i], err = resolveDatum(writer.items, reader.items, e); err != nil {
+				return nil, fmt.Errorf("index %v: %w", i, err)
+			}
+		}
+		return res, nil
+	case "map":
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected map, got %T", v)
+		}
+		res := make(map[string]any, len(m))
+		for k, e := range m {
+			var err error
+			if res[k], err = resolveDatum(writer.items, reader.items, e); err != nil {
+				return nil, fmt.Errorf("key %v: %w", k, err)
+			}
+		}
+		return res, nil
+	case "fixed", "null":
+		return v, nil
+	}
+	return resolvePrimitive(writer, reader, v)
+}
+
+func writerUnionBranch(writer *schemaNode, v any) (*schemaNode, any, error) {
+	if v == nil {
+		for _, b := range writer.branches {
+			if b.typ == "null" {
+				return b, nil, nil
+			}
+		}
+		return nil, nil, errors.New("writer union does not contain null")
+	}
+	m, ok := v.(map[string]any)
+	if !ok || len(m) != 1 {
+		return nil, nil, fmt.Errorf("expected union value, got %T", v)
+	}
+	for k, bv := range m {
+		for _, b := range writer.branches {
+			if b.branchName() == k {
+				return b, bv, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("writer union does not contain type %v", k)
+	}
+	return nil, nil, nil
+}
+
+func resolveRecord(writer, reader *schemaNode, v any) (any, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected record, got %T", v)
+	}
+
+	writerFields := make(map[string]*schemaField, len(writer.fields))
+	for _, wf := range writer.fields {
+		writerFields[wf.name] = wf
+	}
+
+	res := make(map[string]any, len(reader.fields))
+	for _, rf := range reader.fields {
+		wf, exists := writerFields[rf.name]
+		for i := 0; !exists && i < len(rf.aliases); i++ {
+			wf, exists = writerFields[rf.aliases[i]]
+		}
+
+		var err error
+		if exists {
+			res[rf.name], err = resolveDatum(wf.node, rf.node, m[wf.name])
+		} else if rf.hasDefault {
+			res[rf.name], err = defaultDatum(rf.node, rf.def)
+		} else {
+			err = errors.New("field does not exist in the writer schema and has no default")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("field %v: %w", rf.name, err)
+		}
+	}
+	return res, nil
+}
+
+// resolvePrimitive promotes a primitive datum, converting between the native
+// types of logical types where the writer and reader differ.
+func resolvePrimitive(writer, reader *schemaNode, v any) (any, error) {
+	if writer.typ == reader.typ && writer.logicalName() == reader.logicalName() {
+		return v, nil
+	}
+
+	v = fromLogicalNative(writer, v)
+	switch reader.typ {
+	case "long":
+		if i, ok := v.(int32); ok {
+			v = int64(i)
+		}
+	case "float":
+		switch t := v.(type) {
+		case int32:
+			v = float32(t)
+		case int64:
+			v = float32(t)
+		}
+	case "double":
+		switch t := v.(type) {
+		case int32:
+			v = float64(t)
+		case int64:
+			v = float64(t)
+		case float32:
+			v = float64(t)
+		}
+	case "bytes":
+		if s, ok := v.(string); ok {
+			v = []byte(s)
+		}
+	case "string":
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+	}
+	return toLogicalNative(reader, v), nil
+}
+
+// fromLogicalNative converts the native value goavro decodes a logical type
+// into the native value of its underlying type.
+func fromLogicalNative(n *schemaNode, v any) any {
+	switch t := v.(type) {
+	case time.Time:
+		switch n.logicalName() {
+		case "timestamp-millis":
+			return t.UnixMilli()
+		case "timestamp-micros":
+			return t.UnixMicro()
+		case "date":
+			return int32(t.Unix() / 86400)
+		}
+	case time.Duration:
+		switch n.logicalName() {
+		case "time-millis":
+			return int32(t / time.Millisecond)
+		case "time-micros":
+			return int64(t / time.Microsecond)
+		}
+	}
+	return v
+}
+
+// toLogicalNative converts the native value of an underlying type into the
+// native value goavro uses for a logical type.
+func toLogicalNative(n *schemaNode, v any) any {
+	switch t := v.(type) {
+	case int64:
+		switch n.logicalName() {
+		case "timestamp-millis":
+			return time.UnixMilli(t).UTC()
+		case "timestamp-micros":
+			return time.UnixMicro(t).UTC()
+		case "time-micros":
+			return time.Duration(t) * time.Microsecond
+		}
+	case int32:
+		switch n.logicalName() {
+		case "date":
+			return time.Unix(int64(t)*86400, 0).UTC()
+		case "time-millis":
+			return time.Duration(t) * time.Millisecond
+		}
+	}
+	return v
+}
+
+// defaultDatum converts the JSON default value of a field into a native datum
+// of its type.
+func defaultDatum(n *schemaNode, def any) (any, error) {
+	switch n.typ {
+	case "null":
+		if def != nil {
+			return nil, fmt.Errorf("expected null default, got %T", def)
+		}
+		return nil, nil
+	case "union":
+		// The default value of a union corresponds to its first branch.
+		if len(n.branches) == 0 {
+			return nil, errors.New("empty union")
+		}
+		first := n.branches[0]
+		res, err := defaultDatum(first, def)
+		if err != nil || first.typ == "null" {
+			return nil, err
+		}
+		return map[string]any{first.branchName(): res}, nil
+	case "record":
+		m, ok := def.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected object default, got %T", def)
+		}
+		res := make(map[string]any, len(n.fields))
+		for _, f := range n.fields {
+			fDef, exists := m[f.name]
+			if !exists {
+				if !f.hasDefault {
+					return nil, fmt.Errorf("field %v is missing from default", f.name)
+				}
+				fDef = f.def
+			}
+			var err error
+			if res[f.name], err = defaultDatum(f.node, fDef); err != nil {
+				return nil, fmt.Errorf("field %v: %w", f.name, err)
+			}
+		}
+		return res, nil
+	case "array":
+		arr, ok := def.([]any)
+		if !ok {
+			return nil, fmt.Errorf("expected array default, got %T", def)
+		}
+		res := make([]any, len(arr))
+		for i, e := range arr {
+			var err error
+			if res[i], err = defaultDatum(n.items, e); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case "map":
+		m, ok := def.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected object default, got %T", def)
+		}
+		res := make(map[string]any, len(m))
+		for k, e := range m {
+			var err error
+			if res[k], err = defaultDatum(n.items, e); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case "enum", "string":
+		s, ok := def.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string default, got %T", def)
+		}
+		return s, nil
+	case "bytes", "fixed":
+		s, ok := def.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string default, got %T", def)
+		}
+		// Byte defaults are strings where each code point is a byte value.
+		b := make([]byte, 0, len(s))
+		for _, r := range s {
+			b = append(b, byte(r))
+		}
+		if n.logicalName() == "decimal" {
+			return decimalFromBytes(b, n), nil
+		}
+		return b, nil
+	case "boolean":
+		b, ok := def.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected boolean default, got %T", def)
+		}
+		return b, nil
+	}
+
+	f, ok := def.(float64)
+	if !ok {
+		return nil, fmt.Errorf("expected number default, got %T", def)
+	}
+	switch n.typ {
+	case "int":
+		return toLogicalNative(n, int32(f)), nil
+	case "long":
+		return toLogicalNative(n, int64(f)), nil
+	case "float":
+		return float32(f), nil
+	case "double":
+		return f, nil
+	}
+	return nil, fmt.Errorf("unsupported default for type %v", n.typ)
+}
+
+// decimalFromBytes converts the two's complement representation of a decimal
+// into the *big.Rat that goavro uses for decimals.
+func decimalFromBytes(b []byte, n *schemaNode) *big.Rat {
+	unscaled := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	return new(big.Rat).SetFrac(unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n.scale)), nil))
+}
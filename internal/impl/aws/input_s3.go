@@ -48,6 +48,7 @@ const (
 	s3iFieldForcePathStyleURLs = "force_path_style_urls"
 	s3iFieldDeleteObjects      = "delete_objects"
 	s3iFieldSQS                = "sqs"
+	s3iFieldInventory          = "inventory"
 )
 
 type s3iSQSConfig struct {
@@ -97,6 +98,7 @@ type s3iConfig struct {
 	ForcePathStyleURLs bool
 	DeleteObjects      bool
 	SQS                s3iSQSConfig
+	Inventory          s3iInventoryConfig
 }
 
 func s3iConfigFromParsed(pConf *service.ParsedConfig) (conf s3iConfig, err error) {
@@ -123,6 +125,11 @@ func s3iConfigFromParsed(pConf *service.ParsedConfig) (conf s3iConfig, err error
 			return
 		}
 	}
+	if pConf.Contains(s3iFieldInventory) {
+		if conf.Inventory, err = s3iInventoryConfigFromParsed(pConf.Namespace(s3iFieldInventory)); err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -142,6 +149,12 @@ If your notification events are being routed to SQS via an SNS topic then the ev
 
 When using SQS please make sure you have sensible values for `+"`sqs.max_messages`"+` and also the visibility timeout of the queue itself. When Benthos consumes an S3 object the SQS message that triggered it is not deleted until the S3 object has been sent onwards. This ensures at-least-once crash resiliency, but also means that if the S3 object takes longer to process than the visibility timeout of your queue then the same objects might be processed multiple times.
 
+## Backfilling from Inventory Reports
+
+Walking the objects of a bucket containing millions of keys requires a large number of list requests. Alternatively, when an [S3 Inventory](https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-inventory.html) report of the bucket is available the field `+"`inventory.manifest`"+` can be set to the URL of its `+"`manifest.json`"+` file, in which case the objects listed within the report are downloaded instead. Reports in the CSV and Parquet formats are supported, delete markers are skipped and the field `+"`prefix`"+` can be used in order to filter the listed keys.
+
+When a `+"`inventory.cache`"+` is specified the position within the report is persisted as objects are acknowledged, allowing a backfill to resume from where it left off after a restart.
+
 ## Downloading Large Files
 
 When downloading large files it's often necessary to process it in streamed parts in order to avoid loading the entire file in memory at a given time. In order to do this a `+"[`codec`](#codec)"+` can be specified that determines how to break the input into smaller individual messages.
@@ -168,7 +181,7 @@ This input adds the following metadata fields to each message:
 You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries). Note that user defined metadata is case insensitive within AWS, and it is likely that the keys will be received in a capitalized form, if you wish to make them consistent you can map all metadata keys to lower or uppercase using a Bloblang mapping such as `+"`meta = meta().map_each_key(key -> key.lowercase())`"+`.`).
 		Fields(
 			service.NewStringField(s3iFieldBucket).
-				Description("The bucket to consume from. If the field `sqs.url` is specified this field is optional.").
+				Description("The bucket to consume from. If the field `sqs.url` or `inventory.manifest` is specified this field is optional.").
 				Default(""),
 			service.NewStringField(s3iFieldPrefix).
 				Description("An optional path prefix, if set only objects with the prefix are consumed when walking a bucket.").
@@ -224,6 +237,7 @@ You can access these metadata fields using [function interpolation](/docs/config
 			).
 				Description("Consume SQS messages in order to trigger key downloads.").
 				Optional(),
+			s3iInventoryField().Optional(),
 		)
 }
 
@@ -263,6 +277,8 @@ func init() {
 			// If we're not pulling events directly from an SQS queue then
 			// there's no concept of propagating nacks upstream, therefore wrap
 			// our reader within a preserver in order to retry indefinitely.
+			// This includes inventory reports, where the cursor is only moved
+			// once an object is successfully delivered.
 			if conf.SQS.URL == "" {
 				rdr = input.NewAsyncPreserver(rdr)
 			}
@@ -678,6 +694,7 @@ type awsS3Reader struct {
 	objectMut sync.Mutex
 	object    *s3PendingObject
 
+	mgr bundle.NewManagement
 	log log.Modular
 }
 
@@ -690,15 +707,27 @@ type s3PendingObject struct {
 
 // NewAmazonS3 creates a new Amazon S3 bucket reader.Type.
 func newAmazonS3Reader(conf s3iConfig, sess *session.Session, nm bundle.NewManagement) (*awsS3Reader, error) {
-	if conf.Bucket == "" && conf.SQS.URL == "" {
-		return nil, errors.New("either a bucket or an sqs.url must be specified")
+	if conf.Bucket == "" && conf.SQS.URL == "" && conf.Inventory.Manifest == "" {
+		return nil, errors.New("either a bucket, an sqs.url or an inventory.manifest must be specified")
 	}
 	if conf.Prefix != "" && conf.SQS.URL != "" {
 		return nil, errors.New("cannot specify both a prefix and sqs.url")
 	}
+	if conf.Inventory.Manifest != "" {
+		if conf.SQS.URL != "" {
+			return nil, errors.New("cannot specify both an inventory.manifest and sqs.url")
+		}
+		if conf.Inventory.CheckpointLimit <= 0 {
+			return nil, errors.New("inventory.checkpoint_limit must be greater than zero")
+		}
+		if conf.Inventory.Cache != "" && !nm.ProbeCache(conf.Inventory.Cache) {
+			return nil, fmt.Errorf("cache resource '%v' was not found", conf.Inventory.Cache)
+		}
+	}
 	s := &awsS3Reader{
 		conf:    conf,
 		session: sess,
+		mgr:     nm,
 		log:     nm.Logger(),
 	}
 
@@ -721,6 +750,9 @@ func (a *awsS3Reader) getTargetReader(ctx context.Context) (s3ObjectTargetReader
 	if a.sqs != nil {
 		return newSQSTargetReader(a.conf, a.log, a.s3, a.sqs), nil
 	}
+	if a.conf.Inventory.Manifest != "" {
+		return newInventoryTargetReader(ctx, a.conf, a.log, a.mgr, a.s3, s3ObjectGetterFromClient(a.s3))
+	}
 	return newStaticTargetReader(ctx, a.conf, a.log, a.s3)
 }
 
@@ -747,7 +779,9 @@ func (a *awsS3Reader) Connect(ctx context.Context) error {
 		return err
 	}
 
-	if a.conf.SQS.URL == "" {
+	if a.conf.Inventory.Manifest != "" {
+		a.log.Infof("Downloading S3 objects listed in inventory report: %s\n", a.conf.Inventory.Manifest)
+	} else if a.conf.SQS.URL == "" {
 		a.log.Infof("Downloading S3 objects from bucket: %s\n", a.conf.Bucket)
 	} else {
 		a.log.Infof("Downloading S3 objects found in messages from SQS: %s\n", a.conf.SQS.URL)
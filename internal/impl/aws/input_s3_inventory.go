@@ -0,0 +1,406 @@
+package aws
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/checkpoint"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	// S3 Input Inventory Fields
+	s3iInventoryFieldManifest        = "manifest"
+	s3iInventoryFieldCache           = "cache"
+	s3iInventoryFieldCacheKey        = "cache_key"
+	s3iInventoryFieldCheckpointLimit = "checkpoint_limit"
+)
+
+type s3iInventoryConfig struct {
+	Manifest        string
+	Cache           string
+	CacheKey        string
+	CheckpointLimit int
+}
+
+func s3iInventoryConfigFromParsed(pConf *service.ParsedConfig) (conf s3iInventoryConfig, err error) {
+	if conf.Manifest, err = pConf.FieldString(s3iInventoryFieldManifest); err != nil {
+		return
+	}
+	if conf.Cache, err = pConf.FieldString(s3iInventoryFieldCache); err != nil {
+		return
+	}
+	if conf.CacheKey, err = pConf.FieldString(s3iInventoryFieldCacheKey); err != nil {
+		return
+	}
+	if conf.CheckpointLimit, err = pConf.FieldInt(s3iInventoryFieldCheckpointLimit); err != nil {
+		return
+	}
+	return
+}
+
+func s3iInventoryField() *service.ConfigField {
+	return service.NewObjectField(s3iFieldInventory,
+		service.NewStringField(s3iInventoryFieldManifest).
+			Description("An optional URL of the `manifest.json` file of an [S3 Inventory](https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-inventory.html) report. When specified the objects listed within the report are consumed instead of walking the bucket. Reports in the CSV and Parquet formats are supported.").
+			Default("").
+			Example("s3://inventory-bucket/source-bucket/daily/2024-01-01T01-00Z/manifest.json"),
+		service.NewStringField(s3iInventoryFieldCache).
+			Description("An optional [cache resource](/docs/components/caches/about) in which to persist a cursor of the objects that have been consumed from the report, allowing the input to resume from where it left off after a restart.").
+			Default(""),
+		service.NewStringField(s3iInventoryFieldCacheKey).
+			Description("The key under which the cursor is stored within the `cache`. When empty the manifest URL is used, and therefore a new report starts from the beginning.").
+			Default("").
+			Advanced(),
+		service.NewIntField(s3iInventoryFieldCheckpointLimit).
+			Description("The maximum number of objects that can be pending acknowledgement at any given time. Objects are consumed in the order of the report and the cursor only moves past an object once it and all objects before it have been acknowledged, this limit prevents a slow object from blocking the cursor indefinitely.").
+			Default(1024).
+			Advanced(),
+	).Description("Consume the objects listed within an S3 Inventory report, which avoids listing the objects of large buckets.")
+}
+
+//------------------------------------------------------------------------------
+
+// s3InventoryManifest is the subset of an S3 Inventory manifest file that is
+// used to locate the data files of a report.
+type s3InventoryManifest struct {
+	SourceBucket      string `json:"sourceBucket"`
+	DestinationBucket string `json:"destinationBucket"`
+	FileFormat        string `json:"fileFormat"`
+	FileSchema        string `json:"fileSchema"`
+	Files             []struct {
+		Key string `json:"key"`
+	} `json:"files"`
+}
+
+// filesBucket returns the bucket containing the data files of a report, which
+// is expressed as an ARN within the manifest.
+func (m s3InventoryManifest) filesBucket(fallback string) string {
+	if b := strings.TrimPrefix(m.DestinationBucket, "arn:aws:s3:::"); b != "" {
+		return b
+	}
+	return fallback
+}
+
+func parseS3URL(urlStr string) (bucket, key string, err error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != "s3" || u.Host == "" {
+		return "", "", fmt.Errorf("expected a URL of the form s3://bucket/key, got %v", urlStr)
+	}
+	return u.Host, strings.TrimPrefix(u.Path, "/"), nil
+}
+
+// S3InventoryRow is an object listed within an inventory report.
+type S3InventoryRow struct {
+	Bucket         string `parquet:"bucket"`
+	Key            string `parquet:"key"`
+	IsDeleteMarker bool   `parquet:"is_delete_marker,optional"`
+}
+
+// S3InventoryRowsReader returns the rows of an inventory data file.
+type S3InventoryRowsReader func() (*S3InventoryRow, error)
+
+func notImportedS3InventoryParquetFn(r io.Reader) (S3InventoryRowsReader, error) {
+	return nil, errors.New("unable to read Parquet inventory files as this binary does not import components/aws")
+}
+
+// S3InventoryParquetRowsFn is populated with the child `parquet` package when
+// imported.
+var S3InventoryParquetRowsFn = notImportedS3InventoryParquetFn
+
+func s3InventoryCSVRows(r io.Reader, fileSchema string) (S3InventoryRowsReader, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress inventory file: %w", err)
+	}
+
+	bucketIndex, keyIndex, deleteMarkerIndex := -1, -1, -1
+	for i, col := range strings.Split(fileSchema, ",") {
+		switch strings.TrimSpace(col) {
+		case "Bucket":
+			bucketIndex = i
+		case "Key":
+			keyIndex = i
+		case "IsDeleteMarker":
+			deleteMarkerIndex = i
+		}
+	}
+	if bucketIndex == -1 || keyIndex == -1 {
+		return nil, fmt.Errorf("inventory file schema '%v' does not contain both a Bucket and Key", fileSchema)
+	}
+
+	cr := csv.NewReader(gr)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	return func() (*S3InventoryRow, error) {
+		record, err := cr.Read()
+		if err != nil {
+			return nil, err
+		}
+		if len(record) <= bucketIndex || len(record) <= keyIndex {
+			return nil, fmt.Errorf("inventory row has %v columns, expected a Bucket and Key", len(record))
+		}
+		// Object keys within CSV reports are URL encoded.
+		key, err := url.QueryUnescape(record[keyIndex])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object key: %w", err)
+		}
+		row := &S3InventoryRow{
+			Bucket: record[bucketIndex],
+			Key:    key,
+		}
+		if deleteMarkerIndex >= 0 && len(record) > deleteMarkerIndex {
+			row.IsDeleteMarker = record[deleteMarkerIndex] == "true"
+		}
+		return row, nil
+	}, nil
+}
+
+//------------------------------------------------------------------------------
+
+// s3InventoryCursorRowBits is the number of bits of a cursor used for the row
+// within a file, the remaining bits are used for the index of the file.
+const s3InventoryCursorRowBits = 40
+
+func s3InventoryCursor(file, row int64) int64 {
+	return file<<s3InventoryCursorRowBits | row
+}
+
+func s3InventoryCursorSplit(c int64) (file, row int64) {
+	return c >> s3InventoryCursorRowBits, c & (1<<s3InventoryCursorRowBits - 1)
+}
+
+type s3ObjectGetter func(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+
+type inventoryTargetReader struct {
+	conf     s3iConfig
+	log      log.Modular
+	mgr      bundle.NewManagement
+	s3Client *s3.S3
+	get      s3ObjectGetter
+
+	manifest     s3InventoryManifest
+	filesBucket  string
+	cacheKey     string
+	checkpointer *checkpoint.Capped[int64]
+
+	// The next row to consume, rows before it are skipped.
+	file, row int64
+	rows      S3InventoryRowsReader
+	closer    io.Closer
+}
+
+func newInventoryTargetReader(
+	ctx context.Context,
+	conf s3iConfig,
+	log log.Modular,
+	mgr bundle.NewManagement,
+	s3Client *s3.S3,
+	get s3ObjectGetter,
+) (*inventoryTargetReader, error) {
+	manifestBucket, manifestKey, err := parseS3URL(conf.Inventory.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse inventory manifest: %w", err)
+	}
+
+	i := &inventoryTargetReader{
+		conf:         conf,
+		log:          log,
+		mgr:          mgr,
+		s3Client:     s3Client,
+		get:          get,
+		cacheKey:     conf.Inventory.CacheKey,
+		checkpointer: checkpoint.NewCapped[int64](int64(conf.Inventory.CheckpointLimit)),
+	}
+	if i.cacheKey == "" {
+		i.cacheKey = conf.Inventory.Manifest
+	}
+
+	mBody, err := get(ctx, manifestBucket, manifestKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download inventory manifest: %w", err)
+	}
+	defer mBody.Close()
+	if err := json.NewDecoder(mBody).Decode(&i.manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory manifest: %w", err)
+	}
+	switch i.manifest.FileFormat {
+	case "CSV", "Parquet":
+	default:
+		return nil, fmt.Errorf("inventory file format '%v' is not supported", i.manifest.FileFormat)
+	}
+	i.filesBucket = i.manifest.filesBucket(manifestBucket)
+
+	if conf.Inventory.Cache != "" {
+		var cursorBytes []byte
+		if cerr := mgr.AccessCache(ctx, conf.Inventory.Cache, func(c cache.V1) {
+			cursorBytes, err = c.Get(ctx, i.cacheKey)
+		}); cerr != nil {
+			return nil, fmt.Errorf("failed to access inventory cache: %w", cerr)
+		}
+		if err != nil && !errors.Is(err, component.ErrKeyNotFound) {
+			return nil, fmt.Errorf("failed to read inventory cursor: %w", err)
+		}
+		if err == nil {
+			if i.file, i.row, err = parseS3InventoryCursor(string(cursorBytes)); err != nil {
+				return nil, err
+			}
+			log.Infof("Resuming inventory report from file %v row %v\n", i.file, i.row)
+		}
+	}
+	return i, nil
+}
+
+func parseS3InventoryCursor(s string) (file, row int64, err error) {
+	fileStr, rowStr, ok := strings.Cut(s, ":")
+	if ok {
+		if file, err = strconv.ParseInt(fileStr, 10, 64); err == nil {
+			row, err = strconv.ParseInt(rowStr, 10, 64)
+		}
+	}
+	if !ok || err != nil {
+		return 0, 0, fmt.Errorf("failed to parse inventory cursor '%v'", s)
+	}
+	return
+}
+
+// nextRow returns the next row of the report along with its cursor, opening
+// the next data file when required.
+func (i *inventoryTargetReader) nextRow(ctx context.Context) (*S3InventoryRow, int64, error) {
+	for {
+		if i.rows == nil {
+			if i.file >= int64(len(i.manifest.Files)) {
+				return nil, 0, io.EOF
+			}
+			body, err := i.get(ctx, i.filesBucket, i.manifest.Files[i.file].Key)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to download inventory file: %w", err)
+			}
+			if i.manifest.FileFormat == "CSV" {
+				i.rows, err = s3InventoryCSVRows(body, i.manifest.FileSchema)
+			} else {
+				i.rows, err = S3InventoryParquetRowsFn(body)
+			}
+			if err != nil {
+				body.Close()
+				return nil, 0, err
+			}
+			i.closer = body
+
+			// Skip rows that were consumed before a restart.
+			for r := int64(0); r < i.row; r++ {
+				if _, err := i.rows(); err != nil {
+					break
+				}
+			}
+		}
+
+		row, err := i.rows()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return nil, 0, fmt.Errorf("failed to read inventory file: %w", err)
+			}
+			i.closer.Close()
+			i.rows, i.closer = nil, nil
+			i.file++
+			i.row = 0
+			continue
+		}
+
+		cursor := s3InventoryCursor(i.file, i.row)
+		i.row++
+		return row, cursor, nil
+	}
+}
+
+func (i *inventoryTargetReader) commit(ctx context.Context, cursor int64) error {
+	if i.conf.Inventory.Cache == "" {
+		return nil
+	}
+	file, row := s3InventoryCursorSplit(cursor + 1)
+	var err error
+	if cerr := i.mgr.AccessCache(ctx, i.conf.Inventory.Cache, func(c cache.V1) {
+		err = c.Set(ctx, i.cacheKey, []byte(fmt.Sprintf("%v:%v", file, row)), nil)
+	}); cerr != nil {
+		err = cerr
+	}
+	return err
+}
+
+func (i *inventoryTargetReader) Pop(ctx context.Context) (*s3ObjectTarget, error) {
+	for {
+		row, cursor, err := i.nextRow(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		bucket := row.Bucket
+		if bucket == "" {
+			bucket = i.manifest.SourceBucket
+		}
+		release, err := i.checkpointer.Track(ctx, cursor, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		if row.IsDeleteMarker || !strings.HasPrefix(row.Key, i.conf.Prefix) {
+			if highest := release(); highest != nil {
+				if err := i.commit(ctx, *highest); err != nil {
+					i.log.Errorf("Failed to commit inventory cursor: %v\n", err)
+				}
+			}
+			continue
+		}
+
+		ackFn := func(ctx context.Context, err error) error {
+			if err != nil {
+				return nil
+			}
+			if highest := release(); highest != nil {
+				return i.commit(ctx, *highest)
+			}
+			return nil
+		}
+		ackFn = deleteS3ObjectAckFn(i.s3Client, bucket, row.Key, i.conf.DeleteObjects, ackFn)
+		return newS3ObjectTarget(row.Key, bucket, time.Time{}, ackFn), nil
+	}
+}
+
+func (i *inventoryTargetReader) Close(context.Context) error {
+	if i.closer != nil {
+		return i.closer.Close()
+	}
+	return nil
+}
+
+func s3ObjectGetterFromClient(s3Client *s3.S3) s3ObjectGetter {
+	return func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+		obj, err := s3Client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return obj.Body, nil
+	}
+}
@@ -0,0 +1,205 @@
+package aws
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/log"
+	bmock "github.com/benthosdev/benthos/v4/internal/manager/mock"
+)
+
+func testInventoryGetter(objects map[string][]byte) s3ObjectGetter {
+	return func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+		b, exists := objects[bucket+"/"+key]
+		if !exists {
+			return nil, fmt.Errorf("object %v/%v not found", bucket, key)
+		}
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+}
+
+func testInventoryGzip(t testing.TB, content string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func testInventoryCSVObjects(t testing.TB) map[string][]byte {
+	t.Helper()
+
+	return map[string][]byte{
+		"inv/src/manifest.json": []byte(`{
+  "sourceBucket": "src",
+  "destinationBucket": "arn:aws:s3:::inv",
+  "fileFormat": "CSV",
+  "fileSchema": "Bucket, Key, Size, IsDeleteMarker",
+  "files": [
+    { "key": "src/data/a.csv.gz" },
+    { "key": "src/data/b.csv.gz" }
+  ]
+}`),
+		"inv/src/data/a.csv.gz": testInventoryGzip(t, `"src","logs/foo%20bar.json","10","false"
+"src","logs/deleted.json","0","true"
+"src","other/baz.json","20","false"
+`),
+		"inv/src/data/b.csv.gz": testInventoryGzip(t, `"src","logs/qux.json","30","false"
+"src","logs/quz.json","40","false"
+`),
+	}
+}
+
+func testInventoryReader(t testing.TB, mgr *bmock.Manager, objects map[string][]byte, conf s3iConfig) *inventoryTargetReader {
+	t.Helper()
+
+	if conf.Inventory.Manifest == "" {
+		conf.Inventory.Manifest = "s3://inv/src/manifest.json"
+	}
+	if conf.Inventory.CheckpointLimit == 0 {
+		conf.Inventory.CheckpointLimit = 1024
+	}
+	r, err := newInventoryTargetReader(context.Background(), conf, log.Noop(), mgr, nil, testInventoryGetter(objects))
+	require.NoError(t, err)
+	return r
+}
+
+func popInventoryKeys(t testing.TB, r *inventoryTargetReader, n int) (targets []*s3ObjectTarget) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		target, err := r.Pop(context.Background())
+		require.NoError(t, err)
+		targets = append(targets, target)
+	}
+	return
+}
+
+func TestS3InventoryCSV(t *testing.T) {
+	r := testInventoryReader(t, bmock.NewManager(), testInventoryCSVObjects(t), s3iConfig{})
+
+	var keys []string
+	for {
+		target, err := r.Pop(context.Background())
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		assert.Equal(t, "src", target.bucket)
+		keys = append(keys, target.key)
+	}
+	assert.Equal(t, []string{
+		"logs/foo bar.json",
+		"other/baz.json",
+		"logs/qux.json",
+		"logs/quz.json",
+	}, keys)
+	require.NoError(t, r.Close(context.Background()))
+}
+
+func TestS3InventoryPrefix(t *testing.T) {
+	r := testInventoryReader(t, bmock.NewManager(), testInventoryCSVObjects(t), s3iConfig{Prefix: "other/"})
+
+	targets := popInventoryKeys(t, r, 1)
+	assert.Equal(t, "other/baz.json", targets[0].key)
+
+	_, err := r.Pop(context.Background())
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestS3InventoryCursor(t *testing.T) {
+	mgr := bmock.NewManager()
+	mgr.Caches["cursors"] = map[string]bmock.CacheItem{}
+
+	objects := testInventoryCSVObjects(t)
+	conf := s3iConfig{Inventory: s3iInventoryConfig{Cache: "cursors", CacheKey: "foo"}}
+
+	r := testInventoryReader(t, mgr, objects, conf)
+	targets := popInventoryKeys(t, r, 3)
+	assert.Equal(t, "logs/qux.json", targets[2].key)
+
+	// The cursor is only moved once all prior objects are acknowledged.
+	require.NoError(t, targets[1].ackFn(context.Background(), nil))
+	require.NoError(t, targets[2].ackFn(context.Background(), errors.New("nope")))
+	assert.NotContains(t, mgr.Caches["cursors"], "foo")
+
+	require.NoError(t, targets[0].ackFn(context.Background(), nil))
+	assert.Equal(t, "0:3", mgr.Caches["cursors"]["foo"].Value)
+
+	// Resuming starts from the first object that was not acknowledged.
+	r = testInventoryReader(t, mgr, objects, conf)
+	targets = popInventoryKeys(t, r, 2)
+	assert.Equal(t, "logs/qux.json", targets[0].key)
+	assert.Equal(t, "logs/quz.json", targets[1].key)
+
+	require.NoError(t, targets[0].ackFn(context.Background(), nil))
+	require.NoError(t, targets[1].ackFn(context.Background(), nil))
+	assert.Equal(t, "1:2", mgr.Caches["cursors"]["foo"].Value)
+
+	r = testInventoryReader(t, mgr, objects, conf)
+	_, err := r.Pop(context.Background())
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestS3InventoryParquet(t *testing.T) {
+	// Parquet files are decoded by the child parquet package, which is stubbed
+	// here with a decoder of one object per line.
+	defer func(fn func(io.Reader) (S3InventoryRowsReader, error)) {
+		S3InventoryParquetRowsFn = fn
+	}(S3InventoryParquetRowsFn)
+	S3InventoryParquetRowsFn = func(r io.Reader) (S3InventoryRowsReader, error) {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+		return func() (*S3InventoryRow, error) {
+			if len(lines) == 0 {
+				return nil, io.EOF
+			}
+			bucket, key, _ := strings.Cut(lines[0], "/")
+			lines = lines[1:]
+			return &S3InventoryRow{Bucket: bucket, Key: key, IsDeleteMarker: strings.HasPrefix(key, "deleted")}, nil
+		}, nil
+	}
+
+	r := testInventoryReader(t, bmock.NewManager(), map[string][]byte{
+		"inv/manifest.json": []byte(`{
+  "sourceBucket": "src",
+  "destinationBucket": "arn:aws:s3:::inv",
+  "fileFormat": "Parquet",
+  "fileSchema": "message s3.inventory { required binary bucket (STRING); required binary key (STRING); }",
+  "files": [ { "key": "data/a.parquet" } ]
+}`),
+		"inv/data/a.parquet": []byte("src/foo.json\nsrc/deleted.json\nsrc/baz.json\n"),
+	}, s3iConfig{Inventory: s3iInventoryConfig{Manifest: "s3://inv/manifest.json"}})
+
+	targets := popInventoryKeys(t, r, 2)
+	assert.Equal(t, "foo.json", targets[0].key)
+	assert.Equal(t, "baz.json", targets[1].key)
+
+	_, err := r.Pop(context.Background())
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestS3InventoryUnsupportedFormat(t *testing.T) {
+	_, err := newInventoryTargetReader(context.Background(), s3iConfig{
+		Inventory: s3iInventoryConfig{Manifest: "s3://inv/manifest.json", CheckpointLimit: 10},
+	}, log.Noop(), bmock.NewManager(), nil, testInventoryGetter(map[string][]byte{
+		"inv/manifest.json": []byte(`{"fileFormat":"ORC","files":[]}`),
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "inventory file format 'ORC' is not supported")
+}
@@ -2,6 +2,7 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"sync"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/cenkalti/backoff/v4"
 
 	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/input/span"
 	"github.com/benthosdev/benthos/v4/internal/impl/aws/config"
 	"github.com/benthosdev/benthos/v4/internal/shutdown"
 	"github.com/benthosdev/benthos/v4/public/service"
@@ -27,6 +29,8 @@ const (
 	sqsiFieldDeleteMessage       = "delete_message"
 	sqsiFieldResetVisibility     = "reset_visibility"
 	sqsiFieldMaxNumberOfMessages = "max_number_of_messages"
+	sqsiFieldVisibilityTimeout   = "visibility_timeout"
+	sqsiFieldMaxExtension        = "max_visibility_extension"
 
 	sqsiAttributeNameVisibilityTimeout = "VisibilityTimeout"
 )
@@ -37,6 +41,8 @@ type sqsiConfig struct {
 	DeleteMessage       bool
 	ResetVisibility     bool
 	MaxNumberOfMessages int
+	VisibilityTimeout   time.Duration
+	MaxExtension        time.Duration
 }
 
 func sqsiConfigFromParsed(pConf *service.ParsedConfig) (conf sqsiConfig, err error) {
@@ -55,6 +61,20 @@ func sqsiConfigFromParsed(pConf *service.ParsedConfig) (conf sqsiConfig, err err
 	if conf.MaxNumberOfMessages, err = pConf.FieldInt(sqsiFieldMaxNumberOfMessages); err != nil {
 		return
 	}
+	if durStr, _ := pConf.FieldString(sqsiFieldVisibilityTimeout); durStr != "" {
+		if conf.VisibilityTimeout, err = pConf.FieldDuration(sqsiFieldVisibilityTimeout); err != nil {
+			return
+		}
+		if conf.VisibilityTimeout < time.Second {
+			err = errors.New("visibility_timeout must be at least one second")
+			return
+		}
+	}
+	if durStr, _ := pConf.FieldString(sqsiFieldMaxExtension); durStr != "" {
+		if conf.MaxExtension, err = pConf.FieldDuration(sqsiFieldMaxExtension); err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -79,11 +99,17 @@ This input adds the following metadata fields to each message:
 - sqs_message_id
 - sqs_receipt_handle
 - sqs_approximate_receive_count
+- sqs_message_group_id (FIFO queues only)
+- sqs_message_deduplication_id (FIFO queues only)
 - All message attributes
 `+"```"+`
 
 You can access these metadata fields using
-[function interpolation](/docs/configuration/interpolation#bloblang-queries).`).
+[function interpolation](/docs/configuration/interpolation#bloblang-queries).
+
+### Visibility Timeout
+
+Messages that are still being processed are periodically extended before their visibility timeout expires, which prevents long running processing from causing messages to be redelivered. By default the visibility timeout of the queue is used, which can be overridden with the `+"`visibility_timeout`"+` field. In order to prevent messages that are stuck in processing from being held indefinitely the extensions can be capped with the `+"`max_visibility_extension`"+` field.`).
 		Fields(
 			service.NewURLField(sqsiFieldURL).
 				Description("The SQS URL to consume from."),
@@ -104,6 +130,20 @@ You can access these metadata fields using
 				Description("Whether to set the wait time. Enabling this activates long-polling. Valid values: 0 to 20.").
 				Default(0).
 				Advanced(),
+			service.NewDurationField(sqsiFieldVisibilityTimeout).
+				Description("The visibility timeout to set for consumed messages, which is extended periodically whilst a message is being processed. When empty the visibility timeout configured for the queue is used.").
+				Example("30s").
+				Example("5m").
+				Default("").
+				Version("4.24.0").
+				Advanced(),
+			service.NewDurationField(sqsiFieldMaxExtension).
+				Description("The maximum period of time after a message is consumed during which its visibility timeout is extended. Once exceeded the message becomes visible again upon its current timeout expiring, allowing it to be redelivered. When empty the visibility timeout is extended until the message is acknowledged.").
+				Example("1h").
+				Default("").
+				Version("4.24.0").
+				Advanced(),
+			span.ExtractTracingSpanMappingDocs().Version("4.24.0"),
 		).
 		Fields(config.SessionFields()...)
 }
@@ -121,7 +161,11 @@ func init() {
 				return nil, err
 			}
 
-			return newAWSSQSReader(conf, sess, mgr.Logger())
+			rdr, err := newAWSSQSReader(conf, sess, mgr.Logger())
+			if err != nil {
+				return nil, err
+			}
+			return span.NewInput("aws_sqs", pConf, rdr, mgr)
 		})
 	if err != nil {
 		panic(err)
@@ -163,9 +207,7 @@ func (a *awsSQSReader) Connect(ctx context.Context) error {
 		a.sqs = sqs.New(a.session)
 	}
 
-	ift := &sqsInFlightTracker{
-		handles: map[string]sqsInFlightHandle{},
-	}
+	ift := newSQSInFlightTracker(a.visibilityTimeout(ctx), a.conf.MaxExtension)
 
 	var wg sync.WaitGroup
 	wg.Add(2)
@@ -181,34 +223,51 @@ func (a *awsSQSReader) Connect(ctx context.Context) error {
 }
 
 type sqsInFlightHandle struct {
-	receiptHandle  string
-	timeoutSeconds int
-	addedAt        time.Time
+	receiptHandle string
+	addedAt       time.Time
+	deadline      time.Time
 }
 
 type sqsInFlightTracker struct {
+	timeout      time.Duration
+	maxExtension time.Duration
+
 	handles map[string]sqsInFlightHandle
 	m       sync.Mutex
 }
 
-func (t *sqsInFlightTracker) PullToRefresh() (handles []sqsMessageHandle, timeoutSeconds int) {
+func newSQSInFlightTracker(timeout, maxExtension time.Duration) *sqsInFlightTracker {
+	return &sqsInFlightTracker{
+		timeout:      timeout,
+		maxExtension: maxExtension,
+		handles:      map[string]sqsInFlightHandle{},
+	}
+}
+
+// PullToRefresh returns the handles of in flight messages that are due to
+// have their visibility timeout extended, which is once less than half of
+// their timeout remains. The deadlines of the returned handles are reset under
+// the assumption that they are then successfully extended.
+func (t *sqsInFlightTracker) PullToRefresh(now time.Time) (handles []sqsMessageHandle, timeoutSeconds int) {
 	t.m.Lock()
 	defer t.m.Unlock()
 
 	handles = make([]sqsMessageHandle, 0, len(t.handles))
 	for k, v := range t.handles {
-		if time.Since(v.addedAt) < time.Second {
+		if v.deadline.Sub(now) > t.timeout/2 {
+			continue
+		}
+		if t.maxExtension > 0 && now.Sub(v.addedAt) >= t.maxExtension {
 			continue
 		}
 		handles = append(handles, sqsMessageHandle{
 			id:            k,
 			receiptHandle: v.receiptHandle,
 		})
-		if v.timeoutSeconds > timeoutSeconds {
-			timeoutSeconds = v.timeoutSeconds
-		}
+		v.deadline = now.Add(t.timeout)
+		t.handles[k] = v
 	}
-	return
+	return handles, int(t.timeout.Seconds())
 }
 
 func (t *sqsInFlightTracker) Remove(id string) {
@@ -217,7 +276,7 @@ func (t *sqsInFlightTracker) Remove(id string) {
 	delete(t.handles, id)
 }
 
-func (t *sqsInFlightTracker) AddNew(messages ...*sqs.Message) {
+func (t *sqsInFlightTracker) AddNew(now time.Time, messages ...*sqs.Message) {
 	t.m.Lock()
 	defer t.m.Unlock()
 
@@ -225,20 +284,11 @@ func (t *sqsInFlightTracker) AddNew(messages ...*sqs.Message) {
 		if m.MessageId == nil || m.ReceiptHandle == nil {
 			continue
 		}
-
-		handle := sqsInFlightHandle{
-			timeoutSeconds: 30,
-			receiptHandle:  *m.ReceiptHandle,
-			addedAt:        time.Now(),
-		}
-		if timeoutStr, exists := m.Attributes[sqsiAttributeNameVisibilityTimeout]; exists && timeoutStr != nil {
-			// Might as well keep the queue timeout setting refreshed as we
-			// consume new data.
-			if tmpTimeoutSeconds, err := strconv.Atoi(*timeoutStr); err == nil {
-				handle.timeoutSeconds = tmpTimeoutSeconds
-			}
+		t.handles[*m.MessageId] = sqsInFlightHandle{
+			receiptHandle: *m.ReceiptHandle,
+			addedAt:       now,
+			deadline:      now.Add(t.timeout),
 		}
-		t.handles[*m.MessageId] = handle
 	}
 }
 
@@ -274,7 +324,7 @@ func (a *awsSQSReader) ackLoop(wg *sync.WaitGroup, inFlightTracker *sqsInFlightT
 	}
 
 	refreshCurrentHandles := func() {
-		currentHandles, timeoutSeconds := inFlightTracker.PullToRefresh()
+		currentHandles, timeoutSeconds := inFlightTracker.PullToRefresh(time.Now())
 		if len(currentHandles) == 0 {
 			return
 		}
@@ -351,13 +401,17 @@ func (a *awsSQSReader) readLoop(wg *sync.WaitGroup, inFlightTracker *sqsInFlight
 	backoff.MaxElapsedTime = 0
 
 	getMsgs := func() {
-		res, err := a.sqs.ReceiveMessageWithContext(closeAtLeisureCtx, &sqs.ReceiveMessageInput{
+		input := &sqs.ReceiveMessageInput{
 			QueueUrl:              aws.String(a.conf.URL),
 			MaxNumberOfMessages:   aws.Int64(int64(a.conf.MaxNumberOfMessages)),
 			WaitTimeSeconds:       aws.Int64(int64(a.conf.WaitTimeSeconds)),
 			AttributeNames:        []*string{aws.String("All")},
 			MessageAttributeNames: []*string{aws.String("All")},
-		})
+		}
+		if a.conf.VisibilityTimeout > 0 {
+			input.VisibilityTimeout = aws.Int64(int64(a.conf.VisibilityTimeout.Seconds()))
+		}
+		res, err := a.sqs.ReceiveMessageWithContext(closeAtLeisureCtx, input)
 		if err != nil {
 			if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != request.CanceledErrorCode {
 				a.log.Errorf("Failed to pull new SQS messages: %v", aerr)
@@ -365,7 +419,7 @@ func (a *awsSQSReader) readLoop(wg *sync.WaitGroup, inFlightTracker *sqsInFlight
 			return
 		}
 		if len(res.Messages) > 0 {
-			inFlightTracker.AddNew(res.Messages...)
+			inFlightTracker.AddNew(time.Now(), res.Messages...)
 			pendingMsgs = append(pendingMsgs, res.Messages...)
 		}
 		if len(res.Messages) > 0 || a.conf.WaitTimeSeconds > 0 {
@@ -397,6 +451,30 @@ func (a *awsSQSReader) readLoop(wg *sync.WaitGroup, inFlightTracker *sqsInFlight
 	}
 }
 
+// visibilityTimeout returns the configured visibility timeout, or the
+// visibility timeout of the queue when one is not configured.
+func (a *awsSQSReader) visibilityTimeout(ctx context.Context) time.Duration {
+	if a.conf.VisibilityTimeout > 0 {
+		return a.conf.VisibilityTimeout
+	}
+
+	timeout := 30 * time.Second
+	res, err := a.sqs.GetQueueAttributesWithContext(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(a.conf.URL),
+		AttributeNames: []*string{aws.String(sqsiAttributeNameVisibilityTimeout)},
+	})
+	if err != nil {
+		a.log.Warnf("Failed to obtain the visibility timeout of the queue, defaulting to %v: %v", timeout, err)
+		return timeout
+	}
+	if timeoutStr := res.Attributes[sqsiAttributeNameVisibilityTimeout]; timeoutStr != nil {
+		if timeoutSeconds, err := strconv.Atoi(*timeoutStr); err == nil && timeoutSeconds > 0 {
+			timeout = time.Duration(timeoutSeconds) * time.Second
+		}
+	}
+	return timeout
+}
+
 type sqsMessageHandle struct {
 	id, receiptHandle string
 }
@@ -474,6 +552,12 @@ func addSQSMetadata(p *service.Message, sqsMsg *sqs.Message) {
 	if rCountStr := sqsMsg.Attributes["ApproximateReceiveCount"]; rCountStr != nil {
 		p.MetaSetMut("sqs_approximate_receive_count", *rCountStr)
 	}
+	if groupID := sqsMsg.Attributes["MessageGroupId"]; groupID != nil {
+		p.MetaSetMut("sqs_message_group_id", *groupID)
+	}
+	if dedupeID := sqsMsg.Attributes["MessageDeduplicationId"]; dedupeID != nil {
+		p.MetaSetMut("sqs_message_deduplication_id", *dedupeID)
+	}
 	for k, v := range sqsMsg.MessageAttributes {
 		if v.StringValue != nil {
 			p.MetaSetMut(k, *v.StringValue)
@@ -71,7 +71,7 @@ func (m *mockSqsInput) ReceiveMessageWithContext(ctx aws.Context, input *sqs.Rec
 	return &sqs.ReceiveMessageOutput{Messages: messages}, nil
 }
 
-func (m *mockSqsInput) GetQueueAttributes(input *sqs.GetQueueAttributesInput) (*sqs.GetQueueAttributesOutput, error) {
+func (m *mockSqsInput) GetQueueAttributesWithContext(ctx aws.Context, input *sqs.GetQueueAttributesInput, opts ...request.Option) (*sqs.GetQueueAttributesOutput, error) {
 	return &sqs.GetQueueAttributesOutput{Attributes: map[string]*string{sqsiAttributeNameVisibilityTimeout: aws.String(strconv.Itoa(m.queueTimeout))}}, nil
 }
 
@@ -198,3 +198,36 @@ func TestSQSInput(t *testing.T) {
 		return msgsLen == 0
 	}, 5*time.Second, time.Second)
 }
+
+func TestSQSInFlightTrackerRefresh(t *testing.T) {
+	now := time.Now()
+
+	tracker := newSQSInFlightTracker(10*time.Second, time.Minute)
+	tracker.AddNew(now,
+		&sqs.Message{MessageId: aws.String("a"), ReceiptHandle: aws.String("a-handle")},
+		&sqs.Message{MessageId: aws.String("b"), ReceiptHandle: aws.String("b-handle")},
+	)
+
+	// Nothing is due until less than half of the timeout remains.
+	handles, timeoutSeconds := tracker.PullToRefresh(now.Add(4 * time.Second))
+	require.Empty(t, handles)
+	require.Equal(t, 10, timeoutSeconds)
+
+	handles, _ = tracker.PullToRefresh(now.Add(5 * time.Second))
+	require.ElementsMatch(t, []sqsMessageHandle{
+		{id: "a", receiptHandle: "a-handle"},
+		{id: "b", receiptHandle: "b-handle"},
+	}, handles)
+
+	// Deadlines are reset after a refresh.
+	handles, _ = tracker.PullToRefresh(now.Add(6 * time.Second))
+	require.Empty(t, handles)
+
+	tracker.Remove("a")
+	handles, _ = tracker.PullToRefresh(now.Add(10 * time.Second))
+	require.Equal(t, []sqsMessageHandle{{id: "b", receiptHandle: "b-handle"}}, handles)
+
+	// Extensions stop once the maximum extension period is exceeded.
+	handles, _ = tracker.PullToRefresh(now.Add(time.Minute))
+	require.Empty(t, handles)
+}
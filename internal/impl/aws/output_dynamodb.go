@@ -31,13 +31,16 @@ const (
 	ddboFieldTTL            = "ttl"
 	ddboFieldTTLKey         = "ttl_key"
 	ddboFieldBatching       = "batching"
+
+	// The maximum number of write requests accepted by BatchWriteItem.
+	ddboMaxBatchWriteItems = 25
 )
 
 type ddboConfig struct {
 	Table          string
 	StringColumns  map[string]*service.InterpolatedString
 	JSONMapColumns map[string]string
-	TTL            string
+	TTL            *service.InterpolatedString
 	TTLKey         string
 
 	session     *session.Session
@@ -54,7 +57,7 @@ func ddboConfigFromParsed(pConf *service.ParsedConfig) (conf ddboConfig, err err
 	if conf.JSONMapColumns, err = pConf.FieldStringMap(ddboFieldJSONMapColumns); err != nil {
 		return
 	}
-	if conf.TTL, err = pConf.FieldString(ddboFieldTTL); err != nil {
+	if conf.TTL, err = pConf.FieldInterpolatedString(ddboFieldTTL); err != nil {
 		return
 	}
 	if conf.TTLKey, err = pConf.FieldString(ddboFieldTTLKey); err != nil {
@@ -103,6 +106,19 @@ json_map_columns:
 
 In which case the top level document fields will be written at the root of the item, potentially overwriting previously defined column values. If a path is not found within a document the column will not be populated.
 
+The field `+"`ttl`"+` supports interpolation functions, which allows the TTL of each item to be derived from the message it was created from, for example with a default for the whole pipeline:
+
+`+"```yml"+`
+ttl: ${! @ttl.or("24h") }
+ttl_key: expires_at
+`+"```"+`
+
+The expiry timestamp of an item is calculated from the moment the message is sent, and is written as a unix timestamp in seconds to the column `+"`ttl_key`"+`. Messages where the TTL resolves to an empty string are written without an expiry.
+
+### Batching and Errors
+
+Batches of messages are written with the `+"`BatchWriteItem`"+` API in requests of up to 25 items. Items that DynamoDB reports as unprocessed are retried with an exponential backoff that is capped by the `+"`backoff`"+` fields, and when a batch request fails entirely the items are written individually. Items that still fail once the retries are exhausted are reported as errors against the individual messages they were created from, which allows only the failed messages to be retried or routed elsewhere with a [`+"`fallback`"+`](/docs/components/outputs/fallback) or [`+"`switch`"+`](/docs/components/outputs/switch) output.
+
 ### Credentials
 
 By default Benthos will use a shared credentials file when connecting to AWS services. It's also possible to set them explicitly at the component level, allowing you to transfer data across accounts. You can find out more [in this document](/docs/guides/cloud/aws).
@@ -135,8 +151,10 @@ This output benefits from sending messages as a batch for improved performance.
 				Example(map[string]string{
 					"": ".",
 				}),
-			service.NewStringField(ddboFieldTTL).
-				Description("An optional TTL to set for items, calculated from the moment the message is sent.").
+			service.NewInterpolatedStringField(ddboFieldTTL).
+				Description("An optional TTL to set for items, calculated from the moment the message is sent. This field supports interpolation functions and messages where it resolves to an empty string are written without a TTL.").
+				Example("24h").
+				Example(`${! @ttl.or("24h") }`).
 				Default("").
 				Advanced(),
 			service.NewStringField(ddboFieldTTLKey).
@@ -179,7 +197,6 @@ type dynamoDBWriter struct {
 	boffPool sync.Pool
 
 	table *string
-	ttl   time.Duration
 }
 
 func newDynamoDBWriter(conf ddboConfig, mgr *service.Resources) (*dynamoDBWriter, error) {
@@ -196,12 +213,10 @@ func newDynamoDBWriter(conf ddboConfig, mgr *service.Resources) (*dynamoDBWriter
 			conf.JSONMapColumns[k] = ""
 		}
 	}
-	if conf.TTL != "" {
-		ttl, err := time.ParseDuration(conf.TTL)
-		if err != nil {
+	if ttlStr, isStatic := conf.TTL.Static(); isStatic && ttlStr != "" {
+		if _, err := time.ParseDuration(ttlStr); err != nil {
 			return nil, fmt.Errorf("failed to parse TTL: %v", err)
 		}
-		db.ttl = ttl
 	}
 	db.boffPool = sync.Pool{
 		New: func() any {
@@ -305,9 +320,19 @@ func (d *dynamoDBWriter) WriteBatch(ctx context.Context, b service.MessageBatch)
 	writeReqs := []*dynamodb.WriteRequest{}
 	if err := b.WalkWithBatchedErrors(func(i int, p *service.Message) error {
 		items := map[string]*dynamodb.AttributeValue{}
-		if d.ttl != 0 && d.conf.TTLKey != "" {
-			items[d.conf.TTLKey] = &dynamodb.AttributeValue{
-				N: aws.String(strconv.FormatInt(time.Now().Add(d.ttl).Unix(), 10)),
+		if d.conf.TTLKey != "" {
+			ttlStr, err := b.TryInterpolatedString(i, d.conf.TTL)
+			if err != nil {
+				return fmt.Errorf("ttl interpolation error: %w", err)
+			}
+			if ttlStr != "" {
+				ttl, err := time.ParseDuration(ttlStr)
+				if err != nil {
+					return fmt.Errorf("failed to parse TTL: %w", err)
+				}
+				items[d.conf.TTLKey] = &dynamodb.AttributeValue{
+					N: aws.String(strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)),
+				}
 			}
 		}
 		for k, v := range d.conf.StringColumns {
@@ -350,19 +375,60 @@ func (d *dynamoDBWriter) WriteBatch(ctx context.Context, b service.MessageBatch)
 		return err
 	}
 
+	// BatchWriteItem accepts a limited number of requests, so larger batches
+	// are written in chunks, each with their own retries.
+	var batchErr *service.BatchError
+	for start := 0; start < len(writeReqs); start += ddboMaxBatchWriteItems {
+		end := start + ddboMaxBatchWriteItems
+		if end > len(writeReqs) {
+			end = len(writeReqs)
+		}
+
+		boff.Reset()
+		failed, err := d.writeRequests(ctx, boff, writeReqs[start:end])
+		if err == nil {
+			continue
+		}
+		if len(failed) == 0 {
+			// The failed requests could not be mapped back to their origin
+			// messages, so the whole batch must be reattempted.
+			return err
+		}
+		if batchErr == nil {
+			batchErr = service.NewBatchError(b, err)
+		}
+		for i, iErr := range failed {
+			batchErr.Failed(start+i, iErr)
+		}
+	}
+	if batchErr != nil {
+		return batchErr
+	}
+	return nil
+}
+
+// writeRequests attempts to write a chunk of requests with BatchWriteItem,
+// retrying unprocessed items until the backoff is exhausted. When requests
+// fail the returned map contains the errors of each failed request by its
+// index, and when it is empty the error applies to all requests.
+func (d *dynamoDBWriter) writeRequests(ctx context.Context, boff backoff.BackOff, reqs []*dynamodb.WriteRequest) (map[int]error, error) {
 	batchResult, err := d.client.BatchWriteItem(&dynamodb.BatchWriteItemInput{
 		RequestItems: map[string][]*dynamodb.WriteRequest{
-			*d.table: writeReqs,
+			*d.table: reqs,
 		},
 	})
 	if err != nil {
 		headlineErr := err
 
 		// None of the messages were successful, attempt to send individually
+		pending := make([]*dynamodb.WriteRequest, len(reqs))
+		copy(pending, reqs)
+
+		var failed map[int]error
 	individualRequestsLoop:
 		for err != nil {
-			batchErr := service.NewBatchError(b, headlineErr)
-			for i, req := range writeReqs {
+			iterFailed := map[int]error{}
+			for i, req := range pending {
 				if req == nil {
 					continue
 				}
@@ -380,18 +446,19 @@ func (d *dynamoDBWriter) WriteBatch(ctx context.Context, b service.MessageBatch)
 					case <-ctx.Done():
 						break individualRequestsLoop
 					}
-					batchErr.Failed(i, iErr)
+					iterFailed[i] = iErr
 				} else {
-					writeReqs[i] = nil
+					pending[i] = nil
 				}
 			}
-			if batchErr.IndexedErrors() == 0 {
+			if failed = iterFailed; len(failed) == 0 {
 				err = nil
-			} else {
-				err = batchErr
 			}
 		}
-		return err
+		if err != nil {
+			return failed, headlineErr
+		}
+		return nil, nil
 	}
 
 	unproc := batchResult.UnprocessedItems[*d.table]
@@ -420,32 +487,31 @@ unprocessedLoop:
 		}
 	}
 
-	if len(unproc) > 0 {
-		if err == nil {
-			err = errors.New("ran out of request retries")
-		}
-
-		// Sad, we have unprocessed messages, we need to map the requests back
-		// to the origin message index. The DynamoDB API doesn't make this easy.
-		batchErr := service.NewBatchError(b, err)
+	if len(unproc) == 0 {
+		return nil, nil
+	}
+	if err == nil {
+		err = errors.New("ran out of request retries")
+	}
 
-	requestsLoop:
-		for _, req := range unproc {
-			for i, src := range writeReqs {
-				if cmp.Equal(req, src) {
-					batchErr.Failed(i, errors.New("failed to set item"))
-					continue requestsLoop
-				}
+	// Sad, we have unprocessed messages, we need to map the requests back
+	// to the origin message index. The DynamoDB API doesn't make this easy.
+	// Requests that have already been matched are skipped in order to
+	// correctly attribute identical items.
+	failed := make(map[int]error, len(unproc))
+requestsLoop:
+	for _, req := range unproc {
+		for i, src := range reqs {
+			if _, exists := failed[i]; !exists && cmp.Equal(req, src) {
+				failed[i] = errors.New("failed to set item")
+				continue requestsLoop
 			}
-			// If we're unable to map a single request to the origin message
-			// then we return a general error.
-			return err
 		}
-
-		err = batchErr
+		// If we're unable to map a single request to the origin message
+		// then we return a general error.
+		return nil, err
 	}
-
-	return err
+	return failed, err
 }
 
 func (d *dynamoDBWriter) Close(context.Context) error {
@@ -3,7 +3,9 @@ package aws
 import (
 	"context"
 	"errors"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -497,3 +499,129 @@ string_columns:
 
 	assert.Equal(t, expected, requests)
 }
+
+func TestDynamoDBBatchChunks(t *testing.T) {
+	t.Parallel()
+
+	db := testDDBOWriter(t, `
+table: FooTable
+string_columns:
+  id: ${!json("id")}
+backoff:
+  initial_interval: 1ms
+  max_elapsed_time: 100ms
+`)
+
+	var requestSizes []int
+	db.client = &mockDynamoDB{
+		fn: func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+			t.Error("not expected")
+			return nil, errors.New("not implemented")
+		},
+		batchFn: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			request := input.RequestItems["FooTable"]
+			requestSizes = append(requestSizes, len(request))
+
+			// Items with the id "dupe" are never processed.
+			output := &dynamodb.BatchWriteItemOutput{}
+			for _, r := range request {
+				if *r.PutRequest.Item["id"].S == "dupe" {
+					if output.UnprocessedItems == nil {
+						output.UnprocessedItems = map[string][]*dynamodb.WriteRequest{}
+					}
+					output.UnprocessedItems["FooTable"] = append(output.UnprocessedItems["FooTable"], &dynamodb.WriteRequest{
+						PutRequest: &dynamodb.PutRequest{
+							Item: map[string]*dynamodb.AttributeValue{
+								"id": {S: aws.String("dupe")},
+							},
+						},
+					})
+				}
+			}
+			return output, nil
+		},
+	}
+
+	var msg service.MessageBatch
+	for i := 0; i < 60; i++ {
+		id := strconv.Itoa(i)
+		if i == 30 || i == 31 {
+			id = "dupe"
+		}
+		msg = append(msg, service.NewMessage([]byte(`{"id":"`+id+`"}`)))
+	}
+
+	err := db.WriteBatch(context.Background(), msg)
+	require.Error(t, err)
+
+	var batchErr *service.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, 2, batchErr.IndexedErrors())
+
+	var failed []int
+	batchErr.WalkMessages(func(i int, m *service.Message, err error) bool {
+		if err != nil {
+			failed = append(failed, i)
+		}
+		return true
+	})
+	assert.Equal(t, []int{30, 31}, failed)
+
+	// The second chunk is retried with only its unprocessed items before the
+	// final chunk is written.
+	require.GreaterOrEqual(t, len(requestSizes), 4)
+	assert.Equal(t, 25, requestSizes[0])
+	assert.Equal(t, 25, requestSizes[1])
+	for _, size := range requestSizes[2 : len(requestSizes)-1] {
+		assert.Equal(t, 2, size)
+	}
+	assert.Equal(t, 10, requestSizes[len(requestSizes)-1])
+}
+
+func TestDynamoDBTTLInterpolation(t *testing.T) {
+	db := testDDBOWriter(t, `
+table: FooTable
+string_columns:
+  id: ${!json("id")}
+ttl: ${! @ttl.or("1h") }
+ttl_key: expires_at
+`)
+
+	var request []*dynamodb.WriteRequest
+	db.client = &mockDynamoDB{
+		batchFn: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			request = input.RequestItems["FooTable"]
+			return &dynamodb.BatchWriteItemOutput{}, nil
+		},
+	}
+
+	noTTL := service.NewMessage([]byte(`{"id":"bar"}`))
+	noTTL.MetaSetMut("ttl", "")
+
+	customTTL := service.NewMessage([]byte(`{"id":"baz"}`))
+	customTTL.MetaSetMut("ttl", "10m")
+
+	now := time.Now()
+	require.NoError(t, db.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"foo"}`)),
+		noTTL,
+		customTTL,
+	}))
+	require.Len(t, request, 3)
+
+	expiresAt := func(i int) time.Time {
+		attr, exists := request[i].PutRequest.Item["expires_at"]
+		require.True(t, exists)
+		ts, err := strconv.ParseInt(*attr.N, 10, 64)
+		require.NoError(t, err)
+		return time.Unix(ts, 0)
+	}
+
+	assert.WithinDuration(t, now.Add(time.Hour), expiresAt(0), 5*time.Second)
+	assert.NotContains(t, request[1].PutRequest.Item, "expires_at")
+	assert.WithinDuration(t, now.Add(10*time.Minute), expiresAt(2), 5*time.Second)
+
+	badTTL := service.NewMessage([]byte(`{"id":"foo"}`))
+	badTTL.MetaSetMut("ttl", "nope")
+	require.Error(t, db.WriteBatch(context.Background(), service.MessageBatch{badTTL}))
+}
@@ -3,6 +3,7 @@ package aws
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"sort"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
@@ -38,6 +40,15 @@ const (
 	s3oFieldKMSKeyID                = "kms_key_id"
 	s3oFieldServerSideEncryption    = "server_side_encryption"
 	s3oFieldBatching                = "batching"
+	s3oFieldObjectLock              = "object_lock"
+	s3oFieldObjectLockMode          = "mode"
+	s3oFieldObjectLockRetainUntil   = "retain_until"
+	s3oFieldObjectLockLegalHold     = "legal_hold"
+	s3oFieldStreaming               = "streaming"
+	s3oFieldStreamingEnabled        = "enabled"
+	s3oFieldStreamingPartSize       = "part_size"
+	s3oFieldStreamingMaxObjectSize  = "max_object_size"
+	s3oFieldStreamingMaxObjectAge   = "max_object_age"
 )
 
 type s3TagPair struct {
@@ -62,6 +73,12 @@ type s3oConfig struct {
 	KMSKeyID                string
 	ServerSideEncryption    string
 
+	ObjectLockMode        *service.InterpolatedString
+	ObjectLockRetainUntil *service.InterpolatedString
+	ObjectLockLegalHold   bool
+
+	Streaming s3StreamingConfig
+
 	session *session.Session
 }
 
@@ -125,6 +142,23 @@ func s3oConfigFromParsed(pConf *service.ParsedConfig) (conf s3oConfig, err error
 	if conf.ServerSideEncryption, err = pConf.FieldString(s3oFieldServerSideEncryption); err != nil {
 		return
 	}
+	if pConf.Contains(s3oFieldObjectLock) {
+		olConf := pConf.Namespace(s3oFieldObjectLock)
+		if conf.ObjectLockMode, err = olConf.FieldInterpolatedString(s3oFieldObjectLockMode); err != nil {
+			return
+		}
+		if conf.ObjectLockRetainUntil, err = olConf.FieldInterpolatedString(s3oFieldObjectLockRetainUntil); err != nil {
+			return
+		}
+		if conf.ObjectLockLegalHold, err = olConf.FieldBool(s3oFieldObjectLockLegalHold); err != nil {
+			return
+		}
+	}
+	if pConf.Contains(s3oFieldStreaming) {
+		if conf.Streaming, err = s3StreamingConfigFromParsed(pConf.Namespace(s3oFieldStreaming)); err != nil {
+			return
+		}
+	}
 
 	if conf.session, err = GetSession(pConf, func(c *aws.Config) {
 		c.S3ForcePathStyle = aws.Bool(forcePathStyleURLs)
@@ -264,6 +298,23 @@ output:
 				Description("The maximum period to wait on an upload before abandoning it and reattempting.").
 				Advanced().
 				Default("5s"),
+			service.NewObjectField(s3oFieldObjectLock,
+				service.NewInterpolatedStringEnumField(s3oFieldObjectLockMode, "GOVERNANCE", "COMPLIANCE").
+					Description("The Object Lock retention mode to apply to each object. Leave empty in order to apply the default retention of the bucket, if any.").
+					Default(""),
+				service.NewInterpolatedStringField(s3oFieldObjectLockRetainUntil).
+					Description("An RFC 3339 timestamp until which each object is retained, which is required when a `mode` is set.").
+					Example(`${! now().ts_add_iso8601("P30D") }`).
+					Default(""),
+				service.NewBoolField(s3oFieldObjectLockLegalHold).
+					Description("Whether to place a legal hold on each object.").
+					Default(false),
+			).
+				Description("Configure [S3 Object Lock](https://docs.aws.amazon.com/AmazonS3/latest/userguide/object-lock.html) retention for uploaded objects. The target bucket must have Object Lock enabled.").
+				Version("4.24.0").
+				Advanced().
+				Optional(),
+			s3StreamingField(),
 			service.NewBatchPolicyField(s3oFieldBatching),
 		).
 		Fields(config.SessionFields()...)
@@ -293,6 +344,7 @@ func init() {
 type amazonS3Writer struct {
 	conf     s3oConfig
 	uploader *s3manager.Uploader
+	streamer *s3ObjectStreamer
 	log      *service.Logger
 }
 
@@ -309,6 +361,9 @@ func (a *amazonS3Writer) Connect(ctx context.Context) error {
 		return nil
 	}
 	a.uploader = s3manager.NewUploader(a.conf.session)
+	if a.conf.Streaming.Enabled {
+		a.streamer = newS3ObjectStreamer(a.conf.Streaming, a.conf.Timeout, a.uploader.S3, a.log)
+	}
 
 	a.log.Infof("Uploading message parts as objects to Amazon S3 bucket: %v\n", a.conf.Bucket)
 	return nil
@@ -322,116 +377,210 @@ func (a *amazonS3Writer) WriteBatch(wctx context.Context, msg service.MessageBat
 	ctx, cancel := context.WithTimeout(wctx, a.conf.Timeout)
 	defer cancel()
 
-	return msg.WalkWithBatchedErrors(func(i int, m *service.Message) error {
-		metadata := map[string]*string{}
-		_ = a.conf.Metadata.WalkMut(m, func(k string, v any) error {
-			metadata[k] = aws.String(query.IToString(v))
-			return nil
-		})
+	if a.streamer != nil {
+		return a.streamBatch(ctx, wctx, msg)
+	}
 
-		var contentEncoding *string
-		ce, err := msg.TryInterpolatedString(i, a.conf.ContentEncoding)
+	return msg.WalkWithBatchedErrors(func(i int, m *service.Message) error {
+		uploadInput, err := a.uploadInputFor(msg, i)
 		if err != nil {
-			return fmt.Errorf("content encoding interpolation: %w", err)
-		}
-		if len(ce) > 0 {
-			contentEncoding = aws.String(ce)
-		}
-		var cacheControl *string
-		if ce, err = msg.TryInterpolatedString(i, a.conf.CacheControl); err != nil {
-			return fmt.Errorf("cache control interpolation: %w", err)
-		}
-		if len(ce) > 0 {
-			cacheControl = aws.String(ce)
-		}
-		var contentDisposition *string
-		if ce, err = msg.TryInterpolatedString(i, a.conf.ContentDisposition); err != nil {
-			return fmt.Errorf("content disposition interpolation: %w", err)
-		}
-		if len(ce) > 0 {
-			contentDisposition = aws.String(ce)
-		}
-		var contentLanguage *string
-		if ce, err = msg.TryInterpolatedString(i, a.conf.ContentLanguage); err != nil {
-			return fmt.Errorf("content language interpolation: %w", err)
-		}
-		if len(ce) > 0 {
-			contentLanguage = aws.String(ce)
-		}
-		var websiteRedirectLocation *string
-		if ce, err = msg.TryInterpolatedString(i, a.conf.WebsiteRedirectLocation); err != nil {
-			return fmt.Errorf("website redirect location interpolation: %w", err)
+			return err
 		}
-		if len(ce) > 0 {
-			websiteRedirectLocation = aws.String(ce)
+		if _, err := a.uploader.UploadWithContext(ctx, uploadInput); err != nil {
+			return err
 		}
+		return nil
+	})
+}
 
-		key, err := msg.TryInterpolatedString(i, a.conf.Path)
-		if err != nil {
-			return fmt.Errorf("key interpolation: %w", err)
-		}
+// streamBatch appends a batch to the current streamed object and waits for the
+// object to be completed. The wait is bound by the write context rather than
+// the upload timeout as objects remain open for up to their maximum age.
+func (a *amazonS3Writer) streamBatch(ctx, wctx context.Context, msg service.MessageBatch) error {
+	resChans := make([]<-chan error, len(msg))
 
-		contentType, err := msg.TryInterpolatedString(i, a.conf.ContentType)
-		if err != nil {
-			return fmt.Errorf("content type interpolation: %w", err)
+	var batchErr *service.BatchError
+	setErr := func(i int, err error) {
+		if batchErr == nil {
+			batchErr = service.NewBatchError(msg, err)
 		}
+		batchErr.Failed(i, err)
+	}
 
-		storageClass, err := msg.TryInterpolatedString(i, a.conf.StorageClass)
+	for i, m := range msg {
+		uploadInput, err := a.uploadInputFor(msg, i)
 		if err != nil {
-			return fmt.Errorf("storage class interpolation: %w", err)
+			setErr(i, err)
+			continue
 		}
-
 		mBytes, err := m.AsBytes()
 		if err != nil {
-			return err
+			setErr(i, err)
+			continue
 		}
-
-		uploadInput := &s3manager.UploadInput{
-			Bucket:                  &a.conf.Bucket,
-			Key:                     aws.String(key),
-			Body:                    bytes.NewReader(mBytes),
-			ContentType:             aws.String(contentType),
-			ContentEncoding:         contentEncoding,
-			CacheControl:            cacheControl,
-			ContentDisposition:      contentDisposition,
-			ContentLanguage:         contentLanguage,
-			WebsiteRedirectLocation: websiteRedirectLocation,
-			StorageClass:            aws.String(storageClass),
-			Metadata:                metadata,
+		if resChans[i], err = a.streamer.Append(ctx, uploadInput, mBytes); err != nil {
+			setErr(i, err)
 		}
+	}
 
-		// Prepare tags, escaping keys and values to ensure they're valid query string parameters.
-		if len(a.conf.Tags) > 0 {
-			tags := make([]string, len(a.conf.Tags))
-			for j, pair := range a.conf.Tags {
-				tagStr, err := msg.TryInterpolatedString(i, pair.value)
-				if err != nil {
-					return fmt.Errorf("tag %v interpolation: %w", pair.key, err)
-				}
-				tags[j] = url.QueryEscape(pair.key) + "=" + url.QueryEscape(tagStr)
+	for i, resChan := range resChans {
+		if resChan == nil {
+			continue
+		}
+		select {
+		case err := <-resChan:
+			if err != nil {
+				setErr(i, err)
 			}
-			uploadInput.Tagging = aws.String(strings.Join(tags, "&"))
+		case <-wctx.Done():
+			return wctx.Err()
 		}
+	}
+	if batchErr != nil {
+		return batchErr
+	}
+	return nil
+}
 
-		if a.conf.KMSKeyID != "" {
-			uploadInput.ServerSideEncryption = aws.String("aws:kms")
-			uploadInput.SSEKMSKeyId = &a.conf.KMSKeyID
-		}
+func (a *amazonS3Writer) uploadInputFor(msg service.MessageBatch, i int) (*s3manager.UploadInput, error) {
+	m := msg[i]
+	metadata := map[string]*string{}
+	_ = a.conf.Metadata.WalkMut(m, func(k string, v any) error {
+		metadata[k] = aws.String(query.IToString(v))
+		return nil
+	})
+
+	var contentEncoding *string
+	ce, err := msg.TryInterpolatedString(i, a.conf.ContentEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("content encoding interpolation: %w", err)
+	}
+	if len(ce) > 0 {
+		contentEncoding = aws.String(ce)
+	}
+	var cacheControl *string
+	if ce, err = msg.TryInterpolatedString(i, a.conf.CacheControl); err != nil {
+		return nil, fmt.Errorf("cache control interpolation: %w", err)
+	}
+	if len(ce) > 0 {
+		cacheControl = aws.String(ce)
+	}
+	var contentDisposition *string
+	if ce, err = msg.TryInterpolatedString(i, a.conf.ContentDisposition); err != nil {
+		return nil, fmt.Errorf("content disposition interpolation: %w", err)
+	}
+	if len(ce) > 0 {
+		contentDisposition = aws.String(ce)
+	}
+	var contentLanguage *string
+	if ce, err = msg.TryInterpolatedString(i, a.conf.ContentLanguage); err != nil {
+		return nil, fmt.Errorf("content language interpolation: %w", err)
+	}
+	if len(ce) > 0 {
+		contentLanguage = aws.String(ce)
+	}
+	var websiteRedirectLocation *string
+	if ce, err = msg.TryInterpolatedString(i, a.conf.WebsiteRedirectLocation); err != nil {
+		return nil, fmt.Errorf("website redirect location interpolation: %w", err)
+	}
+	if len(ce) > 0 {
+		websiteRedirectLocation = aws.String(ce)
+	}
+
+	key, err := msg.TryInterpolatedString(i, a.conf.Path)
+	if err != nil {
+		return nil, fmt.Errorf("key interpolation: %w", err)
+	}
+
+	contentType, err := msg.TryInterpolatedString(i, a.conf.ContentType)
+	if err != nil {
+		return nil, fmt.Errorf("content type interpolation: %w", err)
+	}
+
+	storageClass, err := msg.TryInterpolatedString(i, a.conf.StorageClass)
+	if err != nil {
+		return nil, fmt.Errorf("storage class interpolation: %w", err)
+	}
 
-		// NOTE: This overrides the ServerSideEncryption set above. We need this to preserve
-		// backwards compatibility, where it is allowed to only set kms_key_id in the config and
-		// the ServerSideEncryption value of "aws:kms" is implied.
-		if a.conf.ServerSideEncryption != "" {
-			uploadInput.ServerSideEncryption = &a.conf.ServerSideEncryption
+	mBytes, err := m.AsBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	uploadInput := &s3manager.UploadInput{
+		Bucket:                  &a.conf.Bucket,
+		Key:                     aws.String(key),
+		Body:                    bytes.NewReader(mBytes),
+		ContentType:             aws.String(contentType),
+		ContentEncoding:         contentEncoding,
+		CacheControl:            cacheControl,
+		ContentDisposition:      contentDisposition,
+		ContentLanguage:         contentLanguage,
+		WebsiteRedirectLocation: websiteRedirectLocation,
+		StorageClass:            aws.String(storageClass),
+		Metadata:                metadata,
+	}
+
+	// Prepare tags, escaping keys and values to ensure they're valid query string parameters.
+	if len(a.conf.Tags) > 0 {
+		tags := make([]string, len(a.conf.Tags))
+		for j, pair := range a.conf.Tags {
+			tagStr, err := msg.TryInterpolatedString(i, pair.value)
+			if err != nil {
+				return nil, fmt.Errorf("tag %v interpolation: %w", pair.key, err)
+			}
+			tags[j] = url.QueryEscape(pair.key) + "=" + url.QueryEscape(tagStr)
 		}
+		uploadInput.Tagging = aws.String(strings.Join(tags, "&"))
+	}
 
-		if _, err := a.uploader.UploadWithContext(ctx, uploadInput); err != nil {
-			return err
+	if a.conf.KMSKeyID != "" {
+		uploadInput.ServerSideEncryption = aws.String("aws:kms")
+		uploadInput.SSEKMSKeyId = &a.conf.KMSKeyID
+	}
+
+	// NOTE: This overrides the ServerSideEncryption set above. We need this to preserve
+	// backwards compatibility, where it is allowed to only set kms_key_id in the config and
+	// the ServerSideEncryption value of "aws:kms" is implied.
+	if a.conf.ServerSideEncryption != "" {
+		uploadInput.ServerSideEncryption = &a.conf.ServerSideEncryption
+	}
+
+	if a.conf.ObjectLockMode != nil {
+		mode, err := msg.TryInterpolatedString(i, a.conf.ObjectLockMode)
+		if err != nil {
+			return nil, fmt.Errorf("object lock mode interpolation: %w", err)
 		}
-		return nil
-	})
+		retainUntilStr, err := msg.TryInterpolatedString(i, a.conf.ObjectLockRetainUntil)
+		if err != nil {
+			return nil, fmt.Errorf("object lock retain until interpolation: %w", err)
+		}
+		if mode != "" {
+			if retainUntilStr == "" {
+				return nil, errors.New("an object lock retain_until timestamp must be provided when a mode is set")
+			}
+			retainUntil, err := time.Parse(time.RFC3339Nano, retainUntilStr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse object lock retain until timestamp: %w", err)
+			}
+			uploadInput.ObjectLockMode = aws.String(mode)
+			uploadInput.ObjectLockRetainUntilDate = aws.Time(retainUntil)
+		}
+		if a.conf.ObjectLockLegalHold {
+			uploadInput.ObjectLockLegalHoldStatus = aws.String(s3.ObjectLockLegalHoldStatusOn)
+		}
+		if uploadInput.ObjectLockMode != nil || uploadInput.ObjectLockLegalHoldStatus != nil {
+			// Uploads with object lock parameters require an integrity
+			// checksum.
+			uploadInput.ChecksumAlgorithm = aws.String(s3.ChecksumAlgorithmCrc32)
+		}
+	}
+	return uploadInput, nil
 }
 
-func (a *amazonS3Writer) Close(context.Context) error {
+func (a *amazonS3Writer) Close(ctx context.Context) error {
+	if a.streamer != nil {
+		a.streamer.Close(ctx)
+	}
 	return nil
 }
@@ -0,0 +1,272 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	s3MinPartSize = 5 * 1024 * 1024
+	s3MaxParts    = 10000
+)
+
+type s3StreamingConfig struct {
+	Enabled       bool
+	PartSize      int
+	MaxObjectSize int
+	MaxObjectAge  time.Duration
+}
+
+func s3StreamingField() *service.ConfigField {
+	return service.NewObjectField(s3oFieldStreaming,
+		service.NewBoolField(s3oFieldStreamingEnabled).
+			Description("Whether to stream messages into objects via multipart uploads.").
+			Default(false),
+		service.NewIntField(s3oFieldStreamingPartSize).
+			Description("The size in bytes of each part uploaded, which must be at least 5MiB.").
+			Default(s3MinPartSize),
+		service.NewIntField(s3oFieldStreamingMaxObjectSize).
+			Description("The size in bytes at which an object is completed. Set to zero in order to only complete objects based on the `max_object_age`.").
+			Default(0),
+		service.NewDurationField(s3oFieldStreamingMaxObjectAge).
+			Description("The maximum period of time after an object is started that it is completed. Set to zero in order to only complete objects based on the `max_object_size`.").
+			Default("5m"),
+	).
+		Description("Stream messages into large objects via multipart uploads, where the contents of messages are appended to the current object and uploaded in parts as they arrive. Objects are completed once they reach a maximum size or age, and messages are only acknowledged once the object they were written to is completed. The path and all other object attributes are resolved from the first message of each object. Since batches are held until their object is completed the `max_in_flight` field should be large enough to cover the number of batches written to each object.").
+		Version("4.24.0").
+		Advanced().
+		Optional()
+}
+
+func s3StreamingConfigFromParsed(pConf *service.ParsedConfig) (conf s3StreamingConfig, err error) {
+	if conf.Enabled, err = pConf.FieldBool(s3oFieldStreamingEnabled); err != nil {
+		return
+	}
+	if conf.PartSize, err = pConf.FieldInt(s3oFieldStreamingPartSize); err != nil {
+		return
+	}
+	if conf.MaxObjectSize, err = pConf.FieldInt(s3oFieldStreamingMaxObjectSize); err != nil {
+		return
+	}
+	if conf.MaxObjectAge, err = pConf.FieldDuration(s3oFieldStreamingMaxObjectAge); err != nil {
+		return
+	}
+	if !conf.Enabled {
+		return
+	}
+	if conf.PartSize < s3MinPartSize {
+		err = fmt.Errorf("streaming part_size must be at least %v bytes", s3MinPartSize)
+		return
+	}
+	if conf.MaxObjectSize <= 0 && conf.MaxObjectAge <= 0 {
+		err = errors.New("streaming requires either a max_object_size or a max_object_age")
+	}
+	return
+}
+
+//------------------------------------------------------------------------------
+
+// s3StreamedObject is an object currently being written via a multipart
+// upload.
+type s3StreamedObject struct {
+	bucket            string
+	key               string
+	uploadID          string
+	checksumAlgorithm *string
+
+	buf     bytes.Buffer
+	parts   []*s3.CompletedPart
+	size    int
+	waiters []chan error
+	timer   *time.Timer
+}
+
+// s3ObjectStreamer appends messages to a single object at a time via multipart
+// uploads, flushing parts as they fill up.
+type s3ObjectStreamer struct {
+	conf    s3StreamingConfig
+	timeout time.Duration
+	client  s3iface.S3API
+	log     *service.Logger
+
+	mut     sync.Mutex
+	current *s3StreamedObject
+}
+
+func newS3ObjectStreamer(conf s3StreamingConfig, timeout time.Duration, client s3iface.S3API, log *service.Logger) *s3ObjectStreamer {
+	return &s3ObjectStreamer{
+		conf:    conf,
+		timeout: timeout,
+		client:  client,
+		log:     log,
+	}
+}
+
+// Append the contents of a message to the current object, starting a new
+// object from the attributes of the upload input if one is not in progress.
+// The returned channel receives the outcome of the object once it has been
+// completed or has failed.
+func (s *s3ObjectStreamer) Append(ctx context.Context, input *s3manager.UploadInput, data []byte) (<-chan error, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	obj := s.current
+	if obj == nil {
+		var err error
+		if obj, err = s.createLocked(ctx, input); err != nil {
+			return nil, err
+		}
+	}
+
+	resChan := make(chan error, 1)
+	obj.waiters = append(obj.waiters, resChan)
+	_, _ = obj.buf.Write(data)
+	obj.size += len(data)
+
+	if obj.buf.Len() >= s.conf.PartSize {
+		if err := s.uploadPartLocked(ctx, obj); err != nil {
+			s.failLocked(obj, err)
+			return resChan, nil
+		}
+	}
+	if (s.conf.MaxObjectSize > 0 && obj.size >= s.conf.MaxObjectSize) || len(obj.parts) >= s3MaxParts-1 {
+		s.completeLocked(ctx, obj)
+	}
+	return resChan, nil
+}
+
+func (s *s3ObjectStreamer) createLocked(ctx context.Context, input *s3manager.UploadInput) (*s3StreamedObject, error) {
+	res, err := s.client.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:                    input.Bucket,
+		Key:                       input.Key,
+		ContentType:               input.ContentType,
+		ContentEncoding:           input.ContentEncoding,
+		CacheControl:              input.CacheControl,
+		ContentDisposition:        input.ContentDisposition,
+		ContentLanguage:           input.ContentLanguage,
+		WebsiteRedirectLocation:   input.WebsiteRedirectLocation,
+		StorageClass:              input.StorageClass,
+		Metadata:                  input.Metadata,
+		Tagging:                   input.Tagging,
+		ServerSideEncryption:      input.ServerSideEncryption,
+		SSEKMSKeyId:               input.SSEKMSKeyId,
+		ObjectLockMode:            input.ObjectLockMode,
+		ObjectLockRetainUntilDate: input.ObjectLockRetainUntilDate,
+		ObjectLockLegalHoldStatus: input.ObjectLockLegalHoldStatus,
+		ChecksumAlgorithm:         input.ChecksumAlgorithm,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+
+	obj := &s3StreamedObject{
+		bucket:            aws.StringValue(input.Bucket),
+		key:               aws.StringValue(input.Key),
+		uploadID:          aws.StringValue(res.UploadId),
+		checksumAlgorithm: input.ChecksumAlgorithm,
+	}
+	if s.conf.MaxObjectAge > 0 {
+		obj.timer = time.AfterFunc(s.conf.MaxObjectAge, func() {
+			s.mut.Lock()
+			defer s.mut.Unlock()
+			if s.current != obj {
+				return
+			}
+			ctx, done := context.WithTimeout(context.Background(), s.timeout)
+			defer done()
+			s.completeLocked(ctx, obj)
+		})
+	}
+	s.current = obj
+	return obj, nil
+}
+
+func (s *s3ObjectStreamer) uploadPartLocked(ctx context.Context, obj *s3StreamedObject) error {
+	partNumber := int64(len(obj.parts) + 1)
+	res, err := s.client.UploadPartWithContext(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(obj.bucket),
+		Key:        aws.String(obj.key),
+		UploadId:   aws.String(obj.uploadID),
+		PartNumber: aws.Int64(partNumber),
+		Body:       bytes.NewReader(obj.buf.Bytes()),
+
+		ChecksumAlgorithm: obj.checksumAlgorithm,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload part %v: %w", partNumber, err)
+	}
+	obj.parts = append(obj.parts, &s3.CompletedPart{
+		ETag:          res.ETag,
+		PartNumber:    aws.Int64(partNumber),
+		ChecksumCRC32: res.ChecksumCRC32,
+	})
+	obj.buf.Reset()
+	return nil
+}
+
+func (s *s3ObjectStreamer) completeLocked(ctx context.Context, obj *s3StreamedObject) {
+	if obj.buf.Len() > 0 || len(obj.parts) == 0 {
+		if err := s.uploadPartLocked(ctx, obj); err != nil {
+			s.failLocked(obj, err)
+			return
+		}
+	}
+	if _, err := s.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(obj.bucket),
+		Key:      aws.String(obj.key),
+		UploadId: aws.String(obj.uploadID),
+		MultipartUpload: &s3.CompletedMultipartUpload{
+			Parts: obj.parts,
+		},
+	}); err != nil {
+		s.failLocked(obj, fmt.Errorf("failed to complete multipart upload: %w", err))
+		return
+	}
+	s.finishLocked(obj, nil)
+}
+
+func (s *s3ObjectStreamer) failLocked(obj *s3StreamedObject, err error) {
+	ctx, done := context.WithTimeout(context.Background(), s.timeout)
+	defer done()
+	if _, aerr := s.client.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(obj.bucket),
+		Key:      aws.String(obj.key),
+		UploadId: aws.String(obj.uploadID),
+	}); aerr != nil {
+		s.log.Errorf("Failed to abort multipart upload of object '%v': %v", obj.key, aerr)
+	}
+	s.finishLocked(obj, err)
+}
+
+func (s *s3ObjectStreamer) finishLocked(obj *s3StreamedObject, err error) {
+	if obj.timer != nil {
+		obj.timer.Stop()
+	}
+	for _, w := range obj.waiters {
+		w <- err
+	}
+	obj.waiters = nil
+	if s.current == obj {
+		s.current = nil
+	}
+}
+
+// Close completes the object currently in progress, if any.
+func (s *s3ObjectStreamer) Close(ctx context.Context) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.current != nil {
+		s.completeLocked(ctx, s.current)
+	}
+}
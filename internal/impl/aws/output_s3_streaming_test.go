@@ -0,0 +1,169 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+type mockMultipartS3 struct {
+	s3iface.S3API
+
+	mut       sync.Mutex
+	uploads   int
+	parts     map[string][][]byte
+	completed map[string][]byte
+	aborted   []string
+	partErr   error
+}
+
+func newMockMultipartS3() *mockMultipartS3 {
+	return &mockMultipartS3{
+		parts:     map[string][][]byte{},
+		completed: map[string][]byte{},
+	}
+}
+
+func (m *mockMultipartS3) CreateMultipartUploadWithContext(ctx aws.Context, input *s3.CreateMultipartUploadInput, opts ...request.Option) (*s3.CreateMultipartUploadOutput, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.uploads++
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String(strconv.Itoa(m.uploads))}, nil
+}
+
+func (m *mockMultipartS3) UploadPartWithContext(ctx aws.Context, input *s3.UploadPartInput, opts ...request.Option) (*s3.UploadPartOutput, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if m.partErr != nil {
+		return nil, m.partErr
+	}
+	data, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.parts[*input.UploadId] = append(m.parts[*input.UploadId], data)
+	return &s3.UploadPartOutput{ETag: aws.String(strconv.FormatInt(*input.PartNumber, 10))}, nil
+}
+
+func (m *mockMultipartS3) CompleteMultipartUploadWithContext(ctx aws.Context, input *s3.CompleteMultipartUploadInput, opts ...request.Option) (*s3.CompleteMultipartUploadOutput, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.completed[*input.Key] = bytes.Join(m.parts[*input.UploadId], nil)
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (m *mockMultipartS3) AbortMultipartUploadWithContext(ctx aws.Context, input *s3.AbortMultipartUploadInput, opts ...request.Option) (*s3.AbortMultipartUploadOutput, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.aborted = append(m.aborted, *input.Key)
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func testUploadInput(key string) *s3manager.UploadInput {
+	return &s3manager.UploadInput{
+		Bucket: aws.String("foo"),
+		Key:    aws.String(key),
+	}
+}
+
+func TestS3ObjectStreamerMaxSize(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	mock := newMockMultipartS3()
+	s := newS3ObjectStreamer(s3StreamingConfig{
+		Enabled:       true,
+		PartSize:      4,
+		MaxObjectSize: 10,
+	}, time.Second, mock, service.MockResources().Logger())
+
+	var resChans []<-chan error
+	for i, key := range []string{"a", "b", "c", "d"} {
+		resChan, err := s.Append(ctx, testUploadInput(key), []byte("hel"+strconv.Itoa(i)))
+		require.NoError(t, err)
+		resChans = append(resChans, resChan)
+	}
+
+	// The first three messages fill the first object, which is named after
+	// the first message.
+	for _, c := range resChans[:3] {
+		require.NoError(t, <-c)
+	}
+	select {
+	case <-resChans[3]:
+		t.Fatal("unexpected result for an incomplete object")
+	default:
+	}
+
+	s.Close(ctx)
+	require.NoError(t, <-resChans[3])
+
+	assert.Equal(t, map[string][]byte{
+		"a": []byte("hel0hel1hel2"),
+		"d": []byte("hel3"),
+	}, mock.completed)
+	assert.Equal(t, [][]byte{[]byte("hel0"), []byte("hel1"), []byte("hel2")}, mock.parts["1"])
+}
+
+func TestS3ObjectStreamerMaxAge(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	mock := newMockMultipartS3()
+	s := newS3ObjectStreamer(s3StreamingConfig{
+		Enabled:      true,
+		PartSize:     1024,
+		MaxObjectAge: time.Millisecond * 50,
+	}, time.Second, mock, service.MockResources().Logger())
+
+	resChan, err := s.Append(ctx, testUploadInput("a"), []byte("hello"))
+	require.NoError(t, err)
+
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+
+	mock.mut.Lock()
+	assert.Equal(t, map[string][]byte{"a": []byte("hello")}, mock.completed)
+	mock.mut.Unlock()
+}
+
+func TestS3ObjectStreamerPartFailure(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	mock := newMockMultipartS3()
+	mock.partErr = errors.New("nope")
+
+	s := newS3ObjectStreamer(s3StreamingConfig{
+		Enabled:  true,
+		PartSize: 4,
+	}, time.Second, mock, service.MockResources().Logger())
+
+	resChan, err := s.Append(ctx, testUploadInput("a"), []byte("hello"))
+	require.NoError(t, err)
+
+	err = <-resChan
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nope")
+	assert.Equal(t, []string{"a"}, mock.aborted)
+	assert.Empty(t, mock.completed)
+}
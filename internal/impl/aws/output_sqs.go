@@ -19,6 +19,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/component/output/span"
 	"github.com/benthosdev/benthos/v4/internal/impl/aws/config"
 	"github.com/benthosdev/benthos/v4/internal/impl/pure"
 	"github.com/benthosdev/benthos/v4/public/service"
@@ -98,6 +99,7 @@ By default Benthos will use a shared credentials file when connecting to AWS ser
 			service.NewMetadataExcludeFilterField(snsoFieldMetadata).
 				Description("Specify criteria for which metadata values are sent as headers."),
 			service.NewBatchPolicyField(koFieldBatching),
+			span.InjectTracingSpanMappingDocs().Version("4.24.0"),
 		).
 		Fields(config.SessionFields()...).
 		Fields(pure.CommonRetryBackOffFields(0, "1s", "5s", "30s")...)
@@ -116,7 +118,10 @@ func init() {
 			if wConf, err = sqsoConfigFromParsed(conf); err != nil {
 				return
 			}
-			out, err = newSQSWriter(wConf, mgr)
+			if out, err = newSQSWriter(wConf, mgr); err != nil {
+				return
+			}
+			out, err = span.NewBatchOutput("aws_sqs", conf, out, mgr)
 			return
 		})
 	if err != nil {
@@ -0,0 +1,41 @@
+package parquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/segmentio/parquet-go"
+
+	"github.com/benthosdev/benthos/v4/internal/impl/aws"
+)
+
+func init() {
+	aws.S3InventoryParquetRowsFn = s3InventoryParquetRows
+}
+
+func s3InventoryParquetRows(r io.Reader) (aws.S3InventoryRowsReader, error) {
+	fileBytes, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	inFile, err := parquet.OpenFile(bytes.NewReader(fileBytes), int64(len(fileBytes)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open inventory file: %w", err)
+	}
+
+	rdr := parquet.NewGenericReader[aws.S3InventoryRow](inFile)
+	rows := make([]aws.S3InventoryRow, 1)
+	return func() (*aws.S3InventoryRow, error) {
+		n, err := rdr.Read(rows)
+		if n == 1 {
+			row := rows[0]
+			return &row, nil
+		}
+		if err == nil {
+			err = io.EOF
+		}
+		return nil, err
+	}, nil
+}
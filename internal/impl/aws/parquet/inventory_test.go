@@ -0,0 +1,41 @@
+package parquet
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/impl/aws"
+)
+
+func TestS3InventoryParquetRows(t *testing.T) {
+	input := []aws.S3InventoryRow{
+		{Bucket: "src", Key: "foo.json"},
+		{Bucket: "src", Key: "bar.json", IsDeleteMarker: true},
+		{Bucket: "src", Key: "baz.json"},
+	}
+
+	var buf bytes.Buffer
+	pw := parquet.NewGenericWriter[aws.S3InventoryRow](&buf)
+	_, err := pw.Write(input)
+	require.NoError(t, err)
+	require.NoError(t, pw.Close())
+
+	rows, err := aws.S3InventoryParquetRowsFn(&buf)
+	require.NoError(t, err)
+
+	var output []aws.S3InventoryRow
+	for {
+		row, err := rows()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		output = append(output, *row)
+	}
+	assert.Equal(t, input, output)
+}
@@ -0,0 +1,68 @@
+package cbor
+
+import (
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+func init() {
+	cborParseSpec := bloblang.NewPluginSpec().
+		Category("Parsing").
+		Description("Parses a [CBOR](https://cbor.io/) message into a structured document.").
+		Version("4.24.0").
+		Example("",
+			`root = content().decode("hex").parse_cbor()`,
+			[2]string{
+				`a163666f6f63626172`,
+				`{"foo":"bar"}`,
+			}).
+		Example("",
+			`root = this.encoded.decode("base64").parse_cbor()`,
+			[2]string{
+				`{"encoded":"oWNmb29jYmFy"}`,
+				`{"foo":"bar"}`,
+			})
+
+	if err := bloblang.RegisterMethodV2(
+		"parse_cbor", cborParseSpec,
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return func(v any) (any, error) {
+				b, err := query.IGetBytes(v)
+				if err != nil {
+					return nil, err
+				}
+				return unmarshal(b)
+			}, nil
+		},
+	); err != nil {
+		panic(err)
+	}
+
+	cborFormatSpec := bloblang.NewPluginSpec().
+		Category("Parsing").
+		Description("Formats data as a [CBOR](https://cbor.io/) message in bytes format.").
+		Version("4.24.0").
+		Example("",
+			`root = this.format_cbor().encode("hex")`,
+			[2]string{
+				`{"foo":"bar"}`,
+				`a163666f6f63626172`,
+			}).
+		Example("",
+			`root.encoded = this.format_cbor().encode("base64")`,
+			[2]string{
+				`{"foo":"bar"}`,
+				`{"encoded":"oWNmb29jYmFy"}`,
+			})
+
+	if err := bloblang.RegisterMethodV2(
+		"format_cbor", cborFormatSpec,
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return func(v any) (any, error) {
+				return marshal(v)
+			}, nil
+		},
+	); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,65 @@
+package cbor
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+var decMode cbor.DecMode
+
+func init() {
+	var err error
+	if decMode, err = (cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]any(nil)),
+	}).DecMode(); err != nil {
+		panic(err)
+	}
+}
+
+// unmarshal parses a CBOR document into a generic structure where maps are
+// represented as map[string]any, and therefore can be serialized to JSON.
+func unmarshal(b []byte) (any, error) {
+	var v any
+	if err := decMode.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// marshal serializes a generic structure as a CBOR document, where json.Number
+// values are encoded as integers or floats rather than strings.
+func marshal(v any) ([]byte, error) {
+	return cbor.Marshal(normaliseNumbers(v))
+}
+
+func normaliseNumbers(v any) any {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(t.String(), 10, 64); err == nil {
+			return u
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+		return t.String()
+	case map[string]any:
+		res := make(map[string]any, len(t))
+		for k, e := range t {
+			res[k] = normaliseNumbers(e)
+		}
+		return res
+	case []any:
+		res := make([]any, len(t))
+		for i, e := range t {
+			res[i] = normaliseNumbers(e)
+		}
+		return res
+	}
+	return v
+}
@@ -0,0 +1,89 @@
+package cbor
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestCBORToJSON(t *testing.T) {
+	input, err := marshal(map[string]any{
+		"key":      "foo",
+		"trueKey":  true,
+		"nullKey":  nil,
+		"intKey":   -123,
+		"floatKey": 45.5,
+		"array":    []any{"bar"},
+		"nested":   map[string]any{"key": "baz"},
+	})
+	require.NoError(t, err)
+
+	proc, err := newProcessor("to_json")
+	require.NoError(t, err)
+
+	msgs, err := proc.Process(context.Background(), service.NewMessage(input))
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+
+	act, err := msgs[0].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"key":      "foo",
+		"trueKey":  true,
+		"nullKey":  nil,
+		"intKey":   int64(-123),
+		"floatKey": 45.5,
+		"array":    []any{"bar"},
+		"nested":   map[string]any{"key": "baz"},
+	}, act)
+
+	_, err = proc.Process(context.Background(), service.NewMessage([]byte{0xff}))
+	require.Error(t, err)
+}
+
+func TestCBORFromJSON(t *testing.T) {
+	proc, err := newProcessor("from_json")
+	require.NoError(t, err)
+
+	msgs, err := proc.Process(context.Background(), service.NewMessage([]byte(
+		`{"int":13,"neg":-257,"uint64":18446744073709551615,"float":45.6,"array":["bar"],"nested":{"key":"baz"}}`,
+	)))
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+
+	rawBytes, err := msgs[0].AsBytes()
+	require.NoError(t, err)
+
+	act, err := unmarshal(rawBytes)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"int":    uint64(13),
+		"neg":    int64(-257),
+		"uint64": uint64(18446744073709551615),
+		"float":  45.6,
+		"array":  []any{"bar"},
+		"nested": map[string]any{"key": "baz"},
+	}, act)
+}
+
+func TestCBORBloblangRoundTrip(t *testing.T) {
+	exe, err := bloblang.Parse(`root = this.format_cbor().parse_cbor()`)
+	require.NoError(t, err)
+
+	res, err := exe.Query(map[string]any{"foo": []any{"bar", true}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"foo": []any{"bar", true}}, res)
+
+	exe, err = bloblang.Parse(`root = this.format_cbor().encode("hex")`)
+	require.NoError(t, err)
+
+	res, err = exe.Query(map[string]any{"foo": "bar"})
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString([]byte("\xa1cfoocbar")), res)
+}
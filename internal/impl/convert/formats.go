@@ -0,0 +1,323 @@
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/clbanning/mxj/v2"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/linkedin/goavro/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/internal/impl/protobuf"
+	"github.com/benthosdev/benthos/v4/internal/impl/xml"
+)
+
+const (
+	formatJSON     = "json"
+	formatAvro     = "avro"
+	formatProtobuf = "protobuf"
+	formatMsgPack  = "msgpack"
+	formatCBOR     = "cbor"
+	formatXML      = "xml"
+)
+
+func formatNames() []string {
+	return []string{formatJSON, formatAvro, formatProtobuf, formatMsgPack, formatCBOR, formatXML}
+}
+
+// defaultContentTypes maps commonly used content types to the formats they
+// represent.
+var defaultContentTypes = map[string]string{
+	"application/json":                   formatJSON,
+	"text/json":                          formatJSON,
+	"application/avro":                   formatAvro,
+	"avro/binary":                        formatAvro,
+	"application/vnd.apache.avro+binary": formatAvro,
+	"application/protobuf":               formatProtobuf,
+	"application/x-protobuf":             formatProtobuf,
+	"application/vnd.google.protobuf":    formatProtobuf,
+	"application/msgpack":                formatMsgPack,
+	"application/x-msgpack":              formatMsgPack,
+	"application/vnd.msgpack":            formatMsgPack,
+	"application/cbor":                   formatCBOR,
+	"application/xml":                    formatXML,
+	"text/xml":                           formatXML,
+}
+
+// canonicalContentTypes are the content types written to messages once they
+// have been converted into a format.
+var canonicalContentTypes = map[string]string{
+	formatJSON:     "application/json",
+	formatAvro:     "application/avro",
+	formatProtobuf: "application/x-protobuf",
+	formatMsgPack:  "application/msgpack",
+	formatCBOR:     "application/cbor",
+	formatXML:      "application/xml",
+}
+
+// formatFromContentType returns the format of a content type, taking into
+// account custom mappings, media type parameters and structured syntax
+// suffixes such as `application/vnd.foo+json`.
+func formatFromContentType(custom map[string]string, contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	if f, exists := custom[mediaType]; exists {
+		return f, true
+	}
+	if f, exists := defaultContentTypes[mediaType]; exists {
+		return f, true
+	}
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		switch mediaType[i+1:] {
+		case "json":
+			return formatJSON, true
+		case "xml":
+			return formatXML, true
+		case "cbor":
+			return formatCBOR, true
+		}
+	}
+	return "", false
+}
+
+//------------------------------------------------------------------------------
+
+// codec decodes payloads of a format into generic structures and encodes
+// generic structures back into payloads.
+type codec interface {
+	decode(b []byte) (any, error)
+	encode(v any) ([]byte, error)
+}
+
+// normaliseNumbers replaces json.Number values, which are produced when
+// parsing JSON documents, with their integer or floating point values so that
+// they can be encoded in binary formats.
+func normaliseNumbers(v any) any {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+		return t.String()
+	case map[string]any:
+		for k, e := range t {
+			t[k] = normaliseNumbers(e)
+		}
+	case []any:
+		for i, e := range t {
+			t[i] = normaliseNumbers(e)
+		}
+	}
+	return v
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) decode(b []byte) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (jsonCodec) encode(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+type msgPackCodec struct{}
+
+func (msgPackCodec) decode(b []byte) (any, error) {
+	var v any
+	if err := msgpack.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (msgPackCodec) encode(v any) ([]byte, error) {
+	return msgpack.Marshal(normaliseNumbers(v))
+}
+
+type cborCodec struct {
+	decMode cbor.DecMode
+}
+
+func newCBORCodec() (*cborCodec, error) {
+	decMode, err := cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]any(nil)),
+	}.DecMode()
+	if err != nil {
+		return nil, err
+	}
+	return &cborCodec{decMode: decMode}, nil
+}
+
+func (c *cborCodec) decode(b []byte) (any, error) {
+	var v any
+	if err := c.decMode.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (c *cborCodec) encode(v any) ([]byte, error) {
+	return cbor.Marshal(normaliseNumbers(v))
+}
+
+type xmlCodec struct {
+	cast bool
+}
+
+func (c xmlCodec) decode(b []byte) (any, error) {
+	return xml.ToMap(b, c.cast)
+}
+
+func (c xmlCodec) encode(v any) ([]byte, error) {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) != 1 {
+		return nil, errors.New("converting to XML requires an object with a single root key")
+	}
+	return mxj.Map(normaliseNumbers(m).(map[string]any)).Xml()
+}
+
+//------------------------------------------------------------------------------
+
+type avroCodec struct {
+	encoding string
+	codec    *goavro.Codec
+}
+
+func newAvroCodec(schema, encoding string) (*avroCodec, error) {
+	if schema == "" {
+		return nil, errors.New("an avro.schema must be specified in order to convert Avro documents")
+	}
+	c, err := goavro.NewCodec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Avro schema: %w", err)
+	}
+	return &avroCodec{encoding: encoding, codec: c}, nil
+}
+
+func (a *avroCodec) decode(b []byte) (v any, err error) {
+	switch a.encoding {
+	case "textual":
+		v, _, err = a.codec.NativeFromTextual(b)
+	case "single":
+		v, _, err = a.codec.NativeFromSingle(b)
+	default:
+		v, _, err = a.codec.NativeFromBinary(b)
+	}
+	return
+}
+
+func (a *avroCodec) encode(v any) ([]byte, error) {
+	// Round trip through JSON so that numbers match the types goavro expects.
+	jBytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	native, _, err := a.codec.NativeFromTextual(jBytes)
+	if err != nil {
+		return nil, err
+	}
+	switch a.encoding {
+	case "textual":
+		return a.codec.TextualFromNative(nil, native)
+	case "single":
+		return a.codec.SingleFromNative(nil, native)
+	}
+	return a.codec.BinaryFromNative(nil, native)
+}
+
+type protobufCodec struct {
+	desc  protoreflect.MessageDescriptor
+	types *protoregistry.Types
+}
+
+func newProtobufCodec(f ifs.FS, message string, importPaths []string) (*protobufCodec, error) {
+	if message == "" {
+		return nil, errors.New("a protobuf.message must be specified in order to convert protobuf messages")
+	}
+
+	filesMap := map[string]string{}
+	for _, importPath := range importPaths {
+		if err := fs.WalkDir(f, importPath, func(path string, info fs.DirEntry, ferr error) error {
+			if ferr != nil || info.IsDir() || filepath.Ext(info.Name()) != ".proto" {
+				return ferr
+			}
+			rPath, ferr := filepath.Rel(importPath, path)
+			if ferr != nil {
+				return fmt.Errorf("failed to get relative path: %v", ferr)
+			}
+			content, ferr := ifs.ReadFile(f, path)
+			if ferr != nil {
+				return fmt.Errorf("failed to read import %v: %v", path, ferr)
+			}
+			filesMap[rPath] = string(content)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	files, types, err := protobuf.RegistriesFromMap(filesMap)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := files.FindDescriptorByName(protoreflect.FullName(message))
+	if err != nil {
+		return nil, fmt.Errorf("unable to find message '%v' definition within '%v'", message, importPaths)
+	}
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("message descriptor %v was unexpected type %T", message, d)
+	}
+	return &protobufCodec{desc: md, types: types}, nil
+}
+
+func (p *protobufCodec) decode(b []byte) (any, error) {
+	dynMsg := dynamicpb.NewMessage(p.desc)
+	if err := proto.Unmarshal(b, dynMsg); err != nil {
+		return nil, err
+	}
+	jBytes, err := protojson.MarshalOptions{Resolver: p.types}.Marshal(dynMsg)
+	if err != nil {
+		return nil, err
+	}
+	return jsonCodec{}.decode(jBytes)
+}
+
+func (p *protobufCodec) encode(v any) ([]byte, error) {
+	jBytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dynMsg := dynamicpb.NewMessage(p.desc)
+	if err := (protojson.UnmarshalOptions{Resolver: p.types}).Unmarshal(jBytes, dynMsg); err != nil {
+		return nil, err
+	}
+	return proto.Marshal(dynMsg)
+}
@@ -0,0 +1,313 @@
+package convert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	cpFieldContentTypeKey  = "content_type_key"
+	cpFieldDefaultFormat   = "default_format"
+	cpFieldConversions     = "conversions"
+	cpFieldContentTypes    = "content_types"
+	cpFieldSetContentType  = "set_content_type"
+	cpFieldAvro            = "avro"
+	cpFieldAvroSchema      = "schema"
+	cpFieldAvroEncoding    = "encoding"
+	cpFieldProtobuf        = "protobuf"
+	cpFieldProtobufMessage = "message"
+	cpFieldProtobufImports = "import_paths"
+	cpFieldXMLCast         = "xml_cast"
+	cpFieldCanonicalJSON   = "canonical_json"
+)
+
+func convertProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Parsing").
+		Version("4.24.0").
+		Summary("Converts messages between JSON, Avro, Protobuf, MessagePack, CBOR and XML formats according to their content type and a table of conversions.").
+		Description(`
+The format of each message is determined by reading its content type from the metadata key `+"`content_type_key`"+`, and if the `+"`conversions`"+` table contains that format then the message is converted into the target format of the table. This makes it possible to normalise topics that contain messages of multiple formats with a single processor.
+
+Messages of formats that are not listed within `+"`conversions`"+` are left unchanged. Once a message has been converted its content type metadata is updated to the canonical content type of the target format, unless `+"`set_content_type`"+` is `+"`false`"+`.
+
+### Content Types
+
+The following content types are recognised by default, including when they contain parameters such as `+"`charset`"+`:
+
+| Format | Content Types |
+|--------|---------------|
+| `+"`json`"+` | `+"`application/json`, `text/json`, `*+json`"+` |
+| `+"`avro`"+` | `+"`application/avro`, `avro/binary`, `application/vnd.apache.avro+binary`"+` |
+| `+"`protobuf`"+` | `+"`application/protobuf`, `application/x-protobuf`, `application/vnd.google.protobuf`"+` |
+| `+"`msgpack`"+` | `+"`application/msgpack`, `application/x-msgpack`, `application/vnd.msgpack`"+` |
+| `+"`cbor`"+` | `+"`application/cbor`, `*+cbor`"+` |
+| `+"`xml`"+` | `+"`application/xml`, `text/xml`, `*+xml`"+` |
+
+Additional content types can be mapped to formats with the field `+"`content_types`"+`.
+
+### Schemas
+
+Avro and Protobuf conversions require a schema, which is configured with the `+"`avro`"+` and `+"`protobuf`"+` fields respectively. Conversions between other formats are performed without a schema.
+
+### XML
+
+XML documents are converted following the same rules as the `+"[`xml` processor](/docs/components/processors/xml#operators)"+`, and therefore converting a structure into XML requires it to be an object with a single root key.
+
+### Canonical JSON
+
+When `+"`canonical_json`"+` is `+"`true`"+` messages converted into JSON are serialized as canonical JSON following [RFC 8785](https://www.rfc-editor.org/rfc/rfc8785), where object keys are sorted, numbers are formatted consistently and HTML characters are not escaped. The result is stable across runs, which makes it suitable for hash based deduplication and signature verification. JSON messages can be canonicalized without changing their format by adding the conversion `+"`json: json`"+`.`).
+		Fields(
+			service.NewStringField(cpFieldContentTypeKey).
+				Description("The metadata key to read the content type of messages from.").
+				Default("content_type"),
+			service.NewStringEnumField(cpFieldDefaultFormat, append([]string{""}, formatNames()...)...).
+				Description("The format of messages that are missing a content type, or have a content type that is not recognised. When empty these messages fail processing.").
+				Default(""),
+			service.NewStringMapField(cpFieldConversions).
+				Description("A table of source formats to the formats they should be converted into, where formats are one of `json`, `avro`, `protobuf`, `msgpack`, `cbor` or `xml`.").
+				Example(map[string]any{"msgpack": "json", "cbor": "json", "xml": "json"}),
+			service.NewStringMapField(cpFieldContentTypes).
+				Description("Additional content types to map to formats.").
+				Example(map[string]any{"application/vnd.acme.event": "protobuf"}).
+				Default(map[string]any{}).
+				Advanced(),
+			service.NewBoolField(cpFieldSetContentType).
+				Description("Whether to update the content type metadata of converted messages.").
+				Default(true).
+				Advanced(),
+			service.NewObjectField(cpFieldAvro,
+				service.NewStringField(cpFieldAvroSchema).
+					Description("A full Avro schema used for conversions to and from Avro.").
+					Default(""),
+				service.NewStringEnumField(cpFieldAvroEncoding, "binary", "textual", "single").
+					Description("The Avro encoding of documents.").
+					Default("binary"),
+			).
+				Description("Configuration for conversions to and from Avro."),
+			service.NewObjectField(cpFieldProtobuf,
+				service.NewStringField(cpFieldProtobufMessage).
+					Description("The fully qualified name of the protobuf message to convert to and from.").
+					Default(""),
+				service.NewStringListField(cpFieldProtobufImports).
+					Description("A list of directories containing .proto files, including all definitions required for parsing the message.").
+					Default([]any{}),
+			).
+				Description("Configuration for conversions to and from Protobuf."),
+			service.NewBoolField(cpFieldXMLCast).
+				Description("Whether to cast the values of XML documents into numbers and booleans when converting from XML.").
+				Default(false).
+				Advanced(),
+			service.NewBoolField(cpFieldCanonicalJSON).
+				Description("Whether to serialize messages converted into JSON as [canonical JSON](#canonical-json), including messages of the conversion `json: json`.").
+				Default(false).
+				Advanced(),
+		).
+		Example(
+			"Normalise Events to JSON",
+			"Here we consume a topic containing events published as JSON, MessagePack, CBOR and Protobuf by various producers, and convert them all into JSON.",
+			`
+input:
+  kafka_franz:
+    seed_brokers: [ localhost:9092 ]
+    topics: [ events ]
+    consumer_group: benthos
+
+pipeline:
+  processors:
+    - convert:
+        content_type_key: content-type
+        conversions:
+          msgpack: json
+          cbor: json
+          protobuf: json
+        protobuf:
+          message: acme.Event
+          import_paths: [ ./schemas ]
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"convert", convertProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			return newConvertProcessorFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type convertProcessor struct {
+	contentTypeKey string
+	defaultFormat  string
+	contentTypes   map[string]string
+	conversions    map[string]string
+	setContentType bool
+	canonicalJSON  bool
+	codecs         map[string]codec
+}
+
+func newConvertProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*convertProcessor, error) {
+	p := &convertProcessor{codecs: map[string]codec{}}
+
+	var err error
+	if p.contentTypeKey, err = conf.FieldString(cpFieldContentTypeKey); err != nil {
+		return nil, err
+	}
+	if p.defaultFormat, err = conf.FieldString(cpFieldDefaultFormat); err != nil {
+		return nil, err
+	}
+	if p.contentTypes, err = conf.FieldStringMap(cpFieldContentTypes); err != nil {
+		return nil, err
+	}
+	if p.setContentType, err = conf.FieldBool(cpFieldSetContentType); err != nil {
+		return nil, err
+	}
+	if p.canonicalJSON, err = conf.FieldBool(cpFieldCanonicalJSON); err != nil {
+		return nil, err
+	}
+	if p.conversions, err = conf.FieldStringMap(cpFieldConversions); err != nil {
+		return nil, err
+	}
+	if len(p.conversions) == 0 {
+		return nil, errors.New("at least one conversion must be specified")
+	}
+
+	validFormat := func(f string) bool {
+		_, exists := canonicalContentTypes[f]
+		return exists
+	}
+	for contentType, f := range p.contentTypes {
+		if !validFormat(f) {
+			return nil, fmt.Errorf("content type %v is mapped to unknown format: %v", contentType, f)
+		}
+	}
+
+	// Only the codecs used by the conversions table are initialised, which
+	// means schemas are only required when they are needed.
+	for from, to := range p.conversions {
+		for _, f := range []string{from, to} {
+			if !validFormat(f) {
+				return nil, fmt.Errorf("unknown format: %v", f)
+			}
+			if _, exists := p.codecs[f]; exists {
+				continue
+			}
+			c, err := newCodec(f, conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			p.codecs[f] = c
+		}
+	}
+	return p, nil
+}
+
+func newCodec(format string, conf *service.ParsedConfig, mgr *service.Resources) (codec, error) {
+	switch format {
+	case formatJSON:
+		return jsonCodec{}, nil
+	case formatMsgPack:
+		return msgPackCodec{}, nil
+	case formatCBOR:
+		return newCBORCodec()
+	case formatXML:
+		cast, err := conf.FieldBool(cpFieldXMLCast)
+		if err != nil {
+			return nil, err
+		}
+		return xmlCodec{cast: cast}, nil
+	case formatAvro:
+		schema, err := conf.FieldString(cpFieldAvro, cpFieldAvroSchema)
+		if err != nil {
+			return nil, err
+		}
+		encoding, err := conf.FieldString(cpFieldAvro, cpFieldAvroEncoding)
+		if err != nil {
+			return nil, err
+		}
+		return newAvroCodec(schema, encoding)
+	case formatProtobuf:
+		message, err := conf.FieldString(cpFieldProtobuf, cpFieldProtobufMessage)
+		if err != nil {
+			return nil, err
+		}
+		importPaths, err := conf.FieldStringList(cpFieldProtobuf, cpFieldProtobufImports)
+		if err != nil {
+			return nil, err
+		}
+		return newProtobufCodec(mgr.FS(), message, importPaths)
+	}
+	return nil, fmt.Errorf("unknown format: %v", format)
+}
+
+func (p *convertProcessor) sourceFormat(msg *service.Message) (string, error) {
+	contentType, _ := msg.MetaGet(p.contentTypeKey)
+	if contentType != "" {
+		if f, ok := formatFromContentType(p.contentTypes, contentType); ok {
+			return f, nil
+		}
+	}
+	if p.defaultFormat != "" {
+		return p.defaultFormat, nil
+	}
+	if contentType == "" {
+		return "", fmt.Errorf("metadata key %v does not contain a content type", p.contentTypeKey)
+	}
+	return "", fmt.Errorf("content type not recognised: %v", contentType)
+}
+
+func (p *convertProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	from, err := p.sourceFormat(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	to, exists := p.conversions[from]
+	if !exists || (to == from && !(to == formatJSON && p.canonicalJSON)) {
+		return service.MessageBatch{msg}, nil
+	}
+
+	mBytes, err := msg.AsBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := p.codecs[from].decode(mBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %v message: %w", from, err)
+	}
+
+	switch {
+	case to == formatJSON && p.canonicalJSON:
+		resBytes, err := message.MarshalCanonicalJSON(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %v message to canonical json: %w", from, err)
+		}
+		msg.SetBytes(resBytes)
+	case to == formatJSON:
+		msg.SetStructuredMut(v)
+	default:
+		resBytes, err := p.codecs[to].encode(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %v message to %v: %w", from, to, err)
+		}
+		msg.SetBytes(resBytes)
+	}
+
+	if p.setContentType {
+		msg.MetaSetMut(p.contentTypeKey, canonicalContentTypes[to])
+	}
+	return service.MessageBatch{msg}, nil
+}
+
+func (p *convertProcessor) Close(ctx context.Context) error {
+	return nil
+}
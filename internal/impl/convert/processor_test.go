@@ -0,0 +1,282 @@
+package convert
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func testConvert(t testing.TB, proc *convertProcessor, contentType string, payload []byte) *service.Message {
+	t.Helper()
+
+	msg := service.NewMessage(payload)
+	if contentType != "" {
+		msg.MetaSetMut("content_type", contentType)
+	}
+	batch, err := proc.Process(context.Background(), msg)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	return batch[0]
+}
+
+func TestConvertToJSON(t *testing.T) {
+	conf, err := convertProcessorConfig().ParseYAML(`
+conversions:
+  msgpack: json
+  cbor: json
+  xml: json
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newConvertProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	msgpackBytes, err := msgpack.Marshal(map[string]any{"id": 1, "name": "foo"})
+	require.NoError(t, err)
+
+	cborBytes, err := cbor.Marshal(map[string]any{"id": 2, "name": "bar"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		contentType string
+		payload     []byte
+		output      string
+	}{
+		{name: "msgpack", contentType: "application/x-msgpack", payload: msgpackBytes, output: `{"id":1,"name":"foo"}`},
+		{name: "cbor", contentType: "application/cbor", payload: cborBytes, output: `{"id":2,"name":"bar"}`},
+		{name: "xml", contentType: "text/xml; charset=utf-8", payload: []byte(`<root><id>3</id></root>`), output: `{"root":{"id":"3"}}`},
+		{name: "json unchanged", contentType: "application/vnd.foo+json", payload: []byte(`{"id":4}`), output: `{"id":4}`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			msg := testConvert(t, proc, test.contentType, test.payload)
+
+			mBytes, err := msg.AsBytes()
+			require.NoError(t, err)
+			assert.JSONEq(t, test.output, string(mBytes))
+
+			contentType, _ := msg.MetaGet("content_type")
+			if test.name == "json unchanged" {
+				assert.Equal(t, test.contentType, contentType)
+			} else {
+				assert.Equal(t, "application/json", contentType)
+			}
+		})
+	}
+}
+
+func TestConvertFromJSON(t *testing.T) {
+	conf, err := convertProcessorConfig().ParseYAML(`
+conversions:
+  json: cbor
+  msgpack: xml
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newConvertProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	msg := testConvert(t, proc, "application/json", []byte(`{"id":1,"tags":["a","b"],"ratio":0.5}`))
+	mBytes, err := msg.AsBytes()
+	require.NoError(t, err)
+
+	var v any
+	require.NoError(t, cbor.Unmarshal(mBytes, &v))
+	assert.Equal(t, map[any]any{"id": uint64(1), "tags": []any{"a", "b"}, "ratio": 0.5}, v)
+
+	msgpackBytes, err := msgpack.Marshal(map[string]any{"doc": map[string]any{"id": "foo"}})
+	require.NoError(t, err)
+
+	msg = testConvert(t, proc, "application/msgpack", msgpackBytes)
+	mBytes, err = msg.AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, `<doc><id>foo</id></doc>`, string(mBytes))
+
+	contentType, _ := msg.MetaGet("content_type")
+	assert.Equal(t, "application/xml", contentType)
+}
+
+func TestConvertAvro(t *testing.T) {
+	schema := `{"type":"record","name":"foo","fields":[{"name":"id","type":"long"},{"name":"name","type":"string"}]}`
+	codec, err := goavro.NewCodec(schema)
+	require.NoError(t, err)
+
+	avroBytes, err := codec.BinaryFromNative(nil, map[string]any{"id": 5, "name": "foo"})
+	require.NoError(t, err)
+
+	toJSONConf, err := convertProcessorConfig().ParseYAML(`
+conversions:
+  avro: json
+avro:
+  schema: '`+schema+`'
+`, nil)
+	require.NoError(t, err)
+
+	toJSON, err := newConvertProcessorFromConfig(toJSONConf, service.MockResources())
+	require.NoError(t, err)
+
+	msg := testConvert(t, toJSON, "avro/binary", avroBytes)
+	mBytes, err := msg.AsBytes()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":5,"name":"foo"}`, string(mBytes))
+
+	fromJSONConf, err := convertProcessorConfig().ParseYAML(`
+conversions:
+  json: avro
+avro:
+  schema: '`+schema+`'
+`, nil)
+	require.NoError(t, err)
+
+	fromJSON, err := newConvertProcessorFromConfig(fromJSONConf, service.MockResources())
+	require.NoError(t, err)
+
+	msg = testConvert(t, fromJSON, "application/json", []byte(`{"id":5,"name":"foo"}`))
+	mBytes, err = msg.AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, avroBytes, mBytes)
+}
+
+func TestConvertProtobuf(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "event.proto"), []byte(`
+syntax = "proto3";
+package testing;
+
+message Event {
+  string id = 1;
+  int32 count = 2;
+}
+`), 0o644))
+
+	conf, err := convertProcessorConfig().ParseYAML(`
+conversions:
+  json: protobuf
+  protobuf: msgpack
+protobuf:
+  message: testing.Event
+  import_paths: [ `+dir+` ]
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newConvertProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	pbMsg := testConvert(t, proc, "application/json", []byte(`{"id":"foo","count":3}`))
+	pbBytes, err := pbMsg.AsBytes()
+	require.NoError(t, err)
+
+	contentType, _ := pbMsg.MetaGet("content_type")
+	assert.Equal(t, "application/x-protobuf", contentType)
+
+	msg := testConvert(t, proc, contentType, pbBytes)
+	mBytes, err := msg.AsBytes()
+	require.NoError(t, err)
+
+	var v map[string]any
+	require.NoError(t, msgpack.Unmarshal(mBytes, &v))
+	assert.Equal(t, map[string]any{"id": "foo", "count": int64(3)}, v)
+}
+
+func TestConvertUnknownContentType(t *testing.T) {
+	conf, err := convertProcessorConfig().ParseYAML(`
+conversions:
+  msgpack: json
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newConvertProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	_, err = proc.Process(context.Background(), service.NewMessage([]byte(`{}`)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not contain a content type")
+
+	msg := service.NewMessage([]byte(`hello`))
+	msg.MetaSetMut("content_type", "text/plain")
+	_, err = proc.Process(context.Background(), msg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "content type not recognised: text/plain")
+
+	defaultConf, err := convertProcessorConfig().ParseYAML(`
+default_format: msgpack
+conversions:
+  msgpack: json
+`, nil)
+	require.NoError(t, err)
+
+	defaultProc, err := newConvertProcessorFromConfig(defaultConf, service.MockResources())
+	require.NoError(t, err)
+
+	msgpackBytes, err := msgpack.Marshal(map[string]any{"id": "foo"})
+	require.NoError(t, err)
+
+	msg = testConvert(t, defaultProc, "", msgpackBytes)
+	mBytes, err := msg.AsBytes()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"foo"}`, string(mBytes))
+}
+
+func TestConvertMissingSchema(t *testing.T) {
+	conf, err := convertProcessorConfig().ParseYAML(`
+conversions:
+  avro: json
+`, nil)
+	require.NoError(t, err)
+
+	_, err = newConvertProcessorFromConfig(conf, service.MockResources())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "an avro.schema must be specified")
+}
+
+func TestConvertCanonicalJSON(t *testing.T) {
+	conf, err := convertProcessorConfig().ParseYAML(`
+conversions:
+  json: json
+  msgpack: json
+canonical_json: true
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newConvertProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	msgpackBytes, err := msgpack.Marshal(map[string]any{"name": "<foo>", "id": 1.0})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		contentType string
+		payload     []byte
+		output      string
+	}{
+		{name: "json", contentType: "application/json", payload: []byte(`{ "name": "<foo>", "id": 1.0e0 }`), output: `{"id":1,"name":"<foo>"}`},
+		{name: "msgpack", contentType: "application/msgpack", payload: msgpackBytes, output: `{"id":1,"name":"<foo>"}`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			msg := testConvert(t, proc, test.contentType, test.payload)
+
+			mBytes, err := msg.AsBytes()
+			require.NoError(t, err)
+			assert.Equal(t, test.output, string(mBytes))
+
+			contentType, _ := msg.MetaGet("content_type")
+			assert.Equal(t, "application/json", contentType)
+		})
+	}
+}
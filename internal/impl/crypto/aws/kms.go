@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+
+	"github.com/benthosdev/benthos/v4/internal/impl/crypto"
+	"github.com/benthosdev/benthos/v4/public/service"
+
+	sess "github.com/benthosdev/benthos/v4/internal/impl/aws"
+)
+
+func init() {
+	crypto.AWSKMSKeyWrapperFromConfigFn = func(c *service.ParsedConfig) (crypto.KeyWrapper, error) {
+		awsSession, err := sess.GetSession(c)
+		if err != nil {
+			return nil, err
+		}
+
+		keyID, err := c.FieldString("key_id")
+		if err != nil {
+			return nil, err
+		}
+		return &kmsKeyWrapper{client: kms.New(awsSession), keyID: keyID}, nil
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type kmsKeyWrapper struct {
+	client kmsiface.KMSAPI
+	keyID  string
+}
+
+func (k *kmsKeyWrapper) WrapKey(ctx context.Context, key []byte) ([]byte, string, error) {
+	if k.keyID == "" {
+		return nil, "", errors.New("a key_id must be set in order to encrypt")
+	}
+	out, err := k.client.EncryptWithContext(ctx, &kms.EncryptInput{
+		KeyId:     aws.String(k.keyID),
+		Plaintext: key,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return out.CiphertextBlob, aws.StringValue(out.KeyId), nil
+}
+
+func (k *kmsKeyWrapper) UnwrapKey(ctx context.Context, wrapped []byte, keyID string) ([]byte, error) {
+	input := &kms.DecryptInput{CiphertextBlob: wrapped}
+	if keyID != "" {
+		input.KeyId = aws.String(keyID)
+	}
+	out, err := k.client.DecryptWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
@@ -0,0 +1,149 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+// KeyWrapper encrypts (wraps) and decrypts (unwraps) the data keys of the
+// field_crypt processor with a key management service.
+type KeyWrapper interface {
+	// WrapKey encrypts a data key, returning the wrapped key along with an ID
+	// of the key it was wrapped with.
+	WrapKey(ctx context.Context, key []byte) (wrapped []byte, keyID string, err error)
+
+	// UnwrapKey decrypts a data key previously wrapped with the key of an ID.
+	UnwrapKey(ctx context.Context, wrapped []byte, keyID string) ([]byte, error)
+}
+
+func notImportedAWSKMSFn(c *service.ParsedConfig) (KeyWrapper, error) {
+	return nil, errors.New("unable to configure AWS KMS as this binary does not import components/aws")
+}
+
+// AWSKMSKeyWrapperFromConfigFn is populated with the child `aws` package when
+// imported.
+var AWSKMSKeyWrapperFromConfigFn = notImportedAWSKMSFn
+
+func notImportedGCPKMSFn(c *service.ParsedConfig) (KeyWrapper, error) {
+	return nil, errors.New("unable to configure GCP KMS as this binary does not import components/gcp")
+}
+
+// GCPKMSKeyWrapperFromConfigFn is populated with the child `gcp` package when
+// imported.
+var GCPKMSKeyWrapperFromConfigFn = notImportedGCPKMSFn
+
+//------------------------------------------------------------------------------
+
+// vaultTransitWrapper wraps keys with the transit secrets engine of HashiCorp
+// Vault.
+type vaultTransitWrapper struct {
+	address string
+	token   string
+	mount   string
+	keyName string
+	client  *http.Client
+}
+
+func vaultTransitWrapperFromConfig(conf *service.ParsedConfig) (*vaultTransitWrapper, error) {
+	v := &vaultTransitWrapper{client: &http.Client{}}
+
+	var err error
+	if v.address, err = conf.FieldString("address"); err != nil {
+		return nil, err
+	}
+	v.address = strings.TrimSuffix(v.address, "/")
+	if v.token, err = conf.FieldString("token"); err != nil {
+		return nil, err
+	}
+	if v.mount, err = conf.FieldString("mount"); err != nil {
+		return nil, err
+	}
+	v.mount = strings.Trim(v.mount, "/")
+	if v.keyName, err = conf.FieldString("key_name"); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (v *vaultTransitWrapper) call(ctx context.Context, op, keyName string, body map[string]any) (map[string]any, error) {
+	reqBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Key IDs are read from messages when decrypting and must therefore be
+	// prevented from escaping the path of the key.
+	if keyName == "." || keyName == ".." {
+		return nil, fmt.Errorf("invalid vault key name: %v", keyName)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%v/v1/%v/%v/%v", v.address, v.mount, op, url.PathEscape(keyName)), bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", v.token)
+
+	res, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("vault %v request failed with status %v: %s", op, res.StatusCode, bytes.TrimSpace(resBytes))
+	}
+
+	var resBody struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(resBytes, &resBody); err != nil {
+		return nil, fmt.Errorf("failed to parse vault %v response: %w", op, err)
+	}
+	return resBody.Data, nil
+}
+
+func (v *vaultTransitWrapper) WrapKey(ctx context.Context, key []byte) ([]byte, string, error) {
+	if v.keyName == "" {
+		return nil, "", errors.New("a key_name must be set in order to encrypt")
+	}
+	data, err := v.call(ctx, "encrypt", v.keyName, map[string]any{
+		"plaintext": base64.StdEncoding.EncodeToString(key),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	ciphertext, _ := data["ciphertext"].(string)
+	if ciphertext == "" {
+		return nil, "", errors.New("vault encrypt response is missing a ciphertext")
+	}
+	return []byte(ciphertext), v.keyName, nil
+}
+
+func (v *vaultTransitWrapper) UnwrapKey(ctx context.Context, wrapped []byte, keyID string) ([]byte, error) {
+	if keyID == "" {
+		keyID = v.keyName
+	}
+	data, err := v.call(ctx, "decrypt", keyID, map[string]any{
+		"ciphertext": string(wrapped),
+	})
+	if err != nil {
+		return nil, err
+	}
+	plaintext, _ := data["plaintext"].(string)
+	return base64.StdEncoding.DecodeString(plaintext)
+}
@@ -0,0 +1,66 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+
+	"google.golang.org/api/cloudkms/v1"
+
+	"github.com/benthosdev/benthos/v4/internal/impl/crypto"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func init() {
+	crypto.GCPKMSKeyWrapperFromConfigFn = func(c *service.ParsedConfig) (crypto.KeyWrapper, error) {
+		keyName, err := c.FieldString("key_name")
+		if err != nil {
+			return nil, err
+		}
+
+		svc, err := cloudkms.NewService(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return &kmsKeyWrapper{keys: svc.Projects.Locations.KeyRings.CryptoKeys, keyName: keyName}, nil
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type kmsKeyWrapper struct {
+	keys    *cloudkms.ProjectsLocationsKeyRingsCryptoKeysService
+	keyName string
+}
+
+func (k *kmsKeyWrapper) WrapKey(ctx context.Context, key []byte) ([]byte, string, error) {
+	if k.keyName == "" {
+		return nil, "", errors.New("a key_name must be set in order to encrypt")
+	}
+	res, err := k.keys.Encrypt(k.keyName, &cloudkms.EncryptRequest{
+		Plaintext: base64.StdEncoding.EncodeToString(key),
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, "", err
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(res.Ciphertext)
+	if err != nil {
+		return nil, "", err
+	}
+	// Encrypt responds with the name of the key version used, whereas decrypt
+	// requires the name of the key itself.
+	return wrapped, k.keyName, nil
+}
+
+func (k *kmsKeyWrapper) UnwrapKey(ctx context.Context, wrapped []byte, keyID string) ([]byte, error) {
+	if keyID == "" {
+		keyID = k.keyName
+	}
+	res, err := k.keys.Decrypt(keyID, &cloudkms.DecryptRequest{
+		Ciphertext: base64.StdEncoding.EncodeToString(wrapped),
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(res.Plaintext)
+}
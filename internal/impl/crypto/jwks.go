@@ -0,0 +1,209 @@
+package crypto
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC and OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %v", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve: %v", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode public key: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type: %v", k.Kty)
+}
+
+type parsedJWK struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+// parseJWKSet parses the keys of a JWK set that are usable for verifying
+// signatures, skipping keys of unsupported types.
+func parseJWKSet(b []byte) ([]parsedJWK, error) {
+	var set jwkSet
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWK set: %w", err)
+	}
+
+	var keys []parsedJWK
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys = append(keys, parsedJWK{kid: k.Kid, alg: k.Alg, key: pub})
+	}
+	return keys, nil
+}
+
+//------------------------------------------------------------------------------
+
+// The minimum period between fetches of a JWK set that are triggered by
+// signatures with unknown key IDs.
+const jwksMinRefetchInterval = time.Minute
+
+// jwksCache holds the keys of a JWK set, which is either static or fetched
+// from a URL and refreshed periodically.
+type jwksCache struct {
+	url           string
+	refreshPeriod time.Duration
+	client        *http.Client
+
+	mut       sync.Mutex
+	keys      []parsedJWK
+	fetchedAt time.Time
+}
+
+func (c *jwksCache) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, http.NoBody)
+	if err != nil {
+		return err
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWK set: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("failed to fetch JWK set: status %v", res.StatusCode)
+	}
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKSet(b)
+	if err != nil {
+		return err
+	}
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	return nil
+}
+
+// find returns the keys that could verify a signature of an algorithm and key
+// ID, fetching the set when it is stale or when the key ID is unknown.
+func (c *jwksCache) find(ctx context.Context, alg, kid string) ([]crypto.PublicKey, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.url != "" && time.Since(c.fetchedAt) > c.refreshPeriod {
+		if err := c.fetch(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := c.match(alg, kid)
+	if len(keys) == 0 && kid != "" && c.url != "" && time.Since(c.fetchedAt) > jwksMinRefetchInterval {
+		if err := c.fetch(ctx); err != nil {
+			return nil, err
+		}
+		keys = c.match(alg, kid)
+	}
+	return keys, nil
+}
+
+func (c *jwksCache) match(alg, kid string) []crypto.PublicKey {
+	var keys []crypto.PublicKey
+	for _, k := range c.keys {
+		if kid != "" && k.kid != kid {
+			continue
+		}
+		if k.alg != "" && k.alg != alg {
+			continue
+		}
+		if !keyMatchesAlgorithm(alg, k.key) {
+			continue
+		}
+		keys = append(keys, k.key)
+	}
+	return keys
+}
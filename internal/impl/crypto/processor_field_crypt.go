@@ -0,0 +1,386 @@
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+
+	"github.com/benthosdev/benthos/v4/internal/impl/aws/config"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	fcpFieldOperator     = "operator"
+	fcpFieldFields       = "fields"
+	fcpFieldKeyProvider  = "key_provider"
+	fcpFieldAWSKMS       = "aws_kms"
+	fcpFieldGCPKMS       = "gcp_kms"
+	fcpFieldVaultTransit = "vault_transit"
+	fcpFieldDataKeyTTL   = "data_key_ttl"
+
+	fieldCryptAlgorithm = "AES-256-GCM"
+)
+
+func fieldCryptProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Encrypts or decrypts fields of structured messages with envelope encryption, where data keys are wrapped by a key management service such as AWS KMS, GCP KMS or the transit secrets engine of HashiCorp Vault.").
+		Description(`
+When encrypting, the value of each field is serialised as JSON and encrypted with AES-256-GCM using a data key that is generated by this processor, and the field is replaced with an object containing the ciphertext along with the data key wrapped (encrypted) by the `+"`key_provider`"+`:
+
+`+"```json"+`
+{
+  "alg": "AES-256-GCM",
+  "provider": "aws_kms",
+  "key_id": "arn:aws:kms:eu-west-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab",
+  "wrapped_key": "AQIDAHh...",
+  "ciphertext": "x7F2b..."
+}
+`+"```"+`
+
+The path of each field is bound to its ciphertext as additional authenticated data, and therefore encrypted values cannot be moved to other fields.
+
+Since the key metadata is stored alongside the ciphertext, any Benthos instance with access to the key provider can decrypt fields without further configuration of keys, and the original value of each field, including its type, is restored. Fields that do not exist within a message are skipped.
+
+### Data Keys
+
+A data key is generated and wrapped once per `+"`data_key_ttl`"+` rather than for each message in order to limit the number of requests made to the key provider. When decrypting, unwrapped data keys are cached in memory for the same reason.
+
+### Key Providers
+
+The `+"`aws_kms`"+` provider requires the AWS components, and the `+"`gcp_kms`"+` provider requires the GCP components, both of which are included in standard builds of Benthos. The GCP provider uses [application default credentials](https://cloud.google.com/docs/authentication/application-default-credentials).`).
+		Fields(
+			service.NewStringEnumField(fcpFieldOperator, "encrypt", "decrypt").
+				Description("Whether to encrypt or decrypt the fields."),
+			service.NewStringListField(fcpFieldFields).
+				Description("A list of dot separated paths of fields to encrypt or decrypt.").
+				Example([]any{"customer.ssn", "customer.card_number"}),
+			service.NewStringEnumField(fcpFieldKeyProvider, "aws_kms", "gcp_kms", "vault_transit").
+				Description("The key management service used to wrap and unwrap data keys."),
+			service.NewObjectField(fcpFieldAWSKMS,
+				append([]*service.ConfigField{
+					service.NewStringField("key_id").
+						Description("The ID, ARN or alias of the KMS key to wrap data keys with, which is only required for encryption.").
+						Example("alias/benthos").
+						Default(""),
+				}, config.SessionFields()...)...,
+			).
+				Description("Configuration for the `aws_kms` key provider.").
+				Advanced(),
+			service.NewObjectField(fcpFieldGCPKMS,
+				service.NewStringField("key_name").
+					Description("The resource name of the Cloud KMS key to wrap data keys with, which is only required for encryption.").
+					Example("projects/my-project/locations/global/keyRings/my-ring/cryptoKeys/my-key").
+					Default(""),
+			).
+				Description("Configuration for the `gcp_kms` key provider.").
+				Advanced(),
+			service.NewObjectField(fcpFieldVaultTransit,
+				service.NewURLField("address").
+					Description("The address of the Vault server.").
+					Default("http://127.0.0.1:8200"),
+				service.NewStringField("token").
+					Description("A Vault token with access to the transit key.").
+					Default("").
+					Secret(),
+				service.NewStringField("mount").
+					Description("The mount path of the transit secrets engine.").
+					Default("transit"),
+				service.NewStringField("key_name").
+					Description("The name of the transit key to wrap data keys with, which is only required for encryption.").
+					Default(""),
+			).
+				Description("Configuration for the `vault_transit` key provider.").
+				Advanced(),
+			service.NewDurationField(fcpFieldDataKeyTTL).
+				Description("The period after which a new data key is generated when encrypting.").
+				Default("1h").
+				Advanced(),
+		).
+		Example(
+			"Encrypt Customer Details",
+			"Here we encrypt the social security and card numbers of customers with data keys wrapped by AWS KMS before writing events to Kafka.",
+			`
+pipeline:
+  processors:
+    - field_crypt:
+        operator: encrypt
+        fields: [ customer.ssn, customer.card_number ]
+        key_provider: aws_kms
+        aws_kms:
+          key_id: alias/customer-data
+          region: eu-west-1
+`,
+		).
+		Example(
+			"Decrypt with Vault",
+			"Here we decrypt a field that was encrypted with data keys wrapped by a Vault transit key, where the name of the key is read from the encrypted field.",
+			`
+pipeline:
+  processors:
+    - field_crypt:
+        operator: decrypt
+        fields: [ customer.ssn ]
+        key_provider: vault_transit
+        vault_transit:
+          address: https://vault.example.com:8200
+          token: ${VAULT_TOKEN}
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"field_crypt", fieldCryptProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			return newFieldCryptProcessorFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type dataKey struct {
+	plaintext []byte
+	wrapped   string
+	keyID     string
+	created   time.Time
+}
+
+type fieldCryptProcessor struct {
+	encrypt  bool
+	fields   []string
+	provider string
+	wrapper  KeyWrapper
+	keyTTL   time.Duration
+
+	keyMut     sync.Mutex
+	currentKey *dataKey
+	unwrapped  map[string][]byte
+
+	log *service.Logger
+}
+
+// The maximum number of unwrapped data keys to cache when decrypting.
+const fieldCryptMaxCachedKeys = 1024
+
+func newFieldCryptProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*fieldCryptProcessor, error) {
+	p := &fieldCryptProcessor{
+		unwrapped: map[string][]byte{},
+		log:       mgr.Logger(),
+	}
+
+	operator, err := conf.FieldString(fcpFieldOperator)
+	if err != nil {
+		return nil, err
+	}
+	p.encrypt = operator == "encrypt"
+
+	if p.fields, err = conf.FieldStringList(fcpFieldFields); err != nil {
+		return nil, err
+	}
+	if p.keyTTL, err = conf.FieldDuration(fcpFieldDataKeyTTL); err != nil {
+		return nil, err
+	}
+
+	if p.provider, err = conf.FieldString(fcpFieldKeyProvider); err != nil {
+		return nil, err
+	}
+	switch p.provider {
+	case "aws_kms":
+		p.wrapper, err = AWSKMSKeyWrapperFromConfigFn(conf.Namespace(fcpFieldAWSKMS))
+	case "gcp_kms":
+		p.wrapper, err = GCPKMSKeyWrapperFromConfigFn(conf.Namespace(fcpFieldGCPKMS))
+	case "vault_transit":
+		p.wrapper, err = vaultTransitWrapperFromConfig(conf.Namespace(fcpFieldVaultTransit))
+	default:
+		err = fmt.Errorf("unknown key provider: %v", p.provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *fieldCryptProcessor) dataKey(ctx context.Context) (*dataKey, error) {
+	p.keyMut.Lock()
+	defer p.keyMut.Unlock()
+
+	if p.currentKey != nil && time.Since(p.currentKey.created) < p.keyTTL {
+		return p.currentKey, nil
+	}
+
+	plaintext := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, plaintext); err != nil {
+		return nil, err
+	}
+	wrapped, keyID, err := p.wrapper.WrapKey(ctx, plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	p.currentKey = &dataKey{
+		plaintext: plaintext,
+		wrapped:   base64.StdEncoding.EncodeToString(wrapped),
+		keyID:     keyID,
+		created:   time.Now(),
+	}
+	return p.currentKey, nil
+}
+
+func (p *fieldCryptProcessor) unwrapKey(ctx context.Context, wrapped, keyID string) ([]byte, error) {
+	p.keyMut.Lock()
+	defer p.keyMut.Unlock()
+
+	if key, exists := p.unwrapped[wrapped]; exists {
+		return key, nil
+	}
+
+	wrappedBytes, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode wrapped key: %w", err)
+	}
+	key, err := p.wrapper.UnwrapKey(ctx, wrappedBytes, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	if len(p.unwrapped) >= fieldCryptMaxCachedKeys {
+		p.unwrapped = map[string][]byte{}
+	}
+	p.unwrapped[wrapped] = key
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (p *fieldCryptProcessor) encryptValue(ctx context.Context, path string, v any) (any, error) {
+	key, err := p.dataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key.plaintext)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, []byte(path))
+
+	return map[string]any{
+		"alg":         fieldCryptAlgorithm,
+		"provider":    p.provider,
+		"key_id":      key.keyID,
+		"wrapped_key": key.wrapped,
+		"ciphertext":  base64.StdEncoding.EncodeToString(ciphertext),
+	}, nil
+}
+
+func (p *fieldCryptProcessor) decryptValue(ctx context.Context, path string, v any) (any, error) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an encrypted object, got %T", v)
+	}
+
+	fieldStr := func(k string) string {
+		s, _ := obj[k].(string)
+		return s
+	}
+	if alg := fieldStr("alg"); alg != fieldCryptAlgorithm {
+		return nil, fmt.Errorf("unsupported encryption algorithm: %v", alg)
+	}
+	if provider := fieldStr("provider"); provider != p.provider {
+		return nil, fmt.Errorf("value was encrypted with key provider %v, expected %v", provider, p.provider)
+	}
+
+	key, err := p.unwrapKey(ctx, fieldStr("wrapped_key"), fieldStr("key_id"))
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(fieldStr("ciphertext"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ciphertext: %w", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	plaintext, err := gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], []byte(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+
+	var res any
+	if err := json.Unmarshal(plaintext, &res); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted value: %w", err)
+	}
+	return res, nil
+}
+
+func (p *fieldCryptProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	v, err := msg.AsStructuredMut()
+	if err != nil {
+		return nil, err
+	}
+
+	gObj := gabs.Wrap(v)
+	for _, path := range p.fields {
+		if !gObj.ExistsP(path) {
+			continue
+		}
+
+		var res any
+		if p.encrypt {
+			res, err = p.encryptValue(ctx, path, gObj.Path(path).Data())
+		} else {
+			res, err = p.decryptValue(ctx, path, gObj.Path(path).Data())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("field %v: %w", path, err)
+		}
+
+		if _, err := gObj.SetP(res, path); err != nil {
+			return nil, fmt.Errorf("field %v: %w", path, err)
+		}
+	}
+
+	msg.SetStructuredMut(gObj.Data())
+	return service.MessageBatch{msg}, nil
+}
+
+func (p *fieldCryptProcessor) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,230 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+// testVaultServer emulates the transit secrets engine of Vault by "wrapping"
+// keys with a reversible prefix.
+func testVaultServer(t testing.TB) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		assert.Equal(t, "footoken", r.Header.Get("X-Vault-Token"))
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		var data map[string]any
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/fookey":
+			data = map[string]any{"ciphertext": "vault:v1:" + body["plaintext"]}
+		case "/v1/transit/decrypt/fookey":
+			data = map[string]any{"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:")}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":["no handler for route"]}`))
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"data": data}))
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &calls
+}
+
+func TestFieldCryptVaultRoundTrip(t *testing.T) {
+	ts, calls := testVaultServer(t)
+
+	encConf, err := fieldCryptProcessorConfig().ParseYAML(`
+operator: encrypt
+fields: [ customer.ssn, customer.cards, customer.missing ]
+key_provider: vault_transit
+vault_transit:
+  address: `+ts.URL+`
+  token: footoken
+  key_name: fookey
+`, nil)
+	require.NoError(t, err)
+
+	encProc, err := newFieldCryptProcessorFromConfig(encConf, service.MockResources())
+	require.NoError(t, err)
+
+	decConf, err := fieldCryptProcessorConfig().ParseYAML(`
+operator: decrypt
+fields: [ customer.ssn, customer.cards, customer.missing ]
+key_provider: vault_transit
+vault_transit:
+  address: `+ts.URL+`
+  token: footoken
+`, nil)
+	require.NoError(t, err)
+
+	decProc, err := newFieldCryptProcessorFromConfig(decConf, service.MockResources())
+	require.NoError(t, err)
+
+	input := `{"customer":{"name":"foo","ssn":"123-45-6789","cards":[{"number":"4111111111111111"}]}}`
+
+	var encrypted []*service.Message
+	for i := 0; i < 3; i++ {
+		batch, err := encProc.Process(context.Background(), service.NewMessage([]byte(input)))
+		require.NoError(t, err)
+		require.Len(t, batch, 1)
+		encrypted = append(encrypted, batch[0])
+	}
+
+	// A single data key is wrapped for all messages.
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	v, err := encrypted[0].AsStructured()
+	require.NoError(t, err)
+
+	customer := v.(map[string]any)["customer"].(map[string]any)
+	assert.Equal(t, "foo", customer["name"])
+	assert.NotContains(t, customer, "missing")
+
+	ssn, ok := customer["ssn"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "AES-256-GCM", ssn["alg"])
+	assert.Equal(t, "vault_transit", ssn["provider"])
+	assert.Equal(t, "fookey", ssn["key_id"])
+	assert.NotContains(t, ssn["ciphertext"], "123-45-6789")
+
+	wrapped, err := base64.StdEncoding.DecodeString(ssn["wrapped_key"].(string))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(wrapped), "vault:v1:"))
+
+	for _, msg := range encrypted {
+		batch, err := decProc.Process(context.Background(), msg)
+		require.NoError(t, err)
+		require.Len(t, batch, 1)
+
+		mBytes, err := batch[0].AsBytes()
+		require.NoError(t, err)
+		assert.JSONEq(t, input, string(mBytes))
+	}
+
+	// A single data key is unwrapped for all messages.
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+func TestFieldCryptTampering(t *testing.T) {
+	ts, _ := testVaultServer(t)
+
+	encConf, err := fieldCryptProcessorConfig().ParseYAML(`
+operator: encrypt
+fields: [ a, b ]
+key_provider: vault_transit
+vault_transit:
+  address: `+ts.URL+`
+  token: footoken
+  key_name: fookey
+`, nil)
+	require.NoError(t, err)
+
+	encProc, err := newFieldCryptProcessorFromConfig(encConf, service.MockResources())
+	require.NoError(t, err)
+
+	decConf, err := fieldCryptProcessorConfig().ParseYAML(`
+operator: decrypt
+fields: [ a, b ]
+key_provider: vault_transit
+vault_transit:
+  address: `+ts.URL+`
+  token: footoken
+`, nil)
+	require.NoError(t, err)
+
+	decProc, err := newFieldCryptProcessorFromConfig(decConf, service.MockResources())
+	require.NoError(t, err)
+
+	batch, err := encProc.Process(context.Background(), service.NewMessage([]byte(`{"a":"foo","b":"bar"}`)))
+	require.NoError(t, err)
+
+	v, err := batch[0].AsStructuredMut()
+	require.NoError(t, err)
+
+	// Swapping encrypted values between fields must fail authentication.
+	obj := v.(map[string]any)
+	obj["a"], obj["b"] = obj["b"], obj["a"]
+	batch[0].SetStructuredMut(obj)
+
+	_, err = decProc.Process(context.Background(), batch[0])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decrypt")
+
+	_, err = decProc.Process(context.Background(), service.NewMessage([]byte(`{"a":"not encrypted"}`)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected an encrypted object")
+}
+
+func TestFieldCryptVaultKeyIDEscaped(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(ts.Close)
+
+	decConf, err := fieldCryptProcessorConfig().ParseYAML(`
+operator: decrypt
+fields: [ a ]
+key_provider: vault_transit
+vault_transit:
+  address: `+ts.URL+`
+  token: footoken
+`, nil)
+	require.NoError(t, err)
+
+	decProc, err := newFieldCryptProcessorFromConfig(decConf, service.MockResources())
+	require.NoError(t, err)
+
+	for _, keyID := range []string{"../../sys/raw/foo", "..", "foo?bar"} {
+		input, err := json.Marshal(map[string]any{
+			"a": map[string]any{
+				"alg":         "AES-256-GCM",
+				"provider":    "vault_transit",
+				"key_id":      keyID,
+				"wrapped_key": base64.StdEncoding.EncodeToString([]byte("vault:v1:foo")),
+				"nonce":       "",
+				"ciphertext":  "",
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = decProc.Process(context.Background(), service.NewMessage(input))
+		require.Error(t, err, keyID)
+	}
+
+	// Key IDs are confined to a single path segment of the decrypt endpoint.
+	assert.Equal(t, []string{
+		"/v1/transit/decrypt/..%2F..%2Fsys%2Fraw%2Ffoo",
+		"/v1/transit/decrypt/foo%3Fbar",
+	}, paths)
+}
+
+func TestFieldCryptProviderNotImported(t *testing.T) {
+	conf, err := fieldCryptProcessorConfig().ParseYAML(`
+operator: encrypt
+fields: [ a ]
+key_provider: gcp_kms
+`, nil)
+	require.NoError(t, err)
+
+	_, err = newFieldCryptProcessorFromConfig(conf, service.MockResources())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "components/gcp")
+}
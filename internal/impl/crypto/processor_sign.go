@@ -0,0 +1,171 @@
+package crypto
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	spFieldFormat         = "format"
+	spFieldAlgorithm      = "algorithm"
+	spFieldPrivateKey     = "private_key"
+	spFieldPrivateKeyFile = "private_key_file"
+	spFieldKeyID          = "key_id"
+	spFieldMetadataKey    = "metadata_key"
+)
+
+func signProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Signs the raw contents of messages with a private key, writing a detached JWS or COSE signature to a metadata field.").
+		Description(`
+The contents of messages are left unchanged, and the signature is written to the metadata key `+"`metadata_key`"+` so that it can be forwarded alongside the message, for example as a header or a Kafka record header. Signatures can be checked downstream with the `+"[`verify` processor](/docs/components/processors/verify)"+`, or any other implementation of the formats.
+
+### Formats
+
+The `+"`jws`"+` format produces a [JWS with a detached payload](https://www.rfc-editor.org/rfc/rfc7515#appendix-F) in compact serialisation, which is of the form `+"`<header>..<signature>`"+`. The `+"`cose`"+` format produces a [COSE_Sign1](https://www.rfc-editor.org/rfc/rfc9052#section-4.2) message with a detached payload, which is base64 encoded before being written to metadata.
+
+In both formats the algorithm is included in the protected header, and the `+"`key_id`"+`, when set, is included as the key ID (`+"`kid`"+`) header so that verifiers can select the correct key from a key set.`).
+		Fields(
+			service.NewStringEnumField(spFieldFormat, "jws", "cose").
+				Description("The format of the signature.").
+				Default("jws"),
+			service.NewStringEnumField(spFieldAlgorithm, signatureAlgorithmNames()...).
+				Description("The algorithm to sign messages with, which must match the type of the private key."),
+			service.NewStringField(spFieldPrivateKey).
+				Description("A PEM encoded private key to sign messages with. Either this field or `private_key_file` must be set.").
+				Default("").
+				Secret(),
+			service.NewStringField(spFieldPrivateKeyFile).
+				Description("The path of a file containing a PEM encoded private key to sign messages with.").
+				Default(""),
+			service.NewStringField(spFieldKeyID).
+				Description("An optional key ID to include in the signature header.").
+				Default(""),
+			service.NewStringField(spFieldMetadataKey).
+				Description("The metadata key to write the signature to.").
+				Default("signature"),
+		).
+		LintRule(`root = match {
+  this.private_key.or("") == "" && this.private_key_file.or("") == "" => [ "either a private_key or a private_key_file must be set" ]
+  this.private_key.or("") != "" && this.private_key_file.or("") != "" => [ "a private_key and a private_key_file cannot both be set" ]
+}`).
+		Example(
+			"Sign Events",
+			"Here we sign events with an ECDSA key before publishing them to Kafka, where the signature is added as a record header.",
+			`
+pipeline:
+  processors:
+    - sign:
+        algorithm: ES256
+        private_key_file: ./keys/signing.pem
+        key_id: events-2024
+
+output:
+  kafka_franz:
+    seed_brokers: [ localhost:9092 ]
+    topic: events
+    metadata:
+      include_patterns: [ signature ]
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"sign", signProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			return newSignProcessorFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type signProcessor struct {
+	cose        bool
+	alg         string
+	key         crypto.PrivateKey
+	kid         string
+	metadataKey string
+}
+
+func newSignProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*signProcessor, error) {
+	p := &signProcessor{}
+
+	format, err := conf.FieldString(spFieldFormat)
+	if err != nil {
+		return nil, err
+	}
+	p.cose = format == "cose"
+
+	if p.alg, err = conf.FieldString(spFieldAlgorithm); err != nil {
+		return nil, err
+	}
+	if _, exists := signatureAlgorithms[p.alg]; !exists {
+		return nil, fmt.Errorf("unsupported algorithm: %v", p.alg)
+	}
+	if p.kid, err = conf.FieldString(spFieldKeyID); err != nil {
+		return nil, err
+	}
+	if p.metadataKey, err = conf.FieldString(spFieldMetadataKey); err != nil {
+		return nil, err
+	}
+
+	keyStr, err := conf.FieldString(spFieldPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	keyFile, err := conf.FieldString(spFieldPrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes := []byte(keyStr)
+	if keyFile != "" {
+		if keyBytes, err = ifs.ReadFile(mgr.FS(), keyFile); err != nil {
+			return nil, fmt.Errorf("failed to read private key file: %w", err)
+		}
+	}
+	if len(keyBytes) == 0 {
+		return nil, errors.New("either a private_key or a private_key_file must be set")
+	}
+	if p.key, err = parsePrivateKeyPEM(p.alg, keyBytes); err != nil {
+		return nil, fmt.Errorf("failed to parse private key for algorithm %v: %w", p.alg, err)
+	}
+	return p, nil
+}
+
+func (p *signProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	payload, err := msg.AsBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.cose {
+		sig, err := signCOSE(p.alg, p.kid, p.key, payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign message: %w", err)
+		}
+		msg.MetaSetMut(p.metadataKey, base64.StdEncoding.EncodeToString(sig))
+	} else {
+		sig, err := signJWS(p.alg, p.kid, p.key, payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign message: %w", err)
+		}
+		msg.MetaSetMut(p.metadataKey, sig)
+	}
+	return service.MessageBatch{msg}, nil
+}
+
+func (p *signProcessor) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,242 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	vpFieldFormat        = "format"
+	vpFieldJWKSURL       = "jwks_url"
+	vpFieldJWKS          = "jwks"
+	vpFieldRefreshPeriod = "refresh_period"
+	vpFieldAlgorithms    = "algorithms"
+	vpFieldMetadataKey   = "metadata_key"
+	vpFieldOnFailure     = "on_failure"
+)
+
+func verifyProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Verifies detached JWS or COSE signatures of messages, stored within a metadata field, against the public keys of a JSON Web Key Set (JWKS).").
+		Description(`
+The raw contents of each message are verified against the signature stored in the metadata key `+"`metadata_key`"+`, such as those produced by the `+"[`sign` processor](/docs/components/processors/sign)"+`. Signatures are checked with the keys of a JWK set that matches the key ID (`+"`kid`"+`) and algorithm of the signature, where signatures without a key ID are checked against all keys of a suitable type.
+
+The JWK set is either fetched from `+"`jwks_url`"+`, in which case it is refreshed every `+"`refresh_period`"+` as well as when a signature has a key ID that is not in the set (at most once a minute), or provided inline with `+"`jwks`"+`.
+
+### Failures
+
+When `+"`on_failure`"+` is `+"`reject`"+` messages that fail verification, including messages without a signature, are flagged as having failed processing, and can be handled with [error handling patterns](/docs/configuration/error_handling). When `+"`on_failure`"+` is `+"`flag`"+` messages are left unchanged, and instead the metadata field `+"`signature_valid`"+` is set to a boolean indicating whether the signature was verified, with the reason for a failure written to the metadata field `+"`signature_error`"+`.`).
+		Fields(
+			service.NewStringEnumField(vpFieldFormat, "jws", "cose").
+				Description("The format of the signatures.").
+				Default("jws"),
+			service.NewURLField(vpFieldJWKSURL).
+				Description("A URL to fetch a JWK set from.").
+				Example("https://example.com/.well-known/jwks.json").
+				Default(""),
+			service.NewStringField(vpFieldJWKS).
+				Description("An inline JWK set, which can be used instead of `jwks_url`.").
+				Default("").
+				Advanced(),
+			service.NewDurationField(vpFieldRefreshPeriod).
+				Description("The period after which a JWK set fetched from `jwks_url` is refreshed.").
+				Default("1h").
+				Advanced(),
+			service.NewStringListField(vpFieldAlgorithms).
+				Description("An optional list of algorithms to accept, signatures of other algorithms fail verification. When empty all supported algorithms are accepted.").
+				Example([]any{"ES256", "EdDSA"}).
+				Default([]any{}),
+			service.NewStringField(vpFieldMetadataKey).
+				Description("The metadata key to read the signature from.").
+				Default("signature"),
+			service.NewStringEnumField(vpFieldOnFailure, "reject", "flag").
+				Description("Whether messages that fail verification are rejected by flagging them as having failed processing, or flagged with metadata.").
+				Default("reject"),
+		).
+		LintRule(`root = match {
+  this.jwks_url.or("") == "" && this.jwks.or("") == "" => [ "either a jwks_url or a jwks must be set" ]
+  this.jwks_url.or("") != "" && this.jwks.or("") != "" => [ "a jwks_url and a jwks cannot both be set" ]
+}`).
+		Example(
+			"Verify Events",
+			"Here we verify events consumed from Kafka against the keys published by their producer, dropping any that fail verification.",
+			`
+input:
+  kafka:
+    addresses: [ localhost:9092 ]
+    topics: [ events ]
+    consumer_group: benthos
+
+pipeline:
+  processors:
+    - verify:
+        jwks_url: https://keys.example.com/.well-known/jwks.json
+        algorithms: [ ES256 ]
+    - catch:
+        - log:
+            level: WARN
+            message: 'Dropping event: ${! error() }'
+        - mapping: root = deleted()
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"verify", verifyProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			return newVerifyProcessorFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type verifyProcessor struct {
+	cose        bool
+	keys        *jwksCache
+	algorithms  map[string]struct{}
+	metadataKey string
+	flag        bool
+}
+
+func newVerifyProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*verifyProcessor, error) {
+	p := &verifyProcessor{
+		keys: &jwksCache{client: &http.Client{Timeout: 30 * time.Second}},
+	}
+
+	format, err := conf.FieldString(vpFieldFormat)
+	if err != nil {
+		return nil, err
+	}
+	p.cose = format == "cose"
+
+	if p.keys.url, err = conf.FieldString(vpFieldJWKSURL); err != nil {
+		return nil, err
+	}
+	if p.keys.refreshPeriod, err = conf.FieldDuration(vpFieldRefreshPeriod); err != nil {
+		return nil, err
+	}
+
+	jwksStr, err := conf.FieldString(vpFieldJWKS)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.keys.url != "" && jwksStr != "":
+		return nil, errors.New("a jwks_url and a jwks cannot both be set")
+	case jwksStr != "":
+		if p.keys.keys, err = parseJWKSet([]byte(jwksStr)); err != nil {
+			return nil, err
+		}
+	case p.keys.url == "":
+		return nil, errors.New("either a jwks_url or a jwks must be set")
+	}
+
+	algorithms, err := conf.FieldStringList(vpFieldAlgorithms)
+	if err != nil {
+		return nil, err
+	}
+	if len(algorithms) > 0 {
+		p.algorithms = map[string]struct{}{}
+		for _, alg := range algorithms {
+			if _, exists := signatureAlgorithms[alg]; !exists {
+				return nil, fmt.Errorf("unsupported algorithm: %v", alg)
+			}
+			p.algorithms[alg] = struct{}{}
+		}
+	}
+
+	if p.metadataKey, err = conf.FieldString(vpFieldMetadataKey); err != nil {
+		return nil, err
+	}
+
+	onFailure, err := conf.FieldString(vpFieldOnFailure)
+	if err != nil {
+		return nil, err
+	}
+	p.flag = onFailure == "flag"
+	return p, nil
+}
+
+func (p *verifyProcessor) verify(ctx context.Context, msg *service.Message) error {
+	sigStr, exists := msg.MetaGet(p.metadataKey)
+	if !exists || sigStr == "" {
+		return fmt.Errorf("metadata key %v does not contain a signature", p.metadataKey)
+	}
+
+	var sig *detachedSignature
+	var err error
+	if p.cose {
+		var sigBytes []byte
+		if sigBytes, err = base64.StdEncoding.DecodeString(sigStr); err != nil {
+			return fmt.Errorf("failed to decode signature: %w", err)
+		}
+		sig, err = parseCOSE(sigBytes)
+	} else {
+		sig, err = parseJWS(sigStr)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, exists := signatureAlgorithms[sig.alg]; !exists {
+		return fmt.Errorf("unsupported algorithm: %v", sig.alg)
+	}
+	if p.algorithms != nil {
+		if _, exists := p.algorithms[sig.alg]; !exists {
+			return fmt.Errorf("algorithm %v is not allowed", sig.alg)
+		}
+	}
+
+	keys, err := p.keys.find(ctx, sig.alg, sig.kid)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		if sig.kid != "" {
+			return fmt.Errorf("no %v key found with ID %v", sig.alg, sig.kid)
+		}
+		return fmt.Errorf("no %v key found", sig.alg)
+	}
+
+	payload, err := msg.AsBytes()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err = sig.verify(key, payload); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature is invalid: %w", err)
+}
+
+func (p *verifyProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	err := p.verify(ctx, msg)
+	if !p.flag {
+		if err != nil {
+			return nil, err
+		}
+		return service.MessageBatch{msg}, nil
+	}
+
+	msg.MetaSetMut("signature_valid", err == nil)
+	if err != nil {
+		msg.MetaSetMut("signature_error", err.Error())
+	}
+	return service.MessageBatch{msg}, nil
+}
+
+func (p *verifyProcessor) Close(ctx context.Context) error {
+	return nil
+}
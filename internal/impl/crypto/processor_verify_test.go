@@ -0,0 +1,228 @@
+package crypto
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+type testSigningKey struct {
+	alg string
+	pem string
+	jwk map[string]any
+}
+
+func b64BigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func testSigningKeys(t testing.TB) []testSigningKey {
+	t.Helper()
+
+	toPEM := func(key any) string {
+		b, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b}))
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return []testSigningKey{
+		{
+			alg: "RS256",
+			pem: toPEM(rsaKey),
+			jwk: map[string]any{
+				"kty": "RSA", "kid": "rsa", "n": b64BigInt(rsaKey.N), "e": b64BigInt(big.NewInt(int64(rsaKey.E))),
+			},
+		},
+		{
+			alg: "ES256",
+			pem: toPEM(ecKey),
+			jwk: map[string]any{
+				"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64BigInt(ecKey.X), "y": b64BigInt(ecKey.Y),
+			},
+		},
+		{
+			alg: "EdDSA",
+			pem: toPEM(edKey),
+			jwk: map[string]any{
+				"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": base64.RawURLEncoding.EncodeToString(edPub),
+			},
+		},
+	}
+}
+
+func testJWKS(t testing.TB, keys []testSigningKey) string {
+	t.Helper()
+
+	var jwks []any
+	for _, k := range keys {
+		jwks = append(jwks, k.jwk)
+	}
+	b, err := json.Marshal(map[string]any{"keys": jwks})
+	require.NoError(t, err)
+	return string(b)
+}
+
+func testSignProc(t testing.TB, format string, key testSigningKey) *signProcessor {
+	t.Helper()
+
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(keyPath, []byte(key.pem), 0o600))
+
+	conf, err := signProcessorConfig().ParseYAML(`
+format: `+format+`
+algorithm: `+key.alg+`
+private_key_file: `+keyPath+`
+key_id: `+key.jwk["kid"].(string)+`
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newSignProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+	return proc
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	keys := testSigningKeys(t)
+
+	var fetches int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		_, _ = w.Write([]byte(testJWKS(t, keys)))
+	}))
+	t.Cleanup(ts.Close)
+
+	for _, format := range []string{"jws", "cose"} {
+		verifyConf, err := verifyProcessorConfig().ParseYAML(`
+format: `+format+`
+jwks_url: `+ts.URL+`
+`, nil)
+		require.NoError(t, err)
+
+		verifyProc, err := newVerifyProcessorFromConfig(verifyConf, service.MockResources())
+		require.NoError(t, err)
+
+		for _, key := range keys {
+			key := key
+			t.Run(format+"/"+key.alg, func(t *testing.T) {
+				signProc := testSignProc(t, format, key)
+
+				batch, err := signProc.Process(context.Background(), service.NewMessage([]byte(`{"id":"foo"}`)))
+				require.NoError(t, err)
+				require.Len(t, batch, 1)
+
+				sig, exists := batch[0].MetaGet("signature")
+				require.True(t, exists)
+				assert.NotEmpty(t, sig)
+
+				mBytes, err := batch[0].AsBytes()
+				require.NoError(t, err)
+				assert.Equal(t, `{"id":"foo"}`, string(mBytes))
+
+				_, err = verifyProc.Process(context.Background(), batch[0])
+				require.NoError(t, err)
+
+				tampered := batch[0].Copy()
+				tampered.SetBytes([]byte(`{"id":"bar"}`))
+				_, err = verifyProc.Process(context.Background(), tampered)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "signature is invalid")
+			})
+		}
+	}
+
+	// The key set is fetched once per processor.
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestVerifyFlag(t *testing.T) {
+	keys := testSigningKeys(t)
+	verifyConf, err := verifyProcessorConfig().ParseYAML(`
+jwks: '`+testJWKS(t, keys[1:2])+`'
+algorithms: [ ES256 ]
+on_failure: flag
+`, nil)
+	require.NoError(t, err)
+
+	verifyProc, err := newVerifyProcessorFromConfig(verifyConf, service.MockResources())
+	require.NoError(t, err)
+
+	signed, err := testSignProc(t, "jws", keys[1]).Process(context.Background(), service.NewMessage([]byte("hello world")))
+	require.NoError(t, err)
+
+	wrongKey, err := testSignProc(t, "jws", keys[2]).Process(context.Background(), service.NewMessage([]byte("hello world")))
+	require.NoError(t, err)
+
+	unknownKID := keys[1]
+	unknownKID.jwk = map[string]any{"kid": "nope"}
+	unknown, err := testSignProc(t, "jws", unknownKID).Process(context.Background(), service.NewMessage([]byte("hello world")))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		msg   *service.Message
+		valid bool
+		err   string
+	}{
+		{name: "valid", msg: signed[0], valid: true},
+		{name: "missing", msg: service.NewMessage([]byte("hello world")), err: "does not contain a signature"},
+		{name: "disallowed algorithm", msg: wrongKey[0], err: "algorithm EdDSA is not allowed"},
+		{name: "unknown key", msg: unknown[0], err: "no ES256 key found with ID nope"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			batch, err := verifyProc.Process(context.Background(), test.msg)
+			require.NoError(t, err)
+			require.Len(t, batch, 1)
+
+			valid, exists := batch[0].MetaGetMut("signature_valid")
+			require.True(t, exists)
+			assert.Equal(t, test.valid, valid)
+
+			errStr, _ := batch[0].MetaGet("signature_error")
+			if test.err == "" {
+				assert.Empty(t, errStr)
+			} else {
+				assert.Contains(t, errStr, test.err)
+			}
+		})
+	}
+}
+
+func TestVerifyConfigErrors(t *testing.T) {
+	conf, err := verifyProcessorConfig().ParseYAML(`format: jws`, nil)
+	require.NoError(t, err)
+
+	_, err = newVerifyProcessorFromConfig(conf, service.MockResources())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "either a jwks_url or a jwks must be set")
+}
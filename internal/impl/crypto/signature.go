@@ -0,0 +1,260 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// signatureAlgorithms are the algorithms supported by the sign and verify
+// processors, along with their COSE identifiers.
+var signatureAlgorithms = map[string]struct {
+	method jwt.SigningMethod
+	coseID int64
+}{
+	"RS256": {jwt.SigningMethodRS256, -257},
+	"RS384": {jwt.SigningMethodRS384, -258},
+	"RS512": {jwt.SigningMethodRS512, -259},
+	"PS256": {jwt.SigningMethodPS256, -37},
+	"PS384": {jwt.SigningMethodPS384, -38},
+	"PS512": {jwt.SigningMethodPS512, -39},
+	"ES256": {jwt.SigningMethodES256, -7},
+	"ES384": {jwt.SigningMethodES384, -35},
+	"ES512": {jwt.SigningMethodES512, -36},
+	"EdDSA": {jwt.SigningMethodEdDSA, -8},
+}
+
+func signatureAlgorithmNames() []string {
+	return []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+}
+
+func coseAlgorithmName(id int64) (string, bool) {
+	for name, alg := range signatureAlgorithms {
+		if alg.coseID == id {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// parsePrivateKeyPEM parses a PEM encoded private key suitable for an
+// algorithm.
+func parsePrivateKeyPEM(alg string, pemBytes []byte) (crypto.PrivateKey, error) {
+	switch alg[:2] {
+	case "RS", "PS":
+		return jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+	case "ES":
+		return jwt.ParseECPrivateKeyFromPEM(pemBytes)
+	case "Ed":
+		return jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+	}
+	return nil, fmt.Errorf("unsupported algorithm: %v", alg)
+}
+
+// keyMatchesAlgorithm returns whether a public key is of a type usable with an
+// algorithm.
+func keyMatchesAlgorithm(alg string, key crypto.PublicKey) bool {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return alg[:2] == "RS" || alg[:2] == "PS"
+	case *ecdsa.PublicKey:
+		return alg[:2] == "ES"
+	case ed25519.PublicKey:
+		return alg == "EdDSA"
+	}
+	return false
+}
+
+//------------------------------------------------------------------------------
+
+// detachedSignature is a parsed detached signature of either format, which
+// provides the bytes that were signed for a given payload.
+type detachedSignature struct {
+	alg          string
+	kid          string
+	signature    []byte
+	signingInput func(payload []byte) []byte
+}
+
+type jwsHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// signJWS creates a JWS with a detached payload in compact serialisation, as
+// described in https://www.rfc-editor.org/rfc/rfc7515#appendix-F.
+func signJWS(alg, kid string, key crypto.PrivateKey, payload []byte) (string, error) {
+	headerBytes, err := json.Marshal(jwsHeader{Alg: alg, Kid: kid})
+	if err != nil {
+		return "", err
+	}
+	header := base64.RawURLEncoding.EncodeToString(headerBytes)
+
+	sig, err := signatureAlgorithms[alg].method.Sign(header+"."+base64.RawURLEncoding.EncodeToString(payload), key)
+	if err != nil {
+		return "", err
+	}
+	return header + ".." + sig, nil
+}
+
+func parseJWS(s string) (*detachedSignature, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("expected a JWS in compact serialisation")
+	}
+	if parts[1] != "" {
+		return nil, errors.New("expected a JWS with a detached payload")
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWS header: %w", err)
+	}
+	var header jwsHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse JWS header: %w", err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWS signature: %w", err)
+	}
+
+	return &detachedSignature{
+		alg:       header.Alg,
+		kid:       header.Kid,
+		signature: sig,
+		signingInput: func(payload []byte) []byte {
+			return []byte(parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload))
+		},
+	}, nil
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	coseHeaderAlg = 1
+	coseHeaderKid = 4
+	coseSign1Tag  = 18
+)
+
+type coseSign1 struct {
+	_           struct{} `cbor:",toarray"`
+	Protected   []byte
+	Unprotected map[int64]any
+	Payload     []byte
+	Signature   []byte
+}
+
+func coseSigStructure(protected, payload []byte) ([]byte, error) {
+	return cbor.Marshal([]any{"Signature1", protected, []byte{}, payload})
+}
+
+// signCOSE creates a COSE_Sign1 message with a detached payload, as described
+// in https://www.rfc-editor.org/rfc/rfc9052#section-4.2.
+func signCOSE(alg, kid string, key crypto.PrivateKey, payload []byte) ([]byte, error) {
+	protected, err := cbor.Marshal(map[int64]any{coseHeaderAlg: signatureAlgorithms[alg].coseID})
+	if err != nil {
+		return nil, err
+	}
+
+	toSign, err := coseSigStructure(protected, payload)
+	if err != nil {
+		return nil, err
+	}
+	sigStr, err := signatureAlgorithms[alg].method.Sign(string(toSign), key)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := jwt.DecodeSegment(sigStr)
+	if err != nil {
+		return nil, err
+	}
+
+	unprotected := map[int64]any{}
+	if kid != "" {
+		unprotected[coseHeaderKid] = []byte(kid)
+	}
+	return cbor.Marshal(cbor.Tag{
+		Number: coseSign1Tag,
+		Content: coseSign1{
+			Protected:   protected,
+			Unprotected: unprotected,
+			Signature:   sig,
+		},
+	})
+}
+
+func parseCOSE(b []byte) (*detachedSignature, error) {
+	var tag cbor.RawTag
+	if err := cbor.Unmarshal(b, &tag); err == nil {
+		if tag.Number != coseSign1Tag {
+			return nil, fmt.Errorf("expected a COSE_Sign1 tag, got %v", tag.Number)
+		}
+		b = tag.Content
+	}
+
+	var msg coseSign1
+	if err := cbor.Unmarshal(b, &msg); err != nil {
+		return nil, fmt.Errorf("failed to parse COSE_Sign1: %w", err)
+	}
+	if msg.Payload != nil {
+		return nil, errors.New("expected a COSE_Sign1 with a detached payload")
+	}
+
+	var protected map[int64]any
+	if len(msg.Protected) > 0 {
+		if err := cbor.Unmarshal(msg.Protected, &protected); err != nil {
+			return nil, fmt.Errorf("failed to parse COSE protected header: %w", err)
+		}
+	}
+
+	var algID int64
+	switch t := protected[coseHeaderAlg].(type) {
+	case int64:
+		algID = t
+	case uint64:
+		algID = int64(t)
+	default:
+		return nil, errors.New("COSE protected header is missing an algorithm")
+	}
+	alg, ok := coseAlgorithmName(algID)
+	if !ok {
+		return nil, fmt.Errorf("unsupported COSE algorithm: %v", algID)
+	}
+
+	var kid string
+	if kidBytes, ok := msg.Unprotected[coseHeaderKid].([]byte); ok {
+		kid = string(kidBytes)
+	} else if kidBytes, ok := protected[coseHeaderKid].([]byte); ok {
+		kid = string(kidBytes)
+	}
+
+	return &detachedSignature{
+		alg:       alg,
+		kid:       kid,
+		signature: msg.Signature,
+		signingInput: func(payload []byte) []byte {
+			toSign, _ := coseSigStructure(msg.Protected, payload)
+			return toSign
+		},
+	}, nil
+}
+
+// verify checks a detached signature of a payload against a public key.
+func (d *detachedSignature) verify(key crypto.PublicKey, payload []byte) error {
+	return signatureAlgorithms[d.alg].method.Verify(
+		string(d.signingInput(payload)),
+		jwt.EncodeSegment(d.signature),
+		key,
+	)
+}
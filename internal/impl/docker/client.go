@@ -0,0 +1,172 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	dcFieldHost = "host"
+	dcFieldTLS  = "tls"
+)
+
+func clientFields() []*service.ConfigField {
+	return []*service.ConfigField{
+		service.NewStringField(dcFieldHost).
+			Description("The address of the Docker Engine API, which can be either a unix socket or a TCP address. Any daemon compatible with the Docker Engine API, such as Podman, can be used.").
+			Default("unix:///var/run/docker.sock").
+			Example("tcp://localhost:2375"),
+		service.NewTLSToggledField(dcFieldTLS).
+			Description("Custom TLS settings for connecting to a TCP address of the API.").
+			Advanced(),
+	}
+}
+
+// apiClient is a minimal client of the Docker Engine API that supports the
+// listing, inspecting and log streaming of containers.
+type apiClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func newAPIClient(conf *service.ParsedConfig) (*apiClient, error) {
+	host, err := conf.FieldString(dcFieldHost)
+	if err != nil {
+		return nil, err
+	}
+	tlsConf, tlsEnabled, err := conf.FieldTLSToggled(dcFieldTLS)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse host: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	c := &apiClient{}
+	switch u.Scheme {
+	case "unix":
+		socketPath := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		}
+		c.baseURL = "http://docker"
+	case "tcp", "http", "https":
+		scheme := "http"
+		if tlsEnabled || u.Scheme == "https" {
+			scheme = "https"
+		}
+		c.baseURL = scheme + "://" + u.Host + strings.TrimSuffix(u.Path, "/")
+	default:
+		return nil, fmt.Errorf("host scheme not supported: %v", u.Scheme)
+	}
+	if tlsEnabled {
+		transport.TLSClientConfig = tlsConf
+	}
+	c.http = &http.Client{Transport: transport}
+	return c, nil
+}
+
+// apiError is returned when the API responds with an unsuccessful status.
+type apiError struct {
+	Code    int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("docker API responded with status %v: %v", e.Code, e.Message)
+}
+
+// get performs a GET request against a path of the API and returns the
+// response when successful, in which case the body must be closed by the
+// caller.
+func (c *apiClient) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &msg); err != nil || msg.Message == "" {
+			msg.Message = string(bytes.TrimSpace(body))
+		}
+		return nil, &apiError{Code: res.StatusCode, Message: msg.Message}
+	}
+	return res, nil
+}
+
+// getJSON performs a GET request and decodes the response body into v.
+func (c *apiClient) getJSON(ctx context.Context, path string, query url.Values, v any) error {
+	res, err := c.get(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// labelFilters returns the encoded filters query parameter for a list of
+// label filters and any additional filters.
+func labelFilters(labels []string, extra map[string][]string) string {
+	filters := map[string][]string{}
+	for k, v := range extra {
+		filters[k] = v
+	}
+	if len(labels) > 0 {
+		filters["label"] = labels
+	}
+	b, _ := json.Marshal(filters)
+	return string(b)
+}
+
+// container is the subset of an inspected container used by the input.
+type container struct {
+	ID     string `json:"Id"`
+	Name   string `json:"Name"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+		Tty    bool              `json:"Tty"`
+	} `json:"Config"`
+	State struct {
+		Running bool `json:"Running"`
+	} `json:"State"`
+}
+
+func (c *apiClient) inspect(ctx context.Context, id string) (*container, error) {
+	var cont container
+	if err := c.getJSON(ctx, "/containers/"+url.PathEscape(id)+"/json", nil, &cont); err != nil {
+		return nil, err
+	}
+	cont.Name = strings.TrimPrefix(cont.Name, "/")
+	return &cont, nil
+}
@@ -0,0 +1,415 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/logtail"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	dliFieldLabels = "labels"
+	dliFieldStdout = "stdout"
+	dliFieldStderr = "stderr"
+	dliFieldSince  = "since"
+)
+
+func logsInputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Services").
+		Version("4.24.0").
+		Summary("Streams the logs of Docker containers matching label filters, where each line of a log is consumed as a message.").
+		Description(`
+Containers matching all of the `+"`labels`"+` filters are discovered by listing the running containers when the input connects, and by following the events of the Docker daemon thereafter, and the logs of each container are streamed until it stops. The logs of containers that are started whilst the input is running are streamed from their beginning, whereas the logs of containers that are already running when the input connects are streamed from the time of connecting, or from the duration of `+"`since`"+` prior when set.
+
+When a log stream is interrupted whilst its container is still running, such as when the Docker daemon is restarted, the stream is resumed from the timestamp of the last line consumed and lines that were already consumed are skipped.
+
+The position of each stream is not stored by Benthos, therefore lines that are logged whilst Benthos is not running are only consumed when `+"`since`"+` covers the downtime.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- docker_container_id
+- docker_container_name
+- docker_image
+- docker_stream
+- docker_timestamp
+- docker_label_* (one for each label of the container)
+`+"```"+`
+
+The field `+"`docker_stream`"+` is either `+"`stdout`"+` or `+"`stderr`"+`, where the logs of containers with a TTY are always `+"`stdout`"+`. The dots and dashes of label keys are replaced with underscores, so that the label `+"`com.docker.compose.service`"+` is added as `+"`docker_label_com_docker_compose_service`"+`.
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).`).
+		Fields(clientFields()...).
+		Fields(
+			service.NewStringListField(dliFieldLabels).
+				Description("A list of label filters that containers must match in order for their logs to be streamed, where each filter is either the key of a label that must exist or a `key=value` pair. When empty the logs of all containers are streamed.").
+				Default([]any{}).
+				Example([]any{"com.example.logs=true"}).
+				Example([]any{"com.docker.compose.project=shop", "tier"}),
+			service.NewBoolField(dliFieldStdout).
+				Description("Whether to stream the standard output of containers.").
+				Default(true).
+				Advanced(),
+			service.NewBoolField(dliFieldStderr).
+				Description("Whether to stream the standard error of containers.").
+				Default(true).
+				Advanced(),
+			service.NewDurationField(dliFieldSince).
+				Description("An optional duration prior to connecting from which to stream the logs of containers that are already running.").
+				Example("10m").
+				Optional(),
+		).
+		Example(
+			"Compose Project",
+			"Here we stream the logs of all containers of a Docker Compose project, and parse those that are JSON.",
+			`
+input:
+  docker_logs:
+    labels: [ com.docker.compose.project=shop ]
+
+pipeline:
+  processors:
+    - mapping: |
+        root = content().string().parse_json().catch({ "message": content().string() })
+        root.service = @docker_label_com_docker_compose_service
+        root.container = @docker_container_name
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterInput(
+		"docker_logs", logsInputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			r, err := newLogsReader(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacks(r), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// daemonEvent is the subset of an event of the Docker daemon used by the
+// input.
+type daemonEvent struct {
+	Action string `json:"Action"`
+	Actor  struct {
+		ID string `json:"ID"`
+	} `json:"Actor"`
+}
+
+type logsReader struct {
+	log    *service.Logger
+	client *apiClient
+
+	labels []string
+	stdout bool
+	stderr bool
+	since  *time.Duration
+
+	tailer *logtail.Reader
+}
+
+func newLogsReader(conf *service.ParsedConfig, mgr *service.Resources) (*logsReader, error) {
+	r := &logsReader{
+		log:    mgr.Logger(),
+		tailer: logtail.NewReader(),
+	}
+
+	var err error
+	if r.client, err = newAPIClient(conf); err != nil {
+		return nil, err
+	}
+	if r.labels, err = conf.FieldStringList(dliFieldLabels); err != nil {
+		return nil, err
+	}
+	if r.stdout, err = conf.FieldBool(dliFieldStdout); err != nil {
+		return nil, err
+	}
+	if r.stderr, err = conf.FieldBool(dliFieldStderr); err != nil {
+		return nil, err
+	}
+	if !r.stdout && !r.stderr {
+		return nil, errors.New("at least one of stdout and stderr must be enabled")
+	}
+	if conf.Contains(dliFieldSince) {
+		since, err := conf.FieldDuration(dliFieldSince)
+		if err != nil {
+			return nil, err
+		}
+		r.since = &since
+	}
+	return r, nil
+}
+
+// listRunning returns the IDs of the running containers that match the label
+// filters.
+func (r *logsReader) listRunning(ctx context.Context) ([]string, error) {
+	var containers []struct {
+		ID string `json:"Id"`
+	}
+	q := url.Values{}
+	q.Set("filters", labelFilters(r.labels, nil))
+	if err := r.client.getJSON(ctx, "/containers/json", q, &containers); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(containers))
+	for _, c := range containers {
+		ids = append(ids, c.ID)
+	}
+	return ids, nil
+}
+
+// watchEvents streams the start and destroy events of containers matching
+// the label filters from a given time until the stream ends, an error occurs
+// or the context is cancelled.
+func (r *logsReader) watchEvents(ctx context.Context, since time.Time, fn func(e daemonEvent)) error {
+	q := url.Values{}
+	q.Set("since", strconv.FormatInt(since.Unix(), 10))
+	q.Set("filters", labelFilters(r.labels, map[string][]string{
+		"type":  {"container"},
+		"event": {"start", "destroy"},
+	}))
+
+	res, err := r.client.get(ctx, "/events", q)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	dec := json.NewDecoder(res.Body)
+	for {
+		var e daemonEvent
+		if err := dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+		fn(e)
+	}
+}
+
+func (r *logsReader) Connect(ctx context.Context) error {
+	return r.tailer.Connect(ctx, func(ctx context.Context) (func(ctx context.Context), error) {
+		connectedAt := time.Now()
+		ids, err := r.listRunning(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// The logs of containers that are already running are streamed from
+		// now, or from the since duration prior.
+		startedAt := connectedAt
+		if r.since != nil {
+			startedAt = startedAt.Add(-*r.since)
+		}
+
+		return func(ctx context.Context) {
+			discover := func(id string, from time.Time) {
+				r.tailer.Follow(ctx, id, from, func(ctx context.Context, cursor *logtail.Cursor) {
+					r.stream(ctx, id, cursor)
+				})
+			}
+
+			for _, id := range ids {
+				discover(id, startedAt)
+			}
+
+			// Events are watched from the time that containers were listed so
+			// that containers started in the meantime aren't missed.
+			eventsSince := connectedAt
+			for ctx.Err() == nil {
+				watchStarted := time.Now()
+				err := r.watchEvents(ctx, eventsSince, func(e daemonEvent) {
+					switch e.Action {
+					case "start":
+						discover(e.Actor.ID, time.Time{})
+					case "destroy":
+						r.tailer.Forget(e.Actor.ID)
+					}
+				})
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					r.log.Errorf("Failed to watch container events: %v", err)
+					select {
+					case <-time.After(time.Second):
+					case <-ctx.Done():
+						return
+					}
+				}
+				eventsSince = watchStarted
+			}
+		}, nil
+	})
+}
+
+// stream consumes the log of a container until it stops, and resumes the
+// stream whenever it is interrupted.
+func (r *logsReader) stream(ctx context.Context, id string, cursor *logtail.Cursor) {
+	for ctx.Err() == nil {
+		cont, err := r.client.inspect(ctx, id)
+		if err != nil {
+			var aErr *apiError
+			if errors.As(err, &aErr) && aErr.Code == http.StatusNotFound {
+				return
+			}
+			r.log.Errorf("Failed to inspect container %v: %v", id, err)
+		} else {
+			if !cont.State.Running {
+				return
+			}
+			if err = r.streamOnce(ctx, cont, cursor); err != nil && ctx.Err() == nil {
+				r.log.Errorf("Failed to stream logs of container %v: %v", cont.Name, err)
+			}
+		}
+
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// logLine is a line of a log along with the stream it was written to.
+type logLine struct {
+	stream string
+	line   string
+}
+
+// readLines reads the lines of a log stream, which is multiplexed into
+// frames of stdout and stderr unless the container has a TTY.
+func readLines(body io.Reader, tty bool, fn func(l logLine) error) error {
+	if tty {
+		reader := bufio.NewReader(body)
+		for {
+			line, err := reader.ReadString('\n')
+			if line = strings.TrimSuffix(line, "\n"); line != "" {
+				if ferr := fn(logLine{stream: "stdout", line: strings.TrimSuffix(line, "\r")}); ferr != nil {
+					return ferr
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	pending := map[byte]string{}
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(body, header); err != nil {
+			return err
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(header[4:]))
+		if _, err := io.ReadFull(body, payload); err != nil {
+			return err
+		}
+
+		streamName := "stdout"
+		if header[0] == 2 {
+			streamName = "stderr"
+		}
+
+		// A frame may contain partial or multiple lines, therefore partial
+		// lines are held until the remainder is read.
+		data := pending[header[0]] + string(payload)
+		for {
+			i := strings.IndexByte(data, '\n')
+			if i < 0 {
+				break
+			}
+			if err := fn(logLine{stream: streamName, line: data[:i]}); err != nil {
+				return err
+			}
+			data = data[i+1:]
+		}
+		pending[header[0]] = data
+	}
+}
+
+func (r *logsReader) streamOnce(ctx context.Context, cont *container, cursor *logtail.Cursor) error {
+	q := url.Values{}
+	q.Set("follow", "true")
+	q.Set("timestamps", "true")
+	q.Set("stdout", strconv.FormatBool(r.stdout))
+	q.Set("stderr", strconv.FormatBool(r.stderr))
+	if last := cursor.Last(); !last.IsZero() {
+		q.Set("since", fmt.Sprintf("%d.%09d", last.Unix(), last.Nanosecond()))
+	}
+	cursor.Resume()
+
+	res, err := r.client.get(ctx, "/containers/"+url.PathEscape(cont.ID)+"/logs", q)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	err = readLines(res.Body, cont.Config.Tty, func(l logLine) error {
+		tsStr, content, _ := strings.Cut(l.line, " ")
+		ts, terr := time.Parse(time.RFC3339Nano, tsStr)
+		if terr != nil {
+			// Lines are always prefixed with a timestamp, but in case one isn't
+			// we consume the line as is.
+			content = l.line
+		} else if !cursor.Accept(ts) {
+			return nil
+		}
+
+		msg := service.NewMessage([]byte(content))
+		msg.MetaSetMut("docker_container_id", cont.ID)
+		msg.MetaSetMut("docker_container_name", cont.Name)
+		msg.MetaSetMut("docker_image", cont.Config.Image)
+		msg.MetaSetMut("docker_stream", l.stream)
+		if terr == nil {
+			msg.MetaSetMut("docker_timestamp", ts.Format(time.RFC3339Nano))
+		}
+		for k, v := range cont.Config.Labels {
+			msg.MetaSetMut("docker_label_"+labelMetaKey(k), v)
+		}
+
+		return r.tailer.Send(ctx, msg)
+	})
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// labelMetaKey replaces the dots and dashes of a label key, which are common
+// within Docker labels, so that the metadata key can be referenced easily
+// within Bloblang.
+func labelMetaKey(k string) string {
+	return strings.NewReplacer(".", "_", "-", "_").Replace(k)
+}
+
+func (r *logsReader) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	return r.tailer.Read(ctx)
+}
+
+func (r *logsReader) Close(ctx context.Context) error {
+	return r.tailer.Close(ctx)
+}
@@ -0,0 +1,130 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func muxFrame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func TestReadLinesMultiplexed(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(muxFrame(1, "foo\nba"))
+	buf.Write(muxFrame(2, "err\n"))
+	buf.Write(muxFrame(1, "r\nbaz\n"))
+
+	var lines []logLine
+	err := readLines(&buf, false, func(l logLine) error {
+		lines = append(lines, l)
+		return nil
+	})
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, []logLine{
+		{stream: "stdout", line: "foo"},
+		{stream: "stderr", line: "err"},
+		{stream: "stdout", line: "bar"},
+		{stream: "stdout", line: "baz"},
+	}, lines)
+}
+
+func TestLogsInput(t *testing.T) {
+	now := time.Now().UTC()
+	line := func(offset time.Duration, s string) string {
+		return now.Add(offset).Format(time.RFC3339Nano) + " " + s + "\n"
+	}
+
+	var inspectMut sync.Mutex
+	inspected := map[string]int{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/containers/json":
+			assert.Equal(t, `{"label":["app=meow"]}`, r.URL.Query().Get("filters"))
+			_, _ = w.Write([]byte(`[{"Id":"aaa"}]`))
+		case r.URL.Path == "/events":
+			assert.Equal(t, `{"event":["start","destroy"],"label":["app=meow"],"type":["container"]}`, r.URL.Query().Get("filters"))
+			_, _ = w.Write([]byte(`{"Type":"container","Action":"start","Actor":{"ID":"bbb"}}` + "\n"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		case strings.HasSuffix(r.URL.Path, "/json"):
+			id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/containers/"), "/json")
+			inspectMut.Lock()
+			inspected[id]++
+			running := inspected[id] == 1
+			inspectMut.Unlock()
+			_, _ = fmt.Fprintf(w, `{"Id":"%v","Name":"/%v_name","Config":{"Image":"%v_image","Labels":{"app":"meow","com.example.tier":"web"},"Tty":%v},"State":{"Running":%v}}`, id, id, id, id == "bbb", running)
+		case r.URL.Path == "/containers/aaa/logs":
+			assert.Equal(t, "true", r.URL.Query().Get("follow"))
+			assert.Equal(t, "true", r.URL.Query().Get("timestamps"))
+			assert.NotEmpty(t, r.URL.Query().Get("since"))
+			_, _ = w.Write(muxFrame(1, line(time.Millisecond, "a out")))
+			_, _ = w.Write(muxFrame(2, line(time.Millisecond*2, "a err")))
+		case r.URL.Path == "/containers/bbb/logs":
+			assert.Empty(t, r.URL.Query().Get("since"))
+			_, _ = w.Write([]byte(line(time.Millisecond, "b out")))
+		default:
+			t.Errorf("unexpected request path: %v", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	conf, err := logsInputConfig().ParseYAML(fmt.Sprintf(`
+host: %v
+labels: [ app=meow ]
+`, ts.URL), nil)
+	require.NoError(t, err)
+
+	r, err := newLogsReader(conf, service.MockResources())
+	require.NoError(t, err)
+	require.NoError(t, r.Connect(context.Background()))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	var results []string
+	for len(results) < 3 {
+		msg, ackFn, err := r.Read(ctx)
+		require.NoError(t, err)
+		require.NoError(t, ackFn(ctx, nil))
+
+		b, err := msg.AsBytes()
+		require.NoError(t, err)
+
+		var meta []string
+		for _, k := range []string{"docker_container_id", "docker_container_name", "docker_image", "docker_stream", "docker_label_com_example_tier"} {
+			v, _ := msg.MetaGet(k)
+			meta = append(meta, v)
+		}
+		results = append(results, string(b)+": "+strings.Join(meta, ","))
+	}
+	sort.Strings(results)
+
+	assert.Equal(t, []string{
+		"a err: aaa,aaa_name,aaa_image,stderr,web",
+		"a out: aaa,aaa_name,aaa_image,stdout,web",
+		"b out: bbb,bbb_name,bbb_image,stdout,web",
+	}, results)
+
+	require.NoError(t, r.Close(context.Background()))
+}
@@ -3,6 +3,7 @@ package gcp
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
 
@@ -24,6 +25,8 @@ const (
 	pbiFieldCreateSub              = "create_subscription"
 	pbiFieldCreateSubEnabled       = "enabled"
 	pbiFieldCreateSubTopicID       = "topic"
+	pbiFieldCreateSubOrdering      = "enable_message_ordering"
+	pbiFieldCreateSubExactlyOnce   = "enable_exactly_once_delivery"
 )
 
 type pbiConfig struct {
@@ -35,6 +38,8 @@ type pbiConfig struct {
 	Sync                   bool
 	CreateEnabled          bool
 	CreateTopicID          string
+	CreateOrdering         bool
+	CreateExactlyOnce      bool
 }
 
 func pbiConfigFromParsed(pConf *service.ParsedConfig) (conf pbiConfig, err error) {
@@ -64,6 +69,12 @@ func pbiConfigFromParsed(pConf *service.ParsedConfig) (conf pbiConfig, err error
 		if conf.CreateTopicID, err = createConf.FieldString(pbiFieldCreateSubTopicID); err != nil {
 			return
 		}
+		if conf.CreateOrdering, err = createConf.FieldBool(pbiFieldCreateSubOrdering); err != nil {
+			return
+		}
+		if conf.CreateExactlyOnce, err = createConf.FieldBool(pbiFieldCreateSubExactlyOnce); err != nil {
+			return
+		}
 	}
 	return
 }
@@ -83,10 +94,20 @@ This input adds the following metadata fields to each message:
 `+"``` text"+`
 - gcp_pubsub_publish_time_unix - The time at which the message was published to the topic.
 - gcp_pubsub_delivery_attempt - When dead lettering is enabled, this is set to the number of times PubSub has attempted to deliver a message.
+- gcp_pubsub_dead_letter_topic - When dead lettering is enabled, this is set to the topic that messages are forwarded to once the maximum delivery attempts are exceeded.
+- gcp_pubsub_max_delivery_attempts - When dead lettering is enabled, this is set to the maximum number of delivery attempts before a message is dead lettered.
+- gcp_pubsub_message_id - The server assigned ID of the message.
+- gcp_pubsub_ordering_key - The ordering key of the message, if one was set.
 - All message attributes
 `+"```"+`
 
+The dead letter metadata fields are obtained from the configuration of the subscription, which requires the `+"`pubsub.subscriptions.get`"+` permission.
+
 You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).
+
+### Exactly-Once Delivery
+
+When consuming from a subscription with exactly-once delivery enabled acknowledgements are confirmed with the server before being considered successful, and a failed acknowledgement is logged as an error. Messages whose acknowledgements fail are redelivered by the server.
 `).
 		Fields(
 			service.NewStringField(pbiFieldProjectID).
@@ -113,6 +134,14 @@ You can access these metadata fields using [function interpolation](/docs/config
 				service.NewStringField(pbiFieldCreateSubTopicID).
 					Description("Defines the topic that the subscription should be vinculated to.").
 					Default(""),
+				service.NewBoolField(pbiFieldCreateSubOrdering).
+					Description("Whether messages published with the same ordering key are delivered to the subscription in the order that they were published.").
+					Default(false).
+					Version("4.24.0"),
+				service.NewBoolField(pbiFieldCreateSubExactlyOnce).
+					Description("Whether exactly-once delivery is enabled for the subscription.").
+					Default(false).
+					Version("4.24.0"),
 			).
 				Description("Allows you to configure the input subscription and creates if it doesn't exist.").
 				Advanced(),
@@ -151,7 +180,11 @@ func createSubscription(conf pbiConfig, client *pubsub.Client, log *service.Logg
 	}
 
 	log.Infof("Creating subscription '%v' on topic '%v'\n", conf.SubscriptionID, conf.CreateTopicID)
-	_, err = client.CreateSubscription(context.Background(), conf.SubscriptionID, pubsub.SubscriptionConfig{Topic: client.Topic(conf.CreateTopicID)})
+	_, err = client.CreateSubscription(context.Background(), conf.SubscriptionID, pubsub.SubscriptionConfig{
+		Topic:                     client.Topic(conf.CreateTopicID),
+		EnableMessageOrdering:     conf.CreateOrdering,
+		EnableExactlyOnceDelivery: conf.CreateExactlyOnce,
+	})
 
 	if err != nil {
 		log.Errorf("Error creating subscription %v", err)
@@ -162,6 +195,7 @@ type gcpPubSubReader struct {
 	conf pbiConfig
 
 	subscription *pubsub.Subscription
+	deadLetter   *pubsub.DeadLetterPolicy
 	msgsChan     chan *pubsub.Message
 	closeFunc    context.CancelFunc
 	subMut       sync.Mutex
@@ -196,7 +230,7 @@ func newGCPPubSubReader(conf pbiConfig, res *service.Resources) (*gcpPubSubReade
 	}, nil
 }
 
-func (c *gcpPubSubReader) Connect(ignored context.Context) error {
+func (c *gcpPubSubReader) Connect(ctx context.Context) error {
 	c.subMut.Lock()
 	defer c.subMut.Unlock()
 	if c.subscription != nil {
@@ -204,6 +238,16 @@ func (c *gcpPubSubReader) Connect(ignored context.Context) error {
 	}
 
 	sub := c.client.Subscription(c.conf.SubscriptionID)
+
+	// The subscription config is only used for enriching message metadata and
+	// therefore failing to obtain it is not considered fatal.
+	c.deadLetter = nil
+	if subConf, err := sub.Config(ctx); err != nil {
+		c.log.Debugf("Failed to obtain subscription config: %v\n", err)
+	} else if subConf.DeadLetterPolicy != nil {
+		c.deadLetter = subConf.DeadLetterPolicy
+	}
+
 	sub.ReceiveSettings.MaxOutstandingMessages = c.conf.MaxOutstandingMessages
 	sub.ReceiveSettings.MaxOutstandingBytes = c.conf.MaxOutstandingBytes
 	sub.ReceiveSettings.Synchronous = c.conf.Sync
@@ -243,6 +287,7 @@ func (c *gcpPubSubReader) Connect(ignored context.Context) error {
 func (c *gcpPubSubReader) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
 	c.subMut.Lock()
 	msgsChan := c.msgsChan
+	deadLetter := c.deadLetter
 	c.subMut.Unlock()
 	if msgsChan == nil {
 		return nil, nil, service.ErrNotConnected
@@ -265,15 +310,34 @@ func (c *gcpPubSubReader) Read(ctx context.Context) (*service.Message, service.A
 	}
 	part.MetaSetMut("gcp_pubsub_publish_time_unix", gmsg.PublishTime.Unix())
 
+	part.MetaSetMut("gcp_pubsub_message_id", gmsg.ID)
+	if gmsg.OrderingKey != "" {
+		part.MetaSetMut("gcp_pubsub_ordering_key", gmsg.OrderingKey)
+	}
+
 	if gmsg.DeliveryAttempt != nil {
 		part.MetaSetMut("gcp_pubsub_delivery_attempt", *gmsg.DeliveryAttempt)
 	}
+	if deadLetter != nil {
+		part.MetaSetMut("gcp_pubsub_dead_letter_topic", deadLetter.DeadLetterTopic)
+		part.MetaSetMut("gcp_pubsub_max_delivery_attempts", deadLetter.MaxDeliveryAttempts)
+	}
 
 	return part, func(ctx context.Context, res error) error {
+		// For subscriptions without exactly-once delivery enabled the result
+		// is resolved immediately.
+		var ackRes *pubsub.AckResult
 		if res != nil {
-			gmsg.Nack()
+			ackRes = gmsg.NackWithResult()
 		} else {
-			gmsg.Ack()
+			ackRes = gmsg.AckWithResult()
+		}
+		status, err := ackRes.Get(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to acknowledge message %v: %w", gmsg.ID, err)
+		}
+		if status != pubsub.AcknowledgeStatusSuccess {
+			return fmt.Errorf("failed to acknowledge message %v: status %v", gmsg.ID, status)
 		}
 		return nil
 	}, nil
@@ -52,7 +52,7 @@ pipeline:
 				Description("An optional endpoint to override the default of `pubsub.googleapis.com:443`. This can be used to connect to a region specific pubsub endpoint. For a list of valid values check out [this document.](https://cloud.google.com/pubsub/docs/reference/service_apis_overview#list_of_regional_endpoints)"),
 			service.NewInterpolatedStringField("ordering_key").
 				Optional().
-				Description("The ordering key to use for publishing messages.").
+				Description("The ordering key to use for publishing messages. When set message ordering is enabled for the topic, and messages sharing an ordering key are delivered in the order they were published to subscriptions with message ordering enabled. When a message fails to publish the ordering key is resumed so that it can be retried.").
 				Advanced(),
 			service.NewIntField("max_in_flight").Default(64).Description("The maximum number of messages to have in flight at a given time. Increasing this may improve throughput."),
 			service.NewIntField("count_threshold").
@@ -312,11 +312,33 @@ func (out *pubsubOutput) writeMessage(ctx context.Context, cachedTopics map[stri
 		return nil, fmt.Errorf("failed to get bytes from message: %w", err)
 	}
 
-	return topic.Publish(ctx, &pubsub.Message{
+	res := topic.Publish(ctx, &pubsub.Message{
 		Data:        data,
 		Attributes:  attr,
 		OrderingKey: orderingKey,
-	}), nil
+	})
+	if orderingKey != "" {
+		res = &orderedPublishResult{res: res, topic: topic, orderingKey: orderingKey}
+	}
+	return res, nil
+}
+
+// orderedPublishResult resumes publishing for an ordering key after a failed
+// publish. The client pauses publishing of an ordering key when a publish
+// fails in order to preserve ordering, and therefore this is required in order
+// for the message to be retried.
+type orderedPublishResult struct {
+	res         publishResult
+	topic       pubsubTopic
+	orderingKey string
+}
+
+func (o *orderedPublishResult) Get(ctx context.Context) (string, error) {
+	serverID, err := o.res.Get(ctx)
+	if err != nil {
+		o.topic.ResumePublish(o.orderingKey)
+	}
+	return serverID, err
 }
 
 func (out *pubsubOutput) getTopic(ctx context.Context, name string) (pubsubTopic, error) {
@@ -279,3 +279,62 @@ func TestPubSubOutput_PublishErrors(t *testing.T) {
 	})
 	require.ElementsMatch(t, []string{"simulated foo error", "simulated bar error"}, errs)
 }
+
+func TestPubSubOutput_OrderingKeyResumedOnError(t *testing.T) {
+	ctx := context.Background()
+
+	conf, err := newPubSubOutputConfig().ParseYAML(`
+    project: sample-project
+    topic: test
+    ordering_key: 'key_${! content().string() }'
+    `,
+		nil,
+	)
+	require.NoError(t, err, "bad output config")
+
+	client := &mockPubSubClient{}
+
+	fooTopic := &mockTopic{}
+	fooTopic.On("Exists").Return(true, nil).Once()
+	fooTopic.On("EnableOrdering").Return().Once()
+	fooTopic.On("ResumePublish", "key_foo").Return().Once()
+	fooTopic.On("Stop").Return().Once()
+
+	fooRes := &mockPublishResult{}
+	fooRes.On("Get").Return("", errors.New("simulated foo error")).Once()
+	fooTopic.On("Publish", "foo", mock.AnythingOfType("*pubsub.Message")).Return(fooRes).Once()
+
+	barRes := &mockPublishResult{}
+	barRes.On("Get").Return("bar", nil).Once()
+	fooTopic.On("Publish", "bar", mock.AnythingOfType("*pubsub.Message")).Return(barRes).Once()
+
+	client.On("Topic", "test").Return(fooTopic).Once()
+
+	out, err := newPubSubOutput(conf)
+	require.NoError(t, err, "failed to create output")
+	out.client = client
+	t.Cleanup(func() {
+		err = out.Close(ctx)
+		require.NoError(t, err, "closing output failed")
+
+		mock.AssertExpectationsForObjects(
+			t,
+			client,
+			fooTopic,
+			fooRes, barRes,
+		)
+	})
+
+	err = out.Connect(ctx)
+	require.NoError(t, err, "connect failed")
+
+	err = out.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte("foo")),
+		service.NewMessage([]byte("bar")),
+	})
+	require.Error(t, err)
+
+	var batchErr *service.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	require.Equal(t, 1, batchErr.IndexedErrors())
+}
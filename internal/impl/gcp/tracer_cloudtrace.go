@@ -30,6 +30,7 @@ func init() {
 			docs.FieldFloat("sampling_ratio", "Sets the ratio of traces to sample. Tuning the sampling ratio is recommended for high-volume production workloads.", 1.0).HasDefault(1.0),
 			docs.FieldString("tags", "A map of tags to add to tracing spans.").Map().Advanced().HasDefault(map[string]any{}),
 			docs.FieldString("flush_interval", "The period of time between each flush of tracing spans.").HasDefault(""),
+			tracer.TailSamplingFieldSpec(),
 		),
 	})
 }
@@ -59,8 +60,13 @@ func NewCloudTrace(config tracer.Config, nm bundle.NewManagement) (trace.TracerP
 		batchOpts = append(batchOpts, tracesdk.WithBatchTimeout(flushInterval))
 	}
 
+	spanProc, err := config.CloudTrace.TailSampling.SpanProcessor(tracesdk.NewBatchSpanProcessor(exp, batchOpts...))
+	if err != nil {
+		return nil, err
+	}
+
 	return tracesdk.NewTracerProvider(
-		tracesdk.WithBatcher(exp, batchOpts...),
+		tracesdk.WithSpanProcessor(spanProc),
 		tracesdk.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attrs...)),
 		tracesdk.WithSampler(sampler),
 	), nil
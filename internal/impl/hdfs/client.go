@@ -0,0 +1,182 @@
+package hdfs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/colinmarc/hdfs/v2"
+	krb "github.com/jcmturner/gokrb5/v8/client"
+	krbconfig "github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	cFieldUseDatanodeHostname = "use_datanode_hostname"
+
+	cFieldKerberos                       = "kerberos"
+	cFieldKerberosEnabled                = "enabled"
+	cFieldKerberosConfigPath             = "config_path"
+	cFieldKerberosRealm                  = "realm"
+	cFieldKerberosUsername               = "username"
+	cFieldKerberosPassword               = "password"
+	cFieldKerberosKeytabPath             = "keytab_path"
+	cFieldKerberosServicePrincipalName   = "service_principal_name"
+	cFieldKerberosDataTransferProtection = "data_transfer_protection"
+)
+
+const hostsDescription = "A list of target host addresses to connect to. When connecting to a cluster with high availability namenodes all of the namenodes should be listed, and requests will fail over between them."
+
+func clientFields() []*service.ConfigField {
+	return []*service.ConfigField{
+		service.NewBoolField(cFieldUseDatanodeHostname).
+			Description("Whether to connect to datanodes via their hostname rather than their IP address, which is useful in multi-homed environments.").
+			Default(false).
+			Advanced().
+			Version("4.24.0"),
+		service.NewObjectField(cFieldKerberos,
+			service.NewBoolField(cFieldKerberosEnabled).
+				Description("Whether to authenticate with Kerberos.").
+				Default(false),
+			service.NewStringField(cFieldKerberosConfigPath).
+				Description("The path of the Kerberos configuration file.").
+				Default("/etc/krb5.conf"),
+			service.NewStringField(cFieldKerberosRealm).
+				Description("The Kerberos realm to authenticate with.").
+				Default(""),
+			service.NewStringField(cFieldKerberosUsername).
+				Description("The principal name to authenticate as, which takes precedence over the `user` field.").
+				Default(""),
+			service.NewStringField(cFieldKerberosPassword).
+				Description("A password to authenticate with. Either a password or a `keytab_path` must be provided.").
+				Default("").
+				Secret(),
+			service.NewStringField(cFieldKerberosKeytabPath).
+				Description("The path of a keytab file to authenticate with. Either a keytab or a `password` must be provided.").
+				Default(""),
+			service.NewStringField(cFieldKerberosServicePrincipalName).
+				Description("The service principal name of the namenodes, as in the `dfs.namenode.kerberos.principal` property of Hadoop. The string `_HOST` is replaced with the address of each namenode.").
+				Default("nn/_HOST"),
+			service.NewStringEnumField(cFieldKerberosDataTransferProtection, "", "authentication", "integrity", "privacy").
+				Description("The level of protection required when communicating with datanodes, as in the `dfs.data.transfer.protection` property of Hadoop. Leave empty in order to disable SASL with datanodes.").
+				Default(""),
+		).
+			Description("Kerberos authentication for connecting to secured clusters.").
+			Advanced().
+			Version("4.24.0"),
+	}
+}
+
+type kerberosConfig struct {
+	configPath             string
+	realm                  string
+	username               string
+	password               string
+	keytabPath             string
+	servicePrincipalName   string
+	dataTransferProtection string
+}
+
+type clientConfig struct {
+	hosts               []string
+	user                string
+	useDatanodeHostname bool
+	kerberos            *kerberosConfig
+}
+
+func clientConfigFromParsed(conf *service.ParsedConfig, hostsField, userField string) (c clientConfig, err error) {
+	if c.hosts, err = conf.FieldStringList(hostsField); err != nil {
+		return
+	}
+	if c.user, err = conf.FieldString(userField); err != nil {
+		return
+	}
+	if c.useDatanodeHostname, err = conf.FieldBool(cFieldUseDatanodeHostname); err != nil {
+		return
+	}
+
+	kConf := conf.Namespace(cFieldKerberos)
+	var enabled bool
+	if enabled, err = kConf.FieldBool(cFieldKerberosEnabled); err != nil || !enabled {
+		return
+	}
+
+	k := &kerberosConfig{}
+	if k.configPath, err = kConf.FieldString(cFieldKerberosConfigPath); err != nil {
+		return
+	}
+	if k.realm, err = kConf.FieldString(cFieldKerberosRealm); err != nil {
+		return
+	}
+	if k.username, err = kConf.FieldString(cFieldKerberosUsername); err != nil {
+		return
+	}
+	if k.password, err = kConf.FieldString(cFieldKerberosPassword); err != nil {
+		return
+	}
+	if k.keytabPath, err = kConf.FieldString(cFieldKerberosKeytabPath); err != nil {
+		return
+	}
+	if k.servicePrincipalName, err = kConf.FieldString(cFieldKerberosServicePrincipalName); err != nil {
+		return
+	}
+	if k.dataTransferProtection, err = kConf.FieldString(cFieldKerberosDataTransferProtection); err != nil {
+		return
+	}
+	if k.username == "" {
+		k.username = c.user
+	}
+	if k.username == "" {
+		err = errors.New("a kerberos username must be provided")
+		return
+	}
+	if k.password == "" && k.keytabPath == "" {
+		err = errors.New("either a kerberos password or keytab_path must be provided")
+		return
+	}
+	c.kerberos = k
+	return
+}
+
+// newClient creates a client from the configuration, logging into the
+// Kerberos KDC first when Kerberos is enabled.
+func (c clientConfig) newClient() (*hdfs.Client, error) {
+	opts := hdfs.ClientOptions{
+		Addresses:           c.hosts,
+		User:                c.user,
+		UseDatanodeHostname: c.useDatanodeHostname,
+	}
+	if c.kerberos != nil {
+		krbClient, err := c.kerberos.login()
+		if err != nil {
+			return nil, err
+		}
+		opts.KerberosClient = krbClient
+		opts.KerberosServicePrincipleName = c.kerberos.servicePrincipalName
+		opts.DataTransferProtection = c.kerberos.dataTransferProtection
+	}
+	return hdfs.NewClient(opts)
+}
+
+func (k *kerberosConfig) login() (*krb.Client, error) {
+	cfg, err := krbconfig.Load(k.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kerberos config: %w", err)
+	}
+
+	var client *krb.Client
+	if k.keytabPath != "" {
+		kt, err := keytab.Load(k.keytabPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kerberos keytab: %w", err)
+		}
+		client = krb.NewWithKeytab(k.username, k.realm, kt, cfg)
+	} else {
+		client = krb.NewWithPassword(k.username, k.realm, k.password, cfg)
+	}
+	if err := client.Login(); err != nil {
+		return nil, fmt.Errorf("failed to login to kerberos: %w", err)
+	}
+	return client, nil
+}
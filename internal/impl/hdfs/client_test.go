@@ -0,0 +1,86 @@
+package hdfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientConfigKerberos(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		kerberos    *kerberosConfig
+		errContains string
+	}{
+		{
+			name: "disabled",
+			config: `
+hosts: [ localhost:9000 ]
+user: foo
+directory: /foo
+`,
+		},
+		{
+			name: "keytab",
+			config: `
+hosts: [ nn1:8020, nn2:8020 ]
+user: foo
+directory: /foo
+kerberos:
+  enabled: true
+  realm: EXAMPLE.COM
+  keytab_path: /etc/foo.keytab
+  data_transfer_protection: privacy
+`,
+			kerberos: &kerberosConfig{
+				configPath:             "/etc/krb5.conf",
+				realm:                  "EXAMPLE.COM",
+				username:               "foo",
+				keytabPath:             "/etc/foo.keytab",
+				servicePrincipalName:   "nn/_HOST",
+				dataTransferProtection: "privacy",
+			},
+		},
+		{
+			name: "missing credentials",
+			config: `
+hosts: [ localhost:9000 ]
+directory: /foo
+kerberos:
+  enabled: true
+  username: bar
+`,
+			errContains: "password or keytab_path",
+		},
+		{
+			name: "missing username",
+			config: `
+hosts: [ localhost:9000 ]
+directory: /foo
+kerberos:
+  enabled: true
+  password: baz
+`,
+			errContains: "username",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			pConf, err := inputSpec().ParseYAML(test.config, nil)
+			require.NoError(t, err)
+
+			conf, err := clientConfigFromParsed(pConf, iFieldHosts, iFieldUser)
+			if test.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.kerberos, conf.kerberos)
+		})
+	}
+}
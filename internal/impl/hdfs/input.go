@@ -2,10 +2,18 @@ package hdfs
 
 import (
 	"context"
+	"errors"
+	"io"
 	"path/filepath"
+	"sync"
 
-	"github.com/colinmarc/hdfs"
+	"github.com/colinmarc/hdfs/v2"
 
+	"github.com/benthosdev/benthos/v4/internal/codec"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/component/interop"
+	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/public/service"
 )
 
@@ -13,6 +21,7 @@ const (
 	iFieldHosts     = "hosts"
 	iFieldUser      = "user"
 	iFieldDirectory = "directory"
+	iFieldCodec     = "codec"
 )
 
 func inputSpec() *service.ConfigSpec {
@@ -21,6 +30,8 @@ func inputSpec() *service.ConfigSpec {
 		Categories("Services").
 		Summary(`Reads files from a HDFS directory, where each discrete file will be consumed as a single message payload.`).
 		Description(`
+When consuming large files it's often necessary to process them in streamed parts in order to avoid loading an entire file in memory at a given time. In order to do this a `+"[`codec`](#codec)"+` can be specified that determines how to break the input into smaller individual messages.
+
 ### Metadata
 
 This input adds the following metadata fields to each message:
@@ -34,35 +45,34 @@ You can access these metadata fields using
 [function interpolation](/docs/configuration/interpolation#bloblang-queries).`).
 		Fields(
 			service.NewStringListField(iFieldHosts).
-				Description("A list of target host addresses to connect to.").
+				Description(hostsDescription).
 				Example("localhost:9000"),
 			service.NewStringField(iFieldUser).
 				Description("A user ID to connect as.").
 				Default(""),
 			service.NewStringField(iFieldDirectory).
 				Description("The directory to consume from."),
-		)
-
+			service.NewInternalField(codec.ReaderDocs).Default("all-bytes").Version("4.24.0"),
+		).
+		Fields(clientFields()...)
 }
 
 func init() {
-	err := service.RegisterInput(
+	err := service.RegisterBatchInput(
 		"hdfs", inputSpec(),
-		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.Input, err error) {
-			rdr := &hdfsReader{
-				log: mgr.Logger(),
-			}
-			out = rdr
-			if rdr.hosts, err = conf.FieldStringList(iFieldHosts); err != nil {
-				return
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			rdr, err := newHDFSReaderFromParsed(conf, mgr)
+			if err != nil {
+				return nil, err
 			}
-			if rdr.user, err = conf.FieldString(iFieldUser); err != nil {
-				return
-			}
-			if rdr.directory, err = conf.FieldString(iFieldDirectory); err != nil {
-				return
+
+			// NOTE: Codecs are only available to internal implementations, and
+			// therefore we punch the reader up to the public API via interop.
+			i, err := input.NewAsyncReader("hdfs", input.NewAsyncPreserver(rdr), interop.UnwrapManagement(mgr))
+			if err != nil {
+				return nil, err
 			}
-			return
+			return interop.NewUnwrapInternalInput(i), nil
 		})
 	if err != nil {
 		panic(err)
@@ -70,36 +80,62 @@ func init() {
 }
 
 type hdfsReader struct {
-	hosts     []string
-	user      string
-	directory string
-
-	targets []string
+	clientConf  clientConfig
+	directory   string
+	scannerCtor codec.ReaderConstructor
 
-	client *hdfs.Client
+	mut         sync.Mutex
+	client      *hdfs.Client
+	targets     []string
+	scanner     codec.Reader
+	currentName string
 
 	log *service.Logger
 }
 
+func newHDFSReaderFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*hdfsReader, error) {
+	rdr := &hdfsReader{
+		log: mgr.Logger(),
+	}
+
+	var err error
+	if rdr.clientConf, err = clientConfigFromParsed(conf, iFieldHosts, iFieldUser); err != nil {
+		return nil, err
+	}
+	if rdr.directory, err = conf.FieldString(iFieldDirectory); err != nil {
+		return nil, err
+	}
+
+	codecStr, err := conf.FieldString(iFieldCodec)
+	if err != nil {
+		return nil, err
+	}
+	if rdr.scannerCtor, err = codec.GetReader(codecStr, codec.NewReaderConfig()); err != nil {
+		return nil, err
+	}
+	return rdr, nil
+}
+
 func (h *hdfsReader) Connect(ctx context.Context) error {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
 	if h.client != nil {
 		return nil
 	}
 
-	client, err := hdfs.NewClient(hdfs.ClientOptions{
-		Addresses: h.hosts,
-		User:      h.user,
-	})
+	client, err := h.clientConf.newClient()
 	if err != nil {
 		return err
 	}
 
-	h.client = client
 	targets, err := client.ReadDir(h.directory)
 	if err != nil {
+		client.Close()
 		return err
 	}
 
+	h.client = client
 	for _, info := range targets {
 		if !info.IsDir() {
 			h.targets = append(h.targets, info.Name())
@@ -110,28 +146,84 @@ func (h *hdfsReader) Connect(ctx context.Context) error {
 	return nil
 }
 
-func (h *hdfsReader) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+// nextScannerLocked opens the next target file with a scanner, returning
+// component.ErrTypeClosed once all files have been consumed.
+func (h *hdfsReader) nextScannerLocked() error {
 	if len(h.targets) == 0 {
-		return nil, nil, service.ErrEndOfInput
+		return component.ErrTypeClosed
 	}
 
 	fileName := h.targets[0]
 	h.targets = h.targets[1:]
 
-	filePath := filepath.Join(h.directory, fileName)
-	msgBytes, readerr := h.client.ReadFile(filePath)
-	if readerr != nil {
-		return nil, nil, readerr
+	file, err := h.client.Open(filepath.Join(h.directory, fileName))
+	if err != nil {
+		return err
 	}
 
-	msg := service.NewMessage(msgBytes)
-	msg.MetaSetMut("hdfs_name", fileName)
-	msg.MetaSetMut("hdfs_path", filePath)
-	return msg, func(ctx context.Context, err error) error {
+	if h.scanner, err = h.scannerCtor(fileName, file, func(ctx context.Context, err error) error {
 		return nil
-	}, nil
+	}); err != nil {
+		file.Close()
+		return err
+	}
+	h.currentName = fileName
+	return nil
 }
 
-func (h *hdfsReader) Close(ctx context.Context) error {
-	return nil
+func (h *hdfsReader) ReadBatch(ctx context.Context) (message.Batch, input.AsyncAckFn, error) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	if h.client == nil {
+		return nil, nil, component.ErrNotConnected
+	}
+
+	for {
+		if h.scanner == nil {
+			if err := h.nextScannerLocked(); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		parts, scnAckFn, err := h.scanner.Next(ctx)
+		if err == nil {
+			filePath := filepath.Join(h.directory, h.currentName)
+			for _, part := range parts {
+				part.MetaSetMut("hdfs_name", h.currentName)
+				part.MetaSetMut("hdfs_path", filePath)
+			}
+			return message.Batch(parts), func(ctx context.Context, res error) error {
+				return scnAckFn(ctx, res)
+			}, nil
+		}
+
+		if !errors.Is(err, io.EOF) {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				err = component.ErrTimeout
+			}
+			return nil, nil, err
+		}
+		if cerr := h.scanner.Close(ctx); cerr != nil {
+			h.log.Warnf("Failed to close file scanner cleanly: %v\n", cerr)
+		}
+		h.scanner = nil
+	}
+}
+
+func (h *hdfsReader) Close(ctx context.Context) (err error) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	if h.scanner != nil {
+		err = h.scanner.Close(ctx)
+		h.scanner = nil
+	}
+	if h.client != nil {
+		if cerr := h.client.Close(); err == nil {
+			err = cerr
+		}
+		h.client = nil
+	}
+	return
 }
@@ -2,11 +2,12 @@ package hdfs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
-	"github.com/colinmarc/hdfs"
+	"github.com/colinmarc/hdfs/v2"
 
 	"github.com/benthosdev/benthos/v4/internal/component/output"
 	"github.com/benthosdev/benthos/v4/public/service"
@@ -17,6 +18,7 @@ const (
 	oFieldUser      = "user"
 	oFieldDirectory = "directory"
 	oFieldPath      = "path"
+	oFieldAppend    = "append"
 	oFieldBatching  = "batching"
 )
 
@@ -25,10 +27,12 @@ func outputSpec() *service.ConfigSpec {
 		Stable().
 		Categories("Services").
 		Summary(`Sends message parts as files to a HDFS directory.`).
-		Description(output.Description(true, false, `Each file is written with the path specified with the 'path' field, in order to have a different path for each object you should use function interpolations described [here](/docs/configuration/interpolation#bloblang-queries).`)).
+		Description(output.Description(true, false, `Each file is written with the path specified with the 'path' field, in order to have a different path for each object you should use function interpolations described [here](/docs/configuration/interpolation#bloblang-queries).
+
+When the `+"`append`"+` field is set to `+"`true`"+` messages written to a path that already exists are appended to the existing file, otherwise writing to an existing path results in an error.`)).
 		Fields(
 			service.NewStringListField(oFieldHosts).
-				Description("A list of target host addresses to connect to.").
+				Description(hostsDescription).
 				Example("localhost:9000"),
 			service.NewStringField(oFieldUser).
 				Description("A user ID to connect as.").
@@ -38,6 +42,13 @@ func outputSpec() *service.ConfigSpec {
 			service.NewInterpolatedStringField(oFieldPath).
 				Description("The path to upload messages as, interpolation functions should be used in order to generate unique file paths.").
 				Default(`${!count("files")}-${!timestamp_unix_nano()}.txt`),
+			service.NewBoolField(oFieldAppend).
+				Description("Whether to append messages to files that already exist rather than failing.").
+				Default(false).
+				Version("4.24.0"),
+		).
+		Fields(clientFields()...).
+		Fields(
 			service.NewOutputMaxInFlightField(),
 			service.NewBatchPolicyField(oFieldBatching),
 		)
@@ -52,10 +63,7 @@ func init() {
 				log: mgr.Logger(),
 			}
 			out = w
-			if w.hosts, err = conf.FieldStringList(oFieldHosts); err != nil {
-				return
-			}
-			if w.user, err = conf.FieldString(oFieldUser); err != nil {
+			if w.clientConf, err = clientConfigFromParsed(conf, oFieldHosts, oFieldUser); err != nil {
 				return
 			}
 			if w.directory, err = conf.FieldInterpolatedString(oFieldDirectory); err != nil {
@@ -64,6 +72,9 @@ func init() {
 			if w.path, err = conf.FieldInterpolatedString(oFieldPath); err != nil {
 				return
 			}
+			if w.append, err = conf.FieldBool(oFieldAppend); err != nil {
+				return
+			}
 			if pol, err = conf.FieldBatchPolicy(oFieldBatching); err != nil {
 				return
 			}
@@ -78,10 +89,10 @@ func init() {
 }
 
 type hdfsWriter struct {
-	hosts     []string
-	user      string
-	directory *service.InterpolatedString
-	path      *service.InterpolatedString
+	clientConf clientConfig
+	directory  *service.InterpolatedString
+	path       *service.InterpolatedString
+	append     bool
 
 	client *hdfs.Client
 	log    *service.Logger
@@ -92,10 +103,7 @@ func (h *hdfsWriter) Connect(ctx context.Context) error {
 		return nil
 	}
 
-	client, err := hdfs.NewClient(hdfs.ClientOptions{
-		Addresses: h.hosts,
-		User:      h.user,
-	})
+	client, err := h.clientConf.newClient()
 	if err != nil {
 		return err
 	}
@@ -126,24 +134,40 @@ func (h *hdfsWriter) WriteBatch(ctx context.Context, batch service.MessageBatch)
 			return err
 		}
 
-		fw, err := h.client.Create(filePath)
+		mBytes, err := m.AsBytes()
 		if err != nil {
 			return err
 		}
 
-		mBytes, err := m.AsBytes()
+		fw, err := h.openFile(filePath)
 		if err != nil {
 			return err
 		}
 
 		if _, err := fw.Write(mBytes); err != nil {
+			fw.Close()
 			return err
 		}
-		fw.Close()
-		return nil
+		return fw.Close()
 	})
 }
 
+func (h *hdfsWriter) openFile(filePath string) (*hdfs.FileWriter, error) {
+	if !h.append {
+		return h.client.Create(filePath)
+	}
+	fw, err := h.client.Append(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return h.client.Create(filePath)
+	}
+	return fw, err
+}
+
 func (h *hdfsWriter) Close(context.Context) error {
-	return nil
+	if h.client == nil {
+		return nil
+	}
+	err := h.client.Close()
+	h.client = nil
+	return err
 }
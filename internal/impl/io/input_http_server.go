@@ -51,6 +51,11 @@ const (
 	hsiFieldCORS                    = "cors"
 	hsiFieldCORSEnabled             = "enabled"
 	hsiFieldCORSAllowedOrigins      = "allowed_origins"
+	hsiFieldCORSAllowedMethods      = "allowed_methods"
+	hsiFieldCORSAllowedHeaders      = "allowed_headers"
+	hsiFieldCORSExposedHeaders      = "exposed_headers"
+	hsiFieldCORSAllowCredentials    = "allow_credentials"
+	hsiFieldCORSMaxAge              = "max_age"
 	hsiFieldResponse                = "sync_response"
 	hsiFieldResponseStatus          = "status"
 	hsiFieldResponseHeaders         = "headers"
@@ -69,6 +74,7 @@ type hsiConfig struct {
 	CertFile           string
 	KeyFile            string
 	CORS               httpserver.CORSConfig
+	Signature          *hsiSignatureVerifier
 	Response           hsiResponseConfig
 }
 
@@ -123,6 +129,9 @@ func hsiConfigFromParsed(pConf *service.ParsedConfig) (conf hsiConfig, err error
 	if conf.CORS, err = corsConfigFromParsed(pConf.Namespace(hsiFieldCORS)); err != nil {
 		return
 	}
+	if conf.Signature, err = hsiSignatureVerifierFromParsed(pConf.Namespace(hsiFieldSignature)); err != nil {
+		return
+	}
 	if conf.Response, err = hsiResponseConfigFromParsed(pConf.Namespace(hsiFieldResponse)); err != nil {
 		return
 	}
@@ -136,6 +145,21 @@ func corsConfigFromParsed(pConf *service.ParsedConfig) (conf httpserver.CORSConf
 	if conf.AllowedOrigins, err = pConf.FieldStringList(hsiFieldCORSAllowedOrigins); err != nil {
 		return
 	}
+	if conf.AllowedMethods, err = pConf.FieldStringList(hsiFieldCORSAllowedMethods); err != nil {
+		return
+	}
+	if conf.AllowedHeaders, err = pConf.FieldStringList(hsiFieldCORSAllowedHeaders); err != nil {
+		return
+	}
+	if conf.ExposedHeaders, err = pConf.FieldStringList(hsiFieldCORSExposedHeaders); err != nil {
+		return
+	}
+	if conf.AllowCredentials, err = pConf.FieldBool(hsiFieldCORSAllowCredentials); err != nil {
+		return
+	}
+	if conf.MaxAge, err = pConf.FieldInt(hsiFieldCORSMaxAge); err != nil {
+		return
+	}
 	return
 }
 
@@ -251,6 +275,7 @@ You can access these metadata fields using [function interpolation](/docs/config
 				Advanced().
 				Default(""),
 			service.NewInternalField(corsSpec),
+			hsiSignatureFieldSpec(),
 			service.NewObjectField(hsiFieldResponse,
 				service.NewInterpolatedStringField(hsiFieldResponseStatus).
 					Description("Specify the status code to return with synchronous responses. This is a string value, which allows you to customize it based on resulting payloads and their metadata.").
@@ -505,6 +530,21 @@ func (h *httpServerInput) postHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if h.conf.Signature != nil {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			h.log.Warnf("Request read failed: %v\n", err)
+			return
+		}
+		if err := h.conf.Signature.verify(r.Header, body); err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			h.log.Debugf("Request rejected: %v\n", err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
 	msg, err := h.extractMessageFromRequest(r)
 	if err != nil {
 		http.Error(w, "Bad request", http.StatusBadRequest)
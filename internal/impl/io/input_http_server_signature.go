@@ -0,0 +1,252 @@
+package io
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	hsiFieldSignature          = "signature"
+	hsiFieldSignatureScheme    = "scheme"
+	hsiFieldSignatureSecret    = "secret"
+	hsiFieldSignatureHeader    = "header"
+	hsiFieldSignatureAlgorithm = "algorithm"
+	hsiFieldSignatureEncoding  = "encoding"
+	hsiFieldSignaturePrefix    = "prefix"
+	hsiFieldSignatureTolerance = "tolerance"
+)
+
+const (
+	hsiSignatureSchemeNone   = "none"
+	hsiSignatureSchemeGitHub = "github"
+	hsiSignatureSchemeStripe = "stripe"
+	hsiSignatureSchemeSlack  = "slack"
+	hsiSignatureSchemeHMAC   = "hmac"
+)
+
+var errHsiInvalidSignature = errors.New("invalid signature")
+
+func hsiSignatureFieldSpec() *service.ConfigField {
+	return service.NewObjectField(hsiFieldSignature,
+		service.NewStringAnnotatedEnumField(hsiFieldSignatureScheme, map[string]string{
+			hsiSignatureSchemeNone:   "Requests are not validated.",
+			hsiSignatureSchemeGitHub: "Validates the `X-Hub-Signature-256` header of [GitHub webhooks](https://docs.github.com/en/webhooks/using-webhooks/validating-webhook-deliveries).",
+			hsiSignatureSchemeStripe: "Validates the `Stripe-Signature` header of [Stripe webhooks](https://stripe.com/docs/webhooks#verify-manually), including the age of its timestamp.",
+			hsiSignatureSchemeSlack:  "Validates the `X-Slack-Signature` and `X-Slack-Request-Timestamp` headers of [Slack requests](https://api.slack.com/authentication/verifying-requests-from-slack), including the age of the timestamp.",
+			hsiSignatureSchemeHMAC:   "Validates a generic HMAC signature of the request body contained within the header specified by `header`, using the `algorithm`, `encoding` and `prefix` fields.",
+		}).
+			Description("The signature scheme to validate requests with.").
+			Default(hsiSignatureSchemeNone),
+		service.NewStringField(hsiFieldSignatureSecret).
+			Description("The secret used in order to sign requests.").
+			Secret().
+			Default(""),
+		service.NewStringField(hsiFieldSignatureHeader).
+			Description("The header containing the signature, used by the `hmac` scheme.").
+			Example("X-Signature").
+			Default(""),
+		service.NewStringEnumField(hsiFieldSignatureAlgorithm, "sha1", "sha256", "sha512").
+			Description("The hashing algorithm of the signature, used by the `hmac` scheme.").
+			Default("sha256"),
+		service.NewStringEnumField(hsiFieldSignatureEncoding, "hex", "base64").
+			Description("The encoding of the signature, used by the `hmac` scheme.").
+			Default("hex"),
+		service.NewStringField(hsiFieldSignaturePrefix).
+			Description("An optional prefix of the signature header value to remove before the signature is decoded, used by the `hmac` scheme.").
+			Example("sha256=").
+			Default(""),
+		service.NewDurationField(hsiFieldSignatureTolerance).
+			Description("The maximum age of a signed timestamp before a request is rejected, used by the `stripe` and `slack` schemes in order to prevent replay attacks.").
+			Default("5m"),
+	).
+		Description("Validates the signatures of webhook requests to the `path` endpoint, requests with a missing or invalid signature are rejected with a 401 status code before they enter the pipeline. Signatures are not validated for websocket connections.").
+		Version("4.24.0").
+		Advanced()
+}
+
+type hsiSignatureVerifier struct {
+	scheme    string
+	secret    []byte
+	header    string
+	newHash   func() hash.Hash
+	encoding  string
+	prefix    string
+	tolerance time.Duration
+	nowFn     func() time.Time
+}
+
+// hsiSignatureVerifierFromParsed returns a signature verifier, or nil if
+// signature validation is disabled.
+func hsiSignatureVerifierFromParsed(pConf *service.ParsedConfig) (*hsiSignatureVerifier, error) {
+	v := &hsiSignatureVerifier{
+		newHash: sha256.New,
+		nowFn:   time.Now,
+	}
+
+	var err error
+	if v.scheme, err = pConf.FieldString(hsiFieldSignatureScheme); err != nil {
+		return nil, err
+	}
+	if v.scheme == hsiSignatureSchemeNone || v.scheme == "" {
+		return nil, nil
+	}
+
+	var secret string
+	if secret, err = pConf.FieldString(hsiFieldSignatureSecret); err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("a secret is required for the signature scheme %v", v.scheme)
+	}
+	v.secret = []byte(secret)
+
+	if v.tolerance, err = pConf.FieldDuration(hsiFieldSignatureTolerance); err != nil {
+		return nil, err
+	}
+
+	switch v.scheme {
+	case hsiSignatureSchemeGitHub, hsiSignatureSchemeStripe, hsiSignatureSchemeSlack:
+	case hsiSignatureSchemeHMAC:
+		if v.header, err = pConf.FieldString(hsiFieldSignatureHeader); err != nil {
+			return nil, err
+		}
+		if v.header == "" {
+			return nil, errors.New("a header is required for the signature scheme hmac")
+		}
+		var algorithm string
+		if algorithm, err = pConf.FieldString(hsiFieldSignatureAlgorithm); err != nil {
+			return nil, err
+		}
+		switch algorithm {
+		case "sha1":
+			v.newHash = sha1.New
+		case "sha256":
+			v.newHash = sha256.New
+		case "sha512":
+			v.newHash = sha512.New
+		default:
+			return nil, fmt.Errorf("signature algorithm not recognised: %v", algorithm)
+		}
+		if v.encoding, err = pConf.FieldString(hsiFieldSignatureEncoding); err != nil {
+			return nil, err
+		}
+		if v.encoding != "hex" && v.encoding != "base64" {
+			return nil, fmt.Errorf("signature encoding not recognised: %v", v.encoding)
+		}
+		if v.prefix, err = pConf.FieldString(hsiFieldSignaturePrefix); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("signature scheme not recognised: %v", v.scheme)
+	}
+	return v, nil
+}
+
+func (v *hsiSignatureVerifier) mac(payloads ...[]byte) []byte {
+	h := hmac.New(v.newHash, v.secret)
+	for _, p := range payloads {
+		_, _ = h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+func (v *hsiSignatureVerifier) checkTimestamp(tsStr string) error {
+	ts, err := strconv.ParseInt(tsStr, 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse signature timestamp: %w", err)
+	}
+	age := v.nowFn().Sub(time.Unix(ts, 0))
+	if age < 0 {
+		age = -age
+	}
+	if age > v.tolerance {
+		return fmt.Errorf("signature timestamp outside of tolerance: %v", age)
+	}
+	return nil
+}
+
+// matchesHex returns true if any of the provided hex encoded signatures match
+// the expected signature.
+func matchesHex(expected []byte, sigs ...string) bool {
+	for _, s := range sigs {
+		sig, err := hex.DecodeString(s)
+		if err != nil {
+			continue
+		}
+		if hmac.Equal(expected, sig) {
+			return true
+		}
+	}
+	return false
+}
+
+// verify checks the signature of a request against its body, returning an
+// error if the signature is missing or invalid.
+func (v *hsiSignatureVerifier) verify(header http.Header, body []byte) error {
+	switch v.scheme {
+	case hsiSignatureSchemeGitHub:
+		sig, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+		if !ok || !matchesHex(v.mac(body), sig) {
+			return errHsiInvalidSignature
+		}
+	case hsiSignatureSchemeStripe:
+		var ts string
+		var sigs []string
+		for _, kv := range strings.Split(header.Get("Stripe-Signature"), ",") {
+			k, val, _ := strings.Cut(strings.TrimSpace(kv), "=")
+			switch k {
+			case "t":
+				ts = val
+			case "v1":
+				sigs = append(sigs, val)
+			}
+		}
+		if ts == "" || len(sigs) == 0 {
+			return errHsiInvalidSignature
+		}
+		if !matchesHex(v.mac([]byte(ts), []byte("."), body), sigs...) {
+			return errHsiInvalidSignature
+		}
+		return v.checkTimestamp(ts)
+	case hsiSignatureSchemeSlack:
+		ts := header.Get("X-Slack-Request-Timestamp")
+		sig, ok := strings.CutPrefix(header.Get("X-Slack-Signature"), "v0=")
+		if ts == "" || !ok {
+			return errHsiInvalidSignature
+		}
+		if !matchesHex(v.mac([]byte("v0:"+ts+":"), body), sig) {
+			return errHsiInvalidSignature
+		}
+		return v.checkTimestamp(ts)
+	case hsiSignatureSchemeHMAC:
+		sigStr, ok := strings.CutPrefix(header.Get(v.header), v.prefix)
+		if !ok || sigStr == "" {
+			return errHsiInvalidSignature
+		}
+		expected := v.mac(body)
+		if v.encoding == "hex" {
+			if !matchesHex(expected, sigStr) {
+				return errHsiInvalidSignature
+			}
+			return nil
+		}
+		sig, err := base64.StdEncoding.DecodeString(sigStr)
+		if err != nil || !hmac.Equal(expected, sig) {
+			return errHsiInvalidSignature
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package io_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+)
+
+func testHMAC(newHash func() hash.Hash, secret string, payloads ...string) []byte {
+	h := hmac.New(newHash, []byte(secret))
+	for _, p := range payloads {
+		_, _ = h.Write([]byte(p))
+	}
+	return h.Sum(nil)
+}
+
+func TestHTTPServerSignatureValidation(t *testing.T) {
+	body := `{"hello":"world"}`
+	nowStr := strconv.FormatInt(time.Now().Unix(), 10)
+	staleStr := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name    string
+		conf    string
+		headers map[string]string
+		valid   bool
+	}{
+		{
+			name: "github valid",
+			conf: `scheme: github`,
+			headers: map[string]string{
+				"X-Hub-Signature-256": "sha256=" + hex.EncodeToString(testHMAC(sha256.New, "foo", body)),
+			},
+			valid: true,
+		},
+		{
+			name: "github wrong secret",
+			conf: `scheme: github`,
+			headers: map[string]string{
+				"X-Hub-Signature-256": "sha256=" + hex.EncodeToString(testHMAC(sha256.New, "bar", body)),
+			},
+		},
+		{
+			name: "github missing",
+			conf: `scheme: github`,
+		},
+		{
+			name: "stripe valid",
+			conf: `scheme: stripe`,
+			headers: map[string]string{
+				"Stripe-Signature": fmt.Sprintf("t=%v,v1=%v,v1=deadbeef", nowStr, hex.EncodeToString(testHMAC(sha256.New, "foo", nowStr, ".", body))),
+			},
+			valid: true,
+		},
+		{
+			name: "stripe stale",
+			conf: `scheme: stripe`,
+			headers: map[string]string{
+				"Stripe-Signature": fmt.Sprintf("t=%v,v1=%v", staleStr, hex.EncodeToString(testHMAC(sha256.New, "foo", staleStr, ".", body))),
+			},
+		},
+		{
+			name: "slack valid",
+			conf: `scheme: slack`,
+			headers: map[string]string{
+				"X-Slack-Request-Timestamp": nowStr,
+				"X-Slack-Signature":         "v0=" + hex.EncodeToString(testHMAC(sha256.New, "foo", "v0:", nowStr, ":", body)),
+			},
+			valid: true,
+		},
+		{
+			name: "slack tampered timestamp",
+			conf: `scheme: slack`,
+			headers: map[string]string{
+				"X-Slack-Request-Timestamp": nowStr,
+				"X-Slack-Signature":         "v0=" + hex.EncodeToString(testHMAC(sha256.New, "foo", "v0:", staleStr, ":", body)),
+			},
+		},
+		{
+			name: "hmac base64 valid",
+			conf: `
+scheme: hmac
+header: X-Signature
+algorithm: sha1
+encoding: base64
+prefix: 'sha1='
+`,
+			headers: map[string]string{
+				"X-Signature": "sha1=" + base64.StdEncoding.EncodeToString(testHMAC(sha1.New, "foo", body)),
+			},
+			valid: true,
+		},
+		{
+			name: "hmac missing prefix",
+			conf: `
+scheme: hmac
+header: X-Signature
+algorithm: sha1
+encoding: base64
+prefix: 'sha1='
+`,
+			headers: map[string]string{
+				"X-Signature": base64.StdEncoding.EncodeToString(testHMAC(sha1.New, "foo", body)),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+			defer done()
+
+			reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+			mgr, err := manager.New(manager.NewResourceConfig(), manager.OptSetAPIReg(reg))
+			require.NoError(t, err)
+
+			sigConf := strings.ReplaceAll(strings.TrimSpace(test.conf), "\n", "\n    ")
+			conf := parseYAMLInputConf(t, `
+http_server:
+  path: /testpost
+  signature:
+    secret: foo
+    %v
+`, sigConf)
+
+			h, err := mgr.NewInput(conf)
+			require.NoError(t, err)
+			defer func() {
+				h.TriggerStopConsuming()
+				assert.NoError(t, h.WaitForClose(tCtx))
+			}()
+
+			server := httptest.NewServer(reg.mut)
+			defer server.Close()
+
+			if test.valid {
+				go func() {
+					select {
+					case tran := <-h.TransactionChan():
+						assert.Equal(t, body, string(tran.Payload.Get(0).AsBytes()))
+						require.NoError(t, tran.Ack(tCtx, nil))
+					case <-tCtx.Done():
+					}
+				}()
+			}
+
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/testpost", bytes.NewReader([]byte(body)))
+			require.NoError(t, err)
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			res.Body.Close()
+
+			if test.valid {
+				assert.Equal(t, http.StatusOK, res.StatusCode)
+			} else {
+				assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestHTTPServerSignatureConfigErrors(t *testing.T) {
+	conf := parseYAMLInputConf(t, `
+http_server:
+  path: /testpost
+  signature:
+    scheme: github
+`)
+	_, err := mock.NewManager().NewInput(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "a secret is required for the signature scheme github")
+
+	conf = parseYAMLInputConf(t, `
+http_server:
+  path: /testpost
+  signature:
+    scheme: hmac
+    secret: foo
+`)
+	_, err = mock.NewManager().NewInput(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "a header is required for the signature scheme hmac")
+}
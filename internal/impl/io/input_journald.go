@@ -0,0 +1,462 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/checkpoint"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	jdiFieldUnits           = "units"
+	jdiFieldPriority        = "priority"
+	jdiFieldMatches         = "matches"
+	jdiFieldDirectory       = "directory"
+	jdiFieldStartFromOldest = "start_from_oldest"
+	jdiFieldRaw             = "raw"
+	jdiFieldCache           = "cache"
+	jdiFieldCacheKey        = "cache_key"
+	jdiFieldJournalctlPath  = "journalctl_path"
+)
+
+func journaldInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Local").
+		Version("4.24.0").
+		Summary("Reads entries of the systemd journal of the host.").
+		Description(`
+Entries are read by following the output of the `+"`journalctl`"+` command, which must be installed on the host (or container) running Benthos and be able to read the journal, which usually requires membership of the `+"`systemd-journal`"+` group.
+
+### Cursor Persistence
+
+When a `+"`cache`"+` is configured the cursor of the newest entry that has been delivered is stored within it, and when the input starts it resumes from the entry following the stored cursor. In order for this to survive restarts a persisted cache such as `+"`file`"+` or `+"`redis`"+` should be used. Without a cursor the input starts from new entries, or from the oldest entry of the journal when `+"`start_from_oldest`"+` is `+"`true`"+`.
+
+### Structured Entries
+
+Each entry is consumed as a JSON object where the name of each journal field is lowercased and stripped of leading underscores, the `+"`priority`"+` is a number and the `+"`timestamp`"+` is the realtime timestamp of the entry formatted as RFC 3339. For example, the fields `+"`MESSAGE`"+`, `+"`PRIORITY`"+` and `+"`_SYSTEMD_UNIT`"+` become `+"`message`"+`, `+"`priority`"+` and `+"`systemd_unit`"+`. Trusted fields (those added by the journal, prefixed with an underscore) take precedence over user provided fields of the same name, and address fields such as `+"`__CURSOR`"+` are omitted.
+
+Setting `+"`raw`"+` to `+"`true`"+` instead emits entries with the fields exactly as they are printed by `+"`journalctl --output=json`"+`.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- journald_cursor
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).`).
+		Fields(
+			service.NewStringListField(jdiFieldUnits).
+				Description("A list of systemd units to read the entries of. When empty the entries of all units are read.").
+				Default([]any{}).
+				Example([]any{"nginx.service", "sshd.service"}),
+			service.NewStringField(jdiFieldPriority).
+				Description("An optional priority, or range of priorities, to filter entries by, which is either a name or number such as `warning` or `4`, or a range such as `err..alert`. When a single priority is given all entries of that priority or higher are read.").
+				Default("").
+				Example("warning").
+				Example("0..3"),
+			service.NewStringListField(jdiFieldMatches).
+				Description("A list of additional [journal matches](https://www.freedesktop.org/software/systemd/man/journalctl.html#Description) of the form `FIELD=value` to filter entries by.").
+				Default([]any{}).
+				Example([]any{"_TRANSPORT=kernel"}).
+				Advanced(),
+			service.NewStringField(jdiFieldDirectory).
+				Description("An optional directory of journal files to read instead of the journal of the host, such as the journal of the host mounted within a container.").
+				Default("").
+				Example("/var/log/journal").
+				Advanced(),
+			service.NewBoolField(jdiFieldStartFromOldest).
+				Description("Whether to read from the oldest entry of the journal when no cursor is stored, rather than from new entries.").
+				Default(false),
+			service.NewBoolField(jdiFieldRaw).
+				Description("Whether to emit entries with the fields printed by `journalctl` rather than mapped into a structured object.").
+				Default(false).
+				Advanced(),
+			service.NewStringField(jdiFieldCache).
+				Description("An optional [cache resource](/docs/components/caches/about) in which to store the cursor of the newest entry that has been delivered, allowing the input to resume from where it left off after a restart.").
+				Default(""),
+			service.NewStringField(jdiFieldCacheKey).
+				Description("The key under which the cursor is stored within the `cache`.").
+				Default("journald_cursor").
+				Advanced(),
+			service.NewStringField(jdiFieldJournalctlPath).
+				Description("The path of the `journalctl` command.").
+				Default("journalctl").
+				Advanced(),
+		).
+		Example(
+			"Service Errors",
+			"Here we read the errors of a service, resuming from where we left off after a restart.",
+			`
+input:
+  journald:
+    units: [ nginx.service ]
+    priority: err
+    cache: cursors
+
+cache_resources:
+  - label: cursors
+    file:
+      directory: /var/lib/benthos/cursors
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterInput(
+		"journald", journaldInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			r, err := newJournaldReader(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacks(r), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// journalEntry is an entry read from the journal along with its cursor.
+type journalEntry struct {
+	cursor string
+	msg    *service.Message
+}
+
+type journaldReader struct {
+	log *service.Logger
+	mgr *service.Resources
+
+	path            string
+	units           []string
+	priority        string
+	matches         []string
+	directory       string
+	startFromOldest bool
+	raw             bool
+	cache           string
+	cacheKey        string
+
+	checkpointer *checkpoint.Capped[string]
+
+	connMut    sync.Mutex
+	lastCursor string
+	entryChan  chan journalEntry
+	errChan    chan error
+	cancelCmd  func()
+}
+
+func newJournaldReader(conf *service.ParsedConfig, mgr *service.Resources) (*journaldReader, error) {
+	r := &journaldReader{
+		log:          mgr.Logger(),
+		mgr:          mgr,
+		checkpointer: checkpoint.NewCapped[string](1024),
+	}
+
+	var err error
+	if r.units, err = conf.FieldStringList(jdiFieldUnits); err != nil {
+		return nil, err
+	}
+	if r.priority, err = conf.FieldString(jdiFieldPriority); err != nil {
+		return nil, err
+	}
+	if r.matches, err = conf.FieldStringList(jdiFieldMatches); err != nil {
+		return nil, err
+	}
+	for _, m := range r.matches {
+		if m != "+" && !strings.Contains(m, "=") {
+			return nil, fmt.Errorf("match '%v' is not of the form FIELD=value", m)
+		}
+	}
+	if r.directory, err = conf.FieldString(jdiFieldDirectory); err != nil {
+		return nil, err
+	}
+	if r.startFromOldest, err = conf.FieldBool(jdiFieldStartFromOldest); err != nil {
+		return nil, err
+	}
+	if r.raw, err = conf.FieldBool(jdiFieldRaw); err != nil {
+		return nil, err
+	}
+	if r.cache, err = conf.FieldString(jdiFieldCache); err != nil {
+		return nil, err
+	}
+	if r.cacheKey, err = conf.FieldString(jdiFieldCacheKey); err != nil {
+		return nil, err
+	}
+	if r.path, err = conf.FieldString(jdiFieldJournalctlPath); err != nil {
+		return nil, err
+	}
+	if r.cache != "" && !mgr.HasCache(r.cache) {
+		return nil, fmt.Errorf("cache resource '%v' was not found", r.cache)
+	}
+	return r, nil
+}
+
+// args returns the arguments of journalctl for following the journal after a
+// cursor, or from the start position when the cursor is empty.
+func (r *journaldReader) args(cursor string) []string {
+	args := []string{"--output=json", "--follow", "--no-pager"}
+	switch {
+	case cursor != "":
+		args = append(args, "--after-cursor="+cursor)
+	case r.startFromOldest:
+		args = append(args, "--lines=all")
+	default:
+		args = append(args, "--lines=0")
+	}
+	for _, u := range r.units {
+		args = append(args, "--unit="+u)
+	}
+	if r.priority != "" {
+		args = append(args, "--priority="+r.priority)
+	}
+	if r.directory != "" {
+		args = append(args, "--directory="+r.directory)
+	}
+	return append(args, r.matches...)
+}
+
+func (r *journaldReader) storedCursor(ctx context.Context) (cursor string, err error) {
+	if r.cache == "" {
+		return "", nil
+	}
+	if cerr := r.mgr.AccessCache(ctx, r.cache, func(c service.Cache) {
+		var b []byte
+		if b, err = c.Get(ctx, r.cacheKey); errors.Is(err, service.ErrKeyNotFound) {
+			err = nil
+		}
+		cursor = string(b)
+	}); cerr != nil {
+		return "", cerr
+	}
+	return
+}
+
+func (r *journaldReader) Connect(ctx context.Context) error {
+	r.connMut.Lock()
+	defer r.connMut.Unlock()
+	if r.entryChan != nil {
+		return nil
+	}
+
+	// When journalctl is restarted we resume from the last entry read,
+	// otherwise from the last entry delivered.
+	cursor := r.lastCursor
+	if cursor == "" {
+		var err error
+		if cursor, err = r.storedCursor(ctx); err != nil {
+			return fmt.Errorf("failed to obtain stored cursor: %w", err)
+		}
+	}
+
+	cmdCtx, cancelCmd := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(cmdCtx, r.path, r.args(cursor)...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancelCmd()
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		cancelCmd()
+		return err
+	}
+
+	entryChan := make(chan journalEntry)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(entryChan)
+
+		reader := bufio.NewReader(stdout)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				entry, perr := r.parseEntry(line)
+				if perr != nil {
+					r.log.Errorf("Failed to parse journal entry: %v", perr)
+				} else {
+					select {
+					case entryChan <- entry:
+					case <-cmdCtx.Done():
+						_ = cmd.Wait()
+						return
+					}
+				}
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					r.log.Errorf("Failed to read journal: %v", err)
+				}
+				break
+			}
+		}
+
+		if err := cmd.Wait(); err != nil && cmdCtx.Err() == nil {
+			errChan <- fmt.Errorf("journalctl exited: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+		}
+	}()
+
+	r.entryChan = entryChan
+	r.errChan = errChan
+	r.cancelCmd = cancelCmd
+	return nil
+}
+
+// parseEntry parses a line of the JSON output of journalctl into an entry.
+func (r *journaldReader) parseEntry(line []byte) (journalEntry, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return journalEntry{}, err
+	}
+
+	cursor, _ := fields["__CURSOR"].(string)
+
+	var msg *service.Message
+	if r.raw {
+		msg = service.NewMessage(line)
+	} else {
+		msg = service.NewMessage(nil)
+		msg.SetStructuredMut(structuredJournalEntry(fields))
+	}
+	msg.MetaSetMut("journald_cursor", cursor)
+	return journalEntry{cursor: cursor, msg: msg}, nil
+}
+
+// journalValue converts the value of a journal field, where values that
+// aren't valid UTF-8 are arrays of bytes and fields with multiple values are
+// arrays of those values.
+func journalValue(v any) any {
+	arr, ok := v.([]any)
+	if !ok {
+		return v
+	}
+	b := make([]byte, 0, len(arr))
+	for _, e := range arr {
+		n, ok := e.(float64)
+		if !ok {
+			vals := make([]any, len(arr))
+			for i, e := range arr {
+				vals[i] = journalValue(e)
+			}
+			return vals
+		}
+		b = append(b, byte(n))
+	}
+	return string(b)
+}
+
+// structuredJournalEntry maps the fields of a journal entry into an object of
+// lowercased field names.
+func structuredJournalEntry(fields map[string]any) map[string]any {
+	obj := make(map[string]any, len(fields))
+	trusted := map[string]struct{}{}
+	for k, v := range fields {
+		switch {
+		case k == "__REALTIME_TIMESTAMP":
+			if s, ok := v.(string); ok {
+				if us, err := strconv.ParseInt(s, 10, 64); err == nil {
+					obj["timestamp"] = time.UnixMicro(us).UTC().Format(time.RFC3339Nano)
+				}
+			}
+			continue
+		case strings.HasPrefix(k, "__"):
+			continue
+		}
+
+		name := strings.ToLower(strings.TrimPrefix(k, "_"))
+		if strings.HasPrefix(k, "_") {
+			trusted[name] = struct{}{}
+		} else if _, exists := trusted[name]; exists {
+			continue
+		}
+
+		value := journalValue(v)
+		if name == "priority" {
+			if s, ok := value.(string); ok {
+				if p, err := strconv.Atoi(s); err == nil {
+					value = p
+				}
+			}
+		}
+		obj[name] = value
+	}
+	return obj
+}
+
+func (r *journaldReader) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	r.connMut.Lock()
+	entryChan, errChan := r.entryChan, r.errChan
+	r.connMut.Unlock()
+	if entryChan == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	var entry journalEntry
+	var open bool
+	select {
+	case entry, open = <-entryChan:
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+	if !open {
+		r.connMut.Lock()
+		r.cancelCmd()
+		r.entryChan, r.errChan = nil, nil
+		r.connMut.Unlock()
+
+		select {
+		case err := <-errChan:
+			r.log.Errorf("%v", err)
+		default:
+		}
+		return nil, nil, service.ErrNotConnected
+	}
+
+	r.connMut.Lock()
+	r.lastCursor = entry.cursor
+	r.connMut.Unlock()
+
+	release, err := r.checkpointer.Track(ctx, entry.cursor, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entry.msg, func(ctx context.Context, err error) error {
+		highest := release()
+		if highest == nil || r.cache == "" {
+			return nil
+		}
+		var setErr error
+		if cerr := r.mgr.AccessCache(ctx, r.cache, func(c service.Cache) {
+			setErr = c.Set(ctx, r.cacheKey, []byte(*highest), nil)
+		}); cerr != nil {
+			return cerr
+		}
+		return setErr
+	}, nil
+}
+
+func (r *journaldReader) Close(ctx context.Context) error {
+	r.connMut.Lock()
+	defer r.connMut.Unlock()
+	if r.cancelCmd != nil {
+		r.cancelCmd()
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package io
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestJournaldStructuredEntry(t *testing.T) {
+	assert.Equal(t, map[string]any{
+		"message":           "hi",
+		"priority":          3,
+		"timestamp":         "2023-11-14T22:13:20.5Z",
+		"systemd_unit":      "nginx.service",
+		"pid":               "12",
+		"syslog_identifier": "nginx",
+		"tags":              []any{"a", "b"},
+	}, structuredJournalEntry(map[string]any{
+		"__CURSOR":              "c1",
+		"__MONOTONIC_TIMESTAMP": "123",
+		"__REALTIME_TIMESTAMP":  "1700000000500000",
+		"MESSAGE":               []any{104.0, 105.0},
+		"PRIORITY":              "3",
+		"_SYSTEMD_UNIT":         "nginx.service",
+		"_PID":                  "12",
+		"PID":                   "13",
+		"SYSLOG_IDENTIFIER":     "nginx",
+		"TAGS":                  []any{"a", "b"},
+	}))
+}
+
+func fakeJournalctl(t *testing.T, lines ...string) (scriptPath, argsPath string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell")
+	}
+
+	dir := t.TempDir()
+	scriptPath = filepath.Join(dir, "journalctl")
+	argsPath = filepath.Join(dir, "args")
+
+	script := fmt.Sprintf("#!/bin/sh\necho \"$@\" >> %v\ncat <<'EOF'\n%v\nEOF\n", argsPath, strings.Join(lines, "\n"))
+	require.NoError(t, os.WriteFile(scriptPath, []byte(script), 0o755))
+	return
+}
+
+func TestJournaldInput(t *testing.T) {
+	scriptPath, argsPath := fakeJournalctl(t,
+		`{"__CURSOR":"c1","__REALTIME_TIMESTAMP":"1700000000000000","MESSAGE":"hello","PRIORITY":"6","_SYSTEMD_UNIT":"nginx.service"}`,
+		`{"__CURSOR":"c2","__REALTIME_TIMESTAMP":"1700000001000000","MESSAGE":"world","PRIORITY":"3","_SYSTEMD_UNIT":"nginx.service"}`,
+	)
+
+	res := service.MockResources(service.MockResourcesOptAddCache("cursors"))
+	newReader := func() *journaldReader {
+		conf, err := journaldInputSpec().ParseYAML(fmt.Sprintf(`
+units: [ nginx.service ]
+priority: warning
+cache: cursors
+journalctl_path: %v
+`, scriptPath), nil)
+		require.NoError(t, err)
+
+		r, err := newJournaldReader(conf, res)
+		require.NoError(t, err)
+		return r
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	r := newReader()
+	require.NoError(t, r.Connect(ctx))
+
+	var messages []string
+	for _, cursor := range []string{"c1", "c2"} {
+		msg, ackFn, err := r.Read(ctx)
+		require.NoError(t, err)
+
+		v, _ := msg.MetaGet("journald_cursor")
+		assert.Equal(t, cursor, v)
+
+		s, err := msg.AsStructured()
+		require.NoError(t, err)
+		messages = append(messages, s.(map[string]any)["message"].(string))
+
+		require.NoError(t, ackFn(ctx, nil))
+	}
+	assert.Equal(t, []string{"hello", "world"}, messages)
+
+	_, _, err := r.Read(ctx)
+	assert.ErrorIs(t, err, service.ErrNotConnected)
+	require.NoError(t, r.Close(ctx))
+
+	// A new reader resumes from the stored cursor.
+	r = newReader()
+	require.NoError(t, r.Connect(ctx))
+	_, _, err = r.Read(ctx)
+	require.NoError(t, err)
+	require.NoError(t, r.Close(ctx))
+
+	argsBytes, err := os.ReadFile(argsPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--output=json --follow --no-pager --lines=0 --unit=nginx.service --priority=warning",
+		"--output=json --follow --no-pager --after-cursor=c2 --unit=nginx.service --priority=warning",
+	}, strings.Split(strings.TrimSpace(string(argsBytes)), "\n"))
+}
@@ -0,0 +1,537 @@
+package io
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/checkpoint"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	wliFieldChannels        = "channels"
+	wliFieldQuery           = "query"
+	wliFieldStartFromOldest = "start_from_oldest"
+	wliFieldRenderMessage   = "render_message"
+	wliFieldRaw             = "raw"
+	wliFieldCache           = "cache"
+	wliFieldCacheKey        = "cache_key"
+)
+
+func winlogInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Local").
+		Version("4.24.0").
+		Summary("Reads events from channels of the Windows Event Log of the host.").
+		Description(`
+Events are read by subscribing to one or more channels of the Windows Event Log, such as `+"`Application`"+`, `+"`System`"+` or `+"`Security`"+`, optionally filtered by an XPath query. This input is only supported when Benthos is running on Windows, and reading some channels (such as `+"`Security`"+`) requires Benthos to run with administrative privileges.
+
+### Bookmark Persistence
+
+When a `+"`cache`"+` is configured a bookmark of the newest event that has been delivered is stored within it, and when the input starts it resumes from the event following the stored bookmark. In order for this to survive restarts a persisted cache such as `+"`file`"+` or `+"`redis`"+` should be used. Without a bookmark the input starts from new events, or from the oldest event of each channel when `+"`start_from_oldest`"+` is `+"`true`"+`.
+
+### Structured Events
+
+Each event is consumed as a JSON object containing the fields of its system section, such as `+"`provider`"+`, `+"`event_id`"+`, `+"`level`"+`, `+"`time_created`"+`, `+"`record_id`"+`, `+"`channel`"+` and `+"`computer`"+`. The named values of the event data section are added to the object `+"`event_data`"+`, where unnamed values are given the names `+"`param1`"+`, `+"`param2`"+` and so on, and the user data section of the event, if present, is added to the object `+"`user_data`"+`.
+
+When `+"`render_message`"+` is `+"`true`"+` the resources of the publisher of each event are used in order to add its rendered message text as the field `+"`message`"+`, along with the names of its level, task, opcode and keywords as the fields `+"`level_name`"+`, `+"`task_name`"+`, `+"`opcode_name`"+` and `+"`keyword_names`"+`.
+
+Setting `+"`raw`"+` to `+"`true`"+` instead emits events as the XML rendered by the Windows Event Log.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- winlog_channel
+- winlog_provider
+- winlog_event_id
+- winlog_record_id
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).`).
+		Fields(
+			service.NewStringListField(wliFieldChannels).
+				Description("A list of channels to read the events of.").
+				Example([]any{"Application", "System"}).
+				Example([]any{"Microsoft-Windows-Sysmon/Operational"}),
+			service.NewStringField(wliFieldQuery).
+				Description("An [XPath query](https://learn.microsoft.com/en-us/windows/win32/wes/consuming-events#xpath-10-limitations) to filter the events of each channel by.").
+				Default("*").
+				Example("*[System[(Level=1 or Level=2 or Level=3)]]").
+				Example("*[System[(EventID=4624 or EventID=4625)]]"),
+			service.NewBoolField(wliFieldStartFromOldest).
+				Description("Whether to read from the oldest event of each channel when no bookmark is stored, rather than from new events.").
+				Default(false),
+			service.NewBoolField(wliFieldRenderMessage).
+				Description("Whether to render the message text of each event from the resources of its publisher. Disabling this reduces the cost of reading events with the trade off that the `message` field is omitted.").
+				Default(true),
+			service.NewBoolField(wliFieldRaw).
+				Description("Whether to emit events as the XML rendered by the Windows Event Log rather than mapped into a structured object.").
+				Default(false).
+				Advanced(),
+			service.NewStringField(wliFieldCache).
+				Description("An optional [cache resource](/docs/components/caches/about) in which to store a bookmark of the newest event that has been delivered, allowing the input to resume from where it left off after a restart.").
+				Default(""),
+			service.NewStringField(wliFieldCacheKey).
+				Description("The key under which the bookmark is stored within the `cache`.").
+				Default("winlog_bookmark").
+				Advanced(),
+		).
+		Example(
+			"Application Errors",
+			"Here we read the warnings and errors of the Application and System channels, resuming from where we left off after a restart.",
+			`
+input:
+  winlog:
+    channels: [ Application, System ]
+    query: '*[System[(Level=1 or Level=2 or Level=3)]]'
+    cache: bookmarks
+
+cache_resources:
+  - label: bookmarks
+    file:
+      directory: C:\ProgramData\benthos\bookmarks
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterInput(
+		"winlog", winlogInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			if !winlogSupported {
+				return nil, errors.New("the winlog input is only supported on Windows")
+			}
+			r, err := newWinlogReader(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacks(r), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// winlogEvent is an event read from a subscription as XML, along with a
+// bookmark of the subscription that includes the event.
+type winlogEvent struct {
+	xml      []byte
+	bookmark string
+}
+
+// winlogSubscription is a subscription to the channels of the event log, which
+// is implemented for each supported platform.
+type winlogSubscription interface {
+	// Next blocks until one or more events are available or the context is
+	// cancelled.
+	Next(ctx context.Context) ([]winlogEvent, error)
+
+	// Close the subscription.
+	Close() error
+}
+
+type winlogReader struct {
+	log *service.Logger
+	mgr *service.Resources
+
+	query           string
+	startFromOldest bool
+	renderMessage   bool
+	raw             bool
+	cache           string
+	cacheKey        string
+
+	open         func(query, bookmark string) (winlogSubscription, error)
+	checkpointer *checkpoint.Capped[string]
+
+	connMut      sync.Mutex
+	lastBookmark string
+	sub          winlogSubscription
+	pending      []winlogEvent
+}
+
+func newWinlogReader(conf *service.ParsedConfig, mgr *service.Resources) (*winlogReader, error) {
+	r := &winlogReader{
+		log:          mgr.Logger(),
+		mgr:          mgr,
+		checkpointer: checkpoint.NewCapped[string](1024),
+	}
+
+	channels, err := conf.FieldStringList(wliFieldChannels)
+	if err != nil {
+		return nil, err
+	}
+	if len(channels) == 0 {
+		return nil, errors.New("at least one channel must be specified")
+	}
+	query, err := conf.FieldString(wliFieldQuery)
+	if err != nil {
+		return nil, err
+	}
+	r.query = winlogQueryXML(channels, query)
+
+	if r.startFromOldest, err = conf.FieldBool(wliFieldStartFromOldest); err != nil {
+		return nil, err
+	}
+	if r.renderMessage, err = conf.FieldBool(wliFieldRenderMessage); err != nil {
+		return nil, err
+	}
+	if r.raw, err = conf.FieldBool(wliFieldRaw); err != nil {
+		return nil, err
+	}
+	if r.cache, err = conf.FieldString(wliFieldCache); err != nil {
+		return nil, err
+	}
+	if r.cacheKey, err = conf.FieldString(wliFieldCacheKey); err != nil {
+		return nil, err
+	}
+	if r.cache != "" && !mgr.HasCache(r.cache) {
+		return nil, fmt.Errorf("cache resource '%v' was not found", r.cache)
+	}
+
+	r.open = func(query, bookmark string) (winlogSubscription, error) {
+		return openWinlogSubscription(r.log, query, bookmark, r.startFromOldest, r.renderMessage)
+	}
+	return r, nil
+}
+
+// winlogQueryXML returns a structured query that selects the events of each
+// channel matching an XPath query.
+func winlogQueryXML(channels []string, query string) string {
+	var b strings.Builder
+	b.WriteString(`<QueryList><Query Id="0">`)
+	for _, c := range channels {
+		b.WriteString(`<Select Path="`)
+		_ = xml.EscapeText(&b, []byte(c))
+		b.WriteString(`">`)
+		_ = xml.EscapeText(&b, []byte(query))
+		b.WriteString(`</Select>`)
+	}
+	b.WriteString(`</Query></QueryList>`)
+	return b.String()
+}
+
+func (r *winlogReader) storedBookmark(ctx context.Context) (bookmark string, err error) {
+	if r.cache == "" {
+		return "", nil
+	}
+	if cerr := r.mgr.AccessCache(ctx, r.cache, func(c service.Cache) {
+		var b []byte
+		if b, err = c.Get(ctx, r.cacheKey); errors.Is(err, service.ErrKeyNotFound) {
+			err = nil
+		}
+		bookmark = string(b)
+	}); cerr != nil {
+		return "", cerr
+	}
+	return
+}
+
+func (r *winlogReader) Connect(ctx context.Context) error {
+	r.connMut.Lock()
+	defer r.connMut.Unlock()
+	if r.sub != nil {
+		return nil
+	}
+
+	// When the subscription is reopened we resume from the last event read,
+	// otherwise from the last event delivered.
+	bookmark := r.lastBookmark
+	if bookmark == "" {
+		var err error
+		if bookmark, err = r.storedBookmark(ctx); err != nil {
+			return fmt.Errorf("failed to obtain stored bookmark: %w", err)
+		}
+	}
+
+	sub, err := r.open(r.query, bookmark)
+	if err != nil {
+		return err
+	}
+	r.sub = sub
+	r.pending = nil
+	return nil
+}
+
+// nextEvent returns the next event of the subscription, blocking until one is
+// available.
+func (r *winlogReader) nextEvent(ctx context.Context) (winlogEvent, error) {
+	r.connMut.Lock()
+	sub := r.sub
+	if sub != nil && len(r.pending) > 0 {
+		e := r.pending[0]
+		r.pending = r.pending[1:]
+		r.connMut.Unlock()
+		return e, nil
+	}
+	r.connMut.Unlock()
+	if sub == nil {
+		return winlogEvent{}, service.ErrNotConnected
+	}
+
+	events, err := sub.Next(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return winlogEvent{}, ctx.Err()
+		}
+		r.log.Errorf("Failed to read events: %v", err)
+
+		r.connMut.Lock()
+		if r.sub == sub {
+			_ = sub.Close()
+			r.sub = nil
+		}
+		r.connMut.Unlock()
+		return winlogEvent{}, service.ErrNotConnected
+	}
+	if len(events) == 0 {
+		return r.nextEvent(ctx)
+	}
+
+	r.connMut.Lock()
+	r.pending = append(r.pending, events[1:]...)
+	r.connMut.Unlock()
+	return events[0], nil
+}
+
+func (r *winlogReader) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	for {
+		event, err := r.nextEvent(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		r.connMut.Lock()
+		r.lastBookmark = event.bookmark
+		r.connMut.Unlock()
+
+		msg, err := r.parseEvent(event.xml)
+		if err != nil {
+			r.log.Errorf("Failed to parse event: %v", err)
+			continue
+		}
+
+		release, err := r.checkpointer.Track(ctx, event.bookmark, 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		return msg, func(ctx context.Context, err error) error {
+			highest := release()
+			if highest == nil || r.cache == "" {
+				return nil
+			}
+			var setErr error
+			if cerr := r.mgr.AccessCache(ctx, r.cache, func(c service.Cache) {
+				setErr = c.Set(ctx, r.cacheKey, []byte(*highest), nil)
+			}); cerr != nil {
+				return cerr
+			}
+			return setErr
+		}, nil
+	}
+}
+
+// parseEvent parses the XML of an event into a message.
+func (r *winlogReader) parseEvent(b []byte) (*service.Message, error) {
+	var event winlogXMLEvent
+	if err := xml.Unmarshal(b, &event); err != nil {
+		return nil, err
+	}
+
+	var msg *service.Message
+	if r.raw {
+		msg = service.NewMessage(b)
+	} else {
+		msg = service.NewMessage(nil)
+		msg.SetStructuredMut(event.structured())
+	}
+	msg.MetaSetMut("winlog_channel", event.System.Channel)
+	msg.MetaSetMut("winlog_provider", event.System.Provider.Name)
+	msg.MetaSetMut("winlog_event_id", strconv.FormatUint(uint64(event.System.EventID), 10))
+	msg.MetaSetMut("winlog_record_id", strconv.FormatUint(event.System.EventRecordID, 10))
+	return msg, nil
+}
+
+func (r *winlogReader) Close(ctx context.Context) error {
+	r.connMut.Lock()
+	defer r.connMut.Unlock()
+	if r.sub == nil {
+		return nil
+	}
+	err := r.sub.Close()
+	r.sub = nil
+	return err
+}
+
+//------------------------------------------------------------------------------
+
+// winlogXMLEvent is an event rendered as XML by the Windows Event Log, which
+// includes rendering info when the message of the event has been formatted.
+type winlogXMLEvent struct {
+	System struct {
+		Provider struct {
+			Name string `xml:"Name,attr"`
+		} `xml:"Provider"`
+		EventID     uint32 `xml:"EventID"`
+		Version     uint8  `xml:"Version"`
+		Level       uint8  `xml:"Level"`
+		Task        uint16 `xml:"Task"`
+		Opcode      uint8  `xml:"Opcode"`
+		Keywords    string `xml:"Keywords"`
+		TimeCreated struct {
+			SystemTime string `xml:"SystemTime,attr"`
+		} `xml:"TimeCreated"`
+		EventRecordID uint64 `xml:"EventRecordID"`
+		Correlation   struct {
+			ActivityID string `xml:"ActivityID,attr"`
+		} `xml:"Correlation"`
+		Execution struct {
+			ProcessID uint32 `xml:"ProcessID,attr"`
+			ThreadID  uint32 `xml:"ThreadID,attr"`
+		} `xml:"Execution"`
+		Channel  string `xml:"Channel"`
+		Computer string `xml:"Computer"`
+		Security struct {
+			UserID string `xml:"UserID,attr"`
+		} `xml:"Security"`
+	} `xml:"System"`
+	EventData struct {
+		Data []struct {
+			Name  string `xml:"Name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Data"`
+		Binary string `xml:"Binary"`
+	} `xml:"EventData"`
+	UserData struct {
+		Nodes []winlogXMLNode `xml:",any"`
+	} `xml:"UserData"`
+	RenderingInfo *struct {
+		Message  string   `xml:"Message"`
+		Level    string   `xml:"Level"`
+		Task     string   `xml:"Task"`
+		Opcode   string   `xml:"Opcode"`
+		Keywords []string `xml:"Keywords>Keyword"`
+	} `xml:"RenderingInfo"`
+}
+
+// winlogXMLNode is an arbitrary element of the user data section of an event.
+type winlogXMLNode struct {
+	XMLName xml.Name
+	Content string          `xml:",chardata"`
+	Nodes   []winlogXMLNode `xml:",any"`
+}
+
+// value returns the text of an element without children, or otherwise an
+// object of its children keyed by their names.
+func (n winlogXMLNode) value() any {
+	if len(n.Nodes) == 0 {
+		return strings.TrimSpace(n.Content)
+	}
+	obj := make(map[string]any, len(n.Nodes))
+	for _, c := range n.Nodes {
+		obj[c.XMLName.Local] = c.value()
+	}
+	return obj
+}
+
+// winlogProviderName returns the name of the provider of an event rendered as XML.
+func winlogProviderName(b []byte) (string, error) {
+	var event struct {
+		System struct {
+			Provider struct {
+				Name string `xml:"Name,attr"`
+			} `xml:"Provider"`
+		} `xml:"System"`
+	}
+	if err := xml.Unmarshal(b, &event); err != nil {
+		return "", err
+	}
+	return event.System.Provider.Name, nil
+}
+
+// structured maps an event into an object.
+func (e *winlogXMLEvent) structured() map[string]any {
+	s := &e.System
+
+	timeCreated := s.TimeCreated.SystemTime
+	if t, err := time.Parse(time.RFC3339Nano, timeCreated); err == nil {
+		timeCreated = t.UTC().Format(time.RFC3339Nano)
+	}
+
+	obj := map[string]any{
+		"provider":     s.Provider.Name,
+		"event_id":     int64(s.EventID),
+		"version":      int64(s.Version),
+		"level":        int64(s.Level),
+		"task":         int64(s.Task),
+		"opcode":       int64(s.Opcode),
+		"keywords":     s.Keywords,
+		"time_created": timeCreated,
+		"record_id":    int64(s.EventRecordID),
+		"channel":      s.Channel,
+		"computer":     s.Computer,
+		"process_id":   int64(s.Execution.ProcessID),
+		"thread_id":    int64(s.Execution.ThreadID),
+	}
+	if s.Correlation.ActivityID != "" {
+		obj["activity_id"] = s.Correlation.ActivityID
+	}
+	if s.Security.UserID != "" {
+		obj["user_id"] = s.Security.UserID
+	}
+
+	if len(e.EventData.Data) > 0 || e.EventData.Binary != "" {
+		data := make(map[string]any, len(e.EventData.Data))
+		for i, d := range e.EventData.Data {
+			name := d.Name
+			if name == "" {
+				name = "param" + strconv.Itoa(i+1)
+			}
+			data[name] = d.Value
+		}
+		if e.EventData.Binary != "" {
+			data["binary"] = e.EventData.Binary
+		}
+		obj["event_data"] = data
+	}
+
+	if len(e.UserData.Nodes) > 0 {
+		data := make(map[string]any, len(e.UserData.Nodes))
+		for _, n := range e.UserData.Nodes {
+			data[n.XMLName.Local] = n.value()
+		}
+		obj["user_data"] = data
+	}
+
+	if info := e.RenderingInfo; info != nil {
+		obj["message"] = strings.TrimSpace(info.Message)
+		for k, v := range map[string]string{
+			"level_name":  info.Level,
+			"task_name":   info.Task,
+			"opcode_name": info.Opcode,
+		} {
+			if v != "" {
+				obj[k] = v
+			}
+		}
+		if len(info.Keywords) > 0 {
+			keywords := make([]any, len(info.Keywords))
+			for i, k := range info.Keywords {
+				keywords[i] = k
+			}
+			obj["keyword_names"] = keywords
+		}
+	}
+	return obj
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package io
+
+import (
+	"errors"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const winlogSupported = false
+
+func openWinlogSubscription(log *service.Logger, query, bookmark string, startFromOldest, renderMessage bool) (winlogSubscription, error) {
+	return nil, errors.New("the winlog input is only supported on Windows")
+}
@@ -0,0 +1,250 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const testWinlogEventXML = `<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event">
+  <System>
+    <Provider Name="Microsoft-Windows-Security-Auditing" Guid="{54849625-5478-4994-a5ba-3e3b0328c30d}"/>
+    <EventID>4624</EventID>
+    <Version>2</Version>
+    <Level>0</Level>
+    <Task>12544</Task>
+    <Opcode>0</Opcode>
+    <Keywords>0x8020000000000000</Keywords>
+    <TimeCreated SystemTime="2023-11-14T22:13:20.1234567Z"/>
+    <EventRecordID>%v</EventRecordID>
+    <Correlation ActivityID="{f4a0a2b1-0000-0000-0000-000000000000}"/>
+    <Execution ProcessID="628" ThreadID="5980"/>
+    <Channel>Security</Channel>
+    <Computer>host.example.com</Computer>
+    <Security/>
+  </System>
+  <EventData>
+    <Data Name="TargetUserName">meow</Data>
+    <Data Name="LogonType">2</Data>
+  </EventData>
+  <RenderingInfo Culture="en-US">
+    <Message>An account was successfully logged on.</Message>
+    <Level>Information</Level>
+    <Task>Logon</Task>
+    <Opcode>Info</Opcode>
+    <Keywords>
+      <Keyword>Audit Success</Keyword>
+    </Keywords>
+  </RenderingInfo>
+</Event>`
+
+func TestWinlogQueryXML(t *testing.T) {
+	assert.Equal(t,
+		`<QueryList><Query Id="0"><Select Path="Application">*[System[(Level=1 or Level=2)]]</Select><Select Path="Microsoft-Windows-Sysmon/Operational">*[System[(Level=1 or Level=2)]]</Select></Query></QueryList>`,
+		winlogQueryXML([]string{"Application", "Microsoft-Windows-Sysmon/Operational"}, "*[System[(Level=1 or Level=2)]]"),
+	)
+	assert.Equal(t,
+		`<QueryList><Query Id="0"><Select Path="&lt;foo&gt;">*[EventData[Data=&#39;a&amp;b&#39;]]</Select></Query></QueryList>`,
+		winlogQueryXML([]string{"<foo>"}, "*[EventData[Data='a&b']]"),
+	)
+}
+
+func TestWinlogStructuredEvent(t *testing.T) {
+	r := &winlogReader{}
+	msg, err := r.parseEvent([]byte(fmt.Sprintf(testWinlogEventXML, 10)))
+	require.NoError(t, err)
+
+	s, err := msg.AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"provider":     "Microsoft-Windows-Security-Auditing",
+		"event_id":     int64(4624),
+		"version":      int64(2),
+		"level":        int64(0),
+		"task":         int64(12544),
+		"opcode":       int64(0),
+		"keywords":     "0x8020000000000000",
+		"time_created": "2023-11-14T22:13:20.1234567Z",
+		"record_id":    int64(10),
+		"activity_id":  "{f4a0a2b1-0000-0000-0000-000000000000}",
+		"channel":      "Security",
+		"computer":     "host.example.com",
+		"process_id":   int64(628),
+		"thread_id":    int64(5980),
+		"event_data": map[string]any{
+			"TargetUserName": "meow",
+			"LogonType":      "2",
+		},
+		"message":       "An account was successfully logged on.",
+		"level_name":    "Information",
+		"task_name":     "Logon",
+		"opcode_name":   "Info",
+		"keyword_names": []any{"Audit Success"},
+	}, s)
+
+	for k, v := range map[string]string{
+		"winlog_channel":   "Security",
+		"winlog_provider":  "Microsoft-Windows-Security-Auditing",
+		"winlog_event_id":  "4624",
+		"winlog_record_id": "10",
+	} {
+		actual, _ := msg.MetaGet(k)
+		assert.Equal(t, v, actual, k)
+	}
+}
+
+func TestWinlogStructuredEventUserData(t *testing.T) {
+	r := &winlogReader{}
+	msg, err := r.parseEvent([]byte(`<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event">
+  <System>
+    <Provider Name="Service Control Manager"/>
+    <EventID Qualifiers="16384">7036</EventID>
+    <Security UserID="S-1-5-18"/>
+  </System>
+  <EventData>
+    <Data>Windows Update</Data>
+    <Data>running</Data>
+  </EventData>
+  <UserData>
+    <LogFileCleared xmlns="http://manifests.microsoft.com/win/2004/08/windows/eventlog">
+      <SubjectUserName>admin</SubjectUserName>
+      <Nested><Value>foo</Value></Nested>
+    </LogFileCleared>
+  </UserData>
+</Event>`))
+	require.NoError(t, err)
+
+	s, err := msg.AsStructured()
+	require.NoError(t, err)
+
+	obj := s.(map[string]any)
+	assert.Equal(t, int64(7036), obj["event_id"])
+	assert.Equal(t, "S-1-5-18", obj["user_id"])
+	assert.Equal(t, map[string]any{
+		"param1": "Windows Update",
+		"param2": "running",
+	}, obj["event_data"])
+	assert.Equal(t, map[string]any{
+		"LogFileCleared": map[string]any{
+			"SubjectUserName": "admin",
+			"Nested":          map[string]any{"Value": "foo"},
+		},
+	}, obj["user_data"])
+	assert.NotContains(t, obj, "message")
+}
+
+type fakeWinlogSubscription struct {
+	batches [][]winlogEvent
+	err     error
+	closed  bool
+}
+
+func (f *fakeWinlogSubscription) Next(ctx context.Context) ([]winlogEvent, error) {
+	if len(f.batches) == 0 {
+		if f.err != nil {
+			return nil, f.err
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	b := f.batches[0]
+	f.batches = f.batches[1:]
+	return b, nil
+}
+
+func (f *fakeWinlogSubscription) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestWinlogReaderBookmarks(t *testing.T) {
+	res := service.MockResources(service.MockResourcesOptAddCache("bookmarks"))
+	conf, err := winlogInputSpec().ParseYAML(`
+channels: [ Security ]
+cache: bookmarks
+`, nil)
+	require.NoError(t, err)
+
+	r, err := newWinlogReader(conf, res)
+	require.NoError(t, err)
+
+	event := func(id int) winlogEvent {
+		return winlogEvent{
+			xml:      []byte(fmt.Sprintf(testWinlogEventXML, id)),
+			bookmark: fmt.Sprintf("bookmark%v", id),
+		}
+	}
+
+	var opened []string
+	sub := &fakeWinlogSubscription{
+		batches: [][]winlogEvent{
+			{event(1), {xml: []byte("not xml"), bookmark: "bookmark2"}, event(3)},
+			{},
+			{event(4)},
+		},
+		err: errors.New("subscription broke"),
+	}
+	r.open = func(query, bookmark string) (winlogSubscription, error) {
+		assert.Equal(t, `<QueryList><Query Id="0"><Select Path="Security">*</Select></Query></QueryList>`, query)
+		opened = append(opened, bookmark)
+		return sub, nil
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, r.Connect(ctx))
+
+	var acks []service.AckFunc
+	var ids []string
+	for i := 0; i < 3; i++ {
+		msg, ackFn, err := r.Read(ctx)
+		require.NoError(t, err)
+
+		id, _ := msg.MetaGet("winlog_record_id")
+		ids = append(ids, id)
+		acks = append(acks, ackFn)
+	}
+	assert.Equal(t, []string{"1", "3", "4"}, ids)
+
+	storedBookmark := func() string {
+		b, err := r.storedBookmark(ctx)
+		require.NoError(t, err)
+		return b
+	}
+
+	// Acknowledging out of order only stores the bookmark of the newest event
+	// of a contiguous sequence of delivered events.
+	require.NoError(t, acks[1](ctx, nil))
+	assert.Equal(t, "", storedBookmark())
+	require.NoError(t, acks[0](ctx, nil))
+	assert.Equal(t, "bookmark3", storedBookmark())
+	require.NoError(t, acks[2](ctx, nil))
+	assert.Equal(t, "bookmark4", storedBookmark())
+
+	// A broken subscription is closed and reopened from the last event read.
+	_, _, err = r.Read(ctx)
+	assert.ErrorIs(t, err, service.ErrNotConnected)
+	assert.True(t, sub.closed)
+
+	sub = &fakeWinlogSubscription{}
+	require.NoError(t, r.Connect(ctx))
+	require.NoError(t, r.Close(ctx))
+	assert.True(t, sub.closed)
+
+	// A new reader resumes from the stored bookmark.
+	r2, err := newWinlogReader(conf, res)
+	require.NoError(t, err)
+	r2.open = r.open
+	require.NoError(t, r2.Connect(ctx))
+	require.NoError(t, r2.Close(ctx))
+
+	assert.Equal(t, []string{"", "bookmark4", "bookmark4"}, opened)
+}
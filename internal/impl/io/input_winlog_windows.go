@@ -0,0 +1,294 @@
+//go:build windows
+
+package io
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const winlogSupported = true
+
+var (
+	modwevtapi = windows.NewLazySystemDLL("wevtapi.dll")
+
+	procEvtClose                 = modwevtapi.NewProc("EvtClose")
+	procEvtCreateBookmark        = modwevtapi.NewProc("EvtCreateBookmark")
+	procEvtFormatMessage         = modwevtapi.NewProc("EvtFormatMessage")
+	procEvtNext                  = modwevtapi.NewProc("EvtNext")
+	procEvtOpenPublisherMetadata = modwevtapi.NewProc("EvtOpenPublisherMetadata")
+	procEvtRender                = modwevtapi.NewProc("EvtRender")
+	procEvtSubscribe             = modwevtapi.NewProc("EvtSubscribe")
+	procEvtUpdateBookmark        = modwevtapi.NewProc("EvtUpdateBookmark")
+)
+
+const (
+	evtSubscribeToFutureEvents      = 1
+	evtSubscribeStartAtOldestRecord = 2
+	evtSubscribeStartAfterBookmark  = 3
+
+	evtRenderEventXML = 1
+	evtRenderBookmark = 2
+
+	evtFormatMessageFlagXML = 9
+
+	// winlogBatchSize is the maximum number of events obtained from a
+	// subscription at a time.
+	winlogBatchSize = 64
+
+	// winlogWaitMillis is the period of time waited for new events before the
+	// context of a read is checked.
+	winlogWaitMillis = 250
+)
+
+type evtHandle uintptr
+
+func evtClose(h evtHandle) {
+	if h != 0 {
+		_, _, _ = procEvtClose.Call(uintptr(h))
+	}
+}
+
+// evtCreateBookmark creates a bookmark from its XML, or an empty bookmark when
+// the XML is empty.
+func evtCreateBookmark(bookmarkXML string) (evtHandle, error) {
+	var xmlPtr *uint16
+	if bookmarkXML != "" {
+		var err error
+		if xmlPtr, err = windows.UTF16PtrFromString(bookmarkXML); err != nil {
+			return 0, err
+		}
+	}
+	h, _, err := procEvtCreateBookmark.Call(uintptr(unsafe.Pointer(xmlPtr)))
+	if h == 0 {
+		return 0, err
+	}
+	return evtHandle(h), nil
+}
+
+// evtRender renders an event or bookmark as XML.
+func evtRender(h evtHandle, flags uint32) (string, error) {
+	buf := make([]uint16, 4096)
+	for {
+		var used, count uint32
+		ok, _, err := procEvtRender.Call(
+			0, uintptr(h), uintptr(flags),
+			uintptr(len(buf)*2), uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(unsafe.Pointer(&used)), uintptr(unsafe.Pointer(&count)),
+		)
+		if ok != 0 {
+			return windows.UTF16ToString(buf[:used/2]), nil
+		}
+		if !errors.Is(err, windows.ERROR_INSUFFICIENT_BUFFER) {
+			return "", err
+		}
+		buf = make([]uint16, used/2+1)
+	}
+}
+
+// evtFormatMessageXML renders an event as XML including the rendering info
+// obtained from the metadata of its publisher.
+func evtFormatMessageXML(publisher, h evtHandle) (string, error) {
+	buf := make([]uint16, 4096)
+	for {
+		var used uint32
+		ok, _, err := procEvtFormatMessage.Call(
+			uintptr(publisher), uintptr(h), 0, 0, 0, evtFormatMessageFlagXML,
+			uintptr(len(buf)), uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(unsafe.Pointer(&used)),
+		)
+		if ok != 0 {
+			return windows.UTF16ToString(buf[:used]), nil
+		}
+		if !errors.Is(err, windows.ERROR_INSUFFICIENT_BUFFER) {
+			return "", err
+		}
+		buf = make([]uint16, used+1)
+	}
+}
+
+type windowsWinlogSubscription struct {
+	log           *service.Logger
+	renderMessage bool
+
+	signal     windows.Handle
+	handle     evtHandle
+	bookmark   evtHandle
+	publishers map[string]evtHandle
+}
+
+func openWinlogSubscription(log *service.Logger, query, bookmark string, startFromOldest, renderMessage bool) (winlogSubscription, error) {
+	s := &windowsWinlogSubscription{
+		log:           log,
+		renderMessage: renderMessage,
+		publishers:    map[string]evtHandle{},
+	}
+
+	var err error
+	if s.signal, err = windows.CreateEvent(nil, 1, 1, nil); err != nil {
+		return nil, fmt.Errorf("failed to create signal event: %w", err)
+	}
+	if s.bookmark, err = evtCreateBookmark(bookmark); err != nil {
+		_ = s.Close()
+		return nil, fmt.Errorf("failed to create bookmark: %w", err)
+	}
+
+	flags := uint32(evtSubscribeToFutureEvents)
+	var after evtHandle
+	switch {
+	case bookmark != "":
+		flags, after = evtSubscribeStartAfterBookmark, s.bookmark
+	case startFromOldest:
+		flags = evtSubscribeStartAtOldestRecord
+	}
+
+	queryPtr, err := windows.UTF16PtrFromString(query)
+	if err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+	h, _, err := procEvtSubscribe.Call(
+		0, uintptr(s.signal), 0, uintptr(unsafe.Pointer(queryPtr)),
+		uintptr(after), 0, 0, uintptr(flags),
+	)
+	if h == 0 {
+		_ = s.Close()
+		return nil, fmt.Errorf("failed to subscribe to channels: %w", err)
+	}
+	s.handle = evtHandle(h)
+	return s, nil
+}
+
+func (s *windowsWinlogSubscription) Next(ctx context.Context) ([]winlogEvent, error) {
+	handles := make([]evtHandle, winlogBatchSize)
+	for {
+		var returned uint32
+		ok, _, err := procEvtNext.Call(
+			uintptr(s.handle), uintptr(len(handles)),
+			uintptr(unsafe.Pointer(&handles[0])), 0, 0,
+			uintptr(unsafe.Pointer(&returned)),
+		)
+		if ok != 0 && returned > 0 {
+			return s.render(handles[:returned])
+		}
+		if ok == 0 && !errors.Is(err, windows.ERROR_NO_MORE_ITEMS) && !errors.Is(err, windows.ERROR_INVALID_OPERATION) {
+			return nil, fmt.Errorf("failed to obtain events: %w", err)
+		}
+
+		// No events are available and so we wait for the subscription to
+		// signal that new ones have arrived.
+		res, err := windows.WaitForSingleObject(s.signal, winlogWaitMillis)
+		switch res {
+		case windows.WAIT_OBJECT_0:
+			if err := windows.ResetEvent(s.signal); err != nil {
+				return nil, err
+			}
+		case uint32(windows.WAIT_TIMEOUT):
+		default:
+			return nil, fmt.Errorf("failed to wait for events: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+	}
+}
+
+// render renders a batch of event handles, advancing the bookmark of the
+// subscription with each one, and then closes them.
+func (s *windowsWinlogSubscription) render(handles []evtHandle) ([]winlogEvent, error) {
+	defer func() {
+		for _, h := range handles {
+			evtClose(h)
+		}
+	}()
+
+	events := make([]winlogEvent, 0, len(handles))
+	for _, h := range handles {
+		eventXML, err := s.renderEvent(h)
+		if err != nil {
+			s.log.Errorf("Failed to render event: %v", err)
+		}
+
+		if ok, _, err := procEvtUpdateBookmark.Call(uintptr(s.bookmark), uintptr(h)); ok == 0 {
+			return nil, fmt.Errorf("failed to update bookmark: %w", err)
+		}
+		if eventXML == "" {
+			continue
+		}
+
+		bookmark, err := evtRender(s.bookmark, evtRenderBookmark)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render bookmark: %w", err)
+		}
+		events = append(events, winlogEvent{
+			xml:      []byte(eventXML),
+			bookmark: bookmark,
+		})
+	}
+	return events, nil
+}
+
+// renderEvent renders an event as XML, including its rendering info when
+// messages are rendered and the metadata of its publisher is available.
+func (s *windowsWinlogSubscription) renderEvent(h evtHandle) (string, error) {
+	eventXML, err := evtRender(h, evtRenderEventXML)
+	if err != nil || !s.renderMessage {
+		return eventXML, err
+	}
+
+	provider, err := winlogProviderName([]byte(eventXML))
+	if err != nil {
+		return eventXML, nil
+	}
+	publisher := s.publisher(provider)
+	if publisher == 0 {
+		return eventXML, nil
+	}
+	if formatted, err := evtFormatMessageXML(publisher, h); err == nil {
+		return formatted, nil
+	}
+	return eventXML, nil
+}
+
+// publisher returns the metadata of a publisher, or zero when it cannot be
+// opened, in which case messages of the publisher aren't rendered.
+func (s *windowsWinlogSubscription) publisher(name string) evtHandle {
+	if h, exists := s.publishers[name]; exists {
+		return h
+	}
+
+	var h evtHandle
+	if namePtr, err := windows.UTF16PtrFromString(name); err == nil {
+		r, _, err := procEvtOpenPublisherMetadata.Call(0, uintptr(unsafe.Pointer(namePtr)), 0, 0, 0)
+		if r == 0 {
+			s.log.Debugf("Failed to open metadata of publisher '%v': %v", name, err)
+		}
+		h = evtHandle(r)
+	}
+	s.publishers[name] = h
+	return h
+}
+
+func (s *windowsWinlogSubscription) Close() error {
+	evtClose(s.handle)
+	evtClose(s.bookmark)
+	for _, h := range s.publishers {
+		evtClose(h)
+	}
+	s.handle, s.bookmark, s.publishers = 0, 0, map[string]evtHandle{}
+	if s.signal != 0 {
+		err := windows.CloseHandle(s.signal)
+		s.signal = 0
+		return err
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package io
 
 import (
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
@@ -9,20 +10,31 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/codec"
 	"github.com/benthosdev/benthos/v4/internal/component/interop"
 	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/public/service"
 )
 
 const (
-	fileOutputFieldPath  = "path"
-	fileOutputFieldCodec = "codec"
+	fileOutputFieldPath        = "path"
+	fileOutputFieldCodec       = "codec"
+	fileOutputFieldCompression = "compression"
+	fileOutputFieldSync        = "sync"
+
+	fileOutputFieldRotation         = "rotation"
+	fileOutputFieldRotationMaxSize  = "max_size"
+	fileOutputFieldRotationMaxAge   = "max_age"
+	fileOutputFieldRotationMaxCount = "max_count"
+	fileOutputFieldRotationPath     = "path"
+	fileOutputFieldRotationGzip     = "gzip"
 )
 
 func fileOutputSpec() *service.ConfigSpec {
@@ -30,7 +42,15 @@ func fileOutputSpec() *service.ConfigSpec {
 		Stable().
 		Categories("Local").
 		Summary(`Writes messages to files on disk based on a chosen codec.`).
-		Description(`Messages can be written to different files by using [interpolation functions](/docs/configuration/interpolation#bloblang-queries) in the path field. However, only one file is ever open at a given time, and therefore when the path changes the previously open file is closed.`).
+		Description(`Messages can be written to different files by using [interpolation functions](/docs/configuration/interpolation#bloblang-queries) in the path field. However, only one file is ever open at a given time, and therefore when the path changes the previously open file is closed.
+
+### Rotation
+
+Files can be rotated once they reach a maximum size, age or number of messages by configuring the `+"[`rotation`](#rotation)"+` fields. When a file is rotated it is closed and moved to a path resolved from `+"[`rotation.path`](#rotationpath)"+`, optionally compressed with gzip, and subsequent messages are written to a new file at the original path. Rotation only applies to codecs that keep files open between messages, such as `+"`lines`"+`.
+
+### Durability
+
+By default the contents of files are flushed to the operating system but not synced to disk, and therefore data written by this output could be lost in the event of a power failure. Set the `+"[`sync`](#sync)"+` field to `+"`batch`"+` in order to sync files to disk before messages are acknowledged.`).
 		Fields(
 			service.NewInterpolatedStringField(fileOutputFieldPath).
 				Description("The file to write to, if the file does not yet exist it will be created.").
@@ -41,12 +61,59 @@ func fileOutputSpec() *service.ConfigSpec {
 				).
 				Version("3.33.0"),
 			service.NewInternalField(codec.WriterDocs).Version("3.33.0").Default("lines"),
+			service.NewInternalField(codec.WriterCompressionDocs),
+			service.NewObjectField(fileOutputFieldRotation,
+				service.NewIntField(fileOutputFieldRotationMaxSize).
+					Description("The size in bytes at which a file is rotated. Set to zero in order to disable rotation by size.").
+					Default(0),
+				service.NewDurationField(fileOutputFieldRotationMaxAge).
+					Description("The maximum period of time after a file is opened before it is rotated, even when no further messages are written to it. Leave empty in order to disable rotation by age.").
+					Default("").
+					Example("1h"),
+				service.NewIntField(fileOutputFieldRotationMaxCount).
+					Description("The number of messages at which a file is rotated. Set to zero in order to disable rotation by message count.").
+					Default(0),
+				service.NewInterpolatedStringField(fileOutputFieldRotationPath).
+					Description("The path to move rotated files to. This field is resolved at the point of rotation with the metadata fields `path`, the path of the file being rotated, and `rotation_count`, the number of messages written to the file.").
+					Default(`${! @path }.${! timestamp_unix_nano() }`).
+					Example(`${! @path.filepath_split().index(0) }archive/${! now().ts_format("2006-01-02T15-04-05") }.log`),
+				service.NewBoolField(fileOutputFieldRotationGzip).
+					Description("Whether to compress rotated files with gzip, in which case the suffix `.gz` is added to the rotated path.").
+					Default(false),
+			).
+				Description("Rotate files once they reach a maximum size, age or number of messages.").
+				Advanced().
+				Version("4.24.0"),
+			service.NewStringAnnotatedEnumField(fileOutputFieldSync, map[string]string{
+				"none":  "Files are never explicitly synced to disk.",
+				"close": "Files are synced to disk before they are closed or rotated.",
+				"batch": "Files are synced to disk after each batch of messages is written, before the messages are acknowledged.",
+			}).
+				Description("When to sync the contents of files to disk.").
+				Default("none").
+				Advanced().
+				Version("4.24.0"),
 		)
 }
 
+type fileRotationConfig struct {
+	MaxSize  int64
+	MaxAge   time.Duration
+	MaxCount int
+	Path     string
+	Gzip     bool
+}
+
+func (r fileRotationConfig) enabled() bool {
+	return r.MaxSize > 0 || r.MaxAge > 0 || r.MaxCount > 0
+}
+
 type fileOutputConfig struct {
-	Path  string
-	Codec string
+	Path        string
+	Codec       string
+	Compression codec.WriterCompressionConfig
+	Sync        string
+	Rotation    fileRotationConfig
 }
 
 func fileOutputConfigFromParsed(pConf *service.ParsedConfig) (conf fileOutputConfig, err error) {
@@ -56,6 +123,37 @@ func fileOutputConfigFromParsed(pConf *service.ParsedConfig) (conf fileOutputCon
 	if conf.Codec, err = pConf.FieldString(fileOutputFieldCodec); err != nil {
 		return
 	}
+	cConf := pConf.Namespace(fileOutputFieldCompression)
+	if conf.Compression.Level, err = cConf.FieldInt("level"); err != nil {
+		return
+	}
+	if conf.Compression.Dictionary, err = cConf.FieldString("dictionary"); err != nil {
+		return
+	}
+	if conf.Sync, err = pConf.FieldString(fileOutputFieldSync); err != nil {
+		return
+	}
+
+	rConf := pConf.Namespace(fileOutputFieldRotation)
+	var maxSize int
+	if maxSize, err = rConf.FieldInt(fileOutputFieldRotationMaxSize); err != nil {
+		return
+	}
+	conf.Rotation.MaxSize = int64(maxSize)
+	if maxAgeStr, _ := rConf.FieldString(fileOutputFieldRotationMaxAge); maxAgeStr != "" {
+		if conf.Rotation.MaxAge, err = rConf.FieldDuration(fileOutputFieldRotationMaxAge); err != nil {
+			return
+		}
+	}
+	if conf.Rotation.MaxCount, err = rConf.FieldInt(fileOutputFieldRotationMaxCount); err != nil {
+		return
+	}
+	if conf.Rotation.Path, err = rConf.FieldString(fileOutputFieldRotationPath); err != nil {
+		return
+	}
+	if conf.Rotation.Gzip, err = rConf.FieldBool(fileOutputFieldRotationGzip); err != nil {
+		return
+	}
 	return
 }
 
@@ -81,7 +179,7 @@ func init() {
 
 			mgr := interop.UnwrapManagement(res)
 			var f *fileWriter
-			if f, err = newFileWriter(conf.Path, conf.Codec, mgr); err != nil {
+			if f, err = newFileWriter(conf, mgr); err != nil {
 				return
 			}
 
@@ -107,28 +205,62 @@ type fileWriter struct {
 	path      *field.Expression
 	codec     codec.WriterConstructor
 	codecConf codec.WriterConfig
+	sync      string
+
+	rotation     fileRotationConfig
+	rotationPath *field.Expression
 
 	handleMut  sync.Mutex
 	handlePath string
 	handle     codec.Writer
+	handleFile fs.File
+	counter    *countingWriter
+	written    int
+	ageTimer   *time.Timer
 }
 
-func newFileWriter(pathStr, codecStr string, mgr bundle.NewManagement) (*fileWriter, error) {
-	codec, codecConf, err := codec.GetWriter(codecStr)
+func newFileWriter(conf fileOutputConfig, mgr bundle.NewManagement) (*fileWriter, error) {
+	codec, codecConf, err := codec.GetCompressedWriter(conf.Codec, conf.Compression, mgr.FS())
 	if err != nil {
 		return nil, err
 	}
-	path, err := mgr.BloblEnvironment().NewField(pathStr)
+	path, err := mgr.BloblEnvironment().NewField(conf.Path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse path expression: %w", err)
 	}
-	return &fileWriter{
+	w := &fileWriter{
 		codec:     codec,
 		codecConf: codecConf,
+		sync:      conf.Sync,
+		rotation:  conf.Rotation,
 		path:      path,
 		log:       mgr.Logger(),
 		nm:        mgr,
-	}, nil
+	}
+	if conf.Rotation.enabled() {
+		if w.rotationPath, err = mgr.BloblEnvironment().NewField(conf.Rotation.Path); err != nil {
+			return nil, fmt.Errorf("failed to parse rotation path expression: %w", err)
+		}
+	}
+	return w, nil
+}
+
+//------------------------------------------------------------------------------
+
+// countingWriter tallies the number of bytes written to a file.
+type countingWriter struct {
+	w io.WriteCloser
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingWriter) Close() error {
+	return c.w.Close()
 }
 
 //------------------------------------------------------------------------------
@@ -137,6 +269,164 @@ func (w *fileWriter) Connect(ctx context.Context) error {
 	return nil
 }
 
+func (w *fileWriter) openLocked(ctx context.Context, path string) error {
+	flag := os.O_CREATE | os.O_RDWR
+	if w.codecConf.Append {
+		flag |= os.O_APPEND
+	}
+	if w.codecConf.Truncate {
+		flag |= os.O_TRUNC
+	}
+
+	if err := w.nm.FS().MkdirAll(filepath.Dir(path), fs.FileMode(0o777)); err != nil {
+		return err
+	}
+
+	file, err := w.nm.FS().OpenFile(path, flag, fs.FileMode(0o666))
+	if err != nil {
+		return err
+	}
+
+	fileWriter, ok := file.(io.WriteCloser)
+	if !ok {
+		_ = file.Close()
+		return errors.New("failed to open file for writing")
+	}
+
+	counter := &countingWriter{w: fileWriter}
+	if w.codecConf.Append && !w.codecConf.Truncate {
+		if info, err := file.Stat(); err == nil {
+			counter.n = info.Size()
+		}
+	}
+
+	handle, err := w.codec(counter)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	w.handlePath = path
+	w.handle = handle
+	w.handleFile = file
+	w.counter = counter
+	w.written = 0
+
+	if w.rotation.MaxAge > 0 && !w.codecConf.CloseAfter {
+		w.ageTimer = time.AfterFunc(w.rotation.MaxAge, func() {
+			w.handleMut.Lock()
+			defer w.handleMut.Unlock()
+			if w.handle != handle {
+				return
+			}
+			if err := w.rotateLocked(context.Background()); err != nil {
+				w.log.Errorf("Failed to rotate file '%v': %v\n", path, err)
+			}
+		})
+	}
+	return nil
+}
+
+func (w *fileWriter) syncLocked() error {
+	if syncer, ok := w.handleFile.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+func (w *fileWriter) closeLocked(ctx context.Context) error {
+	if w.handle == nil {
+		return nil
+	}
+	if w.ageTimer != nil {
+		w.ageTimer.Stop()
+		w.ageTimer = nil
+	}
+
+	var err error
+	if w.sync != "none" {
+		err = w.syncLocked()
+	}
+	if cerr := w.handle.Close(ctx); err == nil {
+		err = cerr
+	}
+	w.handle = nil
+	w.handleFile = nil
+	w.counter = nil
+	return err
+}
+
+func (w *fileWriter) shouldRotateLocked() bool {
+	if w.handle == nil || w.codecConf.CloseAfter || !w.rotation.enabled() {
+		return false
+	}
+	if w.rotation.MaxSize > 0 && w.counter.n >= w.rotation.MaxSize {
+		return true
+	}
+	return w.rotation.MaxCount > 0 && w.written >= w.rotation.MaxCount
+}
+
+// rotateLocked closes the current file and moves it to the rotated path,
+// compressing it when configured.
+func (w *fileWriter) rotateLocked(ctx context.Context) error {
+	path, written := w.handlePath, w.written
+	if err := w.closeLocked(ctx); err != nil {
+		return err
+	}
+
+	p := message.NewPart(nil)
+	p.MetaSetMut("path", path)
+	p.MetaSetMut("rotation_count", written)
+	rotatedPath, err := w.rotationPath.String(0, message.Batch{p})
+	if err != nil {
+		return fmt.Errorf("rotation path interpolation error: %w", err)
+	}
+	rotatedPath = filepath.Clean(rotatedPath)
+
+	if err := w.nm.FS().MkdirAll(filepath.Dir(rotatedPath), fs.FileMode(0o777)); err != nil {
+		return err
+	}
+	if !w.rotation.Gzip {
+		return ifs.Rename(w.nm.FS(), path, rotatedPath)
+	}
+	return w.gzipFile(path, rotatedPath+".gz")
+}
+
+func (w *fileWriter) gzipFile(from, to string) error {
+	src, err := w.nm.FS().Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := w.nm.FS().OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fs.FileMode(0o666))
+	if err != nil {
+		return err
+	}
+	dstWriter, ok := dst.(io.Writer)
+	if !ok {
+		_ = dst.Close()
+		return errors.New("failed to open file for writing")
+	}
+
+	gw := gzip.NewWriter(dstWriter)
+	if _, err = io.Copy(gw, src); err == nil {
+		err = gw.Close()
+	}
+	if err == nil && w.sync != "none" {
+		if syncer, ok := dst.(interface{ Sync() error }); ok {
+			err = syncer.Sync()
+		}
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return w.nm.FS().Remove(from)
+}
+
 func (w *fileWriter) WriteBatch(ctx context.Context, msg message.Batch) error {
 	err := output.IterateBatchedSend(msg, func(i int, p *message.Part) error {
 		path, err := w.path.String(i, msg)
@@ -148,70 +438,47 @@ func (w *fileWriter) WriteBatch(ctx context.Context, msg message.Batch) error {
 		w.handleMut.Lock()
 		defer w.handleMut.Unlock()
 
-		if w.handle != nil && path == w.handlePath {
-			return w.handle.Write(ctx, p)
-		}
-		if w.handle != nil {
-			if err := w.handle.Close(ctx); err != nil {
+		if w.handle != nil && path != w.handlePath {
+			if err := w.closeLocked(ctx); err != nil {
 				return err
 			}
 		}
-
-		flag := os.O_CREATE | os.O_RDWR
-		if w.codecConf.Append {
-			flag |= os.O_APPEND
-		}
-		if w.codecConf.Truncate {
-			flag |= os.O_TRUNC
-		}
-
-		if err := w.nm.FS().MkdirAll(filepath.Dir(path), fs.FileMode(0o777)); err != nil {
-			return err
-		}
-
-		file, err := w.nm.FS().OpenFile(path, flag, fs.FileMode(0o666))
-		if err != nil {
-			return err
-		}
-
-		fileWriter, ok := file.(io.WriteCloser)
-		if !ok {
-			_ = file.Close()
-			return errors.New("failed to open file for writing")
+		if w.handle == nil {
+			if err := w.openLocked(ctx, path); err != nil {
+				return err
+			}
 		}
 
-		w.handlePath = path
-		handle, err := w.codec(fileWriter)
-		if err != nil {
+		if err = w.handle.Write(ctx, p); err != nil {
+			_ = w.closeLocked(ctx)
 			return err
 		}
+		w.written++
 
-		if err = handle.Write(ctx, p); err != nil {
-			handle.Close(ctx)
-			return err
+		if w.codecConf.CloseAfter {
+			return w.closeLocked(ctx)
 		}
-
-		if !w.codecConf.CloseAfter {
-			w.handle = handle
-		} else {
-			handle.Close(ctx)
+		if w.shouldRotateLocked() {
+			return w.rotateLocked(ctx)
 		}
 		return nil
 	})
 	if err != nil {
 		return err
 	}
+
+	if w.sync == "batch" {
+		w.handleMut.Lock()
+		defer w.handleMut.Unlock()
+		if w.handle != nil {
+			return w.syncLocked()
+		}
+	}
 	return nil
 }
 
 func (w *fileWriter) Close(ctx context.Context) error {
 	w.handleMut.Lock()
 	defer w.handleMut.Unlock()
-
-	var err error
-	if w.handle != nil {
-		err = w.handle.Close(ctx)
-		w.handle = nil
-	}
-	return err
+	return w.closeLocked(ctx)
 }
@@ -0,0 +1,139 @@
+package io
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func readDirContents(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	contents := map[string]string{}
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		require.NoError(t, err)
+		contents[e.Name()] = string(b)
+	}
+	return contents
+}
+
+func TestFileOutputRotateByCount(t *testing.T) {
+	dir := t.TempDir()
+
+	pConf, err := fileOutputSpec().ParseYAML(fmt.Sprintf(`
+path: %v
+rotation:
+  max_count: 2
+  path: '${! @path }.${! count("rotations") }'
+`, filepath.Join(dir, "out.txt")), nil)
+	require.NoError(t, err)
+
+	conf, err := fileOutputConfigFromParsed(pConf)
+	require.NoError(t, err)
+
+	w, err := newFileWriter(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, s := range []string{"a", "b", "c", "d", "e"} {
+		require.NoError(t, w.WriteBatch(ctx, message.QuickBatch([][]byte{[]byte(s)})))
+	}
+	require.NoError(t, w.Close(ctx))
+
+	assert.Equal(t, map[string]string{
+		"out.txt.1": "a\nb\n",
+		"out.txt.2": "c\nd\n",
+		"out.txt":   "e\n",
+	}, readDirContents(t, dir))
+}
+
+func TestFileOutputRotateBySizeGzip(t *testing.T) {
+	dir := t.TempDir()
+
+	pConf, err := fileOutputSpec().ParseYAML(fmt.Sprintf(`
+path: %v
+sync: batch
+rotation:
+  max_size: 8
+  path: '${! @path }-${! @rotation_count }'
+  gzip: true
+`, filepath.Join(dir, "out.txt")), nil)
+	require.NoError(t, err)
+
+	conf, err := fileOutputConfigFromParsed(pConf)
+	require.NoError(t, err)
+
+	w, err := newFileWriter(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, w.WriteBatch(ctx, message.QuickBatch([][]byte{
+		[]byte("hello"), []byte("world"), []byte("foo"),
+	})))
+	require.NoError(t, w.Close(ctx))
+
+	f, err := os.Open(filepath.Join(dir, "out.txt-2.gz"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	b, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\nworld\n", string(b))
+
+	b, err = os.ReadFile(filepath.Join(dir, "out.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "foo\n", string(b))
+}
+
+func TestFileOutputRotateByAge(t *testing.T) {
+	dir := t.TempDir()
+
+	pConf, err := fileOutputSpec().ParseYAML(fmt.Sprintf(`
+path: %v
+rotation:
+  max_age: 50ms
+  path: '${! @path }.rotated'
+`, filepath.Join(dir, "out.txt")), nil)
+	require.NoError(t, err)
+
+	conf, err := fileOutputConfigFromParsed(pConf)
+	require.NoError(t, err)
+
+	w, err := newFileWriter(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, w.WriteBatch(ctx, message.QuickBatch([][]byte{[]byte("hello")})))
+
+	assert.Eventually(t, func() bool {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return false
+		}
+		return len(entries) == 1 && entries[0].Name() == "out.txt.rotated"
+	}, time.Second, time.Millisecond*10)
+
+	require.NoError(t, w.Close(ctx))
+
+	b, err := os.ReadFile(filepath.Join(dir, "out.txt.rotated"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(b))
+}
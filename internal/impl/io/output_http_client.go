@@ -3,15 +3,19 @@ package io
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/benthosdev/benthos/v4/internal/batch/policy/batchconfig"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/interop"
 	"github.com/benthosdev/benthos/v4/internal/component/output"
 	"github.com/benthosdev/benthos/v4/internal/component/output/batcher"
+	"github.com/benthosdev/benthos/v4/internal/component/output/span"
 	"github.com/benthosdev/benthos/v4/internal/httpclient"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
 	"github.com/benthosdev/benthos/v4/internal/transaction"
 	"github.com/benthosdev/benthos/v4/public/service"
 )
@@ -30,7 +34,13 @@ The body of the HTTP request is the raw contents of the message payload. If the
 
 ### Propagating Responses
 
-It's possible to propagate the response from each HTTP request back to the input source by setting `+"`propagate_response` to `true`"+`. Only inputs that support [synchronous responses](/docs/guides/sync_responses) are able to make use of these propagated responses.`)).
+It's possible to propagate the response from each HTTP request back to the input source by setting `+"`propagate_response` to `true`"+`. Only inputs that support [synchronous responses](/docs/guides/sync_responses) are able to make use of these propagated responses.
+
+### Capturing Responses
+
+Alternatively, responses can be fed back into a pipeline as a new stream of messages by setting `+"`response_inproc`"+` to an ID, where the responses can then be consumed with an `+"[`inproc` input](/docs/components/inputs/inproc)"+` of the same ID. Each response message contains the metadata of the request message it was created from, including any metadata set specifically for correlation purposes, followed by the metadata of the response such as `+"`http_status_code`"+`.
+
+A request is only considered successful once its responses have been acknowledged by the consumer of the `+"`inproc`"+` ID, and therefore a request may be sent more than once when the delivery of its responses fails. When no input is consuming the ID the output applies back pressure until one is connected.`)).
 		Field(httpclient.ConfigField("POST", true,
 			service.NewBoolField("batch_as_multipart").
 				Description("Send message batches as a single request using [RFC1341](https://www.w3.org/Protocols/rfc1341/7_2_Multipart.html). If disabled messages in batches will be sent as individual requests.").
@@ -38,6 +48,13 @@ It's possible to propagate the response from each HTTP request back to the input
 			service.NewBoolField("propagate_response").
 				Description("Whether responses from the server should be [propagated back](/docs/guides/sync_responses) to the input.").
 				Advanced().Default(false),
+			service.NewStringField("response_inproc").
+				Description("An optional [`inproc`](/docs/components/inputs/inproc) ID to write the responses of requests to as new messages, where they can be consumed by an `inproc` input.").
+				Example("http_responses").
+				Advanced().Version("4.24.0").Default(""),
+			service.NewBoolField("canonical_json").
+				Description("Whether to serialize message payloads as canonical JSON ([RFC 8785](https://www.rfc-editor.org/rfc/rfc8785)) before they are sent, where object keys are sorted, numbers are formatted consistently and HTML characters are not escaped. This ensures that request bodies are stable across runs, which is useful when the receiver verifies a signature or hash of the body. Messages that are not valid JSON are rejected.").
+				Advanced().Version("4.24.0").Default(false),
 			service.NewIntField("max_in_flight").
 				Description("The maximum number of parallel message batches to have in flight at any given time.").
 				Default(64),
@@ -57,7 +74,8 @@ It's possible to propagate the response from each HTTP request back to the input
 					Default(""),
 			).Description("EXPERIMENTAL: Create explicit multipart HTTP requests by specifying an array of parts to add to the request, each part specified consists of content headers and a data field that can be populated dynamically. If this field is populated it will override the default request creation behaviour.").
 				Advanced().Version("3.63.0").Default([]any{}),
-		))
+		)).
+		Field(span.InjectTracingSpanMappingDocs().Version("4.24.0"))
 }
 
 func init() {
@@ -101,7 +119,7 @@ func init() {
 			if o, err = batcher.NewFromConfig(batchConf, o, oldMgr); err != nil {
 				return
 			}
-			bo = interop.NewUnwrapInternalOutput(o)
+			bo, err = span.NewBatchOutput("http_client", conf, interop.NewUnwrapInternalOutput(o), mgr)
 			return
 		})
 	if err != nil {
@@ -113,8 +131,15 @@ type httpClientWriter struct {
 	client *httpclient.Client
 	log    log.Modular
 
-	logURL       string
-	propResponse bool
+	logURL        string
+	propResponse  bool
+	canonicalJSON bool
+
+	mgr          bundle.NewManagement
+	responsePipe string
+	responseChan chan message.Transaction
+	responseMut  sync.RWMutex
+	shutSig      *shutdown.Signaller
 }
 
 func newHTTPClientOutputFromParsed(conf *service.ParsedConfig, mgr bundle.NewManagement) (*httpClientWriter, error) {
@@ -125,6 +150,14 @@ func newHTTPClientOutputFromParsed(conf *service.ParsedConfig, mgr bundle.NewMan
 	if err != nil {
 		return nil, err
 	}
+	responsePipe, err := conf.FieldString("response_inproc")
+	if err != nil {
+		return nil, err
+	}
+	canonicalJSON, err := conf.FieldBool("canonical_json")
+	if err != nil {
+		return nil, err
+	}
 
 	if multiPartObjs, _ := conf.FieldObjectList("multipart"); len(multiPartObjs) > 0 {
 		parts := make([]httpclient.MultipartExpressions, len(multiPartObjs))
@@ -172,12 +205,21 @@ func newHTTPClientOutputFromParsed(conf *service.ParsedConfig, mgr bundle.NewMan
 		return nil, err
 	}
 
-	return &httpClientWriter{
-		client:       client,
-		log:          mgr.Logger(),
-		logURL:       logURL,
-		propResponse: propResponse,
-	}, nil
+	h := &httpClientWriter{
+		client:        client,
+		log:           mgr.Logger(),
+		logURL:        logURL,
+		propResponse:  propResponse,
+		canonicalJSON: canonicalJSON,
+		mgr:           mgr,
+		responsePipe:  responsePipe,
+		shutSig:       shutdown.NewSignaller(),
+	}
+	if responsePipe != "" {
+		h.responseChan = make(chan message.Transaction)
+		mgr.SetPipe(responsePipe, h.responseChan)
+	}
+	return h, nil
 }
 
 func (h *httpClientWriter) Connect(ctx context.Context) error {
@@ -185,8 +227,35 @@ func (h *httpClientWriter) Connect(ctx context.Context) error {
 	return nil
 }
 
+// canonicalJSONBatch returns a copy of a batch where the contents of each
+// message have been serialized as canonical JSON.
+func canonicalJSONBatch(msg message.Batch) (message.Batch, error) {
+	newMsg := make(message.Batch, len(msg))
+	for i, p := range msg {
+		v, err := p.AsStructured()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse message %v as JSON: %w", i, err)
+		}
+		cBytes, err := message.MarshalCanonicalJSON(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize message %v as canonical JSON: %w", i, err)
+		}
+		newMsg[i] = p.ShallowCopy()
+		newMsg[i].SetBytes(cBytes)
+	}
+	return newMsg, nil
+}
+
 func (h *httpClientWriter) WriteBatch(ctx context.Context, msg message.Batch) error {
-	resultMsg, err := h.client.Send(ctx, msg)
+	reqMsg := msg
+	if h.canonicalJSON {
+		var err error
+		if reqMsg, err = canonicalJSONBatch(msg); err != nil {
+			return err
+		}
+	}
+
+	resultMsg, err := h.client.Send(ctx, reqMsg)
 	if err == nil && h.propResponse {
 		parts := make([]*message.Part, resultMsg.Len())
 		_ = resultMsg.Iter(func(i int, p *message.Part) error {
@@ -208,9 +277,75 @@ func (h *httpClientWriter) WriteBatch(ctx context.Context, msg message.Batch) er
 			h.log.Warnf("Unable to propagate response to input: %v", err)
 		}
 	}
+	if err == nil && h.responsePipe != "" {
+		err = h.captureResponse(ctx, msg, resultMsg)
+	}
 	return err
 }
 
+// captureResponse writes the responses of a request to the response inproc
+// pipe and waits for them to be acknowledged.
+func (h *httpClientWriter) captureResponse(ctx context.Context, reqMsg, resMsg message.Batch) error {
+	parts := make(message.Batch, resMsg.Len())
+	_ = resMsg.Iter(func(i int, p *message.Part) error {
+		reqPart := reqMsg.Get(0)
+		if i < reqMsg.Len() {
+			reqPart = reqMsg.Get(i)
+		}
+		parts[i] = reqPart.ShallowCopy()
+		parts[i].SetBytes(p.AsBytes())
+		_ = p.MetaIterMut(func(k string, v any) error {
+			parts[i].MetaSetMut(k, v)
+			return nil
+		})
+		return nil
+	})
+
+	resChan := make(chan error, 1)
+	if err := h.sendResponse(ctx, message.NewTransaction(parts, resChan)); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-resChan:
+		if err != nil {
+			return fmt.Errorf("failed to deliver response to inproc '%v': %w", h.responsePipe, err)
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// sendResponse writes a transaction to the response inproc pipe, the pipe is
+// held open until the send has completed or the writer is closed.
+func (h *httpClientWriter) sendResponse(ctx context.Context, tran message.Transaction) error {
+	h.responseMut.RLock()
+	defer h.responseMut.RUnlock()
+
+	if h.responseChan == nil {
+		return component.ErrTypeClosed
+	}
+	select {
+	case h.responseChan <- tran:
+	case <-h.shutSig.CloseNowChan():
+		return component.ErrTypeClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
 func (h *httpClientWriter) Close(ctx context.Context) error {
+	// Pending sends to the response pipe are abandoned before it is closed.
+	h.shutSig.CloseNow()
+
+	h.responseMut.Lock()
+	if h.responseChan != nil {
+		h.mgr.UnsetPipe(h.responsePipe, h.responseChan)
+		close(h.responseChan)
+		h.responseChan = nil
+	}
+	h.responseMut.Unlock()
 	return h.client.Close(ctx)
 }
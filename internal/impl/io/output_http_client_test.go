@@ -3,6 +3,7 @@ package io
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
@@ -10,6 +11,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -18,6 +20,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/output"
 	"github.com/benthosdev/benthos/v4/internal/manager/mock"
 	"github.com/benthosdev/benthos/v4/internal/message"
@@ -336,6 +339,143 @@ http_client:
 	require.NoError(t, h.WaitForClose(ctx))
 }
 
+func TestHTTPClientResponseInproc(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, _ = w.Write([]byte("echo: "))
+		_, _ = w.Write(b)
+	}))
+	defer ts.Close()
+
+	conf := parseYAMLOutputConf(t, `
+http_client:
+  url: %v/testpost
+  response_inproc: responses
+`, ts.URL)
+
+	mgr := mock.NewManager()
+	h, err := mgr.NewOutput(conf)
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, h.Consume(tChan))
+
+	resPipe, err := mgr.GetPipe("responses")
+	require.NoError(t, err)
+
+	resultChan := make(chan message.Batch)
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		for tran := range resPipe {
+			var ackErr error
+			if string(tran.Payload.Get(0).AsBytes()) == "echo: reject" {
+				ackErr = errors.New("nope")
+			} else {
+				resultChan <- tran.Payload.ShallowCopy()
+			}
+			assert.NoError(t, tran.Ack(ctx, ackErr))
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		testStr := fmt.Sprintf("test%v", i)
+
+		testPart := message.NewPart([]byte(testStr))
+		testPart.MetaSetMut("correlation_id", testStr)
+
+		writeErrChan := make(chan error, 1)
+		go func() {
+			writeErrChan <- writeBatchToChan(ctx, t, message.Batch{testPart}, tChan)
+		}()
+
+		var captured message.Batch
+		select {
+		case captured = <-resultChan:
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for response")
+		}
+		require.NoError(t, <-writeErrChan)
+
+		require.Equal(t, 1, captured.Len())
+		assert.Equal(t, "echo: "+testStr, string(captured.Get(0).AsBytes()))
+		assert.Equal(t, testStr, captured.Get(0).MetaGetStr("correlation_id"))
+		assert.Equal(t, "200", captured.Get(0).MetaGetStr("http_status_code"))
+
+		// The request message itself should not be modified.
+		assert.Equal(t, testStr, string(testPart.AsBytes()))
+	}
+
+	err = writeBatchToChan(ctx, t, message.QuickBatch([][]byte{[]byte("reject")}), tChan)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to deliver response to inproc 'responses': nope")
+
+	h.TriggerCloseNow()
+	require.NoError(t, h.WaitForClose(ctx))
+
+	// Closing the output should close the inproc pipe.
+	select {
+	case <-consumerDone:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for response pipe to close")
+	}
+}
+
+func TestHTTPClientResponseInprocCloseDuringWrites(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	pConf, err := httpClientOutputSpec().ParseYAML(fmt.Sprintf(`
+url: %v/testpost
+response_inproc: responses
+`, ts.URL), nil)
+	require.NoError(t, err)
+
+	mgr := mock.NewManager()
+	w, err := newHTTPClientOutputFromParsed(pConf, mgr)
+	require.NoError(t, err)
+
+	resPipe, err := mgr.GetPipe("responses")
+	require.NoError(t, err)
+	go func() {
+		for tran := range resPipe {
+			_ = tran.Ack(ctx, nil)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				// Writes either succeed or fail once the writer is closed, but
+				// must never send to a closed response pipe.
+				_ = w.WriteBatch(ctx, message.QuickBatch([][]byte{[]byte("hello")}))
+			}
+		}()
+	}
+
+	require.NoError(t, w.Close(ctx))
+	wg.Wait()
+
+	require.NoError(t, w.Close(ctx))
+	err = w.WriteBatch(ctx, message.QuickBatch([][]byte{[]byte("hello")}))
+	require.ErrorIs(t, err, component.ErrTypeClosed)
+}
+
 func TestHTTPClientSyncResponseCopyHeaders(t *testing.T) {
 	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
 	defer done()
@@ -661,3 +801,49 @@ http_client:
 	h.TriggerCloseNow()
 	require.NoError(t, h.WaitForClose(ctx))
 }
+
+func TestHTTPClientCanonicalJSON(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	resultChan := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		resultChan <- string(b)
+	}))
+	defer ts.Close()
+
+	conf := parseYAMLOutputConf(t, `
+http_client:
+  url: %v/testpost
+  canonical_json: true
+`, ts.URL)
+
+	h, err := mock.NewManager().NewOutput(conf)
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, h.Consume(tChan))
+
+	for _, input := range []string{
+		`{"b":1.0,"a":"<foo>","c":{"z":[1e2,true],"y":null}}`,
+		`{ "c": { "y": null, "z": [ 100, true ] }, "a": "<foo>", "b": 1 }`,
+	} {
+		require.NoError(t, writeBatchToChan(ctx, t, message.QuickBatch([][]byte{[]byte(input)}), tChan))
+
+		select {
+		case res := <-resultChan:
+			assert.Equal(t, `{"a":"<foo>","b":1,"c":{"y":null,"z":[100,true]}}`, res)
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	err = writeBatchToChan(ctx, t, message.QuickBatch([][]byte{[]byte(`not json`)}), tChan)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse message 0 as JSON")
+
+	h.TriggerCloseNow()
+	require.NoError(t, h.WaitForClose(ctx))
+}
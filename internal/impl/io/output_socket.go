@@ -29,6 +29,7 @@ func init() {
 			),
 			docs.FieldString("address", "The address (or path) to connect to.", "/tmp/benthos.sock", "localhost:9000"),
 			codec.WriterDocs,
+			codec.WriterCompressionDocs,
 		).ChildDefaultAndTypesFromStruct(output.NewSocketConfig()),
 		Categories: []string{
 			"Network",
@@ -65,7 +66,7 @@ func newSocketWriter(conf output.SocketConfig, mgr bundle.NewManagement, log log
 	default:
 		return nil, fmt.Errorf("socket network '%v' is not supported by this output", conf.Network)
 	}
-	codec, codecConf, err := codec.GetWriter(conf.Codec)
+	codec, codecConf, err := codec.GetCompressedWriter(conf.Codec, conf.Compression, mgr.FS())
 	if err != nil {
 		return nil, err
 	}
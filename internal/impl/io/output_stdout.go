@@ -14,7 +14,13 @@ import (
 
 func init() {
 	err := bundle.AllOutputs.Add(processors.WrapConstructor(func(conf output.Config, nm bundle.NewManagement) (output.Streamed, error) {
-		f, err := newStdoutWriter(conf.STDOUT.Codec)
+		var f output.AsyncSink
+		var err error
+		if conf.STDOUT.Pretty.Enabled {
+			f, err = newStdoutPrettyWriter(conf.STDOUT.Pretty, os.Stdout)
+		} else {
+			f, err = newStdoutWriter(conf.STDOUT.Codec, conf.STDOUT.Compression, nm)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -27,8 +33,29 @@ func init() {
 		Name: "stdout",
 		Summary: `
 Prints messages to stdout as a continuous stream of data, dividing messages according to the specified codec.`,
+		Description: `
+### Pretty Printing
+
+When debugging a pipeline interactively it can be useful to print messages in a more readable format by enabling the ` + "[`pretty`](#pretty)" + ` mode, in which case the codec is ignored. Each message is printed with its metadata, and messages containing JSON are indented and highlighted with colors when writing to a terminal.
+
+` + "```yaml" + `
+output:
+  stdout:
+    pretty:
+      enabled: true
+` + "```" + ``,
 		Config: docs.FieldComponent().WithChildren(
 			codec.WriterDocs.AtVersion("3.46.0").HasDefault("lines"),
+			codec.WriterCompressionDocs,
+			docs.FieldObject("pretty", "Print messages in a human readable format intended for interactive debugging.").WithChildren(
+				docs.FieldBool("enabled", "Whether to print messages in a human readable format.").HasDefault(false),
+				docs.FieldBool("metadata", "Whether to print the metadata of each message.").HasDefault(true),
+				docs.FieldString("color", "Whether to highlight messages with colors.").HasAnnotatedOptions(
+					"auto", "Use colors only when writing to a terminal.",
+					"always", "Always use colors.",
+					"never", "Never use colors.",
+				).HasDefault("auto"),
+			).AtVersion("4.24.0").Advanced(),
 		),
 		Categories: []string{
 			"Local",
@@ -43,8 +70,8 @@ type stdoutWriter struct {
 	handle codec.Writer
 }
 
-func newStdoutWriter(codecStr string) (*stdoutWriter, error) {
-	codec, _, err := codec.GetWriter(codecStr)
+func newStdoutWriter(codecStr string, compConf codec.WriterCompressionConfig, mgr bundle.NewManagement) (*stdoutWriter, error) {
+	codec, _, err := codec.GetCompressedWriter(codecStr, compConf, mgr.FS())
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,189 @@
+package io
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/fatih/color"
+
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// stdoutPrettyWriter prints messages in an indented and colorized format
+// along with their metadata.
+type stdoutPrettyWriter struct {
+	metadata bool
+
+	header  *color.Color
+	metaKey *color.Color
+	key     *color.Color
+	str     *color.Color
+	num     *color.Color
+	literal *color.Color
+
+	mut   sync.Mutex
+	w     io.Writer
+	count int
+}
+
+func newStdoutPrettyWriter(conf output.STDOUTPrettyConfig, w io.Writer) (*stdoutPrettyWriter, error) {
+	p := &stdoutPrettyWriter{
+		metadata: conf.Metadata,
+		w:        w,
+		header:   color.New(color.Faint),
+		metaKey:  color.New(color.FgCyan),
+		key:      color.New(color.FgBlue, color.Bold),
+		str:      color.New(color.FgGreen),
+		num:      color.New(color.FgYellow),
+		literal:  color.New(color.FgMagenta),
+	}
+
+	var setColor func(c *color.Color)
+	switch conf.Color {
+	case "auto", "":
+		setColor = func(*color.Color) {}
+	case "always":
+		setColor = func(c *color.Color) { c.EnableColor() }
+	case "never":
+		setColor = func(c *color.Color) { c.DisableColor() }
+	default:
+		return nil, fmt.Errorf("color option %v not recognised", conf.Color)
+	}
+	for _, c := range []*color.Color{p.header, p.metaKey, p.key, p.str, p.num, p.literal} {
+		setColor(c)
+	}
+	return p, nil
+}
+
+func (p *stdoutPrettyWriter) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (p *stdoutPrettyWriter) WriteBatch(ctx context.Context, msg message.Batch) error {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	var buf bytes.Buffer
+	for i, part := range msg {
+		p.count++
+		if msg.Len() > 1 {
+			buf.WriteString(p.header.Sprintf("# message %v (batch index %v)", p.count, i))
+		} else {
+			buf.WriteString(p.header.Sprintf("# message %v", p.count))
+		}
+		buf.WriteByte('\n')
+
+		if p.metadata {
+			p.writeMetadata(&buf, part)
+		}
+		p.writeContent(&buf, part)
+		buf.WriteString("\n\n")
+	}
+	_, err := p.w.Write(buf.Bytes())
+	return err
+}
+
+func (p *stdoutPrettyWriter) writeMetadata(buf *bytes.Buffer, part *message.Part) {
+	var keys []string
+	_ = part.MetaIterMut(func(k string, _ any) error {
+		keys = append(keys, k)
+		return nil
+	})
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v, _ := part.MetaGetMut(k)
+		buf.WriteString(p.metaKey.Sprintf("@%v", k))
+		buf.WriteString(": ")
+		if s, isStr := v.(string); isStr {
+			buf.WriteString(s)
+		} else {
+			p.writeValue(buf, v, "")
+		}
+		buf.WriteByte('\n')
+	}
+}
+
+func (p *stdoutPrettyWriter) writeContent(buf *bytes.Buffer, part *message.Part) {
+	raw := part.AsBytes()
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		if v, err := part.AsStructured(); err == nil {
+			p.writeValue(buf, v, "")
+			return
+		}
+	}
+	buf.Write(raw)
+}
+
+func (p *stdoutPrettyWriter) writeValue(buf *bytes.Buffer, v any, indent string) {
+	switch t := v.(type) {
+	case map[string]any:
+		if len(t) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		childIndent := indent + "  "
+		buf.WriteString("{\n")
+		for i, k := range keys {
+			keyBytes, _ := json.Marshal(k)
+			buf.WriteString(childIndent)
+			buf.WriteString(p.key.Sprint(string(keyBytes)))
+			buf.WriteString(": ")
+			p.writeValue(buf, t[k], childIndent)
+			if i < len(keys)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent)
+		buf.WriteByte('}')
+	case []any:
+		if len(t) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		childIndent := indent + "  "
+		buf.WriteString("[\n")
+		for i, e := range t {
+			buf.WriteString(childIndent)
+			p.writeValue(buf, e, childIndent)
+			if i < len(t)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent)
+		buf.WriteByte(']')
+	case string:
+		strBytes, _ := json.Marshal(t)
+		buf.WriteString(p.str.Sprint(string(strBytes)))
+	case nil:
+		buf.WriteString(p.literal.Sprint("null"))
+	case bool:
+		buf.WriteString(p.literal.Sprint(t))
+	case json.Number, int, int64, uint64, float64:
+		buf.WriteString(p.num.Sprint(t))
+	default:
+		b, err := json.Marshal(t)
+		if err != nil {
+			b = []byte(fmt.Sprint(t))
+		}
+		buf.Write(b)
+	}
+}
+
+func (p *stdoutPrettyWriter) Close(ctx context.Context) error {
+	return nil
+}
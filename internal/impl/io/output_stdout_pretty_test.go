@@ -0,0 +1,73 @@
+package io
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func TestStdoutPrettyWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	conf := output.NewSTDOUTConfig().Pretty
+	conf.Color = "never"
+
+	w, err := newStdoutPrettyWriter(conf, &buf)
+	require.NoError(t, err)
+
+	part := message.NewPart([]byte(`{"b":[1,true,null],"a":"foo","c":{}}`))
+	part.MetaSetMut("topic", "bar")
+	part.MetaSetMut("structured", map[string]any{"baz": "buz"})
+
+	ctx := context.Background()
+	require.NoError(t, w.WriteBatch(ctx, message.Batch{part}))
+	require.NoError(t, w.WriteBatch(ctx, message.Batch{
+		message.NewPart([]byte("hello world")),
+		message.NewPart([]byte("{not json")),
+	}))
+
+	assert.Equal(t, `# message 1
+@structured: {
+  "baz": "buz"
+}
+@topic: bar
+{
+  "a": "foo",
+  "b": [
+    1,
+    true,
+    null
+  ],
+  "c": {}
+}
+
+# message 2 (batch index 0)
+hello world
+
+# message 3 (batch index 1)
+{not json
+
+`, buf.String())
+}
+
+func TestStdoutPrettyWriterColors(t *testing.T) {
+	var buf bytes.Buffer
+
+	conf := output.NewSTDOUTConfig().Pretty
+	conf.Color = "always"
+	conf.Metadata = false
+
+	w, err := newStdoutPrettyWriter(conf, &buf)
+	require.NoError(t, err)
+
+	require.NoError(t, w.WriteBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`{"a":"foo"}`),
+	})))
+	assert.Contains(t, buf.String(), "\x1b[32m\"foo\"\x1b[0m")
+}
@@ -40,6 +40,7 @@ func init() {
 			docs.FieldFloat("sampler_param", "A parameter to use for sampling. This field is unused for some sampling types.").Advanced().HasDefault(1.0),
 			docs.FieldString("tags", "A map of tags to add to tracing spans.").Map().Advanced().HasDefault(map[string]any{}),
 			docs.FieldString("flush_interval", "The period of time between each flush of tracing spans.").HasDefault(""),
+			tracer.TailSamplingFieldSpec(),
 		),
 	})
 }
@@ -107,8 +108,13 @@ func NewJaeger(config tracer.Config, _ bundle.NewManagement) (trace.TracerProvid
 		batchOpts = append(batchOpts, tracesdk.WithBatchTimeout(flushInterval))
 	}
 
+	spanProc, err := config.Jaeger.TailSampling.SpanProcessor(tracesdk.NewBatchSpanProcessor(exp, batchOpts...))
+	if err != nil {
+		return nil, err
+	}
+
 	return tracesdk.NewTracerProvider(
-		tracesdk.WithBatcher(exp, batchOpts...),
+		tracesdk.WithSpanProcessor(spanProc),
 		tracesdk.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attrs...)),
 		tracesdk.WithSampler(sampler),
 	), nil
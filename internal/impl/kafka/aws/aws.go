@@ -2,6 +2,15 @@ package aws
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/IBM/sarama"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 
 	"github.com/benthosdev/benthos/v4/internal/impl/kafka"
 	"github.com/benthosdev/benthos/v4/public/service"
@@ -32,4 +41,65 @@ func init() {
 			}, nil
 		}), nil
 	}
+
+	kafka.AWSSaramaTokenProviderFromConfigFn = func(c *service.ParsedConfig) (sarama.AccessTokenProvider, error) {
+		awsSession, err := sess.GetSession(c)
+		if err != nil {
+			return nil, err
+		}
+		if awsSession.Config.Region == nil || *awsSession.Config.Region == "" {
+			return nil, errors.New("a region must be specified for AWS_MSK_IAM authentication")
+		}
+		return &mskIAMTokenProvider{
+			region: *awsSession.Config.Region,
+			signer: v4.NewSigner(awsSession.Config.Credentials),
+		}, nil
+	}
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	mskIAMService   = "kafka-cluster"
+	mskIAMAction    = "kafka-cluster:Connect"
+	mskIAMUserAgent = "benthos"
+	mskIAMExpiry    = 15 * time.Minute
+)
+
+// mskIAMTokenProvider generates OAUTHBEARER tokens for AWS MSK IAM
+// authentication, which are base64 encoded presigned URLs for the
+// kafka-cluster:Connect action.
+type mskIAMTokenProvider struct {
+	region string
+	signer *v4.Signer
+}
+
+func (m *mskIAMTokenProvider) Token() (*sarama.AccessToken, error) {
+	token, err := mskIAMToken(m.signer, m.region, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &sarama.AccessToken{Token: token}, nil
+}
+
+func mskIAMToken(signer *v4.Signer, region string, now time.Time) (string, error) {
+	endpoint := fmt.Sprintf("https://kafka.%v.amazonaws.com/", region)
+
+	q := url.Values{}
+	q.Set("Action", mskIAMAction)
+
+	req, err := http.NewRequest(http.MethodGet, endpoint+"?"+q.Encode(), http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	if _, err := signer.Presign(req, nil, mskIAMService, region, mskIAMExpiry, now); err != nil {
+		return "", err
+	}
+
+	signedURL := req.URL
+	signedQuery := signedURL.Query()
+	signedQuery.Set("User-Agent", mskIAMUserAgent)
+	signedURL.RawQuery = signedQuery.Encode()
+
+	return base64.RawURLEncoding.EncodeToString([]byte(signedURL.String())), nil
 }
@@ -0,0 +1,38 @@
+package aws
+
+import (
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMSKIAMToken(t *testing.T) {
+	signer := v4.NewSigner(credentials.NewStaticCredentials("foo", "bar", ""))
+
+	token, err := mskIAMToken(signer, "eu-west-1", time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))
+	require.NoError(t, err)
+
+	rawURL, err := base64.RawURLEncoding.DecodeString(token)
+	require.NoError(t, err)
+
+	u, err := url.Parse(string(rawURL))
+	require.NoError(t, err)
+
+	assert.Equal(t, "https", u.Scheme)
+	assert.Equal(t, "kafka.eu-west-1.amazonaws.com", u.Host)
+
+	q := u.Query()
+	assert.Equal(t, "kafka-cluster:Connect", q.Get("Action"))
+	assert.Equal(t, "benthos", q.Get("User-Agent"))
+	assert.Equal(t, "AWS4-HMAC-SHA256", q.Get("X-Amz-Algorithm"))
+	assert.Equal(t, "20230102T030405Z", q.Get("X-Amz-Date"))
+	assert.Equal(t, "900", q.Get("X-Amz-Expires"))
+	assert.Equal(t, "foo/20230102/eu-west-1/kafka-cluster/aws4_request", q.Get("X-Amz-Credential"))
+	assert.NotEmpty(t, q.Get("X-Amz-Signature"))
+}
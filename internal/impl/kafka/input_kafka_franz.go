@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -13,6 +14,7 @@ import (
 	"github.com/twmb/franz-go/pkg/sasl"
 
 	"github.com/benthosdev/benthos/v4/internal/checkpoint"
+	"github.com/benthosdev/benthos/v4/internal/component/input/span"
 	"github.com/benthosdev/benthos/v4/internal/shutdown"
 	"github.com/benthosdev/benthos/v4/public/service"
 )
@@ -74,6 +76,19 @@ Finally, it's also possible to specify an explicit offset to consume from by add
 			Description("A rack identifier for this client.").
 			Default("").
 			Advanced()).
+		Field(service.NewStringField("instance_id").
+			Description("An optional static member identifier (`group.instance.id`) for this consumer when a `consumer_group` is specified. Static members that restart within the session timeout resume their previous partition assignments without triggering a rebalance of the group. The identifier must be unique amongst members of the group.").
+			Example("benthos-0").
+			Example("${HOSTNAME}").
+			Default("").
+			Advanced().
+			Version("4.24.0")).
+		Field(service.NewStringListField("rebalance_strategies").
+			Description("A priority-ordered list of partition assignment strategies to advertise to the group when a `consumer_group` is specified, the group leader selects the first strategy supported by all members. The `cooperative_sticky` strategy rebalances incrementally, where members keep consuming the partitions that remain assigned to them rather than revoking all partitions at the start of each rebalance. Cooperative and eager strategies can be listed together in order to migrate a group from one to the other. Options are `cooperative_sticky`, `sticky`, `range` and `roundrobin`.").
+			Example([]string{"cooperative_sticky", "range"}).
+			Default([]string{"cooperative_sticky"}).
+			Advanced().
+			Version("4.24.0")).
 		Field(service.NewIntField("checkpoint_limit").
 			Description("Determines how many messages of the same partition can be processed in parallel before applying back pressure. When a message of a given offset is delivered to the output the offset is only allowed to be committed when all messages of prior offsets have also been delivered, this ensures at-least-once delivery guarantees. However, this mechanism also increases the likelihood of duplicates in the event of crashes or server faults, reducing the checkpoint limit will mitigate this.").
 			Default(1024).
@@ -92,6 +107,7 @@ Finally, it's also possible to specify an explicit offset to consume from by add
 		Field(service.NewBatchPolicyField("batching").
 			Description("Allows you to configure a [batching policy](/docs/configuration/batching) that applies to individual topic partitions in order to batch messages together before flushing them for processing. Batching can be beneficial for performance as well as useful for windowed processing, and doing so this way preserves the ordering of topic partitions.").
 			Advanced()).
+		Field(span.ExtractTracingSpanMappingDocs().Version("4.24.0")).
 		LintRule(`
 let has_topic_partitions = this.topics.any(t -> t.contains(":"))
 root = if $has_topic_partitions {
@@ -111,7 +127,7 @@ func init() {
 			if err != nil {
 				return nil, err
 			}
-			return service.AutoRetryNacksBatched(rdr), nil
+			return span.NewBatchInput("kafka_franz", conf, service.AutoRetryNacksBatched(rdr), mgr)
 		})
 	if err != nil {
 		panic(err)
@@ -132,6 +148,8 @@ type franzKafkaReader struct {
 	clientID        string
 	rackID          string
 	consumerGroup   string
+	instanceID      string
+	balancers       []kgo.GroupBalancer
 	tlsConf         *tls.Config
 	saslConfs       []sasl.Mechanism
 	checkpointLimit int
@@ -216,6 +234,14 @@ func newFranzKafkaReaderFromConfig(conf *service.ParsedConfig, res *service.Reso
 		return nil, err
 	}
 
+	if f.instanceID, err = conf.FieldString("instance_id"); err != nil {
+		return nil, err
+	}
+
+	if f.balancers, err = franzBalancersFromConfig(conf); err != nil {
+		return nil, err
+	}
+
 	if f.checkpointLimit, err = conf.FieldInt("checkpoint_limit"); err != nil {
 		return nil, err
 	}
@@ -245,6 +271,32 @@ func newFranzKafkaReaderFromConfig(conf *service.ParsedConfig, res *service.Reso
 	return &f, nil
 }
 
+func franzBalancersFromConfig(conf *service.ParsedConfig) ([]kgo.GroupBalancer, error) {
+	names, err := conf.FieldStringList("rebalance_strategies")
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, errors.New("at least one rebalance strategy must be specified")
+	}
+	balancers := make([]kgo.GroupBalancer, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case "cooperative_sticky":
+			balancers = append(balancers, kgo.CooperativeStickyBalancer())
+		case "sticky":
+			balancers = append(balancers, kgo.StickyBalancer())
+		case "range":
+			balancers = append(balancers, kgo.RangeBalancer())
+		case "roundrobin":
+			balancers = append(balancers, kgo.RoundRobinBalancer())
+		default:
+			return nil, fmt.Errorf("rebalance strategy not recognised: %v", name)
+		}
+	}
+	return balancers, nil
+}
+
 type msgWithRecord struct {
 	msg *service.Message
 	r   *kgo.Record
@@ -630,7 +682,11 @@ func (f *franzKafkaReader) Connect(ctx context.Context) error {
 			kgo.AutoCommitMarks(),
 			kgo.AutoCommitInterval(f.commitPeriod),
 			kgo.WithLogger(&kgoLogger{f.log}),
+			kgo.Balancers(f.balancers...),
 		)
+		if f.instanceID != "" {
+			clientOpts = append(clientOpts, kgo.InstanceID(f.instanceID))
+		}
 	}
 
 	if f.tlsConf != nil {
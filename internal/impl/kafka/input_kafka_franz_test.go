@@ -0,0 +1,40 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestFranzKafkaInputRebalanceStrategies(t *testing.T) {
+	pConf, err := franzKafkaInputConfig().ParseYAML(`
+seed_brokers: [ example.com:1234 ]
+topics: [ foo ]
+consumer_group: bar
+instance_id: baz
+rebalance_strategies: [ cooperative_sticky, range ]
+`, nil)
+	require.NoError(t, err)
+
+	r, err := newFranzKafkaReaderFromConfig(pConf, service.MockResources())
+	require.NoError(t, err)
+
+	assert.Equal(t, "baz", r.instanceID)
+	require.Len(t, r.balancers, 2)
+	assert.Equal(t, "cooperative-sticky", r.balancers[0].ProtocolName())
+	assert.Equal(t, "range", r.balancers[1].ProtocolName())
+
+	pConf, err = franzKafkaInputConfig().ParseYAML(`
+seed_brokers: [ example.com:1234 ]
+topics: [ foo ]
+consumer_group: bar
+rebalance_strategies: [ sticky, nope ]
+`, nil)
+	require.NoError(t, err)
+
+	_, err = newFranzKafkaReaderFromConfig(pConf, service.MockResources())
+	require.EqualError(t, err, "rebalance strategy not recognised: nope")
+}
@@ -3,6 +3,7 @@ package kafka
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
@@ -30,6 +31,8 @@ const (
 	iskFieldGroupSessionTimeout           = "session_timeout"
 	iskFieldGroupSessionHeartbeatInterval = "heartbeat_interval"
 	iskFieldGroupSessionRebalanceTimeout  = "rebalance_timeout"
+	iskFieldGroupInstanceID               = "instance_id"
+	iskFieldGroupRebalanceStrategies      = "rebalance_strategies"
 	iskFieldFetchBufferCap                = "fetch_buffer_cap"
 	iskFieldMultiHeader                   = "multi_header"
 	iskFieldBatching                      = "batching"
@@ -133,6 +136,17 @@ Unfortunately this error message will appear for a wide range of connection prob
 				service.NewDurationField(iskFieldGroupSessionRebalanceTimeout).
 					Description("A period after which rebalancing is abandoned if unresolved.").
 					Default("60s"),
+				service.NewStringField(iskFieldGroupInstanceID).
+					Description("An optional static member identifier (`group.instance.id`) for this consumer. Static members that restart within the `session_timeout` resume their previous partition assignments without triggering a rebalance of the group, which avoids stop-the-world partition revocations during rolling deployments. The identifier must be unique amongst members of the group and requires a `target_version` of at least `2.3.0`.").
+					Example("benthos-0").
+					Example("${HOSTNAME}").
+					Default("").
+					Version("4.24.0"),
+				service.NewStringListField(iskFieldGroupRebalanceStrategies).
+					Description("A priority-ordered list of partition assignment strategies to advertise to the group, the group leader selects the first strategy supported by all members. The `sticky` strategy preserves as many existing assignments as possible when the membership of the group changes. Cooperative incremental rebalancing is not supported by this input, the `kafka_franz` input should be used instead as it rebalances incrementally by default. Options are `range`, `roundrobin` and `sticky`.").
+					Example([]string{"sticky", "range"}).
+					Default([]string{"range"}).
+					Version("4.24.0"),
 			).
 				Description("Tuning parameters for consumer group synchronization.").
 				Advanced(),
@@ -468,6 +482,12 @@ func (k *kafkaReader) saramaConfigFromParsed(conf *service.ParsedConfig) (*saram
 		if config.Consumer.Group.Rebalance.Timeout, err = cConf.FieldDuration(iskFieldGroupSessionRebalanceTimeout); err != nil {
 			return nil, err
 		}
+		if config.Consumer.Group.InstanceId, err = cConf.FieldString(iskFieldGroupInstanceID); err != nil {
+			return nil, err
+		}
+		if config.Consumer.Group.Rebalance.GroupStrategies, err = saramaBalanceStrategiesFromConfig(cConf); err != nil {
+			return nil, err
+		}
 	}
 	if config.ChannelBufferSize, err = conf.FieldInt(iskFieldFetchBufferCap); err != nil {
 		return nil, err
@@ -494,6 +514,30 @@ func (k *kafkaReader) saramaConfigFromParsed(conf *service.ParsedConfig) (*saram
 	return config, nil
 }
 
+func saramaBalanceStrategiesFromConfig(conf *service.ParsedConfig) ([]sarama.BalanceStrategy, error) {
+	names, err := conf.FieldStringList(iskFieldGroupRebalanceStrategies)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, errors.New("at least one rebalance strategy must be specified")
+	}
+	strategies := make([]sarama.BalanceStrategy, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case sarama.RangeBalanceStrategyName:
+			strategies = append(strategies, sarama.NewBalanceStrategyRange())
+		case sarama.RoundRobinBalanceStrategyName:
+			strategies = append(strategies, sarama.NewBalanceStrategyRoundRobin())
+		case sarama.StickyBalanceStrategyName:
+			strategies = append(strategies, sarama.NewBalanceStrategySticky())
+		default:
+			return nil, fmt.Errorf("rebalance strategy not recognised: %v", name)
+		}
+	}
+	return strategies, nil
+}
+
 // Connect establishes a kafkaReader connection.
 func (k *kafkaReader) Connect(ctx context.Context) error {
 	k.cMut.Lock()
@@ -58,3 +58,19 @@ kafka:
 		})
 	}
 }
+
+func TestKafkaBadRebalanceStrategy(t *testing.T) {
+	conf := parseYAMLInputConf(t, `
+kafka:
+  addresses: [ example.com:1234 ]
+  topics: [ foo ]
+  consumer_group: bar
+  group:
+    instance_id: baz
+    rebalance_strategies: [ sticky, nope ]
+`)
+
+	_, err := mock.NewManager().NewInput(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rebalance strategy not recognised: nope")
+}
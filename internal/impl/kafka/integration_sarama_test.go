@@ -418,6 +418,44 @@ input:
 			integration.StreamTestOptVarThree("false"),
 		)
 	})
+
+	templateCustomTopic := `
+output:
+  kafka:
+    addresses: [ localhost:$PORT ]
+    topic: topic-$ID
+    max_in_flight: $MAX_IN_FLIGHT
+    allow_auto_topic_creation: false
+    custom_topic_creation:
+      enabled: true
+      partitions: 2
+      replication_factor: 1
+    batching:
+      count: $OUTPUT_BATCH_COUNT
+
+input:
+  kafka:
+    addresses: [ localhost:$PORT ]
+    topics: [ topic-$ID ]
+    consumer_group: "$VAR4"
+    start_from_oldest: true
+    batching:
+      count: $INPUT_BATCH_COUNT
+`
+
+	t.Run("custom_topic_creation", func(t *testing.T) {
+		t.Parallel()
+		integration.StreamTests(
+			integration.StreamTestOpenClose(),
+			integration.StreamTestSendBatch(10),
+		).Run(
+			t, templateCustomTopic,
+			integration.StreamTestOptPreTest(func(t testing.TB, ctx context.Context, testID string, vars *integration.StreamTestConfigVars) {
+				vars.Var4 = "group" + testID
+			}),
+			integration.StreamTestOptPort(kafkaPortStr),
+		)
+	})
 }
 
 func TestIntegrationSaramaOld(t *testing.T) {
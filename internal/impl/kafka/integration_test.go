@@ -205,6 +205,44 @@ input:
 			integration.StreamTestOptPort(kafkaPortStr),
 		)
 	})
+
+	customTopicTemplate := `
+output:
+  kafka_franz:
+    seed_brokers: [ localhost:$PORT ]
+    topic: topic-$ID
+    max_in_flight: $MAX_IN_FLIGHT
+    timeout: "5s"
+    allow_auto_topic_creation: false
+    custom_topic_creation:
+      enabled: true
+      partitions: 2
+      replication_factor: 1
+    metadata:
+      include_patterns: [ .* ]
+    batching:
+      count: $OUTPUT_BATCH_COUNT
+
+input:
+  kafka_franz:
+    seed_brokers: [ localhost:$PORT ]
+    topics: [ topic-$ID ]
+    consumer_group: "$VAR4"
+    checkpoint_limit: 100
+    commit_period: "1s"
+`
+	t.Run("custom_topic_creation", func(t *testing.T) {
+		integration.StreamTests(
+			integration.StreamTestOpenClose(),
+			integration.StreamTestSendBatch(10),
+		).Run(
+			t, customTopicTemplate,
+			integration.StreamTestOptPreTest(func(t testing.TB, ctx context.Context, testID string, vars *integration.StreamTestConfigVars) {
+				vars.Var4 = "group" + testID
+			}),
+			integration.StreamTestOptPort(kafkaPortStr),
+		)
+	})
 }
 
 func createKafkaTopicSasl(address, id string, partitions int32) error {
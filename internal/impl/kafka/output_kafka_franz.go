@@ -3,16 +3,21 @@ package kafka
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"math"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dustin/go-humanize"
+	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
 	"github.com/twmb/franz-go/pkg/sasl"
 
+	"github.com/benthosdev/benthos/v4/internal/component/output/span"
 	"github.com/benthosdev/benthos/v4/public/service"
 )
 
@@ -48,6 +53,25 @@ This output often out-performs the traditional ` + "`kafka`" + ` output as well
 			Description("An optional explicit partition to set for each message. This field is only relevant when the `partitioner` is set to `manual`. The provided interpolation string must be a valid integer.").
 			Example(`${! meta("partition") }`).
 			Optional()).
+		Field(service.NewBoolField("allow_auto_topic_creation").
+			Description("Whether the broker is allowed to automatically create topics that do not exist when messages are published to them, following the broker configured defaults.").
+			Default(true).
+			Advanced().
+			Version("4.24.0")).
+		Field(service.NewObjectField("custom_topic_creation",
+			service.NewBoolField("enabled").
+				Description("Whether to enable custom topic creation.").Default(false),
+			service.NewIntField("partitions").
+				Description("The number of partitions to create for new topics. Leave at -1 to use the broker configured default. Must be >= 1.").
+				Default(-1),
+			service.NewIntField("replication_factor").
+				Description("The replication factor to use for new topics. Leave at -1 to use the broker configured default. Must be less than or equal to the number of brokers.").
+				Default(-1),
+		).
+			Description("If enabled, topics that do not already exist will be created with the specified number of partitions and replication factor before messages are published to them.").
+			Advanced().
+			Optional().
+			Version("4.24.0")).
 		Field(service.NewStringField("client_id").
 			Description("An identifier for the client connection.").
 			Default("benthos").
@@ -79,6 +103,7 @@ This output often out-performs the traditional ` + "`kafka`" + ` output as well
 			Advanced()).
 		Field(service.NewTLSToggledField("tls")).
 		Field(saslField()).
+		Field(span.InjectTracingSpanMappingDocs().Version("4.24.0")).
 		LintRule(`
 root = if this.partitioner == "manual" {
   if this.partition.or("") == "" {
@@ -103,7 +128,10 @@ func init() {
 			if batchPolicy, err = conf.FieldBatchPolicy("batching"); err != nil {
 				return
 			}
-			output, err = newFranzKafkaWriterFromConfig(conf, mgr.Logger())
+			if output, err = newFranzKafkaWriterFromConfig(conf, mgr.Logger()); err != nil {
+				return
+			}
+			output, err = span.NewBatchOutput("kafka_franz", conf, output, mgr)
 			return
 		})
 	if err != nil {
@@ -125,11 +153,16 @@ type franzKafkaWriter struct {
 	saslConfs        []sasl.Mechanism
 	metaFilter       *service.MetadataFilter
 	partitioner      kgo.Partitioner
+	allowAutoTopics  bool
+	customTopics     bool
+	customTopicParts int32
+	customTopicRepls int16
 	timeout          time.Duration
 	produceMaxBytes  int32
 	compressionPrefs []kgo.CompressionCodec
 
-	client *kgo.Client
+	client        *kgo.Client
+	createdTopics sync.Map
 
 	log *service.Logger
 }
@@ -227,6 +260,31 @@ func newFranzKafkaWriterFromConfig(conf *service.ParsedConfig, log *service.Logg
 		}
 	}
 
+	if f.allowAutoTopics, err = conf.FieldBool("allow_auto_topic_creation"); err != nil {
+		return nil, err
+	}
+
+	if conf.Contains("custom_topic_creation") {
+		cConf := conf.Namespace("custom_topic_creation")
+		if f.customTopics, err = cConf.FieldBool("enabled"); err != nil {
+			return nil, err
+		}
+		var parts, repls int
+		if parts, err = cConf.FieldInt("partitions"); err != nil {
+			return nil, err
+		}
+		if repls, err = cConf.FieldInt("replication_factor"); err != nil {
+			return nil, err
+		}
+		if parts != -1 && parts < 1 {
+			return nil, fmt.Errorf("custom_topic_creation.partitions must be at least one, got %v", parts)
+		}
+		if repls != -1 && repls < 1 {
+			return nil, fmt.Errorf("custom_topic_creation.replication_factor must be at least one, got %v", repls)
+		}
+		f.customTopicParts, f.customTopicRepls = int32(parts), int16(repls)
+	}
+
 	if f.clientID, err = conf.FieldString("client_id"); err != nil {
 		return nil, err
 	}
@@ -265,13 +323,15 @@ func (f *franzKafkaWriter) Connect(ctx context.Context) error {
 	clientOpts := []kgo.Opt{
 		kgo.SeedBrokers(f.seedBrokers...),
 		kgo.SASL(f.saslConfs...),
-		kgo.AllowAutoTopicCreation(), // TODO: Configure this
 		kgo.ProducerBatchMaxBytes(f.produceMaxBytes),
 		kgo.ProduceRequestTimeout(f.timeout),
 		kgo.ClientID(f.clientID),
 		kgo.Rack(f.rackID),
 		kgo.WithLogger(&kgoLogger{f.log}),
 	}
+	if f.allowAutoTopics {
+		clientOpts = append(clientOpts, kgo.AllowAutoTopicCreation())
+	}
 	if f.tlsConf != nil {
 		clientOpts = append(clientOpts, kgo.DialTLSConfig(f.tlsConf))
 	}
@@ -303,6 +363,11 @@ func (f *franzKafkaWriter) WriteBatch(ctx context.Context, b service.MessageBatc
 		if topic, err = b.TryInterpolatedString(i, f.topic); err != nil {
 			return fmt.Errorf("topic interpolation error: %w", err)
 		}
+		if f.customTopics {
+			if err = f.createTopic(ctx, topic); err != nil {
+				return fmt.Errorf("failed to create topic '%v': %w", topic, err)
+			}
+		}
 
 		record := &kgo.Record{Topic: topic}
 		if record.Value, err = msg.AsBytes(); err != nil {
@@ -340,6 +405,36 @@ func (f *franzKafkaWriter) WriteBatch(ctx context.Context, b service.MessageBatc
 	return
 }
 
+// createTopic creates a topic with the configured partitions and replication
+// factor, unless it has already been created or found to exist by this writer.
+func (f *franzKafkaWriter) createTopic(ctx context.Context, topic string) error {
+	if _, exists := f.createdTopics.Load(topic); exists {
+		return nil
+	}
+
+	req := kmsg.NewPtrCreateTopicsRequest()
+	req.TimeoutMillis = int32(f.timeout.Milliseconds())
+
+	reqTopic := kmsg.NewCreateTopicsRequestTopic()
+	reqTopic.Topic = topic
+	reqTopic.NumPartitions = f.customTopicParts
+	reqTopic.ReplicationFactor = f.customTopicRepls
+	req.Topics = append(req.Topics, reqTopic)
+
+	res, err := req.RequestWith(ctx, f.client)
+	if err != nil {
+		return err
+	}
+	for _, t := range res.Topics {
+		if err := kerr.ErrorForCode(t.ErrorCode); err != nil && !errors.Is(err, kerr.TopicAlreadyExists) {
+			return err
+		}
+	}
+
+	f.createdTopics.Store(topic, struct{}{})
+	return nil
+}
+
 func (f *franzKafkaWriter) disconnect() {
 	if f.client == nil {
 		return
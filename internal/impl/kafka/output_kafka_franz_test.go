@@ -68,3 +68,70 @@ kafka_franz:
 		})
 	}
 }
+
+func TestKafkaFranzOutputCustomTopicCreation(t *testing.T) {
+	testCases := []struct {
+		name        string
+		conf        string
+		errContains string
+	}{
+		{
+			name: "defaults",
+			conf: `
+seed_brokers: [ foo:1234 ]
+topic: foo
+custom_topic_creation:
+  enabled: true
+`,
+		},
+		{
+			name: "explicit partitions and replication factor",
+			conf: `
+seed_brokers: [ foo:1234 ]
+topic: foo
+custom_topic_creation:
+  enabled: true
+  partitions: 12
+  replication_factor: 3
+`,
+		},
+		{
+			name: "bad partitions",
+			conf: `
+seed_brokers: [ foo:1234 ]
+topic: foo
+custom_topic_creation:
+  enabled: true
+  partitions: 0
+`,
+			errContains: "partitions must be at least one",
+		},
+		{
+			name: "bad replication factor",
+			conf: `
+seed_brokers: [ foo:1234 ]
+topic: foo
+custom_topic_creation:
+  enabled: true
+  replication_factor: 0
+`,
+			errContains: "replication_factor must be at least one",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			pConf, err := franzKafkaOutputConfig().ParseYAML(test.conf, nil)
+			require.NoError(t, err)
+
+			_, err = newFranzKafkaWriterFromConfig(pConf, nil)
+			if test.errContains == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errContains)
+			}
+		})
+	}
+}
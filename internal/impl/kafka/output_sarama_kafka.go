@@ -29,6 +29,7 @@ const (
 	oskFieldKey                          = "key"
 	oskFieldPartitioner                  = "partitioner"
 	oskFieldPartition                    = "partition"
+	oskFieldAllowAutoTopicCreation       = "allow_auto_topic_creation"
 	oskFieldCustomTopic                  = "custom_topic_creation"
 	oskFieldCustomTopicEnabled           = "enabled"
 	oskFieldCustomTopicPartitions        = "partitions"
@@ -104,6 +105,9 @@ Unfortunately this error message will appear for a wide range of connection prob
 			service.NewInterpolatedStringField(oskFieldPartition).
 				Description("The manually-specified partition to publish messages to, relevant only when the field `partitioner` is set to `manual`. Must be able to parse as a 32-bit integer.").
 				Advanced().Default(""),
+			service.NewBoolField(oskFieldAllowAutoTopicCreation).
+				Description("Whether the broker is allowed to automatically create topics that do not exist when messages are published to them, following the broker configured defaults.").
+				Advanced().Default(true).Version("4.24.0"),
 			service.NewObjectField(oskFieldCustomTopic,
 				service.NewBoolField(oskFieldCustomTopicEnabled).
 					Description("Whether to enable custom topic creation.").Default(false),
@@ -417,6 +421,10 @@ func (k *kafkaWriter) saramaConfigFromParsed(conf *service.ParsedConfig) (*saram
 		return nil, err
 	}
 
+	if config.Metadata.AllowAutoTopicCreation, err = conf.FieldBool(oskFieldAllowAutoTopicCreation); err != nil {
+		return nil, err
+	}
+
 	if config.Producer.MaxMessageBytes, err = conf.FieldInt(oskFieldMaxMsgBytes); err != nil {
 		return nil, err
 	}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/IBM/sarama"
+	"golang.org/x/oauth2"
 
 	"github.com/benthosdev/benthos/v4/internal/impl/aws/config"
 	"github.com/benthosdev/benthos/v4/public/service"
@@ -23,6 +24,15 @@ func notImportedAWSFn(c *service.ParsedConfig) (sasl.Mechanism, error) {
 // AWSSASLFromConfigFn is populated with the child `aws` package when imported.
 var AWSSASLFromConfigFn = notImportedAWSFn
 
+func notImportedAWSSaramaFn(c *service.ParsedConfig) (sarama.AccessTokenProvider, error) {
+	return nil, errors.New("unable to configure AWS SASL as this binary does not import components/aws")
+}
+
+// AWSSaramaTokenProviderFromConfigFn is populated with the child `aws` package
+// when imported, and provides OAUTHBEARER tokens for AWS MSK IAM
+// authentication within the sarama components.
+var AWSSaramaTokenProviderFromConfigFn = notImportedAWSSaramaFn
+
 func saslField() *service.ConfigField {
 	return service.NewObjectListField("sasl",
 		service.NewStringAnnotatedEnumField("mechanism", map[string]string{
@@ -46,6 +56,7 @@ func saslField() *service.ConfigField {
 		service.NewStringMapField("extensions").
 			Description("Key/value pairs to add to OAUTHBEARER authentication requests.").
 			Optional(),
+		saslOAuth2Field(),
 		service.NewObjectField("aws", config.SessionFields()...).
 			Description("Contains AWS specific fields for when the `mechanism` is set to `AWS_MSK_IAM`.").
 			Optional(),
@@ -139,7 +150,21 @@ func oauthSaslFromConfig(c *service.ParsedConfig) (sasl.Mechanism, error) {
 			return nil, err
 		}
 	}
+	tokenSource, err := oauth2TokenSourceFromConfig(c)
+	if err != nil {
+		return nil, err
+	}
 	return oauth.Oauth(func(c context.Context) (oauth.Auth, error) {
+		if tokenSource != nil {
+			tok, err := tokenSource.Token()
+			if err != nil {
+				return oauth.Auth{}, err
+			}
+			return oauth.Auth{
+				Token:      tok.AccessToken,
+				Extensions: extensions,
+			}, nil
+		}
 		return oauth.Auth{
 			Token:      token,
 			Extensions: extensions,
@@ -196,6 +221,9 @@ const (
 	saramaFieldSASLAccessToken = "access_token"
 	saramaFieldSASLTokenCache  = "token_cache"
 	saramaFieldSASLTokenKey    = "token_key"
+	saramaFieldSASLAWS         = "aws"
+
+	saramaSASLTypeAWSMSKIAM = "AWS_MSK_IAM"
 )
 
 // SaramaSASLField returns a field spec definition for SASL within the sarama
@@ -209,6 +237,7 @@ func SaramaSASLField() *service.ConfigField {
 				"OAUTHBEARER":   "OAuth Bearer based authentication.",
 				"SCRAM-SHA-256": "Authentication using the SCRAM-SHA-256 mechanism.",
 				"SCRAM-SHA-512": "Authentication using the SCRAM-SHA-512 mechanism.",
+				"AWS_MSK_IAM":   "AWS IAM based authentication as specified by the 'aws-msk-iam-auth' java library. Requires TLS to be enabled.",
 			}).
 			Description("The SASL authentication mechanism, if left empty SASL authentication is not used. Warning: SCRAM based methods within Benthos have not received a security audit.").
 			Default("none"),
@@ -230,6 +259,11 @@ func SaramaSASLField() *service.ConfigField {
 		service.NewStringField(saramaFieldSASLTokenKey).
 			Description("Required when using a `token_cache`, the key to query the cache with for tokens.").
 			Default(""),
+		saslOAuth2Field(),
+		service.NewObjectField(saramaFieldSASLAWS, config.SessionFields()...).
+			Description("Contains AWS specific fields for when the `mechanism` is set to `AWS_MSK_IAM`.").
+			Optional().
+			Version("4.24.0"),
 	).
 		Description("Enables SASL authentication.").
 		Optional().
@@ -271,12 +305,28 @@ func ApplySaramaSASLFromParsed(pConf *service.ParsedConfig, mgr *service.Resourc
 		return nil
 	}
 
+	tokenSource, err := oauth2TokenSourceFromConfig(pConf)
+	if err != nil {
+		return err
+	}
+
 	switch mechanism {
+	case saramaSASLTypeAWSMSKIAM:
+		tp, err := AWSSaramaTokenProviderFromConfigFn(pConf.Namespace(saramaFieldSASLAWS))
+		if err != nil {
+			return err
+		}
+		conf.Net.SASL.TokenProvider = tp
+
+		// MSK IAM tokens are exchanged using the OAUTHBEARER mechanism.
+		mechanism = sarama.SASLTypeOAuth
 	case sarama.SASLTypeOAuth:
 		var tp sarama.AccessTokenProvider
 		var err error
 
-		if tokenCache != "" {
+		if tokenSource != nil {
+			tp = &oauth2AccessTokenProvider{src: tokenSource}
+		} else if tokenCache != "" {
 			if tp, err = newCacheAccessTokenProvider(mgr, tokenCache, tokenKey); err != nil {
 				return err
 			}
@@ -349,6 +399,22 @@ func (c *cacheAccessTokenProvider) Token() (*sarama.AccessToken, error) {
 
 //------------------------------------------------------------------------------
 
+// oauth2AccessTokenProvider provides SASL OAUTHBEARER access tokens fetched
+// from an OAuth2 token endpoint.
+type oauth2AccessTokenProvider struct {
+	src oauth2.TokenSource
+}
+
+func (o *oauth2AccessTokenProvider) Token() (*sarama.AccessToken, error) {
+	tok, err := o.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	return &sarama.AccessToken{Token: tok.AccessToken}, nil
+}
+
+//------------------------------------------------------------------------------
+
 // staticAccessTokenProvider provides a static SASL OAUTHBEARER access token.
 type staticAccessTokenProvider struct {
 	token string
@@ -0,0 +1,93 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	saslFieldOAuth2               = "oauth2"
+	saslFieldOAuth2Enabled        = "enabled"
+	saslFieldOAuth2ClientKey      = "client_key"
+	saslFieldOAuth2ClientSecret   = "client_secret"
+	saslFieldOAuth2TokenURL       = "token_url"
+	saslFieldOAuth2Scopes         = "scopes"
+	saslFieldOAuth2EndpointParams = "endpoint_params"
+)
+
+func saslOAuth2Field() *service.ConfigField {
+	return service.NewObjectField(saslFieldOAuth2,
+		service.NewBoolField(saslFieldOAuth2Enabled).
+			Description("Whether to fetch OAUTHBEARER tokens from a token endpoint.").
+			Default(false),
+		service.NewStringField(saslFieldOAuth2ClientKey).
+			Description("A value used to identify the client to the token provider.").
+			Default(""),
+		service.NewStringField(saslFieldOAuth2ClientSecret).
+			Description("A secret used to establish ownership of the client key.").
+			Default("").Secret(),
+		service.NewURLField(saslFieldOAuth2TokenURL).
+			Description("The URL of the token provider.").
+			Default(""),
+		service.NewStringListField(saslFieldOAuth2Scopes).
+			Description("A list of optional requested permissions.").
+			Default([]string{}),
+		service.NewStringMapField(saslFieldOAuth2EndpointParams).
+			Description("Optional parameters to add to token requests.").
+			Default(map[string]any{}).
+			Example(map[string]any{"audience": "kafka"}),
+	).
+		Description("Fetch OAUTHBEARER tokens from a token endpoint using the OAuth2 client credentials flow, rather than using a static token. Tokens are cached and refreshed before they expire.").
+		Advanced().
+		Optional().
+		Version("4.24.0")
+}
+
+// oauth2TokenSourceFromConfig returns a token source when OAuth2 token fetching
+// is configured and enabled, otherwise nil is returned.
+func oauth2TokenSourceFromConfig(c *service.ParsedConfig) (oauth2.TokenSource, error) {
+	if !c.Contains(saslFieldOAuth2) {
+		return nil, nil
+	}
+	c = c.Namespace(saslFieldOAuth2)
+
+	enabled, err := c.FieldBool(saslFieldOAuth2Enabled)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	conf := &clientcredentials.Config{
+		EndpointParams: url.Values{},
+	}
+	if conf.ClientID, err = c.FieldString(saslFieldOAuth2ClientKey); err != nil {
+		return nil, err
+	}
+	if conf.ClientSecret, err = c.FieldString(saslFieldOAuth2ClientSecret); err != nil {
+		return nil, err
+	}
+	if conf.TokenURL, err = c.FieldString(saslFieldOAuth2TokenURL); err != nil {
+		return nil, err
+	}
+	if conf.TokenURL == "" {
+		return nil, errors.New("a token_url must be specified when oauth2 is enabled")
+	}
+	if conf.Scopes, err = c.FieldStringList(saslFieldOAuth2Scopes); err != nil {
+		return nil, err
+	}
+	params, err := c.FieldStringMap(saslFieldOAuth2EndpointParams)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range params {
+		conf.EndpointParams.Set(k, v)
+	}
+
+	// The returned token source caches tokens until they are close to expiry.
+	return conf.TokenSource(context.Background()), nil
+}
@@ -2,6 +2,9 @@ package kafka_test
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/IBM/sarama"
@@ -125,6 +128,66 @@ sasl:
 	}
 }
 
+func TestApplyOAuthBearerOAuth2Provider(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.NoError(t, r.ParseForm())
+		if r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("audience") != "kafka" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token":"foo%v","token_type":"bearer","expires_in":3600}`, requests)
+	}))
+	t.Cleanup(ts.Close)
+
+	saslConf := service.NewConfigSpec().Field(kafka.SaramaSASLField())
+	pConf, err := saslConf.ParseYAML(fmt.Sprintf(`
+sasl:
+  mechanism: OAUTHBEARER
+  oauth2:
+    enabled: true
+    client_key: meow
+    client_secret: woof
+    token_url: %v
+    endpoint_params:
+      audience: kafka
+`, ts.URL), nil)
+	require.NoError(t, err)
+
+	conf := &sarama.Config{}
+	require.NoError(t, kafka.ApplySaramaSASLFromParsed(pConf, service.MockResources(), conf))
+
+	if conf.Net.SASL.Mechanism != sarama.SASLTypeOAuth {
+		t.Errorf("Wrong SASL mechanism: %v != %v", conf.Net.SASL.Mechanism, sarama.SASLTypeOAuth)
+	}
+
+	for i := 0; i < 2; i++ {
+		token, err := conf.Net.SASL.TokenProvider.Token()
+		require.NoError(t, err)
+
+		// Tokens are cached until they expire.
+		if act := token.Token; act != "foo1" {
+			t.Errorf("Wrong SASL token: %v != %v", act, "foo1")
+		}
+	}
+}
+
+func TestApplyOAuthBearerOAuth2MissingURL(t *testing.T) {
+	saslConf := service.NewConfigSpec().Field(kafka.SaramaSASLField())
+	pConf, err := saslConf.ParseYAML(`
+sasl:
+  mechanism: OAUTHBEARER
+  oauth2:
+    enabled: true
+`, nil)
+	require.NoError(t, err)
+
+	conf := &sarama.Config{}
+	require.Error(t, kafka.ApplySaramaSASLFromParsed(pConf, service.MockResources(), conf))
+}
+
 func TestApplyUnknownMechanism(t *testing.T) {
 	saslConf := service.NewConfigSpec().Field(kafka.SaramaSASLField())
 	pConf, err := saslConf.ParseYAML(`
@@ -0,0 +1,303 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	kcFieldAPIURL    = "api_url"
+	kcFieldToken     = "token"
+	kcFieldTokenFile = "token_file"
+	kcFieldTLS       = "tls"
+
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount/"
+)
+
+func clientFields() []*service.ConfigField {
+	return []*service.ConfigField{
+		service.NewStringField(kcFieldAPIURL).
+			Description("The URL of the Kubernetes API server. When empty the address is obtained from the environment of the pod that Benthos is running within, along with the certificate authority of the cluster.").
+			Default("").
+			Example("https://kubernetes.default.svc"),
+		service.NewStringField(kcFieldToken).
+			Description("An optional bearer token to authenticate requests with, which takes precedence over `token_file`.").
+			Default("").
+			Secret().
+			Advanced(),
+		service.NewStringField(kcFieldTokenFile).
+			Description("The path of a file containing a bearer token to authenticate requests with, which is read for each request in order to support tokens that are rotated. The file is ignored when it does not exist.").
+			Default(serviceAccountDir + "token").
+			Advanced(),
+		service.NewTLSField(kcFieldTLS).
+			Description("Custom TLS settings can be used to override system defaults. When `api_url` is empty the certificate authority of the service account of the pod is trusted in addition to any root certificate authorities configured here.").
+			Advanced(),
+	}
+}
+
+// apiClient is a minimal client of the Kubernetes API that supports the
+// listing, watching and log streaming of resources.
+type apiClient struct {
+	baseURL   string
+	token     string
+	tokenFile string
+	http      *http.Client
+}
+
+func newAPIClient(conf *service.ParsedConfig) (*apiClient, error) {
+	c := &apiClient{}
+
+	var err error
+	if c.baseURL, err = conf.FieldString(kcFieldAPIURL); err != nil {
+		return nil, err
+	}
+	if c.token, err = conf.FieldString(kcFieldToken); err != nil {
+		return nil, err
+	}
+	if c.tokenFile, err = conf.FieldString(kcFieldTokenFile); err != nil {
+		return nil, err
+	}
+
+	tlsConf, err := conf.FieldTLS(kcFieldTLS)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.baseURL == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, errors.New("api_url must be set when not running within a Kubernetes cluster")
+		}
+		c.baseURL = "https://" + net.JoinHostPort(host, port)
+		if tlsConf, err = withClusterCA(tlsConf); err != nil {
+			return nil, err
+		}
+	}
+	c.baseURL = strings.TrimSuffix(c.baseURL, "/")
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConf
+	c.http = &http.Client{Transport: transport}
+	return c, nil
+}
+
+// withClusterCA adds the certificate authority of the service account of the
+// pod to the root certificate authorities of a TLS config, if it exists.
+func withClusterCA(tlsConf *tls.Config) (*tls.Config, error) {
+	caBytes, err := os.ReadFile(serviceAccountDir + "ca.crt")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return tlsConf, nil
+		}
+		return nil, fmt.Errorf("failed to read cluster certificate authority: %w", err)
+	}
+
+	if tlsConf == nil {
+		tlsConf = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if tlsConf.RootCAs == nil {
+		if tlsConf.RootCAs, err = x509.SystemCertPool(); err != nil {
+			tlsConf.RootCAs = x509.NewCertPool()
+		}
+	}
+	if !tlsConf.RootCAs.AppendCertsFromPEM(caBytes) {
+		return nil, errors.New("failed to parse cluster certificate authority")
+	}
+	return tlsConf, nil
+}
+
+// apiError is returned when the API responds with an unsuccessful status.
+type apiError struct {
+	Code    int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("kubernetes API responded with status %v: %v", e.Code, e.Message)
+}
+
+// isStatusCode returns true if an error is an apiError of a given status code.
+func isStatusCode(err error, code int) bool {
+	var aErr *apiError
+	return errors.As(err, &aErr) && aErr.Code == code
+}
+
+func (c *apiClient) bearerToken() (string, error) {
+	if c.token != "" || c.tokenFile == "" {
+		return c.token, nil
+	}
+	tokenBytes, err := os.ReadFile(c.tokenFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	return strings.TrimSpace(string(tokenBytes)), nil
+}
+
+// get performs a GET request against a path of the API and returns the
+// response when successful, in which case the body must be closed by the
+// caller.
+func (c *apiClient) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.bearerToken()
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return nil, statusError(res.StatusCode, body)
+	}
+	return res, nil
+}
+
+// statusError creates an apiError from the body of a response, which is
+// usually a Status object.
+func statusError(code int, body []byte) error {
+	var status struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil || status.Message == "" {
+		status.Message = string(bytes.TrimSpace(body))
+	}
+	return &apiError{Code: code, Message: status.Message}
+}
+
+// objectMeta is the subset of the metadata of an object used by the inputs.
+type objectMeta struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace"`
+	ResourceVersion string            `json:"resourceVersion"`
+	Labels          map[string]string `json:"labels"`
+}
+
+// listMeta is the metadata of a list of objects.
+type listMeta struct {
+	ResourceVersion string `json:"resourceVersion"`
+	Continue        string `json:"continue"`
+}
+
+// list obtains all objects of a list request, following continue tokens, and
+// returns their raw JSON along with the resource version of the list.
+func (c *apiClient) list(ctx context.Context, path string, query url.Values) (items []json.RawMessage, resourceVersion string, err error) {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("limit", "500")
+	for {
+		var res *http.Response
+		if res, err = c.get(ctx, path, q); err != nil {
+			return
+		}
+		var page struct {
+			Metadata listMeta          `json:"metadata"`
+			Items    []json.RawMessage `json:"items"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&page)
+		res.Body.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decode list response: %w", err)
+		}
+		items = append(items, page.Items...)
+		resourceVersion = page.Metadata.ResourceVersion
+		if page.Metadata.Continue == "" {
+			return
+		}
+		q.Set("continue", page.Metadata.Continue)
+	}
+}
+
+// watchEvent is a single event of a watch stream.
+type watchEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// watch streams the events of a watch request from a resource version until
+// the stream is ended by the server, an error occurs or the context is
+// cancelled. The resource version of each event is passed to fn along with
+// the event, and a watch event of the type ERROR is returned as an apiError.
+func (c *apiClient) watch(ctx context.Context, path string, query url.Values, resourceVersion string, fn func(e watchEvent, meta objectMeta) error) error {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("watch", "true")
+	q.Set("allowWatchBookmarks", "true")
+	q.Set("resourceVersion", resourceVersion)
+
+	res, err := c.get(ctx, path, q)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	dec := json.NewDecoder(res.Body)
+	for {
+		var e watchEvent
+		if err := dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to decode watch event: %w", err)
+		}
+		if e.Type == "ERROR" {
+			var status struct {
+				Code int `json:"code"`
+			}
+			_ = json.Unmarshal(e.Object, &status)
+			return statusError(status.Code, e.Object)
+		}
+
+		var obj struct {
+			Metadata objectMeta `json:"metadata"`
+		}
+		if err := json.Unmarshal(e.Object, &obj); err != nil {
+			return fmt.Errorf("failed to decode watch event object: %w", err)
+		}
+		if err := fn(e, obj.Metadata); err != nil {
+			return err
+		}
+	}
+}
+
+// namespacedPath returns the API path of a resource within a namespace, or
+// within all namespaces when the namespace is empty.
+func namespacedPath(namespace, resource string) string {
+	if namespace == "" {
+		return "/api/v1/" + resource
+	}
+	return "/api/v1/namespaces/" + url.PathEscape(namespace) + "/" + resource
+}
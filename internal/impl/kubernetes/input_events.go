@@ -0,0 +1,283 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	keiFieldNamespace       = "namespace"
+	keiFieldFieldSelector   = "field_selector"
+	keiFieldIncludeExisting = "include_existing"
+)
+
+func eventsInputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Services").
+		Version("4.24.0").
+		Summary("Consumes events of a Kubernetes cluster, such as pods being scheduled, images being pulled and containers crashing.").
+		Description(`
+Events are obtained with the watch API of Kubernetes and each event is consumed as a message containing the JSON of the event object. Events that are repeated are updated by Kubernetes rather than created again, and therefore the updated event is consumed again with a greater `+"`count`"+`.
+
+When running within a cluster the address of the API server and the credentials of the service account of the pod are used by default, where the service account requires the permissions to list and watch events.
+
+Kubernetes only retains events for a limited time, and events are not stored by Benthos, therefore events that occur whilst Benthos is not running are only consumed if they still exist when Benthos starts and `+"`include_existing`"+` is `+"`true`"+`.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- kubernetes_watch_event_type
+- kubernetes_namespace
+- kubernetes_event_type
+- kubernetes_reason
+- kubernetes_involved_kind
+- kubernetes_involved_name
+`+"```"+`
+
+The field `+"`kubernetes_watch_event_type`"+` is either `+"`ADDED`"+` or `+"`MODIFIED`"+`, and `+"`kubernetes_event_type`"+` is the type of the event itself, such as `+"`Normal`"+` or `+"`Warning`"+`.
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).`).
+		Fields(clientFields()...).
+		Fields(
+			service.NewStringField(keiFieldNamespace).
+				Description("The namespace to consume events from. When empty events are consumed from all namespaces.").
+				Default("").
+				Example("default"),
+			service.NewStringField(keiFieldFieldSelector).
+				Description("An optional [field selector](https://kubernetes.io/docs/concepts/overview/working-with-objects/field-selectors/) to filter events by.").
+				Default("").
+				Example("type=Warning").
+				Example("involvedObject.kind=Pod,reason=BackOff"),
+			service.NewBoolField(keiFieldIncludeExisting).
+				Description("Whether to consume the events that already exist when the input connects.").
+				Default(false),
+		).
+		Example(
+			"Warnings",
+			"Here we ship the warning events of all namespaces to Elasticsearch.",
+			`
+input:
+  kubernetes_events:
+    field_selector: type=Warning
+
+output:
+  elasticsearch:
+    urls: [ http://localhost:9200 ]
+    index: kubernetes-events
+    id: ${! json("metadata.uid") }-${! json("count") }
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterInput(
+		"kubernetes_events", eventsInputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			r, err := newEventsReader(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacks(r), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type eventsReader struct {
+	log    *service.Logger
+	client *apiClient
+
+	path            string
+	fieldSelector   string
+	includeExisting bool
+
+	connMut sync.Mutex
+	msgChan chan *service.Message
+	shutSig *shutdown.Signaller
+}
+
+func newEventsReader(conf *service.ParsedConfig, mgr *service.Resources) (*eventsReader, error) {
+	r := &eventsReader{
+		log:     mgr.Logger(),
+		shutSig: shutdown.NewSignaller(),
+	}
+
+	var err error
+	if r.client, err = newAPIClient(conf); err != nil {
+		return nil, err
+	}
+
+	namespace, err := conf.FieldString(keiFieldNamespace)
+	if err != nil {
+		return nil, err
+	}
+	r.path = namespacedPath(namespace, "events")
+
+	if r.fieldSelector, err = conf.FieldString(keiFieldFieldSelector); err != nil {
+		return nil, err
+	}
+	if r.includeExisting, err = conf.FieldBool(keiFieldIncludeExisting); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *eventsReader) query() url.Values {
+	q := url.Values{}
+	if r.fieldSelector != "" {
+		q.Set("fieldSelector", r.fieldSelector)
+	}
+	return q
+}
+
+// event is the subset of an event object used for metadata.
+type event struct {
+	Type           string `json:"type"`
+	Reason         string `json:"reason"`
+	InvolvedObject struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	} `json:"involvedObject"`
+}
+
+func eventMessage(watchType string, meta objectMeta, raw json.RawMessage) *service.Message {
+	var e event
+	_ = json.Unmarshal(raw, &e)
+
+	msg := service.NewMessage(raw)
+	msg.MetaSetMut("kubernetes_watch_event_type", watchType)
+	msg.MetaSetMut("kubernetes_namespace", meta.Namespace)
+	msg.MetaSetMut("kubernetes_event_type", e.Type)
+	msg.MetaSetMut("kubernetes_reason", e.Reason)
+	msg.MetaSetMut("kubernetes_involved_kind", e.InvolvedObject.Kind)
+	msg.MetaSetMut("kubernetes_involved_name", e.InvolvedObject.Name)
+	return msg
+}
+
+func (r *eventsReader) Connect(ctx context.Context) error {
+	r.connMut.Lock()
+	defer r.connMut.Unlock()
+	if r.msgChan != nil {
+		return nil
+	}
+
+	items, resourceVersion, err := r.client.list(ctx, r.path, r.query())
+	if err != nil {
+		return err
+	}
+
+	msgChan := make(chan *service.Message)
+	go func() {
+		defer r.shutSig.ShutdownComplete()
+
+		ctx, done := r.shutSig.CloseAtLeisureCtx(context.Background())
+		defer done()
+
+		send := func(msg *service.Message) bool {
+			select {
+			case msgChan <- msg:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if r.includeExisting {
+			for _, raw := range items {
+				var obj struct {
+					Metadata objectMeta `json:"metadata"`
+				}
+				_ = json.Unmarshal(raw, &obj)
+				if !send(eventMessage("ADDED", obj.Metadata, raw)) {
+					return
+				}
+			}
+		}
+
+		for ctx.Err() == nil {
+			err := r.client.watch(ctx, r.path, r.query(), resourceVersion, func(e watchEvent, meta objectMeta) error {
+				if meta.ResourceVersion != "" {
+					resourceVersion = meta.ResourceVersion
+				}
+				if e.Type != "ADDED" && e.Type != "MODIFIED" {
+					return nil
+				}
+				if !send(eventMessage(e.Type, meta, e.Object)) {
+					return ctx.Err()
+				}
+				return nil
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				continue
+			}
+			if isStatusCode(err, http.StatusGone) {
+				// The resource version has expired and therefore we need to
+				// list the events again, events that occurred in the meantime
+				// are lost.
+				r.log.Warnf("Watch of events expired, events may have been missed: %v", err)
+				if _, resourceVersion, err = r.client.list(ctx, r.path, r.query()); err == nil {
+					continue
+				}
+			}
+			r.log.Errorf("Failed to watch events: %v", err)
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	r.msgChan = msgChan
+	return nil
+}
+
+func (r *eventsReader) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	r.connMut.Lock()
+	msgChan := r.msgChan
+	r.connMut.Unlock()
+	if msgChan == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	select {
+	case msg := <-msgChan:
+		return msg, func(context.Context, error) error { return nil }, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+func (r *eventsReader) Close(ctx context.Context) error {
+	r.connMut.Lock()
+	if r.msgChan == nil {
+		// Indicates that we were never connected, so indicate shutdown is
+		// complete.
+		r.shutSig.ShutdownComplete()
+	}
+	r.connMut.Unlock()
+
+	r.shutSig.CloseAtLeisure()
+	select {
+	case <-r.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,390 @@
+package kubernetes
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/logtail"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	kliFieldNamespace     = "namespace"
+	kliFieldLabelSelector = "label_selector"
+	kliFieldContainer     = "container"
+	kliFieldSince         = "since"
+)
+
+func logsInputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Services").
+		Version("4.24.0").
+		Summary("Streams the logs of the containers of Kubernetes pods selected by labels, where each line of a log is consumed as a message.").
+		Description(`
+Pods matching the `+"`label_selector`"+` are discovered with the watch API of Kubernetes, and the logs of each of their running containers are streamed until the container terminates. The logs of pods that are created whilst the input is running are streamed from their beginning, whereas the logs of pods that already exist when the input connects are streamed from the time of connecting, or from the duration of `+"`since`"+` prior when set.
+
+When a log stream is interrupted, such as when the log file of a container is rotated by the kubelet, the stream is resumed from the timestamp of the last line consumed and lines that were already consumed are skipped.
+
+When running within a cluster the address of the API server and the credentials of the service account of the pod are used by default, where the service account requires the permissions to list and watch pods, and to get the logs of pods.
+
+Log lines are not stored by Kubernetes indefinitely and the position of each stream is not stored by Benthos, therefore lines that are logged whilst Benthos is not running are only consumed when `+"`since`"+` covers the downtime.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- kubernetes_namespace
+- kubernetes_pod
+- kubernetes_container
+- kubernetes_node
+- kubernetes_timestamp
+- kubernetes_label_* (one for each label of the pod)
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).`).
+		Fields(clientFields()...).
+		Fields(
+			service.NewStringField(kliFieldNamespace).
+				Description("The namespace of the pods to stream logs from. When empty pods of all namespaces are selected.").
+				Default("").
+				Example("default"),
+			service.NewStringField(kliFieldLabelSelector).
+				Description("A [label selector](https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#label-selectors) of the pods to stream logs from. When empty all pods are selected.").
+				Default("").
+				Example("app=nginx").
+				Example("app in (web, api),tier!=canary"),
+			service.NewStringField(kliFieldContainer).
+				Description("The name of a container to stream the logs of. When empty the logs of all containers of each pod are streamed.").
+				Default(""),
+			service.NewDurationField(kliFieldSince).
+				Description("An optional duration prior to connecting from which to stream the logs of pods that already exist.").
+				Example("10m").
+				Optional(),
+		).
+		Example(
+			"Shipping Logs",
+			"Here we ship the logs of all pods of an app to Loki via HTTP, with the pod and container of each line as labels.",
+			`
+input:
+  kubernetes_logs:
+    namespace: production
+    label_selector: app=checkout
+
+pipeline:
+  processors:
+    - mapping: |
+        root.streams = [{
+          "stream": {
+            "pod": @kubernetes_pod,
+            "container": @kubernetes_container
+          },
+          "values": [[ (@kubernetes_timestamp.ts_unix_nano()).string(), content().string() ]]
+        }]
+
+output:
+  http_client:
+    url: http://loki:3100/loki/api/v1/push
+    verb: POST
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterInput(
+		"kubernetes_logs", logsInputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			r, err := newLogsReader(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacks(r), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// pod is the subset of a pod object used for discovering containers.
+type pod struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		NodeName string `json:"nodeName"`
+	} `json:"spec"`
+	Status struct {
+		ContainerStatuses []struct {
+			Name  string `json:"name"`
+			State struct {
+				Running *struct{} `json:"running"`
+			} `json:"state"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+// runningContainers returns the names of the running containers of a pod.
+func (p *pod) runningContainers() (names []string) {
+	for _, c := range p.Status.ContainerStatuses {
+		if c.State.Running != nil {
+			names = append(names, c.Name)
+		}
+	}
+	return
+}
+
+type logsReader struct {
+	log    *service.Logger
+	client *apiClient
+
+	namespace     string
+	labelSelector string
+	container     string
+	since         *time.Duration
+
+	tailer *logtail.Reader
+}
+
+func newLogsReader(conf *service.ParsedConfig, mgr *service.Resources) (*logsReader, error) {
+	r := &logsReader{
+		log:    mgr.Logger(),
+		tailer: logtail.NewReader(),
+	}
+
+	var err error
+	if r.client, err = newAPIClient(conf); err != nil {
+		return nil, err
+	}
+	if r.namespace, err = conf.FieldString(kliFieldNamespace); err != nil {
+		return nil, err
+	}
+	if r.labelSelector, err = conf.FieldString(kliFieldLabelSelector); err != nil {
+		return nil, err
+	}
+	if r.container, err = conf.FieldString(kliFieldContainer); err != nil {
+		return nil, err
+	}
+	if conf.Contains(kliFieldSince) {
+		since, err := conf.FieldDuration(kliFieldSince)
+		if err != nil {
+			return nil, err
+		}
+		r.since = &since
+	}
+	return r, nil
+}
+
+func (r *logsReader) podsQuery() url.Values {
+	q := url.Values{}
+	if r.labelSelector != "" {
+		q.Set("labelSelector", r.labelSelector)
+	}
+	return q
+}
+
+func (r *logsReader) Connect(ctx context.Context) error {
+	return r.tailer.Connect(ctx, func(ctx context.Context) (func(ctx context.Context), error) {
+		path := namespacedPath(r.namespace, "pods")
+		items, resourceVersion, err := r.client.list(ctx, path, r.podsQuery())
+		if err != nil {
+			return nil, err
+		}
+
+		// The logs of pods that already exist are streamed from now, or from
+		// the since duration prior.
+		startedAt := time.Now()
+		if r.since != nil {
+			startedAt = startedAt.Add(-*r.since)
+		}
+
+		return func(ctx context.Context) {
+			discover := func(p pod, from time.Time) {
+				key := p.Metadata.Namespace + "/" + p.Metadata.Name
+				for _, c := range p.runningContainers() {
+					if r.container != "" && c != r.container {
+						continue
+					}
+					c := c
+					r.tailer.Follow(ctx, key+"/"+c, from, func(ctx context.Context, cursor *logtail.Cursor) {
+						r.stream(ctx, &p, c, cursor)
+					})
+				}
+			}
+
+			for _, raw := range items {
+				var p pod
+				if err := json.Unmarshal(raw, &p); err != nil {
+					r.log.Errorf("Failed to decode pod: %v", err)
+					continue
+				}
+				discover(p, startedAt)
+			}
+
+			for ctx.Err() == nil {
+				err := r.client.watch(ctx, path, r.podsQuery(), resourceVersion, func(e watchEvent, meta objectMeta) error {
+					if meta.ResourceVersion != "" {
+						resourceVersion = meta.ResourceVersion
+					}
+					switch e.Type {
+					case "ADDED", "MODIFIED":
+						var p pod
+						if err := json.Unmarshal(e.Object, &p); err != nil {
+							return err
+						}
+						discover(p, time.Time{})
+					case "DELETED":
+						r.tailer.ForgetPrefix(meta.Namespace + "/" + meta.Name + "/")
+					}
+					return nil
+				})
+				if ctx.Err() != nil {
+					return
+				}
+				if err == nil {
+					continue
+				}
+				if isStatusCode(err, http.StatusGone) {
+					// The resource version has expired and therefore we need
+					// to list the pods again, existing streams are unaffected.
+					var items []json.RawMessage
+					if items, resourceVersion, err = r.client.list(ctx, path, r.podsQuery()); err == nil {
+						for _, raw := range items {
+							var p pod
+							if err := json.Unmarshal(raw, &p); err == nil {
+								discover(p, time.Time{})
+							}
+						}
+						continue
+					}
+				}
+				r.log.Errorf("Failed to watch pods: %v", err)
+				select {
+				case <-time.After(time.Second):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}, nil
+	})
+}
+
+// containerRunning returns whether a container of a pod is still running.
+func (r *logsReader) containerRunning(ctx context.Context, p *pod, container string) (bool, error) {
+	res, err := r.client.get(ctx, namespacedPath(p.Metadata.Namespace, "pods/"+url.PathEscape(p.Metadata.Name)), nil)
+	if err != nil {
+		if isStatusCode(err, http.StatusNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer res.Body.Close()
+
+	var current pod
+	if err := json.NewDecoder(res.Body).Decode(&current); err != nil {
+		return false, err
+	}
+	for _, c := range current.runningContainers() {
+		if c == container {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// stream consumes the log of a container until it is no longer running, and
+// resumes the stream whenever it is interrupted.
+func (r *logsReader) stream(ctx context.Context, p *pod, container string, cursor *logtail.Cursor) {
+	for ctx.Err() == nil {
+		err := r.streamOnce(ctx, p, container, cursor)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			r.log.Errorf("Failed to stream logs of container %v of pod %v: %v", container, p.Metadata.Name, err)
+		}
+
+		running, err := r.containerRunning(ctx, p, container)
+		if err != nil {
+			r.log.Errorf("Failed to obtain status of pod %v: %v", p.Metadata.Name, err)
+		} else if !running {
+			return
+		}
+
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *logsReader) streamOnce(ctx context.Context, p *pod, container string, cursor *logtail.Cursor) error {
+	q := url.Values{}
+	q.Set("container", container)
+	q.Set("follow", "true")
+	q.Set("timestamps", "true")
+	if last := cursor.Last(); !last.IsZero() {
+		q.Set("sinceTime", last.UTC().Format(time.RFC3339))
+	}
+	cursor.Resume()
+
+	res, err := r.client.get(ctx, namespacedPath(p.Metadata.Namespace, "pods/"+url.PathEscape(p.Metadata.Name)+"/log"), q)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	reader := bufio.NewReader(res.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if line = strings.TrimSuffix(line, "\n"); line != "" {
+			tsStr, content, _ := strings.Cut(line, " ")
+			ts, terr := time.Parse(time.RFC3339Nano, tsStr)
+			if terr != nil {
+				// Lines are always prefixed with a timestamp, but in case one
+				// isn't we consume the line as is.
+				content = line
+			} else if !cursor.Accept(ts) {
+				continue
+			}
+
+			msg := service.NewMessage([]byte(content))
+			msg.MetaSetMut("kubernetes_namespace", p.Metadata.Namespace)
+			msg.MetaSetMut("kubernetes_pod", p.Metadata.Name)
+			msg.MetaSetMut("kubernetes_container", container)
+			msg.MetaSetMut("kubernetes_node", p.Spec.NodeName)
+			if terr == nil {
+				msg.MetaSetMut("kubernetes_timestamp", ts.Format(time.RFC3339Nano))
+			}
+			for k, v := range p.Metadata.Labels {
+				msg.MetaSetMut("kubernetes_label_"+k, v)
+			}
+
+			if err := r.tailer.Send(ctx, msg); err != nil {
+				return nil
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (r *logsReader) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	return r.tailer.Read(ctx)
+}
+
+func (r *logsReader) Close(ctx context.Context) error {
+	return r.tailer.Close(ctx)
+}
@@ -0,0 +1,173 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func readMessages(t *testing.T, in service.Input, n int) (msgs []*service.Message) {
+	t.Helper()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	for len(msgs) < n {
+		msg, ackFn, err := in.Read(ctx)
+		require.NoError(t, err)
+		require.NoError(t, ackFn(ctx, nil))
+		msgs = append(msgs, msg)
+	}
+	return
+}
+
+func TestEventsInput(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer meow", r.Header.Get("Authorization"))
+		assert.Equal(t, "/api/v1/namespaces/default/events", r.URL.Path)
+		assert.Equal(t, "type=Warning", r.URL.Query().Get("fieldSelector"))
+
+		if r.URL.Query().Get("watch") != "true" {
+			_, _ = w.Write([]byte(`{"metadata":{"resourceVersion":"10"},"items":[
+  {"metadata":{"name":"a","namespace":"default","resourceVersion":"9"},"type":"Warning","reason":"BackOff","involvedObject":{"kind":"Pod","name":"foo"}}
+]}`))
+			return
+		}
+
+		assert.Equal(t, "10", r.URL.Query().Get("resourceVersion"))
+		_, _ = w.Write([]byte(`{"type":"ADDED","object":{"metadata":{"name":"b","namespace":"default","resourceVersion":"11"},"type":"Warning","reason":"Failed","involvedObject":{"kind":"Pod","name":"bar"}}}
+{"type":"BOOKMARK","object":{"metadata":{"resourceVersion":"12"}}}
+{"type":"MODIFIED","object":{"metadata":{"name":"a","namespace":"default","resourceVersion":"13"},"type":"Warning","reason":"BackOff","count":2,"involvedObject":{"kind":"Pod","name":"foo"}}}
+`))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	conf, err := eventsInputConfig().ParseYAML(fmt.Sprintf(`
+api_url: %v
+token: meow
+namespace: default
+field_selector: type=Warning
+include_existing: true
+`, ts.URL), nil)
+	require.NoError(t, err)
+
+	r, err := newEventsReader(conf, service.MockResources())
+	require.NoError(t, err)
+	require.NoError(t, r.Connect(context.Background()))
+
+	msgs := readMessages(t, r, 3)
+
+	var names, watchTypes, reasons []string
+	for _, m := range msgs {
+		s, err := m.AsStructured()
+		require.NoError(t, err)
+		names = append(names, s.(map[string]any)["metadata"].(map[string]any)["name"].(string))
+
+		v, _ := m.MetaGet("kubernetes_watch_event_type")
+		watchTypes = append(watchTypes, v)
+		v, _ = m.MetaGet("kubernetes_reason")
+		reasons = append(reasons, v)
+	}
+	assert.Equal(t, []string{"a", "b", "a"}, names)
+	assert.Equal(t, []string{"ADDED", "ADDED", "MODIFIED"}, watchTypes)
+	assert.Equal(t, []string{"BackOff", "Failed", "BackOff"}, reasons)
+
+	kind, _ := msgs[1].MetaGet("kubernetes_involved_kind")
+	assert.Equal(t, "Pod", kind)
+
+	require.NoError(t, r.Close(context.Background()))
+}
+
+func TestLogsInputResumes(t *testing.T) {
+	base := time.Now().UTC().Truncate(time.Second)
+	t1, t2, t3 := base.Add(time.Millisecond), base.Add(time.Millisecond*2), base.Add(time.Second+time.Millisecond)
+	line := func(ts time.Time, s string) string {
+		return ts.Format(time.RFC3339Nano) + " " + s + "\n"
+	}
+
+	podJSON := `{"metadata":{"name":"foo","namespace":"default","labels":{"app":"meow"}},"spec":{"nodeName":"node1"},"status":{"containerStatuses":[{"name":"app","state":{"running":{}}},{"name":"sidecar","state":{"running":{}}}]}}`
+
+	var logRequests, podRequests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/default/pods":
+			assert.Equal(t, "app=meow", r.URL.Query().Get("labelSelector"))
+			if r.URL.Query().Get("watch") == "true" {
+				<-r.Context().Done()
+				return
+			}
+			_, _ = w.Write([]byte(`{"metadata":{"resourceVersion":"10"},"items":[` + podJSON + `]}`))
+		case "/api/v1/namespaces/default/pods/foo":
+			if atomic.AddInt32(&podRequests, 1) == 1 {
+				_, _ = w.Write([]byte(podJSON))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"kind":"Status","message":"pods \"foo\" not found","code":404}`))
+		case "/api/v1/namespaces/default/pods/foo/log":
+			assert.Equal(t, "app", r.URL.Query().Get("container"))
+			assert.Equal(t, "true", r.URL.Query().Get("follow"))
+			assert.Equal(t, "true", r.URL.Query().Get("timestamps"))
+			if atomic.AddInt32(&logRequests, 1) == 1 {
+				_, _ = w.Write([]byte(line(t1, "a") + line(t2, "b") + line(t2, "c")))
+				return
+			}
+			// The stream is resumed from the second of the last line.
+			assert.Equal(t, base.Format(time.RFC3339), r.URL.Query().Get("sinceTime"))
+			_, _ = w.Write([]byte(line(t1, "a") + line(t2, "b") + line(t2, "c") + line(t3, "d")))
+		default:
+			t.Errorf("unexpected request path: %v", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	conf, err := logsInputConfig().ParseYAML(fmt.Sprintf(`
+api_url: %v
+namespace: default
+label_selector: app=meow
+container: app
+since: 1h
+`, ts.URL), nil)
+	require.NoError(t, err)
+
+	r, err := newLogsReader(conf, service.MockResources())
+	require.NoError(t, err)
+	require.NoError(t, r.Connect(context.Background()))
+
+	msgs := readMessages(t, r, 4)
+
+	var lines []string
+	for _, m := range msgs {
+		b, err := m.AsBytes()
+		require.NoError(t, err)
+		lines = append(lines, string(b))
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d"}, lines)
+
+	for k, v := range map[string]string{
+		"kubernetes_namespace": "default",
+		"kubernetes_pod":       "foo",
+		"kubernetes_container": "app",
+		"kubernetes_node":      "node1",
+		"kubernetes_timestamp": t3.Format(time.RFC3339Nano),
+		"kubernetes_label_app": "meow",
+	} {
+		actual, _ := msgs[3].MetaGet(k)
+		assert.Equal(t, v, actual, k)
+	}
+
+	require.NoError(t, r.Close(context.Background()))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&logRequests))
+}
@@ -0,0 +1,416 @@
+package netflow
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	lruv2 "github.com/hashicorp/golang-lru/v2"
+)
+
+// Names of the protocols of decoded datagrams.
+const (
+	protocolNetFlowV5 = "netflow_v5"
+	protocolNetFlowV9 = "netflow_v9"
+	protocolIPFIX     = "ipfix"
+	protocolSFlowV5   = "sflow_v5"
+)
+
+// variableLength is the field length of IPFIX templates that indicates the
+// length of a field is encoded within each record.
+const variableLength = 0xffff
+
+type templateField struct {
+	id         uint16
+	enterprise uint32
+	length     uint16
+}
+
+type template struct {
+	fields  []templateField
+	options bool
+}
+
+// templateKey identifies a template, as template IDs are only unique within
+// an observation domain of an exporter.
+type templateKey struct {
+	exporter string
+	version  uint16
+	domain   uint32
+	id       uint16
+}
+
+// datagram is the result of decoding an export datagram.
+type datagram struct {
+	protocol string
+	sequence uint32
+	domain   uint32
+	agent    net.IP
+	records  []map[string]any
+
+	// The IDs of templates referenced by data sets of the datagram that have
+	// not yet been received from the exporter.
+	missingTemplates []uint16
+}
+
+// decoder decodes export datagrams and retains the templates of NetFlow v9
+// and IPFIX exporters in order to decode their data records. The number of
+// templates retained is limited, where the least recently used templates are
+// evicted first, so that exporters cannot exhaust memory by sending templates
+// with ever changing IDs or observation domains.
+type decoder struct {
+	templates *lruv2.Cache[templateKey, *template]
+}
+
+func newDecoder(maxTemplates int) (*decoder, error) {
+	templates, err := lruv2.New[templateKey, *template](maxTemplates)
+	if err != nil {
+		return nil, err
+	}
+	return &decoder{templates: templates}, nil
+}
+
+// decode decodes a datagram received from an exporter, where the protocol is
+// determined by the version number at the start of the datagram.
+func (d *decoder) decode(exporter string, data []byte) (*datagram, error) {
+	if len(data) < 4 {
+		return nil, errors.New("datagram is truncated")
+	}
+	// The versions of NetFlow and IPFIX are 16 bits whereas the version of
+	// sFlow is 32 bits.
+	version := uint32(binary.BigEndian.Uint16(data[0:2]))
+	if version == 0 {
+		version = binary.BigEndian.Uint32(data[0:4])
+		if version == 5 {
+			return decodeSFlow(data)
+		}
+	}
+	switch version {
+	case 5:
+		return decodeNetFlowV5(data)
+	case 9:
+		return d.decodeNetFlowV9(exporter, data)
+	case 10:
+		return d.decodeIPFIX(exporter, data)
+	}
+	return nil, fmt.Errorf("unsupported datagram version %v", version)
+}
+
+//------------------------------------------------------------------------------
+
+func decodeNetFlowV5(data []byte) (*datagram, error) {
+	if len(data) < 24 {
+		return nil, errors.New("netflow v5 header is truncated")
+	}
+	count := int(binary.BigEndian.Uint16(data[2:4]))
+	sysUptime := binary.BigEndian.Uint32(data[4:8])
+	exportTime := time.Unix(int64(binary.BigEndian.Uint32(data[8:12])), int64(binary.BigEndian.Uint32(data[12:16])))
+	engineType, engineID := int64(data[20]), int64(data[21])
+	samplingInterval := int64(binary.BigEndian.Uint16(data[22:24]) & 0x3fff)
+
+	if len(data) < 24+count*48 {
+		return nil, fmt.Errorf("netflow v5 datagram is truncated, expected %v records", count)
+	}
+
+	dg := &datagram{
+		protocol: protocolNetFlowV5,
+		sequence: binary.BigEndian.Uint32(data[16:20]),
+	}
+	for i := 0; i < count; i++ {
+		r := data[24+i*48 : 24+(i+1)*48]
+		first, last := binary.BigEndian.Uint32(r[24:28]), binary.BigEndian.Uint32(r[28:32])
+		dg.records = append(dg.records, map[string]any{
+			"ipv4_src_addr":     net.IP(r[0:4]).String(),
+			"ipv4_dst_addr":     net.IP(r[4:8]).String(),
+			"ipv4_next_hop":     net.IP(r[8:12]).String(),
+			"input_snmp":        int64(binary.BigEndian.Uint16(r[12:14])),
+			"output_snmp":       int64(binary.BigEndian.Uint16(r[14:16])),
+			"in_pkts":           int64(binary.BigEndian.Uint32(r[16:20])),
+			"in_bytes":          int64(binary.BigEndian.Uint32(r[20:24])),
+			"first_switched":    int64(first),
+			"last_switched":     int64(last),
+			"l4_src_port":       int64(binary.BigEndian.Uint16(r[32:34])),
+			"l4_dst_port":       int64(binary.BigEndian.Uint16(r[34:36])),
+			"tcp_flags":         int64(r[37]),
+			"protocol":          int64(r[38]),
+			"src_tos":           int64(r[39]),
+			"src_as":            int64(binary.BigEndian.Uint16(r[40:42])),
+			"dst_as":            int64(binary.BigEndian.Uint16(r[42:44])),
+			"src_mask":          int64(r[44]),
+			"dst_mask":          int64(r[45]),
+			"engine_type":       engineType,
+			"engine_id":         engineID,
+			"sampling_interval": samplingInterval,
+			"flow_start":        formatTime(uptimeToTime(exportTime, sysUptime, first)),
+			"flow_end":          formatTime(uptimeToTime(exportTime, sysUptime, last)),
+		})
+	}
+	return dg, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (d *decoder) decodeNetFlowV9(exporter string, data []byte) (*datagram, error) {
+	if len(data) < 20 {
+		return nil, errors.New("netflow v9 header is truncated")
+	}
+	sysUptime := binary.BigEndian.Uint32(data[4:8])
+	exportTime := time.Unix(int64(binary.BigEndian.Uint32(data[8:12])), 0)
+
+	dg := &datagram{
+		protocol: protocolNetFlowV9,
+		sequence: binary.BigEndian.Uint32(data[12:16]),
+		domain:   binary.BigEndian.Uint32(data[16:20]),
+	}
+	key := templateKey{exporter: exporter, version: 9, domain: dg.domain}
+
+	err := walkSets(data[20:], func(id uint16, body []byte) error {
+		switch {
+		case id == 0:
+			return d.parseV9Templates(key, body)
+		case id == 1:
+			return d.parseV9OptionsTemplates(key, body)
+		case id > 255:
+			key.id = id
+			d.decodeDataSet(key, body, dg, func(rec map[string]any) {
+				if first, ok := recordUint(rec, "first_switched"); ok {
+					rec["flow_start"] = formatTime(uptimeToTime(exportTime, sysUptime, uint32(first)))
+				}
+				if last, ok := recordUint(rec, "last_switched"); ok {
+					rec["flow_end"] = formatTime(uptimeToTime(exportTime, sysUptime, uint32(last)))
+				}
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dg, nil
+}
+
+func (d *decoder) parseV9Templates(key templateKey, body []byte) error {
+	for len(body) >= 4 {
+		key.id = binary.BigEndian.Uint16(body[0:2])
+		count := int(binary.BigEndian.Uint16(body[2:4]))
+		if key.id < 256 {
+			// The remainder of the set is padding.
+			return nil
+		}
+		body = body[4:]
+		if len(body) < count*4 {
+			return fmt.Errorf("template %v is truncated", key.id)
+		}
+		d.templates.Add(key, &template{fields: parseV9Fields(body[:count*4])})
+		body = body[count*4:]
+	}
+	return nil
+}
+
+func (d *decoder) parseV9OptionsTemplates(key templateKey, body []byte) error {
+	for len(body) >= 6 {
+		key.id = binary.BigEndian.Uint16(body[0:2])
+		length := int(binary.BigEndian.Uint16(body[2:4])) + int(binary.BigEndian.Uint16(body[4:6]))
+		if key.id < 256 {
+			return nil
+		}
+		body = body[6:]
+		if len(body) < length {
+			return fmt.Errorf("options template %v is truncated", key.id)
+		}
+		d.templates.Add(key, &template{fields: parseV9Fields(body[:length]), options: true})
+		body = body[length:]
+	}
+	return nil
+}
+
+func parseV9Fields(data []byte) []templateField {
+	fields := make([]templateField, 0, len(data)/4)
+	for ; len(data) >= 4; data = data[4:] {
+		fields = append(fields, templateField{
+			id:     binary.BigEndian.Uint16(data[0:2]),
+			length: binary.BigEndian.Uint16(data[2:4]),
+		})
+	}
+	return fields
+}
+
+//------------------------------------------------------------------------------
+
+func (d *decoder) decodeIPFIX(exporter string, data []byte) (*datagram, error) {
+	if len(data) < 16 {
+		return nil, errors.New("ipfix header is truncated")
+	}
+	if length := int(binary.BigEndian.Uint16(data[2:4])); length >= 16 && length < len(data) {
+		data = data[:length]
+	}
+
+	dg := &datagram{
+		protocol: protocolIPFIX,
+		sequence: binary.BigEndian.Uint32(data[8:12]),
+		domain:   binary.BigEndian.Uint32(data[12:16]),
+	}
+	key := templateKey{exporter: exporter, version: 10, domain: dg.domain}
+
+	err := walkSets(data[16:], func(id uint16, body []byte) error {
+		switch {
+		case id == 2:
+			return d.parseIPFIXTemplates(key, body, false)
+		case id == 3:
+			return d.parseIPFIXTemplates(key, body, true)
+		case id > 255:
+			key.id = id
+			d.decodeDataSet(key, body, dg, func(rec map[string]any) {
+				if start, ok := recordUint(rec, "flow_start_milliseconds"); ok {
+					rec["flow_start"] = formatTime(time.UnixMilli(int64(start)))
+				} else if start, ok := recordUint(rec, "flow_start_seconds"); ok {
+					rec["flow_start"] = formatTime(time.Unix(int64(start), 0))
+				}
+				if end, ok := recordUint(rec, "flow_end_milliseconds"); ok {
+					rec["flow_end"] = formatTime(time.UnixMilli(int64(end)))
+				} else if end, ok := recordUint(rec, "flow_end_seconds"); ok {
+					rec["flow_end"] = formatTime(time.Unix(int64(end), 0))
+				}
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dg, nil
+}
+
+func (d *decoder) parseIPFIXTemplates(key templateKey, body []byte, options bool) error {
+	for len(body) >= 4 {
+		key.id = binary.BigEndian.Uint16(body[0:2])
+		count := int(binary.BigEndian.Uint16(body[2:4]))
+		if key.id < 256 {
+			return nil
+		}
+		body = body[4:]
+
+		// A template record without fields withdraws the template.
+		if count == 0 {
+			d.templates.Remove(key)
+			continue
+		}
+		if options {
+			if len(body) < 2 {
+				return fmt.Errorf("options template %v is truncated", key.id)
+			}
+			body = body[2:]
+		}
+
+		t := &template{options: options}
+		for i := 0; i < count; i++ {
+			if len(body) < 4 {
+				return fmt.Errorf("template %v is truncated", key.id)
+			}
+			f := templateField{
+				id:     binary.BigEndian.Uint16(body[0:2]),
+				length: binary.BigEndian.Uint16(body[2:4]),
+			}
+			body = body[4:]
+			if f.id&0x8000 != 0 {
+				if len(body) < 4 {
+					return fmt.Errorf("template %v is truncated", key.id)
+				}
+				f.id &= 0x7fff
+				f.enterprise = binary.BigEndian.Uint32(body[0:4])
+				body = body[4:]
+			}
+			t.fields = append(t.fields, f)
+		}
+		d.templates.Add(key, t)
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// walkSets calls a function with the ID and body of each set (or flowset) of
+// a NetFlow v9 or IPFIX datagram.
+func walkSets(data []byte, fn func(id uint16, body []byte) error) error {
+	for len(data) >= 4 {
+		id := binary.BigEndian.Uint16(data[0:2])
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if length < 4 || length > len(data) {
+			return fmt.Errorf("set %v has an invalid length of %v", id, length)
+		}
+		if err := fn(id, data[4:length]); err != nil {
+			return err
+		}
+		data = data[length:]
+	}
+	return nil
+}
+
+// decodeDataSet decodes the records of a data set with the template it
+// references, and calls a function with each record before adding it to the
+// datagram. The records of options templates are not emitted.
+func (d *decoder) decodeDataSet(key templateKey, body []byte, dg *datagram, fn func(rec map[string]any)) {
+	t, exists := d.templates.Get(key)
+	if !exists {
+		dg.missingTemplates = append(dg.missingTemplates, key.id)
+		return
+	}
+	if t.options {
+		return
+	}
+	for {
+		rec, n := t.decodeRecord(body)
+		if n == 0 {
+			// The remainder of the set is padding.
+			return
+		}
+		body = body[n:]
+		fn(rec)
+		dg.records = append(dg.records, rec)
+	}
+}
+
+// decodeRecord decodes a data record from the start of a data set, and
+// returns the record and the number of bytes it occupies, which is zero when
+// a complete record could not be decoded.
+func (t *template) decodeRecord(data []byte) (map[string]any, int) {
+	rec := make(map[string]any, len(t.fields))
+	off := 0
+	for _, f := range t.fields {
+		length := int(f.length)
+		if f.length == variableLength {
+			if off >= len(data) {
+				return nil, 0
+			}
+			length = int(data[off])
+			off++
+			if length == 255 {
+				if off+2 > len(data) {
+					return nil, 0
+				}
+				length = int(binary.BigEndian.Uint16(data[off : off+2]))
+				off += 2
+			}
+		}
+		if off+length > len(data) {
+			return nil, 0
+		}
+		if name, value := decodeField(f, data[off:off+length]); name != "" {
+			rec[name] = value
+		}
+		off += length
+	}
+	return rec, off
+}
+
+// uptimeToTime converts a time measured in milliseconds of the system uptime
+// of an exporter to an absolute time.
+func uptimeToTime(exportTime time.Time, sysUptime, uptime uint32) time.Time {
+	return exportTime.Add(-time.Duration(sysUptime-uptime) * time.Millisecond)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,363 @@
+package netflow
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func u16(v int) []byte { return binary.BigEndian.AppendUint16(nil, uint16(v)) }
+func u32(v int) []byte { return binary.BigEndian.AppendUint32(nil, uint32(v)) }
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+// set encodes a NetFlow v9 flowset or IPFIX set.
+func set(id int, body ...[]byte) []byte {
+	b := concat(body...)
+	return concat(u16(id), u16(len(b)+4), b)
+}
+
+func testDecoder(t *testing.T, maxTemplates int) *decoder {
+	t.Helper()
+
+	dec, err := newDecoder(maxTemplates)
+	require.NoError(t, err)
+	return dec
+}
+
+func netflowV5Datagram() []byte {
+	header := concat(
+		u16(5), u16(1),
+		u32(10000),              // Uptime.
+		u32(1700000000), u32(0), // Export time.
+		u32(42),                       // Sequence.
+		[]byte{1, 2}, u16(0x4000|100), // Engine and sampling interval.
+	)
+	record := concat(
+		net.ParseIP("10.0.0.1").To4(), net.ParseIP("10.0.0.2").To4(), net.ParseIP("0.0.0.0").To4(),
+		u16(1), u16(2),
+		u32(12), u32(4821),
+		u32(7000), u32(9500),
+		u16(51234), u16(443),
+		[]byte{0, 0x1b, 6, 0},
+		u16(64512), u16(64513),
+		[]byte{24, 16, 0, 0},
+	)
+	return concat(header, record)
+}
+
+func TestDecodeNetFlowV5(t *testing.T) {
+	dg, err := testDecoder(t, 10).decode("192.0.2.1", netflowV5Datagram())
+	require.NoError(t, err)
+
+	assert.Equal(t, protocolNetFlowV5, dg.protocol)
+	assert.Equal(t, uint32(42), dg.sequence)
+	assert.Equal(t, []map[string]any{
+		{
+			"ipv4_src_addr":     "10.0.0.1",
+			"ipv4_dst_addr":     "10.0.0.2",
+			"ipv4_next_hop":     "0.0.0.0",
+			"input_snmp":        int64(1),
+			"output_snmp":       int64(2),
+			"in_pkts":           int64(12),
+			"in_bytes":          int64(4821),
+			"first_switched":    int64(7000),
+			"last_switched":     int64(9500),
+			"l4_src_port":       int64(51234),
+			"l4_dst_port":       int64(443),
+			"tcp_flags":         int64(0x1b),
+			"protocol":          int64(6),
+			"src_tos":           int64(0),
+			"src_as":            int64(64512),
+			"dst_as":            int64(64513),
+			"src_mask":          int64(24),
+			"dst_mask":          int64(16),
+			"engine_type":       int64(1),
+			"engine_id":         int64(2),
+			"sampling_interval": int64(100),
+			"flow_start":        "2023-11-14T22:13:17Z",
+			"flow_end":          "2023-11-14T22:13:19.5Z",
+		},
+	}, dg.records)
+
+	_, err = testDecoder(t, 10).decode("192.0.2.1", netflowV5Datagram()[:60])
+	assert.Error(t, err)
+}
+
+func TestDecodeNetFlowV9Templates(t *testing.T) {
+	header := func(seq int) []byte {
+		return concat(u16(9), u16(1), u32(10000), u32(1700000000), u32(seq), u32(7))
+	}
+	templates := set(0,
+		u16(256), u16(5),
+		u16(8), u16(4), // ipv4_src_addr
+		u16(12), u16(4), // ipv4_dst_addr
+		u16(1), u16(4), // in_bytes
+		u16(22), u16(4), // first_switched
+		u16(1000), u16(2), // Unknown.
+	)
+	optionsTemplate := set(1,
+		u16(257), u16(4), u16(4),
+		u16(1), u16(4), // Scope.
+		u16(34), u16(4), // sampling_interval
+	)
+	data := set(256,
+		net.ParseIP("10.0.0.1").To4(), net.ParseIP("10.0.0.2").To4(), u32(1500), u32(9000), u16(3),
+		net.ParseIP("10.0.0.3").To4(), net.ParseIP("10.0.0.4").To4(), u32(60), u32(9500), u16(4),
+		[]byte{0, 0}, // Padding.
+	)
+	options := set(257, u32(1), u32(100))
+
+	dec := testDecoder(t, 10)
+
+	// Data received before its template is dropped.
+	dg, err := dec.decode("192.0.2.1", concat(header(1), data))
+	require.NoError(t, err)
+	assert.Empty(t, dg.records)
+	assert.Equal(t, []uint16{256}, dg.missingTemplates)
+
+	dg, err = dec.decode("192.0.2.1", concat(header(2), templates, optionsTemplate, options))
+	require.NoError(t, err)
+	assert.Empty(t, dg.records)
+	assert.Empty(t, dg.missingTemplates)
+
+	// Templates are not shared between exporters.
+	dg, err = dec.decode("192.0.2.2", concat(header(3), data))
+	require.NoError(t, err)
+	assert.Empty(t, dg.records)
+
+	dg, err = dec.decode("192.0.2.1", concat(header(4), data))
+	require.NoError(t, err)
+	assert.Equal(t, protocolNetFlowV9, dg.protocol)
+	assert.Equal(t, uint32(4), dg.sequence)
+	assert.Equal(t, uint32(7), dg.domain)
+	assert.Equal(t, []map[string]any{
+		{
+			"ipv4_src_addr":  "10.0.0.1",
+			"ipv4_dst_addr":  "10.0.0.2",
+			"in_bytes":       int64(1500),
+			"first_switched": int64(9000),
+			"field_1000":     int64(3),
+			"flow_start":     "2023-11-14T22:13:19Z",
+		},
+		{
+			"ipv4_src_addr":  "10.0.0.3",
+			"ipv4_dst_addr":  "10.0.0.4",
+			"in_bytes":       int64(60),
+			"first_switched": int64(9500),
+			"field_1000":     int64(4),
+			"flow_start":     "2023-11-14T22:13:19.5Z",
+		},
+	}, dg.records)
+
+	_, err = dec.decode("192.0.2.1", concat(header(5), u16(256), u16(100)))
+	assert.Error(t, err)
+}
+
+func TestDecodeIPFIX(t *testing.T) {
+	message := func(sets ...[]byte) []byte {
+		body := concat(sets...)
+		return concat(u16(10), u16(16+len(body)), u32(1700000000), u32(9), u32(3), body)
+	}
+	templates := set(2,
+		u16(300), u16(5),
+		u16(27), u16(16), // ipv6_src_addr
+		u16(152), u16(8), // flow_start_milliseconds
+		u16(82), u16(0xffff), // if_name
+		u16(0x8000|12), u16(4), u32(29305), // Enterprise-specific.
+		u16(56), u16(6), // in_src_mac
+	)
+	data := set(300,
+		net.ParseIP("fd00::1").To16(), binary.BigEndian.AppendUint64(nil, 1700000000250), []byte{4}, []byte("eth0"), []byte{0xde, 0xad, 0xbe, 0xef}, []byte{2, 0, 0, 0, 0, 1},
+		net.ParseIP("fd00::2").To16(), binary.BigEndian.AppendUint64(nil, 1700000001000), []byte{255}, u16(3), []byte("lo0"), u32(1), []byte{2, 0, 0, 0, 0, 2},
+	)
+
+	dec := testDecoder(t, 10)
+	dg, err := dec.decode("192.0.2.1", message(templates, data))
+	require.NoError(t, err)
+
+	assert.Equal(t, protocolIPFIX, dg.protocol)
+	assert.Equal(t, uint32(9), dg.sequence)
+	assert.Equal(t, uint32(3), dg.domain)
+	assert.Equal(t, []map[string]any{
+		{
+			"ipv6_src_addr":           "fd00::1",
+			"flow_start_milliseconds": int64(1700000000250),
+			"if_name":                 "eth0",
+			"enterprise_29305_12":     int64(0xdeadbeef),
+			"in_src_mac":              "02:00:00:00:00:01",
+			"flow_start":              "2023-11-14T22:13:20.25Z",
+		},
+		{
+			"ipv6_src_addr":           "fd00::2",
+			"flow_start_milliseconds": int64(1700000001000),
+			"if_name":                 "lo0",
+			"enterprise_29305_12":     int64(1),
+			"in_src_mac":              "02:00:00:00:00:02",
+			"flow_start":              "2023-11-14T22:13:21Z",
+		},
+	}, dg.records)
+
+	// Withdrawn templates are forgotten.
+	_, err = dec.decode("192.0.2.1", message(set(2, u16(300), u16(0))))
+	require.NoError(t, err)
+
+	dg, err = dec.decode("192.0.2.1", message(data))
+	require.NoError(t, err)
+	assert.Empty(t, dg.records)
+	assert.Equal(t, []uint16{300}, dg.missingTemplates)
+}
+
+func TestDecodeTemplateEviction(t *testing.T) {
+	message := func(domain int, sets ...[]byte) []byte {
+		body := concat(sets...)
+		return concat(u16(10), u16(16+len(body)), u32(1700000000), u32(1), u32(domain), body)
+	}
+	templates := set(2, u16(256), u16(1), u16(7), u16(2))
+	data := set(256, u16(443))
+
+	dec := testDecoder(t, 2)
+	for domain := 1; domain <= 3; domain++ {
+		_, err := dec.decode("192.0.2.1", message(domain, templates))
+		require.NoError(t, err)
+
+		// Using the template of the first domain keeps it from being evicted.
+		dg, err := dec.decode("192.0.2.1", message(1, data))
+		require.NoError(t, err)
+		assert.Len(t, dg.records, 1)
+	}
+
+	// The least recently used template is evicted once the limit is reached.
+	dg, err := dec.decode("192.0.2.1", message(2, data))
+	require.NoError(t, err)
+	assert.Empty(t, dg.records)
+	assert.Equal(t, []uint16{256}, dg.missingTemplates)
+
+	dg, err = dec.decode("192.0.2.1", message(3, data))
+	require.NoError(t, err)
+	assert.Len(t, dg.records, 1)
+
+	_, err = newDecoder(0)
+	assert.Error(t, err)
+}
+
+func TestDecodeSFlow(t *testing.T) {
+	opaque := func(parts ...[]byte) []byte {
+		b := concat(parts...)
+		for len(b)%4 != 0 {
+			b = append(b, 0)
+		}
+		return concat(u32(len(concat(parts...))), b)
+	}
+
+	sampledHeader := concat(
+		[]byte{0x02, 0, 0, 0, 0, 0x02, 0x02, 0, 0, 0, 0, 0x01}, u16(0x8100), u16(12), u16(0x0800),
+		[]byte{0x45, 0x10}, u16(40), u32(0), []byte{64, 6}, u16(0),
+		net.ParseIP("10.0.0.1").To4(), net.ParseIP("10.0.0.2").To4(),
+		u16(51234), u16(443), u32(1), u32(0), []byte{0x50, 0x12}, u16(0),
+	)
+	flowSample := concat(
+		u32(1),
+		opaque(
+			u32(77), u32(3), u32(512), u32(1024), u32(0), u32(3), u32(4),
+			u32(1),
+			u32(1), opaque(u32(1), u32(1514), u32(4), opaque(sampledHeader)),
+		),
+	)
+	counterSample := concat(
+		u32(2),
+		opaque(
+			u32(78), u32(5),
+			u32(2),
+			u32(2), opaque(u32(1)), // Unsupported record.
+			u32(1), opaque(
+				u32(5), u32(6), binary.BigEndian.AppendUint64(nil, 1000000000), u32(1), u32(3),
+				binary.BigEndian.AppendUint64(nil, 123456), u32(1), u32(2), u32(3), u32(4), u32(5), u32(6),
+				binary.BigEndian.AppendUint64(nil, 654321), u32(7), u32(8), u32(9), u32(10), u32(11), u32(0),
+			),
+		),
+	)
+	enterpriseSample := concat(u32(4300<<12|1), opaque(u32(1)))
+
+	datagram := concat(
+		u32(5), u32(1), net.ParseIP("192.0.2.10").To4(), u32(2), u32(99), u32(50000), u32(3),
+		flowSample, enterpriseSample, counterSample,
+	)
+
+	dg, err := testDecoder(t, 10).decode("192.0.2.1", datagram)
+	require.NoError(t, err)
+
+	assert.Equal(t, protocolSFlowV5, dg.protocol)
+	assert.Equal(t, uint32(99), dg.sequence)
+	assert.Equal(t, uint32(2), dg.domain)
+	assert.Equal(t, "192.0.2.10", dg.agent.String())
+	assert.Equal(t, []map[string]any{
+		{
+			"sample_type":     "flow",
+			"sequence_number": int64(77),
+			"source_id_type":  int64(0),
+			"source_id_index": int64(3),
+			"sampling_rate":   int64(512),
+			"sample_pool":     int64(1024),
+			"drops":           int64(0),
+			"input_snmp":      int64(3),
+			"output_snmp":     int64(4),
+			"frame_length":    int64(1514),
+			"in_dst_mac":      "02:00:00:00:00:02",
+			"in_src_mac":      "02:00:00:00:00:01",
+			"src_vlan":        int64(12),
+			"src_tos":         int64(0x10),
+			"protocol":        int64(6),
+			"ipv4_src_addr":   "10.0.0.1",
+			"ipv4_dst_addr":   "10.0.0.2",
+			"l4_src_port":     int64(51234),
+			"l4_dst_port":     int64(443),
+			"tcp_flags":       int64(0x12),
+		},
+		{
+			"sample_type":           "counters",
+			"sequence_number":       int64(78),
+			"source_id_type":        int64(0),
+			"source_id_index":       int64(5),
+			"if_index":              int64(5),
+			"if_type":               int64(6),
+			"if_speed":              int64(1000000000),
+			"if_direction":          int64(1),
+			"if_status":             int64(3),
+			"if_in_octets":          int64(123456),
+			"if_in_ucast_pkts":      int64(1),
+			"if_in_multicast_pkts":  int64(2),
+			"if_in_broadcast_pkts":  int64(3),
+			"if_in_discards":        int64(4),
+			"if_in_errors":          int64(5),
+			"if_in_unknown_protos":  int64(6),
+			"if_out_octets":         int64(654321),
+			"if_out_ucast_pkts":     int64(7),
+			"if_out_multicast_pkts": int64(8),
+			"if_out_broadcast_pkts": int64(9),
+			"if_out_discards":       int64(10),
+			"if_out_errors":         int64(11),
+			"if_promiscuous_mode":   int64(0),
+		},
+	}, dg.records)
+
+	_, err = testDecoder(t, 10).decode("192.0.2.1", datagram[:40])
+	assert.Error(t, err)
+}
+
+func TestDecodeUnsupportedVersion(t *testing.T) {
+	_, err := testDecoder(t, 10).decode("192.0.2.1", concat(u16(7), u16(0), u32(0)))
+	assert.EqualError(t, err, "unsupported datagram version 7")
+
+	_, err = testDecoder(t, 10).decode("192.0.2.1", concat(u32(4), u32(1)))
+	assert.EqualError(t, err, "unsupported datagram version 4")
+}
@@ -0,0 +1,175 @@
+package netflow
+
+import (
+	"encoding/hex"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+)
+
+type fieldKind int
+
+const (
+	kindUnsigned fieldKind = iota
+	kindIP
+	kindMAC
+	kindString
+	kindPadding
+)
+
+type fieldDef struct {
+	name string
+	kind fieldKind
+}
+
+// fieldDefs maps the field types of NetFlow v9 and the information elements
+// of IPFIX, which share their numbering, to the names of record fields. Field
+// types defined by NetFlow v9 use its names, and those only defined by IPFIX
+// use their IANA names in snake case.
+var fieldDefs = map[uint16]fieldDef{
+	1:   {"in_bytes", kindUnsigned},
+	2:   {"in_pkts", kindUnsigned},
+	3:   {"flows", kindUnsigned},
+	4:   {"protocol", kindUnsigned},
+	5:   {"src_tos", kindUnsigned},
+	6:   {"tcp_flags", kindUnsigned},
+	7:   {"l4_src_port", kindUnsigned},
+	8:   {"ipv4_src_addr", kindIP},
+	9:   {"src_mask", kindUnsigned},
+	10:  {"input_snmp", kindUnsigned},
+	11:  {"l4_dst_port", kindUnsigned},
+	12:  {"ipv4_dst_addr", kindIP},
+	13:  {"dst_mask", kindUnsigned},
+	14:  {"output_snmp", kindUnsigned},
+	15:  {"ipv4_next_hop", kindIP},
+	16:  {"src_as", kindUnsigned},
+	17:  {"dst_as", kindUnsigned},
+	18:  {"bgp_ipv4_next_hop", kindIP},
+	19:  {"mul_dst_pkts", kindUnsigned},
+	20:  {"mul_dst_bytes", kindUnsigned},
+	21:  {"last_switched", kindUnsigned},
+	22:  {"first_switched", kindUnsigned},
+	23:  {"out_bytes", kindUnsigned},
+	24:  {"out_pkts", kindUnsigned},
+	25:  {"min_pkt_lngth", kindUnsigned},
+	26:  {"max_pkt_lngth", kindUnsigned},
+	27:  {"ipv6_src_addr", kindIP},
+	28:  {"ipv6_dst_addr", kindIP},
+	29:  {"ipv6_src_mask", kindUnsigned},
+	30:  {"ipv6_dst_mask", kindUnsigned},
+	31:  {"ipv6_flow_label", kindUnsigned},
+	32:  {"icmp_type", kindUnsigned},
+	33:  {"mul_igmp_type", kindUnsigned},
+	34:  {"sampling_interval", kindUnsigned},
+	35:  {"sampling_algorithm", kindUnsigned},
+	36:  {"flow_active_timeout", kindUnsigned},
+	37:  {"flow_inactive_timeout", kindUnsigned},
+	38:  {"engine_type", kindUnsigned},
+	39:  {"engine_id", kindUnsigned},
+	40:  {"total_bytes_exp", kindUnsigned},
+	41:  {"total_pkts_exp", kindUnsigned},
+	42:  {"total_flows_exp", kindUnsigned},
+	46:  {"mpls_top_label_type", kindUnsigned},
+	47:  {"mpls_top_label_ip_addr", kindIP},
+	48:  {"flow_sampler_id", kindUnsigned},
+	49:  {"flow_sampler_mode", kindUnsigned},
+	50:  {"flow_sampler_random_interval", kindUnsigned},
+	52:  {"min_ttl", kindUnsigned},
+	53:  {"max_ttl", kindUnsigned},
+	54:  {"ipv4_ident", kindUnsigned},
+	55:  {"dst_tos", kindUnsigned},
+	56:  {"in_src_mac", kindMAC},
+	57:  {"out_dst_mac", kindMAC},
+	58:  {"src_vlan", kindUnsigned},
+	59:  {"dst_vlan", kindUnsigned},
+	60:  {"ip_protocol_version", kindUnsigned},
+	61:  {"direction", kindUnsigned},
+	62:  {"ipv6_next_hop", kindIP},
+	63:  {"bgp_ipv6_next_hop", kindIP},
+	64:  {"ipv6_option_headers", kindUnsigned},
+	80:  {"in_dst_mac", kindMAC},
+	81:  {"out_src_mac", kindMAC},
+	82:  {"if_name", kindString},
+	83:  {"if_desc", kindString},
+	84:  {"sampler_name", kindString},
+	85:  {"in_permanent_bytes", kindUnsigned},
+	86:  {"in_permanent_pkts", kindUnsigned},
+	89:  {"forwarding_status", kindUnsigned},
+	96:  {"application_name", kindString},
+	130: {"exporter_ipv4_address", kindIP},
+	131: {"exporter_ipv6_address", kindIP},
+	136: {"flow_end_reason", kindUnsigned},
+	148: {"flow_id", kindUnsigned},
+	150: {"flow_start_seconds", kindUnsigned},
+	151: {"flow_end_seconds", kindUnsigned},
+	152: {"flow_start_milliseconds", kindUnsigned},
+	153: {"flow_end_milliseconds", kindUnsigned},
+	176: {"icmp_type_ipv4", kindUnsigned},
+	177: {"icmp_code_ipv4", kindUnsigned},
+	178: {"icmp_type_ipv6", kindUnsigned},
+	179: {"icmp_code_ipv6", kindUnsigned},
+	210: {"padding_octets", kindPadding},
+	225: {"post_nat_source_ipv4_address", kindIP},
+	226: {"post_nat_destination_ipv4_address", kindIP},
+	227: {"post_napt_source_transport_port", kindUnsigned},
+	228: {"post_napt_destination_transport_port", kindUnsigned},
+	234: {"ingress_vrf_id", kindUnsigned},
+	235: {"egress_vrf_id", kindUnsigned},
+}
+
+// decodeField returns the name and value of a field of a data record, or an
+// empty name when the field should be omitted from the record. Fields that
+// are not recognised are named after their type and, for enterprise-specific
+// fields, their private enterprise number.
+func decodeField(f templateField, data []byte) (string, any) {
+	def, known := fieldDefs[f.id]
+	if f.enterprise != 0 {
+		def = fieldDef{name: "enterprise_" + strconv.FormatUint(uint64(f.enterprise), 10) + "_" + strconv.Itoa(int(f.id))}
+	} else if !known {
+		def = fieldDef{name: "field_" + strconv.Itoa(int(f.id))}
+	}
+
+	switch def.kind {
+	case kindPadding:
+		return "", nil
+	case kindIP:
+		if len(data) == net.IPv4len || len(data) == net.IPv6len {
+			return def.name, net.IP(data).String()
+		}
+	case kindMAC:
+		if len(data) == 6 {
+			return def.name, net.HardwareAddr(data).String()
+		}
+	case kindString:
+		return def.name, strings.TrimRight(string(data), "\x00")
+	}
+	if len(data) > 0 && len(data) <= 8 {
+		return def.name, unsignedValue(data)
+	}
+	return def.name, hex.EncodeToString(data)
+}
+
+// unsignedValue decodes a big-endian unsigned integer of up to eight bytes,
+// which is represented as an int64 unless it overflows one.
+func unsignedValue(data []byte) any {
+	var v uint64
+	for _, b := range data {
+		v = v<<8 | uint64(b)
+	}
+	if v > math.MaxInt64 {
+		return v
+	}
+	return int64(v)
+}
+
+// recordUint returns an unsigned integer field of a decoded record.
+func recordUint(rec map[string]any, name string) (uint64, bool) {
+	switch v := rec[name].(type) {
+	case int64:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	}
+	return 0, false
+}
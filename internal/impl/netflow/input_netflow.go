@@ -0,0 +1,291 @@
+package netflow
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	nfiFieldAddress      = "address"
+	nfiFieldMaxTemplates = "max_templates"
+)
+
+func netflowInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Network").
+		Version("4.24.0").
+		Summary("Listens for NetFlow v5, NetFlow v9, IPFIX and sFlow v5 datagrams over UDP and emits the flow records they contain as JSON.").
+		Description(`
+The protocol of each datagram is determined by its version number, and so a single input can collect from exporters of any of the supported protocols. The records of each datagram are emitted as a batch, with one message per record.
+
+### NetFlow v9 and IPFIX Templates
+
+The data records of NetFlow v9 and IPFIX are described by templates that exporters send periodically. Templates are retained per exporter and observation domain, and data records that reference a template which has not yet been received are dropped. The number of templates retained across all exporters is limited by the field `+"`max_templates`"+`, and once the limit is reached the least recently used templates are evicted, which causes the records that reference them to be dropped until they are received again. Records of options templates, which describe the exporter rather than flows, are not emitted.
+
+### Records
+
+The fields of NetFlow records are named after the NetFlow v9 field types, such as `+"`ipv4_src_addr`"+`, `+"`l4_dst_port`"+` and `+"`in_bytes`"+`, and IPFIX information elements without a NetFlow v9 equivalent use their IANA names in snake case, such as `+"`flow_start_milliseconds`"+`. Fields that are not recognised are named `+"`field_<type>`"+`, or `+"`enterprise_<number>_<type>`"+` for enterprise-specific fields. Addresses are emitted as strings, integers as numbers and other values as hex strings. When the start and end times of a flow are present they are also emitted as the timestamps `+"`flow_start`"+` and `+"`flow_end`"+`, for example:
+
+`+"```json"+`
+{
+  "ipv4_src_addr": "10.0.0.1",
+  "ipv4_dst_addr": "10.0.0.2",
+  "ipv4_next_hop": "0.0.0.0",
+  "input_snmp": 1,
+  "output_snmp": 2,
+  "in_pkts": 12,
+  "in_bytes": 4821,
+  "first_switched": 7000,
+  "last_switched": 9500,
+  "l4_src_port": 51234,
+  "l4_dst_port": 443,
+  "tcp_flags": 27,
+  "protocol": 6,
+  "src_tos": 0,
+  "src_as": 0,
+  "dst_as": 0,
+  "src_mask": 24,
+  "dst_mask": 24,
+  "engine_type": 0,
+  "engine_id": 0,
+  "sampling_interval": 0,
+  "flow_start": "2023-11-14T22:13:17Z",
+  "flow_end": "2023-11-14T22:13:19.5Z"
+}
+`+"```"+`
+
+Each sFlow flow sample and counter sample is emitted as a record with the field `+"`sample_type`"+` set to `+"`flow`"+` or `+"`counters`"+`. The sampled packet headers of flow samples are decoded into fields with the same names as those of NetFlow records, and the generic interface counters of counter samples are emitted as fields such as `+"`if_index`"+` and `+"`if_in_octets`"+`.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- netflow_exporter
+- netflow_protocol
+- netflow_sequence
+- netflow_observation_domain
+- netflow_agent_address
+`+"```"+`
+
+The field `+"`netflow_exporter`"+` is the address the datagram was received from, and `+"`netflow_protocol`"+` is one of `+"`netflow_v5`"+`, `+"`netflow_v9`"+`, `+"`ipfix`"+` or `+"`sflow_v5`"+`. The field `+"`netflow_observation_domain`"+` is the source ID of NetFlow v9, the observation domain ID of IPFIX or the sub-agent ID of sFlow, and is not set for NetFlow v5. The field `+"`netflow_agent_address`"+` is only set for sFlow.
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).`).
+		Fields(
+			service.NewStringField(nfiFieldAddress).
+				Description("The address to listen for datagrams on.").
+				Default("0.0.0.0:2055").
+				Example("0.0.0.0:4739").
+				Example("0.0.0.0:6343"),
+			service.NewIntField(nfiFieldMaxTemplates).
+				Description("The maximum number of NetFlow v9 and IPFIX templates to retain across all exporters, beyond which the least recently used templates are evicted.").
+				Default(10000).
+				Advanced(),
+		).
+		Example(
+			"Top Talkers",
+			"Here we collect flows from NetFlow and IPFIX exporters and keep only those that carried more than a megabyte.",
+			`
+input:
+  netflow:
+    address: 0.0.0.0:2055
+  processors:
+    - mapping: |
+        root = if this.in_bytes.or(0) < 1000000 { deleted() }
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchInput(
+		"netflow", netflowInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			r, err := newNetflowReader(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacksBatched(r), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type netflowReader struct {
+	log *service.Logger
+
+	address      string
+	maxTemplates int
+
+	connMut   sync.Mutex
+	conn      net.PacketConn
+	batchChan chan service.MessageBatch
+	cancelFn  func()
+	doneChan  chan struct{}
+}
+
+func newNetflowReader(conf *service.ParsedConfig, mgr *service.Resources) (*netflowReader, error) {
+	r := &netflowReader{log: mgr.Logger()}
+
+	var err error
+	if r.address, err = conf.FieldString(nfiFieldAddress); err != nil {
+		return nil, err
+	}
+	if r.maxTemplates, err = conf.FieldInt(nfiFieldMaxTemplates); err != nil {
+		return nil, err
+	}
+	if r.maxTemplates <= 0 {
+		return nil, errors.New("max_templates must be greater than zero")
+	}
+	return r, nil
+}
+
+func (r *netflowReader) Connect(ctx context.Context) error {
+	r.connMut.Lock()
+	defer r.connMut.Unlock()
+	if r.batchChan != nil {
+		return nil
+	}
+
+	dec, err := newDecoder(r.maxTemplates)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.ListenPacket("udp", r.address)
+	if err != nil {
+		return err
+	}
+
+	listenCtx, cancelFn := context.WithCancel(context.Background())
+	batchChan := make(chan service.MessageBatch)
+	doneChan := make(chan struct{})
+	go func() {
+		defer close(doneChan)
+		r.listen(listenCtx, conn, dec, batchChan)
+	}()
+
+	r.log.Infof("Receiving flow datagrams at: %v", conn.LocalAddr())
+
+	r.conn = conn
+	r.batchChan = batchChan
+	r.cancelFn = cancelFn
+	r.doneChan = doneChan
+	return nil
+}
+
+// listen reads datagrams until the connection is closed, and sends a batch of
+// the records of each one to a channel.
+func (r *netflowReader) listen(ctx context.Context, conn net.PacketConn, dec *decoder, batchChan chan<- service.MessageBatch) {
+	defer close(batchChan)
+
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+				r.log.Errorf("Failed to read datagram: %v", err)
+			}
+			return
+		}
+
+		exporter := addr.String()
+		if udpAddr, ok := addr.(*net.UDPAddr); ok {
+			exporter = udpAddr.IP.String()
+		}
+
+		dg, err := dec.decode(exporter, buf[:n])
+		if err != nil {
+			r.log.Debugf("Failed to decode datagram from %v: %v", addr, err)
+			continue
+		}
+		for _, id := range dg.missingTemplates {
+			r.log.Debugf("Dropping records of %v from %v as template %v has not been received", dg.protocol, addr, id)
+		}
+		if len(dg.records) == 0 {
+			continue
+		}
+
+		select {
+		case batchChan <- dg.batch(addr.String()):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// batch returns a message for each record of a datagram.
+func (dg *datagram) batch(exporter string) service.MessageBatch {
+	batch := make(service.MessageBatch, 0, len(dg.records))
+	for _, rec := range dg.records {
+		msg := service.NewMessage(nil)
+		msg.SetStructuredMut(rec)
+		msg.MetaSetMut("netflow_exporter", exporter)
+		msg.MetaSetMut("netflow_protocol", dg.protocol)
+		msg.MetaSetMut("netflow_sequence", strconv.FormatUint(uint64(dg.sequence), 10))
+		if dg.protocol != protocolNetFlowV5 {
+			msg.MetaSetMut("netflow_observation_domain", strconv.FormatUint(uint64(dg.domain), 10))
+		}
+		if dg.agent != nil {
+			msg.MetaSetMut("netflow_agent_address", dg.agent.String())
+		}
+		batch = append(batch, msg)
+	}
+	return batch
+}
+
+func (r *netflowReader) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	r.connMut.Lock()
+	batchChan := r.batchChan
+	r.connMut.Unlock()
+	if batchChan == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	select {
+	case batch, open := <-batchChan:
+		if !open {
+			r.connMut.Lock()
+			r.cancelFn()
+			if r.conn != nil {
+				_ = r.conn.Close()
+			}
+			r.conn, r.batchChan = nil, nil
+			r.connMut.Unlock()
+			return nil, nil, service.ErrNotConnected
+		}
+		return batch, func(ctx context.Context, err error) error {
+			return nil
+		}, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+func (r *netflowReader) Close(ctx context.Context) error {
+	r.connMut.Lock()
+	conn, cancelFn, doneChan := r.conn, r.cancelFn, r.doneChan
+	r.connMut.Unlock()
+	if cancelFn == nil {
+		return nil
+	}
+
+	cancelFn()
+	if conn != nil {
+		_ = conn.Close()
+	}
+	select {
+	case <-doneChan:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package netflow
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestNetflowInput(t *testing.T) {
+	pConf, err := netflowInputSpec().ParseYAML(`address: 127.0.0.1:0`, nil)
+	require.NoError(t, err)
+
+	r, err := newNetflowReader(pConf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, r.Connect(ctx))
+	t.Cleanup(func() {
+		require.NoError(t, r.Close(context.Background()))
+	})
+
+	conn, err := net.Dial("udp", r.conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("not a datagram"))
+	require.NoError(t, err)
+
+	_, err = conn.Write(netflowV5Datagram())
+	require.NoError(t, err)
+
+	batch, ackFn, err := r.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ackFn(ctx, nil))
+	require.Len(t, batch, 1)
+
+	s, err := batch[0].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", s.(map[string]any)["ipv4_src_addr"])
+	assert.Equal(t, int64(4821), s.(map[string]any)["in_bytes"])
+
+	meta := map[string]any{}
+	require.NoError(t, batch[0].MetaWalkMut(func(k string, v any) error {
+		meta[k] = v
+		return nil
+	}))
+	assert.Equal(t, map[string]any{
+		"netflow_exporter": conn.LocalAddr().String(),
+		"netflow_protocol": "netflow_v5",
+		"netflow_sequence": "42",
+	}, meta)
+}
@@ -0,0 +1,259 @@
+package netflow
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// sflowReader reads the big-endian XDR encoded values of an sFlow datagram.
+// Once a read exceeds the remaining data all subsequent reads return zero
+// values and truncated is set.
+type sflowReader struct {
+	data      []byte
+	truncated bool
+}
+
+func (r *sflowReader) bytes(n int) []byte {
+	if n < 0 || len(r.data) < n {
+		r.data, r.truncated = nil, true
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *sflowReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+// opaque reads a length-prefixed block of data, which is padded to a
+// multiple of four bytes.
+func (r *sflowReader) opaque() []byte {
+	n := int(r.uint32())
+	b := r.bytes(n)
+	if pad := (4 - n%4) % 4; pad > 0 && !r.truncated {
+		r.bytes(pad)
+	}
+	return b
+}
+
+func decodeSFlow(data []byte) (*datagram, error) {
+	r := &sflowReader{data: data}
+	_ = r.uint32() // Version.
+
+	dg := &datagram{protocol: protocolSFlowV5}
+	switch addrType := r.uint32(); addrType {
+	case 1:
+		dg.agent = net.IP(append([]byte(nil), r.bytes(4)...))
+	case 2:
+		dg.agent = net.IP(append([]byte(nil), r.bytes(16)...))
+	default:
+		return nil, fmt.Errorf("unsupported sflow agent address type %v", addrType)
+	}
+	dg.domain = r.uint32()
+	dg.sequence = r.uint32()
+	_ = r.uint32() // Uptime.
+	numSamples := int(r.uint32())
+	if r.truncated {
+		return nil, errors.New("sflow header is truncated")
+	}
+
+	for i := 0; i < numSamples; i++ {
+		format := r.uint32()
+		body := r.opaque()
+		if r.truncated {
+			return nil, fmt.Errorf("sflow datagram is truncated, expected %v samples", numSamples)
+		}
+
+		// Samples of enterprises other than the standard sFlow ones are
+		// skipped.
+		if format>>12 != 0 {
+			continue
+		}
+		sr := &sflowReader{data: body}
+		var rec map[string]any
+		switch format & 0xfff {
+		case 1:
+			rec = decodeSFlowFlowSample(sr, false)
+		case 2:
+			rec = decodeSFlowCounterSample(sr, false)
+		case 3:
+			rec = decodeSFlowFlowSample(sr, true)
+		case 4:
+			rec = decodeSFlowCounterSample(sr, true)
+		default:
+			continue
+		}
+		if sr.truncated {
+			return nil, fmt.Errorf("sflow sample %v is truncated", i)
+		}
+		dg.records = append(dg.records, rec)
+	}
+	return dg, nil
+}
+
+func decodeSFlowSourceID(r *sflowReader, rec map[string]any, expanded bool) {
+	rec["sequence_number"] = int64(r.uint32())
+	if expanded {
+		rec["source_id_type"] = int64(r.uint32())
+		rec["source_id_index"] = int64(r.uint32())
+	} else {
+		sourceID := r.uint32()
+		rec["source_id_type"] = int64(sourceID >> 24)
+		rec["source_id_index"] = int64(sourceID & 0xffffff)
+	}
+}
+
+func decodeSFlowFlowSample(r *sflowReader, expanded bool) map[string]any {
+	rec := map[string]any{"sample_type": "flow"}
+	decodeSFlowSourceID(r, rec, expanded)
+	rec["sampling_rate"] = int64(r.uint32())
+	rec["sample_pool"] = int64(r.uint32())
+	rec["drops"] = int64(r.uint32())
+	if expanded {
+		_ = r.uint32() // Input interface format.
+		rec["input_snmp"] = int64(r.uint32())
+		_ = r.uint32() // Output interface format.
+		rec["output_snmp"] = int64(r.uint32())
+	} else {
+		rec["input_snmp"] = int64(r.uint32() & 0x3fffffff)
+		rec["output_snmp"] = int64(r.uint32() & 0x3fffffff)
+	}
+
+	numRecords := int(r.uint32())
+	for i := 0; i < numRecords && !r.truncated; i++ {
+		format := r.uint32()
+		fr := &sflowReader{data: r.opaque()}
+		switch format {
+		case 1: // Raw packet header.
+			protocol := fr.uint32()
+			rec["frame_length"] = int64(fr.uint32())
+			_ = fr.uint32() // Stripped.
+			decodeSampledHeader(rec, protocol, fr.opaque())
+		case 1001: // Extended switch.
+			rec["src_vlan"] = int64(fr.uint32())
+			rec["src_priority"] = int64(fr.uint32())
+			rec["dst_vlan"] = int64(fr.uint32())
+			rec["dst_priority"] = int64(fr.uint32())
+		}
+	}
+	return rec
+}
+
+func decodeSFlowCounterSample(r *sflowReader, expanded bool) map[string]any {
+	rec := map[string]any{"sample_type": "counters"}
+	decodeSFlowSourceID(r, rec, expanded)
+
+	numRecords := int(r.uint32())
+	for i := 0; i < numRecords && !r.truncated; i++ {
+		format := r.uint32()
+		cr := &sflowReader{data: r.opaque()}
+		if format != 1 {
+			continue
+		}
+
+		// Generic interface counters.
+		rec["if_index"] = int64(cr.uint32())
+		rec["if_type"] = int64(cr.uint32())
+		rec["if_speed"] = unsignedValue(cr.bytes(8))
+		rec["if_direction"] = int64(cr.uint32())
+		rec["if_status"] = int64(cr.uint32())
+		rec["if_in_octets"] = unsignedValue(cr.bytes(8))
+		rec["if_in_ucast_pkts"] = int64(cr.uint32())
+		rec["if_in_multicast_pkts"] = int64(cr.uint32())
+		rec["if_in_broadcast_pkts"] = int64(cr.uint32())
+		rec["if_in_discards"] = int64(cr.uint32())
+		rec["if_in_errors"] = int64(cr.uint32())
+		rec["if_in_unknown_protos"] = int64(cr.uint32())
+		rec["if_out_octets"] = unsignedValue(cr.bytes(8))
+		rec["if_out_ucast_pkts"] = int64(cr.uint32())
+		rec["if_out_multicast_pkts"] = int64(cr.uint32())
+		rec["if_out_broadcast_pkts"] = int64(cr.uint32())
+		rec["if_out_discards"] = int64(cr.uint32())
+		rec["if_out_errors"] = int64(cr.uint32())
+		rec["if_promiscuous_mode"] = int64(cr.uint32())
+	}
+	return rec
+}
+
+// decodeSampledHeader adds the fields of the link, network and transport
+// layers of a sampled packet header to a flow sample record, using the names
+// of the equivalent NetFlow fields.
+func decodeSampledHeader(rec map[string]any, protocol uint32, data []byte) {
+	var etherType uint16
+	switch protocol {
+	case 1: // Ethernet.
+		if len(data) < 14 {
+			return
+		}
+		rec["in_dst_mac"] = net.HardwareAddr(data[0:6]).String()
+		rec["in_src_mac"] = net.HardwareAddr(data[6:12]).String()
+		etherType = binary.BigEndian.Uint16(data[12:14])
+		data = data[14:]
+		if etherType == 0x8100 && len(data) >= 4 {
+			if _, exists := rec["src_vlan"]; !exists {
+				rec["src_vlan"] = int64(binary.BigEndian.Uint16(data[0:2]) & 0x0fff)
+			}
+			etherType = binary.BigEndian.Uint16(data[2:4])
+			data = data[4:]
+		}
+	case 11: // IPv4.
+		etherType = 0x0800
+	case 12: // IPv6.
+		etherType = 0x86dd
+	default:
+		return
+	}
+
+	var proto byte
+	switch etherType {
+	case 0x0800:
+		if len(data) < 20 {
+			return
+		}
+		headerLen := int(data[0]&0x0f) * 4
+		proto = data[9]
+		rec["src_tos"] = int64(data[1])
+		rec["protocol"] = int64(proto)
+		rec["ipv4_src_addr"] = net.IP(data[12:16]).String()
+		rec["ipv4_dst_addr"] = net.IP(data[16:20]).String()
+		fragOffset := binary.BigEndian.Uint16(data[6:8]) & 0x1fff
+		if headerLen < 20 || len(data) < headerLen || fragOffset != 0 {
+			return
+		}
+		data = data[headerLen:]
+	case 0x86dd:
+		if len(data) < 40 {
+			return
+		}
+		proto = data[6]
+		rec["src_tos"] = int64(data[0]<<4 | data[1]>>4)
+		rec["protocol"] = int64(proto)
+		rec["ipv6_src_addr"] = net.IP(data[8:24]).String()
+		rec["ipv6_dst_addr"] = net.IP(data[24:40]).String()
+		data = data[40:]
+	default:
+		return
+	}
+
+	switch proto {
+	case 6: // TCP.
+		if len(data) < 14 {
+			return
+		}
+		rec["tcp_flags"] = int64(data[13])
+		fallthrough
+	case 17: // UDP.
+		if len(data) < 4 {
+			return
+		}
+		rec["l4_src_port"] = int64(binary.BigEndian.Uint16(data[0:2]))
+		rec["l4_dst_port"] = int64(binary.BigEndian.Uint16(data[2:4]))
+	}
+}
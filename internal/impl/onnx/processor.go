@@ -0,0 +1,303 @@
+//go:build x_benthos_extra
+// +build x_benthos_extra
+
+package onnx
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	ort "github.com/yalue/onnxruntime_go"
+
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	opFieldModelPath    = "model_path"
+	opFieldLibraryPath  = "library_path"
+	opFieldInputMapping = "input_mapping"
+	opFieldOutputs      = "outputs"
+	opFieldResultMap    = "result_map"
+)
+
+func onnxProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Runs inference with an [ONNX](https://onnx.ai/) model for each message, with inputs mapped from the message and outputs mapped back onto it.").
+		Description(`
+This processor allows models trained with frameworks such as scikit-learn, PyTorch or TensorFlow and exported to ONNX to be executed inline, e.g. in order to score or classify messages without a round trip to a model server.
+
+By default Benthos does not build with components that require linking to external libraries. If you wish to build Benthos locally with this component then set the build tag `+"`x_benthos_extra`"+`:
+
+`+"```shell"+`
+# With go
+go install -tags "x_benthos_extra" github.com/benthosdev/benthos/v4/cmd/benthos@latest
+
+# Using make
+make TAGS=x_benthos_extra
+`+"```"+`
+
+The [ONNX Runtime](https://onnxruntime.ai/) shared library (version 1.19) must also be installed on the host, and can be located with the field `+"`library_path`"+`.
+
+### Inputs
+
+The `+"`input_mapping`"+` must result in an object with a field for each input of the model, where each value is an array of numbers and the shape of the input tensor is determined by the nesting of the arrays. For example, a model with a single input `+"`features`"+` of shape `+"`[1, 3]`"+` would be provided with `+"`root.features = [[ this.amount, this.age, this.score ]]`"+`. Values are converted to the element type of each input as declared by the model.
+
+### Outputs
+
+The outputs of the model are converted into an object with a field for each output, where tensors are converted into (nested) arrays of numbers, sequences into arrays, and maps into objects. String tensors are not currently supported.
+
+When a `+"`result_map`"+` is specified it is executed with `+"`this`"+` referring to the object of outputs and `+"`root`"+` referring to the original message, otherwise the message is replaced with the object of outputs.
+
+### Parallelism
+
+A single model session is shared across processing threads, which the ONNX Runtime supports executing concurrently.`).
+		Fields(
+			service.NewStringField(opFieldModelPath).
+				Description("The path of the ONNX model to load.").
+				Example("./models/fraud.onnx"),
+			service.NewStringField(opFieldLibraryPath).
+				Description("An optional path to the ONNX Runtime shared library, when omitted the library is located by the system. Since the library is loaded once per process only the first path specified is used.").
+				Example("/usr/lib/libonnxruntime.so").
+				Optional().
+				Advanced(),
+			service.NewBloblangField(opFieldInputMapping).
+				Description("A [Bloblang mapping](/docs/guides/bloblang/about) that results in an object with a field for each input of the model.").
+				Example(`root.float_input = [[ this.amount, this.merchant_risk, this.account_age_days ]]`),
+			service.NewStringListField(opFieldOutputs).
+				Description("An optional list of model outputs to fetch, when omitted all outputs of the model are fetched.").
+				Example([]string{"label", "probabilities"}).
+				Optional().
+				Advanced(),
+			service.NewBloblangField(opFieldResultMap).
+				Description("An optional [Bloblang mapping](/docs/guides/bloblang/about) that maps the outputs of the model onto the message, where `this` refers to the object of outputs and `root` refers to the original message. When omitted the message is replaced with the object of outputs.").
+				Example(`root.fraud_score = this.probabilities.index(0).get("1")`).
+				Optional(),
+		).
+		Example(
+			"Fraud Scoring",
+			"Here we score transactions with a scikit-learn classifier exported to ONNX, adding the predicted label and the probability of fraud to each transaction.",
+			`
+pipeline:
+  processors:
+    - onnx:
+        model_path: ./models/fraud.onnx
+        input_mapping: |
+          root.float_input = [[ this.amount, this.merchant_risk, this.account_age_days ]]
+        result_map: |
+          root.fraud_label = this.output_label.index(0)
+          root.fraud_score = this.output_probability.index(0).get("1")
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"onnx", onnxProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			return newONNXProcessorFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+var (
+	ortEnvOnce sync.Once
+	ortEnvErr  error
+)
+
+// initEnvironment initialises the ONNX Runtime environment, which is shared
+// by all processors of the process and therefore only initialised once.
+func initEnvironment(libraryPath string) error {
+	ortEnvOnce.Do(func() {
+		if libraryPath != "" {
+			ort.SetSharedLibraryPath(libraryPath)
+		}
+		if ortEnvErr = ort.InitializeEnvironment(); ortEnvErr != nil {
+			ortEnvErr = fmt.Errorf("failed to initialise onnx runtime: %w", ortEnvErr)
+		}
+	})
+	return ortEnvErr
+}
+
+type onnxProcessor struct {
+	session     *ort.DynamicAdvancedSession
+	inputs      []ort.InputOutputInfo
+	outputNames []string
+
+	inputMapping *bloblang.Executor
+	resultMap    *bloblang.Executor
+
+	log *service.Logger
+}
+
+func newONNXProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*onnxProcessor, error) {
+	p := &onnxProcessor{log: mgr.Logger()}
+
+	modelPath, err := conf.FieldString(opFieldModelPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var libraryPath string
+	if conf.Contains(opFieldLibraryPath) {
+		if libraryPath, err = conf.FieldString(opFieldLibraryPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.inputMapping, err = conf.FieldBloblang(opFieldInputMapping); err != nil {
+		return nil, err
+	}
+
+	if conf.Contains(opFieldResultMap) {
+		if p.resultMap, err = conf.FieldBloblang(opFieldResultMap); err != nil {
+			return nil, err
+		}
+	}
+
+	var outputNames []string
+	if conf.Contains(opFieldOutputs) {
+		if outputNames, err = conf.FieldStringList(opFieldOutputs); err != nil {
+			return nil, err
+		}
+	}
+
+	modelBytes, err := ifs.ReadFile(mgr.FS(), modelPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model: %w", err)
+	}
+
+	if err := initEnvironment(libraryPath); err != nil {
+		return nil, err
+	}
+
+	inputs, outputs, err := ort.GetInputOutputInfoWithONNXData(modelBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model inputs and outputs: %w", err)
+	}
+	p.inputs = inputs
+
+	if len(outputNames) == 0 {
+		for _, o := range outputs {
+			outputNames = append(outputNames, o.Name)
+		}
+	} else {
+		for _, name := range outputNames {
+			var exists bool
+			for _, o := range outputs {
+				if o.Name == name {
+					exists = true
+					break
+				}
+			}
+			if !exists {
+				return nil, fmt.Errorf("model does not have an output named '%v'", name)
+			}
+		}
+	}
+	p.outputNames = outputNames
+
+	inputNames := make([]string, 0, len(inputs))
+	for _, i := range inputs {
+		inputNames = append(inputNames, i.Name)
+	}
+
+	if p.session, err = ort.NewDynamicAdvancedSessionWithONNXData(modelBytes, inputNames, outputNames, nil); err != nil {
+		return nil, fmt.Errorf("failed to create model session: %w", err)
+	}
+	return p, nil
+}
+
+func destroyValues(values []ort.Value) {
+	for _, v := range values {
+		if v != nil {
+			_ = v.Destroy()
+		}
+	}
+}
+
+func (p *onnxProcessor) infer(msg *service.Message) (map[string]any, error) {
+	inMsg, err := msg.BloblangQuery(p.inputMapping)
+	if err != nil {
+		return nil, fmt.Errorf("input mapping failed: %w", err)
+	}
+
+	inV, err := inMsg.AsStructured()
+	if err != nil {
+		return nil, fmt.Errorf("input mapping returned non-structured result: %w", err)
+	}
+
+	inObj, ok := inV.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("input mapping returned non-object result: %T", inV)
+	}
+
+	inputs := make([]ort.Value, len(p.inputs))
+	defer destroyValues(inputs)
+
+	for i, info := range p.inputs {
+		v, exists := inObj[info.Name]
+		if !exists {
+			return nil, fmt.Errorf("input mapping result is missing model input '%v'", info.Name)
+		}
+		if inputs[i], err = newTensor(info.DataType, v); err != nil {
+			return nil, fmt.Errorf("input '%v': %w", info.Name, err)
+		}
+	}
+
+	outputs := make([]ort.Value, len(p.outputNames))
+	defer destroyValues(outputs)
+
+	if err := p.session.Run(inputs, outputs); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]any, len(outputs))
+	for i, name := range p.outputNames {
+		if res[name], err = valueToAny(outputs[i]); err != nil {
+			return nil, fmt.Errorf("output '%v': %w", name, err)
+		}
+	}
+	return res, nil
+}
+
+func (p *onnxProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	res, err := p.infer(msg)
+	if err != nil {
+		p.log.Debugf("Inference failed: %v", err)
+		return nil, err
+	}
+
+	if p.resultMap == nil {
+		msg.SetStructuredMut(res)
+		return service.MessageBatch{msg}, nil
+	}
+
+	resMsg := service.NewMessage(nil)
+	resMsg.SetStructuredMut(res)
+
+	newMsg, err := msg.BloblangMutateFrom(p.resultMap, resMsg)
+	if err != nil {
+		return nil, fmt.Errorf("result mapping failed: %w", err)
+	}
+	if newMsg == nil {
+		return nil, nil
+	}
+	return service.MessageBatch{newMsg}, nil
+}
+
+func (p *onnxProcessor) Close(ctx context.Context) error {
+	if err := p.session.Destroy(); err != nil {
+		return fmt.Errorf("failed to destroy model session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+//go:build x_benthos_extra
+// +build x_benthos_extra
+
+package onnx
+
+import (
+	"errors"
+	"fmt"
+
+	ort "github.com/yalue/onnxruntime_go"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
+// flatten walks a (nested) array of values and returns its shape along with
+// its values in row-major order. Arrays at the same depth must be of equal
+// length.
+func flatten(v any) (ort.Shape, []any, error) {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("expected array, got %T", v)
+	}
+	if len(arr) == 0 {
+		return nil, nil, errors.New("arrays must not be empty")
+	}
+
+	if _, nested := arr[0].([]any); !nested {
+		for _, e := range arr {
+			if _, isArr := e.([]any); isArr {
+				return nil, nil, errors.New("arrays must be of a consistent depth")
+			}
+		}
+		return ort.NewShape(int64(len(arr))), arr, nil
+	}
+
+	var shape ort.Shape
+	var flat []any
+	for i, e := range arr {
+		eShape, eFlat, err := flatten(e)
+		if err != nil {
+			return nil, nil, err
+		}
+		if i == 0 {
+			shape = eShape
+		} else if !shape.Equals(eShape) {
+			return nil, nil, fmt.Errorf("arrays must be of equal length, expected shape %v, got %v", shape, eShape)
+		}
+		flat = append(flat, eFlat...)
+	}
+	return append(ort.NewShape(int64(len(arr))), shape...), flat, nil
+}
+
+func convertValues[T ort.TensorData](values []any, conv func(any) (T, error)) ([]T, error) {
+	data := make([]T, len(values))
+	for i, v := range values {
+		var err error
+		if data[i], err = conv(v); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func newTensorOf[T ort.TensorData](shape ort.Shape, values []any, conv func(any) (T, error)) (ort.Value, error) {
+	data, err := convertValues(values, conv)
+	if err != nil {
+		return nil, err
+	}
+	return ort.NewTensor(shape, data)
+}
+
+// newTensor creates a tensor of a given element type from a (nested) array of
+// values.
+func newTensor(dataType ort.TensorElementDataType, v any) (ort.Value, error) {
+	shape, values, err := flatten(v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch dataType {
+	case ort.TensorElementDataTypeFloat:
+		return newTensorOf(shape, values, query.IToFloat32)
+	case ort.TensorElementDataTypeDouble:
+		return newTensorOf(shape, values, query.IToFloat64)
+	case ort.TensorElementDataTypeInt8:
+		return newTensorOf(shape, values, query.IToInt8)
+	case ort.TensorElementDataTypeInt16:
+		return newTensorOf(shape, values, query.IToInt16)
+	case ort.TensorElementDataTypeInt32:
+		return newTensorOf(shape, values, query.IToInt32)
+	case ort.TensorElementDataTypeInt64:
+		return newTensorOf(shape, values, query.IToInt)
+	case ort.TensorElementDataTypeUint8:
+		return newTensorOf(shape, values, query.IToUint8)
+	case ort.TensorElementDataTypeUint16:
+		return newTensorOf(shape, values, query.IToUint16)
+	case ort.TensorElementDataTypeUint32:
+		return newTensorOf(shape, values, query.IToUint32)
+	case ort.TensorElementDataTypeUint64:
+		return newTensorOf(shape, values, query.IToUint)
+	}
+	return nil, fmt.Errorf("unsupported element type: %v", dataType)
+}
+
+// unflatten converts row-major values into (nested) arrays of a given shape.
+func unflatten[T ort.TensorData](shape ort.Shape, data []T) any {
+	if len(shape) == 0 {
+		if len(data) == 0 {
+			return nil
+		}
+		return data[0]
+	}
+	if len(shape) == 1 {
+		arr := make([]any, len(data))
+		for i, v := range data {
+			arr[i] = v
+		}
+		return arr
+	}
+
+	n := int(shape[0])
+	arr := make([]any, n)
+	if n == 0 {
+		return arr
+	}
+	stride := len(data) / n
+	for i := range arr {
+		arr[i] = unflatten(shape[1:], data[i*stride:(i+1)*stride])
+	}
+	return arr
+}
+
+func tensorToAny(v ort.Value) (any, error) {
+	switch t := v.(type) {
+	case *ort.Tensor[float32]:
+		return unflatten(t.GetShape(), t.GetData()), nil
+	case *ort.Tensor[float64]:
+		return unflatten(t.GetShape(), t.GetData()), nil
+	case *ort.Tensor[int8]:
+		return unflatten(t.GetShape(), t.GetData()), nil
+	case *ort.Tensor[int16]:
+		return unflatten(t.GetShape(), t.GetData()), nil
+	case *ort.Tensor[int32]:
+		return unflatten(t.GetShape(), t.GetData()), nil
+	case *ort.Tensor[int64]:
+		return unflatten(t.GetShape(), t.GetData()), nil
+	case *ort.Tensor[uint8]:
+		return unflatten(t.GetShape(), t.GetData()), nil
+	case *ort.Tensor[uint16]:
+		return unflatten(t.GetShape(), t.GetData()), nil
+	case *ort.Tensor[uint32]:
+		return unflatten(t.GetShape(), t.GetData()), nil
+	case *ort.Tensor[uint64]:
+		return unflatten(t.GetShape(), t.GetData()), nil
+	}
+	return nil, fmt.Errorf("unsupported tensor type: %T", v)
+}
+
+// valueToAny converts an output of a model into a structured value.
+func valueToAny(v ort.Value) (any, error) {
+	switch t := v.(type) {
+	case *ort.Sequence:
+		values, err := t.GetValues()
+		if err != nil {
+			return nil, err
+		}
+		arr := make([]any, len(values))
+		for i, e := range values {
+			if arr[i], err = valueToAny(e); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	case *ort.Map:
+		keysV, valuesV, err := t.GetKeysAndValues()
+		if err != nil {
+			return nil, err
+		}
+		keys, err := tensorToAny(keysV)
+		if err != nil {
+			return nil, fmt.Errorf("map keys: %w", err)
+		}
+		values, err := tensorToAny(valuesV)
+		if err != nil {
+			return nil, fmt.Errorf("map values: %w", err)
+		}
+		return zipMap(keys, values)
+	}
+	return tensorToAny(v)
+}
+
+// zipMap combines arrays of keys and values into an object.
+func zipMap(keys, values any) (map[string]any, error) {
+	keysArr, ok := keys.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected array of map keys, got %T", keys)
+	}
+	valuesArr, ok := values.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected array of map values, got %T", values)
+	}
+	if len(keysArr) != len(valuesArr) {
+		return nil, fmt.Errorf("mismatched number of map keys (%v) and values (%v)", len(keysArr), len(valuesArr))
+	}
+
+	obj := make(map[string]any, len(keysArr))
+	for i, k := range keysArr {
+		obj[fmt.Sprintf("%v", k)] = valuesArr[i]
+	}
+	return obj, nil
+}
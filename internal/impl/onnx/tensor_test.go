@@ -0,0 +1,93 @@
+//go:build x_benthos_extra
+// +build x_benthos_extra
+
+package onnx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  any
+		shape  ort.Shape
+		values []any
+		err    string
+	}{
+		{
+			name:   "single dimension",
+			input:  []any{1.0, 2.0, 3.0},
+			shape:  ort.NewShape(3),
+			values: []any{1.0, 2.0, 3.0},
+		},
+		{
+			name:   "batch of one",
+			input:  []any{[]any{1.0, 2.0, 3.0}},
+			shape:  ort.NewShape(1, 3),
+			values: []any{1.0, 2.0, 3.0},
+		},
+		{
+			name:   "three dimensions",
+			input:  []any{[]any{[]any{1, 2}, []any{3, 4}}, []any{[]any{5, 6}, []any{7, 8}}},
+			shape:  ort.NewShape(2, 2, 2),
+			values: []any{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		{
+			name:  "scalar",
+			input: 1.0,
+			err:   "expected array, got float64",
+		},
+		{
+			name:  "empty",
+			input: []any{},
+			err:   "arrays must not be empty",
+		},
+		{
+			name:  "ragged",
+			input: []any{[]any{1, 2}, []any{3}},
+			err:   "arrays must be of equal length, expected shape [2], got [1]",
+		},
+		{
+			name:  "inconsistent depth",
+			input: []any{1, []any{3}},
+			err:   "arrays must be of a consistent depth",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			shape, values, err := flatten(test.input)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.shape, shape)
+			assert.Equal(t, test.values, values)
+		})
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	assert.Equal(t, []any{float32(1), float32(2)}, unflatten(ort.NewShape(2), []float32{1, 2}))
+	assert.Equal(t, []any{
+		[]any{int64(1), int64(2), int64(3)},
+		[]any{int64(4), int64(5), int64(6)},
+	}, unflatten(ort.NewShape(2, 3), []int64{1, 2, 3, 4, 5, 6}))
+	assert.Equal(t, int64(7), unflatten(ort.Shape{}, []int64{7}))
+}
+
+func TestZipMap(t *testing.T) {
+	obj, err := zipMap([]any{int64(0), int64(1)}, []any{float32(0.25), float32(0.75)})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"0": float32(0.25), "1": float32(0.75)}, obj)
+
+	_, err = zipMap([]any{int64(0)}, []any{})
+	require.EqualError(t, err, "mismatched number of map keys (1) and values (0)")
+}
@@ -0,0 +1,222 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	oFieldServerAddress      = "server_address"
+	oFieldAPIKey             = "api_key"
+	oFieldModel              = "model"
+	oFieldTimeout            = "timeout"
+	oFieldMaxTokensPerMinute = "max_tokens_per_minute"
+	oFieldPricing            = "pricing"
+	oFieldPricingInput       = "input_per_million_tokens"
+	oFieldPricingOutput      = "output_per_million_tokens"
+)
+
+func baseConfigFields() []*service.ConfigField {
+	return []*service.ConfigField{
+		service.NewURLField(oFieldServerAddress).
+			Description("The base URL of an OpenAI compatible API.").
+			Default("https://api.openai.com/v1"),
+		service.NewStringField(oFieldAPIKey).
+			Description("The API key used to authenticate requests.").
+			Secret(),
+		service.NewStringField(oFieldModel).
+			Description("The name of the model to use."),
+		service.NewDurationField(oFieldTimeout).
+			Description("The maximum period to wait for each request to complete.").
+			Default("30s").
+			Advanced(),
+		service.NewIntField(oFieldMaxTokensPerMinute).
+			Description("An optional limit to the number of tokens consumed per minute across requests made by this processor, as reported by the API. Once the limit is reached requests are paused until enough time has elapsed. Zero means no limit.").
+			Default(0).
+			Advanced(),
+		service.NewObjectField(oFieldPricing,
+			service.NewFloatField(oFieldPricingInput).
+				Description("The cost of one million input (prompt) tokens.").
+				Default(0.0),
+			service.NewFloatField(oFieldPricingOutput).
+				Description("The cost of one million output (completion) tokens.").
+				Default(0.0),
+		).
+			Description("The pricing of the model, which is used to calculate the `openai_cost` metric.").
+			Advanced(),
+	}
+}
+
+const baseMetricsDescription = `
+### Metrics
+
+This processor emits the counter metrics ` + "`openai_prompt_tokens`, `openai_completion_tokens` and `openai_cost`" + `, each labelled with the model, where the cost is calculated from the ` + "`pricing`" + ` of the model.`
+
+//------------------------------------------------------------------------------
+
+type usage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+type apiError struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// baseClient contains the shared functionality for calling an OpenAI
+// compatible API, including rate limiting by tokens and usage metrics.
+type baseClient struct {
+	serverAddress string
+	apiKey        string
+	model         string
+	client        *http.Client
+
+	limiter     *tokenLimiter
+	inputPrice  float64
+	outputPrice float64
+
+	mPromptTokens     *service.MetricCounter
+	mCompletionTokens *service.MetricCounter
+	mCost             *service.MetricCounter
+
+	log *service.Logger
+}
+
+func newBaseClientFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*baseClient, error) {
+	c := &baseClient{
+		mPromptTokens:     mgr.Metrics().NewCounter("openai_prompt_tokens", "model"),
+		mCompletionTokens: mgr.Metrics().NewCounter("openai_completion_tokens", "model"),
+		mCost:             mgr.Metrics().NewCounter("openai_cost", "model"),
+		log:               mgr.Logger(),
+	}
+
+	var err error
+	if c.serverAddress, err = conf.FieldString(oFieldServerAddress); err != nil {
+		return nil, err
+	}
+	c.serverAddress = strings.TrimSuffix(c.serverAddress, "/")
+
+	if c.apiKey, err = conf.FieldString(oFieldAPIKey); err != nil {
+		return nil, err
+	}
+	if c.model, err = conf.FieldString(oFieldModel); err != nil {
+		return nil, err
+	}
+
+	timeout, err := conf.FieldDuration(oFieldTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c.client = &http.Client{Timeout: timeout}
+
+	tpm, err := conf.FieldInt(oFieldMaxTokensPerMinute)
+	if err != nil {
+		return nil, err
+	}
+	if tpm > 0 {
+		c.limiter = newTokenLimiter(tpm, time.Minute)
+	}
+
+	if c.inputPrice, err = conf.FieldFloat(oFieldPricing, oFieldPricingInput); err != nil {
+		return nil, err
+	}
+	if c.outputPrice, err = conf.FieldFloat(oFieldPricing, oFieldPricingOutput); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// call sends a request body to an endpoint of the API and decodes the response
+// into res, recording the usage reported by the response.
+func (c *baseClient) call(ctx context.Context, endpoint string, body, res any) (*usage, error) {
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	reqBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.serverAddress+endpoint, bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	resBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr apiError
+		if jErr := json.Unmarshal(resBytes, &apiErr); jErr == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("request failed with status %v: %v", resp.StatusCode, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("request failed with status %v: %s", resp.StatusCode, resBytes)
+	}
+
+	var wrapped struct {
+		Usage *usage `json:"usage"`
+	}
+	if err := json.Unmarshal(resBytes, &wrapped); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if err := json.Unmarshal(resBytes, res); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	u := wrapped.Usage
+	if u == nil {
+		u = &usage{}
+	}
+	c.recordUsage(u)
+	return u, nil
+}
+
+func (c *baseClient) recordUsage(u *usage) {
+	if c.limiter != nil {
+		total := u.TotalTokens
+		if total == 0 {
+			total = u.PromptTokens + u.CompletionTokens
+		}
+		c.limiter.consume(total)
+	}
+
+	c.mPromptTokens.Incr(u.PromptTokens, c.model)
+	c.mCompletionTokens.Incr(u.CompletionTokens, c.model)
+	if cost := (float64(u.PromptTokens)*c.inputPrice + float64(u.CompletionTokens)*c.outputPrice) / 1e6; cost > 0 {
+		c.mCost.IncrFloat64(cost, c.model)
+	}
+}
+
+func setUsageMetadata(msg *service.Message, model string, u *usage) {
+	msg.MetaSetMut("openai_model", model)
+	msg.MetaSetMut("openai_prompt_tokens", u.PromptTokens)
+	msg.MetaSetMut("openai_completion_tokens", u.CompletionTokens)
+	msg.MetaSetMut("openai_total_tokens", u.TotalTokens)
+}
@@ -0,0 +1,299 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	ccpFieldPrompt         = "prompt"
+	ccpFieldSystemPrompt   = "system_prompt"
+	ccpFieldMaxTokens      = "max_tokens"
+	ccpFieldTemperature    = "temperature"
+	ccpFieldResponseFormat = "response_format"
+	ccpFieldResponseSchema = "response_schema"
+)
+
+func chatCompletionProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Integration").
+		Version("4.24.0").
+		Summary("Generates a response to a prompt for each message using the chat completion API of [OpenAI](https://platform.openai.com/docs/api-reference/chat), or any API compatible with it.").
+		Description(`
+The content of each message is replaced with the response of the model. The prompt can be constructed from the contents of a message with the `+"`prompt`"+` field, otherwise the content of the message is used as the prompt.
+
+### Structured Responses
+
+When `+"`response_format`"+` is set to `+"`json`"+` the model is instructed to respond with a JSON object, which is parsed into the structured content of the message. Specifying a `+"`response_schema`"+` implies a JSON response, and each response is validated against the schema, where a response that fails validation results in a processing error that can be handled with [error handling patterns](/docs/configuration/error_handling).
+
+### Metadata
+
+This processor adds the following metadata fields to each message:
+
+`+"```text"+`
+- openai_model
+- openai_finish_reason
+- openai_prompt_tokens
+- openai_completion_tokens
+- openai_total_tokens
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).
+`+baseMetricsDescription).
+		Fields(baseConfigFields()...).
+		Fields(
+			service.NewInterpolatedStringField(ccpFieldPrompt).
+				Description("The prompt to send to the model, when omitted the content of each message is used as the prompt.").
+				Example("Summarise the following support ticket in one sentence: ${! this.body }").
+				Optional(),
+			service.NewInterpolatedStringField(ccpFieldSystemPrompt).
+				Description("An optional system prompt that instructs the model how to respond.").
+				Example("You are a helpful assistant that classifies support tickets.").
+				Optional(),
+			service.NewIntField(ccpFieldMaxTokens).
+				Description("An optional maximum number of tokens to generate for each response.").
+				Optional().
+				Advanced(),
+			service.NewFloatField(ccpFieldTemperature).
+				Description("An optional sampling temperature between 0 and 2, where higher values result in more random responses.").
+				Optional().
+				Advanced(),
+			service.NewStringEnumField(ccpFieldResponseFormat, "text", "json").
+				Description("The format of responses, where `json` instructs the model to respond with a JSON object.").
+				Default("text"),
+			service.NewStringField(ccpFieldResponseSchema).
+				Description("An optional [JSON Schema](https://json-schema.org/) that responses must conform to, which implies a `response_format` of `json`.").
+				Example(`{"type":"object","properties":{"category":{"type":"string"}},"required":["category"]}`).
+				Optional(),
+		).
+		Example(
+			"Classify Support Tickets",
+			"Here we ask a model to categorise support tickets, validating that each response contains a category before adding it to the ticket.",
+			`
+pipeline:
+  processors:
+    - branch:
+        request_map: 'root = this.body'
+        processors:
+          - openai_chat_completion:
+              api_key: "${OPENAI_API_KEY}"
+              model: gpt-4o-mini
+              system_prompt: |
+                Classify the support ticket into one of the categories billing, technical or other.
+                Respond with a JSON object with a single field "category".
+              response_schema: |
+                {
+                  "type": "object",
+                  "properties": { "category": { "enum": [ "billing", "technical", "other" ] } },
+                  "required": [ "category" ]
+                }
+        result_map: 'root.category = this.category'
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"openai_chat_completion", chatCompletionProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			return newChatCompletionProcessorFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatResponseFormat struct {
+	Type string `json:"type"`
+}
+
+type chatRequest struct {
+	Model          string              `json:"model"`
+	Messages       []chatMessage       `json:"messages"`
+	MaxTokens      *int                `json:"max_tokens,omitempty"`
+	Temperature    *float64            `json:"temperature,omitempty"`
+	ResponseFormat *chatResponseFormat `json:"response_format,omitempty"`
+}
+
+type chatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      chatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+type chatCompletionProcessor struct {
+	client *baseClient
+
+	prompt       *service.InterpolatedString
+	systemPrompt *service.InterpolatedString
+	maxTokens    *int
+	temperature  *float64
+	jsonResponse bool
+	schema       *gojsonschema.Schema
+}
+
+func newChatCompletionProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*chatCompletionProcessor, error) {
+	client, err := newBaseClientFromConfig(conf, mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &chatCompletionProcessor{client: client}
+
+	if conf.Contains(ccpFieldPrompt) {
+		if p.prompt, err = conf.FieldInterpolatedString(ccpFieldPrompt); err != nil {
+			return nil, err
+		}
+	}
+	if conf.Contains(ccpFieldSystemPrompt) {
+		if p.systemPrompt, err = conf.FieldInterpolatedString(ccpFieldSystemPrompt); err != nil {
+			return nil, err
+		}
+	}
+	if conf.Contains(ccpFieldMaxTokens) {
+		maxTokens, err := conf.FieldInt(ccpFieldMaxTokens)
+		if err != nil {
+			return nil, err
+		}
+		p.maxTokens = &maxTokens
+	}
+	if conf.Contains(ccpFieldTemperature) {
+		temperature, err := conf.FieldFloat(ccpFieldTemperature)
+		if err != nil {
+			return nil, err
+		}
+		p.temperature = &temperature
+	}
+
+	format, err := conf.FieldString(ccpFieldResponseFormat)
+	if err != nil {
+		return nil, err
+	}
+	p.jsonResponse = format == "json"
+
+	if conf.Contains(ccpFieldResponseSchema) {
+		schemaStr, err := conf.FieldString(ccpFieldResponseSchema)
+		if err != nil {
+			return nil, err
+		}
+		if p.schema, err = gojsonschema.NewSchema(gojsonschema.NewStringLoader(schemaStr)); err != nil {
+			return nil, fmt.Errorf("failed to parse response schema: %w", err)
+		}
+		p.jsonResponse = true
+	}
+	return p, nil
+}
+
+func (p *chatCompletionProcessor) request(msg *service.Message) (*chatRequest, error) {
+	req := &chatRequest{
+		Model:       p.client.model,
+		MaxTokens:   p.maxTokens,
+		Temperature: p.temperature,
+	}
+	if p.jsonResponse {
+		req.ResponseFormat = &chatResponseFormat{Type: "json_object"}
+	}
+
+	if p.systemPrompt != nil {
+		systemPrompt, err := p.systemPrompt.TryString(msg)
+		if err != nil {
+			return nil, fmt.Errorf("system prompt interpolation error: %w", err)
+		}
+		req.Messages = append(req.Messages, chatMessage{Role: "system", Content: systemPrompt})
+	}
+
+	var prompt string
+	if p.prompt != nil {
+		var err error
+		if prompt, err = p.prompt.TryString(msg); err != nil {
+			return nil, fmt.Errorf("prompt interpolation error: %w", err)
+		}
+	} else {
+		mBytes, err := msg.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		prompt = string(mBytes)
+	}
+	req.Messages = append(req.Messages, chatMessage{Role: "user", Content: prompt})
+	return req, nil
+}
+
+func (p *chatCompletionProcessor) parseJSON(content string) (any, error) {
+	var v any
+	if err := json.Unmarshal([]byte(content), &v); err != nil {
+		return nil, fmt.Errorf("failed to parse response as JSON: %w", err)
+	}
+	if p.schema == nil {
+		return v, nil
+	}
+
+	result, err := p.schema.Validate(gojsonschema.NewGoLoader(v))
+	if err != nil {
+		return nil, err
+	}
+	if !result.Valid() {
+		var errStrs []string
+		for _, desc := range result.Errors() {
+			errStrs = append(errStrs, desc.String())
+		}
+		return nil, fmt.Errorf("response failed schema validation: %v", strings.Join(errStrs, "\n"))
+	}
+	return v, nil
+}
+
+func (p *chatCompletionProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	req, err := p.request(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var res chatResponse
+	u, err := p.client.call(ctx, "/chat/completions", req, &res)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Choices) == 0 {
+		return nil, errors.New("response contained no choices")
+	}
+	choice := res.Choices[0]
+
+	if p.jsonResponse {
+		v, err := p.parseJSON(choice.Message.Content)
+		if err != nil {
+			return nil, err
+		}
+		msg.SetStructuredMut(v)
+	} else {
+		msg.SetBytes([]byte(choice.Message.Content))
+	}
+
+	model := res.Model
+	if model == "" {
+		model = p.client.model
+	}
+	setUsageMetadata(msg, model, u)
+	msg.MetaSetMut("openai_finish_reason", choice.FinishReason)
+	return service.MessageBatch{msg}, nil
+}
+
+func (p *chatCompletionProcessor) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,198 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	epFieldInput      = "input"
+	epFieldDimensions = "dimensions"
+)
+
+func embeddingsProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Integration").
+		Version("4.24.0").
+		Summary("Generates vector embeddings for messages using the embeddings API of [OpenAI](https://platform.openai.com/docs/api-reference/embeddings), or any API compatible with it.").
+		Description(`
+The content of each message is replaced with an array of numbers representing its embedding. The text to embed can be constructed from the contents of a message with the `+"`input`"+` field, otherwise the content of the message is embedded.
+
+A single request is made for each batch of messages, and therefore batching messages prior to this processor reduces the number of requests made.
+
+### Metadata
+
+This processor adds the following metadata fields to each message, where the token counts are those of the entire batch:
+
+`+"```text"+`
+- openai_model
+- openai_prompt_tokens
+- openai_completion_tokens
+- openai_total_tokens
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).
+`+baseMetricsDescription).
+		Fields(baseConfigFields()...).
+		Fields(
+			service.NewInterpolatedStringField(epFieldInput).
+				Description("The text to embed, when omitted the content of each message is embedded.").
+				Example("${! this.title }\n\n${! this.body }").
+				Optional(),
+			service.NewIntField(epFieldDimensions).
+				Description("An optional number of dimensions of the resulting embeddings, which is only supported by some models.").
+				Optional().
+				Advanced(),
+		).
+		Example(
+			"Embed Documents",
+			"Here we add an embedding of the body of each document to the document, sending batches of up to 100 documents per request.",
+			`
+input:
+  kafka_franz:
+    seed_brokers: [ localhost:9092 ]
+    topics: [ documents ]
+    batching:
+      count: 100
+      period: 1s
+
+pipeline:
+  processors:
+    - branch:
+        request_map: 'root = this.body'
+        processors:
+          - openai_embeddings:
+              api_key: "${OPENAI_API_KEY}"
+              model: text-embedding-3-small
+        result_map: 'root.embedding = this'
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchProcessor(
+		"openai_embeddings", embeddingsProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchProcessor, error) {
+			return newEmbeddingsProcessorFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type embeddingsRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions *int     `json:"dimensions,omitempty"`
+}
+
+type embeddingsResponse struct {
+	Model string `json:"model"`
+	Data  []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+type embeddingsProcessor struct {
+	client *baseClient
+
+	input      *service.InterpolatedString
+	dimensions *int
+}
+
+func newEmbeddingsProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*embeddingsProcessor, error) {
+	client, err := newBaseClientFromConfig(conf, mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &embeddingsProcessor{client: client}
+	if conf.Contains(epFieldInput) {
+		if p.input, err = conf.FieldInterpolatedString(epFieldInput); err != nil {
+			return nil, err
+		}
+	}
+	if conf.Contains(epFieldDimensions) {
+		dimensions, err := conf.FieldInt(epFieldDimensions)
+		if err != nil {
+			return nil, err
+		}
+		p.dimensions = &dimensions
+	}
+	return p, nil
+}
+
+func (p *embeddingsProcessor) ProcessBatch(ctx context.Context, batch service.MessageBatch) ([]service.MessageBatch, error) {
+	if len(batch) == 0 {
+		return nil, nil
+	}
+
+	req := embeddingsRequest{
+		Model:      p.client.model,
+		Input:      make([]string, len(batch)),
+		Dimensions: p.dimensions,
+	}
+
+	for i, msg := range batch {
+		if p.input != nil {
+			input, err := batch.TryInterpolatedString(i, p.input)
+			if err != nil {
+				return nil, fmt.Errorf("input interpolation error: %w", err)
+			}
+			req.Input[i] = input
+			continue
+		}
+		mBytes, err := msg.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		req.Input[i] = string(mBytes)
+	}
+
+	var res embeddingsResponse
+	u, err := p.client.call(ctx, "/embeddings", req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings := make([][]float64, len(batch))
+	for _, d := range res.Data {
+		if d.Index < 0 || d.Index >= len(batch) {
+			return nil, fmt.Errorf("response contained an embedding for unexpected index %v", d.Index)
+		}
+		embeddings[d.Index] = d.Embedding
+	}
+
+	model := res.Model
+	if model == "" {
+		model = p.client.model
+	}
+
+	newBatch := make(service.MessageBatch, len(batch))
+	for i, msg := range batch {
+		if embeddings[i] == nil {
+			return nil, fmt.Errorf("response is missing an embedding for message %v", i)
+		}
+
+		arr := make([]any, len(embeddings[i]))
+		for j, f := range embeddings[i] {
+			arr[j] = f
+		}
+
+		newMsg := msg.Copy()
+		newMsg.SetStructuredMut(arr)
+		setUsageMetadata(newMsg, model, u)
+		newBatch[i] = newMsg
+	}
+	return []service.MessageBatch{newBatch}, nil
+}
+
+func (p *embeddingsProcessor) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,222 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func testServer(t testing.TB, handler func(path string, body map[string]any) (int, any)) (*httptest.Server, *[]map[string]any) {
+	t.Helper()
+
+	var mut sync.Mutex
+	var reqs []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer fookey", r.Header.Get("Authorization"))
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		mut.Lock()
+		reqs = append(reqs, body)
+		mut.Unlock()
+
+		status, res := handler(r.URL.Path, body)
+		w.WriteHeader(status)
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &reqs
+}
+
+func chatResponseBody(content string) map[string]any {
+	return map[string]any{
+		"model": "gpt-foo",
+		"choices": []any{
+			map[string]any{
+				"message":       map[string]any{"role": "assistant", "content": content},
+				"finish_reason": "stop",
+			},
+		},
+		"usage": map[string]any{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+	}
+}
+
+func TestChatCompletionProcessor(t *testing.T) {
+	ts, reqs := testServer(t, func(path string, body map[string]any) (int, any) {
+		assert.Equal(t, "/chat/completions", path)
+		return 200, chatResponseBody("hello world")
+	})
+
+	conf, err := chatCompletionProcessorConfig().ParseYAML(`
+server_address: `+ts.URL+`
+api_key: fookey
+model: gpt-foo
+system_prompt: 'be nice'
+prompt: 'say hello to ${! this.name }'
+max_tokens: 20
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newChatCompletionProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	batch, err := proc.Process(context.Background(), service.NewMessage([]byte(`{"name":"bob"}`)))
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+
+	mBytes, err := batch[0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(mBytes))
+
+	v, _ := batch[0].MetaGetMut("openai_total_tokens")
+	assert.Equal(t, int64(15), v)
+	v, _ = batch[0].MetaGetMut("openai_finish_reason")
+	assert.Equal(t, "stop", v)
+
+	require.Len(t, *reqs, 1)
+	assert.Equal(t, map[string]any{
+		"model":      "gpt-foo",
+		"max_tokens": 20.0,
+		"messages": []any{
+			map[string]any{"role": "system", "content": "be nice"},
+			map[string]any{"role": "user", "content": "say hello to bob"},
+		},
+	}, (*reqs)[0])
+}
+
+func TestChatCompletionProcessorSchema(t *testing.T) {
+	var content string
+	ts, reqs := testServer(t, func(path string, body map[string]any) (int, any) {
+		return 200, chatResponseBody(content)
+	})
+
+	conf, err := chatCompletionProcessorConfig().ParseYAML(`
+server_address: `+ts.URL+`
+api_key: fookey
+model: gpt-foo
+response_schema: '{"type":"object","properties":{"category":{"enum":["a","b"]}},"required":["category"]}'
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newChatCompletionProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	content = `{"category":"a"}`
+	batch, err := proc.Process(context.Background(), service.NewMessage([]byte(`classify this`)))
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+
+	v, err := batch[0].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"category": "a"}, v)
+
+	require.Len(t, *reqs, 1)
+	assert.Equal(t, map[string]any{"type": "json_object"}, (*reqs)[0]["response_format"])
+	assert.Equal(t, []any{
+		map[string]any{"role": "user", "content": "classify this"},
+	}, (*reqs)[0]["messages"])
+
+	content = `{"category":"c"}`
+	_, err = proc.Process(context.Background(), service.NewMessage([]byte(`classify this`)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema validation")
+
+	content = `not json`
+	_, err = proc.Process(context.Background(), service.NewMessage([]byte(`classify this`)))
+	require.Error(t, err)
+}
+
+func TestChatCompletionProcessorAPIError(t *testing.T) {
+	ts, _ := testServer(t, func(path string, body map[string]any) (int, any) {
+		return 429, map[string]any{"error": map[string]any{"message": "slow down", "type": "rate_limit"}}
+	})
+
+	conf, err := chatCompletionProcessorConfig().ParseYAML(`
+server_address: `+ts.URL+`
+api_key: fookey
+model: gpt-foo
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newChatCompletionProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	_, err = proc.Process(context.Background(), service.NewMessage([]byte(`hi`)))
+	require.EqualError(t, err, "request failed with status 429: slow down")
+}
+
+func TestEmbeddingsProcessor(t *testing.T) {
+	ts, reqs := testServer(t, func(path string, body map[string]any) (int, any) {
+		assert.Equal(t, "/embeddings", path)
+		return 200, map[string]any{
+			"model": "embed-foo",
+			"data": []any{
+				map[string]any{"index": 1, "embedding": []float64{0.3, 0.4}},
+				map[string]any{"index": 0, "embedding": []float64{0.1, 0.2}},
+			},
+			"usage": map[string]any{"prompt_tokens": 8, "total_tokens": 8},
+		}
+	})
+
+	conf, err := embeddingsProcessorConfig().ParseYAML(`
+server_address: `+ts.URL+`
+api_key: fookey
+model: embed-foo
+input: '${! this.text }'
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newEmbeddingsProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	batches, err := proc.ProcessBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"text":"foo"}`)),
+		service.NewMessage([]byte(`{"text":"bar"}`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 2)
+
+	v, err := batches[0][0].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, []any{0.1, 0.2}, v)
+
+	v, err = batches[0][1].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, []any{0.3, 0.4}, v)
+
+	require.Len(t, *reqs, 1)
+	assert.Equal(t, []any{"foo", "bar"}, (*reqs)[0]["input"])
+}
+
+func TestTokenLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newTokenLimiter(60, time.Minute)
+	l.now = func() time.Time { return now }
+	l.last = now
+
+	assert.Equal(t, time.Duration(0), l.delay())
+
+	l.consume(70)
+	assert.Equal(t, 11*time.Second, l.delay())
+
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, 6*time.Second, l.delay())
+
+	now = now.Add(6 * time.Second)
+	assert.Equal(t, time.Duration(0), l.delay())
+
+	now = now.Add(time.Hour)
+	l.consume(0)
+	assert.Equal(t, 60.0, l.available)
+}
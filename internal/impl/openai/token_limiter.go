@@ -0,0 +1,73 @@
+package openai
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenLimiter is a token bucket that is debited by the tokens consumed by each
+// request once it's known, and therefore the balance can become negative, in
+// which case requests wait until the bucket has been refilled.
+type tokenLimiter struct {
+	mut       sync.Mutex
+	capacity  float64
+	perSecond float64
+	available float64
+	last      time.Time
+
+	now func() time.Time
+}
+
+func newTokenLimiter(tokens int, per time.Duration) *tokenLimiter {
+	return &tokenLimiter{
+		capacity:  float64(tokens),
+		perSecond: float64(tokens) / per.Seconds(),
+		available: float64(tokens),
+		last:      time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (l *tokenLimiter) refillLocked() {
+	now := l.now()
+	l.available += now.Sub(l.last).Seconds() * l.perSecond
+	if l.available > l.capacity {
+		l.available = l.capacity
+	}
+	l.last = now
+}
+
+// delay returns the period to wait until tokens are available.
+func (l *tokenLimiter) delay() time.Duration {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	l.refillLocked()
+	if l.available > 0 {
+		return 0
+	}
+	return time.Duration((1 - l.available) / l.perSecond * float64(time.Second))
+}
+
+func (l *tokenLimiter) wait(ctx context.Context) error {
+	for {
+		d := l.delay()
+		if d <= 0 {
+			return nil
+		}
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *tokenLimiter) consume(tokens int64) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	l.refillLocked()
+	l.available -= float64(tokens)
+}
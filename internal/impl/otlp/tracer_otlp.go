@@ -15,6 +15,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/benthosdev/benthos/v4/internal/cli"
+	"github.com/benthosdev/benthos/v4/internal/component/tracer"
 	"github.com/benthosdev/benthos/v4/public/service"
 )
 
@@ -40,7 +41,8 @@ func init() {
 		Field(service.NewStringMapField("tags").
 			Description("A map of tags to add to all tracing spans.").
 			Default(map[string]string{}).
-			Advanced())
+			Advanced()).
+		Field(service.NewInternalField(tracer.TailSamplingFieldSpec()))
 
 	err := service.RegisterOtelTracerProvider(
 		"open_telemetry_collector",
@@ -63,9 +65,10 @@ type collector struct {
 }
 
 type otlp struct {
-	grpc []collector
-	http []collector
-	tags map[string]string
+	grpc         []collector
+	http         []collector
+	tags         map[string]string
+	tailSampling tracer.TailSamplingConfig
 }
 
 func newOtlpConfig(conf *service.ParsedConfig) (*otlp, error) {
@@ -84,13 +87,30 @@ func newOtlpConfig(conf *service.ParsedConfig) (*otlp, error) {
 		return nil, err
 	}
 
+	tailSampling, err := tailSamplingConfig(conf.Namespace("tail_sampling"))
+	if err != nil {
+		return nil, err
+	}
+
 	return &otlp{
 		grpc,
 		http,
 		tags,
+		tailSampling,
 	}, nil
 }
 
+func tailSamplingConfig(conf *service.ParsedConfig) (c tracer.TailSamplingConfig, err error) {
+	if c.Enabled, err = conf.FieldBool("enabled"); err != nil {
+		return
+	}
+	if c.LatencyBudget, err = conf.FieldString("latency_budget"); err != nil {
+		return
+	}
+	c.MaxPendingTraces, err = conf.FieldInt("max_pending_traces")
+	return
+}
+
 func collectors(conf *service.ParsedConfig, name string) ([]collector, error) {
 	list, err := conf.FieldObjectList(name)
 	if err != nil {
@@ -122,12 +142,12 @@ func newOtlp(config *otlp) (trace.TracerProvider, error) {
 	ctx := context.TODO()
 	var opts []tracesdk.TracerProviderOption
 
-	opts, err := addGrpcCollectors(ctx, config.grpc, opts)
+	opts, err := addGrpcCollectors(ctx, config.grpc, config.tailSampling, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	opts, err = addHTTPCollectors(ctx, config.http, opts)
+	opts, err = addHTTPCollectors(ctx, config.http, config.tailSampling, opts)
 
 	if err != nil {
 		return nil, err
@@ -153,7 +173,7 @@ func newOtlp(config *otlp) (trace.TracerProvider, error) {
 	return tracesdk.NewTracerProvider(opts...), nil
 }
 
-func addGrpcCollectors(ctx context.Context, collectors []collector, opts []tracesdk.TracerProviderOption) ([]tracesdk.TracerProviderOption, error) {
+func addGrpcCollectors(ctx context.Context, collectors []collector, tailSampling tracer.TailSamplingConfig, opts []tracesdk.TracerProviderOption) ([]tracesdk.TracerProviderOption, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
 	defer cancel()
 
@@ -170,12 +190,16 @@ func addGrpcCollectors(ctx context.Context, collectors []collector, opts []trace
 		if err != nil {
 			return nil, err
 		}
-		opts = append(opts, tracesdk.WithBatcher(exp))
+		spanProc, err := tailSampling.SpanProcessor(tracesdk.NewBatchSpanProcessor(exp))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, tracesdk.WithSpanProcessor(spanProc))
 	}
 	return opts, nil
 }
 
-func addHTTPCollectors(ctx context.Context, collectors []collector, opts []tracesdk.TracerProviderOption) ([]tracesdk.TracerProviderOption, error) {
+func addHTTPCollectors(ctx context.Context, collectors []collector, tailSampling tracer.TailSamplingConfig, opts []tracesdk.TracerProviderOption) ([]tracesdk.TracerProviderOption, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
 	defer cancel()
 
@@ -191,7 +215,11 @@ func addHTTPCollectors(ctx context.Context, collectors []collector, opts []trace
 		if err != nil {
 			return nil, err
 		}
-		opts = append(opts, tracesdk.WithBatcher(exp))
+		spanProc, err := tailSampling.SpanProcessor(tracesdk.NewBatchSpanProcessor(exp))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, tracesdk.WithSpanProcessor(spanProc))
 	}
 	return opts, nil
 }
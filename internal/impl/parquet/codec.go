@@ -0,0 +1,132 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/segmentio/parquet-go"
+
+	"github.com/benthosdev/benthos/v4/internal/codec"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func init() {
+	codec.RegisterPartReader("parquet", newParquetCodecReader)
+}
+
+// parquetCodecReader is an input codec that consumes the rows of a parquet
+// file. The parquet format requires random access and therefore the entire
+// file is read into memory before rows are extracted.
+type parquetCodecReader struct {
+	r         io.ReadCloser
+	rdr       *parquet.GenericReader[any]
+	schema    *parquet.Schema
+	eConf     extractConfig
+	sourceAck codec.ReaderAckFn
+
+	rowBuf []parquet.Row
+
+	mut      sync.Mutex
+	finished bool
+	pending  int32
+}
+
+func newParquetCodecReader(path string, r io.ReadCloser, ackFn codec.ReaderAckFn) (codec.Reader, error) {
+	// The source is closed and rejected when it cannot be read, as no reader
+	// exists from which it would otherwise be resolved.
+	fail := func(err error) (codec.Reader, error) {
+		_ = r.Close()
+		_ = ackFn(context.Background(), err)
+		return nil, err
+	}
+
+	fileBytes, err := io.ReadAll(r)
+	if err != nil {
+		return fail(err)
+	}
+
+	inFile, err := parquet.OpenFile(bytes.NewReader(fileBytes), int64(len(fileBytes)))
+	if err != nil {
+		return fail(err)
+	}
+
+	rdr := parquet.NewGenericReader[any](inFile)
+
+	var once sync.Once
+	return &parquetCodecReader{
+		r:      r,
+		rdr:    rdr,
+		schema: rdr.Schema(),
+		// Byte arrays are extracted as strings in order for rows to serialise
+		// as JSON intuitively.
+		eConf: extractConfig{byteArrayAsStrings: true},
+		sourceAck: func(ctx context.Context, err error) (ackErr error) {
+			once.Do(func() {
+				ackErr = ackFn(ctx, err)
+			})
+			return
+		},
+		rowBuf: make([]parquet.Row, 1),
+	}, nil
+}
+
+func (p *parquetCodecReader) ack(ctx context.Context, err error) error {
+	p.mut.Lock()
+	p.pending--
+	doAck := p.pending == 0 && p.finished
+	p.mut.Unlock()
+
+	if err != nil {
+		return p.sourceAck(ctx, err)
+	}
+	if doAck {
+		return p.sourceAck(ctx, nil)
+	}
+	return nil
+}
+
+func (p *parquetCodecReader) Next(ctx context.Context) ([]*message.Part, codec.ReaderAckFn, error) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if p.finished {
+		return nil, nil, io.EOF
+	}
+
+	n, err := p.rdr.ReadRows(p.rowBuf)
+	if n == 0 {
+		if err == nil || errors.Is(err, io.EOF) {
+			p.finished = true
+			return nil, nil, io.EOF
+		}
+		_ = p.sourceAck(ctx, err)
+		return nil, nil, err
+	}
+
+	mappedData := map[string]any{}
+	_, _ = p.eConf.extractPQValueGroup(p.schema.Fields(), p.rowBuf[0], mappedData, 0, 0)
+
+	part := message.NewPart(nil)
+	part.SetStructuredMut(mappedData)
+	part.MetaSetMut("parquet_schema", p.schema.String())
+
+	p.pending++
+	return []*message.Part{part}, p.ack, nil
+}
+
+func (p *parquetCodecReader) Close(ctx context.Context) error {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if !p.finished {
+		_ = p.sourceAck(ctx, errors.New("service shutting down"))
+	}
+	if p.pending == 0 {
+		_ = p.sourceAck(ctx, nil)
+	}
+	_ = p.rdr.Close()
+	return p.r.Close()
+}
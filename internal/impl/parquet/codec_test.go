@@ -0,0 +1,115 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/segmentio/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/codec"
+)
+
+type nopReadCloser struct {
+	io.Reader
+}
+
+func (n nopReadCloser) Close() error {
+	return nil
+}
+
+func TestParquetCodec(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+
+	pWtr := parquet.NewWriter(buf, parquet.SchemaOf(simpleData{}))
+	for _, r := range []simpleData{
+		{ID: 1, Value: "foo 1"},
+		{ID: 2, Value: "foo 2"},
+		{ID: 3, Value: "foo 3"},
+	} {
+		require.NoError(t, pWtr.Write(r))
+	}
+	require.NoError(t, pWtr.Close())
+
+	var gzipBuf bytes.Buffer
+	zw := gzip.NewWriter(&gzipBuf)
+	_, err := zw.Write(buf.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	for _, test := range []struct {
+		codec string
+		path  string
+		data  []byte
+	}{
+		{codec: "parquet", path: "foo.parquet", data: buf.Bytes()},
+		{codec: "gzip/parquet", path: "foo.parquet.gz", data: gzipBuf.Bytes()},
+		{codec: "auto", path: "foo.parquet", data: buf.Bytes()},
+		{codec: "auto", path: "foo", data: buf.Bytes()},
+	} {
+		test := test
+		t.Run(test.codec+" "+test.path, func(t *testing.T) {
+			ctor, err := codec.GetReader(test.codec, codec.NewReaderConfig())
+			require.NoError(t, err)
+
+			var ackErr error
+			acked := false
+			rdr, err := ctor(test.path, nopReadCloser{bytes.NewReader(test.data)}, func(ctx context.Context, err error) error {
+				acked, ackErr = true, err
+				return nil
+			})
+			require.NoError(t, err)
+
+			var docs []string
+			for {
+				parts, ackFn, err := rdr.Next(context.Background())
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				require.Len(t, parts, 1)
+
+				v, err := parts[0].AsStructured()
+				require.NoError(t, err)
+				docs = append(docs, v.(map[string]any)["Value"].(string))
+
+				schema, _ := parts[0].MetaGetMut("parquet_schema")
+				assert.Contains(t, schema, "Value")
+
+				require.NoError(t, ackFn(context.Background(), nil))
+			}
+			assert.Equal(t, []string{"foo 1", "foo 2", "foo 3"}, docs)
+
+			require.NoError(t, rdr.Close(context.Background()))
+			assert.True(t, acked)
+			assert.NoError(t, ackErr)
+		})
+	}
+}
+
+type closeTrackingReader struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTrackingReader) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestParquetCodecInvalidFile(t *testing.T) {
+	src := &closeTrackingReader{Reader: bytes.NewReader([]byte("not a parquet file"))}
+
+	var ackErr error
+	_, err := newParquetCodecReader("foo.parquet", src, func(ctx context.Context, err error) error {
+		ackErr = err
+		return nil
+	})
+	require.Error(t, err)
+	assert.True(t, src.closed)
+	assert.Equal(t, err, ackErr)
+}
@@ -0,0 +1,649 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/segmentio/parquet-go"
+)
+
+// icebergTableMetadata is the subset of the metadata of an Iceberg table that
+// is required in order to append data files to it.
+type icebergTableMetadata struct {
+	FormatVersion      int                    `json:"format-version"`
+	TableUUID          string                 `json:"table-uuid"`
+	Location           string                 `json:"location"`
+	LastSequenceNumber int64                  `json:"last-sequence-number"`
+	CurrentSchemaID    int                    `json:"current-schema-id"`
+	Schemas            []icebergSchema        `json:"schemas"`
+	Schema             *icebergSchema         `json:"schema,omitempty"`
+	DefaultSpecID      int                    `json:"default-spec-id"`
+	PartitionSpecs     []icebergPartitionSpec `json:"partition-specs"`
+	PartitionSpec      []any                  `json:"partition-spec,omitempty"`
+	Properties         map[string]string      `json:"properties"`
+	CurrentSnapshotID  *int64                 `json:"current-snapshot-id"`
+	Snapshots          []icebergSnapshot      `json:"snapshots"`
+}
+
+type icebergSchema struct {
+	Type     string         `json:"type"`
+	SchemaID int            `json:"schema-id"`
+	Fields   []icebergField `json:"fields"`
+}
+
+type icebergField struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	Type     any    `json:"type"`
+}
+
+type icebergPartitionSpec struct {
+	SpecID int   `json:"spec-id"`
+	Fields []any `json:"fields"`
+}
+
+type icebergSnapshot struct {
+	SnapshotID       int64             `json:"snapshot-id"`
+	ParentSnapshotID *int64            `json:"parent-snapshot-id,omitempty"`
+	SequenceNumber   int64             `json:"sequence-number,omitempty"`
+	TimestampMs      int64             `json:"timestamp-ms"`
+	ManifestList     string            `json:"manifest-list"`
+	Summary          map[string]string `json:"summary"`
+	SchemaID         *int              `json:"schema-id,omitempty"`
+}
+
+// currentSchema returns the current schema of the table, including tables of
+// format version 1 that only specify a single schema.
+func (m *icebergTableMetadata) currentSchema() (*icebergSchema, error) {
+	for i, s := range m.Schemas {
+		if s.SchemaID == m.CurrentSchemaID {
+			return &m.Schemas[i], nil
+		}
+	}
+	if m.Schema != nil {
+		return m.Schema, nil
+	}
+	return nil, fmt.Errorf("table metadata does not contain current schema %v", m.CurrentSchemaID)
+}
+
+// isPartitioned returns whether the default partition spec of the table has
+// any fields.
+func (m *icebergTableMetadata) isPartitioned() bool {
+	for _, s := range m.PartitionSpecs {
+		if s.SpecID == m.DefaultSpecID {
+			return len(s.Fields) > 0
+		}
+	}
+	return len(m.PartitionSpec) > 0
+}
+
+// currentSnapshot returns the current snapshot of the table, or nil if the
+// table has no snapshots.
+func (m *icebergTableMetadata) currentSnapshot() *icebergSnapshot {
+	if m.CurrentSnapshotID == nil || *m.CurrentSnapshotID == -1 {
+		return nil
+	}
+	for i, s := range m.Snapshots {
+		if s.SnapshotID == *m.CurrentSnapshotID {
+			return &m.Snapshots[i]
+		}
+	}
+	return nil
+}
+
+// icebergNameMapping returns a name mapping for the fields of a schema, which
+// allows readers to resolve the columns of data files that were written
+// without field IDs.
+func icebergNameMapping(fields []icebergField) []map[string]any {
+	mapping := make([]map[string]any, 0, len(fields))
+	for _, f := range fields {
+		m := map[string]any{
+			"field-id": f.ID,
+			"names":    []string{f.Name},
+		}
+		if t, ok := f.Type.(map[string]any); ok && t["type"] == "struct" {
+			var nested []icebergField
+			if b, err := json.Marshal(t["fields"]); err == nil && json.Unmarshal(b, &nested) == nil {
+				m["fields"] = icebergNameMapping(nested)
+			}
+		}
+		mapping = append(mapping, m)
+	}
+	return mapping
+}
+
+// icebergPrimitiveType returns the Iceberg type of a parquet leaf column.
+func icebergPrimitiveType(t parquet.Type) string {
+	if lt := t.LogicalType(); lt != nil && lt.UTF8 != nil {
+		return "string"
+	}
+	switch t.Kind() {
+	case parquet.Boolean:
+		return "boolean"
+	case parquet.Int32:
+		return "int"
+	case parquet.Int64:
+		return "long"
+	case parquet.Float:
+		return "float"
+	case parquet.Double:
+		return "double"
+	}
+	return "binary"
+}
+
+// icebergSchemaFromParquet converts flat parquet fields into an Iceberg schema
+// with field IDs assigned in order.
+func icebergSchemaFromParquet(fields []parquet.Field) (*icebergSchema, error) {
+	s := &icebergSchema{Type: "struct"}
+	for i, f := range fields {
+		if !f.Leaf() || f.Repeated() {
+			return nil, fmt.Errorf("field %v is not supported, only fields of a primitive type can be written to iceberg tables", f.Name())
+		}
+		s.Fields = append(s.Fields, icebergField{
+			ID:       i + 1,
+			Name:     f.Name(),
+			Required: !f.Optional(),
+			Type:     icebergPrimitiveType(f.Type()),
+		})
+	}
+	return s, nil
+}
+
+// checkIcebergSchema returns an error if rows of a parquet schema cannot be
+// appended to a table with an Iceberg schema.
+func checkIcebergSchema(fields []parquet.Field, schema *icebergSchema) error {
+	written := map[string]parquet.Field{}
+	for _, f := range fields {
+		written[f.Name()] = f
+	}
+
+	tableFields := map[string]icebergField{}
+	for _, tf := range schema.Fields {
+		tableFields[tf.Name] = tf
+
+		f, exists := written[tf.Name]
+		if !exists {
+			if tf.Required {
+				return fmt.Errorf("required table column %v is missing from the schema", tf.Name)
+			}
+			continue
+		}
+		if tf.Required && f.Optional() {
+			return fmt.Errorf("required table column %v must not be optional", tf.Name)
+		}
+	}
+
+	for _, f := range fields {
+		tf, exists := tableFields[f.Name()]
+		if !exists {
+			return fmt.Errorf("column %v does not exist in the table", f.Name())
+		}
+		if !f.Leaf() || f.Repeated() {
+			return fmt.Errorf("field %v is not supported, only fields of a primitive type can be written to iceberg tables", f.Name())
+		}
+		if tType, _ := tf.Type.(string); tType != icebergPrimitiveType(f.Type()) {
+			return fmt.Errorf("column %v has type %v in the table, which does not match type %v", f.Name(), tf.Type, icebergPrimitiveType(f.Type()))
+		}
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// icebergManifestFile is an entry of a manifest list, which describes a
+// manifest file of a snapshot. Partition summaries are not carried over from
+// the manifests of previous snapshots as they are optional.
+type icebergManifestFile struct {
+	Path              string
+	Length            int64
+	SpecID            int32
+	Content           int32
+	SequenceNumber    int64
+	MinSequenceNumber int64
+	AddedSnapshotID   int64
+	AddedFiles        int32
+	ExistingFiles     int32
+	DeletedFiles      int32
+	AddedRows         int64
+	ExistingRows      int64
+	DeletedRows       int64
+	KeyMetadata       []byte
+}
+
+func avroField(name string, t any, id int) map[string]any {
+	return map[string]any{"name": name, "type": t, "field-id": id}
+}
+
+func avroOptionalField(name string, t any, id int) map[string]any {
+	return map[string]any{"name": name, "type": []any{"null", t}, "default": nil, "field-id": id}
+}
+
+// icebergManifestEntrySchema returns the Avro schema of the entries of a
+// manifest file of an unpartitioned table.
+func icebergManifestEntrySchema(formatVersion int) string {
+	dataFileFields := []any{
+		avroField("file_path", "string", 100),
+		avroField("file_format", "string", 101),
+		avroField("partition", map[string]any{"type": "record", "name": "r102", "fields": []any{}}, 102),
+		avroField("record_count", "long", 103),
+		avroField("file_size_in_bytes", "long", 104),
+	}
+	fields := []any{avroField("status", "int", 0)}
+	if formatVersion == 1 {
+		dataFileFields = append(dataFileFields, avroField("block_size_in_bytes", "long", 105))
+		fields = append(fields, avroField("snapshot_id", "long", 1))
+	} else {
+		dataFileFields = append([]any{avroField("content", "int", 134)}, dataFileFields...)
+		fields = append(fields,
+			avroOptionalField("snapshot_id", "long", 1),
+			avroOptionalField("sequence_number", "long", 3),
+			avroOptionalField("file_sequence_number", "long", 4),
+		)
+	}
+	fields = append(fields, avroField("data_file", map[string]any{
+		"type": "record", "name": "r2", "fields": dataFileFields,
+	}, 2))
+
+	b, _ := json.Marshal(map[string]any{"type": "record", "name": "manifest_entry", "fields": fields})
+	return string(b)
+}
+
+// icebergManifestListSchema returns the Avro schema of a manifest list.
+func icebergManifestListSchema(formatVersion int) string {
+	fieldSummary := map[string]any{
+		"type": "record",
+		"name": "r508",
+		"fields": []any{
+			avroField("contains_null", "boolean", 509),
+			avroOptionalField("contains_nan", "boolean", 518),
+			avroOptionalField("lower_bound", "bytes", 510),
+			avroOptionalField("upper_bound", "bytes", 511),
+		},
+	}
+	partitions := map[string]any{"type": "array", "items": fieldSummary, "element-id": 508}
+
+	var fields []any
+	if formatVersion == 1 {
+		fields = []any{
+			avroField("manifest_path", "string", 500),
+			avroField("manifest_length", "long", 501),
+			avroField("partition_spec_id", "int", 502),
+			avroOptionalField("added_snapshot_id", "long", 503),
+			avroOptionalField("added_data_files_count", "int", 504),
+			avroOptionalField("existing_data_files_count", "int", 505),
+			avroOptionalField("deleted_data_files_count", "int", 506),
+			avroOptionalField("partitions", partitions, 507),
+			avroOptionalField("added_rows_count", "long", 512),
+			avroOptionalField("existing_rows_count", "long", 513),
+			avroOptionalField("deleted_rows_count", "long", 514),
+		}
+	} else {
+		fields = []any{
+			avroField("manifest_path", "string", 500),
+			avroField("manifest_length", "long", 501),
+			avroField("partition_spec_id", "int", 502),
+			avroField("content", "int", 517),
+			avroField("sequence_number", "long", 515),
+			avroField("min_sequence_number", "long", 516),
+			avroField("added_snapshot_id", "long", 503),
+			avroField("added_files_count", "int", 504),
+			avroField("existing_files_count", "int", 505),
+			avroField("deleted_files_count", "int", 506),
+			avroField("added_rows_count", "long", 512),
+			avroField("existing_rows_count", "long", 513),
+			avroField("deleted_rows_count", "long", 514),
+			avroOptionalField("partitions", partitions, 507),
+			avroOptionalField("key_metadata", "bytes", 519),
+		}
+	}
+
+	b, _ := json.Marshal(map[string]any{"type": "record", "name": "manifest_file", "fields": fields})
+	return string(b)
+}
+
+// encodeIcebergManifest encodes a manifest file containing a single data file
+// added by a snapshot. Sequence numbers are inherited from the manifest list.
+func encodeIcebergManifest(formatVersion int, schema *icebergSchema, specID int, snapshotID int64, dataPath string, records, size int64) ([]byte, error) {
+	codec, err := goavro.NewCodec(icebergManifestEntrySchema(formatVersion))
+	if err != nil {
+		return nil, err
+	}
+
+	schemaBytes, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	meta := map[string][]byte{
+		"schema":            schemaBytes,
+		"schema-id":         []byte(strconv.Itoa(schema.SchemaID)),
+		"partition-spec":    []byte("[]"),
+		"partition-spec-id": []byte(strconv.Itoa(specID)),
+		"format-version":    []byte(strconv.Itoa(formatVersion)),
+	}
+
+	dataFile := map[string]any{
+		"file_path":          dataPath,
+		"file_format":        "PARQUET",
+		"partition":          map[string]any{},
+		"record_count":       records,
+		"file_size_in_bytes": size,
+	}
+	entry := map[string]any{"status": int32(1)}
+	if formatVersion == 1 {
+		dataFile["block_size_in_bytes"] = int64(64 * 1024 * 1024)
+		entry["snapshot_id"] = snapshotID
+	} else {
+		meta["content"] = []byte("data")
+		dataFile["content"] = int32(0)
+		entry["snapshot_id"] = goavro.Union("long", snapshotID)
+		entry["sequence_number"] = nil
+		entry["file_sequence_number"] = nil
+	}
+	entry["data_file"] = dataFile
+
+	var buf bytes.Buffer
+	w, err := goavro.NewOCFWriter(goavro.OCFConfig{W: &buf, Codec: codec, MetaData: meta})
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Append([]any{entry}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeIcebergManifestList encodes the manifest list of a snapshot.
+func encodeIcebergManifestList(formatVersion int, snapshot *icebergSnapshot, manifests []icebergManifestFile) ([]byte, error) {
+	codec, err := goavro.NewCodec(icebergManifestListSchema(formatVersion))
+	if err != nil {
+		return nil, err
+	}
+
+	meta := map[string][]byte{
+		"snapshot-id":        []byte(strconv.FormatInt(snapshot.SnapshotID, 10)),
+		"parent-snapshot-id": []byte("null"),
+		"format-version":     []byte(strconv.Itoa(formatVersion)),
+	}
+	if snapshot.ParentSnapshotID != nil {
+		meta["parent-snapshot-id"] = []byte(strconv.FormatInt(*snapshot.ParentSnapshotID, 10))
+	}
+	if formatVersion > 1 {
+		meta["sequence-number"] = []byte(strconv.FormatInt(snapshot.SequenceNumber, 10))
+	}
+
+	records := make([]any, 0, len(manifests))
+	for _, m := range manifests {
+		r := map[string]any{
+			"manifest_path":     m.Path,
+			"manifest_length":   m.Length,
+			"partition_spec_id": m.SpecID,
+			"partitions":        nil,
+		}
+		if formatVersion == 1 {
+			r["added_snapshot_id"] = goavro.Union("long", m.AddedSnapshotID)
+			r["added_data_files_count"] = goavro.Union("int", m.AddedFiles)
+			r["existing_data_files_count"] = goavro.Union("int", m.ExistingFiles)
+			r["deleted_data_files_count"] = goavro.Union("int", m.DeletedFiles)
+			r["added_rows_count"] = goavro.Union("long", m.AddedRows)
+			r["existing_rows_count"] = goavro.Union("long", m.ExistingRows)
+			r["deleted_rows_count"] = goavro.Union("long", m.DeletedRows)
+		} else {
+			r["content"] = m.Content
+			r["sequence_number"] = m.SequenceNumber
+			r["min_sequence_number"] = m.MinSequenceNumber
+			r["added_snapshot_id"] = m.AddedSnapshotID
+			r["added_files_count"] = m.AddedFiles
+			r["existing_files_count"] = m.ExistingFiles
+			r["deleted_files_count"] = m.DeletedFiles
+			r["added_rows_count"] = m.AddedRows
+			r["existing_rows_count"] = m.ExistingRows
+			r["deleted_rows_count"] = m.DeletedRows
+			r["key_metadata"] = nil
+			if m.KeyMetadata != nil {
+				r["key_metadata"] = goavro.Union("bytes", m.KeyMetadata)
+			}
+		}
+		records = append(records, r)
+	}
+
+	var buf bytes.Buffer
+	w, err := goavro.NewOCFWriter(goavro.OCFConfig{W: &buf, Codec: codec, MetaData: meta})
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Append(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// avroUnwrap returns the value of an Avro union, or the value itself when it
+// is not a union.
+func avroUnwrap(v any) any {
+	if m, ok := v.(map[string]any); ok && len(m) == 1 {
+		for _, inner := range m {
+			return inner
+		}
+	}
+	return v
+}
+
+func avroInt64(r map[string]any, names ...string) int64 {
+	for _, n := range names {
+		switch v := avroUnwrap(r[n]).(type) {
+		case int64:
+			return v
+		case int32:
+			return int64(v)
+		}
+	}
+	return 0
+}
+
+// decodeIcebergManifestList decodes the manifest files of a manifest list of
+// any format version.
+func decodeIcebergManifestList(data []byte) ([]icebergManifestFile, error) {
+	r, err := goavro.NewOCFReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []icebergManifestFile
+	for r.Scan() {
+		v, err := r.Read()
+		if err != nil {
+			return nil, err
+		}
+		rec, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unexpected manifest list entry type %T", v)
+		}
+
+		m := icebergManifestFile{
+			Length:            avroInt64(rec, "manifest_length"),
+			SpecID:            int32(avroInt64(rec, "partition_spec_id")),
+			Content:           int32(avroInt64(rec, "content")),
+			SequenceNumber:    avroInt64(rec, "sequence_number"),
+			MinSequenceNumber: avroInt64(rec, "min_sequence_number"),
+			AddedSnapshotID:   avroInt64(rec, "added_snapshot_id"),
+			AddedFiles:        int32(avroInt64(rec, "added_files_count", "added_data_files_count")),
+			ExistingFiles:     int32(avroInt64(rec, "existing_files_count", "existing_data_files_count")),
+			DeletedFiles:      int32(avroInt64(rec, "deleted_files_count", "deleted_data_files_count")),
+			AddedRows:         avroInt64(rec, "added_rows_count"),
+			ExistingRows:      avroInt64(rec, "existing_rows_count"),
+			DeletedRows:       avroInt64(rec, "deleted_rows_count"),
+		}
+		m.Path, _ = avroUnwrap(rec["manifest_path"]).(string)
+		m.KeyMetadata, _ = avroUnwrap(rec["key_metadata"]).([]byte)
+		manifests = append(manifests, m)
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	return manifests, nil
+}
+
+//------------------------------------------------------------------------------
+
+// errIcebergCommitConflict is returned when a commit is rejected because the
+// table has been modified since it was loaded.
+var errIcebergCommitConflict = errors.New("commit conflicts with a concurrent change to the table")
+
+// errIcebergCommitUnknown is returned when the outcome of a commit cannot be
+// determined, in which case the files it references must not be removed.
+var errIcebergCommitUnknown = errors.New("commit state unknown")
+
+// icebergCatalog is a client of the Iceberg REST catalog API.
+type icebergCatalog struct {
+	baseURL   string
+	warehouse string
+	token     string
+	client    *http.Client
+
+	prefix string
+}
+
+type icebergLoadTableResult struct {
+	MetadataLocation string               `json:"metadata-location"`
+	Metadata         icebergTableMetadata `json:"metadata"`
+}
+
+type icebergErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+func (c *icebergCatalog) do(ctx context.Context, method, path string, body, res any) (int, error) {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.baseURL, "/")+path, reqBody)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	resBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var errRes icebergErrorResponse
+		if jerr := json.Unmarshal(resBytes, &errRes); jerr == nil && errRes.Error.Message != "" {
+			return resp.StatusCode, fmt.Errorf("%v: %v", errRes.Error.Type, errRes.Error.Message)
+		}
+		return resp.StatusCode, fmt.Errorf("unexpected status code: %v", resp.StatusCode)
+	}
+	if res != nil {
+		if err := json.Unmarshal(resBytes, res); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// loadConfig obtains the prefix of the catalog from its config endpoint.
+func (c *icebergCatalog) loadConfig(ctx context.Context) error {
+	path := "/v1/config"
+	if c.warehouse != "" {
+		path += "?warehouse=" + url.QueryEscape(c.warehouse)
+	}
+	var res struct {
+		Defaults  map[string]string `json:"defaults"`
+		Overrides map[string]string `json:"overrides"`
+	}
+	if _, err := c.do(ctx, http.MethodGet, path, nil, &res); err != nil {
+		return fmt.Errorf("failed to load catalog config: %w", err)
+	}
+	c.prefix = res.Defaults["prefix"]
+	if p, exists := res.Overrides["prefix"]; exists {
+		c.prefix = p
+	}
+	return nil
+}
+
+func (c *icebergCatalog) namespacePath(namespace []string) string {
+	path := "/v1"
+	if c.prefix != "" {
+		path += "/" + url.PathEscape(c.prefix)
+	}
+	return path + "/namespaces/" + url.PathEscape(strings.Join(namespace, "\x1f"))
+}
+
+func (c *icebergCatalog) tablePath(namespace []string, table string) string {
+	return c.namespacePath(namespace) + "/tables/" + url.PathEscape(table)
+}
+
+// loadTable returns the metadata of a table, or nil if it does not exist.
+func (c *icebergCatalog) loadTable(ctx context.Context, namespace []string, table string) (*icebergTableMetadata, error) {
+	var res icebergLoadTableResult
+	code, err := c.do(ctx, http.MethodGet, c.tablePath(namespace, table), nil, &res)
+	if code == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load table: %w", err)
+	}
+	return &res.Metadata, nil
+}
+
+func (c *icebergCatalog) createTable(ctx context.Context, namespace []string, table string, schema *icebergSchema) (*icebergTableMetadata, error) {
+	var res icebergLoadTableResult
+	if _, err := c.do(ctx, http.MethodPost, c.namespacePath(namespace)+"/tables", map[string]any{
+		"name":   table,
+		"schema": schema,
+	}, &res); err != nil {
+		return nil, fmt.Errorf("failed to create table: %w", err)
+	}
+	return &res.Metadata, nil
+}
+
+// commitTable applies updates to a table provided that its requirements are
+// met.
+func (c *icebergCatalog) commitTable(ctx context.Context, namespace []string, table string, requirements, updates []map[string]any) error {
+	code, err := c.do(ctx, http.MethodPost, c.tablePath(namespace, table), map[string]any{
+		"requirements": requirements,
+		"updates":      updates,
+	}, nil)
+	if err == nil {
+		return nil
+	}
+	switch {
+	case code == http.StatusConflict:
+		return fmt.Errorf("%w: %v", errIcebergCommitConflict, err)
+	case code == 0 || code >= 500:
+		return fmt.Errorf("%w: %v", errIcebergCommitUnknown, err)
+	}
+	return err
+}
@@ -0,0 +1,449 @@
+package parquet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/compress"
+
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	dlFieldPath               = "path"
+	dlFieldDefaultCompression = "default_compression"
+	dlFieldMaxInFlight        = "max_in_flight"
+	dlFieldBatching           = "batching"
+
+	deltaLogDir           = "_delta_log"
+	deltaMaxCommitRetries = 100
+)
+
+func deltaLakeOutputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Categories("Local").
+		Summary("Writes batches of structured messages as Parquet files to a [Delta Lake](https://delta.io/) table and commits them to the table transaction log.").
+		Description(`
+Each batch of messages is encoded as a single Parquet file with the configured schema and written within the directory of the table. Once the file has been written a new commit is added to the `+"`_delta_log`"+` directory of the table, which makes the file visible to readers. Messages are only acknowledged once the commit has succeeded.
+
+If the table does not yet exist it is created on the first commit with a schema derived from the `+"`schema`"+` field. Partitioned tables are not currently supported, and tables that were created with a different schema should not be written to.
+
+Commits are written with exclusive file creation, and therefore multiple writers can safely commit to the same table on a local or network filesystem that honours exclusive creation. Object stores are not supported as they do not offer this guarantee.
+
+In order to write to Apache Iceberg tables use the [`+"`iceberg`"+` output](/docs/components/outputs/iceberg) instead.
+
+### Performance
+
+Each batch results in a data file and a commit, and therefore it is recommended to configure a [batching policy](/docs/configuration/batching) that produces large batches in order to avoid creating many small files.`).
+		Field(service.NewStringField(dlFieldPath).
+			Description("The path of the root directory of the table.").
+			Example("/data/tables/events")).
+		Field(parquetSchemaConfig()).
+		Field(service.NewStringEnumField(dlFieldDefaultCompression,
+			"uncompressed", "snappy", "gzip", "brotli", "zstd", "lz4raw",
+		).
+			Description("The default compression type to use for fields.").
+			Default("snappy")).
+		Field(service.NewIntField(dlFieldMaxInFlight).
+			Description("The maximum number of batches to have in flight at a given time. Increase this to improve throughput.").
+			Default(1)).
+		Field(service.NewBatchPolicyField(dlFieldBatching)).
+		Version("4.24.0").
+		Example("Writing Events to a Table",
+			"In this example we consume JSON events from Kafka and write them to a Delta Lake table in batches of at most ten thousand events, or whatever has accumulated after one minute.",
+			`
+input:
+  kafka:
+    addresses: [ TODO ]
+    topics: [ events ]
+    consumer_group: benthos_events
+
+output:
+  delta_lake:
+    path: /data/tables/events
+    schema:
+      - name: id
+        type: UTF8
+      - name: timestamp
+        type: INT64
+      - name: content
+        type: UTF8
+        optional: true
+    batching:
+      count: 10000
+      period: 1m
+`)
+}
+
+func init() {
+	err := service.RegisterBatchOutput(
+		"delta_lake", deltaLakeOutputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPol service.BatchPolicy, maxInFlight int, err error) {
+			if maxInFlight, err = conf.FieldInt(dlFieldMaxInFlight); err != nil {
+				return
+			}
+			if batchPol, err = conf.FieldBatchPolicy(dlFieldBatching); err != nil {
+				return
+			}
+			out, err = newDeltaLakeOutputFromConfig(conf, mgr)
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type deltaLakeOutput struct {
+	path    string
+	encoder *parquetEncodeProcessor
+	fs      *service.FS
+	log     *service.Logger
+
+	// Guards the creation of the table by this writer, commits themselves are
+	// made safe by exclusive file creation.
+	createMut sync.Mutex
+	tableID   string
+}
+
+func newDeltaLakeOutputFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*deltaLakeOutput, error) {
+	path, err := conf.FieldString(dlFieldPath)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, errors.New("a table path must be specified")
+	}
+
+	schemaConfs, err := conf.FieldObjectList("schema")
+	if err != nil {
+		return nil, err
+	}
+	node, err := parquetGroupFromConfig(schemaConfs, defaultEncodingFn)
+	if err != nil {
+		return nil, err
+	}
+
+	compressStr, err := conf.FieldString(dlFieldDefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	var compressDefault compress.Codec
+	switch compressStr {
+	case "uncompressed":
+		compressDefault = &parquet.Uncompressed
+	case "snappy":
+		compressDefault = &parquet.Snappy
+	case "gzip":
+		compressDefault = &parquet.Gzip
+	case "brotli":
+		compressDefault = &parquet.Brotli
+	case "zstd":
+		compressDefault = &parquet.Zstd
+	case "lz4raw":
+		compressDefault = &parquet.Lz4Raw
+	default:
+		return nil, fmt.Errorf("default_compression type %v not recognised", compressStr)
+	}
+
+	encoder, err := newParquetEncodeProcessor(mgr.Logger(), parquet.NewSchema("", node), compressDefault)
+	if err != nil {
+		return nil, err
+	}
+	return &deltaLakeOutput{
+		path:    path,
+		encoder: encoder,
+		fs:      mgr.FS(),
+		log:     mgr.Logger(),
+	}, nil
+}
+
+func (d *deltaLakeOutput) Connect(ctx context.Context) error {
+	return d.fs.MkdirAll(filepath.Join(d.path, deltaLogDir), 0o755)
+}
+
+func (d *deltaLakeOutput) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	data, err := d.encoder.encodeBatch(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode batch: %w", err)
+	}
+
+	fileID, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	fileName := "part-00000-" + fileID.String() + "-c000.parquet"
+	filePath := filepath.Join(d.path, fileName)
+	if err := d.writeExclusive(filePath, data); err != nil {
+		return fmt.Errorf("failed to write data file: %w", err)
+	}
+
+	// The data file is only visible to readers once it has been committed,
+	// and is therefore removed when the commit fails rather than being left
+	// orphaned within the table.
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if err := d.fs.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			d.log.Errorf("Failed to remove uncommitted data file %v: %v", filePath, err)
+		}
+	}()
+
+	now := time.Now().UnixMilli()
+	add := deltaAction{Add: &deltaAdd{
+		Path:             fileName,
+		PartitionValues:  map[string]string{},
+		Size:             int64(len(data)),
+		ModificationTime: now,
+		DataChange:       true,
+		Stats:            fmt.Sprintf(`{"numRecords":%v}`, len(batch)),
+	}}
+	info := deltaAction{CommitInfo: &deltaCommitInfo{
+		Timestamp:  now,
+		Operation:  "WRITE",
+		EngineInfo: "benthos",
+	}}
+
+	for attempt := 0; attempt < deltaMaxCommitRetries; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		version, err := d.latestVersion()
+		if err != nil {
+			return err
+		}
+
+		actions := []deltaAction{add, info}
+		if version < 0 {
+			if actions, err = d.createActions(actions, now); err != nil {
+				return err
+			}
+		}
+
+		if err = d.commit(version+1, actions); err == nil {
+			committed = true
+			return nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("failed to commit version %v: %w", version+1, err)
+		}
+		d.log.Debugf("Commit conflict at version %v, retrying", version+1)
+	}
+	return fmt.Errorf("failed to commit after %v attempts due to concurrent writers", deltaMaxCommitRetries)
+}
+
+// createActions prefixes commit actions with the protocol and metadata actions
+// required in order to create a table.
+func (d *deltaLakeOutput) createActions(actions []deltaAction, now int64) ([]deltaAction, error) {
+	d.createMut.Lock()
+	defer d.createMut.Unlock()
+
+	if d.tableID == "" {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return nil, err
+		}
+		d.tableID = id.String()
+	}
+
+	schemaBytes, err := json.Marshal(deltaStructType(d.encoder.schema.Fields()))
+	if err != nil {
+		return nil, err
+	}
+	return append([]deltaAction{
+		{Protocol: &deltaProtocol{MinReaderVersion: 1, MinWriterVersion: 2}},
+		{MetaData: &deltaMetaData{
+			ID:               d.tableID,
+			Format:           deltaFormat{Provider: "parquet", Options: map[string]string{}},
+			SchemaString:     string(schemaBytes),
+			PartitionColumns: []string{},
+			Configuration:    map[string]string{},
+			CreatedTime:      now,
+		}},
+	}, actions...), nil
+}
+
+// latestVersion returns the version of the most recent commit to the table,
+// or -1 if the table has no commits.
+func (d *deltaLakeOutput) latestVersion() (int64, error) {
+	entries, err := fs.ReadDir(d.fs, filepath.Join(d.path, deltaLogDir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return -1, nil
+		}
+		return 0, fmt.Errorf("failed to list transaction log: %w", err)
+	}
+
+	latest := int64(-1)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		v, err := strconv.ParseInt(strings.TrimSuffix(name, ".json"), 10, 64)
+		if err != nil {
+			continue
+		}
+		if v > latest {
+			latest = v
+		}
+	}
+	return latest, nil
+}
+
+func (d *deltaLakeOutput) commit(version int64, actions []deltaAction) error {
+	var buf strings.Builder
+	for _, a := range actions {
+		b, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	return d.writeExclusive(filepath.Join(d.path, deltaLogDir, deltaCommitFileName(version)), []byte(buf.String()))
+}
+
+// writeExclusive writes a file that must not already exist, a partially
+// written file is removed so that it is never mistaken for a complete one.
+func (d *deltaLakeOutput) writeExclusive(path string, data []byte) error {
+	file, err := d.fs.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = ifs.FileWrite(file, data); err != nil {
+		file.Close()
+		_ = d.fs.Remove(path)
+		return err
+	}
+	if err = file.Close(); err != nil {
+		_ = d.fs.Remove(path)
+		return err
+	}
+	return nil
+}
+
+func (d *deltaLakeOutput) Close(ctx context.Context) error {
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+func deltaCommitFileName(version int64) string {
+	return fmt.Sprintf("%020d.json", version)
+}
+
+type deltaAction struct {
+	Protocol   *deltaProtocol   `json:"protocol,omitempty"`
+	MetaData   *deltaMetaData   `json:"metaData,omitempty"`
+	Add        *deltaAdd        `json:"add,omitempty"`
+	CommitInfo *deltaCommitInfo `json:"commitInfo,omitempty"`
+}
+
+type deltaProtocol struct {
+	MinReaderVersion int `json:"minReaderVersion"`
+	MinWriterVersion int `json:"minWriterVersion"`
+}
+
+type deltaFormat struct {
+	Provider string            `json:"provider"`
+	Options  map[string]string `json:"options"`
+}
+
+type deltaMetaData struct {
+	ID               string            `json:"id"`
+	Format           deltaFormat       `json:"format"`
+	SchemaString     string            `json:"schemaString"`
+	PartitionColumns []string          `json:"partitionColumns"`
+	Configuration    map[string]string `json:"configuration"`
+	CreatedTime      int64             `json:"createdTime"`
+}
+
+type deltaAdd struct {
+	Path             string            `json:"path"`
+	PartitionValues  map[string]string `json:"partitionValues"`
+	Size             int64             `json:"size"`
+	ModificationTime int64             `json:"modificationTime"`
+	DataChange       bool              `json:"dataChange"`
+	Stats            string            `json:"stats,omitempty"`
+}
+
+type deltaCommitInfo struct {
+	Timestamp  int64  `json:"timestamp"`
+	Operation  string `json:"operation"`
+	EngineInfo string `json:"engineInfo"`
+}
+
+// deltaStructType converts parquet fields into the JSON representation of a
+// Spark struct type, which is the format of Delta Lake table schemas.
+func deltaStructType(fields []parquet.Field) map[string]any {
+	sFields := make([]any, 0, len(fields))
+	for _, f := range fields {
+		sFields = append(sFields, map[string]any{
+			"name":     f.Name(),
+			"type":     deltaFieldType(f),
+			"nullable": f.Optional(),
+			"metadata": map[string]any{},
+		})
+	}
+	return map[string]any{
+		"type":   "struct",
+		"fields": sFields,
+	}
+}
+
+func deltaFieldType(n parquet.Node) any {
+	var t any
+	if n.Leaf() {
+		t = deltaLeafType(n.Type())
+	} else {
+		t = deltaStructType(n.Fields())
+	}
+	if n.Repeated() {
+		return map[string]any{
+			"type":         "array",
+			"elementType":  t,
+			"containsNull": false,
+		}
+	}
+	return t
+}
+
+func deltaLeafType(t parquet.Type) string {
+	if lt := t.LogicalType(); lt != nil && lt.UTF8 != nil {
+		return "string"
+	}
+	switch t.Kind() {
+	case parquet.Boolean:
+		return "boolean"
+	case parquet.Int32:
+		return "integer"
+	case parquet.Int64:
+		return "long"
+	case parquet.Float:
+		return "float"
+	case parquet.Double:
+		return "double"
+	}
+	return "binary"
+}
@@ -0,0 +1,171 @@
+package parquet
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func readDeltaCommit(t *testing.T, dir string, version int64) []map[string]any {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join(dir, deltaLogDir, deltaCommitFileName(version)))
+	require.NoError(t, err)
+
+	var actions []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		var action map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &action))
+		actions = append(actions, action)
+	}
+	require.NoError(t, scanner.Err())
+	return actions
+}
+
+func TestDeltaLakeOutputCommits(t *testing.T) {
+	dir := t.TempDir()
+
+	conf, err := deltaLakeOutputConfig().ParseYAML(fmt.Sprintf(`
+path: %v
+schema:
+  - { name: id, type: INT64 }
+  - { name: name, type: UTF8, optional: true }
+  - { name: tags, type: UTF8, repeated: true }
+`, dir), nil)
+	require.NoError(t, err)
+
+	out, err := newDeltaLakeOutputFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, out.Connect(ctx))
+
+	require.NoError(t, out.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte(`{"id":1,"name":"foo","tags":["a","b"]}`)),
+		service.NewMessage([]byte(`{"id":2,"tags":[]}`)),
+	}))
+	require.NoError(t, out.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte(`{"id":3,"name":"bar","tags":["c"]}`)),
+	}))
+	require.NoError(t, out.Close(ctx))
+
+	first := readDeltaCommit(t, dir, 0)
+	require.Len(t, first, 4)
+
+	assert.Equal(t, map[string]any{"minReaderVersion": 1.0, "minWriterVersion": 2.0}, first[0]["protocol"])
+
+	metaData := first[1]["metaData"].(map[string]any)
+	var schema any
+	require.NoError(t, json.Unmarshal([]byte(metaData["schemaString"].(string)), &schema))
+	assert.Equal(t, map[string]any{
+		"type": "struct",
+		"fields": []any{
+			map[string]any{"name": "id", "type": "long", "nullable": false, "metadata": map[string]any{}},
+			map[string]any{"name": "name", "type": "string", "nullable": true, "metadata": map[string]any{}},
+			map[string]any{"name": "tags", "type": map[string]any{
+				"type": "array", "elementType": "string", "containsNull": false,
+			}, "nullable": false, "metadata": map[string]any{}},
+		},
+	}, schema)
+
+	second := readDeltaCommit(t, dir, 1)
+	require.Len(t, second, 2)
+	assert.Nil(t, second[0]["metaData"])
+
+	var ids []int64
+	for _, actions := range [][]map[string]any{first, second} {
+		var add map[string]any
+		for _, a := range actions {
+			if v, ok := a["add"]; ok {
+				add = v.(map[string]any)
+			}
+		}
+		require.NotNil(t, add)
+		assert.Equal(t, true, add["dataChange"])
+
+		f, err := os.Open(filepath.Join(dir, add["path"].(string)))
+		require.NoError(t, err)
+
+		type row struct {
+			ID int64 `parquet:"id"`
+		}
+		rows, err := parquet.Read[row](f, int64(add["size"].(float64)))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		for _, r := range rows {
+			ids = append(ids, r.ID)
+		}
+	}
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+}
+
+func TestDeltaLakeOutputCommitConflict(t *testing.T) {
+	dir := t.TempDir()
+
+	conf, err := deltaLakeOutputConfig().ParseYAML(fmt.Sprintf(`
+path: %v
+schema:
+  - { name: id, type: INT64 }
+`, dir), nil)
+	require.NoError(t, err)
+
+	out, err := newDeltaLakeOutputFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, out.Connect(ctx))
+
+	// Simulate another writer having already created the table.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, deltaLogDir, deltaCommitFileName(0)), []byte(`{"commitInfo":{}}`+"\n"), 0o644))
+
+	require.NoError(t, out.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte(`{"id":1}`)),
+	}))
+
+	actions := readDeltaCommit(t, dir, 1)
+	require.Len(t, actions, 2)
+	assert.Contains(t, actions[0], "add")
+	assert.Contains(t, actions[1], "commitInfo")
+}
+
+func TestDeltaLakeOutputCommitFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	conf, err := deltaLakeOutputConfig().ParseYAML(fmt.Sprintf(`
+path: %v
+schema:
+  - { name: id, type: INT64 }
+`, dir), nil)
+	require.NoError(t, err)
+
+	out, err := newDeltaLakeOutputFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, out.Connect(ctx))
+
+	// A directory in place of the first commit cannot be listed as a version,
+	// and therefore every attempt to commit conflicts with it.
+	require.NoError(t, os.Mkdir(filepath.Join(dir, deltaLogDir, deltaCommitFileName(0)), 0o755))
+
+	require.Error(t, out.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte(`{"id":1}`)),
+	}))
+
+	dataFiles, err := filepath.Glob(filepath.Join(dir, "*.parquet"))
+	require.NoError(t, err)
+	assert.Empty(t, dataFiles)
+}
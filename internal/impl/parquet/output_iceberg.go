@@ -0,0 +1,521 @@
+package parquet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/compress"
+
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	icebergFieldCatalogURL         = "catalog_url"
+	icebergFieldWarehouse          = "warehouse"
+	icebergFieldToken              = "token"
+	icebergFieldNamespace          = "namespace"
+	icebergFieldTable              = "table"
+	icebergFieldDefaultCompression = "default_compression"
+	icebergFieldMaxInFlight        = "max_in_flight"
+	icebergFieldBatching           = "batching"
+
+	icebergNameMappingProperty = "schema.name-mapping.default"
+	icebergMaxCommitRetries    = 100
+)
+
+func icebergOutputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Categories("Local").
+		Summary("Writes batches of structured messages as Parquet files to an [Apache Iceberg](https://iceberg.apache.org/) table and commits them with a [REST catalog](https://iceberg.apache.org/concepts/catalog/#decoupling-using-the-rest-catalog).").
+		Description(`
+Each batch of messages is encoded as a single Parquet file with the configured schema and written within the `+"`data`"+` directory of the table location. A manifest file and a manifest list describing the new file are then written to the `+"`metadata`"+` directory of the table, and a snapshot that appends the file to the table is committed with the catalog. Messages are only acknowledged once the commit has succeeded, and files written for commits that fail are removed.
+
+When a commit conflicts with a concurrent change to the table it is retried against the latest version of the table, and therefore multiple writers can safely append to the same table.
+
+If the table does not yet exist it is created with a schema derived from the `+"`schema`"+` field, otherwise the columns of the `+"`schema`"+` must match the columns of the table by name and type. Only fields of a primitive type are supported, and partitioned tables are not currently supported.
+
+The location of the table must be on a filesystem accessible to Benthos, either as a plain path or a `+"`file://`"+` URI, as object stores are not currently supported. Parquet files are written without field IDs, and therefore a [name mapping](https://iceberg.apache.org/spec/#column-projection) is added to the properties of the table when it does not already have one.
+
+### Performance
+
+Each batch results in a data file, manifest file and snapshot, and therefore it is recommended to configure a [batching policy](/docs/configuration/batching) that produces large batches in order to avoid creating many small files.`).
+		Field(service.NewURLField(icebergFieldCatalogURL).
+			Description("The base URL of the REST catalog.").
+			Example("http://localhost:8181")).
+		Field(service.NewStringField(icebergFieldWarehouse).
+			Description("An optional warehouse to request from the catalog.").
+			Default("").
+			Advanced()).
+		Field(service.NewStringField(icebergFieldToken).
+			Description("An optional bearer token used to authenticate with the catalog.").
+			Default("").
+			Secret()).
+		Field(service.NewStringField(icebergFieldNamespace).
+			Description("The namespace of the table, where the levels of nested namespaces are separated by dots.").
+			Example("analytics").
+			Example("analytics.events")).
+		Field(service.NewStringField(icebergFieldTable).
+			Description("The name of the table.").
+			Example("events")).
+		Field(parquetSchemaConfig()).
+		Field(service.NewStringEnumField(icebergFieldDefaultCompression,
+			"uncompressed", "snappy", "gzip", "brotli", "zstd", "lz4raw",
+		).
+			Description("The default compression type to use for fields.").
+			Default("snappy")).
+		Field(service.NewIntField(icebergFieldMaxInFlight).
+			Description("The maximum number of batches to have in flight at a given time. Increase this to improve throughput.").
+			Default(1)).
+		Field(service.NewBatchPolicyField(icebergFieldBatching)).
+		Version("4.24.0").
+		Example("Writing Events to a Table",
+			"In this example we consume JSON events from Kafka and append them to an Iceberg table in batches of at most ten thousand events, or whatever has accumulated after one minute.",
+			`
+input:
+  kafka:
+    addresses: [ TODO ]
+    topics: [ events ]
+    consumer_group: benthos_events
+
+output:
+  iceberg:
+    catalog_url: http://localhost:8181
+    namespace: analytics
+    table: events
+    schema:
+      - name: id
+        type: UTF8
+      - name: timestamp
+        type: INT64
+      - name: content
+        type: UTF8
+        optional: true
+    batching:
+      count: 10000
+      period: 1m
+`)
+}
+
+func init() {
+	err := service.RegisterBatchOutput(
+		"iceberg", icebergOutputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPol service.BatchPolicy, maxInFlight int, err error) {
+			if maxInFlight, err = conf.FieldInt(icebergFieldMaxInFlight); err != nil {
+				return
+			}
+			if batchPol, err = conf.FieldBatchPolicy(icebergFieldBatching); err != nil {
+				return
+			}
+			out, err = newIcebergOutputFromConfig(conf, mgr)
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type icebergOutput struct {
+	catalog   *icebergCatalog
+	namespace []string
+	table     string
+	encoder   *parquetEncodeProcessor
+	fs        *service.FS
+	log       *service.Logger
+}
+
+func newIcebergOutputFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*icebergOutput, error) {
+	catalogURL, err := conf.FieldString(icebergFieldCatalogURL)
+	if err != nil {
+		return nil, err
+	}
+	warehouse, err := conf.FieldString(icebergFieldWarehouse)
+	if err != nil {
+		return nil, err
+	}
+	token, err := conf.FieldString(icebergFieldToken)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaceStr, err := conf.FieldString(icebergFieldNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if namespaceStr == "" {
+		return nil, errors.New("a namespace must be specified")
+	}
+	table, err := conf.FieldString(icebergFieldTable)
+	if err != nil {
+		return nil, err
+	}
+	if table == "" {
+		return nil, errors.New("a table must be specified")
+	}
+
+	schemaConfs, err := conf.FieldObjectList("schema")
+	if err != nil {
+		return nil, err
+	}
+	node, err := parquetGroupFromConfig(schemaConfs, defaultEncodingFn)
+	if err != nil {
+		return nil, err
+	}
+	schema := parquet.NewSchema("", node)
+	if _, err := icebergSchemaFromParquet(schema.Fields()); err != nil {
+		return nil, err
+	}
+
+	compressStr, err := conf.FieldString(icebergFieldDefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	var compressDefault compress.Codec
+	switch compressStr {
+	case "uncompressed":
+		compressDefault = &parquet.Uncompressed
+	case "snappy":
+		compressDefault = &parquet.Snappy
+	case "gzip":
+		compressDefault = &parquet.Gzip
+	case "brotli":
+		compressDefault = &parquet.Brotli
+	case "zstd":
+		compressDefault = &parquet.Zstd
+	case "lz4raw":
+		compressDefault = &parquet.Lz4Raw
+	default:
+		return nil, fmt.Errorf("default_compression type %v not recognised", compressStr)
+	}
+
+	encoder, err := newParquetEncodeProcessor(mgr.Logger(), schema, compressDefault)
+	if err != nil {
+		return nil, err
+	}
+	return &icebergOutput{
+		catalog: &icebergCatalog{
+			baseURL:   catalogURL,
+			warehouse: warehouse,
+			token:     token,
+			client:    &http.Client{},
+		},
+		namespace: strings.Split(namespaceStr, "."),
+		table:     table,
+		encoder:   encoder,
+		fs:        mgr.FS(),
+		log:       mgr.Logger(),
+	}, nil
+}
+
+func (i *icebergOutput) Connect(ctx context.Context) error {
+	if err := i.catalog.loadConfig(ctx); err != nil {
+		return err
+	}
+
+	meta, err := i.catalog.loadTable(ctx, i.namespace, i.table)
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		schema, err := icebergSchemaFromParquet(i.encoder.schema.Fields())
+		if err != nil {
+			return err
+		}
+		if meta, err = i.catalog.createTable(ctx, i.namespace, i.table, schema); err != nil {
+			return err
+		}
+	}
+	return i.checkTable(meta)
+}
+
+// checkTable returns an error if batches cannot be appended to a table.
+func (i *icebergOutput) checkTable(meta *icebergTableMetadata) error {
+	if meta.isPartitioned() {
+		return errors.New("partitioned tables are not supported")
+	}
+	if _, err := icebergLocalPath(meta.Location); err != nil {
+		return err
+	}
+	schema, err := meta.currentSchema()
+	if err != nil {
+		return err
+	}
+	return checkIcebergSchema(i.encoder.schema.Fields(), schema)
+}
+
+// icebergLocalPath returns the path on the local filesystem of a location
+// within a table.
+func icebergLocalPath(location string) (string, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse table location: %w", err)
+	}
+	switch u.Scheme {
+	case "":
+		return location, nil
+	case "file":
+		return u.Path, nil
+	}
+	return "", fmt.Errorf("table location %v is not supported, only tables on a local filesystem can be written to", location)
+}
+
+func (i *icebergOutput) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	data, err := i.encoder.encodeBatch(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode batch: %w", err)
+	}
+
+	meta, err := i.catalog.loadTable(ctx, i.namespace, i.table)
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		return errors.New("table does not exist")
+	}
+	if err := i.checkTable(meta); err != nil {
+		return err
+	}
+
+	fileID, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	dataLocation := strings.TrimSuffix(meta.Location, "/") + "/data/" + fileID.String() + ".parquet"
+	dataPath, err := i.writeFile(dataLocation, data)
+	if err != nil {
+		return fmt.Errorf("failed to write data file: %w", err)
+	}
+
+	// The data file is only visible to readers once it has been committed,
+	// and is therefore removed when the commit fails rather than being left
+	// orphaned within the table.
+	committed := false
+	defer func() {
+		if !committed {
+			i.removeFile(dataPath)
+		}
+	}()
+
+	for attempt := 0; attempt < icebergMaxCommitRetries; attempt++ {
+		if attempt > 0 {
+			if meta, err = i.catalog.loadTable(ctx, i.namespace, i.table); err != nil {
+				return err
+			}
+			if meta == nil {
+				return errors.New("table does not exist")
+			}
+			if err := i.checkTable(meta); err != nil {
+				return err
+			}
+		}
+
+		err = i.commit(ctx, meta, dataLocation, int64(len(batch)), int64(len(data)))
+		if err == nil {
+			committed = true
+			return nil
+		}
+		if errors.Is(err, errIcebergCommitUnknown) {
+			// The commit may have succeeded, in which case the data file is
+			// referenced by the table and must be kept.
+			committed = true
+			return err
+		}
+		if !errors.Is(err, errIcebergCommitConflict) {
+			return err
+		}
+		i.log.Debugf("Commit conflict for table %v, retrying", i.table)
+	}
+	return fmt.Errorf("failed to commit after %v attempts due to concurrent writers", icebergMaxCommitRetries)
+}
+
+// commit writes the manifest file and manifest list of a snapshot appending a
+// data file to the current snapshot of a table, and then commits it. The
+// manifest files are removed when the commit is known to have failed.
+func (i *icebergOutput) commit(ctx context.Context, meta *icebergTableMetadata, dataLocation string, records, size int64) (err error) {
+	schema, err := meta.currentSchema()
+	if err != nil {
+		return err
+	}
+
+	var written []string
+	defer func() {
+		if err != nil && !errors.Is(err, errIcebergCommitUnknown) {
+			for _, p := range written {
+				i.removeFile(p)
+			}
+		}
+	}()
+
+	snapshot := &icebergSnapshot{
+		SnapshotID:  rand.Int63(),
+		TimestampMs: time.Now().UnixMilli(),
+		SchemaID:    &schema.SchemaID,
+		Summary: map[string]string{
+			"operation":        "append",
+			"added-data-files": "1",
+			"added-records":    strconv.FormatInt(records, 10),
+			"added-files-size": strconv.FormatInt(size, 10),
+		},
+	}
+	if meta.FormatVersion > 1 {
+		snapshot.SequenceNumber = meta.LastSequenceNumber + 1
+	}
+
+	var manifests []icebergManifestFile
+	parent := meta.currentSnapshot()
+	if parent != nil {
+		snapshot.ParentSnapshotID = &parent.SnapshotID
+
+		listPath, err := icebergLocalPath(parent.ManifestList)
+		if err != nil {
+			return err
+		}
+		listBytes, err := ifs.ReadFile(i.fs, listPath)
+		if err != nil {
+			return fmt.Errorf("failed to read manifest list: %w", err)
+		}
+		if manifests, err = decodeIcebergManifestList(listBytes); err != nil {
+			return fmt.Errorf("failed to decode manifest list: %w", err)
+		}
+	}
+	for _, k := range []string{"total-records", "total-data-files", "total-files-size"} {
+		var total int64
+		if parent != nil {
+			v, exists := parent.Summary[k]
+			if !exists {
+				continue
+			}
+			if total, err = strconv.ParseInt(v, 10, 64); err != nil {
+				continue
+			}
+		}
+		switch k {
+		case "total-records":
+			total += records
+		case "total-data-files":
+			total++
+		case "total-files-size":
+			total += size
+		}
+		snapshot.Summary[k] = strconv.FormatInt(total, 10)
+	}
+
+	metaLocation := strings.TrimSuffix(meta.Location, "/") + "/metadata/"
+	manifestID, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	manifestBytes, err := encodeIcebergManifest(meta.FormatVersion, schema, meta.DefaultSpecID, snapshot.SnapshotID, dataLocation, records, size)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	manifestLocation := metaLocation + manifestID.String() + "-m0.avro"
+	manifestPath, err := i.writeFile(manifestLocation, manifestBytes)
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	written = append(written, manifestPath)
+
+	manifests = append([]icebergManifestFile{{
+		Path:              manifestLocation,
+		Length:            int64(len(manifestBytes)),
+		SpecID:            int32(meta.DefaultSpecID),
+		SequenceNumber:    snapshot.SequenceNumber,
+		MinSequenceNumber: snapshot.SequenceNumber,
+		AddedSnapshotID:   snapshot.SnapshotID,
+		AddedFiles:        1,
+		AddedRows:         records,
+	}}, manifests...)
+
+	listBytes, err := encodeIcebergManifestList(meta.FormatVersion, snapshot, manifests)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest list: %w", err)
+	}
+	snapshot.ManifestList = metaLocation + "snap-" + strconv.FormatInt(snapshot.SnapshotID, 10) + "-1-" + manifestID.String() + ".avro"
+	listPath, err := i.writeFile(snapshot.ManifestList, listBytes)
+	if err != nil {
+		return fmt.Errorf("failed to write manifest list: %w", err)
+	}
+	written = append(written, listPath)
+
+	var parentID any
+	if parent != nil {
+		parentID = parent.SnapshotID
+	}
+	requirements := []map[string]any{
+		{"type": "assert-table-uuid", "uuid": meta.TableUUID},
+		{"type": "assert-ref-snapshot-id", "ref": "main", "snapshot-id": parentID},
+	}
+	updates := []map[string]any{
+		{"action": "add-snapshot", "snapshot": snapshot},
+		{"action": "set-snapshot-ref", "ref-name": "main", "type": "branch", "snapshot-id": snapshot.SnapshotID},
+	}
+	if _, exists := meta.Properties[icebergNameMappingProperty]; !exists {
+		mappingBytes, err := json.Marshal(icebergNameMapping(schema.Fields))
+		if err != nil {
+			return err
+		}
+		updates = append(updates, map[string]any{
+			"action":  "set-properties",
+			"updates": map[string]string{icebergNameMappingProperty: string(mappingBytes)},
+		})
+	}
+	return i.catalog.commitTable(ctx, i.namespace, i.table, requirements, updates)
+}
+
+// writeFile writes a new file at a location within the table, and returns its
+// local path.
+func (i *icebergOutput) writeFile(location string, data []byte) (string, error) {
+	path, err := icebergLocalPath(location)
+	if err != nil {
+		return "", err
+	}
+	if err := i.fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	file, err := i.fs.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	if _, err = ifs.FileWrite(file, data); err != nil {
+		file.Close()
+		i.removeFile(path)
+		return "", err
+	}
+	if err = file.Close(); err != nil {
+		i.removeFile(path)
+		return "", err
+	}
+	return path, nil
+}
+
+func (i *icebergOutput) removeFile(path string) {
+	if err := i.fs.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		i.log.Errorf("Failed to remove uncommitted file %v: %v", path, err)
+	}
+}
+
+func (i *icebergOutput) Close(ctx context.Context) error {
+	return nil
+}
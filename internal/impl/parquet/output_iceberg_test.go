@@ -0,0 +1,323 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/segmentio/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+// icebergTestCatalog is a minimal REST catalog serving a single table.
+type icebergTestCatalog struct {
+	mut           sync.Mutex
+	formatVersion int
+	location      string
+	meta          *icebergTableMetadata
+	conflicts     int
+	reject        bool
+}
+
+func (c *icebergTestCatalog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	writeJSON := func(code int, v any) {
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	writeErr := func(code int, msg string) {
+		writeJSON(code, map[string]any{"error": map[string]any{"message": msg, "type": "TestException", "code": code}})
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/config":
+		writeJSON(http.StatusOK, map[string]any{"defaults": map[string]any{}, "overrides": map[string]any{"prefix": "test"}})
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/test/namespaces/analytics\x1fraw/tables/events":
+		if c.meta == nil {
+			writeErr(http.StatusNotFound, "table does not exist")
+			return
+		}
+		writeJSON(http.StatusOK, map[string]any{"metadata": c.meta})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/test/namespaces/analytics\x1fraw/tables":
+		var req struct {
+			Name   string        `json:"name"`
+			Schema icebergSchema `json:"schema"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.meta = &icebergTableMetadata{
+			FormatVersion:  c.formatVersion,
+			TableUUID:      "test-uuid",
+			Location:       "file://" + c.location,
+			Schemas:        []icebergSchema{req.Schema},
+			PartitionSpecs: []icebergPartitionSpec{{Fields: []any{}}},
+			Properties:     map[string]string{},
+		}
+		writeJSON(http.StatusOK, map[string]any{"metadata": c.meta})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/test/namespaces/analytics\x1fraw/tables/events":
+		if c.conflicts > 0 {
+			c.conflicts--
+			writeErr(http.StatusConflict, "nope")
+			return
+		}
+		if c.reject {
+			writeErr(http.StatusBadRequest, "nope")
+			return
+		}
+
+		var req struct {
+			Requirements []map[string]any `json:"requirements"`
+			Updates      []map[string]any `json:"updates"`
+		}
+		dec := json.NewDecoder(r.Body)
+		dec.UseNumber()
+		if err := dec.Decode(&req); err != nil {
+			writeErr(http.StatusBadRequest, err.Error())
+			return
+		}
+		for _, r := range req.Requirements {
+			if r["type"] != "assert-ref-snapshot-id" {
+				continue
+			}
+			var current any
+			if c.meta.CurrentSnapshotID != nil {
+				current = json.Number(strconv.FormatInt(*c.meta.CurrentSnapshotID, 10))
+			}
+			if r["snapshot-id"] != current {
+				writeErr(http.StatusConflict, "snapshot mismatch")
+				return
+			}
+		}
+		for _, u := range req.Updates {
+			switch u["action"] {
+			case "add-snapshot":
+				b, _ := json.Marshal(u["snapshot"])
+				var s icebergSnapshot
+				_ = json.Unmarshal(b, &s)
+				c.meta.Snapshots = append(c.meta.Snapshots, s)
+				c.meta.LastSequenceNumber = s.SequenceNumber
+			case "set-snapshot-ref":
+				id, _ := u["snapshot-id"].(json.Number).Int64()
+				c.meta.CurrentSnapshotID = &id
+			case "set-properties":
+				for k, v := range u["updates"].(map[string]any) {
+					c.meta.Properties[k] = v.(string)
+				}
+			}
+		}
+		writeJSON(http.StatusOK, map[string]any{"metadata": c.meta})
+	default:
+		writeErr(http.StatusNotFound, "unexpected request "+r.URL.Path)
+	}
+}
+
+func newIcebergTestOutput(t *testing.T, catalog *icebergTestCatalog, schema string) *icebergOutput {
+	t.Helper()
+
+	srv := httptest.NewServer(catalog)
+	t.Cleanup(srv.Close)
+
+	conf, err := icebergOutputConfig().ParseYAML(fmt.Sprintf(`
+catalog_url: %v
+namespace: analytics.raw
+table: events
+schema: %v
+`, srv.URL, schema), nil)
+	require.NoError(t, err)
+
+	out, err := newIcebergOutputFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+	return out
+}
+
+func readIcebergAvro(t *testing.T, path string) []map[string]any {
+	t.Helper()
+
+	localPath, err := icebergLocalPath(path)
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(localPath)
+	require.NoError(t, err)
+
+	r, err := goavro.NewOCFReader(bytes.NewReader(b))
+	require.NoError(t, err)
+
+	var records []map[string]any
+	for r.Scan() {
+		v, err := r.Read()
+		require.NoError(t, err)
+		records = append(records, v.(map[string]any))
+	}
+	return records
+}
+
+func TestIcebergOutputAppends(t *testing.T) {
+	for _, version := range []int{1, 2} {
+		version := version
+		t.Run(fmt.Sprintf("format version %v", version), func(t *testing.T) {
+			catalog := &icebergTestCatalog{formatVersion: version, location: t.TempDir()}
+			out := newIcebergTestOutput(t, catalog, `
+  - { name: id, type: INT64 }
+  - { name: name, type: UTF8, optional: true }
+`)
+
+			ctx := context.Background()
+			require.NoError(t, out.Connect(ctx))
+
+			require.NoError(t, out.WriteBatch(ctx, service.MessageBatch{
+				service.NewMessage([]byte(`{"id":1,"name":"foo"}`)),
+				service.NewMessage([]byte(`{"id":2}`)),
+			}))
+			require.NoError(t, out.WriteBatch(ctx, service.MessageBatch{
+				service.NewMessage([]byte(`{"id":3,"name":"bar"}`)),
+			}))
+			require.NoError(t, out.Close(ctx))
+
+			meta := catalog.meta
+			require.Len(t, meta.Snapshots, 2)
+			first, second := meta.Snapshots[0], meta.Snapshots[1]
+			assert.Equal(t, second.SnapshotID, *meta.CurrentSnapshotID)
+			assert.Equal(t, first.SnapshotID, *second.ParentSnapshotID)
+			assert.Equal(t, "append", second.Summary["operation"])
+			assert.Equal(t, "3", second.Summary["total-records"])
+			assert.Equal(t, "2", second.Summary["total-data-files"])
+			if version > 1 {
+				assert.Equal(t, int64(1), first.SequenceNumber)
+				assert.Equal(t, int64(2), second.SequenceNumber)
+			}
+
+			var mapping []map[string]any
+			require.NoError(t, json.Unmarshal([]byte(meta.Properties[icebergNameMappingProperty]), &mapping))
+			assert.Equal(t, []map[string]any{
+				{"field-id": 1.0, "names": []any{"id"}},
+				{"field-id": 2.0, "names": []any{"name"}},
+			}, mapping)
+
+			manifests := readIcebergAvro(t, second.ManifestList)
+			require.Len(t, manifests, 2)
+
+			var ids []int64
+			for _, m := range manifests {
+				entries := readIcebergAvro(t, m["manifest_path"].(string))
+				require.Len(t, entries, 1)
+				assert.Equal(t, int32(1), entries[0]["status"])
+
+				dataFile := entries[0]["data_file"].(map[string]any)
+				assert.Equal(t, "PARQUET", dataFile["file_format"])
+
+				dataPath, err := icebergLocalPath(dataFile["file_path"].(string))
+				require.NoError(t, err)
+				f, err := os.Open(dataPath)
+				require.NoError(t, err)
+
+				type row struct {
+					ID int64 `parquet:"id"`
+				}
+				rows, err := parquet.Read[row](f, dataFile["file_size_in_bytes"].(int64))
+				require.NoError(t, err)
+				require.NoError(t, f.Close())
+				assert.Equal(t, dataFile["record_count"], int64(len(rows)))
+
+				for _, r := range rows {
+					ids = append(ids, r.ID)
+				}
+			}
+			assert.Equal(t, []int64{3, 1, 2}, ids)
+		})
+	}
+}
+
+func TestIcebergOutputCommitConflict(t *testing.T) {
+	catalog := &icebergTestCatalog{formatVersion: 2, location: t.TempDir(), conflicts: 1}
+	out := newIcebergTestOutput(t, catalog, `
+  - { name: id, type: INT64 }
+`)
+
+	ctx := context.Background()
+	require.NoError(t, out.Connect(ctx))
+	require.NoError(t, out.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte(`{"id":1}`)),
+	}))
+	require.Len(t, catalog.meta.Snapshots, 1)
+
+	// The manifests of the rejected attempt are removed.
+	metaFiles, err := os.ReadDir(filepath.Join(catalog.location, "metadata"))
+	require.NoError(t, err)
+	assert.Len(t, metaFiles, 2)
+}
+
+func TestIcebergOutputCommitFailure(t *testing.T) {
+	catalog := &icebergTestCatalog{formatVersion: 2, location: t.TempDir(), reject: true}
+	out := newIcebergTestOutput(t, catalog, `
+  - { name: id, type: INT64 }
+`)
+
+	ctx := context.Background()
+	require.NoError(t, out.Connect(ctx))
+
+	err := out.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte(`{"id":1}`)),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nope")
+
+	for _, dir := range []string{"data", "metadata"} {
+		files, err := os.ReadDir(filepath.Join(catalog.location, dir))
+		require.NoError(t, err)
+		assert.Empty(t, files, dir)
+	}
+}
+
+func TestIcebergOutputSchemaMismatch(t *testing.T) {
+	catalog := &icebergTestCatalog{formatVersion: 2, location: t.TempDir()}
+	catalog.meta = &icebergTableMetadata{
+		FormatVersion: 2,
+		Location:      catalog.location,
+		Schemas: []icebergSchema{{Type: "struct", Fields: []icebergField{
+			{ID: 1, Name: "id", Required: true, Type: "string"},
+		}}},
+		PartitionSpecs: []icebergPartitionSpec{{Fields: []any{}}},
+	}
+
+	for schema, errContains := range map[string]string{
+		`[{ name: id, type: INT64 }]`:                            "column id has type string",
+		`[{ name: id, type: UTF8, optional: true }]`:             "required table column id must not be optional",
+		`[{ name: name, type: UTF8 }]`:                           "required table column id is missing",
+		`[{ name: id, type: UTF8 }, { name: name, type: UTF8 }]`: "column name does not exist",
+	} {
+		out := newIcebergTestOutput(t, catalog, schema)
+		err := out.Connect(context.Background())
+		require.Error(t, err, schema)
+		assert.Contains(t, err.Error(), errContains, schema)
+	}
+
+	conf, err := icebergOutputConfig().ParseYAML(`
+catalog_url: http://localhost:8181
+namespace: analytics
+table: events
+schema:
+  - { name: tags, type: UTF8, repeated: true }
+`, nil)
+	require.NoError(t, err)
+
+	_, err = newIcebergOutputFromConfig(conf, service.MockResources())
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "only fields of a primitive type"), err.Error())
+}
@@ -233,6 +233,18 @@ func (s *parquetEncodeProcessor) ProcessBatch(ctx context.Context, batch service
 		return nil, nil
 	}
 
+	data, err := s.encodeBatch(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	outMsg := batch[0]
+	outMsg.SetBytes(data)
+	return []service.MessageBatch{{outMsg}}, nil
+}
+
+// encodeBatch encodes a batch of structured messages as a parquet file.
+func (s *parquetEncodeProcessor) encodeBatch(batch service.MessageBatch) ([]byte, error) {
 	buf := bytes.NewBuffer(nil)
 	pWtr := parquet.NewGenericWriter[any](buf, s.schema, parquet.Compression(s.compressionType))
 
@@ -259,10 +271,7 @@ func (s *parquetEncodeProcessor) ProcessBatch(ctx context.Context, batch service
 	if err := pWtr.Close(); err != nil {
 		return nil, err
 	}
-
-	outMsg := batch[0]
-	outMsg.SetBytes(buf.Bytes())
-	return []service.MessageBatch{{outMsg}}, nil
+	return buf.Bytes(), nil
 }
 
 func (s *parquetEncodeProcessor) Close(ctx context.Context) error {
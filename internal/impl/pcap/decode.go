@@ -0,0 +1,365 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Link types of captured packets, as defined by
+// https://www.tcpdump.org/linktypes.html.
+const (
+	linkTypeEthernet = 1
+	linkTypeRaw      = 101
+	linkTypeLinuxSLL = 113
+	linkTypeIPv4     = 228
+	linkTypeIPv6     = 229
+)
+
+const (
+	etherTypeIPv4 = 0x0800
+	etherTypeARP  = 0x0806
+	etherTypeVLAN = 0x8100
+	etherTypeQinQ = 0x88a8
+	etherTypeIPv6 = 0x86dd
+)
+
+// IP protocol numbers of the transport layers that are decoded.
+const (
+	ipProtoICMP   = 1
+	ipProtoTCP    = 6
+	ipProtoUDP    = 17
+	ipProtoICMPv6 = 58
+)
+
+// packet is a captured packet along with the time it was captured and its
+// length on the wire, which may exceed the length of the captured data.
+type packet struct {
+	data   []byte
+	ts     time.Time
+	length int
+}
+
+// decodedPacket is a summary of the layers of a packet.
+type decodedPacket struct {
+	timestamp      time.Time
+	length         int
+	capturedLength int
+
+	srcMAC, dstMAC net.HardwareAddr
+	vlan           int
+	hasVLAN        bool
+	etherType      uint16
+
+	ipVersion    int
+	srcIP, dstIP net.IP
+	ttl          int
+	ipProto      int
+
+	hasPorts         bool
+	srcPort, dstPort int
+
+	tcpFlags  uint8
+	tcpSeq    uint32
+	tcpAck    uint32
+	tcpWindow int
+
+	hasICMP            bool
+	icmpType, icmpCode int
+
+	arpOp int
+
+	payloadLength int
+}
+
+// decodePacket decodes the link, network and transport layers of a packet.
+// Layers that cannot be decoded, including truncated ones, are omitted from
+// the result.
+func decodePacket(linkType uint32, p packet) *decodedPacket {
+	d := &decodedPacket{
+		timestamp:      p.ts,
+		length:         p.length,
+		capturedLength: len(p.data),
+	}
+
+	data := p.data
+	switch linkType {
+	case linkTypeEthernet:
+		if len(data) < 14 {
+			return d
+		}
+		d.dstMAC = net.HardwareAddr(data[0:6])
+		d.srcMAC = net.HardwareAddr(data[6:12])
+		d.etherType = binary.BigEndian.Uint16(data[12:14])
+		data = data[14:]
+		for (d.etherType == etherTypeVLAN || d.etherType == etherTypeQinQ) && len(data) >= 4 {
+			if !d.hasVLAN {
+				d.vlan = int(binary.BigEndian.Uint16(data[0:2]) & 0x0fff)
+				d.hasVLAN = true
+			}
+			d.etherType = binary.BigEndian.Uint16(data[2:4])
+			data = data[4:]
+		}
+	case linkTypeLinuxSLL:
+		if len(data) < 16 {
+			return d
+		}
+		if addrLen := int(binary.BigEndian.Uint16(data[4:6])); addrLen == 6 {
+			d.srcMAC = net.HardwareAddr(data[6:12])
+		}
+		d.etherType = binary.BigEndian.Uint16(data[14:16])
+		data = data[16:]
+	case linkTypeRaw, linkTypeIPv4, linkTypeIPv6:
+		if len(data) == 0 {
+			return d
+		}
+		switch data[0] >> 4 {
+		case 4:
+			d.etherType = etherTypeIPv4
+		case 6:
+			d.etherType = etherTypeIPv6
+		}
+	default:
+		return d
+	}
+
+	switch d.etherType {
+	case etherTypeIPv4:
+		d.decodeIPv4(data)
+	case etherTypeIPv6:
+		d.decodeIPv6(data)
+	case etherTypeARP:
+		if len(data) >= 8 {
+			d.arpOp = int(binary.BigEndian.Uint16(data[6:8]))
+		}
+		if len(data) >= 28 && data[4] == 6 && data[5] == 4 {
+			d.srcIP = net.IP(data[14:18])
+			d.dstIP = net.IP(data[24:28])
+		}
+	}
+	return d
+}
+
+func (d *decodedPacket) decodeIPv4(data []byte) {
+	if len(data) < 20 {
+		return
+	}
+	headerLen := int(data[0]&0x0f) * 4
+	if headerLen < 20 || len(data) < headerLen {
+		return
+	}
+	d.ipVersion = 4
+	d.ttl = int(data[8])
+	d.ipProto = int(data[9])
+	d.srcIP = net.IP(data[12:16])
+	d.dstIP = net.IP(data[16:20])
+
+	totalLen := int(binary.BigEndian.Uint16(data[2:4]))
+	payload := data[headerLen:]
+	if totalLen >= headerLen && totalLen-headerLen < len(payload) {
+		payload = payload[:totalLen-headerLen]
+	}
+
+	// Only the first fragment of a datagram contains the transport header.
+	if fragOffset := binary.BigEndian.Uint16(data[6:8]) & 0x1fff; fragOffset != 0 {
+		d.payloadLength = len(payload)
+		return
+	}
+	d.decodeTransport(payload)
+}
+
+func (d *decodedPacket) decodeIPv6(data []byte) {
+	if len(data) < 40 {
+		return
+	}
+	d.ipVersion = 6
+	d.ttl = int(data[7])
+	d.srcIP = net.IP(data[8:24])
+	d.dstIP = net.IP(data[24:40])
+
+	payload := data[40:]
+	if payloadLen := int(binary.BigEndian.Uint16(data[4:6])); payloadLen < len(payload) {
+		payload = payload[:payloadLen]
+	}
+
+	// Skip over extension headers until the transport layer is reached.
+	next := int(data[6])
+extHeaders:
+	for {
+		switch next {
+		case 0, 43, 60: // Hop-by-hop, routing and destination options.
+			if len(payload) < 8 {
+				return
+			}
+			extLen := (int(payload[1]) + 1) * 8
+			if len(payload) < extLen {
+				return
+			}
+			next = int(payload[0])
+			payload = payload[extLen:]
+		case 44: // Fragment.
+			if len(payload) < 8 {
+				return
+			}
+			next = int(payload[0])
+			fragOffset := binary.BigEndian.Uint16(payload[2:4]) >> 3
+			payload = payload[8:]
+			if fragOffset != 0 {
+				d.ipProto = next
+				d.payloadLength = len(payload)
+				return
+			}
+		default:
+			break extHeaders
+		}
+	}
+	d.ipProto = next
+	d.decodeTransport(payload)
+}
+
+func (d *decodedPacket) decodeTransport(data []byte) {
+	switch d.ipProto {
+	case ipProtoTCP:
+		if len(data) < 20 {
+			return
+		}
+		headerLen := int(data[12]>>4) * 4
+		if headerLen < 20 || len(data) < headerLen {
+			return
+		}
+		d.hasPorts = true
+		d.srcPort = int(binary.BigEndian.Uint16(data[0:2]))
+		d.dstPort = int(binary.BigEndian.Uint16(data[2:4]))
+		d.tcpSeq = binary.BigEndian.Uint32(data[4:8])
+		d.tcpAck = binary.BigEndian.Uint32(data[8:12])
+		d.tcpFlags = data[13]
+		d.tcpWindow = int(binary.BigEndian.Uint16(data[14:16]))
+		d.payloadLength = len(data) - headerLen
+	case ipProtoUDP:
+		if len(data) < 8 {
+			return
+		}
+		d.hasPorts = true
+		d.srcPort = int(binary.BigEndian.Uint16(data[0:2]))
+		d.dstPort = int(binary.BigEndian.Uint16(data[2:4]))
+		d.payloadLength = len(data) - 8
+	case ipProtoICMP, ipProtoICMPv6:
+		if len(data) < 4 {
+			return
+		}
+		d.hasICMP = true
+		d.icmpType = int(data[0])
+		d.icmpCode = int(data[1])
+		d.payloadLength = len(data) - 4
+	default:
+		d.payloadLength = len(data)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func protocolName(proto int) string {
+	switch proto {
+	case ipProtoICMP:
+		return "icmp"
+	case ipProtoTCP:
+		return "tcp"
+	case ipProtoUDP:
+		return "udp"
+	case ipProtoICMPv6:
+		return "icmp6"
+	case 2:
+		return "igmp"
+	case 47:
+		return "gre"
+	case 50:
+		return "esp"
+	case 51:
+		return "ah"
+	case 132:
+		return "sctp"
+	}
+	return ""
+}
+
+// protocol returns the name of the transport protocol of the packet, or the
+// name of the network protocol when it has no transport layer.
+func (d *decodedPacket) protocol() string {
+	if d.ipVersion != 0 {
+		if name := protocolName(d.ipProto); name != "" {
+			return name
+		}
+		return "ip_proto_" + strconv.Itoa(d.ipProto)
+	}
+	if d.etherType == etherTypeARP {
+		return "arp"
+	}
+	return ""
+}
+
+var tcpFlagNames = []string{"FIN", "SYN", "RST", "PSH", "ACK", "URG", "ECE", "CWR"}
+
+func tcpFlagList(flags uint8) []any {
+	names := []any{}
+	for i, name := range tcpFlagNames {
+		if flags&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// structured returns a summary of the packet as an object.
+func (d *decodedPacket) structured() map[string]any {
+	obj := map[string]any{
+		"timestamp":       d.timestamp.UTC().Format(time.RFC3339Nano),
+		"length":          int64(d.length),
+		"captured_length": int64(d.capturedLength),
+	}
+	if d.srcMAC != nil {
+		obj["src_mac"] = d.srcMAC.String()
+	}
+	if d.dstMAC != nil {
+		obj["dst_mac"] = d.dstMAC.String()
+	}
+	if d.hasVLAN {
+		obj["vlan"] = int64(d.vlan)
+	}
+	if d.etherType != 0 {
+		obj["ether_type"] = fmt.Sprintf("0x%04x", d.etherType)
+	}
+	if d.srcIP != nil {
+		obj["src_ip"] = d.srcIP.String()
+		obj["dst_ip"] = d.dstIP.String()
+	}
+	if d.ipVersion != 0 {
+		obj["ip_version"] = int64(d.ipVersion)
+		obj["ttl"] = int64(d.ttl)
+	}
+	if proto := d.protocol(); proto != "" {
+		obj["protocol"] = proto
+	}
+	if d.hasPorts {
+		obj["src_port"] = int64(d.srcPort)
+		obj["dst_port"] = int64(d.dstPort)
+	}
+	if d.ipProto == ipProtoTCP && d.hasPorts {
+		obj["tcp_flags"] = tcpFlagList(d.tcpFlags)
+		obj["tcp_seq"] = int64(d.tcpSeq)
+		obj["tcp_ack"] = int64(d.tcpAck)
+		obj["tcp_window"] = int64(d.tcpWindow)
+	}
+	if d.hasICMP {
+		obj["icmp_type"] = int64(d.icmpType)
+		obj["icmp_code"] = int64(d.icmpCode)
+	}
+	if d.arpOp != 0 {
+		obj["arp_op"] = int64(d.arpOp)
+	}
+	if d.ipVersion != 0 {
+		obj["payload_length"] = int64(d.payloadLength)
+	}
+	return obj
+}
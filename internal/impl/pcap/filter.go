@@ -0,0 +1,267 @@
+package pcap
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// parseFilter parses a filter expression written in a subset of the
+// pcap-filter syntax into a tree of nodes that can be compiled into a BPF
+// program. An empty expression results in a nil node, which matches all
+// packets.
+func parseFilter(expr string) (filterNode, error) {
+	p := &filterParser{tokens: tokenizeFilter(expr)}
+	if len(p.tokens) == 0 {
+		return nil, nil
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected token '%v'", tok)
+	}
+	return n, nil
+}
+
+func tokenizeFilter(expr string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			flush()
+		case c == '(' || c == ')':
+			flush()
+			tokens = append(tokens, string(c))
+		case c == '!':
+			flush()
+			tokens = append(tokens, "not")
+		case (c == '&' || c == '|') && i+1 < len(expr) && expr[i+1] == c:
+			flush()
+			if c == '&' {
+				tokens = append(tokens, "and")
+			} else {
+				tokens = append(tokens, "or")
+			}
+			i++
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return tokens
+}
+
+type filterParser struct {
+	tokens []string
+	i      int
+}
+
+func (p *filterParser) peek() (string, bool) {
+	if p.i >= len(p.tokens) {
+		return "", false
+	}
+	return p.tokens[p.i], true
+}
+
+func (p *filterParser) next() (string, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return "", errors.New("unexpected end of filter")
+	}
+	p.i++
+	return tok, nil
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if tok, _ := p.peek(); tok != "or" {
+			return left, nil
+		}
+		p.i++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if tok, _ := p.peek(); tok != "and" {
+			return left, nil
+		}
+		p.i++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case "not":
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{node: n}, nil
+	case "(":
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok, err := p.next(); err != nil || tok != ")" {
+			return nil, errors.New("expected closing parenthesis")
+		}
+		return n, nil
+	}
+	p.i--
+	return p.parsePrimitive()
+}
+
+func (p *filterParser) parsePrimitive() (filterNode, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case "ip", "ip6", "arp", "tcp", "udp", "icmp", "icmp6":
+		proto := &protocolNode{name: tok}
+
+		// A protocol may qualify a following host, net or port primitive,
+		// such as `tcp port 80`.
+		if next, _ := p.peek(); isQualifiedPrimitive(next) {
+			n, err := p.parsePrimitive()
+			if err != nil {
+				return nil, err
+			}
+			return &andNode{left: proto, right: n}, nil
+		}
+		return proto, nil
+	case "vlan":
+		if next, ok := p.peek(); ok {
+			if id, err := strconv.Atoi(next); err == nil {
+				p.i++
+				return &vlanNode{id: id, hasID: true}, nil
+			}
+		}
+		return &vlanNode{}, nil
+	case "less", "greater":
+		arg, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("expected a length after '%v', got '%v'", tok, arg)
+		}
+		return &lengthNode{greater: tok == "greater", n: n}, nil
+	}
+
+	src, dst := true, true
+	switch tok {
+	case "src":
+		dst = false
+		if tok, err = p.next(); err != nil {
+			return nil, err
+		}
+	case "dst":
+		src = false
+		if tok, err = p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	arg, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case "host":
+		ip := net.ParseIP(arg)
+		if ip == nil {
+			return nil, fmt.Errorf("expected an IP address after 'host', got '%v'", arg)
+		}
+		mask := net.CIDRMask(32, 32)
+		if ip.To4() == nil {
+			mask = net.CIDRMask(128, 128)
+		}
+		return newAddrNode(src, dst, ip, mask), nil
+	case "net":
+		_, ipNet, err := net.ParseCIDR(arg)
+		if err != nil {
+			return nil, fmt.Errorf("expected a CIDR after 'net', got '%v'", arg)
+		}
+		return newAddrNode(src, dst, ipNet.IP, ipNet.Mask), nil
+	case "port":
+		port, err := parsePort(arg)
+		if err != nil {
+			return nil, err
+		}
+		return &portNode{src: src, dst: dst, lower: port, upper: port}, nil
+	case "portrange":
+		lowerStr, upperStr, found := strings.Cut(arg, "-")
+		if !found {
+			return nil, fmt.Errorf("expected a port range of the form low-high, got '%v'", arg)
+		}
+		lower, err := parsePort(lowerStr)
+		if err != nil {
+			return nil, err
+		}
+		upper, err := parsePort(upperStr)
+		if err != nil {
+			return nil, err
+		}
+		if lower > upper {
+			lower, upper = upper, lower
+		}
+		return &portNode{src: src, dst: dst, lower: lower, upper: upper}, nil
+	}
+	return nil, fmt.Errorf("unrecognised filter primitive '%v'", tok)
+}
+
+func isQualifiedPrimitive(tok string) bool {
+	switch tok {
+	case "src", "dst", "host", "net", "port", "portrange":
+		return true
+	}
+	return false
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 0 || port > 65535 {
+		return 0, fmt.Errorf("expected a port number, got '%v'", s)
+	}
+	return port, nil
+}
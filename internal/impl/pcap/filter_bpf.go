@@ -0,0 +1,581 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"golang.org/x/net/bpf"
+)
+
+// bpfAcceptLength is the number of bytes of a packet kept by a BPF program
+// that accepts it, which is large enough to keep packets whole.
+const bpfAcceptLength = 262144
+
+// bpfMaxInstructions is the maximum length of a classic BPF program accepted
+// by the kernel.
+const bpfMaxInstructions = 4096
+
+// Scratch memory slots populated by the prologue of a compiled program, which
+// normalise the link layer so that filter nodes need not be aware of it.
+const (
+	memNetOffset = iota
+	memEtherType
+	memHasVLAN
+	memVLAN
+)
+
+// filterNode is a node of a parsed filter expression, which compiles into
+// instructions that jump to onTrue when a packet matches and onFalse when it
+// does not.
+type filterNode interface {
+	compile(c *bpfCompiler, onTrue, onFalse bpfLabel)
+}
+
+// compileFilter compiles a filter expression into a BPF program for packets of
+// a link type, where a nil node results in a program that accepts all packets.
+func compileFilter(node filterNode, linkType uint32) ([]bpf.Instruction, error) {
+	c := &bpfCompiler{}
+	accept, reject := c.newLabel(), c.newLabel()
+	if node != nil {
+		c.prologue(linkType)
+		node.compile(c, accept, reject)
+	}
+	c.place(accept)
+	c.emit(bpf.RetConstant{Val: bpfAcceptLength})
+	c.place(reject)
+	c.emit(bpf.RetConstant{Val: 0})
+	return c.assemble()
+}
+
+//------------------------------------------------------------------------------
+
+type andNode struct {
+	left, right filterNode
+}
+
+func (n *andNode) compile(c *bpfCompiler, onTrue, onFalse bpfLabel) {
+	right := c.newLabel()
+	n.left.compile(c, right, onFalse)
+	c.place(right)
+	n.right.compile(c, onTrue, onFalse)
+}
+
+type orNode struct {
+	left, right filterNode
+}
+
+func (n *orNode) compile(c *bpfCompiler, onTrue, onFalse bpfLabel) {
+	right := c.newLabel()
+	n.left.compile(c, onTrue, right)
+	c.place(right)
+	n.right.compile(c, onTrue, onFalse)
+}
+
+type notNode struct {
+	node filterNode
+}
+
+func (n *notNode) compile(c *bpfCompiler, onTrue, onFalse bpfLabel) {
+	n.node.compile(c, onFalse, onTrue)
+}
+
+type protocolNode struct {
+	name string
+}
+
+func (n *protocolNode) compile(c *bpfCompiler, onTrue, onFalse bpfLabel) {
+	switch n.name {
+	case "ip":
+		c.jumpIfEtherType(etherTypeIPv4, onTrue, onFalse)
+	case "ip6":
+		c.jumpIfEtherType(etherTypeIPv6, onTrue, onFalse)
+	case "arp":
+		c.jumpIfEtherType(etherTypeARP, onTrue, onFalse)
+	case "tcp":
+		c.jumpIfIPProto(ipProtoTCP, true, true, onTrue, onFalse)
+	case "udp":
+		c.jumpIfIPProto(ipProtoUDP, true, true, onTrue, onFalse)
+	case "icmp":
+		c.jumpIfIPProto(ipProtoICMP, true, false, onTrue, onFalse)
+	default: // icmp6
+		c.jumpIfIPProto(ipProtoICMPv6, false, true, onTrue, onFalse)
+	}
+}
+
+type vlanNode struct {
+	id    int
+	hasID bool
+}
+
+func (n *vlanNode) compile(c *bpfCompiler, onTrue, onFalse bpfLabel) {
+	c.emit(bpf.LoadScratch{Dst: bpf.RegA, N: memHasVLAN})
+	if !n.hasID {
+		c.jumpIf(bpf.JumpEqual, 1, onTrue, onFalse)
+		return
+	}
+	checkID := c.newLabel()
+	c.jumpIf(bpf.JumpEqual, 1, checkID, onFalse)
+	c.place(checkID)
+	c.emit(bpf.LoadScratch{Dst: bpf.RegA, N: memVLAN})
+	c.jumpIf(bpf.JumpEqual, uint32(n.id), onTrue, onFalse)
+}
+
+type lengthNode struct {
+	greater bool
+	n       int
+}
+
+func (n *lengthNode) compile(c *bpfCompiler, onTrue, onFalse bpfLabel) {
+	c.emit(bpf.LoadExtension{Num: bpf.ExtLen})
+	if n.greater {
+		c.jumpIf(bpf.JumpGreaterOrEqual, uint32(n.n), onTrue, onFalse)
+	} else {
+		c.jumpIf(bpf.JumpGreaterThan, uint32(n.n), onFalse, onTrue)
+	}
+}
+
+// addrNode matches the source or destination address of IPv4 and ARP packets,
+// or of IPv6 packets, against an address and mask.
+type addrNode struct {
+	src, dst   bool
+	addr, mask []byte
+	v4         bool
+}
+
+func newAddrNode(src, dst bool, ip net.IP, mask net.IPMask) *addrNode {
+	n := &addrNode{src: src, dst: dst, mask: mask}
+	if ip4 := ip.To4(); ip4 != nil && len(mask) == net.IPv4len {
+		n.addr, n.v4 = ip4, true
+	} else {
+		n.addr = ip.To16()
+	}
+	return n
+}
+
+func (n *addrNode) compile(c *bpfCompiler, onTrue, onFalse bpfLabel) {
+	if !n.v4 {
+		isIPv6 := c.newLabel()
+		c.jumpIfEtherType(etherTypeIPv6, isIPv6, onFalse)
+		c.place(isIPv6)
+		c.jumpIfAddr(n.src, n.dst, 8, 24, n.addr, n.mask, onTrue, onFalse)
+		return
+	}
+
+	isIPv4, notIPv4, isARP := c.newLabel(), c.newLabel(), c.newLabel()
+	c.jumpIfEtherType(etherTypeIPv4, isIPv4, notIPv4)
+	c.place(notIPv4)
+	c.jumpIf(bpf.JumpEqual, etherTypeARP, isARP, onFalse)
+	c.place(isIPv4)
+	c.jumpIfAddr(n.src, n.dst, 12, 16, n.addr, n.mask, onTrue, onFalse)
+	c.place(isARP)
+	c.jumpIfAddr(n.src, n.dst, 14, 24, n.addr, n.mask, onTrue, onFalse)
+}
+
+// portNode matches the source or destination port of TCP and UDP packets
+// against an inclusive range.
+type portNode struct {
+	src, dst     bool
+	lower, upper int
+}
+
+func (n *portNode) compile(c *bpfCompiler, onTrue, onFalse bpfLabel) {
+	isIPv4, notIPv4, isIPv6 := c.newLabel(), c.newLabel(), c.newLabel()
+	c.jumpIfEtherType(etherTypeIPv4, isIPv4, notIPv4)
+	c.place(notIPv4)
+	c.jumpIf(bpf.JumpEqual, etherTypeIPv6, isIPv6, onFalse)
+
+	// Only the first fragment of an IPv4 datagram contains the transport
+	// header, which follows a header of variable length.
+	c.place(isIPv4)
+	notTCP4, isTransport4, firstFragment, ports := c.newLabel(), c.newLabel(), c.newLabel(), c.newLabel()
+	c.emit(
+		bpf.LoadScratch{Dst: bpf.RegX, N: memNetOffset},
+		bpf.LoadIndirect{Off: 9, Size: 1},
+	)
+	c.jumpIf(bpf.JumpEqual, ipProtoTCP, isTransport4, notTCP4)
+	c.place(notTCP4)
+	c.jumpIf(bpf.JumpEqual, ipProtoUDP, isTransport4, onFalse)
+	c.place(isTransport4)
+	c.emit(bpf.LoadIndirect{Off: 6, Size: 2})
+	c.jumpIf(bpf.JumpBitsSet, 0x1fff, onFalse, firstFragment)
+	c.place(firstFragment)
+	c.emit(
+		bpf.LoadIndirect{Off: 0, Size: 1},
+		bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: 0x0f},
+		bpf.ALUOpConstant{Op: bpf.ALUOpShiftLeft, Val: 2},
+		bpf.ALUOpX{Op: bpf.ALUOpAdd},
+		bpf.TAX{},
+	)
+	c.jump(ports)
+
+	c.place(isIPv6)
+	notTCP6, isTransport6 := c.newLabel(), c.newLabel()
+	c.emit(
+		bpf.LoadScratch{Dst: bpf.RegX, N: memNetOffset},
+		bpf.LoadIndirect{Off: 6, Size: 1},
+	)
+	c.jumpIf(bpf.JumpEqual, ipProtoTCP, isTransport6, notTCP6)
+	c.place(notTCP6)
+	c.jumpIf(bpf.JumpEqual, ipProtoUDP, isTransport6, onFalse)
+	c.place(isTransport6)
+	c.emit(
+		bpf.TXA{},
+		bpf.ALUOpConstant{Op: bpf.ALUOpAdd, Val: 40},
+		bpf.TAX{},
+	)
+
+	// The X register holds the offset of the transport header.
+	c.place(ports)
+	if n.src {
+		tryDst := onFalse
+		if n.dst {
+			tryDst = c.newLabel()
+		}
+		c.emit(bpf.LoadIndirect{Off: 0, Size: 2})
+		c.jumpIfInRange(uint32(n.lower), uint32(n.upper), onTrue, tryDst)
+		if !n.dst {
+			return
+		}
+		c.place(tryDst)
+	}
+	c.emit(bpf.LoadIndirect{Off: 2, Size: 2})
+	c.jumpIfInRange(uint32(n.lower), uint32(n.upper), onTrue, onFalse)
+}
+
+//------------------------------------------------------------------------------
+
+// bpfLabel identifies a position within a program that is being compiled,
+// which may be the target of jumps before it has been placed.
+type bpfLabel int
+
+// bpfOp is either an instruction, an unconditional jump to a label, or a
+// conditional jump to one of two labels.
+type bpfOp struct {
+	ins bpf.Instruction
+
+	isJump          bool
+	hasCond         bool
+	cond            bpf.JumpTest
+	val             uint32
+	onTrue, onFalse bpfLabel
+
+	// long is set when the targets of a conditional jump are too far away to
+	// fit within the skip fields of the instruction, in which case it is
+	// followed by unconditional jumps to each target.
+	long bool
+}
+
+func (o bpfOp) size() int {
+	if o.hasCond && o.long {
+		return 3
+	}
+	return 1
+}
+
+// bpfCompiler accumulates the instructions of a BPF program, where jumps refer
+// to labels that are resolved when the program is assembled.
+type bpfCompiler struct {
+	ops    []bpfOp
+	labels []int
+}
+
+func (c *bpfCompiler) newLabel() bpfLabel {
+	c.labels = append(c.labels, -1)
+	return bpfLabel(len(c.labels) - 1)
+}
+
+// place sets the position of a label to that of the next instruction.
+func (c *bpfCompiler) place(l bpfLabel) {
+	c.labels[l] = len(c.ops)
+}
+
+func (c *bpfCompiler) emit(ins ...bpf.Instruction) {
+	for _, i := range ins {
+		c.ops = append(c.ops, bpfOp{ins: i})
+	}
+}
+
+func (c *bpfCompiler) jump(l bpfLabel) {
+	c.ops = append(c.ops, bpfOp{isJump: true, onTrue: l})
+}
+
+// jumpIf jumps to onTrue when the A register passes a test against a value,
+// and to onFalse when it does not.
+func (c *bpfCompiler) jumpIf(cond bpf.JumpTest, val uint32, onTrue, onFalse bpfLabel) {
+	c.ops = append(c.ops, bpfOp{
+		isJump:  true,
+		hasCond: true,
+		cond:    cond,
+		val:     val,
+		onTrue:  onTrue,
+		onFalse: onFalse,
+	})
+}
+
+func (c *bpfCompiler) jumpIfInRange(lower, upper uint32, onTrue, onFalse bpfLabel) {
+	if lower == upper {
+		c.jumpIf(bpf.JumpEqual, lower, onTrue, onFalse)
+		return
+	}
+	aboveLower := c.newLabel()
+	c.jumpIf(bpf.JumpGreaterOrEqual, lower, aboveLower, onFalse)
+	c.place(aboveLower)
+	c.jumpIf(bpf.JumpGreaterThan, upper, onFalse, onTrue)
+}
+
+func (c *bpfCompiler) jumpIfEtherType(etherType uint32, onTrue, onFalse bpfLabel) {
+	c.emit(bpf.LoadScratch{Dst: bpf.RegA, N: memEtherType})
+	c.jumpIf(bpf.JumpEqual, etherType, onTrue, onFalse)
+}
+
+// jumpIfIPProto jumps to onTrue when a packet is an IPv4 and/or IPv6 packet
+// carrying a protocol. The protocol of an IPv6 packet is found either in its
+// header or in a fragment extension header that immediately follows it.
+func (c *bpfCompiler) jumpIfIPProto(proto uint32, v4, v6 bool, onTrue, onFalse bpfLabel) {
+	isIPv4, isIPv6 := c.newLabel(), c.newLabel()
+	c.emit(bpf.LoadScratch{Dst: bpf.RegA, N: memEtherType})
+	switch {
+	case v4 && v6:
+		notIPv4 := c.newLabel()
+		c.jumpIf(bpf.JumpEqual, etherTypeIPv4, isIPv4, notIPv4)
+		c.place(notIPv4)
+		c.jumpIf(bpf.JumpEqual, etherTypeIPv6, isIPv6, onFalse)
+	case v4:
+		c.jumpIf(bpf.JumpEqual, etherTypeIPv4, isIPv4, onFalse)
+	default:
+		c.jumpIf(bpf.JumpEqual, etherTypeIPv6, isIPv6, onFalse)
+	}
+
+	if v4 {
+		c.place(isIPv4)
+		c.emit(
+			bpf.LoadScratch{Dst: bpf.RegX, N: memNetOffset},
+			bpf.LoadIndirect{Off: 9, Size: 1},
+		)
+		c.jumpIf(bpf.JumpEqual, proto, onTrue, onFalse)
+	}
+	if v6 {
+		c.place(isIPv6)
+		notProto, isFragment := c.newLabel(), c.newLabel()
+		c.emit(
+			bpf.LoadScratch{Dst: bpf.RegX, N: memNetOffset},
+			bpf.LoadIndirect{Off: 6, Size: 1},
+		)
+		c.jumpIf(bpf.JumpEqual, proto, onTrue, notProto)
+		c.place(notProto)
+		c.jumpIf(bpf.JumpEqual, 44, isFragment, onFalse)
+		c.place(isFragment)
+		c.emit(bpf.LoadIndirect{Off: 40, Size: 1})
+		c.jumpIf(bpf.JumpEqual, proto, onTrue, onFalse)
+	}
+}
+
+// jumpIfAddr jumps to onTrue when the address at either of two offsets from
+// the network header matches an address under a mask.
+func (c *bpfCompiler) jumpIfAddr(src, dst bool, srcOff, dstOff uint32, addr, mask []byte, onTrue, onFalse bpfLabel) {
+	c.emit(bpf.LoadScratch{Dst: bpf.RegX, N: memNetOffset})
+	if src {
+		tryDst := onFalse
+		if dst {
+			tryDst = c.newLabel()
+		}
+		c.jumpIfMasked(srcOff, addr, mask, onTrue, tryDst)
+		if !dst {
+			return
+		}
+		c.place(tryDst)
+	}
+	c.jumpIfMasked(dstOff, addr, mask, onTrue, onFalse)
+}
+
+// jumpIfMasked compares the words at an offset from the X register against an
+// address one word at a time, skipping words that are entirely masked out.
+func (c *bpfCompiler) jumpIfMasked(off uint32, addr, mask []byte, onTrue, onFalse bpfLabel) {
+	var words []int
+	for i := 0; i < len(addr); i += 4 {
+		if binary.BigEndian.Uint32(mask[i:]) != 0 {
+			words = append(words, i)
+		}
+	}
+	if len(words) == 0 {
+		c.jump(onTrue)
+		return
+	}
+	for j, i := range words {
+		m := binary.BigEndian.Uint32(mask[i:])
+		c.emit(bpf.LoadIndirect{Off: off + uint32(i), Size: 4})
+		if m != 0xffffffff {
+			c.emit(bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: m})
+		}
+		next := onTrue
+		if j < len(words)-1 {
+			next = c.newLabel()
+		}
+		c.jumpIf(bpf.JumpEqual, binary.BigEndian.Uint32(addr[i:])&m, next, onFalse)
+		if next != onTrue {
+			c.place(next)
+		}
+	}
+}
+
+// prologue emits instructions that populate the scratch memory slots from the
+// link layer header of a packet.
+func (c *bpfCompiler) prologue(linkType uint32) {
+	c.emit(
+		bpf.LoadConstant{Dst: bpf.RegA, Val: 0},
+		bpf.StoreScratch{Src: bpf.RegA, N: memNetOffset},
+		bpf.StoreScratch{Src: bpf.RegA, N: memEtherType},
+		bpf.StoreScratch{Src: bpf.RegA, N: memHasVLAN},
+		bpf.StoreScratch{Src: bpf.RegA, N: memVLAN},
+	)
+
+	switch linkType {
+	case linkTypeEthernet:
+		// Up to two VLAN tags are skipped, where the ID of the outer tag is
+		// the one that is matched.
+		done := c.newLabel()
+		c.emit(
+			bpf.LoadConstant{Dst: bpf.RegA, Val: 14},
+			bpf.StoreScratch{Src: bpf.RegA, N: memNetOffset},
+			bpf.LoadAbsolute{Off: 12, Size: 2},
+			bpf.StoreScratch{Src: bpf.RegA, N: memEtherType},
+		)
+		c.jumpIfVLANTag(done)
+		c.emit(
+			bpf.LoadAbsolute{Off: 14, Size: 2},
+			bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: 0x0fff},
+			bpf.StoreScratch{Src: bpf.RegA, N: memVLAN},
+			bpf.LoadConstant{Dst: bpf.RegA, Val: 1},
+			bpf.StoreScratch{Src: bpf.RegA, N: memHasVLAN},
+			bpf.LoadConstant{Dst: bpf.RegA, Val: 18},
+			bpf.StoreScratch{Src: bpf.RegA, N: memNetOffset},
+			bpf.LoadAbsolute{Off: 16, Size: 2},
+			bpf.StoreScratch{Src: bpf.RegA, N: memEtherType},
+		)
+		c.jumpIfVLANTag(done)
+		c.emit(
+			bpf.LoadConstant{Dst: bpf.RegA, Val: 22},
+			bpf.StoreScratch{Src: bpf.RegA, N: memNetOffset},
+			bpf.LoadAbsolute{Off: 20, Size: 2},
+			bpf.StoreScratch{Src: bpf.RegA, N: memEtherType},
+		)
+		c.place(done)
+	case linkTypeLinuxSLL:
+		c.emit(
+			bpf.LoadConstant{Dst: bpf.RegA, Val: 16},
+			bpf.StoreScratch{Src: bpf.RegA, N: memNetOffset},
+			bpf.LoadAbsolute{Off: 14, Size: 2},
+			bpf.StoreScratch{Src: bpf.RegA, N: memEtherType},
+		)
+	case linkTypeRaw, linkTypeIPv4, linkTypeIPv6:
+		// The version of the IP header determines the type of the packet.
+		done, notIPv4, isIPv4, isIPv6 := c.newLabel(), c.newLabel(), c.newLabel(), c.newLabel()
+		c.emit(
+			bpf.LoadAbsolute{Off: 0, Size: 1},
+			bpf.ALUOpConstant{Op: bpf.ALUOpShiftRight, Val: 4},
+		)
+		c.jumpIf(bpf.JumpEqual, 4, isIPv4, notIPv4)
+		c.place(notIPv4)
+		c.jumpIf(bpf.JumpEqual, 6, isIPv6, done)
+		c.place(isIPv4)
+		c.emit(
+			bpf.LoadConstant{Dst: bpf.RegA, Val: etherTypeIPv4},
+			bpf.StoreScratch{Src: bpf.RegA, N: memEtherType},
+		)
+		c.jump(done)
+		c.place(isIPv6)
+		c.emit(
+			bpf.LoadConstant{Dst: bpf.RegA, Val: etherTypeIPv6},
+			bpf.StoreScratch{Src: bpf.RegA, N: memEtherType},
+		)
+		c.place(done)
+	}
+}
+
+// jumpIfVLANTag continues to the next instruction when the A register holds
+// the EtherType of a VLAN tag, and jumps to done otherwise.
+func (c *bpfCompiler) jumpIfVLANTag(done bpfLabel) {
+	isTag, notVLAN := c.newLabel(), c.newLabel()
+	c.jumpIf(bpf.JumpEqual, etherTypeVLAN, isTag, notVLAN)
+	c.place(notVLAN)
+	c.jumpIf(bpf.JumpEqual, etherTypeQinQ, isTag, done)
+	c.place(isTag)
+}
+
+// assemble resolves the labels of jumps into relative offsets, using the
+// short form of conditional jumps wherever their targets are in reach.
+func (c *bpfCompiler) assemble() ([]bpf.Instruction, error) {
+	for i, pos := range c.labels {
+		if pos < 0 {
+			return nil, fmt.Errorf("label %v was never placed", i)
+		}
+	}
+
+	positions := make([]int, len(c.ops)+1)
+	for {
+		n := 0
+		for i, op := range c.ops {
+			positions[i] = n
+			n += op.size()
+		}
+		positions[len(c.ops)] = n
+		if n > bpfMaxInstructions {
+			return nil, errors.New("filter is too complex")
+		}
+
+		widened := false
+		for i, op := range c.ops {
+			if !op.hasCond || op.long {
+				continue
+			}
+			next := positions[i] + 1
+			if positions[c.labels[op.onTrue]]-next > 255 || positions[c.labels[op.onFalse]]-next > 255 {
+				c.ops[i].long = true
+				widened = true
+			}
+		}
+		if !widened {
+			break
+		}
+	}
+
+	prog := make([]bpf.Instruction, 0, positions[len(c.ops)])
+	for i, op := range c.ops {
+		if !op.isJump {
+			prog = append(prog, op.ins)
+			continue
+		}
+
+		next := positions[i] + op.size()
+		skipTrue := positions[c.labels[op.onTrue]] - next
+		if !op.hasCond {
+			if skipTrue < 0 {
+				return nil, errors.New("backward jump in filter program")
+			}
+			prog = append(prog, bpf.Jump{Skip: uint32(skipTrue)})
+			continue
+		}
+
+		skipFalse := positions[c.labels[op.onFalse]] - next
+		if skipTrue < 0 || skipFalse < 0 {
+			return nil, errors.New("backward jump in filter program")
+		}
+		if !op.long {
+			prog = append(prog, bpf.JumpIf{
+				Cond:      op.cond,
+				Val:       op.val,
+				SkipTrue:  uint8(skipTrue),
+				SkipFalse: uint8(skipFalse),
+			})
+			continue
+		}
+		prog = append(prog,
+			bpf.JumpIf{Cond: op.cond, Val: op.val, SkipTrue: 0, SkipFalse: 1},
+			bpf.Jump{Skip: uint32(skipTrue + 1)},
+			bpf.Jump{Skip: uint32(skipFalse)},
+		)
+	}
+	return prog, nil
+}
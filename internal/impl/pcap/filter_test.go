@@ -0,0 +1,130 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+)
+
+func TestFilter(t *testing.T) {
+	dns := testPacket{
+		srcIP: "10.0.0.1", dstIP: "8.8.8.8", proto: ipProtoUDP,
+		srcPort: 40000, dstPort: 53, payload: 34, vlan: 12,
+	}.frame()
+	https := testPacket{
+		srcIP: "fd00::1", dstIP: "fd00::2", proto: ipProtoTCP,
+		srcPort: 51234, dstPort: 443, payload: 1426,
+	}.frame()
+
+	arp := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02, 0, 0, 0, 0, 0x01}
+	arp = binary.BigEndian.AppendUint16(arp, etherTypeARP)
+	arp = append(arp, 0, 1, 0x08, 0, 6, 4, 0, 1)
+	arp = append(arp, 0x02, 0, 0, 0, 0, 0x01, 10, 0, 0, 1)
+	arp = append(arp, 0, 0, 0, 0, 0, 0, 10, 0, 0, 254)
+
+	var manyPorts []string
+	for i := 1; i <= 60; i++ {
+		manyPorts = append(manyPorts, fmt.Sprintf("port %v", i))
+	}
+
+	tests := []struct {
+		filter  string
+		matches []bool
+	}{
+		{filter: "", matches: []bool{true, true, true}},
+		{filter: "udp", matches: []bool{true, false, false}},
+		{filter: "ip6 and tcp", matches: []bool{false, true, false}},
+		{filter: "arp", matches: []bool{false, false, true}},
+		{filter: "port 53", matches: []bool{true, false, false}},
+		{filter: "src port 53", matches: []bool{false, false, false}},
+		{filter: "tcp dst port 443", matches: []bool{false, true, false}},
+		{filter: "portrange 400-500", matches: []bool{false, true, false}},
+		{filter: "host 10.0.0.1", matches: []bool{true, false, true}},
+		{filter: "dst host 10.0.0.1", matches: []bool{false, false, false}},
+		{filter: "host fd00::2", matches: []bool{false, true, false}},
+		{filter: "net fd00::/8", matches: []bool{false, true, false}},
+		{filter: "dst net 10.0.0.0/24", matches: []bool{false, false, true}},
+		{filter: "not net 10.0.0.0/8", matches: []bool{false, true, false}},
+		{filter: "!(udp || arp)", matches: []bool{false, true, false}},
+		{filter: "udp and (port 53 or port 5353) && vlan 12", matches: []bool{true, false, false}},
+		{filter: "vlan", matches: []bool{true, false, false}},
+		{filter: "vlan 13", matches: []bool{false, false, false}},
+		{filter: "greater 100", matches: []bool{false, true, false}},
+		{filter: "less 80", matches: []bool{true, false, true}},
+		{filter: "tcp or udp and port 53", matches: []bool{true, true, false}},
+		{filter: strings.Join(manyPorts, " or ") + " or port 53", matches: []bool{true, false, false}},
+	}
+
+	for _, test := range tests {
+		n, err := parseFilter(test.filter)
+		require.NoError(t, err, test.filter)
+
+		prog, err := compileFilter(n, linkTypeEthernet)
+		require.NoError(t, err, test.filter)
+
+		_, err = bpf.Assemble(prog)
+		require.NoError(t, err, test.filter)
+
+		vm, err := bpf.NewVM(prog)
+		require.NoError(t, err, test.filter)
+
+		var matches []bool
+		for _, frame := range [][]byte{dns, https, arp} {
+			n, err := vm.Run(frame)
+			require.NoError(t, err, test.filter)
+			matches = append(matches, n > 0)
+		}
+		assert.Equal(t, test.matches, matches, test.filter)
+	}
+}
+
+func TestFilterRawLink(t *testing.T) {
+	frame := testPacket{
+		srcIP: "fd00::1", dstIP: "fd00::2", proto: ipProtoTCP,
+		srcPort: 51234, dstPort: 443,
+	}.frame()
+
+	for filter, matches := range map[string]bool{
+		"ip6 and tcp port 443": true,
+		"ip":                   false,
+		"udp":                  false,
+	} {
+		n, err := parseFilter(filter)
+		require.NoError(t, err, filter)
+
+		prog, err := compileFilter(n, linkTypeRaw)
+		require.NoError(t, err, filter)
+
+		vm, err := bpf.NewVM(prog)
+		require.NoError(t, err, filter)
+
+		accepted, err := vm.Run(frame[14:])
+		require.NoError(t, err, filter)
+		assert.Equal(t, matches, accepted > 0, filter)
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	for filter, errContains := range map[string]string{
+		"port":              "unexpected end of filter",
+		"port nope":         "expected a port number",
+		"host nope":         "expected an IP address",
+		"net 10.0.0.0":      "expected a CIDR",
+		"portrange 10":      "expected a port range",
+		"(tcp or udp":       "expected closing parenthesis",
+		"tcp udp":           "unexpected token 'udp'",
+		"ether host foo":    "unrecognised filter primitive 'ether'",
+		"less some":         "expected a length",
+		"tcp and and udp":   "unrecognised filter primitive 'and'",
+		"src nope 10.0.0.1": "unrecognised filter primitive 'nope'",
+	} {
+		_, err := parseFilter(filter)
+		require.Error(t, err, filter)
+		assert.Contains(t, err.Error(), errContains, filter)
+	}
+}
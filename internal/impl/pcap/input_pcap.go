@@ -0,0 +1,548 @@
+package pcap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	piFieldInterface    = "interface"
+	piFieldFile         = "file"
+	piFieldFilter       = "filter"
+	piFieldSnapLen      = "snapshot_length"
+	piFieldPromiscuous  = "promiscuous"
+	piFieldSummary      = "summary"
+	piFieldFlowInterval = "flow_interval"
+)
+
+func pcapInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Network").
+		Version("4.24.0").
+		Summary("Captures packets from a network interface, or reads them from a pcap file, and emits summaries of them as JSON.").
+		Description(`
+Packets are either captured live from a network interface, which is only supported on Linux and requires the `+"`CAP_NET_RAW`"+` capability, or read from a file in the classic pcap format such as those written by `+"`tcpdump -w`"+`, in which case the input shuts down once the file is exhausted.
+
+The link, network and transport layers of each packet are decoded, and the input emits either a summary of each packet or summaries of the flows observed within an interval, as determined by the field `+"`summary`"+`. The contents of packets beyond their transport headers are not emitted.
+
+### Packet Summaries
+
+Each packet is emitted as an object containing the fields that could be decoded from it, such as:
+
+`+"```json"+`
+{
+  "timestamp": "2023-11-14T22:13:20.000512Z",
+  "length": 74,
+  "captured_length": 74,
+  "src_mac": "02:42:ac:11:00:02",
+  "dst_mac": "02:42:ac:11:00:03",
+  "ether_type": "0x0800",
+  "ip_version": 4,
+  "src_ip": "172.17.0.2",
+  "dst_ip": "172.17.0.3",
+  "ttl": 64,
+  "protocol": "tcp",
+  "src_port": 51234,
+  "dst_port": 443,
+  "tcp_flags": [ "SYN" ],
+  "tcp_seq": 2864434397,
+  "tcp_ack": 0,
+  "tcp_window": 64240,
+  "payload_length": 0
+}
+`+"```"+`
+
+### Flow Summaries
+
+When `+"`summary`"+` is `+"`flows`"+` packets are grouped by their source and destination addresses, protocol and ports, and at the end of each `+"`flow_interval`"+` the flows observed within it are emitted as objects such as:
+
+`+"```json"+`
+{
+  "start": "2023-11-14T22:13:20.000512Z",
+  "end": "2023-11-14T22:13:24.815301Z",
+  "src_ip": "172.17.0.2",
+  "dst_ip": "172.17.0.3",
+  "protocol": "tcp",
+  "src_port": 51234,
+  "dst_port": 443,
+  "packets": 12,
+  "bytes": 4821,
+  "tcp_flags": [ "SYN", "PSH", "ACK" ]
+}
+`+"```"+`
+
+### Filters
+
+Packets can be filtered with an expression written in a subset of the [pcap-filter](https://www.tcpdump.org/manpages/pcap-filter.7.html) syntax, supporting the primitives `+"`ip`"+`, `+"`ip6`"+`, `+"`arp`"+`, `+"`tcp`"+`, `+"`udp`"+`, `+"`icmp`"+`, `+"`icmp6`"+`, `+"`vlan [id]`"+`, `+"`less <length>`"+` and `+"`greater <length>`"+`, as well as `+"`host <ip>`"+`, `+"`net <cidr>`"+`, `+"`port <port>`"+` and `+"`portrange <low>-<high>`"+`, which can be qualified by a direction (`+"`src`"+` or `+"`dst`"+`) and a protocol, e.g. `+"`tcp dst port 443`"+`. Primitives can be combined with `+"`and`"+` (`+"`&&`"+`), `+"`or`"+` (`+"`||`"+`), `+"`not`"+` (`+"`!`"+`) and parentheses. Filters are compiled to [BPF](https://www.kernel.org/doc/html/latest/networking/filter.html) programs, which are attached to the capture socket of an interface so that packets are dropped by the kernel before they are copied, and are run against each packet read from a file.`).
+		Fields(
+			service.NewStringField(piFieldInterface).
+				Description("The name of a network interface to capture packets from. Either this field or `file` must be set.").
+				Default("").
+				Example("eth0"),
+			service.NewStringField(piFieldFile).
+				Description("The path of a pcap file to read packets from. Either this field or `interface` must be set.").
+				Default("").
+				Example("./capture.pcap"),
+			service.NewStringField(piFieldFilter).
+				Description("An optional filter expression, where only packets that match it are emitted.").
+				Default("").
+				Example("tcp port 443").
+				Example("udp and (port 53 or port 5353)").
+				Example("not net 10.0.0.0/8"),
+			service.NewIntField(piFieldSnapLen).
+				Description("The maximum number of bytes captured from each packet of a network interface.").
+				Default(65535).
+				Advanced(),
+			service.NewBoolField(piFieldPromiscuous).
+				Description("Whether to capture packets of a network interface in promiscuous mode, where packets addressed to other hosts are also captured.").
+				Default(false).
+				Advanced(),
+			service.NewStringAnnotatedEnumField(piFieldSummary, map[string]string{
+				"packets": "Emit a summary of each packet.",
+				"flows":   "Emit a summary of each flow observed within each `flow_interval`.",
+			}).
+				Description("The summaries to emit.").
+				Default("packets"),
+			service.NewDurationField(piFieldFlowInterval).
+				Description("The interval at which flow summaries are emitted when `summary` is `flows`. When reading a file the interval is measured by the timestamps of its packets.").
+				Default("10s"),
+		).
+		LintRule(`root = if this.interface.or("") == "" && this.file.or("") == "" {
+  "either the field interface or file must be set"
+} else if this.interface.or("") != "" && this.file.or("") != "" {
+  "the fields interface and file cannot both be set"
+}`).
+		Example(
+			"DNS Telemetry",
+			"Here we capture the DNS queries and responses of a host and emit a summary of each one.",
+			`
+input:
+  pcap:
+    interface: eth0
+    filter: udp port 53
+`,
+		).
+		Example(
+			"Flows From a Capture File",
+			"Here we summarise the flows of a capture file per minute, excluding those of the private network.",
+			`
+input:
+  pcap:
+    file: ./capture.pcap
+    filter: not net 10.0.0.0/8
+    summary: flows
+    flow_interval: 1m
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterInput(
+		"pcap", pcapInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			r, err := newPcapReader(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacks(r), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type pcapReader struct {
+	log *service.Logger
+
+	iface        string
+	file         string
+	filter       filterNode
+	snapLen      int
+	promiscuous  bool
+	flows        bool
+	flowInterval time.Duration
+
+	connMut   sync.Mutex
+	msgChan   chan *service.Message
+	errChan   chan error
+	cancelFn  func()
+	doneChan  chan struct{}
+	exhausted bool
+}
+
+func newPcapReader(conf *service.ParsedConfig, mgr *service.Resources) (*pcapReader, error) {
+	r := &pcapReader{log: mgr.Logger()}
+
+	var err error
+	if r.iface, err = conf.FieldString(piFieldInterface); err != nil {
+		return nil, err
+	}
+	if r.file, err = conf.FieldString(piFieldFile); err != nil {
+		return nil, err
+	}
+	if (r.iface == "") == (r.file == "") {
+		return nil, errors.New("exactly one of an interface or file must be set")
+	}
+
+	filterStr, err := conf.FieldString(piFieldFilter)
+	if err != nil {
+		return nil, err
+	}
+	if r.filter, err = parseFilter(filterStr); err != nil {
+		return nil, fmt.Errorf("failed to parse filter: %w", err)
+	}
+	if _, err = compileFilter(r.filter, linkTypeEthernet); err != nil {
+		return nil, fmt.Errorf("failed to compile filter: %w", err)
+	}
+
+	if r.snapLen, err = conf.FieldInt(piFieldSnapLen); err != nil {
+		return nil, err
+	}
+	if r.snapLen <= 0 {
+		return nil, errors.New("snapshot length must be greater than zero")
+	}
+	if r.promiscuous, err = conf.FieldBool(piFieldPromiscuous); err != nil {
+		return nil, err
+	}
+
+	summary, err := conf.FieldString(piFieldSummary)
+	if err != nil {
+		return nil, err
+	}
+	r.flows = summary == "flows"
+	if r.flowInterval, err = conf.FieldDuration(piFieldFlowInterval); err != nil {
+		return nil, err
+	}
+	if r.flowInterval <= 0 {
+		return nil, errors.New("flow interval must be greater than zero")
+	}
+	return r, nil
+}
+
+func (r *pcapReader) Connect(ctx context.Context) error {
+	r.connMut.Lock()
+	defer r.connMut.Unlock()
+	if r.msgChan != nil {
+		return nil
+	}
+	if r.exhausted {
+		return service.ErrEndOfInput
+	}
+
+	var src packetSource
+	var err error
+	if r.file != "" {
+		if src, err = openFileSource(r.file); err == nil {
+			src, err = newFilteredSource(src, r.filter)
+		}
+	} else {
+		src, err = openLiveSource(r.iface, r.snapLen, r.promiscuous, r.filter)
+	}
+	if err != nil {
+		return err
+	}
+
+	captureCtx, cancelFn := context.WithCancel(context.Background())
+	pktChan := make(chan *decodedPacket)
+	msgChan := make(chan *service.Message)
+	errChan := make(chan error, 1)
+	doneChan := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		r.capture(captureCtx, src, pktChan, errChan)
+	}()
+	go func() {
+		defer wg.Done()
+		r.summarise(captureCtx, pktChan, msgChan)
+	}()
+	go func() {
+		wg.Wait()
+		close(doneChan)
+	}()
+
+	r.msgChan = msgChan
+	r.errChan = errChan
+	r.cancelFn = cancelFn
+	r.doneChan = doneChan
+	return nil
+}
+
+// capture reads packets from a source until it is exhausted or the context is
+// cancelled, and sends them to a channel once decoded.
+func (r *pcapReader) capture(ctx context.Context, src packetSource, pktChan chan<- *decodedPacket, errChan chan<- error) {
+	defer func() {
+		close(pktChan)
+		if err := src.close(); err != nil {
+			r.log.Debugf("Failed to close packet source: %v", err)
+		}
+	}()
+
+	linkType := src.linkType()
+	for {
+		p, err := src.readPacket(ctx)
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, io.EOF) {
+				errChan <- err
+			}
+			return
+		}
+
+		d := decodePacket(linkType, p)
+		select {
+		case pktChan <- d:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// summarise converts decoded packets into messages, either one per packet or
+// one per flow observed within each interval.
+func (r *pcapReader) summarise(ctx context.Context, pktChan <-chan *decodedPacket, msgChan chan<- *service.Message) {
+	defer close(msgChan)
+
+	send := func(obj map[string]any) bool {
+		msg := service.NewMessage(nil)
+		msg.SetStructuredMut(obj)
+		select {
+		case msgChan <- msg:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	table := newFlowTable()
+	flush := func() bool {
+		for _, f := range table.flush() {
+			if !send(f) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Flows of a live capture are also flushed by the wall clock in order to
+	// emit them in the absence of new packets.
+	var tick <-chan time.Time
+	if r.flows && r.iface != "" {
+		ticker := time.NewTicker(r.flowInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var windowEnd time.Time
+	for {
+		select {
+		case d, open := <-pktChan:
+			if !open {
+				flush()
+				return
+			}
+			if !r.flows {
+				if !send(d.structured()) {
+					return
+				}
+				continue
+			}
+			if windowEnd.IsZero() {
+				windowEnd = d.timestamp.Add(r.flowInterval)
+			} else if !d.timestamp.Before(windowEnd) {
+				if !flush() {
+					return
+				}
+				windowEnd = d.timestamp.Add(r.flowInterval)
+			}
+			table.add(d)
+		case <-tick:
+			if !flush() {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *pcapReader) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	r.connMut.Lock()
+	msgChan, errChan := r.msgChan, r.errChan
+	r.connMut.Unlock()
+	if msgChan == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	var msg *service.Message
+	var open bool
+	select {
+	case msg, open = <-msgChan:
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+	if open {
+		return msg, func(ctx context.Context, err error) error {
+			return nil
+		}, nil
+	}
+
+	r.connMut.Lock()
+	r.cancelFn()
+	r.msgChan, r.errChan = nil, nil
+	r.connMut.Unlock()
+
+	select {
+	case err := <-errChan:
+		r.log.Errorf("Failed to capture packets: %v", err)
+		return nil, nil, service.ErrNotConnected
+	default:
+	}
+	if r.file != "" {
+		r.connMut.Lock()
+		r.exhausted = true
+		r.connMut.Unlock()
+		return nil, nil, service.ErrEndOfInput
+	}
+	return nil, nil, service.ErrNotConnected
+}
+
+func (r *pcapReader) Close(ctx context.Context) error {
+	r.connMut.Lock()
+	cancelFn, doneChan := r.cancelFn, r.doneChan
+	r.connMut.Unlock()
+	if cancelFn == nil {
+		return nil
+	}
+
+	cancelFn()
+	select {
+	case <-doneChan:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type flowKey struct {
+	srcIP, dstIP     string
+	protocol         string
+	srcPort, dstPort int
+}
+
+type flow struct {
+	key        flowKey
+	start, end time.Time
+	packets    int64
+	bytes      int64
+	tcpFlags   uint8
+	hasPorts   bool
+}
+
+// flowTable aggregates packets into unidirectional flows.
+type flowTable struct {
+	flows map[flowKey]*flow
+}
+
+func newFlowTable() *flowTable {
+	return &flowTable{flows: map[flowKey]*flow{}}
+}
+
+func (t *flowTable) add(d *decodedPacket) {
+	if d.srcIP == nil {
+		return
+	}
+
+	key := flowKey{
+		srcIP:    d.srcIP.String(),
+		dstIP:    d.dstIP.String(),
+		protocol: d.protocol(),
+	}
+	if d.hasPorts {
+		key.srcPort, key.dstPort = d.srcPort, d.dstPort
+	}
+
+	f, exists := t.flows[key]
+	if !exists {
+		f = &flow{key: key, start: d.timestamp, hasPorts: d.hasPorts}
+		t.flows[key] = f
+	}
+	if d.timestamp.Before(f.start) {
+		f.start = d.timestamp
+	}
+	if d.timestamp.After(f.end) {
+		f.end = d.timestamp
+	}
+	f.packets++
+	f.bytes += int64(d.length)
+	if d.ipProto == ipProtoTCP {
+		f.tcpFlags |= d.tcpFlags
+	}
+}
+
+// flush returns summaries of all flows in the order they started and resets
+// the table.
+func (t *flowTable) flush() []map[string]any {
+	flows := make([]*flow, 0, len(t.flows))
+	for _, f := range t.flows {
+		flows = append(flows, f)
+	}
+	t.flows = map[flowKey]*flow{}
+
+	sort.Slice(flows, func(i, j int) bool {
+		if !flows[i].start.Equal(flows[j].start) {
+			return flows[i].start.Before(flows[j].start)
+		}
+		ki, kj := flows[i].key, flows[j].key
+		if ki.srcIP != kj.srcIP {
+			return ki.srcIP < kj.srcIP
+		}
+		if ki.dstIP != kj.dstIP {
+			return ki.dstIP < kj.dstIP
+		}
+		if ki.protocol != kj.protocol {
+			return ki.protocol < kj.protocol
+		}
+		if ki.srcPort != kj.srcPort {
+			return ki.srcPort < kj.srcPort
+		}
+		return ki.dstPort < kj.dstPort
+	})
+
+	summaries := make([]map[string]any, len(flows))
+	for i, f := range flows {
+		obj := map[string]any{
+			"start":   f.start.UTC().Format(time.RFC3339Nano),
+			"end":     f.end.UTC().Format(time.RFC3339Nano),
+			"src_ip":  f.key.srcIP,
+			"dst_ip":  f.key.dstIP,
+			"packets": f.packets,
+			"bytes":   f.bytes,
+		}
+		if f.key.protocol != "" {
+			obj["protocol"] = f.key.protocol
+		}
+		if f.hasPorts {
+			obj["src_port"] = int64(f.key.srcPort)
+			obj["dst_port"] = int64(f.key.dstPort)
+		}
+		if f.key.protocol == "tcp" {
+			obj["tcp_flags"] = tcpFlagList(f.tcpFlags)
+		}
+		summaries[i] = obj
+	}
+	return summaries
+}
@@ -0,0 +1,282 @@
+package pcap
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+type testPacket struct {
+	ts               time.Time
+	srcIP, dstIP     string
+	proto            int
+	srcPort, dstPort int
+	tcpFlags         uint8
+	payload          int
+	vlan             int
+}
+
+// frame encodes a test packet as an Ethernet frame.
+func (p testPacket) frame() []byte {
+	var transport []byte
+	switch p.proto {
+	case ipProtoTCP:
+		transport = make([]byte, 20+p.payload)
+		binary.BigEndian.PutUint16(transport[0:2], uint16(p.srcPort))
+		binary.BigEndian.PutUint16(transport[2:4], uint16(p.dstPort))
+		binary.BigEndian.PutUint32(transport[4:8], 1000)
+		binary.BigEndian.PutUint32(transport[8:12], 2000)
+		transport[12] = 5 << 4
+		transport[13] = p.tcpFlags
+		binary.BigEndian.PutUint16(transport[14:16], 64240)
+	case ipProtoUDP:
+		transport = make([]byte, 8+p.payload)
+		binary.BigEndian.PutUint16(transport[0:2], uint16(p.srcPort))
+		binary.BigEndian.PutUint16(transport[2:4], uint16(p.dstPort))
+		binary.BigEndian.PutUint16(transport[4:6], uint16(len(transport)))
+	case ipProtoICMP, ipProtoICMPv6:
+		transport = make([]byte, 8+p.payload)
+		transport[0] = 8
+	}
+
+	var network []byte
+	etherType := uint16(etherTypeIPv4)
+	if ip := net.ParseIP(p.srcIP); ip.To4() != nil {
+		network = make([]byte, 20)
+		network[0] = 0x45
+		binary.BigEndian.PutUint16(network[2:4], uint16(20+len(transport)))
+		network[8] = 64
+		network[9] = byte(p.proto)
+		copy(network[12:16], ip.To4())
+		copy(network[16:20], net.ParseIP(p.dstIP).To4())
+	} else {
+		etherType = etherTypeIPv6
+		network = make([]byte, 40)
+		network[0] = 0x60
+		binary.BigEndian.PutUint16(network[4:6], uint16(len(transport)))
+		network[6] = byte(p.proto)
+		network[7] = 32
+		copy(network[8:24], ip.To16())
+		copy(network[24:40], net.ParseIP(p.dstIP).To16())
+	}
+
+	frame := []byte{0x02, 0, 0, 0, 0, 0x02, 0x02, 0, 0, 0, 0, 0x01}
+	if p.vlan != 0 {
+		frame = binary.BigEndian.AppendUint16(frame, etherTypeVLAN)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(p.vlan))
+	}
+	frame = binary.BigEndian.AppendUint16(frame, etherType)
+	frame = append(frame, network...)
+	return append(frame, transport...)
+}
+
+func writePcapFile(t *testing.T, packets ...testPacket) string {
+	t.Helper()
+
+	buf := binary.LittleEndian.AppendUint32(nil, pcapMagicNanos)
+	buf = binary.LittleEndian.AppendUint16(buf, 2)
+	buf = binary.LittleEndian.AppendUint16(buf, 4)
+	buf = append(buf, make([]byte, 8)...)
+	buf = binary.LittleEndian.AppendUint32(buf, 65535)
+	buf = binary.LittleEndian.AppendUint32(buf, linkTypeEthernet)
+	for _, p := range packets {
+		frame := p.frame()
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(p.ts.Unix()))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(p.ts.Nanosecond()))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(frame)))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(frame)))
+		buf = append(buf, frame...)
+	}
+
+	path := filepath.Join(t.TempDir(), "capture.pcap")
+	require.NoError(t, os.WriteFile(path, buf, 0o644))
+	return path
+}
+
+func readAll(t *testing.T, conf string) []any {
+	t.Helper()
+
+	pConf, err := pcapInputSpec().ParseYAML(conf, nil)
+	require.NoError(t, err)
+
+	r, err := newPcapReader(pConf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, r.Connect(ctx))
+
+	var results []any
+	for {
+		msg, ackFn, err := r.Read(ctx)
+		if errors.Is(err, service.ErrEndOfInput) {
+			break
+		}
+		require.NoError(t, err)
+		require.NoError(t, ackFn(ctx, nil))
+
+		s, err := msg.AsStructured()
+		require.NoError(t, err)
+		results = append(results, s)
+	}
+	require.NoError(t, r.Close(ctx))
+	return results
+}
+
+func TestPcapInputPackets(t *testing.T) {
+	base := time.Date(2023, 11, 14, 22, 13, 20, 512000, time.UTC)
+	path := writePcapFile(t,
+		testPacket{ts: base, srcIP: "172.17.0.2", dstIP: "172.17.0.3", proto: ipProtoTCP, srcPort: 51234, dstPort: 443, tcpFlags: 0x02},
+		testPacket{ts: base.Add(time.Millisecond), srcIP: "172.17.0.2", dstIP: "8.8.8.8", proto: ipProtoUDP, srcPort: 40000, dstPort: 53, payload: 30, vlan: 12},
+		testPacket{ts: base.Add(time.Millisecond * 2), srcIP: "fd00::1", dstIP: "fd00::2", proto: ipProtoICMPv6},
+	)
+
+	assert.Equal(t, []any{
+		map[string]any{
+			"timestamp":       "2023-11-14T22:13:20.000512Z",
+			"length":          int64(54),
+			"captured_length": int64(54),
+			"src_mac":         "02:00:00:00:00:01",
+			"dst_mac":         "02:00:00:00:00:02",
+			"ether_type":      "0x0800",
+			"ip_version":      int64(4),
+			"src_ip":          "172.17.0.2",
+			"dst_ip":          "172.17.0.3",
+			"ttl":             int64(64),
+			"protocol":        "tcp",
+			"src_port":        int64(51234),
+			"dst_port":        int64(443),
+			"tcp_flags":       []any{"SYN"},
+			"tcp_seq":         int64(1000),
+			"tcp_ack":         int64(2000),
+			"tcp_window":      int64(64240),
+			"payload_length":  int64(0),
+		},
+		map[string]any{
+			"timestamp":       "2023-11-14T22:13:20.001512Z",
+			"length":          int64(76),
+			"captured_length": int64(76),
+			"src_mac":         "02:00:00:00:00:01",
+			"dst_mac":         "02:00:00:00:00:02",
+			"vlan":            int64(12),
+			"ether_type":      "0x0800",
+			"ip_version":      int64(4),
+			"src_ip":          "172.17.0.2",
+			"dst_ip":          "8.8.8.8",
+			"ttl":             int64(64),
+			"protocol":        "udp",
+			"src_port":        int64(40000),
+			"dst_port":        int64(53),
+			"payload_length":  int64(30),
+		},
+		map[string]any{
+			"timestamp":       "2023-11-14T22:13:20.002512Z",
+			"length":          int64(62),
+			"captured_length": int64(62),
+			"src_mac":         "02:00:00:00:00:01",
+			"dst_mac":         "02:00:00:00:00:02",
+			"ether_type":      "0x86dd",
+			"ip_version":      int64(6),
+			"src_ip":          "fd00::1",
+			"dst_ip":          "fd00::2",
+			"ttl":             int64(32),
+			"protocol":        "icmp6",
+			"icmp_type":       int64(8),
+			"icmp_code":       int64(0),
+			"payload_length":  int64(4),
+		},
+	}, readAll(t, `
+file: `+path+`
+`))
+
+	results := readAll(t, `
+file: `+path+`
+filter: udp dst port 53 and vlan 12
+`)
+	require.Len(t, results, 1)
+	assert.Equal(t, "8.8.8.8", results[0].(map[string]any)["dst_ip"])
+}
+
+func TestPcapInputFlows(t *testing.T) {
+	base := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	conn := func(offset time.Duration, flags uint8, payload int) testPacket {
+		return testPacket{ts: base.Add(offset), srcIP: "10.0.0.1", dstIP: "10.0.0.2", proto: ipProtoTCP, srcPort: 5000, dstPort: 80, tcpFlags: flags, payload: payload}
+	}
+	dns := func(offset time.Duration) testPacket {
+		return testPacket{ts: base.Add(offset), srcIP: "10.0.0.1", dstIP: "10.0.0.53", proto: ipProtoUDP, srcPort: 4000, dstPort: 53, payload: 10}
+	}
+
+	path := writePcapFile(t,
+		conn(0, 0x02, 0),
+		dns(time.Second),
+		conn(time.Second*2, 0x18, 100),
+		conn(time.Second*11, 0x11, 0),
+	)
+
+	assert.Equal(t, []any{
+		map[string]any{
+			"start":     "2023-11-14T22:13:20Z",
+			"end":       "2023-11-14T22:13:22Z",
+			"src_ip":    "10.0.0.1",
+			"dst_ip":    "10.0.0.2",
+			"protocol":  "tcp",
+			"src_port":  int64(5000),
+			"dst_port":  int64(80),
+			"packets":   int64(2),
+			"bytes":     int64(54 + 154),
+			"tcp_flags": []any{"SYN", "PSH", "ACK"},
+		},
+		map[string]any{
+			"start":    "2023-11-14T22:13:21Z",
+			"end":      "2023-11-14T22:13:21Z",
+			"src_ip":   "10.0.0.1",
+			"dst_ip":   "10.0.0.53",
+			"protocol": "udp",
+			"src_port": int64(4000),
+			"dst_port": int64(53),
+			"packets":  int64(1),
+			"bytes":    int64(52),
+		},
+		map[string]any{
+			"start":     "2023-11-14T22:13:31Z",
+			"end":       "2023-11-14T22:13:31Z",
+			"src_ip":    "10.0.0.1",
+			"dst_ip":    "10.0.0.2",
+			"protocol":  "tcp",
+			"src_port":  int64(5000),
+			"dst_port":  int64(80),
+			"packets":   int64(1),
+			"bytes":     int64(54),
+			"tcp_flags": []any{"FIN", "ACK"},
+		},
+	}, readAll(t, `
+file: `+path+`
+summary: flows
+flow_interval: 10s
+`))
+}
+
+func TestPcapInputConfigErrors(t *testing.T) {
+	for _, conf := range []string{
+		`filter: tcp`,
+		`{ file: a.pcap, interface: eth0 }`,
+		`{ file: a.pcap, filter: 'port nope' }`,
+	} {
+		pConf, err := pcapInputSpec().ParseYAML(conf, nil)
+		require.NoError(t, err)
+
+		_, err = newPcapReader(pConf, service.MockResources())
+		assert.Error(t, err, conf)
+	}
+}
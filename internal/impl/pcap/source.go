@@ -0,0 +1,145 @@
+package pcap
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/net/bpf"
+)
+
+// packetSource is a source of captured packets.
+type packetSource interface {
+	// linkType returns the link type of the packets of the source.
+	linkType() uint32
+
+	// readPacket blocks until a packet is captured, returning io.EOF when the
+	// source is exhausted, or the context error when it is cancelled.
+	readPacket(ctx context.Context) (packet, error)
+
+	close() error
+}
+
+// filteredSource drops the packets of a source that are rejected by a BPF
+// program, which is how filters are applied to sources that the kernel does
+// not filter.
+type filteredSource struct {
+	packetSource
+	vm *bpf.VM
+}
+
+// newFilteredSource wraps a source with a filter, closing the source when the
+// filter cannot be compiled for its link type.
+func newFilteredSource(src packetSource, filter filterNode) (packetSource, error) {
+	prog, err := compileFilter(filter, src.linkType())
+	if err != nil {
+		_ = src.close()
+		return nil, err
+	}
+	vm, err := bpf.NewVM(prog)
+	if err != nil {
+		_ = src.close()
+		return nil, err
+	}
+	return &filteredSource{packetSource: src, vm: vm}, nil
+}
+
+func (s *filteredSource) readPacket(ctx context.Context) (packet, error) {
+	for {
+		p, err := s.packetSource.readPacket(ctx)
+		if err != nil {
+			return p, err
+		}
+		if n, err := s.vm.Run(p.data); err == nil && n > 0 {
+			return p, nil
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	pcapMagicMicros = 0xa1b2c3d4
+	pcapMagicNanos  = 0xa1b23c4d
+)
+
+// fileSource reads packets from a file in the classic pcap format.
+type fileSource struct {
+	f         *os.File
+	r         *bufio.Reader
+	order     binary.ByteOrder
+	nanos     bool
+	link      uint32
+	headerBuf [16]byte
+}
+
+func openFileSource(path string) (*fileSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &fileSource{f: f, r: bufio.NewReader(f)}
+
+	var header [24]byte
+	if _, err := io.ReadFull(s.r, header[:]); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to read pcap file header: %w", err)
+	}
+	switch {
+	case binary.LittleEndian.Uint32(header[0:4]) == pcapMagicMicros:
+		s.order = binary.LittleEndian
+	case binary.LittleEndian.Uint32(header[0:4]) == pcapMagicNanos:
+		s.order, s.nanos = binary.LittleEndian, true
+	case binary.BigEndian.Uint32(header[0:4]) == pcapMagicMicros:
+		s.order = binary.BigEndian
+	case binary.BigEndian.Uint32(header[0:4]) == pcapMagicNanos:
+		s.order, s.nanos = binary.BigEndian, true
+	default:
+		_ = f.Close()
+		return nil, errors.New("file is not in the pcap format")
+	}
+	s.link = s.order.Uint32(header[20:24]) & 0x0fffffff
+	return s, nil
+}
+
+func (s *fileSource) linkType() uint32 {
+	return s.link
+}
+
+func (s *fileSource) readPacket(ctx context.Context) (packet, error) {
+	if _, err := io.ReadFull(s.r, s.headerBuf[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return packet{}, io.EOF
+		}
+		return packet{}, err
+	}
+
+	secs := int64(s.order.Uint32(s.headerBuf[0:4]))
+	frac := int64(s.order.Uint32(s.headerBuf[4:8]))
+	if !s.nanos {
+		frac *= int64(time.Microsecond)
+	}
+
+	p := packet{
+		ts:     time.Unix(secs, frac),
+		data:   make([]byte, s.order.Uint32(s.headerBuf[8:12])),
+		length: int(s.order.Uint32(s.headerBuf[12:16])),
+	}
+	if _, err := io.ReadFull(s.r, p.data); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return packet{}, io.EOF
+		}
+		return packet{}, err
+	}
+	return p, nil
+}
+
+func (s *fileSource) close() error {
+	return s.f.Close()
+}
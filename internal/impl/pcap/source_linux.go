@@ -0,0 +1,139 @@
+//go:build linux
+
+package pcap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+func htons(i uint16) uint16 {
+	return i<<8 | i>>8
+}
+
+// liveSource captures packets from a network interface with an AF_PACKET
+// socket, which has a filter compiled to BPF attached so that packets which do
+// not match it are dropped by the kernel.
+type liveSource struct {
+	fd   int
+	link uint32
+	buf  []byte
+}
+
+func openLiveSource(name string, snapLen int, promiscuous bool, filter filterNode) (packetSource, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// The socket is opened without a protocol so that it receives no packets
+	// until the filter has been attached and it is bound to the interface.
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open packet socket: %w", err)
+	}
+	s := &liveSource{
+		fd:   fd,
+		link: linkTypeEthernet,
+		buf:  make([]byte, snapLen),
+	}
+
+	// Interfaces without a hardware address other than the loopback, such as
+	// tunnels, deliver packets without a link layer header.
+	if len(iface.HardwareAddr) == 0 && iface.Flags&net.FlagLoopback == 0 {
+		s.link = linkTypeRaw
+	}
+
+	if err := attachFilter(fd, filter, s.link); err != nil {
+		_ = unix.Close(fd)
+		return nil, err
+	}
+
+	if err := unix.Bind(fd, &unix.SockaddrLinklayer{
+		Protocol: htons(unix.ETH_P_ALL),
+		Ifindex:  iface.Index,
+	}); err != nil {
+		_ = unix.Close(fd)
+		return nil, fmt.Errorf("failed to bind to interface: %w", err)
+	}
+	if promiscuous {
+		if err := unix.SetsockoptPacketMreq(fd, unix.SOL_PACKET, unix.PACKET_ADD_MEMBERSHIP, &unix.PacketMreq{
+			Ifindex: int32(iface.Index),
+			Type:    unix.PACKET_MR_PROMISC,
+		}); err != nil {
+			_ = unix.Close(fd)
+			return nil, fmt.Errorf("failed to enable promiscuous mode: %w", err)
+		}
+	}
+
+	// A receive timeout allows blocked reads to observe that their context
+	// has been cancelled.
+	tv := unix.NsecToTimeval(int64(time.Millisecond * 250))
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		_ = unix.Close(fd)
+		return nil, err
+	}
+	return s, nil
+}
+
+func attachFilter(fd int, filter filterNode, linkType uint32) error {
+	if filter == nil {
+		return nil
+	}
+	prog, err := compileFilter(filter, linkType)
+	if err != nil {
+		return err
+	}
+	raw, err := bpf.Assemble(prog)
+	if err != nil {
+		return err
+	}
+	filters := make([]unix.SockFilter, len(raw))
+	for i, ins := range raw {
+		filters[i] = unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K}
+	}
+	if err := unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, &unix.SockFprog{
+		Len:    uint16(len(filters)),
+		Filter: &filters[0],
+	}); err != nil {
+		return fmt.Errorf("failed to attach filter: %w", err)
+	}
+	return nil
+}
+
+func (s *liveSource) linkType() uint32 {
+	return s.link
+}
+
+func (s *liveSource) readPacket(ctx context.Context) (packet, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return packet{}, err
+		}
+		n, _, err := unix.Recvfrom(s.fd, s.buf, unix.MSG_TRUNC)
+		if err != nil {
+			if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+				continue
+			}
+			return packet{}, err
+		}
+
+		captured := n
+		if captured > len(s.buf) {
+			captured = len(s.buf)
+		}
+		data := make([]byte, captured)
+		copy(data, s.buf[:captured])
+		return packet{data: data, ts: time.Now(), length: n}, nil
+	}
+}
+
+func (s *liveSource) close() error {
+	return unix.Close(s.fd)
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package pcap
+
+import (
+	"errors"
+)
+
+func openLiveSource(name string, snapLen int, promiscuous bool, filter filterNode) (packetSource, error) {
+	return nil, errors.New("capturing from a network interface is only supported on Linux")
+}
@@ -0,0 +1,297 @@
+package pinecone
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	poFieldHost            = "host"
+	poFieldAPIKey          = "api_key"
+	poFieldNamespace       = "namespace"
+	poFieldID              = "id"
+	poFieldVectorMapping   = "vector_mapping"
+	poFieldMetadataMapping = "metadata_mapping"
+	poFieldMaxBatchSize    = "max_batch_size"
+	poFieldTimeout         = "timeout"
+	poFieldBatching        = "batching"
+	poFieldMaxInFlight     = "max_in_flight"
+)
+
+func pineconeOutputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Services").
+		Version("4.24.0").
+		Summary("Upserts vectors into a [Pinecone](https://www.pinecone.io/) index.").
+		Description(output.Description(true, true, `
+A vector is upserted for each message with an ID, a vector of numbers obtained with the `+"`vector_mapping`"+`, and optional metadata obtained with the `+"`metadata_mapping`"+`. Vectors are upserted by their ID, and therefore writing a vector with an ID that already exists within the namespace replaces it.
+
+Vectors of a batch are written with a single request, unless the batch is larger than `+"`max_batch_size`"+` in which case it is split into multiple requests. Pinecone recommends upserting batches of up to 100 vectors.
+
+This output pairs well with the `+"[`openai_embeddings` processor](/docs/components/processors/openai_embeddings)"+`, which generates embeddings from the contents of messages.`)).
+		Fields(
+			service.NewURLField(poFieldHost).
+				Description("The host of the index to write to, which can be found in the Pinecone console.").
+				Example("https://my-index-abc123.svc.us-east1-gcp.pinecone.io"),
+			service.NewStringField(poFieldAPIKey).
+				Description("The API key used to authenticate requests.").
+				Secret(),
+			service.NewStringField(poFieldNamespace).
+				Description("The namespace of the index to write vectors to.").
+				Default(""),
+			service.NewInterpolatedStringField(poFieldID).
+				Description("The ID of each vector.").
+				Example("${! this.id }").
+				Example("${! meta(\"kafka_key\") }"),
+			service.NewBloblangField(poFieldVectorMapping).
+				Description("A [Bloblang mapping](/docs/guides/bloblang/about) that results in an array of numbers representing the vector of each message.").
+				Example("root = this.embedding").
+				Example("root = this"),
+			service.NewBloblangField(poFieldMetadataMapping).
+				Description("An optional [Bloblang mapping](/docs/guides/bloblang/about) that results in an object of metadata to store with each vector.").
+				Example(`root = { "title": this.title, "url": this.url }`).
+				Optional(),
+			service.NewIntField(poFieldMaxBatchSize).
+				Description("The maximum number of vectors to upsert with each request, batches larger than this are split into multiple requests.").
+				Default(100).
+				Advanced(),
+			service.NewDurationField(poFieldTimeout).
+				Description("The maximum period to wait for each request to complete.").
+				Default("30s").
+				Advanced(),
+			service.NewOutputMaxInFlightField(),
+			service.NewBatchPolicyField(poFieldBatching),
+		).
+		Example(
+			"Index Documents",
+			"Here we generate embeddings of documents and upsert them into a Pinecone index along with their titles.",
+			`
+pipeline:
+  processors:
+    - branch:
+        request_map: 'root = this.body'
+        processors:
+          - openai_embeddings:
+              api_key: "${OPENAI_API_KEY}"
+              model: text-embedding-3-small
+        result_map: 'root.embedding = this'
+
+output:
+  pinecone:
+    host: "${PINECONE_INDEX_HOST}"
+    api_key: "${PINECONE_API_KEY}"
+    id: ${! this.id }
+    vector_mapping: root = this.embedding
+    metadata_mapping: root.title = this.title
+    batching:
+      count: 100
+      period: 1s
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchOutput(
+		"pinecone", pineconeOutputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, maxInFlight int, err error) {
+			if batchPolicy, err = conf.FieldBatchPolicy(poFieldBatching); err != nil {
+				return
+			}
+			if maxInFlight, err = conf.FieldMaxInFlight(); err != nil {
+				return
+			}
+			out, err = newPineconeOutputFromConfig(conf, mgr)
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type vector struct {
+	ID       string         `json:"id"`
+	Values   []float64      `json:"values"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+type upsertRequest struct {
+	Vectors   []vector `json:"vectors"`
+	Namespace string   `json:"namespace,omitempty"`
+}
+
+type pineconeOutput struct {
+	host      string
+	apiKey    string
+	namespace string
+
+	id              *service.InterpolatedString
+	vectorMapping   *bloblang.Executor
+	metadataMapping *bloblang.Executor
+	maxBatchSize    int
+
+	client *http.Client
+	log    *service.Logger
+}
+
+func newPineconeOutputFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*pineconeOutput, error) {
+	p := &pineconeOutput{log: mgr.Logger()}
+
+	var err error
+	if p.host, err = conf.FieldString(poFieldHost); err != nil {
+		return nil, err
+	}
+	p.host = strings.TrimSuffix(p.host, "/")
+	if !strings.Contains(p.host, "://") {
+		p.host = "https://" + p.host
+	}
+
+	if p.apiKey, err = conf.FieldString(poFieldAPIKey); err != nil {
+		return nil, err
+	}
+	if p.namespace, err = conf.FieldString(poFieldNamespace); err != nil {
+		return nil, err
+	}
+	if p.id, err = conf.FieldInterpolatedString(poFieldID); err != nil {
+		return nil, err
+	}
+	if p.vectorMapping, err = conf.FieldBloblang(poFieldVectorMapping); err != nil {
+		return nil, err
+	}
+	if conf.Contains(poFieldMetadataMapping) {
+		if p.metadataMapping, err = conf.FieldBloblang(poFieldMetadataMapping); err != nil {
+			return nil, err
+		}
+	}
+	if p.maxBatchSize, err = conf.FieldInt(poFieldMaxBatchSize); err != nil {
+		return nil, err
+	}
+	if p.maxBatchSize < 1 {
+		return nil, fmt.Errorf("%v must be greater than zero, got %v", poFieldMaxBatchSize, p.maxBatchSize)
+	}
+
+	timeout, err := conf.FieldDuration(poFieldTimeout)
+	if err != nil {
+		return nil, err
+	}
+	p.client = &http.Client{Timeout: timeout}
+	return p, nil
+}
+
+func (p *pineconeOutput) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (p *pineconeOutput) vector(batch service.MessageBatch, i int) (v vector, err error) {
+	if v.ID, err = batch.TryInterpolatedString(i, p.id); err != nil {
+		return v, fmt.Errorf("id interpolation error: %w", err)
+	}
+	if v.ID == "" {
+		return v, fmt.Errorf("id interpolation resulted in an empty string")
+	}
+
+	vecMsg, err := batch.BloblangQuery(i, p.vectorMapping)
+	if err != nil {
+		return v, fmt.Errorf("vector mapping failed: %w", err)
+	}
+	vecV, err := vecMsg.AsStructured()
+	if err != nil {
+		return v, fmt.Errorf("vector mapping returned non-structured result: %w", err)
+	}
+	vecArr, ok := vecV.([]any)
+	if !ok {
+		return v, fmt.Errorf("vector mapping returned non-array result: %T", vecV)
+	}
+	v.Values = make([]float64, len(vecArr))
+	for j, e := range vecArr {
+		if v.Values[j], err = query.IToFloat64(e); err != nil {
+			return v, fmt.Errorf("vector mapping returned non-numerical value at index %v: %w", j, err)
+		}
+	}
+
+	if p.metadataMapping == nil {
+		return v, nil
+	}
+	metaMsg, err := batch.BloblangQuery(i, p.metadataMapping)
+	if err != nil {
+		return v, fmt.Errorf("metadata mapping failed: %w", err)
+	}
+	metaV, err := metaMsg.AsStructured()
+	if err != nil {
+		return v, fmt.Errorf("metadata mapping returned non-structured result: %w", err)
+	}
+	if v.Metadata, ok = metaV.(map[string]any); !ok {
+		return v, fmt.Errorf("metadata mapping returned non-object result: %T", metaV)
+	}
+	return v, nil
+}
+
+func (p *pineconeOutput) upsert(ctx context.Context, vectors []vector) error {
+	reqBytes, err := json.Marshal(upsertRequest{
+		Vectors:   vectors,
+		Namespace: p.namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.host+"/vectors/upsert", bytes.NewReader(reqBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Api-Key", p.apiKey)
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		resBytes, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("upsert failed with status %v: %s", res.StatusCode, bytes.TrimSpace(resBytes))
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+func (p *pineconeOutput) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	vectors := make([]vector, 0, len(batch))
+	for i := range batch {
+		v, err := p.vector(batch, i)
+		if err != nil {
+			return err
+		}
+		vectors = append(vectors, v)
+	}
+
+	for len(vectors) > 0 {
+		n := len(vectors)
+		if n > p.maxBatchSize {
+			n = p.maxBatchSize
+		}
+		if err := p.upsert(ctx, vectors[:n]); err != nil {
+			return err
+		}
+		vectors = vectors[n:]
+	}
+	return nil
+}
+
+func (p *pineconeOutput) Close(ctx context.Context) error {
+	p.client.CloseIdleConnections()
+	return nil
+}
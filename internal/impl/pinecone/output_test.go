@@ -0,0 +1,105 @@
+package pinecone
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestPineconeOutput(t *testing.T) {
+	var mut sync.Mutex
+	var reqs []upsertRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/vectors/upsert", r.URL.Path)
+		assert.Equal(t, "fookey", r.Header.Get("Api-Key"))
+
+		var req upsertRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		mut.Lock()
+		reqs = append(reqs, req)
+		mut.Unlock()
+
+		_, _ = w.Write([]byte(`{"upsertedCount":1}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	conf, err := pineconeOutputConfig().ParseYAML(`
+host: `+ts.URL+`
+api_key: fookey
+namespace: foons
+id: ${! this.id }
+vector_mapping: root = this.vec
+metadata_mapping: root.title = this.title
+max_batch_size: 2
+`, nil)
+	require.NoError(t, err)
+
+	out, err := newPineconeOutputFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+	require.NoError(t, out.Connect(context.Background()))
+
+	require.NoError(t, out.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"a","vec":[1,2],"title":"foo"}`)),
+		service.NewMessage([]byte(`{"id":"b","vec":[3,4],"title":"bar"}`)),
+		service.NewMessage([]byte(`{"id":"c","vec":[5,6],"title":"baz"}`)),
+	}))
+
+	assert.Equal(t, []upsertRequest{
+		{
+			Namespace: "foons",
+			Vectors: []vector{
+				{ID: "a", Values: []float64{1, 2}, Metadata: map[string]any{"title": "foo"}},
+				{ID: "b", Values: []float64{3, 4}, Metadata: map[string]any{"title": "bar"}},
+			},
+		},
+		{
+			Namespace: "foons",
+			Vectors: []vector{
+				{ID: "c", Values: []float64{5, 6}, Metadata: map[string]any{"title": "baz"}},
+			},
+		},
+	}, reqs)
+
+	err = out.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"a","vec":5}`)),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "non-array")
+
+	require.NoError(t, out.Close(context.Background()))
+}
+
+func TestPineconeOutputError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":3,"message":"Vector dimension 2 does not match the dimension of the index 3"}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	conf, err := pineconeOutputConfig().ParseYAML(`
+host: `+ts.URL+`
+api_key: fookey
+id: ${! this.id }
+vector_mapping: root = this.vec
+`, nil)
+	require.NoError(t, err)
+
+	out, err := newPineconeOutputFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	err = out.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"a","vec":[1,2]}`)),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 400")
+	assert.Contains(t, err.Error(), "does not match the dimension")
+}
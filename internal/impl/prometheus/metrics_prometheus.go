@@ -59,6 +59,7 @@ If the Push Gateway requires HTTP Basic Authentication it can be configured with
 				docs.FieldString("password", "The Basic Authentication password.").HasDefault("").Secret(),
 			).Advanced(),
 			docs.FieldString("file_output_path", "An optional file path to write all prometheus metrics on service shutdown.").Advanced().HasDefault(""),
+			docs.FieldBool("add_type_label", "Whether to add a `type` label to metrics that identifies the type of the component (e.g. `kafka`) that they originate from, alongside the `label`, `path` and `stream` (when running in streams mode) labels. This is disabled by default for compatibility with existing dashboards.").Advanced().HasDefault(false).AtVersion("4.24.0"),
 		),
 	})
 }
@@ -154,6 +155,15 @@ func (p *promTimingHistVec) With(labelValues ...string) metrics.StatTimer {
 	}
 }
 
+type promHistogramVec struct {
+	hist  *prometheus.HistogramVec
+	count int
+}
+
+func (p *promHistogramVec) With(labelValues ...string) metrics.StatHistogram {
+	return p.hist.WithLabelValues(labelValues...)
+}
+
 type promGaugeVec struct {
 	ctr   *prometheus.GaugeVec
 	count int
@@ -173,6 +183,7 @@ type prometheusMetrics struct {
 	running    int32
 
 	fileOutputPath string
+	addTypeLabel   bool
 
 	useHistogramTiming bool
 	histogramBuckets   []float64
@@ -186,6 +197,7 @@ type prometheusMetrics struct {
 	gauges     map[string]*promGaugeVec
 	timers     map[string]*promTimingVec
 	timersHist map[string]*promTimingHistVec
+	histograms map[string]*promHistogramVec
 
 	mut sync.Mutex
 }
@@ -196,6 +208,7 @@ func newPrometheus(config metrics.Config, nm bundle.NewManagement) (metrics.Type
 		log:                 nm.Logger(),
 		running:             1,
 		closedChan:          make(chan struct{}),
+		addTypeLabel:        promConf.AddTypeLabel,
 		useHistogramTiming:  promConf.UseHistogramTiming,
 		histogramBuckets:    promConf.HistogramBuckets,
 		summaryQuantilesObj: promConf.SummaryQuantilesObj,
@@ -204,6 +217,7 @@ func newPrometheus(config metrics.Config, nm bundle.NewManagement) (metrics.Type
 		gauges:              map[string]*promGaugeVec{},
 		timers:              map[string]*promTimingVec{},
 		timersHist:          map[string]*promTimingHistVec{},
+		histograms:          map[string]*promHistogramVec{},
 	}
 
 	if len(p.histogramBuckets) == 0 {
@@ -279,6 +293,10 @@ func (p *prometheusMetrics) HandlerFunc() http.HandlerFunc {
 	}
 }
 
+func (p *prometheusMetrics) ComponentTypeLabels() bool {
+	return p.addTypeLabel
+}
+
 func (p *prometheusMetrics) GetQuantilesAsFloatMap() map[float64]float64 {
 	resultFloatMap := make(map[float64]float64)
 	for _, config := range p.summaryQuantilesObj {
@@ -396,6 +414,47 @@ func (p *prometheusMetrics) getTimerHistVec(path string, labelNames ...string) m
 	return pv
 }
 
+func (p *prometheusMetrics) GetHistogramVec(path string, buckets []float64, labelNames ...string) metrics.StatHistogramVec {
+	if !model.IsValidMetricName(model.LabelValue(path)) {
+		p.log.Errorf("Ignoring metric '%v' due to invalid name", path)
+		return metrics.FakeHistogramVec(func(l ...string) metrics.StatHistogram {
+			return &metrics.DudStat{}
+		})
+	}
+
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
+	var pv *promHistogramVec
+
+	p.mut.Lock()
+	var exists bool
+	if pv, exists = p.histograms[path]; !exists {
+		hist := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    path,
+			Help:    "Benthos Histogram metric",
+			Buckets: buckets,
+		}, labelNames)
+		p.reg.MustRegister(hist)
+
+		pv = &promHistogramVec{
+			hist:  hist,
+			count: len(labelNames),
+		}
+		p.histograms[path] = pv
+	}
+	p.mut.Unlock()
+
+	if pv.count != len(labelNames) {
+		p.log.Errorf("Metrics label mismatch %v versus %v %v for name '%v', skipping metric", pv.count, len(labelNames), labelNames, path)
+		return metrics.FakeHistogramVec(func(l ...string) metrics.StatHistogram {
+			return &metrics.DudStat{}
+		})
+	}
+	return pv
+}
+
 func (p *prometheusMetrics) GetGauge(path string) metrics.StatGauge {
 	return p.GetGaugeVec(path).With()
 }
@@ -173,6 +173,27 @@ func TestPrometheusHistMetrics(t *testing.T) {
 	assert.Contains(t, body, "\ntimertwo_sum{label3=\"value4\",label4=\"value5\"} 1.4e-08")
 }
 
+func TestPrometheusHistogramMetrics(t *testing.T) {
+	nm, err := newPrometheus(metrics.NewConfig(), mock.NewManager())
+	require.NoError(t, err)
+
+	hp, ok := nm.(metrics.HistogramProvider)
+	require.True(t, ok)
+
+	hist := hp.GetHistogramVec("histone", []float64{1, 5, 10}, "label1")
+	hist.With("value1").Observe(3)
+	hist.With("value1").Observe(7.5)
+	hist.With("value1").Observe(20)
+
+	body := getPage(t, nm.HandlerFunc())
+
+	assert.Contains(t, body, "\nhistone_bucket{label1=\"value1\",le=\"1\"} 0")
+	assert.Contains(t, body, "\nhistone_bucket{label1=\"value1\",le=\"5\"} 1")
+	assert.Contains(t, body, "\nhistone_bucket{label1=\"value1\",le=\"10\"} 2")
+	assert.Contains(t, body, "\nhistone_bucket{label1=\"value1\",le=\"+Inf\"} 3")
+	assert.Contains(t, body, "\nhistone_sum{label1=\"value1\"} 30.5")
+}
+
 func TestPrometheusWithFileOutputPath(t *testing.T) {
 	config := metrics.NewConfig()
 	config.Prometheus.FileOutputPath = os.TempDir() + "/benthos_metrics.prom"
@@ -24,7 +24,7 @@ func init() {
 		bloblang.NewPluginSpec().
 			Category(query.MethodCategoryEncoding).
 			Description(`Compresses a string or byte array value according to a specified algorithm.`).
-			Param(bloblang.NewStringParam("algorithm").Description("One of `flate`, `gzip`, `pgzip`, `lz4`, `snappy`, `zlib`, `zstd`.")).
+			Param(bloblang.NewStringParam("algorithm").Description("One of `flate`, `gzip`, `pgzip`, `lz4`, `snappy`, `snappy-framed`, `zlib`, `zstd`.")).
 			Param(bloblang.NewInt64Param("level").Description("The level of compression to use. May not be applicable to all algorithms.").Default(-1)).
 			Example("", `let long_content = range(0, 1000).map_each(content()).join(" ")
 root.a_len = $long_content.length()
@@ -65,7 +65,7 @@ root.b_len = $long_content.compress("gzip").length()
 		bloblang.NewPluginSpec().
 			Category(query.MethodCategoryEncoding).
 			Description(`Decompresses a string or byte array value according to a specified algorithm. The result of decompression `).
-			Param(bloblang.NewStringParam("algorithm").Description("One of `gzip`, `pgzip`, `zlib`, `bzip2`, `flate`, `snappy`, `lz4`, `zstd`.")).
+			Param(bloblang.NewStringParam("algorithm").Description("One of `gzip`, `pgzip`, `zlib`, `bzip2`, `flate`, `snappy`, `snappy-framed`, `lz4`, `zstd`.")).
 			Example("", `root = this.compressed.decode("base64").decompress("lz4")`,
 				[2]string{
 					`{"compressed":"BCJNGGRwuRgAAIBoZWxsbyB3b3JsZCBJIGxvdmUgc3BhY2UAAAAAGoETLg=="}`,
@@ -124,6 +124,30 @@ func strToCompressor(str string) (CompressFunc, error) {
 	return fn, nil
 }
 
+// CompressDictFunc creates a compression algorithm that uses a pre-shared
+// dictionary, the compression level is fixed at construction.
+type CompressDictFunc func(level int, dict []byte) (CompressFunc, error)
+
+var compressDictImpls = map[string]CompressDictFunc{}
+
+// AddCompressDictFunc adds a compression algorithm that supports dictionaries
+// to components. The return struct serves no purpose other than allowing you to
+// call it within the global context as an assignment.
+func AddCompressDictFunc(name string, fn CompressDictFunc) struct{} {
+	compressImplsLock.Lock()
+	compressDictImpls[name] = fn
+	compressImplsLock.Unlock()
+	return struct{}{}
+}
+
+func strToDictCompressor(str string, level int, dict []byte) (CompressFunc, error) {
+	fn, exists := compressDictImpls[str]
+	if !exists {
+		return nil, fmt.Errorf("compression type %v does not support dictionaries", str)
+	}
+	return fn(level, dict)
+}
+
 var _ = AddCompressFunc("gzip", func(level int, b []byte) ([]byte, error) {
 	buf := &bytes.Buffer{}
 	w, err := gzip.NewWriterLevel(buf, level)
@@ -192,6 +216,21 @@ var _ = AddCompressFunc("snappy", func(level int, b []byte) ([]byte, error) {
 	return snappy.Encode(nil, b), nil
 })
 
+var _ = AddCompressFunc("snappy-framed", func(level int, b []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := snappy.NewBufferedWriter(buf)
+
+	if _, err := w.Write(b); err != nil {
+		w.Close()
+		return nil, err
+	}
+	// Must flush writer before calling buf.Bytes()
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+})
+
 var _ = AddCompressFunc("lz4", func(level int, b []byte) ([]byte, error) {
 	buf := &bytes.Buffer{}
 	w := lz4.NewWriter(buf)
@@ -239,6 +278,30 @@ func strToDecompressor(str string) (DecompressFunc, error) {
 	return fn, nil
 }
 
+// DecompressDictFunc creates a decompression algorithm that uses a pre-shared
+// dictionary.
+type DecompressDictFunc func(dict []byte) (DecompressFunc, error)
+
+var decompressDictImpls = map[string]DecompressDictFunc{}
+
+// AddDecompressDictFunc adds a decompression algorithm that supports
+// dictionaries to components. The return struct serves no purpose other than
+// allowing you to call it within the global context as an assignment.
+func AddDecompressDictFunc(name string, fn DecompressDictFunc) struct{} {
+	decompressImplsLock.Lock()
+	decompressDictImpls[name] = fn
+	decompressImplsLock.Unlock()
+	return struct{}{}
+}
+
+func strToDictDecompressor(str string, dict []byte) (DecompressFunc, error) {
+	fn, exists := decompressDictImpls[str]
+	if !exists {
+		return nil, fmt.Errorf("decompression type %v does not support dictionaries", str)
+	}
+	return fn(dict)
+}
+
 var _ = AddDecompressFunc("gzip", func(b []byte) ([]byte, error) {
 	r, err := gzip.NewReader(bytes.NewBuffer(b))
 	if err != nil {
@@ -273,6 +336,16 @@ var _ = AddDecompressFunc("snappy", func(b []byte) ([]byte, error) {
 	return snappy.Decode(nil, b)
 })
 
+var _ = AddDecompressFunc("snappy-framed", func(b []byte) ([]byte, error) {
+	r := snappy.NewReader(bytes.NewReader(b))
+
+	outBuf := bytes.Buffer{}
+	if _, err := io.Copy(&outBuf, r); err != nil {
+		return nil, err
+	}
+	return outBuf.Bytes(), nil
+})
+
 var _ = AddDecompressFunc("zlib", func(b []byte) ([]byte, error) {
 	r, err := zlib.NewReader(bytes.NewBuffer(b))
 	if err != nil {
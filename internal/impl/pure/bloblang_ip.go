@@ -0,0 +1,230 @@
+package pure
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+// parseIP parses an IP address, where IPv4-mapped IPv6 addresses are unmapped
+// into IPv4 addresses when unmap is true.
+func parseIP(s string, unmap bool) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("failed to parse IP address: %w", err)
+	}
+	if unmap {
+		addr = addr.Unmap()
+	}
+	return addr, nil
+}
+
+func parseCIDRs(v any) ([]netip.Prefix, error) {
+	var strs []string
+	switch t := v.(type) {
+	case string:
+		strs = []string{t}
+	case []any:
+		for i, e := range t {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("cidr index %v: %w", i, query.NewTypeError(e, query.ValueString))
+			}
+			strs = append(strs, s)
+		}
+	default:
+		return nil, query.NewTypeError(v, query.ValueString, query.ValueArray)
+	}
+
+	prefixes := make([]netip.Prefix, len(strs))
+	for i, s := range strs {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CIDR: %w", err)
+		}
+		prefixes[i] = p.Masked()
+	}
+	return prefixes, nil
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("ip_in_cidr",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryIP).
+			Version("4.24.0").
+			Description(`Checks whether an IP address is contained within a CIDR range, or any of an array of CIDR ranges. IPv4-mapped IPv6 addresses are treated as IPv4 addresses.`).
+			Param(bloblang.NewAnyParam("cidr").Description("A CIDR range, or an array of CIDR ranges, to check.")).
+			Example("", `
+root.internal = this.ip.ip_in_cidr(["10.0.0.0/8", "192.168.0.0/16", "fd00::/8"])
+`,
+				[2]string{`{"ip":"192.168.1.10"}`, `{"internal":true}`},
+				[2]string{`{"ip":"::ffff:10.1.2.3"}`, `{"internal":true}`},
+				[2]string{`{"ip":"8.8.8.8"}`, `{"internal":false}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			cidrs, err := args.Get("cidr")
+			if err != nil {
+				return nil, err
+			}
+			prefixes, err := parseCIDRs(cidrs)
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				addr, err := parseIP(s, true)
+				if err != nil {
+					return nil, err
+				}
+				addr = addr.WithZone("")
+				for _, p := range prefixes {
+					if p.Contains(addr) {
+						return true, nil
+					}
+				}
+				return false, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("ip_normalize",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryIP).
+			Version("4.24.0").
+			Description(`Normalizes an IP address into its canonical form, where IPv4-mapped IPv6 addresses are converted into IPv4 addresses and IPv6 addresses are formatted in their shortest lower case representation.`).
+			Example("", `
+root.ips = this.ips.map_each(ip -> ip.ip_normalize())
+`,
+				[2]string{`{"ips":["::ffff:192.0.2.1","2001:0DB8:0000:0000:0000:0000:0000:0001","10.0.0.1"]}`, `{"ips":["192.0.2.1","2001:db8::1","10.0.0.1"]}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				addr, err := parseIP(s, true)
+				if err != nil {
+					return nil, err
+				}
+				return addr.String(), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("ip_subnet",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryIP).
+			Version("4.24.0").
+			Description(`Returns the subnet of an IP address for a given prefix length as a CIDR range. IPv4-mapped IPv6 addresses are treated as IPv4 addresses.`).
+			Param(bloblang.NewInt64Param("prefix_length").Description("The number of bits of the network prefix, which must not exceed 32 for IPv4 addresses or 128 for IPv6 addresses.")).
+			Example("Anonymise addresses by truncating them to their subnet.", `
+root.subnet = this.ip.ip_subnet(24)
+`,
+				[2]string{`{"ip":"203.0.113.57"}`, `{"subnet":"203.0.113.0/24"}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			bits, err := args.GetInt64("prefix_length")
+			if err != nil {
+				return nil, err
+			}
+			if bits < 0 || bits > 128 {
+				return nil, fmt.Errorf("prefix length must be between 0 and 128, got %v", bits)
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				addr, err := parseIP(s, true)
+				if err != nil {
+					return nil, err
+				}
+				p, err := addr.WithZone("").Prefix(int(bits))
+				if err != nil {
+					return nil, err
+				}
+				return p.String(), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("ip_to_int",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryIP).
+			Version("4.24.0").
+			Description(`Converts an IP address into an integer. IPv6 addresses that exceed the capacity of a 64-bit unsigned integer are returned as a string containing the decimal representation of the integer.`).
+			Example("", `
+root.a = this.a.ip_to_int()
+root.b = this.b.ip_to_int()
+`,
+				[2]string{`{"a":"192.168.0.1","b":"2001:db8::1"}`, `{"a":3232235521,"b":"42540766411282592856903984951653826561"}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				addr, err := parseIP(s, false)
+				if err != nil {
+					return nil, err
+				}
+				n := new(big.Int).SetBytes(addr.AsSlice())
+				if n.IsInt64() {
+					return n.Int64(), nil
+				}
+				if n.IsUint64() {
+					return n.Uint64(), nil
+				}
+				return n.String(), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("int_to_ip",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryIP).
+			Version("4.24.0").
+			Description(`Converts an integer, or a string containing the decimal representation of an integer, into an IP address. The result is an IPv4 address unless the parameter `+"`ipv6`"+` is set, in which case an IPv6 address is returned.`).
+			Param(bloblang.NewBoolParam("ipv6").Description("Whether to convert the integer into an IPv6 address.").Default(false)).
+			Example("", `
+root.a = this.a.int_to_ip()
+root.b = this.b.int_to_ip(ipv6: true)
+`,
+				[2]string{`{"a":3232235521,"b":"42540766411282592856903984951653826561"}`, `{"a":"192.168.0.1","b":"2001:db8::1"}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			ipv6, err := args.GetBool("ipv6")
+			if err != nil {
+				return nil, err
+			}
+			size, version := 4, "IPv4"
+			if ipv6 {
+				size, version = 16, "IPv6"
+			}
+			return func(input any) (any, error) {
+				var n *big.Int
+				switch t := query.ISanitize(input).(type) {
+				case int64:
+					n = big.NewInt(t)
+				case uint64:
+					n = new(big.Int).SetUint64(t)
+				case float64:
+					if t != math.Trunc(t) || math.IsInf(t, 0) {
+						return nil, fmt.Errorf("expected an integer, got %v", t)
+					}
+					n, _ = big.NewFloat(t).Int(nil)
+				case string:
+					var err error
+					if n, err = parseBigInt(t, 10); err != nil {
+						return nil, err
+					}
+				default:
+					return nil, query.NewTypeError(input, query.ValueNumber, query.ValueString)
+				}
+				if n.Sign() < 0 || n.BitLen() > size*8 {
+					return nil, fmt.Errorf("integer %v exceeds the range of an %v address", n, version)
+				}
+				addr, _ := netip.AddrFromSlice(n.FillBytes(make([]byte, size)))
+				return addr.String(), nil
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+}
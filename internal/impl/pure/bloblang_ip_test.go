@@ -0,0 +1,167 @@
+package pure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+func TestIPMethods(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mapping  string
+		input    any
+		output   any
+		parseErr string
+		execErr  string
+	}{
+		{
+			name:    "in single cidr",
+			mapping: `root = this.ip_in_cidr("10.0.0.0/8")`,
+			input:   "10.200.3.4",
+			output:  true,
+		},
+		{
+			name:    "not in single cidr",
+			mapping: `root = this.ip_in_cidr("10.0.0.0/8")`,
+			input:   "11.0.0.1",
+			output:  false,
+		},
+		{
+			name:    "unmasked cidr",
+			mapping: `root = this.ip_in_cidr("192.168.1.77/24")`,
+			input:   "192.168.1.1",
+			output:  true,
+		},
+		{
+			name:    "ipv6 with zone",
+			mapping: `root = this.ip_in_cidr(["10.0.0.0/8", "fe80::/10"])`,
+			input:   "fe80::1%eth0",
+			output:  true,
+		},
+		{
+			name:    "ipv4 not in ipv6 cidr",
+			mapping: `root = this.ip_in_cidr("::/0")`,
+			input:   "1.2.3.4",
+			output:  false,
+		},
+		{
+			name:     "bad cidr",
+			mapping:  `root = this.ip_in_cidr("10.0.0.0/33")`,
+			parseErr: "failed to parse CIDR",
+		},
+		{
+			name:     "bad cidr type",
+			mapping:  `root = this.ip_in_cidr(["10.0.0.0/8", 5])`,
+			parseErr: "cidr index 1: expected string value, got number",
+		},
+		{
+			name:    "bad ip",
+			mapping: `root = this.ip_in_cidr("10.0.0.0/8")`,
+			input:   "10.0.0",
+			execErr: "failed to parse IP address",
+		},
+		{
+			name:    "normalize ipv6",
+			mapping: `root = this.ip_normalize()`,
+			input:   "FE80:0:0::0:1",
+			output:  "fe80::1",
+		},
+		{
+			name:    "subnet ipv6",
+			mapping: `root = this.ip_subnet(48)`,
+			input:   "2001:db8:abcd:12::1",
+			output:  "2001:db8:abcd::/48",
+		},
+		{
+			name:    "subnet mapped ipv4",
+			mapping: `root = this.ip_subnet(16)`,
+			input:   "::ffff:172.16.5.4",
+			output:  "172.16.0.0/16",
+		},
+		{
+			name:    "subnet prefix exceeds ipv4",
+			mapping: `root = this.ip_subnet(40)`,
+			input:   "172.16.5.4",
+			execErr: "prefix length 40 too large for IPv4",
+		},
+		{
+			name:     "subnet bad prefix",
+			mapping:  `root = this.ip_subnet(-1)`,
+			parseErr: "prefix length must be between 0 and 128, got -1",
+		},
+		{
+			name:    "ipv4 to int",
+			mapping: `root = this.ip_to_int()`,
+			input:   "255.255.255.255",
+			output:  int64(4294967295),
+		},
+		{
+			name:    "small ipv6 to int",
+			mapping: `root = this.ip_to_int()`,
+			input:   "::ffff:ffff:ffff",
+			output:  int64(281474976710655),
+		},
+		{
+			name:    "ipv6 to uint",
+			mapping: `root = this.ip_to_int()`,
+			input:   "::ffff:ffff:ffff:ffff",
+			output:  uint64(18446744073709551615),
+		},
+		{
+			name:    "int to ipv4",
+			mapping: `root = this.int_to_ip()`,
+			input:   int64(16909060),
+			output:  "1.2.3.4",
+		},
+		{
+			name:    "int to ipv6",
+			mapping: `root = this.int_to_ip(true)`,
+			input:   int64(1),
+			output:  "::1",
+		},
+		{
+			name:    "int exceeds ipv4",
+			mapping: `root = this.int_to_ip()`,
+			input:   int64(4294967296),
+			execErr: "integer 4294967296 exceeds the range of an IPv4 address",
+		},
+		{
+			name:    "negative int",
+			mapping: `root = this.int_to_ip()`,
+			input:   int64(-1),
+			execErr: "integer -1 exceeds the range of an IPv4 address",
+		},
+		{
+			name:    "ip int round trip",
+			mapping: `root = this.ip_to_int().int_to_ip(ipv6: true)`,
+			input:   "2001:db8:85a3::8a2e:370:7334",
+			output:  "2001:db8:85a3::8a2e:370:7334",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			if test.parseErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.parseErr)
+				return
+			}
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			if test.execErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, res)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.execErr)
+			}
+		})
+	}
+}
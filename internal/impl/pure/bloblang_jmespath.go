@@ -0,0 +1,94 @@
+package pure
+
+import (
+	"encoding/json"
+	"fmt"
+
+	lruv2 "github.com/hashicorp/golang-lru/v2"
+	jmespath "github.com/jmespath/go-jmespath"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+// jmespathCacheSize is the maximum number of compiled JMESPath expressions that
+// are kept in memory.
+const jmespathCacheSize = 1024
+
+var jmespathCache *lruv2.Cache[string, *jmespath.JMESPath]
+
+func init() {
+	var err error
+	if jmespathCache, err = lruv2.New[string, *jmespath.JMESPath](jmespathCacheSize); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("jmespath",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Version("4.24.0").
+			Description("Executes a [JMESPath query](https://jmespath.org/) on an object or array and returns the result. Compiled queries are cached, and therefore dynamic queries, such as `this.jmespath(@query)`, are only compiled the first time each query is seen.").
+			Example("", `root.cities = this.jmespath("locations[?state == 'WA'].name | sort(@) | join(', ', @)")`, [2]string{
+				`{"locations":[{"name":"Seattle","state":"WA"},{"name":"New York","state":"NY"},{"name":"Bellevue","state":"WA"},{"name":"Olympia","state":"WA"}]}`,
+				`{"cities":"Bellevue, Olympia, Seattle"}`,
+			}).
+			Example("", `root.ages = this.jmespath("people[*].age | sort(@)")`, [2]string{
+				`{"people":[{"name":"alice","age":30},{"name":"bob","age":25}]}`,
+				`{"ages":[25,30]}`,
+			}).
+			Param(bloblang.NewStringParam("query").Description("The JMESPath query to execute.")),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			queryStr, err := args.GetString("query")
+			if err != nil {
+				return nil, err
+			}
+			q, err := jmespathCompile(queryStr)
+			if err != nil {
+				return nil, err
+			}
+			return func(v any) (any, error) {
+				return safeSearch(jmespathNormalise(v), q)
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+}
+
+// jmespathCompile returns a compiled JMESPath query, reusing a previously
+// compiled query when the same expression has been seen before.
+func jmespathCompile(queryStr string) (*jmespath.JMESPath, error) {
+	if q, ok := jmespathCache.Get(queryStr); ok {
+		return q, nil
+	}
+	q, err := jmespath.Compile(queryStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile JMESPath query: %v", err)
+	}
+	jmespathCache.Add(queryStr, q)
+	return q, nil
+}
+
+// jmespathNormalise returns a copy of a structured value where all numbers are
+// float64, as JMESPath functions and comparisons do not support other number
+// types.
+func jmespathNormalise(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, v := range t {
+			m[k] = jmespathNormalise(v)
+		}
+		return m
+	case []any:
+		s := make([]any, len(t))
+		for i, v := range t {
+			s[i] = jmespathNormalise(v)
+		}
+		return s
+	case json.Number, int, int32, int64, uint, uint32, uint64, float32:
+		if f, err := query.IGetNumber(t); err == nil {
+			return f
+		}
+	}
+	return v
+}
@@ -0,0 +1,104 @@
+package pure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+func TestJMESPathMethod(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mapping  string
+		input    any
+		output   any
+		parseErr string
+		execErr  string
+	}{
+		{
+			name:    "field selection",
+			mapping: `root = this.jmespath("foo.bar")`,
+			input:   map[string]any{"foo": map[string]any{"bar": "baz"}},
+			output:  "baz",
+		},
+		{
+			name:    "functions on integers",
+			mapping: `root = this.jmespath("sum(nums[?@ > ` + "`1`" + `])")`,
+			input:   map[string]any{"nums": []any{int64(1), int64(2), int64(3)}},
+			output:  float64(5),
+		},
+		{
+			name:    "projection",
+			mapping: `root = this.jmespath("people[*].name")`,
+			input: map[string]any{"people": []any{
+				map[string]any{"name": "alice"},
+				map[string]any{"name": "bob"},
+			}},
+			output: []any{"alice", "bob"},
+		},
+		{
+			name:    "dynamic query",
+			mapping: `root = this.doc.jmespath(this.query)`,
+			input: map[string]any{
+				"query": "length(@)",
+				"doc":   []any{"a", "b", "c"},
+			},
+			output: float64(3),
+		},
+		{
+			name:     "bad query",
+			mapping:  `root = this.jmespath("foo.[")`,
+			parseErr: "failed to compile JMESPath query",
+		},
+		{
+			name:    "bad dynamic query",
+			mapping: `root = this.jmespath(this.query)`,
+			input:   map[string]any{"query": "foo.["},
+			execErr: "failed to compile JMESPath query",
+		},
+		{
+			name:    "bad function arguments",
+			mapping: `root = this.jmespath("sum(foo)")`,
+			input:   map[string]any{"foo": "bar"},
+			execErr: "Invalid type for: bar",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			if test.parseErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.parseErr)
+				return
+			}
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			if test.execErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, res)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.execErr)
+			}
+		})
+	}
+}
+
+func TestJMESPathCompileCache(t *testing.T) {
+	a, err := jmespathCompile("foo.bar")
+	require.NoError(t, err)
+
+	b, err := jmespathCompile("foo.bar")
+	require.NoError(t, err)
+	assert.Same(t, a, b)
+
+	c, err := jmespathCompile("foo.baz")
+	require.NoError(t, err)
+	assert.NotSame(t, a, c)
+}
@@ -1,7 +1,10 @@
 package pure
 
 import (
+	"fmt"
 	"math"
+	"math/big"
+	"strconv"
 	"strings"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
@@ -173,3 +176,312 @@ root.outs = this.ins.map_each(ele -> ele.abs())
 		panic(err)
 	}
 }
+
+//------------------------------------------------------------------------------
+
+var roundingModes = map[string]func(float64) float64{
+	"half_away_from_zero": math.Round,
+	"half_even":           math.RoundToEven,
+	"half_up": func(f float64) float64 {
+		return math.Floor(f + 0.5)
+	},
+	"half_down": func(f float64) float64 {
+		return math.Ceil(f - 0.5)
+	},
+	"ceil":     math.Ceil,
+	"floor":    math.Floor,
+	"truncate": math.Trunc,
+}
+
+// shiftDecimal multiplies a number by 10^places by shifting the exponent of
+// its shortest decimal representation, which avoids the errors introduced by
+// floating point multiplication (where 1.005 * 100 = 100.49999999999999).
+func shiftDecimal(f float64, places int64) float64 {
+	if places == 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+		return f
+	}
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	i := strings.IndexByte(s, 'e')
+	exp, _ := strconv.ParseInt(s[i+1:], 10, 64)
+	shifted, err := strconv.ParseFloat(s[:i]+"e"+strconv.FormatInt(exp+places, 10), 64)
+	if err != nil {
+		return f * math.Pow10(int(places))
+	}
+	return shifted
+}
+
+func roundTo(f float64, places int64, roundFn func(float64) float64) any {
+	rounded := shiftDecimal(roundFn(shiftDecimal(f, places)), -places)
+	if places <= 0 {
+		if i, err := query.IToInt(rounded); err == nil {
+			return i
+		}
+	}
+	return rounded
+}
+
+// parseBigInt parses a string as an arbitrarily large integer, where a base of
+// zero infers the base from the prefix of the string.
+func parseBigInt(s string, base int) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(strings.TrimSpace(s), base)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse %q as an integer of base %v", s, base)
+	}
+	return n, nil
+}
+
+func checkBigIntBase(base int64, allowZero bool) error {
+	if (base == 0 && allowZero) || (base >= 2 && base <= big.MaxBase) {
+		return nil
+	}
+	return fmt.Errorf("base must be between 2 and %v, got %v", big.MaxBase, base)
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("round_to",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Version("4.24.0").
+			Description(`
+Rounds a number to a given number of decimal places using a rounding mode. A negative number of places rounds to the left of the decimal point. When the number of places is zero or less and the result fits within a 64-bit integer then that is returned, otherwise a floating point number is returned.
+
+The following rounding modes are supported:
+
+- `+"`half_away_from_zero`"+`: Rounds half away from zero, which is the behaviour of `+"[`round`](#round)"+`.
+- `+"`half_even`"+`: Rounds half to the nearest even digit, also known as banker's rounding.
+- `+"`half_up`"+`: Rounds half towards positive infinity.
+- `+"`half_down`"+`: Rounds half towards negative infinity.
+- `+"`ceil`"+`: Rounds towards positive infinity.
+- `+"`floor`"+`: Rounds towards negative infinity.
+- `+"`truncate`"+`: Rounds towards zero.`).
+			Param(bloblang.NewInt64Param("places").Description("The number of decimal places to round to.").Default(0)).
+			Param(bloblang.NewStringParam("mode").Description("The rounding mode to use.").Default("half_away_from_zero")).
+			Example("", `
+root.a = this.value.round_to(2)
+root.b = this.value.round_to(2, "floor")
+root.c = this.value.round_to(-1)
+`,
+				[2]string{`{"value":1.005}`, `{"a":1.01,"b":1,"c":0}`},
+				[2]string{`{"value":-14.567}`, `{"a":-14.57,"b":-14.57,"c":-10}`},
+			).
+			Example("Banker's rounding reduces the bias of rounding halves when aggregating values.", `
+root.rounded = this.values.map_each(v -> v.round_to(mode: "half_even"))
+`,
+				[2]string{`{"values":[0.5,1.5,2.5,-2.5]}`, `{"rounded":[0,2,2,-2]}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			places, err := args.GetInt64("places")
+			if err != nil {
+				return nil, err
+			}
+			mode, err := args.GetString("mode")
+			if err != nil {
+				return nil, err
+			}
+			roundFn, exists := roundingModes[mode]
+			if !exists {
+				return nil, fmt.Errorf("unrecognised rounding mode: %v", mode)
+			}
+			return func(input any) (any, error) {
+				switch t := query.ISanitize(input).(type) {
+				case int64:
+					if places >= 0 {
+						return t, nil
+					}
+				case uint64:
+					if places >= 0 {
+						return t, nil
+					}
+				}
+				f, err := query.IGetNumber(input)
+				if err != nil {
+					return nil, err
+				}
+				return roundTo(f, places, roundFn), nil
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("clamp",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Version("4.24.0").
+			Description(`Limits a number to a range, returning `+"`min`"+` when the number is less than it and `+"`max`"+` when the number is greater than it.`).
+			Param(bloblang.NewFloat64Param("min").Description("The lower bound of the range.")).
+			Param(bloblang.NewFloat64Param("max").Description("The upper bound of the range.")).
+			Example("", `
+root.volume = this.volume.clamp(0, 100)
+`,
+				[2]string{`{"volume":150}`, `{"volume":100}`},
+				[2]string{`{"volume":-3}`, `{"volume":0}`},
+				[2]string{`{"volume":42.5}`, `{"volume":42.5}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			lower, err := args.GetFloat64("min")
+			if err != nil {
+				return nil, err
+			}
+			upper, err := args.GetFloat64("max")
+			if err != nil {
+				return nil, err
+			}
+			if lower > upper {
+				return nil, fmt.Errorf("min (%v) must not be greater than max (%v)", lower, upper)
+			}
+			return func(input any) (any, error) {
+				f, err := query.IGetNumber(input)
+				if err != nil {
+					return nil, err
+				}
+				switch {
+				case f < lower:
+					return numberResult(lower), nil
+				case f > upper:
+					return numberResult(upper), nil
+				}
+				return query.ISanitize(input), nil
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("safe_divide",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Version("4.24.0").
+			Description(`Divides a number by a divisor, returning a fallback value instead of an error or an infinite result when the divisor is zero.`).
+			Param(bloblang.NewFloat64Param("divisor").Description("The number to divide by.")).
+			Param(bloblang.NewAnyParam("fallback").Description("The value to return when the divisor is zero.").Default(0)).
+			Example("", `
+root.error_rate = this.errors.safe_divide(this.requests)
+`,
+				[2]string{`{"errors":5,"requests":200}`, `{"error_rate":0.025}`},
+				[2]string{`{"errors":0,"requests":0}`, `{"error_rate":0}`},
+			).
+			Example("", `
+root.avg_latency = this.total_ms.safe_divide(this.count, null)
+`,
+				[2]string{`{"total_ms":420,"count":0}`, `{"avg_latency":null}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			divisor, err := args.GetFloat64("divisor")
+			if err != nil {
+				return nil, err
+			}
+			fallback, err := args.Get("fallback")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.Float64Method(func(f float64) (any, error) {
+				if divisor == 0 {
+					return fallback, nil
+				}
+				return f / divisor, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("parse_bigint",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Version("4.24.0").
+			Description(`
+Parses a string as an integer of arbitrary size in a given base. When the result fits within a 64-bit signed or unsigned integer then a number is returned, otherwise the result is returned as a string containing its decimal representation, which can be formatted with `+"[`format_bigint`](#format_bigint)"+`.
+
+A base of zero infers the base from the prefix of the string, where `+"`0x`"+` is base 16, `+"`0o`"+` is base 8 and `+"`0b`"+` is base 2.`).
+			Param(bloblang.NewInt64Param("base").Description("The base of the string, between 2 and 62, or zero to infer it from the prefix of the string.").Default(10)).
+			Example("", `
+root.a = this.a.parse_bigint(16)
+root.b = this.b.parse_bigint(0)
+`,
+				[2]string{`{"a":"ff","b":"0x152D02C7E14AF6800000"}`, `{"a":255,"b":"100000000000000000000000"}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			base, err := args.GetInt64("base")
+			if err != nil {
+				return nil, err
+			}
+			if err := checkBigIntBase(base, true); err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				n, err := parseBigInt(s, int(base))
+				if err != nil {
+					return nil, err
+				}
+				if n.IsInt64() {
+					return n.Int64(), nil
+				}
+				if n.IsUint64() {
+					return n.Uint64(), nil
+				}
+				return n.String(), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("format_bigint",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Version("4.24.0").
+			Description(`Formats an integer, or a string containing the decimal representation of an integer of arbitrary size, as a string in a given base. Letters are formatted in lower case.`).
+			Param(bloblang.NewInt64Param("base").Description("The base to format the integer in, between 2 and 62.").Default(10)).
+			Example("", `
+root.a = this.a.format_bigint(16)
+root.b = this.b.format_bigint(2)
+`,
+				[2]string{`{"a":"100000000000000000000000","b":10}`, `{"a":"152d02c7e14af6800000","b":"1010"}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			base, err := args.GetInt64("base")
+			if err != nil {
+				return nil, err
+			}
+			if err := checkBigIntBase(base, false); err != nil {
+				return nil, err
+			}
+			return func(input any) (any, error) {
+				var n *big.Int
+				switch t := query.ISanitize(input).(type) {
+				case int64:
+					n = big.NewInt(t)
+				case uint64:
+					n = new(big.Int).SetUint64(t)
+				case float64:
+					if t != math.Trunc(t) || math.IsInf(t, 0) {
+						return nil, fmt.Errorf("expected an integer, got %v", t)
+					}
+					n, _ = big.NewFloat(t).Int(nil)
+				case string:
+					var err error
+					if n, err = parseBigInt(t, 10); err != nil {
+						return nil, err
+					}
+				case []byte:
+					var err error
+					if n, err = parseBigInt(string(t), 10); err != nil {
+						return nil, err
+					}
+				default:
+					return nil, query.NewTypeError(input, query.ValueNumber, query.ValueString)
+				}
+				return n.Text(int(base)), nil
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+}
+
+// numberResult returns a float as an integer when it has no fractional part
+// and fits within a 64-bit integer.
+func numberResult(f float64) any {
+	if f == math.Trunc(f) {
+		if i, err := query.IToInt(f); err == nil {
+			return i
+		}
+	}
+	return f
+}
@@ -0,0 +1,197 @@
+package pure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+func TestNumberMethods(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mapping  string
+		input    any
+		output   any
+		parseErr string
+		execErr  string
+	}{
+		{
+			name:    "round to places",
+			mapping: `root = this.round_to(2)`,
+			input:   2.675,
+			output:  2.68,
+		},
+		{
+			name:    "round to zero places",
+			mapping: `root = this.round_to()`,
+			input:   -2.5,
+			output:  int64(-3),
+		},
+		{
+			name:    "round half even",
+			mapping: `root = this.round_to(1, "half_even")`,
+			input:   0.25,
+			output:  0.2,
+		},
+		{
+			name:    "round half up",
+			mapping: `root = this.round_to(0, "half_up")`,
+			input:   -2.5,
+			output:  int64(-2),
+		},
+		{
+			name:    "round half down",
+			mapping: `root = this.round_to(0, "half_down")`,
+			input:   2.5,
+			output:  int64(2),
+		},
+		{
+			name:    "round ceil",
+			mapping: `root = this.round_to(1, "ceil")`,
+			input:   1.21,
+			output:  1.3,
+		},
+		{
+			name:    "round truncate",
+			mapping: `root = this.round_to(0, "truncate")`,
+			input:   -7.9,
+			output:  int64(-7),
+		},
+		{
+			name:    "round integer to negative places",
+			mapping: `root = this.round_to(-2)`,
+			input:   int64(1250),
+			output:  int64(1300),
+		},
+		{
+			name:    "round integer to positive places",
+			mapping: `root = this.round_to(2)`,
+			input:   uint64(18446744073709551615),
+			output:  uint64(18446744073709551615),
+		},
+		{
+			name:     "round unknown mode",
+			mapping:  `root = this.round_to(2, "sideways")`,
+			parseErr: "unrecognised rounding mode: sideways",
+		},
+		{
+			name:    "round not a number",
+			mapping: `root = this.round_to(2)`,
+			input:   "nope",
+			execErr: "expected number value",
+		},
+		{
+			name:    "clamp within range",
+			mapping: `root = this.clamp(-1, 1)`,
+			input:   int64(0),
+			output:  int64(0),
+		},
+		{
+			name:    "clamp below",
+			mapping: `root = this.clamp(-1.5, 1)`,
+			input:   -20.0,
+			output:  -1.5,
+		},
+		{
+			name:    "clamp above",
+			mapping: `root = this.clamp(-1, 10)`,
+			input:   11.2,
+			output:  int64(10),
+		},
+		{
+			name:     "clamp invalid range",
+			mapping:  `root = this.clamp(10, 1)`,
+			parseErr: "min (10) must not be greater than max (1)",
+		},
+		{
+			name:    "safe divide",
+			mapping: `root = this.safe_divide(4)`,
+			input:   int64(10),
+			output:  2.5,
+		},
+		{
+			name:    "safe divide by zero",
+			mapping: `root = this.safe_divide(0, "n/a")`,
+			input:   int64(10),
+			output:  "n/a",
+		},
+		{
+			name:    "parse bigint fits int64",
+			mapping: `root = this.parse_bigint()`,
+			input:   "-9223372036854775808",
+			output:  int64(-9223372036854775808),
+		},
+		{
+			name:    "parse bigint fits uint64",
+			mapping: `root = this.parse_bigint(16)`,
+			input:   "ffffffffffffffff",
+			output:  uint64(18446744073709551615),
+		},
+		{
+			name:    "parse bigint exceeds 64 bits",
+			mapping: `root = this.parse_bigint(2)`,
+			input:   "10000000000000000000000000000000000000000000000000000000000000000000",
+			output:  "147573952589676412928",
+		},
+		{
+			name:    "parse bigint invalid",
+			mapping: `root = this.parse_bigint(8)`,
+			input:   "129",
+			execErr: `failed to parse "129" as an integer of base 8`,
+		},
+		{
+			name:     "parse bigint invalid base",
+			mapping:  `root = this.parse_bigint(1)`,
+			parseErr: "base must be between 2 and 62, got 1",
+		},
+		{
+			name:    "format bigint from string",
+			mapping: `root = this.format_bigint(36)`,
+			input:   "147573952589676412928",
+			output:  "v57488hd2bqbk",
+		},
+		{
+			name:    "format bigint from negative number",
+			mapping: `root = this.format_bigint(16)`,
+			input:   int64(-255),
+			output:  "-ff",
+		},
+		{
+			name:    "format bigint from fraction",
+			mapping: `root = this.format_bigint()`,
+			input:   1.5,
+			execErr: "expected an integer, got 1.5",
+		},
+		{
+			name:    "bigint round trip",
+			mapping: `root = this.parse_bigint(16).format_bigint(16)`,
+			input:   "de0b6b3a7640000de0b6b3a7640000",
+			output:  "de0b6b3a7640000de0b6b3a7640000",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			if test.parseErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.parseErr)
+				return
+			}
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			if test.execErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, res)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.execErr)
+			}
+		})
+	}
+}
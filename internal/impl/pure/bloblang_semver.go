@@ -0,0 +1,452 @@
+package pure
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+// semver is a semantic version as described by https://semver.org.
+type semver struct {
+	parts [3]uint64
+	pre   []string
+	build string
+}
+
+// parseSemverPartial parses a version that may be missing minor and patch
+// numbers, or contain the wildcards `x`, `X` or `*` in place of them, and
+// returns the number of parts that were specified before any wildcard, and
+// whether a wildcard was present.
+func parseSemverPartial(s string) (v semver, specified int, wildcard bool, err error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "v"), "V")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s, v.build = s[:i], s[i+1:]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		var pre string
+		s, pre = s[:i], s[i+1:]
+		if pre == "" {
+			return v, 0, false, errors.New("empty pre-release")
+		}
+		v.pre = strings.Split(pre, ".")
+	}
+
+	segments := strings.Split(s, ".")
+	if len(segments) > 3 {
+		return v, 0, false, fmt.Errorf("too many version numbers: %v", s)
+	}
+	for i, seg := range segments {
+		if seg == "x" || seg == "X" || seg == "*" {
+			return v, specified, true, nil
+		}
+		if v.parts[i], err = strconv.ParseUint(seg, 10, 64); err != nil {
+			return v, 0, false, fmt.Errorf("invalid version number: %q", seg)
+		}
+		specified++
+	}
+	if specified < 3 && (len(v.pre) > 0 || v.build != "") {
+		return v, 0, false, errors.New("pre-release and build metadata require a full version")
+	}
+	return v, specified, false, nil
+}
+
+func parseSemver(s string) (semver, error) {
+	v, _, wildcard, err := parseSemverPartial(s)
+	if err != nil {
+		return v, fmt.Errorf("failed to parse semantic version %q: %w", s, err)
+	}
+	if wildcard {
+		return v, fmt.Errorf("failed to parse semantic version %q: wildcards are not allowed", s)
+	}
+	return v, nil
+}
+
+// compare returns -1, 0 or 1 depending on the precedence of a version relative
+// to another, where build metadata is ignored.
+func (v semver) compare(o semver) int {
+	for i := range v.parts {
+		if v.parts[i] != o.parts[i] {
+			if v.parts[i] < o.parts[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(v.pre) == 0 && len(o.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(o.pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.pre) && i < len(o.pre); i++ {
+		if c := comparePreIdentifier(v.pre[i], o.pre[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(v.pre) < len(o.pre):
+		return -1
+	case len(v.pre) > len(o.pre):
+		return 1
+	}
+	return 0
+}
+
+func comparePreIdentifier(a, b string) int {
+	aNum, aErr := strconv.ParseUint(a, 10, 64)
+	bNum, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		if aNum == bNum {
+			return 0
+		}
+		if aNum < bNum {
+			return -1
+		}
+		return 1
+	case aErr == nil:
+		// Numeric identifiers have lower precedence than alphanumeric ones.
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// bump returns the lowest version that follows all versions matching the
+// first n parts of a version, which is the lowest pre-release of the version
+// with the nth part incremented.
+func (v semver) bump(n int) semver {
+	b := semver{pre: []string{"0"}}
+	copy(b.parts[:n], v.parts[:n])
+	b.parts[n-1]++
+	return b
+}
+
+type semverComparator struct {
+	op string
+	v  semver
+}
+
+func (c semverComparator) matches(v semver) bool {
+	cmp := v.compare(c.v)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "!=":
+		return cmp != 0
+	}
+	return cmp == 0
+}
+
+// semverConstraint is a set of alternative ranges, where a version satisfies
+// the constraint when it matches all comparators of any range.
+type semverConstraint [][]semverComparator
+
+func (c semverConstraint) matches(v semver) bool {
+	for _, comparators := range c {
+		matched := true
+		for _, cmp := range comparators {
+			if !cmp.matches(v) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+var semverOperators = []string{">=", "<=", "!=", "==", ">", "<", "=", "~", "^"}
+
+func parseSemverTerm(term string) ([]semverComparator, error) {
+	var op string
+	for _, o := range semverOperators {
+		if strings.HasPrefix(term, o) {
+			op, term = o, term[len(o):]
+			break
+		}
+	}
+
+	// Missing parts are treated the same as wildcards within constraints.
+	v, n, _, err := parseSemverPartial(term)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "", "=", "==":
+		if n == 3 {
+			return []semverComparator{{"=", v}}, nil
+		}
+		if n == 0 {
+			return nil, nil
+		}
+		return []semverComparator{{">=", v}, {"<", v.bump(n)}}, nil
+	case "!=":
+		if n < 3 {
+			return nil, errors.New("the operator != requires a full version")
+		}
+		return []semverComparator{{"!=", v}}, nil
+	case ">":
+		if n == 0 {
+			return nil, errors.New("the operator > cannot be used with a wildcard")
+		}
+		if n == 3 {
+			return []semverComparator{{">", v}}, nil
+		}
+		return []semverComparator{{">=", v.bump(n)}}, nil
+	case ">=":
+		if n == 0 {
+			return nil, nil
+		}
+		return []semverComparator{{">=", v}}, nil
+	case "<":
+		if n == 0 {
+			return nil, errors.New("the operator < cannot be used with a wildcard")
+		}
+		return []semverComparator{{"<", v}}, nil
+	case "<=":
+		if n == 0 {
+			return nil, nil
+		}
+		if n == 3 {
+			return []semverComparator{{"<=", v}}, nil
+		}
+		return []semverComparator{{"<", v.bump(n)}}, nil
+	case "~":
+		if n == 0 {
+			return nil, nil
+		}
+		if n == 1 {
+			return []semverComparator{{">=", v}, {"<", v.bump(1)}}, nil
+		}
+		return []semverComparator{{">=", v}, {"<", v.bump(2)}}, nil
+	}
+
+	// The caret operator allows changes that do not modify the left-most
+	// non-zero part of the version.
+	switch {
+	case n == 0:
+		return nil, nil
+	case v.parts[0] > 0 || n == 1:
+		return []semverComparator{{">=", v}, {"<", v.bump(1)}}, nil
+	case v.parts[1] > 0 || n == 2:
+		return []semverComparator{{">=", v}, {"<", v.bump(2)}}, nil
+	}
+	return []semverComparator{{">=", v}, {"<", v.bump(3)}}, nil
+}
+
+func parseSemverConstraint(s string) (semverConstraint, error) {
+	var c semverConstraint
+	for _, alt := range strings.Split(s, "||") {
+		// Operators are allowed to be separated from their versions by
+		// whitespace, and so they are joined with the following token.
+		var tokens []string
+		var pendingOp string
+		for _, t := range strings.FieldsFunc(alt, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == ','
+		}) {
+			if t != "-" && strings.Trim(t, "<>=!~^") == "" {
+				pendingOp += t
+				continue
+			}
+			tokens = append(tokens, pendingOp+t)
+			pendingOp = ""
+		}
+		if pendingOp != "" {
+			return nil, fmt.Errorf("failed to parse constraint %q: operator %v is missing a version", s, pendingOp)
+		}
+
+		var comparators []semverComparator
+		for i := 0; i < len(tokens); i++ {
+			term := tokens[i]
+			if i+2 < len(tokens) && tokens[i+1] == "-" {
+				// Hyphen ranges such as `1.2.3 - 2.3` are inclusive of both
+				// bounds.
+				term, tokens[i+2] = ">="+term, "<="+tokens[i+2]
+				i++
+			} else if term == "-" {
+				return nil, fmt.Errorf("failed to parse constraint %q: hyphen ranges require a version on each side", s)
+			}
+			cmps, err := parseSemverTerm(term)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse constraint %q: %w", s, err)
+			}
+			comparators = append(comparators, cmps...)
+		}
+		c = append(c, comparators)
+	}
+	return c, nil
+}
+
+func registerSemverComparison(name, description, exampleMapping string, examples [][2]string, fn func(cmp int) bool) {
+	if err := bloblang.RegisterMethodV2(name,
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Version("4.24.0").
+			Description(description).
+			Param(bloblang.NewStringParam("other").Description("The semantic version to compare against.")).
+			Example("", exampleMapping, examples...),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			otherStr, err := args.GetString("other")
+			if err != nil {
+				return nil, err
+			}
+			other, err := parseSemver(otherStr)
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				v, err := parseSemver(s)
+				if err != nil {
+					return nil, err
+				}
+				return fn(v.compare(other)), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("parse_semver",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Version("4.24.0").
+			Description(`Parses a [semantic version](https://semver.org) into an object containing the fields `+"`major`, `minor`, `patch`, `prerelease` and `build`"+`. A leading `+"`v`"+` is ignored, and missing minor and patch numbers are treated as zero.`).
+			Example("", `
+root.version = this.firmware.parse_semver()
+`,
+				[2]string{`{"firmware":"v2.4.1-rc.1+build.42"}`, `{"version":{"build":"build.42","major":2,"minor":4,"patch":1,"prerelease":"rc.1"}}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				v, err := parseSemver(s)
+				if err != nil {
+					return nil, err
+				}
+				return map[string]any{
+					"major":      int64(v.parts[0]),
+					"minor":      int64(v.parts[1]),
+					"patch":      int64(v.parts[2]),
+					"prerelease": strings.Join(v.pre, "."),
+					"build":      v.build,
+				}, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("semver_compare",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Version("4.24.0").
+			Description(`Compares a semantic version with another following the rules of [semantic version precedence](https://semver.org/#spec-item-11), returning `+"`-1`"+` when the version is lower, `+"`0`"+` when they are equal and `+"`1`"+` when the version is higher. Build metadata is ignored.`).
+			Param(bloblang.NewStringParam("other").Description("The semantic version to compare against.")).
+			Example("", `
+root.sorted = this.versions.sort(item -> item.left.semver_compare(item.right) < 0)
+`,
+				[2]string{`{"versions":["1.10.0","1.2.0","1.2.0-beta.2","1.2.0-beta.10"]}`, `{"sorted":["1.2.0-beta.2","1.2.0-beta.10","1.2.0","1.10.0"]}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			otherStr, err := args.GetString("other")
+			if err != nil {
+				return nil, err
+			}
+			other, err := parseSemver(otherStr)
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				v, err := parseSemver(s)
+				if err != nil {
+					return nil, err
+				}
+				return int64(v.compare(other)), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	registerSemverComparison("semver_gt",
+		`Checks whether a semantic version has a higher precedence than another.`,
+		`root.needs_upgrade = "2.0.0".semver_gt(this.firmware)`,
+		[][2]string{
+			{`{"firmware":"1.9.3"}`, `{"needs_upgrade":true}`},
+			{`{"firmware":"2.0.0"}`, `{"needs_upgrade":false}`},
+		},
+		func(cmp int) bool { return cmp > 0 })
+
+	registerSemverComparison("semver_lt",
+		`Checks whether a semantic version has a lower precedence than another.`,
+		`root.legacy = this.firmware.semver_lt("1.0.0")`,
+		[][2]string{
+			{`{"firmware":"1.0.0-rc.3"}`, `{"legacy":true}`},
+			{`{"firmware":"1.0.0"}`, `{"legacy":false}`},
+		},
+		func(cmp int) bool { return cmp < 0 })
+
+	if err := bloblang.RegisterMethodV2("semver_satisfies",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Version("4.24.0").
+			Description(`
+Checks whether a semantic version satisfies a constraint. A constraint consists of one or more comparisons separated by whitespace or commas, all of which must match, and alternative constraints can be separated with `+"`||`"+`.
+
+The comparison operators `+"`=`, `!=`, `>`, `>=`, `<` and `<=`"+` are supported, as well as the following:
+
+- `+"`~1.2.3`"+`: Allows patch changes, equivalent to `+"`>=1.2.3 <1.3.0`"+`.
+- `+"`^1.2.3`"+`: Allows changes that do not modify the left-most non-zero number, equivalent to `+"`>=1.2.3 <2.0.0`"+`, whereas `+"`^0.2.3`"+` is equivalent to `+"`>=0.2.3 <0.3.0`"+`.
+- `+"`1.2.x`"+`: Versions can contain the wildcards `+"`x`, `X` or `*`"+`, or omit numbers, in order to match any number in their place.
+- `+"`1.2.3 - 2.3`"+`: Hyphen ranges are inclusive of both versions.
+
+Pre-release versions are compared following the rules of semantic version precedence, and therefore `+"`2.0.0-rc.1`"+` satisfies `+"`>1.0.0`"+`, but does not satisfy `+"`^1.0.0`"+`.`).
+			Param(bloblang.NewStringParam("constraint").Description("The constraint to check the version against.")).
+			Example("", `
+root.supported = this.firmware.semver_satisfies(">=1.4, <3 || 4.x")
+`,
+				[2]string{`{"firmware":"2.9.12"}`, `{"supported":true}`},
+				[2]string{`{"firmware":"3.1.0"}`, `{"supported":false}`},
+				[2]string{`{"firmware":"4.0.1"}`, `{"supported":true}`},
+			).
+			Example("", `
+root.compatible = this.client_version.semver_satisfies("^1.2.0")
+`,
+				[2]string{`{"client_version":"1.8.0"}`, `{"compatible":true}`},
+				[2]string{`{"client_version":"2.0.0-beta.1"}`, `{"compatible":false}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			constraintStr, err := args.GetString("constraint")
+			if err != nil {
+				return nil, err
+			}
+			constraint, err := parseSemverConstraint(constraintStr)
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				v, err := parseSemver(s)
+				if err != nil {
+					return nil, err
+				}
+				return constraint.matches(v), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,244 @@
+package pure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+func TestSemverCompare(t *testing.T) {
+	// Precedence examples taken from https://semver.org/#spec-item-11
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"2.0.0",
+		"2.1.0",
+		"2.1.1",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, err := parseSemver(ordered[i])
+			require.NoError(t, err)
+			b, err := parseSemver(ordered[j])
+			require.NoError(t, err)
+
+			exp := 0
+			if i < j {
+				exp = -1
+			} else if i > j {
+				exp = 1
+			}
+			assert.Equal(t, exp, a.compare(b), "%v %v", ordered[i], ordered[j])
+		}
+	}
+}
+
+func TestSemverSatisfies(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matches    []string
+		misses     []string
+	}{
+		{
+			constraint: "1.2.3",
+			matches:    []string{"1.2.3", "v1.2.3+build"},
+			misses:     []string{"1.2.4", "1.2.3-rc.1"},
+		},
+		{
+			constraint: "!=1.2.3",
+			matches:    []string{"1.2.4"},
+			misses:     []string{"1.2.3"},
+		},
+		{
+			constraint: "1.2.x",
+			matches:    []string{"1.2.0", "1.2.99"},
+			misses:     []string{"1.3.0-0", "1.1.9", "1.2.0-rc.1"},
+		},
+		{
+			constraint: "*",
+			matches:    []string{"0.0.1", "99.0.0"},
+		},
+		{
+			constraint: "> 1.2",
+			matches:    []string{"1.3.0", "1.3.0-alpha"},
+			misses:     []string{"1.2.9"},
+		},
+		{
+			constraint: "<=1.2",
+			matches:    []string{"1.2.9", "0.1.0"},
+			misses:     []string{"1.3.0", "1.3.0-alpha"},
+		},
+		{
+			constraint: "~1.2.3",
+			matches:    []string{"1.2.3", "1.2.9"},
+			misses:     []string{"1.2.2", "1.3.0"},
+		},
+		{
+			constraint: "~1",
+			matches:    []string{"1.0.0", "1.9.0"},
+			misses:     []string{"2.0.0"},
+		},
+		{
+			constraint: "^1.2.3",
+			matches:    []string{"1.2.3", "1.9.9"},
+			misses:     []string{"1.2.2", "2.0.0", "2.0.0-alpha"},
+		},
+		{
+			constraint: "^0.2.3",
+			matches:    []string{"0.2.3", "0.2.9"},
+			misses:     []string{"0.3.0"},
+		},
+		{
+			constraint: "^0.0.3",
+			matches:    []string{"0.0.3"},
+			misses:     []string{"0.0.4"},
+		},
+		{
+			constraint: "^0.x",
+			matches:    []string{"0.0.1", "0.9.0"},
+			misses:     []string{"1.0.0"},
+		},
+		{
+			constraint: "1.2.3 - 2.3",
+			matches:    []string{"1.2.3", "2.3.9"},
+			misses:     []string{"1.2.2", "2.4.0"},
+		},
+		{
+			constraint: ">=1.0.0, <2.0.0 || >=3",
+			matches:    []string{"1.5.0", "3.0.0", "4.1.0"},
+			misses:     []string{"2.5.0", "0.9.0"},
+		},
+	}
+
+	for _, test := range tests {
+		c, err := parseSemverConstraint(test.constraint)
+		require.NoError(t, err, test.constraint)
+		for _, s := range test.matches {
+			v, err := parseSemver(s)
+			require.NoError(t, err)
+			assert.True(t, c.matches(v), "%v should satisfy %v", s, test.constraint)
+		}
+		for _, s := range test.misses {
+			v, err := parseSemver(s)
+			require.NoError(t, err)
+			assert.False(t, c.matches(v), "%v should not satisfy %v", s, test.constraint)
+		}
+	}
+}
+
+func TestSemverMethods(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mapping  string
+		input    any
+		output   any
+		parseErr string
+		execErr  string
+	}{
+		{
+			name:    "parse partial version",
+			mapping: `root = this.parse_semver()`,
+			input:   "V3",
+			output: map[string]any{
+				"major":      int64(3),
+				"minor":      int64(0),
+				"patch":      int64(0),
+				"prerelease": "",
+				"build":      "",
+			},
+		},
+		{
+			name:    "parse invalid version",
+			mapping: `root = this.parse_semver()`,
+			input:   "1.two.3",
+			execErr: `failed to parse semantic version "1.two.3": invalid version number: "two"`,
+		},
+		{
+			name:    "parse wildcard version",
+			mapping: `root = this.parse_semver()`,
+			input:   "1.x",
+			execErr: "wildcards are not allowed",
+		},
+		{
+			name:    "parse partial pre-release",
+			mapping: `root = this.parse_semver()`,
+			input:   "1.2-rc.1",
+			execErr: "pre-release and build metadata require a full version",
+		},
+		{
+			name:    "compare build metadata",
+			mapping: `root = this.semver_compare("1.0.0+b")`,
+			input:   "1.0.0+a",
+			output:  int64(0),
+		},
+		{
+			name:     "compare invalid other",
+			mapping:  `root = this.semver_compare("nope")`,
+			parseErr: `failed to parse semantic version "nope"`,
+		},
+		{
+			name:    "greater than",
+			mapping: `root = this.semver_gt("1.9.0")`,
+			input:   "1.10.0",
+			output:  true,
+		},
+		{
+			name:    "less than",
+			mapping: `root = this.semver_lt("1.9.0")`,
+			input:   "1.10.0",
+			output:  false,
+		},
+		{
+			name:    "satisfies",
+			mapping: `root = this.semver_satisfies(">= 2.1")`,
+			input:   "2.1.0",
+			output:  true,
+		},
+		{
+			name:     "satisfies dangling operator",
+			mapping:  `root = this.semver_satisfies("1.0.0 >=")`,
+			parseErr: "operator >= is missing a version",
+		},
+		{
+			name:     "satisfies bad hyphen range",
+			mapping:  `root = this.semver_satisfies("1.0.0 -")`,
+			parseErr: "hyphen ranges require a version on each side",
+		},
+		{
+			name:     "satisfies wildcard not equal",
+			mapping:  `root = this.semver_satisfies("!=1.x")`,
+			parseErr: "the operator != requires a full version",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			if test.parseErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.parseErr)
+				return
+			}
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			if test.execErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, res)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.execErr)
+			}
+		})
+	}
+}
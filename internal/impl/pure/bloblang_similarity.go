@@ -0,0 +1,392 @@
+package pure
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+func init() {
+	if err := bloblang.RegisterMethodV2("levenshtein",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Version("4.24.0").
+			Description(`Returns the [Levenshtein distance](https://en.wikipedia.org/wiki/Levenshtein_distance) between a string and another, which is the minimum number of single character insertions, deletions or substitutions required to change one into the other. The comparison is case sensitive.`).
+			Param(bloblang.NewStringParam("other").Description("The string to compare against.")).
+			Example("", `
+root.distance = this.a.levenshtein(this.b)
+`,
+				[2]string{`{"a":"kitten","b":"sitting"}`, `{"distance":3}`},
+			).
+			Example("Combined with other string methods the distance can be made case insensitive.", `
+root.is_typo = this.name.lowercase().levenshtein(this.expected.lowercase()) <= 1
+`,
+				[2]string{`{"name":"Benthso","expected":"benthos"}`, `{"is_typo":false}`},
+				[2]string{`{"name":"Bentos","expected":"benthos"}`, `{"is_typo":true}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			other, err := args.GetString("other")
+			if err != nil {
+				return nil, err
+			}
+			otherRunes := []rune(other)
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return int64(levenshtein([]rune(s), otherRunes)), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("jaro_winkler",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Version("4.24.0").
+			Description(`Returns the [Jaro-Winkler similarity](https://en.wikipedia.org/wiki/Jaro%E2%80%93Winkler_distance) of a string and another as a number between 0 and 1, where 1 is an exact match and 0 means the strings have no characters in common. The similarity favours strings with a common prefix, which makes it well suited for comparing short strings such as names. The comparison is case sensitive.`).
+			Param(bloblang.NewStringParam("other").Description("The string to compare against.")).
+			Example("", `
+root.similarity = this.a.jaro_winkler(this.b)
+`,
+				[2]string{`{"a":"MARTHA","b":"MARHTA"}`, `{"similarity":0.9611111111111111}`},
+				[2]string{`{"a":"MARTHA","b":"ZZZ"}`, `{"similarity":0}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			other, err := args.GetString("other")
+			if err != nil {
+				return nil, err
+			}
+			otherRunes := []rune(other)
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return jaroWinkler([]rune(s), otherRunes), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("soundex",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Version("4.24.0").
+			Description(`Returns the [American Soundex](https://en.wikipedia.org/wiki/Soundex) code of a string, which is a letter followed by three digits that is shared by names that sound alike in English. Characters that are not ASCII letters are ignored, and an empty string is returned when the string contains no letters.`).
+			Example("", `
+root.codes = this.names.map_each(name -> name.soundex())
+`,
+				[2]string{`{"names":["Robert","Rupert","Ashcraft","Tymczak"]}`, `{"codes":["R163","R163","A261","T522"]}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return soundex(s), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("metaphone",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Version("4.24.0").
+			Description(`Returns the [Metaphone](https://en.wikipedia.org/wiki/Metaphone) key of a string, which is a phonetic encoding of how a word is pronounced in English that is more accurate than `+"[`soundex`](#soundex)"+`. Characters that are not ASCII letters are ignored. The character `+"`0`"+` is used to represent the "th" sound.`).
+			Example("", `
+root.keys = this.names.map_each(name -> name.metaphone())
+`,
+				[2]string{`{"names":["Knight","Night","Smith","Schmidt"]}`, `{"keys":["NT","NT","SM0","SKMTT"]}`},
+			).
+			Example("Keys can be compared in order to find words that sound alike.", `
+root.match = this.a.metaphone() == this.b.metaphone()
+`,
+				[2]string{`{"a":"Philip","b":"Filip"}`, `{"match":true}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return metaphone(s), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func levenshtein(a, b []rune) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur := minInt(minInt(row[j]+1, row[j-1]+1), prev+cost)
+			prev, row[j] = row[j], cur
+		}
+	}
+	return row[len(b)]
+}
+
+func jaro(a, b []rune) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	window := maxInt(len(a), len(b))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	aMatched := make([]bool, len(a))
+	bMatched := make([]bool, len(b))
+
+	var matches int
+	for i := range a {
+		for j := maxInt(0, i-window); j < minInt(len(b), i+window+1); j++ {
+			if bMatched[j] || a[i] != b[j] {
+				continue
+			}
+			aMatched[i], bMatched[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	var transpositions, j int
+	for i := range a {
+		if !aMatched[i] {
+			continue
+		}
+		for !bMatched[j] {
+			j++
+		}
+		if a[i] != b[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	return (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions)/2)/m) / 3
+}
+
+func jaroWinkler(a, b []rune) float64 {
+	sim := jaro(a, b)
+
+	var prefix int
+	for prefix < minInt(minInt(len(a), len(b)), 4) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	return sim + float64(prefix)*0.1*(1-sim)
+}
+
+// asciiLetters returns the ASCII letters of a string in upper case.
+func asciiLetters(s string) []byte {
+	letters := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < unicode.MaxASCII && unicode.IsLetter(r) {
+			letters = append(letters, byte(unicode.ToUpper(r)))
+		}
+	}
+	return letters
+}
+
+var soundexCodes = func() [26]byte {
+	var codes [26]byte
+	for code, letters := range []string{"AEIOUYHW", "BFPV", "CGJKQSXZ", "DT", "L", "MN", "R"} {
+		for _, l := range letters {
+			codes[l-'A'] = byte('0' + code)
+		}
+	}
+	return codes
+}()
+
+func soundex(s string) string {
+	letters := asciiLetters(s)
+	if len(letters) == 0 {
+		return ""
+	}
+
+	code := []byte{letters[0]}
+	last := soundexCodes[letters[0]-'A']
+	for _, l := range letters[1:] {
+		c := soundexCodes[l-'A']
+		if c != '0' && c != last {
+			if code = append(code, c); len(code) == 4 {
+				break
+			}
+		}
+		// H and W do not separate letters with the same code, whereas vowels
+		// do.
+		if l != 'H' && l != 'W' {
+			last = c
+		}
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// metaphone implements the original Metaphone algorithm by Lawrence Philips.
+func metaphone(s string) string {
+	w := asciiLetters(s)
+	if len(w) == 0 {
+		return ""
+	}
+
+	// Transformations of the beginning of the word.
+	if len(w) > 1 {
+		switch string(w[:2]) {
+		case "AE", "GN", "KN", "PN", "WR":
+			w = w[1:]
+		case "WH":
+			w = append([]byte{'W'}, w[2:]...)
+		}
+	}
+	if w[0] == 'X' {
+		w[0] = 'S'
+	}
+
+	at := func(i int) byte {
+		if i < 0 || i >= len(w) {
+			return 0
+		}
+		return w[i]
+	}
+	isVowel := func(i int) bool {
+		return strings.IndexByte("AEIOU", at(i)) >= 0
+	}
+	isFrontVowel := func(i int) bool {
+		return strings.IndexByte("EIY", at(i)) >= 0
+	}
+	matches := func(i int, str string) bool {
+		return i+len(str) <= len(w) && string(w[i:i+len(str)]) == str
+	}
+
+	var key strings.Builder
+	for i := 0; i < len(w); i++ {
+		c := w[i]
+		if c != 'C' && c == at(i-1) {
+			continue
+		}
+		switch c {
+		case 'A', 'E', 'I', 'O', 'U':
+			if i == 0 {
+				key.WriteByte(c)
+			}
+		case 'B':
+			if !(at(i-1) == 'M' && i == len(w)-1) {
+				key.WriteByte('B')
+			}
+		case 'C':
+			switch {
+			case at(i-1) == 'S' && isFrontVowel(i+1):
+				// Silent in SCI, SCE and SCY.
+			case matches(i, "CIA"):
+				key.WriteByte('X')
+			case isFrontVowel(i + 1):
+				key.WriteByte('S')
+			case at(i-1) == 'S' && at(i+1) == 'H':
+				key.WriteByte('K')
+			case at(i+1) == 'H':
+				if i == 0 && len(w) >= 3 && !isVowel(2) {
+					// Hard in words such as Christ and chrome.
+					key.WriteByte('K')
+				} else {
+					key.WriteByte('X')
+				}
+			default:
+				key.WriteByte('K')
+			}
+		case 'D':
+			if at(i+1) == 'G' && isFrontVowel(i+2) {
+				key.WriteByte('J')
+				i += 2
+			} else {
+				key.WriteByte('T')
+			}
+		case 'G':
+			switch {
+			case at(i+1) == 'H' && (i+1 == len(w)-1 || !isVowel(i+2)):
+				// Silent in GH when it is not followed by a vowel.
+			case i > 0 && (matches(i, "GNED") || (matches(i, "GN") && i+1 == len(w)-1)):
+				// Silent in GN and GNED at the end of words.
+			case isFrontVowel(i+1) && at(i-1) != 'G':
+				key.WriteByte('J')
+			default:
+				key.WriteByte('K')
+			}
+		case 'H':
+			if i < len(w)-1 && strings.IndexByte("CSPTG", at(i-1)) < 0 && isVowel(i+1) {
+				key.WriteByte('H')
+			}
+		case 'K':
+			if at(i-1) != 'C' {
+				key.WriteByte('K')
+			}
+		case 'P':
+			if at(i+1) == 'H' {
+				key.WriteByte('F')
+			} else {
+				key.WriteByte('P')
+			}
+		case 'Q':
+			key.WriteByte('K')
+		case 'S':
+			if matches(i, "SH") || matches(i, "SIO") || matches(i, "SIA") {
+				key.WriteByte('X')
+			} else {
+				key.WriteByte('S')
+			}
+		case 'T':
+			switch {
+			case matches(i, "TIA") || matches(i, "TIO"):
+				key.WriteByte('X')
+			case matches(i, "TCH"):
+				// Silent in TCH.
+			case matches(i, "TH"):
+				key.WriteByte('0')
+			default:
+				key.WriteByte('T')
+			}
+		case 'V':
+			key.WriteByte('F')
+		case 'W', 'Y':
+			if isVowel(i + 1) {
+				key.WriteByte(c)
+			}
+		case 'X':
+			key.WriteString("KS")
+		case 'Z':
+			key.WriteByte('S')
+		case 'F', 'J', 'L', 'M', 'N', 'R':
+			key.WriteByte(c)
+		}
+	}
+	return key.String()
+}
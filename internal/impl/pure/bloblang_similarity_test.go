@@ -0,0 +1,158 @@
+package pure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+func TestSimilarityMethods(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mapping string
+		input   any
+		output  any
+		execErr string
+	}{
+		{
+			name:    "levenshtein equal",
+			mapping: `root = this.levenshtein("benthos")`,
+			input:   "benthos",
+			output:  int64(0),
+		},
+		{
+			name:    "levenshtein empty",
+			mapping: `root = this.levenshtein("")`,
+			input:   "abc",
+			output:  int64(3),
+		},
+		{
+			name:    "levenshtein multibyte",
+			mapping: `root = this.levenshtein("naïve")`,
+			input:   "naive",
+			output:  int64(1),
+		},
+		{
+			name:    "levenshtein not a string",
+			mapping: `root = this.levenshtein("foo")`,
+			input:   int64(5),
+			execErr: "expected string value",
+		},
+		{
+			name:    "jaro winkler",
+			mapping: `root = this.jaro_winkler("DICKSONX")`,
+			input:   "DIXON",
+			output:  0.8133333333333332,
+		},
+		{
+			name:    "jaro winkler equal",
+			mapping: `root = this.jaro_winkler("same")`,
+			input:   "same",
+			output:  1.0,
+		},
+		{
+			name:    "jaro winkler empty",
+			mapping: `root = this.jaro_winkler("")`,
+			input:   "",
+			output:  1.0,
+		},
+		{
+			name:    "jaro winkler one empty",
+			mapping: `root = this.jaro_winkler("foo")`,
+			input:   "",
+			output:  0.0,
+		},
+		{
+			name:    "soundex skips letters matching the first",
+			mapping: `root = this.soundex()`,
+			input:   "Pfister",
+			output:  "P236",
+		},
+		{
+			name:    "soundex pads",
+			mapping: `root = this.soundex()`,
+			input:   "Lee",
+			output:  "L000",
+		},
+		{
+			name:    "soundex ignores non letters",
+			mapping: `root = this.soundex()`,
+			input:   "O'Hara",
+			output:  "O600",
+		},
+		{
+			name:    "soundex no letters",
+			mapping: `root = this.soundex()`,
+			input:   "1234",
+			output:  "",
+		},
+		{
+			name:    "metaphone initial letters",
+			mapping: `root = this.metaphone()`,
+			input:   "Wright",
+			output:  "RT",
+		},
+		{
+			name:    "metaphone initial x",
+			mapping: `root = this.metaphone()`,
+			input:   "Xavier",
+			output:  "SFR",
+		},
+		{
+			name:    "metaphone ch",
+			mapping: `root = this.metaphone()`,
+			input:   "church",
+			output:  "XRX",
+		},
+		{
+			name:    "metaphone hard ch",
+			mapping: `root = this.metaphone()`,
+			input:   "Christopher",
+			output:  "KRSTFR",
+		},
+		{
+			name:    "metaphone dg",
+			mapping: `root = this.metaphone()`,
+			input:   "edge",
+			output:  "EJ",
+		},
+		{
+			name:    "metaphone tion",
+			mapping: `root = this.metaphone()`,
+			input:   "nation",
+			output:  "NXN",
+		},
+		{
+			name:    "metaphone silent b",
+			mapping: `root = this.metaphone()`,
+			input:   "thumb",
+			output:  "0M",
+		},
+		{
+			name:    "metaphone empty",
+			mapping: `root = this.metaphone()`,
+			input:   "",
+			output:  "",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			if test.execErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, res)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.execErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package pure
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+// arrayNumbers returns the elements of an array as floats, returning an error
+// if the array is empty or contains a value that is not a number.
+func arrayNumbers(arr []any) ([]float64, error) {
+	if len(arr) == 0 {
+		return nil, errors.New("the array is empty")
+	}
+	nums := make([]float64, len(arr))
+	for i, v := range arr {
+		f, err := query.IGetNumber(v)
+		if err != nil {
+			return nil, fmt.Errorf("index %v: %w", i, err)
+		}
+		nums[i] = f
+	}
+	return nums, nil
+}
+
+func mean(nums []float64) float64 {
+	var sum float64
+	for _, n := range nums {
+		sum += n
+	}
+	return sum / float64(len(nums))
+}
+
+// percentile returns the p-th percentile of a sorted slice of numbers using
+// linear interpolation between the closest ranks.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[upper]-sorted[lower])
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("mean",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Version("4.24.0").
+			Description(`Returns the arithmetic mean of an array of numbers. An error is returned if the array is empty or contains values that are not numbers.`).
+			Example("", `
+root.mean = this.latencies.mean()
+`,
+				[2]string{`{"latencies":[4,8,15,16,23,42]}`, `{"mean":18}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.ArrayMethod(func(arr []any) (any, error) {
+				nums, err := arrayNumbers(arr)
+				if err != nil {
+					return nil, err
+				}
+				return mean(nums), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("stddev",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Version("4.24.0").
+			Description(`Returns the standard deviation of an array of numbers. By default the population standard deviation is calculated, and the sample standard deviation can be calculated instead with the parameter `+"`sample`"+`. An error is returned if the array is empty or contains values that are not numbers.`).
+			Param(bloblang.NewBoolParam("sample").Description("Whether to calculate the sample standard deviation, which requires at least two numbers.").Default(false)).
+			Example("", `
+root.stddev = this.values.stddev()
+root.sample_stddev = this.values.stddev(sample: true)
+`,
+				[2]string{`{"values":[2,4,4,4,5,5,7,9]}`, `{"sample_stddev":2.138089935299395,"stddev":2}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			sample, err := args.GetBool("sample")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.ArrayMethod(func(arr []any) (any, error) {
+				nums, err := arrayNumbers(arr)
+				if err != nil {
+					return nil, err
+				}
+				n := float64(len(nums))
+				if sample {
+					if len(nums) < 2 {
+						return nil, errors.New("the sample standard deviation requires at least two numbers")
+					}
+					n--
+				}
+				m := mean(nums)
+				var sumSquares float64
+				for _, v := range nums {
+					sumSquares += (v - m) * (v - m)
+				}
+				return math.Sqrt(sumSquares / n), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("percentile",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Version("4.24.0").
+			Description(`Returns the percentile of an array of numbers, interpolating linearly between the two closest values when the percentile falls between them. An error is returned if the array is empty or contains values that are not numbers.`).
+			Param(bloblang.NewFloat64Param("percentile").Description("The percentile to calculate, between 0 and 100.")).
+			Example("", `
+root.p50 = this.latencies.percentile(50)
+root.p90 = this.latencies.percentile(90)
+root.max = this.latencies.percentile(100)
+`,
+				[2]string{`{"latencies":[12,5,30,8,21]}`, `{"max":30,"p50":12,"p90":26.4}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			p, err := args.GetFloat64("percentile")
+			if err != nil {
+				return nil, err
+			}
+			if p < 0 || p > 100 {
+				return nil, fmt.Errorf("percentile must be between 0 and 100, got %v", p)
+			}
+			return bloblang.ArrayMethod(func(arr []any) (any, error) {
+				nums, err := arrayNumbers(arr)
+				if err != nil {
+					return nil, err
+				}
+				sort.Float64s(nums)
+				return percentile(nums, p), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,109 @@
+package pure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+func TestStatisticsMethods(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mapping  string
+		input    any
+		output   any
+		parseErr string
+		execErr  string
+	}{
+		{
+			name:    "mean",
+			mapping: `root = this.mean()`,
+			input:   []any{int64(1), 2.5, uint64(3), 4.5},
+			output:  2.75,
+		},
+		{
+			name:    "mean empty",
+			mapping: `root = this.mean()`,
+			input:   []any{},
+			execErr: "the array is empty",
+		},
+		{
+			name:    "mean not numbers",
+			mapping: `root = this.mean()`,
+			input:   []any{int64(1), "nope"},
+			execErr: "index 1",
+		},
+		{
+			name:    "stddev single value",
+			mapping: `root = this.stddev()`,
+			input:   []any{int64(5)},
+			output:  0.0,
+		},
+		{
+			name:    "sample stddev",
+			mapping: `root = this.stddev(true)`,
+			input:   []any{int64(1), int64(3)},
+			output:  1.4142135623730951,
+		},
+		{
+			name:    "sample stddev single value",
+			mapping: `root = this.stddev(true)`,
+			input:   []any{int64(5)},
+			execErr: "requires at least two numbers",
+		},
+		{
+			name:    "percentile min",
+			mapping: `root = this.percentile(0)`,
+			input:   []any{int64(3), int64(1), int64(2)},
+			output:  1.0,
+		},
+		{
+			name:    "percentile interpolated",
+			mapping: `root = this.percentile(25)`,
+			input:   []any{int64(10), int64(20), int64(30), int64(40)},
+			output:  17.5,
+		},
+		{
+			name:    "percentile single value",
+			mapping: `root = this.percentile(99)`,
+			input:   []any{int64(7)},
+			output:  7.0,
+		},
+		{
+			name:     "percentile out of range",
+			mapping:  `root = this.percentile(101)`,
+			parseErr: "percentile must be between 0 and 100, got 101",
+		},
+		{
+			name:    "percentile not an array",
+			mapping: `root = this.percentile(50)`,
+			input:   int64(5),
+			execErr: "expected array value",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			if test.parseErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.parseErr)
+				return
+			}
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			if test.execErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, res)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.execErr)
+			}
+		})
+	}
+}
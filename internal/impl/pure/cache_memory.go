@@ -1,6 +1,7 @@
 package pure
 
 import (
+	"bytes"
 	"context"
 	"sync"
 	"time"
@@ -239,6 +240,30 @@ func (m *memoryCache) Delete(_ context.Context, key string) error {
 	return nil
 }
 
+func (m *memoryCache) CompareAndSwap(_ context.Context, key string, old, value []byte, ttl *time.Duration) (bool, error) {
+	var expires time.Time
+	if ttl != nil {
+		expires = time.Now().Add(*ttl)
+	} else {
+		expires = time.Now().Add(m.defaultTTL)
+	}
+	shard := m.getShard(key)
+	shard.Lock()
+	defer shard.Unlock()
+
+	k, exists := shard.items[key]
+	if !exists || shard.isExpired(k) || !bytes.Equal(k.value, old) {
+		return false, nil
+	}
+	shard.compaction()
+	if value == nil {
+		delete(shard.items, key)
+	} else {
+		shard.items[key] = item{value: value, expires: expires}
+	}
+	return true, nil
+}
+
 func (m *memoryCache) Close(context.Context) error {
 	return nil
 }
@@ -73,6 +73,47 @@ func TestMemoryCache(t *testing.T) {
 	}
 }
 
+func TestMemoryCacheCompareAndSwap(t *testing.T) {
+	c := newMemCache(time.Millisecond*50, time.Millisecond, 1, map[string]string{})
+	ctx := context.Background()
+
+	swapped, err := c.CompareAndSwap(ctx, "foo", []byte("1"), []byte("2"), nil)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	require.NoError(t, c.Set(ctx, "foo", []byte("1"), nil))
+
+	swapped, err = c.CompareAndSwap(ctx, "foo", []byte("2"), []byte("3"), nil)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	ttl := time.Hour
+	swapped, err = c.CompareAndSwap(ctx, "foo", []byte("1"), []byte("2"), &ttl)
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	// The swap sets the TTL of the key.
+	time.Sleep(time.Millisecond * 100)
+	v, err := c.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(v))
+
+	swapped, err = c.CompareAndSwap(ctx, "foo", []byte("2"), nil, nil)
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	_, err = c.Get(ctx, "foo")
+	assert.Equal(t, service.ErrKeyNotFound, err)
+
+	// Expired keys never match.
+	require.NoError(t, c.Set(ctx, "bar", []byte("1"), nil))
+	time.Sleep(time.Millisecond * 100)
+
+	swapped, err = c.CompareAndSwap(ctx, "bar", []byte("1"), []byte("2"), nil)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+}
+
 func TestMemoryCacheCompaction(t *testing.T) {
 	defConf, err := memCacheConfig().ParseYAML(`
 default_ttl: 0s
@@ -0,0 +1,194 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	dinterop "github.com/benthosdev/benthos/v4/internal/docs/interop"
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	ctrFieldCache  = "cache"
+	ctrFieldPrefix = "prefix"
+	ctrFieldTTL    = "ttl"
+)
+
+func counterSpecFields() []*service.ConfigField {
+	return []*service.ConfigField{
+		service.NewStringField(ctrFieldCache).
+			Description("An optional [cache resource](/docs/components/caches/about) that supports atomic increments, such as `redis`, to store counters within so that they are shared between Benthos instances. By default counters are stored in memory.").
+			Optional(),
+		service.NewStringField(ctrFieldPrefix).
+			Description("A prefix to add to the keys of counters stored within the `cache`.").
+			Default(""),
+		service.NewDurationField(ctrFieldTTL).
+			Description("An optional period after which a counter is reset to zero once it was last incremented or decremented.").
+			Example("1h").
+			Optional(),
+	}
+}
+
+func counterSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().Fields(counterSpecFields()...)
+}
+
+func init() {
+	fields := docs.FieldSpecs{
+		docs.FieldString("label", "A unique label for the resource, which is referenced by the `counter_incr`, `counter_decr` and `counter_read` Bloblang functions."),
+	}
+	for _, f := range counterSpecFields() {
+		fields = append(fields, dinterop.Unwrap(f))
+	}
+	manager.CounterResourceFields = fields
+	manager.CounterConstructor = newCounterFromRaw
+}
+
+//------------------------------------------------------------------------------
+
+func newCounterFromRaw(fields map[string]any, nm bundle.NewManagement) (any, error) {
+	confBytes, err := yaml.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := counterSpec().ParseYAML(string(confBytes), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ttl *time.Duration
+	if conf.Contains(ctrFieldTTL) {
+		t, err := conf.FieldDuration(ctrFieldTTL)
+		if err != nil {
+			return nil, err
+		}
+		ttl = &t
+	}
+
+	if !conf.Contains(ctrFieldCache) {
+		return &memoryCounter{ttl: ttl, counters: map[string]*memoryCounterItem{}}, nil
+	}
+
+	c := &cacheCounter{nm: nm, ttl: ttl}
+	if c.cacheName, err = conf.FieldString(ctrFieldCache); err != nil {
+		return nil, err
+	}
+	if !nm.ProbeCache(c.cacheName) {
+		return nil, fmt.Errorf("cache resource '%v' was not found", c.cacheName)
+	}
+	if c.prefix, err = conf.FieldString(ctrFieldPrefix); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+//------------------------------------------------------------------------------
+
+type memoryCounterItem struct {
+	value   int64
+	expires time.Time
+}
+
+// memoryCounter stores counters in memory, where counters with a TTL are
+// removed lazily when they are next accessed after expiring.
+type memoryCounter struct {
+	ttl *time.Duration
+
+	mut      sync.Mutex
+	counters map[string]*memoryCounterItem
+}
+
+// get returns the item of a counter if it exists and has not expired, must be
+// called whilst holding the lock.
+func (m *memoryCounter) get(key string) *memoryCounterItem {
+	item, exists := m.counters[key]
+	if !exists {
+		return nil
+	}
+	if !item.expires.IsZero() && !time.Now().Before(item.expires) {
+		delete(m.counters, key)
+		return nil
+	}
+	return item
+}
+
+func (m *memoryCounter) Incr(ctx context.Context, key string, delta int64) (int64, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	item := m.get(key)
+	if item == nil {
+		item = &memoryCounterItem{}
+		m.counters[key] = item
+	}
+	item.value += delta
+	if m.ttl != nil {
+		item.expires = time.Now().Add(*m.ttl)
+	}
+	return item.value, nil
+}
+
+func (m *memoryCounter) Read(ctx context.Context, key string) (int64, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if item := m.get(key); item != nil {
+		return item.value, nil
+	}
+	return 0, nil
+}
+
+//------------------------------------------------------------------------------
+
+// cacheCounter stores counters within a cache resource that supports atomic
+// increments.
+type cacheCounter struct {
+	nm        bundle.NewManagement
+	cacheName string
+	prefix    string
+	ttl       *time.Duration
+}
+
+func (c *cacheCounter) Incr(ctx context.Context, key string, delta int64) (v int64, err error) {
+	if cerr := c.nm.AccessCache(ctx, c.cacheName, func(ca cache.V1) {
+		incr, ok := ca.(cache.Incrementer)
+		if !ok {
+			err = cache.ErrIncrNotSupported
+			return
+		}
+		v, err = incr.Incr(ctx, c.prefix+key, delta, c.ttl)
+	}); cerr != nil {
+		return 0, cerr
+	}
+	return
+}
+
+func (c *cacheCounter) Read(ctx context.Context, key string) (v int64, err error) {
+	var b []byte
+	if cerr := c.nm.AccessCache(ctx, c.cacheName, func(ca cache.V1) {
+		b, err = ca.Get(ctx, c.prefix+key)
+	}); cerr != nil {
+		return 0, cerr
+	}
+	if err != nil {
+		if errors.Is(err, component.ErrKeyNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if v, err = strconv.ParseInt(string(b), 10, 64); err != nil {
+		return 0, fmt.Errorf("cached counter value is not an integer: %w", err)
+	}
+	return v, nil
+}
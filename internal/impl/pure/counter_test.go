@@ -0,0 +1,123 @@
+package pure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func TestCounterFunctions(t *testing.T) {
+	var conf manager.ResourceConfig
+	require.NoError(t, yaml.Unmarshal([]byte(`
+counter_resources:
+  - label: seq
+  - label: quotas
+`), &conf))
+
+	mgr, err := manager.New(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		mgr.TriggerCloseNow()
+		require.NoError(t, mgr.WaitForClose(context.Background()))
+	})
+
+	exec, err := mgr.BloblEnvironment().NewMapping(`
+root.seq = counter_incr("seq")
+root.used = counter_incr("quotas", this.user, this.cost)
+root.refund = if this.cost > 2 { counter_decr("quotas", this.user) }
+root.total = counter_read("quotas", this.user)
+`)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		input, output string
+	}{
+		{input: `{"user":"a","cost":1}`, output: `{"seq":1,"total":1,"used":1}`},
+		{input: `{"user":"b","cost":3}`, output: `{"refund":2,"seq":2,"total":2,"used":3}`},
+		{input: `{"user":"a","cost":2}`, output: `{"seq":3,"total":3,"used":3}`},
+	} {
+		p, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(test.input)}))
+		require.NoError(t, err)
+		assert.Equal(t, test.output, string(p.AsBytes()), test.input)
+	}
+
+	_, err = mgr.BloblEnvironment().NewMapping(`root = counter_incr("nope")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to locate resource: nope")
+}
+
+func TestCounterNoManager(t *testing.T) {
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	exec, err := mgr.BloblEnvironment().NewMapping(`root = counter_incr("seq")`)
+	require.NoError(t, err)
+
+	_, err = exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "counter resource 'seq' was not found")
+}
+
+func TestCounterMemoryTTL(t *testing.T) {
+	c, err := newCounterFromRaw(map[string]any{"ttl": "50ms"}, mock.NewManager())
+	require.NoError(t, err)
+	counter := c.(manager.Counter)
+
+	ctx := context.Background()
+	v, err := counter.Incr(ctx, "foo", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), v)
+
+	assert.Eventually(t, func() bool {
+		v, err := counter.Read(ctx, "foo")
+		return err == nil && v == 0
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestCounterCache(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"ctr_bar": {Value: "10"},
+	}
+
+	_, err := newCounterFromRaw(map[string]any{"cache": "nope"}, mgr)
+	require.Error(t, err)
+
+	c, err := newCounterFromRaw(map[string]any{
+		"cache":  "foocache",
+		"prefix": "ctr_",
+		"ttl":    "1h",
+	}, mgr)
+	require.NoError(t, err)
+	counter := c.(manager.Counter)
+
+	ctx := context.Background()
+	v, err := counter.Read(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), v)
+
+	v, err = counter.Incr(ctx, "foo", 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), v)
+
+	v, err = counter.Incr(ctx, "bar", -1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), v)
+
+	v, err = counter.Read(ctx, "bar")
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), v)
+
+	item := mgr.Caches["foocache"]["ctr_foo"]
+	assert.Equal(t, "3", item.Value)
+	require.NotNil(t, item.TTL)
+	assert.Equal(t, time.Hour, *item.TTL)
+}
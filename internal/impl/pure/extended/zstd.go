@@ -9,9 +9,16 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/impl/pure"
 )
 
+func zstdEncoderOpts(level int) []zstd.EOption {
+	if level <= 0 {
+		return nil
+	}
+	return []zstd.EOption{zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level))}
+}
+
 var _ = pure.AddCompressFunc("zstd", func(level int, b []byte) ([]byte, error) {
 	buf := &bytes.Buffer{}
-	w, err := zstd.NewWriter(buf)
+	w, err := zstd.NewWriter(buf, zstdEncoderOpts(level)...)
 	if err != nil {
 		return nil, err
 	}
@@ -39,3 +46,23 @@ var _ = pure.AddDecompressFunc("zstd", func(b []byte) ([]byte, error) {
 	r.Close()
 	return outBuf.Bytes(), nil
 })
+
+var _ = pure.AddCompressDictFunc("zstd", func(level int, dict []byte) (pure.CompressFunc, error) {
+	enc, err := zstd.NewWriter(nil, append(zstdEncoderOpts(level), zstd.WithEncoderDict(dict))...)
+	if err != nil {
+		return nil, err
+	}
+	return func(_ int, b []byte) ([]byte, error) {
+		return enc.EncodeAll(b, nil), nil
+	}, nil
+})
+
+var _ = pure.AddDecompressDictFunc("zstd", func(dict []byte) (pure.DecompressFunc, error) {
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderDicts(dict))
+	if err != nil {
+		return nil, err
+	}
+	return func(b []byte) ([]byte, error) {
+		return dec.DecodeAll(b, nil)
+	}, nil
+})
@@ -1,12 +1,22 @@
 package extended
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/klauspost/compress/dict"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/public/bloblang"
+
+	_ "github.com/benthosdev/benthos/v4/internal/impl/pure"
 )
 
 func TestZstdCompressionDecompression(t *testing.T) {
@@ -29,3 +39,51 @@ func TestZstdCompressionDecompression(t *testing.T) {
 
 	assert.Equal(t, input, decompressed)
 }
+
+func TestZstdProcessorsDictionary(t *testing.T) {
+	var samples [][]byte
+	for i := 0; i < 1000; i++ {
+		samples = append(samples, []byte(fmt.Sprintf(`{"id":"%v","type":"page_view","user":{"name":"user %v","region":"eu-west-%v"}}`, i, i%50, i%3)))
+	}
+	d, err := dict.BuildZstdDict(samples, dict.Options{
+		MaxDictSize: 4096,
+		HashBytes:   6,
+	})
+	require.NoError(t, err)
+
+	dictPath := filepath.Join(t.TempDir(), "events.dict")
+	require.NoError(t, os.WriteFile(dictPath, d, 0o644))
+
+	compConf := processor.NewConfig()
+	compConf.Type = "compress"
+	compConf.Compress.Algorithm = "zstd"
+	compConf.Compress.Level = 3
+	compConf.Compress.Dictionary = dictPath
+
+	comp, err := mock.NewManager().NewProcessor(compConf)
+	require.NoError(t, err)
+
+	decompConf := processor.NewConfig()
+	decompConf.Type = "decompress"
+	decompConf.Decompress.Algorithm = "zstd"
+	decompConf.Decompress.Dictionary = dictPath
+
+	decomp, err := mock.NewManager().NewProcessor(decompConf)
+	require.NoError(t, err)
+
+	input := []byte(`{"id":"1234","type":"page_view","user":{"name":"user 12","region":"eu-west-1"}}`)
+
+	compressed, res := comp.ProcessBatch(context.Background(), message.QuickBatch([][]byte{input}))
+	require.NoError(t, res)
+	require.Len(t, compressed, 1)
+	assert.Less(t, len(compressed[0].Get(0).AsBytes()), len(input))
+
+	decompressed, res := decomp.ProcessBatch(context.Background(), compressed[0])
+	require.NoError(t, res)
+	require.Len(t, decompressed, 1)
+	assert.Equal(t, string(input), string(decompressed[0].Get(0).AsBytes()))
+
+	compConf.Compress.Algorithm = "gzip"
+	_, err = mock.NewManager().NewProcessor(compConf)
+	require.Error(t, err)
+}
@@ -0,0 +1,296 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/component/interop"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	abFieldInput   = "input"
+	abFieldCount   = "count"
+	abFieldPeriod  = "period"
+	abFieldOrdered = "ordered"
+)
+
+func ackBatchedInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Consumes data from a child input and delivers acknowledgements back to it in bursts, either once a number of messages have been acknowledged or after a period of time.").
+		Description(`
+This input holds acknowledgements from the rest of the pipeline and delivers them to the child input in bursts. Each acknowledgement is still delivered to the child input individually, and so this input only reduces overhead for child inputs that buffer acknowledgements themselves before committing or deleting messages, where a burst of acknowledgements is more likely to be coalesced into a single call.
+
+Acknowledgements are delivered once `+"`count`"+` of them are pending, or when `+"`period`"+` has elapsed since the last delivery, whichever comes first. Negative acknowledgements (message rejections) are held and delivered in the same way.
+
+When `+"`ordered`"+` is set to `+"`true`"+` acknowledgements are only ever delivered in the order that messages were consumed, and therefore an acknowledged message is held until every message consumed before it has also been acknowledged. This is useful for sources where acknowledging a message implicitly acknowledges all prior messages, such as committing an offset.
+
+Since acknowledgements are delayed, the child input may hit its limit of messages in flight before `+"`count`"+` is reached, in which case acknowledgements are delivered once the `+"`period`"+` elapses. When the input is shut down acknowledgements are delivered as soon as they are ready.`).
+		Fields(
+			service.NewInputField(abFieldInput).
+				Description("The child input."),
+			service.NewIntField(abFieldCount).
+				Description("The number of pending acknowledgements that triggers a delivery. Set to `0` in order to deliver only based on the `period`.").
+				Default(100),
+			service.NewDurationField(abFieldPeriod).
+				Description("The maximum period of time that acknowledgements are held before being delivered.").
+				Default("1s"),
+			service.NewBoolField(abFieldOrdered).
+				Description("Whether acknowledgements must be delivered in the order that messages were consumed.").
+				Default(false),
+		)
+}
+
+func init() {
+	err := service.RegisterBatchInput(
+		"ack_batched", ackBatchedInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			count, err := conf.FieldInt(abFieldCount)
+			if err != nil {
+				return nil, err
+			}
+			if count < 0 {
+				return nil, errors.New("count must not be negative")
+			}
+			period, err := conf.FieldDuration(abFieldPeriod)
+			if err != nil {
+				return nil, err
+			}
+			if period <= 0 {
+				return nil, errors.New("period must be greater than zero")
+			}
+			ordered, err := conf.FieldBool(abFieldOrdered)
+			if err != nil {
+				return nil, err
+			}
+			child, err := conf.FieldInput(abFieldInput)
+			if err != nil {
+				return nil, err
+			}
+			return interop.NewUnwrapInternalInput(newAckBatchedInput(interop.UnwrapOwnedInput(child), count, period, ordered)), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type pendingAck struct {
+	tran     message.Transaction
+	tracked  bool
+	resolved bool
+	err      error
+}
+
+type ackBatchedInput struct {
+	child   input.Streamed
+	count   int
+	period  time.Duration
+	ordered bool
+
+	mut      sync.Mutex
+	pending  []*pendingAck
+	resolved int
+	draining bool
+	flushSig chan struct{}
+
+	transactions chan message.Transaction
+	shutSig      *shutdown.Signaller
+}
+
+func newAckBatchedInput(child input.Streamed, count int, period time.Duration, ordered bool) *ackBatchedInput {
+	a := &ackBatchedInput{
+		child:        child,
+		count:        count,
+		period:       period,
+		ordered:      ordered,
+		flushSig:     make(chan struct{}, 1),
+		transactions: make(chan message.Transaction),
+		shutSig:      shutdown.NewSignaller(),
+	}
+	go a.loop()
+	return a
+}
+
+// wrap returns a transaction that holds its acknowledgement until it is
+// delivered by the flusher, which only happens once the pending ack is tracked.
+func (a *ackBatchedInput) wrap(tran message.Transaction) (message.Transaction, *pendingAck) {
+	p := &pendingAck{tran: tran}
+
+	ackTran := message.NewTransactionFunc(tran.Payload, func(ctx context.Context, err error) error {
+		a.mut.Lock()
+		if !p.resolved {
+			p.resolved, p.err = true, err
+			if p.tracked {
+				a.resolved++
+			}
+		}
+		ready := a.draining || (a.count > 0 && a.resolved >= a.count)
+		a.mut.Unlock()
+
+		if ready {
+			select {
+			case a.flushSig <- struct{}{}:
+			default:
+			}
+		}
+		return nil
+	})
+	return *ackTran.WithContext(tran.Context()), p
+}
+
+// track adds a pending ack of a transaction that has been sent downstream.
+func (a *ackBatchedInput) track(p *pendingAck) {
+	a.mut.Lock()
+	p.tracked = true
+	a.pending = append(a.pending, p)
+	if p.resolved {
+		a.resolved++
+	}
+	a.mut.Unlock()
+}
+
+// flush delivers all acknowledgements that are ready to the child input.
+func (a *ackBatchedInput) flush(ctx context.Context) {
+	a.mut.Lock()
+	var ready []*pendingAck
+	remaining := a.pending[:0]
+	for i, p := range a.pending {
+		if !p.resolved {
+			if a.ordered {
+				remaining = append(remaining, a.pending[i:]...)
+				break
+			}
+			remaining = append(remaining, p)
+			continue
+		}
+		ready = append(ready, p)
+	}
+	for i := len(remaining); i < len(a.pending); i++ {
+		a.pending[i] = nil
+	}
+	a.pending = remaining
+	a.resolved -= len(ready)
+	a.mut.Unlock()
+
+	for _, p := range ready {
+		_ = p.tran.Ack(ctx, p.err)
+	}
+}
+
+func (a *ackBatchedInput) loop() {
+	// Closed once no more transactions will be tracked, at which point the
+	// flusher drains the remaining acknowledgements.
+	consumeDone := make(chan struct{})
+
+	flusherDone := make(chan struct{})
+	go func() {
+		defer close(flusherDone)
+
+		ctx, done := a.shutSig.CloseNowCtx(context.Background())
+		defer done()
+
+		ticker := time.NewTicker(a.period)
+		defer ticker.Stop()
+
+		consumeDoneChan := consumeDone
+		for {
+			select {
+			case <-ticker.C:
+			case <-a.flushSig:
+				ticker.Reset(a.period)
+			case <-consumeDoneChan:
+				// Continue delivering acknowledgements as soon as they resolve
+				// until there are none pending.
+				consumeDoneChan = nil
+				a.mut.Lock()
+				a.draining = true
+				a.mut.Unlock()
+			case <-ctx.Done():
+				return
+			}
+			a.flush(ctx)
+
+			if consumeDoneChan == nil {
+				a.mut.Lock()
+				pending := len(a.pending)
+				a.mut.Unlock()
+				if pending == 0 {
+					return
+				}
+			}
+		}
+	}()
+
+	defer func() {
+		close(consumeDone)
+		a.shutSig.CloseAtLeisure()
+		<-flusherDone
+
+		a.child.TriggerStopConsuming()
+		a.child.TriggerCloseNow()
+		_ = a.child.WaitForClose(context.Background())
+
+		close(a.transactions)
+		a.shutSig.ShutdownComplete()
+	}()
+
+	for {
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-a.child.TransactionChan():
+			if !open {
+				return
+			}
+		case <-a.shutSig.CloseAtLeisureChan():
+			return
+		}
+
+		// The transaction is only tracked once sent, otherwise it would never
+		// be acknowledged and would block the flusher from draining.
+		ackTran, p := a.wrap(tran)
+		select {
+		case a.transactions <- ackTran:
+			a.track(p)
+		case <-a.shutSig.CloseAtLeisureChan():
+			_ = tran.Ack(context.Background(), component.ErrTypeClosed)
+			return
+		}
+	}
+}
+
+func (a *ackBatchedInput) TransactionChan() <-chan message.Transaction {
+	return a.transactions
+}
+
+func (a *ackBatchedInput) Connected() bool {
+	return a.child.Connected()
+}
+
+func (a *ackBatchedInput) TriggerStopConsuming() {
+	a.shutSig.CloseAtLeisure()
+}
+
+func (a *ackBatchedInput) TriggerCloseNow() {
+	a.shutSig.CloseNow()
+}
+
+func (a *ackBatchedInput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-a.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
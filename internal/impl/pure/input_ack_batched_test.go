@@ -0,0 +1,199 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	bmock "github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+type ackRecorder struct {
+	mut  sync.Mutex
+	acks []string
+}
+
+func (r *ackRecorder) tran(id string) message.Transaction {
+	return message.NewTransactionFunc(message.QuickBatch([][]byte{[]byte(id)}), func(ctx context.Context, err error) error {
+		r.mut.Lock()
+		defer r.mut.Unlock()
+		if err != nil {
+			id += ":" + err.Error()
+		}
+		r.acks = append(r.acks, id)
+		return nil
+	})
+}
+
+func (r *ackRecorder) get() []string {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return append([]string(nil), r.acks...)
+}
+
+func readAckBatchedTrans(t testing.TB, in *ackBatchedInput, child *bmock.Input, rec *ackRecorder, n int) []message.Transaction {
+	t.Helper()
+
+	var trans []message.Transaction
+	for i := 0; i < n; i++ {
+		child.TChan <- rec.tran(strconv.Itoa(i))
+		select {
+		case tran := <-in.TransactionChan():
+			trans = append(trans, tran)
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+	}
+	return trans
+}
+
+func closeAckBatchedInput(t testing.TB, in *ackBatchedInput) {
+	t.Helper()
+
+	in.TriggerStopConsuming()
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+	require.NoError(t, in.WaitForClose(ctx))
+}
+
+func TestAckBatchedInputCount(t *testing.T) {
+	rec := &ackRecorder{}
+	child := &bmock.Input{TChan: make(chan message.Transaction)}
+	in := newAckBatchedInput(child, 3, time.Hour, false)
+
+	trans := readAckBatchedTrans(t, in, child, rec, 4)
+
+	require.NoError(t, trans[1].Ack(context.Background(), nil))
+	require.NoError(t, trans[0].Ack(context.Background(), errors.New("nope")))
+	time.Sleep(time.Millisecond * 50)
+	assert.Empty(t, rec.get())
+
+	require.NoError(t, trans[3].Ack(context.Background(), nil))
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"0:nope", "1", "3"}, rec.get())
+	}, time.Second*5, time.Millisecond*10)
+
+	require.NoError(t, trans[2].Ack(context.Background(), nil))
+	closeAckBatchedInput(t, in)
+	assert.Equal(t, []string{"0:nope", "1", "3", "2"}, rec.get())
+}
+
+func TestAckBatchedInputPeriod(t *testing.T) {
+	rec := &ackRecorder{}
+	child := &bmock.Input{TChan: make(chan message.Transaction)}
+	in := newAckBatchedInput(child, 0, time.Millisecond*50, false)
+
+	trans := readAckBatchedTrans(t, in, child, rec, 3)
+
+	require.NoError(t, trans[2].Ack(context.Background(), nil))
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"2"}, rec.get())
+	}, time.Second*5, time.Millisecond*10)
+
+	require.NoError(t, trans[0].Ack(context.Background(), nil))
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"2", "0"}, rec.get())
+	}, time.Second*5, time.Millisecond*10)
+
+	require.NoError(t, trans[1].Ack(context.Background(), nil))
+	closeAckBatchedInput(t, in)
+	assert.Equal(t, []string{"2", "0", "1"}, rec.get())
+}
+
+func TestAckBatchedInputOrdered(t *testing.T) {
+	rec := &ackRecorder{}
+	child := &bmock.Input{TChan: make(chan message.Transaction)}
+	in := newAckBatchedInput(child, 2, time.Millisecond*20, true)
+
+	trans := readAckBatchedTrans(t, in, child, rec, 4)
+
+	require.NoError(t, trans[3].Ack(context.Background(), nil))
+	require.NoError(t, trans[1].Ack(context.Background(), nil))
+	time.Sleep(time.Millisecond * 100)
+	assert.Empty(t, rec.get())
+
+	require.NoError(t, trans[0].Ack(context.Background(), nil))
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"0", "1"}, rec.get())
+	}, time.Second*5, time.Millisecond*10)
+
+	require.NoError(t, trans[2].Ack(context.Background(), nil))
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"0", "1", "2", "3"}, rec.get())
+	}, time.Second*5, time.Millisecond*10)
+
+	closeAckBatchedInput(t, in)
+}
+
+func TestAckBatchedInputDrainsOnShutdown(t *testing.T) {
+	rec := &ackRecorder{}
+	child := &bmock.Input{TChan: make(chan message.Transaction)}
+	in := newAckBatchedInput(child, 10, time.Hour, true)
+
+	trans := readAckBatchedTrans(t, in, child, rec, 2)
+	require.NoError(t, trans[1].Ack(context.Background(), nil))
+
+	in.TriggerStopConsuming()
+	time.Sleep(time.Millisecond * 50)
+	assert.Empty(t, rec.get())
+
+	require.NoError(t, trans[0].Ack(context.Background(), nil))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+	require.NoError(t, in.WaitForClose(ctx))
+	assert.Equal(t, []string{"0", "1"}, rec.get())
+}
+
+func TestAckBatchedInputRejectsUnsentOnShutdown(t *testing.T) {
+	rec := &ackRecorder{}
+	child := &bmock.Input{TChan: make(chan message.Transaction)}
+	in := newAckBatchedInput(child, 10, time.Hour, false)
+
+	// Consumed from the child but never read downstream.
+	child.TChan <- rec.tran("0")
+
+	closeAckBatchedInput(t, in)
+	assert.Equal(t, []string{"0:" + component.ErrTypeClosed.Error()}, rec.get())
+}
+
+func TestAckBatchedInputStream(t *testing.T) {
+	builder := service.NewStreamBuilder()
+	require.NoError(t, builder.AddInputYAML(`
+ack_batched:
+  count: 3
+  period: 10ms
+  ordered: true
+  input:
+    generate:
+      mapping: 'root.id = count("TEST_ACK_BATCHED_INPUT_STREAM")'
+      count: 10
+      interval: ""
+`))
+
+	var outMsgs []string
+	require.NoError(t, builder.AddConsumerFunc(func(ctx context.Context, msg *service.Message) error {
+		b, err := msg.AsBytes()
+		require.NoError(t, err)
+		outMsgs = append(outMsgs, string(b))
+		return nil
+	}))
+
+	strm, err := builder.Build()
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+	require.NoError(t, strm.Run(ctx))
+
+	assert.Len(t, outMsgs, 10)
+}
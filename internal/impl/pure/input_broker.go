@@ -3,7 +3,6 @@ package pure
 import (
 	"errors"
 	"fmt"
-	"strconv"
 
 	"github.com/benthosdev/benthos/v4/internal/batch/policy"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
@@ -101,7 +100,7 @@ func newBrokerInput(conf input.Config, mgr bundle.NewManagement) (input.Streamed
 
 		for j := 0; j < conf.Broker.Copies; j++ {
 			for i, iConf := range conf.Broker.Inputs {
-				iMgr := mgr.IntoPath("broker", "inputs", strconv.Itoa(i))
+				iMgr := mgr.IntoPath("broker", "inputs", bundle.PathSegment(i, iConf.Label))
 				inputs[len(conf.Broker.Inputs)*j+i], err = iMgr.NewInput(iConf)
 				if err != nil {
 					return nil, err
@@ -0,0 +1,165 @@
+package pure
+
+import (
+	"context"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/interop"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	itFieldTopic      = "topic"
+	itFieldBufferSize = "buffer_size"
+)
+
+func inprocTopicInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Subscribes to a named in-process topic, receiving a copy of every message published to it by [`inproc_topic` outputs](/docs/components/outputs/inproc_topic).").
+		Description(`
+Unlike the `+"[`inproc` input](/docs/components/inputs/inproc)"+`, where connected inputs compete for messages, each `+"`inproc_topic`"+` input subscribed to a topic receives its own copy of every message published to it. This allows a single stream to fan out data to any number of other streams whilst running Benthos in `+"[streams mode](/docs/guides/streams_mode/about)"+`.
+
+Subscribers are independent of one another: a message is only acknowledged upstream once every subscriber has delivered it successfully, and a subscriber that rejects a message will have it redelivered without affecting other subscribers.
+
+Messages are only received whilst subscribed, and therefore messages published to a topic before this input is started, or after it has been shut down, are not received by it.
+
+### Backpressure
+
+Each subscriber buffers up to `+"`buffer_size`"+` messages, once the buffer of any subscriber is full publishers will block until space becomes available, and therefore a topic flows at the pace of its slowest subscriber.`).
+		Fields(
+			service.NewStringField(itFieldTopic).
+				Description("The name of the topic to subscribe to."),
+			service.NewIntField(itFieldBufferSize).
+				Description("The maximum number of messages to buffer for this subscriber before publishers are blocked.").
+				Default(10).
+				Advanced(),
+		)
+}
+
+func init() {
+	err := service.RegisterBatchInput(
+		"inproc_topic", inprocTopicInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			topic, err := conf.FieldString(itFieldTopic)
+			if err != nil {
+				return nil, err
+			}
+			bufferSize, err := conf.FieldInt(itFieldBufferSize)
+			if err != nil {
+				return nil, err
+			}
+			if bufferSize < 0 {
+				bufferSize = 0
+			}
+			return interop.NewUnwrapInternalInput(newInprocTopicInput(topic, bufferSize, interop.UnwrapManagement(mgr))), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type inprocTopicInput struct {
+	topic string
+	mgr   bundle.NewManagement
+	log   log.Modular
+
+	subChan chan message.Transaction
+	done    chan struct{}
+	sub     *bundle.TopicSubscriber
+
+	transactions chan message.Transaction
+
+	shutSig *shutdown.Signaller
+}
+
+func newInprocTopicInput(topic string, bufferSize int, mgr bundle.NewManagement) *inprocTopicInput {
+	i := &inprocTopicInput{
+		topic:        topic,
+		mgr:          mgr,
+		log:          mgr.Logger(),
+		subChan:      make(chan message.Transaction, bufferSize),
+		done:         make(chan struct{}),
+		transactions: make(chan message.Transaction),
+		shutSig:      shutdown.NewSignaller(),
+	}
+	i.sub = &bundle.TopicSubscriber{
+		Transactions: i.subChan,
+		Done:         i.done,
+	}
+
+	i.mgr.SubscribeTopic(i.topic, i.sub)
+	go i.loop()
+	return i
+}
+
+func (i *inprocTopicInput) loop() {
+	defer func() {
+		i.mgr.UnsubscribeTopic(i.topic, i.sub)
+		close(i.done)
+
+		// Any transactions remaining in our buffer are rejected, publishers
+		// will abandon them as our done chan is now closed.
+	drainLoop:
+		for {
+			select {
+			case t := <-i.subChan:
+				_ = t.Ack(context.Background(), component.ErrTypeClosed)
+			default:
+				break drainLoop
+			}
+		}
+
+		close(i.transactions)
+		i.shutSig.ShutdownComplete()
+	}()
+
+	i.log.Infof("Receiving inproc messages from topic: %s\n", i.topic)
+
+	for {
+		select {
+		case t := <-i.subChan:
+			select {
+			case i.transactions <- t:
+			case <-i.shutSig.CloseAtLeisureChan():
+				_ = t.Ack(context.Background(), component.ErrTypeClosed)
+				return
+			}
+		case <-i.shutSig.CloseAtLeisureChan():
+			return
+		}
+	}
+}
+
+func (i *inprocTopicInput) TransactionChan() <-chan message.Transaction {
+	return i.transactions
+}
+
+func (i *inprocTopicInput) Connected() bool {
+	return true
+}
+
+func (i *inprocTopicInput) TriggerStopConsuming() {
+	i.shutSig.CloseAtLeisure()
+}
+
+func (i *inprocTopicInput) TriggerCloseNow() {
+	i.shutSig.CloseNow()
+}
+
+func (i *inprocTopicInput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-i.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
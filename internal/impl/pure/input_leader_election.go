@@ -0,0 +1,519 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/component/interop"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	leFieldInput         = "input"
+	leFieldIdentity      = "identity"
+	leFieldLeaseDuration = "lease_duration"
+	leFieldRenewDeadline = "renew_deadline"
+	leFieldRetryPeriod   = "retry_period"
+	leFieldKubeLease     = "kubernetes_lease"
+	leFieldKubeName      = "name"
+	leFieldKubeNamespace = "namespace"
+	leFieldKubeAPIURL    = "api_url"
+	leFieldKubeTokenFile = "token_file"
+	leFieldKubeCAFile    = "ca_file"
+	leFieldCache         = "cache"
+	leFieldCacheResource = "resource"
+	leFieldCacheKey      = "key"
+)
+
+func leaderElectionInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Consumes from a child input only whilst this instance holds a leadership lease, allowing inputs that must run as singletons to be deployed with multiple replicas.").
+		Description(`
+Some inputs must only ever be consumed by a single instance at a time, such as CDC replication slots, polling an SFTP directory or generating messages on a cron schedule. This input allows such pipelines to be deployed with multiple replicas where exactly one replica consumes from the child input at any given time, and another replica takes over automatically when the leader fails.
+
+Each instance periodically attempts to acquire or renew a lease every `+"`retry_period`"+`. The instance holding the lease constructs the child input and consumes from it, whereas all other instances remain on standby without connecting to the underlying source. When the leader fails to renew its lease within the `+"`renew_deadline`"+` it closes the child input, and once the lease has not been renewed for `+"`lease_duration`"+` another instance acquires it. Therefore the `+"`renew_deadline`"+` must be shorter than the `+"`lease_duration`"+` in order to ensure that two instances are never consuming at the same time.
+
+Messages that are in flight when leadership is lost are rejected so that they can be redelivered by the source. If the child input closes itself then the lease is released and this input also closes.
+
+Whilst on standby this input reports itself as connected, so that standby replicas are not considered unready by health checks.
+
+### Backends
+
+Exactly one backend must be configured. The `+"`kubernetes_lease`"+` backend uses a [Lease object](https://kubernetes.io/docs/concepts/architecture/leases/) within a Kubernetes cluster, and when running within a pod the API address, namespace and credentials are obtained from the service account of the pod, which requires permission to `+"`get`, `create` and `update`"+` leases.
+
+The `+"`cache`"+` backend uses a [cache resource](/docs/components/caches/about) that supports TTLs and atomic compare-and-swap operations, which are currently the `+"`redis`"+` and `+"`memory`"+` caches, where the lease is acquired by adding a key with the identity of the instance as its value. The lease is renewed and released with compare-and-swap operations that only succeed whilst the key still holds the identity of the instance, and caches that do not support them are rejected, as renewing a lease with separate read and write operations could overwrite the lease of another instance that acquired it in between, resulting in two leaders.`).
+		Fields(
+			service.NewInputField(leFieldInput).
+				Description("The child input to consume from whilst this instance is the leader."),
+			service.NewStringField(leFieldIdentity).
+				Description("A unique identity of this instance. When empty the hostname is used, which within Kubernetes is the name of the pod.").
+				Default(""),
+			service.NewDurationField(leFieldLeaseDuration).
+				Description("The duration that non-leader instances wait since the lease was last renewed before attempting to acquire it.").
+				Default("15s").
+				Advanced(),
+			service.NewDurationField(leFieldRenewDeadline).
+				Description("The duration that the leader will continue consuming without successfully renewing its lease before giving up leadership.").
+				Default("10s").
+				Advanced(),
+			service.NewDurationField(leFieldRetryPeriod).
+				Description("The period between attempts to acquire or renew the lease.").
+				Default("2s").
+				Advanced(),
+			service.NewObjectField(leFieldKubeLease,
+				service.NewStringField(leFieldKubeName).
+					Description("The name of the Lease object."),
+				service.NewStringField(leFieldKubeNamespace).
+					Description("The namespace of the Lease object. When empty the namespace of the service account of the pod is used.").
+					Default(""),
+				service.NewStringField(leFieldKubeAPIURL).
+					Description("The base URL of the Kubernetes API. When empty the address of the cluster the pod is running within is used.").
+					Default("").
+					Advanced(),
+				service.NewStringField(leFieldKubeTokenFile).
+					Description("A file containing a bearer token used to authenticate with the Kubernetes API, which is read for each request.").
+					Default(kubeServiceAccountDir+"/token").
+					Advanced(),
+				service.NewStringField(leFieldKubeCAFile).
+					Description("A file containing the certificate authority used to verify the Kubernetes API.").
+					Default(kubeServiceAccountDir+"/ca.crt").
+					Advanced(),
+			).
+				Description("Use a Kubernetes Lease object for leader election.").
+				Optional(),
+			service.NewObjectField(leFieldCache,
+				service.NewStringField(leFieldCacheResource).
+					Description("The name of a cache resource that supports TTLs and compare-and-swap operations."),
+				service.NewStringField(leFieldCacheKey).
+					Description("The key used for the lease."),
+			).
+				Description("Use a cache resource for leader election.").
+				Optional(),
+		).
+		LintRule(`root = match {
+  this.exists("kubernetes_lease") && this.exists("cache") => [ "only one of kubernetes_lease or cache may be specified" ],
+  !this.exists("kubernetes_lease") && !this.exists("cache") => [ "either kubernetes_lease or cache must be specified" ],
+}`).
+		Example("Singleton SFTP Poller", "Poll an SFTP server from only one of many replicas deployed within Kubernetes, where the name of the pod is used as the identity.", `
+input:
+  leader_election:
+    kubernetes_lease:
+      name: benthos-sftp-poller
+    input:
+      sftp:
+        address: TODO
+        paths: [ /data/*.csv ]
+        watcher:
+          enabled: true
+`)
+}
+
+func init() {
+	err := service.RegisterBatchInput(
+		"leader_election", leaderElectionInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			i, err := newLeaderElectionInputFromParsed(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return interop.NewUnwrapInternalInput(i), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// leaderElector is a backend capable of electing a single leader amongst many
+// instances.
+type leaderElector interface {
+	// TryAcquireOrRenew attempts to acquire the lease, or renew it when it is
+	// already held by this instance, and returns true when the lease is held.
+	TryAcquireOrRenew(ctx context.Context) (bool, error)
+
+	// Release the lease when it is held by this instance.
+	Release(ctx context.Context) error
+}
+
+type cacheLeaseElector struct {
+	mgr      *service.Resources
+	resource string
+	key      string
+	identity string
+	ttl      time.Duration
+}
+
+func (c *cacheLeaseElector) TryAcquireOrRenew(ctx context.Context) (held bool, err error) {
+	if cerr := c.mgr.AccessCache(ctx, c.resource, func(cache service.Cache) {
+		if err = cache.Add(ctx, c.key, []byte(c.identity), &c.ttl); err == nil {
+			held = true
+			return
+		}
+		if !errors.Is(err, service.ErrKeyAlreadyExists) {
+			return
+		}
+
+		// Only renew the lease whilst it is still ours, which must be atomic
+		// as the lease might otherwise expire and be acquired by another
+		// instance between reading and writing it.
+		held, err = cacheCompareAndSwap(ctx, cache, c.key, []byte(c.identity), []byte(c.identity), &c.ttl)
+	}); cerr != nil {
+		return false, cerr
+	}
+	return
+}
+
+func (c *cacheLeaseElector) Release(ctx context.Context) (err error) {
+	if cerr := c.mgr.AccessCache(ctx, c.resource, func(cache service.Cache) {
+		_, err = cacheCompareAndSwap(ctx, cache, c.key, []byte(c.identity), nil, nil)
+	}); cerr != nil {
+		return cerr
+	}
+	return
+}
+
+type compareAndSwapCache interface {
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) (bool, error)
+}
+
+// cacheCompareAndSwap sets the value of a key only when its current value is
+// equal to old, where a nil value deletes the key.
+func cacheCompareAndSwap(ctx context.Context, c service.Cache, key string, old, value []byte, ttl *time.Duration) (bool, error) {
+	cas, ok := c.(compareAndSwapCache)
+	if !ok {
+		return false, cache.ErrCompareAndSwapNotSupported
+	}
+	return cas.CompareAndSwap(ctx, key, old, value, ttl)
+}
+
+// checkCacheCompareAndSwap returns an error when a cache resource does not
+// support compare-and-swap operations, which is checked with a swap of a key
+// that never matches as the value of a lease is never empty.
+func checkCacheCompareAndSwap(mgr *service.Resources, resource, key string) error {
+	var err error
+	if cerr := mgr.AccessCache(context.Background(), resource, func(c service.Cache) {
+		_, err = cacheCompareAndSwap(context.Background(), c, key, nil, nil, nil)
+	}); cerr != nil {
+		return cerr
+	}
+	if errors.Is(err, cache.ErrCompareAndSwapNotSupported) {
+		return fmt.Errorf("cache resource '%v' cannot be used for leases: %w", resource, err)
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type leaderElectionInput struct {
+	childConf input.Config
+	childMgr  bundle.NewManagement
+	log       log.Modular
+
+	elector       leaderElector
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+
+	child        atomic.Pointer[input.Streamed]
+	transactions chan message.Transaction
+	shutSig      *shutdown.Signaller
+}
+
+func newLeaderElectionInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*leaderElectionInput, error) {
+	childAny, err := conf.FieldAny(leFieldInput)
+	if err != nil {
+		return nil, err
+	}
+	childNode, ok := childAny.(*yaml.Node)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value, expected object, got %T", childAny)
+	}
+	var childConf input.Config
+	if err := childNode.Decode(&childConf); err != nil {
+		return nil, err
+	}
+
+	identity, err := conf.FieldString(leFieldIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if identity == "" {
+		if identity, err = os.Hostname(); err != nil {
+			return nil, fmt.Errorf("failed to obtain hostname for identity: %w", err)
+		}
+	}
+
+	leaseDuration, err := conf.FieldDuration(leFieldLeaseDuration)
+	if err != nil {
+		return nil, err
+	}
+	renewDeadline, err := conf.FieldDuration(leFieldRenewDeadline)
+	if err != nil {
+		return nil, err
+	}
+	retryPeriod, err := conf.FieldDuration(leFieldRetryPeriod)
+	if err != nil {
+		return nil, err
+	}
+	if renewDeadline >= leaseDuration {
+		return nil, fmt.Errorf("%v must be less than %v", leFieldRenewDeadline, leFieldLeaseDuration)
+	}
+	if retryPeriod <= 0 || retryPeriod >= renewDeadline {
+		return nil, fmt.Errorf("%v must be greater than zero and less than %v", leFieldRetryPeriod, leFieldRenewDeadline)
+	}
+
+	var elector leaderElector
+	switch {
+	case conf.Contains(leFieldKubeLease) && conf.Contains(leFieldCache):
+		return nil, fmt.Errorf("only one of %v or %v may be specified", leFieldKubeLease, leFieldCache)
+	case conf.Contains(leFieldKubeLease):
+		kConf := conf.Namespace(leFieldKubeLease)
+		name, err := kConf.FieldString(leFieldKubeName)
+		if err != nil {
+			return nil, err
+		}
+		namespace, err := kConf.FieldString(leFieldKubeNamespace)
+		if err != nil {
+			return nil, err
+		}
+		apiURL, err := kConf.FieldString(leFieldKubeAPIURL)
+		if err != nil {
+			return nil, err
+		}
+		tokenFile, err := kConf.FieldString(leFieldKubeTokenFile)
+		if err != nil {
+			return nil, err
+		}
+		caFile, err := kConf.FieldString(leFieldKubeCAFile)
+		if err != nil {
+			return nil, err
+		}
+		if elector, err = newKubeLeaseElector(apiURL, namespace, name, identity, tokenFile, caFile, leaseDuration); err != nil {
+			return nil, err
+		}
+	case conf.Contains(leFieldCache):
+		cConf := conf.Namespace(leFieldCache)
+		resource, err := cConf.FieldString(leFieldCacheResource)
+		if err != nil {
+			return nil, err
+		}
+		if !mgr.HasCache(resource) {
+			return nil, fmt.Errorf("cache resource '%v' was not found", resource)
+		}
+		key, err := cConf.FieldString(leFieldCacheKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkCacheCompareAndSwap(mgr, resource, key); err != nil {
+			return nil, err
+		}
+		elector = &cacheLeaseElector{
+			mgr:      mgr,
+			resource: resource,
+			key:      key,
+			identity: identity,
+			ttl:      leaseDuration,
+		}
+	default:
+		return nil, fmt.Errorf("either %v or %v must be specified", leFieldKubeLease, leFieldCache)
+	}
+
+	nm := interop.UnwrapManagement(mgr)
+	return newLeaderElectionInput(childConf, nm.IntoPath("leader_election", leFieldInput), nm.Logger(), elector, renewDeadline, retryPeriod), nil
+}
+
+func newLeaderElectionInput(
+	childConf input.Config,
+	childMgr bundle.NewManagement,
+	logger log.Modular,
+	elector leaderElector,
+	renewDeadline, retryPeriod time.Duration,
+) *leaderElectionInput {
+	l := &leaderElectionInput{
+		childConf:     childConf,
+		childMgr:      childMgr,
+		log:           logger,
+		elector:       elector,
+		renewDeadline: renewDeadline,
+		retryPeriod:   retryPeriod,
+		transactions:  make(chan message.Transaction),
+		shutSig:       shutdown.NewSignaller(),
+	}
+	go l.loop()
+	return l
+}
+
+// electionLoop periodically attempts to acquire or renew the lease and writes
+// changes in leadership to the provided channel.
+func (l *leaderElectionInput) electionLoop(leaderChan chan<- bool) {
+	ctx, done := l.shutSig.CloseAtLeisureCtx(context.Background())
+	defer done()
+
+	var isLeader bool
+	var lastRenewed time.Time
+	for {
+		attemptCtx, attemptDone := context.WithTimeout(ctx, l.retryPeriod)
+		held, err := l.elector.TryAcquireOrRenew(attemptCtx)
+		attemptDone()
+		if err != nil && ctx.Err() == nil {
+			l.log.Warnf("Failed to acquire or renew leadership lease: %v\n", err)
+		}
+
+		now := time.Now()
+		nowLeader := isLeader
+		switch {
+		case held:
+			lastRenewed = now
+			nowLeader = true
+		case isLeader && (err == nil || now.Sub(lastRenewed) >= l.renewDeadline):
+			nowLeader = false
+		}
+
+		if nowLeader != isLeader {
+			select {
+			case leaderChan <- nowLeader:
+			case <-ctx.Done():
+				return
+			}
+			isLeader = nowLeader
+		}
+
+		select {
+		case <-time.After(l.retryPeriod):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (l *leaderElectionInput) startChild() bool {
+	child, err := l.childMgr.NewInput(l.childConf)
+	if err != nil {
+		l.log.Errorf("Failed to create input '%v': %v\n", l.childConf.Type, err)
+		return false
+	}
+	l.child.Store(&child)
+	return true
+}
+
+func (l *leaderElectionInput) stopChild() {
+	childP := l.child.Swap(nil)
+	if childP == nil {
+		return
+	}
+	child := *childP
+	child.TriggerStopConsuming()
+	child.TriggerCloseNow()
+	_ = child.WaitForClose(context.Background())
+}
+
+func (l *leaderElectionInput) loop() {
+	leaderChan := make(chan bool)
+	electionDone := make(chan struct{})
+	go func() {
+		l.electionLoop(leaderChan)
+		close(electionDone)
+	}()
+
+	defer func() {
+		l.stopChild()
+		l.shutSig.CloseAtLeisure()
+		<-electionDone
+
+		releaseCtx, done := context.WithTimeout(context.Background(), l.retryPeriod)
+		if err := l.elector.Release(releaseCtx); err != nil {
+			l.log.Warnf("Failed to release leadership lease: %v\n", err)
+		}
+		done()
+
+		close(l.transactions)
+		l.shutSig.ShutdownComplete()
+	}()
+
+	closeCtx, done := l.shutSig.CloseAtLeisureCtx(context.Background())
+	defer done()
+
+	onLeaderChange := func(isLeader bool) bool {
+		if isLeader {
+			l.log.Infof("Acquired leadership, starting input\n")
+			return l.startChild()
+		}
+		l.log.Warnf("Lost leadership, stopping input\n")
+		l.stopChild()
+		return true
+	}
+
+	for {
+		var tChan <-chan message.Transaction
+		if childP := l.child.Load(); childP != nil {
+			tChan = (*childP).TransactionChan()
+		}
+
+		select {
+		case isLeader := <-leaderChan:
+			if !onLeaderChange(isLeader) {
+				return
+			}
+		case tran, open := <-tChan:
+			if !open {
+				l.log.Infof("Input has closed, releasing leadership\n")
+				return
+			}
+			select {
+			case l.transactions <- tran:
+			case isLeader := <-leaderChan:
+				_ = tran.Ack(closeCtx, errors.New("leadership lost"))
+				if !onLeaderChange(isLeader) {
+					return
+				}
+			case <-l.shutSig.CloseAtLeisureChan():
+				return
+			}
+		case <-l.shutSig.CloseAtLeisureChan():
+			return
+		}
+	}
+}
+
+func (l *leaderElectionInput) TransactionChan() <-chan message.Transaction {
+	return l.transactions
+}
+
+func (l *leaderElectionInput) Connected() bool {
+	if childP := l.child.Load(); childP != nil {
+		return (*childP).Connected()
+	}
+	return true
+}
+
+func (l *leaderElectionInput) TriggerStopConsuming() {
+	l.shutSig.CloseAtLeisure()
+}
+
+func (l *leaderElectionInput) TriggerCloseNow() {
+	l.shutSig.CloseNow()
+}
+
+func (l *leaderElectionInput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-l.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
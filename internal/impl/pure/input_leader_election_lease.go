@@ -0,0 +1,270 @@
+package pure
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	kubeServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	kubeMicroTimeFormat   = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+type kubeLeaseMeta struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type kubeLeaseSpec struct {
+	HolderIdentity       *string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds *int    `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *string `json:"acquireTime,omitempty"`
+	RenewTime            *string `json:"renewTime,omitempty"`
+	LeaseTransitions     *int    `json:"leaseTransitions,omitempty"`
+}
+
+type kubeLease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   kubeLeaseMeta `json:"metadata"`
+	Spec       kubeLeaseSpec `json:"spec"`
+}
+
+func (l *kubeLease) holder() string {
+	if l.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *l.Spec.HolderIdentity
+}
+
+func (l *kubeLease) expired(now time.Time) bool {
+	if l.Spec.RenewTime == nil || l.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	renewed, err := time.Parse(kubeMicroTimeFormat, *l.Spec.RenewTime)
+	if err != nil {
+		return true
+	}
+	return renewed.Add(time.Duration(*l.Spec.LeaseDurationSeconds) * time.Second).Before(now)
+}
+
+// kubeLeaseElector implements leader election using a Kubernetes Lease object
+// via the REST API of the cluster, where optimistic concurrency is provided by
+// the resource version of the lease.
+type kubeLeaseElector struct {
+	baseURL   string
+	namespace string
+	name      string
+	identity  string
+	duration  time.Duration
+	tokenFile string
+	client    *http.Client
+}
+
+func newKubeLeaseElector(apiURL, namespace, name, identity, tokenFile, caFile string, duration time.Duration) (*kubeLeaseElector, error) {
+	if apiURL == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, errors.New("an api_url must be specified when not running within a Kubernetes cluster")
+		}
+		apiURL = "https://" + net.JoinHostPort(host, port)
+	}
+	if namespace == "" {
+		nsBytes, err := os.ReadFile(kubeServiceAccountDir + "/namespace")
+		if err != nil {
+			return nil, fmt.Errorf("a namespace must be specified when not running within a Kubernetes cluster: %w", err)
+		}
+		namespace = strings.TrimSpace(string(nsBytes))
+	}
+
+	tlsConf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		caBytes, err := os.ReadFile(caFile)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) || caFile != kubeServiceAccountDir+"/ca.crt" {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+		} else {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(caBytes) {
+				return nil, errors.New("failed to parse CA file")
+			}
+			tlsConf.RootCAs = pool
+		}
+	}
+
+	return &kubeLeaseElector{
+		baseURL:   strings.TrimSuffix(apiURL, "/") + "/apis/coordination.k8s.io/v1/namespaces/" + url.PathEscape(namespace) + "/leases",
+		namespace: namespace,
+		name:      name,
+		identity:  identity,
+		duration:  duration,
+		tokenFile: tokenFile,
+		client: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConf},
+			Timeout:   duration,
+		},
+	}, nil
+}
+
+func (k *kubeLeaseElector) do(ctx context.Context, method, target string, body any) (int, []byte, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, err
+		}
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bodyReader)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Service account tokens are rotated and therefore we read the token
+	// fresh for each request.
+	if k.tokenFile != "" {
+		token, err := os.ReadFile(k.tokenFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return 0, nil, fmt.Errorf("failed to read token file: %w", err)
+		}
+		if t := strings.TrimSpace(string(token)); t != "" {
+			req.Header.Set("Authorization", "Bearer "+t)
+		}
+	}
+
+	res, err := k.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+
+	resBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, resBytes, nil
+}
+
+func (k *kubeLeaseElector) get(ctx context.Context) (*kubeLease, error) {
+	status, body, err := k.do(ctx, http.MethodGet, k.baseURL+"/"+url.PathEscape(k.name), nil)
+	if err != nil {
+		return nil, err
+	}
+	switch status {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected status code %v reading lease: %s", status, body)
+	}
+	var lease kubeLease
+	if err := json.Unmarshal(body, &lease); err != nil {
+		return nil, fmt.Errorf("failed to parse lease: %w", err)
+	}
+	return &lease, nil
+}
+
+func (k *kubeLeaseElector) write(ctx context.Context, method, target string, lease *kubeLease) (bool, error) {
+	status, body, err := k.do(ctx, method, target, lease)
+	if err != nil {
+		return false, err
+	}
+	switch status {
+	case http.StatusOK, http.StatusCreated:
+		return true, nil
+	case http.StatusConflict:
+		// Another instance modified the lease before us.
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected status code %v writing lease: %s", status, body)
+}
+
+func (k *kubeLeaseElector) TryAcquireOrRenew(ctx context.Context) (bool, error) {
+	lease, err := k.get(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	nowStr := now.UTC().Format(kubeMicroTimeFormat)
+	durationSeconds := int(k.duration.Round(time.Second) / time.Second)
+	if durationSeconds < 1 {
+		durationSeconds = 1
+	}
+
+	if lease == nil {
+		transitions := 0
+		return k.write(ctx, http.MethodPost, k.baseURL, &kubeLease{
+			APIVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+			Metadata: kubeLeaseMeta{
+				Name:      k.name,
+				Namespace: k.namespace,
+			},
+			Spec: kubeLeaseSpec{
+				HolderIdentity:       &k.identity,
+				LeaseDurationSeconds: &durationSeconds,
+				AcquireTime:          &nowStr,
+				RenewTime:            &nowStr,
+				LeaseTransitions:     &transitions,
+			},
+		})
+	}
+
+	holder := lease.holder()
+	if holder != k.identity {
+		if holder != "" && !lease.expired(now) {
+			return false, nil
+		}
+		transitions := 1
+		if lease.Spec.LeaseTransitions != nil {
+			transitions = *lease.Spec.LeaseTransitions + 1
+		}
+		lease.Spec.LeaseTransitions = &transitions
+		lease.Spec.AcquireTime = &nowStr
+	}
+
+	lease.APIVersion, lease.Kind = "coordination.k8s.io/v1", "Lease"
+	lease.Spec.HolderIdentity = &k.identity
+	lease.Spec.LeaseDurationSeconds = &durationSeconds
+	lease.Spec.RenewTime = &nowStr
+	return k.write(ctx, http.MethodPut, k.baseURL+"/"+url.PathEscape(k.name), lease)
+}
+
+func (k *kubeLeaseElector) Release(ctx context.Context) error {
+	lease, err := k.get(ctx)
+	if err != nil {
+		return err
+	}
+	if lease == nil || lease.holder() != k.identity {
+		return nil
+	}
+
+	// Mirror the release semantics of client-go by clearing the holder and
+	// reducing the lease to the minimum duration.
+	empty, minDuration := "", 1
+	lease.APIVersion, lease.Kind = "coordination.k8s.io/v1", "Lease"
+	lease.Spec.HolderIdentity = &empty
+	lease.Spec.LeaseDurationSeconds = &minDuration
+	_, err = k.write(ctx, http.MethodPut, k.baseURL+"/"+url.PathEscape(k.name), lease)
+	return err
+}
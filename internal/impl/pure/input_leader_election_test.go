@@ -0,0 +1,341 @@
+package pure_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	bmock "github.com/benthosdev/benthos/v4/internal/manager/mock"
+)
+
+func readLeaderElectionMessage(t testing.TB, in input.Streamed, timeout time.Duration) (string, bool) {
+	t.Helper()
+
+	select {
+	case tran, open := <-in.TransactionChan():
+		require.True(t, open)
+		require.NoError(t, tran.Ack(context.Background(), nil))
+		return string(tran.Payload.Get(0).AsBytes()), true
+	case <-time.After(timeout):
+	}
+	return "", false
+}
+
+func closeLeaderElectionInput(t testing.TB, in input.Streamed) {
+	t.Helper()
+
+	in.TriggerStopConsuming()
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+	require.NoError(t, in.WaitForClose(ctx))
+}
+
+func leaderElectionLeaseHolder(t testing.TB, mgr *bmock.Manager) (holder string) {
+	t.Helper()
+
+	require.NoError(t, mgr.AccessCache(context.Background(), "leases", func(c cache.V1) {
+		v, _ := c.Get(context.Background(), "foo")
+		holder = string(v)
+	}))
+	return
+}
+
+func TestLeaderElectionConfigErrors(t *testing.T) {
+	mgr := bmock.NewManager()
+	mgr.Caches["leases"] = map[string]bmock.CacheItem{}
+
+	_, err := mgr.NewInput(parseYAMLInputConf(t, `
+leader_election:
+  input:
+    generate:
+      mapping: 'root = "hello"'
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "either kubernetes_lease or cache must be specified")
+
+	_, err = mgr.NewInput(parseYAMLInputConf(t, `
+leader_election:
+  lease_duration: 5s
+  renew_deadline: 5s
+  cache:
+    resource: leases
+    key: foo
+  input:
+    generate:
+      mapping: 'root = "hello"'
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "renew_deadline must be less than lease_duration")
+
+	_, err = mgr.NewInput(parseYAMLInputConf(t, `
+leader_election:
+  cache:
+    resource: nope
+    key: foo
+  input:
+    generate:
+      mapping: 'root = "hello"'
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache resource 'nope' was not found")
+}
+
+func TestLeaderElectionCacheFailover(t *testing.T) {
+	mgr := bmock.NewManager()
+	mgr.Caches["leases"] = map[string]bmock.CacheItem{}
+
+	confStr := `
+leader_election:
+  identity: %v
+  lease_duration: 500ms
+  renew_deadline: 200ms
+  retry_period: 10ms
+  cache:
+    resource: leases
+    key: foo
+  input:
+    generate:
+      interval: 1ms
+      mapping: 'root = "%v"'
+`
+
+	inA, err := mgr.NewInput(parseYAMLInputConf(t, confStr, "a", "a"))
+	require.NoError(t, err)
+
+	msg, ok := readLeaderElectionMessage(t, inA, time.Second*5)
+	require.True(t, ok)
+	assert.Equal(t, "a", msg)
+
+	inB, err := mgr.NewInput(parseYAMLInputConf(t, confStr, "b", "b"))
+	require.NoError(t, err)
+	assert.True(t, inB.Connected(), "standby instances should report as connected")
+
+	for i := 0; i < 10; i++ {
+		msg, ok = readLeaderElectionMessage(t, inA, time.Second*5)
+		require.True(t, ok)
+		assert.Equal(t, "a", msg)
+	}
+
+	_, ok = readLeaderElectionMessage(t, inB, time.Millisecond*100)
+	assert.False(t, ok, "standby instance should not consume")
+
+	closeLeaderElectionInput(t, inA)
+	assert.Equal(t, "", leaderElectionLeaseHolder(t, mgr), "lease should be released")
+
+	msg, ok = readLeaderElectionMessage(t, inB, time.Second*5)
+	require.True(t, ok)
+	assert.Equal(t, "b", msg)
+	assert.Equal(t, "b", leaderElectionLeaseHolder(t, mgr))
+
+	closeLeaderElectionInput(t, inB)
+}
+
+func TestLeaderElectionChildCloses(t *testing.T) {
+	mgr := bmock.NewManager()
+	mgr.Caches["leases"] = map[string]bmock.CacheItem{}
+
+	in, err := mgr.NewInput(parseYAMLInputConf(t, `
+leader_election:
+  identity: a
+  retry_period: 10ms
+  cache:
+    resource: leases
+    key: foo
+  input:
+    generate:
+      count: 2
+      interval: ""
+      mapping: 'root = "hello"'
+`))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		msg, ok := readLeaderElectionMessage(t, in, time.Second*5)
+		require.True(t, ok)
+		assert.Equal(t, "hello", msg)
+	}
+
+	select {
+	case _, open := <-in.TransactionChan():
+		assert.False(t, open)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for input to close")
+	}
+
+	assert.Equal(t, "", leaderElectionLeaseHolder(t, mgr), "lease should be released")
+}
+
+type fakeLeaseServer struct {
+	mut     sync.Mutex
+	lease   map[string]any
+	version int
+	writes  []string
+}
+
+func (f *fakeLeaseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	const basePath = "/apis/coordination.k8s.io/v1/namespaces/testns/leases"
+	if r.Header.Get("Authorization") != "Bearer testtoken" {
+		http.Error(w, "nope", http.StatusUnauthorized)
+		return
+	}
+
+	var body map[string]any
+	if r.Method != http.MethodGet {
+		bodyBytes, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(bodyBytes, &body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == basePath+"/foo":
+		if f.lease == nil {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.lease)
+	case r.Method == http.MethodPost && r.URL.Path == basePath:
+		if f.lease != nil {
+			http.Error(w, "exists", http.StatusConflict)
+			return
+		}
+		f.version++
+		body["metadata"].(map[string]any)["resourceVersion"] = strconv.Itoa(f.version)
+		f.lease = body
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(f.lease)
+	case r.Method == http.MethodPut && r.URL.Path == basePath+"/foo":
+		if body["metadata"].(map[string]any)["resourceVersion"] != strconv.Itoa(f.version) {
+			http.Error(w, "conflict", http.StatusConflict)
+			return
+		}
+		f.version++
+		body["metadata"].(map[string]any)["resourceVersion"] = strconv.Itoa(f.version)
+		f.lease = body
+		_ = json.NewEncoder(w).Encode(f.lease)
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	if r.Method != http.MethodGet {
+		f.writes = append(f.writes, r.Method+":"+fmt.Sprint(body["spec"].(map[string]any)["holderIdentity"]))
+	}
+}
+
+func (f *fakeLeaseServer) holder() string {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if f.lease == nil {
+		return ""
+	}
+	h, _ := f.lease["spec"].(map[string]any)["holderIdentity"].(string)
+	return h
+}
+
+func TestLeaderElectionKubernetesLease(t *testing.T) {
+	fakeServer := &fakeLeaseServer{}
+	server := httptest.NewServer(fakeServer)
+	t.Cleanup(server.Close)
+
+	tokenFile := t.TempDir() + "/token"
+	require.NoError(t, os.WriteFile(tokenFile, []byte("testtoken\n"), 0o600))
+
+	mgr := bmock.NewManager()
+	in, err := mgr.NewInput(parseYAMLInputConf(t, `
+leader_election:
+  identity: a
+  retry_period: 10ms
+  kubernetes_lease:
+    name: foo
+    namespace: testns
+    api_url: %v
+    token_file: %v
+    ca_file: ""
+  input:
+    generate:
+      interval: 1ms
+      mapping: 'root = "hello"'
+`, server.URL, tokenFile))
+	require.NoError(t, err)
+
+	msg, ok := readLeaderElectionMessage(t, in, time.Second*5)
+	require.True(t, ok)
+	assert.Equal(t, "hello", msg)
+	assert.Equal(t, "a", fakeServer.holder())
+
+	closeLeaderElectionInput(t, in)
+	assert.Equal(t, "", fakeServer.holder())
+
+	fakeServer.mut.Lock()
+	writes := strings.Join(fakeServer.writes, ",")
+	fakeServer.mut.Unlock()
+	assert.True(t, strings.HasPrefix(writes, "POST:a,"), writes)
+	assert.True(t, strings.HasSuffix(writes, "PUT:"), writes)
+}
+
+func TestLeaderElectionKubernetesLeaseHeld(t *testing.T) {
+	fakeServer := &fakeLeaseServer{
+		version: 1,
+		lease: map[string]any{
+			"apiVersion": "coordination.k8s.io/v1",
+			"kind":       "Lease",
+			"metadata": map[string]any{
+				"name":            "foo",
+				"namespace":       "testns",
+				"resourceVersion": "1",
+			},
+			"spec": map[string]any{
+				"holderIdentity":       "b",
+				"leaseDurationSeconds": 3600,
+				"renewTime":            time.Now().UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+			},
+		},
+	}
+	server := httptest.NewServer(fakeServer)
+	t.Cleanup(server.Close)
+
+	tokenFile := t.TempDir() + "/token"
+	require.NoError(t, os.WriteFile(tokenFile, []byte("testtoken"), 0o600))
+
+	mgr := bmock.NewManager()
+	in, err := mgr.NewInput(parseYAMLInputConf(t, `
+leader_election:
+  identity: a
+  retry_period: 10ms
+  kubernetes_lease:
+    name: foo
+    namespace: testns
+    api_url: %v
+    token_file: %v
+    ca_file: ""
+  input:
+    generate:
+      interval: 1ms
+      mapping: 'root = "hello"'
+`, server.URL, tokenFile))
+	require.NoError(t, err)
+
+	_, ok := readLeaderElectionMessage(t, in, time.Millisecond*200)
+	assert.False(t, ok, "input should not consume whilst the lease is held elsewhere")
+
+	closeLeaderElectionInput(t, in)
+	assert.Equal(t, "b", fakeServer.holder())
+}
@@ -27,7 +27,7 @@ func TestReadUntilErrs(t *testing.T) {
 	conf.ReadUntil.Input = &inConf
 
 	_, err := bmock.NewManager().NewInput(conf)
-	assert.EqualError(t, err, "failed to init input <no label>: a check query is required")
+	assert.EqualError(t, err, "failed to init input <no label>: a check query, idle_timeout, max_messages or max_bytes is required")
 }
 
 func TestReadUntilInput(t *testing.T) {
@@ -268,3 +268,86 @@ remainingLoop:
 		t.Fatal(err)
 	}
 }
+
+func readUntilDrain(t testing.TB, in input.Streamed) (msgs, finals []string) {
+	t.Helper()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	for {
+		select {
+		case tran, open := <-in.TransactionChan():
+			if !open {
+				require.NoError(t, in.WaitForClose(ctx))
+				return
+			}
+			msgs = append(msgs, string(tran.Payload.Get(0).AsBytes()))
+			finals = append(finals, tran.Payload.Get(0).MetaGetStr("benthos_read_until"))
+			require.NoError(t, tran.Ack(ctx, nil))
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+}
+
+func TestReadUntilMaxMessages(t *testing.T) {
+	in := testInput(t, `
+read_until:
+  max_messages: 3
+  input:
+    generate:
+      interval: ""
+      mapping: 'root = "hello"'
+`)
+
+	msgs, finals := readUntilDrain(t, in)
+	assert.Equal(t, []string{"hello", "hello", "hello"}, msgs)
+	assert.Equal(t, []string{"", "", "final"}, finals)
+}
+
+func TestReadUntilMaxBytes(t *testing.T) {
+	in := testInput(t, `
+read_until:
+  max_bytes: 12
+  input:
+    generate:
+      interval: ""
+      mapping: 'root = "hello"'
+`)
+
+	msgs, finals := readUntilDrain(t, in)
+	assert.Equal(t, []string{"hello", "hello", "hello"}, msgs)
+	assert.Equal(t, []string{"", "", "final"}, finals)
+}
+
+func TestReadUntilMaxMessagesOrCheck(t *testing.T) {
+	in := testInput(t, `
+read_until:
+  max_messages: 10
+  check: 'count("TestReadUntilMaxMessagesOrCheck") %% 3 == 0'
+  input:
+    generate:
+      interval: ""
+      mapping: 'root = "hello"'
+`)
+
+	msgs, finals := readUntilDrain(t, in)
+	assert.Equal(t, []string{"hello", "hello", "hello"}, msgs)
+	assert.Equal(t, []string{"", "", "final"}, finals)
+}
+
+func TestReadUntilIdleTimeout(t *testing.T) {
+	in := testInput(t, `
+read_until:
+  idle_timeout: 100ms
+  input:
+    generate:
+      interval: 1h
+      mapping: 'root = "hello"'
+`)
+
+	msgs, finals := readUntilDrain(t, in)
+	assert.Equal(t, []string{"hello"}, msgs)
+	assert.Equal(t, []string{""}, finals)
+}
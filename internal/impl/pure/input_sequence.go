@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
 	"sync"
 	"time"
 
@@ -416,7 +415,7 @@ func (r *sequenceInput) createNextTarget() (input.Streamed, bool, error) {
 	r.target = nil
 	if len(r.remaining) > 0 {
 		next := r.remaining[0]
-		wMgr := r.mgr.IntoPath("sequence", "inputs", strconv.Itoa(next.index))
+		wMgr := r.mgr.IntoPath("sequence", "inputs", bundle.PathSegment(next.index, next.config.Label))
 		if target, err = wMgr.NewInput(next.config); err == nil {
 			r.spent = append(r.spent, next)
 			r.remaining = r.remaining[1:]
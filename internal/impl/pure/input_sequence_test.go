@@ -25,11 +25,15 @@ func writeFiles(t *testing.T, dir string, nameToContent map[string]string) {
 	}
 }
 
-func testInput(t testing.TB, confPattern string, args ...any) input.Streamed {
-	iConf := input.NewConfig()
-	require.NoError(t, yaml.Unmarshal(fmt.Appendf(nil, confPattern, args...), &iConf))
+func parseYAMLInputConf(t testing.TB, confPattern string, args ...any) (conf input.Config) {
+	t.Helper()
+	conf = input.NewConfig()
+	require.NoError(t, yaml.Unmarshal(fmt.Appendf(nil, confPattern, args...), &conf))
+	return
+}
 
-	i, err := mock.NewManager().NewInput(iConf)
+func testInput(t testing.TB, confPattern string, args ...any) input.Streamed {
+	i, err := mock.NewManager().NewInput(parseYAMLInputConf(t, confPattern, args...))
 	require.NoError(t, err)
 
 	return i
@@ -0,0 +1,621 @@
+package pure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/component/interop"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	shFieldGroup         = "group"
+	shFieldCache         = "cache"
+	shFieldShards        = "shards"
+	shFieldInput         = "input"
+	shFieldInputMapping  = "input_mapping"
+	shFieldIdentity      = "identity"
+	shFieldLeaseDuration = "lease_duration"
+	shFieldRenewDeadline = "renew_deadline"
+	shFieldRetryPeriod   = "retry_period"
+)
+
+func shardedInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Divides a list of shards amongst all instances that share a group name, and consumes from a child input for each shard assigned to this instance.").
+		Description(`
+This input allows work that can be split into units, such as object prefixes, stream shards or ranges of table rows, to be divided horizontally amongst any number of Benthos instances without relying on a broker with consumer groups. For each shard assigned to this instance a child input is created from the `+"`input`"+` config, customised for that shard with the `+"`input_mapping`"+`.
+
+Instances coordinate through a [cache resource](/docs/components/caches/about) that supports TTLs and atomic compare-and-swap operations, which are currently the `+"`redis`"+` and `+"`memory`"+` caches, and which must be shared by all members of the group. Caches that do not support compare-and-swap are rejected, as leases could otherwise be overwritten by a member after they are acquired by another. Every `+"`retry_period`"+` each instance registers itself as a member of the group, and the shards are then divided evenly amongst the members that have registered within the last `+"`lease_duration`"+`. When members join or leave the group the shards are rebalanced automatically.
+
+An instance only consumes a shard whilst it holds a lease on that shard, and therefore a shard is never consumed by more than one instance at a time, even whilst a rebalance is taking place. A lease that fails to be renewed within the `+"`renew_deadline`"+` causes the shard to be stopped, and once a lease has not been renewed for `+"`lease_duration`"+` it can be acquired by another member.
+
+Messages that are in flight when a shard is reassigned are rejected so that they can be redelivered by the source. When the child input of a shard closes itself the shard is considered complete and is not consumed again by this instance whilst it remains assigned to it.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- benthos_shard
+`+"```"+``).
+		Fields(
+			service.NewStringField(shFieldGroup).
+				Description("The name of the group of instances that the shards are divided amongst."),
+			service.NewStringField(shFieldCache).
+				Description("The name of a cache resource that supports TTLs and compare-and-swap operations, which is used for coordinating the group."),
+			service.NewStringListField(shFieldShards).
+				Description("The list of shards to divide amongst the group, every instance of the group should be configured with the same list.").
+				Example([]string{"foo/", "bar/", "baz/"}),
+			service.NewInputField(shFieldInput).
+				Description("The child input config from which an input is created for each shard assigned to this instance."),
+			service.NewBloblangField(shFieldInputMapping).
+				Description("A [Bloblang mapping](/docs/guides/bloblang/about) that customises the child input config for a shard, where the input document is the child config and the shard is available as the metadata field `shard`.").
+				Example(`root.aws_s3.prefix = @shard`),
+			service.NewStringField(shFieldIdentity).
+				Description("A unique identity of this instance within the group. When empty the hostname is used.").
+				Default(""),
+			service.NewDurationField(shFieldLeaseDuration).
+				Description("The duration after which members that have not registered, and shard leases that have not been renewed, are considered expired.").
+				Default("15s").
+				Advanced(),
+			service.NewDurationField(shFieldRenewDeadline).
+				Description("The duration that this instance will continue consuming a shard without successfully renewing its lease before stopping it.").
+				Default("10s").
+				Advanced(),
+			service.NewDurationField(shFieldRetryPeriod).
+				Description("The period between registering with the group and acquiring or renewing shard leases.").
+				Default("2s").
+				Advanced(),
+		).
+		Example("Divide S3 Prefixes", "Consume objects from a bucket where each prefix is consumed by only one of many instances.", `
+input:
+  sharded:
+    group: my_bucket_readers
+    cache: shared_redis
+    shards: [ "2023/", "2024/", "2025/" ]
+    input_mapping: 'root.aws_s3.prefix = @shard'
+    input:
+      aws_s3:
+        bucket: my-bucket
+
+cache_resources:
+  - label: shared_redis
+    redis:
+      url: redis://redis:6379
+`)
+}
+
+func init() {
+	err := service.RegisterBatchInput(
+		"sharded", shardedInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			i, err := newShardedInputFromParsed(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return interop.NewUnwrapInternalInput(i), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type shardedMembers struct {
+	Members map[string]int64 `json:"members"`
+}
+
+// shardCoordinator tracks the members of a group within a cache and
+// determines which shards are assigned to this instance.
+type shardCoordinator struct {
+	mgr      *service.Resources
+	cache    string
+	group    string
+	identity string
+	ttl      time.Duration
+	lockTTL  time.Duration
+}
+
+func (s *shardCoordinator) membersKey() string {
+	return s.group + "/members"
+}
+
+func (s *shardCoordinator) shardKey(shard string) string {
+	return s.group + "/shards/" + shard
+}
+
+// Heartbeat registers this instance as a member of the group, or removes it
+// when leave is true, and returns the sorted list of live members. Updates to
+// the member list are serialised with a lock key, and when the lock is held by
+// another member the list is read without being updated. The lock is only
+// removed whilst it still holds the identity of this instance, which is
+// checked atomically with a compare-and-swap.
+func (s *shardCoordinator) Heartbeat(ctx context.Context, leave bool) (members []string, err error) {
+	if cerr := s.mgr.AccessCache(ctx, s.cache, func(c service.Cache) {
+		lockKey := s.group + "/lock"
+		locked := true
+		if err = c.Add(ctx, lockKey, []byte(s.identity), &s.lockTTL); err != nil {
+			if !errors.Is(err, service.ErrKeyAlreadyExists) {
+				return
+			}
+			locked = false
+		}
+		if locked {
+			defer func() {
+				// The lock may have expired and been acquired by another
+				// member in the meantime, in which case it isn't ours to
+				// remove.
+				_, _ = cacheCompareAndSwap(ctx, c, lockKey, []byte(s.identity), nil, nil)
+			}()
+		}
+
+		var m shardedMembers
+		var mBytes []byte
+		if mBytes, err = c.Get(ctx, s.membersKey()); err == nil {
+			if err = json.Unmarshal(mBytes, &m); err != nil {
+				err = fmt.Errorf("failed to parse group members: %w", err)
+				return
+			}
+		} else if !errors.Is(err, service.ErrKeyNotFound) {
+			return
+		}
+		err = nil
+		if m.Members == nil {
+			m.Members = map[string]int64{}
+		}
+
+		now := time.Now()
+		for k, expires := range m.Members {
+			if expires < now.UnixMilli() {
+				delete(m.Members, k)
+			}
+		}
+
+		if locked {
+			if leave {
+				delete(m.Members, s.identity)
+			} else {
+				m.Members[s.identity] = now.Add(s.ttl).UnixMilli()
+			}
+			if mBytes, err = json.Marshal(m); err != nil {
+				return
+			}
+			if err = c.Set(ctx, s.membersKey(), mBytes, &s.ttl); err != nil {
+				return
+			}
+		}
+
+		for k := range m.Members {
+			members = append(members, k)
+		}
+		sort.Strings(members)
+	}); cerr != nil {
+		return nil, cerr
+	}
+	return
+}
+
+// Assigned returns the subset of shards assigned to this instance given a
+// list of members, where shards are distributed in a round-robin fashion
+// across the sorted list of members.
+func (s *shardCoordinator) Assigned(members, shards []string) map[string]bool {
+	assigned := map[string]bool{}
+	index := sort.SearchStrings(members, s.identity)
+	if index >= len(members) || members[index] != s.identity {
+		return assigned
+	}
+	for i, shard := range shards {
+		if i%len(members) == index {
+			assigned[shard] = true
+		}
+	}
+	return assigned
+}
+
+// Lease returns an elector for the lease of a single shard.
+func (s *shardCoordinator) Lease(shard string) leaderElector {
+	return &cacheLeaseElector{
+		mgr:      s.mgr,
+		resource: s.cache,
+		key:      s.shardKey(shard),
+		identity: s.identity,
+		ttl:      s.ttl,
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type shardState struct {
+	lease       leaderElector
+	lastRenewed time.Time
+	completed   bool
+
+	child       input.Streamed
+	stopForward context.CancelFunc
+	forwardDone chan struct{}
+}
+
+type shardedInput struct {
+	shards       []string
+	childConfs   map[string]input.Config
+	childMgr     bundle.NewManagement
+	log          log.Modular
+	coordinator  *shardCoordinator
+	renewTimeout time.Duration
+	retryPeriod  time.Duration
+
+	stateMut sync.Mutex
+	states   map[string]*shardState
+
+	transactions chan message.Transaction
+	shutSig      *shutdown.Signaller
+}
+
+func newShardedInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*shardedInput, error) {
+	group, err := conf.FieldString(shFieldGroup)
+	if err != nil {
+		return nil, err
+	}
+	cacheName, err := conf.FieldString(shFieldCache)
+	if err != nil {
+		return nil, err
+	}
+	if !mgr.HasCache(cacheName) {
+		return nil, fmt.Errorf("cache resource '%v' was not found", cacheName)
+	}
+	shards, err := conf.FieldStringList(shFieldShards)
+	if err != nil {
+		return nil, err
+	}
+	if len(shards) == 0 {
+		return nil, errors.New("at least one shard must be specified")
+	}
+	seen := map[string]bool{}
+	for _, s := range shards {
+		if seen[s] {
+			return nil, fmt.Errorf("shard '%v' was specified more than once", s)
+		}
+		seen[s] = true
+	}
+
+	childAny, err := conf.FieldAny(shFieldInput)
+	if err != nil {
+		return nil, err
+	}
+	childNode, ok := childAny.(*yaml.Node)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value, expected object, got %T", childAny)
+	}
+	var childStructured any
+	if err := childNode.Decode(&childStructured); err != nil {
+		return nil, err
+	}
+
+	inputMapping, err := conf.FieldBloblang(shFieldInputMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the child config of each shard upfront so that errors in the
+	// mapping are surfaced during construction.
+	childConfs := make(map[string]input.Config, len(shards))
+	for _, s := range shards {
+		if childConfs[s], err = shardInputConfig(inputMapping, childStructured, s); err != nil {
+			return nil, fmt.Errorf("shard '%v': %w", s, err)
+		}
+	}
+
+	identity, err := conf.FieldString(shFieldIdentity)
+	if err != nil {
+		return nil, err
+	}
+	if identity == "" {
+		if identity, err = os.Hostname(); err != nil {
+			return nil, fmt.Errorf("failed to obtain hostname for identity: %w", err)
+		}
+	}
+
+	leaseDuration, err := conf.FieldDuration(shFieldLeaseDuration)
+	if err != nil {
+		return nil, err
+	}
+	renewDeadline, err := conf.FieldDuration(shFieldRenewDeadline)
+	if err != nil {
+		return nil, err
+	}
+	retryPeriod, err := conf.FieldDuration(shFieldRetryPeriod)
+	if err != nil {
+		return nil, err
+	}
+	if renewDeadline >= leaseDuration {
+		return nil, fmt.Errorf("%v must be less than %v", shFieldRenewDeadline, shFieldLeaseDuration)
+	}
+	if retryPeriod <= 0 || retryPeriod >= renewDeadline {
+		return nil, fmt.Errorf("%v must be greater than zero and less than %v", shFieldRetryPeriod, shFieldRenewDeadline)
+	}
+
+	nm := interop.UnwrapManagement(mgr)
+	coordinator := &shardCoordinator{
+		mgr:      mgr,
+		cache:    cacheName,
+		group:    group,
+		identity: identity,
+		ttl:      leaseDuration,
+		lockTTL:  retryPeriod,
+	}
+	if err := checkCacheCompareAndSwap(mgr, cacheName, coordinator.membersKey()); err != nil {
+		return nil, err
+	}
+
+	s := &shardedInput{
+		shards:       shards,
+		childConfs:   childConfs,
+		childMgr:     nm.IntoPath("sharded", shFieldInput),
+		log:          nm.Logger(),
+		coordinator:  coordinator,
+		renewTimeout: renewDeadline,
+		retryPeriod:  retryPeriod,
+		states:       map[string]*shardState{},
+		transactions: make(chan message.Transaction),
+		shutSig:      shutdown.NewSignaller(),
+	}
+	for _, shard := range shards {
+		s.states[shard] = &shardState{lease: s.coordinator.Lease(shard)}
+	}
+	go s.loop()
+	return s, nil
+}
+
+func shardInputConfig(inputMapping *bloblang.Executor, childStructured any, shard string) (input.Config, error) {
+	msg := service.NewMessage(nil)
+	msg.SetStructuredMut(childStructured)
+	msg.MetaSetMut("shard", shard)
+
+	res, err := msg.BloblangMutate(inputMapping)
+	if err != nil {
+		return input.Config{}, fmt.Errorf("failed to execute input mapping: %w", err)
+	}
+	if res == nil {
+		return input.Config{}, errors.New("input mapping resulted in a deleted config")
+	}
+
+	resStructured, err := res.AsStructured()
+	if err != nil {
+		return input.Config{}, fmt.Errorf("input mapping result was not structured: %w", err)
+	}
+
+	var node yaml.Node
+	if err := node.Encode(resStructured); err != nil {
+		return input.Config{}, err
+	}
+	conf := input.NewConfig()
+	if err := node.Decode(&conf); err != nil {
+		return input.Config{}, err
+	}
+	return conf, nil
+}
+
+func (s *shardedInput) startShard(shard string, state *shardState) {
+	child, err := s.childMgr.NewInput(s.childConfs[shard])
+	if err != nil {
+		s.log.Errorf("Failed to create input for shard '%v': %v\n", shard, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	state.child = child
+	state.stopForward = cancel
+	state.forwardDone = make(chan struct{})
+
+	go func() {
+		defer close(state.forwardDone)
+		for {
+			var tran message.Transaction
+			var open bool
+			select {
+			case tran, open = <-child.TransactionChan():
+				if !open {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+
+			_ = tran.Payload.Iter(func(i int, p *message.Part) error {
+				p.MetaSetMut("benthos_shard", shard)
+				return nil
+			})
+
+			select {
+			case s.transactions <- tran:
+			case <-ctx.Done():
+				_ = tran.Ack(context.Background(), errors.New("shard reassigned"))
+				return
+			}
+		}
+	}()
+}
+
+func (s *shardedInput) stopShard(state *shardState) {
+	if state.child == nil {
+		return
+	}
+	state.stopForward()
+	state.child.TriggerStopConsuming()
+	state.child.TriggerCloseNow()
+	_ = state.child.WaitForClose(context.Background())
+	<-state.forwardDone
+	state.child = nil
+}
+
+// childFinished returns true if the child input of a shard has closed itself,
+// as forwarding is otherwise only stopped alongside the child.
+func (s *shardedInput) childFinished(state *shardState) bool {
+	if state.child == nil {
+		return false
+	}
+	select {
+	case <-state.forwardDone:
+		return true
+	default:
+	}
+	return false
+}
+
+func (s *shardedInput) tick(ctx context.Context) {
+	attemptCtx, done := context.WithTimeout(ctx, s.retryPeriod)
+	defer done()
+
+	members, err := s.coordinator.Heartbeat(attemptCtx, false)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.log.Warnf("Failed to register with group: %v\n", err)
+		}
+		// Without an up to date member list we continue to renew the shards
+		// that we are already consuming.
+		members = nil
+	}
+
+	var assigned map[string]bool
+	if err == nil {
+		assigned = s.coordinator.Assigned(members, s.shards)
+	}
+
+	s.stateMut.Lock()
+	defer s.stateMut.Unlock()
+
+	now := time.Now()
+	for _, shard := range s.shards {
+		state := s.states[shard]
+		if s.childFinished(state) {
+			s.stopShard(state)
+			state.completed = true
+			s.log.Infof("Input for shard '%v' has closed, marking as complete\n", shard)
+		}
+
+		wanted := assigned[shard] || (assigned == nil && (state.child != nil || state.completed))
+		if !wanted {
+			if state.child != nil {
+				s.log.Infof("Shard '%v' is no longer assigned, stopping input\n", shard)
+				s.stopShard(state)
+			}
+			if !state.lastRenewed.IsZero() {
+				if err := state.lease.Release(attemptCtx); err != nil && ctx.Err() == nil {
+					s.log.Warnf("Failed to release lease for shard '%v': %v\n", shard, err)
+				}
+				state.lastRenewed = time.Time{}
+			}
+			state.completed = false
+			continue
+		}
+
+		held, err := state.lease.TryAcquireOrRenew(attemptCtx)
+		if err != nil && ctx.Err() == nil {
+			s.log.Warnf("Failed to acquire or renew lease for shard '%v': %v\n", shard, err)
+		}
+		switch {
+		case held:
+			state.lastRenewed = now
+			if state.child == nil && !state.completed {
+				s.log.Infof("Acquired shard '%v', starting input\n", shard)
+				s.startShard(shard, state)
+			}
+		case !state.lastRenewed.IsZero() && (err == nil || now.Sub(state.lastRenewed) >= s.renewTimeout):
+			if state.child != nil {
+				s.log.Warnf("Lost lease for shard '%v', stopping input\n", shard)
+				s.stopShard(state)
+			}
+			state.lastRenewed = time.Time{}
+			state.completed = false
+		}
+	}
+}
+
+func (s *shardedInput) loop() {
+	ctx, done := s.shutSig.CloseAtLeisureCtx(context.Background())
+	defer done()
+
+	defer func() {
+		releaseCtx, releaseDone := context.WithTimeout(context.Background(), s.retryPeriod)
+		defer releaseDone()
+
+		s.stateMut.Lock()
+		for shard, state := range s.states {
+			s.stopShard(state)
+			if state.lastRenewed.IsZero() {
+				continue
+			}
+			if err := state.lease.Release(releaseCtx); err != nil {
+				s.log.Warnf("Failed to release lease for shard '%v': %v\n", shard, err)
+			}
+		}
+		s.stateMut.Unlock()
+
+		if _, err := s.coordinator.Heartbeat(releaseCtx, true); err != nil {
+			s.log.Warnf("Failed to leave group: %v\n", err)
+		}
+
+		close(s.transactions)
+		s.shutSig.ShutdownComplete()
+	}()
+
+	for {
+		s.tick(ctx)
+		select {
+		case <-time.After(s.retryPeriod):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *shardedInput) TransactionChan() <-chan message.Transaction {
+	return s.transactions
+}
+
+func (s *shardedInput) Connected() bool {
+	s.stateMut.Lock()
+	defer s.stateMut.Unlock()
+	for _, state := range s.states {
+		if state.child != nil && !state.child.Connected() {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *shardedInput) TriggerStopConsuming() {
+	s.shutSig.CloseAtLeisure()
+}
+
+func (s *shardedInput) TriggerCloseNow() {
+	s.shutSig.CloseNow()
+}
+
+func (s *shardedInput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-s.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
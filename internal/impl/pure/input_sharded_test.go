@@ -0,0 +1,190 @@
+package pure_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	bmock "github.com/benthosdev/benthos/v4/internal/manager/mock"
+)
+
+type shardTracker struct {
+	mut  sync.Mutex
+	seen map[string]int
+}
+
+func trackShards(t testing.TB, in input.Streamed) *shardTracker {
+	t.Helper()
+
+	tracker := &shardTracker{seen: map[string]int{}}
+	go func() {
+		for tran := range in.TransactionChan() {
+			shard, _ := tran.Payload.Get(0).MetaGetMut("benthos_shard")
+			assert.Equal(t, shard, string(tran.Payload.Get(0).AsBytes()))
+
+			tracker.mut.Lock()
+			tracker.seen[shard.(string)]++
+			tracker.mut.Unlock()
+			_ = tran.Ack(context.Background(), nil)
+		}
+	}()
+	return tracker
+}
+
+func (s *shardTracker) reset() {
+	s.mut.Lock()
+	s.seen = map[string]int{}
+	s.mut.Unlock()
+}
+
+func (s *shardTracker) shards() []string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	var shards []string
+	for k := range s.seen {
+		shards = append(shards, k)
+	}
+	sort.Strings(shards)
+	return shards
+}
+
+const shardedTestConfig = `
+sharded:
+  group: testgroup
+  cache: coord
+  identity: %v
+  shards: [ a, b, c, d ]
+  lease_duration: 300ms
+  renew_deadline: 100ms
+  retry_period: 10ms
+  input_mapping: 'root.generate.mapping = "root = \"%%s\"".format(@shard)'
+  input:
+    generate:
+      interval: 1ms
+      mapping: 'root = "nope"'
+`
+
+func TestShardedInputConfigErrors(t *testing.T) {
+	mgr := bmock.NewManager()
+	mgr.Caches["coord"] = map[string]bmock.CacheItem{}
+
+	_, err := mgr.NewInput(parseYAMLInputConf(t, `
+sharded:
+  group: testgroup
+  cache: coord
+  shards: [ a, b, a ]
+  input_mapping: 'root = this'
+  input:
+    generate:
+      mapping: 'root = "hello"'
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "shard 'a' was specified more than once")
+
+	_, err = mgr.NewInput(parseYAMLInputConf(t, `
+sharded:
+  group: testgroup
+  cache: coord
+  shards: [ a, b ]
+  input_mapping: 'root = if @shard == "b" { deleted() } else { this }'
+  input:
+    generate:
+      mapping: 'root = "hello"'
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "shard 'b': input mapping resulted in a deleted config")
+
+	_, err = mgr.NewInput(parseYAMLInputConf(t, `
+sharded:
+  group: testgroup
+  cache: nope
+  shards: [ a ]
+  input_mapping: 'root = this'
+  input:
+    generate:
+      mapping: 'root = "hello"'
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache resource 'nope' was not found")
+}
+
+func TestShardedInputRebalance(t *testing.T) {
+	mgr := bmock.NewManager()
+	mgr.Caches["coord"] = map[string]bmock.CacheItem{}
+
+	inA, err := mgr.NewInput(parseYAMLInputConf(t, shardedTestConfig, "a"))
+	require.NoError(t, err)
+	trackerA := trackShards(t, inA)
+
+	assert.Eventually(t, func() bool {
+		return len(trackerA.shards()) == 4
+	}, time.Second*5, time.Millisecond*10)
+
+	inB, err := mgr.NewInput(parseYAMLInputConf(t, shardedTestConfig, "b"))
+	require.NoError(t, err)
+	trackerB := trackShards(t, inB)
+
+	assert.Eventually(t, func() bool {
+		return len(trackerB.shards()) == 2
+	}, time.Second*5, time.Millisecond*10)
+
+	// Once the rebalance has settled each shard should only be consumed by a
+	// single instance.
+	time.Sleep(time.Millisecond * 100)
+	trackerA.reset()
+	trackerB.reset()
+	time.Sleep(time.Millisecond * 200)
+
+	assert.Equal(t, []string{"a", "c"}, trackerA.shards())
+	assert.Equal(t, []string{"b", "d"}, trackerB.shards())
+
+	closeLeaderElectionInput(t, inB)
+
+	trackerA.reset()
+	assert.Eventually(t, func() bool {
+		return len(trackerA.shards()) == 4
+	}, time.Second*5, time.Millisecond*10)
+
+	closeLeaderElectionInput(t, inA)
+}
+
+func TestShardedInputChildCompletes(t *testing.T) {
+	mgr := bmock.NewManager()
+	mgr.Caches["coord"] = map[string]bmock.CacheItem{}
+
+	in, err := mgr.NewInput(parseYAMLInputConf(t, `
+sharded:
+  group: testgroup
+  cache: coord
+  identity: a
+  shards: [ a, b, c ]
+  retry_period: 10ms
+  input_mapping: 'root.generate.mapping = "root = \"%%s\"".format(@shard)'
+  input:
+    generate:
+      count: 1
+      interval: ""
+      mapping: 'root = "nope"'
+`))
+	require.NoError(t, err)
+	tracker := trackShards(t, in)
+
+	assert.Eventually(t, func() bool {
+		return len(tracker.shards()) == 3
+	}, time.Second*5, time.Millisecond*10)
+
+	time.Sleep(time.Millisecond * 100)
+
+	tracker.mut.Lock()
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "c": 1}, tracker.seen)
+	tracker.mut.Unlock()
+
+	closeLeaderElectionInput(t, in)
+}
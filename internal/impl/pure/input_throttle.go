@@ -0,0 +1,207 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	thiFieldInput             = "input"
+	thiFieldMessagesPerSecond = "messages_per_second"
+	thiFieldBytesPerSecond    = "bytes_per_second"
+	thiFieldBurstMessages     = "burst_messages"
+	thiFieldBurstBytes        = "burst_bytes"
+)
+
+func throttleInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Consumes from a child input at a limited rate of messages and/or bytes per second.").
+		Description(`
+This input is useful for replaying or backfilling data from a source that is able to deliver messages much faster than downstream systems are able to cope with, such as files or object stores.
+
+The rate is enforced with a token bucket for each limit, where a bucket holds at most a burst of tokens and is refilled at the configured rate. When the child input yields a batch the tokens of the batch are taken from each bucket, and when a bucket holds insufficient tokens the batch is held back until enough tokens have been refilled. A batch that is larger than the burst of a bucket is still delivered, but subsequent batches are held back until the bucket has recovered.
+
+When both limits are zero messages are consumed from the child input without being throttled.`).
+		Fields(
+			service.NewInputField(thiFieldInput).
+				Description("The child input to consume from."),
+			service.NewFloatField(thiFieldMessagesPerSecond).
+				Description("The maximum number of messages to consume per second. When zero the number of messages is not limited.").
+				Default(0),
+			service.NewIntField(thiFieldBytesPerSecond).
+				Description("The maximum number of bytes of message contents to consume per second. When zero the number of bytes is not limited.").
+				Default(0),
+			service.NewIntField(thiFieldBurstMessages).
+				Description("The maximum number of messages that can be consumed in a burst above the rate, after a period of consuming messages below the rate. When zero the burst is equal to the number of messages per second.").
+				Default(0).
+				Advanced(),
+			service.NewIntField(thiFieldBurstBytes).
+				Description("The maximum number of bytes that can be consumed in a burst above the rate, after a period of consuming messages below the rate. When zero the burst is equal to the number of bytes per second.").
+				Default(0).
+				Advanced(),
+		).
+		Example("Backfill", "Replay the objects of a bucket at a rate of at most 500 messages and 1MB per second.", `
+input:
+  throttle:
+    messages_per_second: 500
+    bytes_per_second: 1000000
+    input:
+      aws_s3:
+        bucket: my-archive
+        prefix: 2024/
+        codec: lines
+`)
+}
+
+func init() {
+	err := service.RegisterBatchInput(
+		"throttle", throttleInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			return newThrottleInputFromParsed(conf)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// throttleBucket is a token bucket that is refilled at a fixed rate up to a
+// maximum burst.
+type throttleBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newThrottleBucket(rate, burst float64, now time.Time) *throttleBucket {
+	if burst <= 0 {
+		burst = rate
+	}
+	return &throttleBucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+// reserve takes n tokens from the bucket and returns the period of time to
+// wait until the bucket is no longer in debt. The bucket is allowed to go into
+// debt so that a reservation larger than the burst is still satisfied.
+func (b *throttleBucket) reserve(n float64, now time.Time) time.Duration {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+type throttleInput struct {
+	child *service.OwnedInput
+
+	mut      sync.Mutex
+	messages *throttleBucket
+	bytes    *throttleBucket
+}
+
+func newThrottleInputFromParsed(conf *service.ParsedConfig) (*throttleInput, error) {
+	t := &throttleInput{}
+
+	var err error
+	if t.child, err = conf.FieldInput(thiFieldInput); err != nil {
+		return nil, err
+	}
+
+	msgRate, err := conf.FieldFloat(thiFieldMessagesPerSecond)
+	if err != nil {
+		return nil, err
+	}
+	bytesRate, err := conf.FieldInt(thiFieldBytesPerSecond)
+	if err != nil {
+		return nil, err
+	}
+	msgBurst, err := conf.FieldInt(thiFieldBurstMessages)
+	if err != nil {
+		return nil, err
+	}
+	bytesBurst, err := conf.FieldInt(thiFieldBurstBytes)
+	if err != nil {
+		return nil, err
+	}
+	if msgRate < 0 || bytesRate < 0 || msgBurst < 0 || bytesBurst < 0 {
+		return nil, errors.New("rates and bursts must be zero or greater")
+	}
+
+	now := time.Now()
+	if msgRate > 0 {
+		t.messages = newThrottleBucket(msgRate, float64(msgBurst), now)
+	}
+	if bytesRate > 0 {
+		t.bytes = newThrottleBucket(float64(bytesRate), float64(bytesBurst), now)
+	}
+	return t, nil
+}
+
+func (t *throttleInput) Connect(ctx context.Context) error {
+	return nil
+}
+
+// wait returns the period of time to hold back a batch for.
+func (t *throttleInput) wait(batch service.MessageBatch) time.Duration {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	now := time.Now()
+
+	var wait time.Duration
+	if t.messages != nil {
+		wait = t.messages.reserve(float64(len(batch)), now)
+	}
+	if t.bytes != nil {
+		var size int
+		for _, m := range batch {
+			if b, err := m.AsBytes(); err == nil {
+				size += len(b)
+			}
+		}
+		if w := t.bytes.reserve(float64(size), now); w > wait {
+			wait = w
+		}
+	}
+	return wait
+}
+
+func (t *throttleInput) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	batch, ackFn, err := t.child.ReadBatch(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if wait := t.wait(batch); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			_ = ackFn(context.Background(), ctx.Err())
+			return nil, nil, ctx.Err()
+		}
+	}
+	return batch, ackFn, nil
+}
+
+func (t *throttleInput) Close(ctx context.Context) error {
+	return t.child.Close(ctx)
+}
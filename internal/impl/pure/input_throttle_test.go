@@ -0,0 +1,83 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestThrottleBucket(t *testing.T) {
+	start := time.Unix(0, 0)
+
+	b := newThrottleBucket(10, 2, start)
+	assert.Equal(t, time.Duration(0), b.reserve(1, start))
+	assert.Equal(t, time.Duration(0), b.reserve(1, start))
+	assert.Equal(t, time.Millisecond*100, b.reserve(1, start))
+
+	// After 300ms the debt is repaid and two tokens are available again.
+	now := start.Add(time.Millisecond * 300)
+	assert.Equal(t, time.Duration(0), b.reserve(2, now))
+
+	// A reservation larger than the burst is satisfied with a wait.
+	assert.Equal(t, time.Millisecond*500, b.reserve(5, now))
+
+	b = newThrottleBucket(10, 0, start)
+	assert.Equal(t, time.Duration(0), b.reserve(10, start))
+	assert.Equal(t, time.Millisecond*100, b.reserve(1, start))
+}
+
+func TestThrottleInputMessages(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	conf, err := throttleInputSpec().ParseYAML(`
+messages_per_second: 100
+burst_messages: 1
+input:
+  generate:
+    count: 10
+    interval: ""
+    mapping: 'root = "hello world"'
+`, nil)
+	require.NoError(t, err)
+
+	in, err := newThrottleInputFromParsed(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, in.Close(context.Background()))
+	})
+	require.NoError(t, in.Connect(tCtx))
+
+	start := time.Now()
+	var count int
+	for {
+		batch, ackFn, err := in.ReadBatch(tCtx)
+		if errors.Is(err, service.ErrEndOfInput) {
+			break
+		}
+		require.NoError(t, err)
+		count += len(batch)
+		require.NoError(t, ackFn(tCtx, nil))
+	}
+	assert.Equal(t, 10, count)
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*80)
+}
+
+func TestThrottleInputBadConfig(t *testing.T) {
+	conf, err := throttleInputSpec().ParseYAML(`
+messages_per_second: -1
+input:
+  generate:
+    mapping: 'root = "hello world"'
+`, nil)
+	require.NoError(t, err)
+
+	_, err = newThrottleInputFromParsed(conf)
+	require.Error(t, err)
+}
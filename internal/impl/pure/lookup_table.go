@@ -0,0 +1,269 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	dinterop "github.com/benthosdev/benthos/v4/internal/docs/interop"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	ltFieldInput           = "input"
+	ltFieldKey             = "key"
+	ltFieldValue           = "value"
+	ltFieldRefreshInterval = "refresh_interval"
+	ltFieldTimeout         = "timeout"
+)
+
+func lookupTableSpecFields() []*service.ConfigField {
+	return []*service.ConfigField{
+		service.NewInputField(ltFieldInput).
+			Description("An input to load the table from, which must terminate once all of its messages have been consumed, such as an `sql_select` input, an `aws_s3` input with a `csv` scanner, or a `generate` input combined with an `http` processor."),
+		service.NewInterpolatedStringField(ltFieldKey).
+			Description("The key to store each message of the input under.").
+			Example(`${! this.id }`).
+			Example(`${! meta("kafka_key") }`),
+		service.NewBloblangField(ltFieldValue).
+			Description("An optional [Bloblang mapping](/docs/guides/bloblang/about) that determines the value to store for each message of the input. By default the structured contents of the message are stored, or the raw contents as a string when the message is not structured.").
+			Example(`root = this.without("password")`).
+			Optional(),
+		service.NewDurationField(ltFieldRefreshInterval).
+			Description("An optional period after which the table is reloaded from the input. The existing table continues to serve lookups whilst it is reloaded, and is kept when a reload fails.").
+			Example("5m").
+			Example("1h").
+			Optional(),
+		service.NewDurationField(ltFieldTimeout).
+			Description("The maximum period of time to wait for the input to be consumed when the table is loaded.").
+			Default("5m"),
+	}
+}
+
+func lookupTableSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().Fields(lookupTableSpecFields()...)
+}
+
+func init() {
+	fields := docs.FieldSpecs{
+		docs.FieldString("label", "A unique label for the resource, which is referenced by the `lookup` Bloblang function."),
+	}
+	for _, f := range lookupTableSpecFields() {
+		fields = append(fields, dinterop.Unwrap(f))
+	}
+	manager.LookupTableResourceFields = fields
+	manager.LookupTableConstructor = newLookupTableFromRaw
+}
+
+//------------------------------------------------------------------------------
+
+type lookupTable struct {
+	nm        bundle.NewManagement
+	log       log.Modular
+	inputConf input.Config
+	key       *field.Expression
+	value     *mapping.Executor
+	timeout   time.Duration
+
+	tableMut sync.RWMutex
+	table    map[string]any
+
+	shutSig *shutdown.Signaller
+}
+
+func newLookupTableFromRaw(fields map[string]any, nm bundle.NewManagement) (any, error) {
+	confBytes, err := yaml.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := lookupTableSpec().ParseYAML(string(confBytes), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &lookupTable{
+		nm:        nm,
+		log:       nm.Logger(),
+		inputConf: input.NewConfig(),
+		shutSig:   shutdown.NewSignaller(),
+	}
+
+	inputAny, err := conf.FieldAny(ltFieldInput)
+	if err != nil {
+		return nil, err
+	}
+	inputNode, ok := inputAny.(*yaml.Node)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value, expected object, got %T", inputAny)
+	}
+	if err := inputNode.Decode(&l.inputConf); err != nil {
+		return nil, err
+	}
+
+	keyStr, err := conf.FieldString(ltFieldKey)
+	if err != nil {
+		return nil, err
+	}
+	if l.key, err = nm.BloblEnvironment().NewField(keyStr); err != nil {
+		return nil, fmt.Errorf("failed to parse key expression: %w", err)
+	}
+
+	if conf.Contains(ltFieldValue) {
+		valueStr, err := conf.FieldString(ltFieldValue)
+		if err != nil {
+			return nil, err
+		}
+		if l.value, err = nm.BloblEnvironment().NewMapping(valueStr); err != nil {
+			return nil, fmt.Errorf("failed to parse value mapping: %w", err)
+		}
+	}
+
+	if l.timeout, err = conf.FieldDuration(ltFieldTimeout); err != nil {
+		return nil, err
+	}
+
+	var refreshInterval time.Duration
+	if conf.Contains(ltFieldRefreshInterval) {
+		if refreshInterval, err = conf.FieldDuration(ltFieldRefreshInterval); err != nil {
+			return nil, err
+		}
+	}
+
+	if l.table, err = l.load(context.Background()); err != nil {
+		return nil, err
+	}
+	if refreshInterval > 0 {
+		go l.refreshLoop(refreshInterval)
+	} else {
+		l.shutSig.ShutdownComplete()
+	}
+	return l, nil
+}
+
+// load consumes the input of the table until it terminates, returning a new
+// table from its messages.
+func (l *lookupTable) load(ctx context.Context) (map[string]any, error) {
+	ctx, done := context.WithTimeout(ctx, l.timeout)
+	defer done()
+
+	in, err := l.nm.IntoPath(ltFieldInput).NewInput(l.inputConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create input: %w", err)
+	}
+	defer func() {
+		in.TriggerCloseNow()
+		_ = in.WaitForClose(context.Background())
+	}()
+
+	table := map[string]any{}
+	for {
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-in.TransactionChan():
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to consume input: %w", ctx.Err())
+		}
+		if !open {
+			break
+		}
+
+		if err := tran.Payload.Iter(func(i int, p *message.Part) error {
+			return l.store(table, i, tran.Payload)
+		}); err != nil {
+			_ = tran.Ack(ctx, err)
+			return nil, err
+		}
+		if err := tran.Ack(ctx, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	l.log.Debugf("Loaded %v keys into lookup table", len(table))
+	return table, nil
+}
+
+func (l *lookupTable) store(table map[string]any, i int, batch message.Batch) error {
+	key, err := l.key.String(i, batch)
+	if err != nil {
+		return fmt.Errorf("key interpolation error: %w", err)
+	}
+
+	p := batch.Get(i)
+	if l.value != nil {
+		if p, err = l.value.MapPart(i, batch); err != nil {
+			return fmt.Errorf("value mapping error: %w", err)
+		}
+		if p == nil {
+			return nil
+		}
+	}
+
+	v, err := p.AsStructured()
+	if err != nil {
+		v = string(p.AsBytes())
+	}
+	table[key] = v
+	return nil
+}
+
+func (l *lookupTable) refreshLoop(interval time.Duration) {
+	defer l.shutSig.ShutdownComplete()
+
+	ctx, done := l.shutSig.CloseNowCtx(context.Background())
+	defer done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		table, err := l.load(ctx)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				l.log.Errorf("Failed to reload lookup table: %v", err)
+			}
+			continue
+		}
+
+		l.tableMut.Lock()
+		l.table = table
+		l.tableMut.Unlock()
+	}
+}
+
+// Lookup returns the value stored under a key, and whether it exists.
+func (l *lookupTable) Lookup(key string) (any, bool) {
+	l.tableMut.RLock()
+	v, exists := l.table[key]
+	l.tableMut.RUnlock()
+	return v, exists
+}
+
+func (l *lookupTable) Close(ctx context.Context) error {
+	l.shutSig.CloseNow()
+	select {
+	case <-l.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
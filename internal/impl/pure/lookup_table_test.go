@@ -0,0 +1,128 @@
+package pure_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestLookupTableStream(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	builder := service.NewStreamBuilder()
+	require.NoError(t, builder.SetLoggerYAML(`level: OFF`))
+	require.NoError(t, builder.AddResourcesYAML(`
+lookup_table_resources:
+  - label: users
+    input:
+      generate:
+        count: 1
+        interval: ""
+        mapping: 'root = [{"id":"u1","name":"Alice","password":"x"},{"id":"u2","name":"Bob","password":"y"}]'
+      processors:
+        - unarchive:
+            format: json_array
+    key: ${! this.id }
+    value: 'root = this.without("password")'
+`))
+
+	require.NoError(t, builder.AddProcessorYAML(`
+mapping: |
+  root = this
+  root.user = lookup("users", this.user_id)
+  root.name = lookup("users", this.user_id).name.or("unknown")
+`))
+
+	sendFn, err := builder.AddProducerFunc()
+	require.NoError(t, err)
+
+	resChan := make(chan string, 3)
+	require.NoError(t, builder.AddConsumerFunc(func(ctx context.Context, m *service.Message) error {
+		b, err := m.AsBytes()
+		if err != nil {
+			return err
+		}
+		resChan <- string(b)
+		return nil
+	}))
+
+	stream, err := builder.Build()
+	require.NoError(t, err)
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- stream.Run(tCtx)
+	}()
+
+	for _, doc := range []string{`{"user_id":"u1"}`, `{"user_id":"u2"}`, `{"user_id":"u3"}`} {
+		require.NoError(t, sendFn(tCtx, service.NewMessage([]byte(doc))))
+	}
+
+	assert.Equal(t, `{"name":"Alice","user":{"id":"u1","name":"Alice"},"user_id":"u1"}`, <-resChan)
+	assert.Equal(t, `{"name":"Bob","user":{"id":"u2","name":"Bob"},"user_id":"u2"}`, <-resChan)
+	assert.Equal(t, `{"name":"unknown","user":null,"user_id":"u3"}`, <-resChan)
+
+	require.NoError(t, stream.Stop(tCtx))
+	require.NoError(t, <-runErr)
+}
+
+func TestLookupTableRefresh(t *testing.T) {
+	var conf manager.ResourceConfig
+	require.NoError(t, yaml.Unmarshal([]byte(`
+lookup_table_resources:
+  - label: versions
+    input:
+      generate:
+        count: 1
+        interval: ""
+        mapping: 'root.version = count("lookup_table_refresh_test")'
+    key: current
+    refresh_interval: 10ms
+`), &conf))
+
+	mgr, err := manager.New(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		mgr.TriggerCloseNow()
+		require.NoError(t, mgr.WaitForClose(context.Background()))
+	})
+
+	exec, err := mgr.BloblEnvironment().NewMapping(`root = lookup("versions", "current").version`)
+	require.NoError(t, err)
+
+	queryVersion := func() string {
+		p, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+		require.NoError(t, err)
+		return string(p.AsBytes())
+	}
+	initial := queryVersion()
+	assert.NotEqual(t, "null", initial)
+	assert.Eventually(t, func() bool {
+		return queryVersion() != initial
+	}, time.Second*5, time.Millisecond*10)
+
+	_, err = mgr.BloblEnvironment().NewMapping(`root = lookup("nope", "current")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to locate resource: nope")
+}
+
+func TestLookupTableNoManager(t *testing.T) {
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	exec, err := mgr.BloblEnvironment().NewMapping(`root = lookup("users", this.id)`)
+	require.NoError(t, err)
+
+	_, err = exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{"id":"u1"}`)}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "lookup table resource 'users' was not found")
+}
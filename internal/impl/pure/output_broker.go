@@ -3,9 +3,9 @@ package pure
 import (
 	"errors"
 	"fmt"
-	"strconv"
 
 	"github.com/benthosdev/benthos/v4/internal/batch/policy"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/output"
 	"github.com/benthosdev/benthos/v4/internal/component/output/batcher"
@@ -39,7 +39,9 @@ output:
   # Processors applied to messages sent to all brokered outputs.
   processors:
     - resource: general_processor
-` + "```" + ``,
+` + "```" + `
+
+Child outputs that are given a ` + "`label`" + ` are identified by that label within the ` + "`path`" + ` of their metrics, logs and traces (e.g. ` + "`root.output.broker.outputs.foo`" + `) rather than their index, and therefore remain stable when outputs are added, removed or reordered.`,
 		Footnotes: `
 ## Patterns
 
@@ -73,12 +75,31 @@ With the round robin pattern each message will be assigned a single output follo
 
 ### ` + "`greedy`" + `
 
-The greedy pattern results in higher output throughput at the cost of potentially disproportionate message allocations to those outputs. Each message is sent to a single output, which is determined by allowing outputs to claim messages as soon as they are able to process them. This results in certain faster outputs potentially processing more messages at the cost of slower outputs.`,
+The greedy pattern results in higher output throughput at the cost of potentially disproportionate message allocations to those outputs. Each message is sent to a single output, which is determined by allowing outputs to claim messages as soon as they are able to process them. This results in certain faster outputs potentially processing more messages at the cost of slower outputs.
+
+### ` + "`partition`" + `
+
+With the partition pattern each message is sent to a single output, which is determined by a hash of the ` + "`partition_key`" + ` of the message. Messages that share a key are therefore always sent to the same output, which preserves their ordering whilst messages of different keys are written in parallel. This is useful for spreading messages across multiple connections of an output that is only able to write to a single connection at a time, such as a TCP sink, where the ` + "`copies`" + ` field can be used in order to spawn the connections from a single output config:
+
+` + "```yaml" + `
+output:
+  broker:
+    pattern: partition
+    partition_key: ${! meta("device_id") }
+    copies: 8
+    outputs:
+      - socket:
+          network: tcp
+          address: localhost:6000
+` + "```" + `
+
+Batches are split by the key of each message, and a batch is only acknowledged once all outputs have acknowledged their messages of it. If an output applies back pressure it will block all subsequent messages. Since the output of a key depends on the total number of outputs, changing the number of outputs or copies changes the output that each key is sent to.`,
 		Config: docs.FieldComponent().WithChildren(
 			docs.FieldInt("copies", "The number of copies of each configured output to spawn.").Advanced().HasDefault(1),
 			docs.FieldString("pattern", "The brokering pattern to use.").HasOptions(
-				"fan_out", "fan_out_fail_fast", "fan_out_sequential", "fan_out_sequential_fail_fast", "round_robin", "greedy",
+				"fan_out", "fan_out_fail_fast", "fan_out_sequential", "fan_out_sequential_fail_fast", "round_robin", "greedy", "partition",
 			).HasDefault("fan_out"),
+			docs.FieldInterpolatedString("partition_key", "An interpolated string yielding the key to partition messages by when the `partition` pattern is used.", `${! meta("kafka_key") }`, `${! json("device_id") }`).Advanced().HasDefault("").AtVersion("4.24.0"),
 			docs.FieldOutput("outputs", "A list of child outputs to broker.").Array().HasDefault([]any{}),
 			policy.FieldSpec(),
 		),
@@ -121,7 +142,7 @@ func newBroker(conf output.Config, mgr bundle.NewManagement) (output.Streamed, e
 	var err error
 	for j := 0; j < conf.Broker.Copies; j++ {
 		for i, oConf := range outputConfs {
-			oMgr := mgr.IntoPath("broker", "outputs", strconv.Itoa(i))
+			oMgr := mgr.IntoPath("broker", "outputs", bundle.PathSegment(i, oConf.Label))
 			tmpOut, err := oMgr.NewOutput(oConf)
 			if err != nil {
 				return nil, err
@@ -145,6 +166,15 @@ func newBroker(conf output.Config, mgr bundle.NewManagement) (output.Streamed, e
 		b, err = newRoundRobinOutputBroker(outputs)
 	case "greedy":
 		b, err = newGreedyOutputBroker(outputs)
+	case "partition":
+		if conf.Broker.PartitionKey == "" {
+			return nil, errors.New("a partition_key must be specified when using the partition pattern")
+		}
+		var key *field.Expression
+		if key, err = mgr.BloblEnvironment().NewField(conf.Broker.PartitionKey); err != nil {
+			return nil, fmt.Errorf("failed to parse partition_key expression: %v", err)
+		}
+		b, err = newPartitionOutputBroker(key, mgr.Logger(), outputs)
 	default:
 		return nil, fmt.Errorf("broker pattern was not recognised: %v", conf.Broker.Pattern)
 	}
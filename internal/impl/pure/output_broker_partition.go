@@ -0,0 +1,225 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/batch"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+)
+
+type partitionOutputBroker struct {
+	log log.Modular
+	key *field.Expression
+
+	transactions <-chan message.Transaction
+
+	outputTSChans []chan message.Transaction
+	outputs       []output.Streamed
+
+	shutSig *shutdown.Signaller
+}
+
+func newPartitionOutputBroker(key *field.Expression, log log.Modular, outputs []output.Streamed) (*partitionOutputBroker, error) {
+	o := &partitionOutputBroker{
+		log:     log,
+		key:     key,
+		outputs: outputs,
+		shutSig: shutdown.NewSignaller(),
+	}
+	o.outputTSChans = make([]chan message.Transaction, len(o.outputs))
+	for i := range o.outputTSChans {
+		o.outputTSChans[i] = make(chan message.Transaction)
+		if err := o.outputs[i].Consume(o.outputTSChans[i]); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+func (o *partitionOutputBroker) Consume(ts <-chan message.Transaction) error {
+	if o.transactions != nil {
+		return component.ErrAlreadyStarted
+	}
+	o.transactions = ts
+
+	go o.loop()
+	return nil
+}
+
+func (o *partitionOutputBroker) Connected() bool {
+	for _, out := range o.outputs {
+		if !out.Connected() {
+			return false
+		}
+	}
+	return true
+}
+
+// outputIndex returns the index of the output that a message should be routed
+// to based on a hash of its partition key.
+func (o *partitionOutputBroker) outputIndex(i int, msg message.Batch) int {
+	k, err := o.key.String(i, msg)
+	if err != nil {
+		o.log.Debugf("Failed to resolve partition key: %v\n", err)
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(k))
+	return int(h.Sum32() % uint32(len(o.outputs)))
+}
+
+// dispatch sends the messages of a batch to their allocated outputs, calling
+// ackFn once all outputs have acknowledged their messages.
+func (o *partitionOutputBroker) dispatch(group *message.SortGroup, sourceMsg message.Batch, targets [][]*message.Part, ackFn func(context.Context, error) error) {
+	var errLock sync.Mutex
+	var generalErr error
+	var batchErr *batch.Error
+
+	setErrForPart := func(part *message.Part, err error) {
+		errLock.Lock()
+		defer errLock.Unlock()
+
+		index := group.GetIndex(part)
+		if index == -1 {
+			generalErr = err
+			return
+		}
+		if batchErr == nil {
+			batchErr = batch.NewError(sourceMsg, err)
+		}
+		batchErr.Failed(index, err)
+	}
+	getErr := func() error {
+		errLock.Lock()
+		defer errLock.Unlock()
+		if batchErr != nil {
+			return batchErr
+		}
+		return generalErr
+	}
+
+	var pendingResponses int64
+	for _, parts := range targets {
+		if len(parts) > 0 {
+			pendingResponses++
+		}
+	}
+	if pendingResponses == 0 {
+		ctx, done := o.shutSig.CloseNowCtx(context.Background())
+		defer done()
+		_ = ackFn(ctx, nil)
+		return
+	}
+
+	for i, parts := range targets {
+		if len(parts) == 0 {
+			continue
+		}
+
+		parts := parts
+		select {
+		case o.outputTSChans[i] <- message.NewTransactionFunc(parts, func(ctx context.Context, err error) error {
+			if err != nil {
+				var bErr *batch.Error
+				if errors.As(err, &bErr) {
+					bErr.WalkPartsBySource(group, sourceMsg, func(_ int, p *message.Part, e error) bool {
+						if e != nil {
+							setErrForPart(p, e)
+						}
+						return true
+					})
+				} else {
+					for _, p := range parts {
+						setErrForPart(p, err)
+					}
+				}
+			}
+			if atomic.AddInt64(&pendingResponses, -1) <= 0 {
+				return ackFn(ctx, getErr())
+			}
+			return nil
+		}):
+		case <-o.shutSig.CloseNowChan():
+			return
+		}
+	}
+}
+
+func (o *partitionOutputBroker) loop() {
+	ackInterruptChan := make(chan struct{})
+	var ackPending int64
+
+	defer func() {
+		// Wait for pending acks to be resolved, or forceful termination
+	ackWaitLoop:
+		for atomic.LoadInt64(&ackPending) > 0 {
+			select {
+			case <-ackInterruptChan:
+			case <-time.After(time.Millisecond * 100):
+				// Just incase an interrupt doesn't arrive.
+			case <-o.shutSig.CloseNowChan():
+				break ackWaitLoop
+			}
+		}
+		for _, c := range o.outputTSChans {
+			close(c)
+		}
+		_ = closeAllOutputs(context.Background(), o.outputs)
+		o.shutSig.ShutdownComplete()
+	}()
+
+	for {
+		var ts message.Transaction
+		var open bool
+		select {
+		case ts, open = <-o.transactions:
+			if !open {
+				return
+			}
+		case <-o.shutSig.CloseNowChan():
+			return
+		}
+
+		group, trackedMsg := message.NewSortGroup(ts.Payload)
+
+		targets := make([][]*message.Part, len(o.outputs))
+		_ = trackedMsg.Iter(func(i int, p *message.Part) error {
+			index := o.outputIndex(i, trackedMsg)
+			targets[index] = append(targets[index], p)
+			return nil
+		})
+
+		_ = atomic.AddInt64(&ackPending, 1)
+		o.dispatch(group, trackedMsg, targets, func(ctx context.Context, err error) error {
+			ackErr := ts.Ack(ctx, err)
+			_ = atomic.AddInt64(&ackPending, -1)
+			select {
+			case ackInterruptChan <- struct{}{}:
+			default:
+			}
+			return ackErr
+		})
+	}
+}
+
+func (o *partitionOutputBroker) TriggerCloseNow() {
+	o.shutSig.CloseNow()
+}
+
+func (o *partitionOutputBroker) WaitForClose(ctx context.Context) error {
+	select {
+	case <-o.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
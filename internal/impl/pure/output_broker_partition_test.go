@@ -0,0 +1,159 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/batch"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+var _ output.Streamed = &partitionOutputBroker{}
+
+func testPartitionBroker(t *testing.T, nOutputs int) (chan message.Transaction, []*mock.OutputChanneled) {
+	t.Helper()
+
+	key, err := mock.NewManager().BloblEnvironment().NewField(`${! meta("key") }`)
+	require.NoError(t, err)
+
+	var outputs []output.Streamed
+	var mockOutputs []*mock.OutputChanneled
+	for i := 0; i < nOutputs; i++ {
+		o := &mock.OutputChanneled{}
+		mockOutputs = append(mockOutputs, o)
+		outputs = append(outputs, o)
+	}
+
+	oTM, err := newPartitionOutputBroker(key, log.Noop(), outputs)
+	require.NoError(t, err)
+
+	readChan := make(chan message.Transaction)
+	require.NoError(t, oTM.Consume(readChan))
+	t.Cleanup(func() {
+		oTM.TriggerCloseNow()
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		require.NoError(t, oTM.WaitForClose(ctx))
+	})
+	return readChan, mockOutputs
+}
+
+func partitionTestBatch(keys ...string) message.Batch {
+	b := make(message.Batch, len(keys))
+	for i, k := range keys {
+		b[i] = message.NewPart([]byte(fmt.Sprintf("%v-%v", k, i)))
+		b[i].MetaSetMut("key", k)
+	}
+	return b
+}
+
+func TestPartitionBrokerAffinity(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	readChan, mockOutputs := testPartitionBroker(t, 3)
+
+	keyOutputs := map[string]int{}
+	for i := 0; i < 20; i++ {
+		resChan := make(chan error, 1)
+		key := fmt.Sprintf("key%v", i%5)
+		select {
+		case readChan <- message.NewTransaction(partitionTestBatch(key), resChan):
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+
+		var tran message.Transaction
+		var index int
+		select {
+		case tran = <-mockOutputs[0].TChan:
+			index = 0
+		case tran = <-mockOutputs[1].TChan:
+			index = 1
+		case tran = <-mockOutputs[2].TChan:
+			index = 2
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+
+		require.Equal(t, 1, tran.Payload.Len())
+		assert.Equal(t, key, tran.Payload.Get(0).MetaGetStr("key"))
+		if prev, exists := keyOutputs[key]; exists {
+			assert.Equal(t, prev, index, key)
+		}
+		keyOutputs[key] = index
+		require.NoError(t, tran.Ack(tCtx, nil))
+		require.NoError(t, <-resChan)
+	}
+}
+
+func TestPartitionBrokerBatchSplit(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	readChan, mockOutputs := testPartitionBroker(t, 2)
+
+	// Find two keys that are allocated to different outputs.
+	key, err := mock.NewManager().BloblEnvironment().NewField(`${! meta("key") }`)
+	require.NoError(t, err)
+	o := &partitionOutputBroker{key: key, log: log.Noop(), outputs: make([]output.Streamed, 2)}
+
+	keys := [2]string{}
+	for i := 0; keys[0] == "" || keys[1] == ""; i++ {
+		k := fmt.Sprintf("key%v", i)
+		index := o.outputIndex(0, partitionTestBatch(k))
+		if keys[index] == "" {
+			keys[index] = k
+		}
+	}
+
+	inBatch := partitionTestBatch(keys[0], keys[1], keys[0], keys[1])
+	resChan := make(chan error, 1)
+	select {
+	case readChan <- message.NewTransaction(inBatch, resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	for i, o := range mockOutputs {
+		var tran message.Transaction
+		select {
+		case tran = <-o.TChan:
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+		require.Equal(t, 2, tran.Payload.Len())
+		assert.Equal(t, fmt.Sprintf("%v-%v", keys[i], i), string(tran.Payload.Get(0).AsBytes()))
+		assert.Equal(t, fmt.Sprintf("%v-%v", keys[i], i+2), string(tran.Payload.Get(1).AsBytes()))
+
+		var ackErr error
+		if i == 1 {
+			ackErr = errors.New("nope")
+		}
+		require.NoError(t, tran.Ack(tCtx, ackErr))
+	}
+
+	err = <-resChan
+	require.Error(t, err)
+
+	var bErr *batch.Error
+	require.ErrorAs(t, err, &bErr)
+
+	var failed []int
+	bErr.WalkPartsNaively(func(i int, p *message.Part, err error) bool {
+		if err != nil {
+			failed = append(failed, i)
+		}
+		return true
+	})
+	assert.Equal(t, []int{1, 3}, failed)
+}
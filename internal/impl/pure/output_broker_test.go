@@ -13,7 +13,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/manager/mock"
 	"github.com/benthosdev/benthos/v4/internal/message"
 
@@ -393,3 +395,36 @@ broker:
 		})
 	}
 }
+
+func TestBrokerChildLabelPaths(t *testing.T) {
+	local := metrics.NewLocal()
+	mgr, err := manager.New(manager.NewResourceConfig(), manager.OptSetMetrics(metrics.NewNamespaced(local)))
+	require.NoError(t, err)
+
+	conf := output.NewConfig()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+broker:
+  outputs:
+    - label: foo
+      drop: {}
+    - drop: {}
+`), &conf))
+
+	s, err := mgr.IntoPath("output").NewOutput(conf)
+	require.NoError(t, err)
+
+	sendChan := make(chan message.Transaction)
+	require.NoError(t, s.Consume(sendChan))
+	t.Cleanup(func() {
+		s.TriggerCloseNow()
+		require.NoError(t, s.WaitForClose(context.Background()))
+	})
+
+	resChan := make(chan error)
+	sendChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello world")}), resChan)
+	require.NoError(t, <-resChan)
+
+	counters := local.GetCounters()
+	assert.Equal(t, int64(1), counters[`output_sent{label="foo",path="root.output.broker.outputs.foo"}`], counters)
+	assert.Equal(t, int64(1), counters[`output_sent{label="",path="root.output.broker.outputs.1"}`], counters)
+}
@@ -3,7 +3,6 @@ package pure
 import (
 	"context"
 	"errors"
-	"strconv"
 
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component"
@@ -40,6 +39,8 @@ output:
         path: /usr/local/benthos/everything_failed.jsonl
 ` + "```" + `
 
+Outputs with a ` + "`label`" + ` are identified by their label rather than their position in the list within the ` + "`path`" + ` of their metrics, logs and traces.
+
 ### Metadata
 
 When a given output fails the message routed to the following output will have a metadata value named ` + "`fallback_error`" + ` containing a string error message outlining the cause of the failure. The content of this string will depend on the particular output and can be used to enrich the message or provide information used to broker the data to an appropriate output using something like a ` + "`switch`" + ` output.
@@ -87,7 +88,7 @@ func newFallback(conf output.Config, mgr bundle.NewManagement) (output.Streamed,
 
 	var err error
 	for i, oConf := range outputConfs {
-		oMgr := mgr.IntoPath("fallback", strconv.Itoa(i))
+		oMgr := mgr.IntoPath("fallback", bundle.PathSegment(i, oConf.Label))
 		if outputs[i], err = oMgr.NewOutput(oConf); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,231 @@
+package pure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/interop"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	otFieldTopic         = "topic"
+	otFieldNoSubscribers = "no_subscribers"
+)
+
+func inprocTopicOutputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Publishes messages to a named in-process topic, where each [`inproc_topic` input](/docs/components/inputs/inproc_topic) subscribed to the topic receives its own copy.").
+		Description(`
+This output allows a stream to fan out data to any number of other streams whilst running Benthos in `+"[streams mode](/docs/guides/streams_mode/about)"+`. Any number of outputs can publish to the same topic, and subscribers can come and go at any time.
+
+### Delivery Guarantees
+
+A message is acknowledged once every input subscribed to the topic at the time of publishing has delivered it successfully. When a subscriber rejects a message it is redelivered to that subscriber only, with a backoff, until it succeeds or the subscriber is shut down, in which case delivery to it is abandoned.
+
+When there are no subscribers to a topic the `+"`no_subscribers`"+` field determines whether messages are held until one appears (`+"`wait`"+`) or acknowledged and discarded (`+"`drop`"+`).
+
+### Backpressure
+
+Messages are handed to subscribers in order and publishing blocks whilst the buffer of any subscriber is full, and therefore a topic flows at the pace of its slowest subscriber.`).
+		Fields(
+			service.NewStringField(otFieldTopic).
+				Description("The name of the topic to publish to."),
+			service.NewStringEnumField(otFieldNoSubscribers, "wait", "drop").
+				Description("Determines the behaviour when a message is published to a topic without subscribers.").
+				Default("wait").
+				Advanced(),
+		)
+}
+
+func init() {
+	err := service.RegisterBatchOutput(
+		"inproc_topic", inprocTopicOutputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, maxInFlight int, err error) {
+			var topic, noSubs string
+			if topic, err = conf.FieldString(otFieldTopic); err != nil {
+				return
+			}
+			if noSubs, err = conf.FieldString(otFieldNoSubscribers); err != nil {
+				return
+			}
+			out = interop.NewUnwrapInternalOutput(newInprocTopicOutput(topic, noSubs == "drop", interop.UnwrapManagement(mgr)))
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type inprocTopicOutput struct {
+	topic      string
+	dropNoSubs bool
+	mgr        bundle.NewManagement
+	log        log.Modular
+
+	transactionsIn <-chan message.Transaction
+
+	shutSig *shutdown.Signaller
+}
+
+func newInprocTopicOutput(topic string, dropNoSubs bool, mgr bundle.NewManagement) *inprocTopicOutput {
+	return &inprocTopicOutput{
+		topic:      topic,
+		dropNoSubs: dropNoSubs,
+		mgr:        mgr,
+		log:        mgr.Logger(),
+		shutSig:    shutdown.NewSignaller(),
+	}
+}
+
+// deliver sends a copy of a payload to a subscriber and waits for it to be
+// acknowledged, redelivering it on rejections until either it is successful or
+// the subscriber closes. The sent func is called once the first delivery has
+// been made, or abandoned.
+func (i *inprocTopicOutput) deliver(sub *bundle.TopicSubscriber, payload message.Batch, sent func()) error {
+	defer sent()
+
+	boff := backoff.NewExponentialBackOff()
+	boff.InitialInterval = time.Millisecond * 100
+	boff.MaxInterval = time.Second * 5
+	boff.MaxElapsedTime = 0
+
+	for {
+		resChan := make(chan error, 1)
+		select {
+		case sub.Transactions <- message.NewTransaction(payload.ShallowCopy(), resChan):
+		case <-sub.Done:
+			return nil
+		case <-i.shutSig.CloseNowChan():
+			return component.ErrTypeClosed
+		}
+		sent()
+
+		select {
+		case err := <-resChan:
+			if err == nil {
+				return nil
+			}
+			i.log.Debugf("Redelivering message rejected by subscriber of topic '%v': %v\n", i.topic, err)
+		case <-sub.Done:
+			return nil
+		case <-i.shutSig.CloseNowChan():
+			return component.ErrTypeClosed
+		}
+
+		select {
+		case <-time.After(boff.NextBackOff()):
+		case <-sub.Done:
+			return nil
+		case <-i.shutSig.CloseNowChan():
+			return component.ErrTypeClosed
+		}
+	}
+}
+
+func (i *inprocTopicOutput) loop() {
+	var pending sync.WaitGroup
+	defer func() {
+		pending.Wait()
+		i.shutSig.ShutdownComplete()
+	}()
+
+	i.log.Infof("Sending inproc messages to topic: %s\n", i.topic)
+
+	for {
+		var t message.Transaction
+		var open bool
+		select {
+		case t, open = <-i.transactionsIn:
+			if !open {
+				return
+			}
+		case <-i.shutSig.CloseNowChan():
+			return
+		}
+
+		subs := i.mgr.GetTopicSubscribers(i.topic)
+		for len(subs) == 0 && !i.dropNoSubs {
+			select {
+			case <-time.After(time.Millisecond * 100):
+			case <-i.shutSig.CloseNowChan():
+				return
+			}
+			subs = i.mgr.GetTopicSubscribers(i.topic)
+		}
+		if len(subs) == 0 {
+			_ = t.Ack(context.Background(), nil)
+			continue
+		}
+
+		var wg sync.WaitGroup
+		var errMut sync.Mutex
+		var deliveryErr error
+
+		wg.Add(len(subs))
+		for _, sub := range subs {
+			sentChan := make(chan struct{})
+			var sentOnce sync.Once
+			go func(sub *bundle.TopicSubscriber) {
+				defer wg.Done()
+				if err := i.deliver(sub, t.Payload, func() {
+					sentOnce.Do(func() { close(sentChan) })
+				}); err != nil {
+					errMut.Lock()
+					deliveryErr = err
+					errMut.Unlock()
+				}
+			}(sub)
+
+			// Wait for the subscriber to accept the message before moving
+			// onto the next, which applies backpressure from full buffers.
+			<-sentChan
+		}
+
+		pending.Add(1)
+		go func(t message.Transaction) {
+			defer pending.Done()
+			wg.Wait()
+			_ = t.Ack(context.Background(), deliveryErr)
+		}(t)
+	}
+}
+
+func (i *inprocTopicOutput) Consume(ts <-chan message.Transaction) error {
+	if i.transactionsIn != nil {
+		return component.ErrAlreadyStarted
+	}
+	i.transactionsIn = ts
+	go i.loop()
+	return nil
+}
+
+func (i *inprocTopicOutput) Connected() bool {
+	return true
+}
+
+func (i *inprocTopicOutput) TriggerCloseNow() {
+	i.shutSig.CloseNow()
+}
+
+func (i *inprocTopicOutput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-i.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
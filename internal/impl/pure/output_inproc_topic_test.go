@@ -0,0 +1,157 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func readInprocTopicTransaction(t *testing.T, i *inprocTopicInput) message.Transaction {
+	t.Helper()
+	select {
+	case tran, open := <-i.TransactionChan():
+		require.True(t, open)
+		return tran
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	return message.Transaction{}
+}
+
+func TestInprocTopicFanOut(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	inA := newInprocTopicInput("foo", 1, mgr)
+	inB := newInprocTopicInput("foo", 1, mgr)
+	out := newInprocTopicOutput("foo", false, mgr)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, out.Consume(tChan))
+
+	resChan := make(chan error, 1)
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	tranA := readInprocTopicTransaction(t, inA)
+	tranB := readInprocTopicTransaction(t, inB)
+	assert.Equal(t, "hello", string(tranA.Payload.Get(0).AsBytes()))
+	assert.Equal(t, "hello", string(tranB.Payload.Get(0).AsBytes()))
+
+	// Mutations made by one subscriber are not seen by others.
+	tranA.Payload.Get(0).SetBytes([]byte("changed"))
+	assert.Equal(t, "hello", string(tranB.Payload.Get(0).AsBytes()))
+
+	// Reject from one subscriber results in a redelivery to that subscriber
+	// only.
+	require.NoError(t, tranA.Ack(tCtx, errors.New("nope")))
+	require.NoError(t, tranB.Ack(tCtx, nil))
+
+	tranA = readInprocTopicTransaction(t, inA)
+	assert.Equal(t, "hello", string(tranA.Payload.Get(0).AsBytes()))
+
+	select {
+	case <-resChan:
+		t.Fatal("transaction acknowledged before all subscribers")
+	case tranB = <-inB.TransactionChan():
+		t.Fatalf("unexpected redelivery: %v", tranB)
+	default:
+	}
+
+	require.NoError(t, tranA.Ack(tCtx, nil))
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	for _, i := range []*inprocTopicInput{inA, inB} {
+		i.TriggerStopConsuming()
+		require.NoError(t, i.WaitForClose(tCtx))
+	}
+	assert.Empty(t, mgr.GetTopicSubscribers("foo"))
+
+	close(tChan)
+	out.TriggerCloseNow()
+	require.NoError(t, out.WaitForClose(tCtx))
+}
+
+func TestInprocTopicSubscriberClosed(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	in := newInprocTopicInput("foo", 1, mgr)
+	out := newInprocTopicOutput("foo", false, mgr)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, out.Consume(tChan))
+
+	resChan := make(chan error, 1)
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	// The message is received by the subscriber but never read downstream,
+	// closing the subscriber should result in the delivery being abandoned.
+	in.TriggerStopConsuming()
+	require.NoError(t, in.WaitForClose(tCtx))
+
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	out.TriggerCloseNow()
+	require.NoError(t, out.WaitForClose(tCtx))
+}
+
+func TestInprocTopicNoSubscribers(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	out := newInprocTopicOutput("foo", true, mgr)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, out.Consume(tChan))
+
+	resChan := make(chan error, 1)
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	out.TriggerCloseNow()
+	require.NoError(t, out.WaitForClose(tCtx))
+}
@@ -0,0 +1,645 @@
+package pure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	"github.com/benthosdev/benthos/v4/internal/component/interop"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	oboFieldOutput         = "output"
+	oboFieldWindow         = "window"
+	oboFieldTimestamp      = "timestamp"
+	oboFieldGracePeriod    = "grace_period"
+	oboFieldIdleTimeout    = "idle_timeout"
+	oboFieldMaxMessages    = "max_messages"
+	oboFieldMaxBytes       = "max_bytes"
+	oboFieldFormat         = "format"
+	oboFieldCache          = "cache"
+	oboFieldCacheKeyPrefix = "cache_key_prefix"
+)
+
+func objectBatcherOutputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Accumulates messages into objects that cover fixed windows of time, and writes each object as a single message to a child output such as `aws_s3` or `gcp_cloud_storage`.").
+		Description(`
+Each message is allocated to a window of time aligned to the unix epoch, e.g. with a `+"`window`"+` of `+"`1h`"+` a message with a timestamp of 10:35 UTC belongs to the window starting at 10:00 UTC. By default the timestamp of a message is the time it reached this output, and a `+"`timestamp`"+` query can be specified in order to window messages by a time found within the message instead.
+
+The messages of a window are combined according to a `+"`format`"+` into an object, which is written to the child output as a single message once the window has ended, after waiting for a `+"`grace_period`"+` for late messages. When a `+"`timestamp`"+` query is specified windows end once a message with a timestamp beyond the end of the window has been seen, and therefore an `+"`idle_timeout`"+` should be set in order to write objects when the stream pauses. Objects are also written early once they reach either `+"`max_messages`"+` or `+"`max_bytes`"+`, in which case the remaining messages of the window are written to further objects.
+
+The objects of a window are numbered by a shard starting at zero, and the window and shard of each object are added to its metadata, which allows the child output to write it under a deterministic name:
+
+`+"```yaml"+`
+output:
+  object_batcher:
+    window: 1h
+    output:
+      aws_s3:
+        bucket: TODO
+        path: 'events/${! @object_batcher_window }-${! @object_batcher_shard }.jsonl'
+`+"```"+`
+
+Messages are only acknowledged once the object they belong to has been written, and since each object is written as a single message the child output never writes a partial object. When an object fails to be written the messages it contains are rejected, and its shard is released so that it is claimed by the next object of the window, which prevents gaps in the shard numbers.
+
+### Restarts
+
+When a `+"`cache`"+` is specified the number of shards written for each window is recorded within it, which allows objects that are written after a restart, such as late messages or messages of a window that was only partially written, to continue from the next shard of their window rather than overwriting objects that have already been written. Without a cache shard numbers are only tracked in memory.
+
+### Metadata
+
+The following metadata fields are added to each object:
+
+`+"```text"+`
+- object_batcher_window (the start of the window as a unix timestamp in seconds)
+- object_batcher_window_end (the end of the window as a unix timestamp in seconds)
+- object_batcher_shard
+- object_batcher_count (the number of messages within the object)
+`+"```"+``).
+		Fields(
+			service.NewOutputField(oboFieldOutput).
+				Description("The child output to write objects to."),
+			service.NewDurationField(oboFieldWindow).
+				Description("The duration of each window, windows are aligned to the unix epoch.").
+				Default("1h"),
+			service.NewBloblangField(oboFieldTimestamp).
+				Description("An optional [Bloblang query](/docs/guides/bloblang/about/) that should return the timestamp of a message, which determines the window it belongs to. When omitted the time at which a message reaches this output is used.").
+				Examples(`this.created_at`, `@kafka_timestamp_unix`).
+				Optional(),
+			service.NewDurationField(oboFieldGracePeriod).
+				Description("A duration to wait after the end of a window for late messages before its object is written.").
+				Default("0s").
+				Advanced(),
+			service.NewDurationField(oboFieldIdleTimeout).
+				Description("An optional duration after which an object that has not received any messages is written even when its window has not ended. This should be set when a `timestamp` query is specified.").
+				Examples("1m").
+				Optional(),
+			service.NewIntField(oboFieldMaxMessages).
+				Description("The maximum number of messages within an object, when reached the object is written and subsequent messages of the window are written to a further shard. Set to `0` for no limit.").
+				Default(0),
+			service.NewIntField(oboFieldMaxBytes).
+				Description("The number of bytes at which an object is written, subsequent messages of the window are written to a further shard. Set to `0` for no limit.").
+				Default(0),
+			service.NewStringAnnotatedEnumField(oboFieldFormat, map[string]string{
+				"lines":       "Join the raw contents of messages with line breaks.",
+				"json_array":  "Add messages to a JSON array, messages that are not valid JSON are added as strings.",
+				"concatenate": "Join the raw contents of messages without any delimiter.",
+			}).
+				Description("The format in which the messages of an object are combined.").
+				Default("lines"),
+			service.NewStringField(oboFieldCache).
+				Description("An optional [cache resource](/docs/components/caches/about) in which to record the number of shards written for each window.").
+				Optional(),
+			service.NewStringField(oboFieldCacheKeyPrefix).
+				Description("A prefix to add to the keys of windows recorded within the `cache`, which allows multiple object batchers to share a cache.").
+				Default("object_batcher_").
+				Advanced(),
+		).
+		Example("Hourly Objects", "Write events into an object per hour of the time they were created, with large hours split into objects of up to a hundred megabytes, and with written windows recorded in a Redis cache in order to survive restarts.", `
+output:
+  object_batcher:
+    window: 1h
+    timestamp: this.created_at
+    grace_period: 5m
+    idle_timeout: 5m
+    max_bytes: 100000000
+    cache: windows
+    output:
+      gcp_cloud_storage:
+        bucket: TODO
+        path: 'events/${! @object_batcher_window.number().ts_format("2006/01/02/15", "UTC") }-${! @object_batcher_shard }.jsonl'
+
+cache_resources:
+  - label: windows
+    redis:
+      url: redis://localhost:6379
+`)
+}
+
+func init() {
+	err := service.RegisterBatchOutput(
+		"object_batcher", objectBatcherOutputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, maxInFlight int, err error) {
+			var o *objectBatcherOutput
+			if o, err = newObjectBatcherOutputFromParsed(conf, mgr); err != nil {
+				return
+			}
+			out = interop.NewUnwrapInternalOutput(o)
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// objectBatcherPending is an object that is accumulating the messages of a
+// window.
+type objectBatcherPending struct {
+	window    time.Time
+	buf       bytes.Buffer
+	count     int
+	lastAdded time.Time
+	ackFns    []func(context.Context, error) error
+}
+
+type objectBatcherOutput struct {
+	log log.Modular
+	mgr bundle.NewManagement
+
+	window         time.Duration
+	timestamp      *mapping.Executor
+	gracePeriod    time.Duration
+	idleTimeout    time.Duration
+	maxMessages    int
+	maxBytes       int
+	format         string
+	cache          string
+	cacheKeyPrefix string
+
+	// Only accessed from the loop goroutine.
+	pending   map[int64]*objectBatcherPending
+	watermark time.Time
+
+	// Shards are released by the acknowledgements of failed writes, which
+	// happen outside of the loop goroutine.
+	shardsMut sync.Mutex
+	shards    map[int64]*objectBatcherShards
+
+	child      output.Streamed
+	childTrans chan message.Transaction
+
+	transactionsIn <-chan message.Transaction
+
+	shutSig *shutdown.Signaller
+}
+
+func newObjectBatcherOutputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*objectBatcherOutput, error) {
+	nm := interop.UnwrapManagement(mgr)
+
+	o := &objectBatcherOutput{
+		log:     nm.Logger(),
+		mgr:     nm,
+		pending: map[int64]*objectBatcherPending{},
+		shards:  map[int64]*objectBatcherShards{},
+		shutSig: shutdown.NewSignaller(),
+	}
+
+	var err error
+	if o.window, err = conf.FieldDuration(oboFieldWindow); err != nil {
+		return nil, err
+	}
+	if o.window <= 0 {
+		return nil, errors.New("window must be greater than zero")
+	}
+	if conf.Contains(oboFieldTimestamp) {
+		tsStr, err := conf.FieldString(oboFieldTimestamp)
+		if err != nil {
+			return nil, err
+		}
+		if o.timestamp, err = nm.BloblEnvironment().NewMapping(tsStr); err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp query: %w", err)
+		}
+	}
+	if o.gracePeriod, err = conf.FieldDuration(oboFieldGracePeriod); err != nil {
+		return nil, err
+	}
+	if conf.Contains(oboFieldIdleTimeout) {
+		if o.idleTimeout, err = conf.FieldDuration(oboFieldIdleTimeout); err != nil {
+			return nil, err
+		}
+	}
+	if o.maxMessages, err = conf.FieldInt(oboFieldMaxMessages); err != nil {
+		return nil, err
+	}
+	if o.maxBytes, err = conf.FieldInt(oboFieldMaxBytes); err != nil {
+		return nil, err
+	}
+	if o.format, err = conf.FieldString(oboFieldFormat); err != nil {
+		return nil, err
+	}
+
+	if conf.Contains(oboFieldCache) {
+		if o.cache, err = conf.FieldString(oboFieldCache); err != nil {
+			return nil, err
+		}
+		if !nm.ProbeCache(o.cache) {
+			return nil, fmt.Errorf("cache resource '%v' was not found", o.cache)
+		}
+	}
+	if o.cacheKeyPrefix, err = conf.FieldString(oboFieldCacheKeyPrefix); err != nil {
+		return nil, err
+	}
+
+	childAny, err := conf.FieldAny(oboFieldOutput)
+	if err != nil {
+		return nil, err
+	}
+	childNode, ok := childAny.(*yaml.Node)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value, expected object, got %T", childAny)
+	}
+	childConf := output.NewConfig()
+	if err := childNode.Decode(&childConf); err != nil {
+		return nil, err
+	}
+	if o.child, err = nm.IntoPath("object_batcher", oboFieldOutput).NewOutput(childConf); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// messageWindow returns the start of the window that a message belongs to.
+func (o *objectBatcherOutput) messageWindow(index int, batch message.Batch, now time.Time) time.Time {
+	ts := now
+	if o.timestamp != nil {
+		qTS, err := queryTimestamp(o.timestamp, index, batch)
+		if err != nil {
+			o.log.Errorf("Failed to query message timestamp, falling back to the current time: %v\n", err)
+		} else if qTS != nil {
+			ts = *qTS
+		}
+		if ts.After(o.watermark) {
+			o.watermark = ts
+		}
+	}
+	return objectBatcherWindowStart(ts, o.window)
+}
+
+// objectBatcherWindowStart returns the start of the window of a given duration
+// that a timestamp belongs to, where windows are aligned to the unix epoch.
+func objectBatcherWindowStart(ts time.Time, window time.Duration) time.Time {
+	nanos := ts.UnixNano()
+	offset := nanos % int64(window)
+	if offset < 0 {
+		offset += int64(window)
+	}
+	return time.Unix(0, nanos-offset).UTC()
+}
+
+// currentWatermark returns the time up to which windows are considered
+// complete.
+func (o *objectBatcherOutput) currentWatermark(now time.Time) time.Time {
+	if o.timestamp != nil {
+		return o.watermark
+	}
+	return now
+}
+
+// appendPart writes the contents of a message to a pending object according to
+// the configured format.
+func (o *objectBatcherOutput) appendPart(obj *objectBatcherPending, p *message.Part) {
+	switch o.format {
+	case "lines":
+		if obj.count > 0 {
+			_ = obj.buf.WriteByte('\n')
+		}
+		_, _ = obj.buf.Write(p.AsBytes())
+	case "json_array":
+		if obj.count == 0 {
+			_ = obj.buf.WriteByte('[')
+		} else {
+			_ = obj.buf.WriteByte(',')
+		}
+		if b := p.AsBytes(); json.Valid(b) {
+			_, _ = obj.buf.Write(b)
+		} else {
+			b, _ = json.Marshal(string(b))
+			_, _ = obj.buf.Write(b)
+		}
+	default:
+		_, _ = obj.buf.Write(p.AsBytes())
+	}
+}
+
+func (o *objectBatcherOutput) add(tran message.Transaction, now time.Time) (full []*objectBatcherPending) {
+	var touched []*objectBatcherPending
+	_ = tran.Payload.Iter(func(i int, p *message.Part) error {
+		window := o.messageWindow(i, tran.Payload, now)
+
+		obj, exists := o.pending[window.Unix()]
+		if !exists {
+			obj = &objectBatcherPending{window: window}
+			o.pending[window.Unix()] = obj
+		}
+		o.appendPart(obj, p)
+		obj.count++
+		obj.lastAdded = now
+
+		var seen bool
+		for _, t := range touched {
+			if t == obj {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			touched = append(touched, obj)
+		}
+		return nil
+	})
+
+	ackFn := ttlAckGroup(len(touched), tran)
+	for _, obj := range touched {
+		obj.ackFns = append(obj.ackFns, ackFn)
+		if (o.maxMessages > 0 && obj.count >= o.maxMessages) || (o.maxBytes > 0 && obj.buf.Len() >= o.maxBytes) {
+			full = append(full, obj)
+		}
+	}
+	if len(touched) == 0 {
+		_ = tran.Ack(context.Background(), nil)
+	}
+	return
+}
+
+// expired returns all pending objects that should be written at the provided
+// time, and the time at which the next pending object should be written, which
+// is zero when no object is waiting on the clock.
+func (o *objectBatcherOutput) expired(now time.Time) (objs []*objectBatcherPending, next time.Time) {
+	watermark := o.currentWatermark(now)
+	for _, obj := range o.pending {
+		windowDeadline := obj.window.Add(o.window + o.gracePeriod)
+		if !watermark.Before(windowDeadline) {
+			objs = append(objs, obj)
+			continue
+		}
+
+		var deadlines []time.Time
+		if o.timestamp == nil {
+			deadlines = append(deadlines, windowDeadline)
+		}
+		if o.idleTimeout > 0 {
+			idleDeadline := obj.lastAdded.Add(o.idleTimeout)
+			if !now.Before(idleDeadline) {
+				objs = append(objs, obj)
+				continue
+			}
+			deadlines = append(deadlines, idleDeadline)
+		}
+		for _, d := range deadlines {
+			if next.IsZero() || d.Before(next) {
+				next = d
+			}
+		}
+	}
+	return
+}
+
+func (o *objectBatcherOutput) cacheKey(window time.Time) string {
+	return o.cacheKeyPrefix + strconv.FormatInt(window.Unix(), 10)
+}
+
+// objectBatcherShards tracks the shards claimed for a window, along with those
+// released by failed writes that are yet to be claimed again.
+type objectBatcherShards struct {
+	next     int
+	released []int
+}
+
+func (o *objectBatcherOutput) setCachedShards(ctx context.Context, window time.Time, next int) (err error) {
+	if cerr := o.mgr.AccessCache(ctx, o.cache, func(c cache.V1) {
+		err = c.Set(ctx, o.cacheKey(window), []byte(strconv.Itoa(next)), nil)
+	}); cerr != nil {
+		err = cerr
+	}
+	return
+}
+
+// claimShard returns the next shard of a window, recording it within the cache
+// when one is configured. Shards that have been released are claimed first.
+func (o *objectBatcherOutput) claimShard(ctx context.Context, window time.Time) (shard int, err error) {
+	o.shardsMut.Lock()
+	defer o.shardsMut.Unlock()
+
+	shards, exists := o.shards[window.Unix()]
+	if !exists {
+		shards = &objectBatcherShards{}
+		if o.cache != "" {
+			if cerr := o.mgr.AccessCache(ctx, o.cache, func(c cache.V1) {
+				var v []byte
+				if v, err = c.Get(ctx, o.cacheKey(window)); err != nil {
+					if errors.Is(err, component.ErrKeyNotFound) {
+						err = nil
+					}
+					return
+				}
+				shards.next, err = strconv.Atoi(string(v))
+			}); cerr != nil {
+				err = cerr
+			}
+			if err != nil {
+				return 0, fmt.Errorf("failed to read window from cache: %w", err)
+			}
+		}
+		o.shards[window.Unix()] = shards
+	}
+
+	if len(shards.released) > 0 {
+		shard = shards.released[0]
+		shards.released = shards.released[1:]
+		return shard, nil
+	}
+
+	if o.cache != "" {
+		if err = o.setCachedShards(ctx, window, shards.next+1); err != nil {
+			return 0, fmt.Errorf("failed to record window in cache: %w", err)
+		}
+	}
+	shard = shards.next
+	shards.next++
+	return shard, nil
+}
+
+// releaseShard returns the shard of an object that failed to be written so
+// that it can be claimed again. When it is the latest shard of the window the
+// claim is rolled back, including within the cache, otherwise it is claimed by
+// the next object of the window.
+func (o *objectBatcherOutput) releaseShard(ctx context.Context, window time.Time, shard int) {
+	o.shardsMut.Lock()
+	defer o.shardsMut.Unlock()
+
+	shards, exists := o.shards[window.Unix()]
+	if !exists {
+		return
+	}
+
+	shards.released = append(shards.released, shard)
+	sort.Ints(shards.released)
+
+	next := shards.next
+	for len(shards.released) > 0 && shards.released[len(shards.released)-1] == shards.next-1 {
+		shards.released = shards.released[:len(shards.released)-1]
+		shards.next--
+	}
+
+	if o.cache != "" && shards.next != next {
+		if err := o.setCachedShards(ctx, window, shards.next); err != nil {
+			o.log.Errorf("Failed to release object shard: %v", err)
+		}
+	}
+}
+
+// flush writes a pending object to the child output, the messages of the
+// object are acknowledged once the write has completed.
+func (o *objectBatcherOutput) flush(ctx context.Context, obj *objectBatcherPending) bool {
+	if o.pending[obj.window.Unix()] == obj {
+		delete(o.pending, obj.window.Unix())
+	}
+
+	// Acknowledgements can outlive the loop, and therefore its context.
+	ackAll := func(err error) {
+		for _, fn := range obj.ackFns {
+			_ = fn(context.Background(), err)
+		}
+	}
+
+	shard, err := o.claimShard(ctx, obj.window)
+	if err != nil {
+		o.log.Errorf("Failed to allocate object shard: %v", err)
+		ackAll(err)
+		return true
+	}
+
+	if o.format == "json_array" {
+		_ = obj.buf.WriteByte(']')
+	}
+
+	part := message.NewPart(obj.buf.Bytes())
+	part.MetaSetMut("object_batcher_window", obj.window.Unix())
+	part.MetaSetMut("object_batcher_window_end", obj.window.Add(o.window).Unix())
+	part.MetaSetMut("object_batcher_shard", int64(shard))
+	part.MetaSetMut("object_batcher_count", int64(obj.count))
+
+	resChan := make(chan error, 1)
+	select {
+	case o.childTrans <- message.NewTransaction(message.Batch{part}, resChan):
+	case <-o.shutSig.CloseNowChan():
+		return false
+	}
+
+	go func() {
+		select {
+		case err := <-resChan:
+			if err != nil {
+				o.releaseShard(context.Background(), obj.window, shard)
+			}
+			ackAll(err)
+		case <-o.shutSig.CloseNowChan():
+		}
+	}()
+	return true
+}
+
+func (o *objectBatcherOutput) loop() {
+	ctx, done := o.shutSig.CloseNowCtx(context.Background())
+	defer done()
+
+	defer func() {
+		close(o.childTrans)
+		if o.shutSig.ShouldCloseNow() {
+			o.child.TriggerCloseNow()
+		}
+		_ = o.child.WaitForClose(context.Background())
+		o.shutSig.ShutdownComplete()
+	}()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		now := time.Now()
+
+		expired, next := o.expired(now)
+		for _, obj := range expired {
+			if !o.flush(ctx, obj) {
+				return
+			}
+		}
+
+		var timerChan <-chan time.Time
+		if !next.IsZero() {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(next.Sub(now))
+			timerChan = timer.C
+		}
+
+		select {
+		case tran, open := <-o.transactionsIn:
+			if !open {
+				for _, obj := range o.pending {
+					if !o.flush(ctx, obj) {
+						return
+					}
+				}
+				return
+			}
+			for _, obj := range o.add(tran, time.Now()) {
+				if !o.flush(ctx, obj) {
+					return
+				}
+			}
+		case <-timerChan:
+		case <-o.shutSig.CloseNowChan():
+			return
+		}
+	}
+}
+
+func (o *objectBatcherOutput) Consume(ts <-chan message.Transaction) error {
+	if o.transactionsIn != nil {
+		return component.ErrAlreadyStarted
+	}
+
+	o.childTrans = make(chan message.Transaction)
+	if err := o.child.Consume(o.childTrans); err != nil {
+		return err
+	}
+
+	o.transactionsIn = ts
+	go o.loop()
+	return nil
+}
+
+func (o *objectBatcherOutput) Connected() bool {
+	return o.child.Connected()
+}
+
+func (o *objectBatcherOutput) TriggerCloseNow() {
+	o.shutSig.CloseNow()
+}
+
+func (o *objectBatcherOutput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-o.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+package pure_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	bmock "github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+type objectBatcherTestObject struct {
+	content string
+	window  any
+	shard   any
+	count   any
+}
+
+type objectBatcherTestOutput struct {
+	mut     sync.Mutex
+	objects []objectBatcherTestObject
+	mgr     *bmock.Manager
+}
+
+func newObjectBatcherTestOutput() *objectBatcherTestOutput {
+	o := &objectBatcherTestOutput{mgr: bmock.NewManager()}
+	o.mgr.Outputs["objects"] = func(ctx context.Context, tran message.Transaction) error {
+		o.mut.Lock()
+		_ = tran.Payload.Iter(func(i int, p *message.Part) error {
+			window, _ := p.MetaGetMut("object_batcher_window")
+			shard, _ := p.MetaGetMut("object_batcher_shard")
+			count, _ := p.MetaGetMut("object_batcher_count")
+			o.objects = append(o.objects, objectBatcherTestObject{
+				content: string(p.AsBytes()),
+				window:  window,
+				shard:   shard,
+				count:   count,
+			})
+			return nil
+		})
+		o.mut.Unlock()
+		return tran.Ack(ctx, nil)
+	}
+	return o
+}
+
+func (o *objectBatcherTestOutput) get() []objectBatcherTestObject {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	return append([]objectBatcherTestObject(nil), o.objects...)
+}
+
+func sendObjectBatcherMsgs(t testing.TB, tChan chan<- message.Transaction, contents ...string) <-chan error {
+	t.Helper()
+
+	resChan := make(chan error, 1)
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch(func() (b [][]byte) {
+		for _, c := range contents {
+			b = append(b, []byte(c))
+		}
+		return
+	}()), resChan):
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	return resChan
+}
+
+func requireObjectBatcherAck(t testing.TB, resChan <-chan error) {
+	t.Helper()
+
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+}
+
+func requireObjectBatcherNoAck(t testing.TB, resChan <-chan error) {
+	t.Helper()
+
+	select {
+	case <-resChan:
+		t.Fatal("unexpected acknowledgement")
+	case <-time.After(time.Millisecond * 50):
+	}
+}
+
+func TestObjectBatcherEventTimeWindows(t *testing.T) {
+	outs := newObjectBatcherTestOutput()
+	o, err := outs.mgr.NewOutput(parseYAMLOutputConf(t, `
+object_batcher:
+  window: 1h
+  timestamp: this.ts
+  output:
+    resource: objects
+`))
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tChan))
+
+	resA := sendObjectBatcherMsgs(t, tChan, `{"id":"a","ts":3900}`)
+	resB := sendObjectBatcherMsgs(t, tChan, `{"id":"b","ts":4000}`)
+	requireObjectBatcherNoAck(t, resA)
+	requireObjectBatcherNoAck(t, resB)
+
+	// Moves the watermark beyond the end of the first window.
+	resC := sendObjectBatcherMsgs(t, tChan, `{"id":"c","ts":7300}`)
+	requireObjectBatcherAck(t, resA)
+	requireObjectBatcherAck(t, resB)
+	requireObjectBatcherNoAck(t, resC)
+
+	// Late messages of a written window are written to a further shard.
+	requireObjectBatcherAck(t, sendObjectBatcherMsgs(t, tChan, `{"id":"d","ts":3700}`))
+
+	close(tChan)
+	requireObjectBatcherAck(t, resC)
+	require.NoError(t, o.WaitForClose(context.Background()))
+
+	assert.Equal(t, []objectBatcherTestObject{
+		{content: "{\"id\":\"a\",\"ts\":3900}\n{\"id\":\"b\",\"ts\":4000}", window: int64(3600), shard: int64(0), count: int64(2)},
+		{content: `{"id":"d","ts":3700}`, window: int64(3600), shard: int64(1), count: int64(1)},
+		{content: `{"id":"c","ts":7300}`, window: int64(7200), shard: int64(0), count: int64(1)},
+	}, outs.get())
+}
+
+func TestObjectBatcherShardsWithCache(t *testing.T) {
+	outs := newObjectBatcherTestOutput()
+	outs.mgr.Caches["windows"] = map[string]bmock.CacheItem{
+		"foo_0": {Value: "2"},
+	}
+
+	o, err := outs.mgr.NewOutput(parseYAMLOutputConf(t, `
+object_batcher:
+  window: 1m
+  timestamp: this.ts
+  max_messages: 2
+  format: json_array
+  cache: windows
+  cache_key_prefix: foo_
+  output:
+    resource: objects
+`))
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tChan))
+
+	requireObjectBatcherAck(t, sendObjectBatcherMsgs(t, tChan, `{"ts":1}`, `{"ts":2}`))
+	requireObjectBatcherAck(t, sendObjectBatcherMsgs(t, tChan, `{"ts":3}`, `{"ts":4}`, `{"ts":5}`))
+	res := sendObjectBatcherMsgs(t, tChan, `{"ts":6}`)
+
+	close(tChan)
+	requireObjectBatcherAck(t, res)
+	require.NoError(t, o.WaitForClose(context.Background()))
+
+	assert.Equal(t, []objectBatcherTestObject{
+		{content: `[{"ts":1},{"ts":2}]`, window: int64(0), shard: int64(2), count: int64(2)},
+		{content: `[{"ts":3},{"ts":4},{"ts":5}]`, window: int64(0), shard: int64(3), count: int64(3)},
+		{content: `[{"ts":6}]`, window: int64(0), shard: int64(4), count: int64(1)},
+	}, outs.get())
+	assert.Equal(t, "5", outs.mgr.Caches["windows"]["foo_0"].Value)
+}
+
+func TestObjectBatcherReleasesFailedShards(t *testing.T) {
+	outs := newObjectBatcherTestOutput()
+	outs.mgr.Caches["windows"] = map[string]bmock.CacheItem{}
+
+	writeObject := outs.mgr.Outputs["objects"]
+	var failed bool
+	outs.mgr.Outputs["objects"] = func(ctx context.Context, tran message.Transaction) error {
+		if !failed {
+			failed = true
+			return tran.Ack(ctx, errors.New("nope"))
+		}
+		return writeObject(ctx, tran)
+	}
+
+	o, err := outs.mgr.NewOutput(parseYAMLOutputConf(t, `
+object_batcher:
+  window: 1m
+  timestamp: this.ts
+  max_messages: 2
+  cache: windows
+  output:
+    resource: objects
+`))
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tChan))
+
+	select {
+	case err := <-sendObjectBatcherMsgs(t, tChan, `{"ts":1}`, `{"ts":2}`):
+		require.Error(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	assert.Equal(t, "0", outs.mgr.Caches["windows"]["object_batcher_0"].Value)
+
+	requireObjectBatcherAck(t, sendObjectBatcherMsgs(t, tChan, `{"ts":1}`, `{"ts":2}`))
+	requireObjectBatcherAck(t, sendObjectBatcherMsgs(t, tChan, `{"ts":3}`, `{"ts":4}`))
+
+	close(tChan)
+	require.NoError(t, o.WaitForClose(context.Background()))
+
+	assert.Equal(t, []objectBatcherTestObject{
+		{content: "{\"ts\":1}\n{\"ts\":2}", window: int64(0), shard: int64(0), count: int64(2)},
+		{content: "{\"ts\":3}\n{\"ts\":4}", window: int64(0), shard: int64(1), count: int64(2)},
+	}, outs.get())
+	assert.Equal(t, "2", outs.mgr.Caches["windows"]["object_batcher_0"].Value)
+}
+
+func TestObjectBatcherIdleTimeout(t *testing.T) {
+	outs := newObjectBatcherTestOutput()
+	o, err := outs.mgr.NewOutput(parseYAMLOutputConf(t, `
+object_batcher:
+  window: 1h
+  timestamp: this.ts
+  idle_timeout: 10ms
+  output:
+    resource: objects
+`))
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tChan))
+
+	requireObjectBatcherAck(t, sendObjectBatcherMsgs(t, tChan, `{"ts":1}`))
+	assert.Equal(t, []objectBatcherTestObject{
+		{content: `{"ts":1}`, window: int64(0), shard: int64(0), count: int64(1)},
+	}, outs.get())
+
+	o.TriggerCloseNow()
+	require.NoError(t, o.WaitForClose(context.Background()))
+}
+
+func TestObjectBatcherProcessingTime(t *testing.T) {
+	outs := newObjectBatcherTestOutput()
+	o, err := outs.mgr.NewOutput(parseYAMLOutputConf(t, `
+object_batcher:
+  window: 50ms
+  output:
+    resource: objects
+`))
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tChan))
+
+	requireObjectBatcherAck(t, sendObjectBatcherMsgs(t, tChan, "foo", "bar"))
+	objects := outs.get()
+	require.Len(t, objects, 1)
+	assert.Equal(t, "foo\nbar", objects[0].content)
+
+	o.TriggerCloseNow()
+	require.NoError(t, o.WaitForClose(context.Background()))
+}
+
+func TestObjectBatcherConfigErrors(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		conf   string
+		errStr string
+	}{
+		{
+			name: "zero window",
+			conf: `
+object_batcher:
+  window: 0s
+  output:
+    drop: {}
+`,
+			errStr: "window must be greater than zero",
+		},
+		{
+			name: "missing cache",
+			conf: `
+object_batcher:
+  cache: nope
+  output:
+    drop: {}
+`,
+			errStr: "cache resource 'nope' was not found",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			conf := output.NewConfig()
+			require.NoError(t, yaml.Unmarshal([]byte(test.conf), &conf))
+
+			_, err := bmock.NewManager().NewOutput(conf)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.errStr)
+		})
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -43,7 +42,9 @@ func init() {
 		Summary: `
 The switch output type allows you to route messages to different outputs based on their contents.`,
 		Description: `
-Messages that do not pass the check of a single output case are effectively dropped. In order to prevent this outcome set the field ` + "[`strict_mode`](#strict_mode) to `true`" + `, in which case messages that do not pass at least one case are considered failed and will be nacked and/or reprocessed depending on your input.`,
+Messages that do not pass the check of a single output case are effectively dropped. In order to prevent this outcome set the field ` + "[`strict_mode`](#strict_mode) to `true`" + `, in which case messages that do not pass at least one case are considered failed and will be nacked and/or reprocessed depending on your input.
+
+The output of a case that has a ` + "`label`" + ` is identified by it within the ` + "`path`" + ` of its metrics, logs and traces (e.g. ` + "`root.output.switch.foo.output`" + `) in place of the index of the case.`,
 		Config: docs.FieldComponent().WithChildren(
 			docs.FieldBool(
 				"retry_until_success", `
@@ -221,7 +222,7 @@ func newSwitchOutput(conf output.SwitchConfig, mgr bundle.NewManagement) (output
 
 	var err error
 	for i, cConf := range conf.Cases {
-		oMgr := mgr.IntoPath("switch", strconv.Itoa(i), "output")
+		oMgr := mgr.IntoPath("switch", bundle.PathSegment(i, cConf.Output.Label), "output")
 		if o.outputs[i], err = oMgr.NewOutput(cConf.Output); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,422 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/interop"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	ttloFieldOutput        = "output"
+	ttloFieldExpiresAt     = "expires_at"
+	ttloFieldTimestamp     = "timestamp"
+	ttloFieldTTL           = "ttl"
+	ttloFieldExpiredOutput = "expired_output"
+)
+
+func ttlOutputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Writes messages to a child output unless they have expired, in which case they are either dropped or routed to a separate output.").
+		Description(`
+Messages that have been waiting too long to be delivered, for example whilst an output was unavailable or a backlog was being consumed, are often worthless by the time they arrive. This output checks the expiry of each message right before it is written to the child output, and prevents stale messages from being delivered.
+
+The expiry time of a message is determined either by the `+"`expires_at`"+` query, which should return a timestamp, or by adding the `+"`ttl`"+` duration to the timestamp returned by the `+"`timestamp`"+` query. When both are specified the `+"`expires_at`"+` query takes precedence, and the `+"`ttl`"+` is used as a default for messages where it returns `+"`null`"+`. The `+"`ttl`"+` field supports interpolation functions, which allows a TTL to be taken from the metadata of each message with a default for the whole pipeline, and messages where it resolves to an empty string never expire.
+
+Timestamps can be either timestamp values, numbers representing a unix timestamp in seconds, or strings in RFC 3339 format. Messages where the expiry cannot be determined due to an error are delivered and the error is logged.
+
+When an `+"`expired_output`"+` is specified expired messages are written to it instead, otherwise they are dropped and acknowledged. Messages of a batch are split between the two outputs when some of them have expired, and the batch is acknowledged once both have finished.
+
+### Metrics
+
+The number of messages that have expired is tracked by the counter `+"`output_expired`"+`.`).
+		Fields(
+			service.NewOutputField(ttloFieldOutput).
+				Description("The child output to write messages that have not expired to."),
+			service.NewBloblangField(ttloFieldExpiresAt).
+				Description("An optional [Bloblang query](/docs/guides/bloblang/about/) that should return the timestamp at which a message expires, or `null` in order to fall back to the `ttl`.").
+				Examples(`@expires_at`, `this.expiry_unix`).
+				Optional(),
+			service.NewBloblangField(ttloFieldTimestamp).
+				Description("A [Bloblang query](/docs/guides/bloblang/about/) that should return the timestamp at which a message was created, which the `ttl` is added to in order to determine its expiry. Required when a `ttl` is specified.").
+				Examples(`@kafka_timestamp_unix`, `this.created_at`).
+				Optional(),
+			service.NewInterpolatedStringField(ttloFieldTTL).
+				Description("The duration after the `timestamp` of a message at which it expires. Messages where this resolves to an empty string never expire.").
+				Examples("30s", `${! @ttl.or("5m") }`).
+				Optional(),
+			service.NewOutputField(ttloFieldExpiredOutput).
+				Description("An optional output to write expired messages to. When omitted expired messages are dropped.").
+				Optional(),
+		).
+		Example("Real-time Notifications", "Drop push notifications that are older than thirty seconds by the time they would be sent, unless they specify their own TTL within metadata.", `
+output:
+  ttl:
+    timestamp: '@kafka_timestamp_unix'
+    ttl: '${! @ttl.or("30s") }'
+    output:
+      http_client:
+        url: https://example.com/push
+        verb: POST
+`).
+		Example("Routing Expired Messages", "Write messages to a topic unless they have passed an expiry specified by the message itself, in which case they are written to a separate topic.", `
+output:
+  ttl:
+    expires_at: 'this.expires_at'
+    output:
+      kafka:
+        addresses: [ TODO ]
+        topic: notifications
+    expired_output:
+      kafka:
+        addresses: [ TODO ]
+        topic: notifications_expired
+`)
+}
+
+func init() {
+	err := service.RegisterBatchOutput(
+		"ttl", ttlOutputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, maxInFlight int, err error) {
+			var t *ttlOutput
+			if t, err = newTTLOutputFromParsed(conf, mgr); err != nil {
+				return
+			}
+			out = interop.NewUnwrapInternalOutput(t)
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type ttlOutput struct {
+	log      log.Modular
+	mExpired metrics.StatCounter
+
+	expiresAt *mapping.Executor
+	timestamp *mapping.Executor
+	ttl       *field.Expression
+
+	child             output.Streamed
+	childTrans        chan message.Transaction
+	expiredChild      output.Streamed
+	expiredChildTrans chan message.Transaction
+
+	transactionsIn <-chan message.Transaction
+
+	shutSig *shutdown.Signaller
+}
+
+func ttlChildOutput(conf *service.ParsedConfig, nm bundle.NewManagement, name string) (output.Streamed, error) {
+	childAny, err := conf.FieldAny(name)
+	if err != nil {
+		return nil, err
+	}
+	childNode, ok := childAny.(*yaml.Node)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value, expected object, got %T", childAny)
+	}
+	childConf := output.NewConfig()
+	if err := childNode.Decode(&childConf); err != nil {
+		return nil, err
+	}
+	return nm.IntoPath("ttl", name).NewOutput(childConf)
+}
+
+func newTTLOutputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*ttlOutput, error) {
+	nm := interop.UnwrapManagement(mgr)
+
+	t := &ttlOutput{
+		log:      nm.Logger(),
+		mExpired: nm.Metrics().GetCounter("output_expired"),
+		shutSig:  shutdown.NewSignaller(),
+	}
+
+	for _, q := range []struct {
+		name string
+		exec **mapping.Executor
+	}{
+		{name: ttloFieldExpiresAt, exec: &t.expiresAt},
+		{name: ttloFieldTimestamp, exec: &t.timestamp},
+	} {
+		if !conf.Contains(q.name) {
+			continue
+		}
+		qStr, err := conf.FieldString(q.name)
+		if err != nil {
+			return nil, err
+		}
+		if *q.exec, err = nm.BloblEnvironment().NewMapping(qStr); err != nil {
+			return nil, fmt.Errorf("failed to parse %v query: %w", q.name, err)
+		}
+	}
+
+	if conf.Contains(ttloFieldTTL) {
+		ttlStr, err := conf.FieldString(ttloFieldTTL)
+		if err != nil {
+			return nil, err
+		}
+		if t.ttl, err = nm.BloblEnvironment().NewField(ttlStr); err != nil {
+			return nil, fmt.Errorf("failed to parse ttl expression: %w", err)
+		}
+		if t.timestamp == nil {
+			return nil, errors.New("a timestamp query is required when a ttl is specified")
+		}
+	}
+	if t.expiresAt == nil && t.ttl == nil {
+		return nil, errors.New("either an expires_at query or a ttl is required")
+	}
+
+	var err error
+	if t.child, err = ttlChildOutput(conf, nm, ttloFieldOutput); err != nil {
+		return nil, err
+	}
+	if conf.Contains(ttloFieldExpiredOutput) {
+		if t.expiredChild, err = ttlChildOutput(conf, nm, ttloFieldExpiredOutput); err != nil {
+			t.child.TriggerCloseNow()
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// queryTimestamp executes a query against a message and returns the resulting
+// timestamp, or nil if the query resulted in null.
+func queryTimestamp(exec *mapping.Executor, index int, batch message.Batch) (*time.Time, error) {
+	p, err := exec.MapPart(index, batch)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, nil
+	}
+	// String results are stored as raw bytes and are therefore not guaranteed
+	// to be valid JSON.
+	v, err := p.AsStructured()
+	if err != nil {
+		v = p.AsBytes()
+	}
+	if v == nil {
+		return nil, nil
+	}
+	ts, err := query.IGetTimestamp(v)
+	if err != nil {
+		return nil, err
+	}
+	return &ts, nil
+}
+
+// expiry returns the time at which a message expires, or nil if it does not
+// expire.
+func (t *ttlOutput) expiry(index int, batch message.Batch) (*time.Time, error) {
+	if t.expiresAt != nil {
+		ts, err := queryTimestamp(t.expiresAt, index, batch)
+		if err != nil {
+			return nil, fmt.Errorf("expires_at query: %w", err)
+		}
+		if ts != nil || t.ttl == nil {
+			return ts, nil
+		}
+	}
+
+	ttlStr, err := t.ttl.String(index, batch)
+	if err != nil {
+		return nil, fmt.Errorf("ttl interpolation: %w", err)
+	}
+	if ttlStr == "" {
+		return nil, nil
+	}
+	ttl, err := time.ParseDuration(ttlStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ttl: %w", err)
+	}
+
+	ts, err := queryTimestamp(t.timestamp, index, batch)
+	if err != nil {
+		return nil, fmt.Errorf("timestamp query: %w", err)
+	}
+	if ts == nil {
+		return nil, nil
+	}
+	expires := ts.Add(ttl)
+	return &expires, nil
+}
+
+// ttlAckGroup returns an acknowledgement func to be called n times, which
+// acknowledges the parent transaction once all calls have been made with the
+// first error encountered, if any.
+func ttlAckGroup(n int, tran message.Transaction) func(context.Context, error) error {
+	var mut sync.Mutex
+	var firstErr error
+	return func(ctx context.Context, err error) error {
+		mut.Lock()
+		n--
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		remaining, ackErr := n, firstErr
+		mut.Unlock()
+
+		if remaining == 0 {
+			return tran.Ack(ctx, ackErr)
+		}
+		return nil
+	}
+}
+
+func (t *ttlOutput) loop() {
+	ctx, done := t.shutSig.CloseNowCtx(context.Background())
+	defer done()
+
+	defer func() {
+		close(t.childTrans)
+		if t.expiredChildTrans != nil {
+			close(t.expiredChildTrans)
+		}
+		if t.shutSig.ShouldCloseNow() {
+			t.child.TriggerCloseNow()
+			if t.expiredChild != nil {
+				t.expiredChild.TriggerCloseNow()
+			}
+		}
+		_ = t.child.WaitForClose(context.Background())
+		if t.expiredChild != nil {
+			_ = t.expiredChild.WaitForClose(context.Background())
+		}
+		t.shutSig.ShutdownComplete()
+	}()
+
+	send := func(c chan<- message.Transaction, tran message.Transaction) bool {
+		select {
+		case c <- tran:
+			return true
+		case <-t.shutSig.CloseNowChan():
+			return false
+		}
+	}
+
+	for {
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-t.transactionsIn:
+			if !open {
+				return
+			}
+		case <-t.shutSig.CloseNowChan():
+			return
+		}
+
+		now := time.Now()
+		var fresh, expired message.Batch
+		_ = tran.Payload.Iter(func(i int, p *message.Part) error {
+			expires, err := t.expiry(i, tran.Payload)
+			if err != nil {
+				t.log.Errorf("Failed to determine message expiry: %v\n", err)
+			}
+			if expires != nil && !now.Before(*expires) {
+				expired = append(expired, p)
+			} else {
+				fresh = append(fresh, p)
+			}
+			return nil
+		})
+
+		if len(expired) == 0 {
+			if !send(t.childTrans, tran) {
+				return
+			}
+			continue
+		}
+		t.mExpired.Incr(int64(len(expired)))
+
+		if t.expiredChild == nil {
+			if len(fresh) == 0 {
+				_ = tran.Ack(ctx, nil)
+				continue
+			}
+			if !send(t.childTrans, message.NewTransactionFunc(fresh, tran.Ack)) {
+				return
+			}
+			continue
+		}
+
+		if len(fresh) == 0 {
+			if !send(t.expiredChildTrans, tran) {
+				return
+			}
+			continue
+		}
+
+		ackFn := ttlAckGroup(2, tran)
+		if !send(t.childTrans, message.NewTransactionFunc(fresh, ackFn)) {
+			return
+		}
+		if !send(t.expiredChildTrans, message.NewTransactionFunc(expired, ackFn)) {
+			return
+		}
+	}
+}
+
+func (t *ttlOutput) Consume(ts <-chan message.Transaction) error {
+	if t.transactionsIn != nil {
+		return component.ErrAlreadyStarted
+	}
+
+	t.childTrans = make(chan message.Transaction)
+	if err := t.child.Consume(t.childTrans); err != nil {
+		return err
+	}
+	if t.expiredChild != nil {
+		t.expiredChildTrans = make(chan message.Transaction)
+		if err := t.expiredChild.Consume(t.expiredChildTrans); err != nil {
+			return err
+		}
+	}
+
+	t.transactionsIn = ts
+	go t.loop()
+	return nil
+}
+
+func (t *ttlOutput) Connected() bool {
+	if t.expiredChild != nil && !t.expiredChild.Connected() {
+		return false
+	}
+	return t.child.Connected()
+}
+
+func (t *ttlOutput) TriggerCloseNow() {
+	t.shutSig.CloseNow()
+}
+
+func (t *ttlOutput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-t.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package pure_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	bmock "github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+type ttlTestOutputs struct {
+	mut     sync.Mutex
+	written map[string][]string
+	mgr     *bmock.Manager
+}
+
+func newTTLTestOutputs(names ...string) *ttlTestOutputs {
+	o := &ttlTestOutputs{
+		written: map[string][]string{},
+		mgr:     bmock.NewManager(),
+	}
+	for _, name := range names {
+		name := name
+		o.mgr.Outputs[name] = func(ctx context.Context, tran message.Transaction) error {
+			o.mut.Lock()
+			_ = tran.Payload.Iter(func(i int, p *message.Part) error {
+				o.written[name] = append(o.written[name], string(p.AsBytes()))
+				return nil
+			})
+			o.mut.Unlock()
+			return tran.Ack(ctx, nil)
+		}
+	}
+	return o
+}
+
+func (o *ttlTestOutputs) get(name string) []string {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	return o.written[name]
+}
+
+func sendTTLBatch(t testing.TB, tChan chan<- message.Transaction, batch message.Batch) error {
+	t.Helper()
+
+	resChan := make(chan error, 1)
+	select {
+	case tChan <- message.NewTransaction(batch, resChan):
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	select {
+	case err := <-resChan:
+		return err
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	return nil
+}
+
+func ttlTestPart(content string, ts time.Time, ttl string) *message.Part {
+	p := message.NewPart([]byte(content))
+	p.MetaSetMut("ts", strconv.FormatInt(ts.Unix(), 10))
+	if ttl != "" {
+		p.MetaSetMut("ttl", ttl)
+	}
+	return p
+}
+
+func TestTTLOutputConfigErrors(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		conf   string
+		errStr string
+	}{
+		{
+			name: "no expiry",
+			conf: `
+ttl:
+  output:
+    drop: {}
+`,
+			errStr: "either an expires_at query or a ttl is required",
+		},
+		{
+			name: "ttl without timestamp",
+			conf: `
+ttl:
+  ttl: 10s
+  output:
+    drop: {}
+`,
+			errStr: "a timestamp query is required when a ttl is specified",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			conf := output.NewConfig()
+			require.NoError(t, yaml.Unmarshal([]byte(test.conf), &conf))
+
+			_, err := bmock.NewManager().NewOutput(conf)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.errStr)
+		})
+	}
+}
+
+func TestTTLOutputDrop(t *testing.T) {
+	outs := newTTLTestOutputs("fresh")
+	o, err := outs.mgr.NewOutput(parseYAMLOutputConf(t, `
+ttl:
+  timestamp: '@ts.number()'
+  ttl: '${! @ttl.or("1m") }'
+  output:
+    resource: fresh
+`))
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tChan))
+
+	now := time.Now()
+	require.NoError(t, sendTTLBatch(t, tChan, message.Batch{
+		ttlTestPart("a", now, ""),
+		ttlTestPart("b", now.Add(-time.Hour), ""),
+		ttlTestPart("c", now.Add(-time.Hour), "2h"),
+		ttlTestPart("d", now.Add(-time.Hour), "1s"),
+	}))
+	require.NoError(t, sendTTLBatch(t, tChan, message.Batch{
+		ttlTestPart("e", now.Add(-time.Hour), ""),
+	}))
+	require.NoError(t, sendTTLBatch(t, tChan, message.Batch{
+		ttlTestPart("f", now, "3s"),
+	}))
+
+	assert.Equal(t, []string{"a", "c", "f"}, outs.get("fresh"))
+
+	o.TriggerCloseNow()
+	require.NoError(t, o.WaitForClose(context.Background()))
+}
+
+func TestTTLOutputExpiredOutput(t *testing.T) {
+	outs := newTTLTestOutputs("fresh", "expired")
+	o, err := outs.mgr.NewOutput(parseYAMLOutputConf(t, `
+ttl:
+  expires_at: 'this.expires_at'
+  output:
+    resource: fresh
+  expired_output:
+    resource: expired
+`))
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tChan))
+
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	require.NoError(t, sendTTLBatch(t, tChan, message.QuickBatch([][]byte{
+		[]byte(`{"id":"a","expires_at":"` + future + `"}`),
+		[]byte(`{"id":"b","expires_at":"` + past + `"}`),
+		[]byte(`{"id":"c","expires_at":null}`),
+	})))
+	require.NoError(t, sendTTLBatch(t, tChan, message.QuickBatch([][]byte{
+		[]byte(`{"id":"d","expires_at":"` + past + `"}`),
+	})))
+
+	assert.Equal(t, []string{
+		`{"id":"a","expires_at":"` + future + `"}`,
+		`{"id":"c","expires_at":null}`,
+	}, outs.get("fresh"))
+	assert.Equal(t, []string{
+		`{"id":"b","expires_at":"` + past + `"}`,
+		`{"id":"d","expires_at":"` + past + `"}`,
+	}, outs.get("expired"))
+
+	close(tChan)
+	require.NoError(t, o.WaitForClose(context.Background()))
+}
@@ -0,0 +1,396 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/interop"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	wuFieldOutput      = "output"
+	wuFieldCheck       = "check"
+	wuFieldIdleTimeout = "idle_timeout"
+	wuFieldMaxMessages = "max_messages"
+	wuFieldMaxBytes    = "max_bytes"
+	wuFieldRollOver    = "roll_over"
+)
+
+func writeUntilOutputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Writes messages to a child output until a written message passes a [Bloblang query](/docs/guides/bloblang/about/), or a limit is reached, at which point the child output is closed and either this output closes or the child output is rolled over.").
+		Description(`
+This output is the counterpart of the `+"[`read_until` input](/docs/components/inputs/read_until)"+`, and is useful for bounded batch jobs where the stream should end once a certain amount of data has been written, or for rolling over outputs such as files once they reach a certain size.
+
+The child output is closed once a message passes the `+"`check`"+` query, once a total number of messages or bytes have been written with `+"`max_messages` and `max_bytes`"+`, or once no messages have been written for the duration of `+"`idle_timeout`"+`. At least one of these conditions must be specified, and when multiple are specified the child output is closed as soon as any of them is met. The message that meets a condition is the last to be written to the child output, and it is only acknowledged once the child output has closed successfully.
+
+When `+"`roll_over`"+` is `+"`false`"+` this output closes along with the child output, which ends the stream. When `+"`roll_over`"+` is `+"`true`"+` a new child output is created for the next message and the conditions are reset, where an idle timeout only rolls over the child output when messages have been written to it.
+
+Message counts include messages that are reattempted after failing to be delivered.
+
+### Metadata
+
+This output adds the following metadata fields to each message written to the child output:
+
+`+"```text"+`
+- benthos_write_until_opened
+`+"```"+`
+
+The field contains the unix timestamp in nanoseconds at which the current child output was opened, and can be used within interpolations of the child output in order to write to a new destination each time it is rolled over.`).
+		Fields(
+			service.NewOutputField(wuFieldOutput).
+				Description("The child output to write to."),
+			service.NewBloblangField(wuFieldCheck).
+				Description("A [Bloblang query](/docs/guides/bloblang/about/) that should return a boolean value indicating whether the child output should now be closed, which is executed for each message of a batch.").
+				Examples(`this.type == "end_of_file"`, `count("messages") >= 100`).
+				Optional(),
+			service.NewDurationField(wuFieldIdleTimeout).
+				Description("An optional duration after which the child output is closed when no messages have been written.").
+				Examples("10s", "1m").
+				Optional(),
+			service.NewIntField(wuFieldMaxMessages).
+				Description("An optional maximum number of messages to write before closing the child output, where `0` means no limit.").
+				Default(0),
+			service.NewIntField(wuFieldMaxBytes).
+				Description("An optional maximum number of bytes of message payloads to write before closing the child output, where `0` means no limit.").
+				Default(0),
+			service.NewBoolField(wuFieldRollOver).
+				Description("Whether to create a new child output once the current one is closed rather than closing this output.").
+				Default(false),
+		).
+		Example("Rolling Files", "Write messages to files containing at most ten thousand lines each, where each file is named by the timestamp at which it was opened.", `
+output:
+  write_until:
+    max_messages: 10000
+    roll_over: true
+    output:
+      file:
+        path: ./data/${! @benthos_write_until_opened }.jsonl
+        codec: lines
+`).
+		Example("Bounded Batch Job", "Write a million messages and then end the stream.", `
+output:
+  write_until:
+    max_messages: 1000000
+    output:
+      aws_s3:
+        bucket: TODO
+        path: ${! counter() }.json
+`)
+}
+
+func init() {
+	err := service.RegisterBatchOutput(
+		"write_until", writeUntilOutputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, maxInFlight int, err error) {
+			var w *writeUntilOutput
+			if w, err = newWriteUntilOutputFromParsed(conf, mgr); err != nil {
+				return
+			}
+			out = interop.NewUnwrapInternalOutput(w)
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type writeUntilOutput struct {
+	childConf output.Config
+	childMgr  bundle.NewManagement
+	log       log.Modular
+
+	check       *mapping.Executor
+	idleTimeout time.Duration
+	maxMessages int
+	maxBytes    int
+	rollOver    bool
+
+	child          output.Streamed
+	childOpened    string
+	childLocked    atomic.Pointer[output.Streamed]
+	childTrans     chan message.Transaction
+	transactionsIn <-chan message.Transaction
+
+	shutSig *shutdown.Signaller
+}
+
+func newWriteUntilOutputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*writeUntilOutput, error) {
+	nm := interop.UnwrapManagement(mgr)
+
+	childAny, err := conf.FieldAny(wuFieldOutput)
+	if err != nil {
+		return nil, err
+	}
+	childNode, ok := childAny.(*yaml.Node)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value, expected object, got %T", childAny)
+	}
+
+	w := &writeUntilOutput{
+		childConf: output.NewConfig(),
+		childMgr:  nm.IntoPath("write_until", wuFieldOutput),
+		log:       nm.Logger(),
+		shutSig:   shutdown.NewSignaller(),
+	}
+	if err := childNode.Decode(&w.childConf); err != nil {
+		return nil, err
+	}
+
+	if conf.Contains(wuFieldCheck) {
+		checkStr, err := conf.FieldString(wuFieldCheck)
+		if err != nil {
+			return nil, err
+		}
+		if w.check, err = nm.BloblEnvironment().NewMapping(checkStr); err != nil {
+			return nil, fmt.Errorf("failed to parse check query: %w", err)
+		}
+	}
+	if conf.Contains(wuFieldIdleTimeout) {
+		if w.idleTimeout, err = conf.FieldDuration(wuFieldIdleTimeout); err != nil {
+			return nil, err
+		}
+	}
+	if w.maxMessages, err = conf.FieldInt(wuFieldMaxMessages); err != nil {
+		return nil, err
+	}
+	if w.maxBytes, err = conf.FieldInt(wuFieldMaxBytes); err != nil {
+		return nil, err
+	}
+	if w.rollOver, err = conf.FieldBool(wuFieldRollOver); err != nil {
+		return nil, err
+	}
+
+	if w.check == nil && w.idleTimeout <= 0 && w.maxMessages <= 0 && w.maxBytes <= 0 {
+		return nil, errors.New("a check query, idle_timeout, max_messages or max_bytes is required")
+	}
+
+	// Create the first child output upfront so that configuration errors are
+	// surfaced during construction.
+	if err := w.openChild(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *writeUntilOutput) openChild() error {
+	child, err := w.childMgr.NewOutput(w.childConf)
+	if err != nil {
+		return err
+	}
+	childTrans := make(chan message.Transaction)
+	if err := child.Consume(childTrans); err != nil {
+		child.TriggerCloseNow()
+		return err
+	}
+	w.child, w.childTrans = child, childTrans
+	w.childOpened = strconv.FormatInt(time.Now().UnixNano(), 10)
+	w.childLocked.Store(&child)
+	return nil
+}
+
+// closeChild gracefully closes the child output once all pending messages have
+// been delivered.
+func (w *writeUntilOutput) closeChild(ctx context.Context) error {
+	if w.child == nil {
+		return nil
+	}
+	close(w.childTrans)
+	err := w.child.WaitForClose(ctx)
+	if err != nil {
+		w.child.TriggerCloseNow()
+	}
+	w.child, w.childTrans = nil, nil
+	w.childLocked.Store(nil)
+	return err
+}
+
+// conditionMet updates counters with a batch being written and returns true if
+// any condition for closing the child output has been met.
+func (w *writeUntilOutput) conditionMet(batch message.Batch, written, writtenBytes *int) bool {
+	*written += batch.Len()
+	_ = batch.Iter(func(i int, p *message.Part) error {
+		*writtenBytes += len(p.AsBytes())
+		return nil
+	})
+	if (w.maxMessages > 0 && *written >= w.maxMessages) || (w.maxBytes > 0 && *writtenBytes >= w.maxBytes) {
+		return true
+	}
+	if w.check == nil {
+		return false
+	}
+	for i := 0; i < batch.Len(); i++ {
+		met, err := w.check.QueryPart(i, batch)
+		if err != nil {
+			w.log.Errorf("Failed to execute check query: %v\n", err)
+			continue
+		}
+		if met {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *writeUntilOutput) loop() {
+	ctx, done := w.shutSig.CloseNowCtx(context.Background())
+	defer done()
+
+	defer func() {
+		if w.child != nil {
+			if w.shutSig.ShouldCloseNow() {
+				w.child.TriggerCloseNow()
+			}
+			_ = w.closeChild(ctx)
+		}
+		w.shutSig.ShutdownComplete()
+	}()
+
+	var idleTimer *time.Timer
+	var idleChan <-chan time.Time
+	if w.idleTimeout > 0 {
+		idleTimer = time.NewTimer(w.idleTimeout)
+		defer idleTimer.Stop()
+		idleChan = idleTimer.C
+	}
+
+	var written, writtenBytes int
+	for {
+		if idleTimer != nil {
+			if !idleTimer.Stop() {
+				select {
+				case <-idleTimer.C:
+				default:
+				}
+			}
+			idleTimer.Reset(w.idleTimeout)
+		}
+
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-w.transactionsIn:
+			if !open {
+				return
+			}
+		case <-idleChan:
+			if !w.rollOver {
+				w.log.Infof("No messages written within idle timeout, closing output\n")
+				return
+			}
+			if written > 0 {
+				w.log.Debugf("No messages written within idle timeout, rolling over output\n")
+				if err := w.closeChild(ctx); err != nil {
+					w.log.Errorf("Failed to close output: %v\n", err)
+				}
+				written, writtenBytes = 0, 0
+			}
+			continue
+		case <-w.shutSig.CloseNowChan():
+			return
+		}
+
+		if w.child == nil {
+			if err := w.openChild(); err != nil {
+				w.log.Errorf("Failed to create output '%v': %v\n", w.childConf.Type, err)
+				_ = tran.Ack(ctx, err)
+				continue
+			}
+		}
+
+		batch := tran.Payload.ShallowCopy()
+		_ = batch.Iter(func(i int, p *message.Part) error {
+			p.MetaSetMut("benthos_write_until_opened", w.childOpened)
+			return nil
+		})
+
+		if !w.conditionMet(batch, &written, &writtenBytes) {
+			select {
+			case w.childTrans <- message.NewTransactionFunc(batch, tran.Ack):
+			case <-w.shutSig.CloseNowChan():
+				return
+			}
+			continue
+		}
+
+		// Deliver the final message and wait for it to be written before
+		// closing the child output.
+		resChan := make(chan error, 1)
+		select {
+		case w.childTrans <- message.NewTransaction(batch, resChan):
+		case <-w.shutSig.CloseNowChan():
+			return
+		}
+
+		var res error
+		select {
+		case res = <-resChan:
+		case <-w.shutSig.CloseNowChan():
+			return
+		}
+		if res == nil {
+			res = w.closeChild(ctx)
+		}
+		_ = tran.Ack(ctx, res)
+		if res != nil {
+			continue
+		}
+
+		if !w.rollOver {
+			w.log.Infof("Output condition met, closing output\n")
+			return
+		}
+		written, writtenBytes = 0, 0
+	}
+}
+
+func (w *writeUntilOutput) Consume(ts <-chan message.Transaction) error {
+	if w.transactionsIn != nil {
+		return component.ErrAlreadyStarted
+	}
+	w.transactionsIn = ts
+	go w.loop()
+	return nil
+}
+
+func (w *writeUntilOutput) Connected() bool {
+	// Between roll overs there is no child output, which we consider to be
+	// connected.
+	childP := w.childLocked.Load()
+	if childP == nil {
+		return true
+	}
+	return (*childP).Connected()
+}
+
+func (w *writeUntilOutput) TriggerCloseNow() {
+	w.shutSig.CloseNow()
+}
+
+func (w *writeUntilOutput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-w.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
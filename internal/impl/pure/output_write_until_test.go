@@ -0,0 +1,147 @@
+package pure_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	bmock "github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func runWriteUntilStream(t testing.TB, inputConf, outputConf string) {
+	t.Helper()
+
+	builder := service.NewStreamBuilder()
+	require.NoError(t, builder.AddInputYAML(inputConf))
+	require.NoError(t, builder.AddOutputYAML(outputConf))
+
+	strm, err := builder.Build()
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+	require.NoError(t, strm.Run(ctx))
+}
+
+func readWriteUntilFiles(t testing.TB, dir string) []string {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	require.NoError(t, err)
+	sort.Strings(paths)
+
+	var contents []string
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		require.NoError(t, err)
+		contents = append(contents, string(b))
+	}
+	return contents
+}
+
+func TestWriteUntilConfigErrors(t *testing.T) {
+	conf := output.NewConfig()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+write_until:
+  output:
+    drop: {}
+`), &conf))
+
+	_, err := bmock.NewManager().NewOutput(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "a check query, idle_timeout, max_messages or max_bytes is required")
+}
+
+func TestWriteUntilMaxMessages(t *testing.T) {
+	dir := t.TempDir()
+
+	runWriteUntilStream(t, `
+generate:
+  count: 10
+  interval: ""
+  mapping: 'root = "hello"'
+`, fmt.Sprintf(`
+write_until:
+  max_messages: 3
+  output:
+    file:
+      path: %v
+      codec: lines
+`, filepath.Join(dir, "out.txt")))
+
+	assert.Equal(t, []string{"hello\nhello\nhello\n"}, readWriteUntilFiles(t, dir))
+}
+
+func TestWriteUntilCheck(t *testing.T) {
+	dir := t.TempDir()
+
+	runWriteUntilStream(t, `
+generate:
+  count: 10
+  interval: ""
+  mapping: 'root = counter().string()'
+`, fmt.Sprintf(`
+write_until:
+  check: 'content() == "4"'
+  output:
+    file:
+      path: %v
+      codec: lines
+`, filepath.Join(dir, "out.txt")))
+
+	assert.Equal(t, []string{"1\n2\n3\n4\n"}, readWriteUntilFiles(t, dir))
+}
+
+func TestWriteUntilRollOver(t *testing.T) {
+	dir := t.TempDir()
+
+	runWriteUntilStream(t, `
+generate:
+  count: 7
+  interval: ""
+  mapping: 'root = counter().string()'
+`, fmt.Sprintf(`
+write_until:
+  max_messages: 3
+  roll_over: true
+  output:
+    file:
+      path: '%v/${! @benthos_write_until_opened }.txt'
+      codec: lines
+`, dir))
+
+	assert.Equal(t, []string{"1\n2\n3\n", "4\n5\n6\n", "7\n"}, readWriteUntilFiles(t, dir))
+}
+
+func TestWriteUntilIdleTimeout(t *testing.T) {
+	dir := t.TempDir()
+
+	runWriteUntilStream(t, `
+generate:
+  interval: 1ms
+  mapping: |
+    root = counter().string()
+    root = if counter() > 5 { deleted() }
+`, fmt.Sprintf(`
+write_until:
+  idle_timeout: 50ms
+  output:
+    file:
+      path: %v
+      codec: lines
+`, filepath.Join(dir, "out.txt")))
+
+	contents := readWriteUntilFiles(t, dir)
+	require.Len(t, contents, 1)
+	assert.Equal(t, "1\n2\n3\n4\n5\n", contents[0])
+}
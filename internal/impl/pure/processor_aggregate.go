@@ -0,0 +1,325 @@
+package pure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/tracing"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	agpFieldGroupBy         = "group_by"
+	agpFieldAggregates      = "aggregates"
+	agpFieldAggregatesName  = "name"
+	agpFieldAggregatesType  = "type"
+	agpFieldAggregatesValue = "value"
+)
+
+func aggregateProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Reduces the messages of a batch into compact records of counts, sums, minimums, maximums and means, one for each group of messages sharing the same dimensions.").
+		Description(`
+Pre-aggregating metric-like events such as request logs can drastically reduce the volume of data sent to expensive sinks. Each message of a batch is assigned to a group by the object resulting from the `+"`group_by`"+` mapping, and a single record is emitted for each group containing the fields of that object along with a field for each of the `+"`aggregates`"+`.
+
+Records are emitted in the order in which their groups first appear within the batch, and adopt the metadata of the first message of their group. Messages that fail either the `+"`group_by`"+` mapping or the `+"`value`"+` mapping of an aggregate are not aggregated, and are instead emitted unchanged and flagged with the error, where they can be handled with [error handling patterns](/docs/configuration/error_handling).
+
+### Windowing
+
+The functionality of this processor depends on being applied across messages that are batched, and therefore the window of time over which messages are aggregated is that of the batch. Batches covering a fixed period can be created with a [`+"`batching`"+` policy](/docs/configuration/batching) with a `+"`period`"+`, or aligned to the wall clock with the [`+"`system_window`"+` buffer](/docs/components/buffers/system_window), which adds the end of each window to messages as the metadata field `+"`window_end_timestamp`"+`.
+
+### Delivery Guarantees
+
+The messages of a batch are acknowledged once the records aggregated from them are delivered, and therefore the at-least-once delivery guarantees of the pipeline are preserved. However, the larger the windows the more data needs to be reprocessed in the event of an outage.`).
+		Fields(
+			service.NewBloblangField(agpFieldGroupBy).
+				Description("An optional [Bloblang mapping](/docs/guides/bloblang/about) that results in an object of dimensions to group each message by. When omitted all messages of a batch are aggregated into a single record.").
+				Example(`root.service = this.service
+root.status = this.status_code`).
+				Optional(),
+			service.NewObjectListField(agpFieldAggregates,
+				service.NewStringField(agpFieldAggregatesName).
+					Description("The name of the field to store the aggregated value in."),
+				service.NewStringAnnotatedEnumField(agpFieldAggregatesType, map[string]string{
+					"count": "The number of messages within the group.",
+					"sum":   "The sum of the values of the group.",
+					"min":   "The minimum value of the group.",
+					"max":   "The maximum value of the group.",
+					"mean":  "The mean average of the values of the group.",
+				}).
+					Description("The type of aggregation to perform."),
+				service.NewBloblangField(agpFieldAggregatesValue).
+					Description("A [Bloblang mapping](/docs/guides/bloblang/about) that results in the number to aggregate for each message, which is required by all types other than `count`.").
+					Example(`root = this.duration_ms`).
+					Optional(),
+			).
+				Description("A list of aggregates to calculate for each group."),
+		).
+		Example(
+			"Request Metrics",
+			"Here we reduce a stream of HTTP request logs into a record per minute for each service and status code, containing the number of requests and their latencies.",
+			`
+input:
+  kafka:
+    addresses: [ localhost:9092 ]
+    topics: [ request_logs ]
+    consumer_group: aggregator
+
+buffer:
+  system_window:
+    timestamp_mapping: root = this.timestamp.ts_parse("2006-01-02T15:04:05Z07:00")
+    size: 1m
+
+pipeline:
+  processors:
+    - aggregate:
+        group_by: |
+          root.service = this.service
+          root.status = this.status_code
+        aggregates:
+          - name: requests
+            type: count
+          - name: total_latency_ms
+            type: sum
+            value: root = this.latency_ms
+          - name: max_latency_ms
+            type: max
+            value: root = this.latency_ms
+    - mutation: |
+        root.window_end = @window_end_timestamp
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchProcessor(
+		"aggregate", aggregateProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchProcessor, error) {
+			return newAggregateProcessorFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type aggregateField struct {
+	name  string
+	typ   string
+	value *bloblang.Executor
+}
+
+type aggregateProcessor struct {
+	groupBy    *bloblang.Executor
+	aggregates []aggregateField
+	tracer     trace.TracerProvider
+}
+
+func newAggregateProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*aggregateProcessor, error) {
+	p := &aggregateProcessor{tracer: mgr.OtelTracer()}
+
+	if conf.Contains(agpFieldGroupBy) {
+		var err error
+		if p.groupBy, err = conf.FieldBloblang(agpFieldGroupBy); err != nil {
+			return nil, err
+		}
+	}
+
+	aggConfs, err := conf.FieldObjectList(agpFieldAggregates)
+	if err != nil {
+		return nil, err
+	}
+	if len(aggConfs) == 0 {
+		return nil, errors.New("at least one aggregate must be specified")
+	}
+	for i, aggConf := range aggConfs {
+		var f aggregateField
+		if f.name, err = aggConf.FieldString(agpFieldAggregatesName); err != nil {
+			return nil, err
+		}
+		if f.name == "" {
+			return nil, fmt.Errorf("aggregate %v: name must not be empty", i)
+		}
+		if f.typ, err = aggConf.FieldString(agpFieldAggregatesType); err != nil {
+			return nil, err
+		}
+		if aggConf.Contains(agpFieldAggregatesValue) {
+			if f.value, err = aggConf.FieldBloblang(agpFieldAggregatesValue); err != nil {
+				return nil, err
+			}
+		}
+		if f.typ != "count" && f.value == nil {
+			return nil, fmt.Errorf("aggregate %v: a value mapping is required for the type %v", f.name, f.typ)
+		}
+		p.aggregates = append(p.aggregates, f)
+	}
+	return p, nil
+}
+
+// aggregateState tracks the running aggregates of a single group.
+type aggregateState struct {
+	first      *service.Message
+	sources    []context.Context
+	dimensions map[string]any
+	count      int64
+	sums       []float64
+	mins       []float64
+	maxs       []float64
+}
+
+func (p *aggregateProcessor) newState(first *service.Message, dimensions map[string]any) *aggregateState {
+	s := &aggregateState{
+		first:      first,
+		dimensions: dimensions,
+		sums:       make([]float64, len(p.aggregates)),
+		mins:       make([]float64, len(p.aggregates)),
+		maxs:       make([]float64, len(p.aggregates)),
+	}
+	for i := range p.aggregates {
+		s.mins[i] = math.Inf(1)
+		s.maxs[i] = math.Inf(-1)
+	}
+	return s
+}
+
+func (s *aggregateState) add(msg *service.Message, values []float64) {
+	s.count++
+	s.sources = append(s.sources, msg.Context())
+	for i, v := range values {
+		s.sums[i] += v
+		s.mins[i] = math.Min(s.mins[i], v)
+		s.maxs[i] = math.Max(s.maxs[i], v)
+	}
+}
+
+func (p *aggregateProcessor) record(s *aggregateState) *service.Message {
+	obj := make(map[string]any, len(s.dimensions)+len(p.aggregates))
+	for k, v := range s.dimensions {
+		obj[k] = v
+	}
+	for i, f := range p.aggregates {
+		switch f.typ {
+		case "count":
+			obj[f.name] = s.count
+		case "sum":
+			obj[f.name] = s.sums[i]
+		case "min":
+			obj[f.name] = s.mins[i]
+		case "max":
+			obj[f.name] = s.maxs[i]
+		case "mean":
+			obj[f.name] = s.sums[i] / float64(s.count)
+		}
+	}
+
+	msg := s.first.Copy()
+	msg.SetStructuredMut(obj)
+	return msg.WithContext(tracing.WithJoinedSpan(p.tracer, "aggregate_join", s.sources...))
+}
+
+func (p *aggregateProcessor) dimensions(batch service.MessageBatch, i int) (map[string]any, error) {
+	if p.groupBy == nil {
+		return map[string]any{}, nil
+	}
+	res, err := batch.BloblangQuery(i, p.groupBy)
+	if err != nil {
+		return nil, fmt.Errorf("group_by mapping failed: %w", err)
+	}
+	if res == nil {
+		return nil, errors.New("group_by mapping deleted the message")
+	}
+	v, err := res.AsStructured()
+	if err != nil {
+		return nil, fmt.Errorf("group_by mapping failed: %w", err)
+	}
+	dims, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("group_by mapping resulted in a non-object value: %T", v)
+	}
+	return dims, nil
+}
+
+func (p *aggregateProcessor) values(batch service.MessageBatch, i int) ([]float64, error) {
+	values := make([]float64, len(p.aggregates))
+	for j, f := range p.aggregates {
+		if f.value == nil {
+			continue
+		}
+		res, err := batch.BloblangQuery(i, f.value)
+		if err != nil {
+			return nil, fmt.Errorf("aggregate %v: value mapping failed: %w", f.name, err)
+		}
+		if res == nil {
+			return nil, fmt.Errorf("aggregate %v: value mapping deleted the message", f.name)
+		}
+		v, err := res.AsStructured()
+		if err != nil {
+			return nil, fmt.Errorf("aggregate %v: value mapping failed: %w", f.name, err)
+		}
+		if values[j], err = query.IGetNumber(v); err != nil {
+			return nil, fmt.Errorf("aggregate %v: %w", f.name, err)
+		}
+	}
+	return values, nil
+}
+
+func (p *aggregateProcessor) ProcessBatch(ctx context.Context, batch service.MessageBatch) ([]service.MessageBatch, error) {
+	groups := map[string]*aggregateState{}
+	var order []*aggregateState
+	var failed service.MessageBatch
+
+	for i, msg := range batch {
+		dims, err := p.dimensions(batch, i)
+		if err != nil {
+			msg.SetError(err)
+			failed = append(failed, msg)
+			continue
+		}
+		values, err := p.values(batch, i)
+		if err != nil {
+			msg.SetError(err)
+			failed = append(failed, msg)
+			continue
+		}
+
+		keyBytes, err := json.Marshal(dims)
+		if err != nil {
+			msg.SetError(fmt.Errorf("failed to serialise group dimensions: %w", err))
+			failed = append(failed, msg)
+			continue
+		}
+
+		s, exists := groups[string(keyBytes)]
+		if !exists {
+			s = p.newState(msg, dims)
+			groups[string(keyBytes)] = s
+			order = append(order, s)
+		}
+		s.add(msg, values)
+	}
+
+	resBatch := make(service.MessageBatch, 0, len(order)+len(failed))
+	for _, s := range order {
+		resBatch = append(resBatch, p.record(s))
+	}
+	resBatch = append(resBatch, failed...)
+	if len(resBatch) == 0 {
+		return nil, nil
+	}
+	return []service.MessageBatch{resBatch}, nil
+}
+
+func (p *aggregateProcessor) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,122 @@
+package pure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestAggregateGroups(t *testing.T) {
+	conf, err := aggregateProcessorConfig().ParseYAML(`
+group_by: |
+  root.service = this.service
+aggregates:
+  - name: requests
+    type: count
+  - name: total
+    type: sum
+    value: root = this.latency
+  - name: min
+    type: min
+    value: root = this.latency
+  - name: max
+    type: max
+    value: root = this.latency
+  - name: mean
+    type: mean
+    value: root = this.latency
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newAggregateProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	var batch service.MessageBatch
+	for _, doc := range []string{
+		`{"service":"foo","latency":10}`,
+		`{"service":"bar","latency":5}`,
+		`{"service":"foo","latency":30}`,
+		`{"service":"foo","latency":"nope"}`,
+		`{"service":"foo","latency":20}`,
+	} {
+		msg := service.NewMessage([]byte(doc))
+		msg.MetaSet("source", doc)
+		batch = append(batch, msg)
+	}
+
+	res, err := proc.ProcessBatch(context.Background(), batch)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Len(t, res[0], 3)
+
+	v, err := res[0][0].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"service":  "foo",
+		"requests": int64(3),
+		"total":    60.0,
+		"min":      10.0,
+		"max":      30.0,
+		"mean":     20.0,
+	}, v)
+	source, _ := res[0][0].MetaGet("source")
+	assert.Equal(t, `{"service":"foo","latency":10}`, source)
+
+	v, err = res[0][1].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"service":  "bar",
+		"requests": int64(1),
+		"total":    5.0,
+		"min":      5.0,
+		"max":      5.0,
+		"mean":     5.0,
+	}, v)
+
+	mBytes, err := res[0][2].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, `{"service":"foo","latency":"nope"}`, string(mBytes))
+	assert.Error(t, res[0][2].GetError())
+}
+
+func TestAggregateNoGroups(t *testing.T) {
+	conf, err := aggregateProcessorConfig().ParseYAML(`
+aggregates:
+  - name: count
+    type: count
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newAggregateProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	res, err := proc.ProcessBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`a`)),
+		service.NewMessage([]byte(`b`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Len(t, res[0], 1)
+
+	v, err := res[0][0].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"count": int64(2)}, v)
+}
+
+func TestAggregateConfigErrors(t *testing.T) {
+	for _, confStr := range []string{
+		`aggregates: []`,
+		`aggregates: [ { name: foo, type: sum } ]`,
+		`aggregates: [ { name: "", type: count } ]`,
+	} {
+		conf, err := aggregateProcessorConfig().ParseYAML(confStr, nil)
+		require.NoError(t, err)
+
+		_, err = newAggregateProcessorFromConfig(conf, service.MockResources())
+		assert.Error(t, err, confStr)
+	}
+}
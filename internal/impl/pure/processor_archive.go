@@ -4,13 +4,18 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/benthosdev/benthos/v4/internal/batch"
 	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/tracing"
 	"github.com/benthosdev/benthos/v4/public/service"
 )
 
@@ -28,6 +33,7 @@ The functionality of this processor depends on being applied across messages tha
 		Field(service.NewStringAnnotatedEnumField("format", map[string]string{
 			`concatenate`: `Join the raw contents of each message into a single binary message.`,
 			`tar`:         `Archive messages to a unix standard tape archive.`,
+			`tar.gz`:      `Archive messages to a gzip compressed unix standard tape archive.`,
 			`zip`:         `Archive messages to a zip file.`,
 			`binary`:      `Archive messages to a [binary blob format](https://github.com/benthosdev/benthos/blob/main/internal/message/message.go#L96).`,
 			`lines`:       `Join the raw contents of each message and insert a line break between each one.`,
@@ -74,28 +80,46 @@ type headerFunc func(index int, body *service.Message) os.FileInfo
 
 func tarArchive(hFunc headerFunc, msg service.MessageBatch) (*service.Message, error) {
 	buf := &bytes.Buffer{}
-	tw := tar.NewWriter(buf)
+	if err := writeTar(buf, hFunc, msg); err != nil {
+		return nil, err
+	}
+	msg[0].SetBytes(buf.Bytes())
+	return msg[0], nil
+}
+
+func tarGzipArchive(hFunc headerFunc, msg service.MessageBatch) (*service.Message, error) {
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	if err := writeTar(gw, hFunc, msg); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	msg[0].SetBytes(buf.Bytes())
+	return msg[0], nil
+}
+
+func writeTar(w io.Writer, hFunc headerFunc, msg service.MessageBatch) error {
+	tw := tar.NewWriter(w)
 
 	for i, part := range msg {
 		hdr, err := tar.FileInfoHeader(hFunc(i, part), "")
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if err := tw.WriteHeader(hdr); err != nil {
-			return nil, err
+			return err
 		}
 		pBytes, err := part.AsBytes()
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if _, err := tw.Write(pBytes); err != nil {
-			return nil, err
+			return err
 		}
 	}
-
-	tw.Close()
-	msg[0].SetBytes(buf.Bytes())
-	return msg[0], nil
+	return tw.Close()
 }
 
 func zipArchive(hFunc headerFunc, msg service.MessageBatch) (*service.Message, error) {
@@ -185,6 +209,8 @@ func strToArchiver(str string) (archiveFunc, error) {
 	switch str {
 	case "tar":
 		return tarArchive, nil
+	case "tar.gz":
+		return tarGzipArchive, nil
 	case "zip":
 		return zipArchive, nil
 	case "binary":
@@ -205,6 +231,7 @@ type archive struct {
 	archive archiveFunc
 	path    *service.InterpolatedString
 	log     *service.Logger
+	tracer  trace.TracerProvider
 }
 
 func newArchiveFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*archive, error) {
@@ -228,6 +255,7 @@ func newArchive(nm *service.Resources, format string, path *service.Interpolated
 		archive: archiver,
 		path:    path,
 		log:     nm.Logger(),
+		tracer:  nm.OtelTracer(),
 	}, nil
 }
 
@@ -291,7 +319,13 @@ func (d *archive) ProcessBatch(ctx context.Context, msg service.MessageBatch) ([
 		return nil, err
 	}
 
-	newPart = newPart.WithContext(batch.CtxWithCollapsedCount(newPart.Context(), len(msg)))
+	sources := make([]context.Context, len(msg))
+	for i, m := range msg {
+		sources[i] = m.Context()
+	}
+	joinedCtx := tracing.WithJoinedSpan(d.tracer, "archive_join", sources...)
+
+	newPart = newPart.WithContext(batch.CtxWithCollapsedCount(joinedCtx, len(msg)))
 	return []service.MessageBatch{{newPart}}, nil
 }
 
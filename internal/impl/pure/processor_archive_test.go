@@ -83,6 +83,52 @@ path: 'foo-${!meta("path")}'
 	assert.Equal(t, len(exp), i)
 }
 
+func TestArchiveTarGzipRoundTrip(t *testing.T) {
+	conf, err := archiveProcConfig().ParseYAML(`
+format: tar.gz
+path: 'foo/${!meta("path")}'
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newArchiveFromParsed(conf, service.MockResources())
+	require.NoError(t, err)
+
+	exp := []string{"first part", "second part", "third part"}
+
+	var msg service.MessageBatch
+	for i, e := range exp {
+		p := service.NewMessage([]byte(e))
+		p.MetaSet("path", fmt.Sprintf("bar%v.txt", i))
+		msg = append(msg, p)
+	}
+
+	batches, err := proc.ProcessBatch(context.Background(), msg)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 1)
+
+	uConf, err := unarchiveProcConfig().ParseYAML(`
+format: tar.gz
+`, nil)
+	require.NoError(t, err)
+
+	uProc, err := newUnarchiveFromParsed(uConf, service.MockResources())
+	require.NoError(t, err)
+
+	msgs, err := uProc.Process(context.Background(), batches[0][0])
+	require.NoError(t, err)
+	require.Len(t, msgs, len(exp))
+
+	for i, e := range exp {
+		mBytes, err := msgs[i].AsBytes()
+		require.NoError(t, err)
+		assert.Equal(t, e, string(mBytes))
+
+		name, _ := msgs[i].MetaGet("archive_basename")
+		assert.Equal(t, fmt.Sprintf("bar%v.txt", i), name)
+	}
+}
+
 func TestArchiveZip(t *testing.T) {
 	conf, err := archiveProcConfig().ParseYAML(`
 format: zip
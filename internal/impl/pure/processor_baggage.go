@@ -0,0 +1,111 @@
+package pure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benthosdev/benthos/v4/internal/tracing"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	bgpFieldMapping = "mapping"
+)
+
+func baggageProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Sets members of the [baggage](https://www.w3.org/TR/baggage/) of the tracing context of messages from the result of a Bloblang mapping.").
+		Description(`
+Baggage is a set of key/value pairs that is propagated along with the tracing spans of messages, and is therefore included in the tracing information injected into outbound messages by outputs (with `+"`inject_tracing_map`"+`) and extracted by inputs (with `+"`extract_tracing_map`"+`). This allows values such as tenant identifiers to follow a message across services without modifying its payload or metadata.
+
+The mapping must result in an object, where each key and value is set as a member of the baggage of the message, replacing any existing member with the same key. Members assigned a `+"`null`"+` value are removed from the baggage. The baggage of a message can be read with the [`+"`tracing_baggage`"+` function](/docs/guides/bloblang/functions#tracing_baggage).`).
+		Field(service.NewBloblangField(bgpFieldMapping).
+			Description("A [Bloblang mapping](/docs/guides/bloblang/about) that results in an object of baggage members to set.").
+			Example(`root.tenant_id = @tenant_id`).
+			Example(`root.user_id = this.user.id
+root.stale_member = null`)).
+		Example(
+			"Propagate a Tenant",
+			"Here we add the tenant of each message to its baggage so that it is propagated within the Kafka headers of the output, and can be read by the services consuming it.",
+			`
+pipeline:
+  processors:
+    - baggage:
+        mapping: 'root.tenant_id = this.tenant'
+
+output:
+  kafka_franz:
+    seed_brokers: [ localhost:9092 ]
+    topic: events
+    inject_tracing_map: 'meta = @.merge(this)'
+    metadata:
+      include_patterns: [ '.*' ]
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchProcessor(
+		"baggage", baggageProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchProcessor, error) {
+			mapping, err := conf.FieldBloblang(bgpFieldMapping)
+			if err != nil {
+				return nil, err
+			}
+			return &baggageProcessor{mapping: mapping}, nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type baggageProcessor struct {
+	mapping *bloblang.Executor
+}
+
+func (p *baggageProcessor) members(batch service.MessageBatch, i int) (map[string]any, error) {
+	res, err := batch.BloblangQuery(i, p.mapping)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, nil
+	}
+	v, err := res.AsStructured()
+	if err != nil {
+		return nil, err
+	}
+	members, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("mapping resulted in a non-object value: %T", v)
+	}
+	return members, nil
+}
+
+func (p *baggageProcessor) ProcessBatch(ctx context.Context, batch service.MessageBatch) ([]service.MessageBatch, error) {
+	for i, msg := range batch {
+		members, err := p.members(batch, i)
+		if err != nil {
+			msg.SetError(fmt.Errorf("baggage mapping failed: %w", err))
+			continue
+		}
+		if len(members) == 0 {
+			continue
+		}
+		mCtx, err := tracing.WithBaggageMembers(msg.Context(), members)
+		if err != nil {
+			msg.SetError(err)
+			continue
+		}
+		batch[i] = msg.WithContext(mCtx)
+	}
+	return []service.MessageBatch{batch}, nil
+}
+
+func (p *baggageProcessor) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,51 @@
+package pure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/baggage"
+
+	"github.com/benthosdev/benthos/v4/internal/tracing"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestBaggageProcessor(t *testing.T) {
+	conf, err := baggageProcessorConfig().ParseYAML(`
+mapping: |
+  root.tenant = this.tenant
+  root.stale = null
+`, nil)
+	require.NoError(t, err)
+
+	mapping, err := conf.FieldBloblang(bgpFieldMapping)
+	require.NoError(t, err)
+	proc := &baggageProcessor{mapping: mapping}
+
+	staleCtx, err := tracing.WithBaggageMembers(context.Background(), map[string]any{
+		"stale": "foo",
+		"other": "bar",
+	})
+	require.NoError(t, err)
+
+	batch := service.MessageBatch{
+		service.NewMessage([]byte(`{"tenant":"acme"}`)).WithContext(staleCtx),
+		service.NewMessage([]byte(`not json`)),
+	}
+
+	res, err := proc.ProcessBatch(context.Background(), batch)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Len(t, res[0], 2)
+
+	b := baggage.FromContext(res[0][0].Context())
+	assert.Equal(t, "acme", b.Member("tenant").Value())
+	assert.Equal(t, "bar", b.Member("other").Value())
+	assert.Equal(t, "", b.Member("stale").Key())
+	assert.NoError(t, res[0][0].GetError())
+
+	assert.Error(t, res[0][1].GetError())
+	assert.Equal(t, 0, baggage.FromContext(res[0][1].Context()).Len())
+}
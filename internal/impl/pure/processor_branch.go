@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"sort"
-	"strconv"
 	"time"
 
 	"go.opentelemetry.io/otel/trace"
@@ -212,7 +211,7 @@ type Branch struct {
 func newBranch(conf processor.BranchConfig, mgr bundle.NewManagement) (*Branch, error) {
 	children := make([]processor.V1, 0, len(conf.Processors))
 	for i, pconf := range conf.Processors {
-		pMgr := mgr.IntoPath("branch", "processors", strconv.Itoa(i))
+		pMgr := mgr.IntoPath("branch", "processors", bundle.PathSegment(i, pconf.Label))
 		proc, err := pMgr.NewProcessor(pconf)
 		if err != nil {
 			return nil, fmt.Errorf("failed to init processor %v: %w", i, err)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
@@ -35,9 +36,9 @@ For use cases where you wish to cache the result of processors consider using th
 This processor will interpolate functions within the ` + "`key` and `value`" + ` fields individually for each message. This allows you to specify dynamic keys and values based on the contents of the message payloads and metadata. You can find a list of functions [here](/docs/configuration/interpolation#bloblang-queries).`,
 		Config: docs.FieldComponent().WithChildren(
 			docs.FieldString("resource", "The [`cache` resource](/docs/components/caches/about) to target with this processor."),
-			docs.FieldString("operator", "The [operation](#operators) to perform with the cache.").HasOptions("set", "add", "get", "delete"),
+			docs.FieldString("operator", "The [operation](#operators) to perform with the cache.").HasOptions("set", "add", "get", "delete", "incr", "decr"),
 			docs.FieldString("key", "A key to use with the cache.").IsInterpolated(),
-			docs.FieldString("value", "A value to use with the cache (when applicable).").IsInterpolated(),
+			docs.FieldString("value", "A value to use with the cache (when applicable). For the `incr` and `decr` operators this is the integer to increment or decrement by, and defaults to `1` when empty.").IsInterpolated(),
 			docs.FieldString(
 				"ttl", "The TTL of each individual item as a duration string. After this period an item will be eligible for removal during the next compaction. Not all caches support per-key TTLs, those that do will have a configuration field `default_ttl`, and those that do not will fall back to their generally configured TTL setting.",
 				"60s", "5m", "36h",
@@ -142,7 +143,19 @@ can be detected with [processor error handling](/docs/configuration/error_handli
 ### ` + "`delete`" + `
 
 Delete a key and its contents from the cache.  If the key does not exist the
-action is a no-op and will not fail with an error.`,
+action is a no-op and will not fail with an error.
+
+### ` + "`incr`" + `
+
+Atomically increment the integer value of a key by the ` + "`value`" + ` (or
+` + "`1`" + ` when empty) and replace the original message payload with the
+result, where a key that does not exist is treated as zero. Only caches that
+support atomic increments (such as ` + "`redis`" + `) can be used with this
+operator, other caches fail with an error.
+
+### ` + "`decr`" + `
+
+The same as ` + "`incr`" + ` except the value of the key is decremented.`,
 	})
 	if err != nil {
 		panic(err)
@@ -234,6 +247,27 @@ func newCacheDeleteOperator() cacheOperator {
 	}
 }
 
+func newCacheIncrOperator(sign int64) cacheOperator {
+	return func(ctx context.Context, c cache.V1, key string, value []byte, ttl *time.Duration) ([]byte, bool, error) {
+		delta := int64(1)
+		if len(value) > 0 {
+			var err error
+			if delta, err = strconv.ParseInt(string(value), 10, 64); err != nil {
+				return nil, false, fmt.Errorf("value must be an integer: %w", err)
+			}
+		}
+		incr, ok := c.(cache.Incrementer)
+		if !ok {
+			return nil, false, cache.ErrIncrNotSupported
+		}
+		result, err := incr.Incr(ctx, key, sign*delta, ttl)
+		if err != nil {
+			return nil, false, err
+		}
+		return strconv.AppendInt(nil, result, 10), true, nil
+	}
+}
+
 func cacheOperatorFromString(operator string) (cacheOperator, error) {
 	switch operator {
 	case "set":
@@ -244,6 +278,10 @@ func cacheOperatorFromString(operator string) (cacheOperator, error) {
 		return newCacheGetOperator(), nil
 	case "delete":
 		return newCacheDeleteOperator(), nil
+	case "incr":
+		return newCacheIncrOperator(1), nil
+	case "decr":
+		return newCacheIncrOperator(-1), nil
 	}
 	return nil, fmt.Errorf("operator not recognised: %v", operator)
 }
@@ -202,3 +202,54 @@ func TestCacheDelete(t *testing.T) {
 	_, ok = mgr.Caches["foocache"]["3"]
 	require.False(t, ok)
 }
+
+func TestCacheIncrDecr(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"1": {Value: "10"},
+		"2": {Value: "nope"},
+	}
+
+	conf := processor.NewConfig()
+	conf.Type = "cache"
+	conf.Cache.Key = "${!json(\"key\")}"
+	conf.Cache.Value = "${!json(\"by\")}"
+	conf.Cache.Resource = "foocache"
+	conf.Cache.Operator = "incr"
+	incrProc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	conf.Cache.Operator = "decr"
+	decrProc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	output, res := incrProc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","by":""}`),
+		[]byte(`{"key":"1","by":5}`),
+		[]byte(`{"key":"3","by":2}`),
+		[]byte(`{"key":"2","by":1}`),
+		[]byte(`{"key":"3","by":"nope"}`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+
+	assert.Equal(t, [][]byte{
+		[]byte(`11`),
+		[]byte(`16`),
+		[]byte(`2`),
+		[]byte(`{"key":"2","by":1}`),
+		[]byte(`{"key":"3","by":"nope"}`),
+	}, message.GetAllBytes(output[0]))
+	assert.Error(t, output[0].Get(3).ErrorGet())
+	assert.Error(t, output[0].Get(4).ErrorGet())
+
+	output, res = decrProc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","by":20}`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+	assert.Equal(t, [][]byte{[]byte(`-4`)}, message.GetAllBytes(output[0]))
+
+	assert.Equal(t, "-4", mgr.Caches["foocache"]["1"].Value)
+	assert.Equal(t, "2", mgr.Caches["foocache"]["3"].Value)
+}
@@ -3,7 +3,6 @@ package pure
 import (
 	"context"
 	"errors"
-	"strconv"
 
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
@@ -49,7 +48,9 @@ fail for the processor ` + "`foo`" + ` will skip these processors.
 
 When messages leave the catch block their fail flags are cleared. This processor
 is useful for when it's possible to recover failed messages, or when special
-actions (such as logging/metrics) are required before dropping them.
+actions (such as logging/metrics) are required before dropping them. For
+applying different processors depending on the error of a message use the
+` + "[`try_catch`](/docs/components/processors/try_catch)" + ` processor.
 
 More information about error handling can be found [here](/docs/configuration/error_handling).`,
 		Config: docs.FieldProcessor("", "").Array().
@@ -91,7 +92,7 @@ type catchProc struct {
 func newCatch(conf []processor.Config, mgr bundle.NewManagement) (*catchProc, error) {
 	var children []processor.V1
 	for i, pconf := range conf {
-		pMgr := mgr.IntoPath("catch", strconv.Itoa(i))
+		pMgr := mgr.IntoPath("catch", bundle.PathSegment(i, pconf.Label))
 		proc, err := pMgr.NewProcessor(pconf)
 		if err != nil {
 			return nil, err
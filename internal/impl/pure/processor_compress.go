@@ -2,10 +2,12 @@ package pure
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/message"
 )
@@ -24,12 +26,19 @@ func init() {
 		},
 		Summary: `
 Compresses messages according to the selected algorithm. Supported compression
-algorithms are: gzip, pgzip, zlib, flate, snappy, lz4.`,
+algorithms are: gzip, pgzip, zlib, flate, snappy, snappy-framed, lz4, zstd.`,
 		Description: `
-The 'level' field might not apply to all algorithms.`,
+The 'level' field might not apply to all algorithms.
+
+The ` + "`snappy`" + ` algorithm produces raw snappy blocks, whereas ` + "`snappy-framed`" + ` produces the snappy framing format that is compatible with streaming decoders.
+
+### Dictionaries
+
+The ` + "`zstd`" + ` algorithm supports compressing with a pre-shared dictionary, which can drastically improve the compression ratio of small messages that share a common structure. Messages compressed with a dictionary must be decompressed with the same dictionary.`,
 		Config: docs.FieldComponent().WithChildren(
-			docs.FieldString("algorithm", "The compression algorithm to use.").HasOptions("gzip", "pgzip", "zlib", "flate", "snappy", "lz4"),
+			docs.FieldString("algorithm", "The compression algorithm to use.").HasOptions("gzip", "pgzip", "zlib", "flate", "snappy", "snappy-framed", "lz4", "zstd"),
 			docs.FieldInt("level", "The level of compression to use. May not be applicable to all algorithms."),
+			docs.FieldString("dictionary", "An optional path to a file containing a compression dictionary. Only supported by the `zstd` algorithm.", "./dicts/events.dict").Advanced().AtVersion("4.24.0"),
 		).ChildDefaultAndTypesFromStruct(processor.NewCompressConfig()),
 	})
 	if err != nil {
@@ -44,7 +53,17 @@ type compressProc struct {
 }
 
 func newCompress(conf processor.CompressConfig, mgr bundle.NewManagement) (*compressProc, error) {
-	cor, err := strToCompressor(conf.Algorithm)
+	var cor CompressFunc
+	var err error
+	if conf.Dictionary != "" {
+		var dict []byte
+		if dict, err = ifs.ReadFile(mgr.FS(), conf.Dictionary); err != nil {
+			return nil, fmt.Errorf("failed to read dictionary: %w", err)
+		}
+		cor, err = strToDictCompressor(conf.Algorithm, conf.Level, dict)
+	} else {
+		cor, err = strToCompressor(conf.Algorithm)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,385 @@
+package pure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	dbzFieldFormat        = "format"
+	dbzFieldAvroSchema    = "avro_schema"
+	dbzFieldDeletes       = "deletes"
+	dbzFieldTombstones    = "tombstones"
+	dbzFieldIncludeBefore = "include_before"
+)
+
+func debeziumProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Categories("Parsing").
+		Version("4.24.0").
+		Summary("Unwraps [Debezium](https://debezium.io/) change data capture events into documents of the row state, with the details of the change added as metadata.").
+		Description(`
+Debezium change events contain the state of a row before and after a change along with an operation code and details of the source of the change. This processor replaces the contents of each message with the state of the row after the change, which is similar to the `+"`ExtractNewRecordState`"+` transformation of Kafka Connect.
+
+Events serialized with the Kafka Connect JSON converter are supported both with and without schemas enabled, in which case the `+"`payload`"+` of the event is unwrapped. Avro encoded events should first be decoded with the `+"[`schema_registry_decode`](/docs/components/processors/schema_registry_decode)"+` processor. When `+"`avro_raw_json`"+` is enabled on the decoder the event contains no unions and the `+"`json`"+` format can be used. Otherwise the decoded event contains the type wrappers of union values, in which case the `+"`format`"+` field should be set to `+"`avro`"+` and the Avro schema of the events provided with the `+"`avro_schema`"+` field, which determines the fields that are unions and are therefore unwrapped.
+
+Events that are not Debezium change events, such as heartbeats or schema changes, are rejected with an error and can be handled with [error handling](/docs/configuration/error_handling) patterns.
+
+### Metadata
+
+This processor adds the following metadata fields to each message:
+
+`+"```text"+`
+- debezium_op
+- debezium_operation
+- debezium_ts_ms
+- debezium_source_*
+`+"```"+`
+
+The field `+"`debezium_op`"+` contains the operation code of the change (`+"`c`, `u`, `d`, `r` or `t`"+`) and `+"`debezium_operation`"+` the name of the operation (`+"`create`, `update`, `delete`, `read` or `truncate`"+`). Each field of the `+"`source`"+` block of the event, such as `+"`db`, `table` and `lsn`"+`, is added with the prefix `+"`debezium_source_`"+`.
+
+Truncate events result in an empty document.`).
+		Field(service.NewStringEnumField(dbzFieldFormat, "json", "avro").
+			Description("The format the change events were decoded from. When set to `avro` the union types of events decoded from Avro are unwrapped according to the `avro_schema`.").
+			Default("json")).
+		Field(service.NewStringField(dbzFieldAvroSchema).
+			Description("The Avro schema of the change events, which is required when the `format` is `avro`.").
+			Optional()).
+		Field(service.NewStringAnnotatedEnumField(dbzFieldDeletes, map[string]string{
+			"before": "Replace the message with the state of the row before it was deleted.",
+			"null":   "Replace the message with a `null` document.",
+			"drop":   "Remove the message.",
+		}).
+			Description("How to handle delete events, which contain no row state after the change.").
+			Default("before")).
+		Field(service.NewStringAnnotatedEnumField(dbzFieldTombstones, map[string]string{
+			"drop": "Remove the message.",
+			"keep": "Keep the message with empty contents, which is useful when writing to compacted Kafka topics.",
+		}).
+			Description("How to handle tombstones, which are messages with empty contents that follow delete events.").
+			Default("drop")).
+		Field(service.NewBoolField(dbzFieldIncludeBefore).
+			Description("Whether to add the state of the row before the change as the structured metadata field `debezium_before`, which can be accessed with the `@debezium_before` syntax from Bloblang.").
+			Default(false).
+			Advanced()).
+		LintRule(`root = if this.format.or("json") == "avro" && this.avro_schema.or("") == "" { "an avro_schema must be set when the format is avro" }`).
+		Example("Streaming Rows from Kafka",
+			"In this example we consume Avro encoded change events from a Kafka topic populated by Debezium and write the latest state of each row to Elasticsearch, deleting documents for rows that have been deleted.",
+			`
+input:
+  kafka:
+    addresses: [ TODO ]
+    topics: [ dbserver1.inventory.customers ]
+    consumer_group: benthos_customers
+
+pipeline:
+  processors:
+    - schema_registry_decode:
+        url: http://localhost:8081
+        avro_raw_json: true
+    - debezium: {}
+
+output:
+  elasticsearch:
+    urls: [ http://localhost:9200 ]
+    index: customers
+    id: ${! json("id") }
+    action: ${! if meta("debezium_op") == "d" { "delete" } else { "index" } }
+`)
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"debezium", debeziumProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			return newDebeziumProcessorFromConfig(conf)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+var debeziumOperations = map[string]string{
+	"c": "create",
+	"u": "update",
+	"d": "delete",
+	"r": "read",
+	"t": "truncate",
+}
+
+type debeziumProcessor struct {
+	avroUnions    *avroUnionUnwrapper
+	deletes       string
+	keepTombstone bool
+	includeBefore bool
+}
+
+func newDebeziumProcessorFromConfig(conf *service.ParsedConfig) (*debeziumProcessor, error) {
+	format, err := conf.FieldString(dbzFieldFormat)
+	if err != nil {
+		return nil, err
+	}
+	deletes, err := conf.FieldString(dbzFieldDeletes)
+	if err != nil {
+		return nil, err
+	}
+	tombstones, err := conf.FieldString(dbzFieldTombstones)
+	if err != nil {
+		return nil, err
+	}
+	includeBefore, err := conf.FieldBool(dbzFieldIncludeBefore)
+	if err != nil {
+		return nil, err
+	}
+	var avroUnions *avroUnionUnwrapper
+	if format == "avro" {
+		if !conf.Contains(dbzFieldAvroSchema) {
+			return nil, errors.New("an avro_schema must be set when the format is avro")
+		}
+		schema, err := conf.FieldString(dbzFieldAvroSchema)
+		if err != nil {
+			return nil, err
+		}
+		if avroUnions, err = newAvroUnionUnwrapper(schema); err != nil {
+			return nil, err
+		}
+	}
+	return &debeziumProcessor{
+		avroUnions:    avroUnions,
+		deletes:       deletes,
+		keepTombstone: tombstones == "keep",
+		includeBefore: includeBefore,
+	}, nil
+}
+
+func (d *debeziumProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	raw, err := msg.AsBytes()
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return d.tombstone(msg)
+	}
+
+	v, err := msg.AsStructuredMut()
+	if err != nil {
+		return nil, err
+	}
+
+	envelope, isObj := v.(map[string]any)
+	if !isObj {
+		return nil, fmt.Errorf("expected change event object, got %T", v)
+	}
+	if payload, exists := envelope["payload"]; exists {
+		if _, hasSchema := envelope["schema"]; hasSchema {
+			if payload == nil {
+				return d.tombstone(msg)
+			}
+			if envelope, isObj = payload.(map[string]any); !isObj {
+				return nil, fmt.Errorf("expected change event payload object, got %T", payload)
+			}
+		}
+	}
+	if d.avroUnions != nil {
+		envelope, _ = d.avroUnions.unwrap(d.avroUnions.schema, envelope).(map[string]any)
+	}
+
+	op, _ := envelope["op"].(string)
+	opName, exists := debeziumOperations[op]
+	if !exists {
+		return nil, errors.New("message is not a debezium change event")
+	}
+
+	msg.MetaSetMut("debezium_op", op)
+	msg.MetaSetMut("debezium_operation", opName)
+	if ts, exists := envelope["ts_ms"]; exists && ts != nil {
+		msg.MetaSetMut("debezium_ts_ms", query.IToString(ts))
+	}
+	if source, ok := envelope["source"].(map[string]any); ok {
+		for k, v := range source {
+			if v == nil {
+				continue
+			}
+			msg.MetaSetMut("debezium_source_"+k, query.IToString(v))
+		}
+	}
+	if d.includeBefore {
+		if before := envelope["before"]; before != nil {
+			msg.MetaSetMut("debezium_before", before)
+		}
+	}
+
+	switch op {
+	case "d":
+		switch d.deletes {
+		case "drop":
+			return nil, nil
+		case "null":
+			msg.SetStructuredMut(nil)
+		default:
+			msg.SetStructuredMut(envelope["before"])
+		}
+	case "t":
+		msg.SetBytes(nil)
+	default:
+		msg.SetStructuredMut(envelope["after"])
+	}
+	return service.MessageBatch{msg}, nil
+}
+
+func (d *debeziumProcessor) tombstone(msg *service.Message) (service.MessageBatch, error) {
+	if !d.keepTombstone {
+		return nil, nil
+	}
+	msg.SetBytes(nil)
+	return service.MessageBatch{msg}, nil
+}
+
+func (d *debeziumProcessor) Close(ctx context.Context) error {
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// avroUnionUnwrapper removes the type wrappers that Avro JSON encoding adds to
+// union values, which are objects with a single key naming the type of the
+// value. Values are only unwrapped where the schema declares a union.
+type avroUnionUnwrapper struct {
+	schema any
+	named  map[string]map[string]any
+}
+
+func newAvroUnionUnwrapper(schema string) (*avroUnionUnwrapper, error) {
+	u := &avroUnionUnwrapper{named: map[string]map[string]any{}}
+	if err := json.Unmarshal([]byte(schema), &u.schema); err != nil {
+		return nil, fmt.Errorf("failed to parse avro_schema: %w", err)
+	}
+	u.register(u.schema, "")
+	return u, nil
+}
+
+// register walks a schema and records its named types by their full name,
+// which replaces the name of each type, as well as their short name.
+func (u *avroUnionUnwrapper) register(schema any, namespace string) {
+	switch s := schema.(type) {
+	case []any:
+		for _, branch := range s {
+			u.register(branch, namespace)
+		}
+	case map[string]any:
+		switch t := s["type"]; t {
+		case "record", "error", "enum", "fixed":
+			name, _ := s["name"].(string)
+			if ns, _ := s["namespace"].(string); ns != "" && !strings.Contains(name, ".") {
+				namespace = ns
+			}
+			if i := strings.LastIndex(name, "."); i >= 0 {
+				namespace = name[:i]
+			} else if namespace != "" {
+				name = namespace + "." + name
+			}
+			s["name"] = name
+			u.named[name] = s
+			if short := name[strings.LastIndex(name, ".")+1:]; u.named[short] == nil {
+				u.named[short] = s
+			}
+			fields, _ := s["fields"].([]any)
+			for _, f := range fields {
+				if fObj, ok := f.(map[string]any); ok {
+					u.register(fObj["type"], namespace)
+				}
+			}
+		case "array":
+			u.register(s["items"], namespace)
+		case "map":
+			u.register(s["values"], namespace)
+		default:
+			u.register(t, namespace)
+		}
+	}
+}
+
+// typeName returns the name that identifies a branch of a union.
+func (u *avroUnionUnwrapper) typeName(schema any) string {
+	switch s := schema.(type) {
+	case string:
+		if named, exists := u.named[s]; exists {
+			name, _ := named["name"].(string)
+			return name
+		}
+		return s
+	case map[string]any:
+		switch t := s["type"]; t {
+		case "record", "error", "enum", "fixed":
+			name, _ := s["name"].(string)
+			return name
+		default:
+			return u.typeName(t)
+		}
+	}
+	return ""
+}
+
+// unwrap returns the value with the union wrappers declared by the schema
+// removed, objects and arrays are modified in place.
+func (u *avroUnionUnwrapper) unwrap(schema, v any) any {
+	switch s := schema.(type) {
+	case string:
+		if named, exists := u.named[s]; exists {
+			return u.unwrap(named, v)
+		}
+	case []any:
+		obj, ok := v.(map[string]any)
+		if !ok || len(obj) != 1 {
+			return v
+		}
+		for k, inner := range obj {
+			for _, branch := range s {
+				if u.typeName(branch) == k {
+					return u.unwrap(branch, inner)
+				}
+			}
+		}
+	case map[string]any:
+		switch t := s["type"]; t {
+		case "record", "error":
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return v
+			}
+			fields, _ := s["fields"].([]any)
+			for _, f := range fields {
+				fObj, _ := f.(map[string]any)
+				name, _ := fObj["name"].(string)
+				if inner, exists := obj[name]; exists {
+					obj[name] = u.unwrap(fObj["type"], inner)
+				}
+			}
+		case "array":
+			if arr, ok := v.([]any); ok {
+				for i, inner := range arr {
+					arr[i] = u.unwrap(s["items"], inner)
+				}
+			}
+		case "map":
+			if obj, ok := v.(map[string]any); ok {
+				for k, inner := range obj {
+					obj[k] = u.unwrap(s["values"], inner)
+				}
+			}
+		case "enum", "fixed":
+		default:
+			return u.unwrap(t, v)
+		}
+	}
+	return v
+}
@@ -0,0 +1,203 @@
+package pure
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const debeziumTestAvroConfig = `
+format: avro
+avro_schema: |
+  {
+    "type": "record", "name": "Envelope", "namespace": "dbserver1.inventory.customers",
+    "fields": [
+      { "name": "before", "type": [ "null", {
+        "type": "record", "name": "Value",
+        "fields": [
+          { "name": "id", "type": "int" },
+          { "name": "name", "type": [ "null", "string" ] },
+          { "name": "email", "type": [ "null", "string" ] },
+          { "name": "tags", "type": { "type": "record", "name": "Tags", "fields": [ { "name": "string", "type": "string" } ] } }
+        ]
+      } ] },
+      { "name": "after", "type": [ "null", "Value" ] },
+      { "name": "source", "type": {
+        "type": "record", "name": "Source", "namespace": "io.debezium.connector.postgresql",
+        "fields": [
+          { "name": "db", "type": "string" },
+          { "name": "snapshot", "type": [ "null", "string" ] }
+        ]
+      } },
+      { "name": "op", "type": "string" },
+      { "name": "ts_ms", "type": [ "null", "long" ] }
+    ]
+  }
+`
+
+func TestDebeziumProcessor(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		input       string
+		output      []string
+		metadata    map[string]any
+		errContains string
+	}{
+		{
+			name:   "json create",
+			input:  `{"before":null,"after":{"id":1,"name":"foo"},"source":{"db":"inventory","table":"customers","lsn":123456789012},"op":"c","ts_ms":1700000000000}`,
+			output: []string{`{"id":1,"name":"foo"}`},
+			metadata: map[string]any{
+				"debezium_op":           "c",
+				"debezium_operation":    "create",
+				"debezium_ts_ms":        "1700000000000",
+				"debezium_source_db":    "inventory",
+				"debezium_source_table": "customers",
+				"debezium_source_lsn":   "123456789012",
+			},
+		},
+		{
+			name:   "json with schema update",
+			input:  `{"schema":{"type":"struct"},"payload":{"before":{"id":1,"name":"foo"},"after":{"id":1,"name":"bar"},"source":{"table":"customers"},"op":"u"}}`,
+			output: []string{`{"id":1,"name":"bar"}`},
+			metadata: map[string]any{
+				"debezium_op":           "u",
+				"debezium_operation":    "update",
+				"debezium_source_table": "customers",
+			},
+		},
+		{
+			name:   "delete before",
+			input:  `{"before":{"id":1,"name":"foo"},"after":null,"op":"d"}`,
+			output: []string{`{"id":1,"name":"foo"}`},
+			metadata: map[string]any{
+				"debezium_op": "d",
+			},
+		},
+		{
+			name:   "delete null",
+			config: `deletes: "null"`,
+			input:  `{"before":{"id":1,"name":"foo"},"after":null,"op":"d"}`,
+			output: []string{`null`},
+		},
+		{
+			name:   "delete drop",
+			config: `deletes: drop`,
+			input:  `{"before":{"id":1,"name":"foo"},"after":null,"op":"d"}`,
+		},
+		{
+			name:  "tombstone dropped",
+			input: ``,
+		},
+		{
+			name:  "schema tombstone dropped",
+			input: `{"schema":null,"payload":null}`,
+		},
+		{
+			name:   "tombstone kept",
+			config: `tombstones: keep`,
+			input:  ``,
+			output: []string{``},
+		},
+		{
+			name:   "avro unions",
+			config: debeziumTestAvroConfig,
+			input:  `{"before":null,"after":{"dbserver1.inventory.customers.Value":{"id":1,"name":{"string":"foo"},"email":null,"tags":{"string":"bar"}}},"source":{"db":"inventory","snapshot":{"string":"true"}},"op":"r","ts_ms":{"long":1700000000000}}`,
+			output: []string{`{"email":null,"id":1,"name":"foo","tags":{"string":"bar"}}`},
+			metadata: map[string]any{
+				"debezium_op":              "r",
+				"debezium_operation":       "read",
+				"debezium_ts_ms":           "1700000000000",
+				"debezium_source_snapshot": "true",
+			},
+		},
+		{
+			name:   "include before",
+			config: `include_before: true`,
+			input:  `{"before":{"id":1},"after":{"id":2},"op":"u"}`,
+			output: []string{`{"id":2}`},
+			metadata: map[string]any{
+				"debezium_before": map[string]any{"id": json.Number("1")},
+			},
+		},
+		{
+			name:        "not a change event",
+			input:       `{"ts_ms":1700000000000}`,
+			errContains: "not a debezium change event",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			conf, err := debeziumProcessorConfig().ParseYAML(test.config, nil)
+			require.NoError(t, err)
+
+			proc, err := newDebeziumProcessorFromConfig(conf)
+			require.NoError(t, err)
+
+			batch, err := proc.Process(context.Background(), service.NewMessage([]byte(test.input)))
+			if test.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, batch, len(test.output))
+
+			for i, exp := range test.output {
+				b, err := batch[i].AsBytes()
+				require.NoError(t, err)
+				assert.Equal(t, exp, string(b))
+			}
+			for k, exp := range test.metadata {
+				v, exists := batch[0].MetaGetMut(k)
+				require.True(t, exists, k)
+				assert.Equal(t, exp, v, k)
+			}
+		})
+	}
+}
+
+func TestDebeziumProcessorAvroSchemaRequired(t *testing.T) {
+	conf, err := debeziumProcessorConfig().ParseYAML(`format: avro`, nil)
+	require.NoError(t, err)
+
+	_, err = newDebeziumProcessorFromConfig(conf)
+	require.EqualError(t, err, "an avro_schema must be set when the format is avro")
+}
+
+func TestDebeziumProcessorCopies(t *testing.T) {
+	conf, err := debeziumProcessorConfig().ParseYAML(debeziumTestAvroConfig, nil)
+	require.NoError(t, err)
+
+	proc, err := newDebeziumProcessorFromConfig(conf)
+	require.NoError(t, err)
+
+	msg := service.NewMessage(nil)
+	msg.SetStructured(map[string]any{
+		"after": map[string]any{"dbserver1.inventory.customers.Value": map[string]any{"id": 1, "name": map[string]any{"string": "foo"}}},
+		"op":    "c",
+	})
+
+	batch, err := proc.Process(context.Background(), msg.Copy())
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+
+	b, err := batch[0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1,"name":"foo"}`, string(b))
+
+	// The structured contents of the original message are left unchanged.
+	b, err = msg.AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, `{"after":{"dbserver1.inventory.customers.Value":{"id":1,"name":{"string":"foo"}}},"op":"c"}`, string(b))
+	_, exists := msg.MetaGetMut("debezium_op")
+	assert.False(t, exists)
+}
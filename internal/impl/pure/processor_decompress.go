@@ -2,10 +2,12 @@ package pure
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/message"
 )
@@ -24,9 +26,10 @@ func init() {
 		},
 		Summary: `
 Decompresses messages according to the selected algorithm. Supported
-decompression types are: gzip, pgzip, zlib, bzip2, flate, snappy, lz4.`,
+decompression types are: gzip, pgzip, zlib, bzip2, flate, snappy, snappy-framed, lz4, zstd.`,
 		Config: docs.FieldComponent().WithChildren(
-			docs.FieldString("algorithm", "The decompression algorithm to use.").HasOptions("gzip", "pgzip", "zlib", "bzip2", "flate", "snappy", "lz4"),
+			docs.FieldString("algorithm", "The decompression algorithm to use.").HasOptions("gzip", "pgzip", "zlib", "bzip2", "flate", "snappy", "snappy-framed", "lz4", "zstd"),
+			docs.FieldString("dictionary", "An optional path to a file containing the dictionary that messages were compressed with. Only supported by the `zstd` algorithm.", "./dicts/events.dict").Advanced().AtVersion("4.24.0"),
 		).ChildDefaultAndTypesFromStruct(processor.NewDecompressConfig()),
 	})
 	if err != nil {
@@ -40,7 +43,17 @@ type decompressProc struct {
 }
 
 func newDecompress(conf processor.DecompressConfig, mgr bundle.NewManagement) (*decompressProc, error) {
-	dcor, err := strToDecompressor(conf.Algorithm)
+	var dcor DecompressFunc
+	var err error
+	if conf.Dictionary != "" {
+		var dict []byte
+		if dict, err = ifs.ReadFile(mgr.FS(), conf.Dictionary); err != nil {
+			return nil, fmt.Errorf("failed to read dictionary: %w", err)
+		}
+		dcor, err = strToDictDecompressor(conf.Algorithm, dict)
+	} else {
+		dcor, err = strToDecompressor(conf.Algorithm)
+	}
 	if err != nil {
 		return nil, err
 	}
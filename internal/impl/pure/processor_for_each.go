@@ -3,7 +3,6 @@ package pure
 import (
 	"context"
 	"fmt"
-	"strconv"
 
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
@@ -48,7 +47,7 @@ type forEachProc struct {
 func newForEach(conf []processor.Config, mgr bundle.NewManagement) (*forEachProc, error) {
 	var children []processor.V1
 	for i, pconf := range conf {
-		pMgr := mgr.IntoPath("for_each", strconv.Itoa(i))
+		pMgr := mgr.IntoPath("for_each", bundle.PathSegment(i, pconf.Label))
 		proc, err := pMgr.NewProcessor(pconf)
 		if err != nil {
 			return nil, fmt.Errorf("child processor [%v]: %w", i, err)
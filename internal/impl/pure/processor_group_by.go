@@ -106,7 +106,7 @@ func newGroupBy(conf processor.GroupByConfig, mgr bundle.NewManagement) (process
 		}
 
 		for j, pConf := range gConf.Processors {
-			pMgr := mgr.IntoPath("group_by", strconv.Itoa(i), "processors", strconv.Itoa(j))
+			pMgr := mgr.IntoPath("group_by", strconv.Itoa(i), "processors", bundle.PathSegment(j, pConf.Label))
 			proc, err := pMgr.NewProcessor(pConf)
 			if err != nil {
 				return nil, err
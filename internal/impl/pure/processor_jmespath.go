@@ -33,6 +33,8 @@ the message with the resulting document.`,
 :::note Try out Bloblang
 For better performance and improved capabilities try out native Benthos mapping with the [` + "`mapping`" + ` processor](/docs/components/processors/mapping).
 :::
+
+JMESPath queries can also be executed within Bloblang mappings with the [` + "`jmespath`" + ` method](/docs/guides/bloblang/methods#jmespath), which allows queries to be mixed with native mappings.
 `,
 		Examples: []docs.AnnotatedExample{
 			{
@@ -81,9 +83,9 @@ type jmespathProc struct {
 }
 
 func newJMESPath(conf processor.JMESPathConfig, mgr bundle.NewManagement) (processor.AutoObserved, error) {
-	query, err := jmespath.Compile(conf.Query)
+	query, err := jmespathCompile(conf.Query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to compile JMESPath query: %v", err)
+		return nil, err
 	}
 	j := &jmespathProc{
 		query: query,
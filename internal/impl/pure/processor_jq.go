@@ -4,10 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 
+	lruv2 "github.com/hashicorp/golang-lru/v2"
 	"github.com/itchyny/gojq"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/docs"
@@ -40,7 +46,9 @@ as a structured JSON value or as a raw string using the field ` + "`raw`" + `,
 and the message is replaced with the query result.
 
 Message metadata is also accessible within the query from the variable
-` + "`$metadata`" + `.
+` + "`$metadata`" + `. Further string variables can be declared with the field
+` + "`args`" + `, similar to the ` + "`--arg`" + ` flag of the jq cli, where each
+value is an interpolated string resolved for each message.
 
 This processor uses the [gojq library][gojq], and therefore does not require
 jq to be installed as a dependency. However, this also means there are some
@@ -49,7 +57,12 @@ differences in how these queries are executed versus the jq cli which you can
 
 If the query does not emit any value then the message is filtered, if the query
 returns multiple values then the resulting message will be an array containing
-all values.
+all values, unless the field ` + "`output_multiple`" + ` is set, in which case a
+message is emitted for each value.
+
+Compiled queries are shared between processors with the same query and
+variables, and therefore processors running across multiple pipeline threads
+only compile their query once.
 
 The full query syntax is described in [jq's documentation][jq-docs].
 
@@ -91,6 +104,22 @@ pipeline:
   processors:
     - jq:
         query: '{Cities: .locations | map(select(.state == "WA").name) | sort | join(", ") }'
+`,
+			},
+			{
+				Title: "Multiple Outputs",
+				Summary: `
+Queries that emit multiple values can be used to split a message into several.
+Here we emit a message for each item of an order, where each message is tagged
+with a customer ID taken from the metadata of the original message:`,
+				Config: `
+pipeline:
+  processors:
+    - jq:
+        query: '.items[] | {customer: $customer, item: .}'
+        output_multiple: true
+        args:
+          customer: ${! @customer_id }
 `,
 			},
 		},
@@ -98,6 +127,13 @@ pipeline:
 			docs.FieldString("query", "The jq query to filter and transform messages with."),
 			docs.FieldBool("raw", "Whether to process the input as a raw string instead of as JSON.").Advanced(),
 			docs.FieldBool("output_raw", "Whether to output raw text (unquoted) instead of JSON strings when the emitted values are string types.").Advanced(),
+			docs.FieldBool("output_multiple", "Whether to emit a message for each value returned by the query rather than a single message containing an array of all values.").Advanced().AtVersion("4.24.0"),
+			docs.FieldString(
+				"args", "A map of variable names to values that are accessible from within the query, e.g. the key `foo` can be referenced with `$foo`. Values are resolved as strings for each message.",
+				map[string]string{
+					"tenant": `${! @tenant }`,
+				},
+			).IsInterpolated().Map().Advanced().AtVersion("4.24.0"),
 		).ChildDefaultAndTypesFromStruct(processor.NewJQConfig()),
 	})
 	if err != nil {
@@ -105,34 +141,93 @@ pipeline:
 	}
 }
 
-var jqCompileOptions = []gojq.CompilerOption{
-	gojq.WithVariables([]string{"$metadata"}),
-}
+// jqCacheSize is the maximum number of compiled jq queries that are kept in
+// memory.
+const jqCacheSize = 1024
 
-type jqProc struct {
-	inRaw  bool
-	outRaw bool
-	log    log.Modular
-	code   *gojq.Code
+var (
+	jqArgNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	jqCodeCache     *lruv2.Cache[string, *gojq.Code]
+)
+
+func init() {
+	var err error
+	if jqCodeCache, err = lruv2.New[string, *gojq.Code](jqCacheSize); err != nil {
+		panic(err)
+	}
 }
 
-func newJQ(conf processor.JQConfig, mgr bundle.NewManagement) (*jqProc, error) {
-	j := &jqProc{
-		inRaw:  conf.Raw,
-		outRaw: conf.OutputRaw,
-		log:    mgr.Logger(),
+// jqCompile returns a compiled jq query with the provided variables declared,
+// reusing a previously compiled query when the same query and variables have
+// been seen before.
+func jqCompile(queryStr string, variables []string) (*gojq.Code, error) {
+	key := queryStr + "\x00" + strings.Join(variables, ",")
+	if code, ok := jqCodeCache.Get(key); ok {
+		return code, nil
 	}
 
-	query, err := gojq.Parse(conf.Query)
+	query, err := gojq.Parse(queryStr)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing jq query: %w", err)
 	}
 
-	j.code, err = gojq.Compile(query, jqCompileOptions...)
+	code, err := gojq.Compile(query, gojq.WithVariables(variables))
 	if err != nil {
 		return nil, fmt.Errorf("error compiling jq query: %w", err)
 	}
 
+	jqCodeCache.Add(key, code)
+	return code, nil
+}
+
+type jqArg struct {
+	name  string
+	value *field.Expression
+}
+
+type jqProc struct {
+	inRaw       bool
+	outRaw      bool
+	outMultiple bool
+	args        []jqArg
+	log         log.Modular
+	code        *gojq.Code
+}
+
+func newJQ(conf processor.JQConfig, mgr bundle.NewManagement) (*jqProc, error) {
+	j := &jqProc{
+		inRaw:       conf.Raw,
+		outRaw:      conf.OutputRaw,
+		outMultiple: conf.OutputMultiple,
+		log:         mgr.Logger(),
+	}
+
+	argNames := make([]string, 0, len(conf.Args))
+	for k := range conf.Args {
+		argNames = append(argNames, k)
+	}
+	sort.Strings(argNames)
+
+	variables := []string{"$metadata"}
+	for _, k := range argNames {
+		if !jqArgNameRegexp.MatchString(k) {
+			return nil, fmt.Errorf("arg name '%v' is not a valid jq variable name", k)
+		}
+		if k == "metadata" {
+			return nil, errors.New("arg name 'metadata' is reserved")
+		}
+		value, err := mgr.BloblEnvironment().NewField(conf.Args[k])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse arg '%v' expression: %w", k, err)
+		}
+		j.args = append(j.args, jqArg{name: k, value: value})
+		variables = append(variables, "$"+k)
+	}
+
+	var err error
+	if j.code, err = jqCompile(conf.Query, variables); err != nil {
+		return nil, err
+	}
 	return j, nil
 }
 
@@ -156,14 +251,14 @@ func (j *jqProc) getPartValue(part *message.Part, raw bool) (obj any, err error)
 	return obj, nil
 }
 
-func safeQuery(input any, meta map[string]any, c *gojq.Code) (emitted []any, err error) {
+func safeQuery(input any, vars []any, c *gojq.Code) (emitted []any, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("jq panic: %v", r)
 		}
 	}()
 
-	iter := c.Run(input, meta)
+	iter := c.Run(input, vars...)
 	for {
 		out, ok := iter.Next()
 		if !ok {
@@ -183,14 +278,26 @@ func (j *jqProc) Process(ctx context.Context, msg *message.Part) ([]*message.Par
 	if err != nil {
 		return nil, err
 	}
-	metadata := j.getPartMetadata(msg)
+	vars := []any{j.getPartMetadata(msg)}
+	for _, arg := range j.args {
+		v, err := arg.value.String(0, message.Batch{msg})
+		if err != nil {
+			j.log.Debugf("Failed to resolve arg '%v': %v", arg.name, err)
+			return nil, fmt.Errorf("arg '%v' interpolation error: %w", arg.name, err)
+		}
+		vars = append(vars, v)
+	}
 
-	emitted, err := safeQuery(in, metadata, j.code)
+	emitted, err := safeQuery(in, vars, j.code)
 	if err != nil {
 		j.log.Debugf(err.Error())
 		return nil, err
 	}
 
+	if j.outMultiple {
+		return j.splitEmitted(msg, emitted)
+	}
+
 	if j.outRaw {
 		raw, err := j.marshalRaw(emitted)
 		if err != nil {
@@ -218,6 +325,33 @@ func (j *jqProc) Process(ctx context.Context, msg *message.Part) ([]*message.Par
 	return []*message.Part{msg}, nil
 }
 
+// splitEmitted returns a message for each emitted value, where the first value
+// is written to the original message and subsequent values to copies of it.
+func (j *jqProc) splitEmitted(msg *message.Part, emitted []any) ([]*message.Part, error) {
+	if len(emitted) == 0 {
+		return nil, nil
+	}
+	parts := make([]*message.Part, 0, len(emitted))
+	for i, v := range emitted {
+		part := msg
+		if i > 0 {
+			part = msg.ShallowCopy()
+		}
+		if j.outRaw {
+			raw, err := j.marshalRaw([]any{v})
+			if err != nil {
+				j.log.Debugf("Failed to marshal raw text: %s", err)
+				return nil, err
+			}
+			part.SetBytes(raw)
+		} else {
+			part.SetStructuredMut(v)
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
 func (*jqProc) Close(ctx context.Context) error {
 	return nil
 }
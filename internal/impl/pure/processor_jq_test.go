@@ -1,4 +1,4 @@
-package pure_test
+package pure
 
 import (
 	"context"
@@ -279,3 +279,98 @@ func TestJQ_OutputRaw(t *testing.T) {
 		})
 	}
 }
+
+func TestJQOutputMultiple(t *testing.T) {
+	conf := processor.NewConfig()
+	conf.Type = "jq"
+	conf.JQ.Query = `.items[]`
+	conf.JQ.OutputMultiple = true
+
+	jSet, err := mock.NewManager().NewProcessor(conf)
+	require.NoError(t, err)
+
+	msgIn := message.QuickBatch([][]byte{
+		[]byte(`{"items":[{"id":1},"two"]}`),
+		[]byte(`{"items":[]}`),
+		[]byte(`{"items":[3]}`),
+	})
+	msgIn.Get(0).MetaSetMut("foo", "bar")
+
+	msgs, res := jSet.ProcessBatch(context.Background(), msgIn)
+	require.Nil(t, res)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, []string{`{"id":1}`, `"two"`, `3`}, jqBatchStrings(msgs[0]))
+	assert.Equal(t, "bar", msgs[0].Get(0).MetaGetStr("foo"))
+	assert.Equal(t, "bar", msgs[0].Get(1).MetaGetStr("foo"))
+
+	conf.JQ.OutputRaw = true
+	jSet, err = mock.NewManager().NewProcessor(conf)
+	require.NoError(t, err)
+
+	msgs, res = jSet.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`{"items":["one","two"]}`),
+	}))
+	require.Nil(t, res)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, []string{`one`, `two`}, jqBatchStrings(msgs[0]))
+}
+
+func TestJQArgs(t *testing.T) {
+	conf := processor.NewConfig()
+	conf.Type = "jq"
+	conf.JQ.Query = `{id: .id, tenant: $tenant, topic: $metadata.topic}`
+	conf.JQ.Args = map[string]string{
+		"tenant": `${! @tenant }-${! json("region") }`,
+	}
+
+	jSet, err := mock.NewManager().NewProcessor(conf)
+	require.NoError(t, err)
+
+	msgIn := message.QuickBatch([][]byte{[]byte(`{"id":"a","region":"eu"}`)})
+	msgIn.Get(0).MetaSetMut("tenant", "acme")
+	msgIn.Get(0).MetaSetMut("topic", "orders")
+
+	msgs, res := jSet.ProcessBatch(context.Background(), msgIn)
+	require.Nil(t, res)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, []string{`{"id":"a","tenant":"acme-eu","topic":"orders"}`}, jqBatchStrings(msgs[0]))
+}
+
+func TestJQArgsBadName(t *testing.T) {
+	for _, name := range []string{"metadata", "foo-bar", "1foo"} {
+		conf := processor.NewConfig()
+		conf.Type = "jq"
+		conf.JQ.Query = `.`
+		conf.JQ.Args = map[string]string{name: "foo"}
+
+		_, err := mock.NewManager().NewProcessor(conf)
+		require.Error(t, err, name)
+	}
+}
+
+func TestJQSharedCode(t *testing.T) {
+	conf := processor.NewJQConfig()
+	conf.Query = `.foo | $bar`
+	conf.Args = map[string]string{"bar": "baz"}
+
+	a, err := newJQ(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	b, err := newJQ(conf, mock.NewManager())
+	require.NoError(t, err)
+	assert.Same(t, a.code, b.code)
+
+	conf.Args = map[string]string{"qux": "baz"}
+	conf.Query = `.foo | $qux`
+	c, err := newJQ(conf, mock.NewManager())
+	require.NoError(t, err)
+	assert.NotSame(t, a.code, c.code)
+}
+
+func jqBatchStrings(b message.Batch) []string {
+	var strs []string
+	for _, p := range b {
+		strs = append(strs, string(p.AsBytes()))
+	}
+	return strs
+}
@@ -3,6 +3,7 @@ package pure
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"sort"
 	"strings"
 
@@ -47,6 +48,21 @@ pipeline:
           root.age = this.user.age
           root.kafka_topic = meta("kafka_topic")
 ` + "```" + `
+
+The mapping is executed for each message and can produce any structured value for each field, including objects and arrays. If the mapping results in ` + "`deleted()`" + ` then the log is printed without additional fields.
+
+### Sampling
+
+When logging messages on high volume paths it can be useful to only log a proportion of messages, which can be done by setting the field ` + "[`sample_rate`](#sample_rate)" + ` to a value between 0 and 1:
+
+` + "```yaml" + `
+pipeline:
+  processors:
+    - log:
+        level: INFO
+        message: 'processing order ${! this.id }'
+        sample_rate: 0.01
+` + "```" + `
 `,
 		Config: docs.FieldComponent().WithChildren(
 			docs.FieldString("level", "The log level to use.").HasOptions("FATAL", "ERROR", "WARN", "INFO", "DEBUG", "TRACE", "ALL").LinterFunc(nil),
@@ -59,6 +75,8 @@ root.age = this.user.age.number()
 root.kafka_topic = meta("kafka_topic")`,
 			).AtVersion("3.40.0").IsBloblang(),
 			docs.FieldString("message", "The message to print.").IsInterpolated(),
+			docs.FieldFloat("sample_rate", "The proportion of messages to print a log event for, where `1` prints a log event for every message and `0.1` prints a log event for roughly one in ten messages. Messages are selected at random.").AtVersion("4.24.0").Advanced().
+				LinterBlobl(`root = if this <= 0 || this > 1 { [ "sample_rate must be greater than 0 and less than or equal to 1" ] }`),
 		).ChildDefaultAndTypesFromStruct(processor.NewLogConfig()),
 	})
 	if err != nil {
@@ -73,6 +91,7 @@ type logProcessor struct {
 	fields        map[string]*field.Expression
 	printFn       func(logger log.Modular, msg string)
 	fieldsMapping *mapping.Executor
+	sampleRate    float64
 }
 
 func newLogProcessor(conf processor.Config, mgr bundle.NewManagement, logger log.Modular) (processor.AutoObservedBatched, error) {
@@ -80,11 +99,15 @@ func newLogProcessor(conf processor.Config, mgr bundle.NewManagement, logger log
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse message expression: %v", err)
 	}
+	if conf.Log.SampleRate <= 0 || conf.Log.SampleRate > 1 {
+		return nil, fmt.Errorf("sample_rate must be greater than 0 and less than or equal to 1, got %v", conf.Log.SampleRate)
+	}
 	l := &logProcessor{
-		logger:  logger,
-		level:   conf.Log.Level,
-		fields:  map[string]*field.Expression{},
-		message: message,
+		logger:     logger,
+		level:      conf.Log.Level,
+		fields:     map[string]*field.Expression{},
+		message:    message,
+		sampleRate: conf.Log.SampleRate,
 	}
 	if len(conf.Log.Fields) > 0 {
 		for k, v := range conf.Log.Fields {
@@ -131,8 +154,42 @@ func (l *logProcessor) levelToLogFn(level string) (func(logger log.Modular, msg
 	return nil, fmt.Errorf("log level not recognised: %v", level)
 }
 
+// withMappedFields adds the fields of a fields mapping result to a logger,
+// where a deleted result adds no fields.
+func withMappedFields(logger log.Modular, fieldsMsg *message.Part) (log.Modular, error) {
+	if fieldsMsg == nil {
+		return logger, nil
+	}
+
+	v, err := fieldsMsg.AsStructured()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract fields object: %w", err)
+	}
+
+	vObj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("fields mapping yielded a non-object result: %T", v)
+	}
+
+	keys := make([]string, 0, len(vObj))
+	for k := range vObj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	args := make([]any, 0, len(vObj)*2)
+	for _, k := range keys {
+		args = append(args, k, vObj[k])
+	}
+	return logger.With(args...), nil
+}
+
 func (l *logProcessor) ProcessBatch(ctx *processor.BatchProcContext, msg message.Batch) ([]message.Batch, error) {
 	_ = msg.Iter(func(i int, _ *message.Part) error {
+		if l.sampleRate < 1 && rand.Float64() >= l.sampleRate {
+			return nil
+		}
+
 		targetLog := l.logger
 		if l.fieldsMapping != nil {
 			fieldsMsg, err := l.fieldsMapping.MapPart(i, msg)
@@ -140,30 +197,10 @@ func (l *logProcessor) ProcessBatch(ctx *processor.BatchProcContext, msg message
 				l.logger.Errorf("Failed to execute fields mapping: %v", err)
 				return nil
 			}
-
-			v, err := fieldsMsg.AsStructured()
-			if err != nil {
-				l.logger.Errorf("Failed to extract fields object: %v", err)
-				return nil
-			}
-
-			vObj, ok := v.(map[string]any)
-			if !ok {
-				l.logger.Errorf("Fields mapping yielded a non-object result: %T", v)
+			if targetLog, err = withMappedFields(targetLog, fieldsMsg); err != nil {
+				l.logger.Errorf("Fields mapping error: %v", err)
 				return nil
 			}
-
-			keys := make([]string, 0, len(vObj))
-			for k := range vObj {
-				keys = append(keys, k)
-			}
-			sort.Strings(keys)
-
-			args := make([]any, 0, len(vObj)*2)
-			for _, k := range keys {
-				args = append(args, k, vObj[k])
-			}
-			targetLog = targetLog.With(args...)
 		}
 
 		if len(l.fields) > 0 {
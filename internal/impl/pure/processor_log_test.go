@@ -236,3 +236,67 @@ root.is_cool = this.is_cool`
 		"static", "static value",
 	}, logMock.mappingFields)
 }
+
+func TestLogWithFieldsMappingStructured(t *testing.T) {
+	conf := processor.NewConfig()
+	conf.Type = "log"
+	conf.Log.Message = "hello world"
+	conf.Log.FieldsMapping = `root = if this.skip.or(false) { deleted() } else { {"user": this.user, "tags": this.tags} }`
+
+	logMock := &mockLog{}
+
+	mgr := mock.NewManager()
+	mgr.L = logMock
+
+	l, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	input := message.QuickBatch([][]byte{
+		[]byte(`{"user":{"name":"bob"},"tags":["a","b"]}`),
+		[]byte(`{"skip":true}`),
+	})
+	_, res := l.ProcessBatch(context.Background(), input)
+	require.Nil(t, res)
+
+	assert.Equal(t, []string{"hello world", "hello world"}, logMock.infos)
+	assert.Equal(t, []any{
+		"tags", []any{"a", "b"},
+		"user", map[string]any{"name": "bob"},
+	}, logMock.mappingFields)
+	assert.Empty(t, logMock.errors)
+}
+
+func TestLogSampleRate(t *testing.T) {
+	conf := processor.NewConfig()
+	conf.Type = "log"
+	conf.Log.Message = "hello world"
+	conf.Log.SampleRate = 0.5
+
+	logMock := &mockLog{}
+
+	mgr := mock.NewManager()
+	mgr.L = logMock
+
+	l, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	for i := 0; i < 1000; i++ {
+		_, res := l.ProcessBatch(context.Background(), message.QuickBatch([][]byte{[]byte(`hello`)}))
+		require.Nil(t, res)
+	}
+
+	assert.Greater(t, len(logMock.infos), 350)
+	assert.Less(t, len(logMock.infos), 650)
+}
+
+func TestLogBadSampleRate(t *testing.T) {
+	for _, rate := range []float64{0, -0.5, 1.5} {
+		conf := processor.NewConfig()
+		conf.Type = "log"
+		conf.Log.SampleRate = rate
+
+		_, err := mock.NewManager().NewProcessor(conf)
+		require.Error(t, err, rate)
+		assert.Contains(t, err.Error(), "sample_rate must be greater than 0", rate)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"strings"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
@@ -36,6 +38,7 @@ Custom metrics such as these are emitted along with Benthos internal metrics, wh
 				"counter_by",
 				"gauge",
 				"timing",
+				"histogram",
 			),
 			docs.FieldString("name", "The name of the metric to create, this must be unique across all Benthos components otherwise it will overwrite those other metrics."),
 			docs.FieldString(
@@ -45,7 +48,18 @@ Custom metrics such as these are emitted along with Benthos internal metrics, wh
 					"topic": "${! meta(\"kafka_topic\") }",
 				},
 			).IsInterpolated().Map(),
+			docs.FieldBloblang(
+				"labels_mapping", "An optional [Bloblang mapping](/docs/guides/bloblang/about) executed for each message that computes label values. Each assignment to a field of `root` adds a label of that name, and the value assigned is converted into a string. Fields that are not assigned for a given message result in an empty label value.",
+				`root.status = this.status_code.string()`,
+				`root.region = meta("region").or("unknown")`,
+			).AtVersion("4.24.0"),
 			docs.FieldString("value", "For some metric types specifies a value to set, increment. Certain metrics exporters such as Prometheus support floating point values, but those that do not will cast a floating point value into an integer.").IsInterpolated(),
+			docs.FieldFloat("buckets", "The upper bounds of the buckets of a `histogram` metric. When empty the default buckets of the metrics exporter are used.", []float64{0.1, 0.5, 1, 5, 10}).Array().AtVersion("4.24.0"),
+			docs.FieldBloblang(
+				"check", "An optional [Bloblang query](/docs/guides/bloblang/about) that should return a boolean value indicating whether the metric should be emitted for a message. If left empty the metric is emitted for all messages.",
+				`this.type == "purchase"`,
+				`meta("kafka_topic") != "audit"`,
+			).AtVersion("4.24.0"),
 		).ChildDefaultAndTypesFromStruct(processor.NewMetricConfig()),
 		Examples: []docs.AnnotatedExample{
 			{
@@ -89,6 +103,26 @@ pipeline:
 metrics:
   mapping: 'if this != "FooSize" { deleted() }'
   prometheus: {}
+`,
+			},
+			{
+				Title:   "Histogram",
+				Summary: "In this example we emit a histogram metric called `OrderValue` for each purchase event, where the buckets are set explicitly and the labels are computed with a Bloblang mapping. Events of other types are ignored with a `check`.",
+				Config: `
+pipeline:
+  processors:
+    - metric:
+        name: OrderValue
+        type: histogram
+        check: this.type == "purchase"
+        value: ${! json("order.total") }
+        buckets: [ 10, 50, 100, 500, 1000 ]
+        labels_mapping: |
+          root.currency = this.order.currency.uppercase()
+          root.tier = if this.customer.orders > 10 { "loyal" } else { "new" }
+
+metrics:
+  prometheus: {}
 `,
 			},
 		},
@@ -136,7 +170,13 @@ pipeline:
 
 ### ` + "`timing`" + `
 
-Equivalent to ` + "`gauge`" + ` where instead the metric is a timing. It is recommended that timing values are recorded in nanoseconds in order to be consistent with standard Benthos timing metrics, as in some cases these values are automatically converted into other units such as when exporting timings as histograms with Prometheus metrics.`,
+Equivalent to ` + "`gauge`" + ` where instead the metric is a timing. It is recommended that timing values are recorded in nanoseconds in order to be consistent with standard Benthos timing metrics, as in some cases these values are automatically converted into other units such as when exporting timings as histograms with Prometheus metrics.
+
+### ` + "`histogram`" + `
+
+If the contents of ` + "`value`" + ` can be parsed as a number then it is observed by a histogram with the buckets configured with the field ` + "`buckets`" + `. Unlike timings the value is not converted into other units.
+
+Histograms are currently supported by the ` + "`prometheus`" + ` metrics exporter, other exporters emit the last observed value as a gauge instead.`,
 	})
 	if err != nil {
 		panic(err)
@@ -148,8 +188,11 @@ type metricProcessor struct {
 	log   log.Modular
 	stats metrics.Type
 
-	value  *field.Expression
-	labels labels
+	value         *field.Expression
+	labels        labels
+	labelsMapping *mapping.Executor
+	labelNames    []string
+	check         *mapping.Executor
 
 	mCounter metrics.StatCounter
 	mGauge   metrics.StatGauge
@@ -159,6 +202,8 @@ type metricProcessor struct {
 	mGaugeVec   metrics.StatGaugeVec
 	mTimerVec   metrics.StatTimerVec
 
+	mHistogramVec metrics.StatHistogramVec
+
 	handler func(string, int, message.Batch) error
 }
 
@@ -194,6 +239,66 @@ func (l labels) values(index int, msg message.Batch) ([]string, error) {
 	return values, nil
 }
 
+// labelsMappingNames returns the label names of a labels mapping, which are
+// the fields of root that it assigns.
+func labelsMappingNames(exec *mapping.Executor) ([]string, error) {
+	seen := map[string]struct{}{}
+	var names []string
+	for _, target := range exec.AssignmentTargets() {
+		switch target.Type {
+		case mapping.TargetVariable:
+			continue
+		case mapping.TargetValue:
+			if len(target.Path) == 1 {
+				if _, exists := seen[target.Path[0]]; !exists {
+					seen[target.Path[0]] = struct{}{}
+					names = append(names, target.Path[0])
+				}
+				continue
+			}
+		}
+		return nil, errors.New("labels mapping must only assign to fields of root")
+	}
+	if len(names) == 0 {
+		return nil, errors.New("labels mapping must assign at least one label")
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *metricProcessor) labelValues(index int, msg message.Batch) ([]string, error) {
+	values, err := m.labels.values(index, msg)
+	if err != nil {
+		return nil, err
+	}
+	if m.labelsMapping == nil {
+		return values, nil
+	}
+
+	p, err := m.labelsMapping.MapPart(index, msg)
+	if err != nil {
+		return nil, fmt.Errorf("labels mapping error: %w", err)
+	}
+	var obj map[string]any
+	if p != nil {
+		v, err := p.AsStructured()
+		if err != nil {
+			return nil, fmt.Errorf("labels mapping error: %w", err)
+		}
+		if obj, _ = v.(map[string]any); obj == nil {
+			return nil, fmt.Errorf("labels mapping error: %w", query.NewTypeError(v, query.ValueObject))
+		}
+	}
+	for _, n := range m.labelNames[len(m.labels):] {
+		var vStr string
+		if v, exists := obj[n]; exists && v != nil {
+			vStr = query.IToString(v)
+		}
+		values = append(values, vStr)
+	}
+	return values, nil
+}
+
 func newMetricProcessor(conf processor.Config, mgr bundle.NewManagement, log log.Modular, stats metrics.Type) (processor.V1, error) {
 	value, err := mgr.BloblEnvironment().NewField(conf.Metric.Value)
 	if err != nil {
@@ -228,36 +333,62 @@ func newMetricProcessor(conf processor.Config, mgr bundle.NewManagement, log log
 			value: v,
 		})
 	}
+	m.labelNames = m.labels.names()
+
+	if conf.Metric.LabelsMapping != "" {
+		if m.labelsMapping, err = mgr.BloblEnvironment().NewMapping(conf.Metric.LabelsMapping); err != nil {
+			return nil, fmt.Errorf("failed to parse labels mapping: %w", err)
+		}
+		mappingNames, err := labelsMappingNames(m.labelsMapping)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range mappingNames {
+			if _, exists := conf.Metric.Labels[n]; exists {
+				return nil, fmt.Errorf("label '%v' is set by both labels and labels_mapping", n)
+			}
+		}
+		m.labelNames = append(m.labelNames, mappingNames...)
+	}
+
+	if conf.Metric.Check != "" {
+		if m.check, err = mgr.BloblEnvironment().NewMapping(conf.Metric.Check); err != nil {
+			return nil, fmt.Errorf("failed to parse check: %w", err)
+		}
+	}
 
 	switch strings.ToLower(conf.Metric.Type) {
 	case "counter":
-		if len(m.labels) > 0 {
-			m.mCounterVec = stats.GetCounterVec(name, m.labels.names()...)
+		if len(m.labelNames) > 0 {
+			m.mCounterVec = stats.GetCounterVec(name, m.labelNames...)
 		} else {
 			m.mCounter = stats.GetCounter(name)
 		}
 		m.handler = m.handleCounter
 	case "counter_by":
-		if len(m.labels) > 0 {
-			m.mCounterVec = stats.GetCounterVec(name, m.labels.names()...)
+		if len(m.labelNames) > 0 {
+			m.mCounterVec = stats.GetCounterVec(name, m.labelNames...)
 		} else {
 			m.mCounter = stats.GetCounter(name)
 		}
 		m.handler = m.handleCounterBy
 	case "gauge":
-		if len(m.labels) > 0 {
-			m.mGaugeVec = stats.GetGaugeVec(name, m.labels.names()...)
+		if len(m.labelNames) > 0 {
+			m.mGaugeVec = stats.GetGaugeVec(name, m.labelNames...)
 		} else {
 			m.mGauge = stats.GetGauge(name)
 		}
 		m.handler = m.handleGauge
 	case "timing":
-		if len(m.labels) > 0 {
-			m.mTimerVec = stats.GetTimerVec(name, m.labels.names()...)
+		if len(m.labelNames) > 0 {
+			m.mTimerVec = stats.GetTimerVec(name, m.labelNames...)
 		} else {
 			m.mTimer = stats.GetTimer(name)
 		}
 		m.handler = m.handleTimer
+	case "histogram":
+		m.mHistogramVec = metrics.GetHistogramVec(stats, name, conf.Metric.Buckets, m.labelNames...)
+		m.handler = m.handleHistogram
 	default:
 		return nil, fmt.Errorf("metric type unrecognised: %v", conf.Metric.Type)
 	}
@@ -266,8 +397,8 @@ func newMetricProcessor(conf processor.Config, mgr bundle.NewManagement, log log
 }
 
 func (m *metricProcessor) handleCounter(val string, index int, msg message.Batch) error {
-	if len(m.labels) > 0 {
-		labelValues, err := m.labels.values(index, msg)
+	if len(m.labelNames) > 0 {
+		labelValues, err := m.labelValues(index, msg)
 		if err != nil {
 			return err
 		}
@@ -297,8 +428,8 @@ func withNumberStr(val string, ifn func(i int64) error, ffn func(f float64) erro
 }
 
 func (m *metricProcessor) handleCounterBy(val string, index int, msg message.Batch) error {
-	if len(m.labels) > 0 {
-		labelValues, err := m.labels.values(index, msg)
+	if len(m.labelNames) > 0 {
+		labelValues, err := m.labelValues(index, msg)
 		if err != nil {
 			return err
 		}
@@ -320,8 +451,8 @@ func (m *metricProcessor) handleCounterBy(val string, index int, msg message.Bat
 }
 
 func (m *metricProcessor) handleGauge(val string, index int, msg message.Batch) error {
-	if len(m.labels) > 0 {
-		labelValues, err := m.labels.values(index, msg)
+	if len(m.labelNames) > 0 {
+		labelValues, err := m.labelValues(index, msg)
 		if err != nil {
 			return err
 		}
@@ -350,8 +481,8 @@ func (m *metricProcessor) handleTimer(val string, index int, msg message.Batch)
 	if i < 0 {
 		return errors.New("value is negative")
 	}
-	if len(m.labels) > 0 {
-		labelValues, err := m.labels.values(index, msg)
+	if len(m.labelNames) > 0 {
+		labelValues, err := m.labelValues(index, msg)
 		if err != nil {
 			return err
 		}
@@ -362,8 +493,31 @@ func (m *metricProcessor) handleTimer(val string, index int, msg message.Batch)
 	return nil
 }
 
+func (m *metricProcessor) handleHistogram(val string, index int, msg message.Batch) error {
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return err
+	}
+	labelValues, err := m.labelValues(index, msg)
+	if err != nil {
+		return err
+	}
+	m.mHistogramVec.With(labelValues...).Observe(f)
+	return nil
+}
+
 func (m *metricProcessor) ProcessBatch(ctx context.Context, msg message.Batch) ([]message.Batch, error) {
 	_ = msg.Iter(func(i int, p *message.Part) error {
+		if m.check != nil {
+			test, err := m.check.QueryPart(i, msg)
+			if err != nil {
+				m.log.Errorf("Check error: %v", err)
+				return nil
+			}
+			if !test {
+				return nil
+			}
+		}
 		value, err := m.value.String(i, msg)
 		if err != nil {
 			m.log.Errorf("Value interpolation error: %v", err)
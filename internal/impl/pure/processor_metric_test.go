@@ -227,3 +227,107 @@ func TestMetricTiming(t *testing.T) {
 
 	assert.Equal(t, expTimingAvgs, actTimingAvgs)
 }
+
+func TestMetricLabelsMappingAndCheck(t *testing.T) {
+	conf := processor.NewConfig()
+	conf.Type = "metric"
+	conf.Metric.Type = "counter_by"
+	conf.Metric.Name = "foo.bar"
+	conf.Metric.Value = `${! json("count") }`
+	conf.Metric.Labels = map[string]string{
+		"topic": `${! meta("topic") }`,
+	}
+	conf.Metric.LabelsMapping = `
+let code = this.code.or(0)
+root.status = $code.string()
+root.tier = this.tier
+`
+	conf.Metric.Check = `this.type == "purchase"`
+
+	mockMetrics := metrics.NewLocal()
+
+	mgr := mock.NewManager()
+	mgr.M = mockMetrics
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	batch := message.QuickBatch([][]byte{
+		[]byte(`{"type":"purchase","count":2,"code":200,"tier":"gold"}`),
+		[]byte(`{"type":"purchase","count":3,"code":200,"tier":"gold"}`),
+		[]byte(`{"type":"purchase","count":1}`),
+		[]byte(`{"type":"refund","count":10,"code":200,"tier":"gold"}`),
+	})
+	for _, p := range batch {
+		p.MetaSetMut("topic", "orders")
+	}
+
+	msg, res := proc.ProcessBatch(context.Background(), batch)
+	assert.Len(t, msg, 1)
+	assert.Nil(t, res)
+
+	assert.Equal(t, map[string]int64{
+		`foo.bar{status="200",tier="gold",topic="orders"}`: 5,
+		`foo.bar{status="0",tier="",topic="orders"}`:       1,
+	}, mockMetrics.FlushCounters())
+}
+
+func TestMetricHistogram(t *testing.T) {
+	conf := processor.NewConfig()
+	conf.Type = "metric"
+	conf.Metric.Type = "histogram"
+	conf.Metric.Name = "foo.bar"
+	conf.Metric.Value = `${! json("foo.bar") }`
+	conf.Metric.Buckets = []float64{1, 5, 10}
+	conf.Metric.LabelsMapping = `root.type = this.type`
+
+	mockMetrics := metrics.NewLocal()
+
+	mgr := mock.NewManager()
+	mgr.M = mockMetrics
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	msg, res := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`{"type":"a","foo":{"bar":2.5}}`),
+		[]byte(`{"type":"a","foo":{"bar":"nope"}}`),
+		[]byte(`{"type":"b","foo":{"bar":7}}`),
+	}))
+	assert.Len(t, msg, 1)
+	assert.Nil(t, res)
+
+	// The local metrics exporter does not support histograms and therefore
+	// emits the last observed value as a gauge.
+	assert.Equal(t, map[string]int64{
+		`foo.bar{type="a"}`: 2,
+		`foo.bar{type="b"}`: 7,
+	}, mockMetrics.FlushCounters())
+}
+
+func TestMetricLabelsMappingBad(t *testing.T) {
+	for _, test := range []struct {
+		mapping     string
+		labels      map[string]string
+		errContains string
+	}{
+		{mapping: `root = this`, errContains: "must only assign to fields of root"},
+		{mapping: `root.foo.bar = this`, errContains: "must only assign to fields of root"},
+		{mapping: `meta foo = this`, errContains: "must only assign to fields of root"},
+		{mapping: `let foo = this`, errContains: "must assign at least one label"},
+		{mapping: `root.foo = this`, labels: map[string]string{"foo": "bar"}, errContains: "set by both labels and labels_mapping"},
+	} {
+		conf := processor.NewConfig()
+		conf.Type = "metric"
+		conf.Metric.Type = "counter"
+		conf.Metric.Name = "foo.bar"
+		conf.Metric.LabelsMapping = test.mapping
+		if test.labels != nil {
+			conf.Metric.Labels = test.labels
+		}
+
+		_, err := mock.NewManager().NewProcessor(conf)
+		require.Error(t, err, test.mapping)
+		assert.Contains(t, err.Error(), test.errContains, test.mapping)
+	}
+}
@@ -2,7 +2,6 @@ package pure
 
 import (
 	"context"
-	"strconv"
 	"sync"
 
 	"github.com/benthosdev/benthos/v4/internal/bundle"
@@ -28,6 +27,8 @@ A processor that applies a list of child processors to messages of a batch as th
 		Description: `
 The field ` + "`cap`" + `, if greater than zero, caps the maximum number of parallel processing threads.
 
+For isolating the failures of individual messages, processing timeouts, or emitting messages in the order that they finish processing, use the ` + "[`parallel_map`](/docs/components/processors/parallel_map)" + ` processor.
+
 The functionality of this processor depends on being applied across messages that are batched. You can find out more about batching [in this doc](/docs/configuration/batching).`,
 		Config: docs.FieldComponent().WithChildren(
 			docs.FieldInt("cap", "The maximum number of messages to have processing at a given time."),
@@ -47,7 +48,7 @@ type parallelProc struct {
 func newParallel(conf processor.ParallelConfig, mgr bundle.NewManagement) (processor.AutoObservedBatched, error) {
 	var children []processor.V1
 	for i, pconf := range conf.Processors {
-		pMgr := mgr.IntoPath("parallel", strconv.Itoa(i))
+		pMgr := mgr.IntoPath("parallel", bundle.PathSegment(i, pconf.Label))
 		proc, err := pMgr.NewProcessor(pconf)
 		if err != nil {
 			return nil, err
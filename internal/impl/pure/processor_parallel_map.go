@@ -0,0 +1,203 @@
+package pure
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	pmpFieldProcessors    = "processors"
+	pmpFieldWorkers       = "workers"
+	pmpFieldPreserveOrder = "preserve_order"
+	pmpFieldTimeout       = "timeout"
+)
+
+func parallelMapProcSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Composition").
+		Version("4.24.0").
+		Summary("Applies a list of child processors to each message of a batch as though they were each a batch of one message, where messages are processed in parallel by a bounded pool of workers.").
+		Description(`
+This processor is similar to the `+"[`parallel`](/docs/components/processors/parallel)"+` processor, but each message of a batch is processed in isolation: when the child processors fail, return an error or exceed the `+"`timeout`"+` for a message then that message alone is marked as failed with its original contents, and can be handled with [error handling patterns](/docs/configuration/error_handling), whilst the remaining messages of the batch are unaffected.
+
+When `+"`preserve_order`"+` is set to `+"`false`"+` the resulting messages are emitted in the order in which they finished processing, which allows messages that are slow to process to be placed at the end of the batch.
+
+When a message exceeds the `+"`timeout`"+` the context provided to its child processors is cancelled and a worker is freed for the next message, processors that do not respect the cancellation of their context may therefore continue executing in the background until they finish.
+
+The functionality of this processor depends on being applied across messages that are batched. You can find out more about batching [in this doc](/docs/configuration/batching).`).
+		Fields(
+			service.NewProcessorListField(pmpFieldProcessors).
+				Description("A list of child processors to apply to each message."),
+			service.NewIntField(pmpFieldWorkers).
+				Description("The maximum number of messages to process in parallel. When set to zero all messages of a batch are processed in parallel.").
+				Default(0),
+			service.NewBoolField(pmpFieldPreserveOrder).
+				Description("Whether the resulting messages should retain the order of the original batch, otherwise messages are emitted in the order in which they finished processing.").
+				Default(true),
+			service.NewDurationField(pmpFieldTimeout).
+				Description("An optional maximum period of time to spend processing each message, after which the message is marked as failed.").
+				Example("10s").
+				Optional(),
+		).
+		Example("Bounded Enrichment", "Enrich the messages of a batch with HTTP requests, at most five at a time, where requests that take longer than five seconds are abandoned and the message is marked as failed.", `
+pipeline:
+  processors:
+    - parallel_map:
+        workers: 5
+        timeout: 5s
+        processors:
+          - branch:
+              request_map: 'root.id = this.id'
+              processors:
+                - http:
+                    url: https://example.com/enrich
+                    verb: POST
+              result_map: 'root.enrichment = this'
+`)
+}
+
+func init() {
+	err := service.RegisterBatchProcessor(
+		"parallel_map", parallelMapProcSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchProcessor, error) {
+			return newParallelMapProcFromParsed(conf)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type parallelMapProc struct {
+	children      []*service.OwnedProcessor
+	workers       int
+	preserveOrder bool
+	timeout       time.Duration
+}
+
+func newParallelMapProcFromParsed(conf *service.ParsedConfig) (p *parallelMapProc, err error) {
+	p = &parallelMapProc{}
+	if p.children, err = conf.FieldProcessorList(pmpFieldProcessors); err != nil {
+		return
+	}
+	if p.workers, err = conf.FieldInt(pmpFieldWorkers); err != nil {
+		return
+	}
+	if p.workers < 0 {
+		return nil, fmt.Errorf("workers must be zero or greater, got %v", p.workers)
+	}
+	if p.preserveOrder, err = conf.FieldBool(pmpFieldPreserveOrder); err != nil {
+		return
+	}
+	if conf.Contains(pmpFieldTimeout) {
+		if p.timeout, err = conf.FieldDuration(pmpFieldTimeout); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// processPart applies the child processors to a single message, returning the
+// resulting messages, or the original message marked as failed when the
+// children return an error or the timeout is exceeded.
+func (p *parallelMapProc) processPart(ctx context.Context, msg *service.Message) service.MessageBatch {
+	pCtx, done := ctx, func() {}
+	if p.timeout > 0 {
+		pCtx, done = context.WithTimeout(ctx, p.timeout)
+	}
+	defer done()
+
+	type result struct {
+		batches []service.MessageBatch
+		err     error
+	}
+	resChan := make(chan result, 1)
+	go func() {
+		batches, err := service.ExecuteProcessors(pCtx, p.children, service.MessageBatch{msg.Copy()})
+		resChan <- result{batches: batches, err: err}
+	}()
+
+	var res result
+	select {
+	case res = <-resChan:
+	case <-pCtx.Done():
+		res.err = pCtx.Err()
+		if p.timeout > 0 && ctx.Err() == nil {
+			res.err = fmt.Errorf("message processing exceeded timeout of %v: %w", p.timeout, res.err)
+		}
+	}
+	if res.err != nil {
+		msg.SetError(res.err)
+		return service.MessageBatch{msg}
+	}
+
+	var resBatch service.MessageBatch
+	for _, b := range res.batches {
+		resBatch = append(resBatch, b...)
+	}
+	return resBatch
+}
+
+func (p *parallelMapProc) ProcessBatch(ctx context.Context, batch service.MessageBatch) ([]service.MessageBatch, error) {
+	workers := p.workers
+	if workers == 0 || len(batch) < workers {
+		workers = len(batch)
+	}
+
+	results := make([]service.MessageBatch, len(batch))
+	var finished service.MessageBatch
+	var finishedMut sync.Mutex
+
+	reqChan := make(chan int)
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range reqChan {
+				res := p.processPart(ctx, batch[index])
+				if p.preserveOrder {
+					results[index] = res
+					continue
+				}
+				finishedMut.Lock()
+				finished = append(finished, res...)
+				finishedMut.Unlock()
+			}
+		}()
+	}
+	for i := range batch {
+		reqChan <- i
+	}
+	close(reqChan)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if p.preserveOrder {
+		for _, r := range results {
+			finished = append(finished, r...)
+		}
+	}
+	if len(finished) == 0 {
+		return nil, nil
+	}
+	return []service.MessageBatch{finished}, nil
+}
+
+func (p *parallelMapProc) Close(ctx context.Context) error {
+	for _, c := range p.children {
+		if err := c.Close(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package pure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func parallelMapBatch(contents ...string) service.MessageBatch {
+	var b service.MessageBatch
+	for _, c := range contents {
+		b = append(b, service.NewMessage([]byte(c)))
+	}
+	return b
+}
+
+func parallelMapResults(t *testing.T, batches []service.MessageBatch) (contents, errs []string) {
+	t.Helper()
+
+	require.Len(t, batches, 1)
+	for _, m := range batches[0] {
+		b, err := m.AsBytes()
+		require.NoError(t, err)
+		contents = append(contents, string(b))
+
+		var errStr string
+		if err := m.GetError(); err != nil {
+			errStr = err.Error()
+		}
+		errs = append(errs, errStr)
+	}
+	return
+}
+
+func TestParallelMapPreserveOrder(t *testing.T) {
+	conf, err := parallelMapProcSpec().ParseYAML(`
+workers: 2
+processors:
+  - sleep:
+      duration: '${! this.sleep }'
+  - mapping: 'root = this.id.uppercase()'
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newParallelMapProcFromParsed(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, proc.Close(context.Background()))
+	})
+
+	batches, err := proc.ProcessBatch(context.Background(), parallelMapBatch(
+		`{"id":"a","sleep":"50ms"}`,
+		`{"id":"b","sleep":"0s"}`,
+		`{"id":"c","sleep":"10ms"}`,
+		`{"id":"d","sleep":"0s"}`,
+	))
+	require.NoError(t, err)
+
+	contents, errs := parallelMapResults(t, batches)
+	assert.Equal(t, []string{"A", "B", "C", "D"}, contents)
+	assert.Equal(t, []string{"", "", "", ""}, errs)
+}
+
+func TestParallelMapCompletionOrder(t *testing.T) {
+	conf, err := parallelMapProcSpec().ParseYAML(`
+preserve_order: false
+processors:
+  - sleep:
+      duration: '${! this.sleep }'
+  - mapping: 'root = this.id'
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newParallelMapProcFromParsed(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, proc.Close(context.Background()))
+	})
+
+	batches, err := proc.ProcessBatch(context.Background(), parallelMapBatch(
+		`{"id":"a","sleep":"200ms"}`,
+		`{"id":"b","sleep":"0s"}`,
+	))
+	require.NoError(t, err)
+
+	contents, _ := parallelMapResults(t, batches)
+	assert.Equal(t, []string{"b", "a"}, contents)
+}
+
+func TestParallelMapTimeout(t *testing.T) {
+	conf, err := parallelMapProcSpec().ParseYAML(`
+timeout: 50ms
+processors:
+  - sleep:
+      duration: '${! this.sleep }'
+  - mapping: 'root = this.id'
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newParallelMapProcFromParsed(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, proc.Close(context.Background()))
+	})
+
+	batches, err := proc.ProcessBatch(context.Background(), parallelMapBatch(
+		`{"id":"a","sleep":"10s"}`,
+		`{"id":"b","sleep":"0s"}`,
+	))
+	require.NoError(t, err)
+
+	contents, errs := parallelMapResults(t, batches)
+	assert.Equal(t, []string{`{"id":"a","sleep":"10s"}`, "b"}, contents)
+	assert.Contains(t, errs[0], "message processing exceeded timeout of 50ms")
+	assert.Equal(t, "", errs[1])
+}
+
+func TestParallelMapFiltered(t *testing.T) {
+	conf, err := parallelMapProcSpec().ParseYAML(`
+processors:
+  - mapping: 'root = if this.drop { deleted() } else { this.id }'
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newParallelMapProcFromParsed(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, proc.Close(context.Background()))
+	})
+
+	batches, err := proc.ProcessBatch(context.Background(), parallelMapBatch(
+		`{"id":"a","drop":true}`,
+		`{"id":"b","drop":false}`,
+	))
+	require.NoError(t, err)
+
+	contents, _ := parallelMapResults(t, batches)
+	assert.Equal(t, []string{"b"}, contents)
+
+	batches, err = proc.ProcessBatch(context.Background(), parallelMapBatch(`{"id":"a","drop":true}`))
+	require.NoError(t, err)
+	assert.Empty(t, batches)
+}
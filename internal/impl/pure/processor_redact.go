@@ -0,0 +1,579 @@
+package pure
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	rdpFieldDetectors      = "detectors"
+	rdpFieldPatterns       = "patterns"
+	rdpFieldPaths          = "paths"
+	rdpFieldAction         = "action"
+	rdpFieldMask           = "mask"
+	rdpFieldMaskCharacter  = "character"
+	rdpFieldMaskKeepLast   = "keep_last"
+	rdpFieldSalt           = "salt"
+	rdpFieldTokenize       = "tokenize"
+	rdpFieldTokenizeCache  = "cache"
+	rdpFieldTokenizePrefix = "prefix"
+	rdpFieldTokenizeTTL    = "ttl"
+	rdpFieldReportMetadata = "report_metadata"
+)
+
+const redactReportDefaultMeta = "redact_report"
+
+func redactProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Detects personally identifiable information (PII) such as email addresses and credit card numbers within messages and masks, hashes or tokenizes it.").
+		Description(`
+Each detector finds a type of sensitive value within text, and every value found is replaced according to the `+"`action`"+`. The following detectors are built in:
+
+| Detector | Finds |
+|---|---|
+| `+"`email`"+` | Email addresses. |
+| `+"`credit_card`"+` | Credit card numbers of 13 to 19 digits, optionally separated by spaces or dashes, that pass the Luhn checksum. |
+| `+"`ipv4`"+` | IPv4 addresses. |
+| `+"`ipv6`"+` | IPv6 addresses. |
+| `+"`phone`"+` | Phone numbers of 7 to 15 digits, optionally with a country code, parentheses and separators. |
+
+Custom detectors can be added with `+"`patterns`"+`, which is a map of detector names to regular expressions. When the matches of detectors overlap the longest match is redacted.
+
+### Scoping
+
+By default the entire contents of each message are scanned as text. When `+"`paths`"+` are specified the message is instead parsed as a structured document and only the string values at those paths are scanned, where objects and arrays at a path are scanned recursively. Paths are dot separated, and a segment of `+"`*`"+` matches all fields of an object or all elements of an array.
+
+### Actions
+
+- `+"`mask`"+` replaces each character of a value with the `+"`mask.character`"+`, optionally keeping the last `+"`mask.keep_last`"+` characters.
+- `+"`hash`"+` replaces a value with the hex encoded HMAC-SHA256 of the value keyed with the `+"`salt`"+`, which allows values to be correlated without being revealed.
+- `+"`tokenize`"+` replaces a value with a token derived from an HMAC of the value keyed with the `+"`salt`"+`, and stores the original value in the cache `+"`tokenize.cache`"+` under the token, which allows authorised systems to reverse the tokenization.
+
+### Report
+
+When any values are redacted from a message a report is added to it as the metadata field `+"`"+redactReportDefaultMeta+"`"+` (configurable with `+"`report_metadata`"+`), which is an object of the number of values redacted by each detector, e.g. `+"`{\"email\":2,\"credit_card\":1}`"+`.`).
+		Fields(
+			service.NewStringListField(rdpFieldDetectors).
+				Description("A list of built in detectors to enable.").
+				Default([]any{"email", "credit_card", "ipv4", "ipv6", "phone"}).
+				Example([]any{"email", "credit_card"}).
+				LintRule(`root = if this.type() == "array" { this.filter(d -> !["email", "credit_card", "ipv4", "ipv6", "phone"].contains(d)).map_each(d -> "unknown detector: %v".format(d)) }`),
+			service.NewStringMapField(rdpFieldPatterns).
+				Description("A map of custom detector names to regular expressions, where all matches of an expression are redacted.").
+				Example(map[string]any{"employee_id": `EMP-\d{6}`}).
+				Default(map[string]any{}),
+			service.NewStringListField(rdpFieldPaths).
+				Description("An optional list of dot separated paths of a structured message to scan, when empty the entire message is scanned as text.").
+				Example([]any{"user.email", "user.addresses.*.phone"}).
+				Default([]any{}),
+			service.NewStringEnumField(rdpFieldAction, "mask", "hash", "tokenize").
+				Description("The action to perform on detected values.").
+				Default("mask"),
+			service.NewObjectField(rdpFieldMask,
+				service.NewStringField(rdpFieldMaskCharacter).
+					Description("The character to replace each character of a value with.").
+					Default("*"),
+				service.NewIntField(rdpFieldMaskKeepLast).
+					Description("The number of trailing characters of each value to leave unmasked.").
+					Default(0),
+			).
+				Description("Options for the `mask` action.").
+				Advanced(),
+			service.NewStringField(rdpFieldSalt).
+				Description("A secret salt used as the key of the HMAC computed by the `hash` and `tokenize` actions.").
+				Default("").
+				Secret(),
+			service.NewObjectField(rdpFieldTokenize,
+				service.NewStringField(rdpFieldTokenizeCache).
+					Description("A [cache resource](/docs/components/caches/about) to store original values within, keyed by their tokens.").
+					Default(""),
+				service.NewStringField(rdpFieldTokenizePrefix).
+					Description("A prefix to add to each token.").
+					Default("tok_"),
+				service.NewDurationField(rdpFieldTokenizeTTL).
+					Description("An optional TTL to set for each stored value, some caches only have a general TTL and will therefore ignore this setting.").
+					Optional(),
+			).
+				Description("Options for the `tokenize` action.").
+				Advanced(),
+			service.NewStringField(rdpFieldReportMetadata).
+				Description("The metadata key to add the report of redacted values to.").
+				Default(redactReportDefaultMeta).
+				Advanced(),
+		).
+		LintRule(`root = if this.action.or("mask") == "tokenize" && this.tokenize.cache.or("") == "" {
+  "the field tokenize.cache must be set when the action is tokenize"
+}`).
+		Example(
+			"Mask Emails and Cards",
+			"Here we mask email addresses and credit card numbers within the free text of support tickets, keeping the last four characters of each.",
+			`
+pipeline:
+  processors:
+    - redact:
+        detectors: [ email, credit_card ]
+        paths: [ subject, body ]
+        mask:
+          keep_last: 4
+`,
+		).
+		Example(
+			"Tokenize Customer Details",
+			"Here we tokenize all detected PII and custom customer IDs within an event, storing the original values within a Redis cache so that they can be recovered by authorised systems.",
+			`
+pipeline:
+  processors:
+    - redact:
+        patterns:
+          customer_id: 'CUST-\d{8}'
+        action: tokenize
+        salt: ${REDACT_SALT}
+        tokenize:
+          cache: pii_vault
+
+cache_resources:
+  - label: pii_vault
+    redis:
+      url: redis://localhost:6379
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"redact", redactProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			return newRedactProcessorFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type redactDetector struct {
+	name  string
+	re    *regexp.Regexp
+	valid func(string) bool
+
+	// Whether matches must not be adjacent to other digits, as the regexp
+	// package doesn't support lookarounds.
+	digitBounded bool
+}
+
+var (
+	redactEmailRegexp      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+	redactCreditCardRegexp = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+	redactIPv4Regexp       = regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`)
+	redactIPv6Regexp       = regexp.MustCompile(`(?i)(?:[0-9a-f]{1,4}|:)?(?::[0-9a-f]{0,4}){2,7}(?:(?:\d{1,3}\.){3}\d{1,3})?`)
+	redactPhoneRegexp      = regexp.MustCompile(`(?:\+\d{1,3}[ .\-]?)?(?:\(\d{1,4}\)[ .\-]?|\d{2,4}[ .\-]?)?\d{3,4}[ .\-]?\d{3,4}\b`)
+)
+
+func redactDigits(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func isDigitAt(s string, i int) bool {
+	return i >= 0 && i < len(s) && s[i] >= '0' && s[i] <= '9'
+}
+
+// luhnValid returns whether a string of digits passes the Luhn checksum.
+func luhnValid(digits string) bool {
+	var sum int
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func builtinRedactDetector(name string) (redactDetector, error) {
+	switch name {
+	case "email":
+		return redactDetector{name: name, re: redactEmailRegexp}, nil
+	case "credit_card":
+		return redactDetector{name: name, re: redactCreditCardRegexp, digitBounded: true, valid: func(s string) bool {
+			digits := redactDigits(s)
+			return len(digits) >= 13 && len(digits) <= 19 && luhnValid(digits)
+		}}, nil
+	case "ipv4":
+		return redactDetector{name: name, re: redactIPv4Regexp, digitBounded: true}, nil
+	case "ipv6":
+		return redactDetector{name: name, re: redactIPv6Regexp, valid: func(s string) bool {
+			ip := net.ParseIP(s)
+			return ip != nil && strings.Contains(s, ":")
+		}}, nil
+	case "phone":
+		return redactDetector{name: name, re: redactPhoneRegexp, digitBounded: true, valid: func(s string) bool {
+			digits := redactDigits(s)
+			return len(digits) >= 7 && len(digits) <= 15
+		}}, nil
+	}
+	return redactDetector{}, fmt.Errorf("unknown detector: %v", name)
+}
+
+type redactMatch struct {
+	start, end int
+	detector   string
+}
+
+type redactProcessor struct {
+	detectors []redactDetector
+	paths     [][]string
+
+	action       string
+	maskChar     string
+	maskKeepLast int
+	salt         []byte
+
+	cacheName   string
+	tokenPrefix string
+	tokenTTL    *time.Duration
+
+	reportMeta string
+	mgr        *service.Resources
+}
+
+func newRedactProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*redactProcessor, error) {
+	p := &redactProcessor{mgr: mgr}
+
+	detectorNames, err := conf.FieldStringList(rdpFieldDetectors)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range detectorNames {
+		d, err := builtinRedactDetector(name)
+		if err != nil {
+			return nil, err
+		}
+		p.detectors = append(p.detectors, d)
+	}
+
+	patterns, err := conf.FieldStringMap(rdpFieldPatterns)
+	if err != nil {
+		return nil, err
+	}
+	patternNames := make([]string, 0, len(patterns))
+	for name := range patterns {
+		patternNames = append(patternNames, name)
+	}
+	sort.Strings(patternNames)
+	for _, name := range patternNames {
+		re, err := regexp.Compile(patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile pattern '%v': %w", name, err)
+		}
+		p.detectors = append(p.detectors, redactDetector{name: name, re: re})
+	}
+	if len(p.detectors) == 0 {
+		return nil, errors.New("at least one detector or pattern must be specified")
+	}
+
+	paths, err := conf.FieldStringList(rdpFieldPaths)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		p.paths = append(p.paths, strings.Split(path, "."))
+	}
+
+	if p.action, err = conf.FieldString(rdpFieldAction); err != nil {
+		return nil, err
+	}
+	if p.maskChar, err = conf.FieldString(rdpFieldMask, rdpFieldMaskCharacter); err != nil {
+		return nil, err
+	}
+	if p.maskKeepLast, err = conf.FieldInt(rdpFieldMask, rdpFieldMaskKeepLast); err != nil {
+		return nil, err
+	}
+
+	salt, err := conf.FieldString(rdpFieldSalt)
+	if err != nil {
+		return nil, err
+	}
+	p.salt = []byte(salt)
+
+	if p.cacheName, err = conf.FieldString(rdpFieldTokenize, rdpFieldTokenizeCache); err != nil {
+		return nil, err
+	}
+	if p.tokenPrefix, err = conf.FieldString(rdpFieldTokenize, rdpFieldTokenizePrefix); err != nil {
+		return nil, err
+	}
+	if conf.Contains(rdpFieldTokenize, rdpFieldTokenizeTTL) {
+		ttl, err := conf.FieldDuration(rdpFieldTokenize, rdpFieldTokenizeTTL)
+		if err != nil {
+			return nil, err
+		}
+		p.tokenTTL = &ttl
+	}
+	if p.action == "tokenize" {
+		if p.cacheName == "" {
+			return nil, errors.New("the field tokenize.cache must be set when the action is tokenize")
+		}
+		if !mgr.HasCache(p.cacheName) {
+			return nil, fmt.Errorf("cache resource '%v' was not found", p.cacheName)
+		}
+	}
+
+	if p.reportMeta, err = conf.FieldString(rdpFieldReportMetadata); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// findMatches returns the non-overlapping matches of all detectors within a
+// string in order of their position, where the longest of overlapping matches
+// is chosen.
+func (p *redactProcessor) findMatches(s string) []redactMatch {
+	var matches []redactMatch
+	for _, d := range p.detectors {
+		for _, loc := range d.re.FindAllStringIndex(s, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			if d.digitBounded && (isDigitAt(s, loc[0]-1) || isDigitAt(s, loc[1])) {
+				continue
+			}
+			if d.valid != nil && !d.valid(s[loc[0]:loc[1]]) {
+				continue
+			}
+			matches = append(matches, redactMatch{start: loc[0], end: loc[1], detector: d.name})
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		li, lj := matches[i].end-matches[i].start, matches[j].end-matches[j].start
+		if li != lj {
+			return li > lj
+		}
+		return matches[i].start < matches[j].start
+	})
+
+	var chosen []redactMatch
+	for _, m := range matches {
+		overlaps := false
+		for _, c := range chosen {
+			if m.start < c.end && c.start < m.end {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			chosen = append(chosen, m)
+		}
+	}
+
+	sort.Slice(chosen, func(i, j int) bool {
+		return chosen[i].start < chosen[j].start
+	})
+	return chosen
+}
+
+func (p *redactProcessor) hmac(value string) string {
+	h := hmac.New(sha256.New, p.salt)
+	_, _ = h.Write([]byte(value))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (p *redactProcessor) mask(value string) string {
+	n := utf8.RuneCountInString(value)
+	keep := p.maskKeepLast
+	if keep > n {
+		keep = n
+	}
+	if keep < 0 {
+		keep = 0
+	}
+
+	var sb strings.Builder
+	i := 0
+	for _, r := range value {
+		if i < n-keep {
+			sb.WriteString(p.maskChar)
+		} else {
+			sb.WriteRune(r)
+		}
+		i++
+	}
+	return sb.String()
+}
+
+func (p *redactProcessor) replacement(ctx context.Context, value string) (string, error) {
+	switch p.action {
+	case "hash":
+		return p.hmac(value), nil
+	case "tokenize":
+		token := p.tokenPrefix + p.hmac(value)[:32]
+		var setErr error
+		if err := p.mgr.AccessCache(ctx, p.cacheName, func(c service.Cache) {
+			setErr = c.Set(ctx, token, []byte(value), p.tokenTTL)
+		}); err != nil {
+			return "", err
+		}
+		if setErr != nil {
+			return "", fmt.Errorf("failed to store tokenized value: %w", setErr)
+		}
+		return token, nil
+	}
+	return p.mask(value), nil
+}
+
+// redactString replaces all detected values within a string, incrementing the
+// counts of the report for each value replaced.
+func (p *redactProcessor) redactString(ctx context.Context, s string, report map[string]int64) (string, error) {
+	matches := p.findMatches(s)
+	if len(matches) == 0 {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		r, err := p.replacement(ctx, s[m.start:m.end])
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(s[last:m.start])
+		sb.WriteString(r)
+		last = m.end
+		report[m.detector]++
+	}
+	sb.WriteString(s[last:])
+	return sb.String(), nil
+}
+
+// redactValue redacts all strings within a structured value recursively.
+func (p *redactProcessor) redactValue(ctx context.Context, v any, report map[string]int64) (any, error) {
+	var err error
+	switch t := v.(type) {
+	case string:
+		return p.redactString(ctx, t, report)
+	case map[string]any:
+		for k, e := range t {
+			if t[k], err = p.redactValue(ctx, e, report); err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		for i, e := range t {
+			if t[i], err = p.redactValue(ctx, e, report); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+
+// redactPath redacts the values of a structured value found at a path, where
+// the segment * matches all fields of an object or elements of an array.
+func (p *redactProcessor) redactPath(ctx context.Context, v any, path []string, report map[string]int64) (any, error) {
+	if len(path) == 0 {
+		return p.redactValue(ctx, v, report)
+	}
+
+	var err error
+	switch t := v.(type) {
+	case map[string]any:
+		if path[0] == "*" {
+			for k, e := range t {
+				if t[k], err = p.redactPath(ctx, e, path[1:], report); err != nil {
+					return nil, err
+				}
+			}
+		} else if e, exists := t[path[0]]; exists {
+			if t[path[0]], err = p.redactPath(ctx, e, path[1:], report); err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		if path[0] == "*" {
+			for i, e := range t {
+				if t[i], err = p.redactPath(ctx, e, path[1:], report); err != nil {
+					return nil, err
+				}
+			}
+		} else if i, err := strconv.Atoi(path[0]); err == nil && i >= 0 && i < len(t) {
+			if t[i], err = p.redactPath(ctx, t[i], path[1:], report); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+
+func (p *redactProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	report := map[string]int64{}
+
+	if len(p.paths) == 0 {
+		mBytes, err := msg.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		res, err := p.redactString(ctx, string(mBytes), report)
+		if err != nil {
+			return nil, err
+		}
+		if len(report) > 0 {
+			msg.SetBytes([]byte(res))
+		}
+	} else {
+		v, err := msg.AsStructuredMut()
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range p.paths {
+			if v, err = p.redactPath(ctx, v, path, report); err != nil {
+				return nil, err
+			}
+		}
+		if len(report) > 0 {
+			msg.SetStructuredMut(v)
+		}
+	}
+
+	if len(report) > 0 {
+		reportObj := make(map[string]any, len(report))
+		for k, c := range report {
+			reportObj[k] = c
+		}
+		msg.MetaSetMut(p.reportMeta, reportObj)
+	}
+	return service.MessageBatch{msg}, nil
+}
+
+func (p *redactProcessor) Close(ctx context.Context) error {
+	return nil
+}
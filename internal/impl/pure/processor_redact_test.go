@@ -0,0 +1,221 @@
+package pure
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestRedactDetectors(t *testing.T) {
+	conf, err := redactProcessorConfig().ParseYAML(`{}`, nil)
+	require.NoError(t, err)
+
+	proc, err := newRedactProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		input  string
+		output string
+		report map[string]any
+	}{
+		{
+			name:   "email",
+			input:  "contact foo.bar@example.co.uk now",
+			output: "contact " + strings.Repeat("*", 21) + " now",
+			report: map[string]any{"email": int64(1)},
+		},
+		{
+			name:   "valid credit card",
+			input:  "card 4111 1111 1111 1111 ok",
+			output: "card " + strings.Repeat("*", 19) + " ok",
+			report: map[string]any{"credit_card": int64(1)},
+		},
+		{
+			name:   "invalid credit card",
+			input:  "number 4111111111111112 and 123456789012345678",
+			output: "number 4111111111111112 and 123456789012345678",
+		},
+		{
+			name:   "ipv4",
+			input:  "from 192.168.0.10 to 10.0.0.1",
+			output: "from ************ to ********",
+			report: map[string]any{"ipv4": int64(2)},
+		},
+		{
+			name:   "ipv6",
+			input:  "host 2001:db8::ff00:42:8329 up at 12:30:45",
+			output: "host " + strings.Repeat("*", 22) + " up at 12:30:45",
+			report: map[string]any{"ipv6": int64(1)},
+		},
+		{
+			name:   "phone",
+			input:  "call +44 20 7946 0958 or (555) 123-4567",
+			output: "call " + strings.Repeat("*", 16) + " or " + strings.Repeat("*", 14),
+			report: map[string]any{"phone": int64(2)},
+		},
+		{
+			name:   "nothing",
+			input:  "nothing to see on 2024-01-15",
+			output: "nothing to see on 2024-01-15",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			batch, err := proc.Process(context.Background(), service.NewMessage([]byte(test.input)))
+			require.NoError(t, err)
+			require.Len(t, batch, 1)
+
+			mBytes, err := batch[0].AsBytes()
+			require.NoError(t, err)
+			assert.Equal(t, test.output, string(mBytes))
+
+			report, exists := batch[0].MetaGetMut("redact_report")
+			if test.report == nil {
+				assert.False(t, exists)
+			} else {
+				assert.Equal(t, test.report, report)
+			}
+		})
+	}
+}
+
+func TestRedactPathsAndPatterns(t *testing.T) {
+	conf, err := redactProcessorConfig().ParseYAML(`
+detectors: [ email ]
+patterns:
+  employee_id: 'EMP-\d{6}'
+paths: [ user.email, notes.*.text ]
+mask:
+  character: X
+  keep_last: 4
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newRedactProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	batch, err := proc.Process(context.Background(), service.NewMessage([]byte(`{
+  "user": { "email": "foo@example.com", "name": "bar@example.com" },
+  "notes": [
+    { "text": "raised by EMP-123456", "by": "baz@example.com" },
+    { "text": [ "cc qux@example.com" ] }
+  ]
+}`)))
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+
+	v, err := batch[0].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"user": map[string]any{"email": "XXXXXXXXXXX.com", "name": "bar@example.com"},
+		"notes": []any{
+			map[string]any{"text": "raised by XXXXXX3456", "by": "baz@example.com"},
+			map[string]any{"text": []any{"cc XXXXXXXXXXX.com"}},
+		},
+	}, v)
+
+	report, _ := batch[0].MetaGetMut("redact_report")
+	assert.Equal(t, map[string]any{"email": int64(2), "employee_id": int64(1)}, report)
+}
+
+func TestRedactHash(t *testing.T) {
+	conf, err := redactProcessorConfig().ParseYAML(`
+detectors: [ email ]
+action: hash
+salt: foo
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newRedactProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	batch, err := proc.Process(context.Background(), service.NewMessage([]byte(`a@example.com b@example.com a@example.com`)))
+	require.NoError(t, err)
+
+	mBytes, err := batch[0].AsBytes()
+	require.NoError(t, err)
+
+	parts := strings.Split(string(mBytes), " ")
+	require.Len(t, parts, 3)
+	assert.Len(t, parts[0], 64)
+	assert.Equal(t, parts[0], parts[2])
+	assert.NotEqual(t, parts[0], parts[1])
+	assert.Equal(t, proc.hmac("a@example.com"), parts[0])
+}
+
+func TestRedactTokenize(t *testing.T) {
+	res := service.MockResources(service.MockResourcesOptAddCache("vault"))
+	conf, err := redactProcessorConfig().ParseYAML(`
+detectors: [ credit_card ]
+action: tokenize
+salt: foo
+tokenize:
+  cache: vault
+  prefix: 'card_'
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newRedactProcessorFromConfig(conf, res)
+	require.NoError(t, err)
+
+	batch, err := proc.Process(context.Background(), service.NewMessage([]byte(`paid with 5555555555554444`)))
+	require.NoError(t, err)
+
+	mBytes, err := batch[0].AsBytes()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(mBytes), "paid with card_"))
+
+	token := strings.TrimPrefix(string(mBytes), "paid with ")
+	assert.Len(t, token, 37)
+
+	var value []byte
+	require.NoError(t, res.AccessCache(context.Background(), "vault", func(c service.Cache) {
+		value, err = c.Get(context.Background(), token)
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "5555555555554444", string(value))
+}
+
+func TestRedactConfigErrors(t *testing.T) {
+	conf, err := redactProcessorConfig().ParseYAML(`
+action: tokenize
+tokenize:
+  cache: nope
+`, nil)
+	require.NoError(t, err)
+
+	_, err = newRedactProcessorFromConfig(conf, service.MockResources())
+	require.Error(t, err)
+
+	conf, err = redactProcessorConfig().ParseYAML(`
+detectors: []
+`, nil)
+	require.NoError(t, err)
+
+	_, err = newRedactProcessorFromConfig(conf, service.MockResources())
+	require.Error(t, err)
+
+	env := service.NewEnvironment()
+	require.Error(t, env.NewStreamBuilder().AddProcessorYAML(`
+redact:
+  action: tokenize
+`))
+	require.Error(t, env.NewStreamBuilder().AddProcessorYAML(`
+redact:
+  detectors: [ email, nope ]
+`))
+}
+
+func TestLuhnValid(t *testing.T) {
+	assert.True(t, luhnValid("4111111111111111"))
+	assert.True(t, luhnValid("79927398713"))
+	assert.False(t, luhnValid("79927398710"))
+}
@@ -0,0 +1,204 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/OneOfOne/xxhash"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	spFieldMode             = "mode"
+	spFieldProbability      = "probability"
+	spFieldRate             = "rate"
+	spFieldKey              = "key"
+	spFieldDropUnsampled    = "drop_unsampled"
+	spFieldDecisionMetadata = "decision_metadata"
+)
+
+func sampleProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Version("4.24.0").
+		Summary("Samples a subset of messages, dropping the rest, either randomly, at a fixed rate or consistently by a key.").
+		Description(`
+Sampling is a cheap way to reduce the volume (and cost) of data such as logs and traces flowing through a pipeline whilst keeping a representative subset of it. The `+"`mode`"+` determines how messages are chosen:
+
+- `+"`random`"+` keeps each message with a chance of `+"`probability`"+`.
+- `+"`rate`"+` keeps exactly one in every `+"`rate`"+` messages, starting with the first.
+- `+"`hash`"+` keeps a message when a hash of its `+"`key`"+` falls within the `+"`probability`"+`, which means that all messages sharing a key (such as a trace ID) are either kept or dropped together, even across separate Benthos instances.
+
+### Sampling Decisions
+
+When `+"`decision_metadata`"+` is set each message is given a metadata field of that name with the value `+"`true`"+` when it was sampled and `+"`false`"+` otherwise. Setting `+"`drop_unsampled`"+` to `+"`false`"+` keeps unsampled messages in the pipeline, which allows downstream processors and outputs to route or flag them by their sampling decision rather than dropping them.`).
+		Fields(
+			service.NewStringEnumField(spFieldMode, "random", "rate", "hash").
+				Description("The sampling mode to use.").
+				Default("random"),
+			service.NewFloatField(spFieldProbability).
+				Description("The probability between 0 and 1 of a message being sampled, used by the `random` and `hash` modes.").
+				Default(1.0).
+				Example(0.1),
+			service.NewIntField(spFieldRate).
+				Description("The number of messages of which one is sampled, used by the `rate` mode.").
+				Default(1).
+				Example(100),
+			service.NewInterpolatedStringField(spFieldKey).
+				Description("An interpolated string yielding the key to sample by, used by the `hash` mode.").
+				Default("").
+				Example(`${! json("trace_id") }`).
+				Example(`${! meta("kafka_key") }`),
+			service.NewBoolField(spFieldDropUnsampled).
+				Description("Whether messages that are not sampled should be dropped.").
+				Default(true),
+			service.NewStringField(spFieldDecisionMetadata).
+				Description("An optional metadata key to add the sampling decision of each message to as either `true` or `false`.").
+				Default("").
+				Example("sampled"),
+		).
+		LintRule(`root = if this.mode.or("random") == "hash" && this.key.or("") == "" {
+  "the field key must be set when the mode is hash"
+}`).
+		Example(
+			"Keep Whole Traces",
+			"Here we keep roughly a tenth of all spans, where every span of a trace is either kept or dropped together.",
+			`
+pipeline:
+  processors:
+    - sample:
+        mode: hash
+        key: ${! json("trace_id") }
+        probability: 0.1
+`,
+		).
+		Example(
+			"Flag Instead of Drop",
+			"Here one in every hundred messages is flagged as sampled and all messages are kept, allowing a downstream output to route them by their sampling decision.",
+			`
+pipeline:
+  processors:
+    - sample:
+        mode: rate
+        rate: 100
+        drop_unsampled: false
+        decision_metadata: sampled
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"sample", sampleProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			return newSampleProcessorFromConfig(conf)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type sampleProcessor struct {
+	mode        string
+	probability float64
+	rate        uint64
+	key         *service.InterpolatedString
+
+	dropUnsampled bool
+	decisionMeta  string
+
+	count     uint64
+	randFloat func() float64
+}
+
+func newSampleProcessorFromConfig(conf *service.ParsedConfig) (*sampleProcessor, error) {
+	p := &sampleProcessor{randFloat: rand.Float64}
+
+	var err error
+	if p.mode, err = conf.FieldString(spFieldMode); err != nil {
+		return nil, err
+	}
+
+	if p.probability, err = conf.FieldFloat(spFieldProbability); err != nil {
+		return nil, err
+	}
+	if p.probability < 0 || p.probability > 1 {
+		return nil, fmt.Errorf("probability must be between 0 and 1, got %v", p.probability)
+	}
+
+	rate, err := conf.FieldInt(spFieldRate)
+	if err != nil {
+		return nil, err
+	}
+	if rate < 1 {
+		return nil, fmt.Errorf("rate must be greater than 0, got %v", rate)
+	}
+	p.rate = uint64(rate)
+
+	if p.mode == "hash" {
+		keyStr, err := conf.FieldString(spFieldKey)
+		if err != nil {
+			return nil, err
+		}
+		if keyStr == "" {
+			return nil, errors.New("the field key must be set when the mode is hash")
+		}
+		if p.key, err = conf.FieldInterpolatedString(spFieldKey); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.dropUnsampled, err = conf.FieldBool(spFieldDropUnsampled); err != nil {
+		return nil, err
+	}
+	if p.decisionMeta, err = conf.FieldString(spFieldDecisionMetadata); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// hashSampled returns whether a key falls within the sampled fraction of the
+// hash space.
+func (p *sampleProcessor) hashSampled(key string) bool {
+	return float64(xxhash.ChecksumString64(key)>>11)/(1<<53) < p.probability
+}
+
+func (p *sampleProcessor) sampled(msg *service.Message) (bool, error) {
+	switch p.mode {
+	case "rate":
+		return (atomic.AddUint64(&p.count, 1)-1)%p.rate == 0, nil
+	case "hash":
+		key, err := p.key.TryString(msg)
+		if err != nil {
+			return false, fmt.Errorf("key interpolation error: %w", err)
+		}
+		return p.hashSampled(key), nil
+	}
+	return p.randFloat() < p.probability, nil
+}
+
+func (p *sampleProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	sampled, err := p.sampled(msg)
+	if err != nil {
+		return nil, err
+	}
+	if !sampled && p.dropUnsampled {
+		return nil, nil
+	}
+	if p.decisionMeta != "" {
+		msg.MetaSet(p.decisionMeta, strconv.FormatBool(sampled))
+	}
+	return service.MessageBatch{msg}, nil
+}
+
+func (p *sampleProcessor) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,139 @@
+package pure
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestSampleRate(t *testing.T) {
+	conf, err := sampleProcessorConfig().ParseYAML(`
+mode: rate
+rate: 3
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newSampleProcessorFromConfig(conf)
+	require.NoError(t, err)
+
+	var kept []string
+	for i := 0; i < 10; i++ {
+		res, err := proc.Process(context.Background(), service.NewMessage([]byte(fmt.Sprintf("msg%v", i))))
+		require.NoError(t, err)
+		for _, m := range res {
+			b, err := m.AsBytes()
+			require.NoError(t, err)
+			kept = append(kept, string(b))
+		}
+	}
+	assert.Equal(t, []string{"msg0", "msg3", "msg6", "msg9"}, kept)
+}
+
+func TestSampleRandom(t *testing.T) {
+	conf, err := sampleProcessorConfig().ParseYAML(`
+probability: 0.5
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newSampleProcessorFromConfig(conf)
+	require.NoError(t, err)
+
+	rolls := []float64{0.1, 0.7, 0.49, 0.5}
+	proc.randFloat = func() float64 {
+		r := rolls[0]
+		rolls = rolls[1:]
+		return r
+	}
+
+	var kept int
+	for i := 0; i < 4; i++ {
+		res, err := proc.Process(context.Background(), service.NewMessage([]byte("hello")))
+		require.NoError(t, err)
+		kept += len(res)
+	}
+	assert.Equal(t, 2, kept)
+}
+
+func TestSampleHashConsistent(t *testing.T) {
+	conf, err := sampleProcessorConfig().ParseYAML(`
+mode: hash
+key: ${! json("trace_id") }
+probability: 0.5
+drop_unsampled: false
+decision_metadata: sampled
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newSampleProcessorFromConfig(conf)
+	require.NoError(t, err)
+
+	decisions := map[string]string{}
+	for i := 0; i < 100; i++ {
+		traceID := fmt.Sprintf("trace%v", i%20)
+		res, err := proc.Process(context.Background(), service.NewMessage([]byte(fmt.Sprintf(`{"trace_id":%q}`, traceID))))
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+
+		decision, _ := res[0].MetaGet("sampled")
+		if prev, exists := decisions[traceID]; exists {
+			assert.Equal(t, prev, decision, traceID)
+		}
+		decisions[traceID] = decision
+	}
+
+	var sampled int
+	for _, d := range decisions {
+		if d == "true" {
+			sampled++
+		}
+	}
+	assert.Greater(t, sampled, 0)
+	assert.Less(t, sampled, 20)
+}
+
+func TestSampleHashBounds(t *testing.T) {
+	noneConf, err := sampleProcessorConfig().ParseYAML(`
+mode: hash
+key: ${! content() }
+probability: 0
+`, nil)
+	require.NoError(t, err)
+
+	none, err := newSampleProcessorFromConfig(noneConf)
+	require.NoError(t, err)
+
+	allConf, err := sampleProcessorConfig().ParseYAML(`
+mode: hash
+key: ${! content() }
+probability: 1
+`, nil)
+	require.NoError(t, err)
+
+	all, err := newSampleProcessorFromConfig(allConf)
+	require.NoError(t, err)
+
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("key%v", i)
+		assert.False(t, none.hashSampled(key), key)
+		assert.True(t, all.hashSampled(key), key)
+	}
+}
+
+func TestSampleConfigErrors(t *testing.T) {
+	for _, confStr := range []string{
+		`probability: 1.5`,
+		`{ mode: rate, rate: 0 }`,
+		`mode: hash`,
+	} {
+		conf, err := sampleProcessorConfig().ParseYAML(confStr, nil)
+		require.NoError(t, err)
+
+		_, err = newSampleProcessorFromConfig(conf)
+		assert.Error(t, err, confStr)
+	}
+}
@@ -4,10 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/docs"
@@ -28,9 +34,50 @@ func init() {
 			"Utility",
 		},
 		Summary: `Sleep for a period of time specified as a duration string for each message. This processor will interpolate functions within the ` + "`duration`" + ` field, you can find a list of functions [here](/docs/configuration/interpolation#bloblang-queries).`,
+		Description: `
+### Computed Durations
+
+The duration can instead be computed for each message with a [Bloblang mapping](/docs/guides/bloblang/about) set with the field ` + "`duration_mapping`" + `, which is useful for honouring values such as a ` + "`Retry-After`" + ` header. The mapping can result in any of the following:
+
+- A duration string such as ` + "`1m30s`" + `.
+- A number, or a string containing a number, of seconds.
+- A timestamp, or a string containing an HTTP date, in which case the processor sleeps until that time.
+
+Durations that are negative or in the past result in no sleep.
+
+### Jitter
+
+When many messages are slept for the same duration it can be polite to spread them out, which can be done by setting ` + "`jitter`" + ` to a proportion of the duration that each sleep is randomly adjusted by. For example, a ` + "`jitter`" + ` of ` + "`0.2`" + ` with a duration of ` + "`10s`" + ` results in sleeps of between 8 and 12 seconds.`,
 		Config: docs.FieldComponent().WithChildren(
 			docs.FieldInterpolatedString("duration", "The duration of time to sleep for each execution.").HasDefault(""),
+			docs.FieldBloblang(
+				"duration_mapping", "An optional [Bloblang mapping](/docs/guides/bloblang/about) that computes the duration to sleep for each message, and when set takes precedence over `duration`. The mapping can result in a duration string, a number of seconds, or a timestamp to sleep until.",
+				`root = meta("retry_after").or("1s")`,
+				`root = this.backoff_ms / 1000`,
+			).HasDefault("").AtVersion("4.24.0"),
+			docs.FieldFloat("jitter", "A proportion of the duration to randomly adjust each sleep by, where `0` disables jitter and `0.5` results in sleeps between half and one and a half times the duration.").
+				HasDefault(0).AtVersion("4.24.0").Advanced().
+				LinterBlobl(`root = if this < 0 || this > 1 { [ "jitter must be between 0 and 1" ] }`),
 		),
+		Examples: []docs.AnnotatedExample{
+			{
+				Title:   "Honouring Retry-After",
+				Summary: "Here we call an API and, when we are rate limited, sleep for the duration specified by the `Retry-After` header of the response before continuing, with a small amount of jitter in order to avoid every message resuming at once.",
+				Config: `
+pipeline:
+  processors:
+    - http:
+        url: https://example.com/api
+        verb: POST
+    - switch:
+        - check: meta("http_status_code") == "429"
+          processors:
+            - sleep:
+                duration_mapping: root = meta("retry-after").or("5s")
+                jitter: 0.1
+`,
+			},
+		},
 	})
 	if err != nil {
 		panic(err)
@@ -38,10 +85,12 @@ func init() {
 }
 
 type sleepProc struct {
-	closeOnce   sync.Once
-	closeChan   chan struct{}
-	durationStr *field.Expression
-	log         log.Modular
+	closeOnce       sync.Once
+	closeChan       chan struct{}
+	durationStr     *field.Expression
+	durationMapping *mapping.Executor
+	jitter          float64
+	log             log.Modular
 }
 
 func newSleep(conf processor.SleepConfig, mgr bundle.NewManagement) (*sleepProc, error) {
@@ -49,24 +98,91 @@ func newSleep(conf processor.SleepConfig, mgr bundle.NewManagement) (*sleepProc,
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse duration expression: %v", err)
 	}
+	if conf.Jitter < 0 || conf.Jitter > 1 {
+		return nil, fmt.Errorf("jitter must be between 0 and 1, got %v", conf.Jitter)
+	}
 	t := &sleepProc{
 		closeChan:   make(chan struct{}),
 		durationStr: durationStr,
+		jitter:      conf.Jitter,
 		log:         mgr.Logger(),
 	}
+	if conf.DurationMapping != "" {
+		if t.durationMapping, err = mgr.BloblEnvironment().NewMapping(conf.DurationMapping); err != nil {
+			return nil, fmt.Errorf("failed to parse duration mapping: %w", err)
+		}
+	}
 	return t, nil
 }
 
-func (s *sleepProc) ProcessBatch(ctx *processor.BatchProcContext, msg message.Batch) ([]message.Batch, error) {
-	_ = msg.Iter(func(i int, p *message.Part) error {
+// durationFromValue converts the result of a duration mapping into a duration,
+// which can be a duration string, a number of seconds or a timestamp.
+func durationFromValue(v any) (time.Duration, error) {
+	switch t := v.(type) {
+	case string:
+		if d, err := time.ParseDuration(t); err == nil {
+			return d, nil
+		}
+		if f, err := strconv.ParseFloat(strings.TrimSpace(t), 64); err == nil {
+			return time.Duration(f * float64(time.Second)), nil
+		}
+		if ts, err := http.ParseTime(t); err == nil {
+			return time.Until(ts), nil
+		}
+		return 0, fmt.Errorf("failed to parse duration: %v", t)
+	case []byte:
+		return durationFromValue(string(t))
+	case time.Time:
+		return time.Until(t), nil
+	}
+	f, err := query.IGetNumber(v)
+	if err != nil {
+		return 0, query.NewTypeError(v, query.ValueString, query.ValueNumber, query.ValueTimestamp)
+	}
+	return time.Duration(f * float64(time.Second)), nil
+}
+
+func (s *sleepProc) period(i int, msg message.Batch) (time.Duration, error) {
+	var period time.Duration
+	if s.durationMapping != nil {
+		p, err := s.durationMapping.MapPart(i, msg)
+		if err != nil {
+			return 0, fmt.Errorf("duration mapping error: %w", err)
+		}
+		if p == nil {
+			return 0, errors.New("duration mapping error: result was deleted")
+		}
+		// String results are stored as raw bytes, which may not be valid JSON.
+		v, err := p.AsStructured()
+		if err != nil {
+			v = p.AsBytes()
+		}
+		if period, err = durationFromValue(v); err != nil {
+			return 0, fmt.Errorf("duration mapping error: %w", err)
+		}
+	} else {
 		periodStr, err := s.durationStr.String(i, msg)
 		if err != nil {
-			s.log.Errorf("Period interpolation error: %v", err)
-			return nil
+			return 0, fmt.Errorf("period interpolation error: %w", err)
 		}
-		period, err := time.ParseDuration(periodStr)
+		if period, err = time.ParseDuration(periodStr); err != nil {
+			return 0, fmt.Errorf("failed to parse duration: %w", err)
+		}
+	}
+	if s.jitter > 0 {
+		period = time.Duration(float64(period) * (1 + s.jitter*(2*rand.Float64()-1)))
+	}
+	if period < 0 {
+		period = 0
+	}
+	return period, nil
+}
+
+func (s *sleepProc) ProcessBatch(ctx *processor.BatchProcContext, msg message.Batch) ([]message.Batch, error) {
+	_ = msg.Iter(func(i int, p *message.Part) error {
+		period, err := s.period(i, msg)
 		if err != nil {
-			s.log.Errorf("Failed to parse duration: %v", err)
+			s.log.Errorf("Failed to determine sleep duration: %v", err)
 			return nil
 		}
 		select {
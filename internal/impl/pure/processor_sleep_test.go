@@ -101,3 +101,97 @@ func TestSleepInterpolated(t *testing.T) {
 		t.Errorf("Message didn't take long enough")
 	}
 }
+
+func TestSleepDurationMapping(t *testing.T) {
+	tests := map[string]struct {
+		mapping string
+		input   string
+		minDur  time.Duration
+		maxDur  time.Duration
+	}{
+		"duration string": {
+			mapping: `root = this.wait`,
+			input:   `{"wait":"100ms"}`,
+			minDur:  time.Millisecond * 100,
+			maxDur:  time.Second,
+		},
+		"seconds number": {
+			mapping: `root = this.wait`,
+			input:   `{"wait":0.1}`,
+			minDur:  time.Millisecond * 100,
+			maxDur:  time.Second,
+		},
+		"seconds string": {
+			mapping: `root = this.wait`,
+			input:   `{"wait":"0.1"}`,
+			minDur:  time.Millisecond * 100,
+			maxDur:  time.Second,
+		},
+		"timestamp": {
+			mapping: `root = now().ts_parse("2006-01-02T15:04:05.999999999Z07:00").ts_add_iso8601("PT0.1S")`,
+			input:   `{}`,
+			minDur:  time.Millisecond * 50,
+			maxDur:  time.Second,
+		},
+		"timestamp in the past": {
+			mapping: `root = "Mon, 02 Jan 2006 15:04:05 GMT"`,
+			input:   `{}`,
+			minDur:  0,
+			maxDur:  time.Millisecond * 100,
+		},
+		"unparseable": {
+			mapping: `root = "nope"`,
+			input:   `{}`,
+			minDur:  0,
+			maxDur:  time.Millisecond * 100,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			conf := processor.NewConfig()
+			conf.Type = "sleep"
+			conf.Sleep.Duration = "10s"
+			conf.Sleep.DurationMapping = test.mapping
+
+			slp, err := mock.NewManager().NewProcessor(conf)
+			require.NoError(t, err)
+
+			tBefore := time.Now()
+			batches, err := slp.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+				[]byte(test.input),
+			}))
+			dur := time.Since(tBefore)
+			require.NoError(t, err)
+			require.Len(t, batches, 1)
+
+			assert.GreaterOrEqual(t, dur, test.minDur)
+			assert.Less(t, dur, test.maxDur)
+		})
+	}
+}
+
+func TestSleepJitter(t *testing.T) {
+	conf := processor.NewConfig()
+	conf.Type = "sleep"
+	conf.Sleep.Duration = "20ms"
+	conf.Sleep.Jitter = 0.5
+
+	slp, err := mock.NewManager().NewProcessor(conf)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		tBefore := time.Now()
+		_, err := slp.ProcessBatch(context.Background(), message.QuickBatch([][]byte{[]byte(`hello`)}))
+		dur := time.Since(tBefore)
+		require.NoError(t, err)
+
+		assert.GreaterOrEqual(t, dur, time.Millisecond*10)
+		assert.Less(t, dur, time.Millisecond*500)
+	}
+
+	conf.Sleep.Jitter = 1.5
+	_, err = mock.NewManager().NewProcessor(conf)
+	require.Error(t, err)
+}
@@ -115,7 +115,7 @@ func newSwitchProc(conf processor.SwitchConfig, mgr bundle.NewManagement) (*swit
 		}
 
 		for j, procConf := range caseConf.Processors {
-			pMgr := mgr.IntoPath("switch", strconv.Itoa(i), "processors", strconv.Itoa(j))
+			pMgr := mgr.IntoPath("switch", strconv.Itoa(i), "processors", bundle.PathSegment(j, procConf.Label))
 			proc, err := pMgr.NewProcessor(procConf)
 			if err != nil {
 				return nil, fmt.Errorf("case [%v] processor [%v]: %w", i, j, err)
@@ -2,7 +2,6 @@ package pure
 
 import (
 	"context"
-	"strconv"
 
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
@@ -82,7 +81,7 @@ type tryProc struct {
 func newTryProc(conf []processor.Config, mgr bundle.NewManagement) (*tryProc, error) {
 	var children []processor.V1
 	for i, pconf := range conf {
-		pMgr := mgr.IntoPath("try", strconv.Itoa(i))
+		pMgr := mgr.IntoPath("try", bundle.PathSegment(i, pconf.Label))
 		proc, err := pMgr.NewProcessor(pconf)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,345 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	tcpFieldTry             = "try"
+	tcpFieldCatch           = "catch"
+	tcpFieldCatchErrorClass = "error_class"
+	tcpFieldCatchCheck      = "check"
+	tcpFieldCatchProcessors = "processors"
+	tcpFieldFinally         = "finally"
+)
+
+const (
+	tcpErrorClassAny         = ""
+	tcpErrorClassTimeout     = "timeout"
+	tcpErrorClassNetwork     = "network"
+	tcpErrorClassRateLimited = "rate_limited"
+	tcpErrorClassTransient   = "transient"
+	tcpErrorClassPermanent   = "permanent"
+)
+
+func tryCatchProcSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Composition").
+		Version("4.24.0").
+		Summary("Executes a list of child processors and routes messages that fail them to the first matching branch of a list of catch branches, followed by an optional list of processors that are executed on all messages.").
+		Description(`
+This processor combines the behaviour of the `+"[`try`](/docs/components/processors/try)"+` and `+"[`catch`](/docs/components/processors/catch)"+` processors, but allows for differentiated handling of errors. Processors of the `+"`try`"+` field are applied to each message of a batch until one of them fails, and messages that have failed (including those that failed a processor prior to this one) are then checked against each branch of `+"`catch`"+` in order.
+
+A message is caught by the first branch where both the `+"`error_class`"+` matches the class of its error and the `+"`check`"+` query returns `+"`true`"+`, and either can be omitted in order to match all errors. The processors of the branch are applied to the message and its error is cleared once they have finished, just like the `+"`catch`"+` processor. Messages that do not match any branch keep their error.
+
+Finally, the processors of `+"`finally`"+` are applied to all messages, regardless of whether they have failed or been caught.
+
+### Error Classes
+
+The class of an error is determined by the underlying type of the error, and is one of the following:
+
+- `+"`timeout`"+`: The operation timed out, for example a request deadline was exceeded.
+- `+"`network`"+`: A network operation failed, for example a connection was refused or reset.
+- `+"`rate_limited`"+`: An HTTP request was rejected with a 429 status code.
+- `+"`transient`"+`: Any of the above, an HTTP request resulting in a 5xx status code, or a component that is not connected. These errors are likely to be resolved by retrying.
+- `+"`permanent`"+`: Any error that is not transient, such as a failed mapping or schema validation, which is unlikely to be resolved by retrying.`).
+		Fields(
+			service.NewProcessorListField(tcpFieldTry).
+				Description("A list of processors to apply to messages, a message that fails a processor skips all subsequent processors of this list."),
+			service.NewObjectListField(tcpFieldCatch,
+				service.NewStringAnnotatedEnumField(tcpFieldCatchErrorClass, map[string]string{
+					tcpErrorClassAny:         "Matches errors of any class.",
+					tcpErrorClassTimeout:     "Matches errors caused by an operation timing out.",
+					tcpErrorClassNetwork:     "Matches errors caused by a failed network operation.",
+					tcpErrorClassRateLimited: "Matches errors caused by an HTTP request being rate limited.",
+					tcpErrorClassTransient:   "Matches errors that are likely to be resolved by retrying.",
+					tcpErrorClassPermanent:   "Matches errors that are not transient.",
+				}).
+					Description("The class of error that this branch catches.").
+					Default(tcpErrorClassAny),
+				service.NewBloblangField(tcpFieldCatchCheck).
+					Description("An optional [Bloblang query](/docs/guides/bloblang/about/) that should return a boolean value indicating whether a failed message should be caught by this branch. The error of the message can be accessed with the `error()` function.").
+					Examples(`error().contains("schema")`, `@http_status_code == "404"`).
+					Optional(),
+				service.NewProcessorListField(tcpFieldCatchProcessors).
+					Description("A list of processors to apply to caught messages."),
+			).
+				Description("A list of branches that are checked in order against each failed message, the first branch that matches a message is applied to it.").
+				Default([]any{}),
+			service.NewProcessorListField(tcpFieldFinally).
+				Description("A list of processors to apply to all messages after the `try` and `catch` stages.").
+				Default([]any{}),
+		).
+		Example("Differentiated Error Handling", "Enrich documents with an HTTP request, where documents that fail validation are annotated and forwarded, documents that fail the request due to a transient error are marked for a retry, and all other errors are left for the output to handle.", `
+pipeline:
+  processors:
+    - try_catch:
+        try:
+          - json_schema:
+              schema_path: file://schema.json
+          - branch:
+              request_map: 'root.id = this.id'
+              processors:
+                - http:
+                    url: https://example.com/enrich
+                    verb: POST
+              result_map: 'root.enrichment = this'
+        catch:
+          - check: 'error().contains("schema")'
+            processors:
+              - mapping: 'root.invalid = true'
+          - error_class: transient
+            processors:
+              - mapping: 'meta retry = "true"'
+        finally:
+          - log:
+              message: 'Processed document ${! this.id }'
+`)
+}
+
+func init() {
+	err := service.RegisterBatchProcessor(
+		"try_catch", tryCatchProcSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchProcessor, error) {
+			return newTryCatchProcFromParsed(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type tryCatchBranch struct {
+	errorClass string
+	check      *bloblang.Executor
+	procs      []*service.OwnedProcessor
+}
+
+type tryCatchProc struct {
+	log      *service.Logger
+	try      []*service.OwnedProcessor
+	branches []tryCatchBranch
+	finally  []*service.OwnedProcessor
+}
+
+func newTryCatchProcFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (t *tryCatchProc, err error) {
+	t = &tryCatchProc{log: mgr.Logger()}
+	if t.try, err = conf.FieldProcessorList(tcpFieldTry); err != nil {
+		return
+	}
+
+	var branchConfs []*service.ParsedConfig
+	if branchConfs, err = conf.FieldObjectList(tcpFieldCatch); err != nil {
+		return
+	}
+	for i, bConf := range branchConfs {
+		var b tryCatchBranch
+		if b.errorClass, err = bConf.FieldString(tcpFieldCatchErrorClass); err != nil {
+			return
+		}
+		if bConf.Contains(tcpFieldCatchCheck) {
+			if b.check, err = bConf.FieldBloblang(tcpFieldCatchCheck); err != nil {
+				return
+			}
+		}
+		if b.procs, err = bConf.FieldProcessorList(tcpFieldCatchProcessors); err != nil {
+			return nil, fmt.Errorf("catch branch %v: %w", i, err)
+		}
+		t.branches = append(t.branches, b)
+	}
+
+	if t.finally, err = conf.FieldProcessorList(tcpFieldFinally); err != nil {
+		return
+	}
+	return
+}
+
+func errorIsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, os.ErrDeadlineExceeded) ||
+		errors.Is(err, component.ErrTimeout) {
+		return true
+	}
+	var nErr net.Error
+	return errors.As(err, &nErr) && nErr.Timeout()
+}
+
+func errorIsNetwork(err error) bool {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return errors.As(err, &opErr) ||
+		errors.As(err, &dnsErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+func errorHTTPStatus(err error) int {
+	var hErr component.ErrUnexpectedHTTPRes
+	if errors.As(err, &hErr) {
+		return hErr.Code
+	}
+	return 0
+}
+
+// errorMatchesClass returns whether an error belongs to an error class.
+func errorMatchesClass(err error, class string) bool {
+	switch class {
+	case tcpErrorClassAny:
+		return true
+	case tcpErrorClassTimeout:
+		return errorIsTimeout(err)
+	case tcpErrorClassNetwork:
+		return errorIsNetwork(err)
+	case tcpErrorClassRateLimited:
+		return errorHTTPStatus(err) == http.StatusTooManyRequests
+	case tcpErrorClassTransient, tcpErrorClassPermanent:
+		var bErr *component.ErrBackOff
+		code := errorHTTPStatus(err)
+		transient := errorIsTimeout(err) ||
+			errorIsNetwork(err) ||
+			code == http.StatusTooManyRequests ||
+			code >= 500 ||
+			errors.As(err, &bErr) ||
+			errors.Is(err, component.ErrNotConnected)
+		return transient == (class == tcpErrorClassTransient)
+	}
+	return false
+}
+
+func (b *tryCatchBranch) matches(batch service.MessageBatch) (bool, error) {
+	if !errorMatchesClass(batch[0].GetError(), b.errorClass) {
+		return false, nil
+	}
+	if b.check == nil {
+		return true, nil
+	}
+
+	res, err := batch.BloblangQuery(0, b.check)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute check query: %w", err)
+	}
+	if res == nil {
+		return false, nil
+	}
+	v, err := res.AsStructured()
+	if err != nil {
+		return false, fmt.Errorf("check query did not return a structured result: %w", err)
+	}
+	matched, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("check query did not return a boolean result: %T", v)
+	}
+	return matched, nil
+}
+
+func (t *tryCatchProc) catch(ctx context.Context, batch service.MessageBatch) ([]service.MessageBatch, error) {
+	for i, b := range t.branches {
+		matched, err := b.matches(batch)
+		if err != nil {
+			// A branch that cannot be checked is skipped, and the message
+			// continues to be checked against subsequent branches.
+			t.log.Errorf("Catch branch %v: %v", i, err)
+			continue
+		}
+		if !matched {
+			continue
+		}
+
+		caught, err := service.ExecuteProcessors(ctx, b.procs, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, cb := range caught {
+			for _, m := range cb {
+				m.SetError(nil)
+			}
+		}
+		return caught, nil
+	}
+	return []service.MessageBatch{batch}, nil
+}
+
+func (t *tryCatchProc) ProcessBatch(ctx context.Context, batch service.MessageBatch) ([]service.MessageBatch, error) {
+	batches := make([]service.MessageBatch, len(batch))
+	for i, m := range batch {
+		batches[i] = service.MessageBatch{m}
+	}
+
+	for _, proc := range t.try {
+		var nextBatches []service.MessageBatch
+		for _, b := range batches {
+			// Skip messages that failed a prior stage.
+			if len(b) == 0 || b[0].GetError() != nil {
+				nextBatches = append(nextBatches, b)
+				continue
+			}
+			res, err := proc.ProcessBatch(ctx, b)
+			if err != nil {
+				return nil, err
+			}
+			nextBatches = append(nextBatches, res...)
+		}
+		batches = nextBatches
+	}
+
+	var caughtBatches []service.MessageBatch
+	for _, b := range batches {
+		if len(b) == 0 || b[0].GetError() == nil {
+			caughtBatches = append(caughtBatches, b)
+			continue
+		}
+		res, err := t.catch(ctx, b)
+		if err != nil {
+			return nil, err
+		}
+		caughtBatches = append(caughtBatches, res...)
+	}
+
+	resBatches, err := service.ExecuteProcessors(ctx, t.finally, caughtBatches...)
+	if err != nil {
+		return nil, err
+	}
+
+	var resBatch service.MessageBatch
+	for _, b := range resBatches {
+		resBatch = append(resBatch, b...)
+	}
+	if len(resBatch) == 0 {
+		return nil, nil
+	}
+	return []service.MessageBatch{resBatch}, nil
+}
+
+func (t *tryCatchProc) Close(ctx context.Context) error {
+	for _, p := range t.try {
+		if err := p.Close(ctx); err != nil {
+			return err
+		}
+	}
+	for _, b := range t.branches {
+		for _, p := range b.procs {
+			if err := p.Close(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	for _, p := range t.finally {
+		if err := p.Close(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
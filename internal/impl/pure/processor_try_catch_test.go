@@ -0,0 +1,170 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestTryCatchBranches(t *testing.T) {
+	conf, err := tryCatchProcSpec().ParseYAML(`
+try:
+  - mapping: 'root = if this.fail != null { throw(this.fail) } else { this }'
+  - mutation: 'root.tried = true'
+catch:
+  - error_class: transient
+    processors:
+      - mutation: 'root.caught = "transient"'
+  - check: 'error().contains("validation")'
+    processors:
+      - mutation: 'root.caught = "validation"'
+  - check: 'error().contains("boom")'
+    processors:
+      - mutation: 'root.caught = "boom"'
+      - mapping: 'root = throw("caught errors are cleared")'
+finally:
+  - mutation: 'meta finally = "true"'
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newTryCatchProcFromParsed(conf, service.MockResources())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, proc.Close(context.Background()))
+	})
+
+	batches, err := proc.ProcessBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"a"}`)),
+		service.NewMessage([]byte(`{"id":"b","fail":"validation failed"}`)),
+		service.NewMessage([]byte(`{"id":"c","fail":"boom"}`)),
+		service.NewMessage([]byte(`{"id":"d","fail":"unknown"}`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 4)
+
+	for i, exp := range []struct {
+		content string
+		err     string
+	}{
+		{content: `{"id":"a","tried":true}`},
+		{content: `{"caught":"validation","fail":"validation failed","id":"b"}`},
+		{content: `{"caught":"boom","fail":"boom","id":"c"}`},
+		{content: `{"fail":"unknown","id":"d"}`, err: "unknown"},
+	} {
+		msg := batches[0][i]
+
+		b, err := msg.AsBytes()
+		require.NoError(t, err)
+		assert.Equal(t, exp.content, string(b), i)
+
+		if exp.err == "" {
+			assert.NoError(t, msg.GetError(), i)
+		} else {
+			require.Error(t, msg.GetError(), i)
+			assert.Contains(t, msg.GetError().Error(), exp.err, i)
+		}
+
+		v, ok := msg.MetaGet("finally")
+		assert.True(t, ok, i)
+		assert.Equal(t, "true", v, i)
+	}
+}
+
+func TestTryCatchPriorErrors(t *testing.T) {
+	conf, err := tryCatchProcSpec().ParseYAML(`
+try:
+  - mapping: 'root = "tried"'
+catch:
+  - processors:
+      - mapping: 'root = "caught: " + error()'
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newTryCatchProcFromParsed(conf, service.MockResources())
+	require.NoError(t, err)
+
+	msg := service.NewMessage([]byte("hello"))
+	msg.SetError(errors.New("prior failure"))
+
+	batches, err := proc.ProcessBatch(context.Background(), service.MessageBatch{msg})
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 1)
+
+	b, err := batches[0][0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "caught: prior failure", string(b))
+	assert.NoError(t, batches[0][0].GetError())
+}
+
+func TestTryCatchErrorClasses(t *testing.T) {
+	opErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+	tests := []struct {
+		name    string
+		err     error
+		classes []string
+	}{
+		{
+			name:    "deadline exceeded",
+			err:     fmt.Errorf("request failed: %w", context.DeadlineExceeded),
+			classes: []string{"timeout", "transient"},
+		},
+		{
+			name:    "connection refused",
+			err:     opErr,
+			classes: []string{"network", "transient"},
+		},
+		{
+			name:    "not connected",
+			err:     component.ErrNotConnected,
+			classes: []string{"transient"},
+		},
+		{
+			name:    "http too many requests",
+			err:     component.ErrUnexpectedHTTPRes{Code: 429},
+			classes: []string{"rate_limited", "transient"},
+		},
+		{
+			name:    "http server error",
+			err:     component.ErrUnexpectedHTTPRes{Code: 503},
+			classes: []string{"transient"},
+		},
+		{
+			name:    "http bad request",
+			err:     component.ErrUnexpectedHTTPRes{Code: 400},
+			classes: []string{"permanent"},
+		},
+		{
+			name:    "generic error",
+			err:     errors.New("failed to parse document"),
+			classes: []string{"permanent"},
+		},
+	}
+
+	allClasses := []string{"timeout", "network", "rate_limited", "transient", "permanent"}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			assert.True(t, errorMatchesClass(test.err, ""))
+
+			var matched []string
+			for _, c := range allClasses {
+				if errorMatchesClass(test.err, c) {
+					matched = append(matched, c)
+				}
+			}
+			assert.Equal(t, test.classes, matched)
+		})
+	}
+}
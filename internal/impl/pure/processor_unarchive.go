@@ -4,15 +4,20 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"path"
 	"strings"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/tracing"
 	"github.com/benthosdev/benthos/v4/public/service"
 )
 
@@ -26,10 +31,28 @@ When a message is unarchived the new messages replace the original message in th
 
 ## Metadata
 
-The metadata found on the messages handled by this processor will be copied into the resulting messages. For the unarchive formats that contain file information (tar, zip), a metadata field is also added to each message called ` + "`archive_filename`" + ` with the extracted filename.
+The metadata found on the messages handled by this processor will be copied into the resulting messages. For the unarchive formats that contain file information (tar, tar.gz, zip), the following metadata fields are also added to each message:
+
+` + "```text" + `
+- archive_filename
+- archive_dir
+- archive_basename
+- archive_size
+` + "```" + `
+
+Where ` + "`archive_filename`" + ` is the full path of the entry within the archive, ` + "`archive_dir`" + ` and ` + "`archive_basename`" + ` are the directory and final element of that path respectively, and ` + "`archive_size`" + ` is the uncompressed size of the entry in bytes.
+
+## Size Limits
+
+Compressed archives can expand to many times their original size, which can be exploited (for example with a zip bomb) in order to exhaust the memory of a Benthos instance. The field ` + "`max_decompressed_size`" + ` can be used in order to cap the total number of bytes extracted from a single tar, tar.gz or zip archive, where archives that exceed the limit fail to unarchive.
+
+## Streaming Archives
+
+This processor extracts the entries of an archive from a single message and therefore requires the whole archive and all of its entries to fit in memory. Inputs that consume files, such as ` + "`file`" + ` and ` + "`aws_s3`" + `, can instead extract tar archives as they are read with the codec ` + "`tar`" + `, or ` + "`gzip/tar`" + ` for tar.gz archives, which only holds a single entry in memory at a time and adds the same ` + "`archive_*`" + ` metadata fields to each message.
 `).
 		Field(service.NewStringAnnotatedEnumField("format", map[string]string{
 			`tar`:            `Extract messages from a unix standard tape archive.`,
+			`tar.gz`:         `Extract messages from a gzip compressed unix standard tape archive. The whole message is held in memory along with every extracted entry, and so the ` + "`max_decompressed_size`" + ` field should be used to limit the size of archives. Large archives can instead be [extracted as they are read](#streaming-archives) by an input.`,
 			`zip`:            `Extract messages from a zip file.`,
 			`binary`:         `Extract messages from a [binary blob format](https://github.com/benthosdev/benthos/blob/main/internal/message/message.go#L96).`,
 			`lines`:          `Extract the lines of a message each into their own message.`,
@@ -38,7 +61,13 @@ The metadata found on the messages handled by this processor will be copied into
 			`json_map`:       `Attempt to parse the message as a JSON map and for each element of the map expands its contents into a new message. A metadata field is added to each message called ` + "`archive_key`" + ` with the relevant key from the top-level map.`,
 			`csv`:            `Attempt to parse the message as a csv file (header required) and for each row in the file expands its contents into a json object in a new message.`,
 			`csv:x`:          `Attempt to parse the message as a csv file (header required) and for each row in the file expands its contents into a json object in a new message using a custom delimiter. The custom delimiter must be a single character, e.g. the format "csv:\t" would consume a tab delimited file.`,
-		}).Description("The unarchiving format to apply."))
+		}).Description("The unarchiving format to apply.")).
+		Field(service.NewIntField("max_decompressed_size").
+			Description("The maximum total number of bytes that may be extracted from a single tar, tar.gz or zip archive. Archives that exceed this limit fail to unarchive. Set to zero in order to disable the limit.").
+			Example(104857600).
+			Advanced().
+			Version("4.24.0").
+			Default(0))
 }
 
 func init() {
@@ -54,14 +83,67 @@ func init() {
 
 type unarchiveFunc func(part *service.Message) (service.MessageBatch, error)
 
-func tarUnarchive(part *service.Message) (service.MessageBatch, error) {
-	pBytes, err := part.AsBytes()
+// sizeLimiter tracks the total number of bytes extracted from an archive and
+// fails once a configured maximum is exceeded.
+type sizeLimiter struct {
+	max  int64
+	read int64
+}
+
+func (l *sizeLimiter) readAll(r io.Reader) ([]byte, error) {
+	if l.max <= 0 {
+		return io.ReadAll(r)
+	}
+
+	// Read at most one byte more than the remaining budget so that we can
+	// detect when it has been exceeded without reading the remainder.
+	b, err := io.ReadAll(io.LimitReader(r, l.max-l.read+1))
 	if err != nil {
 		return nil, err
 	}
+	if l.read += int64(len(b)); l.read > l.max {
+		return nil, fmt.Errorf("archive exceeds the maximum decompressed size of %v bytes", l.max)
+	}
+	return b, nil
+}
 
-	buf := bytes.NewBuffer(pBytes)
-	tr := tar.NewReader(buf)
+func setArchiveEntryMeta(part *service.Message, name string, size int) {
+	part.MetaSet("archive_filename", name)
+	part.MetaSet("archive_dir", path.Dir(name))
+	part.MetaSet("archive_basename", path.Base(name))
+	part.MetaSetMut("archive_size", size)
+}
+
+func tarUnarchive(maxSize int64) unarchiveFunc {
+	return func(part *service.Message) (service.MessageBatch, error) {
+		pBytes, err := part.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		return tarUnarchiveFrom(part, bytes.NewReader(pBytes), maxSize)
+	}
+}
+
+func tarGzipUnarchive(maxSize int64) unarchiveFunc {
+	return func(part *service.Message) (service.MessageBatch, error) {
+		pBytes, err := part.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+
+		gr, err := gzip.NewReader(bytes.NewReader(pBytes))
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+
+		return tarUnarchiveFrom(part, gr, maxSize)
+	}
+}
+
+func tarUnarchiveFrom(part *service.Message, r io.Reader, maxSize int64) (service.MessageBatch, error) {
+	tr := tar.NewReader(r)
+	limiter := sizeLimiter{max: maxSize}
 
 	var newParts []*service.Message
 
@@ -76,53 +158,70 @@ func tarUnarchive(part *service.Message) (service.MessageBatch, error) {
 			return nil, err
 		}
 
-		newPartBuf := bytes.Buffer{}
-		if _, err = newPartBuf.ReadFrom(tr); err != nil {
+		b, err := limiter.readAll(tr)
+		if err != nil {
 			return nil, err
 		}
 
 		newPart := part.Copy()
-		newPart.SetBytes(newPartBuf.Bytes())
-		newPart.MetaSet("archive_filename", h.Name)
+		newPart.SetBytes(b)
+		setArchiveEntryMeta(newPart, h.Name, len(b))
 		newParts = append(newParts, newPart)
 	}
 
 	return newParts, nil
 }
 
-func zipUnarchive(part *service.Message) (service.MessageBatch, error) {
-	pBytes, err := part.AsBytes()
-	if err != nil {
-		return nil, err
-	}
-
-	buf := bytes.NewReader(pBytes)
-	zr, err := zip.NewReader(buf, int64(buf.Len()))
-	if err != nil {
-		return nil, err
-	}
-
-	var newParts service.MessageBatch
-
-	// Iterate through the files in the archive.
-	for _, f := range zr.File {
-		fr, err := f.Open()
+func zipUnarchive(maxSize int64) unarchiveFunc {
+	return func(part *service.Message) (service.MessageBatch, error) {
+		pBytes, err := part.AsBytes()
 		if err != nil {
 			return nil, err
 		}
 
-		newPartBuf := bytes.Buffer{}
-		if _, err = newPartBuf.ReadFrom(fr); err != nil {
+		buf := bytes.NewReader(pBytes)
+		zr, err := zip.NewReader(buf, int64(buf.Len()))
+		if err != nil {
 			return nil, err
 		}
 
-		newPart := part.Copy()
-		newPart.SetBytes(newPartBuf.Bytes())
-		newPart.MetaSet("archive_filename", f.Name)
-		newParts = append(newParts, newPart)
-	}
+		// Reject archives that declare a total size beyond the limit before
+		// decompressing anything. The declared sizes can't be trusted and so
+		// the limit is also enforced whilst reading.
+		if maxSize > 0 {
+			var declared uint64
+			for _, f := range zr.File {
+				declared += f.UncompressedSize64
+			}
+			if declared > uint64(maxSize) {
+				return nil, fmt.Errorf("archive exceeds the maximum decompressed size of %v bytes", maxSize)
+			}
+		}
 
-	return newParts, nil
+		limiter := sizeLimiter{max: maxSize}
+		var newParts service.MessageBatch
+
+		// Iterate through the files in the archive.
+		for _, f := range zr.File {
+			fr, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+
+			b, err := limiter.readAll(fr)
+			fr.Close()
+			if err != nil {
+				return nil, err
+			}
+
+			newPart := part.Copy()
+			newPart.SetBytes(b)
+			setArchiveEntryMeta(newPart, f.Name, len(b))
+			newParts = append(newParts, newPart)
+		}
+
+		return newParts, nil
+	}
 }
 
 func binaryUnarchive(part *service.Message) (service.MessageBatch, error) {
@@ -284,12 +383,14 @@ func csvUnarchive(customComma *rune) func(*service.Message) (service.MessageBatc
 	}
 }
 
-func strToUnarchiver(str string) (unarchiveFunc, error) {
+func strToUnarchiver(str string, maxSize int64) (unarchiveFunc, error) {
 	switch str {
 	case "tar":
-		return tarUnarchive, nil
+		return tarUnarchive(maxSize), nil
+	case "tar.gz":
+		return tarGzipUnarchive(maxSize), nil
 	case "zip":
-		return zipUnarchive, nil
+		return zipUnarchive(maxSize), nil
 	case "binary":
 		return binaryUnarchive, nil
 	case "lines":
@@ -325,6 +426,7 @@ func strToUnarchiver(str string) (unarchiveFunc, error) {
 type unarchiveProc struct {
 	unarchive unarchiveFunc
 	log       *service.Logger
+	tracer    trace.TracerProvider
 }
 
 func newUnarchiveFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*unarchiveProc, error) {
@@ -332,17 +434,22 @@ func newUnarchiveFromParsed(conf *service.ParsedConfig, mgr *service.Resources)
 	if err != nil {
 		return nil, err
 	}
-	return newUnarchive(mgr, formatStr)
+	maxSize, err := conf.FieldInt("max_decompressed_size")
+	if err != nil {
+		return nil, err
+	}
+	return newUnarchive(mgr, formatStr, int64(maxSize))
 }
 
-func newUnarchive(nm *service.Resources, format string) (*unarchiveProc, error) {
-	unarchiver, err := strToUnarchiver(format)
+func newUnarchive(nm *service.Resources, format string, maxSize int64) (*unarchiveProc, error) {
+	unarchiver, err := strToUnarchiver(format, maxSize)
 	if err != nil {
 		return nil, err
 	}
 	return &unarchiveProc{
 		unarchive: unarchiver,
 		log:       nm.Logger(),
+		tracer:    nm.OtelTracer(),
 	}, nil
 }
 
@@ -352,6 +459,14 @@ func (d *unarchiveProc) Process(ctx context.Context, msg *service.Message) (serv
 		d.log.Errorf("Failed to unarchive message part: %v\n", err)
 		return nil, err
 	}
+	if len(newParts) > 1 {
+		// Give each extracted message a span of its own so that the work
+		// performed on them can be told apart within the trace of the archive.
+		ctxs := tracing.WithSplitSpans(d.tracer, "unarchive_split", msg.Context(), len(newParts))
+		for i, p := range newParts {
+			newParts[i] = p.WithContext(ctxs[i])
+		}
+	}
 	return newParts, nil
 }
 
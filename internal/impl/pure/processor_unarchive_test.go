@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
 	"testing"
@@ -140,6 +141,121 @@ format: zip
 	}
 }
 
+func TestUnarchiveTarGzip(t *testing.T) {
+	conf, err := unarchiveProcConfig().ParseYAML(`
+format: tar.gz
+`, nil)
+	require.NoError(t, err)
+
+	input := map[string]string{
+		"foo/bar/first.txt": "hello world first part",
+		"foo/second.txt":    "hello world second part",
+		"third.txt":         "third part",
+	}
+	names := []string{"foo/bar/first.txt", "foo/second.txt", "third.txt"}
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, name := range names {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0o600,
+			Size: int64(len(input[name])),
+		}))
+		_, err := tw.Write([]byte(input[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	proc, err := newUnarchiveFromParsed(conf, service.MockResources())
+	require.NoError(t, err)
+
+	msgs, err := proc.Process(context.Background(), service.NewMessage(buf.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, msgs, len(names))
+
+	expDirs := []string{"foo/bar", "foo", "."}
+	expBases := []string{"first.txt", "second.txt", "third.txt"}
+	for i, name := range names {
+		mBytes, err := msgs[i].AsBytes()
+		require.NoError(t, err)
+		assert.Equal(t, input[name], string(mBytes))
+
+		v, _ := msgs[i].MetaGet("archive_filename")
+		assert.Equal(t, name, v)
+
+		v, _ = msgs[i].MetaGet("archive_dir")
+		assert.Equal(t, expDirs[i], v)
+
+		v, _ = msgs[i].MetaGet("archive_basename")
+		assert.Equal(t, expBases[i], v)
+
+		size, _ := msgs[i].MetaGetMut("archive_size")
+		assert.Equal(t, len(input[name]), size)
+	}
+}
+
+func TestUnarchiveMaxDecompressedSize(t *testing.T) {
+	// Highly compressible content that expands well beyond the limit.
+	content := bytes.Repeat([]byte("a"), 1<<20)
+
+	var tarBuf bytes.Buffer
+	gw := gzip.NewWriter(&tarBuf)
+	tw := tar.NewWriter(gw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "bomb", Mode: 0o600, Size: int64(len(content))}))
+	_, err := tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	fw, err := zw.Create("bomb")
+	require.NoError(t, err)
+	_, err = fw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	for _, test := range []struct {
+		format string
+		data   []byte
+	}{
+		{format: "tar.gz", data: tarBuf.Bytes()},
+		{format: "zip", data: zipBuf.Bytes()},
+	} {
+		test := test
+		t.Run(test.format, func(t *testing.T) {
+			conf, err := unarchiveProcConfig().ParseYAML(fmt.Sprintf(`
+format: %v
+max_decompressed_size: 1024
+`, test.format), nil)
+			require.NoError(t, err)
+
+			proc, err := newUnarchiveFromParsed(conf, service.MockResources())
+			require.NoError(t, err)
+
+			_, err = proc.Process(context.Background(), service.NewMessage(test.data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "maximum decompressed size")
+
+			conf, err = unarchiveProcConfig().ParseYAML(fmt.Sprintf(`
+format: %v
+max_decompressed_size: %v
+`, test.format, len(content)), nil)
+			require.NoError(t, err)
+
+			proc, err = newUnarchiveFromParsed(conf, service.MockResources())
+			require.NoError(t, err)
+
+			msgs, err := proc.Process(context.Background(), service.NewMessage(test.data))
+			require.NoError(t, err)
+			require.Len(t, msgs, 1)
+		})
+	}
+}
+
 func TestUnarchiveLines(t *testing.T) {
 	conf, err := unarchiveProcConfig().ParseYAML(`
 format: lines
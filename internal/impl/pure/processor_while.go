@@ -79,7 +79,7 @@ func newWhile(conf processor.WhileConfig, mgr bundle.NewManagement) (*whileProc,
 
 	var children []processor.V1
 	for i, pconf := range conf.Processors {
-		pMgr := mgr.IntoPath("while", "processors", strconv.Itoa(i))
+		pMgr := mgr.IntoPath("while", "processors", bundle.PathSegment(i, pconf.Label))
 		proc, err := pMgr.NewProcessor(pconf)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,328 @@
+package qdrant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	qoFieldURL            = "url"
+	qoFieldAPIKey         = "api_key"
+	qoFieldCollection     = "collection"
+	qoFieldID             = "id"
+	qoFieldVectorMapping  = "vector_mapping"
+	qoFieldVectorName     = "vector_name"
+	qoFieldPayloadMapping = "payload_mapping"
+	qoFieldWait           = "wait"
+	qoFieldMaxBatchSize   = "max_batch_size"
+	qoFieldTimeout        = "timeout"
+	qoFieldBatching       = "batching"
+)
+
+func qdrantOutputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Services").
+		Version("4.24.0").
+		Summary("Upserts points into a [Qdrant](https://qdrant.tech/) collection.").
+		Description(output.Description(true, true, `
+A point is upserted for each message with an ID, a vector of numbers obtained with the `+"`vector_mapping`"+`, and an optional payload obtained with the `+"`payload_mapping`"+`. Points are upserted by their ID, and therefore writing a point with an ID that already exists within the collection replaces it. Qdrant requires IDs to be either unsigned integers or UUIDs, and IDs that can be parsed as unsigned integers are written as such.
+
+Points of a batch are written with a single request, unless the batch is larger than `+"`max_batch_size`"+` in which case it is split into multiple requests.
+
+This output pairs well with the `+"[`openai_embeddings` processor](/docs/components/processors/openai_embeddings)"+`, which generates embeddings from the contents of messages.`)).
+		Fields(
+			service.NewURLField(qoFieldURL).
+				Description("The URL of the Qdrant REST API.").
+				Default("http://localhost:6333"),
+			service.NewStringField(qoFieldAPIKey).
+				Description("An optional API key used to authenticate requests.").
+				Secret().
+				Optional(),
+			service.NewStringField(qoFieldCollection).
+				Description("The collection to upsert points into.").
+				Example("documents"),
+			service.NewInterpolatedStringField(qoFieldID).
+				Description("The ID of each point, which must be either an unsigned integer or a UUID.").
+				Example("${! this.id }").
+				Example("${! uuid_v4() }"),
+			service.NewBloblangField(qoFieldVectorMapping).
+				Description("A [Bloblang mapping](/docs/guides/bloblang/about) that results in an array of numbers representing the vector of each message.").
+				Example("root = this.embedding").
+				Example("root = this"),
+			service.NewStringField(qoFieldVectorName).
+				Description("An optional name of the vector to write, for collections configured with multiple named vectors.").
+				Example("text").
+				Optional().
+				Advanced(),
+			service.NewBloblangField(qoFieldPayloadMapping).
+				Description("An optional [Bloblang mapping](/docs/guides/bloblang/about) that results in an object to store as the payload of each point.").
+				Example(`root = { "title": this.title, "url": this.url }`).
+				Optional(),
+			service.NewBoolField(qoFieldWait).
+				Description("Whether to wait for upserts to be applied before acknowledging messages.").
+				Default(true).
+				Advanced(),
+			service.NewIntField(qoFieldMaxBatchSize).
+				Description("The maximum number of points to upsert with each request, batches larger than this are split into multiple requests.").
+				Default(100).
+				Advanced(),
+			service.NewDurationField(qoFieldTimeout).
+				Description("The maximum period to wait for each request to complete.").
+				Default("30s").
+				Advanced(),
+			service.NewOutputMaxInFlightField(),
+			service.NewBatchPolicyField(qoFieldBatching),
+		).
+		Example(
+			"Index Documents",
+			"Here we generate embeddings of documents and upsert them into a Qdrant collection along with their titles.",
+			`
+pipeline:
+  processors:
+    - branch:
+        request_map: 'root = this.body'
+        processors:
+          - openai_embeddings:
+              api_key: "${OPENAI_API_KEY}"
+              model: text-embedding-3-small
+        result_map: 'root.embedding = this'
+
+output:
+  qdrant:
+    url: http://localhost:6333
+    collection: documents
+    id: ${! this.id }
+    vector_mapping: root = this.embedding
+    payload_mapping: root.title = this.title
+    batching:
+      count: 100
+      period: 1s
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchOutput(
+		"qdrant", qdrantOutputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, maxInFlight int, err error) {
+			if batchPolicy, err = conf.FieldBatchPolicy(qoFieldBatching); err != nil {
+				return
+			}
+			if maxInFlight, err = conf.FieldMaxInFlight(); err != nil {
+				return
+			}
+			out, err = newQdrantOutputFromConfig(conf, mgr)
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type point struct {
+	ID      any            `json:"id"`
+	Vector  any            `json:"vector"`
+	Payload map[string]any `json:"payload,omitempty"`
+}
+
+type upsertRequest struct {
+	Points []point `json:"points"`
+}
+
+type qdrantOutput struct {
+	upsertURL string
+	apiKey    string
+
+	id             *service.InterpolatedString
+	vectorMapping  *bloblang.Executor
+	vectorName     string
+	payloadMapping *bloblang.Executor
+	maxBatchSize   int
+
+	client *http.Client
+	log    *service.Logger
+}
+
+func newQdrantOutputFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*qdrantOutput, error) {
+	q := &qdrantOutput{log: mgr.Logger()}
+
+	baseURL, err := conf.FieldString(qoFieldURL)
+	if err != nil {
+		return nil, err
+	}
+	collection, err := conf.FieldString(qoFieldCollection)
+	if err != nil {
+		return nil, err
+	}
+	wait, err := conf.FieldBool(qoFieldWait)
+	if err != nil {
+		return nil, err
+	}
+	q.upsertURL = fmt.Sprintf("%v/collections/%v/points?wait=%v", strings.TrimSuffix(baseURL, "/"), url.PathEscape(collection), wait)
+
+	if conf.Contains(qoFieldAPIKey) {
+		if q.apiKey, err = conf.FieldString(qoFieldAPIKey); err != nil {
+			return nil, err
+		}
+	}
+	if q.id, err = conf.FieldInterpolatedString(qoFieldID); err != nil {
+		return nil, err
+	}
+	if q.vectorMapping, err = conf.FieldBloblang(qoFieldVectorMapping); err != nil {
+		return nil, err
+	}
+	if conf.Contains(qoFieldVectorName) {
+		if q.vectorName, err = conf.FieldString(qoFieldVectorName); err != nil {
+			return nil, err
+		}
+	}
+	if conf.Contains(qoFieldPayloadMapping) {
+		if q.payloadMapping, err = conf.FieldBloblang(qoFieldPayloadMapping); err != nil {
+			return nil, err
+		}
+	}
+	if q.maxBatchSize, err = conf.FieldInt(qoFieldMaxBatchSize); err != nil {
+		return nil, err
+	}
+	if q.maxBatchSize < 1 {
+		return nil, fmt.Errorf("%v must be greater than zero, got %v", qoFieldMaxBatchSize, q.maxBatchSize)
+	}
+
+	timeout, err := conf.FieldDuration(qoFieldTimeout)
+	if err != nil {
+		return nil, err
+	}
+	q.client = &http.Client{Timeout: timeout}
+	return q, nil
+}
+
+func (q *qdrantOutput) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (q *qdrantOutput) point(batch service.MessageBatch, i int) (p point, err error) {
+	idStr, err := batch.TryInterpolatedString(i, q.id)
+	if err != nil {
+		return p, fmt.Errorf("id interpolation error: %w", err)
+	}
+	if idStr == "" {
+		return p, fmt.Errorf("id interpolation resulted in an empty string")
+	}
+	if idInt, err := strconv.ParseUint(idStr, 10, 64); err == nil {
+		p.ID = idInt
+	} else {
+		p.ID = idStr
+	}
+
+	vecMsg, err := batch.BloblangQuery(i, q.vectorMapping)
+	if err != nil {
+		return p, fmt.Errorf("vector mapping failed: %w", err)
+	}
+	vecV, err := vecMsg.AsStructured()
+	if err != nil {
+		return p, fmt.Errorf("vector mapping returned non-structured result: %w", err)
+	}
+	vecArr, ok := vecV.([]any)
+	if !ok {
+		return p, fmt.Errorf("vector mapping returned non-array result: %T", vecV)
+	}
+	values := make([]float64, len(vecArr))
+	for j, e := range vecArr {
+		if values[j], err = query.IToFloat64(e); err != nil {
+			return p, fmt.Errorf("vector mapping returned non-numerical value at index %v: %w", j, err)
+		}
+	}
+	if q.vectorName != "" {
+		p.Vector = map[string]any{q.vectorName: values}
+	} else {
+		p.Vector = values
+	}
+
+	if q.payloadMapping == nil {
+		return p, nil
+	}
+	payloadMsg, err := batch.BloblangQuery(i, q.payloadMapping)
+	if err != nil {
+		return p, fmt.Errorf("payload mapping failed: %w", err)
+	}
+	payloadV, err := payloadMsg.AsStructured()
+	if err != nil {
+		return p, fmt.Errorf("payload mapping returned non-structured result: %w", err)
+	}
+	if p.Payload, ok = payloadV.(map[string]any); !ok {
+		return p, fmt.Errorf("payload mapping returned non-object result: %T", payloadV)
+	}
+	return p, nil
+}
+
+func (q *qdrantOutput) upsert(ctx context.Context, points []point) error {
+	reqBytes, err := json.Marshal(upsertRequest{Points: points})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, q.upsertURL, bytes.NewReader(reqBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if q.apiKey != "" {
+		req.Header.Set("api-key", q.apiKey)
+	}
+
+	res, err := q.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		resBytes, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("upsert failed with status %v: %s", res.StatusCode, bytes.TrimSpace(resBytes))
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+func (q *qdrantOutput) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	points := make([]point, 0, len(batch))
+	for i := range batch {
+		p, err := q.point(batch, i)
+		if err != nil {
+			return err
+		}
+		points = append(points, p)
+	}
+
+	for len(points) > 0 {
+		n := len(points)
+		if n > q.maxBatchSize {
+			n = q.maxBatchSize
+		}
+		if err := q.upsert(ctx, points[:n]); err != nil {
+			return err
+		}
+		points = points[n:]
+	}
+	return nil
+}
+
+func (q *qdrantOutput) Close(ctx context.Context) error {
+	q.client.CloseIdleConnections()
+	return nil
+}
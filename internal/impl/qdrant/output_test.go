@@ -0,0 +1,99 @@
+package qdrant
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestQdrantOutput(t *testing.T) {
+	var reqs []any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/collections/foo/points", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("wait"))
+		assert.Equal(t, "fookey", r.Header.Get("api-key"))
+
+		var req any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		reqs = append(reqs, req)
+
+		_, _ = w.Write([]byte(`{"result":{"status":"completed"},"status":"ok"}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	conf, err := qdrantOutputConfig().ParseYAML(`
+url: `+ts.URL+`
+api_key: fookey
+collection: foo
+id: ${! this.id }
+vector_mapping: root = this.vec
+vector_name: text
+payload_mapping: root.title = this.title
+`, nil)
+	require.NoError(t, err)
+
+	out, err := newQdrantOutputFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+	require.NoError(t, out.Connect(context.Background()))
+
+	require.NoError(t, out.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"10","vec":[1,2],"title":"foo"}`)),
+		service.NewMessage([]byte(`{"id":"5c56c793-69f3-4fbf-87e6-c4bf54c28c26","vec":[3,4.5],"title":"bar"}`)),
+	}))
+
+	assert.Equal(t, []any{
+		map[string]any{
+			"points": []any{
+				map[string]any{
+					"id":      10.0,
+					"vector":  map[string]any{"text": []any{1.0, 2.0}},
+					"payload": map[string]any{"title": "foo"},
+				},
+				map[string]any{
+					"id":      "5c56c793-69f3-4fbf-87e6-c4bf54c28c26",
+					"vector":  map[string]any{"text": []any{3.0, 4.5}},
+					"payload": map[string]any{"title": "bar"},
+				},
+			},
+		},
+	}, reqs)
+
+	require.NoError(t, out.Close(context.Background()))
+}
+
+func TestQdrantOutputBatchSize(t *testing.T) {
+	var sizes []int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req upsertRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		sizes = append(sizes, len(req.Points))
+	}))
+	t.Cleanup(ts.Close)
+
+	conf, err := qdrantOutputConfig().ParseYAML(`
+url: `+ts.URL+`
+collection: foo
+id: ${! batch_index() }
+vector_mapping: root = [ 1, 2 ]
+max_batch_size: 2
+`, nil)
+	require.NoError(t, err)
+
+	out, err := newQdrantOutputFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	var batch service.MessageBatch
+	for i := 0; i < 5; i++ {
+		batch = append(batch, service.NewMessage([]byte(`{}`)))
+	}
+	require.NoError(t, out.WriteBatch(context.Background(), batch))
+	assert.Equal(t, []int{2, 2, 1}, sizes)
+}
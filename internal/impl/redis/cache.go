@@ -30,12 +30,26 @@ func redisCacheConfig() *service.ConfigSpec {
 		Field(service.NewStringField("prefix").
 			Description("An optional string to prefix item keys with in order to prevent collisions with similar services.").
 			Optional()).
+		Field(service.NewBoolField("prefix_hash_tag").
+			Description("Whether the `prefix` should be wrapped in a [hash tag](https://redis.io/docs/reference/cluster-spec/#hash-tags) (`{prefix}`), which ensures that all items of the cache are stored on the same node of a cluster.").
+			Default(false).
+			Advanced().
+			Version("4.24.0")).
 		Field(service.NewDurationField("default_ttl").
 			Description("An optional default TTL to set for items, calculated from the moment the item is cached.").
 			Optional().
 			Advanced()).
+		Field(service.NewBoolField("refresh_ttl_on_get").
+			Description("Whether the TTL of an item should be reset to the `default_ttl` each time it is read, using the GETEX command (Redis 6.2+), which allows frequently read items to remain cached.").
+			Default(false).
+			Advanced().
+			Version("4.24.0")).
 		Field(service.NewBackOffField("retries", false, retriesDefaults).
-			Advanced())
+			Advanced()).
+		LintRule(`root = match {
+  this.prefix_hash_tag.or(false) && this.prefix.or("") == "" => "a prefix must be set when prefix_hash_tag is enabled"
+  this.refresh_ttl_on_get.or(false) && this.default_ttl.or("") == "" => "a default_ttl must be set when refresh_ttl_on_get is enabled"
+}`)
 
 	return spec
 }
@@ -64,6 +78,17 @@ func newRedisCacheFromConfig(conf *service.ParsedConfig) (*redisCache, error) {
 		}
 	}
 
+	hashTag, err := conf.FieldBool("prefix_hash_tag")
+	if err != nil {
+		return nil, err
+	}
+	if hashTag {
+		if prefix == "" {
+			return nil, errors.New("a prefix must be set when prefix_hash_tag is enabled")
+		}
+		prefix = "{" + prefix + "}"
+	}
+
 	var ttl time.Duration
 	if conf.Contains("default_ttl") {
 		ttlTmp, err := conf.FieldDuration("default_ttl")
@@ -73,11 +98,24 @@ func newRedisCacheFromConfig(conf *service.ParsedConfig) (*redisCache, error) {
 		ttl = ttlTmp
 	}
 
+	refreshTTL, err := conf.FieldBool("refresh_ttl_on_get")
+	if err != nil {
+		return nil, err
+	}
+	if refreshTTL && ttl <= 0 {
+		return nil, errors.New("a default_ttl must be set when refresh_ttl_on_get is enabled")
+	}
+
 	backOff, err := conf.FieldBackOff("retries")
 	if err != nil {
 		return nil, err
 	}
-	return newRedisCache(ttl, prefix, client, backOff)
+	c, err := newRedisCache(ttl, prefix, client, backOff)
+	if err != nil {
+		return nil, err
+	}
+	c.refreshTTL = refreshTTL
+	return c, nil
 }
 
 //------------------------------------------------------------------------------
@@ -85,7 +123,9 @@ func newRedisCacheFromConfig(conf *service.ParsedConfig) (*redisCache, error) {
 type redisCache struct {
 	client     redis.UniversalClient
 	defaultTTL time.Duration
+	refreshTTL bool
 	prefix     string
+	casScript  *redis.Script
 
 	boffPool sync.Pool
 }
@@ -100,6 +140,21 @@ func newRedisCache(
 		defaultTTL: defaultTTL,
 		prefix:     prefix,
 		client:     client,
+		casScript: redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+
+if ARGV[2] == "delete" then
+	redis.call("DEL", KEYS[1])
+elseif tonumber(ARGV[4]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[3], "PX", tonumber(ARGV[4]))
+else
+	redis.call("SET", KEYS[1], ARGV[3])
+end
+
+return 1
+`),
 		boffPool: sync.Pool{
 			New: func() any {
 				bo := *backOff
@@ -122,7 +177,13 @@ func (r *redisCache) Get(ctx context.Context, key string) ([]byte, error) {
 	}
 
 	for {
-		res, err := r.client.Get(ctx, key).Result()
+		var res string
+		var err error
+		if r.refreshTTL {
+			res, err = r.client.GetEx(ctx, key, r.defaultTTL).Result()
+		} else {
+			res, err = r.client.Get(ctx, key).Result()
+		}
 		if err == nil {
 			return []byte(res), nil
 		}
@@ -248,6 +309,59 @@ func (r *redisCache) Delete(ctx context.Context, key string) error {
 	}
 }
 
+func (r *redisCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	if len(r.prefix) > 0 {
+		key = r.prefix + key
+	}
+
+	var t time.Duration
+	if ttl != nil {
+		t = *ttl
+	} else {
+		t = r.defaultTTL
+	}
+
+	// Increments are not retried as a failed attempt might have been applied
+	// regardless, in which case a retry would increment the counter twice.
+	var incr *redis.IntCmd
+	if _, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.IncrBy(ctx, key, delta)
+		if t > 0 {
+			pipe.Expire(ctx, key, t)
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (r *redisCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) (bool, error) {
+	if len(r.prefix) > 0 {
+		key = r.prefix + key
+	}
+
+	var t time.Duration
+	if ttl != nil {
+		t = *ttl
+	} else {
+		t = r.defaultTTL
+	}
+
+	op := "set"
+	if value == nil {
+		op = "delete"
+	}
+
+	// Swaps are not retried as a failed attempt might have been applied
+	// regardless, in which case a retry would report a mismatch.
+	swapped, err := r.casScript.Run(ctx, r.client, []string{key}, old, op, value, t.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return swapped == 1, nil
+}
+
 func (r *redisCache) Close(ctx context.Context) error {
 	return r.client.Close()
 }
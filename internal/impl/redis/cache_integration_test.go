@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/benthosdev/benthos/v4/internal/integration"
+	"github.com/benthosdev/benthos/v4/public/service"
 )
 
 func TestIntegrationRedisCache(t *testing.T) {
@@ -66,6 +67,71 @@ cache_resources:
 		t, template,
 		integration.CacheTestOptPort(resource.GetPort("6379/tcp")),
 	)
+
+	t.Run("incr and refresh ttl", func(t *testing.T) {
+		url := fmt.Sprintf("tcp://localhost:%v/1", resource.GetPort("6379/tcp"))
+		pConf, err := redisCacheConfig().ParseYAML(fmt.Sprintf(`
+url: %v
+prefix: incr
+prefix_hash_tag: true
+default_ttl: 1m
+refresh_ttl_on_get: true
+`, url), nil)
+		require.NoError(t, err)
+
+		r, err := newRedisCacheFromConfig(pConf)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = r.Close(context.Background())
+		})
+
+		ctx := context.Background()
+		v, err := r.Incr(ctx, "counter", 5, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), v)
+
+		v, err = r.Incr(ctx, "counter", -2, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), v)
+
+		short := time.Second * 10
+		require.NoError(t, r.Set(ctx, "short", []byte("foo"), &short))
+
+		b, err := r.Get(ctx, "short")
+		require.NoError(t, err)
+		assert.Equal(t, "foo", string(b))
+
+		ttl, err := r.client.TTL(ctx, "{incr}short").Result()
+		require.NoError(t, err)
+		assert.Greater(t, ttl, short)
+
+		require.NoError(t, r.Set(ctx, "str", []byte("foo"), nil))
+		_, err = r.Incr(ctx, "str", 1, nil)
+		require.Error(t, err)
+
+		swapped, err := r.CompareAndSwap(ctx, "str", []byte("bar"), []byte("baz"), nil)
+		require.NoError(t, err)
+		assert.False(t, swapped)
+
+		swapped, err = r.CompareAndSwap(ctx, "str", []byte("foo"), []byte("baz"), &short)
+		require.NoError(t, err)
+		assert.True(t, swapped)
+
+		ttl, err = r.client.TTL(ctx, "{incr}str").Result()
+		require.NoError(t, err)
+		assert.LessOrEqual(t, ttl, short)
+
+		swapped, err = r.CompareAndSwap(ctx, "str", []byte("baz"), nil, nil)
+		require.NoError(t, err)
+		assert.True(t, swapped)
+
+		_, err = r.Get(ctx, "str")
+		assert.ErrorIs(t, err, service.ErrKeyNotFound)
+
+		swapped, err = r.CompareAndSwap(ctx, "str", []byte("baz"), []byte("qux"), nil)
+		require.NoError(t, err)
+		assert.False(t, swapped)
+	})
 }
 
 func TestIntegrationRedisClusterCache(t *testing.T) {
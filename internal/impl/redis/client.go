@@ -35,6 +35,32 @@ Some cloud hosted instances of Redis (such as Azure Cache) might need some hand
 			Default("").
 			Example("mymaster").
 			Advanced(),
+		service.NewStringField("sentinel_username").
+			Description("An optional username to authenticate with sentinel servers when `kind` is `failover`.").
+			Default("").
+			Advanced().
+			Version("4.24.0"),
+		service.NewStringField("sentinel_password").
+			Description("An optional password to authenticate with sentinel servers when `kind` is `failover`.").
+			Default("").
+			Secret().
+			Advanced().
+			Version("4.24.0"),
+		service.NewIntField("max_redirects").
+			Description("The maximum number of MOVED and ASK redirects to follow for a command when `kind` is `cluster`, setting this to `-1` disables redirects.").
+			Default(3).
+			Advanced().
+			Version("4.24.0"),
+		service.NewBoolField("route_by_latency").
+			Description("Whether read-only commands should be routed to the node with the lowest latency when `kind` is `cluster`.").
+			Default(false).
+			Advanced().
+			Version("4.24.0"),
+		service.NewBoolField("route_randomly").
+			Description("Whether read-only commands should be routed to a random node when `kind` is `cluster`.").
+			Default(false).
+			Advanced().
+			Version("4.24.0"),
 		tlsField,
 	}
 }
@@ -55,6 +81,31 @@ func getClient(parsedConf *service.ParsedConfig) (redis.UniversalClient, error)
 		return nil, err
 	}
 
+	sentinelUsername, err := parsedConf.FieldString("sentinel_username")
+	if err != nil {
+		return nil, err
+	}
+
+	sentinelPassword, err := parsedConf.FieldString("sentinel_password")
+	if err != nil {
+		return nil, err
+	}
+
+	maxRedirects, err := parsedConf.FieldInt("max_redirects")
+	if err != nil {
+		return nil, err
+	}
+
+	routeByLatency, err := parsedConf.FieldBool("route_by_latency")
+	if err != nil {
+		return nil, err
+	}
+
+	routeRandomly, err := parsedConf.FieldBool("route_randomly")
+	if err != nil {
+		return nil, err
+	}
+
 	tlsConf, tlsEnabled, err := parsedConf.FieldTLSToggled("tls")
 	if err != nil {
 		return nil, err
@@ -95,6 +146,13 @@ func getClient(parsedConf *service.ParsedConfig) (redis.UniversalClient, error)
 		DB:        redisDB,
 		Password:  pass,
 		TLSConfig: tlsConf,
+
+		SentinelUsername: sentinelUsername,
+		SentinelPassword: sentinelPassword,
+
+		MaxRedirects:   maxRedirects,
+		RouteByLatency: routeByLatency,
+		RouteRandomly:  routeRandomly,
 	}
 
 	switch kind {
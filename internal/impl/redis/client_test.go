@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestRedisClientClusterOptions(t *testing.T) {
+	conf, err := service.NewConfigSpec().Fields(clientFields()...).ParseYAML(`
+url: redis://localhost:6379,redis://localhost:6380
+kind: cluster
+max_redirects: 5
+route_by_latency: true
+`, nil)
+	require.NoError(t, err)
+
+	client, err := getClient(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	cClient, ok := client.(*redis.ClusterClient)
+	require.True(t, ok, "%T", client)
+
+	opts := cClient.Options()
+	assert.Equal(t, []string{"localhost:6379", "localhost:6380"}, opts.Addrs)
+	assert.Equal(t, 5, opts.MaxRedirects)
+	assert.True(t, opts.RouteByLatency)
+	assert.False(t, opts.RouteRandomly)
+}
+
+func TestRedisScriptOutputConfErrors(t *testing.T) {
+	conf, err := redisScriptOutputConfig().ParseYAML(`
+url: redis://localhost:6379
+script: return 1
+keys_mapping: 'root = [ '
+args_mapping: 'root = []'
+`, nil)
+	if err == nil {
+		_, err = newRedisScriptWriter(conf, service.MockResources())
+	}
+	require.Error(t, err)
+}
@@ -199,6 +199,8 @@ output:
     key: $ID-${! json("id") }
     fields:
       content: ${! content() }
+    batching:
+      count: $OUTPUT_BATCH_COUNT
 `
 		hashGetFn := func(ctx context.Context, testID, id string) (string, []string, error) {
 			client := redis.NewClient(&redis.Options{
@@ -224,6 +226,41 @@ output:
 			integration.StreamTestOptPort(resource.GetPort("6379/tcp")),
 		)
 	})
+
+	// SCRIPT
+	t.Run("script", func(t *testing.T) {
+		t.Parallel()
+		template := `
+output:
+  redis_script:
+    url: tcp://localhost:$PORT
+    script: |
+      redis.call('rpush', KEYS[1], ARGV[1])
+    keys_mapping: 'root = [ "script-key-$ID" ]'
+    args_mapping: 'root = [ content().string() ]'
+    max_in_flight: $MAX_IN_FLIGHT
+    batching:
+      count: $OUTPUT_BATCH_COUNT
+
+input:
+  redis_list:
+    url: tcp://localhost:$PORT
+    key: script-key-$ID
+`
+		suite := integration.StreamTests(
+			integration.StreamTestOpenClose(),
+			integration.StreamTestSendBatch(10),
+			integration.StreamTestSendBatches(20, 100, 1),
+			integration.StreamTestStreamSequential(1000),
+			integration.StreamTestSendBatchCount(10),
+		)
+		suite.Run(
+			t, template,
+			integration.StreamTestOptSleepAfterInput(100*time.Millisecond),
+			integration.StreamTestOptSleepAfterOutput(100*time.Millisecond),
+			integration.StreamTestOptPort(resource.GetPort("6379/tcp")),
+		)
+	})
 }
 
 func BenchmarkIntegrationRedis(b *testing.B) {
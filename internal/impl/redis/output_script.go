@@ -0,0 +1,218 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	scoFieldScript      = "script"
+	scoFieldArgsMapping = "args_mapping"
+	scoFieldKeysMapping = "keys_mapping"
+	scoFieldBatching    = "batching"
+)
+
+func redisScriptOutputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("4.24.0").
+		Summary(`Executes a [LUA script](https://redis.io/docs/manual/programmability/eval-intro/) against Redis for each message, with keys and arguments derived from the message.`).
+		Description(output.Description(true, true, `
+The script is loaded into Redis when connecting and is then executed for each message with the EVALSHA command, where the keys and arguments of each execution are the results of the `+"`keys_mapping`"+` and `+"`args_mapping`"+` mappings respectively. Batches of messages are executed with a single pipeline of commands.
+
+When `+"`kind`"+` is `+"`cluster`"+` all of the keys of an execution must belong to the same hash slot, which can be ensured with [hash tags](https://redis.io/docs/reference/cluster-spec/#hash-tags).`)).
+		Categories("Services").
+		Fields(clientFields()...).
+		Fields(
+			service.NewStringField(scoFieldScript).
+				Description("The LUA script to execute for each message.").
+				Example(`redis.call('incrby', KEYS[1], ARGV[1])
+redis.call('expire', KEYS[1], ARGV[2])`),
+			service.NewBloblangField(scoFieldArgsMapping).
+				Description("A [Bloblang mapping](/docs/guides/bloblang/about) which should evaluate to an array of values to pass to the script as arguments.").
+				Example("root = [ this.count, 3600 ]").
+				Example(`root = [ meta("kafka_key"), "hardcoded_value" ]`),
+			service.NewBloblangField(scoFieldKeysMapping).
+				Description("A [Bloblang mapping](/docs/guides/bloblang/about) which should evaluate to an array of keys to pass to the script.").
+				Example(`root = [ "counter:" + this.user_id ]`),
+			service.NewOutputMaxInFlightField(),
+			service.NewBatchPolicyField(scoFieldBatching),
+		).
+		Example(
+			"Expiring Counters",
+			"Here we increment a counter for each user that expires an hour after it was last incremented.",
+			`
+output:
+  redis_script:
+    url: redis://localhost:6379
+    script: |
+      redis.call('incrby', KEYS[1], ARGV[1])
+      redis.call('expire', KEYS[1], 3600)
+    keys_mapping: 'root = [ "counter:" + this.user_id ]'
+    args_mapping: 'root = [ this.count ]'
+    batching:
+      count: 100
+      period: 1s
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchOutput(
+		"redis_script", redisScriptOutputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPol service.BatchPolicy, mif int, err error) {
+			if batchPol, err = conf.FieldBatchPolicy(scoFieldBatching); err != nil {
+				return
+			}
+			if mif, err = conf.FieldMaxInFlight(); err != nil {
+				return
+			}
+			out, err = newRedisScriptWriter(conf, mgr)
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type redisScriptWriter struct {
+	log *service.Logger
+
+	script      *redis.Script
+	argsMapping *bloblang.Executor
+	keysMapping *bloblang.Executor
+
+	clientCtor func() (redis.UniversalClient, error)
+	client     redis.UniversalClient
+	connMut    sync.RWMutex
+}
+
+func newRedisScriptWriter(conf *service.ParsedConfig, mgr *service.Resources) (r *redisScriptWriter, err error) {
+	r = &redisScriptWriter{
+		log: mgr.Logger(),
+		clientCtor: func() (redis.UniversalClient, error) {
+			return getClient(conf)
+		},
+	}
+
+	var script string
+	if script, err = conf.FieldString(scoFieldScript); err != nil {
+		return
+	}
+	r.script = redis.NewScript(script)
+
+	if r.argsMapping, err = conf.FieldBloblang(scoFieldArgsMapping); err != nil {
+		return
+	}
+	if r.keysMapping, err = conf.FieldBloblang(scoFieldKeysMapping); err != nil {
+		return
+	}
+
+	_, err = getClient(conf)
+	return
+}
+
+func (r *redisScriptWriter) Connect(ctx context.Context) error {
+	r.connMut.Lock()
+	defer r.connMut.Unlock()
+
+	client, err := r.clientCtor()
+	if err != nil {
+		return err
+	}
+	if _, err = client.Ping(ctx).Result(); err != nil {
+		return err
+	}
+	if err = r.script.Load(ctx, client).Err(); err != nil {
+		_ = client.Close()
+		return err
+	}
+
+	r.client = client
+	return nil
+}
+
+func isNoScriptErr(err error) bool {
+	return strings.HasPrefix(err.Error(), "NOSCRIPT")
+}
+
+func (r *redisScriptWriter) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	r.connMut.RLock()
+	client := r.client
+	r.connMut.RUnlock()
+
+	if client == nil {
+		return service.ErrNotConnected
+	}
+
+	pipe := client.Pipeline()
+
+	for i := range batch {
+		args, err := getArgsMapping(batch, i, r.argsMapping)
+		if err != nil {
+			return err
+		}
+		keys, err := getKeysStrMapping(batch, i, r.keysMapping)
+		if err != nil {
+			return err
+		}
+		_ = r.script.EvalSha(ctx, pipe, keys, args...)
+	}
+
+	// Errors returned by the server for individual scripts are reported per
+	// message, any other error is considered a connection problem.
+	cmders, err := pipe.Exec(ctx)
+	var rErr redis.Error
+	if err != nil && !errors.As(err, &rErr) {
+		_ = r.disconnect()
+		r.log.Errorf("Error from redis: %v\n", err)
+		return service.ErrNotConnected
+	}
+
+	var batchErr *service.BatchError
+	var reloaded bool
+	for i, res := range cmders {
+		if res.Err() == nil || errors.Is(res.Err(), redis.Nil) {
+			continue
+		}
+		if !reloaded && isNoScriptErr(res.Err()) {
+			// The script cache of the server has been flushed, therefore we
+			// reload it so that the failed messages succeed when retried.
+			if lerr := r.script.Load(ctx, client).Err(); lerr != nil {
+				r.log.Errorf("Failed to reload script: %v\n", lerr)
+			}
+			reloaded = true
+		}
+		if batchErr == nil {
+			batchErr = service.NewBatchError(batch, res.Err())
+		}
+		batchErr.Failed(i, res.Err())
+	}
+	if batchErr != nil {
+		return batchErr
+	}
+	return nil
+}
+
+func (r *redisScriptWriter) disconnect() error {
+	r.connMut.Lock()
+	defer r.connMut.Unlock()
+	if r.client != nil {
+		err := r.client.Close()
+		r.client = nil
+		return err
+	}
+	return nil
+}
+
+func (r *redisScriptWriter) Close(context.Context) error {
+	return r.disconnect()
+}
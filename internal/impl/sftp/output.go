@@ -49,6 +49,7 @@ func init() {
 				"The file to save the messages to on the server.",
 			),
 			codec.WriterDocs,
+			codec.WriterCompressionDocs,
 			docs.FieldObject(
 				"credentials",
 				"The credentials to use to log into the server.",
@@ -91,7 +92,7 @@ func newSFTPWriter(conf output.SFTPConfig, mgr bundle.NewManagement) (*sftpWrite
 	}
 
 	var err error
-	if s.codec, s.codecConf, err = codec.GetWriter(conf.Codec); err != nil {
+	if s.codec, s.codecConf, err = codec.GetCompressedWriter(conf.Codec, conf.Compression, mgr.FS()); err != nil {
 		return nil, err
 	}
 	if s.path, err = mgr.BloblEnvironment().NewField(conf.Path); err != nil {
@@ -0,0 +1,255 @@
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BER tags of the ASN.1 types used by SNMP, including the application types
+// of SNMPv2-SMI and the exceptions of SNMPv2 responses.
+const (
+	tagInteger        = 0x02
+	tagOctetString    = 0x04
+	tagNull           = 0x05
+	tagOID            = 0x06
+	tagSequence       = 0x30
+	tagIPAddress      = 0x40
+	tagCounter32      = 0x41
+	tagGauge32        = 0x42
+	tagTimeTicks      = 0x43
+	tagOpaque         = 0x44
+	tagCounter64      = 0x46
+	tagNoSuchObject   = 0x80
+	tagNoSuchInstance = 0x81
+	tagEndOfMibView   = 0x82
+)
+
+var errTruncated = errors.New("value is truncated")
+
+// readTLV reads a BER encoded tag, length and value from the start of data,
+// and returns the tag, the value and the remaining data.
+func readTLV(data []byte) (tag byte, value, rest []byte, err error) {
+	if len(data) < 2 {
+		return 0, nil, nil, errTruncated
+	}
+	tag = data[0]
+	length, off := int(data[1]), 2
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 3 {
+			return 0, nil, nil, fmt.Errorf("unsupported length encoding of tag 0x%02x", tag)
+		}
+		if len(data) < 2+n {
+			return 0, nil, nil, errTruncated
+		}
+		length = 0
+		for _, b := range data[2 : 2+n] {
+			length = length<<8 | int(b)
+		}
+		off += n
+	}
+	if len(data)-off < length {
+		return 0, nil, nil, errTruncated
+	}
+	return tag, data[off : off+length], data[off+length:], nil
+}
+
+// readExpected reads a BER encoded value with an expected tag.
+func readExpected(data []byte, expected byte) (value, rest []byte, err error) {
+	tag, value, rest, err := readTLV(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tag != expected {
+		return nil, nil, fmt.Errorf("expected tag 0x%02x, got 0x%02x", expected, tag)
+	}
+	return value, rest, nil
+}
+
+func readInt(data []byte) (int64, []byte, error) {
+	value, rest, err := readExpected(data, tagInteger)
+	if err != nil {
+		return 0, nil, err
+	}
+	v, err := decodeInt(value)
+	return v, rest, err
+}
+
+func appendTLV(b []byte, tag byte, value []byte) []byte {
+	b = append(b, tag)
+	switch l := len(value); {
+	case l < 0x80:
+		b = append(b, byte(l))
+	case l <= 0xff:
+		b = append(b, 0x81, byte(l))
+	case l <= 0xffff:
+		b = append(b, 0x82, byte(l>>8), byte(l))
+	default:
+		b = append(b, 0x83, byte(l>>16), byte(l>>8), byte(l))
+	}
+	return append(b, value...)
+}
+
+// encodeInt encodes an integer in the minimum number of bytes of two's
+// complement.
+func encodeInt(v int64) []byte {
+	n := 1
+	for i := v; i > 127 || i < -128; i >>= 8 {
+		n++
+	}
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return b
+}
+
+// encodeUint encodes an unsigned integer, which is prefixed with a zero byte
+// when its most significant bit would otherwise be set.
+func encodeUint(v uint64) []byte {
+	n := 1
+	for i := v; i > 127; i >>= 8 {
+		n++
+	}
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return b
+}
+
+func decodeInt(b []byte) (int64, error) {
+	if len(b) == 0 || len(b) > 8 {
+		return 0, fmt.Errorf("invalid integer length %v", len(b))
+	}
+	v := int64(int8(b[0]))
+	for _, c := range b[1:] {
+		v = v<<8 | int64(c)
+	}
+	return v, nil
+}
+
+func decodeUint(b []byte) (uint64, error) {
+	if len(b) == 0 || len(b) > 9 || (len(b) == 9 && b[0] != 0) {
+		return 0, fmt.Errorf("invalid unsigned integer length %v", len(b))
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+//------------------------------------------------------------------------------
+
+// oid is an object identifier.
+type oid []uint32
+
+// parseOID parses an object identifier in dotted notation, where a leading
+// dot is optional.
+func parseOID(s string) (oid, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "."), ".")
+	o := make(oid, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid object identifier '%v'", s)
+		}
+		o = append(o, uint32(n))
+	}
+	return o, nil
+}
+
+func (o oid) String() string {
+	var sb strings.Builder
+	for i, n := range o {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(strconv.FormatUint(uint64(n), 10))
+	}
+	return sb.String()
+}
+
+func (o oid) hasPrefix(prefix oid) bool {
+	if len(prefix) > len(o) {
+		return false
+	}
+	for i, n := range prefix {
+		if o[i] != n {
+			return false
+		}
+	}
+	return true
+}
+
+// compare returns a negative number, zero or a positive number when o is
+// lexicographically before, equal to or after other.
+func (o oid) compare(other oid) int {
+	for i := 0; i < len(o) && i < len(other); i++ {
+		if o[i] != other[i] {
+			if o[i] < other[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(o) - len(other)
+}
+
+func encodeOID(o oid) ([]byte, error) {
+	if len(o) < 2 || o[0] > 2 || (o[0] < 2 && o[1] >= 40) {
+		return nil, fmt.Errorf("invalid object identifier '%v'", o)
+	}
+	b := appendBase128(nil, o[0]*40+o[1])
+	for _, n := range o[2:] {
+		b = appendBase128(b, n)
+	}
+	return b, nil
+}
+
+func appendBase128(b []byte, n uint32) []byte {
+	var tmp [5]byte
+	i := len(tmp) - 1
+	tmp[i] = byte(n & 0x7f)
+	for n >>= 7; n > 0; n >>= 7 {
+		i--
+		tmp[i] = byte(n&0x7f) | 0x80
+	}
+	return append(b, tmp[i:]...)
+}
+
+func decodeOID(b []byte) (oid, error) {
+	if len(b) == 0 {
+		return nil, errors.New("empty object identifier")
+	}
+	var o oid
+	var n uint64
+	for i, c := range b {
+		n = n<<7 | uint64(c&0x7f)
+		if n > 0xffffffff {
+			return nil, errors.New("object identifier component overflows")
+		}
+		if c&0x80 != 0 {
+			if i == len(b)-1 {
+				return nil, errTruncated
+			}
+			continue
+		}
+		if len(o) == 0 {
+			first := n / 40
+			if first > 2 {
+				first = 2
+			}
+			o = append(o, uint32(first), uint32(n-first*40))
+		} else {
+			o = append(o, uint32(n))
+		}
+		n = 0
+	}
+	return o, nil
+}
@@ -0,0 +1,190 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// client sends requests to an SNMP agent over UDP.
+type client struct {
+	conn      net.Conn
+	version   int64
+	community string
+	timeout   time.Duration
+	retries   int
+
+	requestID int64
+}
+
+func dialClient(address string, version int64, community string, timeout time.Duration, retries int) (*client, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "161")
+	}
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &client{
+		conn:      conn,
+		version:   version,
+		community: community,
+		timeout:   timeout,
+		retries:   retries,
+		requestID: time.Now().UnixNano() & 0x7fffffff,
+	}, nil
+}
+
+func (c *client) close() error {
+	return c.conn.Close()
+}
+
+// request sends a request PDU and waits for the response with a matching
+// request ID, resending the request each time the timeout elapses until the
+// retries are exhausted.
+func (c *client) request(ctx context.Context, pduType byte, variables []variable, errorStatus, errorIndex int64) (*packet, error) {
+	c.requestID = (c.requestID + 1) & 0x7fffffff
+	req := &packet{
+		version:     c.version,
+		community:   c.community,
+		pduType:     pduType,
+		requestID:   c.requestID,
+		errorStatus: errorStatus,
+		errorIndex:  errorIndex,
+		variables:   variables,
+	}
+	data, err := req.marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 65535)
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if _, err := c.conn.Write(data); err != nil {
+			return nil, err
+		}
+
+		deadline := time.Now().Add(c.timeout)
+		if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+		if err := c.conn.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+
+		for {
+			n, err := c.conn.Read(buf)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					break
+				}
+				return nil, err
+			}
+			resp, err := unmarshalPacket(buf[:n])
+			if err != nil || resp.pduType != pduResponse || resp.requestID != req.requestID {
+				// Malformed responses and those of previous requests are
+				// ignored.
+				continue
+			}
+			return resp, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("request timed out after %v attempts", c.retries+1)
+}
+
+// get returns the values of a list of objects.
+func (c *client) get(ctx context.Context, oids []oid) ([]variable, error) {
+	variables := make([]variable, len(oids))
+	for i, o := range oids {
+		variables[i] = variable{oid: o, tag: tagNull}
+	}
+
+	resp, err := c.request(ctx, pduGetRequest, variables, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if resp.errorStatus != 0 {
+		return nil, responseError(resp, variables)
+	}
+	return resp.variables, nil
+}
+
+// walk returns the values of all objects within a subtree, using GetBulk
+// requests for SNMPv2c and GetNext requests for SNMPv1.
+func (c *client) walk(ctx context.Context, root oid, maxRepetitions int) ([]variable, error) {
+	var results []variable
+	current := root
+	for {
+		req := []variable{{oid: current, tag: tagNull}}
+
+		var resp *packet
+		var err error
+		if c.version == version1 {
+			resp, err = c.request(ctx, pduGetNextRequest, req, 0, 0)
+		} else {
+			resp, err = c.request(ctx, pduGetBulkRequest, req, 0, int64(maxRepetitions))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp.errorStatus == errorStatusNoSuchName && c.version == version1 {
+			// SNMPv1 agents signal the end of the MIB view with an error.
+			return results, nil
+		}
+		if resp.errorStatus != 0 {
+			return nil, responseError(resp, req)
+		}
+		if len(resp.variables) == 0 {
+			return results, nil
+		}
+
+		for _, v := range resp.variables {
+			if v.tag == tagEndOfMibView || !v.oid.hasPrefix(root) {
+				return results, nil
+			}
+			if v.oid.compare(current) <= 0 {
+				return nil, fmt.Errorf("agent returned object '%v' which does not follow '%v'", v.oid, current)
+			}
+			results = append(results, v)
+			current = v.oid
+		}
+	}
+}
+
+var errorStatusNames = map[int64]string{
+	1:  "tooBig",
+	2:  "noSuchName",
+	3:  "badValue",
+	4:  "readOnly",
+	5:  "genErr",
+	6:  "noAccess",
+	7:  "wrongType",
+	8:  "wrongLength",
+	9:  "wrongEncoding",
+	10: "wrongValue",
+	11: "noCreation",
+	12: "inconsistentValue",
+	13: "resourceUnavailable",
+	14: "commitFailed",
+	15: "undoFailed",
+	16: "authorizationError",
+	17: "notWritable",
+	18: "inconsistentName",
+}
+
+func responseError(resp *packet, req []variable) error {
+	status, exists := errorStatusNames[resp.errorStatus]
+	if !exists {
+		status = fmt.Sprintf("error status %v", resp.errorStatus)
+	}
+	if i := int(resp.errorIndex) - 1; i >= 0 && i < len(req) {
+		return fmt.Errorf("agent responded with %v for object '%v'", status, req[i].oid)
+	}
+	return fmt.Errorf("agent responded with %v", status)
+}
@@ -0,0 +1,300 @@
+package snmp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	spiFieldAddress        = "address"
+	spiFieldVersion        = "version"
+	spiFieldCommunity      = "community"
+	spiFieldOIDs           = "oids"
+	spiFieldWalk           = "walk"
+	spiFieldInterval       = "interval"
+	spiFieldTimeout        = "timeout"
+	spiFieldRetries        = "retries"
+	spiFieldMaxRepetitions = "max_repetitions"
+)
+
+func snmpPollInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Network").
+		Version("4.24.0").
+		Summary("Polls an SNMP agent for the values of objects at an interval, and emits each poll as a JSON message.").
+		Description(`
+The agent is polled once when the input connects and then each time the interval elapses. Objects listed in the field `+"`oids`"+` are requested with a single GET request, and each subtree listed in the field `+"`walk`"+` is walked with GETBULK requests for SNMPv2c agents and GETNEXT requests for SNMPv1 agents. SNMPv3 is not supported.
+
+Objects can be specified either as object identifiers in dotted notation such as `+"`1.3.6.1.2.1.1.3.0`"+`, or by name such as `+"`sysUpTime.0`"+` or `+"`IF-MIB::ifDescr`"+`, where names are resolved with the objects of loaded MIB modules.
+
+Each message is an object containing the variables of a poll in the order they were requested, such as:
+
+`+"```json"+`
+{
+  "variables": [
+    { "oid": "1.3.6.1.2.1.1.3.0", "name": "sysUpTime.0", "type": "timeticks", "value": 8432911 },
+    { "oid": "1.3.6.1.2.1.2.2.1.10.1", "name": "ifInOctets.1", "type": "counter32", "value": 77843421 },
+    { "oid": "1.3.6.1.2.1.2.2.1.10.2", "name": "ifInOctets.2", "type": "counter32", "value": 1209434 }
+  ]
+}
+`+"```"+`
+
+### Variables
+
+The names of variables are resolved with the objects of loaded MIB modules, where the name of the closest known parent object is followed by the remaining components of the object identifier, and the object identifier itself is used when no parent is known. Values of the types `+"`integer`"+`, `+"`counter32`"+`, `+"`counter64`"+`, `+"`gauge32`"+` and `+"`timeticks`"+` are numbers, object identifiers and IP addresses are strings, and octet strings are text when they are printable and otherwise hex bytes separated by colons, such as `+"`00:1a:2b:3c:4d:5e`"+`. Objects that do not exist on the agent have a null value and a type of either `+"`no_such_object`"+` or `+"`no_such_instance`"+`.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- snmp_address
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).`).
+		Fields(
+			service.NewStringField(spiFieldAddress).
+				Description("The address of the agent to poll, where the port defaults to 161 when omitted.").
+				Example("10.0.0.1").
+				Example("router.example.com:1161"),
+			service.NewStringEnumField(spiFieldVersion, "v1", "v2c").
+				Description("The version of SNMP to poll with.").
+				Default("v2c"),
+			service.NewStringField(spiFieldCommunity).
+				Description("The community string to poll with.").
+				Default("public").
+				Secret(),
+			service.NewStringListField(spiFieldOIDs).
+				Description("A list of objects to get the values of.").
+				Default([]any{}).
+				Example([]any{"sysUpTime.0", "sysName.0", "1.3.6.1.2.1.1.1.0"}),
+			service.NewStringListField(spiFieldWalk).
+				Description("A list of subtrees to walk, where the values of all objects within each subtree are returned.").
+				Default([]any{}).
+				Example([]any{"ifDescr", "ifHCInOctets", "ifHCOutOctets"}),
+			service.NewDurationField(spiFieldInterval).
+				Description("The period of time between each poll of the agent.").
+				Default("1m"),
+			service.NewDurationField(spiFieldTimeout).
+				Description("The maximum period of time to wait for the response to each request before resending it.").
+				Default("5s"),
+			service.NewIntField(spiFieldRetries).
+				Description("The number of times to resend a request that has timed out before the poll fails.").
+				Default(3),
+			service.NewIntField(spiFieldMaxRepetitions).
+				Description("The maximum number of objects to request with each GETBULK request of a walk.").
+				Default(10).
+				Advanced(),
+			mibFilesField(),
+		).
+		LintRule(`root = if this.oids.or([]).length() == 0 && this.walk.or([]).length() == 0 {
+  "at least one of the fields oids and walk must be set"
+}`).
+		Example(
+			"Interface Counters",
+			"Here we poll a switch every thirty seconds for the traffic counters of its interfaces, and emit a message for each counter.",
+			`
+input:
+  snmp_poll:
+    address: 10.0.0.1
+    community: public
+    interval: 30s
+    walk: [ ifHCInOctets, ifHCOutOctets ]
+  processors:
+    - mapping: 'root = this.variables'
+    - unarchive:
+        format: json_array
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterInput(
+		"snmp_poll", snmpPollInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			r, err := newSNMPPollReader(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacks(r), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type snmpPollReader struct {
+	mib *mib
+
+	address        string
+	version        int64
+	community      string
+	oids           []oid
+	walks          []oid
+	interval       time.Duration
+	timeout        time.Duration
+	retries        int
+	maxRepetitions int
+
+	clientMut sync.Mutex
+	client    *client
+	nextPoll  time.Time
+}
+
+func newSNMPPollReader(conf *service.ParsedConfig, mgr *service.Resources) (*snmpPollReader, error) {
+	r := &snmpPollReader{}
+
+	var err error
+	if r.address, err = conf.FieldString(spiFieldAddress); err != nil {
+		return nil, err
+	}
+
+	var version string
+	if version, err = conf.FieldString(spiFieldVersion); err != nil {
+		return nil, err
+	}
+	r.version = version2c
+	if version == "v1" {
+		r.version = version1
+	}
+
+	if r.community, err = conf.FieldString(spiFieldCommunity); err != nil {
+		return nil, err
+	}
+	if r.interval, err = conf.FieldDuration(spiFieldInterval); err != nil {
+		return nil, err
+	}
+	if r.timeout, err = conf.FieldDuration(spiFieldTimeout); err != nil {
+		return nil, err
+	}
+	if r.retries, err = conf.FieldInt(spiFieldRetries); err != nil {
+		return nil, err
+	}
+	if r.maxRepetitions, err = conf.FieldInt(spiFieldMaxRepetitions); err != nil {
+		return nil, err
+	}
+	if r.maxRepetitions < 1 {
+		return nil, fmt.Errorf("field %v must be at least 1", spiFieldMaxRepetitions)
+	}
+	if r.mib, err = mibFromConfig(conf, mgr); err != nil {
+		return nil, err
+	}
+
+	if r.oids, err = r.resolveList(conf, spiFieldOIDs); err != nil {
+		return nil, err
+	}
+	if r.walks, err = r.resolveList(conf, spiFieldWalk); err != nil {
+		return nil, err
+	}
+	if len(r.oids) == 0 && len(r.walks) == 0 {
+		return nil, fmt.Errorf("at least one of the fields %v and %v must be set", spiFieldOIDs, spiFieldWalk)
+	}
+	return r, nil
+}
+
+func (r *snmpPollReader) resolveList(conf *service.ParsedConfig, field string) ([]oid, error) {
+	strs, err := conf.FieldStringList(field)
+	if err != nil {
+		return nil, err
+	}
+	oids := make([]oid, 0, len(strs))
+	for _, s := range strs {
+		o, err := r.mib.resolve(s)
+		if err != nil {
+			return nil, fmt.Errorf("field %v: %w", field, err)
+		}
+		oids = append(oids, o)
+	}
+	return oids, nil
+}
+
+func (r *snmpPollReader) Connect(ctx context.Context) error {
+	r.clientMut.Lock()
+	defer r.clientMut.Unlock()
+	if r.client != nil {
+		return nil
+	}
+
+	c, err := dialClient(r.address, r.version, r.community, r.timeout, r.retries)
+	if err != nil {
+		return err
+	}
+	r.client = c
+	r.nextPoll = time.Time{}
+	return nil
+}
+
+func (r *snmpPollReader) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	r.clientMut.Lock()
+	defer r.clientMut.Unlock()
+	if r.client == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	if wait := time.Until(r.nextPoll); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+	// Failed polls are not retried until the next interval in order to avoid
+	// flooding an agent that is struggling.
+	r.nextPoll = time.Now().Add(r.interval)
+
+	variables, err := r.poll(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to poll agent %v: %w", r.address, err)
+	}
+
+	vars := make([]any, 0, len(variables))
+	for _, v := range variables {
+		vars = append(vars, v.structured(r.mib))
+	}
+
+	msg := service.NewMessage(nil)
+	msg.SetStructuredMut(map[string]any{
+		"variables": vars,
+	})
+	msg.MetaSetMut("snmp_address", r.address)
+	return msg, func(ctx context.Context, err error) error {
+		return nil
+	}, nil
+}
+
+func (r *snmpPollReader) poll(ctx context.Context) ([]variable, error) {
+	var variables []variable
+	if len(r.oids) > 0 {
+		vars, err := r.client.get(ctx, r.oids)
+		if err != nil {
+			return nil, err
+		}
+		variables = append(variables, vars...)
+	}
+	for _, root := range r.walks {
+		vars, err := r.client.walk(ctx, root, r.maxRepetitions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk '%v': %w", r.mib.name(root), err)
+		}
+		variables = append(variables, vars...)
+	}
+	return variables, nil
+}
+
+func (r *snmpPollReader) Close(ctx context.Context) error {
+	r.clientMut.Lock()
+	defer r.clientMut.Unlock()
+	if r.client == nil {
+		return nil
+	}
+	err := r.client.close()
+	r.client = nil
+	return err
+}
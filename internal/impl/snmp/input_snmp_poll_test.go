@@ -0,0 +1,204 @@
+package snmp
+
+import (
+	"context"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+// fakeAgent responds to GET, GETNEXT and GETBULK requests with the values of
+// a fixed set of objects.
+func fakeAgent(t *testing.T, objects []variable) string {
+	t.Helper()
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].oid.compare(objects[j].oid) < 0
+	})
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	next := func(o oid) variable {
+		for _, v := range objects {
+			if v.oid.compare(o) > 0 {
+				return v
+			}
+		}
+		return variable{oid: o, tag: tagEndOfMibView}
+	}
+
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			req, err := unmarshalPacket(buf[:n])
+			if err != nil || req.community != "secret" {
+				continue
+			}
+
+			resp := *req
+			resp.pduType = pduResponse
+			resp.errorStatus, resp.errorIndex = 0, 0
+			resp.variables = nil
+			switch req.pduType {
+			case pduGetRequest:
+				for _, rv := range req.variables {
+					v := variable{oid: rv.oid, tag: tagNoSuchObject}
+					for _, o := range objects {
+						if o.oid.compare(rv.oid) == 0 {
+							v = o
+						}
+					}
+					resp.variables = append(resp.variables, v)
+				}
+			case pduGetNextRequest:
+				v := next(req.variables[0].oid)
+				if v.tag == tagEndOfMibView {
+					resp.errorStatus, resp.errorIndex = errorStatusNoSuchName, 1
+					v = req.variables[0]
+				}
+				resp.variables = append(resp.variables, v)
+			case pduGetBulkRequest:
+				current := req.variables[0].oid
+				for i := int64(0); i < req.errorIndex; i++ {
+					v := next(current)
+					resp.variables = append(resp.variables, v)
+					if v.tag == tagEndOfMibView {
+						break
+					}
+					current = v.oid
+				}
+			}
+
+			data, err := resp.marshal()
+			require.NoError(t, err)
+			_, _ = conn.WriteTo(data, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func testAgentObjects() []variable {
+	return []variable{
+		{oid: oid{1, 3, 6, 1, 2, 1, 1, 3, 0}, tag: tagTimeTicks, value: uint64(8432911)},
+		{oid: oid{1, 3, 6, 1, 2, 1, 1, 5, 0}, tag: tagOctetString, value: []byte("core-1")},
+		{oid: oid{1, 3, 6, 1, 2, 1, 2, 2, 1, 2, 1}, tag: tagOctetString, value: []byte("lo")},
+		{oid: oid{1, 3, 6, 1, 2, 1, 2, 2, 1, 2, 2}, tag: tagOctetString, value: []byte("eth0")},
+		{oid: oid{1, 3, 6, 1, 2, 1, 2, 2, 1, 2, 3}, tag: tagOctetString, value: []byte("eth1")},
+		{oid: oid{1, 3, 6, 1, 2, 1, 2, 2, 1, 3, 1}, tag: tagInteger, value: int64(24)},
+	}
+}
+
+func TestSNMPPollInput(t *testing.T) {
+	for _, version := range []string{"v1", "v2c"} {
+		version := version
+		t.Run(version, func(t *testing.T) {
+			address := fakeAgent(t, testAgentObjects())
+
+			pConf, err := snmpPollInputSpec().ParseYAML(`
+address: `+address+`
+version: `+version+`
+community: secret
+oids: [ sysUpTime.0, sysName.0, sysContact.0 ]
+walk: [ ifDescr ]
+interval: 100ms
+timeout: 1s
+max_repetitions: 2
+`, nil)
+			require.NoError(t, err)
+
+			r, err := newSNMPPollReader(pConf, service.MockResources())
+			require.NoError(t, err)
+
+			ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+			defer done()
+
+			require.NoError(t, r.Connect(ctx))
+			t.Cleanup(func() {
+				require.NoError(t, r.Close(context.Background()))
+			})
+
+			for i := 0; i < 2; i++ {
+				msg, ackFn, err := r.Read(ctx)
+				require.NoError(t, err)
+				require.NoError(t, ackFn(ctx, nil))
+
+				s, err := msg.AsStructured()
+				require.NoError(t, err)
+
+				var names, values []any
+				for _, v := range s.(map[string]any)["variables"].([]any) {
+					names = append(names, v.(map[string]any)["name"])
+					values = append(values, v.(map[string]any)["value"])
+				}
+				assert.Equal(t, []any{"sysUpTime.0", "sysName.0", "sysContact.0", "ifDescr.1", "ifDescr.2", "ifDescr.3"}, names)
+				assert.Equal(t, []any{int64(8432911), "core-1", nil, "lo", "eth0", "eth1"}, values)
+
+				meta, _ := msg.MetaGetMut("snmp_address")
+				assert.Equal(t, address, meta)
+			}
+		})
+	}
+}
+
+func TestSNMPPollInputTimeout(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	pConf, err := snmpPollInputSpec().ParseYAML(`
+address: `+conn.LocalAddr().String()+`
+oids: [ sysUpTime.0 ]
+timeout: 10ms
+retries: 1
+`, nil)
+	require.NoError(t, err)
+
+	r, err := newSNMPPollReader(pConf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, r.Connect(ctx))
+	defer r.Close(ctx)
+
+	_, _, err = r.Read(ctx)
+	require.EqualError(t, err, "failed to poll agent "+conn.LocalAddr().String()+": request timed out after 2 attempts")
+}
+
+func TestSNMPPollInputConfigErrors(t *testing.T) {
+	tests := map[string]string{
+		"no objects": `
+address: 10.0.0.1
+`,
+		"unknown object": `
+address: 10.0.0.1
+walk: [ notAnObject ]
+`,
+	}
+
+	for name, confStr := range tests {
+		confStr := confStr
+		t.Run(name, func(t *testing.T) {
+			pConf, err := snmpPollInputSpec().ParseYAML(confStr, nil)
+			require.NoError(t, err)
+
+			_, err = newSNMPPollReader(pConf, service.MockResources())
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	stiFieldAddress   = "address"
+	stiFieldCommunity = "community"
+)
+
+func snmpTrapInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Network").
+		Version("4.24.0").
+		Summary("Listens for SNMPv1 and SNMPv2c traps and informs, and emits each one as a JSON message.").
+		Description(`
+Informs are acknowledged with a response once they have been received. Traps of SNMPv1 are converted to the form of SNMPv2c traps as described in [RFC 3584](https://www.rfc-editor.org/rfc/rfc3584#section-3.1), and so the notification of every trap is identified by the field `+"`trap_oid`"+`. SNMPv3 messages are not supported and are dropped.
+
+Each message is an object such as:
+
+`+"```json"+`
+{
+  "version": "v2c",
+  "pdu_type": "trap",
+  "uptime": 8432911,
+  "trap_oid": "1.3.6.1.6.3.1.1.5.3",
+  "trap_name": "linkDown",
+  "variables": [
+    { "oid": "1.3.6.1.2.1.2.2.1.1.3", "name": "ifIndex.3", "type": "integer", "value": 3 },
+    { "oid": "1.3.6.1.2.1.2.2.1.7.3", "name": "ifAdminStatus.3", "type": "integer", "value": 1 },
+    { "oid": "1.3.6.1.2.1.2.2.1.8.3", "name": "ifOperStatus.3", "type": "integer", "value": 2 }
+  ]
+}
+`+"```"+`
+
+Messages of SNMPv1 traps also contain the fields `+"`enterprise`"+`, `+"`agent_address`"+`, `+"`generic_trap`"+` and `+"`specific_trap`"+`.
+
+### Variables
+
+The names of variables are resolved with the objects of loaded MIB modules, where the name of the closest known parent object is followed by the remaining components of the object identifier, and the object identifier itself is used when no parent is known. Values of the types `+"`integer`"+`, `+"`counter32`"+`, `+"`counter64`"+`, `+"`gauge32`"+` and `+"`timeticks`"+` are numbers, object identifiers and IP addresses are strings, and octet strings are text when they are printable and otherwise hex bytes separated by colons, such as `+"`00:1a:2b:3c:4d:5e`"+`.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- snmp_source
+- snmp_version
+- snmp_community
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).`).
+		Fields(
+			service.NewStringField(stiFieldAddress).
+				Description("The address to listen for traps on.").
+				Default("0.0.0.0:162").
+				Example("0.0.0.0:1162"),
+			service.NewStringField(stiFieldCommunity).
+				Description("An optional community string, where traps and informs of other communities are dropped. When empty traps of any community are accepted.").
+				Default("").
+				Secret(),
+			mibFilesField(),
+		).
+		Example(
+			"Link State Changes",
+			"Here we receive traps from network devices and keep only those that notify of interfaces going up or down.",
+			`
+input:
+  snmp_trap:
+    address: 0.0.0.0:162
+    community: public
+  processors:
+    - mapping: |
+        root = if ![ "linkDown", "linkUp" ].contains(this.trap_name) { deleted() }
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterInput(
+		"snmp_trap", snmpTrapInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			r, err := newSNMPTrapReader(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacks(r), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type snmpTrapReader struct {
+	log *service.Logger
+
+	address   string
+	community string
+	mib       *mib
+
+	connMut  sync.Mutex
+	conn     net.PacketConn
+	msgChan  chan *service.Message
+	cancelFn func()
+	doneChan chan struct{}
+}
+
+func newSNMPTrapReader(conf *service.ParsedConfig, mgr *service.Resources) (*snmpTrapReader, error) {
+	r := &snmpTrapReader{log: mgr.Logger()}
+
+	var err error
+	if r.address, err = conf.FieldString(stiFieldAddress); err != nil {
+		return nil, err
+	}
+	if r.community, err = conf.FieldString(stiFieldCommunity); err != nil {
+		return nil, err
+	}
+	if r.mib, err = mibFromConfig(conf, mgr); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *snmpTrapReader) Connect(ctx context.Context) error {
+	r.connMut.Lock()
+	defer r.connMut.Unlock()
+	if r.msgChan != nil {
+		return nil
+	}
+
+	conn, err := net.ListenPacket("udp", r.address)
+	if err != nil {
+		return err
+	}
+
+	listenCtx, cancelFn := context.WithCancel(context.Background())
+	msgChan := make(chan *service.Message)
+	doneChan := make(chan struct{})
+	go func() {
+		defer close(doneChan)
+		r.listen(listenCtx, conn, msgChan)
+	}()
+
+	r.log.Infof("Receiving SNMP traps at: %v", conn.LocalAddr())
+
+	r.conn = conn
+	r.msgChan = msgChan
+	r.cancelFn = cancelFn
+	r.doneChan = doneChan
+	return nil
+}
+
+// listen reads traps until the connection is closed, and sends a message for
+// each one to a channel.
+func (r *snmpTrapReader) listen(ctx context.Context, conn net.PacketConn, msgChan chan<- *service.Message) {
+	defer close(msgChan)
+
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+				r.log.Errorf("Failed to read trap: %v", err)
+			}
+			return
+		}
+
+		p, err := unmarshalPacket(buf[:n])
+		if err != nil {
+			r.log.Debugf("Failed to decode trap from %v: %v", addr, err)
+			continue
+		}
+		if p.pduType != pduTrapV1 && p.pduType != pduTrapV2 && p.pduType != pduInformRequest {
+			r.log.Debugf("Dropping PDU of type 0x%02x from %v as it is not a trap or inform", p.pduType, addr)
+			continue
+		}
+		if r.community != "" && p.community != r.community {
+			r.log.Debugf("Dropping trap from %v as its community does not match", addr)
+			continue
+		}
+
+		if p.pduType == pduInformRequest {
+			if err := r.acknowledge(conn, addr, p); err != nil {
+				r.log.Errorf("Failed to acknowledge inform from %v: %v", addr, err)
+			}
+		}
+
+		msg := service.NewMessage(nil)
+		msg.SetStructuredMut(r.structured(p))
+		msg.MetaSetMut("snmp_source", addr.String())
+		msg.MetaSetMut("snmp_version", versionName(p.version))
+		msg.MetaSetMut("snmp_community", p.community)
+
+		select {
+		case msgChan <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// acknowledge responds to an inform with a response containing the same
+// request ID and variables.
+func (r *snmpTrapReader) acknowledge(conn net.PacketConn, addr net.Addr, inform *packet) error {
+	resp := *inform
+	resp.pduType = pduResponse
+	resp.errorStatus, resp.errorIndex = 0, 0
+	data, err := resp.marshal()
+	if err != nil {
+		return err
+	}
+	_, err = conn.WriteTo(data, addr)
+	return err
+}
+
+// snmpTrapsOID is the parent of the notifications of the generic traps of
+// SNMPv1.
+var snmpTrapsOID = oid{1, 3, 6, 1, 6, 3, 1, 1, 5}
+
+func (r *snmpTrapReader) structured(p *packet) map[string]any {
+	obj := map[string]any{
+		"version": versionName(p.version),
+	}
+
+	variables := p.variables
+	var trapOID oid
+	if p.pduType == pduTrapV1 {
+		obj["pdu_type"] = "trap"
+		obj["enterprise"] = p.enterprise.String()
+		obj["agent_address"] = p.agentAddress.String()
+		obj["generic_trap"] = p.genericTrap
+		obj["specific_trap"] = p.specificTrap
+		obj["uptime"] = int64(p.timestamp)
+
+		if p.genericTrap == 6 {
+			trapOID = append(append(oid(nil), p.enterprise...), 0, uint32(p.specificTrap))
+		} else {
+			trapOID = append(append(oid(nil), snmpTrapsOID...), uint32(p.genericTrap)+1)
+		}
+	} else {
+		obj["pdu_type"] = "trap"
+		if p.pduType == pduInformRequest {
+			obj["pdu_type"] = "inform"
+		}
+
+		// The first two variables of SNMPv2 notifications are sysUpTime.0 and
+		// snmpTrapOID.0.
+		if len(variables) > 0 && variables[0].tag == tagTimeTicks {
+			obj["uptime"] = int64(variables[0].value.(uint64))
+			variables = variables[1:]
+		}
+		if len(variables) > 0 && variables[0].tag == tagOID {
+			trapOID = variables[0].value.(oid)
+			variables = variables[1:]
+		}
+	}
+	if trapOID != nil {
+		obj["trap_oid"] = trapOID.String()
+		obj["trap_name"] = r.mib.name(trapOID)
+	}
+
+	vars := make([]any, 0, len(variables))
+	for _, v := range variables {
+		vars = append(vars, v.structured(r.mib))
+	}
+	obj["variables"] = vars
+	return obj
+}
+
+func versionName(version int64) string {
+	if version == version1 {
+		return "v1"
+	}
+	return "v2c"
+}
+
+func (r *snmpTrapReader) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	r.connMut.Lock()
+	msgChan := r.msgChan
+	r.connMut.Unlock()
+	if msgChan == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	select {
+	case msg, open := <-msgChan:
+		if !open {
+			r.connMut.Lock()
+			r.cancelFn()
+			if r.conn != nil {
+				_ = r.conn.Close()
+			}
+			r.conn, r.msgChan = nil, nil
+			r.connMut.Unlock()
+			return nil, nil, service.ErrNotConnected
+		}
+		return msg, func(ctx context.Context, err error) error {
+			return nil
+		}, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+func (r *snmpTrapReader) Close(ctx context.Context) error {
+	r.connMut.Lock()
+	conn, cancelFn, doneChan := r.conn, r.cancelFn, r.doneChan
+	r.connMut.Unlock()
+	if cancelFn == nil {
+		return nil
+	}
+
+	cancelFn()
+	if conn != nil {
+		_ = conn.Close()
+	}
+	select {
+	case <-doneChan:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
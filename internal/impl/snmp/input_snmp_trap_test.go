@@ -0,0 +1,180 @@
+package snmp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func sendPacket(t *testing.T, conn net.Conn, p *packet) {
+	t.Helper()
+
+	data, err := p.marshal()
+	require.NoError(t, err)
+
+	_, err = conn.Write(data)
+	require.NoError(t, err)
+}
+
+func TestSNMPTrapInputInform(t *testing.T) {
+	pConf, err := snmpTrapInputSpec().ParseYAML(`
+address: 127.0.0.1:0
+community: public
+`, nil)
+	require.NoError(t, err)
+
+	r, err := newSNMPTrapReader(pConf, service.MockResources())
+	require.NoError(t, err)
+
+	require.NoError(t, r.Connect(context.Background()))
+	t.Cleanup(func() {
+		require.NoError(t, r.Close(context.Background()))
+	})
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	conn, err := net.Dial("udp", r.conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	linkDown := []variable{
+		{oid: oid{1, 3, 6, 1, 2, 1, 1, 3, 0}, tag: tagTimeTicks, value: uint64(8432911)},
+		{oid: oid{1, 3, 6, 1, 6, 3, 1, 1, 4, 1, 0}, tag: tagOID, value: oid{1, 3, 6, 1, 6, 3, 1, 1, 5, 3}},
+		{oid: oid{1, 3, 6, 1, 2, 1, 2, 2, 1, 1, 3}, tag: tagInteger, value: int64(3)},
+		{oid: oid{1, 3, 6, 1, 2, 1, 2, 2, 1, 8, 3}, tag: tagInteger, value: int64(2)},
+	}
+
+	// Traps of other communities are dropped.
+	sendPacket(t, conn, &packet{
+		version:   version2c,
+		community: "private",
+		pduType:   pduTrapV2,
+		requestID: 1,
+		variables: linkDown,
+	})
+	sendPacket(t, conn, &packet{
+		version:   version2c,
+		community: "public",
+		pduType:   pduInformRequest,
+		requestID: 2,
+		variables: linkDown,
+	})
+
+	msg, ackFn, err := r.Read(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ackFn(ctx, nil))
+
+	s, err := msg.AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"version":   "v2c",
+		"pdu_type":  "inform",
+		"uptime":    int64(8432911),
+		"trap_oid":  "1.3.6.1.6.3.1.1.5.3",
+		"trap_name": "linkDown",
+		"variables": []any{
+			map[string]any{"oid": "1.3.6.1.2.1.2.2.1.1.3", "name": "ifIndex.3", "type": "integer", "value": int64(3)},
+			map[string]any{"oid": "1.3.6.1.2.1.2.2.1.8.3", "name": "ifOperStatus.3", "type": "integer", "value": int64(2)},
+		},
+	}, s)
+
+	source, _ := msg.MetaGetMut("snmp_source")
+	assert.Equal(t, conn.LocalAddr().String(), source)
+	version, _ := msg.MetaGetMut("snmp_version")
+	assert.Equal(t, "v2c", version)
+	community, _ := msg.MetaGetMut("snmp_community")
+	assert.Equal(t, "public", community)
+
+	// The inform is acknowledged with a response of the same request ID.
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second*10)))
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	resp, err := unmarshalPacket(buf[:n])
+	require.NoError(t, err)
+	assert.Equal(t, byte(pduResponse), resp.pduType)
+	assert.Equal(t, int64(2), resp.requestID)
+	assert.Equal(t, linkDown, resp.variables)
+}
+
+func TestSNMPTrapInputV1(t *testing.T) {
+	pConf, err := snmpTrapInputSpec().ParseYAML(`address: 127.0.0.1:0`, nil)
+	require.NoError(t, err)
+
+	r, err := newSNMPTrapReader(pConf, service.MockResources())
+	require.NoError(t, err)
+
+	require.NoError(t, r.Connect(context.Background()))
+	t.Cleanup(func() {
+		require.NoError(t, r.Close(context.Background()))
+	})
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	conn, err := net.Dial("udp", r.conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("not a trap"))
+	require.NoError(t, err)
+
+	sendPacket(t, conn, &packet{
+		version:      version1,
+		community:    "anything",
+		pduType:      pduTrapV1,
+		enterprise:   oid{1, 3, 6, 1, 4, 1, 9},
+		agentAddress: net.IP{192, 168, 1, 1},
+		genericTrap:  6,
+		specificTrap: 17,
+		timestamp:    5000,
+		variables: []variable{
+			{oid: oid{1, 3, 6, 1, 4, 1, 9, 9, 1}, tag: tagOctetString, value: []byte("fan failure")},
+		},
+	})
+	sendPacket(t, conn, &packet{
+		version:      version1,
+		community:    "anything",
+		pduType:      pduTrapV1,
+		enterprise:   oid{1, 3, 6, 1, 4, 1, 9},
+		agentAddress: net.IP{192, 168, 1, 1},
+		genericTrap:  0,
+		timestamp:    10,
+	})
+
+	msg, _, err := r.Read(ctx)
+	require.NoError(t, err)
+
+	s, err := msg.AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"version":       "v1",
+		"pdu_type":      "trap",
+		"enterprise":    "1.3.6.1.4.1.9",
+		"agent_address": "192.168.1.1",
+		"generic_trap":  int64(6),
+		"specific_trap": int64(17),
+		"uptime":        int64(5000),
+		"trap_oid":      "1.3.6.1.4.1.9.0.17",
+		"trap_name":     "enterprises.9.0.17",
+		"variables": []any{
+			map[string]any{"oid": "1.3.6.1.4.1.9.9.1", "name": "enterprises.9.9.1", "type": "octet_string", "value": "fan failure"},
+		},
+	}, s)
+
+	msg, _, err = r.Read(ctx)
+	require.NoError(t, err)
+
+	s, err = msg.AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, "coldStart", s.(map[string]any)["trap_name"])
+	assert.Equal(t, []any{}, s.(map[string]any)["variables"])
+}
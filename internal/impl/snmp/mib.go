@@ -0,0 +1,407 @@
+package snmp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	sFieldMIBFiles = "mib_files"
+)
+
+func mibFilesField() *service.ConfigField {
+	return service.NewStringListField(sFieldMIBFiles).
+		Description("A list of paths of MIB module files to load, which are used to resolve the names of objects in addition to the objects of the SNMPv2-MIB and IF-MIB that are always available. Modules must be listed along with any modules they import from, other than SNMPv2-SMI, and can be listed in any order.").
+		Default([]any{}).
+		Example([]any{"./mibs/CISCO-SMI.my", "./mibs/CISCO-ENVMON-MIB.my"})
+}
+
+// mibFromConfig returns a MIB with the modules of the mib_files field loaded.
+func mibFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*mib, error) {
+	paths, err := conf.FieldStringList(sFieldMIBFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := make([]string, 0, len(paths))
+	for _, p := range paths {
+		moduleBytes, err := ifs.ReadFile(mgr.FS(), p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MIB file '%v': %w", p, err)
+		}
+		modules = append(modules, string(moduleBytes))
+	}
+
+	m := newMIB()
+	if err := m.load(modules...); err != nil {
+		return nil, fmt.Errorf("failed to load MIB files: %w", err)
+	}
+	return m, nil
+}
+
+// builtinNames are the object identifiers of the SNMPv2-SMI roots and of
+// commonly used objects and notifications of the SNMPv2-MIB, IF-MIB and
+// SNMP-COMMUNITY-MIB, which are always available without loading MIB files.
+var builtinNames = map[string]string{
+	"ccitt":           "0",
+	"iso":             "1",
+	"joint-iso-ccitt": "2",
+	"org":             "1.3",
+	"dod":             "1.3.6",
+	"internet":        "1.3.6.1",
+	"directory":       "1.3.6.1.1",
+	"mgmt":            "1.3.6.1.2",
+	"mib-2":           "1.3.6.1.2.1",
+	"transmission":    "1.3.6.1.2.1.10",
+	"experimental":    "1.3.6.1.3",
+	"private":         "1.3.6.1.4",
+	"enterprises":     "1.3.6.1.4.1",
+	"security":        "1.3.6.1.5",
+	"snmpV2":          "1.3.6.1.6",
+	"snmpDomains":     "1.3.6.1.6.1",
+	"snmpProxys":      "1.3.6.1.6.2",
+	"snmpModules":     "1.3.6.1.6.3",
+
+	"system":      "1.3.6.1.2.1.1",
+	"sysDescr":    "1.3.6.1.2.1.1.1",
+	"sysObjectID": "1.3.6.1.2.1.1.2",
+	"sysUpTime":   "1.3.6.1.2.1.1.3",
+	"sysContact":  "1.3.6.1.2.1.1.4",
+	"sysName":     "1.3.6.1.2.1.1.5",
+	"sysLocation": "1.3.6.1.2.1.1.6",
+	"sysServices": "1.3.6.1.2.1.1.7",
+
+	"interfaces":        "1.3.6.1.2.1.2",
+	"ifNumber":          "1.3.6.1.2.1.2.1",
+	"ifTable":           "1.3.6.1.2.1.2.2",
+	"ifEntry":           "1.3.6.1.2.1.2.2.1",
+	"ifIndex":           "1.3.6.1.2.1.2.2.1.1",
+	"ifDescr":           "1.3.6.1.2.1.2.2.1.2",
+	"ifType":            "1.3.6.1.2.1.2.2.1.3",
+	"ifMtu":             "1.3.6.1.2.1.2.2.1.4",
+	"ifSpeed":           "1.3.6.1.2.1.2.2.1.5",
+	"ifPhysAddress":     "1.3.6.1.2.1.2.2.1.6",
+	"ifAdminStatus":     "1.3.6.1.2.1.2.2.1.7",
+	"ifOperStatus":      "1.3.6.1.2.1.2.2.1.8",
+	"ifLastChange":      "1.3.6.1.2.1.2.2.1.9",
+	"ifInOctets":        "1.3.6.1.2.1.2.2.1.10",
+	"ifInUcastPkts":     "1.3.6.1.2.1.2.2.1.11",
+	"ifInNUcastPkts":    "1.3.6.1.2.1.2.2.1.12",
+	"ifInDiscards":      "1.3.6.1.2.1.2.2.1.13",
+	"ifInErrors":        "1.3.6.1.2.1.2.2.1.14",
+	"ifInUnknownProtos": "1.3.6.1.2.1.2.2.1.15",
+	"ifOutOctets":       "1.3.6.1.2.1.2.2.1.16",
+	"ifOutUcastPkts":    "1.3.6.1.2.1.2.2.1.17",
+	"ifOutNUcastPkts":   "1.3.6.1.2.1.2.2.1.18",
+	"ifOutDiscards":     "1.3.6.1.2.1.2.2.1.19",
+	"ifOutErrors":       "1.3.6.1.2.1.2.2.1.20",
+	"ifOutQLen":         "1.3.6.1.2.1.2.2.1.21",
+	"ifSpecific":        "1.3.6.1.2.1.2.2.1.22",
+
+	"ifMIB":                      "1.3.6.1.2.1.31",
+	"ifXTable":                   "1.3.6.1.2.1.31.1.1",
+	"ifXEntry":                   "1.3.6.1.2.1.31.1.1.1",
+	"ifName":                     "1.3.6.1.2.1.31.1.1.1.1",
+	"ifInMulticastPkts":          "1.3.6.1.2.1.31.1.1.1.2",
+	"ifInBroadcastPkts":          "1.3.6.1.2.1.31.1.1.1.3",
+	"ifOutMulticastPkts":         "1.3.6.1.2.1.31.1.1.1.4",
+	"ifOutBroadcastPkts":         "1.3.6.1.2.1.31.1.1.1.5",
+	"ifHCInOctets":               "1.3.6.1.2.1.31.1.1.1.6",
+	"ifHCInUcastPkts":            "1.3.6.1.2.1.31.1.1.1.7",
+	"ifHCInMulticastPkts":        "1.3.6.1.2.1.31.1.1.1.8",
+	"ifHCInBroadcastPkts":        "1.3.6.1.2.1.31.1.1.1.9",
+	"ifHCOutOctets":              "1.3.6.1.2.1.31.1.1.1.10",
+	"ifHCOutUcastPkts":           "1.3.6.1.2.1.31.1.1.1.11",
+	"ifHCOutMulticastPkts":       "1.3.6.1.2.1.31.1.1.1.12",
+	"ifHCOutBroadcastPkts":       "1.3.6.1.2.1.31.1.1.1.13",
+	"ifLinkUpDownTrapEnable":     "1.3.6.1.2.1.31.1.1.1.14",
+	"ifHighSpeed":                "1.3.6.1.2.1.31.1.1.1.15",
+	"ifPromiscuousMode":          "1.3.6.1.2.1.31.1.1.1.16",
+	"ifConnectorPresent":         "1.3.6.1.2.1.31.1.1.1.17",
+	"ifAlias":                    "1.3.6.1.2.1.31.1.1.1.18",
+	"ifCounterDiscontinuityTime": "1.3.6.1.2.1.31.1.1.1.19",
+
+	"snmpMIB":               "1.3.6.1.6.3.1",
+	"snmpMIBObjects":        "1.3.6.1.6.3.1.1",
+	"snmpTrap":              "1.3.6.1.6.3.1.1.4",
+	"snmpTrapOID":           "1.3.6.1.6.3.1.1.4.1",
+	"snmpTrapEnterprise":    "1.3.6.1.6.3.1.1.4.3",
+	"snmpTraps":             "1.3.6.1.6.3.1.1.5",
+	"coldStart":             "1.3.6.1.6.3.1.1.5.1",
+	"warmStart":             "1.3.6.1.6.3.1.1.5.2",
+	"linkDown":              "1.3.6.1.6.3.1.1.5.3",
+	"linkUp":                "1.3.6.1.6.3.1.1.5.4",
+	"authenticationFailure": "1.3.6.1.6.3.1.1.5.5",
+	"egpNeighborLoss":       "1.3.6.1.6.3.1.1.5.6",
+	"snmpTrapAddress":       "1.3.6.1.6.3.18.1.3",
+	"snmpTrapCommunity":     "1.3.6.1.6.3.18.1.4",
+}
+
+// mib resolves object identifiers to and from the names of the objects they
+// identify.
+type mib struct {
+	names map[string]string
+	oids  map[string]oid
+}
+
+func newMIB() *mib {
+	m := &mib{
+		names: make(map[string]string, len(builtinNames)),
+		oids:  make(map[string]oid, len(builtinNames)),
+	}
+	for name, s := range builtinNames {
+		o, err := parseOID(s)
+		if err != nil {
+			panic(err)
+		}
+		m.add(name, o)
+	}
+	return m
+}
+
+func (m *mib) add(name string, o oid) {
+	m.names[o.String()] = name
+	m.oids[name] = o
+}
+
+// name returns the name of an object identifier, which is the name of its
+// longest known prefix followed by the remaining components, or the object
+// identifier in dotted notation when no prefix is known.
+func (m *mib) name(o oid) string {
+	for i := len(o); i > 0; i-- {
+		name, exists := m.names[o[:i].String()]
+		if !exists {
+			continue
+		}
+		if i == len(o) {
+			return name
+		}
+		return name + "." + o[i:].String()
+	}
+	return o.String()
+}
+
+// resolve parses an object identifier written either in dotted notation or
+// as the name of an object optionally followed by further components, such as
+// `sysUpTime.0`.
+func (m *mib) resolve(s string) (oid, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("empty object identifier")
+	}
+	if c := s[0]; c == '.' || (c >= '0' && c <= '9') {
+		return parseOID(s)
+	}
+
+	name, suffix, _ := strings.Cut(s, ".")
+	if i := strings.LastIndex(name, "::"); i >= 0 {
+		// Module names such as IF-MIB::ifDescr are not needed as object
+		// names are assumed to be unique.
+		name = name[i+2:]
+	}
+	base, exists := m.oids[name]
+	if !exists {
+		return nil, fmt.Errorf("object name '%v' is not defined by a known MIB", name)
+	}
+	o := append(oid(nil), base...)
+	if suffix != "" {
+		rest, err := parseOID(suffix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid object identifier '%v'", s)
+		}
+		o = append(o, rest...)
+	}
+	return o, nil
+}
+
+//------------------------------------------------------------------------------
+
+// mibMacros are the macros of SMIv1 and SMIv2 that assign an object
+// identifier to the name that precedes them.
+var mibMacros = map[string]bool{
+	"OBJECT-TYPE":        true,
+	"OBJECT-IDENTITY":    true,
+	"MODULE-IDENTITY":    true,
+	"NOTIFICATION-TYPE":  true,
+	"OBJECT-GROUP":       true,
+	"NOTIFICATION-GROUP": true,
+	"MODULE-COMPLIANCE":  true,
+	"AGENT-CAPABILITIES": true,
+}
+
+type mibDefinition struct {
+	name   string
+	parent string
+	arcs   oid
+}
+
+// load parses the object identifier assignments of MIB modules and adds the
+// names they define. Only the object identifiers of definitions are parsed,
+// and definitions that refer to objects of modules which have not been loaded
+// result in an error.
+func (m *mib) load(modules ...string) error {
+	var defs []mibDefinition
+	for _, module := range modules {
+		moduleDefs, err := parseMIBDefinitions(module)
+		if err != nil {
+			return err
+		}
+		defs = append(defs, moduleDefs...)
+	}
+
+	// Definitions may refer to those that follow them, or to those of other
+	// modules, and so they're resolved until no more progress is made.
+	for len(defs) > 0 {
+		var pending []mibDefinition
+		for _, d := range defs {
+			parent, exists := m.oids[d.parent]
+			if !exists && d.parent != "" {
+				pending = append(pending, d)
+				continue
+			}
+			m.add(d.name, append(append(oid(nil), parent...), d.arcs...))
+		}
+		if len(pending) == len(defs) {
+			return fmt.Errorf("failed to resolve the parent '%v' of object '%v'", pending[0].parent, pending[0].name)
+		}
+		defs = pending
+	}
+	return nil
+}
+
+func parseMIBDefinitions(module string) ([]mibDefinition, error) {
+	tokens := tokenizeMIB(module)
+
+	var defs []mibDefinition
+	var current string
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case mibMacros[tok]:
+			if i > 0 && isMIBValueName(tokens[i-1]) {
+				current = tokens[i-1]
+			}
+		case tok == "OBJECT":
+			if i > 0 && i+2 < len(tokens) && tokens[i+1] == "IDENTIFIER" && tokens[i+2] == "::=" && isMIBValueName(tokens[i-1]) {
+				current = tokens[i-1]
+			}
+		case tok == "::=":
+			if current == "" || i+1 >= len(tokens) || tokens[i+1] != "{" {
+				current = ""
+				continue
+			}
+			end := i + 2
+			for end < len(tokens) && tokens[end] != "}" {
+				end++
+			}
+			if end >= len(tokens) {
+				return nil, fmt.Errorf("unterminated object identifier of '%v'", current)
+			}
+			valueDefs, err := parseMIBValue(current, tokens[i+2:end])
+			if err != nil {
+				return nil, err
+			}
+			defs = append(defs, valueDefs...)
+			current = ""
+			i = end
+		}
+	}
+	return defs, nil
+}
+
+// parseMIBValue parses the components of an object identifier value such as
+// `{ ifEntry 2 }` or `{ iso org(3) dod(6) 1 }`, where named components with a
+// number also define those names.
+func parseMIBValue(name string, components []string) ([]mibDefinition, error) {
+	if len(components) == 0 {
+		return nil, fmt.Errorf("invalid object identifier of '%v'", name)
+	}
+
+	var defs []mibDefinition
+	parent, arcs := components[0], oid(nil)
+	if n, err := strconv.ParseUint(parent, 10, 32); err == nil {
+		// A value that starts with a number is absolute, which is represented
+		// by an empty parent.
+		parent, arcs = "", oid{uint32(n)}
+	}
+	for i := 1; i < len(components); i++ {
+		c := components[i]
+		if n, err := strconv.ParseUint(c, 10, 32); err == nil {
+			arcs = append(arcs, uint32(n))
+			continue
+		}
+		if i+3 < len(components) && components[i+1] == "(" && components[i+3] == ")" {
+			n, err := strconv.ParseUint(components[i+2], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid object identifier of '%v'", name)
+			}
+			arcs = append(arcs, uint32(n))
+			defs = append(defs, mibDefinition{name: c, parent: parent, arcs: append(oid(nil), arcs...)})
+			i += 3
+			continue
+		}
+		return nil, fmt.Errorf("invalid object identifier of '%v'", name)
+	}
+	return append(defs, mibDefinition{name: name, parent: parent, arcs: arcs}), nil
+}
+
+func isMIBValueName(tok string) bool {
+	r := []rune(tok)
+	return len(r) > 0 && unicode.IsLower(r[0])
+}
+
+// tokenizeMIB splits a MIB module into tokens, where comments and quoted
+// strings are omitted.
+func tokenizeMIB(module string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for i := 0; i < len(module); i++ {
+		c := module[i]
+		switch {
+		case c == '"':
+			flush()
+			end := strings.IndexByte(module[i+1:], '"')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case c == '-' && i+1 < len(module) && module[i+1] == '-':
+			// Comments end at either the end of the line or another pair of
+			// dashes.
+			flush()
+			i += 2
+			for i < len(module) && module[i] != '\n' {
+				if module[i] == '-' && i+1 < len(module) && module[i+1] == '-' {
+					i++
+					break
+				}
+				i++
+			}
+		case c == ':' && strings.HasPrefix(module[i:], "::="):
+			flush()
+			tokens = append(tokens, "::=")
+			i += 2
+		case c == '{' || c == '}' || c == '(' || c == ')' || c == ',' || c == ';':
+			flush()
+			tokens = append(tokens, string(c))
+		case unicode.IsSpace(rune(c)):
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return tokens
+}
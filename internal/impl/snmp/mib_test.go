@@ -0,0 +1,106 @@
+package snmp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testVendorSMI = `
+ACME-SMI DEFINITIONS ::= BEGIN
+
+IMPORTS
+    MODULE-IDENTITY, OBJECT-IDENTITY, enterprises
+        FROM SNMPv2-SMI;
+
+acme MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "Acme -- not a comment"
+    CONTACT-INFO "support@acme.example"
+    DESCRIPTION  "The structure of management information of Acme."
+    ::= { enterprises 99999 }
+
+acmeProducts OBJECT IDENTIFIER ::= { acme 1 }
+acmeMgmt     OBJECT IDENTIFIER ::= { acme 2 } -- Management objects
+
+END
+`
+
+const testVendorMIB = `
+ACME-ENV-MIB DEFINITIONS ::= BEGIN
+
+IMPORTS
+    OBJECT-TYPE, NOTIFICATION-TYPE, Integer32 FROM SNMPv2-SMI
+    acmeMgmt FROM ACME-SMI;
+
+acmeEnvTemperature OBJECT-TYPE
+    SYNTAX      Integer32 (-50..150)
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "The temperature of the chassis { not a value }."
+    ::= { acmeEnvObjects 1 }
+
+acmeEnvObjects OBJECT IDENTIFIER ::= { acmeMgmt env(4) 1 }
+
+acmeEnvOverheat NOTIFICATION-TYPE
+    OBJECTS { acmeEnvTemperature }
+    STATUS  current
+    DESCRIPTION "The chassis is overheating."
+    ::= { acmeMgmt 0 1 }
+
+END
+`
+
+func TestMIBLoad(t *testing.T) {
+	m := newMIB()
+	require.NoError(t, m.load(testVendorMIB, testVendorSMI))
+
+	for name, expected := range map[string]string{
+		"acme":               "1.3.6.1.4.1.99999",
+		"acmeProducts":       "1.3.6.1.4.1.99999.1",
+		"env":                "1.3.6.1.4.1.99999.2.4",
+		"acmeEnvObjects":     "1.3.6.1.4.1.99999.2.4.1",
+		"acmeEnvTemperature": "1.3.6.1.4.1.99999.2.4.1.1",
+		"acmeEnvOverheat":    "1.3.6.1.4.1.99999.2.0.1",
+	} {
+		o, err := m.resolve(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, o.String(), name)
+	}
+
+	assert.Equal(t, "acmeEnvTemperature.0", m.name(oid{1, 3, 6, 1, 4, 1, 99999, 2, 4, 1, 1, 0}))
+	assert.Equal(t, "acmeEnvOverheat", m.name(oid{1, 3, 6, 1, 4, 1, 99999, 2, 0, 1}))
+}
+
+func TestMIBLoadMissingParent(t *testing.T) {
+	m := newMIB()
+	require.EqualError(t, m.load(testVendorMIB), "failed to resolve the parent 'acmeEnvObjects' of object 'acmeEnvTemperature'")
+}
+
+func TestMIBResolve(t *testing.T) {
+	m := newMIB()
+
+	tests := map[string]string{
+		"sysUpTime.0":           "1.3.6.1.2.1.1.3.0",
+		"SNMPv2-MIB::sysName.0": "1.3.6.1.2.1.1.5.0",
+		"IF-MIB::ifHCInOctets":  "1.3.6.1.2.1.31.1.1.1.6",
+		".1.3.6.1.2.1.1.1.0":    "1.3.6.1.2.1.1.1.0",
+		"1.3.6.1.2.1.2.2.1.2":   "1.3.6.1.2.1.2.2.1.2",
+		"enterprises.9.9.13":    "1.3.6.1.4.1.9.9.13",
+	}
+	for s, expected := range tests {
+		o, err := m.resolve(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, o.String(), s)
+	}
+
+	_, err := m.resolve("notAnObject.0")
+	require.Error(t, err)
+
+	_, err = m.resolve("sysUpTime.zero")
+	require.Error(t, err)
+
+	assert.Equal(t, "linkDown", m.name(oid{1, 3, 6, 1, 6, 3, 1, 1, 5, 3}))
+	assert.Equal(t, "5.1", m.name(oid{5, 1}))
+}
@@ -0,0 +1,310 @@
+package snmp
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SNMP message versions as encoded on the wire.
+const (
+	version1  = 0
+	version2c = 1
+)
+
+// PDU types.
+const (
+	pduGetRequest     = 0xa0
+	pduGetNextRequest = 0xa1
+	pduResponse       = 0xa2
+	pduTrapV1         = 0xa4
+	pduGetBulkRequest = 0xa5
+	pduInformRequest  = 0xa6
+	pduTrapV2         = 0xa7
+)
+
+// Error statuses of response PDUs that are handled explicitly.
+const (
+	errorStatusNoSuchName = 2
+)
+
+// variable is a variable binding of a PDU.
+type variable struct {
+	oid oid
+	tag byte
+
+	// One of int64, uint64, []byte, oid, net.IP or nil depending on the tag.
+	value any
+}
+
+// packet is an SNMPv1 or SNMPv2c message.
+type packet struct {
+	version   int64
+	community string
+	pduType   byte
+
+	requestID int64
+	// For GetBulkRequest PDUs these contain the non-repeaters and
+	// max-repetitions respectively.
+	errorStatus int64
+	errorIndex  int64
+	variables   []variable
+
+	// Fields of SNMPv1 trap PDUs, which have no request ID or error fields.
+	enterprise   oid
+	agentAddress net.IP
+	genericTrap  int64
+	specificTrap int64
+	timestamp    uint64
+}
+
+func (p *packet) marshal() ([]byte, error) {
+	var varbinds []byte
+	for _, v := range p.variables {
+		vb, err := marshalVariable(v)
+		if err != nil {
+			return nil, err
+		}
+		varbinds = append(varbinds, vb...)
+	}
+
+	var pdu []byte
+	if p.pduType == pduTrapV1 {
+		enterprise, err := encodeOID(p.enterprise)
+		if err != nil {
+			return nil, err
+		}
+		pdu = appendTLV(pdu, tagOID, enterprise)
+		pdu = appendTLV(pdu, tagIPAddress, p.agentAddress.To4())
+		pdu = appendTLV(pdu, tagInteger, encodeInt(p.genericTrap))
+		pdu = appendTLV(pdu, tagInteger, encodeInt(p.specificTrap))
+		pdu = appendTLV(pdu, tagTimeTicks, encodeUint(p.timestamp))
+	} else {
+		pdu = appendTLV(pdu, tagInteger, encodeInt(p.requestID))
+		pdu = appendTLV(pdu, tagInteger, encodeInt(p.errorStatus))
+		pdu = appendTLV(pdu, tagInteger, encodeInt(p.errorIndex))
+	}
+	pdu = appendTLV(pdu, tagSequence, varbinds)
+
+	var msg []byte
+	msg = appendTLV(msg, tagInteger, encodeInt(p.version))
+	msg = appendTLV(msg, tagOctetString, []byte(p.community))
+	msg = appendTLV(msg, p.pduType, pdu)
+	return appendTLV(nil, tagSequence, msg), nil
+}
+
+func marshalVariable(v variable) ([]byte, error) {
+	name, err := encodeOID(v.oid)
+	if err != nil {
+		return nil, err
+	}
+
+	var value []byte
+	switch t := v.value.(type) {
+	case nil:
+	case int64:
+		value = encodeInt(t)
+	case uint64:
+		value = encodeUint(t)
+	case []byte:
+		value = t
+	case net.IP:
+		value = t.To4()
+	case oid:
+		if value, err = encodeOID(t); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported variable value type %T", v.value)
+	}
+
+	vb := appendTLV(nil, tagOID, name)
+	vb = appendTLV(vb, v.tag, value)
+	return appendTLV(nil, tagSequence, vb), nil
+}
+
+func unmarshalPacket(data []byte) (*packet, error) {
+	msg, _, err := readExpected(data, tagSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &packet{}
+	if p.version, msg, err = readInt(msg); err != nil {
+		return nil, fmt.Errorf("failed to read version: %w", err)
+	}
+	if p.version != version1 && p.version != version2c {
+		return nil, fmt.Errorf("unsupported version %v", p.version)
+	}
+
+	var community []byte
+	if community, msg, err = readExpected(msg, tagOctetString); err != nil {
+		return nil, fmt.Errorf("failed to read community: %w", err)
+	}
+	p.community = string(community)
+
+	var pdu []byte
+	if p.pduType, pdu, _, err = readTLV(msg); err != nil {
+		return nil, fmt.Errorf("failed to read pdu: %w", err)
+	}
+
+	if p.pduType == pduTrapV1 {
+		var value []byte
+		if value, pdu, err = readExpected(pdu, tagOID); err != nil {
+			return nil, fmt.Errorf("failed to read enterprise: %w", err)
+		}
+		if p.enterprise, err = decodeOID(value); err != nil {
+			return nil, fmt.Errorf("failed to read enterprise: %w", err)
+		}
+		if value, pdu, err = readExpected(pdu, tagIPAddress); err != nil || len(value) != 4 {
+			return nil, errors.New("failed to read agent address")
+		}
+		p.agentAddress = net.IP(append([]byte(nil), value...))
+		if p.genericTrap, pdu, err = readInt(pdu); err != nil {
+			return nil, fmt.Errorf("failed to read generic trap: %w", err)
+		}
+		if p.specificTrap, pdu, err = readInt(pdu); err != nil {
+			return nil, fmt.Errorf("failed to read specific trap: %w", err)
+		}
+		if value, pdu, err = readExpected(pdu, tagTimeTicks); err != nil {
+			return nil, fmt.Errorf("failed to read timestamp: %w", err)
+		}
+		if p.timestamp, err = decodeUint(value); err != nil {
+			return nil, fmt.Errorf("failed to read timestamp: %w", err)
+		}
+	} else {
+		if p.requestID, pdu, err = readInt(pdu); err != nil {
+			return nil, fmt.Errorf("failed to read request id: %w", err)
+		}
+		if p.errorStatus, pdu, err = readInt(pdu); err != nil {
+			return nil, fmt.Errorf("failed to read error status: %w", err)
+		}
+		if p.errorIndex, pdu, err = readInt(pdu); err != nil {
+			return nil, fmt.Errorf("failed to read error index: %w", err)
+		}
+	}
+
+	varbinds, _, err := readExpected(pdu, tagSequence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variable bindings: %w", err)
+	}
+	for len(varbinds) > 0 {
+		var vb []byte
+		if vb, varbinds, err = readExpected(varbinds, tagSequence); err != nil {
+			return nil, fmt.Errorf("failed to read variable binding: %w", err)
+		}
+		v, err := unmarshalVariable(vb)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read variable binding: %w", err)
+		}
+		p.variables = append(p.variables, v)
+	}
+	return p, nil
+}
+
+func unmarshalVariable(data []byte) (v variable, err error) {
+	name, data, err := readExpected(data, tagOID)
+	if err != nil {
+		return v, err
+	}
+	if v.oid, err = decodeOID(name); err != nil {
+		return v, err
+	}
+
+	var value []byte
+	if v.tag, value, _, err = readTLV(data); err != nil {
+		return v, err
+	}
+	switch v.tag {
+	case tagInteger:
+		v.value, err = decodeInt(value)
+	case tagOctetString, tagOpaque:
+		v.value = append([]byte(nil), value...)
+	case tagOID:
+		v.value, err = decodeOID(value)
+	case tagIPAddress:
+		if len(value) != 4 {
+			return v, fmt.Errorf("invalid ip address length %v", len(value))
+		}
+		v.value = net.IP(append([]byte(nil), value...))
+	case tagCounter32, tagGauge32, tagTimeTicks, tagCounter64:
+		v.value, err = decodeUint(value)
+	case tagNull, tagNoSuchObject, tagNoSuchInstance, tagEndOfMibView:
+	default:
+		return v, fmt.Errorf("unsupported value tag 0x%02x", v.tag)
+	}
+	return v, err
+}
+
+//------------------------------------------------------------------------------
+
+var typeNames = map[byte]string{
+	tagInteger:        "integer",
+	tagOctetString:    "octet_string",
+	tagNull:           "null",
+	tagOID:            "oid",
+	tagIPAddress:      "ip_address",
+	tagCounter32:      "counter32",
+	tagGauge32:        "gauge32",
+	tagTimeTicks:      "timeticks",
+	tagOpaque:         "opaque",
+	tagCounter64:      "counter64",
+	tagNoSuchObject:   "no_such_object",
+	tagNoSuchInstance: "no_such_instance",
+	tagEndOfMibView:   "end_of_mib_view",
+}
+
+// structured returns a variable as an object, where its name is resolved
+// with a MIB.
+func (v variable) structured(m *mib) map[string]any {
+	obj := map[string]any{
+		"oid":  v.oid.String(),
+		"name": m.name(v.oid),
+		"type": typeNames[v.tag],
+	}
+	switch t := v.value.(type) {
+	case nil:
+		obj["value"] = nil
+	case []byte:
+		obj["value"] = octetString(t)
+	case oid:
+		obj["value"] = t.String()
+	case net.IP:
+		obj["value"] = t.String()
+	case uint64:
+		if t > 1<<63-1 {
+			obj["value"] = t
+		} else {
+			obj["value"] = int64(t)
+		}
+	default:
+		obj["value"] = t
+	}
+	return obj
+}
+
+// octetString returns an octet string as text when it is printable, and
+// otherwise as colon separated hex bytes.
+func octetString(b []byte) string {
+	if utf8.Valid(b) {
+		printable := true
+		for _, r := range string(b) {
+			if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+				printable = false
+				break
+			}
+		}
+		if printable {
+			return string(b)
+		}
+	}
+	parts := make([]string, len(b))
+	for i, c := range b {
+		parts[i] = hex.EncodeToString([]byte{c})
+	}
+	return strings.Join(parts, ":")
+}
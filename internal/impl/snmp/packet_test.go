@@ -0,0 +1,147 @@
+package snmp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegerEncoding(t *testing.T) {
+	for _, v := range []int64{0, 1, -1, 127, 128, -128, -129, 256, 1 << 31, -1 << 31, 1<<63 - 1, -1 << 63} {
+		got, err := decodeInt(encodeInt(v))
+		require.NoError(t, err)
+		assert.Equal(t, v, got, "value %v", v)
+	}
+
+	for _, v := range []uint64{0, 127, 128, 255, 1<<32 - 1, 1<<64 - 1} {
+		got, err := decodeUint(encodeUint(v))
+		require.NoError(t, err)
+		assert.Equal(t, v, got, "value %v", v)
+	}
+
+	assert.Equal(t, []byte{0x00, 0x80}, encodeUint(128))
+	assert.Equal(t, []byte{0xff, 0x7f}, encodeInt(-129))
+}
+
+func TestOIDEncoding(t *testing.T) {
+	o, err := parseOID(".1.3.6.1.4.1.2636.3.1.13.1.5")
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.4.1.2636.3.1.13.1.5", o.String())
+
+	b, err := encodeOID(o)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0x94, 0x4c, 0x03, 0x01, 0x0d, 0x01, 0x05}, b)
+
+	got, err := decodeOID(b)
+	require.NoError(t, err)
+	assert.Equal(t, o, got)
+
+	_, err = decodeOID([]byte{0x2b, 0x94})
+	require.Error(t, err)
+
+	_, err = parseOID("1.3.six")
+	require.Error(t, err)
+
+	assert.Negative(t, oid{1, 3, 6}.compare(oid{1, 3, 6, 1}))
+	assert.Negative(t, oid{1, 3, 6, 1}.compare(oid{1, 3, 7}))
+	assert.Zero(t, oid{1, 3}.compare(oid{1, 3}))
+	assert.True(t, oid{1, 3, 6, 1}.hasPrefix(oid{1, 3, 6}))
+	assert.False(t, oid{1, 3, 7}.hasPrefix(oid{1, 3, 6}))
+}
+
+func TestPacketRoundTrip(t *testing.T) {
+	p := &packet{
+		version:   version2c,
+		community: "public",
+		pduType:   pduResponse,
+		requestID: 1234,
+		variables: []variable{
+			{oid: oid{1, 3, 6, 1, 2, 1, 1, 1, 0}, tag: tagOctetString, value: []byte("Linux router 5.10")},
+			{oid: oid{1, 3, 6, 1, 2, 1, 1, 2, 0}, tag: tagOID, value: oid{1, 3, 6, 1, 4, 1, 8072, 3, 2, 10}},
+			{oid: oid{1, 3, 6, 1, 2, 1, 1, 3, 0}, tag: tagTimeTicks, value: uint64(8432911)},
+			{oid: oid{1, 3, 6, 1, 2, 1, 2, 2, 1, 7, 1}, tag: tagInteger, value: int64(-3)},
+			{oid: oid{1, 3, 6, 1, 2, 1, 4, 20, 1, 1, 1}, tag: tagIPAddress, value: net.IP{10, 0, 0, 1}},
+			{oid: oid{1, 3, 6, 1, 2, 1, 31, 1, 1, 1, 6, 1}, tag: tagCounter64, value: uint64(1<<64 - 1)},
+			{oid: oid{1, 3, 6, 1, 2, 1, 1, 9, 0}, tag: tagNoSuchObject},
+		},
+	}
+
+	data, err := p.marshal()
+	require.NoError(t, err)
+
+	got, err := unmarshalPacket(data)
+	require.NoError(t, err)
+	assert.Equal(t, p, got)
+
+	_, err = unmarshalPacket(data[:len(data)-3])
+	require.Error(t, err)
+}
+
+func TestPacketTrapV1RoundTrip(t *testing.T) {
+	p := &packet{
+		version:      version1,
+		community:    "traps",
+		pduType:      pduTrapV1,
+		enterprise:   oid{1, 3, 6, 1, 4, 1, 9},
+		agentAddress: net.IP{192, 168, 1, 1},
+		genericTrap:  6,
+		specificTrap: 17,
+		timestamp:    5000,
+		variables: []variable{
+			{oid: oid{1, 3, 6, 1, 4, 1, 9, 9, 1}, tag: tagGauge32, value: uint64(42)},
+		},
+	}
+
+	data, err := p.marshal()
+	require.NoError(t, err)
+
+	got, err := unmarshalPacket(data)
+	require.NoError(t, err)
+	assert.Equal(t, p, got)
+}
+
+func TestVariableStructured(t *testing.T) {
+	m := newMIB()
+
+	tests := []struct {
+		v        variable
+		expected map[string]any
+	}{
+		{
+			v: variable{oid: oid{1, 3, 6, 1, 2, 1, 2, 2, 1, 2, 3}, tag: tagOctetString, value: []byte("eth0")},
+			expected: map[string]any{
+				"oid": "1.3.6.1.2.1.2.2.1.2.3", "name": "ifDescr.3", "type": "octet_string", "value": "eth0",
+			},
+		},
+		{
+			v: variable{oid: oid{1, 3, 6, 1, 2, 1, 2, 2, 1, 6, 3}, tag: tagOctetString, value: []byte{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}},
+			expected: map[string]any{
+				"oid": "1.3.6.1.2.1.2.2.1.6.3", "name": "ifPhysAddress.3", "type": "octet_string", "value": "00:1a:2b:3c:4d:5e",
+			},
+		},
+		{
+			v: variable{oid: oid{1, 3, 6, 1, 2, 1, 2, 2, 1, 10, 3}, tag: tagCounter32, value: uint64(77843421)},
+			expected: map[string]any{
+				"oid": "1.3.6.1.2.1.2.2.1.10.3", "name": "ifInOctets.3", "type": "counter32", "value": int64(77843421),
+			},
+		},
+		{
+			v: variable{oid: oid{1, 3, 6, 1, 4, 1, 9, 2, 1}, tag: tagNoSuchInstance},
+			expected: map[string]any{
+				"oid": "1.3.6.1.4.1.9.2.1", "name": "enterprises.9.2.1", "type": "no_such_instance", "value": nil,
+			},
+		},
+		{
+			v: variable{oid: oid{1, 2, 3}, tag: tagIPAddress, value: net.IP{10, 0, 0, 1}},
+			expected: map[string]any{
+				"oid": "1.2.3", "name": "iso.2.3", "type": "ip_address", "value": "10.0.0.1",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.v.structured(m))
+	}
+}
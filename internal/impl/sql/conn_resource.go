@@ -0,0 +1,165 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/interop"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	dinterop "github.com/benthosdev/benthos/v4/internal/docs/interop"
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func init() {
+	fields := docs.FieldSpecs{
+		docs.FieldString("label", "A unique label for the resource, which SQL components reference with their `conn_resource` field."),
+	}
+	for _, f := range connResourceSpecFields() {
+		fields = append(fields, dinterop.Unwrap(f))
+	}
+	manager.SQLResourceFields = fields
+}
+
+func connResourceSpecFields() []*service.ConfigField {
+	return append([]*service.ConfigField{driverField(), dsnField()}, connFields()...)
+}
+
+func connResourceSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().Fields(connResourceSpecFields()...)
+}
+
+// connResourceField returns a field for referencing an sql resource, which
+// components that support it add alongside optional driver and dsn fields.
+func connResourceField() *service.ConfigField {
+	return service.NewStringField("conn_resource").
+		Description("An optional [SQL resource](/docs/configuration/resources#sql-resources) to execute queries with. When set the shared connection pool of the resource is used, and the fields `driver`, `dsn` and connection settings of this component are ignored.").
+		Optional().
+		Advanced().
+		Version("4.24.0")
+}
+
+// connResourceFields returns the fields of a component that connects either to
+// a database with its own pool of connections or via an sql resource.
+func connResourceFields() []*service.ConfigField {
+	return []*service.ConfigField{
+		driverField().Optional(),
+		dsnField().Optional(),
+		connResourceField(),
+	}
+}
+
+const connResourceLintRule = `root = if this.conn_resource.or("") == "" && (this.driver.or("") == "" || this.dsn.or("") == "") {
+  "either the fields driver and dsn, or the field conn_resource must be set"
+}`
+
+//------------------------------------------------------------------------------
+
+// sharedDB is the database of an sql resource, which is shared by all
+// components that reference it.
+type sharedDB struct {
+	db     *sql.DB
+	driver string
+}
+
+func (s *sharedDB) Close(ctx context.Context) error {
+	return s.db.Close()
+}
+
+func getSharedDB(mgr *service.Resources, name string) (*sharedDB, error) {
+	nm := interop.UnwrapManagement(mgr)
+	if !nm.ProbeSQLDB(name) {
+		return nil, fmt.Errorf("sql resource '%v' was not found", name)
+	}
+
+	d, err := nm.GetSQLDB(name, func(fields map[string]any, _ bundle.NewManagement) (any, error) {
+		confBytes, err := yaml.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		conf, err := connResourceSpec().ParseYAML(string(confBytes), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		dbConf, err := dbConfigFromParsed(conf, mgr)
+		if err != nil {
+			return nil, err
+		}
+
+		db, err := sqlOpenWithReworks(mgr.Logger(), dbConf.driver, dbConf.dsn)
+		if err != nil {
+			return nil, err
+		}
+		dbConf.connSettings.apply(context.Background(), db, mgr.Logger())
+		return &sharedDB{db: db, driver: dbConf.driver}, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init sql resource '%v': %w", name, err)
+	}
+
+	shared, ok := d.(*sharedDB)
+	if !ok {
+		return nil, errors.New("sql resource is of an unexpected type")
+	}
+	return shared, nil
+}
+
+//------------------------------------------------------------------------------
+
+// dbConfig describes the database of a component, which is either opened by
+// the component with its own pool of connections or shared from an sql
+// resource.
+type dbConfig struct {
+	driver       string
+	dsn          string
+	connSettings *connSettings
+
+	shared *sharedDB
+}
+
+func dbConfigFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (c dbConfig, err error) {
+	if conf.Contains("conn_resource") {
+		var name string
+		if name, err = conf.FieldString("conn_resource"); err != nil {
+			return
+		}
+		if name != "" {
+			if c.shared, err = getSharedDB(mgr, name); err != nil {
+				return
+			}
+			c.driver = c.shared.driver
+			return
+		}
+	}
+
+	if c.driver, err = conf.FieldString("driver"); err != nil {
+		return
+	}
+	if c.dsn, err = conf.FieldString("dsn"); err != nil {
+		return
+	}
+	c.connSettings, err = connSettingsFromParsed(conf, mgr)
+	return
+}
+
+// open returns the database of the component along with a func that releases
+// it once the component has finished with it. Databases shared from an sql
+// resource are not closed by the func as they're owned by the resource.
+func (c dbConfig) open(ctx context.Context, logger *service.Logger) (*sql.DB, func() error, error) {
+	if c.shared != nil {
+		return c.shared.db, func() error { return nil }, nil
+	}
+
+	db, err := sqlOpenWithReworks(logger, c.driver, c.dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.connSettings.apply(ctx, db, logger)
+	return db, db.Close, nil
+}
@@ -0,0 +1,89 @@
+package sql_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestConnResource(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	builder := service.NewStreamBuilder()
+	require.NoError(t, builder.SetLoggerYAML(`level: OFF`))
+	require.NoError(t, builder.AddResourcesYAML(fmt.Sprintf(`
+sql_resources:
+  - label: things_db
+    driver: sqlite
+    dsn: file:%v/foo.db
+    conn_max_open: 1
+    init_statement: |
+      CREATE TABLE IF NOT EXISTS things (
+        foo varchar(50) not null,
+        bar varchar(50) not null,
+        primary key (foo)
+      ) WITHOUT ROWID;
+`, t.TempDir())))
+
+	require.NoError(t, builder.AddProcessorYAML(`
+sql_insert:
+  conn_resource: things_db
+  table: things
+  columns: [ foo, bar ]
+  args_mapping: 'root = [ this.foo, this.bar ]'
+`))
+	require.NoError(t, builder.AddProcessorYAML(`
+sql_select:
+  conn_resource: things_db
+  table: things
+  columns: [ foo, bar ]
+  where: foo = ?
+  args_mapping: 'root = [ this.foo ]'
+`))
+
+	inFn, err := builder.AddProducerFunc()
+	require.NoError(t, err)
+
+	resChan := make(chan string, 2)
+	require.NoError(t, builder.AddConsumerFunc(func(ctx context.Context, m *service.Message) error {
+		b, err := m.AsBytes()
+		if err != nil {
+			return err
+		}
+		resChan <- string(b)
+		return nil
+	}))
+
+	strm, err := builder.Build()
+	require.NoError(t, err)
+
+	go func() {
+		assert.NoError(t, strm.Run(tCtx))
+	}()
+
+	require.NoError(t, inFn(tCtx, service.NewMessage([]byte(`{"foo":"first","bar":"first bar"}`))))
+	require.NoError(t, inFn(tCtx, service.NewMessage([]byte(`{"foo":"second","bar":"second bar"}`))))
+
+	assert.Equal(t, `[{"bar":"first bar","foo":"first"}]`, <-resChan)
+	assert.Equal(t, `[{"bar":"second bar","foo":"second"}]`, <-resChan)
+
+	require.NoError(t, strm.Stop(tCtx))
+}
+
+func TestConnResourceLint(t *testing.T) {
+	builder := service.NewStreamBuilder()
+	err := builder.AddProcessorYAML(`
+sql_select:
+  table: things
+  columns: [ foo, bar ]
+`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "either the fields driver and dsn, or the field conn_resource must be set")
+}
@@ -0,0 +1,145 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func newSQLiteOutbox(t *testing.T, extra string) (*sqlOutboxInput, *sql.DB) {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%v/foo.db", t.TempDir())
+
+	db, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	_, err = db.Exec(`CREATE TABLE outbox (
+  id integer primary key,
+  payload varchar(50) not null,
+  dispatched_at timestamp
+);`)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err = db.Exec(`INSERT INTO outbox (payload) VALUES (?)`, fmt.Sprintf("event %v", i))
+		require.NoError(t, err)
+	}
+
+	conf, err := sqlOutboxInputConfig().ParseYAML(fmt.Sprintf(`
+driver: sqlite
+dsn: %v
+table: outbox
+columns: [ id, payload ]
+batch_size: 2
+poll_interval: 10ms
+%v
+`, dsn, extra), nil)
+	require.NoError(t, err)
+
+	i, err := newSQLOutboxInputFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, i.Close(context.Background()))
+	})
+
+	require.NoError(t, i.Connect(context.Background()))
+	return i, db
+}
+
+func outboxBatchPayloads(t *testing.T, batch service.MessageBatch) (payloads []string) {
+	t.Helper()
+	for _, m := range batch {
+		v, err := m.AsStructured()
+		require.NoError(t, err)
+		payloads = append(payloads, v.(map[string]any)["payload"].(string))
+	}
+	return
+}
+
+func TestSQLOutboxDelete(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	i, db := newSQLiteOutbox(t, "")
+
+	batch, ackFn, err := i.ReadBatch(tCtx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"event 0", "event 1"}, outboxBatchPayloads(t, batch))
+
+	id, _ := batch[0].MetaGetMut("sql_outbox_id")
+	assert.Equal(t, int64(1), id)
+
+	// Rejected rows are read again
+	require.NoError(t, ackFn(tCtx, errors.New("nope")))
+
+	batch, ackFn, err = i.ReadBatch(tCtx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"event 0", "event 1"}, outboxBatchPayloads(t, batch))
+	require.NoError(t, ackFn(tCtx, nil))
+
+	batch, ackFn, err = i.ReadBatch(tCtx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"event 2", "event 3"}, outboxBatchPayloads(t, batch))
+	require.NoError(t, ackFn(tCtx, nil))
+
+	batch, ackFn, err = i.ReadBatch(tCtx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"event 4"}, outboxBatchPayloads(t, batch))
+	require.NoError(t, ackFn(tCtx, nil))
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM outbox`).Scan(&count))
+	assert.Equal(t, 0, count)
+
+	// Rows inserted later are picked up by polling
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		_, err := db.Exec(`INSERT INTO outbox (payload) VALUES ('event 5')`)
+		assert.NoError(t, err)
+	}()
+
+	batch, ackFn, err = i.ReadBatch(tCtx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"event 5"}, outboxBatchPayloads(t, batch))
+	require.NoError(t, ackFn(tCtx, nil))
+}
+
+func TestSQLOutboxDispatchedColumn(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	i, db := newSQLiteOutbox(t, `dispatched_column: dispatched_at`)
+
+	for _, exp := range [][]string{
+		{"event 0", "event 1"},
+		{"event 2", "event 3"},
+		{"event 4"},
+	} {
+		batch, ackFn, err := i.ReadBatch(tCtx)
+		require.NoError(t, err)
+		assert.Equal(t, exp, outboxBatchPayloads(t, batch))
+		require.NoError(t, ackFn(tCtx, nil))
+	}
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM outbox WHERE dispatched_at IS NOT NULL`).Scan(&count))
+	assert.Equal(t, 5, count)
+
+	readCtx, readDone := context.WithTimeout(tCtx, time.Millisecond*100)
+	defer readDone()
+
+	_, _, err := i.ReadBatch(readCtx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -17,8 +17,8 @@ func sqlRawInputConfig() *service.ConfigSpec {
 		Categories("Services").
 		Summary("Executes a select query and creates a message for each row received.").
 		Description(`Once the rows from the query are exhausted this input shuts down, allowing the pipeline to gracefully terminate (or the next input in a [sequence](/docs/components/inputs/sequence) to execute).`).
-		Field(driverField).
-		Field(dsnField).
+		Field(driverField()).
+		Field(dsnField()).
 		Field(rawQueryField().
 			Example("SELECT * FROM footable WHERE user_id = $1;")).
 		Field(service.NewBloblangField("args_mapping").
@@ -19,8 +19,8 @@ func sqlSelectInputConfig() *service.ConfigSpec {
 		Categories("Services").
 		Summary("Executes a select query and creates a message for each row received.").
 		Description(`Once the rows from the query are exhausted this input shuts down, allowing the pipeline to gracefully terminate (or the next input in a [sequence](/docs/components/inputs/sequence) to execute).`).
-		Field(driverField).
-		Field(dsnField).
+		Field(driverField()).
+		Field(dsnField()).
 		Field(service.NewStringField("table").
 			Description("The table to select from.").
 			Example("foo")).
@@ -14,7 +14,7 @@ func sqlDeprecatedOutputConfig() *service.ConfigSpec {
 ## Alternatives
 
 For basic inserts use the ` + "[`sql_insert`](/docs/components/outputs/sql)" + ` output. For more complex queries use the ` + "[`sql_raw`](/docs/components/outputs/sql_raw)" + ` output.`).
-		Field(driverField).
+		Field(driverField()).
 		Field(service.NewStringField("data_source_name").Description("Data source name.")).
 		Field(rawQueryField().
 			Example("INSERT INTO footable (foo, bar, baz) VALUES (?, ?, ?);")).
@@ -77,5 +77,9 @@ func newSQLDeprecatedOutputFromConfig(conf *service.ParsedConfig, mgr *service.R
 	if err != nil {
 		return nil, err
 	}
-	return newSQLRawOutput(mgr.Logger(), driverStr, dsnStr, queryStatic, nil, argsMapping, connSettings), nil
+	return newSQLRawOutput(mgr.Logger(), dbConfig{
+		driver:       driverStr,
+		dsn:          dsnStr,
+		connSettings: connSettings,
+	}, queryStatic, nil, argsMapping), nil
 }
@@ -19,8 +19,8 @@ func sqlInsertOutputConfig() *service.ConfigSpec {
 		Categories("Services").
 		Summary("Inserts a row into an SQL database for each message.").
 		Description(``).
-		Field(driverField).
-		Field(dsnField).
+		Fields(connResourceFields()...).
+		LintRule(connResourceLintRule).
 		Field(service.NewStringField("table").
 			Description("The table to insert to.").
 			Example("foo")).
@@ -93,7 +93,7 @@ func init() {
 
 type sqlInsertOutput struct {
 	driver  string
-	dsn     string
+	dbConf  dbConfig
 	db      *sql.DB
 	builder squirrel.InsertBuilder
 	dbMut   sync.RWMutex
@@ -101,8 +101,6 @@ type sqlInsertOutput struct {
 	useTxStmt   bool
 	argsMapping *bloblang.Executor
 
-	connSettings *connSettings
-
 	logger  *service.Logger
 	shutSig *shutdown.Signaller
 }
@@ -115,9 +113,10 @@ func newSQLInsertOutputFromConfig(conf *service.ParsedConfig, mgr *service.Resou
 
 	var err error
 
-	if s.driver, err = conf.FieldString("driver"); err != nil {
+	if s.dbConf, err = dbConfigFromParsed(conf, mgr); err != nil {
 		return nil, err
 	}
+	s.driver = s.dbConf.driver
 	if _, in := map[string]struct{}{
 		"clickhouse": {},
 		"oracle":     {},
@@ -125,10 +124,6 @@ func newSQLInsertOutputFromConfig(conf *service.ParsedConfig, mgr *service.Resou
 		s.useTxStmt = true
 	}
 
-	if s.dsn, err = conf.FieldString("dsn"); err != nil {
-		return nil, err
-	}
-
 	tableStr, err := conf.FieldString("table")
 	if err != nil {
 		return nil, err
@@ -175,10 +170,6 @@ func newSQLInsertOutputFromConfig(conf *service.ParsedConfig, mgr *service.Resou
 		}
 		s.builder = s.builder.Suffix(suffixStr)
 	}
-
-	if s.connSettings, err = connSettingsFromParsed(conf, mgr); err != nil {
-		return nil, err
-	}
 	return s, nil
 }
 
@@ -191,17 +182,16 @@ func (s *sqlInsertOutput) Connect(ctx context.Context) error {
 	}
 
 	var err error
-	if s.db, err = sqlOpenWithReworks(s.logger, s.driver, s.dsn); err != nil {
+	var closeDB func() error
+	if s.db, closeDB, err = s.dbConf.open(ctx, s.logger); err != nil {
 		return err
 	}
 
-	s.connSettings.apply(ctx, s.db, s.logger)
-
 	go func() {
 		<-s.shutSig.CloseNowChan()
 
 		s.dbMut.Lock()
-		_ = s.db.Close()
+		_ = closeDB()
 		s.dbMut.Unlock()
 
 		s.shutSig.ShutdownComplete()
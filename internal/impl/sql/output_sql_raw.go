@@ -17,8 +17,8 @@ func sqlRawOutputConfig() *service.ConfigSpec {
 		Categories("Services").
 		Summary("Executes an arbitrary SQL query for each message.").
 		Description(``).
-		Field(driverField).
-		Field(dsnField).
+		Fields(connResourceFields()...).
+		LintRule(connResourceLintRule).
 		Field(rawQueryField().
 			Example("INSERT INTO footable (foo, bar, baz) VALUES (?, ?, ?);")).
 		Field(service.NewBoolField("unsafe_dynamic_query").
@@ -81,8 +81,7 @@ func init() {
 //------------------------------------------------------------------------------
 
 type sqlRawOutput struct {
-	driver string
-	dsn    string
+	dbConf dbConfig
 	db     *sql.DB
 	dbMut  sync.RWMutex
 
@@ -91,19 +90,12 @@ type sqlRawOutput struct {
 
 	argsMapping *bloblang.Executor
 
-	connSettings *connSettings
-
 	logger  *service.Logger
 	shutSig *shutdown.Signaller
 }
 
 func newSQLRawOutputFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*sqlRawOutput, error) {
-	driverStr, err := conf.FieldString("driver")
-	if err != nil {
-		return nil, err
-	}
-
-	dsnStr, err := conf.FieldString("dsn")
+	dbConf, err := dbConfigFromParsed(conf, mgr)
 	if err != nil {
 		return nil, err
 	}
@@ -129,30 +121,23 @@ func newSQLRawOutputFromConfig(conf *service.ParsedConfig, mgr *service.Resource
 		}
 	}
 
-	connSettings, err := connSettingsFromParsed(conf, mgr)
-	if err != nil {
-		return nil, err
-	}
-	return newSQLRawOutput(mgr.Logger(), driverStr, dsnStr, queryStatic, queryDyn, argsMapping, connSettings), nil
+	return newSQLRawOutput(mgr.Logger(), dbConf, queryStatic, queryDyn, argsMapping), nil
 }
 
 func newSQLRawOutput(
 	logger *service.Logger,
-	driverStr, dsnStr string,
+	dbConf dbConfig,
 	queryStatic string,
 	queryDyn *service.InterpolatedString,
 	argsMapping *bloblang.Executor,
-	connSettings *connSettings,
 ) *sqlRawOutput {
 	return &sqlRawOutput{
-		logger:       logger,
-		shutSig:      shutdown.NewSignaller(),
-		driver:       driverStr,
-		dsn:          dsnStr,
-		queryStatic:  queryStatic,
-		queryDyn:     queryDyn,
-		argsMapping:  argsMapping,
-		connSettings: connSettings,
+		logger:      logger,
+		shutSig:     shutdown.NewSignaller(),
+		dbConf:      dbConf,
+		queryStatic: queryStatic,
+		queryDyn:    queryDyn,
+		argsMapping: argsMapping,
 	}
 }
 
@@ -165,17 +150,16 @@ func (s *sqlRawOutput) Connect(ctx context.Context) error {
 	}
 
 	var err error
-	if s.db, err = sqlOpenWithReworks(s.logger, s.driver, s.dsn); err != nil {
+	var closeDB func() error
+	if s.db, closeDB, err = s.dbConf.open(ctx, s.logger); err != nil {
 		return err
 	}
 
-	s.connSettings.apply(ctx, s.db, s.logger)
-
 	go func() {
 		<-s.shutSig.CloseNowChan()
 
 		s.dbMut.Lock()
-		_ = s.db.Close()
+		_ = closeDB()
 		s.dbMut.Unlock()
 
 		s.shutSig.ShutdownComplete()
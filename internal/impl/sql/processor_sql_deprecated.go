@@ -17,7 +17,7 @@ If the query fails to execute then the message will remain unchanged and the err
 ## Alternatives
 
 For basic inserts or select queries use use either the ` + "[`sql_insert`](/docs/components/processors/sql_insert)" + ` or the ` + "[`sql_select`](/docs/components/processors/sql_select)" + ` processor. For more complex queries use the ` + "[`sql_raw`](/docs/components/processors/sql_raw)" + ` processor.`).
-		Field(driverField).
+		Field(driverField()).
 		Field(service.NewStringField("data_source_name").Description("Data source name.")).
 		Field(rawQueryField().
 			Example("INSERT INTO footable (foo, bar, baz) VALUES (?, ?, ?);")).
@@ -93,5 +93,9 @@ func NewSQLDeprecatedProcessorFromConfig(conf *service.ParsedConfig, mgr *servic
 	if err != nil {
 		return nil, err
 	}
-	return newSQLRawProcessor(mgr.Logger(), driverStr, dsnStr, queryStatic, queryDyn, onlyExec, argsMapping, connSettings)
+	return newSQLRawProcessor(mgr.Logger(), dbConfig{
+		driver:       driverStr,
+		dsn:          dsnStr,
+		connSettings: connSettings,
+	}, queryStatic, queryDyn, onlyExec, argsMapping, 0)
 }
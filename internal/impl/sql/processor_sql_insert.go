@@ -21,8 +21,8 @@ func InsertProcessorConfig() *service.ConfigSpec {
 		Summary("Inserts rows into an SQL database for each message, and leaves the message unchanged.").
 		Description(`
 If the insert fails to execute then the message will still remain unchanged and the error can be caught using error handling methods outlined [here](/docs/configuration/error_handling).`).
-		Field(driverField).
-		Field(dsnField).
+		Fields(connResourceFields()...).
+		LintRule(connResourceLintRule).
 		Field(service.NewStringField("table").
 			Description("The table to insert to.").
 			Example("foo")).
@@ -103,10 +103,12 @@ func NewSQLInsertProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Re
 		shutSig: shutdown.NewSignaller(),
 	}
 
-	driverStr, err := conf.FieldString("driver")
+	dbConf, err := dbConfigFromParsed(conf, mgr)
 	if err != nil {
 		return nil, err
 	}
+
+	driverStr := dbConf.driver
 	if _, in := map[string]struct{}{
 		"clickhouse": {},
 		"oracle":     {},
@@ -114,11 +116,6 @@ func NewSQLInsertProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Re
 		s.useTxStmt = true
 	}
 
-	dsnStr, err := conf.FieldString("dsn")
-	if err != nil {
-		return nil, err
-	}
-
 	tableStr, err := conf.FieldString("table")
 	if err != nil {
 		return nil, err
@@ -166,22 +163,16 @@ func NewSQLInsertProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Re
 		s.builder = s.builder.Suffix(suffixStr)
 	}
 
-	connSettings, err := connSettingsFromParsed(conf, mgr)
-	if err != nil {
+	var closeDB func() error
+	if s.db, closeDB, err = dbConf.open(context.Background(), s.logger); err != nil {
 		return nil, err
 	}
 
-	if s.db, err = sqlOpenWithReworks(mgr.Logger(), driverStr, dsnStr); err != nil {
-		return nil, err
-	}
-
-	connSettings.apply(context.Background(), s.db, s.logger)
-
 	go func() {
 		<-s.shutSig.CloseNowChan()
 
 		s.dbMut.Lock()
-		_ = s.db.Close()
+		_ = closeDB()
 		s.dbMut.Unlock()
 
 		s.shutSig.ShutdownComplete()
@@ -0,0 +1,225 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+
+	_ "modernc.org/sqlite"
+)
+
+func newSQLiteRawProcessor(t *testing.T, dsn, extra string) *sqlRawProcessor {
+	t.Helper()
+
+	conf, err := RawProcessorConfig().ParseYAML(fmt.Sprintf(`
+driver: sqlite
+dsn: %v
+init_statement: |
+  CREATE TABLE IF NOT EXISTS things (
+    foo varchar(50) not null,
+    bar varchar(50) not null,
+    primary key (foo)
+  ) WITHOUT ROWID;
+%v
+`, dsn, extra), nil)
+	require.NoError(t, err)
+
+	p, err := NewSQLRawProcessorFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, p.Close(context.Background()))
+	})
+	return p
+}
+
+func TestSQLRawProcessorBatchInsert(t *testing.T) {
+	dsn := fmt.Sprintf("file:%v/foo.db", t.TempDir())
+
+	insertProc := newSQLiteRawProcessor(t, dsn, `
+query: INSERT INTO things (foo, bar) VALUES (?, ?) ON CONFLICT DO NOTHING
+args_mapping: 'root = [ this.foo, this.bar ]'
+batch_insert: true
+prepared_statement_cache_size: 1
+`)
+
+	selectProc := newSQLiteRawProcessor(t, dsn, `
+query: SELECT foo, bar FROM things ORDER BY foo
+result_map: |
+  root.rows = this
+  meta count = this.length()
+prepared_statement_cache_size: 1
+`)
+
+	for _, batch := range [][]string{
+		{`{"foo":"a","bar":"a1"}`, `{"foo":"b","bar":"b1"}`},
+		{`{"foo":"c","bar":"c1"}`, `{"foo":"d","bar":"d1"}`, `{"foo":"a","bar":"ignored"}`},
+		{`{"foo":"e","bar":"e1"}`},
+	} {
+		var inBatch service.MessageBatch
+		for _, m := range batch {
+			inBatch = append(inBatch, service.NewMessage([]byte(m)))
+		}
+
+		res, err := insertProc.ProcessBatch(context.Background(), inBatch)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Len(t, res[0], len(batch))
+		for i, m := range res[0] {
+			require.NoError(t, m.GetError())
+			mBytes, err := m.AsBytes()
+			require.NoError(t, err)
+			assert.Equal(t, batch[i], string(mBytes))
+		}
+	}
+
+	res, err := selectProc.ProcessBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"id":"first"}`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Len(t, res[0], 1)
+
+	require.NoError(t, res[0][0].GetError())
+	mBytes, err := res[0][0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"first","rows":[{"bar":"a1","foo":"a"},{"bar":"b1","foo":"b"},{"bar":"c1","foo":"c"},{"bar":"d1","foo":"d"},{"bar":"e1","foo":"e"}]}`, string(mBytes))
+
+	count, _ := res[0][0].MetaGetMut("count")
+	assert.Equal(t, int64(5), count)
+}
+
+func TestSQLRawProcessorErrorMeta(t *testing.T) {
+	dsn := fmt.Sprintf("file:%v/foo.db", t.TempDir())
+
+	insertProc := newSQLiteRawProcessor(t, dsn, `
+query: INSERT INTO things (foo, bar) VALUES (?, ?)
+args_mapping: 'root = [ this.foo, this.bar ]'
+exec_only: true
+`)
+
+	res, err := insertProc.ProcessBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"foo":"a","bar":"a1"}`)),
+		service.NewMessage([]byte(`{"foo":"a","bar":"a2"}`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Len(t, res[0], 2)
+
+	require.NoError(t, res[0][0].GetError())
+	_, exists := res[0][0].MetaGetMut("sql_error")
+	assert.False(t, exists)
+
+	require.Error(t, res[0][1].GetError())
+	errMeta, exists := res[0][1].MetaGetMut("sql_error")
+	require.True(t, exists)
+	assert.Equal(t, map[string]any{"code": int64(1555)}, errMeta) // SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+func TestSQLRawProcessorLint(t *testing.T) {
+	err := service.NewStreamBuilder().AddProcessorYAML(`
+sql_raw:
+  driver: sqlite
+  dsn: file:foo.db
+  query: INSERT INTO things (foo, bar) VALUES (?, ?)
+  unsafe_dynamic_query: true
+  batch_insert: true
+`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the field batch_insert cannot be used with unsafe_dynamic_query")
+}
+
+func TestExpandValuesTuple(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		rows       int
+		argsPerRow int
+		expected   string
+		err        string
+	}{
+		{
+			name:       "question marks",
+			query:      "INSERT INTO foo (a, b) VALUES (?, ?);",
+			rows:       3,
+			argsPerRow: 2,
+			expected:   "INSERT INTO foo (a, b) VALUES (?, ?), (?, ?), (?, ?);",
+		},
+		{
+			name:       "dollar placeholders with suffix",
+			query:      "insert into foo (a, b, c) values ($1, lower($2), 'x:1') on conflict (a) do nothing",
+			rows:       2,
+			argsPerRow: 2,
+			expected:   "insert into foo (a, b, c) values ($1, lower($2), 'x:1'), ($3, lower($4), 'x:3') on conflict (a) do nothing",
+		},
+		{
+			name:       "oracle placeholders",
+			query:      "INSERT INTO foo (a) VALUES (:1)",
+			rows:       2,
+			argsPerRow: 1,
+			expected:   "INSERT INTO foo (a) VALUES (:1), (:2)",
+		},
+		{
+			name:  "no values",
+			query: "UPDATE foo SET a = ?",
+			rows:  2,
+			err:   "query does not contain a VALUES clause",
+		},
+		{
+			name:  "unterminated",
+			query: "INSERT INTO foo (a) VALUES (?",
+			rows:  2,
+			err:   "query contains an unterminated VALUES tuple",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			res, err := expandValuesTuple(test.query, test.rows, test.argsPerRow)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+type fakePQError struct {
+	Code string
+}
+
+func (e *fakePQError) Error() string    { return "pq: " + e.Code }
+func (e *fakePQError) SQLState() string { return e.Code }
+
+type fakeMySQLError struct {
+	Number   uint16
+	SQLState [5]byte
+}
+
+func (e *fakeMySQLError) Error() string { return "mysql" }
+
+func TestSQLErrorMeta(t *testing.T) {
+	assert.Nil(t, sqlErrorMeta(errors.New("nope")))
+
+	assert.Equal(t, map[string]any{
+		"code":  "23505",
+		"state": "23505",
+	}, sqlErrorMeta(fmt.Errorf("wrapped: %w", &fakePQError{Code: "23505"})))
+
+	assert.Equal(t, map[string]any{
+		"code":  int64(1062),
+		"state": "23000",
+	}, sqlErrorMeta(&fakeMySQLError{Number: 1062, SQLState: [5]byte{'2', '3', '0', '0', '0'}}))
+
+	assert.Equal(t, map[string]any{
+		"code": int64(1064),
+	}, sqlErrorMeta(&fakeMySQLError{Number: 1064}))
+}
@@ -21,8 +21,8 @@ func SelectProcessorConfig() *service.ConfigSpec {
 		Summary("Runs an SQL select query against a database and returns the result as an array of objects, one for each row returned, containing a key for each column queried and its value.").
 		Description(`
 If the query fails to execute then the message will remain unchanged and the error can be caught using error handling methods outlined [here](/docs/configuration/error_handling).`).
-		Field(driverField).
-		Field(dsnField).
+		Fields(connResourceFields()...).
+		LintRule(connResourceLintRule).
 		Field(service.NewStringField("table").
 			Description("The table to query.").
 			Example("foo")).
@@ -111,15 +111,11 @@ func NewSQLSelectProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Re
 		shutSig: shutdown.NewSignaller(),
 	}
 
-	driverStr, err := conf.FieldString("driver")
-	if err != nil {
-		return nil, err
-	}
-
-	dsnStr, err := conf.FieldString("dsn")
+	dbConf, err := dbConfigFromParsed(conf, mgr)
 	if err != nil {
 		return nil, err
 	}
+	driverStr := dbConf.driver
 
 	tableStr, err := conf.FieldString("table")
 	if err != nil {
@@ -166,21 +162,16 @@ func NewSQLSelectProcessorFromConfig(conf *service.ParsedConfig, mgr *service.Re
 		s.builder = s.builder.Suffix(suffixStr)
 	}
 
-	connSettings, err := connSettingsFromParsed(conf, mgr)
-	if err != nil {
-		return nil, err
-	}
-
-	if s.db, err = sqlOpenWithReworks(mgr.Logger(), driverStr, dsnStr); err != nil {
+	var closeDB func() error
+	if s.db, closeDB, err = dbConf.open(context.Background(), s.logger); err != nil {
 		return nil, err
 	}
-	connSettings.apply(context.Background(), s.db, s.logger)
 
 	go func() {
 		<-s.shutSig.CloseNowChan()
 
 		s.dbMut.Lock()
-		_ = s.db.Close()
+		_ = closeDB()
 		s.dbMut.Unlock()
 
 		s.shutSig.ShutdownComplete()
@@ -0,0 +1,98 @@
+package sql
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+// stmtCache is a cache of prepared statements keyed by their query. Once the
+// cache exceeds its size the least recently used statement is evicted, and is
+// closed once all callers using it have released it.
+type stmtCache struct {
+	db   *sql.DB
+	size int
+
+	mut   sync.Mutex
+	stmts map[string]*list.Element
+	order *list.List
+}
+
+func newStmtCache(db *sql.DB, size int) *stmtCache {
+	return &stmtCache{
+		db:    db,
+		size:  size,
+		stmts: map[string]*list.Element{},
+		order: list.New(),
+	}
+}
+
+// get returns a prepared statement for a query, preparing it if it isn't
+// already cached, along with a func that must be called once the caller has
+// finished with the statement.
+func (c *stmtCache) get(ctx context.Context, query string) (*sql.Stmt, func(), error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if e, exists := c.stmts[query]; exists {
+		c.order.MoveToFront(e)
+		s := e.Value.(*cachedStmt)
+		s.refs++
+		return s.stmt, func() { c.release(s) }, nil
+	}
+
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	c.stmts[query] = c.order.PushFront(s)
+
+	for c.order.Len() > c.size {
+		e := c.order.Back()
+		c.order.Remove(e)
+
+		old := e.Value.(*cachedStmt)
+		delete(c.stmts, old.query)
+		c.evictLocked(old)
+	}
+	return stmt, func() { c.release(s) }, nil
+}
+
+func (c *stmtCache) evictLocked(s *cachedStmt) {
+	s.evicted = true
+	if s.refs == 0 {
+		_ = s.stmt.Close()
+	}
+}
+
+func (c *stmtCache) release(s *cachedStmt) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	s.refs--
+	if s.evicted && s.refs == 0 {
+		_ = s.stmt.Close()
+	}
+}
+
+// close evicts all statements from the cache.
+func (c *stmtCache) close() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	for _, e := range c.stmts {
+		c.evictLocked(e.Value.(*cachedStmt))
+	}
+	c.stmts = map[string]*list.Element{}
+	c.order.Init()
+}
@@ -2,6 +2,12 @@ package sql
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 func sqlRowsToArray(rows *sql.Rows) ([]any, error) {
@@ -78,3 +84,130 @@ func sqlRowToMap(rows *sql.Rows) (map[string]any, error) {
 	}
 	return jObj, nil
 }
+
+// sqlErrorMeta attempts to extract the driver specific error code and SQL state
+// from an error returned by a query, and returns them as a structured value
+// suitable for metadata. Drivers are not imported directly, instead the error
+// chain is inspected for the methods and fields used by the supported drivers.
+// Returns nil if neither could be found.
+func sqlErrorMeta(err error) map[string]any {
+	for ; err != nil; err = errors.Unwrap(err) {
+		meta := map[string]any{}
+
+		switch t := err.(type) {
+		case interface{ SQLErrorNumber() int32 }: // mssql
+			meta["code"] = int64(t.SQLErrorNumber())
+		case interface{ Code() int }: // sqlite
+			meta["code"] = int64(t.Code())
+		}
+		if t, ok := err.(interface{ SQLState() string }); ok { // postgres
+			if state := t.SQLState(); state != "" {
+				meta["state"] = state
+			}
+		}
+
+		if v := reflect.Indirect(reflect.ValueOf(err)); v.Kind() == reflect.Struct {
+			if _, exists := meta["code"]; !exists {
+				for _, name := range []string{"Code", "Number", "ErrCode"} {
+					if code, ok := sqlErrorFieldValue(v.FieldByName(name)); ok {
+						meta["code"] = code
+						break
+					}
+				}
+			}
+			if _, exists := meta["state"]; !exists {
+				if state, ok := sqlErrorFieldValue(v.FieldByName("SQLState")); ok {
+					meta["state"] = state
+				}
+			}
+		}
+
+		if len(meta) > 0 {
+			return meta
+		}
+	}
+	return nil
+}
+
+func sqlErrorFieldValue(f reflect.Value) (any, bool) {
+	if !f.IsValid() {
+		return nil, false
+	}
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(f.Uint()), true
+	case reflect.String:
+		if s := f.String(); s != "" {
+			return s, true
+		}
+	case reflect.Array:
+		if f.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, f.Len())
+			for i := range b {
+				b[i] = byte(f.Index(i).Uint())
+			}
+			if s := strings.TrimRight(string(b), "\x00"); s != "" {
+				return s, true
+			}
+		}
+	}
+	return nil, false
+}
+
+var (
+	valuesTupleRegexp = regexp.MustCompile(`(?i)\bVALUES\s*\(`)
+	placeholderRegexp = regexp.MustCompile(`(\$|:|@p)(\d+)`)
+)
+
+// expandValuesTuple expands the final VALUES tuple of an insert query so that
+// it is repeated for the given number of rows. Numbered placeholders within
+// the tuple (e.g. $1, :1 or @p1) are offset for each repetition by the number
+// of arguments per row.
+func expandValuesTuple(query string, rows, argsPerRow int) (string, error) {
+	matches := valuesTupleRegexp.FindAllStringIndex(query, -1)
+	if len(matches) == 0 {
+		return "", errors.New("query does not contain a VALUES clause")
+	}
+	start := matches[len(matches)-1][1] - 1
+
+	end, depth, quoted := -1, 0, false
+	for i := start; i < len(query) && end == -1; i++ {
+		switch query[i] {
+		case '\'':
+			quoted = !quoted
+		case '(':
+			if !quoted {
+				depth++
+			}
+		case ')':
+			if !quoted {
+				if depth--; depth == 0 {
+					end = i + 1
+				}
+			}
+		}
+	}
+	if end == -1 {
+		return "", errors.New("query contains an unterminated VALUES tuple")
+	}
+
+	tuple := query[start:end]
+
+	var buf strings.Builder
+	_, _ = buf.WriteString(query[:start])
+	for r := 0; r < rows; r++ {
+		if r > 0 {
+			_, _ = buf.WriteString(", ")
+		}
+		offset := r * argsPerRow
+		_, _ = buf.WriteString(placeholderRegexp.ReplaceAllStringFunc(tuple, func(p string) string {
+			sub := placeholderRegexp.FindStringSubmatch(p)
+			n, _ := strconv.Atoi(sub[2])
+			return fmt.Sprintf("%v%v", sub[1], n+offset)
+		}))
+	}
+	_, _ = buf.WriteString(query[end:])
+	return buf.String(), nil
+}
@@ -0,0 +1,45 @@
+package logtail
+
+import (
+	"time"
+)
+
+// Cursor tracks the position of a log stream by the timestamp of the last line
+// consumed and the number of lines consumed with that timestamp, which allows
+// a stream to be resumed without repeating lines.
+type Cursor struct {
+	last       time.Time
+	seenAtLast int
+	skip       int
+}
+
+// Last returns the timestamp of the last line consumed, or the time that the
+// stream started from when no lines have been consumed.
+func (c *Cursor) Last() time.Time {
+	return c.last
+}
+
+// Resume prepares the cursor for a new stream starting at the last timestamp.
+func (c *Cursor) Resume() {
+	c.skip = c.seenAtLast
+}
+
+// Accept returns whether a line with a timestamp has not yet been consumed,
+// and advances the cursor when it has not.
+func (c *Cursor) Accept(ts time.Time) bool {
+	if ts.Before(c.last) {
+		return false
+	}
+	if ts.Equal(c.last) {
+		if c.skip > 0 {
+			c.skip--
+			return false
+		}
+		c.seenAtLast++
+		return true
+	}
+	c.last = ts
+	c.seenAtLast = 1
+	c.skip = 0
+	return true
+}
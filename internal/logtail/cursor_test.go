@@ -0,0 +1,29 @@
+package logtail
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	base := time.Now()
+
+	var c Cursor
+	assert.True(t, c.Accept(base))
+	assert.True(t, c.Accept(base.Add(time.Millisecond)))
+	assert.True(t, c.Accept(base.Add(time.Millisecond)))
+
+	c.Resume()
+	assert.False(t, c.Accept(base))
+	assert.False(t, c.Accept(base.Add(time.Millisecond)))
+	assert.False(t, c.Accept(base.Add(time.Millisecond)))
+	assert.True(t, c.Accept(base.Add(time.Millisecond)))
+	assert.True(t, c.Accept(base.Add(time.Second)))
+
+	c.Resume()
+	assert.False(t, c.Accept(base.Add(time.Second)))
+	assert.True(t, c.Accept(base.Add(time.Second)))
+	assert.Equal(t, base.Add(time.Second), c.Last())
+}
@@ -0,0 +1,4 @@
+// Package logtail implements the plumbing shared by inputs that follow the
+// logs of containers, such as tracking the position of each log stream so that
+// interrupted streams can be resumed without repeating lines.
+package logtail
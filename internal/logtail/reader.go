@@ -0,0 +1,168 @@
+package logtail
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+// stream is the state of a log stream, which outlives the stream itself in
+// order to resume from the same position when it is followed again.
+type stream struct {
+	cursor    Cursor
+	following bool
+}
+
+// Reader implements the lifecycle of an input that follows any number of log
+// streams in the background, where lines are consumed as messages with the
+// Read method of the input.
+type Reader struct {
+	streamsMut sync.Mutex
+	streams    map[string]*stream
+
+	connMut sync.Mutex
+	msgChan chan *service.Message
+	wg      sync.WaitGroup
+	shutSig *shutdown.Signaller
+}
+
+// NewReader returns a reader that is not yet connected.
+func NewReader() *Reader {
+	return &Reader{
+		streams: map[string]*stream{},
+		shutSig: shutdown.NewSignaller(),
+	}
+}
+
+// Connect calls prepare unless the reader is already connected, and then runs
+// the watch func it returns in the background until the reader is closed. The
+// watch func is expected to discover log streams and follow them.
+func (r *Reader) Connect(ctx context.Context, prepare func(ctx context.Context) (watch func(ctx context.Context), err error)) error {
+	r.connMut.Lock()
+	defer r.connMut.Unlock()
+	if r.msgChan != nil {
+		return nil
+	}
+
+	watch, err := prepare(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.msgChan = make(chan *service.Message)
+	go func() {
+		ctx, done := r.shutSig.CloseAtLeisureCtx(context.Background())
+		defer func() {
+			done()
+			r.wg.Wait()
+			r.shutSig.ShutdownComplete()
+		}()
+		watch(ctx)
+	}()
+	return nil
+}
+
+// Follow runs fn in the background with the cursor of the log stream of a key,
+// unless the stream is already being followed, until fn returns. The cursor of
+// a stream that has not been followed before starts from a given time.
+func (r *Reader) Follow(ctx context.Context, key string, from time.Time, fn func(ctx context.Context, cursor *Cursor)) {
+	r.streamsMut.Lock()
+	s, exists := r.streams[key]
+	if !exists {
+		s = &stream{}
+		s.cursor.last = from
+		r.streams[key] = s
+	}
+	if s.following {
+		r.streamsMut.Unlock()
+		return
+	}
+	s.following = true
+	r.streamsMut.Unlock()
+
+	r.wg.Add(1)
+	go func() {
+		defer func() {
+			r.streamsMut.Lock()
+			s.following = false
+			r.streamsMut.Unlock()
+			r.wg.Done()
+		}()
+		fn(ctx, &s.cursor)
+	}()
+}
+
+// Forget removes the state of the log stream of a key, which should be called
+// once the source of the stream no longer exists.
+func (r *Reader) Forget(key string) {
+	r.streamsMut.Lock()
+	delete(r.streams, key)
+	r.streamsMut.Unlock()
+}
+
+// ForgetPrefix removes the state of all log streams with keys that have a
+// given prefix.
+func (r *Reader) ForgetPrefix(prefix string) {
+	r.streamsMut.Lock()
+	defer r.streamsMut.Unlock()
+	for k := range r.streams {
+		if strings.HasPrefix(k, prefix) {
+			delete(r.streams, k)
+		}
+	}
+}
+
+// Send delivers a message to the Read method, and returns an error if the
+// context is cancelled first.
+func (r *Reader) Send(ctx context.Context, msg *service.Message) error {
+	r.connMut.Lock()
+	msgChan := r.msgChan
+	r.connMut.Unlock()
+
+	select {
+	case msgChan <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Read returns the next line consumed from any of the log streams.
+func (r *Reader) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	r.connMut.Lock()
+	msgChan := r.msgChan
+	r.connMut.Unlock()
+	if msgChan == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	select {
+	case msg := <-msgChan:
+		return msg, func(context.Context, error) error { return nil }, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+// Close stops following all log streams and waits for them to finish.
+func (r *Reader) Close(ctx context.Context) error {
+	r.connMut.Lock()
+	if r.msgChan == nil {
+		// Indicates that we were never connected, so indicate shutdown is
+		// complete.
+		r.shutSig.ShutdownComplete()
+	}
+	r.connMut.Unlock()
+
+	r.shutSig.CloseAtLeisure()
+	select {
+	case <-r.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
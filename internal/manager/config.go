@@ -1,6 +1,10 @@
 package manager
 
 import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+
 	"github.com/benthosdev/benthos/v4/internal/component/cache"
 	"github.com/benthosdev/benthos/v4/internal/component/input"
 	"github.com/benthosdev/benthos/v4/internal/component/output"
@@ -11,21 +15,29 @@ import (
 // ResourceConfig contains fields for specifying resource components at the root
 // of a Benthos config.
 type ResourceConfig struct {
-	ResourceInputs     []input.Config     `json:"input_resources,omitempty" yaml:"input_resources,omitempty"`
-	ResourceProcessors []processor.Config `json:"processor_resources,omitempty" yaml:"processor_resources,omitempty"`
-	ResourceOutputs    []output.Config    `json:"output_resources,omitempty" yaml:"output_resources,omitempty"`
-	ResourceCaches     []cache.Config     `json:"cache_resources,omitempty" yaml:"cache_resources,omitempty"`
-	ResourceRateLimits []ratelimit.Config `json:"rate_limit_resources,omitempty" yaml:"rate_limit_resources,omitempty"`
+	ResourceInputs       []input.Config      `json:"input_resources,omitempty" yaml:"input_resources,omitempty"`
+	ResourceProcessors   []processor.Config  `json:"processor_resources,omitempty" yaml:"processor_resources,omitempty"`
+	ResourceOutputs      []output.Config     `json:"output_resources,omitempty" yaml:"output_resources,omitempty"`
+	ResourceCaches       []cache.Config      `json:"cache_resources,omitempty" yaml:"cache_resources,omitempty"`
+	ResourceRateLimits   []ratelimit.Config  `json:"rate_limit_resources,omitempty" yaml:"rate_limit_resources,omitempty"`
+	ResourceHTTPClients  []RawResourceConfig `json:"http_client_resources,omitempty" yaml:"http_client_resources,omitempty"`
+	ResourceSQLDBs       []RawResourceConfig `json:"sql_resources,omitempty" yaml:"sql_resources,omitempty"`
+	ResourceLookupTables []RawResourceConfig `json:"lookup_table_resources,omitempty" yaml:"lookup_table_resources,omitempty"`
+	ResourceCounters     []RawResourceConfig `json:"counter_resources,omitempty" yaml:"counter_resources,omitempty"`
 }
 
 // NewResourceConfig creates a ResourceConfig with default values.
 func NewResourceConfig() ResourceConfig {
 	return ResourceConfig{
-		ResourceInputs:     []input.Config{},
-		ResourceProcessors: []processor.Config{},
-		ResourceOutputs:    []output.Config{},
-		ResourceCaches:     []cache.Config{},
-		ResourceRateLimits: []ratelimit.Config{},
+		ResourceInputs:       []input.Config{},
+		ResourceProcessors:   []processor.Config{},
+		ResourceOutputs:      []output.Config{},
+		ResourceCaches:       []cache.Config{},
+		ResourceRateLimits:   []ratelimit.Config{},
+		ResourceHTTPClients:  []RawResourceConfig{},
+		ResourceSQLDBs:       []RawResourceConfig{},
+		ResourceLookupTables: []RawResourceConfig{},
+		ResourceCounters:     []RawResourceConfig{},
 	}
 }
 
@@ -37,5 +49,47 @@ func (r *ResourceConfig) AddFrom(extra *ResourceConfig) error {
 	r.ResourceOutputs = append(r.ResourceOutputs, extra.ResourceOutputs...)
 	r.ResourceCaches = append(r.ResourceCaches, extra.ResourceCaches...)
 	r.ResourceRateLimits = append(r.ResourceRateLimits, extra.ResourceRateLimits...)
+	r.ResourceHTTPClients = append(r.ResourceHTTPClients, extra.ResourceHTTPClients...)
+	r.ResourceSQLDBs = append(r.ResourceSQLDBs, extra.ResourceSQLDBs...)
+	r.ResourceLookupTables = append(r.ResourceLookupTables, extra.ResourceLookupTables...)
+	r.ResourceCounters = append(r.ResourceCounters, extra.ResourceCounters...)
 	return nil
 }
+
+// RawResourceConfig is the config of a resource such as an http_client, sql,
+// lookup_table or counter resource. The fields of the resource are kept raw as
+// they are parsed by the implementation of the resource when it is first
+// accessed.
+type RawResourceConfig struct {
+	Label  string         `json:"label" yaml:"label"`
+	Fields map[string]any `json:"-" yaml:"-"`
+}
+
+// UnmarshalYAML extracts the label of a resource and stores the remaining
+// fields raw.
+func (c *RawResourceConfig) UnmarshalYAML(value *yaml.Node) error {
+	fields := map[string]any{}
+	if err := value.Decode(&fields); err != nil {
+		return err
+	}
+	c.Label, _ = fields["label"].(string)
+	delete(fields, "label")
+	c.Fields = fields
+	return nil
+}
+
+// MarshalYAML returns the label and raw fields of a resource.
+func (c RawResourceConfig) MarshalYAML() (any, error) {
+	m := make(map[string]any, len(c.Fields)+1)
+	for k, v := range c.Fields {
+		m[k] = v
+	}
+	m["label"] = c.Label
+	return m, nil
+}
+
+// MarshalJSON returns the label and raw fields of a resource.
+func (c RawResourceConfig) MarshalJSON() ([]byte, error) {
+	m, _ := c.MarshalYAML()
+	return json.Marshal(m)
+}
@@ -0,0 +1,68 @@
+package manager
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+)
+
+type connStatusRegistry struct {
+	mut      sync.Mutex
+	nextID   int
+	trackers map[int]*component.ConnectionStatusTracker
+}
+
+func newConnStatusRegistry() *connStatusRegistry {
+	return &connStatusRegistry{
+		trackers: map[int]*component.ConnectionStatusTracker{},
+	}
+}
+
+func (r *connStatusRegistry) register(c *component.ConnectionStatusTracker) func() {
+	r.mut.Lock()
+	id := r.nextID
+	r.nextID++
+	r.trackers[id] = c
+	r.mut.Unlock()
+
+	return func() {
+		r.mut.Lock()
+		delete(r.trackers, id)
+		r.mut.Unlock()
+	}
+}
+
+func (r *connStatusRegistry) statuses() []component.ConnectionStatus {
+	r.mut.Lock()
+	ids := make([]int, 0, len(r.trackers))
+	for id := range r.trackers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	trackers := make([]*component.ConnectionStatusTracker, 0, len(ids))
+	for _, id := range ids {
+		trackers = append(trackers, r.trackers[id])
+	}
+	r.mut.Unlock()
+
+	statuses := make([]component.ConnectionStatus, 0, len(trackers))
+	for _, c := range trackers {
+		statuses = append(statuses, c.Status())
+	}
+	return statuses
+}
+
+// RegisterConnectionStatus adds a connection status tracker of an input or
+// output to the manager, which is then included in the results of
+// ConnectionStatuses.
+func (t *Type) RegisterConnectionStatus(c *component.ConnectionStatusTracker) func() {
+	return t.connStatuses.register(c)
+}
+
+// ConnectionStatuses returns the current connection status of each input and
+// output created by the manager (or a stream variant of it), in the order in
+// which they were created.
+func (t *Type) ConnectionStatuses() []component.ConnectionStatus {
+	return t.connStatuses.statuses()
+}
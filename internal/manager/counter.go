@@ -0,0 +1,159 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+)
+
+// Counter is the store of a counter resource, which holds a number of named
+// integer counters.
+type Counter interface {
+	// Incr adds a delta to the counter of a key, where a counter that does not
+	// exist is treated as zero, and returns the resulting value.
+	Incr(ctx context.Context, key string, delta int64) (int64, error)
+
+	// Read returns the value of the counter of a key, or zero if it does not
+	// exist.
+	Read(ctx context.Context, key string) (int64, error)
+}
+
+// CounterConstructor creates the store of a counter resource from its raw
+// config, which is registered by the counter implementation as it cannot be
+// imported here.
+var CounterConstructor bundle.LazyResourceConstructor
+
+var counterIncrFunctionSpec = query.NewFunctionSpec(
+	query.FunctionCategoryEnvironment, "counter_incr",
+	"Increments a counter of a [counter resource](/docs/configuration/resources#counter-resources) and returns the resulting value. Counters that do not yet exist start at zero.",
+	query.NewNotTestedExampleSpec("Counters can be used to number the messages of a stream.",
+		`root = this
+root.seq = counter_incr("sequence")`,
+	),
+	query.NewNotTestedExampleSpec("A key can be provided in order to count separately, here we count the requests of each user and reject users that exceed a quota.",
+		`root = if counter_incr("quotas", this.user_id) > 1000 {
+  throw("user %v has exceeded their quota".format(this.user_id))
+}`,
+	),
+).Beta().AtVersion("4.24.0").MarkImpure().
+	Param(query.ParamString("counter", "The label of the counter resource.").DisableDynamic()).
+	Param(query.ParamString("key", "The key of the counter to increment.").Default("")).
+	Param(query.ParamInt64("by", "The amount to increment the counter by.").Default(1))
+
+var counterDecrFunctionSpec = query.NewFunctionSpec(
+	query.FunctionCategoryEnvironment, "counter_decr",
+	"Decrements a counter of a [counter resource](/docs/configuration/resources#counter-resources) and returns the resulting value. Counters that do not yet exist start at zero.",
+	query.NewNotTestedExampleSpec("",
+		`root.in_flight = counter_decr("jobs", this.queue)`,
+	),
+).Beta().AtVersion("4.24.0").MarkImpure().
+	Param(query.ParamString("counter", "The label of the counter resource.").DisableDynamic()).
+	Param(query.ParamString("key", "The key of the counter to decrement.").Default("")).
+	Param(query.ParamInt64("by", "The amount to decrement the counter by.").Default(1))
+
+var counterReadFunctionSpec = query.NewFunctionSpec(
+	query.FunctionCategoryEnvironment, "counter_read",
+	"Returns the value of a counter of a [counter resource](/docs/configuration/resources#counter-resources) without modifying it, or zero if the counter does not exist.",
+	query.NewNotTestedExampleSpec("",
+		`root.requests_so_far = counter_read("quotas", this.user_id)`,
+	),
+).Beta().AtVersion("4.24.0").MarkImpure().
+	Param(query.ParamString("counter", "The label of the counter resource.").DisableDynamic()).
+	Param(query.ParamString("key", "The key of the counter to read.").Default(""))
+
+func init() {
+	// Mappings that are parsed outside of a manager, such as when linting, do
+	// not have access to counters and therefore fail when executed.
+	for _, spec := range []query.FunctionSpec{counterIncrFunctionSpec, counterDecrFunctionSpec, counterReadFunctionSpec} {
+		fnName := spec.Name
+		if err := bloblang.GlobalEnvironment().RegisterFunction(spec, func(args *query.ParsedParams) (query.Function, error) {
+			name, err := args.FieldString("counter")
+			if err != nil {
+				return nil, err
+			}
+			return query.ClosureFunction("function "+fnName, func(ctx query.FunctionContext) (any, error) {
+				return nil, fmt.Errorf("counter resource '%v' was not found", name)
+			}, nil), nil
+		}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ProbeCounter returns true if a counter resource exists under the provided
+// name.
+func (t *Type) ProbeCounter(name string) bool {
+	_, exists := t.counters[name]
+	return exists
+}
+
+// GetCounter returns the store of a counter resource, which is created the
+// first time it is accessed.
+func (t *Type) GetCounter(name string) (Counter, error) {
+	l, exists := t.counters[name]
+	if !exists {
+		return nil, ErrResourceNotFound(name)
+	}
+	if CounterConstructor == nil {
+		return nil, errors.New("counters are not supported by this build")
+	}
+	r, err := l.get(func(conf map[string]any) (any, error) {
+		return CounterConstructor(conf, t.intoPath("counter_resources").forLabel(name))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init counter resource '%v': %w", name, err)
+	}
+	c, ok := r.(Counter)
+	if !ok {
+		return nil, fmt.Errorf("counter resource '%v' is of an unexpected type: %T", name, r)
+	}
+	return c, nil
+}
+
+// counterFunctionCtor returns a constructor for a counter function of mappings
+// parsed by components of the manager, where the counter is incremented by the
+// by parameter multiplied by sign, or read when sign is zero.
+func (t *Type) counterFunctionCtor(fnName string, sign int64) query.FunctionCtor {
+	return func(args *query.ParsedParams) (query.Function, error) {
+		name, err := args.FieldString("counter")
+		if err != nil {
+			return nil, err
+		}
+		key, err := args.FieldString("key")
+		if err != nil {
+			return nil, err
+		}
+		var by int64
+		if sign != 0 {
+			if by, err = args.FieldInt64("by"); err != nil {
+				return nil, err
+			}
+		}
+
+		c, err := t.GetCounter(name)
+		if err != nil {
+			return nil, err
+		}
+		return query.ClosureFunction("function "+fnName, func(ctx query.FunctionContext) (any, error) {
+			if sign == 0 {
+				return c.Read(context.Background(), key)
+			}
+			return c.Incr(context.Background(), key, sign*by)
+		}, nil), nil
+	}
+}
+
+func (t *Type) registerCounterFunctions() error {
+	t.bloblEnv = t.bloblEnv.WithoutFunctions(counterIncrFunctionSpec.Name, counterDecrFunctionSpec.Name, counterReadFunctionSpec.Name)
+	if err := t.bloblEnv.RegisterFunction(counterIncrFunctionSpec, t.counterFunctionCtor(counterIncrFunctionSpec.Name, 1)); err != nil {
+		return err
+	}
+	if err := t.bloblEnv.RegisterFunction(counterDecrFunctionSpec, t.counterFunctionCtor(counterDecrFunctionSpec.Name, -1)); err != nil {
+		return err
+	}
+	return t.bloblEnv.RegisterFunction(counterReadFunctionSpec, t.counterFunctionCtor(counterReadFunctionSpec.Name, 0))
+}
@@ -22,6 +22,23 @@ func lintResource(ctx docs.LintContext, line, col int, v any) []docs.Lint {
 	return nil
 }
 
+// HTTPClientResourceFields are the fields of an http_client resource, which are
+// registered by the HTTP client implementation as it cannot be imported here.
+var HTTPClientResourceFields docs.FieldSpecs
+
+// SQLResourceFields are the fields of an sql resource, which are registered by
+// the SQL implementation as it cannot be imported here.
+var SQLResourceFields docs.FieldSpecs
+
+// LookupTableResourceFields are the fields of a lookup_table resource, which
+// are registered by the lookup table implementation as it cannot be imported
+// here.
+var LookupTableResourceFields docs.FieldSpecs
+
+// CounterResourceFields are the fields of a counter resource, which are
+// registered by the counter implementation as it cannot be imported here.
+var CounterResourceFields docs.FieldSpecs
+
 // Spec returns a field spec for the manager configuration.
 func Spec() docs.FieldSpecs {
 	return docs.FieldSpecs{
@@ -44,5 +61,21 @@ func Spec() docs.FieldSpecs {
 		docs.FieldRateLimit(
 			"rate_limit_resources", "A list of rate limit resources, each must have a unique label.",
 		).Array().LinterFunc(lintResource).HasDefault([]any{}),
+
+		docs.FieldObject(
+			"http_client_resources", "A list of HTTP client resources, each must have a unique label. HTTP client resources can be referenced by HTTP components in order to share connection pools, authentication and rate limits.",
+		).WithChildren(HTTPClientResourceFields...).Array().LinterFunc(lintResource).HasDefault([]any{}).AtVersion("4.24.0"),
+
+		docs.FieldObject(
+			"sql_resources", "A list of SQL resources, each must have a unique label. SQL resources can be referenced by SQL components in order to share a pool of database connections.",
+		).WithChildren(SQLResourceFields...).Array().LinterFunc(lintResource).HasDefault([]any{}).AtVersion("4.24.0"),
+
+		docs.FieldObject(
+			"lookup_table_resources", "A list of lookup table resources, each must have a unique label. Lookup tables are loaded into memory from an input and can be queried from Bloblang mappings with the `lookup` function.",
+		).WithChildren(LookupTableResourceFields...).Array().LinterFunc(lintResource).HasDefault([]any{}).AtVersion("4.24.0"),
+
+		docs.FieldObject(
+			"counter_resources", "A list of counter resources, each must have a unique label. Counters can be incremented, decremented and read from Bloblang mappings with the `counter_incr`, `counter_decr` and `counter_read` functions.",
+		).WithChildren(CounterResourceFields...).Array().LinterFunc(lintResource).HasDefault([]any{}).AtVersion("4.24.0"),
 	}
 }
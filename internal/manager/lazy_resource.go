@@ -0,0 +1,45 @@
+package manager
+
+import (
+	"context"
+	"sync"
+)
+
+// lazyResource holds the raw config of a resource along with the resource
+// constructed from it once it has been accessed.
+type lazyResource struct {
+	conf map[string]any
+
+	mut sync.Mutex
+	res any
+}
+
+func (l *lazyResource) get(ctor func(conf map[string]any) (any, error)) (any, error) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if l.res != nil {
+		return l.res, nil
+	}
+
+	r, err := ctor(l.conf)
+	if err != nil {
+		return nil, err
+	}
+	l.res = r
+	return r, nil
+}
+
+func (l *lazyResource) close(ctx context.Context) error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	closer, ok := l.res.(interface {
+		Close(ctx context.Context) error
+	})
+	l.res = nil
+	if !ok {
+		return nil
+	}
+	return closer.Close(ctx)
+}
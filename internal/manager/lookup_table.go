@@ -0,0 +1,110 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+)
+
+// LookupTable is the in-memory table of a lookup_table resource.
+type LookupTable interface {
+	// Lookup returns the value stored under a key, and whether it exists.
+	Lookup(key string) (any, bool)
+}
+
+// LookupTableConstructor creates the table of a lookup_table resource from its
+// raw config, which is registered by the lookup table implementation as it
+// cannot be imported here.
+var LookupTableConstructor bundle.LazyResourceConstructor
+
+var lookupFunctionSpec = query.NewFunctionSpec(
+	query.FunctionCategoryEnvironment, "lookup",
+	"Returns the value stored under a key of a [lookup table resource](/docs/configuration/resources#lookup-table-resources), or `null` if the key does not exist. Lookup tables are held in memory and therefore lookups do not incur any network or disk latency.",
+	query.NewNotTestedExampleSpec("",
+		`root = this
+root.user = lookup("users", this.user_id)`,
+		`{"user_id":"u1"}`,
+		`{"user":{"id":"u1","name":"Alice"},"user_id":"u1"}`,
+	),
+	query.NewNotTestedExampleSpec("The `or` method can be used in order to provide a fallback for keys that do not exist.",
+		`root.country = lookup("countries", this.country_code).or("unknown")`,
+	),
+).Beta().AtVersion("4.24.0").MarkImpure().
+	Param(query.ParamString("table", "The label of the lookup table resource.").DisableDynamic()).
+	Param(query.ParamQuery("key", "The key to look up, which is converted to a string.", true))
+
+func init() {
+	// Mappings that are parsed outside of a manager, such as when linting, do
+	// not have access to lookup tables and therefore fail when executed.
+	if err := bloblang.GlobalEnvironment().RegisterFunction(lookupFunctionSpec, func(args *query.ParsedParams) (query.Function, error) {
+		name, err := args.FieldString("table")
+		if err != nil {
+			return nil, err
+		}
+		return query.ClosureFunction("function lookup", func(ctx query.FunctionContext) (any, error) {
+			return nil, fmt.Errorf("lookup table resource '%v' was not found", name)
+		}, nil), nil
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// ProbeLookupTable returns true if a lookup table resource exists under the
+// provided name.
+func (t *Type) ProbeLookupTable(name string) bool {
+	_, exists := t.lookupTables[name]
+	return exists
+}
+
+// GetLookupTable returns the table of a lookup table resource, which is loaded
+// the first time it is accessed.
+func (t *Type) GetLookupTable(name string) (LookupTable, error) {
+	l, exists := t.lookupTables[name]
+	if !exists {
+		return nil, ErrResourceNotFound(name)
+	}
+	if LookupTableConstructor == nil {
+		return nil, errors.New("lookup tables are not supported by this build")
+	}
+	r, err := l.get(func(conf map[string]any) (any, error) {
+		return LookupTableConstructor(conf, t.intoPath("lookup_table_resources").forLabel(name))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init lookup table resource '%v': %w", name, err)
+	}
+	table, ok := r.(LookupTable)
+	if !ok {
+		return nil, fmt.Errorf("lookup table resource '%v' is of an unexpected type: %T", name, r)
+	}
+	return table, nil
+}
+
+func (t *Type) lookupFunctionCtor(args *query.ParsedParams) (query.Function, error) {
+	name, err := args.FieldString("table")
+	if err != nil {
+		return nil, err
+	}
+	key, err := args.FieldQuery("key")
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := t.GetLookupTable(name)
+	if err != nil {
+		return nil, err
+	}
+	return query.ClosureFunction("function lookup", func(ctx query.FunctionContext) (any, error) {
+		k, err := key.Exec(ctx)
+		if err != nil {
+			return nil, err
+		}
+		v, exists := table.Lookup(query.IToString(k))
+		if !exists {
+			return nil, nil
+		}
+		return query.IClone(v), nil
+	}, key.QueryTargets), nil
+}
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/component"
@@ -66,6 +67,40 @@ func (c *Cache) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// Incr increments the integer value of a mock cache item.
+func (c *Cache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	var v int64
+	if i, ok := c.Values[key]; ok {
+		var err error
+		if v, err = strconv.ParseInt(i.Value, 10, 64); err != nil {
+			return 0, err
+		}
+	}
+	v += delta
+	c.Values[key] = CacheItem{
+		Value: strconv.FormatInt(v, 10),
+		TTL:   ttl,
+	}
+	return v, nil
+}
+
+// CompareAndSwap sets or deletes a mock cache item when its current value
+// matches.
+func (c *Cache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) (bool, error) {
+	if i, ok := c.Values[key]; !ok || i.Value != string(old) {
+		return false, nil
+	}
+	if value == nil {
+		delete(c.Values, key)
+		return true, nil
+	}
+	c.Values[key] = CacheItem{
+		Value: string(value),
+		TTL:   ttl,
+	}
+	return true, nil
+}
+
 // Close does nothing.
 func (c *Cache) Close(ctx context.Context) error {
 	return nil
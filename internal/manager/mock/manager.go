@@ -31,7 +31,14 @@ type Manager struct {
 	Outputs    map[string]OutputWriter
 	Processors map[string]Processor
 	Pipes      map[string]<-chan message.Transaction
-	lock       sync.Mutex
+	// HTTPClients contains http client resources, which are raw configs that
+	// are replaced by their constructed client when first accessed.
+	HTTPClients map[string]any
+	// SQLDBs contains sql resources, which are raw configs that are replaced
+	// by their constructed database when first accessed.
+	SQLDBs map[string]any
+	Topics map[string][]*bundle.TopicSubscriber
+	lock   sync.Mutex
 
 	// OnRegisterEndpoint can be set in order to intercept endpoints registered
 	// by components.
@@ -46,16 +53,19 @@ type Manager struct {
 // NewManager provides a new mock manager.
 func NewManager() *Manager {
 	return &Manager{
-		Inputs:     map[string]*Input{},
-		Caches:     map[string]map[string]CacheItem{},
-		RateLimits: map[string]RateLimit{},
-		Outputs:    map[string]OutputWriter{},
-		Processors: map[string]Processor{},
-		Pipes:      map[string]<-chan message.Transaction{},
-		CustomFS:   ifs.OS(),
-		M:          metrics.Noop(),
-		L:          log.Noop(),
-		T:          trace.NewNoopTracerProvider(),
+		Inputs:      map[string]*Input{},
+		Caches:      map[string]map[string]CacheItem{},
+		RateLimits:  map[string]RateLimit{},
+		Outputs:     map[string]OutputWriter{},
+		Processors:  map[string]Processor{},
+		Pipes:       map[string]<-chan message.Transaction{},
+		HTTPClients: map[string]any{},
+		SQLDBs:      map[string]any{},
+		Topics:      map[string][]*bundle.TopicSubscriber{},
+		CustomFS:    ifs.OS(),
+		M:           metrics.Noop(),
+		L:           log.Noop(),
+		T:           trace.NewNoopTracerProvider(),
 	}
 }
 
@@ -226,6 +236,54 @@ func (m *Manager) RemoveRateLimit(ctx context.Context, name string) error {
 	return nil
 }
 
+// ProbeHTTPClient returns true if an http client resource exists under the
+// provided name.
+func (m *Manager) ProbeHTTPClient(name string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	_, exists := m.HTTPClients[name]
+	return exists
+}
+
+// GetHTTPClient returns an http client resource, constructing it if it is a
+// raw config.
+func (m *Manager) GetHTTPClient(name string, ctor bundle.LazyResourceConstructor) (any, error) {
+	return m.getLazy(m.HTTPClients, name, ctor, component.ErrHTTPClientNotFound)
+}
+
+// ProbeSQLDB returns true if an sql resource exists under the provided name.
+func (m *Manager) ProbeSQLDB(name string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	_, exists := m.SQLDBs[name]
+	return exists
+}
+
+// GetSQLDB returns an sql resource, constructing it if it is a raw config.
+func (m *Manager) GetSQLDB(name string, ctor bundle.LazyResourceConstructor) (any, error) {
+	return m.getLazy(m.SQLDBs, name, ctor, component.ErrSQLDBNotFound)
+}
+
+func (m *Manager) getLazy(resources map[string]any, name string, ctor bundle.LazyResourceConstructor, notFoundErr error) (any, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	r, exists := resources[name]
+	if !exists {
+		return nil, notFoundErr
+	}
+	if conf, isConf := r.(map[string]any); isConf {
+		var err error
+		if r, err = ctor(conf, m); err != nil {
+			return nil, err
+		}
+		resources[name] = r
+	}
+	return r, nil
+}
+
 // ProbeInput returns true if an input resource exists under the provided name.
 func (m *Manager) ProbeInput(name string) bool {
 	m.lock.Lock()
@@ -350,3 +408,24 @@ func (m *Manager) SetPipe(name string, t <-chan message.Transaction) {
 func (m *Manager) UnsetPipe(name string, t <-chan message.Transaction) {
 	delete(m.Pipes, name)
 }
+
+// GetTopicSubscribers returns the subscribers of a named topic.
+func (m *Manager) GetTopicSubscribers(name string) []*bundle.TopicSubscriber {
+	return m.Topics[name]
+}
+
+// SubscribeTopic adds a subscriber to a named topic.
+func (m *Manager) SubscribeTopic(name string, s *bundle.TopicSubscriber) {
+	m.Topics[name] = append(m.Topics[name], s)
+}
+
+// UnsubscribeTopic removes a subscriber from a named topic.
+func (m *Manager) UnsubscribeTopic(name string, s *bundle.TopicSubscriber) {
+	var subs []*bundle.TopicSubscriber
+	for _, existing := range m.Topics[name] {
+		if existing != s {
+			subs = append(subs, existing)
+		}
+	}
+	m.Topics[name] = subs
+}
@@ -0,0 +1,197 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	"github.com/benthosdev/benthos/v4/internal/component/ratelimit"
+	"github.com/benthosdev/benthos/v4/internal/log"
+)
+
+// initResource constructs a resource, making further attempts according to its
+// init config when construction fails.
+func initResource[T any](ctx context.Context, logger log.Modular, initConf *component.ResourceInitConfig, ctor func() (T, error)) (res T, err error) {
+	conf := component.NewResourceInitConfig()
+	if initConf != nil {
+		conf = *initConf
+	}
+
+	var period time.Duration
+	if conf.Retries > 0 {
+		if period, err = time.ParseDuration(conf.RetryPeriod); err != nil {
+			return res, fmt.Errorf("failed to parse init retry_period: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if res, err = ctor(); err == nil || attempt >= conf.Retries {
+			return
+		}
+		logger.Warnf("Failed to initialise resource, retrying in %v (%v of %v): %v", period, attempt+1, conf.Retries, err)
+		select {
+		case <-time.After(period):
+		case <-ctx.Done():
+			return res, ctx.Err()
+		}
+	}
+}
+
+// lazyCtor holds the constructor of a resource until the resource is first
+// used. Failed attempts to construct the resource are repeated on the next use.
+type lazyCtor[T any] struct {
+	ctor func() (T, error)
+
+	mut         sync.Mutex
+	res         T
+	initialised bool
+}
+
+func (l *lazyCtor[T]) get() (T, error) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if l.initialised {
+		return l.res, nil
+	}
+
+	res, err := l.ctor()
+	if err != nil {
+		return res, fmt.Errorf("failed to initialise resource: %w", err)
+	}
+	l.res, l.initialised = res, true
+	return res, nil
+}
+
+// closeWith executes a close function against the resource only if it has been
+// initialised.
+func (l *lazyCtor[T]) closeWith(fn func(T) error) error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if !l.initialised {
+		return nil
+	}
+	return fn(l.res)
+}
+
+//------------------------------------------------------------------------------
+
+type lazyCache struct {
+	l *lazyCtor[cache.V1]
+}
+
+func (c *lazyCache) Get(ctx context.Context, key string) ([]byte, error) {
+	r, err := c.l.get()
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, key)
+}
+
+func (c *lazyCache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
+	r, err := c.l.get()
+	if err != nil {
+		return err
+	}
+	return r.Set(ctx, key, value, ttl)
+}
+
+func (c *lazyCache) SetMulti(ctx context.Context, items map[string]cache.TTLItem) error {
+	r, err := c.l.get()
+	if err != nil {
+		return err
+	}
+	return r.SetMulti(ctx, items)
+}
+
+func (c *lazyCache) Add(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
+	r, err := c.l.get()
+	if err != nil {
+		return err
+	}
+	return r.Add(ctx, key, value, ttl)
+}
+
+func (c *lazyCache) Delete(ctx context.Context, key string) error {
+	r, err := c.l.get()
+	if err != nil {
+		return err
+	}
+	return r.Delete(ctx, key)
+}
+
+func (c *lazyCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	r, err := c.l.get()
+	if err != nil {
+		return 0, err
+	}
+	i, ok := r.(cache.Incrementer)
+	if !ok {
+		return 0, cache.ErrIncrNotSupported
+	}
+	return i.Incr(ctx, key, delta, ttl)
+}
+
+func (c *lazyCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) (bool, error) {
+	r, err := c.l.get()
+	if err != nil {
+		return false, err
+	}
+	cas, ok := r.(cache.CompareAndSwapper)
+	if !ok {
+		return false, cache.ErrCompareAndSwapNotSupported
+	}
+	return cas.CompareAndSwap(ctx, key, old, value, ttl)
+}
+
+func (c *lazyCache) Close(ctx context.Context) error {
+	return c.l.closeWith(func(r cache.V1) error {
+		return r.Close(ctx)
+	})
+}
+
+//------------------------------------------------------------------------------
+
+type lazyRateLimit struct {
+	l *lazyCtor[ratelimit.V1]
+}
+
+func (r *lazyRateLimit) Access(ctx context.Context) (time.Duration, error) {
+	rl, err := r.l.get()
+	if err != nil {
+		return 0, err
+	}
+	return rl.Access(ctx)
+}
+
+func (r *lazyRateLimit) Close(ctx context.Context) error {
+	return r.l.closeWith(func(rl ratelimit.V1) error {
+		return rl.Close(ctx)
+	})
+}
+
+//------------------------------------------------------------------------------
+
+func (t *Type) initCache(ctx context.Context, conf cache.Config) (cache.V1, error) {
+	ctor := func() (cache.V1, error) {
+		return t.NewCache(conf)
+	}
+	if conf.Init != nil && conf.Init.Lazy {
+		return &lazyCache{l: &lazyCtor[cache.V1]{ctor: ctor}}, nil
+	}
+	return initResource(ctx, t.forLabel(conf.Label).logger, conf.Init, ctor)
+}
+
+func (t *Type) initRateLimit(ctx context.Context, conf ratelimit.Config) (ratelimit.V1, error) {
+	ctor := func() (ratelimit.V1, error) {
+		return t.NewRateLimit(conf)
+	}
+	if conf.Init != nil && conf.Init.Lazy {
+		return &lazyRateLimit{l: &lazyCtor[ratelimit.V1]{ctor: ctor}}, nil
+	}
+	return initResource(ctx, t.forLabel(conf.Label).logger, conf.Init, ctor)
+}
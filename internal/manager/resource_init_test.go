@@ -0,0 +1,155 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	"github.com/benthosdev/benthos/v4/internal/component/ratelimit"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+)
+
+// flakyResourcesEnv returns an environment with a cache and rate limit that
+// fail to initialise until the returned counters reach the provided attempts.
+func flakyResourcesEnv(t *testing.T, failures int64) (env *bundle.Environment, cacheAttempts, rlAttempts *int64) {
+	t.Helper()
+
+	cacheAttempts, rlAttempts = new(int64), new(int64)
+
+	env = bundle.NewEnvironment()
+	require.NoError(t, env.CacheAdd(func(c cache.Config, mgr bundle.NewManagement) (cache.V1, error) {
+		if atomic.AddInt64(cacheAttempts, 1) <= failures {
+			return nil, errors.New("cache unreachable")
+		}
+		return &mock.Cache{Values: map[string]mock.CacheItem{"foo": {Value: "bar"}}}, nil
+	}, docs.ComponentSpec{
+		Name: "flakycache",
+	}))
+	require.NoError(t, env.RateLimitAdd(func(c ratelimit.Config, mgr bundle.NewManagement) (ratelimit.V1, error) {
+		if atomic.AddInt64(rlAttempts, 1) <= failures {
+			return nil, errors.New("rate limit unreachable")
+		}
+		return mock.RateLimit(func(context.Context) (time.Duration, error) {
+			return time.Second, nil
+		}), nil
+	}, docs.ComponentSpec{
+		Name: "flakyratelimit",
+	}))
+	return
+}
+
+func TestResourceInitRetries(t *testing.T) {
+	env, cacheAttempts, rlAttempts := flakyResourcesEnv(t, 2)
+
+	conf := NewResourceConfig()
+
+	fooCache := cache.NewConfig()
+	fooCache.Label = "foo"
+	fooCache.Type = "flakycache"
+	fooCache.Init = &component.ResourceInitConfig{Retries: 2, RetryPeriod: "1ms"}
+	conf.ResourceCaches = append(conf.ResourceCaches, fooCache)
+
+	barRL := ratelimit.NewConfig()
+	barRL.Label = "bar"
+	barRL.Type = "flakyratelimit"
+	barRL.Init = &component.ResourceInitConfig{Retries: 3, RetryPeriod: "1ms"}
+	conf.ResourceRateLimits = append(conf.ResourceRateLimits, barRL)
+
+	mgr, err := New(conf, OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), atomic.LoadInt64(cacheAttempts))
+	assert.Equal(t, int64(3), atomic.LoadInt64(rlAttempts))
+
+	require.NoError(t, mgr.AccessCache(context.Background(), "foo", func(c cache.V1) {
+		v, err := c.Get(context.Background(), "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "bar", string(v))
+	}))
+}
+
+func TestResourceInitRetriesExhausted(t *testing.T) {
+	env, cacheAttempts, _ := flakyResourcesEnv(t, 3)
+
+	conf := NewResourceConfig()
+
+	fooCache := cache.NewConfig()
+	fooCache.Label = "foo"
+	fooCache.Type = "flakycache"
+	fooCache.Init = &component.ResourceInitConfig{Retries: 1, RetryPeriod: "1ms"}
+	conf.ResourceCaches = append(conf.ResourceCaches, fooCache)
+
+	_, err := New(conf, OptSetEnvironment(env))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache unreachable")
+	assert.Equal(t, int64(2), atomic.LoadInt64(cacheAttempts))
+}
+
+func TestResourceInitLazy(t *testing.T) {
+	env, cacheAttempts, rlAttempts := flakyResourcesEnv(t, 1)
+
+	conf := NewResourceConfig()
+
+	fooCache := cache.NewConfig()
+	fooCache.Label = "foo"
+	fooCache.Type = "flakycache"
+	fooCache.Init = &component.ResourceInitConfig{Lazy: true, RetryPeriod: "1s"}
+	conf.ResourceCaches = append(conf.ResourceCaches, fooCache)
+
+	barRL := ratelimit.NewConfig()
+	barRL.Label = "bar"
+	barRL.Type = "flakyratelimit"
+	barRL.Init = &component.ResourceInitConfig{Lazy: true, RetryPeriod: "1s"}
+	conf.ResourceRateLimits = append(conf.ResourceRateLimits, barRL)
+
+	mgr, err := New(conf, OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(0), atomic.LoadInt64(cacheAttempts))
+	assert.Equal(t, int64(0), atomic.LoadInt64(rlAttempts))
+
+	getFoo := func() (v []byte, err error) {
+		require.NoError(t, mgr.AccessCache(context.Background(), "foo", func(c cache.V1) {
+			v, err = c.Get(context.Background(), "foo")
+		}))
+		return
+	}
+
+	_, err = getFoo()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache unreachable")
+
+	v, err := getFoo()
+	require.NoError(t, err)
+	assert.Equal(t, "bar", string(v))
+
+	_, _ = getFoo()
+	assert.Equal(t, int64(2), atomic.LoadInt64(cacheAttempts))
+
+	accessBar := func() (d time.Duration, err error) {
+		require.NoError(t, mgr.AccessRateLimit(context.Background(), "bar", func(r ratelimit.V1) {
+			d, err = r.Access(context.Background())
+		}))
+		return
+	}
+
+	_, err = accessBar()
+	require.Error(t, err)
+
+	d, err := accessBar()
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, d)
+	assert.Equal(t, int64(2), atomic.LoadInt64(rlAttempts))
+
+	mgr.TriggerCloseNow()
+	require.NoError(t, mgr.WaitForClose(context.Background()))
+}
@@ -76,6 +76,11 @@ type Type struct {
 	outputs    *liveResources[*outputWrapper]
 	rateLimits *liveResources[ratelimit.V1]
 
+	httpClients  map[string]*lazyResource
+	sqlDBs       map[string]*lazyResource
+	lookupTables map[string]*lazyResource
+	counters     map[string]*lazyResource
+
 	// Collections of component constructors
 	env      *bundle.Environment
 	bloblEnv *bloblang.Environment
@@ -85,7 +90,10 @@ type Type struct {
 	tracer trace.TracerProvider
 
 	pipes    map[string]<-chan message.Transaction
+	topics   map[string][]*bundle.TopicSubscriber
 	pipeLock *sync.RWMutex
+
+	connStatuses *connStatusRegistry
 }
 
 // OptFunc is an opt setting for a manager type.
@@ -157,6 +165,16 @@ func OptSetStreamsMode(b bool) OptFunc {
 	}
 }
 
+// OptSetStream sets a stream identifier for the manager, which prefixes the
+// paths of HTTP endpoints registered by components (when stream HTTP
+// namespacing is enabled) and is added as a label to logs and metrics,
+// including those of resources.
+func OptSetStream(id string) OptFunc {
+	return func(t *Type) {
+		t.stream = id
+	}
+}
+
 // OptSetFS determines which ifs.FS implementation to use for its filesystem.
 // This can be used to override the default os based filesystem implementation.
 func OptSetFS(fs ifs.FS) OptFunc {
@@ -178,6 +196,11 @@ func New(conf ResourceConfig, opts ...OptFunc) (*Type, error) {
 		outputs:    newLiveResources[*outputWrapper](),
 		rateLimits: newLiveResources[ratelimit.V1](),
 
+		httpClients:  map[string]*lazyResource{},
+		sqlDBs:       map[string]*lazyResource{},
+		lookupTables: map[string]*lazyResource{},
+		counters:     map[string]*lazyResource{},
+
 		// Environment defaults to global (everything that was imported).
 		env:      bundle.GlobalEnvironment,
 		bloblEnv: bloblang.GlobalEnvironment(),
@@ -189,13 +212,29 @@ func New(conf ResourceConfig, opts ...OptFunc) (*Type, error) {
 		fs: ifs.OS(),
 
 		pipes:    map[string]<-chan message.Transaction{},
+		topics:   map[string][]*bundle.TopicSubscriber{},
 		pipeLock: &sync.RWMutex{},
+
+		connStatuses: newConnStatusRegistry(),
 	}
 
 	for _, opt := range opts {
 		opt(t)
 	}
 
+	if t.stream != "" {
+		t.logger = t.logger.WithFields(map[string]string{
+			"stream": t.stream,
+		})
+		t.stats = t.stats.WithLabels("stream", t.stream)
+	}
+
+	if metrics.HasComponentTypeLabels(t.stats) {
+		// Ensures that all metrics share a consistent set of labels, metrics
+		// created outside of a component have an empty type.
+		t.stats = t.stats.WithLabels("type", "")
+	}
+
 	seen := map[string]struct{}{}
 
 	checkLabel := func(typeStr, label string) error {
@@ -244,6 +283,49 @@ func New(conf ResourceConfig, opts ...OptFunc) (*Type, error) {
 		}
 		t.rateLimits.Add(c.Label, nil)
 	}
+	for _, c := range conf.ResourceHTTPClients {
+		if err := checkLabel("http client", c.Label); err != nil {
+			return nil, err
+		}
+		// HTTP clients are constructed lazily when first accessed as the
+		// manager does not know how to construct them.
+		t.httpClients[c.Label] = &lazyResource{conf: c.Fields}
+	}
+	for _, c := range conf.ResourceSQLDBs {
+		if err := checkLabel("sql", c.Label); err != nil {
+			return nil, err
+		}
+		t.sqlDBs[c.Label] = &lazyResource{conf: c.Fields}
+	}
+	for _, c := range conf.ResourceLookupTables {
+		if err := checkLabel("lookup table", c.Label); err != nil {
+			return nil, err
+		}
+		t.lookupTables[c.Label] = &lazyResource{conf: c.Fields}
+	}
+
+	if len(t.lookupTables) > 0 {
+		// The lookup function of mappings parsed by components of this manager
+		// accesses the lookup tables of the manager.
+		t.bloblEnv = t.bloblEnv.WithoutFunctions("lookup")
+		if err := t.bloblEnv.RegisterFunction(lookupFunctionSpec, t.lookupFunctionCtor); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, c := range conf.ResourceCounters {
+		if err := checkLabel("counter", c.Label); err != nil {
+			return nil, err
+		}
+		t.counters[c.Label] = &lazyResource{conf: c.Fields}
+	}
+	if len(t.counters) > 0 {
+		// The counter functions of mappings parsed by components of this
+		// manager access the counters of the manager.
+		if err := t.registerCounterFunctions(); err != nil {
+			return nil, err
+		}
+	}
 
 	// Labels validated, begin construction
 	for _, conf := range conf.ResourceRateLimits {
@@ -295,6 +377,7 @@ func (t *Type) forStream(id string) *Type {
 		"stream": id,
 	})
 	newT.stats = t.stats.WithLabels("stream", id)
+	newT.connStatuses = newConnStatusRegistry()
 	return &newT
 }
 
@@ -308,6 +391,14 @@ func (t *Type) forLabel(name string) *Type {
 	return &newT
 }
 
+func (t *Type) forComponent(typeStr, label string) *Type {
+	newT := t.forLabel(label)
+	if metrics.HasComponentTypeLabels(newT.stats) {
+		newT.stats = newT.stats.WithLabels("type", typeStr)
+	}
+	return newT
+}
+
 // IntoPath returns a variant of this manager to be used by a particular
 // component path, which is a child of the current component, where
 // observability components will be automatically tagged with the new path.
@@ -394,6 +485,41 @@ func (t *Type) UnsetPipe(name string, tran <-chan message.Transaction) {
 	t.pipeLock.Unlock()
 }
 
+// GetTopicSubscribers returns the current subscribers of a named topic.
+func (t *Type) GetTopicSubscribers(name string) []*bundle.TopicSubscriber {
+	t.pipeLock.RLock()
+	subs := t.topics[name]
+	t.pipeLock.RUnlock()
+	return subs
+}
+
+// SubscribeTopic adds a subscriber to a named topic.
+func (t *Type) SubscribeTopic(name string, s *bundle.TopicSubscriber) {
+	t.pipeLock.Lock()
+	// Copy on write so that slices obtained by publishers are never mutated.
+	subs := make([]*bundle.TopicSubscriber, 0, len(t.topics[name])+1)
+	subs = append(subs, t.topics[name]...)
+	t.topics[name] = append(subs, s)
+	t.pipeLock.Unlock()
+}
+
+// UnsubscribeTopic removes a subscriber from a named topic.
+func (t *Type) UnsubscribeTopic(name string, s *bundle.TopicSubscriber) {
+	t.pipeLock.Lock()
+	var subs []*bundle.TopicSubscriber
+	for _, existing := range t.topics[name] {
+		if existing != s {
+			subs = append(subs, existing)
+		}
+	}
+	if len(subs) == 0 {
+		delete(t.topics, name)
+	} else {
+		t.topics[name] = subs
+	}
+	t.pipeLock.Unlock()
+}
+
 //------------------------------------------------------------------------------
 
 // WithMetricsMapping returns a manager with the stored metrics exporter wrapped
@@ -443,7 +569,7 @@ func (t *Type) GetDocs(name string, ctype docs.Type) (docs.ComponentSpec, bool)
 // NewBuffer attempts to create a new buffer component from a config.
 func (t *Type) NewBuffer(conf buffer.Config) (buffer.Streamed, error) {
 	// Buffers currently never have a label
-	return t.env.BufferInit(conf, t.forLabel(""))
+	return t.env.BufferInit(conf, t.forComponent(conf.Type, ""))
 }
 
 //------------------------------------------------------------------------------
@@ -475,7 +601,7 @@ func (t *Type) AccessCache(ctx context.Context, name string, fn func(cache.V1))
 
 // NewCache attempts to create a new cache component from a config.
 func (t *Type) NewCache(conf cache.Config) (cache.V1, error) {
-	return t.env.CacheInit(conf, t.forLabel(conf.Label))
+	return t.env.CacheInit(conf, t.forComponent(conf.Type, conf.Label))
 }
 
 // StoreCache attempts to store a new cache resource. If an existing resource
@@ -494,7 +620,7 @@ func (t *Type) StoreCache(ctx context.Context, name string, conf cache.Config) e
 		}
 
 		var newCache cache.V1
-		if newCache, initErr = t.intoPath("cache_resources").NewCache(conf); initErr != nil {
+		if newCache, initErr = t.intoPath("cache_resources").initCache(ctx, conf); initErr != nil {
 			return
 		}
 		set(&newCache)
@@ -550,7 +676,7 @@ func (t *Type) AccessInput(ctx context.Context, name string, fn func(input.Strea
 
 // NewInput attempts to create a new input component from a config.
 func (t *Type) NewInput(conf input.Config) (input.Streamed, error) {
-	return t.env.InputInit(conf, t.forLabel(conf.Label))
+	return t.env.InputInit(conf, t.forComponent(conf.Type, conf.Label))
 }
 
 // StoreInput attempts to store a new input resource. If an existing resource
@@ -638,7 +764,7 @@ func (t *Type) AccessProcessor(ctx context.Context, name string, fn func(process
 
 // NewProcessor attempts to create a new processor component from a config.
 func (t *Type) NewProcessor(conf processor.Config) (processor.V1, error) {
-	return t.env.ProcessorInit(conf, t.forLabel(conf.Label))
+	return t.env.ProcessorInit(conf, t.forComponent(conf.Type, conf.Label))
 }
 
 // StoreProcessor attempts to store a new processor resource. If an existing
@@ -714,7 +840,7 @@ func (t *Type) AccessOutput(ctx context.Context, name string, fn func(output.Syn
 
 // NewOutput attempts to create a new output component from a config.
 func (t *Type) NewOutput(conf output.Config, pipelines ...processor.PipelineConstructorFunc) (output.Streamed, error) {
-	return t.env.OutputInit(conf, t.forLabel(conf.Label), pipelines...)
+	return t.env.OutputInit(conf, t.forComponent(conf.Type, conf.Label), pipelines...)
 }
 
 // StoreOutput attempts to store a new output resource. If an existing resource
@@ -806,7 +932,7 @@ func (t *Type) AccessRateLimit(ctx context.Context, name string, fn func(ratelim
 
 // NewRateLimit attempts to create a new rate limit component from a config.
 func (t *Type) NewRateLimit(conf ratelimit.Config) (ratelimit.V1, error) {
-	return t.env.RateLimitInit(conf, t.forLabel(conf.Label))
+	return t.env.RateLimitInit(conf, t.forComponent(conf.Type, conf.Label))
 }
 
 // StoreRateLimit attempts to store a new rate limit resource. If an existing
@@ -825,7 +951,7 @@ func (t *Type) StoreRateLimit(ctx context.Context, name string, conf ratelimit.C
 		}
 
 		var newRL ratelimit.V1
-		if newRL, initErr = t.intoPath("rate_limit_resources").NewRateLimit(conf); initErr != nil {
+		if newRL, initErr = t.intoPath("rate_limit_resources").initRateLimit(ctx, conf); initErr != nil {
 			return
 		}
 		set(&newRL)
@@ -852,6 +978,43 @@ func (t *Type) RemoveRateLimit(ctx context.Context, name string) error {
 	return closeErr
 }
 
+// ProbeHTTPClient returns true if an http client resource exists under the
+// provided name.
+func (t *Type) ProbeHTTPClient(name string) bool {
+	_, exists := t.httpClients[name]
+	return exists
+}
+
+// GetHTTPClient returns the shared client of an http client resource, which is
+// created with the provided constructor the first time it is accessed.
+func (t *Type) GetHTTPClient(name string, ctor bundle.LazyResourceConstructor) (any, error) {
+	c, exists := t.httpClients[name]
+	if !exists {
+		return nil, ErrResourceNotFound(name)
+	}
+	return c.get(func(conf map[string]any) (any, error) {
+		return ctor(conf, t.intoPath("http_client_resources").forLabel(name))
+	})
+}
+
+// ProbeSQLDB returns true if an sql resource exists under the provided name.
+func (t *Type) ProbeSQLDB(name string) bool {
+	_, exists := t.sqlDBs[name]
+	return exists
+}
+
+// GetSQLDB returns the shared database of an sql resource, which is created
+// with the provided constructor the first time it is accessed.
+func (t *Type) GetSQLDB(name string, ctor bundle.LazyResourceConstructor) (any, error) {
+	d, exists := t.sqlDBs[name]
+	if !exists {
+		return nil, ErrResourceNotFound(name)
+	}
+	return d.get(func(conf map[string]any) (any, error) {
+		return ctor(conf, t.intoPath("sql_resources").forLabel(name))
+	})
+}
+
 //------------------------------------------------------------------------------
 
 // CloseObservability attempts to clean up observability (metrics, tracing, etc)
@@ -967,5 +1130,13 @@ func (t *Type) WaitForClose(ctx context.Context) error {
 	}); err != nil {
 		return err
 	}
+
+	for _, lazies := range []map[string]*lazyResource{t.httpClients, t.sqlDBs, t.lookupTables, t.counters} {
+		for name, l := range lazies {
+			if err := l.close(ctx); err != nil {
+				return fmt.Errorf("resource '%s' failed to cleanly shutdown: %v", name, err)
+			}
+		}
+	}
 	return nil
 }
@@ -2,16 +2,19 @@ package manager_test
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/cache"
 	"github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/component/output"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/component/ratelimit"
@@ -534,3 +537,111 @@ func TestManagerPipeGetSet(t *testing.T) {
 		t.Error("Wrong transaction chan returned")
 	}
 }
+
+type typeLabelledMetrics struct {
+	*metrics.Local
+}
+
+func (t typeLabelledMetrics) ComponentTypeLabels() bool {
+	return true
+}
+
+func TestManagerComponentTypeLabels(t *testing.T) {
+	local := metrics.NewLocal()
+
+	mgr, err := manager.New(manager.NewResourceConfig(), manager.OptSetMetrics(metrics.NewNamespaced(typeLabelledMetrics{local})))
+	require.NoError(t, err)
+
+	conf := processor.NewConfig()
+	conf.Type = "bloblang"
+	conf.Bloblang = "root = this"
+	conf.Label = "foo"
+
+	p, err := mgr.IntoPath("pipeline", "processors", "0").NewProcessor(conf)
+	require.NoError(t, err)
+
+	_, err = p.ProcessBatch(context.Background(), message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.NoError(t, err)
+
+	mgr.Metrics().GetCounter("bar").Incr(1)
+
+	counters := local.GetCounters()
+	assert.Contains(t, counters, `processor_received{label="foo",path="root.pipeline.processors.0",type="bloblang"}`)
+	assert.Contains(t, counters, `bar{type=""}`)
+}
+
+type testHTTPClient struct {
+	conf   map[string]any
+	closed bool
+}
+
+func (t *testHTTPClient) Close(ctx context.Context) error {
+	t.closed = true
+	return nil
+}
+
+func TestManagerHTTPClient(t *testing.T) {
+	conf := manager.NewResourceConfig()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+http_client_resources:
+  - label: foo
+    timeout: 10s
+`), &conf))
+	require.Len(t, conf.ResourceHTTPClients, 1)
+	assert.Equal(t, "foo", conf.ResourceHTTPClients[0].Label)
+
+	mgr, err := manager.New(conf)
+	require.NoError(t, err)
+
+	assert.True(t, mgr.ProbeHTTPClient("foo"))
+	assert.False(t, mgr.ProbeHTTPClient("bar"))
+
+	var ctorCalls int
+	ctor := func(conf map[string]any, mgr bundle.NewManagement) (any, error) {
+		ctorCalls++
+		return &testHTTPClient{conf: conf}, nil
+	}
+
+	c, err := mgr.GetHTTPClient("foo", ctor)
+	require.NoError(t, err)
+
+	// Streams share http client resources.
+	c2, err := mgr.ForStream("baz").GetHTTPClient("foo", ctor)
+	require.NoError(t, err)
+	assert.Same(t, c, c2)
+	assert.Equal(t, 1, ctorCalls)
+	assert.Equal(t, map[string]any{"timeout": "10s"}, c.(*testHTTPClient).conf)
+
+	_, err = mgr.GetHTTPClient("bar", ctor)
+	require.Error(t, err)
+
+	require.NoError(t, mgr.WaitForClose(context.Background()))
+	assert.True(t, c.(*testHTTPClient).closed)
+}
+
+type recordingAPIReg struct {
+	paths []string
+}
+
+func (r *recordingAPIReg) RegisterEndpoint(path, desc string, h http.HandlerFunc) {
+	r.paths = append(r.paths, path)
+}
+
+func TestManagerStreamOption(t *testing.T) {
+	local := metrics.NewLocal()
+	apiReg := &recordingAPIReg{}
+
+	mgr, err := manager.New(manager.NewResourceConfig(),
+		manager.OptSetAPIReg(apiReg),
+		manager.OptSetStreamHTTPNamespacing(true),
+		manager.OptSetMetrics(metrics.NewNamespaced(local)),
+		manager.OptSetStream("foo"),
+	)
+	require.NoError(t, err)
+
+	mgr.Metrics().GetCounter("bar").Incr(1)
+	mgr.RegisterEndpoint("/baz", "", func(w http.ResponseWriter, r *http.Request) {})
+
+	assert.Equal(t, map[string]int64{`bar{stream="foo"}`: 1}, local.GetCounters())
+	assert.Equal(t, []string{"/foo/baz"}, apiReg.paths)
+}
@@ -0,0 +1,87 @@
+package message
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooledBufferSize is the largest capacity of a buffer that will be
+// returned to the pool, larger buffers are left for the garbage collector in
+// order to avoid pinning the memory of occasional large documents.
+const maxPooledBufferSize = 1 << 20
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		return &bytes.Buffer{}
+	},
+}
+
+// getBuffer returns an empty buffer from the pool. The buffer must be returned
+// with putBuffer once its contents are no longer referenced.
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer resets a buffer and returns it to the pool.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+//------------------------------------------------------------------------------
+
+// partBuffer is a reference counted byte slice drawn from a pool that backs the
+// raw contents of message parts. Shallow copies of a part share the buffer
+// without copying it and each hold a reference, a copy that wishes to mutate
+// the contents must first copy them into a buffer of its own unless it holds
+// the only reference. The buffer is returned to the pool once every part
+// referencing it has replaced its contents.
+type partBuffer struct {
+	b    []byte
+	refs atomic.Int32
+}
+
+var partBufferPool = sync.Pool{
+	New: func() any {
+		return &partBuffer{}
+	},
+}
+
+// newPartBuffer returns a buffer of a given length from the pool with a single
+// reference held by the caller. The contents of the buffer are undefined.
+func newPartBuffer(size int) *partBuffer {
+	p := partBufferPool.Get().(*partBuffer)
+	if cap(p.b) < size {
+		p.b = make([]byte, size)
+	}
+	p.b = p.b[:size]
+	p.refs.Store(1)
+	return p
+}
+
+// retain adds a reference to the buffer.
+func (p *partBuffer) retain() {
+	p.refs.Add(1)
+}
+
+// exclusive returns true when the caller holds the only reference to the
+// buffer, and can therefore mutate it in place.
+func (p *partBuffer) exclusive() bool {
+	return p.refs.Load() == 1
+}
+
+// release drops a reference to the buffer, returning it to the pool when no
+// references remain.
+func (p *partBuffer) release() {
+	if p.refs.Add(-1) > 0 {
+		return
+	}
+	if cap(p.b) > maxPooledBufferSize {
+		p.b = nil
+	}
+	partBufferPool.Put(p)
+}
@@ -0,0 +1,202 @@
+package message
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MarshalCanonicalJSON serialises a value as canonical JSON following the JSON
+// Canonicalization Scheme (RFC 8785). Object keys are sorted, insignificant
+// whitespace is omitted, numbers are formatted consistently regardless of how
+// they were originally written (1.0 and 1 are both serialised as 1) and
+// strings are only escaped where required, meaning HTML characters are left
+// intact.
+//
+// The result is stable across runs and is therefore suitable for hashing and
+// signing.
+func MarshalCanonicalJSON(v any) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if err := writeCanonicalJSON(buf, v); err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, buf.Len())
+	copy(b, buf.Bytes())
+	return b, nil
+}
+
+func writeCanonicalJSON(buf *bytes.Buffer, v any) error {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if t {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case string:
+		writeCanonicalString(buf, t)
+	case []byte:
+		// Bytes are serialised as a string of their raw contents, which
+		// matches the way in which raw message contents are treated
+		// throughout Bloblang.
+		writeCanonicalString(buf, string(t))
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			buf.WriteString(strconv.FormatInt(i, 10))
+			return nil
+		}
+		f, err := t.Float64()
+		if err != nil {
+			return err
+		}
+		return writeCanonicalFloat(buf, f)
+	case int:
+		buf.WriteString(strconv.FormatInt(int64(t), 10))
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(t), 10))
+	case int64:
+		buf.WriteString(strconv.FormatInt(t, 10))
+	case uint32:
+		buf.WriteString(strconv.FormatUint(uint64(t), 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(t, 10))
+	case float32:
+		return writeCanonicalFloat(buf, float64(t))
+	case float64:
+		return writeCanonicalFloat(buf, t)
+	case []any:
+		buf.WriteByte('[')
+		for i, e := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return lessUTF16(keys[i], keys[j])
+		})
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, t[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		// For any other type we fall back to the standard serialisation and
+		// then canonicalise the generic result.
+		b, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		var generic any
+		if err := dec.Decode(&generic); err != nil {
+			return err
+		}
+		return writeCanonicalJSON(buf, generic)
+	}
+	return nil
+}
+
+// writeCanonicalFloat writes a number formatted as per the ECMAScript
+// Number.prototype.toString algorithm, which the standard library encoder
+// already implements for float64 values.
+func writeCanonicalFloat(buf *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return errors.New("unsupported number value: " + strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	if f == 0 {
+		// Negative zero is serialised as zero.
+		buf.WriteByte('0')
+		return nil
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch c {
+			case '"':
+				buf.WriteString(`\"`)
+			case '\\':
+				buf.WriteString(`\\`)
+			case '\b':
+				buf.WriteString(`\b`)
+			case '\f':
+				buf.WriteString(`\f`)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				if c < 0x20 {
+					buf.WriteString(`\u00`)
+					buf.WriteByte(hexDigits[c>>4])
+					buf.WriteByte(hexDigits[c&0xf])
+				} else {
+					buf.WriteByte(c)
+				}
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			// Invalid UTF-8 is replaced, matching the standard encoder.
+			buf.WriteString("�")
+		} else {
+			buf.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	buf.WriteByte('"')
+}
+
+// lessUTF16 compares strings by their UTF-16 code units, which is the key
+// ordering mandated by RFC 8785.
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
@@ -0,0 +1,88 @@
+package message
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  any
+		output string
+	}{
+		{
+			name:   "sorted keys",
+			input:  map[string]any{"b": 1, "a": 2, "c": map[string]any{"z": true, "y": nil}},
+			output: `{"a":2,"b":1,"c":{"y":null,"z":true}}`,
+		},
+		{
+			name:   "utf16 key ordering",
+			input:  map[string]any{"\U0001F600": 1, "\uFB33": 2, "a": 3},
+			output: "{\"a\":3,\"\U0001F600\":1,\"\uFB33\":2}",
+		},
+		{
+			name:   "numbers",
+			input:  []any{json.Number("1.0"), json.Number("1e2"), json.Number("-0"), 1.5, int64(-10), uint64(20), 1e21, 1e-7, 0.000001},
+			output: `[1,100,0,1.5,-10,20,1e+21,1e-7,0.000001]`,
+		},
+		{
+			name:   "no html escaping",
+			input:  "<foo> & 'bar'",
+			output: `"<foo> & 'bar'"`,
+		},
+		{
+			name:   "control characters",
+			input:  "a\"b\\c\n\t\x01 ",
+			output: "\"a\\\"b\\\\c\\n\\t\\u0001 \"",
+		},
+		{
+			name:   "bytes",
+			input:  []byte("foo"),
+			output: `"foo"`,
+		},
+		{
+			name:   "other types",
+			input:  []string{"b", "a"},
+			output: `["b","a"]`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			b, err := MarshalCanonicalJSON(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.output, string(b))
+		})
+	}
+}
+
+func TestMarshalCanonicalJSONStable(t *testing.T) {
+	a, err := decodeJSON([]byte(`{"b":[1.0,2.50],"a":{"y":"<",  "x":1e2}}`))
+	require.NoError(t, err)
+
+	b, err := decodeJSON([]byte(`{"a":{"x":100,"y":"<"},"b":[1,2.5]}`))
+	require.NoError(t, err)
+
+	aBytes, err := MarshalCanonicalJSON(a)
+	require.NoError(t, err)
+
+	bBytes, err := MarshalCanonicalJSON(b)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"a":{"x":100,"y":"<"},"b":[1,2.5]}`, string(aBytes))
+	assert.Equal(t, string(aBytes), string(bBytes))
+}
+
+func TestMarshalCanonicalJSONErrors(t *testing.T) {
+	_, err := MarshalCanonicalJSON(math.NaN())
+	require.Error(t, err)
+
+	_, err = MarshalCanonicalJSON(map[string]any{"a": math.Inf(1)})
+	require.Error(t, err)
+}
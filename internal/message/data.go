@@ -1,17 +1,38 @@
 package message
 
+import (
+	"sync/atomic"
+
+	"github.com/Jeffail/gabs/v2"
+	"golang.org/x/exp/slices"
+)
+
 // Contains underlying allocated data for messages.
 type messageData struct {
-	rawBytes []byte // Contents are always read-only
+	rawBytes []byte // Contents are read-only unless we own buf exclusively
 	err      error
 
+	// Pooled buffer backing rawBytes, which is shared by shallow copies of
+	// the message that each hold a reference to it
+	buf *partBuffer
+
 	// Mutable when readOnlyStructured = false
 	readOnlyStructured bool
 	structured         any // Sometimes mutable
 
-	// Mutable when readOnlyMeta = false
-	readOnlyMeta bool
-	metadata     map[string]any
+	// Read-only values of paths resolved against rawBytes without parsing
+	// the whole document, reset whenever the contents change
+	lazyPaths []lazyPathValue
+
+	// Mutable when readOnlyMeta = false, which is atomic as the metadata of a
+	// message is frozen by copies that may be taken concurrently
+	readOnlyMeta atomic.Bool
+	metadata     *metadata
+}
+
+type lazyPathValue struct {
+	path  []string
+	value any
 }
 
 func newMessageBytes(content []byte) *messageData {
@@ -22,25 +43,57 @@ func newMessageBytes(content []byte) *messageData {
 	}
 }
 
-func (m *messageData) SetBytes(d []byte) {
+// setRawBytes replaces the raw contents of the message, dropping our reference
+// to the pooled buffer that backed the previous contents, if any.
+func (m *messageData) setRawBytes(d []byte, buf *partBuffer) {
+	if m.buf != nil {
+		m.buf.release()
+	}
 	m.rawBytes = d
+	m.buf = buf
+}
+
+func (m *messageData) SetBytes(d []byte) {
+	m.setRawBytes(d, nil)
 	m.structured = nil
+	m.lazyPaths = nil
 }
 
 func (m *messageData) AsBytes() []byte {
 	if len(m.rawBytes) == 0 && m.structured != nil {
-		m.rawBytes = encodeJSON(m.structured)
+		if buf := encodeJSONPooled(m.structured); buf != nil {
+			m.setRawBytes(buf.b, buf)
+		}
+	}
+	return m.rawBytes
+}
+
+// AsBytesMut returns the raw contents of the message as a slice that can be
+// mutated in place. When the contents are shared with other copies of the
+// message, or were not allocated from the pool, they are first copied into a
+// pooled buffer that this message owns (copy on write).
+func (m *messageData) AsBytesMut() []byte {
+	raw := m.AsBytes()
+	if m.buf == nil || !m.buf.exclusive() {
+		buf := newPartBuffer(len(raw))
+		copy(buf.b, raw)
+		m.setRawBytes(buf.b, buf)
 	}
+
+	// Our structured form may become stale as the bytes change
+	m.structured = nil
+	m.readOnlyStructured = false
+	m.lazyPaths = nil
 	return m.rawBytes
 }
 
 func (m *messageData) SetStructured(jObj any) {
-	m.rawBytes = nil
+	m.lazyPaths = nil
 	if jObj == nil {
-		m.rawBytes = []byte(`null`)
+		m.setRawBytes([]byte(`null`), nil)
 		return
 	}
-	m.rawBytes = nil
+	m.setRawBytes(nil, nil)
 	m.structured = jObj
 	m.readOnlyStructured = true
 }
@@ -64,12 +117,65 @@ func (m *messageData) AsStructured() (any, error) {
 	return m.structured, err
 }
 
+// AsStructuredPath returns the value located at a path within the structured
+// form of the message. When the message has not yet been parsed the raw bytes
+// are scanned for the target value only, which means reading a small number of
+// fields from a large document does not require decoding all of it. The values
+// of scanned paths are cached until the contents of the message change.
+func (m *messageData) AsStructuredPath(path []string) (any, error) {
+	if m.structured == nil && len(m.rawBytes) > 0 && len(path) > 0 {
+		for _, p := range m.lazyPaths {
+			if slices.Equal(p.path, path) {
+				return p.value, nil
+			}
+		}
+		if useSIMD.Load() {
+			if raw, ok := simdJSONPath(m.rawBytes, path); ok {
+				return m.cacheLazyPath(path, raw)
+			}
+		}
+		if raw, found, ok := lazyJSONPath(m.rawBytes, path); ok {
+			if !found {
+				return m.cacheLazyPath(path, nil)
+			}
+			return m.cacheLazyPath(path, raw)
+		}
+	}
+
+	v, err := m.AsStructured()
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return v, nil
+	}
+	return gabs.Wrap(v).Search(path...).Data(), nil
+}
+
+func (m *messageData) cacheLazyPath(path []string, raw []byte) (any, error) {
+	var v any
+	if raw != nil {
+		var err error
+		if v, err = decodeJSON(raw); err != nil {
+			return nil, err
+		}
+	}
+	m.lazyPaths = append(m.lazyPaths, lazyPathValue{
+		path:  slices.Clone(path),
+		value: v,
+	})
+	return v, nil
+}
+
 func (m *messageData) AsStructuredMut() (any, error) {
 	if m.readOnlyStructured {
+		// Copy on write: the structured value may be shared with other copies
+		// of this message and so we clone it once, after which our copy is
+		// owned and can be mutated freely.
 		if m.structured != nil {
 			m.structured = cloneGeneric(m.structured)
 		}
-		m.readOnlyStructured = true
+		m.readOnlyStructured = false
 	}
 
 	v, err := m.AsStructured()
@@ -78,23 +184,35 @@ func (m *messageData) AsStructuredMut() (any, error) {
 	}
 
 	// Bytes need resetting as our structured form may change
-	m.rawBytes = nil
+	m.setRawBytes(nil, nil)
+	m.lazyPaths = nil
 	return v, nil
 }
 
 // ShallowCopy returns a copy of the message data that can be mutated without
 // mutating the original message contents (metadata and structured data).
 func (m *messageData) ShallowCopy() *messageData {
-	return &messageData{
+	// The metadata is now shared and so both the original and the copy must
+	// write to new layers on top of it.
+	m.readOnlyMeta.Store(true)
+	if m.buf != nil {
+		m.buf.retain()
+	}
+	c := &messageData{
 		rawBytes: m.rawBytes,
 		err:      m.err,
+		buf:      m.buf,
 
 		readOnlyStructured: true,
 		structured:         m.structured,
 
-		readOnlyMeta: true,
-		metadata:     m.metadata,
+		// Capped so that appends from either copy never share an array
+		lazyPaths: m.lazyPaths[:len(m.lazyPaths):len(m.lazyPaths)],
+
+		metadata: m.metadata,
 	}
+	c.readOnlyMeta.Store(true)
+	return c
 }
 
 // DeepCopy returns a copy of the message data that can be mutated without
@@ -105,12 +223,9 @@ func (m *messageData) ShallowCopy() *messageData {
 // This is worth doing on values persisted outside of the lifetime of a
 // transaction unless some other strategy is used for persistence.
 func (m *messageData) DeepCopy() *messageData {
-	var clonedMeta map[string]any
+	var clonedMeta *metadata
 	if m.metadata != nil {
-		clonedMeta = make(map[string]any, len(m.metadata))
-		for k, v := range m.metadata {
-			clonedMeta[k] = cloneGeneric(v)
-		}
+		clonedMeta = m.metadata.flatten(cloneGeneric)
 	}
 
 	var bytesCopy []byte
@@ -137,57 +252,42 @@ func (m *messageData) IsEmpty() bool {
 }
 
 func (m *messageData) writeableMeta() {
-	if !m.readOnlyMeta {
+	if !m.readOnlyMeta.Load() {
 		return
 	}
-
-	var clonedMeta map[string]any
 	if m.metadata != nil {
-		clonedMeta = make(map[string]any, len(m.metadata))
-		for k, v := range m.metadata {
-			// NOTE: All metadata is store as mutable so no need to deep clone.
-			clonedMeta[k] = v
-		}
+		m.metadata = m.metadata.writeable()
 	}
-
-	m.metadata = clonedMeta
-	m.readOnlyMeta = false
+	m.readOnlyMeta.Store(false)
 }
 
 func (m *messageData) MetaGetMut(key string) (any, bool) {
 	if m.metadata == nil {
 		return nil, false
 	}
-	s, exists := m.metadata[key]
-	if !exists {
-		return nil, false
-	}
-	return s, true
+	return m.metadata.get(key)
 }
 
 func (m *messageData) MetaSetMut(key string, value any) {
 	m.writeableMeta()
 	if m.metadata == nil {
-		m.metadata = map[string]any{
-			key: value,
-		}
-		return
+		m.metadata = &metadata{}
 	}
-	m.metadata[key] = value
+	m.metadata.set(key, value)
 }
 
 func (m *messageData) MetaDelete(key string) {
 	m.writeableMeta()
-	delete(m.metadata, key)
+	if m.metadata != nil {
+		m.metadata.delete(key)
+	}
 }
 
 func (m *messageData) MetaIterMut(f func(k string, v any) error) error {
-	for ak, av := range m.metadata {
-		if err := f(ak, av); err != nil {
-			return err
-		}
+	if m.metadata == nil {
+		return nil
 	}
-	return nil
+	return m.metadata.iter(f)
 }
 
 func (m *messageData) ErrorGet() error {
@@ -98,3 +98,90 @@ func TestConcurrentMutationsFromStructured(t *testing.T) {
 	close(kickOffChan)
 	wg.Wait()
 }
+
+func TestStructuredCopyOnWrite(t *testing.T) {
+	source := newMessageBytes(nil)
+	source.SetStructured(map[string]any{
+		"foo": "bar",
+	})
+
+	local := source.ShallowCopy()
+
+	first, err := local.AsStructuredMut()
+	require.NoError(t, err)
+	first.(map[string]any)["foo"] = "baz"
+
+	// Subsequent mutable access should reuse our owned copy rather than
+	// cloning again.
+	second, err := local.AsStructuredMut()
+	require.NoError(t, err)
+	assert.Equal(t, "baz", second.(map[string]any)["foo"])
+
+	original, err := source.AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, "bar", original.(map[string]any)["foo"])
+	assert.Equal(t, `{"foo":"baz"}`, string(local.AsBytes()))
+	assert.Equal(t, `{"foo":"bar"}`, string(source.AsBytes()))
+}
+
+func TestBytesCopyOnWrite(t *testing.T) {
+	source := newMessageBytes([]byte(`hello world`))
+
+	// Contents that were not allocated from the pool are copied first
+	owned := source.AsBytesMut()
+	owned[0] = 'H'
+	require.NotNil(t, source.buf)
+	assert.Equal(t, `Hello world`, string(source.AsBytes()))
+
+	local := source.ShallowCopy()
+	assert.Same(t, source.buf, local.buf)
+	assert.Equal(t, int32(2), source.buf.refs.Load())
+
+	// Shared contents are copied before being mutated
+	mut := local.AsBytesMut()
+	mut[6] = 'W'
+	assert.NotSame(t, source.buf, local.buf)
+	assert.Equal(t, int32(1), source.buf.refs.Load())
+	assert.Equal(t, `Hello World`, string(local.AsBytes()))
+	assert.Equal(t, `Hello world`, string(source.AsBytes()))
+
+	// Once exclusively owned the contents are mutated in place
+	buf := local.buf
+	local.AsBytesMut()[0] = 'J'
+	assert.Same(t, buf, local.buf)
+	assert.Equal(t, `Jello World`, string(local.AsBytes()))
+}
+
+func TestBytesMutResetsStructured(t *testing.T) {
+	source := newMessageBytes(nil)
+	source.SetStructured(map[string]any{"foo": "bar"})
+
+	raw := source.AsBytesMut()
+	copy(raw[len(`{"foo":"`):], "baz")
+
+	v, err := source.AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"foo": "baz"}, v)
+}
+
+func TestBytesPooledBufferReleased(t *testing.T) {
+	source := newMessageBytes(nil)
+	source.SetStructured(map[string]any{"foo": "bar"})
+	assert.Equal(t, `{"foo":"bar"}`, string(source.AsBytes()))
+
+	buf := source.buf
+	require.NotNil(t, buf)
+
+	local := source.ShallowCopy()
+	assert.Equal(t, int32(2), buf.refs.Load())
+
+	source.SetBytes([]byte(`first`))
+	assert.Nil(t, source.buf)
+	assert.Equal(t, int32(1), buf.refs.Load())
+	assert.Equal(t, `{"foo":"bar"}`, string(local.AsBytes()))
+
+	_, err := local.AsStructuredMut()
+	require.NoError(t, err)
+	assert.Nil(t, local.buf)
+	assert.Equal(t, int32(0), buf.refs.Load())
+}
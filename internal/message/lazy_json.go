@@ -0,0 +1,289 @@
+package message
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// lazyJSONPath scans a raw JSON document for the value located at a path
+// without decoding the rest of the document. The full document is still walked
+// in order to detect malformed input, but skipped values are never allocated.
+//
+// When ok is false the scan was unable to determine a result with the same
+// semantics as a full parse (malformed input, ambiguous array traversal, etc)
+// and the caller should fall back to decoding the entire document.
+func lazyJSONPath(doc []byte, path []string) (raw []byte, found, ok bool) {
+	s := lazyScanner{b: doc}
+	start := s.skipWS(0)
+	end, raw, found, ok := s.scan(start, path)
+	if !ok {
+		return nil, false, false
+	}
+	if s.skipWS(end) != len(doc) {
+		return nil, false, false
+	}
+	return raw, found, true
+}
+
+type lazyScanner struct {
+	b []byte
+}
+
+func (s *lazyScanner) skipWS(i int) int {
+	for i < len(s.b) {
+		switch s.b[i] {
+		case ' ', '\t', '\n', '\r':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// scan walks the value starting at index i and returns the index following it.
+// When path is non-empty the value at the path (relative to this value) is
+// returned when found.
+func (s *lazyScanner) scan(i int, path []string) (end int, raw []byte, found, ok bool) {
+	if i >= len(s.b) {
+		return 0, nil, false, false
+	}
+	switch s.b[i] {
+	case '{':
+		return s.scanObject(i, path)
+	case '[':
+		return s.scanArray(i, path)
+	}
+	if end, ok = s.skipScalar(i); !ok {
+		return 0, nil, false, false
+	}
+	if len(path) == 0 {
+		return end, s.b[i:end], true, true
+	}
+	// Paths that continue through a scalar value resolve to nothing.
+	return end, nil, false, true
+}
+
+func (s *lazyScanner) scanObject(i int, path []string) (end int, raw []byte, found, ok bool) {
+	start := i
+	i = s.skipWS(i + 1)
+	if i < len(s.b) && s.b[i] == '}' {
+		if len(path) == 0 {
+			return i + 1, s.b[start : i+1], true, true
+		}
+		return i + 1, nil, false, true
+	}
+	for {
+		if i >= len(s.b) || s.b[i] != '"' {
+			return 0, nil, false, false
+		}
+		keyEnd, escaped, kOK := s.skipString(i)
+		if !kOK {
+			return 0, nil, false, false
+		}
+
+		matched := false
+		if len(path) > 0 {
+			if escaped {
+				var key string
+				if err := json.Unmarshal(s.b[i:keyEnd], &key); err != nil {
+					return 0, nil, false, false
+				}
+				matched = key == path[0]
+			} else {
+				matched = string(s.b[i+1:keyEnd-1]) == path[0]
+			}
+		}
+
+		if i = s.skipWS(keyEnd); i >= len(s.b) || s.b[i] != ':' {
+			return 0, nil, false, false
+		}
+		i = s.skipWS(i + 1)
+
+		var subPath []string
+		if matched {
+			subPath = path[1:]
+		}
+		vEnd, vRaw, vFound, vOK := s.scan(i, subPath)
+		if !vOK {
+			return 0, nil, false, false
+		}
+		if matched {
+			// Duplicate keys are resolved by the last occurrence, matching
+			// the behaviour of a full decode.
+			raw, found = vRaw, vFound
+		}
+
+		if i = s.skipWS(vEnd); i >= len(s.b) {
+			return 0, nil, false, false
+		}
+		switch s.b[i] {
+		case ',':
+			i = s.skipWS(i + 1)
+		case '}':
+			if len(path) == 0 {
+				return i + 1, s.b[start : i+1], true, true
+			}
+			return i + 1, raw, found, true
+		default:
+			return 0, nil, false, false
+		}
+	}
+}
+
+func (s *lazyScanner) scanArray(i int, path []string) (end int, raw []byte, found, ok bool) {
+	start := i
+	target := -1
+	if len(path) > 0 {
+		// Non-index path segments are applied to each element of an array
+		// during a full search, which we do not replicate here.
+		var err error
+		if target, err = strconv.Atoi(path[0]); err != nil || target < 0 {
+			return 0, nil, false, false
+		}
+	}
+
+	i = s.skipWS(i + 1)
+	if i < len(s.b) && s.b[i] == ']' {
+		if len(path) == 0 {
+			return i + 1, s.b[start : i+1], true, true
+		}
+		return i + 1, nil, false, true
+	}
+	for index := 0; ; index++ {
+		var subPath []string
+		if index == target {
+			subPath = path[1:]
+		}
+		vEnd, vRaw, vFound, vOK := s.scan(i, subPath)
+		if !vOK {
+			return 0, nil, false, false
+		}
+		if index == target {
+			raw, found = vRaw, vFound
+		}
+
+		if i = s.skipWS(vEnd); i >= len(s.b) {
+			return 0, nil, false, false
+		}
+		switch s.b[i] {
+		case ',':
+			i = s.skipWS(i + 1)
+		case ']':
+			if len(path) == 0 {
+				return i + 1, s.b[start : i+1], true, true
+			}
+			return i + 1, raw, found, true
+		default:
+			return 0, nil, false, false
+		}
+	}
+}
+
+// skipString returns the index following a quoted string starting at i, and
+// whether the string contains escape sequences.
+func (s *lazyScanner) skipString(i int) (end int, escaped, ok bool) {
+	for i++; i < len(s.b); i++ {
+		switch c := s.b[i]; {
+		case c == '\\':
+			escaped = true
+			if i++; i >= len(s.b) {
+				return 0, false, false
+			}
+			switch s.b[i] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				if i+4 >= len(s.b) {
+					return 0, false, false
+				}
+				for _, h := range s.b[i+1 : i+5] {
+					if !isHexDigit(h) {
+						return 0, false, false
+					}
+				}
+				i += 4
+			default:
+				return 0, false, false
+			}
+		case c == '"':
+			return i + 1, escaped, true
+		case c < 0x20:
+			return 0, false, false
+		}
+	}
+	return 0, false, false
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+var (
+	lazyTrue  = []byte("true")
+	lazyFalse = []byte("false")
+	lazyNull  = []byte("null")
+)
+
+func (s *lazyScanner) skipScalar(i int) (end int, ok bool) {
+	switch c := s.b[i]; {
+	case c == '"':
+		end, _, ok = s.skipString(i)
+		return
+	case c == 't':
+		return i + len(lazyTrue), bytes.HasPrefix(s.b[i:], lazyTrue)
+	case c == 'f':
+		return i + len(lazyFalse), bytes.HasPrefix(s.b[i:], lazyFalse)
+	case c == 'n':
+		return i + len(lazyNull), bytes.HasPrefix(s.b[i:], lazyNull)
+	case c == '-' || isDigit(c):
+		return s.skipNumber(i)
+	}
+	return 0, false
+}
+
+// skipNumber returns the index following a number starting at i, which must
+// follow the JSON grammar: -?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?
+func (s *lazyScanner) skipNumber(i int) (end int, ok bool) {
+	digits := func(i int) int {
+		for i < len(s.b) && isDigit(s.b[i]) {
+			i++
+		}
+		return i
+	}
+
+	if s.b[i] == '-' {
+		i++
+	}
+	switch {
+	case i >= len(s.b) || !isDigit(s.b[i]):
+		return 0, false
+	case s.b[i] == '0':
+		i++
+	default:
+		i = digits(i)
+	}
+
+	if i < len(s.b) && s.b[i] == '.' {
+		if end = digits(i + 1); end == i+1 {
+			return 0, false
+		}
+		i = end
+	}
+
+	if i < len(s.b) && (s.b[i] == 'e' || s.b[i] == 'E') {
+		i++
+		if i < len(s.b) && (s.b[i] == '+' || s.b[i] == '-') {
+			i++
+		}
+		if end = digits(i); end == i {
+			return 0, false
+		}
+		i = end
+	}
+	return i, true
+}
@@ -0,0 +1,323 @@
+package message
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/minio/simdjson-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazyJSONPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		path     []string
+		raw      string
+		notFound bool
+		fallback bool
+	}{
+		{
+			name: "top level field",
+			doc:  `{"foo":"bar","baz":10}`,
+			path: []string{"baz"},
+			raw:  `10`,
+		},
+		{
+			name: "nested object",
+			doc:  ` { "a" : { "b" : { "c" : [1, 2, {"d":true}] } } } `,
+			path: []string{"a", "b"},
+			raw:  `{ "c" : [1, 2, {"d":true}] }`,
+		},
+		{
+			name: "array index",
+			doc:  `{"a":[1,"two",{"three":3}]}`,
+			path: []string{"a", "2", "three"},
+			raw:  `3`,
+		},
+		{
+			name:     "array index out of bounds",
+			doc:      `{"a":[1,2]}`,
+			path:     []string{"a", "5"},
+			notFound: true,
+		},
+		{
+			name:     "missing field",
+			doc:      `{"a":{"b":"c"}}`,
+			path:     []string{"a", "nope"},
+			notFound: true,
+		},
+		{
+			name:     "path through scalar",
+			doc:      `{"a":"b"}`,
+			path:     []string{"a", "b"},
+			notFound: true,
+		},
+		{
+			name: "escaped keys and strings",
+			doc:  `{"a\"b":"c\"}d","ef":"g"}`,
+			path: []string{"ef"},
+			raw:  `"g"`,
+		},
+		{
+			name: "duplicate keys",
+			doc:  `{"a":1,"a":2}`,
+			path: []string{"a"},
+			raw:  `2`,
+		},
+		{
+			name:     "array wildcard",
+			doc:      `{"a":[{"b":1},{"b":2}]}`,
+			path:     []string{"a", "b"},
+			fallback: true,
+		},
+		{
+			name:     "truncated document",
+			doc:      `{"a":1,"b":`,
+			path:     []string{"a"},
+			fallback: true,
+		},
+		{
+			name:     "trailing content",
+			doc:      `{"a":1} {"a":2}`,
+			path:     []string{"a"},
+			fallback: true,
+		},
+		{
+			name:     "bad literal",
+			doc:      `{"a":1,"b":nul}`,
+			path:     []string{"a"},
+			fallback: true,
+		},
+		{
+			name: "valid numbers and escapes",
+			doc:  `{"a":[0,-0,1.5,-12e3,4E+2,0.5e-1],"b":"\\\/\b\f\n\r\t\u00e9\"","c":true}`,
+			path: []string{"c"},
+			raw:  `true`,
+		},
+	}
+	for _, bad := range []string{`01`, `-`, `1.`, `.5`, `1.e5`, `1e`, `1e+`, `--1`, `1-2`, `0x10`, `"\x"`, `"\u12g4"`, `"\u12"`} {
+		tests = append(tests, struct {
+			name     string
+			doc      string
+			path     []string
+			raw      string
+			notFound bool
+			fallback bool
+		}{
+			name:     "malformed " + bad,
+			doc:      `{"a":1,"b":` + bad + `}`,
+			path:     []string{"a"},
+			fallback: true,
+		})
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			raw, found, ok := lazyJSONPath([]byte(test.doc), test.path)
+			if test.fallback {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			if test.notFound {
+				assert.False(t, found)
+				return
+			}
+			require.True(t, found)
+			assert.Equal(t, test.raw, string(raw))
+		})
+	}
+}
+
+func TestPartAsStructuredPath(t *testing.T) {
+	docs := []string{
+		`{"a":{"b":[1,{"c":"d"}]},"e":null}`,
+		`{"a":[{"b":1},{"b":2}]}`,
+		`[1,2,3]`,
+	}
+	paths := [][]string{
+		{"a"},
+		{"a", "b"},
+		{"a", "b", "1", "c"},
+		{"e"},
+		{"nope"},
+		{"1"},
+	}
+
+	for _, doc := range docs {
+		for _, path := range paths {
+			full, err := decodeJSON([]byte(doc))
+			require.NoError(t, err)
+
+			exp := gabs.Wrap(full).Search(path...).Data()
+			act, err := NewPart([]byte(doc)).AsStructuredPath(path...)
+			require.NoError(t, err)
+			assert.Equal(t, exp, act, "%v: %v", doc, path)
+		}
+	}
+
+	_, err := NewPart([]byte(`{"a":1,`)).AsStructuredPath("a")
+	require.Error(t, err)
+
+	p := NewPart(nil)
+	p.SetStructured(map[string]any{"a": "b"})
+	v, err := p.AsStructuredPath("a")
+	require.NoError(t, err)
+	assert.Equal(t, "b", v)
+}
+
+func TestPartAsStructuredPathCached(t *testing.T) {
+	p := NewPart([]byte(`{"a":{"b":"c"},"d":"e"}`))
+
+	v, err := p.AsStructuredPath("a")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"b": "c"}, v)
+
+	v, err = p.AsStructuredPath("nope")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+
+	// Cached values are returned without scanning the contents again.
+	p.data.rawBytes[7] = 'x'
+	v, err = p.AsStructuredPath("a")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"b": "c"}, v)
+
+	v, err = p.ShallowCopy().AsStructuredPath("a")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"b": "c"}, v)
+
+	// Changing the contents resets the cache.
+	p.SetBytes([]byte(`{"a":"f"}`))
+	v, err = p.AsStructuredPath("a")
+	require.NoError(t, err)
+	assert.Equal(t, "f", v)
+
+	_, err = p.AsStructuredMut()
+	require.NoError(t, err)
+	assert.Nil(t, p.data.lazyPaths)
+}
+
+func BenchmarkLazyJSONPath(b *testing.B) {
+	doc := []byte(`{
+		"root":{
+			"first":{
+				"value1": 1,
+				"value2": 1.2,
+				"value3": false,
+				"value4": "hello world"
+			},
+			"second": [
+				1,
+				1.2,
+				false,
+				"hello world"
+			]
+		}
+	}`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewPart(doc).AsStructuredPath("root", "first", "value4"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSIMDJSONPath(t *testing.T) {
+	if !simdjson.SupportedCPU() {
+		t.Skip("CPU does not support SIMD JSON parsing")
+	}
+
+	doc := []byte(`{"a":{"b":[1,{"c":"d"}],"e":{"f":"g"}},"h":null}`)
+
+	raw, ok := simdJSONPath(doc, []string{"a", "e"})
+	require.True(t, ok)
+	assert.Equal(t, `{"f":"g"}`, string(raw))
+
+	raw, ok = simdJSONPath(doc, []string{"h"})
+	require.True(t, ok)
+	assert.Equal(t, `null`, string(raw))
+
+	_, ok = simdJSONPath(doc, []string{"a", "b", "1", "c"})
+	assert.False(t, ok)
+
+	_, ok = simdJSONPath([]byte(`{"a":`), []string{"a"})
+	assert.False(t, ok)
+}
+
+func TestSetSIMDJSON(t *testing.T) {
+	t.Cleanup(func() {
+		SetSIMDJSON(false)
+	})
+
+	assert.Equal(t, simdjson.SupportedCPU(), SetSIMDJSON(true))
+	assert.Equal(t, simdjson.SupportedCPU(), useSIMD.Load())
+
+	v, err := NewPart([]byte(`{"a":{"e":{"f":"g"}}}`)).AsStructuredPath("a", "e")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"f": "g"}, v)
+
+	assert.True(t, SetSIMDJSON(false))
+	assert.False(t, useSIMD.Load())
+}
+
+func benchmarkDoc() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"items":[`)
+	for i := 0; i < 500; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `{"id":%v,"name":"item %v","tags":["a","b","c"],"nested":{"value":%v.5}}`, i, i, i)
+	}
+	buf.WriteString(`],"meta":{"id":"foo","count":500}}`)
+	return buf.Bytes()
+}
+
+func BenchmarkJSONPathFullParse(b *testing.B) {
+	doc := benchmarkDoc()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(doc)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v, err := decodeJSON(doc)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = gabs.Wrap(v).Search("meta", "id").Data()
+	}
+}
+
+func BenchmarkJSONPathLazy(b *testing.B) {
+	doc := benchmarkDoc()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(doc)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, ok := lazyJSONPath(doc, []string{"meta", "id"}); !ok {
+			b.Fatal("expected lazy path result")
+		}
+	}
+}
+
+func BenchmarkJSONPathSIMD(b *testing.B) {
+	if !simdjson.SupportedCPU() {
+		b.Skip("CPU does not support SIMD JSON parsing")
+	}
+	doc := benchmarkDoc()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(doc)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := simdJSONPath(doc, []string{"meta", "id"}); !ok {
+			b.Fatal("expected SIMD path result")
+		}
+	}
+}
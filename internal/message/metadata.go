@@ -0,0 +1,108 @@
+package message
+
+// The maximum number of layers a metadata store may have before it is
+// flattened on the next write. This bounds the cost of lookups and iteration
+// for messages that are copied and modified many times.
+const maxMetadataDepth = 4
+
+// metadataTombstone marks a key as deleted within a layer, masking any value
+// of the same key within its parents.
+type metadataTombstone struct{}
+
+// metadata is a layered, copy-on-write key/value store. A layer referenced by
+// more than one message is read-only, and modifications made by any of those
+// messages are written to a new layer on top of it. This means that copying a
+// message and then modifying a few metadata keys (a very common pattern for
+// large batches) costs only the keys that were modified rather than a full
+// copy of the metadata.
+type metadata struct {
+	parent *metadata
+	values map[string]any
+	depth  int
+}
+
+func (m *metadata) get(key string) (any, bool) {
+	for l := m; l != nil; l = l.parent {
+		if v, exists := l.values[key]; exists {
+			if _, deleted := v.(metadataTombstone); deleted {
+				return nil, false
+			}
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// set writes a key to the top layer, which must be owned by the caller.
+func (m *metadata) set(key string, value any) {
+	if m.values == nil {
+		m.values = map[string]any{}
+	}
+	m.values[key] = value
+}
+
+// delete removes a key from the top layer, which must be owned by the caller.
+func (m *metadata) delete(key string) {
+	if m.parent != nil {
+		if _, exists := m.parent.get(key); exists {
+			m.set(key, metadataTombstone{})
+			return
+		}
+	}
+	delete(m.values, key)
+}
+
+func (m *metadata) iter(f func(k string, v any) error) error {
+	if m.parent == nil {
+		for k, v := range m.values {
+			if _, deleted := v.(metadataTombstone); deleted {
+				continue
+			}
+			if err := f(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	seen := map[string]struct{}{}
+	for l := m; l != nil; l = l.parent {
+		for k, v := range l.values {
+			if _, exists := seen[k]; exists {
+				continue
+			}
+			seen[k] = struct{}{}
+			if _, deleted := v.(metadataTombstone); deleted {
+				continue
+			}
+			if err := f(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// flatten returns a single layer containing the merged contents of all layers,
+// with each value passed through fn.
+func (m *metadata) flatten(fn func(any) any) *metadata {
+	values := make(map[string]any, len(m.values))
+	_ = m.iter(func(k string, v any) error {
+		values[k] = fn(v)
+		return nil
+	})
+	return &metadata{values: values}
+}
+
+// writeable returns a layer that can be modified by the caller, where m is
+// read-only.
+func (m *metadata) writeable() *metadata {
+	if m.depth+1 >= maxMetadataDepth {
+		// NOTE: All metadata is stored as mutable so no need to deep clone.
+		return m.flatten(func(v any) any { return v })
+	}
+	return &metadata{
+		parent: m,
+		depth:  m.depth + 1,
+	}
+}
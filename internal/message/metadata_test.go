@@ -0,0 +1,113 @@
+package message
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func metaAsMap(t testing.TB, p *Part) map[string]any {
+	t.Helper()
+	m := map[string]any{}
+	require.NoError(t, p.MetaIterMut(func(k string, v any) error {
+		_, exists := m[k]
+		require.False(t, exists, "key %v iterated twice", k)
+		m[k] = v
+		return nil
+	}))
+	return m
+}
+
+func TestMetadataLayers(t *testing.T) {
+	ts := time.Unix(10, 0)
+
+	root := NewPart(nil)
+	root.MetaSetMut("a", "a1")
+	root.MetaSetMut("b", int64(2))
+	root.MetaSetMut("c", ts)
+
+	child := root.ShallowCopy()
+	child.MetaSetMut("a", "a2")
+	child.MetaDelete("b")
+	child.MetaSetMut("d", true)
+
+	grandchild := child.ShallowCopy()
+	grandchild.MetaSetMut("b", 3.5)
+	grandchild.MetaDelete("d")
+	grandchild.MetaDelete("nope")
+
+	assert.Equal(t, map[string]any{"a": "a1", "b": int64(2), "c": ts}, metaAsMap(t, root))
+	assert.Equal(t, map[string]any{"a": "a2", "c": ts, "d": true}, metaAsMap(t, child))
+	assert.Equal(t, map[string]any{"a": "a2", "b": 3.5, "c": ts}, metaAsMap(t, grandchild))
+
+	_, exists := child.MetaGetMut("b")
+	assert.False(t, exists)
+
+	v, exists := grandchild.MetaGetMut("c")
+	assert.True(t, exists)
+	assert.Equal(t, ts, v)
+
+	deep := grandchild.DeepCopy()
+	assert.Equal(t, metaAsMap(t, grandchild), metaAsMap(t, deep))
+	assert.Nil(t, deep.data.metadata.parent)
+}
+
+func TestMetadataLayersOriginalIsolated(t *testing.T) {
+	root := NewPart(nil)
+	root.MetaSetMut("a", "1")
+
+	child := root.ShallowCopy()
+	child.MetaSetMut("b", "2")
+
+	root.MetaSetMut("a", "changed")
+	root.MetaSetMut("c", "3")
+	root.MetaDelete("b")
+
+	assert.Equal(t, map[string]any{"a": "changed", "c": "3"}, metaAsMap(t, root))
+	assert.Equal(t, map[string]any{"a": "1", "b": "2"}, metaAsMap(t, child))
+
+	grandchild := child.ShallowCopy()
+	child.MetaDelete("a")
+
+	assert.Equal(t, map[string]any{"b": "2"}, metaAsMap(t, child))
+	assert.Equal(t, map[string]any{"a": "1", "b": "2"}, metaAsMap(t, grandchild))
+}
+
+func TestMetadataLayersFlatten(t *testing.T) {
+	p := NewPart(nil)
+	p.MetaSetMut("root", "value")
+
+	for i := 0; i < maxMetadataDepth*3; i++ {
+		p = p.ShallowCopy()
+		p.MetaSetMut(fmt.Sprintf("key%v", i), i)
+		p.MetaDelete("root")
+		p.MetaSetMut("root", i)
+		assert.Less(t, p.data.metadata.depth, maxMetadataDepth)
+	}
+
+	m := metaAsMap(t, p)
+	assert.Len(t, m, maxMetadataDepth*3+1)
+	assert.Equal(t, maxMetadataDepth*3-1, m["root"])
+}
+
+func BenchmarkMetadataCopyAndSet(b *testing.B) {
+	source := NewPart(nil)
+	for i := 0; i < 50; i++ {
+		source.MetaSetMut(fmt.Sprintf("key%v", i), fmt.Sprintf("value%v", i))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p := source.ShallowCopy()
+		p.MetaSetMut("key0", "new value")
+		p.MetaSetMut("extra", int64(i))
+		if v, _ := p.MetaGetMut("key49"); v != "value49" {
+			b.Fatalf("unexpected value: %v", v)
+		}
+	}
+}
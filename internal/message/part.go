@@ -77,11 +77,20 @@ func (p *Part) ErrorSet(err error) {
 	p.data.ErrorSet(err)
 }
 
-// AsBytes returns the body of the message part.
+// AsBytes returns the body of the message part. The returned slice is
+// read-only, and may be recycled once the contents of the part are changed, and
+// therefore must be copied if it is to be retained beyond that point.
 func (p *Part) AsBytes() []byte {
 	return p.data.AsBytes()
 }
 
+// AsBytesMut returns the body of the message part as a slice that is safe to
+// mutate in place. The contents are copied first only when they are shared with
+// other copies of the part.
+func (p *Part) AsBytesMut() []byte {
+	return p.data.AsBytesMut()
+}
+
 // AsStructuredMut returns the structured format of the message if already set,
 // or attempts to parse the raw bytes as a JSON document if not. The returned
 // structure is mutable and therefore safe to mutate directly.
@@ -96,6 +105,14 @@ func (p *Part) AsStructured() (any, error) {
 	return p.data.AsStructured()
 }
 
+// AsStructuredPath returns the value located at a path within the structured
+// format of the message, or nil if the path does not exist. If the message has
+// not yet been parsed then only the target value is decoded and cached until
+// the contents change. The returned value should be considered read-only.
+func (p *Part) AsStructuredPath(path ...string) (any, error) {
+	return p.data.AsStructuredPath(path)
+}
+
 // SetBytes the value of the message part as a raw byte slice.
 func (p *Part) SetBytes(data []byte) *Part {
 	p.data.SetBytes(data)
@@ -0,0 +1,61 @@
+package message
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/minio/simdjson-go"
+)
+
+// useSIMD enables an experimental SIMD accelerated parser for read-only path
+// lookups on unparsed messages. It is opt-in as the parser normalises the
+// formatting of numbers within extracted objects and arrays, and is only
+// available on CPUs that support AVX2 and CLMUL.
+var useSIMD atomic.Bool
+
+// SetSIMDJSON enables or disables the SIMD accelerated parser used for path
+// lookups on unparsed messages. Returns false when the parser is enabled on a
+// CPU that does not support it, in which case it remains disabled.
+func SetSIMDJSON(enabled bool) bool {
+	if enabled && !simdjson.SupportedCPU() {
+		useSIMD.Store(false)
+		return false
+	}
+	useSIMD.Store(enabled)
+	return true
+}
+
+var simdTapePool = sync.Pool{
+	New: func() any {
+		return (*simdjson.ParsedJson)(nil)
+	},
+}
+
+// simdJSONPath attempts to locate and extract the value at a path within a raw
+// JSON document using a SIMD accelerated parser. When ok is false the result
+// could not be determined (unsupported document shape, path traverses an
+// array, etc) and the caller should fall back to another method.
+func simdJSONPath(doc []byte, path []string) (raw []byte, ok bool) {
+	reuse := simdTapePool.Get().(*simdjson.ParsedJson)
+	pj, err := simdjson.Parse(doc, reuse)
+	if err != nil {
+		simdTapePool.Put(reuse)
+		return nil, false
+	}
+	defer simdTapePool.Put(pj)
+
+	iter := pj.Iter()
+	elem, err := iter.FindElement(nil, path...)
+	if err != nil {
+		// A missing path might be the result of traversing an array, which
+		// the SIMD parser doesn't support, and so we defer to a fallback
+		// rather than report the value as missing.
+		return nil, false
+	}
+
+	// The tape is reused and so the extracted value must be copied out.
+	if raw, err = elem.Iter.MarshalJSON(); err != nil {
+		return nil, false
+	}
+	return raw, true
+}
@@ -60,16 +60,41 @@ func decodeJSON(rawBytes []byte) (structured any, err error) {
 }
 
 func encodeJSON(d any) (rawBytes []byte) {
-	var buf bytes.Buffer
-	enc := json.NewEncoder(&buf)
-	enc.SetEscapeHTML(false)
-	if err := enc.Encode(d); err != nil {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if encodeJSONTo(buf, d) {
+		// The pooled buffer is reused and so the result is copied into an
+		// allocation of the exact size, trimming the trailing newline.
+		rawBytes = make([]byte, buf.Len()-1)
+		copy(rawBytes, buf.Bytes())
+	}
+	return
+}
+
+// encodeJSONPooled is equivalent to encodeJSON but writes the result into a
+// pooled part buffer, returning nil if the value could not be encoded.
+func encodeJSONPooled(d any) *partBuffer {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if !encodeJSONTo(buf, d) {
 		return nil
 	}
-	if buf.Len() > 1 {
-		rawBytes = buf.Bytes()[:buf.Len()-1]
+	pb := newPartBuffer(buf.Len() - 1)
+	copy(pb.b, buf.Bytes())
+	return pb
+}
+
+// encodeJSONTo writes a value to a buffer as JSON followed by a newline, and
+// returns false if the value could not be encoded or the result is empty.
+func encodeJSONTo(buf *bytes.Buffer, d any) bool {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(d); err != nil {
+		return false
 	}
-	return
+	return buf.Len() > 1
 }
 
 // Copy of query.IToString
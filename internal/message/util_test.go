@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 //------------------------------------------------------------------------------
@@ -182,3 +184,49 @@ func BenchmarkCloneJSON(b *testing.B) {
 }
 
 //------------------------------------------------------------------------------
+
+func BenchmarkEncodeJSON(b *testing.B) {
+	var generic any
+	err := json.Unmarshal([]byte(`{
+		"root":{
+			"first":{
+				"value1": 1,
+				"value2": 1.2,
+				"value3": false,
+				"value4": "hello world"
+			},
+			"second": [
+				1,
+				1.2,
+				false,
+				"hello world"
+			]
+		}
+	}`), &generic)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var res []byte
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		res = encodeJSON(generic)
+	}
+	b.StopTimer()
+
+	if len(res) == 0 {
+		b.Error("Empty result")
+	}
+}
+
+func TestEncodeJSONPooledBuffers(t *testing.T) {
+	first := encodeJSON(map[string]any{"foo": "bar"})
+	second := encodeJSON(map[string]any{"baz": "buz"})
+
+	// Results must not share memory with reused pool buffers.
+	assert.Equal(t, `{"foo":"bar"}`, string(first))
+	assert.Equal(t, `{"baz":"buz"}`, string(second))
+	assert.Equal(t, `"<a>"`, string(encodeJSON("<a>")))
+}
@@ -1,8 +1,9 @@
 package pipeline
 
 import (
-	"strconv"
+	"fmt"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
 )
@@ -15,16 +16,28 @@ import (
 // In order to fully utilise each processing thread you must either have a
 // number of parallel inputs that matches or surpasses the number of pipeline
 // threads, or use a memory buffer.
+//
+// When an ordering key is specified batches that resolve to the same key are
+// always routed to the same processing thread, which preserves their relative
+// ordering even when multiple threads are configured.
+//
+// The split ack mode determines how messages that processors split into
+// multiple batches are acknowledged, and can be either strict or best_effort,
+// where an empty mode is strict.
 type Config struct {
-	Threads    int                `json:"threads" yaml:"threads"`
-	Processors []processor.Config `json:"processors" yaml:"processors"`
+	Threads      int                `json:"threads" yaml:"threads"`
+	OrderingKey  string             `json:"ordering_key,omitempty" yaml:"ordering_key,omitempty"`
+	SplitAckMode string             `json:"split_ack_mode,omitempty" yaml:"split_ack_mode,omitempty"`
+	Processors   []processor.Config `json:"processors" yaml:"processors"`
 }
 
 // NewConfig returns a configuration struct fully populated with default values.
 func NewConfig() Config {
 	return Config{
-		Threads:    -1,
-		Processors: []processor.Config{},
+		Threads:      -1,
+		OrderingKey:  "",
+		SplitAckMode: "",
+		Processors:   []processor.Config{},
 	}
 }
 
@@ -32,17 +45,44 @@ func NewConfig() Config {
 
 // New creates an input type based on an input configuration.
 func New(conf Config, mgr bundle.NewManagement) (processor.Pipeline, error) {
+	splitAcks := SplitAckStrict
+	switch SplitAckMode(conf.SplitAckMode) {
+	case "", SplitAckStrict:
+	case SplitAckBestEffort:
+		splitAcks = SplitAckBestEffort
+	default:
+		return nil, fmt.Errorf("split_ack_mode not recognised: %v", conf.SplitAckMode)
+	}
+
 	processors := make([]processor.V1, len(conf.Processors))
 	for j, procConf := range conf.Processors {
 		var err error
-		pMgr := mgr.IntoPath("processors", strconv.Itoa(j))
+		pMgr := mgr.IntoPath("processors", bundle.PathSegment(j, procConf.Label))
 		processors[j], err = pMgr.NewProcessor(procConf)
 		if err != nil {
 			return nil, err
 		}
 	}
 	if conf.Threads == 1 {
-		return NewProcessor(processors...), nil
+		proc := NewProcessor(processors...)
+		proc.SetSplitAckMode(splitAcks)
+		return proc, nil
+	}
+
+	var pool *Pool
+	var err error
+	if conf.OrderingKey != "" {
+		var key *field.Expression
+		if key, err = mgr.BloblEnvironment().NewField(conf.OrderingKey); err != nil {
+			return nil, fmt.Errorf("failed to parse ordering_key expression: %w", err)
+		}
+		pool, err = NewKeyedPool(conf.Threads, key, mgr.Logger(), processors...)
+	} else {
+		pool, err = NewPool(conf.Threads, mgr.Logger(), processors...)
+	}
+	if err != nil {
+		return nil, err
 	}
-	return NewPool(conf.Threads, mgr.Logger(), processors...)
+	pool.SetSplitAckMode(splitAcks)
+	return pool, nil
 }
@@ -0,0 +1,94 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/pipeline"
+
+	_ "github.com/benthosdev/benthos/v4/internal/impl/pure"
+)
+
+func TestPipelineLabelPaths(t *testing.T) {
+	local := metrics.NewLocal()
+	mgr, err := manager.New(manager.NewResourceConfig(), manager.OptSetMetrics(metrics.NewNamespaced(local)))
+	require.NoError(t, err)
+
+	conf := pipeline.NewConfig()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+processors:
+  - mapping: 'root = content()'
+  - label: foo
+    mapping: 'root = content()'
+`), &conf))
+
+	p, err := pipeline.New(conf, mgr.IntoPath("pipeline"))
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, p.Consume(tChan))
+	t.Cleanup(func() {
+		close(tChan)
+		require.NoError(t, p.WaitForClose(context.Background()))
+	})
+
+	resChan := make(chan error, 1)
+	tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello world")}), resChan)
+
+	tran := <-p.TransactionChan()
+	require.NoError(t, tran.Ack(context.Background(), nil))
+	require.NoError(t, <-resChan)
+
+	counters := local.GetCounters()
+	assert.Equal(t, int64(1), counters[`processor_received{label="",path="root.pipeline.processors.0"}`], counters)
+	assert.Equal(t, int64(1), counters[`processor_received{label="foo",path="root.pipeline.processors.foo"}`], counters)
+}
+
+func TestPipelineSplitAckMode(t *testing.T) {
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	conf := pipeline.NewConfig()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+threads: 2
+split_ack_mode: best_effort
+processors:
+  - mapping: 'root = content().string().split(",")'
+  - unarchive:
+      format: json_array
+  - split: {}
+`), &conf))
+
+	p, err := pipeline.New(conf, mgr.IntoPath("pipeline"))
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, p.Consume(tChan))
+	t.Cleanup(func() {
+		close(tChan)
+		require.NoError(t, p.WaitForClose(context.Background()))
+	})
+
+	resChan := make(chan error, 1)
+	tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("a,b")}), resChan)
+
+	var trans []message.Transaction
+	for i := 0; i < 2; i++ {
+		trans = append(trans, <-p.TransactionChan())
+	}
+	require.NoError(t, trans[0].Ack(context.Background(), nil))
+	require.NoError(t, trans[1].Ack(context.Background(), errors.New("nope")))
+	require.EqualError(t, <-resChan, "nope")
+
+	conf.SplitAckMode = "meow"
+	_, err = pipeline.New(conf, mgr.IntoPath("pipeline"))
+	require.EqualError(t, err, "split_ack_mode not recognised: meow")
+}
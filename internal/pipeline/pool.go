@@ -2,10 +2,12 @@ package pipeline
 
 import (
 	"context"
+	"hash/fnv"
 	"runtime"
 	"sync"
 	"sync/atomic"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/log"
@@ -16,8 +18,13 @@ import (
 // Pool is a pool of pipelines. Each pipeline reads from a shared transaction
 // channel. Inputs remain coupled to their outputs as they propagate the
 // response channel in the transaction.
+//
+// When the pool is created with an ordering key each transaction is routed to a
+// worker chosen by a hash of the key, otherwise workers compete for
+// transactions freely.
 type Pool struct {
 	workers []processor.Pipeline
+	key     *field.Expression
 
 	log log.Modular
 
@@ -47,8 +54,75 @@ func NewPool(threads int, log log.Modular, msgProcessors ...processor.V1) (*Pool
 	return p, nil
 }
 
+// NewKeyedPool creates a new processing pool where transactions that resolve
+// to the same key are always processed by the same worker, and therefore
+// preserve their relative ordering. The key is resolved against the first
+// message of each batch.
+func NewKeyedPool(threads int, key *field.Expression, log log.Modular, msgProcessors ...processor.V1) (*Pool, error) {
+	p, err := NewPool(threads, log, msgProcessors...)
+	if err != nil {
+		return nil, err
+	}
+	p.key = key
+	return p, nil
+}
+
+// SetSplitAckMode sets the mode by which transactions that are split into
+// multiple batches by processors are acknowledged for all workers of the pool,
+// which must be called before the pool starts consuming.
+func (p *Pool) SetSplitAckMode(mode SplitAckMode) {
+	for _, w := range p.workers {
+		if proc, ok := w.(*Processor); ok {
+			proc.SetSplitAckMode(mode)
+		}
+	}
+}
+
 //------------------------------------------------------------------------------
 
+// workerIndex returns the index of the worker that a transaction should be
+// routed to based on the ordering key.
+func (p *Pool) workerIndex(t message.Transaction) int {
+	if t.Payload.Len() == 0 {
+		return 0
+	}
+	k, err := p.key.String(0, t.Payload)
+	if err != nil {
+		p.log.Debugf("Failed to resolve ordering key: %v\n", err)
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(k))
+	return int(h.Sum32() % uint32(len(p.workers)))
+}
+
+// dispatch reads transactions from the shared input channel and routes each
+// to the input channel of a worker determined by the ordering key. Worker
+// channels are closed once the shared input channel is closed.
+func (p *Pool) dispatch(workerChans []chan message.Transaction) {
+	defer func() {
+		for _, c := range workerChans {
+			close(c)
+		}
+	}()
+	for {
+		var t message.Transaction
+		var open bool
+		select {
+		case t, open = <-p.messagesIn:
+			if !open {
+				return
+			}
+		case <-p.shutSig.CloseNowChan():
+			return
+		}
+		select {
+		case workerChans[p.workerIndex(t)] <- t:
+		case <-p.shutSig.CloseNowChan():
+			return
+		}
+	}
+}
+
 // loop is the processing loop of this pipeline.
 func (p *Pool) loop() {
 	// Note this is currently kept open as we only have our children as a
@@ -74,8 +148,22 @@ func (p *Pool) loop() {
 
 	var closeInternalOnce sync.Once
 
-	for _, worker := range p.workers {
-		if err := worker.Consume(p.messagesIn); err != nil {
+	workerInputs := make([]<-chan message.Transaction, len(p.workers))
+	if p.key != nil {
+		workerChans := make([]chan message.Transaction, len(p.workers))
+		for i := range workerChans {
+			workerChans[i] = make(chan message.Transaction)
+			workerInputs[i] = workerChans[i]
+		}
+		go p.dispatch(workerChans)
+	} else {
+		for i := range workerInputs {
+			workerInputs[i] = p.messagesIn
+		}
+	}
+
+	for i, worker := range p.workers {
+		if err := worker.Consume(workerInputs[i]); err != nil {
 			p.log.Errorf("Failed to start pipeline worker: %v\n", err)
 			atomic.AddInt64(&remainingWorkers, -1)
 			continue
@@ -2,6 +2,7 @@ package pipeline_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"testing"
@@ -276,3 +277,66 @@ func TestPoolMultiNaturalClose(t *testing.T) {
 	close(tChan)
 	require.NoError(t, proc.WaitForClose(context.Background()))
 }
+
+func TestPoolOrderingKey(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	procConf := processor.NewConfig()
+	procConf.Type = "sleep"
+	procConf.Sleep.Duration = `${! (json("seq") % 3) }ms`
+
+	conf := pipeline.NewConfig()
+	conf.Threads = 4
+	conf.OrderingKey = `${! meta("key") }`
+	conf.Processors = append(conf.Processors, procConf)
+
+	proc, err := pipeline.New(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, proc.Consume(tChan))
+
+	keys := []string{"foo", "bar", "baz", "buz", "qux"}
+	nMsgs := 200
+
+	go func() {
+		for i := 0; i < nMsgs; i++ {
+			part := message.NewPart([]byte(fmt.Sprintf(`{"seq":%v}`, i)))
+			part.MetaSetMut("key", keys[i%len(keys)])
+			select {
+			case tChan <- message.NewTransactionFunc(message.Batch{part}, func(context.Context, error) error { return nil }):
+			case <-ctx.Done():
+				return
+			}
+		}
+		close(tChan)
+	}()
+
+	lastSeen := map[string]int{}
+	for i := 0; i < nMsgs; i++ {
+		var tran message.Transaction
+		select {
+		case tran = <-proc.TransactionChan():
+		case <-ctx.Done():
+			t.Fatal("Timed out")
+		}
+		require.Equal(t, 1, tran.Payload.Len())
+
+		key, _ := tran.Payload.Get(0).MetaGetMut("key")
+		v, err := tran.Payload.Get(0).AsStructured()
+		require.NoError(t, err)
+
+		seq, err := v.(map[string]any)["seq"].(json.Number).Int64()
+		require.NoError(t, err)
+
+		if last, exists := lastSeen[key.(string)]; exists {
+			assert.Greater(t, int(seq), last, key)
+		}
+		lastSeen[key.(string)] = int(seq)
+		require.NoError(t, tran.Ack(ctx, nil))
+	}
+	assert.Len(t, lastSeen, len(keys))
+
+	require.NoError(t, proc.WaitForClose(ctx))
+}
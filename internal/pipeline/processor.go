@@ -3,6 +3,7 @@ package pipeline
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
@@ -11,11 +12,31 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/shutdown"
 )
 
+// SplitAckMode determines how the acknowledgement of a transaction is derived
+// from the batches that processors split it into.
+type SplitAckMode string
+
+const (
+	// SplitAckStrict acknowledges a transaction only once every batch derived
+	// from it has been delivered, where derived batches that are rejected are
+	// retried individually until they succeed.
+	SplitAckStrict SplitAckMode = "strict"
+
+	// SplitAckBestEffort dispatches the batches derived from a transaction
+	// without tracking them for retries, and acknowledges the transaction once
+	// every derived batch has received a response, with the first error of
+	// any rejected batch. The input is therefore responsible for redelivering
+	// the whole transaction, which may result in duplicates of the derived
+	// batches that were delivered.
+	SplitAckBestEffort SplitAckMode = "best_effort"
+)
+
 // Processor is a pipeline that supports both Consumer and Producer interfaces.
 // The processor will read from a source, perform some processing, and then
 // either propagate a new message or drop it.
 type Processor struct {
 	msgProcessors []processor.V1
+	splitAcks     SplitAckMode
 
 	messagesOut chan message.Transaction
 	responsesIn chan error
@@ -29,12 +50,20 @@ type Processor struct {
 func NewProcessor(msgProcessors ...processor.V1) *Processor {
 	return &Processor{
 		msgProcessors: msgProcessors,
+		splitAcks:     SplitAckStrict,
 		messagesOut:   make(chan message.Transaction),
 		responsesIn:   make(chan error),
 		shutSig:       shutdown.NewSignaller(),
 	}
 }
 
+// SetSplitAckMode sets the mode by which transactions that are split into
+// multiple batches by processors are acknowledged, which must be called before
+// the pipeline starts consuming.
+func (p *Processor) SetSplitAckMode(mode SplitAckMode) {
+	p.splitAcks = mode
+}
+
 //------------------------------------------------------------------------------
 
 // loop is the processing loop of this pipeline.
@@ -75,7 +104,11 @@ func (p *Processor) loop() {
 		}
 
 		if len(resultMsgs) > 1 {
-			p.dispatchMessages(closeNowCtx, resultMsgs, tran.Ack)
+			if p.splitAcks == SplitAckBestEffort {
+				p.dispatchMessagesBestEffort(closeNowCtx, resultMsgs, tran.Ack)
+			} else {
+				p.dispatchMessages(closeNowCtx, resultMsgs, tran.Ack)
+			}
 		} else {
 			select {
 			case p.messagesOut <- message.NewTransactionFunc(resultMsgs[0], tran.Ack):
@@ -141,6 +174,42 @@ func (p *Processor) dispatchMessages(ctx context.Context, msgs []message.Batch,
 	_ = ackFn(ctx, nil)
 }
 
+// dispatchMessagesBestEffort sends multiple message results of processors over
+// the shared messages channel without waiting for their responses, the
+// transaction they were derived from is acknowledged once all of them have
+// responded, with the first error returned by any of them.
+func (p *Processor) dispatchMessagesBestEffort(ctx context.Context, msgs []message.Batch, ackFn func(context.Context, error) error) {
+	remaining := int64(len(msgs))
+
+	var firstErr error
+	var firstErrMut sync.Mutex
+
+	for _, b := range msgs {
+		transac := message.NewTransactionFunc(b.ShallowCopy(), func(ctx context.Context, err error) error {
+			if err != nil {
+				firstErrMut.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				firstErrMut.Unlock()
+			}
+			if atomic.AddInt64(&remaining, -1) > 0 {
+				return nil
+			}
+			firstErrMut.Lock()
+			err = firstErr
+			firstErrMut.Unlock()
+			return ackFn(ctx, err)
+		})
+
+		select {
+		case p.messagesOut <- transac:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // Consume assigns a messages channel for the pipeline to read.
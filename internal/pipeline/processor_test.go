@@ -382,3 +382,65 @@ func TestProcessorMultiMsgsOddSync(t *testing.T) {
 		t.Error("Expected mockproc to have waited for close")
 	}
 }
+
+func TestProcessorMultiMsgsBestEffort(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	mockProc := &mockMultiMsgProcessor{N: 3}
+
+	proc := pipeline.NewProcessor(mockProc)
+	proc.SetSplitAckMode(pipeline.SplitAckBestEffort)
+
+	tChan, resChan := make(chan message.Transaction), make(chan error)
+	require.NoError(t, proc.Consume(tChan))
+
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch(nil), resChan):
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	// All derived messages are dispatched before any are acknowledged
+	resFns := []func(context.Context, error) error{}
+	for i := 0; i < mockProc.N; i++ {
+		select {
+		case procT, open := <-proc.TransactionChan():
+			require.True(t, open)
+			assert.Equal(t, fmt.Sprintf("test%v", i), string(procT.Payload.Get(0).AsBytes()))
+			resFns = append(resFns, procT.Ack)
+		case <-time.After(time.Second):
+			t.Fatal("Timed out")
+		}
+	}
+
+	require.NoError(t, resFns[0](ctx, nil))
+	require.NoError(t, resFns[1](ctx, errors.New("nope")))
+
+	select {
+	case res := <-resChan:
+		t.Fatalf("Unexpected early response: %v", res)
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	go func() {
+		assert.NoError(t, resFns[2](ctx, nil))
+	}()
+
+	select {
+	case res := <-resChan:
+		require.EqualError(t, res, "nope")
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	// Rejected messages are not retried
+	select {
+	case procT := <-proc.TransactionChan():
+		t.Fatalf("Unexpected retry: %s", procT.Payload.Get(0).AsBytes())
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	proc.TriggerCloseNow()
+	require.NoError(t, proc.WaitForClose(ctx))
+}
@@ -0,0 +1,74 @@
+package profiling
+
+import (
+	"github.com/benthosdev/benthos/v4/internal/docs"
+)
+
+// Types of profile that can be captured.
+const (
+	ProfileCPU       = "cpu"
+	ProfileHeap      = "heap"
+	ProfileAllocs    = "allocs"
+	ProfileGoroutine = "goroutine"
+	ProfileMutex     = "mutex"
+	ProfileBlock     = "block"
+)
+
+// Config contains configuration fields for continuous profiling.
+type Config struct {
+	Enabled     bool            `json:"enabled" yaml:"enabled"`
+	Interval    string          `json:"interval" yaml:"interval"`
+	CPUDuration string          `json:"cpu_duration" yaml:"cpu_duration"`
+	Profiles    []string        `json:"profiles" yaml:"profiles"`
+	Output      string          `json:"output" yaml:"output"`
+	Pyroscope   PyroscopeConfig `json:"pyroscope" yaml:"pyroscope"`
+}
+
+// PyroscopeConfig contains configuration fields for shipping profiles to a
+// Pyroscope compatible server.
+type PyroscopeConfig struct {
+	URL             string `json:"url" yaml:"url"`
+	ApplicationName string `json:"application_name" yaml:"application_name"`
+}
+
+// NewConfig creates a new profiling config with default values.
+func NewConfig() Config {
+	return Config{
+		Enabled:     false,
+		Interval:    "1m",
+		CPUDuration: "10s",
+		Profiles:    []string{ProfileCPU, ProfileHeap},
+		Output:      "",
+		Pyroscope: PyroscopeConfig{
+			URL:             "",
+			ApplicationName: "benthos",
+		},
+	}
+}
+
+// Spec returns a field spec for the profiling configuration.
+func Spec() docs.FieldSpec {
+	return docs.FieldObject(
+		"profiling", "Configures the periodic capture of runtime profiles, which are shipped to an output resource and/or a Pyroscope compatible server so that the performance of long running pipelines can be diagnosed after the fact.",
+	).WithChildren(
+		docs.FieldBool("enabled", "Whether continuous profiling is enabled.").HasDefault(false),
+		docs.FieldString("interval", "The period of time between each round of profile captures.").HasDefault("1m"),
+		docs.FieldString("cpu_duration", "The period of time over which CPU profiles are sampled, which must be shorter than the interval.").HasDefault("10s"),
+		docs.FieldString("profiles", "The types of profile to capture.").Array().HasAnnotatedOptions(
+			ProfileCPU, "CPU usage sampled over the `cpu_duration`.",
+			ProfileHeap, "Memory allocations of live objects.",
+			ProfileAllocs, "All past memory allocations.",
+			ProfileGoroutine, "Stack traces of all current goroutines.",
+			ProfileMutex, "Stack traces of holders of contended mutexes.",
+			ProfileBlock, "Stack traces that led to blocking on synchronization primitives.",
+		).HasDefault([]string{ProfileCPU, ProfileHeap}),
+		docs.FieldString(
+			"output", "The label of an [output resource](/docs/configuration/resources) to send profiles to, where each profile is a message containing a gzipped pprof protobuf. The metadata fields `profile_type`, `profile_timestamp` and `profile_filename` are added to each message, which can be used in order to build object paths.",
+			"profiles_bucket",
+		).HasDefault(""),
+		docs.FieldObject("pyroscope", "Send profiles to the ingest API of a Pyroscope compatible server.").WithChildren(
+			docs.FieldString("url", "The base URL of the server. Profiles are not sent when this field is empty.", "http://localhost:4040").HasDefault(""),
+			docs.FieldString("application_name", "The application name to register profiles under.").HasDefault("benthos"),
+		),
+	).Advanced().AtVersion("4.24.0")
+}
@@ -0,0 +1,285 @@
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// Profile is a captured runtime profile encoded as a gzipped pprof protobuf.
+type Profile struct {
+	Type  string
+	From  time.Time
+	Until time.Time
+	Data  []byte
+}
+
+// Filename returns a file name for the profile that is unique for its type and
+// capture time.
+func (p Profile) Filename() string {
+	return fmt.Sprintf("%v-%v.pprof", p.Type, p.Until.UTC().Format("20060102T150405Z"))
+}
+
+// Profiler periodically captures runtime profiles and ships them to the
+// configured destinations.
+type Profiler struct {
+	interval    time.Duration
+	cpuDuration time.Duration
+	profiles    []string
+	output      string
+	pyroURL     string
+	pyroApp     string
+
+	mgr        bundle.NewManagement
+	log        log.Modular
+	mCaptured  metrics.StatCounter
+	mFailed    metrics.StatCounter
+	httpClient *http.Client
+
+	ctx       context.Context
+	cancel    func()
+	loopDone  chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// New creates a profiler from a config, the profiler does not begin capturing
+// profiles until Start is called.
+func New(conf Config, mgr bundle.NewManagement) (*Profiler, error) {
+	p := &Profiler{
+		output:     conf.Output,
+		pyroURL:    strings.TrimSuffix(conf.Pyroscope.URL, "/"),
+		pyroApp:    conf.Pyroscope.ApplicationName,
+		mgr:        mgr,
+		log:        mgr.Logger(),
+		mCaptured:  mgr.Metrics().GetCounter("profiling_captured"),
+		mFailed:    mgr.Metrics().GetCounter("profiling_failed"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		loopDone:   make(chan struct{}),
+	}
+
+	var err error
+	if p.interval, err = time.ParseDuration(conf.Interval); err != nil {
+		return nil, fmt.Errorf("failed to parse profiling interval: %w", err)
+	}
+	if p.interval <= 0 {
+		return nil, errors.New("profiling interval must be greater than zero")
+	}
+	if p.cpuDuration, err = time.ParseDuration(conf.CPUDuration); err != nil {
+		return nil, fmt.Errorf("failed to parse profiling cpu_duration: %w", err)
+	}
+	if p.cpuDuration <= 0 || p.cpuDuration >= p.interval {
+		return nil, errors.New("profiling cpu_duration must be greater than zero and less than the interval")
+	}
+	if p.output == "" && p.pyroURL == "" {
+		return nil, errors.New("profiling requires either an output or a pyroscope url")
+	}
+	if p.output != "" && !mgr.ProbeOutput(p.output) {
+		return nil, fmt.Errorf("output resource '%v' was not found", p.output)
+	}
+
+	for _, t := range conf.Profiles {
+		switch t {
+		case ProfileCPU, ProfileHeap, ProfileAllocs, ProfileGoroutine:
+		case ProfileMutex:
+			if runtime.SetMutexProfileFraction(-1) == 0 {
+				runtime.SetMutexProfileFraction(5)
+			}
+		case ProfileBlock:
+			runtime.SetBlockProfileRate(int(time.Millisecond))
+		default:
+			return nil, fmt.Errorf("profile type not recognised: %v", t)
+		}
+		p.profiles = append(p.profiles, t)
+	}
+	if len(p.profiles) == 0 {
+		return nil, errors.New("at least one profile type must be specified")
+	}
+
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	return p, nil
+}
+
+// Start begins capturing profiles in the background.
+func (p *Profiler) Start() {
+	p.startOnce.Do(func() {
+		go p.loop()
+	})
+}
+
+func (p *Profiler) loop() {
+	defer close(p.loopDone)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.ctx.Done():
+			return
+		}
+		for _, t := range p.profiles {
+			prof, err := p.Capture(p.ctx, t)
+			if err != nil {
+				if p.ctx.Err() != nil {
+					return
+				}
+				p.mFailed.Incr(1)
+				p.log.Errorf("Failed to capture %v profile: %v\n", t, err)
+				continue
+			}
+			if err := p.Ship(p.ctx, prof); err != nil {
+				p.mFailed.Incr(1)
+				p.log.Errorf("Failed to ship %v profile: %v\n", t, err)
+				continue
+			}
+			p.mCaptured.Incr(1)
+		}
+	}
+}
+
+// Capture a single profile of a given type.
+func (p *Profiler) Capture(ctx context.Context, profileType string) (Profile, error) {
+	prof := Profile{
+		Type: profileType,
+		From: time.Now(),
+	}
+
+	var buf bytes.Buffer
+	if profileType == ProfileCPU {
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return prof, err
+		}
+		select {
+		case <-time.After(p.cpuDuration):
+		case <-ctx.Done():
+		}
+		pprof.StopCPUProfile()
+		if err := ctx.Err(); err != nil {
+			return prof, err
+		}
+	} else {
+		rp := pprof.Lookup(profileType)
+		if rp == nil {
+			return prof, fmt.Errorf("profile type not recognised: %v", profileType)
+		}
+		if err := rp.WriteTo(&buf, 0); err != nil {
+			return prof, err
+		}
+	}
+
+	prof.Until = time.Now()
+	prof.Data = buf.Bytes()
+	return prof, nil
+}
+
+// Ship a profile to each configured destination.
+func (p *Profiler) Ship(ctx context.Context, prof Profile) error {
+	var errs []error
+	if p.output != "" {
+		if err := p.shipToOutput(ctx, prof); err != nil {
+			errs = append(errs, fmt.Errorf("output resource: %w", err))
+		}
+	}
+	if p.pyroURL != "" {
+		if err := p.shipToPyroscope(ctx, prof); err != nil {
+			errs = append(errs, fmt.Errorf("pyroscope: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Profiler) shipToOutput(ctx context.Context, prof Profile) error {
+	part := message.NewPart(prof.Data)
+	part.MetaSetMut("profile_type", prof.Type)
+	part.MetaSetMut("profile_timestamp", prof.Until.UTC().Format(time.RFC3339))
+	part.MetaSetMut("profile_filename", prof.Filename())
+
+	resChan := make(chan error, 1)
+	tran := message.NewTransaction(message.Batch{part}, resChan)
+
+	var err error
+	if aerr := p.mgr.AccessOutput(ctx, p.output, func(o output.Sync) {
+		err = o.WriteTransaction(ctx, tran)
+	}); aerr != nil {
+		return aerr
+	}
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err = <-resChan:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	return err
+}
+
+func (p *Profiler) shipToPyroscope(ctx context.Context, prof Profile) error {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("profile", "profile.pprof")
+	if err != nil {
+		return err
+	}
+	if _, err = fw.Write(prof.Data); err != nil {
+		return err
+	}
+	if err = mw.Close(); err != nil {
+		return err
+	}
+
+	q := url.Values{}
+	q.Set("name", p.pyroApp+"{}")
+	q.Set("from", strconv.FormatInt(prof.From.Unix(), 10))
+	q.Set("until", strconv.FormatInt(prof.Until.Unix(), 10))
+	q.Set("spyName", "gospy")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.pyroURL+"/ingest?"+q.Encode(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code: %v", res.StatusCode)
+	}
+	return nil
+}
+
+// Close stops the profiler and waits for any capture in progress to end.
+func (p *Profiler) Close(ctx context.Context) error {
+	p.stopOnce.Do(p.cancel)
+	p.startOnce.Do(func() {
+		close(p.loopDone)
+	})
+	select {
+	case <-p.loopDone:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package profiling
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func assertGzipped(t testing.TB, b []byte) {
+	t.Helper()
+
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	require.NoError(t, err)
+
+	_, err = io.ReadAll(r)
+	require.NoError(t, err)
+}
+
+func TestProfilerConfigErrors(t *testing.T) {
+	mgr := mock.NewManager()
+
+	tests := map[string]func(c *Config){
+		"bad interval":         func(c *Config) { c.Interval = "nope" },
+		"cpu exceeds interval": func(c *Config) { c.CPUDuration = "2m" },
+		"no destination":       func(c *Config) { c.Output = "" },
+		"missing output":       func(c *Config) { c.Output = "does_not_exist" },
+		"bad profile type":     func(c *Config) { c.Profiles = []string{"nope"} },
+		"no profile types":     func(c *Config) { c.Profiles = nil },
+	}
+
+	mgr.Outputs["foo"] = mock.OutputWriter(func(ctx context.Context, t message.Transaction) error {
+		return nil
+	})
+
+	for name, fn := range tests {
+		fn := fn
+		t.Run(name, func(t *testing.T) {
+			conf := NewConfig()
+			conf.Output = "foo"
+			fn(&conf)
+			_, err := New(conf, mgr)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestProfilerShipToOutput(t *testing.T) {
+	mgr := mock.NewManager()
+
+	msgChan := make(chan message.Batch, 10)
+	mgr.Outputs["foo"] = mock.OutputWriter(func(ctx context.Context, t message.Transaction) error {
+		msgChan <- t.Payload
+		return t.Ack(ctx, nil)
+	})
+
+	conf := NewConfig()
+	conf.Interval = "50ms"
+	conf.CPUDuration = "10ms"
+	conf.Profiles = []string{ProfileCPU, ProfileGoroutine}
+	conf.Output = "foo"
+
+	p, err := New(conf, mgr)
+	require.NoError(t, err)
+	p.Start()
+
+	var types []string
+	for len(types) < 2 {
+		select {
+		case b := <-msgChan:
+			require.Len(t, b, 1)
+			assertGzipped(t, b.Get(0).AsBytes())
+
+			pType := b.Get(0).MetaGetStr("profile_type")
+			types = append(types, pType)
+			assert.Contains(t, b.Get(0).MetaGetStr("profile_filename"), pType+"-")
+			assert.NotEmpty(t, b.Get(0).MetaGetStr("profile_timestamp"))
+		case <-time.After(time.Second * 10):
+			t.Fatal("timed out waiting for profiles")
+		}
+	}
+	assert.Equal(t, []string{ProfileCPU, ProfileGoroutine}, types)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+	require.NoError(t, p.Close(ctx))
+}
+
+func TestProfilerShipToPyroscope(t *testing.T) {
+	reqChan := make(chan *http.Request, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("profile")
+		if assert.NoError(t, err) {
+			b, err := io.ReadAll(f)
+			require.NoError(t, err)
+			assertGzipped(t, b)
+		}
+		reqChan <- r
+	}))
+	defer ts.Close()
+
+	conf := NewConfig()
+	conf.Pyroscope.URL = ts.URL + "/"
+	conf.Pyroscope.ApplicationName = "foo"
+
+	p, err := New(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	prof, err := p.Capture(context.Background(), ProfileHeap)
+	require.NoError(t, err)
+	require.NoError(t, p.Ship(context.Background(), prof))
+
+	req := <-reqChan
+	assert.Equal(t, "/ingest", req.URL.Path)
+	assert.Equal(t, "foo{}", req.URL.Query().Get("name"))
+	assert.NotEmpty(t, req.URL.Query().Get("from"))
+	assert.NotEmpty(t, req.URL.Query().Get("until"))
+
+	// Closing a profiler that was never started must not block.
+	require.NoError(t, p.Close(context.Background()))
+}
@@ -0,0 +1,112 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// CompletionConfig contains configuration fields for callbacks that are fired
+// with a job report once a stream terminates.
+type CompletionConfig struct {
+	HookURL string `json:"hook_url" yaml:"hook_url"`
+	Output  string `json:"output" yaml:"output"`
+}
+
+// NewCompletionConfig creates a new completion config with default values.
+func NewCompletionConfig() CompletionConfig {
+	return CompletionConfig{
+		HookURL: "",
+		Output:  "",
+	}
+}
+
+// Enabled returns whether any completion callbacks are configured.
+func (c CompletionConfig) Enabled() bool {
+	return c.HookURL != "" || c.Output != ""
+}
+
+// CompletionFieldSpec returns a field spec for the completion configuration.
+func CompletionFieldSpec() docs.FieldSpec {
+	return docs.FieldObject(
+		"completion", "Configures callbacks that are fired with a JSON report of the job statistics once a pipeline terminates on its own accord, such as when a bounded input has been drained, so that external orchestrators can detect completion.",
+	).WithChildren(
+		docs.FieldString("hook_url", "A URL to send an HTTP POST request to with the job report as the JSON body.").HasDefault(""),
+		docs.FieldString("output", "The label of an [output resource](/docs/configuration/resources) to send the job report to as a JSON message, which can be used in order to write a marker object to a bucket. The metadata fields `job_succeeded` and `job_finished_at` are added to the message, which can be used in order to build object paths.").HasDefault(""),
+	).Advanced().AtVersion("4.24.0")
+}
+
+// SendCompletion fires the configured completion callbacks with a job report.
+func SendCompletion(ctx context.Context, conf CompletionConfig, report JobReport, mgr bundle.NewManagement) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job report: %w", err)
+	}
+
+	var errs []error
+	if conf.HookURL != "" {
+		if err := sendCompletionHook(ctx, conf.HookURL, body); err != nil {
+			errs = append(errs, fmt.Errorf("hook: %w", err))
+		}
+	}
+	if conf.Output != "" {
+		if err := sendCompletionOutput(ctx, conf.Output, report, body, mgr); err != nil {
+			errs = append(errs, fmt.Errorf("output resource: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sendCompletionHook(ctx context.Context, hookURL string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %v", res.StatusCode)
+	}
+	return nil
+}
+
+func sendCompletionOutput(ctx context.Context, name string, report JobReport, body []byte, mgr bundle.NewManagement) error {
+	part := message.NewPart(body)
+	part.MetaSetMut("job_succeeded", strconv.FormatBool(report.Succeeded))
+	part.MetaSetMut("job_finished_at", report.FinishedAt.UTC().Format(time.RFC3339))
+
+	resChan := make(chan error, 1)
+	tran := message.NewTransaction(message.Batch{part}, resChan)
+
+	var err error
+	if aerr := mgr.AccessOutput(ctx, name, func(o output.Sync) {
+		err = o.WriteTransaction(ctx, tran)
+	}); aerr != nil {
+		return aerr
+	}
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err = <-resChan:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	return err
+}
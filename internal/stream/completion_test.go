@@ -0,0 +1,80 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func TestSendCompletion(t *testing.T) {
+	hookBodies := make(chan []byte, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		hookBodies <- b
+	}))
+	defer ts.Close()
+
+	var outParts []*message.Part
+	mgr := mock.NewManager()
+	mgr.Outputs["marker"] = func(ctx context.Context, tran message.Transaction) error {
+		outParts = append(outParts, tran.Payload...)
+		return tran.Ack(ctx, nil)
+	}
+
+	conf := NewCompletionConfig()
+	assert.False(t, conf.Enabled())
+
+	conf.HookURL = ts.URL
+	conf.Output = "marker"
+	require.True(t, conf.Enabled())
+
+	finishedAt := time.Date(2023, 11, 2, 10, 14, 30, 0, time.UTC)
+	report := JobReport{
+		Succeeded:  true,
+		Drained:    true,
+		FinishedAt: finishedAt,
+		Received:   10,
+		Failures:   []JobFailure{},
+	}
+	require.NoError(t, SendCompletion(context.Background(), conf, report, mgr))
+
+	var hookReport JobReport
+	require.NoError(t, json.Unmarshal(<-hookBodies, &hookReport))
+	assert.Equal(t, report, hookReport)
+
+	require.Len(t, outParts, 1)
+
+	var outReport JobReport
+	require.NoError(t, json.Unmarshal(outParts[0].AsBytes(), &outReport))
+	assert.Equal(t, report, outReport)
+	assert.Equal(t, "true", outParts[0].MetaGetStr("job_succeeded"))
+	assert.Equal(t, "2023-11-02T10:14:30Z", outParts[0].MetaGetStr("job_finished_at"))
+}
+
+func TestSendCompletionErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	conf := NewCompletionConfig()
+	conf.HookURL = ts.URL
+	conf.Output = "nope"
+
+	err := SendCompletion(context.Background(), conf, JobReport{}, mock.NewManager())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hook: unexpected status code 502")
+	assert.Contains(t, err.Error(), "output resource: ")
+}
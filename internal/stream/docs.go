@@ -11,6 +11,18 @@ func Spec() docs.FieldSpecs {
 		docs.FieldBuffer("buffer", "An optional buffer to store messages during transit.").Optional(),
 		docs.FieldObject("pipeline", "Describes optional processing pipelines used for mutating messages.").WithChildren(
 			docs.FieldInt("threads", "The number of threads to execute processing pipelines across.").HasDefault(-1),
+			docs.FieldInterpolatedString(
+				"ordering_key",
+				"An optional key resolved against the first message of each batch. When set, batches that resolve to the same key are always processed by the same thread, which preserves their relative ordering when `threads` is greater than one.",
+				`${! meta("kafka_key") }`, `${! json("user.id") }`,
+			).Advanced().HasDefault(""),
+			docs.FieldString(
+				"split_ack_mode",
+				"Determines how messages that processors split into multiple batches, such as with the `split` processor, are acknowledged.",
+			).HasAnnotatedOptions(
+				"strict", "Batches derived from a message that are rejected by the output are retried individually until they succeed, and the processing thread waits for all of them before it continues. The source message is only acknowledged once every batch derived from it has been delivered.",
+				"best_effort", "Batches derived from a message are not tracked for retries, and the source message is resolved as soon as every batch derived from it has responded, whether or not they were delivered. When any of them are rejected the source message is rejected with the first error, leaving redelivery to the input. This frees up processing threads sooner but may result in duplicates of the batches that were delivered.",
+			).Advanced().HasDefault("strict").AtVersion("4.24.0"),
 			docs.FieldProcessor("processors", "A list of processors to apply to messages.").Array().HasDefault([]any{}),
 		),
 		docs.FieldOutput("output", "An output to sink messages to.").Optional(),
@@ -0,0 +1,169 @@
+package stream
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// Messages sent by an output that is a secondary tier of a fallback broker are
+// considered dead lettered.
+var deadLetterPathRegex = regexp.MustCompile(`\.fallback\.[1-9][0-9]*(\.|$)`)
+
+// IsDeadLetterPath returns true if the provided component path belongs to an
+// output that only receives messages that failed to be delivered elsewhere,
+// such as the secondary tiers of a fallback broker.
+func IsDeadLetterPath(path string) bool {
+	return deadLetterPathRegex.MatchString(path)
+}
+
+// The maximum number of distinct failure messages included within a job
+// report.
+const jobReportMaxFailures = 10
+
+// OptJobTracker sets a job tracker that keeps track of all messages consumed
+// by the stream and their acknowledgements.
+func OptJobTracker(j *JobTracker) func(*Type) {
+	return func(t *Type) {
+		t.jobTracker = j
+	}
+}
+
+// JobFailure describes a distinct error that caused messages to be rejected
+// during a job.
+type JobFailure struct {
+	Error string `json:"error"`
+	Count int64  `json:"count"`
+}
+
+// JobReport summarises the execution of a stream that is run as a job.
+type JobReport struct {
+	Succeeded       bool         `json:"succeeded"`
+	Drained         bool         `json:"drained"`
+	StartedAt       time.Time    `json:"started_at"`
+	FinishedAt      time.Time    `json:"finished_at"`
+	Duration        string       `json:"duration"`
+	Received        int64        `json:"received"`
+	Acknowledged    int64        `json:"acknowledged"`
+	Rejected        int64        `json:"rejected"`
+	DeadLettered    int64        `json:"dead_lettered"`
+	Pending         int64        `json:"pending"`
+	ProcessorErrors int64        `json:"processor_errors"`
+	OutputErrors    int64        `json:"output_errors"`
+	Failures        []JobFailure `json:"failures"`
+}
+
+// JobTracker keeps track of the messages consumed by a stream and their
+// acknowledgements in order to determine whether a job completed successfully.
+type JobTracker struct {
+	started time.Time
+	local   *metrics.Local
+
+	mut          sync.Mutex
+	received     int64
+	acknowledged int64
+	rejected     int64
+	failures     map[string]int64
+}
+
+// NewJobTracker creates a job tracker. The provided local metrics should be
+// added to the metrics of the tracked stream, and are used for counting dead
+// lettered messages and errors.
+func NewJobTracker(local *metrics.Local) *JobTracker {
+	return &JobTracker{
+		started:  time.Now(),
+		local:    local,
+		failures: map[string]int64{},
+	}
+}
+
+// Metrics returns the local metrics of the job tracker.
+func (j *JobTracker) Metrics() *metrics.Local {
+	return j.local
+}
+
+// track returns a transaction channel that forwards transactions from the
+// provided channel, and keeps track of their acknowledgements.
+func (j *JobTracker) track(in <-chan message.Transaction) <-chan message.Transaction {
+	return trackTransactions(in, func(tran message.Transaction) func(context.Context, error) error {
+		count := int64(tran.Payload.Len())
+
+		j.mut.Lock()
+		j.received += count
+		j.mut.Unlock()
+
+		return func(ctx context.Context, err error) error {
+			j.mut.Lock()
+			if err != nil {
+				j.rejected += count
+				j.failures[err.Error()] += count
+			} else {
+				j.acknowledged += count
+			}
+			j.mut.Unlock()
+			return tran.Ack(ctx, err)
+		}
+	})
+}
+
+// Report returns a summary of the job so far. The terminated argument
+// indicates whether the stream ended on its own accord rather than being
+// stopped, which along with the absence of pending messages means that the
+// input was fully drained. A job is considered successful when it was fully
+// drained and no messages were rejected or dead lettered.
+func (j *JobTracker) Report(terminated bool) JobReport {
+	j.mut.Lock()
+	defer j.mut.Unlock()
+
+	finished := time.Now()
+	r := JobReport{
+		StartedAt:    j.started,
+		FinishedAt:   finished,
+		Duration:     finished.Sub(j.started).String(),
+		Received:     j.received,
+		Acknowledged: j.acknowledged,
+		Rejected:     j.rejected,
+		Pending:      j.received - j.acknowledged - j.rejected,
+		Failures:     []JobFailure{},
+	}
+	r.Drained = terminated && r.Pending == 0
+
+	if j.local != nil {
+		for k, v := range j.local.GetCounters() {
+			name, tagNames, tagValues := metrics.ReverseLabelledPath(k)
+			switch name {
+			case "processor_error":
+				r.ProcessorErrors += v
+			case "output_error":
+				r.OutputErrors += v
+			case "output_sent":
+				for i, tName := range tagNames {
+					if tName == "path" && IsDeadLetterPath(tagValues[i]) {
+						r.DeadLettered += v
+					}
+				}
+			}
+		}
+	}
+
+	for e, c := range j.failures {
+		r.Failures = append(r.Failures, JobFailure{Error: e, Count: c})
+	}
+	sort.Slice(r.Failures, func(i, k int) bool {
+		if r.Failures[i].Count == r.Failures[k].Count {
+			return r.Failures[i].Error < r.Failures[k].Error
+		}
+		return r.Failures[i].Count > r.Failures[k].Count
+	})
+	if len(r.Failures) > jobReportMaxFailures {
+		r.Failures = r.Failures[:jobReportMaxFailures]
+	}
+
+	r.Succeeded = r.Drained && r.Rejected == 0 && r.DeadLettered == 0
+	return r
+}
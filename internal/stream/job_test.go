@@ -0,0 +1,80 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func TestJobTrackerReport(t *testing.T) {
+	local := metrics.NewLocal()
+	j := NewJobTracker(local)
+
+	in := make(chan message.Transaction)
+	out := j.track(in)
+
+	var trans []message.Transaction
+	for _, b := range [][][]byte{
+		{[]byte("a"), []byte("b")},
+		{[]byte("c")},
+		{[]byte("d")},
+		{[]byte("e")},
+	} {
+		in <- message.NewTransaction(message.QuickBatch(b), make(chan error, 1))
+		trans = append(trans, <-out)
+	}
+
+	require.NoError(t, trans[0].Ack(context.Background(), nil))
+	require.NoError(t, trans[1].Ack(context.Background(), errors.New("nope")))
+	require.NoError(t, trans[2].Ack(context.Background(), errors.New("nope")))
+
+	report := j.Report(true)
+	assert.False(t, report.Succeeded)
+	assert.False(t, report.Drained)
+	assert.Equal(t, int64(5), report.Received)
+	assert.Equal(t, int64(2), report.Acknowledged)
+	assert.Equal(t, int64(2), report.Rejected)
+	assert.Equal(t, int64(1), report.Pending)
+	assert.Equal(t, []JobFailure{{Error: "nope", Count: 2}}, report.Failures)
+
+	require.NoError(t, trans[3].Ack(context.Background(), nil))
+
+	report = j.Report(true)
+	assert.False(t, report.Succeeded)
+	assert.True(t, report.Drained)
+	assert.Equal(t, int64(0), report.Pending)
+}
+
+func TestJobTrackerSuccess(t *testing.T) {
+	local := metrics.NewLocal()
+	j := NewJobTracker(local)
+
+	in := make(chan message.Transaction)
+	out := j.track(in)
+
+	in <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("a")}), make(chan error, 1))
+	tran := <-out
+	require.NoError(t, tran.Ack(context.Background(), nil))
+
+	report := j.Report(false)
+	assert.False(t, report.Succeeded)
+	assert.False(t, report.Drained)
+
+	report = j.Report(true)
+	assert.True(t, report.Succeeded)
+	assert.True(t, report.Drained)
+	assert.Equal(t, []JobFailure{}, report.Failures)
+
+	local.GetCounterVec("output_sent", "label", "path").With("", "root.output.fallback.1").Incr(1)
+	local.GetCounterVec("output_sent", "label", "path").With("", "root.output.fallback.0").Incr(3)
+
+	report = j.Report(true)
+	assert.False(t, report.Succeeded)
+	assert.Equal(t, int64(1), report.DeadLettered)
+}
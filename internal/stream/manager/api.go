@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 
@@ -41,6 +42,12 @@ func (m *Type) registerEndpoints(enableCrud bool) {
 		"POST: Create or replace a given resource configuration of a specified type. Types supported are `cache`, `input`, `output`, `processor` and `rate_limit`.",
 		m.HandleResourceCRUD,
 	)
+	m.manager.RegisterEndpoint(
+		"/bulk/streams",
+		"POST: Create, update and delete multiple streams in a single request,"+
+			" where no changes are made unless all operations are valid.",
+		m.HandleStreamsBulk,
+	)
 	m.manager.RegisterEndpoint(
 		"/streams/{id}/stats",
 		"GET a structured JSON object containing metrics for the stream.",
@@ -261,6 +268,138 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+type bulkRequest struct {
+	Create map[string]yaml.Node `yaml:"create"`
+	Update map[string]yaml.Node `yaml:"update"`
+	Delete []string             `yaml:"delete"`
+}
+
+type bulkResponse struct {
+	DryRun     bool                `json:"dry_run,omitempty"`
+	Created    []string            `json:"created"`
+	Updated    []string            `json:"updated"`
+	Deleted    []string            `json:"deleted"`
+	LintErrors map[string][]string `json:"lint_errors,omitempty"`
+	Errors     []string            `json:"errors,omitempty"`
+}
+
+// HandleStreamsBulk is an http.HandleFunc for creating, updating and deleting
+// multiple streams within a single request. All stream configs are linted and
+// all operations are validated before any changes are made, and when the URL
+// param `dry_run` is `true` the results of validation are returned without
+// making changes.
+func (m *Type) HandleStreamsBulk(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if serverErr != nil {
+			m.manager.Logger().Errorf("Streams bulk Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debugf("Streams request bulk Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	if r.Method != "POST" {
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+		return
+	}
+
+	ignoreLints := r.URL.Query().Get("chilled") == "true"
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	var reqBytes []byte
+	if reqBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+		return
+	}
+	if reqBytes, requestErr = config.ReplaceEnvVariables(reqBytes, os.LookupEnv); requestErr != nil {
+		var errEnvMissing *config.ErrMissingEnvVars
+		if !ignoreLints || !errors.As(requestErr, &errEnvMissing) {
+			return
+		}
+		reqBytes = errEnvMissing.BestAttempt
+		requestErr = nil
+	}
+
+	var req bulkRequest
+	if requestErr = yaml.Unmarshal(reqBytes, &req); requestErr != nil {
+		return
+	}
+
+	res := bulkResponse{
+		DryRun:     dryRun,
+		Created:    []string{},
+		Updated:    []string{},
+		Deleted:    []string{},
+		LintErrors: map[string][]string{},
+	}
+
+	op := BulkOperation{
+		Create: map[string]stream.Config{},
+		Update: map[string]stream.Config{},
+		Delete: req.Delete,
+	}
+	decodeConfigs := func(nodes map[string]yaml.Node, confs map[string]stream.Config) {
+		for id, n := range nodes {
+			n := n
+			if !ignoreLints {
+				if lints := m.lintStreamConfigNode(&n); len(lints) > 0 {
+					res.LintErrors[id] = lints
+				}
+			}
+			conf := stream.NewConfig()
+			if err := n.Decode(&conf); err != nil {
+				res.Errors = append(res.Errors, fmt.Sprintf("stream '%v': %v", id, err))
+				continue
+			}
+			confs[id] = conf
+		}
+	}
+	decodeConfigs(req.Create, op.Create)
+	decodeConfigs(req.Update, op.Update)
+
+	for _, err := range m.Validate(op) {
+		res.Errors = append(res.Errors, err.Error())
+	}
+	sort.Strings(res.Errors)
+
+	res.Created = append(res.Created, sortedConfigIDs(op.Create)...)
+	res.Updated = append(res.Updated, sortedConfigIDs(op.Update)...)
+	res.Deleted = append(res.Deleted, op.Delete...)
+
+	writeRes := func(status int) {
+		resBytes, err := json.Marshal(res)
+		if err != nil {
+			serverErr = err
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write(resBytes)
+	}
+
+	if dryRun {
+		writeRes(http.StatusOK)
+		return
+	}
+	if len(res.LintErrors) > 0 || len(res.Errors) > 0 {
+		res.Created, res.Updated, res.Deleted = []string{}, []string{}, []string{}
+		writeRes(http.StatusBadRequest)
+		return
+	}
+
+	if serverErr = m.Apply(r.Context(), op); serverErr != nil {
+		return
+	}
+	writeRes(http.StatusOK)
+}
+
 // HandleStreamCRUD is an http.HandleFunc for performing CRUD operations on
 // individual streams.
 func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
@@ -382,11 +521,13 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 				Active    bool    `json:"active"`
 				Uptime    float64 `json:"uptime"`
 				UptimeStr string  `json:"uptime_str"`
+				Stats     Stats   `json:"stats"`
 				Config    any     `json:"config"`
 			}{
 				Active:    info.IsRunning(),
 				Uptime:    info.Uptime().Seconds(),
 				UptimeStr: info.Uptime().String(),
+				Stats:     info.Stats(),
 				Config:    sanit,
 			}); serverErr != nil {
 				return
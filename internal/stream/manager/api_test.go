@@ -2,6 +2,7 @@ package manager_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -37,6 +38,7 @@ func router(m *manager.Type) *mux.Router {
 	router.HandleFunc("/streams/{id}", m.HandleStreamCRUD)
 	router.HandleFunc("/streams/{id}/stats", m.HandleStreamStats)
 	router.HandleFunc("/resources/{type}/{id}", m.HandleResourceCRUD)
+	router.HandleFunc("/bulk/streams", m.HandleStreamsBulk)
 	return router
 }
 
@@ -536,6 +538,216 @@ func TestTypeAPISetStreams(t *testing.T) {
 	assert.Equal(t, "root = this.BAZ_ONE", gabs.Wrap(conf.Config).S("input", "generate", "mapping").Data())
 }
 
+type bulkBody struct {
+	DryRun     bool                `json:"dry_run"`
+	Created    []string            `json:"created"`
+	Updated    []string            `json:"updated"`
+	Deleted    []string            `json:"deleted"`
+	LintErrors map[string][]string `json:"lint_errors"`
+	Errors     []string            `json:"errors"`
+}
+
+func parseBulkBody(t *testing.T, data *bytes.Buffer) bulkBody {
+	t.Helper()
+	result := bulkBody{}
+	require.NoError(t, json.Unmarshal(data.Bytes(), &result), data.String())
+	return result
+}
+
+func TestTypeAPIBulkStreams(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+
+	r := router(mgr)
+
+	origConf := stream.NewConfig()
+	origConf.Input.Type = "generate"
+	origConf.Input.Generate.Mapping = "root = deleted()"
+	origConf.Output.Type = "drop"
+
+	require.NoError(t, mgr.Create("foo", origConf))
+	require.NoError(t, mgr.Create("bar", origConf))
+
+	barConf := harmlessConf()
+	_, _ = gabs.Wrap(barConf).Set("root = this.BAR_ONE", "input", "generate", "mapping")
+	bazConf := harmlessConf()
+	_, _ = gabs.Wrap(bazConf).Set("root = this.BAZ_ONE", "input", "generate", "mapping")
+
+	body := map[string]any{
+		"create": map[string]any{"baz": bazConf},
+		"update": map[string]any{"bar": barConf},
+		"delete": []string{"foo"},
+	}
+
+	request := genRequest("POST", "/bulk/streams?dry_run=true", body)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	bRes := parseBulkBody(t, response.Body)
+	assert.True(t, bRes.DryRun)
+	assert.Equal(t, []string{"baz"}, bRes.Created)
+	assert.Equal(t, []string{"bar"}, bRes.Updated)
+	assert.Equal(t, []string{"foo"}, bRes.Deleted)
+	assert.Empty(t, bRes.LintErrors)
+	assert.Empty(t, bRes.Errors)
+
+	// A dry run makes no changes.
+	request = genRequest("GET", "/streams", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	info := parseListBody(response.Body)
+	assert.Contains(t, info, "foo")
+	assert.NotContains(t, info, "baz")
+
+	request = genRequest("POST", "/bulk/streams", body)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	bRes = parseBulkBody(t, response.Body)
+	assert.False(t, bRes.DryRun)
+	assert.Equal(t, []string{"baz"}, bRes.Created)
+
+	request = genRequest("GET", "/streams", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	info = parseListBody(response.Body)
+	assert.NotContains(t, info, "foo")
+	assert.Contains(t, info, "bar")
+	assert.Contains(t, info, "baz")
+
+	request = genRequest("GET", "/streams/bar", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	conf := parseGetBody(t, response.Body)
+	assert.Equal(t, "root = this.BAR_ONE", gabs.Wrap(conf.Config).S("input", "generate", "mapping").Data())
+}
+
+func TestTypeAPIBulkStreamsInvalid(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+
+	r := router(mgr)
+
+	origConf := stream.NewConfig()
+	origConf.Input.Type = "generate"
+	origConf.Input.Generate.Mapping = "root = deleted()"
+	origConf.Output.Type = "drop"
+
+	require.NoError(t, mgr.Create("foo", origConf))
+
+	body := `
+create:
+  bar:
+    input:
+      generate:
+        mapping: 'root = deleted()'
+    output:
+      drop: {}
+  baz:
+    input:
+      generate:
+        mapping: 'root = deleted()'
+        nope: true
+    output:
+      drop: {}
+update:
+  nope:
+    input:
+      generate:
+        mapping: 'root = deleted()'
+    output:
+      drop: {}
+delete: [ foo, bar ]
+`
+
+	request := genYAMLRequest("POST", "/bulk/streams", body)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	bRes := parseBulkBody(t, response.Body)
+	assert.Empty(t, bRes.Created)
+	assert.Equal(t, map[string][]string{
+		"baz": {"(13,1) field nope not recognised"},
+	}, bRes.LintErrors)
+	assert.Equal(t, []string{
+		"stream 'bar': targeted by more than one operation",
+		"stream 'nope': stream does not exist",
+	}, bRes.Errors)
+
+	// Nothing is applied when any operation is invalid.
+	request = genRequest("GET", "/streams", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	info := parseListBody(response.Body)
+	assert.Contains(t, info, "foo")
+	assert.NotContains(t, info, "bar")
+	assert.NotContains(t, info, "baz")
+
+	request = genYAMLRequest("POST", "/bulk/streams?dry_run=true", body)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	bRes = parseBulkBody(t, response.Body)
+	assert.True(t, bRes.DryRun)
+	assert.Len(t, bRes.LintErrors, 1)
+	assert.Len(t, bRes.Errors, 2)
+}
+
+func TestTypeBulkApplyRollback(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+
+	goodConf := stream.NewConfig()
+	goodConf.Input.Type = "generate"
+	goodConf.Input.Generate.Mapping = "root = deleted()"
+	goodConf.Output.Type = "drop"
+
+	badConf := stream.NewConfig()
+	badConf.Input.Type = "generate"
+	badConf.Input.Generate.Mapping = "root = not a valid mapping"
+	badConf.Output.Type = "drop"
+
+	require.NoError(t, mgr.Create("foo", goodConf))
+
+	updatedConf := goodConf
+	updatedConf.Input.Generate.Mapping = `root = "updated"`
+
+	err = mgr.Apply(context.Background(), manager.BulkOperation{
+		Create: map[string]stream.Config{"bar": goodConf, "baz": badConf},
+		Update: map[string]stream.Config{"foo": updatedConf},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create stream 'baz'")
+
+	_, err = mgr.Read("bar")
+	assert.ErrorIs(t, err, manager.ErrStreamDoesNotExist)
+
+	err = mgr.Apply(context.Background(), manager.BulkOperation{
+		Create: map[string]stream.Config{"bar": goodConf},
+		Update: map[string]stream.Config{"foo": badConf},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to update stream 'foo'")
+
+	_, err = mgr.Read("bar")
+	assert.ErrorIs(t, err, manager.ErrStreamDoesNotExist)
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "root = deleted()", info.Config().Input.Generate.Mapping)
+	assert.True(t, info.IsRunning())
+}
+
 func TestTypeAPIStreamsDefaultConf(t *testing.T) {
 	res, err := bmanager.New(bmanager.NewResourceConfig())
 	require.NoError(t, err)
@@ -842,6 +1054,61 @@ func TestTypeAPIGetStats(t *testing.T) {
 	assert.Greater(t, len(stats.ChildrenMap()), 0, response.Body.String())
 }
 
+func TestTypeAPIGetStreamStats(t *testing.T) {
+	mgr, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	smgr := manager.New(mgr)
+
+	r := router(smgr)
+
+	request := genYAMLRequest("POST", "/streams/foo", `
+input:
+  generate:
+    count: 5
+    interval: ""
+    mapping: 'root = "hello world"'
+pipeline:
+  threads: 4
+  processors:
+    - mapping: 'root = this'
+    - mapping: 'root = content()'
+output:
+  fallback:
+    - reject: nope
+    - drop: {}
+`)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	assert.Eventually(t, func() bool {
+		request = genRequest("GET", "/streams/foo", nil)
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		if response.Code != http.StatusOK {
+			return false
+		}
+		info, err := gabs.ParseJSON(response.Body.Bytes())
+		if err != nil {
+			return false
+		}
+		v, _ := info.Path("stats.output.dead_lettered").Data().(float64)
+		return v == 5
+	}, time.Second*5, time.Millisecond*50)
+
+	info, err := gabs.ParseJSON(response.Body.Bytes())
+	require.NoError(t, err)
+
+	assert.Equal(t, 5.0, info.Path("stats.input.received").Data(), response.Body.String())
+	assert.Equal(t, 5.0, info.Path("stats.output.sent").Data(), response.Body.String())
+	assert.Equal(t, 5.0, info.Path("stats.output.errors").Data(), response.Body.String())
+	assert.Equal(t, 5.0, info.Path("stats.processor.received").Data(), response.Body.String())
+	assert.Equal(t, 5.0, info.Path("stats.processor.sent").Data(), response.Body.String())
+	assert.Equal(t, 5.0, info.Path("stats.processor.errors").Data(), response.Body.String())
+	assert.IsType(t, true, info.Path("stats.output.connected").Data(), response.Body.String())
+}
+
 func TestTypeAPISetResources(t *testing.T) {
 	bmgr, err := bmanager.New(bmanager.NewResourceConfig())
 	require.NoError(t, err)
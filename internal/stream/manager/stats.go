@@ -0,0 +1,109 @@
+package manager
+
+import (
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+)
+
+// InputStats contains aggregated statistics for the input layer of a stream.
+type InputStats struct {
+	Connected          bool  `json:"connected"`
+	Received           int64 `json:"received"`
+	ConnectionFailures int64 `json:"connection_failures"`
+	ConnectionsLost    int64 `json:"connections_lost"`
+}
+
+// ProcessorStats contains aggregated statistics for the processors of a
+// stream, where messages are counted as they enter the first processor of the
+// pipeline and leave the last, and errors are counted once by the pipeline
+// processor that raised them.
+type ProcessorStats struct {
+	Received int64 `json:"received"`
+	Sent     int64 `json:"sent"`
+	Errors   int64 `json:"errors"`
+}
+
+// OutputStats contains aggregated statistics for the output layer of a stream.
+type OutputStats struct {
+	Connected          bool  `json:"connected"`
+	Sent               int64 `json:"sent"`
+	Errors             int64 `json:"errors"`
+	DeadLettered       int64 `json:"dead_lettered"`
+	ConnectionFailures int64 `json:"connection_failures"`
+	ConnectionsLost    int64 `json:"connections_lost"`
+}
+
+// Stats contains a summary of the metrics of a stream, aggregated across all
+// components of each layer regardless of their labels.
+type Stats struct {
+	Input     InputStats     `json:"input"`
+	Processor ProcessorStats `json:"processor"`
+	Output    OutputStats    `json:"output"`
+}
+
+// Stats returns a summary of the current metrics of the stream.
+func (s *StreamStatus) Stats() Stats {
+	var stats Stats
+	if s.strm != nil {
+		stats.Input.Connected, stats.Output.Connected = s.strm.Connected()
+	}
+
+	// Each message passes through every processor of the pipeline, and so the
+	// counters of a single processor are used for each direction rather than
+	// a sum of all of them.
+	var firstProc, lastProc string
+	pipelineProcs := map[string]struct{}{}
+	for i, pConf := range s.config.Pipeline.Processors {
+		path := "root.pipeline.processors." + bundle.PathSegment(i, pConf.Label)
+		if i == 0 {
+			firstProc = path
+		}
+		lastProc = path
+		pipelineProcs[path] = struct{}{}
+	}
+
+	for k, v := range s.metrics.GetCounters() {
+		name, tagNames, tagValues := metrics.ReverseLabelledPath(k)
+
+		var path string
+		for i, tName := range tagNames {
+			if tName == "path" {
+				path = tagValues[i]
+			}
+		}
+
+		switch name {
+		case "input_received":
+			stats.Input.Received += v
+		case "input_connection_failed":
+			stats.Input.ConnectionFailures += v
+		case "input_connection_lost":
+			stats.Input.ConnectionsLost += v
+		case "processor_received":
+			if path == firstProc {
+				stats.Processor.Received += v
+			}
+		case "processor_sent":
+			if path == lastProc {
+				stats.Processor.Sent += v
+			}
+		case "processor_error":
+			if _, exists := pipelineProcs[path]; exists {
+				stats.Processor.Errors += v
+			}
+		case "output_sent":
+			stats.Output.Sent += v
+			if stream.IsDeadLetterPath(path) {
+				stats.Output.DeadLettered += v
+			}
+		case "output_error":
+			stats.Output.Errors += v
+		case "output_connection_failed":
+			stats.Output.ConnectionFailures += v
+		case "output_connection_lost":
+			stats.Output.ConnectionsLost += v
+		}
+	}
+	return stats
+}
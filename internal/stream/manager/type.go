@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -89,6 +90,9 @@ type Type struct {
 	apiEnabled bool
 
 	lock sync.Mutex
+
+	// applyLock prevents bulk operations from interleaving.
+	applyLock sync.Mutex
 }
 
 // New creates a new stream manager.Type.
@@ -228,6 +232,139 @@ func (m *Type) Delete(ctx context.Context, id string) error {
 
 //------------------------------------------------------------------------------
 
+// BulkOperation describes a set of streams to create, update and delete as a
+// single operation.
+type BulkOperation struct {
+	Create map[string]stream.Config
+	Update map[string]stream.Config
+	Delete []string
+}
+
+// Validate checks that a bulk operation can be applied to the current set of
+// streams, where streams being created must not exist, streams being updated
+// or deleted must exist, and each stream is only targeted by one operation.
+func (m *Type) Validate(op BulkOperation) []error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return []error{component.ErrTypeClosed}
+	}
+
+	var errs []error
+	seen := map[string]struct{}{}
+	checkSeen := func(id string) bool {
+		if _, exists := seen[id]; exists {
+			errs = append(errs, fmt.Errorf("stream '%v': targeted by more than one operation", id))
+			return false
+		}
+		seen[id] = struct{}{}
+		return true
+	}
+
+	for _, id := range sortedConfigIDs(op.Create) {
+		if !checkSeen(id) {
+			continue
+		}
+		if _, exists := m.streams[id]; exists {
+			errs = append(errs, fmt.Errorf("stream '%v': %w", id, ErrStreamExists))
+		}
+	}
+	for _, id := range sortedConfigIDs(op.Update) {
+		if !checkSeen(id) {
+			continue
+		}
+		if _, exists := m.streams[id]; !exists {
+			errs = append(errs, fmt.Errorf("stream '%v': %w", id, ErrStreamDoesNotExist))
+		}
+	}
+	for _, id := range op.Delete {
+		if !checkSeen(id) {
+			continue
+		}
+		if _, exists := m.streams[id]; !exists {
+			errs = append(errs, fmt.Errorf("stream '%v': %w", id, ErrStreamDoesNotExist))
+		}
+	}
+	return errs
+}
+
+// Apply validates and then executes a bulk operation. Streams are created and
+// updated first, and if any of these fail then the changes made so far are
+// rolled back and an error is returned. Streams are deleted once all creates
+// and updates have succeeded.
+func (m *Type) Apply(ctx context.Context, op BulkOperation) error {
+	m.applyLock.Lock()
+	defer m.applyLock.Unlock()
+
+	if errs := m.Validate(op); len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	var created []string
+	previous := map[string]stream.Config{}
+
+	rollback := func() {
+		for _, id := range created {
+			if err := m.Delete(ctx, id); err != nil {
+				m.manager.Logger().Errorf("Failed to roll back creation of stream '%v': %v", id, err)
+			}
+		}
+		for id, conf := range previous {
+			if err := m.Update(ctx, id, conf); err != nil {
+				m.manager.Logger().Errorf("Failed to roll back update of stream '%v': %v", id, err)
+			}
+		}
+	}
+
+	for _, id := range sortedConfigIDs(op.Create) {
+		if err := m.Create(id, op.Create[id]); err != nil {
+			rollback()
+			return fmt.Errorf("failed to create stream '%v': %w", id, err)
+		}
+		created = append(created, id)
+	}
+
+	for _, id := range sortedConfigIDs(op.Update) {
+		info, err := m.Read(id)
+		if err == nil {
+			prev := info.Config()
+			if err = m.Update(ctx, id, op.Update[id]); err == nil {
+				previous[id] = prev
+				continue
+			}
+			// A failed update might have removed the stream, in which case the
+			// previous config is restored.
+			if _, rerr := m.Read(id); errors.Is(rerr, ErrStreamDoesNotExist) {
+				if cerr := m.Create(id, prev); cerr != nil {
+					m.manager.Logger().Errorf("Failed to roll back update of stream '%v': %v", id, cerr)
+				}
+			}
+		}
+		rollback()
+		return fmt.Errorf("failed to update stream '%v': %w", id, err)
+	}
+
+	var errs []error
+	for _, id := range op.Delete {
+		if err := m.Delete(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete stream '%v': %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sortedConfigIDs(confs map[string]stream.Config) []string {
+	ids := make([]string, 0, len(confs))
+	for id := range confs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+//------------------------------------------------------------------------------
+
 // Stop attempts to gracefully shut down all active streams and close the
 // stream manager.
 func (m *Type) Stop(ctx context.Context) error {
@@ -0,0 +1,152 @@
+package stream
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/component"
+)
+
+const (
+	statusConnected    = "connected"
+	statusDegraded     = "degraded"
+	statusDisconnected = "disconnected"
+)
+
+// OptReadiness determines how the readiness of the stream is derived from the
+// connection status of its components. When strict is true the stream is not
+// ready when any output is disconnected. Components that have lost their
+// connection for less than the grace period are considered degraded, which
+// does not fail readiness.
+func OptReadiness(strict bool, gracePeriod time.Duration) func(*Type) {
+	return func(t *Type) {
+		t.readinessStrict = strict
+		t.readinessGrace = gracePeriod
+	}
+}
+
+type componentReadiness struct {
+	Type      string    `json:"type"`
+	Label     string    `json:"label"`
+	Path      string    `json:"path"`
+	Status    string    `json:"status"`
+	LastError string    `json:"last_error,omitempty"`
+	Since     time.Time `json:"since"`
+}
+
+type readinessReport struct {
+	Ready      bool                 `json:"ready"`
+	Components []componentReadiness `json:"components"`
+
+	inputConnected  bool
+	outputConnected bool
+}
+
+type connectionStatusProvider interface {
+	ConnectionStatuses() []component.ConnectionStatus
+}
+
+func (t *Type) componentStatus(s component.ConnectionStatus, now time.Time) string {
+	if s.Connected {
+		return statusConnected
+	}
+	if t.readinessGrace > 0 && s.HasConnected && now.Sub(s.Since) < t.readinessGrace {
+		return statusDegraded
+	}
+	return statusDisconnected
+}
+
+func (t *Type) readiness() readinessReport {
+	report := readinessReport{
+		inputConnected:  t.inputLayer.Connected(),
+		outputConnected: t.outputLayer.Connected(),
+		Components:      []componentReadiness{},
+	}
+
+	var statuses []component.ConnectionStatus
+	if p, ok := t.manager.(connectionStatusProvider); ok {
+		statuses = p.ConnectionStatuses()
+	}
+
+	now := time.Now()
+	seenKind := map[string]bool{}
+	disconnectedKind := map[string]bool{}
+	for _, s := range statuses {
+		c := componentReadiness{
+			Type:   s.Kind,
+			Label:  s.Label,
+			Path:   "root",
+			Status: t.componentStatus(s, now),
+			Since:  s.Since.UTC(),
+		}
+		if len(s.Path) > 0 {
+			c.Path = "root." + query.SliceToDotPath(s.Path...)
+		}
+		if s.Err != nil {
+			c.LastError = s.Err.Error()
+		}
+		seenKind[s.Kind] = true
+		if c.Status == statusDisconnected {
+			disconnectedKind[s.Kind] = true
+		}
+		report.Components = append(report.Components, c)
+	}
+
+	// With a grace period the layers are considered connected when they have
+	// components and none of them are fully disconnected.
+	if t.readinessGrace > 0 {
+		if !report.inputConnected && seenKind["input"] && !disconnectedKind["input"] {
+			report.inputConnected = true
+		}
+		if !report.outputConnected && seenKind["output"] && !disconnectedKind["output"] {
+			report.outputConnected = true
+		}
+	}
+	if t.readinessStrict && disconnectedKind["output"] {
+		report.outputConnected = false
+	}
+
+	report.Ready = report.inputConnected && report.outputConnected
+	return report
+}
+
+func wantsJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "json" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+func (t *Type) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadUint32(&t.closed) == 1 {
+		http.Error(w, "Stream terminated", http.StatusNotFound)
+		return
+	}
+
+	report := t.readiness()
+	if wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		if !report.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	if report.Ready {
+		_, _ = w.Write([]byte("OK"))
+		return
+	}
+
+	w.WriteHeader(http.StatusServiceUnavailable)
+	if !report.inputConnected {
+		_, _ = w.Write([]byte("input not connected\n"))
+	}
+	if !report.outputConnected {
+		_, _ = w.Write([]byte("output not connected\n"))
+	}
+}
@@ -0,0 +1,233 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// Actions that can be taken when the drain timeout of a shutdown is reached.
+const (
+	ShutdownOnTimeoutForce    = "force"
+	ShutdownOnTimeoutNack     = "nack"
+	ShutdownOnTimeoutComplete = "complete"
+)
+
+// ErrShutdownDrainTimeout is the error used for rejecting in-flight messages
+// when the drain timeout of a shutdown is reached.
+var ErrShutdownDrainTimeout = errors.New("shutdown drain timeout reached before message was delivered")
+
+// ShutdownLayerTimeouts contains the maximum periods of time to wait for each
+// layer of a stream to close during an ordered shutdown.
+type ShutdownLayerTimeouts struct {
+	Input  string `json:"input" yaml:"input"`
+	Buffer string `json:"buffer" yaml:"buffer"`
+	Output string `json:"output" yaml:"output"`
+}
+
+// ShutdownConfig contains configuration fields that determine how a stream
+// drains in-flight messages when it is shut down.
+type ShutdownConfig struct {
+	DrainTimeout  string                `json:"drain_timeout" yaml:"drain_timeout"`
+	OnTimeout     string                `json:"on_timeout" yaml:"on_timeout"`
+	LayerTimeouts ShutdownLayerTimeouts `json:"layer_timeouts" yaml:"layer_timeouts"`
+}
+
+// NewShutdownConfig creates a new shutdown config with default values.
+func NewShutdownConfig() ShutdownConfig {
+	return ShutdownConfig{
+		DrainTimeout: "",
+		OnTimeout:    ShutdownOnTimeoutForce,
+		LayerTimeouts: ShutdownLayerTimeouts{
+			Input:  "",
+			Buffer: "",
+			Output: "",
+		},
+	}
+}
+
+// ShutdownFieldSpec returns a field spec for the shutdown configuration.
+func ShutdownFieldSpec() docs.FieldSpec {
+	return docs.FieldObject(
+		"shutdown", "Configures how in-flight messages are drained when the pipeline is shut down. Layers are always closed in order, starting with the input, followed by the buffer and finally the processors and output, with each layer given the opportunity to finish processing before the next is closed.",
+	).WithChildren(
+		docs.FieldString("drain_timeout", "The maximum period of time to wait for in-flight messages to be delivered during an ordered shutdown before the `on_timeout` action is taken. When empty the drain timeout is three quarters of the `shutdown_timeout`. This should be less than the `shutdown_timeout`, which remains a hard deadline for the whole process. When `on_timeout` is `nack` or `complete` the drain also waits for every message consumed by the input to be acknowledged.", "10s", "1m").HasDefault(""),
+		docs.FieldString("on_timeout", "The action to take when the drain timeout is reached with messages still in flight.").HasAnnotatedOptions(
+			ShutdownOnTimeoutForce, "Close all remaining components forcefully, in-flight messages may or may not have been delivered.",
+			ShutdownOnTimeoutNack, "Reject all in-flight messages back to the input before closing all remaining components forcefully, which allows inputs that support it to redeliver them. Messages that are rejected may still have been delivered, and therefore could be duplicated on the next run.",
+			ShutdownOnTimeoutComplete, "Continue waiting for in-flight messages to be delivered until the `shutdown_timeout` is reached.",
+		).HasDefault(ShutdownOnTimeoutForce),
+		docs.FieldObject("layer_timeouts", "Optional timeouts for each stage of the ordered shutdown. When a stage exceeds its timeout the `on_timeout` action is taken immediately. Empty values mean that a stage is only bound by the `drain_timeout`.").WithChildren(
+			docs.FieldString("input", "The maximum period of time to wait for the input to stop consuming.").HasDefault(""),
+			docs.FieldString("buffer", "The maximum period of time to wait for the buffer to empty, after the input has closed.").HasDefault(""),
+			docs.FieldString("output", "The maximum period of time to wait for the processors and output to finish, after the buffer has closed.").HasDefault(""),
+		),
+	).Advanced().AtVersion("4.24.0")
+}
+
+// OptShutdown sets the shutdown behaviour of the stream.
+func OptShutdown(conf ShutdownConfig) func(*Type) {
+	return func(t *Type) {
+		t.shutdownConf = conf
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type shutdownPolicy struct {
+	drainTimeout  time.Duration
+	onTimeout     string
+	inputTimeout  time.Duration
+	bufferTimeout time.Duration
+	outputTimeout time.Duration
+}
+
+func parseOptionalDuration(field, s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse shutdown %v: %w", field, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("shutdown %v must not be negative, got %v", field, s)
+	}
+	return d, nil
+}
+
+func newShutdownPolicy(conf ShutdownConfig) (p shutdownPolicy, err error) {
+	switch conf.OnTimeout {
+	case ShutdownOnTimeoutForce, ShutdownOnTimeoutNack, ShutdownOnTimeoutComplete:
+		p.onTimeout = conf.OnTimeout
+	case "":
+		p.onTimeout = ShutdownOnTimeoutForce
+	default:
+		return p, fmt.Errorf("shutdown on_timeout action not recognised: %v", conf.OnTimeout)
+	}
+	if p.drainTimeout, err = parseOptionalDuration("drain_timeout", conf.DrainTimeout); err != nil {
+		return
+	}
+	if p.inputTimeout, err = parseOptionalDuration("layer_timeouts.input", conf.LayerTimeouts.Input); err != nil {
+		return
+	}
+	if p.bufferTimeout, err = parseOptionalDuration("layer_timeouts.buffer", conf.LayerTimeouts.Buffer); err != nil {
+		return
+	}
+	if p.outputTimeout, err = parseOptionalDuration("layer_timeouts.output", conf.LayerTimeouts.Output); err != nil {
+		return
+	}
+	return
+}
+
+// layerCtx returns a context for a single stage of an ordered shutdown.
+func layerCtx(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+//------------------------------------------------------------------------------
+
+type inFlightTran struct {
+	tran    message.Transaction
+	ackOnce sync.Once
+}
+
+// inFlightTracker keeps track of transactions that have been consumed from the
+// input and not yet acknowledged, so that they can be rejected on demand.
+type inFlightTracker struct {
+	mut      sync.Mutex
+	pending  map[*inFlightTran]struct{}
+	emptySig chan struct{}
+}
+
+func newInFlightTracker() *inFlightTracker {
+	return &inFlightTracker{
+		pending: map[*inFlightTran]struct{}{},
+	}
+}
+
+// track returns a transaction channel that forwards transactions from the
+// provided channel, and keeps track of those that are in flight.
+func (f *inFlightTracker) track(in <-chan message.Transaction) <-chan message.Transaction {
+	return trackTransactions(in, func(tran message.Transaction) func(context.Context, error) error {
+		t := &inFlightTran{tran: tran}
+
+		f.mut.Lock()
+		if len(f.pending) == 0 {
+			f.emptySig = make(chan struct{})
+		}
+		f.pending[t] = struct{}{}
+		f.mut.Unlock()
+
+		return func(ctx context.Context, err error) error {
+			var ackErr error
+			t.ackOnce.Do(func() {
+				f.remove(t)
+				ackErr = t.tran.Ack(ctx, err)
+			})
+			return ackErr
+		}
+	})
+}
+
+func (f *inFlightTracker) remove(t *inFlightTran) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if _, exists := f.pending[t]; !exists {
+		return
+	}
+	delete(f.pending, t)
+	if len(f.pending) == 0 {
+		close(f.emptySig)
+	}
+}
+
+// waitForEmpty blocks until there are no transactions in flight or the context
+// is cancelled.
+func (f *inFlightTracker) waitForEmpty(ctx context.Context) error {
+	f.mut.Lock()
+	if len(f.pending) == 0 {
+		f.mut.Unlock()
+		return nil
+	}
+	emptySig := f.emptySig
+	f.mut.Unlock()
+
+	select {
+	case <-emptySig:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// nackAll rejects all transactions that are in flight, acknowledgements that
+// arrive afterwards for those transactions are ignored. Returns the number of
+// transactions that were rejected.
+func (f *inFlightTracker) nackAll(ctx context.Context) int {
+	f.mut.Lock()
+	pending := make([]*inFlightTran, 0, len(f.pending))
+	for t := range f.pending {
+		pending = append(pending, t)
+	}
+	if len(f.pending) > 0 {
+		f.pending = map[*inFlightTran]struct{}{}
+		close(f.emptySig)
+	}
+	f.mut.Unlock()
+
+	for _, t := range pending {
+		t.ackOnce.Do(func() {
+			_ = t.tran.Ack(ctx, ErrShutdownDrainTimeout)
+		})
+	}
+	return len(pending)
+}
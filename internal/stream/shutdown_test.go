@@ -0,0 +1,173 @@
+package stream_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+)
+
+func newShutdownTestStream(t testing.TB, shutConf stream.ShutdownConfig) (strm *stream.Type, inChan chan message.Transaction, outChan <-chan message.Transaction) {
+	t.Helper()
+
+	conf := stream.NewConfig()
+	conf.Input.Type = "inproc"
+	conf.Input.Inproc = "in"
+	conf.Output.Type = "inproc"
+	conf.Output.Inproc = "out"
+
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	inChan = make(chan message.Transaction)
+	mgr.SetPipe("in", inChan)
+
+	strm, err = stream.New(conf, mgr, stream.OptShutdown(shutConf))
+	require.NoError(t, err)
+
+	outChan, err = mgr.GetPipe("out")
+	require.NoError(t, err)
+	return
+}
+
+func sendShutdownTestMessage(t testing.TB, inChan chan message.Transaction, outChan <-chan message.Transaction) (message.Transaction, chan error) {
+	t.Helper()
+
+	resChan := make(chan error, 1)
+	select {
+	case inChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+
+	select {
+	case tran := <-outChan:
+		return tran, resChan
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	return message.Transaction{}, nil
+}
+
+func TestShutdownConfigErrors(t *testing.T) {
+	conf := stream.NewConfig()
+	conf.Input.Type = "generate"
+	conf.Input.Generate.Mapping = "root = {}"
+	conf.Output.Type = "drop"
+
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	shutConf := stream.NewShutdownConfig()
+	shutConf.OnTimeout = "nope"
+	_, err = stream.New(conf, mgr, stream.OptShutdown(shutConf))
+	require.EqualError(t, err, "shutdown on_timeout action not recognised: nope")
+
+	shutConf = stream.NewShutdownConfig()
+	shutConf.LayerTimeouts.Buffer = "-1s"
+	_, err = stream.New(conf, mgr, stream.OptShutdown(shutConf))
+	require.EqualError(t, err, "shutdown layer_timeouts.buffer must not be negative, got -1s")
+}
+
+func TestShutdownNackOnTimeout(t *testing.T) {
+	shutConf := stream.NewShutdownConfig()
+	shutConf.DrainTimeout = "100ms"
+	shutConf.OnTimeout = stream.ShutdownOnTimeoutNack
+
+	strm, inChan, outChan := newShutdownTestStream(t, shutConf)
+	tran, resChan := sendShutdownTestMessage(t, inChan, outChan)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	// The output never acknowledges the message, and so it is rejected once the
+	// drain timeout is reached.
+	go func() {
+		_ = strm.Stop(ctx)
+	}()
+
+	select {
+	case err := <-resChan:
+		assert.ErrorIs(t, err, stream.ErrShutdownDrainTimeout)
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	// Late acknowledgements are ignored.
+	require.NoError(t, tran.Ack(ctx, nil))
+	select {
+	case err := <-resChan:
+		t.Fatalf("unexpected acknowledgement: %v", err)
+	case <-time.After(time.Millisecond * 50):
+	}
+}
+
+func TestShutdownCompleteOnTimeout(t *testing.T) {
+	shutConf := stream.NewShutdownConfig()
+	shutConf.DrainTimeout = "10ms"
+	shutConf.OnTimeout = stream.ShutdownOnTimeoutComplete
+
+	strm, inChan, outChan := newShutdownTestStream(t, shutConf)
+	tran, resChan := sendShutdownTestMessage(t, inChan, outChan)
+
+	go func() {
+		time.Sleep(time.Millisecond * 200)
+		_ = tran.Ack(context.Background(), nil)
+	}()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	require.NoError(t, strm.Stop(ctx))
+	select {
+	case err := <-resChan:
+		assert.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+}
+
+func TestShutdownCompleteLayerTimeout(t *testing.T) {
+	shutConf := stream.NewShutdownConfig()
+	shutConf.OnTimeout = stream.ShutdownOnTimeoutComplete
+	shutConf.LayerTimeouts.Output = "50ms"
+
+	strm, inChan, outChan := newShutdownTestStream(t, shutConf)
+	_, resChan := sendShutdownTestMessage(t, inChan, outChan)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	// In-flight messages are never rejected when completing.
+	require.NoError(t, strm.Stop(ctx))
+	select {
+	case err := <-resChan:
+		t.Fatalf("unexpected acknowledgement: %v", err)
+	case <-time.After(time.Millisecond * 50):
+	}
+}
+
+func TestShutdownLayerTimeout(t *testing.T) {
+	shutConf := stream.NewShutdownConfig()
+	shutConf.OnTimeout = stream.ShutdownOnTimeoutNack
+	shutConf.LayerTimeouts.Output = "50ms"
+
+	strm, inChan, outChan := newShutdownTestStream(t, shutConf)
+	_, _ = sendShutdownTestMessage(t, inChan, outChan)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	err := strm.StopGracefully(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "output: ")
+	assert.NoError(t, ctx.Err())
+
+	require.NoError(t, strm.StopUnordered(ctx))
+}
@@ -0,0 +1,43 @@
+package stream
+
+import (
+	"context"
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// trackTransactions returns a transaction channel that forwards transactions
+// from the provided channel. The track closure is called with each transaction
+// before it is forwarded, and the acknowledgement func it returns, if not nil,
+// replaces the acknowledgement of the forwarded transaction. The returned func
+// is called at most once and is responsible for propagating the
+// acknowledgement, subsequent acknowledgements are ignored.
+func trackTransactions(
+	in <-chan message.Transaction,
+	track func(tran message.Transaction) func(ctx context.Context, err error) error,
+) <-chan message.Transaction {
+	out := make(chan message.Transaction)
+	go func() {
+		defer close(out)
+		for tran := range in {
+			ackFn := track(tran)
+			if ackFn == nil {
+				out <- tran
+				continue
+			}
+
+			var ackOnce sync.Once
+			var ackErr error
+			wrapped := message.NewTransactionFunc(tran.Payload, func(ctx context.Context, err error) error {
+				ackOnce.Do(func() {
+					ackErr = ackFn(ctx, err)
+				})
+				return ackErr
+			})
+
+			out <- *wrapped.WithContext(tran.Context())
+		}
+	}()
+	return out
+}
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
-	"net/http"
+	"fmt"
 	"runtime/pprof"
 	"sync/atomic"
 	"time"
@@ -29,6 +29,20 @@ type Type struct {
 
 	manager bundle.NewManagement
 
+	readinessStrict bool
+	readinessGrace  time.Duration
+
+	watchdogConf      WatchdogConfig
+	onWatchdogRestart func()
+	watchdog          *watchdog
+
+	jobTracker *JobTracker
+	sampler    func(message.Batch)
+
+	shutdownConf ShutdownConfig
+	shutdown     shutdownPolicy
+	inFlight     *inFlightTracker
+
 	onClose func()
 	closed  uint32
 }
@@ -44,37 +58,31 @@ func New(conf Config, mgr bundle.NewManagement, opts ...func(*Type)) (*Type, err
 	for _, opt := range opts {
 		opt(t)
 	}
-	if err := t.start(); err != nil {
+	var err error
+	if t.shutdown, err = newShutdownPolicy(t.shutdownConf); err != nil {
 		return nil, err
 	}
-
-	healthCheck := func(w http.ResponseWriter, r *http.Request) {
-		inputConnected := t.inputLayer.Connected()
-		outputConnected := t.outputLayer.Connected()
-
-		if atomic.LoadUint32(&t.closed) == 1 {
-			http.Error(w, "Stream terminated", http.StatusNotFound)
-			return
-		}
-
-		if inputConnected && outputConnected {
-			_, _ = w.Write([]byte("OK"))
-			return
-		}
-
-		w.WriteHeader(http.StatusServiceUnavailable)
-		if !inputConnected {
-			_, _ = w.Write([]byte("input not connected\n"))
-		}
-		if !outputConnected {
-			_, _ = w.Write([]byte("output not connected\n"))
+	if t.watchdogConf.Enabled {
+		if t.watchdog, err = newWatchdog(t.watchdogConf, t.onWatchdogRestart, mgr); err != nil {
+			return nil, err
 		}
 	}
+	if err := t.start(); err != nil {
+		return nil, err
+	}
+
 	t.manager.RegisterEndpoint(
 		"/ready",
 		"Returns 200 OK if all inputs and outputs are connected, otherwise a 503 is returned.",
-		healthCheck,
+		t.readinessHandler,
 	)
+	if t.watchdog != nil && t.watchdog.action == WatchdogActionFailLiveness {
+		t.manager.RegisterEndpoint(
+			"/ping",
+			"Returns 200 OK unless the watchdog has detected a stalled pipeline, in which case a 503 is returned.",
+			t.watchdog.livenessHandler,
+		)
+	}
 	return t, nil
 }
 
@@ -87,12 +95,29 @@ func OptOnClose(onClose func()) func(*Type) {
 	}
 }
 
+// OptSampler sets a closure to be called with each batch consumed from the
+// input, which must not modify the batch.
+func OptSampler(fn func(message.Batch)) func(*Type) {
+	return func(t *Type) {
+		t.sampler = fn
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // IsReady returns a boolean indicating whether both the input and output layers
-// of the stream are connected.
+// of the stream are connected, taking into account the readiness options of the
+// stream.
 func (t *Type) IsReady() bool {
-	return t.inputLayer.Connected() && t.outputLayer.Connected()
+	return t.readiness().Ready
+}
+
+// Connected returns booleans indicating whether the input and output layers of
+// the stream are individually connected, taking into account the readiness
+// options of the stream.
+func (t *Type) Connected() (input, output bool) {
+	r := t.readiness()
+	return r.inputConnected, r.outputConnected
 }
 
 func (t *Type) start() (err error) {
@@ -122,6 +147,20 @@ func (t *Type) start() (err error) {
 	var nextTranChan <-chan message.Transaction
 
 	nextTranChan = t.inputLayer.TransactionChan()
+	if t.shutdown.onTimeout != ShutdownOnTimeoutForce {
+		t.inFlight = newInFlightTracker()
+		nextTranChan = t.inFlight.track(nextTranChan)
+	}
+	if t.watchdog != nil {
+		nextTranChan = t.watchdog.track(nextTranChan)
+		go t.watchdog.loop()
+	}
+	if t.jobTracker != nil {
+		nextTranChan = t.jobTracker.track(nextTranChan)
+	}
+	if t.sampler != nil {
+		nextTranChan = sampleTransactions(nextTranChan, t.sampler)
+	}
 	if t.bufferLayer != nil {
 		if err = t.bufferLayer.Consume(nextTranChan); err != nil {
 			return
@@ -141,6 +180,9 @@ func (t *Type) start() (err error) {
 	go func(out output.Streamed) {
 		for {
 			if err := out.WaitForClose(context.Background()); err == nil {
+				if t.watchdog != nil {
+					t.watchdog.Close()
+				}
 				t.onClose()
 				atomic.StoreUint32(&t.closed, 1)
 				return
@@ -151,34 +193,62 @@ func (t *Type) start() (err error) {
 	return nil
 }
 
+// sampleTransactions returns a transaction channel that forwards transactions
+// from the provided channel after calling a closure with their payloads.
+func sampleTransactions(in <-chan message.Transaction, fn func(message.Batch)) <-chan message.Transaction {
+	return trackTransactions(in, func(tran message.Transaction) func(context.Context, error) error {
+		fn(tran.Payload)
+		return nil
+	})
+}
+
 // StopGracefully attempts to close the stream in the most graceful way by only
 // closing the input layer and waiting for all other layers to terminate by
 // proxy. This should guarantee that all in-flight and buffered data is resolved
-// before shutting down.
+// before shutting down. Each layer is given its configured shutdown timeout,
+// if any, within the deadline of the provided context.
 func (t *Type) StopGracefully(ctx context.Context) (err error) {
+	inputCtx, inputDone := layerCtx(ctx, t.shutdown.inputTimeout)
+	defer inputDone()
+
 	t.inputLayer.TriggerStopConsuming()
-	if err = t.inputLayer.WaitForClose(ctx); err != nil {
-		return
+	if err = t.inputLayer.WaitForClose(inputCtx); err != nil {
+		return fmt.Errorf("input: %w", err)
 	}
 
 	// If we have a buffer then wait right here. We want to try and allow the
 	// buffer to empty out before prompting the other layers to shut down.
 	if t.bufferLayer != nil {
+		bufferCtx, bufferDone := layerCtx(ctx, t.shutdown.bufferTimeout)
+		defer bufferDone()
+
 		t.bufferLayer.TriggerStopConsuming()
-		if err = t.bufferLayer.WaitForClose(ctx); err != nil {
-			return
+		if err = t.bufferLayer.WaitForClose(bufferCtx); err != nil {
+			return fmt.Errorf("buffer: %w", err)
 		}
 	}
 
 	// After this point we can start closing the remaining components.
+	outputCtx, outputDone := layerCtx(ctx, t.shutdown.outputTimeout)
+	defer outputDone()
+
 	if t.pipelineLayer != nil {
-		if err = t.pipelineLayer.WaitForClose(ctx); err != nil {
-			return
+		if err = t.pipelineLayer.WaitForClose(outputCtx); err != nil {
+			return fmt.Errorf("pipeline: %w", err)
 		}
 	}
 
-	if err = t.outputLayer.WaitForClose(ctx); err != nil {
-		return
+	if err = t.outputLayer.WaitForClose(outputCtx); err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+
+	// Some outputs hand off messages without waiting for them to be
+	// acknowledged, in which case we wait for the acknowledgements of any
+	// messages being tracked.
+	if t.inFlight != nil {
+		if err = t.inFlight.waitForEmpty(outputCtx); err != nil {
+			return fmt.Errorf("output: %w", err)
+		}
 	}
 	return nil
 }
@@ -227,10 +297,24 @@ func (t *Type) StopUnordered(ctx context.Context) (err error) {
 func (t *Type) Stop(ctx context.Context) error {
 	ctxCloseGraceful := ctx
 
-	// If the provided context has a known deadline then we calculate a period
-	// of time whereby it would be appropriate to abandon graceful termination
-	// and attempt ungraceful termination within that deadline.
-	if deadline, ok := ctx.Deadline(); ok {
+	// Unless a drain timeout is configured, if the provided context has a known
+	// deadline then we calculate a period of time whereby it would be
+	// appropriate to abandon graceful termination and attempt ungraceful
+	// termination within that deadline.
+	switch {
+	case t.shutdown.onTimeout == ShutdownOnTimeoutComplete:
+		// Wait for in-flight messages to be delivered for as long as the
+		// provided context allows.
+	case t.shutdown.drainTimeout > 0:
+		var gDone func()
+		ctxCloseGraceful, gDone = context.WithTimeout(ctx, t.shutdown.drainTimeout)
+		defer gDone()
+	default:
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			break
+		}
+
 		// The calculated time we're willing to wait for graceful termination is
 		// three quarters of the overall deadline.
 		tUntil := time.Until(deadline)
@@ -253,6 +337,12 @@ func (t *Type) Stop(ctx context.Context) error {
 		t.manager.Logger().Errorf("Encountered error whilst attempting to shut down gracefully: %v\n", err)
 	}
 
+	if t.inFlight != nil && t.shutdown.onTimeout == ShutdownOnTimeoutNack {
+		if n := t.inFlight.nackAll(ctx); n > 0 {
+			t.manager.Logger().Warnf("Rejected %v in-flight message batches that were not delivered within the shutdown drain timeout\n", n)
+		}
+	}
+
 	// If graceful termination failed then call unordered termination, if the
 	// overall ctx is already cancelled this will still trigger asynchronous
 	// clean up of resources, which is a best attempt.
@@ -2,6 +2,8 @@ package stream_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/message"
@@ -206,3 +209,119 @@ func TestHealthCheck(t *testing.T) {
 
 	validateHealthCheckResponse(t, mockAPIReg.server.URL, "Stream terminated\n")
 }
+
+func readinessReport(t *testing.T, serverURL string) (int, map[string]any) {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", serverURL+"/ready", http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var report map[string]any
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+	return res.StatusCode, report
+}
+
+func TestHealthCheckReadinessOptions(t *testing.T) {
+	tests := []struct {
+		name           string
+		strict         bool
+		grace          time.Duration
+		lostConnection bool
+		expectedCode   int
+		expectedStatus string
+	}{
+		{
+			name:           "not strict",
+			expectedCode:   http.StatusOK,
+			expectedStatus: "disconnected",
+		},
+		{
+			name:           "strict",
+			strict:         true,
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "disconnected",
+		},
+		{
+			name:           "strict never connected within grace period",
+			strict:         true,
+			grace:          time.Hour,
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "disconnected",
+		},
+		{
+			name:           "strict lost connection within grace period",
+			strict:         true,
+			grace:          time.Hour,
+			lostConnection: true,
+			expectedCode:   http.StatusOK,
+			expectedStatus: "degraded",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			conf := stream.NewConfig()
+			conf.Input.Type = "generate"
+			conf.Input.Generate.Mapping = "root = {}"
+			conf.Output.Type = "drop"
+
+			mockAPIReg := newMockAPIReg()
+			defer mockAPIReg.Close()
+
+			newMgr, err := manager.New(manager.NewResourceConfig(), manager.OptSetAPIReg(&mockAPIReg))
+			require.NoError(t, err)
+
+			// Emulates an output that isn't the top level output of the
+			// stream, such as the child of a broker.
+			tracker := component.NewConnectionStatusTracker("output", newMgr.IntoPath("output", "broker", "outputs", "1"))
+			defer tracker.Close()
+			if test.lostConnection {
+				tracker.SetConnected()
+			}
+			tracker.SetDisconnected(errors.New("nope"))
+
+			strm, err := stream.New(conf, newMgr, stream.OptReadiness(test.strict, test.grace))
+			require.NoError(t, err)
+
+			ctx, done := context.WithTimeout(context.Background(), time.Second)
+			defer done()
+			for {
+				code, report := readinessReport(t, mockAPIReg.server.URL)
+				components, _ := report["components"].([]any)
+				connected := 0
+				for _, c := range components {
+					if c.(map[string]any)["status"] == "connected" {
+						connected++
+					}
+				}
+				if connected == 2 {
+					assert.Equal(t, test.expectedCode, code)
+					assert.Equal(t, test.expectedCode == http.StatusOK, report["ready"])
+					require.Len(t, components, 3)
+
+					extra := components[0].(map[string]any)
+					assert.Equal(t, "output", extra["type"])
+					assert.Equal(t, "root.output.broker.outputs.1", extra["path"])
+					assert.Equal(t, test.expectedStatus, extra["status"])
+					assert.Equal(t, "nope", extra["last_error"])
+					break
+				}
+				select {
+				case <-ctx.Done():
+					t.Fatalf("Failed to start stream")
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+
+			stopCtx, stopDone := context.WithTimeout(context.Background(), time.Minute)
+			defer stopDone()
+			assert.NoError(t, strm.StopUnordered(stopCtx))
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// Actions that can be taken by the watchdog when a stall is detected.
+const (
+	WatchdogActionLog          = "log"
+	WatchdogActionFailLiveness = "fail_liveness"
+	WatchdogActionRestart      = "restart"
+	WatchdogActionHook         = "hook"
+)
+
+// WatchdogConfig contains configuration fields for a watchdog that detects
+// streams that have stopped making progress.
+type WatchdogConfig struct {
+	Enabled      bool   `json:"enabled" yaml:"enabled"`
+	StallTimeout string `json:"stall_timeout" yaml:"stall_timeout"`
+	Action       string `json:"action" yaml:"action"`
+	HookURL      string `json:"hook_url" yaml:"hook_url"`
+}
+
+// NewWatchdogConfig creates a new watchdog config with default values.
+func NewWatchdogConfig() WatchdogConfig {
+	return WatchdogConfig{
+		Enabled:      false,
+		StallTimeout: "5m",
+		Action:       WatchdogActionLog,
+		HookURL:      "",
+	}
+}
+
+// WatchdogFieldSpec returns a field spec for the watchdog configuration.
+func WatchdogFieldSpec() docs.FieldSpec {
+	return docs.FieldObject(
+		"watchdog", "Configures a watchdog that detects when a pipeline stops making progress, which is when messages have been consumed by the input but none have been acknowledged for a period of time.",
+	).WithChildren(
+		docs.FieldBool("enabled", "Whether the watchdog is enabled.").HasDefault(false),
+		docs.FieldString("stall_timeout", "The period of time without any acknowledgements, whilst messages are pending, after which the pipeline is considered stalled.", "1m", "10m").HasDefault("5m"),
+		docs.FieldString("action", "The action to take when a stall is detected. A stall is always logged.").HasAnnotatedOptions(
+			WatchdogActionLog, "Only log the stall.",
+			WatchdogActionFailLiveness, "The `/ping` endpoint returns a 503 status until the pipeline makes progress again.",
+			WatchdogActionRestart, "Close the stream and create it again from the same config. This action is only supported when running a single config (not streams mode), and otherwise falls back to `log`.",
+			WatchdogActionHook, "Send an HTTP POST request with a JSON body describing the stall to `hook_url`.",
+		).HasDefault(WatchdogActionLog),
+		docs.FieldString("hook_url", "The URL to send stall events to when the `action` is `hook`.").HasDefault(""),
+	).Advanced().AtVersion("4.24.0")
+}
+
+// OptWatchdog enables a watchdog on the stream.
+func OptWatchdog(conf WatchdogConfig) func(*Type) {
+	return func(t *Type) {
+		t.watchdogConf = conf
+	}
+}
+
+// OptOnWatchdogRestart sets a closure to be called when the watchdog detects a
+// stall and is configured to restart the stream. The closure is responsible
+// for stopping the stream and creating it again.
+func OptOnWatchdogRestart(fn func()) func(*Type) {
+	return func(t *Type) {
+		t.onWatchdogRestart = fn
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// WatchdogEvent describes a stall detected by a watchdog, and is the body of
+// requests sent to the hook URL.
+type WatchdogEvent struct {
+	Event        string    `json:"event"`
+	Pending      int       `json:"pending"`
+	StalledFor   string    `json:"stalled_for"`
+	LastProgress time.Time `json:"last_progress"`
+}
+
+type watchdog struct {
+	timeout   time.Duration
+	action    string
+	hookURL   string
+	onRestart func()
+
+	log     log.Modular
+	mStalls metrics.StatCounter
+
+	mut          sync.Mutex
+	pending      int
+	lastProgress time.Time
+	stalled      bool
+
+	hookClient *http.Client
+	closeChan  chan struct{}
+	closeOnce  sync.Once
+}
+
+func newWatchdog(conf WatchdogConfig, onRestart func(), mgr bundle.NewManagement) (*watchdog, error) {
+	timeout, err := time.ParseDuration(conf.StallTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse watchdog stall_timeout: %w", err)
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("watchdog stall_timeout must be greater than zero, got %v", conf.StallTimeout)
+	}
+
+	w := &watchdog{
+		timeout:      timeout,
+		action:       conf.Action,
+		hookURL:      conf.HookURL,
+		onRestart:    onRestart,
+		log:          mgr.Logger(),
+		mStalls:      mgr.Metrics().GetCounter("watchdog_stalls"),
+		lastProgress: time.Now(),
+		hookClient:   &http.Client{Timeout: 10 * time.Second},
+		closeChan:    make(chan struct{}),
+	}
+
+	switch w.action {
+	case WatchdogActionLog, WatchdogActionFailLiveness:
+	case WatchdogActionRestart:
+		if w.onRestart == nil {
+			w.log.Warnln("Watchdog restart action is not supported in this mode, stalls will only be logged")
+			w.action = WatchdogActionLog
+		}
+	case WatchdogActionHook:
+		if w.hookURL == "" {
+			return nil, fmt.Errorf("watchdog action %v requires a hook_url", w.action)
+		}
+	default:
+		return nil, fmt.Errorf("watchdog action not recognised: %v", w.action)
+	}
+	return w, nil
+}
+
+// track returns a transaction channel that forwards transactions from the
+// provided channel, and keeps track of their acknowledgements.
+func (w *watchdog) track(in <-chan message.Transaction) <-chan message.Transaction {
+	return trackTransactions(in, func(tran message.Transaction) func(context.Context, error) error {
+		w.mut.Lock()
+		if w.pending == 0 {
+			// Time spent idle without pending messages isn't a stall.
+			w.lastProgress = time.Now()
+		}
+		w.pending++
+		w.mut.Unlock()
+
+		return func(ctx context.Context, err error) error {
+			w.mut.Lock()
+			w.pending--
+			w.lastProgress = time.Now()
+			w.stalled = false
+			w.mut.Unlock()
+			return tran.Ack(ctx, err)
+		}
+	})
+}
+
+func (w *watchdog) loop() {
+	checkPeriod := w.timeout / 10
+	if checkPeriod > 10*time.Second {
+		checkPeriod = 10 * time.Second
+	}
+
+	ticker := time.NewTicker(checkPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.closeChan:
+			return
+		}
+		if event, isNew := w.check(time.Now()); isNew {
+			w.react(event)
+		}
+	}
+}
+
+// check returns an event describing the current stall, if any, and whether the
+// stall is newly detected.
+func (w *watchdog) check(now time.Time) (WatchdogEvent, bool) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	if w.stalled || w.pending == 0 || now.Sub(w.lastProgress) < w.timeout {
+		return WatchdogEvent{}, false
+	}
+	w.stalled = true
+	return WatchdogEvent{
+		Event:        "pipeline_stalled",
+		Pending:      w.pending,
+		StalledFor:   now.Sub(w.lastProgress).Round(time.Second).String(),
+		LastProgress: w.lastProgress.UTC(),
+	}, true
+}
+
+func (w *watchdog) react(event WatchdogEvent) {
+	w.mStalls.Incr(1)
+	w.log.Errorf("Pipeline stalled: %v pending message batches have not been acknowledged for %v\n", event.Pending, event.StalledFor)
+
+	switch w.action {
+	case WatchdogActionRestart:
+		w.log.Warnln("Restarting stream due to stalled pipeline")
+		go w.onRestart()
+	case WatchdogActionHook:
+		go w.sendHook(event)
+	}
+}
+
+func (w *watchdog) sendHook(event WatchdogEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		w.log.Errorf("Failed to marshal watchdog event: %v\n", err)
+		return
+	}
+	res, err := w.hookClient.Post(w.hookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		w.log.Errorf("Failed to send watchdog event: %v\n", err)
+		return
+	}
+	_ = res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		w.log.Errorf("Failed to send watchdog event: unexpected status code %v\n", res.StatusCode)
+	}
+}
+
+// Stalled returns whether a stall has been detected and the pipeline has not
+// yet made progress since.
+func (w *watchdog) Stalled() bool {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	return w.stalled
+}
+
+func (w *watchdog) livenessHandler(rw http.ResponseWriter, r *http.Request) {
+	if w.Stalled() {
+		http.Error(rw, "pipeline stalled", http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = rw.Write([]byte("pong"))
+}
+
+func (w *watchdog) Close() {
+	w.closeOnce.Do(func() {
+		close(w.closeChan)
+	})
+}
@@ -0,0 +1,165 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func TestWatchdogDetectsStall(t *testing.T) {
+	conf := NewWatchdogConfig()
+	conf.Enabled = true
+	conf.StallTimeout = "1m"
+	conf.Action = WatchdogActionFailLiveness
+
+	w, err := newWatchdog(conf, nil, mock.NewManager())
+	require.NoError(t, err)
+
+	in := make(chan message.Transaction)
+	out := w.track(in)
+
+	resChan := make(chan error, 1)
+	in <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("foo")}), resChan)
+	tran := <-out
+
+	_, isNew := w.check(time.Now())
+	assert.False(t, isNew)
+
+	event, isNew := w.check(time.Now().Add(2 * time.Minute))
+	require.True(t, isNew)
+	assert.Equal(t, 1, event.Pending)
+	assert.True(t, w.Stalled())
+
+	// Stalls are only reported once.
+	_, isNew = w.check(time.Now().Add(3 * time.Minute))
+	assert.False(t, isNew)
+
+	rec := httptest.NewRecorder()
+	w.livenessHandler(rec, httptest.NewRequest("GET", "/ping", http.NoBody))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	require.NoError(t, tran.Ack(context.Background(), nil))
+	require.NoError(t, <-resChan)
+	assert.False(t, w.Stalled())
+
+	rec = httptest.NewRecorder()
+	w.livenessHandler(rec, httptest.NewRequest("GET", "/ping", http.NoBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Without pending messages there is no stall.
+	_, isNew = w.check(time.Now().Add(time.Hour))
+	assert.False(t, isNew)
+
+	close(in)
+	_, open := <-out
+	assert.False(t, open)
+}
+
+func TestWatchdogDuplicateAcks(t *testing.T) {
+	conf := NewWatchdogConfig()
+	conf.Enabled = true
+	conf.StallTimeout = "1m"
+
+	w, err := newWatchdog(conf, nil, mock.NewManager())
+	require.NoError(t, err)
+
+	in := make(chan message.Transaction)
+	out := w.track(in)
+
+	var trans []message.Transaction
+	for i := 0; i < 2; i++ {
+		in <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("foo")}), make(chan error, 1))
+		trans = append(trans, <-out)
+	}
+
+	// Acknowledging the same transaction twice must not hide the other one.
+	require.NoError(t, trans[0].Ack(context.Background(), nil))
+	require.NoError(t, trans[0].Ack(context.Background(), nil))
+
+	event, isNew := w.check(time.Now().Add(2 * time.Minute))
+	require.True(t, isNew)
+	assert.Equal(t, 1, event.Pending)
+
+	close(in)
+}
+
+func TestWatchdogHook(t *testing.T) {
+	eventChan := make(chan WatchdogEvent, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var event WatchdogEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		eventChan <- event
+	}))
+	defer ts.Close()
+
+	conf := NewWatchdogConfig()
+	conf.Enabled = true
+	conf.StallTimeout = "1m"
+	conf.Action = WatchdogActionHook
+	conf.HookURL = ts.URL
+
+	w, err := newWatchdog(conf, nil, mock.NewManager())
+	require.NoError(t, err)
+
+	w.react(WatchdogEvent{Event: "pipeline_stalled", Pending: 3, StalledFor: "1m0s"})
+
+	select {
+	case event := <-eventChan:
+		assert.Equal(t, "pipeline_stalled", event.Event)
+		assert.Equal(t, 3, event.Pending)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for hook")
+	}
+}
+
+func TestWatchdogRestart(t *testing.T) {
+	restarted := make(chan struct{})
+
+	conf := NewWatchdogConfig()
+	conf.Enabled = true
+	conf.Action = WatchdogActionRestart
+
+	w, err := newWatchdog(conf, func() { close(restarted) }, mock.NewManager())
+	require.NoError(t, err)
+
+	w.react(WatchdogEvent{Event: "pipeline_stalled"})
+
+	select {
+	case <-restarted:
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for restart")
+	}
+}
+
+func TestWatchdogConfigErrors(t *testing.T) {
+	conf := NewWatchdogConfig()
+	conf.StallTimeout = "nope"
+	_, err := newWatchdog(conf, nil, mock.NewManager())
+	require.Error(t, err)
+
+	conf = NewWatchdogConfig()
+	conf.Action = WatchdogActionHook
+	_, err = newWatchdog(conf, nil, mock.NewManager())
+	require.Error(t, err)
+
+	conf = NewWatchdogConfig()
+	conf.Action = "nope"
+	_, err = newWatchdog(conf, nil, mock.NewManager())
+	require.Error(t, err)
+
+	// Restart without a handler falls back to logging.
+	conf = NewWatchdogConfig()
+	conf.Action = WatchdogActionRestart
+	w, err := newWatchdog(conf, nil, mock.NewManager())
+	require.NoError(t, err)
+	assert.Equal(t, WatchdogActionLog, w.action)
+}
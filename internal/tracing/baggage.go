@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel/baggage"
+
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// GetBaggage returns the members of the baggage attached to a message part as
+// a map of keys to values. Returns an empty map if the part doesn't have any
+// baggage attached.
+func GetBaggage(p *message.Part) map[string]any {
+	members := baggage.FromContext(message.GetContext(p)).Members()
+	m := make(map[string]any, len(members))
+	for _, member := range members {
+		m[member.Key()] = member.Value()
+	}
+	return m
+}
+
+// GetBaggageMember returns the value of a member of the baggage attached to a
+// message part, and whether it exists.
+func GetBaggageMember(p *message.Part, key string) (string, bool) {
+	member := baggage.FromContext(message.GetContext(p)).Member(key)
+	if member.Key() == "" {
+		return "", false
+	}
+	return member.Value(), true
+}
+
+// WithBaggageMembers returns a context with members added to its baggage, which
+// replace any existing members of the same key. Members with a nil value are
+// removed from the baggage instead.
+func WithBaggageMembers(ctx context.Context, members map[string]any) (context.Context, error) {
+	b := baggage.FromContext(ctx)
+	for k, v := range members {
+		if v == nil {
+			b = b.DeleteMember(k)
+			continue
+		}
+		vStr, ok := v.(string)
+		if !ok {
+			vStr = fmt.Sprintf("%v", v)
+		}
+		member, err := baggage.NewMember(k, url.QueryEscape(vStr))
+		if err != nil {
+			return nil, fmt.Errorf("baggage member %v: %w", k, err)
+		}
+		if b, err = b.SetMember(member); err != nil {
+			return nil, fmt.Errorf("baggage member %v: %w", k, err)
+		}
+	}
+	return baggage.ContextWithBaggage(ctx, b), nil
+}
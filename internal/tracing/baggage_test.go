@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func TestBaggageMembers(t *testing.T) {
+	part := message.NewPart([]byte("hello"))
+	assert.Equal(t, map[string]any{}, GetBaggage(part))
+
+	ctx, err := WithBaggageMembers(part.GetContext(), map[string]any{
+		"tenant": "foo bar",
+		"count":  5,
+		"nope":   nil,
+	})
+	require.NoError(t, err)
+	part = part.WithContext(ctx)
+
+	assert.Equal(t, map[string]any{
+		"tenant": "foo bar",
+		"count":  "5",
+	}, GetBaggage(part))
+
+	v, exists := GetBaggageMember(part, "tenant")
+	assert.True(t, exists)
+	assert.Equal(t, "foo bar", v)
+
+	_, exists = GetBaggageMember(part, "nope")
+	assert.False(t, exists)
+
+	ctx, err = WithBaggageMembers(part.GetContext(), map[string]any{
+		"tenant": nil,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"count": "5"}, GetBaggage(part.WithContext(ctx)))
+
+	_, err = WithBaggageMembers(context.Background(), map[string]any{
+		"bad key": "foo",
+	})
+	require.Error(t, err)
+}
+
+func TestBaggagePropagation(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	tp := trace.NewNoopTracerProvider()
+
+	batch := message.Batch{message.NewPart([]byte("hello"))}
+	require.NoError(t, InitSpansFromParentTextMap(tp, "test", map[string]any{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"baggage":     "tenant=foo",
+	}, batch))
+
+	assert.Equal(t, map[string]any{"tenant": "foo"}, GetBaggage(batch[0]))
+
+	ctx, err := WithBaggageMembers(batch[0].GetContext(), map[string]any{"user": "bar"})
+	require.NoError(t, err)
+
+	textMap, err := GetSpanFromContext(ctx).TextMap()
+	require.NoError(t, err)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", textMap["traceparent"])
+	assert.Contains(t, textMap["baggage"], "tenant=foo")
+	assert.Contains(t, textMap["baggage"], "user=bar")
+}
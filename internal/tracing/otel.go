@@ -109,6 +109,41 @@ func WithSiblingSpans(prov trace.TracerProvider, operationName string, batch mes
 	return newParts, spans
 }
 
+// WithJoinedSpan creates a span representing the joining of many source
+// messages into a single message, which is a child of the span of the first
+// source context and is linked to the spans of all others. The span is
+// finished immediately and a context containing it is returned, which can be
+// attached to the joined message.
+func WithJoinedSpan(prov trace.TracerProvider, operationName string, sources ...context.Context) context.Context {
+	if len(sources) == 0 {
+		return context.Background()
+	}
+	var links []trace.Link
+	for _, ctx := range sources[1:] {
+		if sCtx := trace.SpanContextFromContext(ctx); sCtx.IsValid() {
+			links = append(links, trace.Link{SpanContext: sCtx})
+		}
+	}
+	ctx, t := prov.Tracer(name).Start(sources[0], operationName, trace.WithLinks(links...))
+	t.End()
+	return ctx
+}
+
+// WithSplitSpans creates a span for each of a number of messages split from a
+// single source message, each of which is a child of the span of the source
+// context. The spans are finished immediately and a context containing each is
+// returned, which can be attached to the split messages in order to
+// distinguish their traces.
+func WithSplitSpans(prov trace.TracerProvider, operationName string, source context.Context, n int) []context.Context {
+	ctxs := make([]context.Context, n)
+	for i := range ctxs {
+		var t trace.Span
+		ctxs[i], t = prov.Tracer(name).Start(source, operationName)
+		t.End()
+	}
+	return ctxs
+}
+
 //------------------------------------------------------------------------------
 
 // InitSpans sets up OpenTracing spans on each message part if one does not
@@ -1,11 +1,15 @@
 package tracing
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/benthosdev/benthos/v4/internal/message"
@@ -37,3 +41,52 @@ func TestInitSpansFromParentTextMap(t *testing.T) {
 		assert.Equal(t, "00f067aa0ba902b7", spanTwo.SpanContext().SpanID().String())
 	})
 }
+
+func TestWithJoinedSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var sources []context.Context
+	for _, name := range []string{"a", "b", "c"} {
+		ctx, span := tp.Tracer("test").Start(context.Background(), name)
+		span.End()
+		sources = append(sources, ctx)
+	}
+
+	joinedCtx := WithJoinedSpan(tp, "join", sources...)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+
+	joined := spans[3]
+	assert.Equal(t, "join", joined.Name())
+	assert.Equal(t, trace.SpanContextFromContext(joinedCtx), joined.SpanContext())
+	assert.Equal(t, trace.SpanContextFromContext(sources[0]), joined.Parent())
+
+	require.Len(t, joined.Links(), 2)
+	assert.Equal(t, trace.SpanContextFromContext(sources[1]), joined.Links()[0].SpanContext)
+	assert.Equal(t, trace.SpanContextFromContext(sources[2]), joined.Links()[1].SpanContext)
+}
+
+func TestWithSplitSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	sourceCtx, span := tp.Tracer("test").Start(context.Background(), "source")
+	span.End()
+
+	ctxs := WithSplitSpans(tp, "split", sourceCtx, 3)
+	require.Len(t, ctxs, 3)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+
+	seen := map[trace.SpanID]struct{}{}
+	for i, s := range spans[1:] {
+		assert.Equal(t, "split", s.Name())
+		assert.Equal(t, span.SpanContext(), s.Parent())
+		assert.Equal(t, trace.SpanContextFromContext(ctxs[i]), s.SpanContext())
+		seen[s.SpanContext().SpanID()] = struct{}{}
+	}
+	assert.Len(t, seen, 3)
+}
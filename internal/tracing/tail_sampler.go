@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type pendingTrace struct {
+	spans []sdktrace.ReadOnlySpan
+	keep  bool
+}
+
+// TailSampler is a span processor that holds back the spans of each trace
+// until its root span ends, at which point the spans are forwarded to another
+// span processor only when any of them recorded an error, or when the root
+// span exceeded a latency budget. Since the root span of a message is ended
+// once it has been delivered (or rejected) by an output the decision covers
+// the entire journey of the message.
+//
+// The number of traces held back is capped, and when exceeded the oldest
+// pending trace is dropped.
+type TailSampler struct {
+	next          sdktrace.SpanProcessor
+	latencyBudget time.Duration
+	maxPending    int
+
+	mut     sync.Mutex
+	pending map[trace.TraceID]*pendingTrace
+	order   []trace.TraceID
+}
+
+// NewTailSampler creates a span processor that only forwards the spans of
+// traces that errored or, when latencyBudget is greater than zero, took longer
+// than latencyBudget. When maxPending is greater than zero it caps the number
+// of traces that are held back whilst waiting for a decision.
+func NewTailSampler(next sdktrace.SpanProcessor, latencyBudget time.Duration, maxPending int) *TailSampler {
+	return &TailSampler{
+		next:          next,
+		latencyBudget: latencyBudget,
+		maxPending:    maxPending,
+		pending:       map[trace.TraceID]*pendingTrace{},
+	}
+}
+
+// isRootSpan returns true if a span has no parent within this process.
+func isRootSpan(s sdktrace.ReadOnlySpan) bool {
+	return !s.Parent().IsValid() || s.Parent().IsRemote()
+}
+
+func spanErrored(s sdktrace.ReadOnlySpan) bool {
+	if s.Status().Code == codes.Error {
+		return true
+	}
+	for _, attr := range s.Attributes() {
+		if attr.Key == "error" && attr.Value.AsString() == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// OnStart forwards the start of a span to the next span processor.
+func (t *TailSampler) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	t.next.OnStart(parent, s)
+}
+
+// OnEnd holds back an ended span until the root span of its trace ends, and
+// then either forwards or drops the spans of the trace.
+func (t *TailSampler) OnEnd(s sdktrace.ReadOnlySpan) {
+	traceID := s.SpanContext().TraceID()
+
+	t.mut.Lock()
+	p, exists := t.pending[traceID]
+	if !exists {
+		p = &pendingTrace{}
+		t.pending[traceID] = p
+		t.order = append(t.order, traceID)
+		t.evict()
+	}
+	p.spans = append(p.spans, s)
+	if !p.keep && spanErrored(s) {
+		p.keep = true
+	}
+	if !isRootSpan(s) {
+		t.mut.Unlock()
+		return
+	}
+
+	delete(t.pending, traceID)
+	keep := p.keep || (t.latencyBudget > 0 && s.EndTime().Sub(s.StartTime()) > t.latencyBudget)
+	t.mut.Unlock()
+
+	if !keep {
+		return
+	}
+	for _, ps := range p.spans {
+		t.next.OnEnd(ps)
+	}
+}
+
+// evict drops the oldest pending traces until the cap is satisfied, and also
+// compacts the order of pending traces, must be called whilst holding the
+// lock.
+func (t *TailSampler) evict() {
+	if len(t.order) > 2*len(t.pending)+64 {
+		order := make([]trace.TraceID, 0, len(t.pending))
+		for _, id := range t.order {
+			if _, exists := t.pending[id]; exists {
+				order = append(order, id)
+			}
+		}
+		t.order = order
+	}
+	for t.maxPending > 0 && len(t.pending) > t.maxPending && len(t.order) > 0 {
+		delete(t.pending, t.order[0])
+		t.order = t.order[1:]
+	}
+}
+
+// Shutdown drops all pending traces and shuts down the next span processor.
+func (t *TailSampler) Shutdown(ctx context.Context) error {
+	t.mut.Lock()
+	t.pending = map[trace.TraceID]*pendingTrace{}
+	t.order = nil
+	t.mut.Unlock()
+	return t.next.Shutdown(ctx)
+}
+
+// ForceFlush flushes the next span processor, traces that are pending a
+// decision are not flushed.
+func (t *TailSampler) ForceFlush(ctx context.Context) error {
+	return t.next.ForceFlush(ctx)
+}
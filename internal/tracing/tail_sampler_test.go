@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func tailSampledProvider(latencyBudget time.Duration, maxPending int) (*tracetest.SpanRecorder, trace.TracerProvider) {
+	rec := tracetest.NewSpanRecorder()
+	return rec, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(NewTailSampler(rec, latencyBudget, maxPending)))
+}
+
+func endedSpanNames(rec *tracetest.SpanRecorder) (names []string) {
+	for _, s := range rec.Ended() {
+		names = append(names, s.Name())
+	}
+	return
+}
+
+func TestTailSamplerErrors(t *testing.T) {
+	rec, prov := tailSampledProvider(0, 0)
+	tr := prov.Tracer("test")
+
+	ctx, root := tr.Start(context.Background(), "root")
+	_, child := tr.Start(ctx, "child")
+	child.End()
+	assert.Empty(t, rec.Ended())
+	root.End()
+	assert.Empty(t, rec.Ended())
+
+	ctx, root = tr.Start(context.Background(), "root_errored")
+	_, child = tr.Start(ctx, "child_errored")
+	child.SetStatus(codes.Error, "nope")
+	child.End()
+	assert.Empty(t, rec.Ended())
+	root.End()
+	assert.Equal(t, []string{"child_errored", "root_errored"}, endedSpanNames(rec))
+}
+
+func TestTailSamplerLatencyBudget(t *testing.T) {
+	rec, prov := tailSampledProvider(time.Second, 0)
+	tr := prov.Tracer("test")
+
+	start := time.Now()
+
+	_, root := tr.Start(context.Background(), "fast", trace.WithTimestamp(start))
+	root.End(trace.WithTimestamp(start.Add(time.Millisecond)))
+
+	_, root = tr.Start(context.Background(), "slow", trace.WithTimestamp(start))
+	root.End(trace.WithTimestamp(start.Add(time.Minute)))
+
+	assert.Equal(t, []string{"slow"}, endedSpanNames(rec))
+}
+
+func TestTailSamplerMaxPending(t *testing.T) {
+	rec, prov := tailSampledProvider(0, 1)
+	tr := prov.Tracer("test")
+
+	ctxA, rootA := tr.Start(context.Background(), "root_a")
+	_, childA := tr.Start(ctxA, "child_a")
+	childA.SetStatus(codes.Error, "nope")
+	childA.End()
+
+	ctxB, rootB := tr.Start(context.Background(), "root_b")
+	_, childB := tr.Start(ctxB, "child_b")
+	childB.SetStatus(codes.Error, "nope")
+	childB.End()
+
+	// The pending spans of trace A were dropped in order to make room for
+	// trace B.
+	rootB.End()
+	rootA.End()
+	assert.Equal(t, []string{"child_b", "root_b"}, endedSpanNames(rec))
+}
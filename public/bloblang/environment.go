@@ -336,6 +336,16 @@ func (e *Environment) WithMaxMapRecursion(n int) *Environment {
 	}
 }
 
+// WithMaps returns a copy of the environment where the map definitions of a
+// parsed mapping are available to interpolated strings and mappings parsed from
+// the environment, where they can be executed with the method `apply`. Maps
+// declared within a mapping take precedence over those of the environment.
+func (e *Environment) WithMaps(exec *Executor) *Environment {
+	return &Environment{
+		env: e.env.WithMaps(exec.exec),
+	}
+}
+
 // OnlyPure removes any methods and functions that have been registered but are
 // marked as impure. Impure in this context means the method/function is able to
 // mutate global state or access machine state (read environment variables,
@@ -106,3 +106,31 @@ func TestEnvironmentDisabledImports(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "imports are disabled in this context")
 }
+
+func TestEnvironmentWithMaps(t *testing.T) {
+	maps, err := Parse(`
+map foo {
+  root.foo = this.value.uppercase()
+}
+`)
+	require.NoError(t, err)
+
+	env := NewEnvironment().WithMaps(maps)
+
+	exe, err := env.Parse(`root = this.apply("foo")`)
+	require.NoError(t, err)
+
+	res, err := exe.Query(map[string]any{"value": "hello world"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"foo": "HELLO WORLD"}, res)
+
+	exe, err = env.Parse(`map foo {
+  root.bar = this.value
+}
+root = this.apply("foo")`)
+	require.NoError(t, err)
+
+	res, err = exe.Query(map[string]any{"value": "hello world"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"bar": "hello world"}, res)
+}
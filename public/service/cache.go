@@ -55,17 +55,42 @@ type batchedCache interface {
 	SetMulti(ctx context.Context, keyValues ...CacheItem) error
 }
 
+// incrementerCache represents a cache that is able to atomically increment
+// the integer value of a key. This interface is optional for caches and when
+// implemented allows the cache to be used for counting.
+type incrementerCache interface {
+	// Incr atomically adds a delta to the integer value of a key, where a key
+	// that does not exist is treated as zero, and returns the resulting value.
+	// The TTL of the key is set when provided.
+	Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error)
+}
+
+// compareAndSwapCache represents a cache that is able to atomically replace
+// the value of a key based on its current value. This interface is optional
+// for caches and when implemented allows the cache to be used for leases.
+type compareAndSwapCache interface {
+	// CompareAndSwap sets the value of a key only when its current value is
+	// equal to old, and returns whether the value was swapped. A key that does
+	// not exist never matches, and a nil value deletes the key rather than
+	// setting it. The TTL of the key is set when provided.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) (bool, error)
+}
+
 //------------------------------------------------------------------------------
 
 // Implements types.Cache.
 type airGapCache struct {
 	c  Cache
 	cm batchedCache
+	ci incrementerCache
+	cc compareAndSwapCache
 }
 
 func newAirGapCache(c Cache, stats metrics.Type) cache.V1 {
 	ag := &airGapCache{c: c, cm: nil}
 	ag.cm, _ = c.(batchedCache)
+	ag.ci, _ = c.(incrementerCache)
+	ag.cc, _ = c.(compareAndSwapCache)
 	return cache.MetricsForCache(ag, stats)
 }
 
@@ -113,6 +138,20 @@ func (a *airGapCache) Delete(ctx context.Context, key string) error {
 	return a.c.Delete(ctx, key)
 }
 
+func (a *airGapCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	if a.ci == nil {
+		return 0, cache.ErrIncrNotSupported
+	}
+	return a.ci.Incr(ctx, key, delta, ttl)
+}
+
+func (a *airGapCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) (bool, error) {
+	if a.cc == nil {
+		return false, cache.ErrCompareAndSwapNotSupported
+	}
+	return a.cc.CompareAndSwap(ctx, key, old, value, ttl)
+}
+
 func (a *airGapCache) Close(ctx context.Context) error {
 	return a.c.Close(ctx)
 }
@@ -151,6 +190,22 @@ func (r *reverseAirGapCache) Delete(ctx context.Context, key string) error {
 	return r.c.Delete(ctx, key)
 }
 
+func (r *reverseAirGapCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	i, ok := r.c.(cache.Incrementer)
+	if !ok {
+		return 0, cache.ErrIncrNotSupported
+	}
+	return i.Incr(ctx, key, delta, ttl)
+}
+
+func (r *reverseAirGapCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) (bool, error) {
+	c, ok := r.c.(cache.CompareAndSwapper)
+	if !ok {
+		return false, cache.ErrCompareAndSwapNotSupported
+	}
+	return c.CompareAndSwap(ctx, key, old, value, ttl)
+}
+
 func (r *reverseAirGapCache) Close(ctx context.Context) error {
 	return r.c.Close(ctx)
 }
@@ -90,6 +90,39 @@ func (c *closableCacheMulti) SetMulti(ctx context.Context, keyValues ...CacheIte
 	return nil
 }
 
+type closableCacheIncr struct {
+	*closableCache
+
+	counters map[string]int64
+}
+
+func (c *closableCacheIncr) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	if c.closableCache.err != nil {
+		return 0, c.closableCache.err
+	}
+	c.counters[key] += delta
+	return c.counters[key], nil
+}
+
+type closableCacheCAS struct {
+	*closableCache
+}
+
+func (c *closableCacheCAS) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) (bool, error) {
+	if c.closableCache.err != nil {
+		return false, c.closableCache.err
+	}
+	if i, ok := c.m[key]; !ok || string(i.b) != string(old) {
+		return false, nil
+	}
+	if value == nil {
+		delete(c.m, key)
+		return true, nil
+	}
+	c.m[key] = testCacheItem{b: value, ttl: ttl}
+	return true, nil
+}
+
 func TestCacheAirGapShutdown(t *testing.T) {
 	rl := &closableCache{}
 	agrl := newAirGapCache(rl, metrics.Noop())
@@ -490,3 +523,54 @@ func TestCacheReverseAirGapDelete(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, map[string]testCacheItem{}, rl.m)
 }
+
+func TestCacheAirGapIncr(t *testing.T) {
+	ctx := context.Background()
+
+	agrl := newAirGapCache(&closableCache{m: map[string]testCacheItem{}}, metrics.Noop())
+	_, err := agrl.(cache.Incrementer).Incr(ctx, "foo", 1, nil)
+	assert.Equal(t, cache.ErrIncrNotSupported, err)
+
+	rl := &closableCacheIncr{
+		closableCache: &closableCache{m: map[string]testCacheItem{}},
+		counters:      map[string]int64{},
+	}
+	agrl = newAirGapCache(rl, metrics.Noop())
+
+	v, err := agrl.(cache.Incrementer).Incr(ctx, "foo", 5, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), v)
+
+	v, err = agrl.(cache.Incrementer).Incr(ctx, "foo", -7, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-2), v)
+}
+
+func TestCacheAirGapCompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+
+	agrl := newAirGapCache(&closableCache{m: map[string]testCacheItem{}}, metrics.Noop())
+	_, err := agrl.(cache.CompareAndSwapper).CompareAndSwap(ctx, "foo", nil, []byte("bar"), nil)
+	assert.Equal(t, cache.ErrCompareAndSwapNotSupported, err)
+
+	rl := &closableCacheCAS{
+		closableCache: &closableCache{m: map[string]testCacheItem{
+			"foo": {b: []byte("bar")},
+		}},
+	}
+	agrl = newAirGapCache(rl, metrics.Noop())
+
+	swapped, err := agrl.(cache.CompareAndSwapper).CompareAndSwap(ctx, "foo", []byte("nope"), []byte("baz"), nil)
+	assert.NoError(t, err)
+	assert.False(t, swapped)
+
+	swapped, err = agrl.(cache.CompareAndSwapper).CompareAndSwap(ctx, "foo", []byte("bar"), []byte("baz"), nil)
+	assert.NoError(t, err)
+	assert.True(t, swapped)
+	assert.Equal(t, "baz", string(rl.m["foo"].b))
+
+	swapped, err = agrl.(cache.CompareAndSwapper).CompareAndSwap(ctx, "foo", []byte("baz"), nil, nil)
+	assert.NoError(t, err)
+	assert.True(t, swapped)
+	assert.Equal(t, map[string]testCacheItem{}, rl.m)
+}
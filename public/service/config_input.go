@@ -2,11 +2,11 @@ package service
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/input"
 	"github.com/benthosdev/benthos/v4/internal/docs"
 )
@@ -85,7 +85,7 @@ func (p *ParsedConfig) FieldInputList(path ...string) ([]*OwnedInput, error) {
 	tmpMgr := p.mgr.IntoPath(path...)
 	ins := make([]*OwnedInput, len(configs))
 	for i, c := range configs {
-		iproc, err := tmpMgr.IntoPath(strconv.Itoa(i)).NewInput(c)
+		iproc, err := tmpMgr.IntoPath(bundle.PathSegment(i, c.Label)).NewInput(c)
 		if err != nil {
 			return nil, fmt.Errorf("input %v: %w", i, err)
 		}
@@ -2,11 +2,11 @@ package service
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/output"
 	"github.com/benthosdev/benthos/v4/internal/docs"
 )
@@ -85,7 +85,7 @@ func (p *ParsedConfig) FieldOutputList(path ...string) ([]*OwnedOutput, error) {
 	tmpMgr := p.mgr.IntoPath(path...)
 	ins := make([]*OwnedOutput, len(configs))
 	for i, c := range configs {
-		iproc, err := tmpMgr.IntoPath(strconv.Itoa(i)).NewOutput(c)
+		iproc, err := tmpMgr.IntoPath(bundle.PathSegment(i, c.Label)).NewOutput(c)
 		if err != nil {
 			return nil, fmt.Errorf("output %v: %w", i, err)
 		}
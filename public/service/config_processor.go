@@ -2,11 +2,11 @@ package service
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/docs"
 )
@@ -93,7 +93,7 @@ func (p *ParsedConfig) FieldProcessorList(path ...string) ([]*OwnedProcessor, er
 	tmpMgr := p.mgr.IntoPath(path...)
 	procs := make([]*OwnedProcessor, len(procConfigs))
 	for i, c := range procConfigs {
-		iproc, err := tmpMgr.IntoPath(strconv.Itoa(i)).NewProcessor(c)
+		iproc, err := tmpMgr.IntoPath(bundle.PathSegment(i, c.Label)).NewProcessor(c)
 		if err != nil {
 			return nil, fmt.Errorf("processor %v: %w", i, err)
 		}
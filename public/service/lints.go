@@ -118,10 +118,17 @@ type Lint struct {
 	Column int
 	Type   LintType
 	What   string
+
+	// Label is the label of the closest component that encloses the lint, or
+	// empty if the component is not labelled.
+	Label string
 }
 
 // Error returns an error string.
 func (l Lint) Error() string {
+	if l.Label != "" {
+		return fmt.Sprintf("(%v,%v) %v: %v", l.Line, l.Column, l.Label, l.What)
+	}
 	return fmt.Sprintf("(%v,%v) %v", l.Line, l.Column, l.What)
 }
 
@@ -147,6 +154,7 @@ func convertDocsLint(l docs.Lint) Lint {
 		Column: l.Column,
 		Type:   convertDocsLintType(l.Type),
 		What:   l.What,
+		Label:  l.Label,
 	}
 }
 
@@ -828,7 +828,7 @@ func (s *StreamBuilder) buildWithEnv(env *bundle.Environment) (*Stream, error) {
 			sanitConf.DocsProvider = env
 			_ = config.Spec().SanitiseYAML(&sanitNode, sanitConf)
 		}
-		if apiType, err = api.New("", "", s.http, sanitNode, logger, stats); err != nil {
+		if apiType, err = api.New("", "", s.http, sanitNode, logger, stats, api.OptWithBloblangEnvironment(s.env.getBloblangParserEnv())); err != nil {
 			return nil, fmt.Errorf("unable to create stream HTTP server due to: %w. Tip: you can disable the server with `http.enabled` set to `false`, or override the configured server with SetHTTPMux", err)
 		}
 		apiMut = apiType
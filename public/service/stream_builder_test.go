@@ -530,6 +530,7 @@ drop: {}
     broker:
         copies: 1
         pattern: fan_out
+        partition_key: ""
         outputs:`,
 		`            - label: baz
               drop:`,
@@ -888,7 +889,14 @@ file:
 					name:    "stdout",
 					conf: `label: ""
 stdout:
-    codec: lines`,
+    codec: lines
+    compression:
+        level: -1
+        dictionary: ""
+    pretty:
+        enabled: false
+        metadata: true
+        color: auto`,
 				},
 				{
 					typeStr: "metrics",